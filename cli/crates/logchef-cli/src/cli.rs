@@ -3,8 +3,8 @@ use clap::{CommandFactory, Parser, Subcommand};
 use tracing_subscriber::EnvFilter;
 
 use crate::commands::{
-    auth, collections, completions, config, doctor, explain, fields, find, histogram, history,
-    open, query, saved_queries, schema, skills, sources, sql, tail, teams, whoami,
+    alerts, auth, collections, completions, config, doctor, explain, fields, find, histogram,
+    history, open, query, saved_queries, schema, skills, sources, sql, tail, teams, whoami,
 };
 
 const LONG_ABOUT: &str = "\
@@ -127,6 +127,9 @@ enum Commands {
     #[command(about = "List sources for a team")]
     Sources(sources::SourcesArgs),
 
+    #[command(about = "Export and import a team's alerts")]
+    Alerts(alerts::AlertsArgs),
+
     #[command(about = "Show schema for a source")]
     Schema(schema::SchemaArgs),
 
@@ -190,6 +193,7 @@ impl Cli {
             Some(Commands::Teams(args)) => teams::run(args, global).await,
             Some(Commands::Whoami(args)) => whoami::run(args, global).await,
             Some(Commands::Sources(args)) => sources::run(args, global).await,
+            Some(Commands::Alerts(args)) => alerts::run(args, global).await,
             Some(Commands::Schema(args)) => schema::run(args, global).await,
             Some(Commands::Doctor(args)) => doctor::run(args, global).await,
             Some(Commands::Config(args)) => config::run(args).await,
@@ -0,0 +1,198 @@
+use anyhow::{Context, Result};
+use clap::{Args, Subcommand};
+use logchef_core::Config;
+use logchef_core::api::{Client, ImportAlertsRequest};
+use logchef_core::cache::Cache;
+use std::io::Read;
+use std::path::PathBuf;
+
+use crate::cli::GlobalArgs;
+use crate::commands::resolve_team;
+use crate::session;
+
+#[derive(Args)]
+pub struct AlertsArgs {
+    #[command(subcommand)]
+    command: AlertsCmd,
+}
+
+#[derive(Subcommand)]
+enum AlertsCmd {
+    /// Export the alerts on a team's sources. Recipients are written as
+    /// emails and sources by name, so the document can move between servers.
+    Export {
+        /// Team ID or name
+        #[arg(long, short = 't')]
+        team: Option<String>,
+
+        /// Document format
+        #[arg(long, default_value = "yaml")]
+        format: DocumentFormat,
+
+        /// Write the document to FILE instead of stdout
+        #[arg(long = "file", short = 'f')]
+        file: Option<PathBuf>,
+    },
+    /// Import alerts from an exported document. Alerts are matched by source
+    /// and name: missing ones are created, changed ones updated.
+    Import {
+        /// Exported YAML or JSON document ("-" reads stdin)
+        file: PathBuf,
+
+        /// Team ID or name
+        #[arg(long, short = 't')]
+        team: Option<String>,
+
+        /// Report what would change without changing anything
+        #[arg(long)]
+        dry_run: bool,
+
+        /// Output format
+        #[arg(long, default_value = "text")]
+        output: OutputFormat,
+    },
+}
+
+#[derive(Clone, Debug, clap::ValueEnum)]
+enum DocumentFormat {
+    Yaml,
+    Json,
+}
+
+#[derive(Clone, Debug, clap::ValueEnum)]
+enum OutputFormat {
+    Text,
+    Json,
+    Jsonl,
+    Table,
+}
+
+pub async fn run(args: AlertsArgs, global: GlobalArgs) -> Result<()> {
+    let config = Config::load().context("Failed to load config")?;
+    let s = session::authed(&config, &global)?;
+    let (client, ctx) = (&s.client, &s.ctx);
+    let mut cache = Cache::new(&ctx.server_url);
+
+    match args.command {
+        AlertsCmd::Export { team, format, file } => {
+            let team = team.or_else(|| ctx.defaults.team_with_env());
+            let team_id = resolve_team(client, &mut cache, team).await?;
+            export(client, team_id, format, file).await
+        }
+        AlertsCmd::Import {
+            file,
+            team,
+            dry_run,
+            output,
+        } => {
+            let team = team.or_else(|| ctx.defaults.team_with_env());
+            let team_id = resolve_team(client, &mut cache, team).await?;
+            import(client, team_id, file, dry_run, output).await
+        }
+    }
+}
+
+async fn export(
+    client: &Client,
+    team_id: i64,
+    format: DocumentFormat,
+    file: Option<PathBuf>,
+) -> Result<()> {
+    let format = match format {
+        DocumentFormat::Yaml => "yaml",
+        DocumentFormat::Json => "json",
+    };
+    let document = client
+        .export_alerts(team_id, format)
+        .await
+        .context("Failed to export alerts")?;
+
+    match file {
+        Some(path) => {
+            std::fs::write(&path, &document)
+                .with_context(|| format!("Failed to write {}", path.display()))?;
+            eprintln!("Exported alerts to {}", path.display());
+        }
+        None => print!("{}", document),
+    }
+    Ok(())
+}
+
+async fn import(
+    client: &Client,
+    team_id: i64,
+    file: PathBuf,
+    dry_run: bool,
+    output: OutputFormat,
+) -> Result<()> {
+    let document = if file.as_os_str() == "-" {
+        let mut buf = String::new();
+        std::io::stdin()
+            .read_to_string(&mut buf)
+            .context("Failed to read document from stdin")?;
+        buf
+    } else {
+        std::fs::read_to_string(&file)
+            .with_context(|| format!("Failed to read {}", file.display()))?
+    };
+
+    let request = ImportAlertsRequest { document, dry_run };
+    let result = client
+        .import_alerts(team_id, &request)
+        .await
+        .context("Failed to import alerts")?;
+
+    match output {
+        OutputFormat::Json => {
+            println!("{}", serde_json::to_string_pretty(&result)?);
+        }
+        OutputFormat::Jsonl => {
+            for row in &result.alerts {
+                println!("{}", serde_json::to_string(row)?);
+            }
+        }
+        OutputFormat::Text | OutputFormat::Table => {
+            println!(
+                "{:<28} {:<20} {:<14} {:<6} ERROR",
+                "NAME", "SOURCE", "STATUS", "ID"
+            );
+            println!("{}", "-".repeat(96));
+            for row in &result.alerts {
+                let id = row
+                    .alert_id
+                    .map(|id| id.to_string())
+                    .unwrap_or_else(|| "-".to_string());
+                println!(
+                    "{:<28} {:<20} {:<14} {:<6} {}",
+                    truncate_str(&row.name, 28),
+                    truncate_str(&row.source, 20),
+                    row.status,
+                    id,
+                    row.error.as_deref().unwrap_or("")
+                );
+            }
+            let (created, updated) = if result.dry_run {
+                ("would be created", "would be updated")
+            } else {
+                ("created", "updated")
+            };
+            println!(
+                "\n{} {}, {} {}, {} unchanged, {} failed",
+                result.created, created, result.updated, updated, result.unchanged, result.failed
+            );
+        }
+    }
+
+    if result.failed > 0 {
+        anyhow::bail!("{} alert(s) failed to import", result.failed);
+    }
+    Ok(())
+}
+
+fn truncate_str(s: &str, max_len: usize) -> String {
+    if s.len() > max_len {
+        format!("{}...", &s[..max_len.saturating_sub(3)])
+    } else {
+        s.to_string()
+    }
+}
@@ -25,6 +25,10 @@ use crate::ui;
   logchef query --from '2026-07-14 09:00:00' --to '2026-07-14 10:00:00' \\
     --limit 500 --output jsonl | jq 'select(.status >= 500)'
 
+  # One day of errors as CSV, every column, for a spreadsheet
+  logchef query 'level=\"error\"' --from '2026-07-14 00:00:00' --to '2026-07-14 23:59:59' \\
+    --output csv > errors.csv
+
   # See the ClickHouse SQL / LogsQL a query compiles to, then run it
   logchef query 'status>=500' --since 15m --show-sql")]
 pub struct QueryArgs {
@@ -96,6 +100,7 @@ enum OutputFormat {
     JsonFlat,
     Table,
     Msg,
+    Csv,
 }
 
 #[derive(Serialize)]
@@ -313,6 +318,15 @@ pub async fn run(args: QueryArgs, global: GlobalArgs) -> Result<()> {
         OutputFormat::Msg => {
             print_msg(entries, &response.columns, false);
         }
+        OutputFormat::Csv => {
+            print_csv(entries, &response.columns);
+            ui::print_stats(
+                global.quiet,
+                entries.len(),
+                response.stats.execution_time_ms,
+                response.stats.rows_read,
+            );
+        }
         OutputFormat::Text => {
             let highlighter = if args.no_highlight || !ui::human(global.quiet) {
                 None
@@ -500,6 +514,33 @@ fn print_table(entries: &[logchef_core::api::LogEntry], columns: &[logchef_core:
     }
 }
 
+/// Prints every column as CSV (RFC 4180), header first. Non-string values
+/// are written as JSON.
+fn print_csv(entries: &[logchef_core::api::LogEntry], columns: &[logchef_core::api::Column]) {
+    let header: Vec<String> = columns.iter().map(|c| csv_field(&c.name)).collect();
+    println!("{}", header.join(","));
+
+    for entry in entries {
+        let row: Vec<String> = columns
+            .iter()
+            .map(|c| match entry.get(&c.name) {
+                Some(serde_json::Value::String(s)) => csv_field(s),
+                Some(serde_json::Value::Null) | None => String::new(),
+                Some(v) => csv_field(&v.to_string()),
+            })
+            .collect();
+        println!("{}", row.join(","));
+    }
+}
+
+fn csv_field(value: &str) -> String {
+    if value.contains([',', '"', '\n', '\r']) {
+        format!("\"{}\"", value.replace('"', "\"\""))
+    } else {
+        value.to_string()
+    }
+}
+
 async fn prompt_team_interactive(client: &Client, cache: &mut Cache) -> Result<i64> {
     let teams = client.list_teams().await.context("Failed to list teams")?;
     if teams.is_empty() {
@@ -570,3 +611,16 @@ fn prompt_query_interactive() -> Result<String> {
         .context("Failed to read query")?;
     Ok(query)
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn csv_field_quotes_only_when_needed() {
+        assert_eq!(csv_field("plain"), "plain");
+        assert_eq!(csv_field("a,b"), "\"a,b\"");
+        assert_eq!(csv_field("say \"hi\""), "\"say \"\"hi\"\"\"");
+        assert_eq!(csv_field("two\nlines"), "\"two\nlines\"");
+    }
+}
@@ -1,17 +1,21 @@
 use anyhow::{Context, Result};
-use clap::Args;
+use clap::{Args, Subcommand};
 use inquire::Select;
 use logchef_core::Config;
-use logchef_core::api::Client;
+use logchef_core::api::{Client, ExportSourcesRequest, ImportSourcesRequest};
 use logchef_core::cache::{Cache, Identifier, parse_identifier};
 use serde::Serialize;
-use std::io::IsTerminal;
+use std::io::{IsTerminal, Read};
+use std::path::PathBuf;
 
 use crate::cli::GlobalArgs;
 use crate::session;
 
 #[derive(Args)]
 pub struct SourcesArgs {
+    #[command(subcommand)]
+    command: Option<SourcesCmd>,
+
     /// Team ID or name
     #[arg(long, short = 't')]
     team: Option<String>,
@@ -21,6 +25,49 @@ pub struct SourcesArgs {
     output: OutputFormat,
 }
 
+#[derive(Subcommand)]
+enum SourcesCmd {
+    /// Export every source definition (admin). Credentials are left out
+    /// unless a passphrase is given, which seals them into the document.
+    Export {
+        /// Document format
+        #[arg(long, default_value = "yaml")]
+        format: DocumentFormat,
+
+        /// Write the document to FILE instead of stdout
+        #[arg(long = "file", short = 'f')]
+        file: Option<PathBuf>,
+
+        /// Environment variable holding the passphrase that seals credentials
+        #[arg(long)]
+        passphrase_env: Option<String>,
+    },
+    /// Import source definitions from an exported document (admin). Sources
+    /// that already exist are left unchanged.
+    Import {
+        /// Exported YAML or JSON document ("-" reads stdin)
+        file: PathBuf,
+
+        /// Environment variable holding the export's passphrase
+        #[arg(long)]
+        passphrase_env: Option<String>,
+
+        /// Report what would be created without creating anything
+        #[arg(long)]
+        dry_run: bool,
+
+        /// Output format
+        #[arg(long, default_value = "text")]
+        output: OutputFormat,
+    },
+}
+
+#[derive(Clone, Debug, clap::ValueEnum)]
+enum DocumentFormat {
+    Yaml,
+    Json,
+}
+
 #[derive(Clone, Debug, clap::ValueEnum)]
 enum OutputFormat {
     Text,
@@ -46,6 +93,21 @@ pub async fn run(args: SourcesArgs, global: GlobalArgs) -> Result<()> {
     let s = session::authed(&config, &global)?;
     let (client, ctx) = (&s.client, &s.ctx);
 
+    match args.command {
+        Some(SourcesCmd::Export {
+            format,
+            file,
+            passphrase_env,
+        }) => return export(client, format, file, passphrase_env).await,
+        Some(SourcesCmd::Import {
+            file,
+            passphrase_env,
+            dry_run,
+            output,
+        }) => return import(client, file, passphrase_env, dry_run, output).await,
+        None => {}
+    }
+
     let mut cache = Cache::new(&ctx.server_url);
     let default_team = ctx.defaults.team_with_env();
 
@@ -148,6 +210,127 @@ pub async fn run(args: SourcesArgs, global: GlobalArgs) -> Result<()> {
     Ok(())
 }
 
+async fn export(
+    client: &Client,
+    format: DocumentFormat,
+    file: Option<PathBuf>,
+    passphrase_env: Option<String>,
+) -> Result<()> {
+    let request = ExportSourcesRequest {
+        format: match format {
+            DocumentFormat::Yaml => "yaml",
+            DocumentFormat::Json => "json",
+        }
+        .to_string(),
+        passphrase: read_passphrase(passphrase_env.as_deref())?,
+    };
+    let sealed = request.passphrase.is_some();
+    let document = client
+        .export_sources(&request)
+        .await
+        .context("Failed to export sources")?;
+
+    match file {
+        Some(path) => {
+            std::fs::write(&path, &document)
+                .with_context(|| format!("Failed to write {}", path.display()))?;
+            let note = if sealed {
+                "credentials sealed with the passphrase"
+            } else {
+                "credentials not included"
+            };
+            eprintln!("Exported sources to {} ({})", path.display(), note);
+        }
+        None => print!("{}", document),
+    }
+    Ok(())
+}
+
+async fn import(
+    client: &Client,
+    file: PathBuf,
+    passphrase_env: Option<String>,
+    dry_run: bool,
+    output: OutputFormat,
+) -> Result<()> {
+    let document = if file.as_os_str() == "-" {
+        let mut buf = String::new();
+        std::io::stdin()
+            .read_to_string(&mut buf)
+            .context("Failed to read document from stdin")?;
+        buf
+    } else {
+        std::fs::read_to_string(&file)
+            .with_context(|| format!("Failed to read {}", file.display()))?
+    };
+
+    let request = ImportSourcesRequest {
+        document,
+        passphrase: read_passphrase(passphrase_env.as_deref())?,
+        dry_run,
+    };
+    let result = client
+        .import_sources(&request)
+        .await
+        .context("Failed to import sources")?;
+
+    match output {
+        OutputFormat::Json => {
+            println!("{}", serde_json::to_string_pretty(&result)?);
+        }
+        OutputFormat::Jsonl => {
+            for row in &result.sources {
+                println!("{}", serde_json::to_string(row)?);
+            }
+        }
+        OutputFormat::Text | OutputFormat::Table => {
+            println!("{:<24} {:<14} {:<6} ERROR", "NAME", "STATUS", "ID");
+            println!("{}", "-".repeat(80));
+            for row in &result.sources {
+                let id = row
+                    .source_id
+                    .map(|id| id.to_string())
+                    .unwrap_or_else(|| "-".to_string());
+                println!(
+                    "{:<24} {:<14} {:<6} {}",
+                    truncate_str(&row.name, 24),
+                    row.status,
+                    id,
+                    row.error.as_deref().unwrap_or("")
+                );
+            }
+            let verb = if result.dry_run {
+                "would be created"
+            } else {
+                "created"
+            };
+            println!(
+                "\n{} {}, {} already exist, {} failed",
+                result.created, verb, result.exists, result.failed
+            );
+        }
+    }
+
+    if result.failed > 0 {
+        anyhow::bail!("{} source(s) failed to import", result.failed);
+    }
+    Ok(())
+}
+
+/// Reads a passphrase from the named environment variable, so it never
+/// appears in shell history or the process list.
+fn read_passphrase(var: Option<&str>) -> Result<Option<String>> {
+    let Some(var) = var else {
+        return Ok(None);
+    };
+    let value = std::env::var(var)
+        .with_context(|| format!("Environment variable {} is not set", var))?;
+    if value.is_empty() {
+        anyhow::bail!("Environment variable {} is empty", var);
+    }
+    Ok(Some(value))
+}
+
 async fn prompt_team_interactive(client: &Client, cache: &mut Cache) -> Result<i64> {
     let teams = client.list_teams().await.context("Failed to list teams")?;
     if teams.is_empty() {
@@ -154,6 +154,92 @@ impl Client {
         Ok(response.data)
     }
 
+    /// Exports every source definition as a YAML or JSON document. With a
+    /// passphrase, credentials are sealed into the document; without one
+    /// they are left out.
+    pub async fn export_sources(&self, request: &ExportSourcesRequest) -> Result<String> {
+        let url = format!("{}/api/v1/admin/sources/export", self.base_url);
+        debug!(url = %url, "POST request");
+
+        let response = self
+            .http
+            .post(&url)
+            .headers(self.headers())
+            .json(request)
+            .send()
+            .await?;
+
+        let status = response.status();
+        if !status.is_success() {
+            let status_code = status.as_u16();
+            let body = response.text().await.unwrap_or_default();
+
+            if let Ok(api_error) = serde_json::from_str::<ApiErrorResponse>(&body) {
+                return Err(Error::api_with_type(
+                    Some(status_code),
+                    api_error.message,
+                    api_error.error_type,
+                ));
+            }
+
+            return Err(Error::api(
+                Some(status_code),
+                format!("HTTP {}: {}", status_code, body),
+            ));
+        }
+
+        Ok(response.text().await?)
+    }
+
+    pub async fn import_sources(&self, request: &ImportSourcesRequest) -> Result<ImportSourcesResult> {
+        let response: ApiResponse<ImportSourcesResult> =
+            self.post("/api/v1/admin/sources/import", request).await?;
+        Ok(response.data)
+    }
+
+    /// Exports the alerts on a team's sources as a YAML or JSON document.
+    pub async fn export_alerts(&self, team_id: i64, format: &str) -> Result<String> {
+        let url = format!(
+            "{}/api/v1/teams/{}/alerts/export?format={}",
+            self.base_url, team_id, format
+        );
+        debug!(url = %url, "GET request");
+
+        let response = self.http.get(&url).headers(self.headers()).send().await?;
+
+        let status = response.status();
+        if !status.is_success() {
+            let status_code = status.as_u16();
+            let body = response.text().await.unwrap_or_default();
+
+            if let Ok(api_error) = serde_json::from_str::<ApiErrorResponse>(&body) {
+                return Err(Error::api_with_type(
+                    Some(status_code),
+                    api_error.message,
+                    api_error.error_type,
+                ));
+            }
+
+            return Err(Error::api(
+                Some(status_code),
+                format!("HTTP {}: {}", status_code, body),
+            ));
+        }
+
+        Ok(response.text().await?)
+    }
+
+    pub async fn import_alerts(
+        &self,
+        team_id: i64,
+        request: &ImportAlertsRequest,
+    ) -> Result<ImportAlertsResult> {
+        let response: ApiResponse<ImportAlertsResult> = self
+            .post(&format!("/api/v1/teams/{}/alerts/import", team_id), request)
+            .await?;
+        Ok(response.data)
+    }
+
     pub async fn get_schema(&self, team_id: i64, source_id: i64) -> Result<Vec<Column>> {
         let response: ApiResponse<Vec<Column>> = self
             .get(&format!(
@@ -190,6 +190,67 @@ pub struct ExportSqlRequest {
     pub query_timeout: Option<u32>,
 }
 
+#[derive(Debug, Serialize)]
+pub struct ExportSourcesRequest {
+    pub format: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub passphrase: Option<String>,
+}
+
+#[derive(Debug, Serialize)]
+pub struct ImportSourcesRequest {
+    pub document: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub passphrase: Option<String>,
+    pub dry_run: bool,
+}
+
+#[derive(Debug, Deserialize, Serialize)]
+pub struct ImportSourcesResult {
+    pub dry_run: bool,
+    pub created: u32,
+    pub exists: u32,
+    pub failed: u32,
+    pub sources: Vec<ImportedSource>,
+}
+
+#[derive(Debug, Deserialize, Serialize)]
+pub struct ImportedSource {
+    pub name: String,
+    pub status: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub source_id: Option<i64>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub error: Option<String>,
+}
+
+#[derive(Debug, Serialize)]
+pub struct ImportAlertsRequest {
+    pub document: String,
+    pub dry_run: bool,
+}
+
+#[derive(Debug, Deserialize, Serialize)]
+pub struct ImportAlertsResult {
+    pub dry_run: bool,
+    pub created: u32,
+    pub updated: u32,
+    pub unchanged: u32,
+    pub failed: u32,
+    pub alerts: Vec<ImportedAlert>,
+}
+
+#[derive(Debug, Deserialize, Serialize)]
+pub struct ImportedAlert {
+    pub name: String,
+    pub source: String,
+    pub status: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub alert_id: Option<i64>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub error: Option<String>,
+}
+
 #[derive(Debug, Deserialize)]
 pub struct ExportJobResponse {
     pub id: String,
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"os"
 
 	"github.com/mr-karan/logchef/internal/app"
@@ -19,6 +22,18 @@ func main() {
 	configPath := flag.String("config", "config.toml", "path to config file")
 	flag.Parse()
 
+	// "logchef admin ..." manages users, teams and sources without starting the server.
+	if flag.Arg(0) == "admin" {
+		if err := app.RunAdmin(context.Background(), *configPath, flag.Args()[1:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			if errors.Is(err, app.ErrAdminUsage) {
+				fmt.Fprint(os.Stderr, app.AdminUsage())
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize logger before config is loaded.
 	// It will be reconfigured with the correct level once the app loads its config.
 	log := logger.New(false)
@@ -191,7 +191,20 @@ Key areas of the docs:
           items: [
             { label: "Collections & Saved Queries", link: "/features/collections" },
             { label: "Dashboards", link: "/features/dashboards" },
+            { label: "Notebooks", link: "/features/notebooks" },
             { label: "Alerting", link: "/features/alerting" },
+            { label: "SLOs & Burn-Rate Alerts", link: "/features/slos" },
+            { label: "Rollups & Trends", link: "/features/rollups" },
+            { label: "Field Extraction Rules", link: "/features/extraction-rules" },
+            { label: "Log Patterns", link: "/features/log-patterns" },
+            { label: "Volume Anomalies", link: "/features/volume-anomalies" },
+            { label: "Compare Time Ranges", link: "/features/compare" },
+            { label: "Trace Correlation", link: "/features/trace-correlation" },
+            { label: "Share Links", link: "/features/share-links" },
+            { label: "Annotations", link: "/features/annotations" },
+            { label: "Log Bookmarks", link: "/features/log-bookmarks" },
+            { label: "Column Presets", link: "/features/column-presets" },
+            { label: "Query Snippets", link: "/features/query-snippets" },
             { label: "AI SQL Generation", link: "/features/ai-sql-generation" },
             { label: "User Management", link: "/core/user-management" },
             { label: "Service Tokens", link: "/features/service-tokens" },
@@ -206,8 +219,13 @@ Key areas of the docs:
             { label: "OpenTelemetry Collector", link: "/integration/otel-collector" },
             { label: "Kubernetes Logs", link: "/integration/kubernetes" },
             { label: "Docker Logs", link: "/integration/docker" },
+            { label: "HTTP Ingestion", link: "/integration/http-ingest" },
+            { label: "Syslog", link: "/integration/syslog" },
+            { label: "OTLP Receiver", link: "/integration/otlp" },
             { label: "CLI", link: "/integration/cli" },
             { label: "MCP Server", link: "/integration/mcp-server" },
+            { label: "SCIM Provisioning", link: "/integration/scim" },
+            { label: "Webhooks", link: "/integration/webhooks" },
             { label: "Schema Design", link: "/integration/schema-design" },
           ],
         },
@@ -240,6 +258,9 @@ Key areas of the docs:
           items: [
             { label: "Database & High Availability", link: "/operations/database-backends" },
             { label: "Metrics Reference", link: "/operations/metrics" },
+            { label: "Tracing", link: "/operations/tracing" },
+            { label: "Audit Log", link: "/operations/audit-log" },
+            { label: "Admin CLI", link: "/operations/admin-cli" },
             { label: "Contributing", link: "/contributing/setup" },
           ],
         },
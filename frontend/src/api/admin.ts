@@ -1,4 +1,5 @@
 import { apiClient } from "./apiUtils";
+import type { QueryHistoryRecord } from "./explore";
 
 // ---------------------------------------------------------------------------
 // Admin "Query Activity" (#58)
@@ -92,6 +93,82 @@ export interface QueryStatsResponse {
   volume_by_day: DailyQueryVolume[];
 }
 
+// ---------------------------------------------------------------------------
+// Admin "Query Usage"
+//
+// Usage and slow-query report built from recent query history: top users and
+// sources, slowest queries and the most filtered fields per source.
+//
+// Response shape mirrors GET /api/v1/admin/query-usage exactly (snake_case).
+// ---------------------------------------------------------------------------
+
+export interface QueryUsageUser {
+  user_id: number;
+  user_email: string;
+  queries: number;
+  failed_queries: number;
+  total_duration_ms: number;
+}
+
+export interface QueryUsageSource {
+  source_id: number;
+  // Empty string when the source row has been deleted.
+  source_name: string;
+  queries: number;
+  failed_queries: number;
+  total_duration_ms: number;
+  avg_duration_ms: number;
+  p95_duration_ms: number;
+  rows_returned: number;
+}
+
+export interface QueryFieldUsage {
+  source_id: number;
+  source_name: string;
+  field: string;
+  queries: number;
+}
+
+export interface QueryUsageReport {
+  since: string;
+  generated_at: string;
+  days: number;
+  total_queries: number;
+  failed_queries: number;
+  slow_query_threshold_ms: number;
+  slow_queries: number;
+  // Set when the window held more history than one report scans.
+  truncated: boolean;
+  top_users: QueryUsageUser[];
+  top_sources: QueryUsageSource[];
+  slowest_queries: QueryHistoryRecord[];
+  top_fields: QueryFieldUsage[];
+}
+
+// ---------------------------------------------------------------------------
+// Admin "Active Queries"
+//
+// Queries in flight across all users (previews, exports and live tails), read
+// from the server's query tracker. Names are empty when the user, team or
+// source has been deleted.
+//
+// Response shape mirrors GET /api/v1/admin/active-queries exactly (snake_case).
+// ---------------------------------------------------------------------------
+
+export interface ActiveQuery {
+  query_id: string;
+  class: "preview" | "export" | "tail";
+  user_id: number;
+  user_email: string;
+  team_id?: number;
+  team_name?: string;
+  source_id: number;
+  source_name: string;
+  sql: string;
+  started_at: string;
+  elapsed_ms: number;
+}
+
 export const adminApi = {
   // Fetch recent query activity across all users. `limit` controls the
   // recent-feed length; it is clamped server-side (default 100, max 500).
@@ -106,4 +183,20 @@ export const adminApi = {
     const search = typeof days === "number" ? `?days=${days}` : "";
     return apiClient.get<QueryStatsResponse>(`/admin/query-stats${search}`);
   },
+
+  // Fetch the usage and slow-query report over the last `days` (default
+  // query_analytics.window_days, clamped 1..365 server-side).
+  getQueryUsage: (days?: number) => {
+    const search = typeof days === "number" ? `?days=${days}` : "";
+    return apiClient.get<QueryUsageReport>(`/admin/query-usage${search}`);
+  },
+
+  // List queries running right now, oldest first.
+  listActiveQueries: () => apiClient.get<ActiveQuery[]>("/admin/active-queries"),
+
+  // Cancel any user's query; ClickHouse queries are also killed server-side.
+  killActiveQuery: (queryId: string) =>
+    apiClient.post<{ message: string; query_id: string }>(
+      `/admin/active-queries/${encodeURIComponent(queryId)}/kill`,
+    ),
 };
@@ -1,8 +1,29 @@
 import { apiClient } from "./apiUtils";
+import { MAX_LIST_LIMIT } from "./types";
 import type { AlertEditorMode, QueryLanguage } from "@/lib/queryMetadata";
 
 export type AlertThresholdOperator = "gt" | "gte" | "lt" | "lte" | "eq" | "neq";
 export type AlertSeverity = "info" | "warning" | "critical";
+export type AlertChannelType = "email" | "webhook" | "slack";
+
+export interface AlertChannel {
+  type: AlertChannelType;
+  url?: string;
+  emails?: string[];
+  template?: string;
+  headers?: Record<string, string>;
+}
+
+export interface AlertChannelDelivery {
+  channel: string;
+  type: AlertChannelType;
+  /** queued: held by notification grouping and sent with the destination's next batch. */
+  status: "delivered" | "failed" | "queued";
+  attempts: number;
+  error?: string;
+  at: string;
+}
+
 export interface Alert {
   id: number;
   source_id: number;
@@ -16,17 +37,27 @@ export interface Alert {
   threshold_operator: AlertThresholdOperator;
   threshold_value: number;
   frequency_seconds: number;
+  /** How long the condition must hold before the alert fires; 0 fires on the first breach. */
+  for_seconds: number;
   severity: AlertSeverity;
   labels?: Record<string, string>;
   annotations?: Record<string, string>;
   generator_url?: string;
   recipient_user_ids: number[];
   webhook_urls: string[];
+  channels?: AlertChannel[];
+  /** Set for burn-rate alerts: the value is this SLO's burn rate over lookback_seconds. */
+  slo_id?: number | null;
   is_active: boolean;
-  last_state: "firing" | "resolved";
+  last_state: "firing" | "pending" | "resolved";
   last_evaluated_at?: string | null;
   last_triggered_at?: string | null;
+  /** Start of the current run of breaches while the alert is pending. */
+  pending_since?: string | null;
+  breach_count: number;
   created_by?: number | null;
+  /** Declared in provisioning config; the API rejects edits and deletes. */
+  managed?: boolean;
   created_at: string;
   updated_at: string;
 }
@@ -43,6 +74,25 @@ export interface AlertHistoryEntry {
   created_at: string;
 }
 
+export interface AlertStateSpan {
+  state: Alert["last_state"];
+  start: string;
+  end: string;
+  /** Value that triggered a firing span. */
+  value?: number | null;
+}
+
+export interface AlertStateResponse {
+  alert: Alert;
+  state: Alert["last_state"];
+  /** Value of the most recent history entry that recorded one. */
+  last_value?: number | null;
+  from: string;
+  to: string;
+  /** Consecutive state spans covering from..to, oldest first. */
+  timeline: AlertStateSpan[];
+}
+
 export interface CreateAlertRequest {
   source_id: number;
   name: string;
@@ -55,12 +105,15 @@ export interface CreateAlertRequest {
   threshold_operator: AlertThresholdOperator;
   threshold_value: number;
   frequency_seconds: number;
+  for_seconds?: number;
   severity: AlertSeverity;
   labels?: Record<string, string>;
   annotations?: Record<string, string>;
   generator_url?: string;
   recipient_user_ids?: number[];
   webhook_urls?: string[];
+  channels?: AlertChannel[];
+  slo_id?: number;
   is_active: boolean;
 }
 
@@ -75,12 +128,16 @@ export interface UpdateAlertRequest {
   threshold_operator?: AlertThresholdOperator;
   threshold_value?: number;
   frequency_seconds?: number;
+  for_seconds?: number;
   severity?: AlertSeverity;
   labels?: Record<string, string>;
   annotations?: Record<string, string>;
   generator_url?: string;
   recipient_user_ids?: number[];
   webhook_urls?: string[];
+  channels?: AlertChannel[];
+  /** 0 unlinks the alert from its SLO. */
+  slo_id?: number;
   is_active?: boolean;
 }
 
@@ -107,12 +164,31 @@ export interface TestAlertQueryResponse {
   warnings: string[];
 }
 
+export type AlertDocumentFormat = "yaml" | "json";
+
+export interface ImportedAlert {
+  name: string;
+  source: string;
+  status: "created" | "updated" | "unchanged" | "would_create" | "would_update" | "failed";
+  alert_id?: number;
+  error?: string;
+}
+
+export interface ImportAlertsResult {
+  dry_run: boolean;
+  created: number;
+  updated: number;
+  unchanged: number;
+  failed: number;
+  alerts: ImportedAlert[];
+}
+
 export const alertsApi = {
   list: (sourceId?: number) => {
     const url =
       sourceId !== undefined && sourceId !== null
-        ? `/alerts?source_id=${sourceId}`
-        : "/alerts";
+        ? `/alerts?source_id=${sourceId}&limit=${MAX_LIST_LIMIT}`
+        : `/alerts?limit=${MAX_LIST_LIMIT}`;
     return apiClient.get<Alert[]>(url);
   },
   get: (alertId: number) =>
@@ -129,6 +205,28 @@ export const alertsApi = {
     const search = limit ? `?limit=${encodeURIComponent(limit)}` : "";
     return apiClient.get<AlertHistoryEntry[]>(`/alerts/${alertId}/history${search}`);
   },
+  state: (alertId: number, window?: string) => {
+    const search = window ? `?window=${encodeURIComponent(window)}` : "";
+    return apiClient.get<AlertStateResponse>(`/alerts/${alertId}/state${search}`);
+  },
   testQuery: (payload: TestAlertQueryRequest) =>
     apiClient.post<TestAlertQueryResponse>("/alerts/test", payload),
+  exportTeamAlerts: async (teamId: number, format: AlertDocumentFormat = "yaml"): Promise<Blob> => {
+    const response = await fetch(`/api/v1/teams/${teamId}/alerts/export?format=${format}`, {
+      credentials: "same-origin",
+    });
+    if (!response.ok) {
+      let message = `Alert export failed (${response.status})`;
+      try {
+        const body = await response.json();
+        if (body?.message) message = body.message;
+      } catch {
+        // non-JSON error body; keep the default message
+      }
+      throw new Error(message);
+    }
+    return response.blob();
+  },
+  importTeamAlerts: (teamId: number, document: string, dryRun = false) =>
+    apiClient.post<ImportAlertsResult>(`/teams/${teamId}/alerts/import`, { document, dry_run: dryRun }),
 };
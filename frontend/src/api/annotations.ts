@@ -0,0 +1,49 @@
+import { apiClient } from "./apiUtils";
+
+/** A timeline marker (mirrors pkg/models Annotation). */
+export interface Annotation {
+  id: number;
+  team_id: number;
+  /** Absent for team-wide annotations shown on every source. */
+  source_id?: number;
+  text: string;
+  tags: string[];
+  starts_at: string;
+  /** Absent for point-in-time markers. */
+  ends_at?: string;
+  created_by?: number | null;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface AnnotationRequest {
+  source_id?: number;
+  text: string;
+  tags?: string[];
+  /** Defaults to now on create and to the stored start on update. */
+  starts_at?: string;
+  ends_at?: string;
+}
+
+export interface AnnotationFilter {
+  source_id?: number;
+  /** RFC3339; start_time and end_time must be sent together. */
+  start_time?: string;
+  end_time?: string;
+}
+
+export const annotationsApi = {
+  list: (teamId: number, filter: AnnotationFilter = {}) => {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(filter)) {
+      if (value !== undefined && value !== null) params.set(key, String(value));
+    }
+    const search = params.toString();
+    return apiClient.get<Annotation[]>(`/teams/${teamId}/annotations${search ? `?${search}` : ""}`);
+  },
+  get: (teamId: number, id: number) => apiClient.get<Annotation>(`/teams/${teamId}/annotations/${id}`),
+  create: (teamId: number, req: AnnotationRequest) => apiClient.post<Annotation>(`/teams/${teamId}/annotations`, req),
+  update: (teamId: number, id: number, req: AnnotationRequest) =>
+    apiClient.put<Annotation>(`/teams/${teamId}/annotations/${id}`, req),
+  remove: (teamId: number, id: number) => apiClient.delete<{ message: string }>(`/teams/${teamId}/annotations/${id}`),
+};
@@ -0,0 +1,46 @@
+import { apiClient } from "./apiUtils";
+
+/** How the log table renders a column; "" leaves it to the column type. */
+export type ColumnFormat = "" | "text" | "json" | "timestamp" | "relative_time" | "number" | "bytes";
+
+export interface ColumnPresetColumn {
+  name: string;
+  /** Width in pixels; omitted or 0 sizes the column automatically. */
+  width?: number;
+  format?: ColumnFormat;
+  wrap?: boolean;
+}
+
+/** A saved column layout for a source (mirrors pkg/models ColumnPreset). */
+export interface ColumnPreset {
+  source_id: number;
+  /** Set on a user's own preset. */
+  user_id?: number;
+  /** Set on a team default. */
+  team_id?: number;
+  columns: ColumnPresetColumn[];
+  updated_by?: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface ColumnPresets {
+  personal: ColumnPreset | null;
+  team: ColumnPreset | null;
+  /** The preset that applies: personal if set, otherwise the team default. */
+  effective: ColumnPreset | null;
+}
+
+const base = (teamId: number, sourceId: number) => `/teams/${teamId}/sources/${sourceId}/column-presets`;
+
+export const columnPresetsApi = {
+  get: (teamId: number, sourceId: number) => apiClient.get<ColumnPresets>(base(teamId, sourceId)),
+  savePersonal: (teamId: number, sourceId: number, columns: ColumnPresetColumn[]) =>
+    apiClient.put<ColumnPreset>(`${base(teamId, sourceId)}/personal`, { columns }),
+  resetPersonal: (teamId: number, sourceId: number) =>
+    apiClient.delete<{ message: string }>(`${base(teamId, sourceId)}/personal`),
+  saveTeam: (teamId: number, sourceId: number, columns: ColumnPresetColumn[]) =>
+    apiClient.put<ColumnPreset>(`${base(teamId, sourceId)}/team`, { columns }),
+  removeTeam: (teamId: number, sourceId: number) =>
+    apiClient.delete<{ message: string }>(`${base(teamId, sourceId)}/team`),
+};
@@ -1,5 +1,6 @@
 import { apiClient } from "./apiUtils";
 import { createSSEParser } from "@/lib/sse";
+import type { Annotation } from "./annotations";
 
 // Keep these for the UI filter builder
 export interface FilterCondition {
@@ -46,8 +47,10 @@ export interface ColumnInfo {
 // Template variable for SQL substitution
 export interface TemplateVariable {
   name: string;
-  type: 'text' | 'number' | 'date' | 'string';
+  type: 'text' | 'number' | 'date' | 'string' | 'enum';
   value: string | number | string[];
+  allowed_values?: string[]; // Values an enum variable accepts
+  source_query_id?: number; // Saved query listing an enum variable's values (loaded server-side)
 }
 
 // Simplified query parameters - intended for API communication
@@ -56,22 +59,43 @@ export interface QueryParams {
   limit?: number;
   window?: string;
   group_by?: string;
+  severity_breakdown?: boolean; // Histogram: group by the severity field when group_by is unset
   timezone?: string; // User's timezone identifier (e.g., 'America/New_York', 'UTC')
   start_time?: string; // ISO formatted start time
   end_time?: string;   // ISO formatted end time
   query_timeout?: number; // Query timeout in seconds
   variables?: TemplateVariable[]; // Template variables for SQL substitution
+  derived_columns?: DerivedColumn[]; // Extra computed result columns (ClickHouse only)
+  include_count?: boolean; // Also count the rows the query matches without its limit
+  sample?: number; // Fraction of rows (0-1 exclusive) to sample (ClickHouse only)
+  dedupe?: { fields?: string[] }; // Collapse consecutive identical rows into one with _repeat_count
+  distributed?: { shard?: number; prefer_localhost_replica?: boolean }; // Route a Distributed-table query (ClickHouse only)
+}
+
+// Client-defined result column, e.g. { name: 'duration_s', expression: 'duration_ms / 1000' }
+export interface DerivedColumn {
+  name: string;
+  expression: string;
 }
 
 export interface QueryStats {
   execution_time_ms: number;
+  // What ClickHouse scanned; rows_read falls back to the returned row count
+  // when the server reports no progress.
   rows_read: number;
   bytes_read: number;
+  peak_memory_bytes?: number;
   rows_returned?: number;
   bytes_returned?: number;
   limit_applied?: number;
   truncated?: boolean;
   truncated_reason?: string;
+  sampled?: boolean; // Rows are a sample, not the full result
+  sample_ratio?: number;
+  sample_method?: 'sample' | 'random'; // SAMPLE clause or random-bucket filter
+  deduped_rows?: number; // Rows a deduped query folded into the rows before them
+  timezone?: string; // Zone the returned DateTime values are in (the request's timezone)
+  timezone_offset?: string; // Its UTC offset when the query ran, e.g. "+05:30"
 }
 
 export interface QueryWarning {
@@ -89,6 +113,7 @@ export interface QuerySuccessResponse {
   columns: ColumnInfo[];
   query_id?: string; // Add query_id for cancellation
   warnings?: QueryWarning[];
+  total_count?: number; // Rows matched without the limit; set when include_count was requested and the count finished in time
 }
 
 export interface QueryErrorResponse {
@@ -112,6 +137,10 @@ export interface HistogramResponse {
   data: HistogramDataPoint[];
   /** Non-fatal notice (e.g., series were capped to a top-N set). */
   notice?: string;
+  /** Field the buckets were grouped by, including a severity breakdown. */
+  group_by?: string;
+  /** Team annotations (deploys, incidents) overlapping the requested range. */
+  annotations?: Annotation[];
 }
 
 // Log context types (surrounding logs around a target timestamp)
@@ -123,6 +152,10 @@ export interface LogContextRequest {
   before_offset?: number;
   after_offset?: number;
   exclude_boundary?: boolean;
+  // VictoriaLogs `_stream` of the target row; scopes context to that stream.
+  stream?: string;
+  // Field values the context must share with the target row, e.g. { pod: 'api-7f9c' }.
+  scope?: Record<string, string>;
 }
 
 export interface LogContextResponse {
@@ -177,6 +210,8 @@ export interface QuerySharePayload {
     isRequired?: boolean;
     options?: Array<{ value: string; label?: string }>;
   }>;
+  // Visible table columns, in order; omitted to show the default layout.
+  columns?: string[];
 }
 
 export interface QueryShareResponse {
@@ -185,7 +220,7 @@ export interface QueryShareResponse {
   team_id: number;
   source_id: number;
   payload: QuerySharePayload;
-  expires_at: string;
+  expires_at?: string | null; // absent for a permanent link
   created_at: string;
   created_by: number;
 }
@@ -195,17 +230,45 @@ export interface QueryShareResponse {
 // across all teams/sources, newest-first. See issue #58.
 export type QueryHistoryLanguage = "logchefql" | "clickhouse-sql" | "logsql";
 
+export type QueryHistoryStatus = "success" | "error";
+
 export interface QueryHistoryRecord {
   id: number;
+  user_id: number;
   team_id: number;
   source_id: number;
   query_text: string;
   query_language: QueryHistoryLanguage;
   duration_ms: number;
   row_count: number;
+  status: QueryHistoryStatus;
+  error_message?: string;
+  user_email?: string;
+  source_name?: string;
   created_at: string;
 }
 
+export interface QueryHistoryFilters {
+  limit?: number;
+  offset?: number;
+  search?: string;
+  source_id?: number;
+  status?: QueryHistoryStatus;
+  // Team view only.
+  user_id?: number;
+}
+
+function queryHistorySearch(filters: QueryHistoryFilters): string {
+  const params = new URLSearchParams();
+  for (const [key, value] of Object.entries(filters)) {
+    if (value !== undefined && value !== "") {
+      params.set(key, String(value));
+    }
+  }
+  const search = params.toString();
+  return search ? `?${search}` : "";
+}
+
 export const exploreApi = {
   getLogs: (sourceId: number, params: QueryParams, teamId: number, signal?: AbortSignal) => {
     if (!teamId) {
@@ -316,7 +379,12 @@ export const exploreApi = {
     );
   },
 
-  createQueryShare: (sourceId: number, payload: QuerySharePayload, teamId: number) => {
+  createQueryShare: (
+    sourceId: number,
+    payload: QuerySharePayload,
+    teamId: number,
+    options?: { expiresInSeconds?: number; permanent?: boolean }
+  ) => {
     if (!teamId) {
       throw new Error("Team ID is required for sharing queries");
     }
@@ -325,7 +393,11 @@ export const exploreApi = {
     }
     return apiClient.post<QueryShareResponse>(
       `/teams/${teamId}/sources/${sourceId}/query-shares`,
-      { payload }
+      {
+        payload,
+        expires_in_seconds: options?.expiresInSeconds,
+        permanent: options?.permanent,
+      }
     );
   },
 
@@ -338,9 +410,13 @@ export const exploreApi = {
 
   // Fetch the caller's recent query history (newest-first). limit is clamped
   // server-side (default 50, max 200).
-  getMyQueryHistory: (limit?: number) => {
-    const search = typeof limit === "number" ? `?limit=${limit}` : "";
-    return apiClient.get<QueryHistoryRecord[]>(`/me/query-history${search}`);
+  getMyQueryHistory: (limit?: number, filters: QueryHistoryFilters = {}) => {
+    return apiClient.get<QueryHistoryRecord[]>(`/me/query-history${queryHistorySearch({ ...filters, limit })}`);
+  },
+
+  // Team-wide query history, for team admins and global admins.
+  getTeamQueryHistory: (teamId: number, filters: QueryHistoryFilters = {}) => {
+    return apiClient.get<QueryHistoryRecord[]>(`/teams/${teamId}/query-history${queryHistorySearch(filters)}`);
   }
 };
 
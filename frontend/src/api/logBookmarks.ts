@@ -0,0 +1,44 @@
+import { apiClient } from "./apiUtils";
+
+/** A pinned log row (mirrors pkg/models LogBookmark). */
+export interface LogBookmark {
+  id: number;
+  team_id: number;
+  source_id: number;
+  /** The user who pinned the row. */
+  user_id: number;
+  /** Shared bookmarks are visible to the whole team; others only to user_id. */
+  shared: boolean;
+  /** The row's own timestamp. */
+  log_timestamp: string;
+  fingerprint: string;
+  /** Snapshot of the row as it was pinned. */
+  payload: Record<string, unknown>;
+  note: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CreateLogBookmarkRequest {
+  source_id: number;
+  timestamp: string;
+  payload: Record<string, unknown>;
+  note?: string;
+  shared?: boolean;
+}
+
+export interface UpdateLogBookmarkRequest {
+  note: string;
+  shared: boolean;
+}
+
+export const logBookmarksApi = {
+  list: (teamId: number, sourceId?: number) =>
+    apiClient.get<LogBookmark[]>(`/teams/${teamId}/bookmarks${sourceId ? `?source_id=${sourceId}` : ""}`),
+  get: (teamId: number, id: number) => apiClient.get<LogBookmark>(`/teams/${teamId}/bookmarks/${id}`),
+  create: (teamId: number, req: CreateLogBookmarkRequest) =>
+    apiClient.post<LogBookmark>(`/teams/${teamId}/bookmarks`, req),
+  update: (teamId: number, id: number, req: UpdateLogBookmarkRequest) =>
+    apiClient.put<LogBookmark>(`/teams/${teamId}/bookmarks/${id}`, req),
+  remove: (teamId: number, id: number) => apiClient.delete<{ message: string }>(`/teams/${teamId}/bookmarks/${id}`),
+};
@@ -53,6 +53,53 @@ export interface ValidateResponse {
   error?: ParseError;
 }
 
+export type LintWarningCode =
+  | 'UNKNOWN_FIELD'
+  | 'REGEX_ON_UNINDEXED_COLUMN'
+  | 'MISSING_TIME_BOUND'
+  | 'SELECT_STAR_ON_WIDE_TABLE'
+  | 'LIMIT_TOO_LARGE';
+
+export interface LintWarning {
+  code: LintWarningCode;
+  message: string;
+  field?: string;
+  position?: {
+    line: number;
+    column: number;
+  };
+}
+
+export interface LintRequest {
+  query: string;
+  query_language?: QueryLanguage;  // Defaults to "logchefql"
+  limit?: number;
+}
+
+export interface LintResponse {
+  valid: boolean;
+  error?: ParseError;
+  warnings: LintWarning[];
+}
+
+export interface FormatResponse {
+  valid: boolean;
+  error?: ParseError;
+  formatted_query?: string;  // Pretty-printed SQL
+  convertible: boolean;
+  logchefql: string;         // The LogchefQL equivalent, when convertible
+  reason?: string;           // Why a valid query didn't convert
+}
+
+// A template variable as the query declares it, e.g. {{limit:number:default=100}}
+export interface VariableDeclaration {
+  name: string;
+  type: 'string' | 'text' | 'number' | 'date' | 'enum';
+  default?: string; // Inline default; absent when the variable requires a value
+  options?: string[]; // Inline choices of an enum
+  source_query_id?: number; // Saved query listing an enum's choices
+}
+
 export interface TemplateVariable {
   name: string;
   type: 'text' | 'number' | 'date' | 'string';
@@ -67,6 +114,8 @@ export interface QueryRequest {
   limit?: number;
   query_timeout?: number;
   variables?: TemplateVariable[];  // Template variables for backend substitution
+  include_count?: boolean;  // Also count the rows the query matches without its limit
+  sample?: number;  // Fraction of rows (0-1 exclusive) to sample (ClickHouse only)
 }
 
 export interface QueryResponse {
@@ -76,11 +125,48 @@ export interface QueryResponse {
     execution_time_ms: number;
     rows_read: number;
     bytes_read: number;
+    peak_memory_bytes?: number;
   };
   query_id?: string;
   generated_sql?: string;  // Deprecated compatibility field for generated_query
   generated_query?: string;
   generated_query_language?: QueryLanguage;
+  total_count?: number;  // Set when include_count was requested and the count finished in time
+}
+
+export interface FederatedQueryRequest extends Omit<QueryRequest, 'variables'> {
+  source_ids: number[];  // 2-10 sources of the same type linked to the team
+}
+
+export interface FederatedSourceResult {
+  source_id: number;
+  source_name: string;
+  rows: number;
+  stats: QueryResponse['stats'];
+  error?: string;
+}
+
+// Rows carry _source_id and _source naming the source they came from.
+export interface FederatedQueryResponse {
+  logs: Record<string, any>[];
+  columns: { name: string; type: string }[];
+  stats: QueryResponse['stats'];
+  sources: FederatedSourceResult[];
+  query_id?: string;
+  warnings?: { code: string; message: string }[];
+}
+
+// A link to a trace in one source's tracing backend.
+export interface TraceLink {
+  source_id: number;
+  source_name: string;
+  backend_name?: string;
+  url: string;
+}
+
+export interface TraceLogsResponse extends FederatedQueryResponse {
+  trace_id: string;
+  links: TraceLink[];
 }
 
 /**
@@ -110,6 +196,35 @@ export const logchefqlApi = {
       { query }
     ),
 
+  /**
+   * Lint a LogchefQL or ClickHouse SQL query against the source's schema
+   * Returns parse errors plus advisory warnings; never runs the query
+   */
+  lint: (teamId: number, sourceId: number, request: LintRequest) =>
+    apiClient.post<LintResponse>(
+      `/teams/${teamId}/sources/${sourceId}/logs/lint`,
+      request
+    ),
+
+  /**
+   * Pretty-print a ClickHouse SQL query (or bare WHERE filter)
+   * Also converts it back to LogchefQL when it is a simple filter, for switching editor modes
+   */
+  format: (teamId: number, sourceId: number, query: string) =>
+    apiClient.post<FormatResponse>(
+      `/teams/${teamId}/sources/${sourceId}/logs/format`,
+      { query }
+    ),
+
+  /**
+   * List the template variables a SQL query declares, with their types and inline defaults
+   */
+  variables: (teamId: number, sourceId: number, query: string) =>
+    apiClient.post<{ variables: VariableDeclaration[] }>(
+      `/teams/${teamId}/sources/${sourceId}/logs/variables`,
+      { query }
+    ),
+
   /**
    * Execute a LogchefQL query
    * The backend handles translation and execution in one step
@@ -120,6 +235,38 @@ export const logchefqlApi = {
       params,
       options
     ),
+
+  /**
+   * Execute one LogchefQL query across several sources of the team
+   * Rows are merged newest first and tagged with their source
+   */
+  federatedQuery: (teamId: number, params: FederatedQueryRequest, options?: { signal?: AbortSignal; timeout?: number }) =>
+    apiClient.post<FederatedQueryResponse>(
+      `/teams/${teamId}/federated/logchefql/query`,
+      params,
+      options
+    ),
+
+  /**
+   * Fetch one trace's logs from every team source with trace correlation
+   * Without a time range the last 24 hours are searched
+   */
+  traceLogs: (
+    teamId: number,
+    traceId: string,
+    params: { start_time?: string; end_time?: string; timezone?: string; limit?: number } = {},
+    options?: { signal?: AbortSignal }
+  ) => {
+    const query = new URLSearchParams();
+    if (params.start_time) query.set('start_time', params.start_time);
+    if (params.end_time) query.set('end_time', params.end_time);
+    if (params.timezone) query.set('timezone', params.timezone);
+    if (params.limit) query.set('limit', String(params.limit));
+    return apiClient.get<TraceLogsResponse>(
+      `/teams/${teamId}/traces/${encodeURIComponent(traceId)}/logs?${query.toString()}`,
+      options
+    );
+  },
 };
 
 /**
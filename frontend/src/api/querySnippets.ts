@@ -0,0 +1,31 @@
+import { apiClient } from "./apiUtils";
+
+/** A reusable LogchefQL filter in a team's library (mirrors pkg/models QuerySnippet). */
+export interface QuerySnippet {
+  id: number;
+  team_id: number;
+  name: string;
+  description: string;
+  /** LogchefQL, inserted into the editor as-is. */
+  query: string;
+  created_by?: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface QuerySnippetRequest {
+  name: string;
+  description?: string;
+  query: string;
+}
+
+export const querySnippetsApi = {
+  /** Lists the team's snippets by name; `search` keeps names containing it. */
+  list: (teamId: number, search?: string) =>
+    apiClient.get<QuerySnippet[]>(`/teams/${teamId}/snippets${search ? `?q=${encodeURIComponent(search)}` : ""}`),
+  get: (teamId: number, id: number) => apiClient.get<QuerySnippet>(`/teams/${teamId}/snippets/${id}`),
+  create: (teamId: number, req: QuerySnippetRequest) => apiClient.post<QuerySnippet>(`/teams/${teamId}/snippets`, req),
+  update: (teamId: number, id: number, req: QuerySnippetRequest) =>
+    apiClient.put<QuerySnippet>(`/teams/${teamId}/snippets/${id}`, req),
+  remove: (teamId: number, id: number) => apiClient.delete<{ message: string }>(`/teams/${teamId}/snippets/${id}`),
+};
@@ -109,5 +109,9 @@ export const savedQueriesApi = {
     return apiClient.get<ResolvedSavedQuery>(`/saved-queries/${queryId}/resolve${suffix}`);
   },
 
+  // Values of the query's first column, for enum variables using it as their source query.
+  choices: (queryId: number | string) =>
+    apiClient.get<{ choices: string[] }>(`/saved-queries/${queryId}/choices`),
+
   getUserTeams: () => apiClient.get<Team[]>("/me/teams"),
 };
@@ -0,0 +1,65 @@
+import { apiClient } from "./apiUtils";
+
+/** Weekly maintenance window (mirrors pkg/models SilenceRecurrence). */
+export interface SilenceRecurrence {
+  /** 0 (Sunday) to 6 (Saturday); empty opens the window every day. */
+  weekdays?: number[];
+  /** Local opening time, "HH:MM". */
+  start_time: string;
+  duration_minutes: number;
+  /** IANA zone; defaults to UTC. */
+  timezone?: string;
+}
+
+/** Exactly one of alert_id, source_id and team_id is set. */
+export interface AlertSilence {
+  id: number;
+  alert_id?: number;
+  source_id?: number;
+  team_id?: number;
+  reason: string;
+  starts_at: string;
+  /** Absent only for open-ended recurring silences. */
+  ends_at?: string;
+  recurrence?: SilenceRecurrence;
+  created_by?: number | null;
+  created_at: string;
+  updated_at: string;
+  /** Whether the silence applies right now. */
+  active: boolean;
+}
+
+export interface CreateAlertSilenceRequest {
+  alert_id?: number;
+  source_id?: number;
+  team_id?: number;
+  reason?: string;
+  starts_at?: string;
+  ends_at?: string;
+  duration_seconds?: number;
+  recurrence?: SilenceRecurrence;
+}
+
+export type UpdateAlertSilenceRequest = Omit<CreateAlertSilenceRequest, "alert_id" | "source_id" | "team_id">;
+
+export interface AlertSilenceFilter {
+  alert_id?: number;
+  source_id?: number;
+  team_id?: number;
+  active?: boolean;
+}
+
+export const silencesApi = {
+  list: (filter: AlertSilenceFilter = {}) => {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(filter)) {
+      if (value !== undefined && value !== null) params.set(key, String(value));
+    }
+    const search = params.toString();
+    return apiClient.get<AlertSilence[]>(`/silences${search ? `?${search}` : ""}`);
+  },
+  get: (id: number) => apiClient.get<AlertSilence>(`/silences/${id}`),
+  create: (req: CreateAlertSilenceRequest) => apiClient.post<AlertSilence>("/silences", req),
+  update: (id: number, req: UpdateAlertSilenceRequest) => apiClient.put<AlertSilence>(`/silences/${id}`, req),
+  remove: (id: number) => apiClient.delete<{ message: string }>(`/silences/${id}`),
+};
@@ -0,0 +1,62 @@
+import { apiClient } from "./apiUtils";
+import type { QueryLanguage } from "@/lib/queryMetadata";
+
+/** One evaluation of an SLO over its window (mirrors pkg/models SLOEvaluation). */
+export interface SLOEvaluation {
+  id: number;
+  slo_id: number;
+  good: number;
+  total: number;
+  /** good / total; null when the window held no logs or the evaluation failed. */
+  compliance: number | null;
+  /** 1 spends exactly the error budget over the window. */
+  burn_rate: number | null;
+  /** Fraction of the error budget left; negative once the objective is missed. */
+  error_budget_remaining: number | null;
+  error?: string;
+  evaluated_at: string;
+}
+
+export interface SLO {
+  id: number;
+  team_id: number;
+  source_id: number;
+  name: string;
+  description?: string;
+  query_language: QueryLanguage;
+  good_query: string;
+  total_query: string;
+  /** Objective as a fraction, e.g. 0.999. */
+  target: number;
+  window_seconds: number;
+  created_by?: number | null;
+  created_at: string;
+  updated_at: string;
+  /** Latest evaluation; absent until the SLO is first evaluated. */
+  status?: SLOEvaluation;
+  can_edit?: boolean;
+}
+
+export interface CreateSLORequest {
+  source_id: number;
+  name: string;
+  description?: string;
+  query_language: QueryLanguage;
+  good_query: string;
+  total_query: string;
+  target: number;
+  window_seconds: number;
+}
+
+export type UpdateSLORequest = Omit<CreateSLORequest, "source_id">;
+
+export const slosApi = {
+  list: (teamId: number) => apiClient.get<SLO[]>(`/teams/${teamId}/slos`),
+  get: (teamId: number, id: number) => apiClient.get<SLO>(`/teams/${teamId}/slos/${id}`),
+  create: (teamId: number, req: CreateSLORequest) => apiClient.post<SLO>(`/teams/${teamId}/slos`, req),
+  update: (teamId: number, id: number, req: UpdateSLORequest) =>
+    apiClient.put<SLO>(`/teams/${teamId}/slos/${id}`, req),
+  remove: (teamId: number, id: number) => apiClient.delete<{ message: string }>(`/teams/${teamId}/slos/${id}`),
+  history: (teamId: number, id: number, limit?: number) =>
+    apiClient.get<SLOEvaluation[]>(`/teams/${teamId}/slos/${id}/history${limit ? `?limit=${limit}` : ""}`),
+};
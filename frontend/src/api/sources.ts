@@ -1,5 +1,5 @@
 import { apiClient } from "./apiUtils";
-import type { Team } from "./types";
+import { MAX_LIST_LIMIT, type Team } from "./types";
 import type { QueryLanguage } from "@/lib/queryMetadata";
 
 // Optional per-source ClickHouse query settings applied to every query run
@@ -12,18 +12,37 @@ export interface ClickHouseQuerySettings {
   max_result_bytes?: number;
   max_rows_to_read?: number;
   max_bytes_to_read?: number;
+  max_threads?: number;
+  max_memory_usage?: number;
   readonly?: number;
   result_overflow_mode?: string;
 }
 
+// Optional per-source connection pool limits. Unset or 0 keeps the server
+// defaults; max_concurrent_queries of 0 means unlimited.
+export interface ClickHouseConnectionPool {
+  max_open_conns?: number;
+  max_idle_conns?: number;
+  dial_timeout_seconds?: number;
+  max_concurrent_queries?: number;
+}
+
 export interface ClickHouseConnectionInfo {
   host: string;
   username?: string;
   password?: string;
   database: string;
   table_name: string;
+  protocol?: "native" | "http";
   tls_enable?: boolean;
+  // PEM contents. The client key is write-only: responses carry has_tls_client_key.
+  tls_ca_cert?: string;
+  tls_client_cert?: string;
+  tls_client_key?: string;
+  has_tls_client_key?: boolean;
+  tls_skip_verify?: boolean;
   settings?: ClickHouseQuerySettings;
+  pool?: ClickHouseConnectionPool;
 }
 
 export interface VictoriaLogsConnectionInfo {
@@ -68,6 +87,46 @@ export interface ValidateConnectionRequestInfo {
   severity_field?: string;
 }
 
+// Connection details for the source setup wizard. Table listing reads
+// connection.database; previews read connection.database and table_name.
+export interface DiscoveryRequest {
+  source_type?: string;
+  connection: ClickHouseConnectionInfo;
+  limit?: number;
+}
+
+export interface DiscoveredTable {
+  name: string;
+  engine: string;
+  total_rows: number | null;
+  total_bytes: number | null;
+}
+
+// Candidate lists are ordered most likely first.
+export interface TablePreview {
+  columns: { name: string; type: string }[];
+  rows: Record<string, unknown>[];
+  timestamp_candidates: string[];
+  severity_candidates: string[];
+}
+
+export type SourceDocumentFormat = "yaml" | "json";
+
+export interface ImportedSource {
+  name: string;
+  status: "created" | "would_create" | "exists" | "failed";
+  source_id?: number;
+  error?: string;
+}
+
+export interface ImportSourcesResult {
+  dry_run: boolean;
+  created: number;
+  exists: number;
+  failed: number;
+  sources: ImportedSource[];
+}
+
 export interface Source {
   id: number;
   name: string;
@@ -82,6 +141,10 @@ export interface Source {
   connection: SourceConnectionInfo;
   description?: string;
   ttl_days: number;
+  tags?: Record<string, string>;
+  extraction_rules?: ExtractionRule[];
+  trace_correlation?: TraceCorrelation;
+  masked_columns?: MaskedColumn[];
   created_at: string;
   updated_at: string;
   is_connected: boolean;
@@ -91,11 +154,43 @@ export interface Source {
   engine?: string;
   engine_params?: string[];
   sort_keys?: string[];
+  // TTL expression read from the table's CREATE statement
+  effective_ttl?: string;
 }
 
 export interface ColumnInfo {
   name: string;
   type: string;
+  // Set for columns computed by a source extraction rule.
+  virtual?: boolean;
+  expression?: string;
+}
+
+// Mirrors models.ExtractionRule: a virtual column read from `field` (default
+// "body") by regex capture or JSON key path.
+export interface ExtractionRule {
+  name: string;
+  kind: "regex" | "json";
+  field?: string;
+  pattern?: string;
+  path?: string;
+  type?: "string" | "int" | "float";
+}
+
+// Mirrors models.TraceCorrelation: the trace/span ID columns and a link
+// template where {trace_id} and {span_id} are substituted.
+export interface TraceCorrelation {
+  trace_id_field: string;
+  span_id_field?: string;
+  url_template?: string;
+  backend_name?: string;
+}
+
+// A column whose values are hashed or redacted for users without
+// view_unmasked on their team.
+export interface MaskedColumn {
+  column: string;
+  mode?: "hash" | "redact";
 }
 
 export interface SourceWithTeamsResponse {
@@ -117,6 +212,7 @@ export interface CreateSourcePayload {
   description?: string;
   ttl_days: number;
   schema?: string;
+  tags?: Record<string, string>;
 }
 
 export interface UpdateSourcePayload {
@@ -126,6 +222,45 @@ export interface UpdateSourcePayload {
   meta_ts_field?: string;
   meta_severity_field?: string;
   connection?: SourceConnectionInfo;
+  tags?: Record<string, string>;
+  extraction_rules?: ExtractionRule[];
+  trace_correlation?: TraceCorrelation;
+  masked_columns?: MaskedColumn[];
+}
+
+export interface UpdateSourceTTLPayload {
+  ttl_days: number;
+  dry_run?: boolean;
+}
+
+export interface SourceTTLChange {
+  ttl_days: number;
+  statement: string;
+  applied: boolean;
+  effective_ttl?: string;
+}
+
+export interface TablePartition {
+  partition_id: string;
+  partition: string;
+  rows: number;
+  part_count: number;
+  bytes_on_disk: number;
+  uncompressed_bytes: number;
+  min_time?: string;
+  max_time?: string;
+}
+
+export interface DropPartitionsPayload {
+  partition_ids: string[];
+  dry_run?: boolean;
+}
+
+export interface DropPartitionsResult {
+  statements: string[];
+  applied: boolean;
+  rows: number;
+  bytes_on_disk: number;
 }
 
 export interface InspectionDetail {
@@ -178,6 +313,23 @@ export interface SourceInspection {
   storage?: InspectionMetric[];
   activity?: SourceActivity | null;
   schema?: SourceSchemaInspection | null;
+  cluster?: ClusterInspection | null;
+}
+
+// Cluster behind a source whose table uses the Distributed engine
+export interface ClusterInspection {
+  name: string;
+  local_database: string;
+  local_table: string;
+  shards: number;
+  replicas: {
+    shard: number;
+    replica: number;
+    host: string;
+    port: number;
+    is_local?: boolean;
+    errors_count?: number;
+  }[];
 }
 
 // Field values types for sidebar exploration
@@ -192,14 +344,135 @@ export interface FieldValuesResult {
   is_low_cardinality: boolean;
   values: FieldValueInfo[];
   total_distinct: number;
+  // Numeric fields: adaptive histogram buckets with approximate counts.
+  numeric_buckets?: { min: number; max: number; count: number }[];
+  // Date and DateTime fields: counts per day (YYYY-MM-DD); values is empty.
+  day_counts?: { day: string; count: number }[];
 }
 
 export type AllFieldValuesResult = Record<string, FieldValuesResult>;
 
+// Statistics for a numeric or datetime field. Datetime values are Unix
+// milliseconds; value statistics are null when the range has no values.
+export interface FieldStatsResult {
+  field_name: string;
+  field_type: string;
+  kind: 'numeric' | 'datetime';
+  count: number;
+  null_count: number;
+  null_percent: number;
+  min: number | null;
+  max: number | null;
+  avg: number | null;
+  p50: number | null;
+  p95: number | null;
+  p99: number | null;
+}
+
+// A flattened key found in JSON stored in a String column. `field` is the
+// LogchefQL reference, e.g. body.user.id; frequency is a 0-1 fraction of the
+// sampled rows.
+export interface JSONField {
+  column: string;
+  path: string[];
+  field: string;
+  type: 'string' | 'number' | 'bool' | 'array' | 'object' | 'null';
+  types: string[];
+  count: number;
+  frequency: number;
+}
+
+export interface JSONFieldsResult {
+  sampled_rows: number;
+  columns: { column: string; json_rows: number }[];
+  fields: JSONField[];
+  truncated: boolean;
+}
+
+// A message template with variable parts replaced by placeholders such as
+// <num>. first_seen/last_seen are Unix milliseconds; baseline_count is only
+// set when a baseline window was requested.
+export interface LogPattern {
+  pattern: string;
+  count: number;
+  percent: number;
+  examples: string[];
+  first_seen: number;
+  last_seen: number;
+  baseline_count?: number;
+  new: boolean;
+}
+
+export interface LogPatternsResult {
+  field: string;
+  total_rows: number;
+  total_patterns: number;
+  patterns: LogPattern[];
+}
+
+// Row counts of one window; the error fields are only set when the source
+// has a severity field. error_rate is a percentage of rows.
+export interface VolumeWindow {
+  start: string;
+  end: string;
+  rows: number;
+  error_rows?: number;
+  error_rate?: number;
+}
+
+export type VolumeAnomalyKind = 'volume_spike' | 'volume_drop' | 'error_rate_spike';
+
+export interface VolumeAnomalyReport {
+  baseline: 'previous_day' | 'previous_week';
+  threshold_percent: number;
+  min_rows: number;
+  current: VolumeWindow;
+  previous: VolumeWindow;
+  volume_change_percent: number;
+  error_rate_change?: number; // percentage points
+  anomalies: VolumeAnomalyKind[];
+}
+
+export type ValueChangeKind = 'appeared' | 'disappeared' | 'increased' | 'decreased';
+
+// A field value's count in both ranges; the percents are shares of each
+// range's rows.
+export interface ValueChange {
+  value: string;
+  baseline_count: number;
+  target_count: number;
+  baseline_percent: number;
+  target_percent: number;
+  change: ValueChangeKind;
+}
+
+export interface RangeComparison {
+  threshold_percent: number;
+  baseline: { start: string; end: string; rows: number };
+  target: { start: string; end: string; rows: number };
+  fields: { field: string; changes: ValueChange[] }[];
+}
+
+export interface SchemaChange {
+  kind: 'added' | 'dropped' | 'type_changed';
+  column: string;
+  old_type?: string;
+  new_type?: string;
+}
+
+export interface SourceSchemaSnapshot {
+  id: number;
+  source_id: number;
+  columns: { name: string; type: string; description?: string }[];
+  // Empty for a source's first (baseline) snapshot.
+  changes: SchemaChange[];
+  captured_at: string;
+}
+
 export const sourcesApi = {
   // Source management
   listAllSourcesForAdmin: () =>
-    apiClient.get<Source[]>("/admin/sources"),
+    apiClient.get<Source[]>(`/admin/sources?limit=${MAX_LIST_LIMIT}`),
   listTeamSources: (teamId: number) =>
     apiClient.get<Source[]>(`/teams/${teamId}/sources`),
   getTeamSource: (teamId: number, sourceId: number) =>
@@ -208,6 +481,12 @@ export const sourcesApi = {
     apiClient.post<Source>("/admin/sources", payload),
   updateSource: (id: number, payload: UpdateSourcePayload) =>
     apiClient.put<Source>(`/admin/sources/${id}`, payload),
+  updateSourceTTL: (id: number, payload: UpdateSourceTTLPayload) =>
+    apiClient.put<SourceTTLChange>(`/admin/sources/${id}/ttl`, payload),
+  listSourcePartitions: (id: number) =>
+    apiClient.get<TablePartition[]>(`/admin/sources/${id}/partitions`),
+  dropSourcePartitions: (id: number, payload: DropPartitionsPayload) =>
+    apiClient.post<DropPartitionsResult>(`/admin/sources/${id}/partitions/drop`, payload),
   deleteSource: (id: number) =>
     apiClient.delete<{ message: string }>(`/admin/sources/${id}`),
 
@@ -222,6 +501,13 @@ export const sourcesApi = {
     apiClient.get<SourceActivity>(`/teams/${teamId}/sources/${sourceId}/activity${refresh ? '?refresh=true' : ''}`, { timeout: 5, suppressErrorToast: true }),
   getTeamSourceSchema: (teamId: number, sourceId: number) =>
     apiClient.get<string>(`/teams/${teamId}/sources/${sourceId}/schema`),
+  getTeamSchemaHistory: (teamId: number, sourceId: number, limit = 50) =>
+    apiClient.get<SourceSchemaSnapshot[]>(`/teams/${teamId}/sources/${sourceId}/schema/history?limit=${limit}`),
+  updateTeamSourceExtractionRules: (teamId: number, sourceId: number, rules: ExtractionRule[]) =>
+    apiClient.put<Source>(`/teams/${teamId}/sources/${sourceId}/extraction-rules`, { rules }),
+  // Pass an empty trace_id_field to clear the source's trace correlation.
+  updateTeamSourceTraceCorrelation: (teamId: number, sourceId: number, tracing: TraceCorrelation) =>
+    apiClient.put<Source>(`/teams/${teamId}/sources/${sourceId}/trace-correlation`, tracing),
 
   // Validation
   validateSourceConnection: (connectionInfo: ValidateConnectionRequestInfo) =>
@@ -232,6 +518,37 @@ export const sourcesApi = {
       severity_field: connectionInfo.severity_field,
     }),
 
+  // Table discovery for guided source setup
+  discoverDatabases: (req: DiscoveryRequest) =>
+    apiClient.post<string[]>("/admin/sources/discover/databases", { source_type: "clickhouse", ...req }),
+  discoverTables: (req: DiscoveryRequest) =>
+    apiClient.post<DiscoveredTable[]>("/admin/sources/discover/tables", { source_type: "clickhouse", ...req }),
+  previewSourceTable: (req: DiscoveryRequest) =>
+    apiClient.post<TablePreview>("/admin/sources/discover/preview", { source_type: "clickhouse", ...req }),
+
+  // Bulk export/import of source definitions
+  exportSources: async (format: SourceDocumentFormat = "yaml", passphrase?: string): Promise<Blob> => {
+    const response = await fetch("/api/v1/admin/sources/export", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      credentials: "same-origin",
+      body: JSON.stringify({ format, passphrase }),
+    });
+    if (!response.ok) {
+      let message = `Source export failed (${response.status})`;
+      try {
+        const body = await response.json();
+        if (body?.message) message = body.message;
+      } catch {
+        // non-JSON error body; keep the default message
+      }
+      throw new Error(message);
+    }
+    return response.blob();
+  },
+  importSources: (document: string, passphrase?: string, dryRun = false) =>
+    apiClient.post<ImportSourcesResult>("/admin/sources/import", { document, passphrase, dry_run: dryRun }),
+
   // Field values for sidebar exploration
   // Time range is required for performance (avoids full table scan)
   // Query is optional - filters field values based on the current datasource-native query
@@ -290,4 +607,134 @@ export const sourcesApi = {
     }
     return apiClient.get<AllFieldValuesResult>(url, { signal });
   },
+  getFieldStats: (
+    teamId: number,
+    sourceId: number,
+    fieldName: string,
+    fieldType: string,
+    startTime: string,  // ISO8601 format
+    endTime: string,    // ISO8601 format
+    queryLanguage?: QueryLanguage,
+    query?: string,      // Optional datasource-native query to narrow the rows
+    signal?: AbortSignal
+  ) => {
+    let url = `/teams/${teamId}/sources/${sourceId}/fields/${encodeURIComponent(fieldName)}/stats?` +
+      `type=${encodeURIComponent(fieldType)}` +
+      `&start_time=${encodeURIComponent(startTime)}` +
+      `&end_time=${encodeURIComponent(endTime)}`;
+    if (queryLanguage) {
+      url += `&query_language=${encodeURIComponent(queryLanguage)}`;
+    }
+    if (query) {
+      url += `&query=${encodeURIComponent(query)}`;
+    }
+    return apiClient.get<FieldStatsResult>(url, { signal, suppressErrorToast: true });
+  },
+  getJSONFields: (
+    teamId: number,
+    sourceId: number,
+    options: {
+      columns?: string[];     // String columns to inspect; defaults to body
+      startTime?: string;     // ISO8601 format; defaults to an hour before endTime
+      endTime?: string;       // ISO8601 format; defaults to now
+      sampleSize?: number;
+      query?: string;
+    } = {},
+    signal?: AbortSignal
+  ) => {
+    const params = new URLSearchParams();
+    if (options.columns?.length) params.set('columns', options.columns.join(','));
+    if (options.startTime) params.set('start_time', options.startTime);
+    if (options.endTime) params.set('end_time', options.endTime);
+    if (options.sampleSize) params.set('sample_size', String(options.sampleSize));
+    if (options.query) params.set('query', options.query);
+    const qs = params.toString();
+    return apiClient.get<JSONFieldsResult>(
+      `/teams/${teamId}/sources/${sourceId}/fields/json${qs ? `?${qs}` : ''}`,
+      { signal, suppressErrorToast: true }
+    );
+  },
+  getLogPatterns: (
+    teamId: number,
+    sourceId: number,
+    options: {
+      startTime: string;      // ISO8601 format
+      endTime: string;        // ISO8601 format
+      field?: string;         // String column to group; defaults to body
+      limit?: number;
+      baseline?: boolean;     // Compare with the preceding window to flag new patterns
+      query?: string;
+    },
+    signal?: AbortSignal
+  ) => {
+    const params = new URLSearchParams({
+      start_time: options.startTime,
+      end_time: options.endTime,
+    });
+    if (options.field) params.set('field', options.field);
+    if (options.limit) params.set('limit', String(options.limit));
+    if (options.baseline) params.set('baseline', 'true');
+    if (options.query) params.set('query', options.query);
+    return apiClient.get<LogPatternsResult>(
+      `/teams/${teamId}/sources/${sourceId}/patterns?${params.toString()}`,
+      { signal }
+    );
+  },
+
+  getVolumeAnomalies: (
+    teamId: number,
+    sourceId: number,
+    options: {
+      windowSeconds?: number;  // Defaults to 3600
+      endTime?: string;        // ISO8601 format; defaults to now
+      baseline?: 'previous_day' | 'previous_week';
+      thresholdPercent?: number;
+      minRows?: number;
+      query?: string;
+    } = {},
+    signal?: AbortSignal
+  ) => {
+    const params = new URLSearchParams();
+    if (options.windowSeconds) params.set('window_seconds', String(options.windowSeconds));
+    if (options.endTime) params.set('end_time', options.endTime);
+    if (options.baseline) params.set('baseline', options.baseline);
+    if (options.thresholdPercent) params.set('threshold_percent', String(options.thresholdPercent));
+    if (options.minRows) params.set('min_rows', String(options.minRows));
+    if (options.query) params.set('query', options.query);
+    return apiClient.get<VolumeAnomalyReport>(
+      `/teams/${teamId}/sources/${sourceId}/anomalies/volume?${params.toString()}`,
+      { signal }
+    );
+  },
+
+  compareRanges: (
+    teamId: number,
+    sourceId: number,
+    options: {
+      baselineStart: string;  // ISO8601 format
+      baselineEnd: string;    // ISO8601 format
+      startTime: string;      // ISO8601 format, target range
+      endTime: string;        // ISO8601 format, target range
+      fields?: string[];      // Defaults to LowCardinality/Enum columns
+      limit?: number;
+      thresholdPercent?: number;
+      query?: string;
+    },
+    signal?: AbortSignal
+  ) => {
+    const params = new URLSearchParams({
+      baseline_start: options.baselineStart,
+      baseline_end: options.baselineEnd,
+      start_time: options.startTime,
+      end_time: options.endTime,
+    });
+    if (options.fields?.length) params.set('fields', options.fields.join(','));
+    if (options.limit) params.set('limit', String(options.limit));
+    if (options.thresholdPercent) params.set('threshold_percent', String(options.thresholdPercent));
+    if (options.query) params.set('query', options.query);
+    return apiClient.get<RangeComparison>(
+      `/teams/${teamId}/sources/${sourceId}/compare?${params.toString()}`,
+      { signal }
+    );
+  },
 };
@@ -1,4 +1,5 @@
 import { apiClient } from "./apiUtils";
+import { MAX_LIST_LIMIT } from "./types";
 import type { Source } from "./sources";
 
 export interface Team {
@@ -9,6 +10,7 @@ export interface Team {
   created_at: string;
   updated_at: string;
   member_count?: number;
+  raw_sql_enabled?: boolean;
 }
 
 export interface UserTeamMembership {
@@ -18,13 +20,14 @@ export interface UserTeamMembership {
   created_at: string;
   updated_at: string;
   member_count: number;
-  role: "admin" | "member" | "editor";
+  role: "admin" | "member" | "editor" | "viewer";
+  raw_sql_enabled?: boolean;
 }
 
 export interface TeamMember {
   team_id: number;
   user_id: number;
-  role: "admin" | "member" | "editor";
+  role: "admin" | "member" | "editor" | "viewer";
   created_at: string;
   updated_at: string;
   email: string;
@@ -40,6 +43,7 @@ export interface CreateTeamRequest {
 export interface UpdateTeamRequest {
   name: string;
   description: string;
+  raw_sql_enabled?: boolean;
 }
 
 export interface UserIdentifier {
@@ -48,22 +52,44 @@ export interface UserIdentifier {
 
 export interface AddTeamMemberRequest {
   user_id: number;
-  role: 'admin' | 'member' | 'editor';
+  role: 'admin' | 'member' | 'editor' | 'viewer';
 }
 
 export interface TeamWithMemberCount extends Team {
   member_count: number;
 }
 
+/** A team's query usage today (UTC) against its daily quota; 0 caps are unlimited. */
+export interface TeamUsage {
+  team_id: number;
+  date: string;
+  queries: number;
+  rows_read: number;
+  limits: { max_queries_per_day: number; max_rows_read_per_day: number };
+  /** null when the matching cap is unlimited. */
+  remaining_queries: number | null;
+  remaining_rows_read: number | null;
+  exceeded: boolean;
+  resets_at: string;
+}
+
+/** The row filter ANDed into every query the team runs on a source; "" when none. */
+export interface TeamSourcePolicy {
+  team_id: number;
+  source_id: number;
+  row_filter: string;
+}
+
 export const teamsApi = {
   listUserTeams: () => apiClient.get<UserTeamMembership[]>("/me/teams"),
-  listAllTeams: () => apiClient.get<TeamWithMemberCount[]>("/admin/teams"),
+  listAllTeams: () => apiClient.get<TeamWithMemberCount[]>(`/admin/teams?limit=${MAX_LIST_LIMIT}`),
   getTeam: (id: number) => apiClient.get<Team & { member_count?: number }>(`/teams/${id}`),
   createTeam: (data: CreateTeamRequest) => apiClient.post<Team>("/admin/teams", data),
   updateTeam: (id: number, data: UpdateTeamRequest) =>
     apiClient.put<Team>(`/teams/${id}`, data),
   deleteTeam: (id: number) =>
     apiClient.delete<{ message: string }>(`/admin/teams/${id}`),
+  getTeamUsage: (id: number) => apiClient.get<TeamUsage>(`/teams/${id}/usage`),
 
   // Team members
   listTeamMembers: (teamId: number) =>
@@ -79,5 +105,9 @@ export const teamsApi = {
   addTeamSource: (teamId: number, sourceId: number) =>
     apiClient.post<Source>(`/teams/${teamId}/sources`, { source_id: sourceId }),
   removeTeamSource: (teamId: number, sourceId: number) =>
-    apiClient.delete<{ message: string }>(`/teams/${teamId}/sources/${sourceId}`)
+    apiClient.delete<{ message: string }>(`/teams/${teamId}/sources/${sourceId}`),
+  getTeamSourcePolicy: (teamId: number, sourceId: number) =>
+    apiClient.get<TeamSourcePolicy>(`/admin/teams/${teamId}/sources/${sourceId}/policy`),
+  updateTeamSourcePolicy: (teamId: number, sourceId: number, rowFilter: string) =>
+    apiClient.put<TeamSourcePolicy>(`/admin/teams/${teamId}/sources/${sourceId}/policy`, { row_filter: rowFilter })
 };
@@ -3,8 +3,26 @@ import type { VariableState } from "@/stores/variables";
 export interface APISuccessResponse<T> {
   status: "success";
   data: T | null;
+  // Present on list endpoints; data then holds one page.
+  pagination?: Pagination;
 }
 
+/**
+ * Page details of a list endpoint response. total counts every item matching
+ * the request's filters.
+ */
+export interface Pagination {
+  total: number;
+  limit: number;
+  offset: number;
+  sort?: string;
+  order?: "asc" | "desc";
+  has_more: boolean;
+}
+
+// Largest page a list endpoint returns; screens that show a whole list ask for it.
+export const MAX_LIST_LIMIT = 1000;
+
 export interface APIListResponse<T> {
   status: "success";
   data: T[];
@@ -30,6 +48,14 @@ export interface APIErrorResponse {
 
 export type APIResponse<T = any> = APISuccessResponse<T> | APIErrorResponse;
 
+// Data of a "QueryCostError" response: the query's estimated scan was over
+// the rows or bytes cap configured under [query.cost].
+export interface QueryCostErrorData {
+  limit: "rows" | "bytes";
+  max: number;
+  estimated: { rows: number; bytes: number; parts: number; marks: number };
+}
+
 /**
  * Team information
  */
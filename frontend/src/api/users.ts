@@ -1,4 +1,5 @@
 import { apiClient } from "./apiUtils";
+import { MAX_LIST_LIMIT } from "./types";
 import type { User } from "@/types";
 
 export interface CreateUserRequest {
@@ -17,7 +18,7 @@ export interface UpdateUserRequest {
 export const usersApi = {
   // Note: /users is accessible to any authenticated user to allow team admins
   // to select users when adding members to their teams.
-  listUsers: () => apiClient.get<User[]>("/users"),
+  listUsers: () => apiClient.get<User[]>(`/users?limit=${MAX_LIST_LIMIT}`),
   getUser: (id: string) => apiClient.get<{ user: User }>(`/admin/users/${id}`),
   createUser: (data: CreateUserRequest) => apiClient.post<User>("/admin/users", data),
   updateUser: (id: string, data: UpdateUserRequest) =>
@@ -0,0 +1,55 @@
+import { apiClient } from "./apiUtils";
+
+/** Lifecycle event types (mirrors pkg/models EventType); "*" receives all. */
+export type WebhookEventType =
+  | "source.created"
+  | "source.deleted"
+  | "source.health_changed"
+  | "alert.fired"
+  | "alert.resolved"
+  | "team.member_added"
+  | "team.member_removed"
+  | "*";
+
+export interface Webhook {
+  id: number;
+  name: string;
+  url: string;
+  event_types: WebhookEventType[];
+  is_active: boolean;
+  created_by?: number | null;
+  created_at: string;
+  updated_at: string;
+}
+
+/** Returned on create and secret rotation: the only time the secret is shown. */
+export interface WebhookWithSecret extends Webhook {
+  secret: string;
+}
+
+export interface CreateWebhookRequest {
+  name: string;
+  url: string;
+  event_types: WebhookEventType[];
+  /** At least 16 characters; generated when omitted. */
+  secret?: string;
+  is_active?: boolean;
+}
+
+export interface UpdateWebhookRequest {
+  name: string;
+  url: string;
+  event_types: WebhookEventType[];
+  is_active?: boolean;
+  rotate_secret?: boolean;
+}
+
+export const webhooksApi = {
+  list: () => apiClient.get<Webhook[]>("/admin/webhooks"),
+  get: (id: number) => apiClient.get<Webhook>(`/admin/webhooks/${id}`),
+  create: (req: CreateWebhookRequest) => apiClient.post<WebhookWithSecret>("/admin/webhooks", req),
+  update: (id: number, req: UpdateWebhookRequest) =>
+    apiClient.put<Webhook | WebhookWithSecret>(`/admin/webhooks/${id}`, req),
+  remove: (id: number) => apiClient.delete<{ message: string }>(`/admin/webhooks/${id}`),
+  test: (id: number) => apiClient.post<{ message: string }>(`/admin/webhooks/${id}/test`),
+};
@@ -31,13 +31,14 @@ export interface AlertFormState {
   description: string;
   editor_mode: "condition" | "native";
   query: string;
-  condition_json: string; // LogChefQL condition string
+  condition_json: string; // LogChefQL filter; sent as a structured condition
   aggregate_function: "count" | "sum" | "avg" | "min" | "max";
   aggregate_field: string;
   lookback_seconds: number;
   threshold_operator: Alert["threshold_operator"];
   threshold_value: number;
   frequency_seconds: number;
+  for_seconds: number;
   severity: Alert["severity"];
   is_active: boolean;
   labels: Array<{ id: number; key: string; value: string }>;
@@ -60,6 +61,23 @@ export interface ConditionTemplate {
   aggregate: "count";
 }
 
+// StructuredCondition mirrors the server's condition_json object. Older alerts
+// store the bare LogChefQL string instead.
+interface StructuredCondition {
+  filter?: string;
+  aggregate?: AlertFormState["aggregate_function"];
+  field?: string;
+}
+
+function parseStructuredCondition(raw: string): StructuredCondition | null {
+  if (!raw.trim().startsWith("{")) return null;
+  try {
+    return JSON.parse(raw) as StructuredCondition;
+  } catch {
+    return null;
+  }
+}
+
 export interface UseAlertFormProps {
   open: boolean;
   mode: "create" | "edit";
@@ -144,6 +162,7 @@ export function useAlertForm(props: UseAlertFormProps, emit: UseAlertFormEmit) {
     threshold_operator: "gt",
     threshold_value: 1,
     frequency_seconds: 300,
+    for_seconds: 0,
     severity: "warning",
     is_active: true,
     labels: [],
@@ -522,6 +541,7 @@ WHERE \`${tsField}\` >= ${lookback}`,
       form.threshold_operator = "gt";
       form.threshold_value = 1;
       form.frequency_seconds = 300;
+      form.for_seconds = 0;
       form.severity = "warning";
       form.is_active = true;
       form.labels = [];
@@ -543,17 +563,27 @@ WHERE \`${tsField}\` >= ${lookback}`,
     form.condition_json = alert.condition_json ?? "";
     form.aggregate_function = "count";
     form.aggregate_field = "";
-    const aggMatch = (alert.query || "").match(/(count|sum|avg|min|max)\(\s*[`"]?([^`")]*)[`"]?\s*\)/);
-    if (aggMatch) {
-      form.aggregate_function = aggMatch[1] as typeof form.aggregate_function;
-      if (aggMatch[1] !== "count") {
-        form.aggregate_field = aggMatch[2].trim();
+    const structured = parseStructuredCondition(form.condition_json);
+    if (structured) {
+      form.condition_json = structured.filter ?? "";
+      form.aggregate_function = structured.aggregate ?? "count";
+      form.aggregate_field = structured.field ?? "";
+    } else {
+      // Legacy condition alerts store the bare LogchefQL string; recover the
+      // aggregation from the generated query.
+      const aggMatch = (alert.query || "").match(/(count|sum|avg|min|max)\(\s*[`"]?([^`")]*)[`"]?\s*\)/);
+      if (aggMatch) {
+        form.aggregate_function = aggMatch[1] as typeof form.aggregate_function;
+        if (aggMatch[1] !== "count") {
+          form.aggregate_field = aggMatch[2].trim();
+        }
       }
     }
     form.lookback_seconds = alert.lookback_seconds;
     form.threshold_operator = alert.threshold_operator;
     form.threshold_value = alert.threshold_value;
     form.frequency_seconds = alert.frequency_seconds;
+    form.for_seconds = alert.for_seconds ?? 0;
     form.severity = alert.severity;
     form.is_active = alert.is_active;
     labelCounter.value = 0;
@@ -569,6 +599,17 @@ WHERE \`${tsField}\` >= ${lookback}`,
     form.webhook_urls = extendedAlert.webhook_urls ? [...extendedAlert.webhook_urls] : [];
   }
 
+  // The server compiles and evaluates the structured condition itself; the
+  // generated query is still sent for display and older servers.
+  function buildConditionJSON(): string {
+    const aggregate = form.aggregate_function;
+    return JSON.stringify({
+      filter: form.condition_json.trim(),
+      aggregate,
+      ...(aggregate !== "count" ? { field: form.aggregate_field.trim() } : {}),
+    });
+  }
+
   async function handleTestQuery() {
     if (!props.teamId || !props.sourceId || !form.query.trim()) {
       return;
@@ -584,7 +625,7 @@ WHERE \`${tsField}\` >= ${lookback}`,
         query_language: alertMetadata.value.queryLanguage,
         editor_mode: alertMetadata.value.editorMode,
         query: form.query.trim(),
-        condition_json: form.editor_mode === "condition" ? form.condition_json.trim() : undefined,
+        condition_json: form.editor_mode === "condition" ? buildConditionJSON() : undefined,
         lookback_seconds: form.lookback_seconds,
         threshold_operator: form.threshold_operator,
         threshold_value: form.threshold_value,
@@ -665,11 +706,12 @@ WHERE \`${tsField}\` >= ${lookback}`,
       query_language: alertMetadata.value.queryLanguage,
       editor_mode: alertMetadata.value.editorMode,
       query: form.query.trim(),
-      condition_json: form.editor_mode === "condition" ? form.condition_json.trim() : undefined,
+      condition_json: form.editor_mode === "condition" ? buildConditionJSON() : undefined,
       lookback_seconds: Number(form.lookback_seconds),
       threshold_operator: form.threshold_operator,
       threshold_value: Number(form.threshold_value),
       frequency_seconds: Number(form.frequency_seconds),
+      for_seconds: Number(form.for_seconds) || 0,
       severity: form.severity,
       is_active: form.is_active,
       labels: labelsRecord,
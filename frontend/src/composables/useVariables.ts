@@ -2,7 +2,8 @@ import {useVariableStore, type VariableState} from "@/stores/variables.ts";
 import {storeToRefs} from "pinia";
 import type { TemplateVariable } from "@/api/explore";
 
-const createVariablePattern = () => /\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}/g;
+// Matches {{name}} and declared forms such as {{name:number:default="x"}}.
+const createVariablePattern = () => /\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(?::(?:[^{}"]|"(?:[^"\\]|\\.)*")*)?\}\}/g;
 // Regex to match [[ ... ]] optional clauses (non-greedy)
 const createOptionalClausePattern = () => /\[\[(.+?)\]\]/gs;
 
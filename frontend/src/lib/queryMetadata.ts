@@ -89,7 +89,12 @@ export function hasSourceCapability(source: SourceDescriptor, capability: string
       capability === "schema_inspection" ||
       capability === "source_inspection" ||
       capability === "ai_sql_generation" ||
-      capability === "exports"
+      capability === "exports" ||
+      capability === "field_stats" ||
+      capability === "json_fields" ||
+      capability === "log_patterns" ||
+      capability === "volume_anomalies" ||
+      capability === "range_comparison"
     );
   }
   return false;
@@ -11,6 +11,7 @@ export type TokenScope =
   | "sources:read"
   | "sources:write"
   | "logs:read"
+  | "logs:write"
   | "saved_queries:read"
   | "saved_queries:write"
   | "collections:read"
@@ -19,10 +20,17 @@ export type TokenScope =
   | "alerts:write"
   | "dashboards:read"
   | "dashboards:write"
+  | "notebooks:read"
+  | "notebooks:write"
   | "query_shares:read"
   | "query_shares:write"
+  | "annotations:read"
+  | "annotations:write"
+  | "bookmarks:read"
+  | "bookmarks:write"
   | "settings:read"
-  | "settings:write";
+  | "settings:write"
+  | "audit:read";
 
 export interface TokenScopeOption {
   value: TokenScope;
@@ -42,8 +50,12 @@ export const READ_ONLY_SCOPES: TokenScope[] = [
   "collections:read",
   "alerts:read",
   "dashboards:read",
+  "notebooks:read",
   "query_shares:read",
+  "annotations:read",
+  "bookmarks:read",
   "settings:read",
+  "audit:read",
 ];
 
 export const TOKEN_SCOPE_OPTIONS: TokenScopeOption[] = [
@@ -58,6 +70,7 @@ export const TOKEN_SCOPE_OPTIONS: TokenScopeOption[] = [
   { value: "sources:read", label: "Sources read", description: "Read source metadata, schema, and stats.", group: "Logs" },
   { value: "sources:write", label: "Sources write", description: "Create, validate, update, and delete sources.", group: "Logs" },
   { value: "logs:read", label: "Logs read", description: "Run queries, histograms, exports, context lookup, and LogchefQL translation.", group: "Logs" },
+  { value: "logs:write", label: "Logs write", description: "Push log batches to the ingest endpoint.", group: "Logs" },
   { value: "saved_queries:read", label: "Saved queries read", description: "List and resolve saved queries.", group: "Logs" },
   { value: "saved_queries:write", label: "Saved queries write", description: "Create, update, and delete saved queries.", group: "Logs" },
   { value: "collections:read", label: "Collections read", description: "List collections, members, and items.", group: "Collections" },
@@ -66,10 +79,17 @@ export const TOKEN_SCOPE_OPTIONS: TokenScopeOption[] = [
   { value: "alerts:write", label: "Alerts write", description: "Create, test, update, delete, and resolve alerts.", group: "Alerts" },
   { value: "dashboards:read", label: "Dashboards read", description: "List and view dashboards and their panels.", group: "Dashboards" },
   { value: "dashboards:write", label: "Dashboards write", description: "Create, update, and delete dashboards.", group: "Dashboards" },
+  { value: "notebooks:read", label: "Notebooks read", description: "List, view, and export team notebooks.", group: "Notebooks" },
+  { value: "notebooks:write", label: "Notebooks write", description: "Create, update, and delete notebooks and refresh cached cell results.", group: "Notebooks" },
   { value: "query_shares:read", label: "Query shares read", description: "Open existing query share links.", group: "Sharing" },
   { value: "query_shares:write", label: "Query shares write", description: "Create and delete query share links.", group: "Sharing" },
+  { value: "annotations:read", label: "Annotations read", description: "List timeline annotations such as deploy and incident markers.", group: "Annotations" },
+  { value: "annotations:write", label: "Annotations write", description: "Create, update, and delete timeline annotations.", group: "Annotations" },
+  { value: "bookmarks:read", label: "Bookmarks read", description: "List your pinned log lines and your teams' shared ones.", group: "Bookmarks" },
+  { value: "bookmarks:write", label: "Bookmarks write", description: "Pin, annotate, share, and remove log lines.", group: "Bookmarks" },
   { value: "settings:read", label: "Settings read", description: "Read system settings and provisioning export.", group: "Administration" },
   { value: "settings:write", label: "Settings write", description: "Update system settings and test notifications.", group: "Administration" },
+  { value: "audit:read", label: "Audit read", description: "List and filter the audit trail of sensitive actions.", group: "Administration" },
 ];
 
 export interface TokenScopePreset {
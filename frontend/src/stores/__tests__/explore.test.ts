@@ -58,6 +58,7 @@ vi.mock("@/stores/exploreHistogram", () => ({
     isLoadingHistogram: false,
     histogramError: null,
     histogramGranularity: null,
+    histogramGroupedBy: null,
     groupByField: null,
   }),
 }));
@@ -17,7 +17,7 @@ import {
   type CacheDirective,
 } from "@/api/dashboards";
 import { isSuccessResponse, type APIResponse } from "@/api/types";
-import { HistogramService, type HistogramData } from "@/services/HistogramService";
+import type { HistogramData } from "@/services/HistogramService";
 import { sumHistogramCounts, reflowPanels, validatePanelsBlob } from "@/utils/dashboardPanels";
 import { parseRelativeTimeString, calendarDateTimeToTimestamp } from "@/utils/time";
 import { isCanceledError } from "@/api/error-handler";
@@ -547,14 +547,14 @@ export const useDashboardsStore = defineStore("dashboards", () => {
         return { status: "error", error: "Breakdown panels require a group-by field." };
       }
       const { query: queryText, language: nativeLanguage } = await resolveNativeQuery(panel, range, signal);
-      const window = HistogramService.calculateOptimalGranularity(startIso, endIso);
-
       // query_language is advisory today (the native endpoints interpret
       // query_text by source type) but is sent so a VictoriaLogs source runs
       // native LogsQL rather than being re-parsed as ClickHouse SQL.
       const histBody: HistogramRequestBody = {
         query_text: queryText,
-        window,
+        // The server sizes buckets from the range; the response's
+        // granularity reports the window it chose.
+        window: "auto",
         group_by: groupBy,
         start_time: startIso,
         end_time: endIso,
@@ -31,6 +31,7 @@ import { type TimeRange } from '@/types/query';
 import { useVariables } from "@/composables/useVariables";
 import { useVariableStore, type VariableState } from "@/stores/variables";
 import { createTimeRangeCondition } from '@/utils/time-utils';
+import { visibleTableColumns } from '@/utils/tableState';
 import { asClickHouseConnection } from '@/api/sources';
 import {
   getExploreModeForQueryLanguage,
@@ -86,6 +87,8 @@ export interface ExploreState {
   selectedQueryId: string | null;
   activeShareToken: string | null;
   activeShareSnapshot: string | null;
+  // Columns of the opened share link, applied to the results table.
+  sharedColumns: string[] | null;
   activeSavedQueryName: string | null;
   savedQuerySnapshot: SavedQuerySnapshot | null;
   stats?: any;
@@ -225,6 +228,7 @@ export const useExploreStore = defineStore("explore", () => {
     selectedQueryId: null,
     activeShareToken: null,
     activeShareSnapshot: null,
+    sharedColumns: null,
     activeSavedQueryName: null,
     savedQuerySnapshot: null,
     selectedTimezoneIdentifier: null,
@@ -444,6 +448,7 @@ export const useExploreStore = defineStore("explore", () => {
   function clearActiveShareSelection() {
     state.data.value.activeShareToken = null;
     state.data.value.activeShareSnapshot = null;
+    state.data.value.sharedColumns = null;
   }
 
   function clearActiveSavedQuerySelection() {
@@ -1016,6 +1021,7 @@ export const useExploreStore = defineStore("explore", () => {
         state.data.value.limit = payload.limit;
       }
       state.data.value.selectedTimezoneIdentifier = payload.timezone || null;
+      state.data.value.sharedColumns = payload.columns?.length ? [...payload.columns] : null;
 
       const relative = payload.time_range?.relative;
       const absolute = payload.time_range?.absolute;
@@ -1671,6 +1677,12 @@ export const useExploreStore = defineStore("explore", () => {
       variables: variableStore.allVariables as unknown as QuerySharePayload["variables"],
     };
 
+    const currentTeamId = useTeamsStore().currentTeamId;
+    const columns = currentTeamId && sourceId.value ? visibleTableColumns(currentTeamId, sourceId.value) : [];
+    if (columns.length > 0) {
+      payload.columns = columns;
+    }
+
     if (selectedRelativeTime) {
       payload.time_range = { relative: selectedRelativeTime };
     } else if (timeRange) {
@@ -1685,7 +1697,7 @@ export const useExploreStore = defineStore("explore", () => {
     return payload;
   }
 
-  async function createQueryShare(options?: { persistActiveToken?: boolean }) {
+  async function createQueryShare(options?: { persistActiveToken?: boolean; expiresInSeconds?: number; permanent?: boolean }) {
     const currentTeamId = useTeamsStore().currentTeamId;
     if (!currentTeamId || !sourceId.value) {
       throw new Error("Team and source are required to share a query");
@@ -1696,7 +1708,10 @@ export const useExploreStore = defineStore("explore", () => {
       throw new Error("Query is required to create a share link");
     }
 
-    const response = await exploreApi.createQueryShare(sourceId.value, payload, currentTeamId);
+    const response = await exploreApi.createQueryShare(sourceId.value, payload, currentTeamId, {
+      expiresInSeconds: options?.expiresInSeconds,
+      permanent: options?.permanent,
+    });
     if (response.data && options?.persistActiveToken !== false) {
       state.data.value.activeShareToken = response.data.token;
       state.data.value.activeShareSnapshot = buildCurrentShareSnapshot();
@@ -1886,6 +1901,7 @@ export const useExploreStore = defineStore("explore", () => {
     hasExecutedQuery: computed(() => state.data.value.hasExecutedQuery),
     selectedQueryId: computed(() => state.data.value.selectedQueryId),
     activeShareToken: computed(() => state.data.value.activeShareToken),
+    sharedColumns: computed(() => state.data.value.sharedColumns),
     activeSavedQueryName: computed(() => state.data.value.activeSavedQueryName),
     selectedTimezoneIdentifier: computed(() => state.data.value.selectedTimezoneIdentifier),
     generatedDisplayQuery: computed(() => state.data.value.generatedDisplayQuery),
@@ -1901,6 +1917,7 @@ export const useExploreStore = defineStore("explore", () => {
     histogramError: computed(() => histogramStore.histogramError),
     histogramNotice: computed(() => histogramStore.histogramNotice),
     histogramGranularity: computed(() => histogramStore.histogramGranularity),
+    histogramGroupedBy: computed(() => histogramStore.histogramGroupedBy),
     groupByField: computed(() => histogramStore.groupByField),
 
     // Loading state
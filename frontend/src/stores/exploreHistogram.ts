@@ -3,7 +3,7 @@ import { computed, ref } from "vue";
 import { exploreApi } from "@/api/explore";
 import { useTeamsStore } from "@/stores/teams";
 import { useContextStore } from "@/stores/context";
-import { HistogramService, type HistogramData } from '@/services/HistogramService';
+import type { HistogramData } from '@/services/HistogramService';
 import { useVariables } from "@/composables/useVariables";
 
 interface HistogramState {
@@ -13,6 +13,8 @@ interface HistogramState {
   notice: string | null;
   granularity: string | null;
   groupByField: string | null;
+  // Field the current data is grouped by, as reported by the server
+  groupedBy: string | null;
 }
 
 export const useExploreHistogramStore = defineStore("exploreHistogram", () => {
@@ -25,6 +27,7 @@ export const useExploreHistogramStore = defineStore("exploreHistogram", () => {
     notice: null,
     granularity: null,
     groupByField: null,
+    groupedBy: null,
   });
 
   // Cancels a superseded histogram fetch so a slower earlier request can't
@@ -37,6 +40,7 @@ export const useExploreHistogramStore = defineStore("exploreHistogram", () => {
   const histogramNotice = computed(() => state.value.notice);
   const histogramGranularity = computed(() => state.value.granularity);
   const groupByField = computed(() => state.value.groupByField);
+  const histogramGroupedBy = computed(() => state.value.groupedBy);
 
   function clearHistogramData() {
     // Cancel any in-flight fetch so its late response can't repopulate the data
@@ -49,6 +53,7 @@ export const useExploreHistogramStore = defineStore("exploreHistogram", () => {
     state.value.error = null;
     state.value.notice = null;
     state.value.granularity = null;
+    state.value.groupedBy = null;
     state.value.isLoading = false;
   }
 
@@ -115,7 +120,9 @@ export const useExploreHistogramStore = defineStore("exploreHistogram", () => {
           'minute' in timeRange.end ? timeRange.end.minute : 0,
           'second' in timeRange.end ? timeRange.end.second : 0
         ).toISOString();
-        windowGranularity = HistogramService.calculateOptimalGranularity(startISO, endISO);
+        // Let the server size buckets for the range; it reports the window
+        // it picked back as `granularity`.
+        windowGranularity = 'auto';
       } else if (timeRange) {
         startISO = new Date(
           timeRange.start.year, timeRange.start.month - 1, timeRange.start.day,
@@ -145,6 +152,8 @@ export const useExploreHistogramStore = defineStore("exploreHistogram", () => {
         group_by: state.value.groupByField === "__none__" || state.value.groupByField === null
           ? undefined
           : state.value.groupByField,
+        // No explicit choice yet: let the server stack series by severity
+        severity_breakdown: state.value.groupByField === null ? true : undefined,
         query_timeout: queryTimeout,
         variables: variables.length > 0 ? variables : undefined,
       };
@@ -161,12 +170,14 @@ export const useExploreHistogramStore = defineStore("exploreHistogram", () => {
         state.value.data = response.data.data || [];
         state.value.granularity = response.data.granularity || null;
         state.value.notice = response.data.notice || null;
+        state.value.groupedBy = response.data.group_by || null;
         state.value.error = null;
         return { success: true, data: response.data };
       } else {
         state.value.data = [];
         state.value.granularity = null;
         state.value.notice = null;
+        state.value.groupedBy = null;
         state.value.error = "Failed to fetch histogram data";
         return { success: false, error: { message: "Failed to fetch histogram data" } };
       }
@@ -196,6 +207,7 @@ export const useExploreHistogramStore = defineStore("exploreHistogram", () => {
     histogramError,
     histogramNotice,
     histogramGranularity,
+    histogramGroupedBy,
     groupByField,
 
     clearHistogramData,
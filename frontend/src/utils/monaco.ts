@@ -428,7 +428,7 @@ function registerLogchefQL() {
     ],
 
     // Keywords (boolean operators)
-    keywords: ['and', 'or'],
+    keywords: ['and', 'or', 'not'],
 
     // The main tokenizer
     tokenizer: {
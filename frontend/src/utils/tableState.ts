@@ -0,0 +1,32 @@
+import type { ColumnSizingState, VisibilityState } from '@tanstack/vue-table'
+
+// Column layout of the explorer table, persisted per team and source.
+export interface DataTableState {
+  columnOrder: string[]
+  columnSizing: ColumnSizingState
+  columnVisibility: VisibilityState
+}
+
+export function tableStateKey(teamId: number, sourceId: number | string): string {
+  return `logchef-tableState-${teamId}-${sourceId}`
+}
+
+export function loadTableState(teamId: number, sourceId: number | string): DataTableState | null {
+  try {
+    const stored = localStorage.getItem(tableStateKey(teamId, sourceId))
+    return stored ? (JSON.parse(stored) as DataTableState) : null
+  } catch (error) {
+    console.error('Error loading table state from localStorage:', error)
+    return null
+  }
+}
+
+/**
+ * Returns the visible columns of the explorer table in display order, or an
+ * empty list when the user hasn't customised the layout.
+ */
+export function visibleTableColumns(teamId: number, sourceId: number | string): string[] {
+  const state = loadTableState(teamId, sourceId)
+  if (!state?.columnOrder?.length) return []
+  return state.columnOrder.filter(id => state.columnVisibility?.[id] !== false)
+}
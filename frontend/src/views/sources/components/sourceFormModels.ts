@@ -20,6 +20,8 @@ export interface ClickHouseSettingsFormState {
   maxResultBytes: string;
   maxRowsToRead: string;
   maxBytesToRead: string;
+  maxThreads: string;
+  maxMemoryUsage: string;
   readonly: string;
   resultOverflowMode: string;
 }
@@ -85,6 +87,8 @@ export function createDefaultClickHouseSettingsState(): ClickHouseSettingsFormSt
     maxResultBytes: "",
     maxRowsToRead: "",
     maxBytesToRead: "",
+    maxThreads: "",
+    maxMemoryUsage: "",
     readonly: "",
     resultOverflowMode: "",
   };
@@ -181,6 +185,14 @@ export function buildClickHouseSettings(
   if (maxBytesToRead !== undefined) {
     settings.max_bytes_to_read = maxBytesToRead;
   }
+  const maxThreads = parseNonNegativeSetting(state.maxThreads);
+  if (maxThreads !== undefined) {
+    settings.max_threads = maxThreads;
+  }
+  const maxMemoryUsage = parseNonNegativeSetting(state.maxMemoryUsage);
+  if (maxMemoryUsage !== undefined) {
+    settings.max_memory_usage = maxMemoryUsage;
+  }
 
   const readonly = parseNonNegativeSetting(state.readonly);
   if (readonly !== undefined) {
@@ -293,6 +305,12 @@ function clickHouseSettingsStateFromConnection(
   if (settings.max_bytes_to_read !== undefined && settings.max_bytes_to_read !== null) {
     state.maxBytesToRead = String(settings.max_bytes_to_read);
   }
+  if (settings.max_threads !== undefined && settings.max_threads !== null) {
+    state.maxThreads = String(settings.max_threads);
+  }
+  if (settings.max_memory_usage !== undefined && settings.max_memory_usage !== null) {
+    state.maxMemoryUsage = String(settings.max_memory_usage);
+  }
   if (settings.readonly !== undefined && settings.readonly !== null) {
     state.readonly = String(settings.readonly);
   }
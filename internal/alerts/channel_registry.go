@@ -0,0 +1,140 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+const (
+	defaultDeliveryAttempts = 3
+	defaultInitialBackoff   = time.Second
+	defaultMaxBackoff       = 10 * time.Second
+)
+
+// ChannelRegistryOptions configures delivery retries. Zero values use the
+// defaults (3 attempts, backoff doubling from 1s up to 10s).
+type ChannelRegistryOptions struct {
+	// Attempts is how many times a failing channel is tried per notification.
+	Attempts       int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Logger         *slog.Logger
+}
+
+// ChannelRegistry is the AlertSender that routes each of a notification's
+// channels to the ChannelSender registered for its type. Channels are
+// delivered concurrently; a failing channel is retried with exponential
+// backoff without holding up the others.
+type ChannelRegistry struct {
+	senders        map[models.AlertChannelType]ChannelSender
+	attempts       int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	logger         *slog.Logger
+
+	// sleep waits between attempts; tests replace it to skip the backoff.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewChannelRegistry creates a registry with no channel senders registered.
+func NewChannelRegistry(opts ChannelRegistryOptions) *ChannelRegistry {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	r := &ChannelRegistry{
+		senders:        make(map[models.AlertChannelType]ChannelSender),
+		attempts:       opts.Attempts,
+		initialBackoff: opts.InitialBackoff,
+		maxBackoff:     opts.MaxBackoff,
+		logger:         logger.With("component", "alert_channel_registry"),
+		sleep:          sleepContext,
+	}
+	if r.attempts <= 0 {
+		r.attempts = defaultDeliveryAttempts
+	}
+	if r.initialBackoff <= 0 {
+		r.initialBackoff = defaultInitialBackoff
+	}
+	if r.maxBackoff < r.initialBackoff {
+		r.maxBackoff = max(defaultMaxBackoff, r.initialBackoff)
+	}
+	return r
+}
+
+// Register routes channels of the given type to sender.
+func (r *ChannelRegistry) Register(channelType models.AlertChannelType, sender ChannelSender) {
+	r.senders[channelType] = sender
+}
+
+// Deliver sends the notification to each of its channels and returns one
+// result per channel, in channel order.
+func (r *ChannelRegistry) Deliver(ctx context.Context, notification AlertNotification) []models.AlertChannelDelivery {
+	if len(notification.Channels) == 0 {
+		return nil
+	}
+	results := make([]models.AlertChannelDelivery, len(notification.Channels))
+	var wg sync.WaitGroup
+	for i, channel := range notification.Channels {
+		wg.Go(func() {
+			results[i] = r.deliverChannel(ctx, channel, notification)
+		})
+	}
+	wg.Wait()
+	return results
+}
+
+func (r *ChannelRegistry) deliverChannel(ctx context.Context, channel models.AlertChannel, notification AlertNotification) models.AlertChannelDelivery {
+	result := models.AlertChannelDelivery{Channel: channel.Key(), Type: channel.Type}
+
+	sender, ok := r.senders[channel.Type]
+	if !ok {
+		result.Status = models.AlertDeliveryFailed
+		result.Error = fmt.Sprintf("no sender registered for channel type %q", channel.Type)
+		result.At = time.Now().UTC()
+		return result
+	}
+
+	backoff := r.initialBackoff
+	var err error
+	for attempt := 1; attempt <= r.attempts; attempt++ {
+		result.Attempts = attempt
+		if err = sender.Send(ctx, channel, notification); err == nil {
+			break
+		}
+		if attempt == r.attempts {
+			break
+		}
+		r.logger.Debug("alert channel delivery failed, retrying",
+			"alert_id", notification.AlertID, "channel_type", channel.Type, "attempt", attempt, "backoff", backoff, "error", err)
+		if r.sleep(ctx, backoff) != nil {
+			break
+		}
+		backoff = min(backoff*2, r.maxBackoff)
+	}
+
+	result.At = time.Now().UTC()
+	if err != nil {
+		result.Status = models.AlertDeliveryFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = models.AlertDeliveryDelivered
+	return result
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
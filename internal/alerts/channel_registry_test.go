@@ -0,0 +1,182 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// flakySender fails the first failures sends per channel, then succeeds.
+type flakySender struct {
+	mu       sync.Mutex
+	failures int
+	calls    map[string]int
+}
+
+func (f *flakySender) Send(_ context.Context, channel models.AlertChannel, _ AlertNotification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[channel.Key()]++
+	if f.calls[channel.Key()] <= f.failures {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func TestChannelRegistryRetriesWithBackoff(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var waits []time.Duration
+	r := NewChannelRegistry(ChannelRegistryOptions{Attempts: 4, InitialBackoff: time.Second, MaxBackoff: 3 * time.Second})
+	r.sleep = func(_ context.Context, d time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		waits = append(waits, d)
+		return nil
+	}
+	r.Register(models.AlertChannelWebhook, &flakySender{failures: 3})
+	r.Register(models.AlertChannelSlack, &flakySender{failures: 10})
+
+	results := r.Deliver(context.Background(), AlertNotification{Channels: []models.AlertChannel{
+		{Type: models.AlertChannelWebhook, URL: "https://example.com/a"},
+		{Type: models.AlertChannelSlack, URL: "https://hooks.slack.com/x"},
+		{Type: models.AlertChannelEmail, Emails: []string{"a@example.com"}},
+	}})
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+
+	if got := results[0]; got.Status != models.AlertDeliveryDelivered || got.Attempts != 4 || got.Channel != "webhook:https://example.com/a" {
+		t.Errorf("webhook result = %+v, want delivered on attempt 4", got)
+	}
+	if got := results[1]; got.Status != models.AlertDeliveryFailed || got.Attempts != 4 || got.Error != "unavailable" {
+		t.Errorf("slack result = %+v, want failed after 4 attempts", got)
+	}
+	if got := results[2]; got.Status != models.AlertDeliveryFailed || got.Attempts != 0 {
+		t.Errorf("email result = %+v, want failed with no sender registered", got)
+	}
+
+	// Each failing channel waits 1s, 2s, then is capped at 3s.
+	counts := map[time.Duration]int{}
+	for _, w := range waits {
+		counts[w]++
+	}
+	if len(waits) != 6 || counts[time.Second] != 2 || counts[2*time.Second] != 2 || counts[3*time.Second] != 2 {
+		t.Errorf("backoff waits = %v", waits)
+	}
+}
+
+func TestWebhookSenderChannels(t *testing.T) {
+	t.Parallel()
+
+	type request struct {
+		path, contentType, auth string
+		body                    []byte
+	}
+	requests := make(chan request, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("Authorization"), body}
+		if r.URL.Path == "/fail" {
+			http.Error(w, "nope", http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	sender := NewWebhookSender(WebhookSenderOptions{Timeout: time.Second})
+	notification := AlertNotification{
+		AlertName: `disk "full"`,
+		Status:    models.AlertStatusTriggered,
+		Severity:  models.AlertSeverityCritical,
+		Value:     97.5,
+		Labels:    map[string]string{"host": "db-1"},
+	}
+	ctx := context.Background()
+
+	err := sender.SendChannel(ctx, models.AlertChannel{
+		Type:     models.AlertChannelWebhook,
+		URL:      srv.URL + "/tmpl",
+		Template: `{"title":{{json .AlertName}},"host":{{json (index .Labels "host")}},"value":{{.Value}}}`,
+		Headers:  map[string]string{"Authorization": "Bearer t"},
+	}, notification)
+	if err != nil {
+		t.Fatalf("SendChannel(template): %v", err)
+	}
+	got := <-requests
+	var decoded map[string]any
+	if err := json.Unmarshal(got.body, &decoded); err != nil {
+		t.Fatalf("templated body is not JSON: %s", got.body)
+	}
+	if decoded["title"] != `disk "full"` || decoded["host"] != "db-1" || decoded["value"] != 97.5 || got.auth != "Bearer t" {
+		t.Errorf("unexpected templated request: %+v body=%s", got, got.body)
+	}
+
+	if err := sender.SendSlack(ctx, srv.URL+"/slack", notification); err != nil {
+		t.Fatalf("SendSlack: %v", err)
+	}
+	got = <-requests
+	var slack slackMessage
+	if err := json.Unmarshal(got.body, &slack); err != nil || len(slack.Attachments) != 1 {
+		t.Fatalf("unexpected slack body: %s", got.body)
+	}
+	if slack.Text != `[TRIGGERED] disk "full"` || slack.Attachments[0].Color != "danger" {
+		t.Errorf("unexpected slack message: %+v", slack)
+	}
+
+	err = sender.SendChannel(ctx, models.AlertChannel{Type: models.AlertChannelWebhook, URL: srv.URL + "/fail"}, notification)
+	if err == nil {
+		t.Fatal("expected a non-2xx response to fail delivery")
+	}
+	<-requests
+}
+
+func TestNotificationChannelsAndRetrySkips(t *testing.T) {
+	t.Parallel()
+
+	alert := &models.Alert{
+		RecipientUserIDs: []models.UserID{1},
+		WebhookURLs:      []string{"https://example.com/a"},
+		Channels: []models.AlertChannel{
+			{Type: models.AlertChannelWebhook, URL: "https://example.com/a"},
+			{Type: models.AlertChannelSlack, URL: "https://hooks.slack.com/x"},
+		},
+	}
+	channels := notificationChannels(alert, []string{"a@example.com"})
+	var keys []string
+	for _, c := range channels {
+		keys = append(keys, c.Key())
+	}
+	want := []string{"email:a@example.com", "webhook:https://example.com/a", "slack:https://hooks.slack.com/x"}
+	if len(keys) != len(want) {
+		t.Fatalf("channels = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("channel %d = %q, want %q", i, keys[i], want[i])
+		}
+	}
+
+	// History payloads come back from the store as decoded JSON.
+	payload := map[string]any{}
+	raw, _ := json.Marshal(map[string]any{"deliveries": []models.AlertChannelDelivery{
+		{Channel: want[0], Status: models.AlertDeliveryDelivered},
+		{Channel: want[2], Status: models.AlertDeliveryFailed},
+	}})
+	_ = json.Unmarshal(raw, &payload)
+	delivered := successfulDeliveries(payload)
+	if len(delivered) != 1 || delivered[0].Channel != want[0] {
+		t.Errorf("successfulDeliveries = %+v, want only %q", delivered, want[0])
+	}
+}
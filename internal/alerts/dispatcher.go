@@ -0,0 +1,254 @@
+package alerts
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// defaultRoomMaxPending caps the notifications buffered for one room; beyond
+// it the oldest are dropped.
+const defaultRoomMaxPending = 500
+
+// GroupSender delivers several notifications to one channel as a single
+// payload.
+type GroupSender interface {
+	SendGroup(ctx context.Context, channel models.AlertChannel, notifications []AlertNotification) error
+}
+
+// DispatcherOptions configures notification grouping.
+type DispatcherOptions struct {
+	// FlushInterval is how long notifications for a room are buffered before
+	// they go out together.
+	FlushInterval time.Duration
+	// RoomMaxPerHour caps the payloads one room receives per hour. A room at
+	// its limit keeps buffering until it may be sent to again. 0 = no limit.
+	RoomMaxPerHour int
+	// RoomMaxPending caps the notifications buffered per room (default 500).
+	RoomMaxPending int
+	Logger         *slog.Logger
+}
+
+// Dispatcher is an AlertSender that groups notifications per room. A room is
+// one webhook or Slack destination: notifications bound for it are buffered
+// and delivered as one grouped payload every FlushInterval, within the room's
+// rate limit. Email channels and webhooks with their own template can't be
+// grouped and go straight to the next sender.
+//
+// A buffered channel is reported as queued; a grouped delivery that still
+// fails after its retries is logged, since the alert's history entry was
+// written when the notification was queued.
+type Dispatcher struct {
+	next       AlertSender
+	group      GroupSender
+	interval   time.Duration
+	maxPerHour int
+	maxPending int
+	logger     *slog.Logger
+
+	// sleep waits between attempts; tests replace it to skip the backoff.
+	sleep func(ctx context.Context, d time.Duration) error
+
+	mu    sync.Mutex
+	rooms map[string]*room
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// room is one destination's buffer and its recent sends.
+type room struct {
+	channel models.AlertChannel
+	pending []AlertNotification
+	sent    []time.Time
+}
+
+// NewDispatcher creates a dispatcher that groups webhook and Slack channels
+// through group and hands every other channel to next.
+func NewDispatcher(next AlertSender, group GroupSender, opts DispatcherOptions) *Dispatcher {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	interval := opts.FlushInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	maxPending := opts.RoomMaxPending
+	if maxPending <= 0 {
+		maxPending = defaultRoomMaxPending
+	}
+	return &Dispatcher{
+		next:       next,
+		group:      group,
+		interval:   interval,
+		maxPerHour: max(opts.RoomMaxPerHour, 0),
+		maxPending: maxPending,
+		logger:     logger.With("component", "alert_dispatcher"),
+		sleep:      sleepContext,
+		rooms:      make(map[string]*room),
+		stop:       make(chan struct{}),
+	}
+}
+
+// groupable reports whether a channel's notifications can share a payload.
+func groupable(channel models.AlertChannel) bool {
+	switch channel.Type {
+	case models.AlertChannelSlack:
+		return true
+	case models.AlertChannelWebhook:
+		return channel.Template == ""
+	default:
+		return false
+	}
+}
+
+// Deliver buffers the notification for each of its groupable channels and
+// delivers the rest through the next sender. Results are in channel order.
+func (d *Dispatcher) Deliver(ctx context.Context, notification AlertNotification) []models.AlertChannelDelivery {
+	if len(notification.Channels) == 0 {
+		return nil
+	}
+	results := make([]models.AlertChannelDelivery, len(notification.Channels))
+	var direct []models.AlertChannel
+	var directIdx []int
+	now := time.Now().UTC()
+	for i, channel := range notification.Channels {
+		if !groupable(channel) {
+			direct = append(direct, channel)
+			directIdx = append(directIdx, i)
+			continue
+		}
+		d.enqueue(channel, notification)
+		results[i] = models.AlertChannelDelivery{Channel: channel.Key(), Type: channel.Type, Status: models.AlertDeliveryQueued, At: now}
+	}
+	if len(direct) > 0 {
+		rest := notification
+		rest.Channels = direct
+		for j, result := range d.next.Deliver(ctx, rest) {
+			results[directIdx[j]] = result
+		}
+	}
+	return results
+}
+
+func (d *Dispatcher) enqueue(channel models.AlertChannel, notification AlertNotification) {
+	notification.Channels = nil
+	key := channel.Key()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	r, ok := d.rooms[key]
+	if !ok {
+		r = &room{channel: channel}
+		d.rooms[key] = r
+	}
+	r.pending = append(r.pending, notification)
+	if over := len(r.pending) - d.maxPending; over > 0 {
+		d.logger.Warn("alert room buffer full, dropping oldest notifications", "channel", key, "dropped", over)
+		r.pending = append(r.pending[:0], r.pending[over:]...)
+	}
+}
+
+// Start flushes the rooms every FlushInterval until Stop is called or ctx
+// ends.
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.wg.Go(func() {
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.flush(ctx, time.Now(), false)
+			case <-d.stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// Stop ends the flush loop and delivers whatever is still buffered,
+// regardless of rate limits, so no notification is lost on shutdown.
+func (d *Dispatcher) Stop() {
+	close(d.stop)
+	d.wg.Wait()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	d.flush(ctx, time.Now(), true)
+}
+
+// batch is one room's notifications taken for delivery.
+type batch struct {
+	channel       models.AlertChannel
+	notifications []AlertNotification
+}
+
+// flush delivers each room's buffer as one payload. Rooms at their hourly
+// limit are skipped unless force is set.
+func (d *Dispatcher) flush(ctx context.Context, now time.Time, force bool) {
+	batches := d.takeBatches(now, force)
+	var wg sync.WaitGroup
+	for _, b := range batches {
+		wg.Go(func() {
+			d.deliverBatch(ctx, b)
+		})
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) takeBatches(now time.Time, force bool) []batch {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var batches []batch
+	cutoff := now.Add(-time.Hour)
+	for key, r := range d.rooms {
+		recent := r.sent[:0]
+		for _, at := range r.sent {
+			if at.After(cutoff) {
+				recent = append(recent, at)
+			}
+		}
+		r.sent = recent
+		if len(r.pending) == 0 {
+			if len(r.sent) == 0 {
+				delete(d.rooms, key)
+			}
+			continue
+		}
+		if !force && d.maxPerHour > 0 && len(r.sent) >= d.maxPerHour {
+			d.logger.Debug("alert room rate limited, holding notifications", "channel", key, "pending", len(r.pending))
+			continue
+		}
+		batches = append(batches, batch{channel: r.channel, notifications: r.pending})
+		r.pending = nil
+		r.sent = append(r.sent, now)
+	}
+	return batches
+}
+
+// deliverBatch sends one room's payload, retrying with the same backoff as
+// ChannelRegistry.
+func (d *Dispatcher) deliverBatch(ctx context.Context, b batch) {
+	backoff := defaultInitialBackoff
+	var err error
+	for attempt := 1; attempt <= defaultDeliveryAttempts; attempt++ {
+		if err = d.group.SendGroup(ctx, b.channel, b.notifications); err == nil {
+			return
+		}
+		if attempt == defaultDeliveryAttempts || d.sleep(ctx, backoff) != nil {
+			break
+		}
+		backoff = min(backoff*2, defaultMaxBackoff)
+	}
+	alertIDs := make([]models.AlertID, len(b.notifications))
+	for i, n := range b.notifications {
+		alertIDs[i] = n.AlertID
+	}
+	d.logger.Error("grouped alert notification failed",
+		"channel", b.channel.Key(), "alert_ids", alertIDs, "attempts", defaultDeliveryAttempts, "error", err)
+}
@@ -0,0 +1,168 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// recordingGroupSender records each grouped send, failing the first failures.
+type recordingGroupSender struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	sent     map[string][][]models.AlertID
+}
+
+func (r *recordingGroupSender) SendGroup(_ context.Context, channel models.AlertChannel, notifications []AlertNotification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	if r.calls <= r.failures {
+		return errors.New("unavailable")
+	}
+	if r.sent == nil {
+		r.sent = make(map[string][][]models.AlertID)
+	}
+	ids := make([]models.AlertID, len(notifications))
+	for i, n := range notifications {
+		ids[i] = n.AlertID
+	}
+	r.sent[channel.Key()] = append(r.sent[channel.Key()], ids)
+	return nil
+}
+
+// recordingNext records the channels handed to the next sender.
+type recordingNext struct {
+	channels []models.AlertChannel
+}
+
+func (r *recordingNext) Deliver(_ context.Context, n AlertNotification) []models.AlertChannelDelivery {
+	r.channels = append(r.channels, n.Channels...)
+	results := make([]models.AlertChannelDelivery, len(n.Channels))
+	for i, channel := range n.Channels {
+		results[i] = models.AlertChannelDelivery{Channel: channel.Key(), Type: channel.Type, Status: models.AlertDeliveryDelivered, Attempts: 1}
+	}
+	return results
+}
+
+func TestDispatcherGroupsPerRoom(t *testing.T) {
+	t.Parallel()
+
+	slack := models.AlertChannel{Type: models.AlertChannelSlack, URL: "https://hooks.slack.com/ops"}
+	hook := models.AlertChannel{Type: models.AlertChannelWebhook, URL: "https://example.com/hook"}
+	templated := models.AlertChannel{Type: models.AlertChannelWebhook, URL: "https://example.com/t", Template: `{"a":"{{.AlertName}}"}`}
+	email := models.AlertChannel{Type: models.AlertChannelEmail, Emails: []string{"a@example.com"}}
+
+	next := &recordingNext{}
+	group := &recordingGroupSender{}
+	d := NewDispatcher(next, group, DispatcherOptions{FlushInterval: time.Minute})
+	ctx := context.Background()
+
+	results := d.Deliver(ctx, AlertNotification{AlertID: 1, Channels: []models.AlertChannel{slack, email, hook, templated}})
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+	for i, want := range []models.AlertDeliveryStatus{models.AlertDeliveryQueued, models.AlertDeliveryDelivered, models.AlertDeliveryQueued, models.AlertDeliveryDelivered} {
+		if results[i].Status != want {
+			t.Errorf("results[%d] = %+v, want %s", i, results[i], want)
+		}
+	}
+	if len(next.channels) != 2 || next.channels[0].Key() != email.Key() || next.channels[1].Key() != templated.Key() {
+		t.Errorf("next sender got %+v, want the email and templated webhook channels", next.channels)
+	}
+
+	d.Deliver(ctx, AlertNotification{AlertID: 2, Channels: []models.AlertChannel{slack}})
+	d.Deliver(ctx, AlertNotification{AlertID: 3, Channels: []models.AlertChannel{slack, hook}})
+	d.flush(ctx, time.Now(), false)
+
+	if got := group.sent[slack.Key()]; len(got) != 1 || len(got[0]) != 3 {
+		t.Errorf("slack room got %v, want one payload with alerts 1, 2 and 3", got)
+	}
+	if got := group.sent[hook.Key()]; len(got) != 1 || len(got[0]) != 2 {
+		t.Errorf("webhook room got %v, want one payload with alerts 1 and 3", got)
+	}
+
+	// Nothing buffered, nothing sent.
+	d.flush(ctx, time.Now(), false)
+	if group.calls != 2 {
+		t.Errorf("empty flush sent %d payloads, want none beyond the first 2", group.calls-2)
+	}
+}
+
+func TestDispatcherRateLimitsRooms(t *testing.T) {
+	t.Parallel()
+
+	room := models.AlertChannel{Type: models.AlertChannelWebhook, URL: "https://example.com/hook"}
+	group := &recordingGroupSender{}
+	d := NewDispatcher(&recordingNext{}, group, DispatcherOptions{FlushInterval: time.Minute, RoomMaxPerHour: 2})
+	ctx := context.Background()
+	start := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+
+	for i := range 3 {
+		d.Deliver(ctx, AlertNotification{AlertID: models.AlertID(i + 1), Channels: []models.AlertChannel{room}})
+		d.flush(ctx, start.Add(time.Duration(i)*time.Minute), false)
+	}
+	if got := group.sent[room.Key()]; len(got) != 2 {
+		t.Fatalf("room got %d payloads in the first hour, want 2", len(got))
+	}
+
+	// The held notification goes out once the first send leaves the window.
+	d.Deliver(ctx, AlertNotification{AlertID: 4, Channels: []models.AlertChannel{room}})
+	d.flush(ctx, start.Add(61*time.Minute), false)
+	got := group.sent[room.Key()]
+	if len(got) != 3 || len(got[2]) != 2 || got[2][0] != 3 || got[2][1] != 4 {
+		t.Errorf("room payloads = %v, want a third with alerts 3 and 4", got)
+	}
+}
+
+func TestDispatcherRetriesAndStopFlushes(t *testing.T) {
+	t.Parallel()
+
+	room := models.AlertChannel{Type: models.AlertChannelSlack, URL: "https://hooks.slack.com/ops"}
+	group := &recordingGroupSender{failures: 2}
+	d := NewDispatcher(&recordingNext{}, group, DispatcherOptions{FlushInterval: time.Hour, RoomMaxPerHour: 1})
+	d.sleep = func(context.Context, time.Duration) error { return nil }
+	d.Start(context.Background())
+
+	d.Deliver(context.Background(), AlertNotification{AlertID: 7, Channels: []models.AlertChannel{room}})
+	d.Stop()
+	if group.calls != 3 || len(group.sent[room.Key()]) != 1 {
+		t.Errorf("calls = %d, sent = %v; want delivery on the third attempt at Stop", group.calls, group.sent)
+	}
+}
+
+func TestWebhookSenderSendGroup(t *testing.T) {
+	t.Parallel()
+
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	sender := NewWebhookSender(WebhookSenderOptions{})
+	err := sender.SendGroup(context.Background(), models.AlertChannel{Type: models.AlertChannelWebhook, URL: srv.URL}, []AlertNotification{
+		{AlertID: 1, AlertName: "a", Status: models.AlertStatusResolved},
+		{AlertID: 2, AlertName: "b", Status: models.AlertStatusTriggered},
+	})
+	if err != nil {
+		t.Fatalf("SendGroup: %v", err)
+	}
+	var payload webhookGroupPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Status != "triggered" || payload.Count != 2 || len(payload.Alerts) != 2 || payload.Alerts[1].AlertName != "b" {
+		t.Errorf("payload = %+v", payload)
+	}
+}
@@ -2,8 +2,11 @@ package alerts
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
 )
 
 type SettingsReader interface {
@@ -28,7 +31,17 @@ func NewDynamicEmailSender(settings SettingsReader, logger *slog.Logger) *Dynami
 	}
 }
 
-func (d *DynamicEmailSender) Send(ctx context.Context, notification AlertNotification) error {
+// Send emails the notification to the channel's addresses, using the SMTP
+// settings current at delivery time.
+func (d *DynamicEmailSender) Send(ctx context.Context, channel models.AlertChannel, notification AlertNotification) error {
+	if len(channel.Emails) == 0 {
+		return fmt.Errorf("no valid email recipients resolved")
+	}
+	notification.RecipientEmails = channel.Emails
+	notification.RecipientUserIDs = nil
+	notification.MissingRecipientUserIDs = nil
+	notification.RecipientResolutionErr = ""
+
 	opts := EmailSenderOptions{
 		Host:          d.settings.GetSettingWithDefault(ctx, "alerts.smtp_host", ""),
 		Port:          d.settings.GetIntSetting(ctx, "alerts.smtp_port", 587),
@@ -2,8 +2,11 @@ package alerts
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
 )
 
 type DynamicWebhookSender struct {
@@ -21,12 +24,32 @@ func NewDynamicWebhookSender(settings SettingsReader, logger *slog.Logger) *Dyna
 	}
 }
 
-func (d *DynamicWebhookSender) Send(ctx context.Context, notification AlertNotification) error {
-	opts := WebhookSenderOptions{
+// sender builds a webhook sender with the HTTP settings current at delivery
+// time.
+func (d *DynamicWebhookSender) sender(ctx context.Context) *WebhookSender {
+	return NewWebhookSender(WebhookSenderOptions{
 		Timeout:       d.settings.GetDurationSetting(ctx, "alerts.request_timeout", 5*time.Second),
 		SkipTLSVerify: d.settings.GetBoolSetting(ctx, "alerts.tls_insecure_skip_verify", false),
 		Logger:        d.logger,
+	})
+}
+
+// Send posts the notification to a webhook or Slack channel, using the HTTP
+// settings current at delivery time.
+func (d *DynamicWebhookSender) Send(ctx context.Context, channel models.AlertChannel, notification AlertNotification) error {
+	sender := d.sender(ctx)
+	switch channel.Type {
+	case models.AlertChannelSlack:
+		return sender.SendSlack(ctx, channel.URL, notification)
+	case models.AlertChannelWebhook:
+		return sender.SendChannel(ctx, channel, notification)
+	default:
+		return fmt.Errorf("unsupported channel type %q", channel.Type)
 	}
-	sender := NewWebhookSender(opts)
-	return sender.Send(ctx, notification)
+}
+
+// SendGroup posts several notifications to a webhook or Slack channel as one
+// payload, using the HTTP settings current at delivery time.
+func (d *DynamicWebhookSender) SendGroup(ctx context.Context, channel models.AlertChannel, notifications []AlertNotification) error {
+	return d.sender(ctx).SendGroup(ctx, channel, notifications)
 }
@@ -2,10 +2,13 @@ package alerts
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"maps"
 	"math"
+	"os"
 	"strconv"
 	"strings"
 	"sync"
@@ -13,6 +16,8 @@ import (
 
 	"github.com/mr-karan/logchef/internal/config"
 	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/events"
+	"github.com/mr-karan/logchef/internal/metrics"
 	"github.com/mr-karan/logchef/internal/store"
 	"github.com/mr-karan/logchef/internal/util"
 	"github.com/mr-karan/logchef/pkg/models"
@@ -32,6 +37,17 @@ type Options struct {
 	Datasources *datasource.Service
 	Logger      *slog.Logger
 	Sender      AlertSender
+	// BurnRates evaluates burn-rate alerts (those with an SLOID).
+	BurnRates BurnRateSource
+	// Instance names this process in alert evaluation leases. Empty uses the
+	// hostname and process ID.
+	Instance string
+}
+
+// BurnRateSource computes an SLO's error-budget burn rate over a lookback
+// window. *slo.Manager implements it.
+type BurnRateSource interface {
+	BurnRate(ctx context.Context, id models.SLOID, lookbackSeconds int) (float64, error)
 }
 
 // Manager coordinates alert evaluation and dispatches notifications when thresholds are met.
@@ -41,6 +57,12 @@ type Manager struct {
 	datasource *datasource.Service
 	log        *slog.Logger
 	sender     AlertSender
+	burnRates  BurnRateSource
+
+	// instance holds this process's evaluation leases, so replicas sharing a
+	// database don't evaluate, and notify for, the same alert. Empty
+	// evaluates every due alert without leasing.
+	instance string
 
 	// evalTimeout bounds a single alert's evaluation; evalFn is the function
 	// invoked per alert. Both are seams so the per-alert timeout isolation can
@@ -58,12 +80,18 @@ func NewManager(opts Options) *Manager {
 	if sender == nil {
 		sender = noopSender{}
 	}
+	instance := opts.Instance
+	if instance == "" {
+		instance = defaultInstance()
+	}
 	m := &Manager{
 		cfg:         opts.Config,
 		db:          opts.DB,
 		datasource:  opts.Datasources,
 		log:         opts.Logger.With("component", "alert_manager"),
 		sender:      sender,
+		burnRates:   opts.BurnRates,
+		instance:    instance,
 		evalTimeout: alertEvaluationTimeout,
 		stop:        make(chan struct{}),
 	}
@@ -105,10 +133,30 @@ func (m *Manager) Start(ctx context.Context) {
 	})
 }
 
-// Stop signals the manager to stop evaluating alerts.
+// Stop signals the manager to stop evaluating alerts, waits for a running
+// cycle to finish and releases this instance's leases, so another replica
+// takes over its alerts without waiting for them to expire.
 func (m *Manager) Stop() {
 	close(m.stop)
 	m.wg.Wait()
+	if !m.cfg.Enabled || m.instance == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.db.ReleaseAlertLeases(ctx, m.instance); err != nil {
+		m.log.Warn("failed to release alert leases", "error", err)
+	}
+}
+
+// defaultInstance names this process by hostname and process ID, so two
+// processes on one host hold separate leases.
+func defaultInstance() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "logchef"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
 }
 
 func (m *Manager) evaluateCycle(ctx context.Context) {
@@ -122,12 +170,39 @@ func (m *Manager) evaluateCycle(ctx context.Context) {
 	}
 
 	for _, alert := range alerts {
+		if !m.acquireLease(ctx, alert) {
+			continue
+		}
 		if err := m.evaluateAlertWithTimeout(ctx, alert); err != nil {
 			m.log.Error("alert evaluation failed", "alert_id", alert.ID, "error", err)
 		}
 	}
 }
 
+// acquireLease reports whether this instance may evaluate alert now. The lease
+// is kept, not released, after the evaluation: it lasts at least the alert's
+// frequency, so a replica that listed the alert as due before this evaluation
+// was recorded can't evaluate it a second time. While this instance keeps
+// renewing, it stays the alert's only evaluator; once it stops, another
+// replica takes over when the lease expires. A failed claim skips the alert,
+// since a missed cycle is better than a duplicate notification.
+func (m *Manager) acquireLease(ctx context.Context, alert *models.Alert) bool {
+	if m.instance == "" {
+		return true
+	}
+	ttl := max(time.Duration(alert.FrequencySeconds)*time.Second, m.evalTimeout)
+	now := time.Now()
+	ok, err := m.db.AcquireAlertLease(ctx, alert.ID, m.instance, now, now.Add(ttl))
+	if err != nil {
+		m.log.Error("failed to acquire alert lease", "alert_id", alert.ID, "error", err)
+		return false
+	}
+	if !ok {
+		m.log.Debug("alert leased by another instance", "alert_id", alert.ID)
+	}
+	return ok
+}
+
 // evaluateAlertWithTimeout bounds a single alert's evaluation with its own
 // deadline. The manager runs on the process-lifetime context, so without this
 // a source whose endpoint wedges (stalled socket) would block the sequential
@@ -140,18 +215,30 @@ func (m *Manager) evaluateAlertWithTimeout(ctx context.Context, alert *models.Al
 	}
 	alertCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
-	return m.evalFn(alertCtx, alert)
+
+	start := time.Now()
+	err := m.evalFn(alertCtx, alert)
+	kind := "query"
+	if alert.SLOID != nil {
+		kind = "burn_rate"
+	}
+	result := "success"
+	switch {
+	case err != nil && errors.Is(alertCtx.Err(), context.DeadlineExceeded):
+		result = "timeout"
+	case err != nil:
+		result = "failure"
+	}
+	metrics.RecordAlertEvaluation(kind, result, time.Since(start))
+	return err
 }
 
 func (m *Manager) evaluateAlert(ctx context.Context, alert *models.Alert) error {
 	if alert == nil {
 		return nil
 	}
-
-	query := strings.TrimSpace(alert.Query)
-	if query == "" {
-		m.log.Warn("alert query is empty; skipping evaluation", "alert_id", alert.ID, "query_language", alert.QueryLanguage, "editor_mode", alert.EditorMode)
-		return nil
+	if alert.SLOID != nil {
+		return m.evaluateBurnRateAlert(ctx, alert)
 	}
 
 	if m.datasource == nil {
@@ -160,13 +247,21 @@ func (m *Manager) evaluateAlert(ctx context.Context, alert *models.Alert) error
 		return err
 	}
 
+	// Structured condition alerts are compiled here, on every evaluation, so
+	// their window always ends now; everything else runs the stored query.
+	req, err := m.datasource.ResolveAlertQuery(ctx, alert, time.Now())
+	if err != nil {
+		m.recordEvaluationError(ctx, alert, err)
+		return err
+	}
+	if req.Query == "" {
+		m.log.Warn("alert query is empty; skipping evaluation", "alert_id", alert.ID, "query_language", alert.QueryLanguage, "editor_mode", alert.EditorMode)
+		return nil
+	}
+
 	timeout := models.DefaultQueryTimeoutSeconds
-	result, err := m.datasource.EvaluateAlert(ctx, alert.SourceID, datasource.AlertQueryRequest{
-		Language:        alert.QueryLanguage,
-		Query:           query,
-		LookbackSeconds: alert.LookbackSeconds,
-		QueryTimeout:    &timeout,
-	})
+	req.QueryTimeout = &timeout
+	result, err := m.datasource.EvaluateAlert(ctx, alert.SourceID, req)
 	if err != nil {
 		m.recordEvaluationError(ctx, alert, fmt.Errorf("alert query failed: %w", err))
 		return fmt.Errorf("alert query failed: %w", err)
@@ -177,7 +272,28 @@ func (m *Manager) evaluateAlert(ctx context.Context, alert *models.Alert) error
 		m.recordEvaluationError(ctx, alert, fmt.Errorf("failed to extract alert result: %w", err))
 		return fmt.Errorf("failed to extract alert result: %w", err)
 	}
+	return m.applyValue(ctx, alert, value)
+}
+
+// evaluateBurnRateAlert takes the alert's value from its SLO's burn rate over
+// the alert's lookback instead of running a query.
+func (m *Manager) evaluateBurnRateAlert(ctx context.Context, alert *models.Alert) error {
+	if m.burnRates == nil {
+		err := fmt.Errorf("slo evaluation is not configured")
+		m.recordEvaluationError(ctx, alert, err)
+		return err
+	}
+	value, err := m.burnRates.BurnRate(ctx, *alert.SLOID, alert.LookbackSeconds)
+	if err != nil {
+		m.recordEvaluationError(ctx, alert, fmt.Errorf("slo burn rate failed: %w", err))
+		return fmt.Errorf("slo burn rate failed: %w", err)
+	}
+	return m.applyValue(ctx, alert, value)
+}
 
+// applyValue compares an evaluated value with the alert's threshold and
+// triggers or resolves the alert accordingly.
+func (m *Manager) applyValue(ctx context.Context, alert *models.Alert, value float64) error {
 	triggered := compareThreshold(value, alert.ThresholdValue, alert.ThresholdOperator)
 
 	m.log.Debug("alert evaluation complete",
@@ -189,11 +305,40 @@ func (m *Manager) evaluateAlert(ctx context.Context, alert *models.Alert) error
 		"triggered", triggered)
 
 	if triggered {
+		if m.holdPending(ctx, alert) {
+			return nil
+		}
 		return m.handleTriggered(ctx, alert, value)
 	}
 	return m.handleResolved(ctx, alert, value)
 }
 
+// holdPending records a breach of an alert with a for-duration that is not
+// firing yet, and reports whether the alert should stay pending rather than
+// fire. An alert already firing keeps firing without waiting again.
+func (m *Manager) holdPending(ctx context.Context, alert *models.Alert) bool {
+	if alert.ForSeconds <= 0 || alert.LastState == models.AlertStateFiring {
+		return false
+	}
+	pendingSince, breaches, err := m.db.MarkAlertPending(ctx, alert.ID)
+	if err != nil {
+		// Without the pending state there is nothing to wait on; fire as an
+		// alert without a for-duration would.
+		m.log.Error("failed to mark alert pending", "alert_id", alert.ID, "error", err)
+		return false
+	}
+	if time.Since(pendingSince) < time.Duration(alert.ForSeconds)*time.Second {
+		m.log.Debug("alert pending",
+			"alert_id", alert.ID,
+			"alert_name", alert.Name,
+			"pending_since", pendingSince,
+			"breach_count", breaches,
+			"for_seconds", alert.ForSeconds)
+		return true
+	}
+	return false
+}
+
 func (m *Manager) recordEvaluationError(ctx context.Context, alert *models.Alert, evalErr error) {
 	if alert == nil || evalErr == nil {
 		return
@@ -233,18 +378,37 @@ func (m *Manager) handleTriggered(ctx context.Context, alert *models.Alert, valu
 	}
 	alreadyActive := err == nil && prevHistory != nil
 
-	// Check if previous delivery failed - if so, we should retry
+	// Check if previous delivery failed - if so, we should retry the channels
+	// that didn't receive it.
 	shouldRetryDelivery := false
+	var delivered []models.AlertChannelDelivery
 	if alreadyActive && prevHistory.Payload != nil {
 		if deliveryFailed, ok := prevHistory.Payload["delivery_failed"].(bool); ok && deliveryFailed {
 			m.log.Debug("retrying alert delivery", "alert_id", alert.ID, "history_id", prevHistory.ID)
 			shouldRetryDelivery = true
+			delivered = successfulDeliveries(prevHistory.Payload)
 		}
 	}
 
 	if markErr := m.db.MarkAlertTriggered(ctx, alert.ID); markErr != nil {
 		m.log.Error("failed to mark alert triggered", "alert_id", alert.ID, "error", markErr)
 	}
+	if !alreadyActive {
+		publishAlertEvent(models.EventAlertFired, alert, value, fmt.Sprintf("alert %s triggered with value %.4f", alert.Name, value))
+	}
+
+	// A silenced alert still records that it fired, but nobody is notified.
+	// Once the silence ends, the held-back notification goes out like a retry.
+	if silence := m.activeSilence(ctx, alert, time.Now()); silence != nil {
+		if !alreadyActive {
+			m.recordSilencedTrigger(ctx, alert, value, silence)
+		}
+		return nil
+	}
+	if alreadyActive && notificationSilenced(prevHistory) {
+		shouldRetryDelivery = true
+		delivered = nil
+	}
 
 	// If already active and delivery succeeded previously, suppress duplicate notification
 	if alreadyActive && !shouldRetryDelivery {
@@ -265,12 +429,14 @@ func (m *Manager) handleTriggered(ctx context.Context, alert *models.Alert, valu
 	valueCopy := value
 	message := fmt.Sprintf("alert %s triggered with value %.4f", alert.Name, value)
 
+	var deliveries []models.AlertChannelDelivery
 	var deliveryErr error
 	var history *models.AlertHistoryEntry
 	if shouldRetryDelivery && prevHistory != nil {
 		// Retry on existing history entry - update it with new attempt
 		history = prevHistory
-		deliveryErr = m.sendNotification(ctx, alert, history, labels, annotations, models.AlertStatusTriggered, value)
+		deliveries, deliveryErr = m.sendNotification(ctx, alert, history, labels, annotations, models.AlertStatusTriggered, value, delivered)
+		deliveries = append(delivered, deliveries...)
 	} else {
 		// Create new history entry
 		now := time.Now().UTC()
@@ -281,7 +447,7 @@ func (m *Manager) handleTriggered(ctx context.Context, alert *models.Alert, valu
 			Value:       &valueCopy,
 			Message:     message,
 		}
-		deliveryErr = m.sendNotification(ctx, alert, history, labels, annotations, models.AlertStatusTriggered, value)
+		deliveries, deliveryErr = m.sendNotification(ctx, alert, history, labels, annotations, models.AlertStatusTriggered, value, nil)
 	}
 
 	// Record history with delivery status
@@ -291,6 +457,9 @@ func (m *Manager) handleTriggered(ctx context.Context, alert *models.Alert, valu
 		"status":          string(models.AlertStatusTriggered),
 		"delivery_failed": deliveryErr != nil,
 	}
+	if len(deliveries) > 0 {
+		historyPayload["deliveries"] = deliveries
+	}
 	if deliveryErr != nil {
 		historyPayload["delivery_error"] = deliveryErr.Error()
 		m.log.Warn("failed to send alert notifications", "alert_id", alert.ID, "error", deliveryErr)
@@ -318,6 +487,33 @@ func (m *Manager) handleTriggered(ctx context.Context, alert *models.Alert, valu
 	return nil
 }
 
+// recordSilencedTrigger records a trigger that a silence held back, marking
+// the history entry so the notification can be sent once the silence ends.
+func (m *Manager) recordSilencedTrigger(ctx context.Context, alert *models.Alert, value float64, silence *models.AlertSilence) {
+	m.log.Info("alert triggered while silenced",
+		"alert_id", alert.ID,
+		"alert_name", alert.Name,
+		"silence_id", silence.ID,
+		"value", value)
+
+	labels, annotations := m.buildAlertMetadata(ctx, alert, models.AlertStatusTriggered, value)
+	payload := map[string]any{
+		"labels":      copyStringMap(labels),
+		"annotations": copyStringMap(annotations),
+		"status":      string(models.AlertStatusTriggered),
+		"silenced":    true,
+		"silence_id":  int64(silence.ID),
+	}
+	message := fmt.Sprintf("alert %s triggered with value %.4f (silenced)", alert.Name, value)
+	if _, err := m.db.InsertAlertHistory(ctx, alert.ID, models.AlertStatusTriggered, &value, message, payload); err != nil {
+		m.log.Error("failed to insert alert history", "alert_id", alert.ID, "error", err)
+		return
+	}
+	if err := m.db.PruneAlertHistory(ctx, alert.ID, m.cfg.HistoryLimit); err != nil {
+		m.log.Warn("failed to prune alert history", "alert_id", alert.ID, "error", err)
+	}
+}
+
 func (m *Manager) handleResolved(ctx context.Context, alert *models.Alert, value float64) error {
 	if err := m.db.MarkAlertEvaluated(ctx, alert.ID); err != nil {
 		m.log.Error("failed to mark alert evaluated", "alert_id", alert.ID, "error", err)
@@ -344,6 +540,7 @@ func (m *Manager) handleResolved(ctx context.Context, alert *models.Alert, value
 		}
 		return fmt.Errorf("failed to resolve alert history: %w", err)
 	}
+	publishAlertEvent(models.EventAlertResolved, alert, value, message)
 
 	now := time.Now().UTC()
 	entry.Message = message
@@ -353,30 +550,47 @@ func (m *Manager) handleResolved(ctx context.Context, alert *models.Alert, value
 		entry.Value = &value
 	}
 
+	// Nobody heard about a trigger that was silenced throughout, and a
+	// resolution during a silence is held back like a trigger would be.
+	if notificationSilenced(entry) {
+		return nil
+	}
+	if silence := m.activeSilence(ctx, alert, now); silence != nil {
+		m.log.Debug("suppressing resolved notification for silenced alert", "alert_id", alert.ID, "silence_id", silence.ID)
+		return nil
+	}
+
 	labels, annotations := m.buildAlertMetadata(ctx, alert, models.AlertStatusResolved, value)
 	if annotations == nil {
 		annotations = make(map[string]string, 1)
 	}
 	annotations["resolved_at"] = now.Format(time.RFC3339Nano)
 
-	if sendErr := m.sendNotification(ctx, alert, entry, labels, annotations, models.AlertStatusResolved, value); sendErr != nil {
+	deliveries, sendErr := m.sendNotification(ctx, alert, entry, labels, annotations, models.AlertStatusResolved, value, nil)
+	if sendErr != nil {
 		m.log.Warn("failed to send resolved alert notifications", "alert_id", alert.ID, "error", sendErr)
 	} else {
 		m.log.Debug("resolved alert notifications sent", "alert_id", alert.ID, "alert_name", alert.Name)
 	}
+	m.recordResolveDeliveries(ctx, entry, deliveries)
 	return nil
 }
 
+// buildAlertMetadata assembles a notification's labels: the source's tags,
+// overridden by the alert's own labels, overridden by the built-in ones, so
+// receivers can route on tags like env=prod.
 func (m *Manager) buildAlertMetadata(ctx context.Context, alert *models.Alert, status models.AlertStatus, value float64) (labels, annotations map[string]string) {
-	labels = copyStringMap(alert.Labels)
-	if labels == nil {
-		labels = make(map[string]string, 8)
+	source, err := m.db.GetSource(ctx, alert.SourceID)
+	labels = make(map[string]string, 8)
+	if err == nil && source != nil {
+		maps.Copy(labels, source.Tags)
 	}
+	maps.Copy(labels, alert.Labels)
 	labels["alertname"] = alert.Name
 	labels["alert_id"] = strconv.FormatInt(int64(alert.ID), 10)
 	labels["severity"] = string(alert.Severity)
 
-	if source, err := m.db.GetSource(ctx, alert.SourceID); err == nil && source != nil {
+	if err == nil && source != nil {
 		labels["source"] = source.Name
 		labels["source_id"] = strconv.FormatInt(int64(alert.SourceID), 10)
 	} else {
@@ -405,20 +619,123 @@ func (m *Manager) buildAlertMetadata(ctx context.Context, alert *models.Alert, s
 	if alert.LookbackSeconds > 0 {
 		annotations["lookback_seconds"] = strconv.Itoa(alert.LookbackSeconds)
 	}
+	if alert.ForSeconds > 0 {
+		annotations["for_seconds"] = strconv.Itoa(alert.ForSeconds)
+	}
 	return labels, annotations
 }
 
-func (m *Manager) sendNotification(ctx context.Context, alert *models.Alert, history *models.AlertHistoryEntry, labels, annotations map[string]string, status models.AlertStatus, value float64) error {
+// sendNotification delivers the notification to the alert's channels, except
+// those listed in skip (already delivered on an earlier attempt). It returns
+// the per-channel results and an error summarising the failed channels.
+func (m *Manager) sendNotification(ctx context.Context, alert *models.Alert, history *models.AlertHistoryEntry, labels, annotations map[string]string, status models.AlertStatus, value float64, skip []models.AlertChannelDelivery) ([]models.AlertChannelDelivery, error) {
 	if m.sender == nil || history == nil {
-		return nil
+		return nil, nil
 	}
 
 	notification := m.buildNotification(ctx, alert, history, labels, annotations, status, value)
-	return m.sender.Send(ctx, notification)
+	if len(skip) > 0 {
+		done := make(map[string]struct{}, len(skip))
+		for _, d := range skip {
+			done[d.Channel] = struct{}{}
+		}
+		pending := notification.Channels[:0:0]
+		for _, channel := range notification.Channels {
+			if _, ok := done[channel.Key()]; !ok {
+				pending = append(pending, channel)
+			}
+		}
+		notification.Channels = pending
+	}
+	if len(notification.Channels) == 0 {
+		return nil, nil
+	}
+
+	deliveries := m.sender.Deliver(ctx, notification)
+	var errs []string
+	for _, d := range deliveries {
+		if d.Status == models.AlertDeliveryFailed {
+			errs = append(errs, fmt.Sprintf("%s: %s", d.Type, d.Error))
+		}
+	}
+	if len(errs) > 0 {
+		return deliveries, fmt.Errorf("notification delivery failed: %s", strings.Join(errs, "; "))
+	}
+	return deliveries, nil
+}
+
+// recordResolveDeliveries adds the resolution's per-channel results to the
+// history entry's payload.
+func (m *Manager) recordResolveDeliveries(ctx context.Context, entry *models.AlertHistoryEntry, deliveries []models.AlertChannelDelivery) {
+	if len(deliveries) == 0 {
+		return
+	}
+	payload := make(map[string]any, len(entry.Payload)+1)
+	maps.Copy(payload, entry.Payload)
+	payload["resolve_deliveries"] = deliveries
+	if err := m.db.UpdateAlertHistoryPayload(ctx, entry.ID, payload); err != nil {
+		m.log.Error("failed to record resolve deliveries", "alert_id", entry.AlertID, "history_id", entry.ID, "error", err)
+	}
+}
+
+// successfulDeliveries returns the channels a history payload records as
+// delivered or queued for a grouped delivery. Payloads read back from the
+// store hold decoded JSON, so the list is re-decoded rather than
+// type-asserted.
+func successfulDeliveries(payload map[string]any) []models.AlertChannelDelivery {
+	raw, ok := payload["deliveries"]
+	if !ok {
+		return nil
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var all []models.AlertChannelDelivery
+	if err := json.Unmarshal(encoded, &all); err != nil {
+		return nil
+	}
+	delivered := all[:0]
+	for _, d := range all {
+		if d.Status != models.AlertDeliveryFailed {
+			delivered = append(delivered, d)
+		}
+	}
+	return delivered
+}
+
+// notificationChannels lists an alert's delivery channels: an email channel
+// for its recipients (kept even when none resolved, so the failure is
+// reported), a webhook channel per webhook URL, then its configured channels.
+// Channels with the same destination are delivered once.
+func notificationChannels(alert *models.Alert, recipientEmails []string) []models.AlertChannel {
+	channels := make([]models.AlertChannel, 0, 1+len(alert.WebhookURLs)+len(alert.Channels))
+	if len(alert.RecipientUserIDs) > 0 {
+		channels = append(channels, models.AlertChannel{Type: models.AlertChannelEmail, Emails: recipientEmails})
+	}
+	for _, url := range alert.WebhookURLs {
+		channels = append(channels, models.AlertChannel{Type: models.AlertChannelWebhook, URL: url})
+	}
+	channels = append(channels, alert.Channels...)
+
+	seen := make(map[string]struct{}, len(channels))
+	out := channels[:0]
+	for _, channel := range channels {
+		key := channel.Key()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, channel)
+	}
+	return out
 }
 
 func (m *Manager) buildNotification(ctx context.Context, alert *models.Alert, history *models.AlertHistoryEntry, labels, annotations map[string]string, status models.AlertStatus, value float64) AlertNotification {
 	recipientEmails, missingRecipients := m.resolveRecipientEmails(ctx, alert)
+	if len(missingRecipients) > 0 {
+		m.log.Warn("alert recipients have no usable email", "alert_id", alert.ID, "user_ids", missingRecipients)
+	}
 	sourceName := labels["source"]
 
 	return AlertNotification{
@@ -446,6 +763,7 @@ func (m *Manager) buildNotification(ctx context.Context, alert *models.Alert, hi
 		RecipientEmails:         recipientEmails,
 		MissingRecipientUserIDs: missingRecipients,
 		WebhookURLs:             append([]string(nil), alert.WebhookURLs...),
+		Channels:                notificationChannels(alert, recipientEmails),
 	}
 }
 
@@ -498,7 +816,7 @@ func (m *Manager) generatorURL(ctx context.Context, alert *models.Alert) string
 
 type noopSender struct{}
 
-func (noopSender) Send(_ context.Context, _ AlertNotification) error {
+func (noopSender) Deliver(_ context.Context, _ AlertNotification) []models.AlertChannelDelivery {
 	return nil
 }
 
@@ -534,6 +852,12 @@ func (m *Manager) ManualResolve(ctx context.Context, alertID models.AlertID, mes
 	if entry.Value != nil {
 		value = *entry.Value
 	}
+	publishAlertEvent(models.EventAlertResolved, alert, value, message)
+
+	// There is nothing to follow up on if the trigger was never notified.
+	if notificationSilenced(entry) {
+		return nil
+	}
 
 	labels, annotations := m.buildAlertMetadata(ctx, alert, models.AlertStatusResolved, value)
 	if annotations == nil {
@@ -542,15 +866,32 @@ func (m *Manager) ManualResolve(ctx context.Context, alertID models.AlertID, mes
 	annotations["resolved_at"] = now.Format(time.RFC3339Nano)
 	annotations["resolved_by"] = "manual"
 
-	if sendErr := m.sendNotification(ctx, alert, entry, labels, annotations, models.AlertStatusResolved, value); sendErr != nil {
+	deliveries, sendErr := m.sendNotification(ctx, alert, entry, labels, annotations, models.AlertStatusResolved, value, nil)
+	if sendErr != nil {
 		m.log.Warn("failed to send manual resolution notifications", "alert_id", alertID, "error", sendErr)
 	} else {
 		m.log.Debug("manual resolution notifications sent", "alert_id", alertID)
 	}
+	m.recordResolveDeliveries(ctx, entry, deliveries)
 
 	return nil
 }
 
+// publishAlertEvent announces an alert firing or resolving on the event bus.
+// Silences hold back notifications, not these events.
+func publishAlertEvent(t models.EventType, alert *models.Alert, value float64, message string) {
+	events.Publish(t, models.AlertEventData{
+		AlertID:           alert.ID,
+		Name:              alert.Name,
+		SourceID:          alert.SourceID,
+		Severity:          alert.Severity,
+		Value:             value,
+		ThresholdOperator: alert.ThresholdOperator,
+		ThresholdValue:    alert.ThresholdValue,
+		Message:           message,
+	})
+}
+
 func copyStringMap(src map[string]string) map[string]string {
 	if len(src) == 0 {
 		return nil
@@ -3,9 +3,16 @@ package alerts
 import (
 	"context"
 	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
 	"testing"
 	"time"
 
+	vmetrics "github.com/VictoriaMetrics/metrics"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/store/sqlite"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
@@ -27,6 +34,9 @@ func TestEvaluateAlertWithTimeoutIsolatesHungEvaluation(t *testing.T) {
 		},
 	}
 
+	timeouts := vmetrics.GetOrCreateCounter(`logchef_alert_evaluations_total{kind="query",result="timeout"}`)
+	before := timeouts.Get()
+
 	done := make(chan error, 1)
 	go func() {
 		done <- m.evaluateAlertWithTimeout(context.Background(), &models.Alert{ID: 1})
@@ -44,4 +54,349 @@ func TestEvaluateAlertWithTimeoutIsolatesHungEvaluation(t *testing.T) {
 	if hadDeadline := <-sawDeadline; !hadDeadline {
 		t.Fatal("evaluation context carried no deadline")
 	}
+	if got := timeouts.Get(); got <= before {
+		t.Errorf("timeout evaluations = %d, want > %d", got, before)
+	}
+}
+
+func newTestStore(t *testing.T) (*sqlite.DB, *slog.Logger) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := sqlite.New(context.Background(), sqlite.Options{
+		Logger: logger,
+		Config: config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")},
+	})
+	if err != nil {
+		t.Fatalf("sqlite.New failed: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db, logger
+}
+
+func newTestSource(t *testing.T, db *sqlite.DB) *models.Source {
+	t.Helper()
+	source := &models.Source{
+		Name:        "app",
+		MetaTSField: "timestamp",
+		Connection:  models.ConnectionInfo{Host: "ch:9000", Username: "default", Database: "logs", TableName: "app"},
+	}
+	if err := db.CreateSource(context.Background(), source); err != nil {
+		t.Fatalf("CreateSource: %v", err)
+	}
+	return source
+}
+
+// TestAlertLabelsIncludeSourceTags checks notifications carry the source's
+// tags as labels, with the alert's own and the built-in labels taking
+// precedence.
+func TestAlertLabelsIncludeSourceTags(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db, logger := newTestStore(t)
+	source := newTestSource(t, db)
+	source.Tags = models.SourceTags{"env": "prod", "region": "eu", "source": "tag"}
+	if err := db.UpdateSource(ctx, source); err != nil {
+		t.Fatalf("UpdateSource: %v", err)
+	}
+	alert := &models.Alert{ID: 1, SourceID: source.ID, Name: "errors", Severity: models.AlertSeverityWarning, Labels: map[string]string{"region": "us"}}
+
+	m := NewManager(Options{DB: db, Logger: logger})
+	labels, _ := m.buildAlertMetadata(ctx, alert, models.AlertStatusTriggered, 1)
+	want := map[string]string{"env": "prod", "region": "us", "source": "app"}
+	for key, value := range want {
+		if labels[key] != value {
+			t.Errorf("labels[%q] = %q, want %q (labels %v)", key, labels[key], value, labels)
+		}
+	}
+}
+
+type fixedBurnRate struct {
+	value    float64
+	lookback int
+}
+
+func (f *fixedBurnRate) BurnRate(_ context.Context, _ models.SLOID, lookbackSeconds int) (float64, error) {
+	f.lookback = lookbackSeconds
+	return f.value, nil
+}
+
+// TestEvaluateBurnRateAlert checks that an alert linked to an SLO takes its
+// value from the SLO's burn rate over the alert's lookback.
+func TestEvaluateBurnRateAlert(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db, logger := newTestStore(t)
+	source := newTestSource(t, db)
+	team := &models.Team{Name: "payments"}
+	if err := db.CreateTeam(ctx, team); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+	slo := &models.SLO{
+		TeamID: team.ID, SourceID: source.ID, Name: "checkout",
+		QueryLanguage: models.QueryLanguageLogchefQL, GoodQuery: "status < 500", TotalQuery: `service = "checkout"`,
+		Target: 0.999, WindowSeconds: 30 * 24 * 3600,
+	}
+	if err := db.CreateSLO(ctx, slo); err != nil {
+		t.Fatalf("CreateSLO: %v", err)
+	}
+	alert := &models.Alert{
+		SourceID:          source.ID,
+		Name:              "fast burn",
+		QueryLanguage:     models.QueryLanguageClickHouseSQL,
+		EditorMode:        models.AlertEditorModeNative,
+		LookbackSeconds:   3600,
+		ThresholdOperator: models.AlertThresholdGreaterThan,
+		ThresholdValue:    14.4,
+		FrequencySeconds:  60,
+		Severity:          models.AlertSeverityCritical,
+		SLOID:             &slo.ID,
+		IsActive:          true,
+		LastState:         models.AlertStateResolved,
+	}
+	if err := db.CreateAlert(ctx, alert); err != nil {
+		t.Fatalf("CreateAlert: %v", err)
+	}
+
+	burn := &fixedBurnRate{value: 20}
+	m := NewManager(Options{DB: db, Logger: logger, BurnRates: burn})
+	if err := m.evaluateAlert(ctx, alert); err != nil {
+		t.Fatalf("evaluateAlert: %v", err)
+	}
+	if burn.lookback != 3600 {
+		t.Errorf("burn rate lookback = %d, want the alert's 3600", burn.lookback)
+	}
+	history, err := db.ListAlertHistory(ctx, alert.ID, 10)
+	if err != nil || len(history) != 1 {
+		t.Fatalf("ListAlertHistory: %v / %d", err, len(history))
+	}
+	if history[0].Status != models.AlertStatusTriggered || history[0].Value == nil || *history[0].Value != 20 {
+		t.Errorf("history = %+v, want triggered at 20", history[0])
+	}
+}
+
+// TestAlertPendingUntilForSeconds checks that an alert with a for-duration
+// stays pending while its condition holds for less than for_seconds, resets on
+// a non-breaching evaluation, and fires once the condition has held long enough.
+func TestAlertPendingUntilForSeconds(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db, logger := newTestStore(t)
+	source := newTestSource(t, db)
+	alert := &models.Alert{
+		SourceID:          source.ID,
+		Name:              "sustained errors",
+		QueryLanguage:     models.QueryLanguageClickHouseSQL,
+		EditorMode:        models.AlertEditorModeNative,
+		Query:             "SELECT count() FROM logs",
+		LookbackSeconds:   300,
+		ThresholdOperator: models.AlertThresholdGreaterThan,
+		ThresholdValue:    10,
+		FrequencySeconds:  60,
+		ForSeconds:        300,
+		Severity:          models.AlertSeverityWarning,
+		IsActive:          true,
+		LastState:         models.AlertStateResolved,
+	}
+	if err := db.CreateAlert(ctx, alert); err != nil {
+		t.Fatalf("CreateAlert: %v", err)
+	}
+	m := NewManager(Options{DB: db, Logger: logger})
+
+	apply := func(value float64) *models.Alert {
+		t.Helper()
+		if err := m.applyValue(ctx, alert, value); err != nil {
+			t.Fatalf("applyValue(%v): %v", value, err)
+		}
+		got, err := db.GetAlert(ctx, alert.ID)
+		if err != nil {
+			t.Fatalf("GetAlert: %v", err)
+		}
+		alert = got
+		return got
+	}
+	historyLen := func() int {
+		t.Helper()
+		history, err := db.ListAlertHistory(ctx, alert.ID, 10)
+		if err != nil {
+			t.Fatalf("ListAlertHistory: %v", err)
+		}
+		return len(history)
+	}
+
+	apply(20)
+	got := apply(20)
+	if got.LastState != models.AlertStatePending || got.BreachCount != 2 || got.PendingSince == nil {
+		t.Fatalf("after two breaches: state=%s breaches=%d pending_since=%v, want pending/2/set", got.LastState, got.BreachCount, got.PendingSince)
+	}
+	if n := historyLen(); n != 0 {
+		t.Fatalf("pending alert recorded %d history entries, want none", n)
+	}
+
+	got = apply(5)
+	if got.LastState != models.AlertStateResolved || got.BreachCount != 0 || got.PendingSince != nil {
+		t.Fatalf("after recovery: state=%s breaches=%d pending_since=%v, want resolved/0/unset", got.LastState, got.BreachCount, got.PendingSince)
+	}
+
+	alert.ForSeconds = 1
+	if err := db.UpdateAlert(ctx, alert); err != nil {
+		t.Fatalf("UpdateAlert: %v", err)
+	}
+	apply(20)
+	time.Sleep(1100 * time.Millisecond)
+	got = apply(20)
+	if got.LastState != models.AlertStateFiring || got.PendingSince != nil {
+		t.Fatalf("after holding for for_seconds: state=%s pending_since=%v, want firing/unset", got.LastState, got.PendingSince)
+	}
+	if n := historyLen(); n != 1 {
+		t.Fatalf("fired alert has %d history entries, want 1", n)
+	}
+
+	// A firing alert keeps firing without going back to pending.
+	if got = apply(20); got.LastState != models.AlertStateFiring {
+		t.Errorf("firing alert moved to %s on a further breach", got.LastState)
+	}
+}
+
+// recordingSender records notifications and reports every channel delivered.
+type recordingSender struct {
+	sent []models.AlertStatus
+}
+
+func (r *recordingSender) Deliver(_ context.Context, n AlertNotification) []models.AlertChannelDelivery {
+	r.sent = append(r.sent, n.Status)
+	deliveries := make([]models.AlertChannelDelivery, 0, len(n.Channels))
+	for _, channel := range n.Channels {
+		deliveries = append(deliveries, models.AlertChannelDelivery{
+			Channel: channel.Key(), Type: channel.Type, Status: models.AlertDeliveryDelivered, Attempts: 1, At: time.Now(),
+		})
+	}
+	return deliveries
+}
+
+// TestSilencedAlertHoldsNotifications checks that a silenced alert records
+// its trigger without notifying, and notifies once the silence is lifted.
+func TestSilencedAlertHoldsNotifications(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db, logger := newTestStore(t)
+	source := newTestSource(t, db)
+	team := &models.Team{Name: "payments"}
+	if err := db.CreateTeam(ctx, team); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+	if err := db.AddTeamSource(ctx, team.ID, source.ID); err != nil {
+		t.Fatalf("AddTeamSource: %v", err)
+	}
+	alert := &models.Alert{
+		SourceID:          source.ID,
+		Name:              "errors",
+		QueryLanguage:     models.QueryLanguageClickHouseSQL,
+		EditorMode:        models.AlertEditorModeNative,
+		Query:             "SELECT count() FROM logs.app",
+		ThresholdOperator: models.AlertThresholdGreaterThan,
+		ThresholdValue:    10,
+		FrequencySeconds:  60,
+		Severity:          models.AlertSeverityWarning,
+		WebhookURLs:       []string{"https://hooks.example.com/alerts"},
+		IsActive:          true,
+		LastState:         models.AlertStateResolved,
+	}
+	if err := db.CreateAlert(ctx, alert); err != nil {
+		t.Fatalf("CreateAlert: %v", err)
+	}
+
+	// Silence every alert on the team's sources for the next hour.
+	ends := time.Now().Add(time.Hour)
+	silence := &models.AlertSilence{TeamID: &team.ID, Reason: "maintenance", StartsAt: time.Now().Add(-time.Minute), EndsAt: &ends}
+	if err := db.CreateAlertSilence(ctx, silence); err != nil {
+		t.Fatalf("CreateAlertSilence: %v", err)
+	}
+
+	sender := &recordingSender{}
+	m := NewManager(Options{DB: db, Logger: logger, Sender: sender})
+	for range 2 {
+		if err := m.applyValue(ctx, alert, 50); err != nil {
+			t.Fatalf("applyValue: %v", err)
+		}
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("silenced alert sent %v", sender.sent)
+	}
+	history, err := db.ListAlertHistory(ctx, alert.ID, 10)
+	if err != nil || len(history) != 1 {
+		t.Fatalf("ListAlertHistory: %v / %d", err, len(history))
+	}
+	if silenced, _ := history[0].Payload["silenced"].(bool); !silenced {
+		t.Errorf("history payload = %v, want silenced", history[0].Payload)
+	}
+
+	// Lifting the silence sends the held-back trigger once.
+	if err := db.DeleteAlertSilence(ctx, silence.ID); err != nil {
+		t.Fatalf("DeleteAlertSilence: %v", err)
+	}
+	for range 2 {
+		if err := m.applyValue(ctx, alert, 50); err != nil {
+			t.Fatalf("applyValue: %v", err)
+		}
+	}
+	if len(sender.sent) != 1 || sender.sent[0] != models.AlertStatusTriggered {
+		t.Fatalf("after silence ended sent %v, want one trigger", sender.sent)
+	}
+	if err := m.applyValue(ctx, alert, 0); err != nil {
+		t.Fatalf("applyValue(resolve): %v", err)
+	}
+	if len(sender.sent) != 2 || sender.sent[1] != models.AlertStatusResolved {
+		t.Errorf("sent %v, want trigger then resolve", sender.sent)
+	}
+}
+
+// TestEvaluateCycleLeasesAlerts checks that two replicas sharing a database
+// evaluate a due alert once between them, and that the second takes over once
+// the first releases its leases.
+func TestEvaluateCycleLeasesAlerts(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db, logger := newTestStore(t)
+	source := newTestSource(t, db)
+	alert := &models.Alert{
+		SourceID:          source.ID,
+		Name:              "errors",
+		QueryLanguage:     models.QueryLanguageClickHouseSQL,
+		EditorMode:        models.AlertEditorModeNative,
+		Query:             "SELECT count() FROM logs",
+		LookbackSeconds:   300,
+		ThresholdOperator: models.AlertThresholdGreaterThan,
+		FrequencySeconds:  60,
+		Severity:          models.AlertSeverityWarning,
+		IsActive:          true,
+		LastState:         models.AlertStateResolved,
+	}
+	if err := db.CreateAlert(ctx, alert); err != nil {
+		t.Fatalf("CreateAlert: %v", err)
+	}
+
+	evaluated := map[string]int{}
+	newReplica := func(instance string) *Manager {
+		m := NewManager(Options{Config: config.AlertsConfig{Enabled: true}, DB: db, Logger: logger, Instance: instance})
+		m.evalFn = func(context.Context, *models.Alert) error {
+			evaluated[instance]++
+			return nil
+		}
+		return m
+	}
+	a, b := newReplica("host-a"), newReplica("host-b")
+
+	// The alert stays due: evalFn doesn't mark it evaluated.
+	a.evaluateCycle(ctx)
+	b.evaluateCycle(ctx)
+	a.evaluateCycle(ctx)
+	if evaluated["host-a"] != 2 || evaluated["host-b"] != 0 {
+		t.Fatalf("evaluations = %v, want host-a only", evaluated)
+	}
+
+	a.Stop()
+	b.evaluateCycle(ctx)
+	if evaluated["host-b"] != 1 {
+		t.Fatalf("evaluations = %v, want host-b to take over after host-a stopped", evaluated)
+	}
 }
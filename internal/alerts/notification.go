@@ -35,9 +35,20 @@ type AlertNotification struct {
 	MissingRecipientUserIDs []models.UserID
 	RecipientResolutionErr  string
 	WebhookURLs             []string
+
+	// Channels are the destinations this notification is delivered to: the
+	// alert's recipients and webhook URLs plus its configured channels, less
+	// any that already received it on an earlier attempt.
+	Channels []models.AlertChannel
 }
 
-// AlertSender abstracts the delivery mechanism for alert notifications.
+// AlertSender delivers a notification to each of its channels and reports
+// one result per channel.
 type AlertSender interface {
-	Send(ctx context.Context, notification AlertNotification) error
+	Deliver(ctx context.Context, notification AlertNotification) []models.AlertChannelDelivery
+}
+
+// ChannelSender delivers a notification over one kind of channel.
+type ChannelSender interface {
+	Send(ctx context.Context, channel models.AlertChannel, notification AlertNotification) error
 }
@@ -0,0 +1,54 @@
+package alerts
+
+import (
+	"context"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// activeSilence returns the silence covering alert at now, or nil. Lookup
+// errors are logged and treated as "not silenced": a missed notification is
+// worse than an unwanted one.
+func (m *Manager) activeSilence(ctx context.Context, alert *models.Alert, now time.Time) *models.AlertSilence {
+	silences, err := m.db.ListUnexpiredAlertSilences(ctx, now)
+	if err != nil {
+		m.log.Warn("failed to load alert silences", "alert_id", alert.ID, "error", err)
+		return nil
+	}
+	for _, silence := range silences {
+		if !silence.ActiveAt(now) {
+			continue
+		}
+		switch {
+		case silence.AlertID != nil:
+			if *silence.AlertID == alert.ID {
+				return silence
+			}
+		case silence.SourceID != nil:
+			if *silence.SourceID == alert.SourceID {
+				return silence
+			}
+		case silence.TeamID != nil:
+			linked, err := m.db.TeamHasSource(ctx, *silence.TeamID, alert.SourceID)
+			if err != nil {
+				m.log.Warn("failed to check team silence", "alert_id", alert.ID, "silence_id", silence.ID, "error", err)
+				continue
+			}
+			if linked {
+				return silence
+			}
+		}
+	}
+	return nil
+}
+
+// notificationSilenced reports whether a history entry was recorded while the
+// alert was silenced, i.e. nobody has been notified about it yet.
+func notificationSilenced(entry *models.AlertHistoryEntry) bool {
+	if entry == nil || entry.Payload == nil {
+		return false
+	}
+	silenced, _ := entry.Payload["silenced"].(bool)
+	return silenced
+}
@@ -0,0 +1,134 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// slackMessage is the incoming-webhook payload: a fallback text line plus one
+// colour-coded attachment carrying the alert details.
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Color     string       `json:"color,omitempty"`
+	Title     string       `json:"title"`
+	TitleLink string       `json:"title_link,omitempty"`
+	Text      string       `json:"text,omitempty"`
+	Fields    []slackField `json:"fields,omitempty"`
+	Footer    string       `json:"footer,omitempty"`
+	Timestamp int64        `json:"ts,omitempty"`
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+func slackColor(notification AlertNotification) string {
+	if notification.Status == models.AlertStatusResolved {
+		return "good"
+	}
+	switch notification.Severity {
+	case models.AlertSeverityCritical:
+		return "danger"
+	case models.AlertSeverityWarning:
+		return "warning"
+	default:
+		return "#439FE0"
+	}
+}
+
+func buildSlackMessage(notification AlertNotification) slackMessage {
+	status := strings.ToUpper(string(notification.Status))
+	text := fmt.Sprintf("[%s] %s", status, notification.AlertName)
+
+	fields := []slackField{
+		{Title: "Severity", Value: strings.ToUpper(string(notification.Severity)), Short: true},
+		{Title: "Value", Value: strconv.FormatFloat(notification.Value, 'f', -1, 64), Short: true},
+		{Title: "Threshold", Value: fmt.Sprintf("%s %s", notification.ThresholdOp, strconv.FormatFloat(notification.ThresholdValue, 'f', -1, 64)), Short: true},
+	}
+	if notification.SourceName != "" {
+		fields = append(fields, slackField{Title: "Source", Value: notification.SourceName, Short: true})
+	}
+
+	body := notification.Description
+	if notification.Message != "" {
+		if body != "" {
+			body += "\n"
+		}
+		body += notification.Message
+	}
+
+	ts := notification.TriggeredAt
+	if notification.ResolvedAt != nil {
+		ts = *notification.ResolvedAt
+	}
+	return slackMessage{
+		Text: text,
+		Attachments: []slackAttachment{{
+			Color:     slackColor(notification),
+			Title:     text,
+			TitleLink: notification.GeneratorURL,
+			Text:      body,
+			Fields:    fields,
+			Footer:    "Logchef",
+			Timestamp: ts.Unix(),
+		}},
+	}
+}
+
+// SendSlack posts the notification to a Slack incoming webhook.
+func (s *WebhookSender) SendSlack(ctx context.Context, url string, notification AlertNotification) error {
+	body, err := json.Marshal(buildSlackMessage(notification))
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack payload: %w", err)
+	}
+	if err := s.post(ctx, url, body, nil); err != nil {
+		return fmt.Errorf("slack delivery failed: %w", err)
+	}
+	return nil
+}
+
+// slackMaxAttachments caps the alerts listed in one grouped Slack message.
+const slackMaxAttachments = 20
+
+// buildSlackGroupMessage lists several notifications in one message, one
+// attachment per alert.
+func buildSlackGroupMessage(notifications []AlertNotification) slackMessage {
+	status := strings.ToUpper(string(groupStatus(notifications)))
+	names := make([]string, 0, len(notifications))
+	attachments := make([]slackAttachment, 0, min(len(notifications), slackMaxAttachments))
+	for i, n := range notifications {
+		names = append(names, n.AlertName)
+		if i < slackMaxAttachments {
+			attachments = append(attachments, buildSlackMessage(n).Attachments...)
+		}
+	}
+	text := fmt.Sprintf("[%s] %d alerts: %s", status, len(notifications), strings.Join(names, ", "))
+	if hidden := len(notifications) - slackMaxAttachments; hidden > 0 {
+		text += fmt.Sprintf(" (%d more not shown)", hidden)
+	}
+	return slackMessage{Text: text, Attachments: attachments}
+}
+
+// SendSlackGroup posts several notifications to a Slack incoming webhook as
+// one message.
+func (s *WebhookSender) SendSlackGroup(ctx context.Context, url string, notifications []AlertNotification) error {
+	body, err := json.Marshal(buildSlackGroupMessage(notifications))
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack payload: %w", err)
+	}
+	if err := s.post(ctx, url, body, nil); err != nil {
+		return fmt.Errorf("slack delivery failed: %w", err)
+	}
+	return nil
+}
@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"log/slog"
+
+	"github.com/mr-karan/logchef/pkg/models"
 )
 
 type WebhookSenderOptions struct {
@@ -66,11 +68,8 @@ func NewWebhookSender(opts WebhookSenderOptions) *WebhookSender {
 	}
 }
 
-func (s *WebhookSender) Send(ctx context.Context, notification AlertNotification) error {
-	if len(notification.WebhookURLs) == 0 {
-		return nil
-	}
-	payload := webhookPayload{
+func newWebhookPayload(notification AlertNotification) webhookPayload {
+	return webhookPayload{
 		AlertID:           int64(notification.AlertID),
 		AlertName:         notification.AlertName,
 		Description:       notification.Description,
@@ -92,40 +91,135 @@ func (s *WebhookSender) Send(ctx context.Context, notification AlertNotification
 		GeneratorURL:      notification.GeneratorURL,
 		Message:           notification.Message,
 	}
-	body, err := json.Marshal(payload)
+}
+
+// Send posts the default JSON payload to each of the notification's webhook URLs.
+func (s *WebhookSender) Send(ctx context.Context, notification AlertNotification) error {
+	if len(notification.WebhookURLs) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(newWebhookPayload(notification))
 	if err != nil {
 		return fmt.Errorf("failed to marshal webhook payload: %w", err)
 	}
 
 	var errs []string
 	for _, url := range notification.WebhookURLs {
-		request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
-		if err != nil {
+		if err := s.post(ctx, url, body, nil); err != nil {
 			errs = append(errs, fmt.Sprintf("%s: %v", url, err))
-			continue
 		}
-		request.Header.Set("Content-Type", "application/json")
-		response, err := s.client.Do(request)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("webhook delivery failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// SendChannel posts the notification to a webhook channel. The body is the
+// channel's template rendered with the notification, or the default JSON
+// payload when it has none.
+func (s *WebhookSender) SendChannel(ctx context.Context, channel models.AlertChannel, notification AlertNotification) error {
+	var body []byte
+	if channel.Template != "" {
+		rendered, err := renderWebhookTemplate(channel.Template, notification)
 		if err != nil {
-			errs = append(errs, fmt.Sprintf("%s: %v", url, err))
-			continue
+			return err
 		}
-		responseBody, readErr := io.ReadAll(response.Body)
-		_ = response.Body.Close()
-		if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
-			if readErr != nil {
-				errs = append(errs, fmt.Sprintf("%s: status %d (body read error: %v)", url, response.StatusCode, readErr))
-				continue
-			}
-			trimmed := strings.TrimSpace(string(responseBody))
-			if trimmed == "" {
-				trimmed = response.Status
-			}
-			errs = append(errs, fmt.Sprintf("%s: status %d (%s)", url, response.StatusCode, trimmed))
+		body = rendered
+	} else {
+		encoded, err := json.Marshal(newWebhookPayload(notification))
+		if err != nil {
+			return fmt.Errorf("failed to marshal webhook payload: %w", err)
 		}
+		body = encoded
 	}
-	if len(errs) > 0 {
-		return fmt.Errorf("webhook delivery failed: %s", strings.Join(errs, "; "))
+	if err := s.post(ctx, channel.URL, body, channel.Headers); err != nil {
+		return fmt.Errorf("webhook delivery failed: %s: %w", channel.URL, err)
+	}
+	return nil
+}
+
+// webhookGroupPayload carries several alerts delivered to one webhook
+// together. Status is triggered when any of them triggered.
+type webhookGroupPayload struct {
+	Status string           `json:"status"`
+	Count  int              `json:"count"`
+	Alerts []webhookPayload `json:"alerts"`
+}
+
+// groupStatus is triggered when any of the notifications triggered, resolved
+// otherwise.
+func groupStatus(notifications []AlertNotification) models.AlertStatus {
+	for _, n := range notifications {
+		if n.Status == models.AlertStatusTriggered {
+			return models.AlertStatusTriggered
+		}
+	}
+	return models.AlertStatusResolved
+}
+
+// SendGroup posts several notifications to a webhook or Slack channel as one
+// payload.
+func (s *WebhookSender) SendGroup(ctx context.Context, channel models.AlertChannel, notifications []AlertNotification) error {
+	if channel.Type == models.AlertChannelSlack {
+		return s.SendSlackGroup(ctx, channel.URL, notifications)
+	}
+	payload := webhookGroupPayload{
+		Status: string(groupStatus(notifications)),
+		Count:  len(notifications),
+		Alerts: make([]webhookPayload, len(notifications)),
+	}
+	for i, n := range notifications {
+		payload.Alerts[i] = newWebhookPayload(n)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+	if err := s.post(ctx, channel.URL, body, channel.Headers); err != nil {
+		return fmt.Errorf("webhook delivery failed: %s: %w", channel.URL, err)
+	}
+	return nil
+}
+
+func renderWebhookTemplate(text string, notification AlertNotification) ([]byte, error) {
+	tmpl, err := models.ParseWebhookTemplate(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, notification); err != nil {
+		return nil, fmt.Errorf("failed to render webhook template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// post sends body to url. Content-Type defaults to application/json; headers
+// may override it.
+func (s *WebhookSender) post(ctx context.Context, url string, body []byte, headers map[string]string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	responseBody, readErr := io.ReadAll(io.LimitReader(response.Body, 4096))
+	_ = response.Body.Close()
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		if readErr != nil {
+			return fmt.Errorf("status %d (body read error: %v)", response.StatusCode, readErr)
+		}
+		trimmed := strings.TrimSpace(string(responseBody))
+		if trimmed == "" {
+			trimmed = response.Status
+		}
+		return fmt.Errorf("status %d (%s)", response.StatusCode, trimmed)
 	}
 	return nil
 }
@@ -0,0 +1,484 @@
+// Package alerttransfer exports a team's alert definitions to a YAML or JSON
+// document and imports them back, so alert changes can be reviewed in Git and
+// promoted between Logchef instances.
+//
+// An alert is identified by its source's name and its own name. Importing
+// creates the alerts a team doesn't have yet and updates the ones it has.
+// Recipients are written as emails; SLO burn-rate alerts are left out, since
+// the SLO they follow can't be matched across instances.
+package alerttransfer
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// DocumentVersion is the version of the document format written by Export.
+const DocumentVersion = 1
+
+// Document formats.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// ErrInvalidDocument wraps errors in an import document or request.
+var ErrInvalidDocument = errors.New("invalid alert document")
+
+// Document is an exported set of alerts.
+type Document struct {
+	Version    int       `json:"version" yaml:"version"`
+	ExportedAt time.Time `json:"exported_at" yaml:"exported_at"`
+	Alerts     []Alert   `json:"alerts" yaml:"alerts"`
+}
+
+// Alert is one alert definition. Source is the name of the source it
+// evaluates against and Recipients are the emails of the users it notifies.
+type Alert struct {
+	Name              string                        `json:"name" yaml:"name"`
+	Source            string                        `json:"source" yaml:"source"`
+	Description       string                        `json:"description,omitempty" yaml:"description,omitempty"`
+	QueryLanguage     models.QueryLanguage          `json:"query_language" yaml:"query_language"`
+	EditorMode        models.AlertEditorMode        `json:"editor_mode" yaml:"editor_mode"`
+	Query             string                        `json:"query,omitempty" yaml:"query,omitempty"`
+	ConditionJSON     string                        `json:"condition_json,omitempty" yaml:"condition_json,omitempty"`
+	LookbackSeconds   int                           `json:"lookback_seconds" yaml:"lookback_seconds"`
+	ThresholdOperator models.AlertThresholdOperator `json:"threshold_operator" yaml:"threshold_operator"`
+	ThresholdValue    float64                       `json:"threshold_value" yaml:"threshold_value"`
+	FrequencySeconds  int                           `json:"frequency_seconds" yaml:"frequency_seconds"`
+	ForSeconds        int                           `json:"for_seconds,omitempty" yaml:"for_seconds,omitempty"`
+	Severity          models.AlertSeverity          `json:"severity" yaml:"severity"`
+	Labels            map[string]string             `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations       map[string]string             `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Recipients        []string                      `json:"recipients,omitempty" yaml:"recipients,omitempty"`
+	WebhookURLs       []string                      `json:"webhook_urls,omitempty" yaml:"webhook_urls,omitempty"`
+	Channels          []models.AlertChannel         `json:"channels,omitempty" yaml:"channels,omitempty"`
+	GeneratorURL      string                        `json:"generator_url,omitempty" yaml:"generator_url,omitempty"`
+	// Disabled keeps the alert defined but not evaluated.
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+}
+
+// Export builds a document of the alerts on the team's sources, ordered by
+// source and name so that successive exports diff cleanly.
+func Export(ctx context.Context, db store.StoreOps, teamID models.TeamID) (*Document, error) {
+	sources, err := db.ListTeamSources(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team sources: %w", err)
+	}
+
+	doc := &Document{
+		Version:    DocumentVersion,
+		ExportedAt: time.Now().UTC(),
+		Alerts:     []Alert{},
+	}
+	emails := newEmailCache(db)
+	for _, src := range sources {
+		alerts, err := db.ListAlertsBySource(ctx, src.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list alerts of source %q: %w", src.Name, err)
+		}
+		for _, alert := range alerts {
+			if alert.SLOID != nil {
+				continue
+			}
+			recipients, err := emails.lookup(ctx, alert.RecipientUserIDs)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve recipients of alert %q: %w", alert.Name, err)
+			}
+			doc.Alerts = append(doc.Alerts, exportAlert(alert, src.Name, recipients))
+		}
+	}
+	slices.SortFunc(doc.Alerts, func(a, b Alert) int {
+		return cmp.Or(cmp.Compare(a.Source, b.Source), cmp.Compare(a.Name, b.Name))
+	})
+	return doc, nil
+}
+
+func exportAlert(alert *models.Alert, source string, recipients []string) Alert {
+	return Alert{
+		Name:              alert.Name,
+		Source:            source,
+		Description:       alert.Description,
+		QueryLanguage:     alert.QueryLanguage,
+		EditorMode:        alert.EditorMode,
+		Query:             alert.Query,
+		ConditionJSON:     alert.ConditionJSON,
+		LookbackSeconds:   alert.LookbackSeconds,
+		ThresholdOperator: alert.ThresholdOperator,
+		ThresholdValue:    alert.ThresholdValue,
+		FrequencySeconds:  alert.FrequencySeconds,
+		ForSeconds:        alert.ForSeconds,
+		Severity:          alert.Severity,
+		Labels:            alert.Labels,
+		Annotations:       alert.Annotations,
+		Recipients:        recipients,
+		WebhookURLs:       alert.WebhookURLs,
+		Channels:          alert.Channels,
+		GeneratorURL:      alert.GeneratorURL,
+		Disabled:          !alert.IsActive,
+	}
+}
+
+// emailCache maps user ids to emails, loading each user once.
+type emailCache struct {
+	db     store.StoreOps
+	emails map[models.UserID]string
+}
+
+func newEmailCache(db store.StoreOps) *emailCache {
+	return &emailCache{db: db, emails: make(map[models.UserID]string)}
+}
+
+// lookup returns the emails of ids. Users that no longer exist are skipped.
+func (c *emailCache) lookup(ctx context.Context, ids []models.UserID) ([]string, error) {
+	var out []string
+	for _, id := range ids {
+		email, ok := c.emails[id]
+		if !ok {
+			user, err := c.db.GetUser(ctx, id)
+			switch {
+			case err == nil:
+				email = user.Email
+			case !models.IsNotFound(err):
+				return nil, err
+			}
+			c.emails[id] = email
+		}
+		if email != "" {
+			out = append(out, email)
+		}
+	}
+	return out, nil
+}
+
+// Marshal encodes doc in format, FormatYAML or FormatJSON.
+func Marshal(doc *Document, format string) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		return yaml.Marshal(doc)
+	case FormatJSON:
+		return json.MarshalIndent(doc, "", "  ")
+	default:
+		return nil, fmt.Errorf("%w: format must be %q or %q", ErrInvalidDocument, FormatYAML, FormatJSON)
+	}
+}
+
+// Parse decodes a YAML or JSON document (JSON is valid YAML) and checks its
+// version, and that every alert is named, names a source and appears once.
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	if doc.Version != DocumentVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d (expected %d)", ErrInvalidDocument, doc.Version, DocumentVersion)
+	}
+	seen := make(map[[2]string]bool, len(doc.Alerts))
+	for i := range doc.Alerts {
+		alert := &doc.Alerts[i]
+		alert.Name = strings.TrimSpace(alert.Name)
+		alert.Source = strings.TrimSpace(alert.Source)
+		if alert.Name == "" {
+			return nil, fmt.Errorf("%w: alerts[%d]: name is required", ErrInvalidDocument, i)
+		}
+		if alert.Source == "" {
+			return nil, fmt.Errorf("%w: alert %q: source is required", ErrInvalidDocument, alert.Name)
+		}
+		key := [2]string{alert.Source, alert.Name}
+		if seen[key] {
+			return nil, fmt.Errorf("%w: alert %q appears twice for source %q", ErrInvalidDocument, alert.Name, alert.Source)
+		}
+		seen[key] = true
+	}
+	return &doc, nil
+}
+
+// Import statuses of an alert.
+const (
+	StatusCreated     = "created"
+	StatusUpdated     = "updated"
+	StatusUnchanged   = "unchanged"
+	StatusWouldCreate = "would_create"
+	StatusWouldUpdate = "would_update"
+	StatusFailed      = "failed"
+)
+
+// ImportOptions control an import.
+type ImportOptions struct {
+	// DryRun reports what would change without saving anything.
+	DryRun bool
+}
+
+// ImportedAlert is the outcome for one alert of a document.
+type ImportedAlert struct {
+	Name    string         `json:"name"`
+	Source  string         `json:"source"`
+	Status  string         `json:"status"`
+	AlertID models.AlertID `json:"alert_id,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// ImportResult summarises an import.
+type ImportResult struct {
+	DryRun    bool            `json:"dry_run"`
+	Created   int             `json:"created"`
+	Updated   int             `json:"updated"`
+	Unchanged int             `json:"unchanged"`
+	Failed    int             `json:"failed"`
+	Alerts    []ImportedAlert `json:"alerts"`
+}
+
+// Import creates or updates the document's alerts on the team's sources as
+// user. An alert matches an existing one with the same name on the same
+// source; a match whose definition already equals the document's is left
+// alone. Changes to alerts managed by provisioning, or to alerts user may not
+// edit, are reported as failed. Each alert is imported on its own, so one that fails
+// doesn't stop the rest.
+func Import(ctx context.Context, db store.StoreOps, ds *datasource.Service, log *slog.Logger, teamID models.TeamID, user *models.User, doc *Document, opts ImportOptions) (*ImportResult, error) {
+	sources, err := db.ListTeamSources(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team sources: %w", err)
+	}
+	byName := make(map[string]*models.Source, len(sources))
+	for _, src := range sources {
+		byName[src.Name] = src
+	}
+	team, err := db.GetTeam(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load team: %w", err)
+	}
+
+	im := &importer{
+		db:       db,
+		ds:       ds,
+		log:      log,
+		user:     user,
+		rawSQL:   user.Role == models.UserRoleAdmin || team.RawSQLEnabled,
+		dryRun:   opts.DryRun,
+		sources:  byName,
+		existing: make(map[models.SourceID][]*models.Alert),
+		emails:   newEmailCache(db),
+	}
+	result := &ImportResult{DryRun: opts.DryRun, Alerts: make([]ImportedAlert, 0, len(doc.Alerts))}
+	for _, alert := range doc.Alerts {
+		outcome := im.importAlert(ctx, alert)
+		switch outcome.Status {
+		case StatusCreated, StatusWouldCreate:
+			result.Created++
+		case StatusUpdated, StatusWouldUpdate:
+			result.Updated++
+		case StatusUnchanged:
+			result.Unchanged++
+		case StatusFailed:
+			result.Failed++
+		}
+		result.Alerts = append(result.Alerts, outcome)
+	}
+	return result, nil
+}
+
+// importer holds the lookups shared by the alerts of one import.
+type importer struct {
+	db       store.StoreOps
+	ds       *datasource.Service
+	log      *slog.Logger
+	user     *models.User
+	rawSQL   bool // whether user may write raw SQL queries in the team
+	dryRun   bool
+	sources  map[string]*models.Source
+	existing map[models.SourceID][]*models.Alert
+	emails   *emailCache
+}
+
+func (im *importer) importAlert(ctx context.Context, alert Alert) ImportedAlert {
+	outcome := ImportedAlert{Name: alert.Name, Source: alert.Source}
+	fail := func(err error) ImportedAlert {
+		outcome.Status = StatusFailed
+		outcome.Error = err.Error()
+		return outcome
+	}
+
+	src, ok := im.sources[alert.Source]
+	if !ok {
+		return fail(fmt.Errorf("source %q is not linked to the team", alert.Source))
+	}
+	var err error
+	alert.QueryLanguage, alert.EditorMode, err = models.ResolveAlertMetadata(alert.QueryLanguage, alert.EditorMode)
+	if err != nil {
+		return fail(err)
+	}
+	recipients, err := im.recipientIDs(ctx, alert.Recipients)
+	if err != nil {
+		return fail(err)
+	}
+	existing, err := im.find(ctx, src.ID, alert.Name)
+	if err != nil {
+		return fail(err)
+	}
+
+	if existing == nil {
+		if im.dryRun {
+			outcome.Status = StatusWouldCreate
+			return outcome
+		}
+		created, err := core.CreateAlert(ctx, im.db, im.ds, im.log, src.ID, im.user.ID, im.rawSQL, createRequest(alert, recipients))
+		if err != nil {
+			return fail(err)
+		}
+		outcome.Status = StatusCreated
+		outcome.AlertID = created.ID
+		return outcome
+	}
+
+	outcome.AlertID = existing.ID
+	current, err := im.emails.lookup(ctx, existing.RecipientUserIDs)
+	if err != nil {
+		return fail(err)
+	}
+	if sameDefinition(exportAlert(existing, src.Name, current), alert) {
+		outcome.Status = StatusUnchanged
+		return outcome
+	}
+	switch {
+	case existing.Managed:
+		return fail(errors.New("alert is managed by provisioning"))
+	case !core.UserCanEditAlert(existing, im.user):
+		return fail(errors.New("only the alert's creator or a global admin can update it"))
+	}
+	if im.dryRun {
+		outcome.Status = StatusWouldUpdate
+		return outcome
+	}
+	if _, err := core.UpdateAlert(ctx, im.db, im.ds, im.log, existing.ID, im.rawSQL, updateRequest(alert, recipients)); err != nil {
+		return fail(err)
+	}
+	outcome.Status = StatusUpdated
+	return outcome
+}
+
+// find returns the alert named name on the source, or nil when there is none.
+func (im *importer) find(ctx context.Context, sourceID models.SourceID, name string) (*models.Alert, error) {
+	alerts, ok := im.existing[sourceID]
+	if !ok {
+		var err error
+		if alerts, err = im.db.ListAlertsBySource(ctx, sourceID); err != nil {
+			return nil, fmt.Errorf("failed to list alerts of the source: %w", err)
+		}
+		im.existing[sourceID] = alerts
+	}
+	for _, alert := range alerts {
+		if alert.Name == name && alert.SLOID == nil {
+			return alert, nil
+		}
+	}
+	return nil, nil
+}
+
+// recipientIDs resolves recipient emails to user ids. Unlike provisioning, an
+// email without a user account fails the alert: the import is run by hand and
+// can be corrected.
+func (im *importer) recipientIDs(ctx context.Context, emails []string) ([]models.UserID, error) {
+	ids := []models.UserID{}
+	for _, email := range emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		user, err := im.db.GetUserByEmail(ctx, email)
+		if err != nil {
+			if models.IsNotFound(err) {
+				return nil, fmt.Errorf("recipient %q has no user account", email)
+			}
+			return nil, fmt.Errorf("failed to look up recipient %q: %w", email, err)
+		}
+		if !slices.Contains(ids, user.ID) {
+			ids = append(ids, user.ID)
+		}
+	}
+	return ids, nil
+}
+
+// sameDefinition reports whether two alerts define the same rule, treating
+// empty and missing lists and maps alike.
+func sameDefinition(a, b Alert) bool {
+	left, errA := json.Marshal(a)
+	right, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(left) == string(right)
+}
+
+func createRequest(alert Alert, recipients []models.UserID) *models.CreateAlertRequest {
+	return &models.CreateAlertRequest{
+		Name:              alert.Name,
+		Description:       alert.Description,
+		QueryLanguage:     alert.QueryLanguage,
+		EditorMode:        alert.EditorMode,
+		Query:             alert.Query,
+		ConditionJSON:     alert.ConditionJSON,
+		LookbackSeconds:   alert.LookbackSeconds,
+		ThresholdOperator: alert.ThresholdOperator,
+		ThresholdValue:    alert.ThresholdValue,
+		FrequencySeconds:  alert.FrequencySeconds,
+		ForSeconds:        alert.ForSeconds,
+		Severity:          alert.Severity,
+		Labels:            alert.Labels,
+		Annotations:       alert.Annotations,
+		RecipientUserIDs:  recipients,
+		WebhookURLs:       alert.WebhookURLs,
+		Channels:          alert.Channels,
+		GeneratorURL:      alert.GeneratorURL,
+		IsActive:          !alert.Disabled,
+	}
+}
+
+// updateRequest sets every field of the definition, so fields left out of the
+// document are cleared rather than kept.
+func updateRequest(alert Alert, recipients []models.UserID) *models.UpdateAlertRequest {
+	labels := alert.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	annotations := alert.Annotations
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	webhookURLs := alert.WebhookURLs
+	if webhookURLs == nil {
+		webhookURLs = []string{}
+	}
+	channels := alert.Channels
+	if channels == nil {
+		channels = []models.AlertChannel{}
+	}
+	isActive := !alert.Disabled
+	return &models.UpdateAlertRequest{
+		Description:       &alert.Description,
+		QueryLanguage:     &alert.QueryLanguage,
+		EditorMode:        &alert.EditorMode,
+		Query:             &alert.Query,
+		ConditionJSON:     &alert.ConditionJSON,
+		LookbackSeconds:   &alert.LookbackSeconds,
+		ThresholdOperator: &alert.ThresholdOperator,
+		ThresholdValue:    &alert.ThresholdValue,
+		FrequencySeconds:  &alert.FrequencySeconds,
+		ForSeconds:        &alert.ForSeconds,
+		Severity:          &alert.Severity,
+		Labels:            &labels,
+		Annotations:       &annotations,
+		RecipientUserIDs:  &recipients,
+		WebhookURLs:       &webhookURLs,
+		Channels:          &channels,
+		GeneratorURL:      &alert.GeneratorURL,
+		IsActive:          &isActive,
+	}
+}
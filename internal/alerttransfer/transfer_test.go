@@ -0,0 +1,168 @@
+package alerttransfer
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/store/sqlite"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func newTestDB(t *testing.T) *sqlite.DB {
+	t.Helper()
+	db, err := sqlite.New(context.Background(), sqlite.Options{
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Config: config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")},
+	})
+	if err != nil {
+		t.Fatalf("sqlite.New failed: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+// seed creates a team with source "app", an owner, and two alerts on app: a
+// plain one notifying the owner and a managed one.
+func seed(t *testing.T, db *sqlite.DB) (models.TeamID, *models.User) {
+	t.Helper()
+	ctx := context.Background()
+	owner := &models.User{Email: "owner@example.com", FullName: "Owner", Role: models.UserRoleMember, Status: "active"}
+	if err := db.CreateUser(ctx, owner); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	team := &models.Team{Name: "platform"}
+	if err := db.CreateTeam(ctx, team); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+	src := &models.Source{
+		Name:        "app",
+		MetaTSField: "timestamp",
+		Connection:  models.ConnectionInfo{Host: "ch:9000", Database: "logs", TableName: "app"},
+	}
+	if err := db.CreateSource(ctx, src); err != nil {
+		t.Fatalf("CreateSource: %v", err)
+	}
+	if err := db.AddTeamSource(ctx, team.ID, src.ID); err != nil {
+		t.Fatalf("AddTeamSource: %v", err)
+	}
+
+	for _, alert := range []*models.Alert{
+		{
+			Name: "5xx spike", Labels: map[string]string{"team": "platform"},
+			RecipientUserIDs: []models.UserID{owner.ID},
+			Channels:         []models.AlertChannel{{Type: models.AlertChannelSlack, URL: "https://hooks.slack.com/ops"}},
+		},
+		{Name: "provisioned"},
+	} {
+		alert.SourceID = src.ID
+		alert.QueryLanguage = models.QueryLanguageClickHouseSQL
+		alert.EditorMode = models.AlertEditorModeNative
+		alert.Query = "SELECT count() FROM logs"
+		alert.LookbackSeconds = 300
+		alert.ThresholdOperator = models.AlertThresholdGreaterThan
+		alert.ThresholdValue = 10
+		alert.FrequencySeconds = 60
+		alert.Severity = models.AlertSeverityWarning
+		alert.IsActive = true
+		alert.CreatedBy = &owner.ID
+		if err := db.CreateAlert(ctx, alert); err != nil {
+			t.Fatalf("CreateAlert(%s): %v", alert.Name, err)
+		}
+		if alert.Name == "provisioned" {
+			if err := db.SetAlertManaged(ctx, alert.ID, true); err != nil {
+				t.Fatalf("SetAlertManaged: %v", err)
+			}
+		}
+	}
+	return team.ID, owner
+}
+
+func TestExportParseRoundTrip(t *testing.T) {
+	db := newTestDB(t)
+	teamID, _ := seed(t, db)
+
+	doc, err := Export(context.Background(), db, teamID)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	out, err := Marshal(doc, FormatYAML)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	text := string(out)
+	for _, want := range []string{"name: 5xx spike", "source: app", "- owner@example.com", "url: https://hooks.slack.com/ops"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("export lacks %q:\n%s", want, text)
+		}
+	}
+
+	parsed, err := Parse(out)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(parsed.Alerts) != 2 || parsed.Alerts[0].Name != "5xx spike" || !sameDefinition(parsed.Alerts[0], doc.Alerts[0]) {
+		t.Errorf("round trip = %+v, want %+v", parsed.Alerts, doc.Alerts)
+	}
+}
+
+func TestParseRejectsBadDocuments(t *testing.T) {
+	for name, doc := range map[string]string{
+		"version":   "version: 2\nalerts: []\n",
+		"no name":   "version: 1\nalerts:\n  - source: app\n",
+		"no source": "version: 1\nalerts:\n  - name: a\n",
+		"duplicate": "version: 1\nalerts:\n  - {name: a, source: app}\n  - {name: a, source: app}\n",
+	} {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("%s: Parse accepted %q", name, doc)
+		}
+	}
+}
+
+func TestImportDryRunMatchesByName(t *testing.T) {
+	db := newTestDB(t)
+	teamID, owner := seed(t, db)
+	ctx := context.Background()
+
+	doc, err := Export(ctx, db, teamID)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	// Re-importing an export changes nothing.
+	result, err := Import(ctx, db, nil, nil, teamID, owner, doc, ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Import(export): %v", err)
+	}
+	if result.Unchanged != 2 || result.Failed != 0 {
+		t.Errorf("Import(export) = %+v", result)
+	}
+
+	changed := doc.Alerts[0]
+	changed.ThresholdValue = 50
+	added := doc.Alerts[0]
+	added.Name = "new alert"
+	unknownSource := doc.Alerts[0]
+	unknownSource.Name, unknownSource.Source = "elsewhere", "missing"
+	unknownRecipient := doc.Alerts[0]
+	unknownRecipient.Name, unknownRecipient.Recipients = "stranger", []string{"nobody@example.com"}
+	managed := doc.Alerts[1]
+	managed.Disabled = true
+	doc.Alerts = []Alert{changed, added, unknownSource, unknownRecipient, managed}
+
+	result, err = Import(ctx, db, nil, nil, teamID, owner, doc, ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	for i, want := range []string{StatusWouldUpdate, StatusWouldCreate, StatusFailed, StatusFailed, StatusFailed} {
+		if got := result.Alerts[i]; got.Status != want {
+			t.Errorf("%s: status = %s (%s), want %s", got.Name, got.Status, got.Error, want)
+		}
+	}
+	if result.Created != 1 || result.Updated != 1 || result.Failed != 3 {
+		t.Errorf("Import counts = %+v", result)
+	}
+}
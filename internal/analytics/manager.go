@@ -0,0 +1,156 @@
+// Package analytics aggregates recorded query history into usage reports:
+// who queries most, which sources carry the load, which queries are slowest
+// and which fields are filtered on. The reports feed an admin endpoint and
+// Prometheus gauges for capacity planning of the ClickHouse cluster.
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/metrics"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+const (
+	// scanPageSize is how many history entries a report reads per page.
+	scanPageSize = 1000
+	// maxScanEntries bounds the entries one report reads, newest first.
+	maxScanEntries = 50000
+	// refreshTimeout bounds one scheduled report.
+	refreshTimeout = time.Minute
+)
+
+// historyStore is the store surface the manager needs. store.Store
+// implements it.
+type historyStore interface {
+	ListQueryHistory(ctx context.Context, filter models.QueryHistoryFilter) ([]*models.QueryHistory, error)
+}
+
+// Options encapsulates the dependencies of the analytics manager.
+type Options struct {
+	Config config.QueryAnalyticsConfig
+	DB     historyStore
+	Logger *slog.Logger
+}
+
+// Manager builds usage reports and periodically publishes one as gauges.
+type Manager struct {
+	cfg config.QueryAnalyticsConfig
+	db  historyStore
+	log *slog.Logger
+
+	// now is a seam for tests.
+	now func() time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewManager constructs an analytics manager.
+func NewManager(opts Options) *Manager {
+	return &Manager{
+		cfg:  opts.Config,
+		db:   opts.DB,
+		log:  opts.Logger.With("component", "query_analytics_manager"),
+		now:  time.Now,
+		stop: make(chan struct{}),
+	}
+}
+
+// Start launches the gauge refresh loop. It is a no-op when analytics are
+// disabled.
+func (m *Manager) Start(ctx context.Context) {
+	if !m.cfg.Enabled {
+		m.log.Debug("query analytics disabled")
+		return
+	}
+	m.log.Debug("starting query analytics manager", "interval", m.cfg.Interval, "window_days", m.cfg.WindowDays)
+
+	m.wg.Go(func() {
+		ticker := time.NewTicker(m.cfg.Interval)
+		defer ticker.Stop()
+
+		m.refresh(ctx)
+		for {
+			select {
+			case <-ticker.C:
+				m.refresh(ctx)
+			case <-m.stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// Stop signals the refresh loop to stop and waits for the current run to end.
+func (m *Manager) Stop() {
+	close(m.stop)
+	m.wg.Wait()
+}
+
+func (m *Manager) refresh(ctx context.Context) {
+	runCtx, cancel := context.WithTimeout(ctx, refreshTimeout)
+	defer cancel()
+	report, err := m.Report(runCtx, m.cfg.WindowDays)
+	if err != nil {
+		m.log.Error("failed to build query usage report", "error", err)
+		return
+	}
+	metrics.SetQueryUsage(report)
+}
+
+// WindowDays is the report window used when a request doesn't give one.
+func (m *Manager) WindowDays() int {
+	return m.cfg.WindowDays
+}
+
+// Report builds a usage report over the history recorded in the last days
+// days. It reads at most maxScanEntries entries, newest first.
+func (m *Manager) Report(ctx context.Context, days int) (*models.QueryUsageReport, error) {
+	now := m.now().UTC()
+	since := now.AddDate(0, 0, -days)
+
+	var (
+		entries   []*models.QueryHistory
+		seen      = make(map[int64]bool)
+		truncated bool
+	)
+	filter := models.QueryHistoryFilter{Since: &since, Limit: scanPageSize}
+	for {
+		page, err := m.db.ListQueryHistory(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("error reading query history: %w", err)
+		}
+		for _, e := range page {
+			// New entries shift later pages while the scan runs.
+			if !seen[e.ID] {
+				seen[e.ID] = true
+				entries = append(entries, e)
+			}
+		}
+		if len(page) < scanPageSize {
+			break
+		}
+		if len(entries) >= maxScanEntries {
+			truncated = true
+			break
+		}
+		filter.Offset += scanPageSize
+	}
+
+	report := Aggregate(entries, ReportOptions{
+		Since:              since,
+		Days:               days,
+		SlowQueryThreshold: m.cfg.SlowQueryThreshold,
+		TopN:               m.cfg.TopN,
+	}, now)
+	report.Truncated = truncated
+	return report, nil
+}
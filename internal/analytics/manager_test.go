@@ -0,0 +1,70 @@
+package analytics
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// fakeHistory serves a fixed number of entries, newest first, honoring the
+// filter's paging, and records the filters it was called with.
+type fakeHistory struct {
+	total   int
+	filters []models.QueryHistoryFilter
+}
+
+func (f *fakeHistory) ListQueryHistory(_ context.Context, filter models.QueryHistoryFilter) ([]*models.QueryHistory, error) {
+	f.filters = append(f.filters, filter)
+	var page []*models.QueryHistory
+	for i := filter.Offset; i < min(filter.Offset+filter.Limit, f.total); i++ {
+		page = append(page, &models.QueryHistory{ID: int64(f.total - i), UserID: 1, SourceID: 1, DurationMs: 10})
+	}
+	return page, nil
+}
+
+func newTestManager(store *fakeHistory, now time.Time) *Manager {
+	m := NewManager(Options{
+		Config: config.QueryAnalyticsConfig{WindowDays: 7, SlowQueryThreshold: time.Second, TopN: 5},
+		DB:     store,
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	m.now = func() time.Time { return now }
+	return m
+}
+
+func TestReportPagesThroughWindow(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := &fakeHistory{total: scanPageSize + 5}
+	report, err := newTestManager(store, now).Report(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if report.TotalQueries != scanPageSize+5 || report.Truncated {
+		t.Errorf("TotalQueries = %d (truncated %v), want %d", report.TotalQueries, report.Truncated, scanPageSize+5)
+	}
+	if len(store.filters) != 2 || store.filters[1].Offset != scanPageSize {
+		t.Fatalf("filters = %+v, want two pages", store.filters)
+	}
+	if since := store.filters[0].Since; since == nil || !since.Equal(now.AddDate(0, 0, -3)) {
+		t.Errorf("Since = %v, want three days before now", since)
+	}
+	if report.Days != 3 {
+		t.Errorf("Days = %d, want 3", report.Days)
+	}
+}
+
+func TestReportStopsAtScanCap(t *testing.T) {
+	store := &fakeHistory{total: maxScanEntries + scanPageSize}
+	report, err := newTestManager(store, time.Now()).Report(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if report.TotalQueries != maxScanEntries || !report.Truncated {
+		t.Errorf("TotalQueries = %d (truncated %v), want %d truncated", report.TotalQueries, report.Truncated, maxScanEntries)
+	}
+}
@@ -0,0 +1,149 @@
+package analytics
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/clickhouse"
+	"github.com/mr-karan/logchef/internal/logchefql"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// ReportOptions shapes a usage report.
+type ReportOptions struct {
+	Since              time.Time
+	Days               int
+	SlowQueryThreshold time.Duration
+	TopN               int
+}
+
+type sourceKey struct {
+	id    models.SourceID
+	field string
+}
+
+// Aggregate builds a usage report from history entries. Entries may come in
+// any order; ranking ties break on IDs and names so reports are stable.
+func Aggregate(entries []*models.QueryHistory, opts ReportOptions, now time.Time) *models.QueryUsageReport {
+	report := &models.QueryUsageReport{
+		Since:                opts.Since,
+		GeneratedAt:          now,
+		Days:                 opts.Days,
+		SlowQueryThresholdMs: opts.SlowQueryThreshold.Milliseconds(),
+	}
+
+	users := make(map[models.UserID]*models.QueryUsageUser)
+	sources := make(map[models.SourceID]*models.QueryUsageSource)
+	durations := make(map[models.SourceID][]int64)
+	fields := make(map[sourceKey]*models.QueryFieldUsage)
+
+	for _, e := range entries {
+		failed := e.Status == models.QueryHistoryStatusError
+		report.TotalQueries++
+		if failed {
+			report.FailedQueries++
+		}
+		if e.DurationMs >= report.SlowQueryThresholdMs {
+			report.SlowQueries++
+		}
+
+		u, ok := users[e.UserID]
+		if !ok {
+			u = &models.QueryUsageUser{UserID: e.UserID, UserEmail: e.UserEmail}
+			users[e.UserID] = u
+		}
+		u.Queries++
+		u.TotalDurationMs += e.DurationMs
+
+		src, ok := sources[e.SourceID]
+		if !ok {
+			src = &models.QueryUsageSource{SourceID: e.SourceID, SourceName: e.SourceName}
+			sources[e.SourceID] = src
+		}
+		src.Queries++
+		src.TotalDurationMs += e.DurationMs
+		src.RowsReturned += e.RowCount
+		durations[e.SourceID] = append(durations[e.SourceID], e.DurationMs)
+
+		if failed {
+			u.FailedQueries++
+			src.FailedQueries++
+			continue
+		}
+		for _, field := range filteredFields(e) {
+			key := sourceKey{id: e.SourceID, field: field}
+			f, ok := fields[key]
+			if !ok {
+				f = &models.QueryFieldUsage{SourceID: e.SourceID, SourceName: e.SourceName, Field: field}
+				fields[key] = f
+			}
+			f.Queries++
+		}
+	}
+
+	for id, src := range sources {
+		src.AvgDurationMs = src.TotalDurationMs / int64(src.Queries)
+		src.P95DurationMs = percentile(durations[id], 0.95)
+	}
+
+	report.TopUsers = topN(users, opts.TopN, func(a, b *models.QueryUsageUser) int {
+		return cmp.Or(cmp.Compare(b.Queries, a.Queries), cmp.Compare(a.UserID, b.UserID))
+	})
+	report.TopSources = topN(sources, opts.TopN, func(a, b *models.QueryUsageSource) int {
+		return cmp.Or(cmp.Compare(b.Queries, a.Queries), cmp.Compare(a.SourceID, b.SourceID))
+	})
+	report.TopFields = topN(fields, opts.TopN, func(a, b *models.QueryFieldUsage) int {
+		return cmp.Or(cmp.Compare(b.Queries, a.Queries), cmp.Compare(a.SourceID, b.SourceID), cmp.Compare(a.Field, b.Field))
+	})
+
+	slowest := slices.Clone(entries)
+	slices.SortFunc(slowest, func(a, b *models.QueryHistory) int {
+		return cmp.Or(cmp.Compare(b.DurationMs, a.DurationMs), cmp.Compare(b.ID, a.ID))
+	})
+	report.SlowestQueries = slowest[:min(opts.TopN, len(slowest))]
+	return report
+}
+
+// filteredFields returns the fields a query filtered on. Queries that no
+// longer parse, and LogsQL, which is passed through as written, report none.
+func filteredFields(e *models.QueryHistory) []string {
+	var (
+		fields []string
+		err    error
+	)
+	switch models.NormalizeQueryLanguage(e.QueryLanguage) {
+	case models.QueryLanguageLogchefQL:
+		fields, err = logchefql.FilteredFields(e.QueryText)
+	case models.QueryLanguageClickHouseSQL:
+		fields, err = clickhouse.FilteredColumns(e.QueryText)
+	}
+	if err != nil {
+		return nil
+	}
+	return fields
+}
+
+// percentile returns the nearest-rank percentile of values, sorting them.
+func percentile(values []int64, p float64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	slices.Sort(values)
+	rank := int(math.Ceil(float64(len(values))*p)) - 1
+	return values[min(max(rank, 0), len(values)-1)]
+}
+
+func topN[K comparable, V any](m map[K]*V, n int, compare func(a, b *V) int) []V {
+	items := make([]*V, 0, len(m))
+	for _, v := range m {
+		items = append(items, v)
+	}
+	slices.SortFunc(items, compare)
+	out := make([]V, 0, min(n, len(items)))
+	for _, v := range items[:min(n, len(items))] {
+		out = append(out, *v)
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestAggregate(t *testing.T) {
+	entries := []*models.QueryHistory{
+		{ID: 1, UserID: 1, UserEmail: "a@x", SourceID: 10, SourceName: "app", DurationMs: 100, RowCount: 5,
+			QueryLanguage: models.QueryLanguageLogchefQL, QueryText: `service_name = "api" and level = "error"`},
+		{ID: 2, UserID: 1, UserEmail: "a@x", SourceID: 10, SourceName: "app", DurationMs: 12000, RowCount: 7,
+			QueryLanguage: models.QueryLanguageClickHouseSQL, QueryText: "SELECT * FROM logs WHERE service_name = 'web'"},
+		{ID: 3, UserID: 2, UserEmail: "b@x", SourceID: 20, SourceName: "edge", DurationMs: 300,
+			QueryLanguage: models.QueryLanguageClickHouseSQL, QueryText: "SELECT * FROM logs WHERE host = 'a'",
+			Status: models.QueryHistoryStatusError},
+		{ID: 4, UserID: 1, UserEmail: "a@x", SourceID: 10, SourceName: "app", DurationMs: 200,
+			QueryLanguage: models.QueryLanguageLogchefQL, QueryText: `level =`},
+	}
+	r := Aggregate(entries, ReportOptions{Days: 7, SlowQueryThreshold: 10 * time.Second, TopN: 2}, time.Now())
+
+	if r.TotalQueries != 4 || r.FailedQueries != 1 || r.SlowQueries != 1 {
+		t.Errorf("totals = %d/%d/%d, want 4/1/1", r.TotalQueries, r.FailedQueries, r.SlowQueries)
+	}
+	if len(r.TopUsers) != 2 || r.TopUsers[0].UserEmail != "a@x" || r.TopUsers[0].Queries != 3 || r.TopUsers[1].FailedQueries != 1 {
+		t.Errorf("TopUsers = %+v", r.TopUsers)
+	}
+	app := r.TopSources[0]
+	if app.SourceName != "app" || app.Queries != 3 || app.AvgDurationMs != 4100 || app.P95DurationMs != 12000 || app.RowsReturned != 12 {
+		t.Errorf("TopSources[0] = %+v", app)
+	}
+	if len(r.SlowestQueries) != 2 || r.SlowestQueries[0].ID != 2 || r.SlowestQueries[1].ID != 3 {
+		t.Errorf("SlowestQueries = %v, %v", r.SlowestQueries[0].ID, r.SlowestQueries[1].ID)
+	}
+	// Failed and unparseable queries don't count toward field usage.
+	if len(r.TopFields) != 2 || r.TopFields[0].Field != "service_name" || r.TopFields[0].Queries != 2 || r.TopFields[1].Field != "level" {
+		t.Errorf("TopFields = %+v", r.TopFields)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	if got := percentile(nil, 0.95); got != 0 {
+		t.Errorf("percentile(nil) = %d, want 0", got)
+	}
+	values := make([]int64, 0, 100)
+	for i := 100; i >= 1; i-- {
+		values = append(values, int64(i))
+	}
+	if got := percentile(values, 0.95); got != 95 {
+		t.Errorf("percentile(1..100, 0.95) = %d, want 95", got)
+	}
+	if got := percentile([]int64{7}, 0.95); got != 7 {
+		t.Errorf("percentile([7]) = %d, want 7", got)
+	}
+}
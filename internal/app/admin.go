@@ -0,0 +1,348 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mr-karan/logchef/internal/auth"
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/secrets"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/internal/store/sqlite"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// adminUsage lists the admin subcommands.
+const adminUsage = `usage: logchef [-config config.toml] admin <command> [flags]
+
+Runs directly against the metadata database in config, without the server or
+SSO, for bootstrap automation and recovery.
+
+commands:
+  user add         -email E -name N [-role admin|member] [-password-env VAR]
+  user list        [-json]
+  team create      -name N [-description D]
+  team add-member  -team NAME|ID -email E [-role admin|editor|member|viewer]
+  source link      -team NAME|ID -source NAME|ID
+  secrets encrypt
+  backup           -out FILE
+  restore          -from FILE
+`
+
+// ErrAdminUsage is returned for an unknown or incomplete admin command; the
+// caller prints adminUsage.
+var ErrAdminUsage = errors.New("invalid admin command")
+
+// AdminUsage returns the admin subcommand help.
+func AdminUsage() string { return adminUsage }
+
+// RunAdmin runs an admin subcommand ("logchef admin user add ...") against
+// the metadata store named in the config, writing results to out.
+func RunAdmin(ctx context.Context, configPath string, args []string, out io.Writer) error {
+	// Most commands are "<noun> <verb>"; backup and restore are one word.
+	var name string
+	switch {
+	case len(args) >= 1 && (args[0] == "backup" || args[0] == "restore"):
+		name, args = args[0], args[1:]
+	case len(args) >= 2:
+		name, args = args[0]+" "+args[1], args[2:]
+	default:
+		return ErrAdminUsage
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	cmd := &adminCmd{cfg: cfg, log: log, out: out}
+
+	// restore must not open the database it is about to replace.
+	if name != "restore" {
+		db, err := openStore(ctx, cfg, log)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		cmd.db = db
+	}
+	return cmd.run(ctx, name, args)
+}
+
+type adminCmd struct {
+	cfg *config.Config
+	db  store.Store
+	log *slog.Logger
+	out io.Writer
+}
+
+func (a *adminCmd) run(ctx context.Context, name string, args []string) error {
+	fs := flag.NewFlagSet("admin "+name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	switch name {
+	case "user add":
+		email := fs.String("email", "", "")
+		fullName := fs.String("name", "", "")
+		role := fs.String("role", string(models.UserRoleMember), "")
+		passwordEnv := fs.String("password-env", "", "")
+		if err := parseAdminFlags(fs, args, "email", "name"); err != nil {
+			return err
+		}
+		return a.userAdd(ctx, *email, *fullName, models.UserRole(*role), *passwordEnv)
+	case "user list":
+		asJSON := fs.Bool("json", false, "")
+		if err := parseAdminFlags(fs, args); err != nil {
+			return err
+		}
+		return a.userList(ctx, *asJSON)
+	case "team create":
+		teamName := fs.String("name", "", "")
+		description := fs.String("description", "", "")
+		if err := parseAdminFlags(fs, args, "name"); err != nil {
+			return err
+		}
+		return a.teamCreate(ctx, *teamName, *description)
+	case "team add-member":
+		team := fs.String("team", "", "")
+		email := fs.String("email", "", "")
+		role := fs.String("role", string(models.TeamRoleMember), "")
+		if err := parseAdminFlags(fs, args, "team", "email"); err != nil {
+			return err
+		}
+		return a.teamAddMember(ctx, *team, *email, models.TeamRole(*role))
+	case "source link":
+		team := fs.String("team", "", "")
+		source := fs.String("source", "", "")
+		if err := parseAdminFlags(fs, args, "team", "source"); err != nil {
+			return err
+		}
+		return a.sourceLink(ctx, *team, *source)
+	case "secrets encrypt":
+		if err := parseAdminFlags(fs, args); err != nil {
+			return err
+		}
+		return a.secretsEncrypt(ctx)
+	case "backup":
+		dest := fs.String("out", "", "")
+		if err := parseAdminFlags(fs, args, "out"); err != nil {
+			return err
+		}
+		return a.backup(ctx, *dest)
+	case "restore":
+		src := fs.String("from", "", "")
+		if err := parseAdminFlags(fs, args, "from"); err != nil {
+			return err
+		}
+		return a.restore(ctx, *src)
+	default:
+		return fmt.Errorf("%w: unknown command %q", ErrAdminUsage, name)
+	}
+}
+
+// parseAdminFlags parses args into fs and checks the required flags are set.
+func parseAdminFlags(fs *flag.FlagSet, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", ErrAdminUsage, err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrAdminUsage, fs.Arg(0))
+	}
+	for _, name := range required {
+		if strings.TrimSpace(fs.Lookup(name).Value.String()) == "" {
+			return fmt.Errorf("%w: -%s is required", ErrAdminUsage, name)
+		}
+	}
+	return nil
+}
+
+func (a *adminCmd) userAdd(ctx context.Context, email, fullName string, role models.UserRole, passwordEnv string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+	var password string
+	if passwordEnv != "" {
+		password = os.Getenv(passwordEnv)
+		if password == "" {
+			return fmt.Errorf("environment variable %s is empty or not set", passwordEnv)
+		}
+	}
+
+	user, err := core.CreateUser(ctx, a.db, a.log, email, fullName, role, models.UserStatusActive)
+	if err != nil {
+		return err
+	}
+	if password != "" {
+		// Only usable for sign-in when [auth.local] is enabled.
+		hash, err := auth.HashLocalPassword(password)
+		if err != nil {
+			return fmt.Errorf("hashing password: %w", err)
+		}
+		if err := a.db.SetUserPasswordHash(ctx, user.ID, hash); err != nil {
+			return err
+		}
+	}
+	a.audit(ctx, models.AuditActionUserCreate, models.AuditResourceUser, strconv.FormatInt(int64(user.ID), 10), nil,
+		map[string]any{"email": user.Email, "role": user.Role, "password_set": password != ""})
+	fmt.Fprintf(a.out, "created user %d %s (%s)\n", user.ID, user.Email, user.Role)
+	return nil
+}
+
+func (a *adminCmd) userList(ctx context.Context, asJSON bool) error {
+	users, err := core.ListUsers(ctx, a.db)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		enc := json.NewEncoder(a.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(users)
+	}
+	tw := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tEMAIL\tNAME\tROLE\tSTATUS")
+	for _, u := range users {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", u.ID, u.Email, u.FullName, u.Role, u.Status)
+	}
+	return tw.Flush()
+}
+
+func (a *adminCmd) teamCreate(ctx context.Context, name, description string) error {
+	team, err := core.CreateTeam(ctx, a.db, a.log, strings.TrimSpace(name), description)
+	if err != nil {
+		return err
+	}
+	a.audit(ctx, models.AuditActionTeamCreate, models.AuditResourceTeam, strconv.FormatInt(int64(team.ID), 10), &team.ID,
+		map[string]any{"name": team.Name})
+	fmt.Fprintf(a.out, "created team %d %s\n", team.ID, team.Name)
+	return nil
+}
+
+func (a *adminCmd) teamAddMember(ctx context.Context, teamRef, email string, role models.TeamRole) error {
+	team, err := a.resolveTeam(ctx, teamRef)
+	if err != nil {
+		return err
+	}
+	user, err := core.GetUserByEmail(ctx, a.db, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return fmt.Errorf("user %q: %w", email, err)
+	}
+	if err := core.AddTeamMember(ctx, a.db, a.log, team.ID, user.ID, role); err != nil {
+		return err
+	}
+	a.audit(ctx, models.AuditActionTeamMemberAdd, models.AuditResourceTeamMember, fmt.Sprintf("%d:%d", team.ID, user.ID), &team.ID,
+		map[string]any{"user_id": user.ID, "email": user.Email, "role": role})
+	fmt.Fprintf(a.out, "added %s to team %s as %s\n", user.Email, team.Name, role)
+	return nil
+}
+
+func (a *adminCmd) sourceLink(ctx context.Context, teamRef, sourceRef string) error {
+	team, err := a.resolveTeam(ctx, teamRef)
+	if err != nil {
+		return err
+	}
+	source, err := a.resolveSource(ctx, sourceRef)
+	if err != nil {
+		return err
+	}
+	if err := core.AddTeamSource(ctx, a.db, a.log, team.ID, source.ID); err != nil {
+		return err
+	}
+	a.audit(ctx, models.AuditActionTeamSourceAdd, models.AuditResourceTeamSource, fmt.Sprintf("%d:%d", team.ID, source.ID), &team.ID,
+		map[string]any{"source_id": source.ID, "source_name": source.Name})
+	fmt.Fprintf(a.out, "linked source %s to team %s\n", source.Name, team.Name)
+	return nil
+}
+
+// secretsEncrypt seals the source secrets stored in plaintext, and re-seals
+// those under a previous key, with secrets.encryption_key.
+func (a *adminCmd) secretsEncrypt(ctx context.Context) error {
+	n, err := a.db.SealSourceSecrets(ctx)
+	if errors.Is(err, secrets.ErrNoKey) {
+		return errors.New("set secrets.encryption_key (or LOGCHEF_SECRETS__ENCRYPTION_KEY) to encrypt source secrets")
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.out, "encrypted the secrets of %d source(s)\n", n)
+	return nil
+}
+
+func (a *adminCmd) backup(ctx context.Context, dest string) error {
+	backuper, ok := a.db.(store.Backuper)
+	if !ok {
+		return errors.New("online backup is only available for the SQLite backend; back up Postgres with pg_dump")
+	}
+	if err := backuper.Backup(ctx, dest); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.out, "wrote backup to %s\n", dest)
+	return nil
+}
+
+func (a *adminCmd) restore(ctx context.Context, src string) error {
+	if a.cfg.Database.Driver == "postgres" {
+		return errors.New("restore is only available for the SQLite backend; restore Postgres with pg_restore")
+	}
+	if err := sqlite.StageRestore(ctx, a.cfg.SQLite.Path, src); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.out, "verified %s and staged it for %s; restart logchef to apply\n", src, a.cfg.SQLite.Path)
+	return nil
+}
+
+// resolveTeam looks a team up by ID, or by name when ref isn't a number.
+func (a *adminCmd) resolveTeam(ctx context.Context, ref string) (*models.Team, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return core.GetTeam(ctx, a.db, models.TeamID(id))
+	}
+	team, err := a.db.GetTeamByName(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("team %q: %w", ref, err)
+	}
+	return team, nil
+}
+
+// resolveSource looks a source up by ID, or by name when ref isn't a number.
+func (a *adminCmd) resolveSource(ctx context.Context, ref string) (*models.Source, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		source, err := a.db.GetSource(ctx, models.SourceID(id))
+		if err != nil {
+			return nil, fmt.Errorf("source %d: %w", id, err)
+		}
+		return source, nil
+	}
+	source, err := a.db.GetSourceByNameForProvisioning(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("source %q: %w", ref, err)
+	}
+	return source, nil
+}
+
+// audit records a change made from the command line. Failures are logged,
+// not returned: the change itself has already been made.
+func (a *adminCmd) audit(ctx context.Context, action models.AuditAction, resourceType, resourceID string, teamID *models.TeamID, details map[string]any) {
+	details["via"] = "cli"
+	raw, err := json.Marshal(details)
+	if err != nil {
+		a.log.Warn("failed to encode audit details", "error", err, "action", action)
+		return
+	}
+	event := &models.AuditEvent{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		TeamID:       teamID,
+		Details:      raw,
+	}
+	if err := a.db.InsertAuditEvent(ctx, event); err != nil {
+		a.log.Warn("failed to record audit event", "error", err, "action", action)
+	}
+}
@@ -0,0 +1,207 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/store/sqlite"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// adminTestConfig is a minimal config; %DB% is replaced with the SQLite path.
+const adminTestConfig = `
+[auth]
+admin_emails = ["admin@example.com"]
+api_token_secret = "0123456789abcdef0123456789abcdef"
+
+[oidc]
+provider_url = "http://localhost/dex"
+auth_url = "http://localhost/dex/auth"
+token_url = "http://localhost/dex/token"
+client_id = "logchef"
+redirect_url = "http://localhost/callback"
+
+[sqlite]
+path = "%DB%"
+`
+
+// newAdminTestEnv writes a config for a fresh SQLite database holding one
+// source, "nginx", and returns the config and database paths.
+func newAdminTestEnv(t *testing.T) (configPath, dbPath string) {
+	t.Helper()
+	dir := t.TempDir()
+	dbPath = filepath.Join(dir, "logchef.db")
+	configPath = filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(configPath, []byte(strings.ReplaceAll(adminTestConfig, "%DB%", dbPath)), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	db := openAdminTestDB(t, dbPath)
+	source := &models.Source{Name: "nginx", Connection: models.ConnectionInfo{
+		Host: "ch:9000", Username: "default", Database: "default", TableName: "nginx",
+	}}
+	if err := db.CreateSource(context.Background(), source); err != nil {
+		t.Fatalf("CreateSource: %v", err)
+	}
+	_ = db.Close()
+	return configPath, dbPath
+}
+
+func openAdminTestDB(t *testing.T, path string) *sqlite.DB {
+	t.Helper()
+	db, err := sqlite.New(context.Background(), sqlite.Options{
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Config: config.SQLiteConfig{Path: path},
+	})
+	if err != nil {
+		t.Fatalf("sqlite.New: %v", err)
+	}
+	return db
+}
+
+func TestRunAdmin(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     [][]string // commands run first, each expected to succeed
+		args      []string
+		wantErr   error // nil: success; errAny: any error
+		wantOut   string
+		wantAudit models.AuditAction
+	}{
+		{
+			name:      "user add",
+			args:      []string{"user", "add", "-email", "Alice@Example.com", "-name", "Alice"},
+			wantOut:   "created user 1 alice@example.com (member)",
+			wantAudit: models.AuditActionUserCreate,
+		},
+		{
+			name:    "user add duplicate",
+			setup:   [][]string{{"user", "add", "-email", "alice@example.com", "-name", "Alice"}},
+			args:    []string{"user", "add", "-email", "alice@example.com", "-name", "Alice again"},
+			wantErr: errAny,
+		},
+		{
+			name:    "user add missing name",
+			args:    []string{"user", "add", "-email", "alice@example.com"},
+			wantErr: ErrAdminUsage,
+		},
+		{
+			name:    "user list",
+			setup:   [][]string{{"user", "add", "-email", "alice@example.com", "-name", "Alice"}},
+			args:    []string{"user", "list"},
+			wantOut: "alice@example.com",
+		},
+		{
+			name:    "user list unknown flag",
+			args:    []string{"user", "list", "-yaml"},
+			wantErr: ErrAdminUsage,
+		},
+		{
+			name:      "team create",
+			args:      []string{"team", "create", "-name", "platform", "-description", "Platform team"},
+			wantOut:   "created team 1 platform",
+			wantAudit: models.AuditActionTeamCreate,
+		},
+		{
+			name:    "team create duplicate",
+			setup:   [][]string{{"team", "create", "-name", "platform"}},
+			args:    []string{"team", "create", "-name", "platform"},
+			wantErr: errAny,
+		},
+		{
+			name: "team add-member",
+			setup: [][]string{
+				{"team", "create", "-name", "platform"},
+				{"user", "add", "-email", "alice@example.com", "-name", "Alice"},
+			},
+			args:      []string{"team", "add-member", "-team", "platform", "-email", "alice@example.com", "-role", "editor"},
+			wantOut:   "added alice@example.com to team platform as editor",
+			wantAudit: models.AuditActionTeamMemberAdd,
+		},
+		{
+			name:    "team add-member unknown user",
+			setup:   [][]string{{"team", "create", "-name", "platform"}},
+			args:    []string{"team", "add-member", "-team", "platform", "-email", "nobody@example.com"},
+			wantErr: errAny,
+		},
+		{
+			name:    "team add-member unknown team",
+			setup:   [][]string{{"user", "add", "-email", "alice@example.com", "-name", "Alice"}},
+			args:    []string{"team", "add-member", "-team", "ghosts", "-email", "alice@example.com"},
+			wantErr: errAny,
+		},
+		{
+			name:      "source link",
+			setup:     [][]string{{"team", "create", "-name", "platform"}},
+			args:      []string{"source", "link", "-team", "1", "-source", "nginx"},
+			wantOut:   "linked source nginx to team platform",
+			wantAudit: models.AuditActionTeamSourceAdd,
+		},
+		{
+			name:    "source link unknown source",
+			setup:   [][]string{{"team", "create", "-name", "platform"}},
+			args:    []string{"source", "link", "-team", "platform", "-source", "missing"},
+			wantErr: errAny,
+		},
+		{
+			name:    "source link stray argument",
+			args:    []string{"source", "link", "-team", "platform", "-source", "nginx", "extra"},
+			wantErr: ErrAdminUsage,
+		},
+		{
+			name:    "unknown command",
+			args:    []string{"user", "delete"},
+			wantErr: ErrAdminUsage,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			configPath, dbPath := newAdminTestEnv(t)
+			for _, args := range tc.setup {
+				if err := RunAdmin(ctx, configPath, args, io.Discard); err != nil {
+					t.Fatalf("setup %v: %v", args, err)
+				}
+			}
+
+			var out bytes.Buffer
+			err := RunAdmin(ctx, configPath, tc.args, &out)
+			switch {
+			case tc.wantErr == nil && err != nil:
+				t.Fatalf("RunAdmin(%v): %v", tc.args, err)
+			case tc.wantErr != nil && err == nil:
+				t.Fatalf("RunAdmin(%v) succeeded, want an error", tc.args)
+			case tc.wantErr != nil && tc.wantErr != errAny && !errors.Is(err, tc.wantErr):
+				t.Fatalf("RunAdmin(%v) err = %v, want %v", tc.args, err, tc.wantErr)
+			}
+			if !strings.Contains(out.String(), tc.wantOut) {
+				t.Errorf("output = %q, want it to contain %q", out.String(), tc.wantOut)
+			}
+
+			if tc.wantAudit == "" {
+				return
+			}
+			db := openAdminTestDB(t, dbPath)
+			defer db.Close()
+			events, err := db.ListAuditEvents(ctx, models.AuditEventFilter{Action: tc.wantAudit, Limit: models.AuditEventsDefaultLimit})
+			if err != nil {
+				t.Fatalf("ListAuditEvents: %v", err)
+			}
+			if len(events) != 1 || !strings.Contains(string(events[0].Details), `"via":"cli"`) {
+				t.Errorf("audit events for %s = %+v, want one recorded via the cli", tc.wantAudit, events)
+			}
+		})
+	}
+}
+
+// errAny stands for any error in TestRunAdmin's cases.
+var errAny = errors.New("any error")
@@ -9,33 +9,64 @@ import (
 	"time"
 
 	"github.com/mr-karan/logchef/internal/alerts"
+	"github.com/mr-karan/logchef/internal/analytics"
+	"github.com/mr-karan/logchef/internal/artifacts"
+	"github.com/mr-karan/logchef/internal/audit"
 	"github.com/mr-karan/logchef/internal/auth"
 	"github.com/mr-karan/logchef/internal/clickhouse"
 	"github.com/mr-karan/logchef/internal/config"
 	"github.com/mr-karan/logchef/internal/core"
 	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/events"
+	"github.com/mr-karan/logchef/internal/metrics"
 	"github.com/mr-karan/logchef/internal/provisioning"
+	"github.com/mr-karan/logchef/internal/rollups"
+	"github.com/mr-karan/logchef/internal/schemadrift"
+	"github.com/mr-karan/logchef/internal/secrets"
 	"github.com/mr-karan/logchef/internal/server"
+	"github.com/mr-karan/logchef/internal/slo"
+	"github.com/mr-karan/logchef/internal/sourcealerts"
+	"github.com/mr-karan/logchef/internal/sourcestats"
 	"github.com/mr-karan/logchef/internal/store"
 	"github.com/mr-karan/logchef/internal/store/postgres"
 	"github.com/mr-karan/logchef/internal/store/sqlite"
+	"github.com/mr-karan/logchef/internal/syslog"
+	"github.com/mr-karan/logchef/internal/tracing"
 	"github.com/mr-karan/logchef/internal/victorialogs"
+	"github.com/mr-karan/logchef/internal/webhooks"
 	"github.com/mr-karan/logchef/pkg/logger"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
 // App represents the core application context, holding dependencies and configuration.
 type App struct {
-	Config      *config.Config
-	SQLite      store.Store
-	ClickHouse  *clickhouse.Manager
-	Datasources *datasource.Service
-	Logger      *slog.Logger
-	server      *server.Server
-	WebFS       http.FileSystem
-	BuildInfo   string
-	Version     string
-	Alerts      *alerts.Manager
+	Config       *config.Config
+	SQLite       store.Store
+	ClickHouse   *clickhouse.Manager
+	Datasources  *datasource.Service
+	Logger       *slog.Logger
+	server       *server.Server
+	WebFS        http.FileSystem
+	BuildInfo    string
+	Version      string
+	Alerts       *alerts.Manager
+	Audit        *audit.Writer
+	Rollups      *rollups.Manager
+	SourceStats  *sourcestats.Manager
+	SchemaDrift  *schemadrift.Manager
+	SourceAlerts *sourcealerts.Manager
+	Analytics    *analytics.Manager
+	SLOs         *slo.Manager
+	Syslog       *syslog.Manager
+	Artifacts    artifacts.Store
+	Webhooks     *webhooks.Dispatcher
+
+	// AlertDispatcher groups alert notifications per destination; nil when
+	// alerts.group_interval is unset.
+	AlertDispatcher *alerts.Dispatcher
+
+	// shutdownTracing flushes spans still queued for export.
+	shutdownTracing func(context.Context) error
 }
 
 // Options contains configuration needed when creating a new App instance.
@@ -69,26 +100,9 @@ func New(opts Options) (*App, error) {
 func (a *App) Initialize(ctx context.Context) error {
 	var err error
 
-	// Initialize the metadata backend selected by config. SQLite is the default
-	// single-binary backend; Postgres is opt-in for multi-replica deployments.
 	// The field is named SQLite for historical reasons but holds a store.Store.
-	switch a.Config.Database.Driver {
-	case "postgres":
-		a.SQLite, err = postgres.New(ctx, postgres.Options{
-			Config: a.Config.Postgres,
-			Logger: a.Logger,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to initialize postgres: %w", err)
-		}
-	default: // "sqlite" (config validation guarantees one of these two)
-		a.SQLite, err = sqlite.New(ctx, sqlite.Options{
-			Config: a.Config.SQLite,
-			Logger: a.Logger,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to initialize sqlite: %w", err)
-		}
+	if a.SQLite, err = openStore(ctx, a.Config, a.Logger); err != nil {
+		return err
 	}
 
 	// Initialize admin users based on configuration.
@@ -108,11 +122,27 @@ func (a *App) Initialize(ctx context.Context) error {
 	a.Config = config.LoadRuntimeConfig(ctx, a.Config, a.SQLite)
 	a.Logger.Info("runtime configuration loaded from database and config.toml")
 
-	// Initialize ClickHouse connection manager.
+	// Query pipeline spans are exported over OTLP when tracing is enabled.
+	a.shutdownTracing = tracing.Setup(a.Config.Tracing, a.Logger)
+
+	// Large generated artifacts (exports, notebook snapshots) live on local
+	// disk or in S3, not in the metadata database.
+	a.Artifacts, err = artifacts.New(ctx, a.Config.Storage)
+	if err != nil {
+		return fmt.Errorf("failed to initialize artifact storage: %w", err)
+	}
+	a.Logger.Info("artifact storage initialized", "backend", a.Artifacts.Name())
+
+	// Initialize ClickHouse connection manager. Secret references in source
+	// connections are resolved by both providers as they connect.
+	secretResolver := secrets.NewResolver(a.Config.Secrets)
 	a.ClickHouse = clickhouse.NewManager(a.Logger)
+	a.ClickHouse.SetSecretResolver(secretResolver)
+	vlProvider := victorialogs.NewProvider(a.Logger)
+	vlProvider.SetSecretResolver(secretResolver)
 	a.Datasources = datasource.NewService(a.SQLite, a.Logger)
 	a.Datasources.Register(datasource.NewClickHouseProvider(a.ClickHouse, a.Logger))
-	a.Datasources.Register(victorialogs.NewProvider(a.Logger))
+	a.Datasources.Register(vlProvider)
 
 	// Initialize OIDC Provider.
 	// This is optional; if OIDC is not configured, auth features relying on it might be disabled.
@@ -154,17 +184,111 @@ func (a *App) Initialize(ctx context.Context) error {
 	// Use 0 to trigger the default interval defined in the manager.
 	a.ClickHouse.StartBackgroundHealthChecks(0)
 
-	// Initialize alerts manager with dynamic senders that read config from DB
-	emailSender := alerts.NewDynamicEmailSender(a.SQLite, a.Logger)
+	// Probe every source in the background so listings read cached health.
+	a.Datasources.StartHealthProbes(ctx, 0)
+
+	// Initialize alerts manager with a channel registry whose senders read
+	// their config from the DB at delivery time.
 	webhookSender := alerts.NewDynamicWebhookSender(a.SQLite, a.Logger)
-	alertSender := alerts.NewMultiSender(emailSender, webhookSender)
+	alertSender := alerts.NewChannelRegistry(alerts.ChannelRegistryOptions{Logger: a.Logger})
+	alertSender.Register(models.AlertChannelEmail, alerts.NewDynamicEmailSender(a.SQLite, a.Logger))
+	alertSender.Register(models.AlertChannelWebhook, webhookSender)
+	alertSender.Register(models.AlertChannelSlack, webhookSender)
+
+	// SLOs are evaluated in the background; burn-rate alerts read their burn
+	// rates through the same manager.
+	a.SLOs = slo.NewManager(slo.Options{
+		Config:      a.Config.SLOs,
+		DB:          a.SQLite,
+		Datasources: a.Datasources,
+		Logger:      a.Logger,
+	})
+
+	// Alert rule notifications can be grouped per destination; schema drift
+	// and source alerts below always go out immediately.
+	var ruleSender alerts.AlertSender = alertSender
+	if a.Config.Alerts.GroupInterval > 0 {
+		a.AlertDispatcher = alerts.NewDispatcher(alertSender, webhookSender, alerts.DispatcherOptions{
+			FlushInterval:  a.Config.Alerts.GroupInterval,
+			RoomMaxPerHour: a.Config.Alerts.RoomMaxPerHour,
+			Logger:         a.Logger,
+		})
+		ruleSender = a.AlertDispatcher
+	}
 
 	a.Alerts = alerts.NewManager(alerts.Options{
 		Config:      a.Config.Alerts,
 		DB:          a.SQLite,
 		Datasources: a.Datasources,
 		Logger:      a.Logger,
+		Sender:      ruleSender,
+		BurnRates:   a.SLOs,
+	})
+
+	// Audit events are persisted off the request path by a background writer.
+	a.Audit = audit.NewWriter(audit.Options{Store: a.SQLite, Logger: a.Logger})
+	a.Audit.Start()
+
+	// Cross-cutting subsystems follow what happens elsewhere on the event bus:
+	// the audit trail records source health changes, metrics count events.
+	a.Audit.ObserveEvents(events.Default())
+	metrics.ObserveEvents(events.Default())
+
+	// Lifecycle events published by core are delivered to registered webhooks.
+	a.Webhooks = webhooks.NewDispatcher(webhooks.Options{
+		Config: a.Config.Webhooks,
+		DB:     a.SQLite,
+		Logger: a.Logger,
+	})
+
+	// Source rollups are maintained in the background and read by trend queries.
+	a.Rollups = rollups.NewManager(rollups.Options{
+		Config:     a.Config.Rollups,
+		DB:         a.SQLite,
+		ClickHouse: a.ClickHouse,
+		Logger:     a.Logger,
+	})
+
+	// Daily source storage snapshots back the stats history API.
+	a.SourceStats = sourcestats.NewManager(sourcestats.Options{
+		Config:     a.Config.SourceStats,
+		DB:         a.SQLite,
+		ClickHouse: a.ClickHouse,
+		Logger:     a.Logger,
+	})
+
+	// Column history per source; drift is announced over the alert channels.
+	a.SchemaDrift = schemadrift.NewManager(schemadrift.Options{
+		Config:      a.Config.SchemaDrift,
+		DB:          a.SQLite,
+		Datasources: a.Datasources,
+		Sender:      alertSender,
+		Logger:      a.Logger,
+	})
+
+	// Source degradation alerts, evaluated after each health probe cycle and
+	// announced over the alert channels.
+	a.SourceAlerts = sourcealerts.NewManager(sourcealerts.Options{
+		Config:      a.Config.SourceAlerts,
+		DB:          a.SQLite,
+		Datasources: a.Datasources,
 		Sender:      alertSender,
+		Logger:      a.Logger,
+	})
+
+	// Query usage reports over the recorded history, published as gauges.
+	a.Analytics = analytics.NewManager(analytics.Options{
+		Config: a.Config.QueryAnalytics,
+		DB:     a.SQLite,
+		Logger: a.Logger,
+	})
+
+	// Syslog listeners insert into their sources through the datasource layer.
+	a.Syslog = syslog.NewManager(syslog.Options{
+		Config:      a.Config.Ingest,
+		DB:          a.SQLite,
+		Datasources: a.Datasources,
+		Logger:      a.Logger,
 	})
 
 	// Initialize HTTP server with alerts manager for manual resolution.
@@ -174,6 +298,14 @@ func (a *App) Initialize(ctx context.Context) error {
 		ClickHouse:    a.ClickHouse,
 		Datasources:   a.Datasources,
 		AlertsManager: a.Alerts,
+		Audit:         a.Audit,
+		Rollups:       a.Rollups,
+		SourceStats:   a.SourceStats,
+		SchemaDrift:   a.SchemaDrift,
+		SourceAlerts:  a.SourceAlerts,
+		Analytics:     a.Analytics,
+		Artifacts:     a.Artifacts,
+		Webhooks:      a.Webhooks,
 		OIDCProvider:  oidcProvider,
 		FS:            a.WebFS,
 		Logger:        a.Logger,
@@ -182,8 +314,23 @@ func (a *App) Initialize(ctx context.Context) error {
 	}
 	a.server = server.New(serverOpts) //nolint:contextcheck // starts an app-lifetime cleanup janitor with its own timeout context; no request ctx to propagate
 
+	// Subscribe webhooks first so events from the loops below are delivered.
+	a.Webhooks.Start(ctx)
+
 	// Start the alerts evaluation loop.
+	if a.AlertDispatcher != nil {
+		a.AlertDispatcher.Start(ctx)
+	}
 	a.Alerts.Start(ctx)
+	a.Rollups.Start(ctx)
+	a.SourceStats.Start(ctx)
+	a.SchemaDrift.Start(ctx)
+	a.SourceAlerts.Start(ctx)
+	a.Analytics.Start(ctx)
+	a.SLOs.Start(ctx)
+	if err := a.Syslog.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start syslog listeners: %w", err)
+	}
 
 	return nil
 }
@@ -206,23 +353,69 @@ func (a *App) Shutdown(ctx context.Context) error {
 	// Ensure a shutdown context with timeout exists.
 	if ctx == nil {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel = context.WithTimeout(context.Background(), a.Config.Server.DrainTimeout+10*time.Second)
 		defer cancel()
 	}
 
-	// Create derived contexts with shorter timeouts for each component
+	// Stop admitting queries and let running ones finish, within the drain
+	// timeout, before anything they depend on is stopped.
+	if a.server != nil {
+		drainCtx, drainCancel := context.WithTimeout(ctx, a.Config.Server.DrainTimeout)
+		a.server.Drain(drainCtx)
+		drainCancel()
+	}
+
+	// Create derived contexts with shorter timeouts for each component,
+	// started after the drain so it doesn't eat into them.
 	serverCtx, serverCancel := context.WithTimeout(ctx, 5*time.Second)
 	defer serverCancel()
 
 	clickhouseCtx, clickhouseCancel := context.WithTimeout(ctx, 8*time.Second)
 	defer clickhouseCancel()
 
+	// Alert evaluations in progress finish, so their state is recorded.
 	if a.Alerts != nil {
 		a.Logger.Info("stopping alert manager")
 		a.Alerts.Stop()
 	}
+	// Grouped notifications still buffered go out once evaluation has stopped.
+	if a.AlertDispatcher != nil {
+		a.Logger.Info("flushing grouped alert notifications")
+		a.AlertDispatcher.Stop()
+	}
 
-	// Shutdown server first to stop accepting new requests.
+	if a.Rollups != nil {
+		a.Logger.Info("stopping rollup manager")
+		a.Rollups.Stop()
+	}
+	if a.SourceStats != nil {
+		a.Logger.Info("stopping source stats manager")
+		a.SourceStats.Stop()
+	}
+	if a.SchemaDrift != nil {
+		a.Logger.Info("stopping schema drift manager")
+		a.SchemaDrift.Stop()
+	}
+	if a.SourceAlerts != nil {
+		a.Logger.Info("stopping source alert manager")
+		a.SourceAlerts.Stop()
+	}
+	if a.Analytics != nil {
+		a.Logger.Info("stopping query analytics manager")
+		a.Analytics.Stop()
+	}
+
+	if a.SLOs != nil {
+		a.Logger.Info("stopping slo manager")
+		a.SLOs.Stop()
+	}
+
+	if a.Datasources != nil {
+		a.Logger.Info("stopping source health probes")
+		a.Datasources.StopHealthProbes()
+	}
+
+	// Queries are drained; close the listener and remaining requests.
 	if a.server != nil {
 		a.Logger.Info("shutting down HTTP server")
 
@@ -244,6 +437,31 @@ func (a *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	// Insert syslog messages still buffered while ClickHouse is reachable.
+	if a.Syslog != nil {
+		a.Logger.Info("stopping syslog listeners")
+		a.Syslog.Stop()
+	}
+
+	// Give webhook deliveries still retrying up; queued events are dropped.
+	if a.Webhooks != nil {
+		a.Logger.Info("stopping webhook dispatcher")
+		a.Webhooks.Stop()
+	}
+
+	// Flush queued audit events once no new requests can record any.
+	if a.Audit != nil {
+		a.Logger.Info("flushing audit events")
+		a.Audit.Stop()
+	}
+
+	// Export spans from the last requests before the process exits.
+	if a.shutdownTracing != nil {
+		if err := a.shutdownTracing(ctx); err != nil {
+			a.Logger.Warn("error flushing trace spans", "error", err)
+		}
+	}
+
 	// Close ClickHouse manager (stops health checks and closes clients).
 	if a.ClickHouse != nil {
 		a.Logger.Info("shutting down ClickHouse connections")
@@ -330,6 +548,18 @@ func (a *App) seedSystemSettings(ctx context.Context) error {
 			description: "Maximum number of alert history entries to keep per alert",
 			isSensitive: false,
 		},
+		"alerts.group_interval": {
+			value:       a.Config.Alerts.GroupInterval.String(),
+			valueType:   "duration",
+			description: "Batch notifications per webhook or Slack destination at this interval (0s sends each immediately)",
+			isSensitive: false,
+		},
+		"alerts.room_max_per_hour": {
+			value:       fmt.Sprintf("%d", a.Config.Alerts.RoomMaxPerHour),
+			valueType:   "number",
+			description: "Maximum grouped notifications per webhook or Slack destination per hour (0 = no limit)",
+			isSensitive: false,
+		},
 		"alerts.smtp_host": {
 			value:       "",
 			valueType:   "string",
@@ -501,6 +731,38 @@ func (a *App) seedSystemSettings(ctx context.Context) error {
 	return nil
 }
 
+// openStore opens the metadata backend selected by config. SQLite is the
+// default single-binary backend; Postgres is opt-in for multi-replica
+// deployments.
+func openStore(ctx context.Context, cfg *config.Config, log *slog.Logger) (store.Store, error) {
+	box, err := secrets.FromConfig(cfg.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	switch cfg.Database.Driver {
+	case "postgres":
+		db, err := postgres.New(ctx, postgres.Options{
+			Config:  cfg.Postgres,
+			Logger:  log,
+			Secrets: box,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize postgres: %w", err)
+		}
+		return db, nil
+	default: // "sqlite" (config validation guarantees one of these two)
+		db, err := sqlite.New(ctx, sqlite.Options{
+			Config:  cfg.SQLite,
+			Logger:  log,
+			Secrets: box,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize sqlite: %w", err)
+		}
+		return db, nil
+	}
+}
+
 // ensureLocalAdmin creates or updates the bootstrap admin for local
 // email+password auth. Idempotent: safe to run on every startup.
 func ensureLocalAdmin(ctx context.Context, cfg *config.Config, db store.Store, log *slog.Logger) error {
@@ -49,8 +49,9 @@ func Run(opts Options) error {
 	<-shutdown
 	app.Logger.Info("received shutdown signal")
 
-	// Create a context with timeout for graceful shutdown phase.
-	shutdownTimeout := 10 * time.Second
+	// Create a context with timeout for graceful shutdown phase: the query
+	// drain, then the components' own shutdown.
+	shutdownTimeout := app.Config.Server.DrainTimeout + 10*time.Second
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer shutdownCancel()
 
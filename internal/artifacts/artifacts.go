@@ -0,0 +1,64 @@
+// Package artifacts stores large generated files — export results and
+// notebook snapshots — outside the metadata database, on local disk or in an
+// S3-compatible bucket. Callers keep an artifact's key alongside its metadata
+// row and delete the object before the row, so an interrupted cleanup is
+// retried rather than leaking the object.
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/config"
+)
+
+// ErrNotFound is returned by Open when no object exists under the key.
+var ErrNotFound = errors.New("artifact not found")
+
+// Store is an object store for artifacts. Keys are slash-separated relative
+// paths such as "exports/<job-id>.csv".
+type Store interface {
+	// Put stores size bytes read from body under key, replacing any existing
+	// object.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Open returns the object's contents, or ErrNotFound.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that downloads the object as fileName without
+	// further authentication until ttl passes, or "" when the backend cannot
+	// sign URLs. Callers fall back to streaming the object through Open.
+	SignedURL(ctx context.Context, key, fileName string, ttl time.Duration) (string, error)
+	// Name identifies the backend in logs.
+	Name() string
+}
+
+// New returns the store selected by cfg.Backend.
+func New(ctx context.Context, cfg config.StorageConfig) (Store, error) {
+	switch cfg.Backend {
+	case "", "local":
+		return NewLocal(cfg.LocalDir)
+	case "s3":
+		return NewS3(ctx, cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown artifact storage backend %q", cfg.Backend)
+	}
+}
+
+// validKey rejects keys that could escape the store's root: absolute paths,
+// empty segments and "." or ".." segments.
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return fmt.Errorf("invalid artifact key %q", key)
+	}
+	for segment := range strings.SplitSeq(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid artifact key %q", key)
+		}
+	}
+	return nil
+}
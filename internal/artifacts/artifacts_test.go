@@ -0,0 +1,183 @@
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/config"
+)
+
+// roundTrip exercises the Store contract shared by every backend.
+func roundTrip(t *testing.T, store Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	body := "id,msg\n1,hello\n"
+	if err := store.Put(ctx, "exports/job-1.csv", strings.NewReader(body), int64(len(body)), "text/csv"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	rc, err := store.Open(ctx, "exports/job-1.csv")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil || string(got) != body {
+		t.Fatalf("Open read %q, %v; want %q", got, err, body)
+	}
+
+	if err := store.Delete(ctx, "exports/job-1.csv"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Open(ctx, "exports/job-1.csv"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Open after delete: %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, "exports/job-1.csv"); err != nil {
+		t.Fatalf("Delete of a missing artifact: %v", err)
+	}
+	if err := store.Put(ctx, "../escape", strings.NewReader(""), 0, ""); err == nil {
+		t.Fatal("Put accepted a key escaping the store")
+	}
+}
+
+func TestLocal(t *testing.T) {
+	t.Parallel()
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	roundTrip(t, store)
+
+	if err := store.Put(context.Background(), "short", strings.NewReader("abc"), 5, ""); err == nil {
+		t.Error("Put accepted a body shorter than its declared size")
+	}
+	if url, err := store.SignedURL(context.Background(), "short", "x.csv", time.Minute); err != nil || url != "" {
+		t.Errorf("SignedURL = %q, %v; local storage has no signed URLs", url, err)
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	t.Parallel()
+	for _, key := range []string{"exports/a.csv", "notebooks/1/snap.html"} {
+		if err := validKey(key); err != nil {
+			t.Errorf("validKey(%q) = %v", key, err)
+		}
+	}
+	for _, key := range []string{"", "/abs", "a//b", "a/../b", "./a", `a\b`, "a/"} {
+		if validKey(key) == nil {
+			t.Errorf("validKey(%q) accepted an unsafe key", key)
+		}
+	}
+}
+
+// fakeS3 is a minimal path-style S3 that checks requests are signed.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+		r.Header.Get("X-Amz-Content-Sha256") != unsignedPayload {
+		http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = body
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3(t *testing.T) {
+	t.Parallel()
+	fake := &fakeS3{objects: map[string][]byte{}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	cfg := config.S3StorageConfig{
+		Bucket:    "artifacts",
+		Region:    "us-east-1",
+		Endpoint:  srv.URL,
+		Prefix:    "logchef/",
+		PathStyle: true,
+		AccessKey: "AKID",
+		SecretKey: "secret",
+	}
+	store, err := NewS3(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+	roundTrip(t, store)
+
+	body := "x"
+	if err := store.Put(context.Background(), "notebooks/snap 1.html", strings.NewReader(body), 1, "text/html"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, ok := fake.objects["/artifacts/logchef/notebooks/snap 1.html"]; !ok {
+		t.Errorf("object stored under unexpected path; have %v", fake.objects)
+	}
+
+	signed, err := store.SignedURL(context.Background(), "notebooks/snap 1.html", "report.html", 10*time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("parse signed url: %v", err)
+	}
+	q := u.Query()
+	if q.Get("X-Amz-Signature") == "" || q.Get("X-Amz-Expires") != "600" {
+		t.Errorf("signed url missing signature or expiry: %s", signed)
+	}
+	if got := q.Get("response-content-disposition"); got != "attachment; filename=report.html" {
+		t.Errorf("response-content-disposition = %q", got)
+	}
+
+	cfg.AccessKey = "WRONG"
+	denied, err := NewS3(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+	err = denied.Put(context.Background(), "a", strings.NewReader("x"), 1, "")
+	if err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("Put with bad credentials: %v, want AccessDenied", err)
+	}
+}
+
+func TestS3VirtualHostURL(t *testing.T) {
+	t.Parallel()
+	store, err := NewS3(context.Background(), config.S3StorageConfig{
+		Bucket: "b", Region: "eu-west-1", AccessKey: "k", SecretKey: "s",
+	})
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+	u, err := store.objectURL("exports/a.csv")
+	if err != nil {
+		t.Fatalf("objectURL: %v", err)
+	}
+	if got := u.String(); got != "https://b.s3.eu-west-1.amazonaws.com/exports/a.csv" {
+		t.Errorf("objectURL = %s", got)
+	}
+}
@@ -0,0 +1,218 @@
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+
+	"github.com/mr-karan/logchef/internal/config"
+)
+
+// unsignedPayload tells S3 the body is not part of the signature, so uploads
+// can stream without hashing the artifact first.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3 stores artifacts in an S3 bucket or an S3-compatible service. Requests
+// are signed with SigV4 and sent over plain HTTP; only the three object
+// operations and presigned GETs are needed.
+type S3 struct {
+	cfg      config.S3StorageConfig
+	endpoint *url.URL
+	creds    aws.CredentialsProvider
+	signer   *v4.Signer
+	client   *http.Client
+}
+
+// NewS3 returns a store for cfg's bucket. Static credentials are used when
+// set; otherwise the default AWS credential chain resolves them on first use.
+func NewS3(ctx context.Context, cfg config.S3StorageConfig) (*S3, error) {
+	if cfg.Bucket == "" || cfg.Region == "" {
+		return nil, fmt.Errorf("s3 artifact storage needs a bucket and region")
+	}
+	rawEndpoint := cfg.Endpoint
+	if rawEndpoint == "" {
+		rawEndpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(rawEndpoint, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", cfg.Endpoint)
+	}
+
+	var creds aws.CredentialsProvider
+	if cfg.AccessKey != "" {
+		static := aws.Credentials{AccessKeyID: cfg.AccessKey, SecretAccessKey: cfg.SecretKey, Source: "logchef config"}
+		creds = aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) { return static, nil })
+	} else {
+		awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(cfg.Region))
+		if err != nil {
+			return nil, fmt.Errorf("error loading aws config: %w", err)
+		}
+		creds = awsCfg.Credentials
+	}
+
+	return &S3{
+		cfg:      cfg,
+		endpoint: endpoint,
+		creds:    creds,
+		// S3 signs the path as sent rather than escaping it a second time.
+		signer: v4.NewSigner(func(o *v4.SignerOptions) { o.DisableURIPathEscaping = true }),
+		client: &http.Client{},
+	}, nil
+}
+
+// Name implements Store.
+func (s *S3) Name() string { return "s3" }
+
+// objectURL addresses key in path style (endpoint/bucket/key) or virtual-host
+// style (bucket.endpoint/key).
+func (s *S3) objectURL(key string) (*url.URL, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+	full := s.cfg.Prefix + key
+
+	u := *s.endpoint
+	segments := strings.Split(full, "/")
+	if s.cfg.PathStyle {
+		segments = append([]string{s.cfg.Bucket}, segments...)
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+	}
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+	u.Path = strings.TrimSuffix(s.endpoint.Path, "/") + "/" + strings.Join(segments, "/")
+	u.RawPath = strings.TrimSuffix(s.endpoint.EscapedPath(), "/") + "/" + strings.Join(escaped, "/")
+	return &u, nil
+}
+
+func (s *S3) do(ctx context.Context, method, key string, body io.Reader, size int64, contentType string) (*http.Response, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("error building s3 request: %w", err)
+	}
+	if body != nil {
+		req.ContentLength = size
+		req.Header.Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	creds, err := s.creds.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving s3 credentials: %w", err)
+	}
+	if err := s.signer.SignHTTP(ctx, creds, req, unsignedPayload, "s3", s.cfg.Region, time.Now()); err != nil {
+		return nil, fmt.Errorf("error signing s3 request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s %s: %w", method, key, err)
+	}
+	return resp, nil
+}
+
+// Put implements Store.
+func (s *S3) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if size < 0 {
+		return fmt.Errorf("s3 uploads need the artifact size")
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, body, size, contentType)
+	if err != nil {
+		return err
+	}
+	defer drain(resp)
+	return s3Error(resp, http.MethodPut, key)
+}
+
+// Open implements Store.
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if err := s3Error(resp, http.MethodGet, key); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete implements Store. S3 answers 204 whether or not the object existed.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0, "")
+	if err != nil {
+		return err
+	}
+	defer drain(resp)
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return s3Error(resp, http.MethodDelete, key)
+}
+
+// SignedURL implements Store with a presigned GET that asks S3 to serve the
+// object as an attachment named fileName.
+func (s *S3) SignedURL(ctx context.Context, key, fileName string, ttl time.Duration) (string, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(ttl/time.Second), 10))
+	if fileName != "" {
+		query.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("error building s3 request: %w", err)
+	}
+	creds, err := s.creds.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error resolving s3 credentials: %w", err)
+	}
+	signed, _, err := s.signer.PresignHTTP(ctx, creds, req, unsignedPayload, "s3", s.cfg.Region, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("error presigning s3 url: %w", err)
+	}
+	return signed, nil
+}
+
+// s3Error turns a non-2xx response into an error carrying the start of S3's
+// XML error body, which names the failure (AccessDenied, NoSuchBucket, ...).
+func s3Error(resp *http.Response, method, key string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("s3 %s %s: %s: %s", method, key, resp.Status, strings.TrimSpace(string(detail)))
+}
+
+// drain reads and closes a response body so the connection can be reused.
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
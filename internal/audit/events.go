@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/mr-karan/logchef/internal/events"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// ObserveEvents records events Logchef raises on its own, with no user behind
+// them, from bus: a source.health_changed event becomes a source.health_change
+// entry. Changes users make are recorded by the handlers that make them, which
+// know who acted and from where.
+func (w *Writer) ObserveEvents(bus *events.Bus) (unsubscribe func()) {
+	if w == nil {
+		return func() {}
+	}
+	return events.On(bus, models.EventSourceHealthChanged, func(event models.Event, data models.SourceHealthEventData) {
+		details, err := json.Marshal(map[string]any{
+			"name":            data.Name,
+			"status":          data.Status,
+			"previous_status": data.PreviousStatus,
+			"error":           data.Error,
+		})
+		if err != nil {
+			w.log.Warn("failed to encode audit details", "error", err, "action", models.AuditActionSourceHealth)
+		}
+		w.Record(&models.AuditEvent{
+			Action:       models.AuditActionSourceHealth,
+			ResourceType: models.AuditResourceSource,
+			ResourceID:   strconv.FormatInt(int64(data.SourceID), 10),
+			Details:      details,
+			CreatedAt:    event.OccurredAt,
+		})
+	})
+}
@@ -0,0 +1,126 @@
+// Package audit records the audit trail of sensitive operations (source
+// create/delete, team membership changes, alert changes, raw SQL execution)
+// and the source health changes published on the event bus.
+//
+// Events are handed to a Writer, which persists them from a background
+// goroutine so the request path never waits on the metadata store. Recording
+// is best-effort: when the buffer is full the event is dropped and logged
+// rather than blocking the caller.
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+const (
+	// DefaultBufferSize is the number of events that may be queued before
+	// Record starts dropping.
+	DefaultBufferSize = 1024
+	// writeTimeout bounds a single insert.
+	writeTimeout = 5 * time.Second
+)
+
+// Options configures a Writer.
+type Options struct {
+	Store      store.AuditStore
+	Logger     *slog.Logger
+	BufferSize int
+}
+
+// Writer persists audit events asynchronously. A nil *Writer is valid and
+// discards everything, so callers need no audit-enabled checks.
+type Writer struct {
+	store  store.AuditStore
+	log    *slog.Logger
+	events chan *models.AuditEvent
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewWriter creates a Writer. Call Start to begin persisting events.
+func NewWriter(opts Options) *Writer {
+	size := opts.BufferSize
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	return &Writer{
+		store:  opts.Store,
+		log:    opts.Logger.With("component", "audit_writer"),
+		events: make(chan *models.AuditEvent, size),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start launches the background goroutine that drains queued events.
+func (w *Writer) Start() {
+	if w == nil {
+		return
+	}
+	w.wg.Go(func() {
+		for {
+			select {
+			case event := <-w.events:
+				w.write(event)
+			case <-w.stop:
+				w.drain()
+				return
+			}
+		}
+	})
+}
+
+// Stop stops the writer after persisting every event queued so far. It is safe
+// to call more than once.
+func (w *Writer) Stop() {
+	if w == nil {
+		return
+	}
+	w.stopOnce.Do(func() { close(w.stop) })
+	w.wg.Wait()
+}
+
+// Record queues an event without blocking. CreatedAt defaults to now, so the
+// stored timestamp is when the action happened rather than when the write
+// landed. The event is dropped (and a warning logged) when the buffer is full.
+func (w *Writer) Record(event *models.AuditEvent) {
+	if w == nil || event == nil {
+		return
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	select {
+	case w.events <- event:
+	default:
+		w.log.Warn("audit buffer full, dropping event",
+			"action", event.Action, "resource_type", event.ResourceType, "resource_id", event.ResourceID, "user_email", event.UserEmail)
+	}
+}
+
+// drain persists whatever is still queued at shutdown.
+func (w *Writer) drain() {
+	for {
+		select {
+		case event := <-w.events:
+			w.write(event)
+		default:
+			return
+		}
+	}
+}
+
+func (w *Writer) write(event *models.AuditEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	if err := w.store.InsertAuditEvent(ctx, event); err != nil {
+		w.log.Warn("failed to record audit event", "error", err, "action", event.Action, "resource_id", event.ResourceID)
+	}
+}
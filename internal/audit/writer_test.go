@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mr-karan/logchef/internal/events"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// recordingStore is an in-memory store.AuditStore.
+type recordingStore struct {
+	mu     sync.Mutex
+	events []*models.AuditEvent
+}
+
+func (s *recordingStore) InsertAuditEvent(_ context.Context, event *models.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *recordingStore) ListAuditEvents(context.Context, models.AuditEventFilter) ([]*models.AuditEvent, error) {
+	return nil, nil
+}
+
+func (s *recordingStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.events)
+}
+
+func newTestWriter(s *recordingStore, size int) *Writer {
+	return NewWriter(Options{Store: s, Logger: slog.New(slog.NewTextHandler(io.Discard, nil)), BufferSize: size})
+}
+
+func TestWriterFlushesOnStop(t *testing.T) {
+	t.Parallel()
+
+	s := &recordingStore{}
+	w := newTestWriter(s, 16)
+	w.Start()
+	for range 10 {
+		w.Record(&models.AuditEvent{Action: models.AuditActionSourceCreate})
+	}
+	w.Stop()
+	w.Stop() // idempotent
+
+	if got := s.count(); got != 10 {
+		t.Fatalf("persisted %d events, want 10", got)
+	}
+	for _, e := range s.events {
+		if e.CreatedAt.IsZero() {
+			t.Fatal("Record did not stamp CreatedAt")
+		}
+	}
+}
+
+func TestWriterDropsWhenFull(t *testing.T) {
+	t.Parallel()
+
+	// With the writer not started nothing drains, so the buffer fills and
+	// Record must return instead of blocking.
+	s := &recordingStore{}
+	w := newTestWriter(s, 2)
+	for range 5 {
+		w.Record(&models.AuditEvent{Action: models.AuditActionQueryExecuteSQL})
+	}
+	w.Start()
+	w.Stop()
+
+	if got := s.count(); got != 2 {
+		t.Fatalf("persisted %d events, want the 2 that fit the buffer", got)
+	}
+}
+
+func TestNilWriterIsNoop(t *testing.T) {
+	t.Parallel()
+
+	var w *Writer
+	w.Start()
+	w.Record(&models.AuditEvent{Action: models.AuditActionAlertDelete})
+	w.Stop()
+}
+
+func TestObserveEventsRecordsHealthChanges(t *testing.T) {
+	t.Parallel()
+
+	s := &recordingStore{}
+	w := newTestWriter(s, 16)
+	w.Start()
+	bus := events.NewBus()
+	unsubscribe := w.ObserveEvents(bus)
+	bus.Publish(models.EventSourceHealthChanged, models.SourceHealthEventData{
+		SourceID: 4, Name: "edge", Status: models.HealthStatusUnhealthy, PreviousStatus: models.HealthStatusHealthy,
+	})
+	bus.Publish(models.EventSourceCreated, models.SourceEventData{SourceID: 5})
+	unsubscribe()
+	w.Stop()
+
+	if got := s.count(); got != 1 {
+		t.Fatalf("persisted %d events, want 1", got)
+	}
+	e := s.events[0]
+	if e.Action != models.AuditActionSourceHealth || e.ResourceID != "4" || e.UserID != nil ||
+		!strings.Contains(string(e.Details), `"status":"unhealthy"`) {
+		t.Fatalf("unexpected event: %+v details=%s", e, e.Details)
+	}
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// SyncGroupTeams reconciles the user's team memberships with the groups
+// claim of a verified ID token, per auth.group_sync. It is best-effort: every
+// failure is logged and skipped, it never fails the login.
+func SyncGroupTeams(ctx context.Context, db store.StoreOps, log *slog.Logger, cfg config.GroupSyncConfig, user *models.User, idToken *oidc.IDToken) {
+	if !cfg.Enabled {
+		return
+	}
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		log.Warn("group_sync: failed to parse ID token claims, skipping", "email", user.Email, "error", err)
+		return
+	}
+	groups, ok := groupsFromClaims(claims, cfg.Claim)
+	if !ok {
+		// An absent claim usually means a missing scope or IdP mapper, not
+		// "member of nothing"; don't strip memberships over it.
+		log.Warn("group_sync: groups claim missing from ID token, skipping", "claim", cfg.Claim, "email", user.Email)
+		return
+	}
+	syncGroupTeams(ctx, db, log, cfg, user, groups)
+}
+
+// groupsFromClaims returns the string list at the dotted claim path. ok is
+// false when the claim is absent or isn't a string or list of strings.
+func groupsFromClaims(claims map[string]any, claim string) ([]string, bool) {
+	var v any = claims
+	for _, part := range strings.Split(claim, ".") {
+		obj, isObj := v.(map[string]any)
+		if !isObj {
+			return nil, false
+		}
+		if v, isObj = obj[part]; !isObj {
+			return nil, false
+		}
+	}
+
+	switch t := v.(type) {
+	case string:
+		return []string{t}, true
+	case []any:
+		groups := make([]string, 0, len(t))
+		for _, g := range t {
+			if s, isStr := g.(string); isStr {
+				groups = append(groups, s)
+			}
+		}
+		return groups, true
+	default:
+		return nil, false
+	}
+}
+
+// syncGroupTeams joins the user to every mapped team one of their groups
+// matches, at the highest matching role, and (unless KeepUnmatched) removes
+// them from mapped teams no group matches. Unmapped teams are not touched.
+func syncGroupTeams(ctx context.Context, db store.StoreOps, log *slog.Logger, cfg config.GroupSyncConfig, user *models.User, groups []string) {
+	member := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		member[g] = true
+	}
+
+	teams := make(map[string]*models.Team) // by name; nil for a missing team
+	mapped := make(map[models.TeamID]bool)
+	desired := make(map[models.TeamID]models.TeamRole)
+	for _, m := range cfg.Mappings {
+		team, seen := teams[m.Team]
+		if !seen {
+			t, err := db.GetTeamByName(ctx, m.Team)
+			if err != nil {
+				log.Warn("group_sync: team not found, skipping mapping", "team", m.Team, "group", m.Group, "error", err)
+			}
+			teams[m.Team], team = t, t
+		}
+		if team == nil {
+			continue
+		}
+		mapped[team.ID] = true
+		if !member[m.Group] {
+			continue
+		}
+		role := models.TeamRole(m.Role)
+		if cur, ok := desired[team.ID]; !ok || teamRoleRank(role) > teamRoleRank(cur) {
+			desired[team.ID] = role
+		}
+	}
+
+	memberships, err := core.ListTeamsForUser(ctx, db, user.ID)
+	if err != nil {
+		log.Warn("group_sync: failed to list team memberships, skipping", "email", user.Email, "error", err)
+		return
+	}
+	current := make(map[models.TeamID]models.TeamRole, len(memberships))
+	for _, t := range memberships {
+		current[t.ID] = t.Role
+	}
+
+	var joined, left []models.TeamID
+	for _, id := range slices.Sorted(maps.Keys(desired)) {
+		if current[id] == desired[id] {
+			continue
+		}
+		if err := core.AddTeamMember(ctx, db, log, id, user.ID, desired[id]); err != nil {
+			log.Warn("group_sync: failed to add team membership, skipping", "team_id", id, "email", user.Email, "error", err)
+			continue
+		}
+		joined = append(joined, id)
+		auditMembership(ctx, db, log, user, models.AuditActionTeamMemberAdd, id, map[string]any{"role": desired[id]})
+	}
+	if !cfg.KeepUnmatched {
+		for _, id := range slices.Sorted(maps.Keys(mapped)) {
+			if _, want := desired[id]; want {
+				continue
+			}
+			if _, has := current[id]; !has {
+				continue
+			}
+			if err := core.RemoveTeamMember(ctx, db, log, id, user.ID); err != nil {
+				log.Warn("group_sync: failed to remove team membership, skipping", "team_id", id, "email", user.Email, "error", err)
+				continue
+			}
+			left = append(left, id)
+			auditMembership(ctx, db, log, user, models.AuditActionTeamMemberRemove, id, map[string]any{"role": current[id]})
+		}
+	}
+
+	if len(joined) > 0 || len(left) > 0 {
+		log.Info("user.group_sync", "email", user.Email, "user_id", user.ID, "joined_team_ids", joined, "left_team_ids", left)
+	}
+}
+
+// auditMembership records a membership change made by group sync, attributed
+// to the user logging in. Like the sync itself it is best-effort: a failure
+// is logged and the login goes on.
+func auditMembership(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, action models.AuditAction, teamID models.TeamID, details map[string]any) {
+	details["via"] = "oidc_group_sync"
+	raw, err := json.Marshal(details)
+	if err != nil {
+		log.Warn("group_sync: failed to encode audit details", "error", err, "action", action)
+		return
+	}
+	userID := user.ID
+	event := &models.AuditEvent{
+		UserID:       &userID,
+		UserEmail:    user.Email,
+		Action:       action,
+		ResourceType: models.AuditResourceTeamMember,
+		ResourceID:   fmt.Sprintf("%d:%d", teamID, user.ID),
+		TeamID:       &teamID,
+		Details:      raw,
+	}
+	if err := db.InsertAuditEvent(ctx, event); err != nil {
+		log.Warn("group_sync: failed to record audit event", "error", err, "action", action, "team_id", teamID)
+	}
+}
+
+// teamRoleRank orders team roles from least to most privileged.
+func teamRoleRank(r models.TeamRole) int {
+	switch r {
+	case models.TeamRoleViewer:
+		return 1
+	case models.TeamRoleMember:
+		return 2
+	case models.TeamRoleEditor:
+		return 3
+	case models.TeamRoleAdmin:
+		return 4
+	default:
+		return 0
+	}
+}
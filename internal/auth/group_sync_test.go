@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestGroupsFromClaims(t *testing.T) {
+	claims := map[string]any{
+		"groups":       []any{"sre", "dev", 42},
+		"single":       "ops",
+		"realm_access": map[string]any{"roles": []any{"admin"}},
+		"number":       7.0,
+	}
+	tests := []struct {
+		claim  string
+		want   []string
+		wantOK bool
+	}{
+		{"groups", []string{"sre", "dev"}, true},
+		{"single", []string{"ops"}, true},
+		{"realm_access.roles", []string{"admin"}, true},
+		{"missing", nil, false},
+		{"realm_access.missing", nil, false},
+		{"single.nested", nil, false},
+		{"number", nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := groupsFromClaims(claims, tt.claim)
+		if ok != tt.wantOK || !slices.Equal(got, tt.want) {
+			t.Errorf("groupsFromClaims(%q) = %v, %v; want %v, %v", tt.claim, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// TestSyncGroupTeams walks one user through successive logins with changing
+// groups: mapped teams are joined at the highest matching role, left when no
+// group matches, and an unmapped team membership is never touched.
+func TestSyncGroupTeams(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	log := discardLoggerAP()
+	db := newAuthTestDB(t)
+
+	newTeam := func(name string) models.TeamID {
+		t.Helper()
+		team, err := core.CreateTeam(ctx, db, log, name, "")
+		if err != nil {
+			t.Fatalf("CreateTeam(%s): %v", name, err)
+		}
+		return team.ID
+	}
+	platform, payments, manual := newTeam("platform"), newTeam("payments"), newTeam("manual")
+
+	user, err := core.CreateUser(ctx, db, log, "dev@example.com", "Dev User", models.UserRoleMember, models.UserStatusActive)
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if err := core.AddTeamMember(ctx, db, log, manual, user.ID, models.TeamRoleViewer); err != nil {
+		t.Fatalf("AddTeamMember: %v", err)
+	}
+
+	cfg := config.GroupSyncConfig{
+		Enabled: true,
+		Claim:   "groups",
+		Mappings: []config.GroupTeamMapping{
+			{Group: "eng", Team: "platform", Role: "member"},
+			{Group: "sre", Team: "platform", Role: "admin"},
+			{Group: "billing", Team: "payments", Role: "viewer"},
+			{Group: "ghost", Team: "does-not-exist", Role: "member"},
+		},
+	}
+	memberships := func() map[models.TeamID]models.TeamRole {
+		t.Helper()
+		teams, err := core.ListTeamsForUser(ctx, db, user.ID)
+		if err != nil {
+			t.Fatalf("ListTeamsForUser: %v", err)
+		}
+		got := make(map[models.TeamID]models.TeamRole, len(teams))
+		for _, team := range teams {
+			got[team.ID] = team.Role
+		}
+		return got
+	}
+	check := func(step string, want map[models.TeamID]models.TeamRole) {
+		t.Helper()
+		got := memberships()
+		if len(got) != len(want) {
+			t.Fatalf("%s: memberships = %v, want %v", step, got, want)
+		}
+		for id, role := range want {
+			if got[id] != role {
+				t.Fatalf("%s: memberships = %v, want %v", step, got, want)
+			}
+		}
+	}
+
+	syncGroupTeams(ctx, db, log, cfg, user, []string{"eng", "sre", "billing", "ghost"})
+	check("first login", map[models.TeamID]models.TeamRole{
+		platform: models.TeamRoleAdmin, payments: models.TeamRoleViewer, manual: models.TeamRoleViewer,
+	})
+
+	syncGroupTeams(ctx, db, log, cfg, user, []string{"eng"})
+	check("left sre and billing", map[models.TeamID]models.TeamRole{
+		platform: models.TeamRoleMember, manual: models.TeamRoleViewer,
+	})
+
+	keep := cfg
+	keep.KeepUnmatched = true
+	syncGroupTeams(ctx, db, log, keep, user, nil)
+	check("keep_unmatched", map[models.TeamID]models.TeamRole{
+		platform: models.TeamRoleMember, manual: models.TeamRoleViewer,
+	})
+
+	syncGroupTeams(ctx, db, log, cfg, user, nil)
+	check("no groups", map[models.TeamID]models.TeamRole{manual: models.TeamRoleViewer})
+
+	// Every join and leave is audited against the user, oldest first here.
+	events, err := db.ListAuditEvents(ctx, models.AuditEventFilter{ResourceType: models.AuditResourceTeamMember, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListAuditEvents: %v", err)
+	}
+	slices.Reverse(events)
+	type audited struct {
+		action models.AuditAction
+		team   models.TeamID
+		role   models.TeamRole
+	}
+	want := []audited{
+		{models.AuditActionTeamMemberAdd, platform, models.TeamRoleAdmin},
+		{models.AuditActionTeamMemberAdd, payments, models.TeamRoleViewer},
+		{models.AuditActionTeamMemberAdd, platform, models.TeamRoleMember},
+		{models.AuditActionTeamMemberRemove, payments, models.TeamRoleViewer},
+		{models.AuditActionTeamMemberRemove, platform, models.TeamRoleMember},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d audit events, want %d", len(events), len(want))
+	}
+	for i, e := range events {
+		var details struct {
+			Via  string          `json:"via"`
+			Role models.TeamRole `json:"role"`
+		}
+		if err := json.Unmarshal(e.Details, &details); err != nil {
+			t.Fatalf("event %d details: %v", i, err)
+		}
+		got := audited{e.Action, 0, details.Role}
+		if e.TeamID != nil {
+			got.team = *e.TeamID
+		}
+		if got != want[i] || details.Via != "oidc_group_sync" {
+			t.Errorf("event %d = %+v via %q, want %+v via oidc_group_sync", i, got, details.Via, want[i])
+		}
+		if e.UserID == nil || *e.UserID != user.ID || e.UserEmail != user.Email {
+			t.Errorf("event %d attributed to %v/%q, want %d/%q", i, e.UserID, e.UserEmail, user.ID, user.Email)
+		}
+		if wantID := fmt.Sprintf("%d:%d", want[i].team, user.ID); e.ResourceID != wantID {
+			t.Errorf("event %d resource = %q, want %q", i, e.ResourceID, wantID)
+		}
+	}
+}
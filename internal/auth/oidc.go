@@ -265,6 +265,9 @@ func (p *OIDCProvider) HandleCallback(ctx context.Context, db store.Store, log *
 		return nil, nil, ErrUserInactive
 	}
 
+	// Reconcile team memberships from IdP groups (best effort).
+	SyncGroupTeams(ctx, db, log, authCfg.GroupSync, user, idToken)
+
 	// Update user's last login time (best effort).
 	now := time.Now()
 	updateData := models.User{LastLoginAt: &now}
@@ -38,7 +38,7 @@ const (
 
 // keyVersion is bumped when the canonical cache-key encoding changes so old
 // entries can never collide with new ones.
-const keyVersion = 1
+const keyVersion = 3
 
 // sweepInterval is the cadence of the background expiry sweep. TTL is primarily
 // enforced lazily on Get; the sweep just reclaims memory from entries that are
@@ -296,6 +296,8 @@ type KeyInput struct {
 	HistogramWindow  string
 	HistogramGroupBy string
 	QueryTimeoutSecs int64
+	RowFilter        string // the team's row filter for the source; a policy change must miss
+	Masked           bool   // whether the source's masked columns are masked for the requester
 }
 
 // ComputeKey returns the SHA-256 of the canonical, length-prefixed encoding of
@@ -319,6 +321,12 @@ func ComputeKey(in KeyInput) [32]byte {
 	writeStr(h, in.HistogramWindow)
 	writeStr(h, in.HistogramGroupBy)
 	writeInt(h, in.QueryTimeoutSecs)
+	writeStr(h, in.RowFilter)
+	if in.Masked {
+		writeInt(h, 1)
+	} else {
+		writeInt(h, 0)
+	}
 
 	var out [32]byte
 	copy(out[:], h.Sum(nil))
@@ -278,6 +278,8 @@ func TestComputeKeyDeterministicAndDistinct(t *testing.T) {
 		func(k *KeyInput) { k.HistogramWindow = "5m" },
 		func(k *KeyInput) { k.HistogramGroupBy = "level" },
 		func(k *KeyInput) { k.QueryTimeoutSecs = 30 },
+		func(k *KeyInput) { k.RowFilter = "namespace = 'a'" },
+		func(k *KeyInput) { k.Masked = true },
 	}
 	baseKey := ComputeKey(base)
 	for i, m := range mutators {
@@ -0,0 +1,108 @@
+package clickhouse
+
+// Capability probing and error classification for source health. Read-only
+// replicas answer SELECTs normally but reject DDL, so the health check records
+// what a connection may do and admin flows consult it before issuing DDL.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// ClickHouse exception codes used to classify failures.
+const (
+	chExceptionReadonly         int32 = 164 // READONLY: the user or query runs with readonly > 0.
+	chExceptionTableIsReadOnly  int32 = 242 // TABLE_IS_READ_ONLY: replica lost its Keeper session.
+	chExceptionUnknownUser      int32 = 192
+	chExceptionWrongPassword    int32 = 193
+	chExceptionRequiredPassword int32 = 194
+	chExceptionAccessDenied     int32 = 497
+	chExceptionAuthFailed       int32 = 516
+)
+
+// ProbeCapabilities checks which kinds of operations the connection
+// supports. database and table, when set, also check whether the source
+// table's replica is read-only. A probe query that fails leaves the
+// corresponding capability at its optimistic default for DDL, so an old or
+// locked-down system table never blocks admin flows on its own.
+func (c *Client) ProbeCapabilities(ctx context.Context, database, table string) models.SourceHealthCapabilities {
+	if c.conn == nil {
+		return models.SourceHealthCapabilities{}
+	}
+	caps := models.SourceHealthCapabilities{CanQuery: true, CanDDL: true}
+
+	var readonly string
+	if err := c.conn.QueryRow(ctx, `SELECT value FROM system.settings WHERE name = 'readonly'`).Scan(&readonly); err != nil {
+		c.logger.Debug("readonly setting probe failed", "error", err)
+	} else if readonly != "0" {
+		caps.CanDDL = false
+		caps.ReadOnlyReason = "readonly=" + readonly
+	}
+
+	if caps.CanDDL && database != "" && table != "" {
+		// No row means the table isn't replicated, which is fine.
+		var replicaReadOnly uint8
+		err := c.conn.QueryRow(ctx, `SELECT is_readonly FROM system.replicas WHERE database = ? AND table = ?`, database, table).Scan(&replicaReadOnly)
+		if err == nil && replicaReadOnly != 0 {
+			caps.CanDDL = false
+			caps.ReadOnlyReason = fmt.Sprintf("replica of %s.%s is read-only", database, table)
+		}
+	}
+
+	var parts uint64
+	if err := c.conn.QueryRow(ctx, `SELECT count() FROM system.parts WHERE database = ? AND table = ?`, database, table).Scan(&parts); err != nil {
+		c.logger.Debug("system.parts probe failed", "error", err)
+	} else {
+		caps.CanStats = true
+	}
+	return caps
+}
+
+// IsReadOnlyError reports whether err is ClickHouse refusing a write or DDL
+// because the connection or the replica is read-only.
+func IsReadOnlyError(err error) bool {
+	if errors.Is(err, ErrReadOnly) {
+		return true
+	}
+	var exception *clickhouse.Exception
+	if errors.As(err, &exception) {
+		return exception.Code == chExceptionReadonly || exception.Code == chExceptionTableIsReadOnly
+	}
+	return false
+}
+
+// ClassifyHealthError maps a failed health check to a coarse kind so the UI
+// can tell an unreachable server from bad credentials.
+func ClassifyHealthError(err error) models.HealthErrorKind {
+	if isTimeoutError(err) {
+		return models.HealthErrorTimeout
+	}
+	var exception *clickhouse.Exception
+	if errors.As(err, &exception) {
+		switch exception.Code {
+		case chExceptionUnknownUser, chExceptionWrongPassword, chExceptionRequiredPassword, chExceptionAuthFailed:
+			return models.HealthErrorAuth
+		case chExceptionAccessDenied:
+			return models.HealthErrorPermission
+		}
+	}
+	return models.HealthErrorConnection
+}
+
+// RequireDDL returns an ErrReadOnly error, with the probed reason, when caps
+// show the connection can't run DDL. Unknown capabilities (nil) pass, so a
+// source that hasn't been checked yet isn't blocked.
+func RequireDDL(caps *models.SourceHealthCapabilities) error {
+	if caps == nil || caps.CanDDL {
+		return nil
+	}
+	if caps.ReadOnlyReason == "" {
+		return ErrReadOnly
+	}
+	return fmt.Errorf("%w (%s)", ErrReadOnly, caps.ReadOnlyReason)
+}
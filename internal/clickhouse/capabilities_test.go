@@ -0,0 +1,83 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestClassifyHealthError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want models.HealthErrorKind
+	}{
+		{"timeout", fmt.Errorf("reconnection failed: %w", context.DeadlineExceeded), models.HealthErrorTimeout},
+		{"wrong password", fmt.Errorf("ping failed: %w", &clickhouse.Exception{Code: 516, Name: "AUTHENTICATION_FAILED"}), models.HealthErrorAuth},
+		{"unknown user", &clickhouse.Exception{Code: 192, Name: "UNKNOWN_USER"}, models.HealthErrorAuth},
+		{"access denied", &clickhouse.Exception{Code: 497, Name: "ACCESS_DENIED"}, models.HealthErrorPermission},
+		{"refused", errors.New("dial tcp 10.0.0.1:9000: connect: connection refused"), models.HealthErrorConnection},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ClassifyHealthError(tc.err); got != tc.want {
+				t.Errorf("ClassifyHealthError(%v) = %q, want %q", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsReadOnlyError(t *testing.T) {
+	for _, err := range []error{
+		ErrReadOnly,
+		fmt.Errorf("executing DDL query: %w", &clickhouse.Exception{Code: 164, Name: "READONLY"}),
+		&clickhouse.Exception{Code: 242, Name: "TABLE_IS_READ_ONLY"},
+	} {
+		if !IsReadOnlyError(err) {
+			t.Errorf("IsReadOnlyError(%v) = false", err)
+		}
+	}
+	if IsReadOnlyError(&clickhouse.Exception{Code: 60, Name: "UNKNOWN_TABLE"}) {
+		t.Error("UNKNOWN_TABLE classified as read-only")
+	}
+}
+
+func TestRequireDDL(t *testing.T) {
+	if err := RequireDDL(nil); err != nil {
+		t.Errorf("unprobed source blocked: %v", err)
+	}
+	if err := RequireDDL(&models.SourceHealthCapabilities{CanQuery: true, CanDDL: true}); err != nil {
+		t.Errorf("writable source blocked: %v", err)
+	}
+	err := RequireDDL(&models.SourceHealthCapabilities{CanQuery: true, ReadOnlyReason: "readonly=2"})
+	if !errors.Is(err, ErrReadOnly) || err.Error() != "source connection is read-only (readonly=2)" {
+		t.Errorf("RequireDDL(read-only) = %v", err)
+	}
+}
+
+func TestUpdateHealthStatusRecordsCapabilities(t *testing.T) {
+	m := NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	const id models.SourceID = 7
+
+	m.updateHealthStatus(id, nil, &models.SourceHealthCapabilities{CanQuery: true, CanStats: true, ReadOnlyReason: "readonly=1"})
+	health := m.GetCachedHealth(id)
+	if health.Status != models.HealthStatusHealthy || health.Capabilities == nil || health.Capabilities.CanDDL {
+		t.Fatalf("healthy read-only source recorded as %+v", health)
+	}
+	if err := m.RequireDDL(id); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("RequireDDL = %v, want ErrReadOnly", err)
+	}
+
+	m.updateHealthStatus(id, fmt.Errorf("reconnection failed: %w", &clickhouse.Exception{Code: 516, Name: "AUTHENTICATION_FAILED"}), nil)
+	health = m.GetCachedHealth(id)
+	if health.Status != models.HealthStatusUnhealthy || health.ErrorKind != models.HealthErrorAuth || health.Capabilities != nil {
+		t.Fatalf("failed check recorded as %+v", health)
+	}
+}
@@ -3,6 +3,7 @@ package clickhouse
 // Client connection lifecycle: options, construction, hooks, close/reconnect.
 
 import (
+	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
@@ -14,6 +15,7 @@ import (
 	"time"
 
 	"github.com/mr-karan/logchef/internal/metrics"
+	"github.com/mr-karan/logchef/internal/secrets"
 	"github.com/mr-karan/logchef/pkg/models"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
@@ -33,16 +35,25 @@ const (
 	// into dozens of simultaneous ClickHouse queries.
 	fieldValuesConcurrency = 6
 
+	// fieldValuesBatchSize caps how many low-cardinality fields
+	// GetAllFilterableFieldValues reads in one query.
+	fieldValuesBatchSize = 16
+
 	// DefaultQueryTimeout is the default max_execution_time in seconds if not specified
 	DefaultQueryTimeout = 60
 	// MaxQueryTimeout is the maximum allowed timeout to prevent resource abuse
 	MaxQueryTimeout = 300 // 5 minutes
+
+	// Connection pool defaults, overridable per source via connection.pool.
+	defaultDialTimeout  = 10 * time.Second
+	defaultMaxOpenConns = 10
+	defaultMaxIdleConns = 5
 )
 
-// Client represents a connection to a ClickHouse database using the native protocol.
+// Client represents a connection to a ClickHouse database over the native or HTTP protocol.
 // It provides methods for executing queries and retrieving metadata.
 type Client struct {
-	conn       driver.Conn // Underlying ClickHouse native connection.
+	conn       driver.Conn // Underlying ClickHouse connection.
 	logger     *slog.Logger
 	queryHooks []QueryHook         // Hooks to execute before/after queries.
 	mu         sync.Mutex          // Protects shared resources within the client if any
@@ -53,72 +64,177 @@ type Client struct {
 	// querySettings holds per-source ClickHouse settings applied to every query
 	// context (e.g. max_result_rows, readonly). Nil when the source configures none.
 	querySettings clickhouse.Settings
+	// querySlots is a semaphore bounding concurrent queries against the source.
+	// Nil when the source sets no max_concurrent_queries.
+	querySlots chan struct{}
+	// secrets, hostRef and passwordRef re-resolve the host and password on
+	// Reconnect when they are secret references.
+	secrets     *secrets.Resolver
+	hostRef     string
+	passwordRef string
+	defaultPort string
+	// replicas steers new connections to the replicas listed in the host
+	// that last answered; see replicaHealth.
+	replicas *replicaHealth
 }
 
 // ClientOptions holds configuration for establishing a new ClickHouse client connection.
 type ClientOptions struct {
-	Host      string         // Hostname or IP address.
+	Host      string         // Hostname or IP address, or several replicas' comma separated.
 	Database  string         // Target database name.
 	Username  string         // Username for authentication.
 	Password  string         // Password for authentication.
 	Settings  map[string]any // Additional ClickHouse settings (e.g., max_execution_time).
 	SourceID  string         // Source ID for metrics tracking.
 	Source    *models.Source // Source model for enhanced metrics.
+	Protocol  string         // models.ClickHouseProtocolNative (default) or models.ClickHouseProtocolHTTP.
 	TLSEnable bool           // Enable TLS for the connection.
+	TLS       TLSOptions     // Certificates and verification, used with TLSEnable.
 	// QuerySettings are per-source ClickHouse settings applied to every query
 	// context (not as connection defaults), so they can override LogChef's
 	// per-query defaults for caps/timeouts/read-only. Only set settings appear.
 	QuerySettings map[string]any
+	// Pool overrides the connection pool defaults and caps concurrent queries.
+	Pool *models.ClickHouseConnectionPool
+	// Secrets resolves secret references in Host and Password. They are
+	// resolved again, bypassing the cache, on Reconnect.
+	Secrets *secrets.Resolver
 }
 
-// NewClient establishes a new connection to a ClickHouse server using the native protocol.
-// It takes connection options and a logger, creates the connection, and returns a Client instance.
-// Note: This does not automatically verify the connection with a ping - callers should do that if needed.
-func NewClient(opts ClientOptions, logger *slog.Logger) (*Client, error) {
-	// Ensure host includes the native protocol port if not specified.
-	// Default to 9440 for TLS connections, 9000 for plaintext.
-	host := opts.Host
-	if !strings.Contains(host, ":") {
-		if opts.TLSEnable {
-			host += ":9440"
-		} else {
-			host += ":9000"
-		}
+// TLSOptions customises certificate handling for a TLS connection. The
+// certificates and key are PEM encoded.
+type TLSOptions struct {
+	CACert     string // Trusted CA bundle; the system roots when empty.
+	ClientCert string // Client certificate for mutual TLS.
+	ClientKey  string // Private key of ClientCert.
+	SkipVerify bool   // Accept any server certificate.
+}
+
+// TLSOptionsFor returns a source connection's TLS options.
+func TLSOptionsFor(conn models.ConnectionInfo) TLSOptions {
+	return TLSOptions{
+		CACert:     conn.TLSCACert,
+		ClientCert: conn.TLSClientCert,
+		ClientKey:  conn.TLSClientKey,
+		SkipVerify: conn.TLSSkipVerify,
 	}
+}
 
-	// Build TLS config if enabled. Uses the system root CA pool; operators
-	// who need a custom CA bundle should install it into the OS trust store.
-	var tlsCfg *tls.Config
-	if opts.TLSEnable {
+// BuildTLSConfig turns TLS options into a tls.Config. It fails on a CA bundle
+// without certificates, a malformed client key pair, or only half of one.
+func BuildTLSConfig(opts TLSOptions, logger *slog.Logger) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: opts.SkipVerify, //nolint:gosec // explicit per-source opt-in for self-signed clusters
+	}
+
+	if strings.TrimSpace(opts.CACert) != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(opts.CACert)) {
+			return nil, fmt.Errorf("CA certificate contains no valid PEM certificates")
+		}
+		cfg.RootCAs = pool
+	} else {
 		rootCAs, err := x509.SystemCertPool()
 		if err != nil {
 			logger.Warn("failed to load system cert pool, falling back to empty pool", "error", err)
 			rootCAs = x509.NewCertPool()
 		}
-		tlsCfg = &tls.Config{
-			RootCAs:    rootCAs,
-			MinVersion: tls.VersionTLS12,
+		cfg.RootCAs = rootCAs
+	}
+
+	hasCert, hasKey := strings.TrimSpace(opts.ClientCert) != "", strings.TrimSpace(opts.ClientKey) != ""
+	if hasCert != hasKey {
+		return nil, fmt.Errorf("client certificate and client key must be set together")
+	}
+	if hasCert {
+		pair, err := tls.X509KeyPair([]byte(opts.ClientCert), []byte(opts.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+	return cfg, nil
+}
+
+// defaultPort is ClickHouse's default port for a protocol.
+func defaultPort(protocol string, tlsEnable bool) string {
+	switch {
+	case protocol == models.ClickHouseProtocolHTTP && tlsEnable:
+		return "8443"
+	case protocol == models.ClickHouseProtocolHTTP:
+		return "8123"
+	case tlsEnable:
+		return "9440"
+	default:
+		return "9000"
+	}
+}
+
+// withPort appends port to host unless it already has one.
+func withPort(host, port string) string {
+	if strings.Contains(host, ":") {
+		return host
+	}
+	return host + ":" + port
+}
+
+// NewClient establishes a new connection to a ClickHouse server over the native
+// or HTTP(S) protocol. It takes connection options and a logger, creates the
+// connection, and returns a Client instance.
+// Note: This does not automatically verify the connection with a ping - callers should do that if needed.
+func NewClient(opts ClientOptions, logger *slog.Logger) (*Client, error) {
+	protocol := models.ConnectionInfo{Protocol: opts.Protocol}.ClickHouseProtocol()
+	port := defaultPort(protocol, opts.TLSEnable)
+
+	host, password := opts.Host, opts.Password
+	if err := opts.Secrets.ResolveFields(context.Background(), &host, &password); err != nil {
+		return nil, fmt.Errorf("resolving connection secrets: %w", err)
+	}
+	addrs := replicaAddrs(host, port)
+
+	var tlsCfg *tls.Config
+	if opts.TLSEnable {
+		var err error
+		if tlsCfg, err = BuildTLSConfig(opts.TLS, logger); err != nil {
+			return nil, fmt.Errorf("configuring TLS: %w", err)
 		}
 	}
 
+	// HTTP responses are compressed with gzip; the driver only speaks LZ4 natively.
+	chProtocol := clickhouse.Native
+	compression := &clickhouse.Compression{Method: clickhouse.CompressionLZ4}
+	if protocol == models.ClickHouseProtocolHTTP {
+		chProtocol = clickhouse.HTTP
+		compression = &clickhouse.Compression{Method: clickhouse.CompressionGZIP, Level: gzip.DefaultCompression}
+	}
+
+	var chMetrics *metrics.ClickHouseMetrics
+	if opts.Source != nil {
+		chMetrics = metrics.NewClickHouseMetrics(opts.Source)
+	}
+	replicas := newReplicaHealth(logger, chMetrics)
+
 	options := &clickhouse.Options{
-		Addr: []string{host},
+		Addr: addrs,
 		Auth: clickhouse.Auth{
 			Database: opts.Database,
 			Username: opts.Username,
-			Password: opts.Password,
+			Password: password,
 		},
 		Settings: clickhouse.Settings{
 			// Default settings.
 			"max_execution_time": 60,
 		},
-		DialTimeout: 10 * time.Second,
-		Compression: &clickhouse.Compression{
-			Method: clickhouse.CompressionLZ4,
-		},
-		Protocol: clickhouse.Native,
-		TLS:      tlsCfg,
+		DialTimeout:  defaultDialTimeout,
+		MaxOpenConns: defaultMaxOpenConns,
+		MaxIdleConns: defaultMaxIdleConns,
+		Compression:  compression,
+		Protocol:     chProtocol,
+		TLS:          tlsCfg,
+		DialStrategy: replicas.dialStrategy,
 	}
+	applyPool(options, opts.Pool)
 
 	// Apply any additional user-provided settings.
 	if opts.Settings != nil {
@@ -126,9 +242,9 @@ func NewClient(opts ClientOptions, logger *slog.Logger) (*Client, error) {
 	}
 
 	logger.Debug("creating clickhouse connection",
-		"host", host,
+		"hosts", addrs,
 		"database", opts.Database,
-		"protocol", "native",
+		"protocol", protocol,
 		"tls", opts.TLSEnable,
 	)
 
@@ -144,10 +260,20 @@ func NewClient(opts ClientOptions, logger *slog.Logger) (*Client, error) {
 		opts:       options,
 		sourceID:   opts.SourceID,
 		source:     opts.Source,
+		metrics:    chMetrics,
+		replicas:   replicas,
 	}
 	if len(opts.QuerySettings) > 0 {
 		client.querySettings = clickhouse.Settings(opts.QuerySettings)
 	}
+	if opts.Pool != nil && opts.Pool.MaxConcurrentQueries > 0 {
+		client.querySlots = make(chan struct{}, opts.Pool.MaxConcurrentQueries)
+	}
+	if secrets.IsRef(opts.Host) || secrets.IsRef(opts.Password) {
+		client.secrets = opts.Secrets
+		client.hostRef, client.passwordRef = opts.Host, opts.Password
+		client.defaultPort = port
+	}
 
 	// Apply a default hook for basic query logging.
 	client.AddQueryHook(NewLogQueryHook(logger, false)) // Verbose logging disabled by default.
@@ -155,21 +281,66 @@ func NewClient(opts ClientOptions, logger *slog.Logger) (*Client, error) {
 	// Add metrics hook if source is provided
 	if opts.Source != nil {
 		client.AddQueryHook(metrics.NewMetricsQueryHook(opts.Source))
-		client.metrics = metrics.NewClickHouseMetrics(opts.Source)
 	}
 
 	return client, nil
 }
 
+// applyPool overrides the driver's pool options with the source's non-zero
+// connection.pool settings. An idle cap above the open cap is lowered to it.
+func applyPool(options *clickhouse.Options, pool *models.ClickHouseConnectionPool) {
+	if pool == nil {
+		return
+	}
+	if pool.MaxOpenConns > 0 {
+		options.MaxOpenConns = pool.MaxOpenConns
+	}
+	if pool.MaxIdleConns > 0 {
+		options.MaxIdleConns = pool.MaxIdleConns
+	}
+	if pool.DialTimeoutSeconds > 0 {
+		options.DialTimeout = time.Duration(pool.DialTimeoutSeconds) * time.Second
+	}
+	options.MaxIdleConns = min(options.MaxIdleConns, options.MaxOpenConns)
+}
+
+// acquireQuerySlot waits for one of the source's concurrent query slots and
+// returns the function releasing it. It fails with ErrSourceBusy when ctx ends
+// first. Sources without a limit acquire immediately.
+func (c *Client) acquireQuerySlot(ctx context.Context) (func(), error) {
+	if c.querySlots == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.querySlots <- struct{}{}:
+		return func() { <-c.querySlots }, nil
+	default:
+	}
+	c.logger.Debug("waiting for a query slot", "source_id", c.sourceID, "max_concurrent_queries", cap(c.querySlots))
+	select {
+	case c.querySlots <- struct{}{}:
+		return func() { <-c.querySlots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %w", ErrSourceBusy, ctx.Err())
+	}
+}
+
 // AddQueryHook registers a hook to be executed before and after queries run by this client.
 func (c *Client) AddQueryHook(hook QueryHook) {
 	c.queryHooks = append(c.queryHooks, hook)
 }
 
 // executeQueryWithHooks wraps the execution of a query function (`fn`)
-// with the registered BeforeQuery and AfterQuery hooks.
+// with the registered BeforeQuery and AfterQuery hooks. It first takes one of
+// the source's concurrent query slots; time spent waiting for it is not
+// counted in the query duration.
 func (c *Client) executeQueryWithHooks(ctx context.Context, query string, fn func(context.Context) error) error {
-	var err error
+	release, err := c.acquireQuerySlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	start := time.Now()
 
 	// Execute BeforeQuery hooks.
@@ -223,8 +394,25 @@ func (c *Client) Close() error {
 	}
 }
 
+// refreshSecrets resolves the host and password references again, bypassing
+// the cache, so a rotated credential is picked up. Callers hold c.mu.
+func (c *Client) refreshSecrets(ctx context.Context) error {
+	if c.hostRef == "" && c.passwordRef == "" {
+		return nil
+	}
+	c.secrets.Forget(c.hostRef, c.passwordRef)
+	host, password := c.hostRef, c.passwordRef
+	if err := c.secrets.ResolveFields(ctx, &host, &password); err != nil {
+		return fmt.Errorf("resolving connection secrets: %w", err)
+	}
+	c.opts.Addr = replicaAddrs(host, c.defaultPort)
+	c.opts.Auth.Password = password
+	return nil
+}
+
 // Reconnect attempts to re-establish the connection to the ClickHouse server.
 // This is useful for recovering from connection failures during health checks.
+// Secret references in the host and password are resolved again first.
 func (c *Client) Reconnect(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -264,6 +452,9 @@ func (c *Client) Reconnect(ctx context.Context) error {
 	if c.opts == nil {
 		return fmt.Errorf("missing connection options for reconnect")
 	}
+	if err := c.refreshSecrets(ctx); err != nil {
+		return err
+	}
 
 	// Create a new connection with the same settings
 	newConn, err := clickhouse.Open(c.opts)
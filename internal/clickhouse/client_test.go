@@ -1,8 +1,22 @@
 package clickhouse
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
 	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
 
 	"github.com/mr-karan/logchef/pkg/models"
 )
@@ -51,3 +65,119 @@ func TestWithColumnDescriptions(t *testing.T) {
 		t.Fatalf("expected msg description, got %q", got[1].Description)
 	}
 }
+
+// selfSignedPEM returns a throwaway certificate and its key, PEM encoded.
+func selfSignedPEM(t *testing.T) (certPEM, keyPEM string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "logchef-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	certPEM, keyPEM := selfSignedPEM(t)
+
+	cfg, err := BuildTLSConfig(TLSOptions{CACert: certPEM, ClientCert: certPEM, ClientKey: keyPEM, SkipVerify: true}, logger)
+	if err != nil {
+		t.Fatalf("BuildTLSConfig: %v", err)
+	}
+	if cfg.RootCAs == nil || len(cfg.Certificates) != 1 || !cfg.InsecureSkipVerify {
+		t.Errorf("unexpected config: roots=%v certs=%d skip=%v", cfg.RootCAs != nil, len(cfg.Certificates), cfg.InsecureSkipVerify)
+	}
+
+	for name, opts := range map[string]TLSOptions{
+		"bad CA":         {CACert: "not a certificate"},
+		"cert only":      {ClientCert: certPEM},
+		"mismatched key": {ClientCert: certPEM, ClientKey: "garbage"},
+	} {
+		if _, err := BuildTLSConfig(opts, logger); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDefaultPort(t *testing.T) {
+	cases := []struct {
+		protocol string
+		tls      bool
+		want     string
+	}{
+		{models.ClickHouseProtocolNative, false, "9000"},
+		{models.ClickHouseProtocolNative, true, "9440"},
+		{models.ClickHouseProtocolHTTP, false, "8123"},
+		{models.ClickHouseProtocolHTTP, true, "8443"},
+	}
+	for _, tc := range cases {
+		if got := defaultPort(tc.protocol, tc.tls); got != tc.want {
+			t.Errorf("defaultPort(%q, %v) = %s, want %s", tc.protocol, tc.tls, got, tc.want)
+		}
+	}
+}
+
+func TestApplyPool(t *testing.T) {
+	options := &clickhouse.Options{DialTimeout: defaultDialTimeout, MaxOpenConns: defaultMaxOpenConns, MaxIdleConns: defaultMaxIdleConns}
+	applyPool(options, &models.ClickHouseConnectionPool{MaxOpenConns: 3, DialTimeoutSeconds: 2})
+	if options.MaxOpenConns != 3 || options.MaxIdleConns != 3 || options.DialTimeout != 2*time.Second {
+		t.Errorf("applyPool = open %d idle %d dial %s", options.MaxOpenConns, options.MaxIdleConns, options.DialTimeout)
+	}
+}
+
+func TestQuerySlotsLimitConcurrency(t *testing.T) {
+	c := &Client{
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		querySlots: make(chan struct{}, 1),
+	}
+
+	running := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		_ = c.executeQueryWithHooks(context.Background(), "SELECT 1", func(context.Context) error {
+			close(running)
+			<-done
+			return nil
+		})
+	}()
+	<-running
+
+	// The only slot is taken, so a second query waits until its context ends.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := c.executeQueryWithHooks(ctx, "SELECT 2", func(context.Context) error {
+		t.Error("second query ran while the slot was taken")
+		return nil
+	})
+	if !errors.Is(err, ErrSourceBusy) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want ErrSourceBusy wrapping the deadline", err)
+	}
+
+	// Once the first query finishes its slot is free again.
+	close(done)
+	ran := false
+	err = c.executeQueryWithHooks(context.Background(), "SELECT 3", func(context.Context) error {
+		ran = true
+		return nil
+	})
+	if err != nil || !ran {
+		t.Fatalf("query after release: ran=%v err=%v", ran, err)
+	}
+}
@@ -0,0 +1,52 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	clickhouseparser "github.com/AfterShip/clickhouse-sql-parser/parser"
+)
+
+// CountRows counts the rows query returns without its LIMIT, e.g. to show
+// "100 of 1.2M" next to a limited result. opts bounds the count like any
+// other query.
+func (c *Client) CountRows(ctx context.Context, query string, opts QueryOptions) (int64, error) {
+	countQuery, err := buildCountQuery(query)
+	if err != nil {
+		return 0, err
+	}
+	result, err := c.QueryWithOptions(ctx, countQuery, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count query rows: %w", err)
+	}
+	if len(result.Logs) == 0 {
+		return 0, nil
+	}
+	total, _ := extractInt64FromRow(result.Logs[0], "total")
+	return total, nil
+}
+
+// buildCountQuery wraps a SELECT in count(), dropping its LIMIT and, since it
+// can't change the count, its ORDER BY.
+func buildCountQuery(query string) (string, error) {
+	const placeholder = "___ESCAPED_QUOTE___"
+	processedSQL := strings.ReplaceAll(query, "''", placeholder)
+
+	stmts, err := clickhouseparser.NewParser(processedSQL).ParseStmts()
+	if err != nil {
+		return "", fmt.Errorf("invalid SQL syntax: %w", err)
+	}
+	if len(stmts) != 1 {
+		return "", fmt.Errorf("expected one statement, got %d", len(stmts))
+	}
+	selectQuery, ok := stmts[0].(*clickhouseparser.SelectQuery)
+	if !ok {
+		return "", fmt.Errorf("only SELECT queries are supported")
+	}
+	selectQuery.Limit = nil
+	selectQuery.OrderBy = nil
+
+	inner := strings.ReplaceAll(formatSQL(selectQuery), placeholder, "''")
+	return "SELECT count() AS total FROM (" + inner + ")", nil
+}
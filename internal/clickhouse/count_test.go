@@ -0,0 +1,58 @@
+package clickhouse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildCountQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    []string
+		notWant []string
+		wantErr bool
+	}{
+		{
+			name:    "drops limit and order by",
+			query:   "SELECT * FROM logs.app WHERE level = 'error' ORDER BY ts DESC LIMIT 100",
+			want:    []string{"SELECT count() AS total FROM (", "level = 'error'"},
+			notWant: []string{"LIMIT", "ORDER BY"},
+		},
+		{
+			name:  "keeps escaped quotes",
+			query: "SELECT * FROM logs.app WHERE msg = 'it''s' LIMIT 10",
+			want:  []string{"msg = 'it''s'"},
+		},
+		{
+			name:    "rejects multiple statements",
+			query:   "SELECT 1; SELECT 2",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildCountQuery(tt.query)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("buildCountQuery(%q) = %q, want error", tt.query, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildCountQuery(%q): %v", tt.query, err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("buildCountQuery(%q) = %q, want it to contain %q", tt.query, got, s)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("buildCountQuery(%q) = %q, want no %q", tt.query, got, s)
+				}
+			}
+		})
+	}
+}
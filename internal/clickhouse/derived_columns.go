@@ -0,0 +1,263 @@
+package clickhouse
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	clickhouseparser "github.com/AfterShip/clickhouse-sql-parser/parser"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+const (
+	// MaxDerivedColumns caps the derived columns a single query may add.
+	MaxDerivedColumns = 10
+	// maxDerivedExpressionLen caps the length of one derived column expression.
+	maxDerivedExpressionLen = 512
+)
+
+// derivedColumnNameRe is stricter than validIdentifierRe: the name becomes an
+// unquoted result alias, so dots, hyphens and "@" are not allowed.
+var derivedColumnNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+
+// derivedFunctions is the allow-list of functions a derived column may call.
+// It is limited to cheap scalar functions; aggregates, table functions and
+// anything that reads outside the current row are not included. Keys are
+// lower-cased and matched case-insensitively; ClickHouse still rejects a
+// misspelled case for functions that are case-sensitive.
+var derivedFunctions = map[string]struct{}{
+	// Arithmetic.
+	"abs": {}, "ceil": {}, "floor": {}, "round": {}, "trunc": {}, "intdiv": {},
+	"modulo": {}, "sqrt": {}, "exp": {}, "log": {}, "log2": {}, "log10": {},
+	"pow": {}, "least": {}, "greatest": {},
+	// Conditionals and nulls.
+	"if": {}, "multiif": {}, "coalesce": {}, "ifnull": {}, "nullif": {},
+	"isnull": {}, "isnotnull": {},
+	// Strings.
+	"lower": {}, "upper": {}, "length": {}, "substring": {}, "concat": {},
+	"trim": {}, "trimboth": {}, "trimleft": {}, "trimright": {}, "replaceall": {},
+	"position": {}, "startswith": {}, "endswith": {}, "extract": {}, "match": {},
+	"domain": {}, "path": {},
+	// Type conversion.
+	"tostring": {}, "toint64": {}, "touint64": {}, "tofloat64": {},
+	"toint64ornull": {}, "tofloat64ornull": {}, "toint64orzero": {}, "tofloat64orzero": {},
+	"todate": {}, "todatetime": {},
+	// Dates and times.
+	"tostartofminute": {}, "tostartofhour": {}, "tostartofday": {}, "tohour": {},
+	"todayofweek": {}, "tounixtimestamp": {}, "formatdatetime": {}, "datediff": {},
+	// JSON.
+	"jsonextractstring": {}, "jsonextractint": {}, "jsonextractfloat": {}, "jsonextractbool": {},
+}
+
+// derivedOperators are the binary operators a derived column may use.
+var derivedOperators = map[clickhouseparser.TokenKind]struct{}{
+	clickhouseparser.TokenKindPlus: {}, clickhouseparser.TokenKindMinus: {},
+	clickhouseparser.TokenKindMul: {}, clickhouseparser.TokenKindDiv: {},
+	clickhouseparser.TokenKindMod: {}, clickhouseparser.TokenKindConcat: {},
+	clickhouseparser.TokenKindSingleEQ: {}, clickhouseparser.TokenKindDoubleEQ: {},
+	clickhouseparser.TokenKindNE: {}, clickhouseparser.TokenKindLT: {},
+	clickhouseparser.TokenKindLE: {}, clickhouseparser.TokenKindGT: {},
+	clickhouseparser.TokenKindGE: {},
+	clickhouseparser.KeywordAnd:  {}, clickhouseparser.KeywordOr: {},
+	clickhouseparser.KeywordLike: {},
+}
+
+// ValidateDerivedColumns checks client-defined derived columns: names must be
+// plain identifiers that are unique and don't shadow a schema column, and each
+// expression may only use allow-listed functions and operators, literals and
+// the given schema columns. A nil schema skips the column checks; the query
+// builder uses that to re-check expressions it was handed already validated.
+func ValidateDerivedColumns(cols []models.DerivedColumn, schema []string) error {
+	_, err := parseDerivedColumns(cols, schema)
+	return err
+}
+
+func parseDerivedColumns(cols []models.DerivedColumn, schema []string) ([]*clickhouseparser.SelectItem, error) {
+	if len(cols) > MaxDerivedColumns {
+		return nil, &ValidationError{Message: fmt.Sprintf("at most %d derived columns are allowed", MaxDerivedColumns)}
+	}
+
+	var known map[string]struct{}
+	if schema != nil {
+		known = make(map[string]struct{}, len(schema))
+		for _, name := range schema {
+			known[name] = struct{}{}
+		}
+	}
+
+	items := make([]*clickhouseparser.SelectItem, 0, len(cols))
+	seen := make(map[string]struct{}, len(cols))
+	for _, col := range cols {
+		if !derivedColumnNameRe.MatchString(col.Name) {
+			return nil, &ValidationError{Message: fmt.Sprintf("invalid derived column name %q: use letters, digits and underscores", col.Name)}
+		}
+		if _, dup := seen[col.Name]; dup {
+			return nil, &ValidationError{Message: fmt.Sprintf("duplicate derived column %q", col.Name)}
+		}
+		seen[col.Name] = struct{}{}
+		if _, exists := known[col.Name]; exists {
+			return nil, &ValidationError{Message: fmt.Sprintf("derived column %q has the same name as a source column", col.Name)}
+		}
+
+		expr, err := parseDerivedExpression(col.Expression)
+		if err != nil {
+			return nil, &ValidationError{Message: fmt.Sprintf("derived column %q: %v", col.Name, err)}
+		}
+		if err := checkDerivedExpr(expr, known); err != nil {
+			return nil, &ValidationError{Message: fmt.Sprintf("derived column %q: %v", col.Name, err)}
+		}
+		items = append(items, &clickhouseparser.SelectItem{
+			Expr:  expr,
+			Alias: &clickhouseparser.Ident{Name: col.Name},
+		})
+	}
+	return items, nil
+}
+
+// parseDerivedExpression parses a lone expression by wrapping it in a SELECT
+// and requiring the result to be exactly one unaliased select item.
+func parseDerivedExpression(expression string) (clickhouseparser.Expr, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return nil, fmt.Errorf("expression is required")
+	}
+	if len(expression) > maxDerivedExpressionLen {
+		return nil, fmt.Errorf("expression is longer than %d characters", maxDerivedExpressionLen)
+	}
+
+	stmts, err := clickhouseparser.NewParser("SELECT " + expression).ParseStmts()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %w", err)
+	}
+	if len(stmts) != 1 {
+		return nil, fmt.Errorf("expected a single expression")
+	}
+	sel, ok := stmts[0].(*clickhouseparser.SelectQuery)
+	if !ok || len(sel.SelectItems) != 1 || sel.From != nil || sel.Where != nil ||
+		sel.GroupBy != nil || sel.OrderBy != nil || sel.Limit != nil || sel.Settings != nil ||
+		sel.Format != nil || sel.With != nil || sel.UnionAll != nil || sel.UnionDistinct != nil ||
+		sel.Except != nil || sel.Intersect != nil {
+		return nil, fmt.Errorf("expected a single expression")
+	}
+	item := sel.SelectItems[0]
+	if item.Alias != nil || len(item.Modifiers) > 0 {
+		return nil, fmt.Errorf("expected a single expression without an alias")
+	}
+	return item.Expr, nil
+}
+
+// checkDerivedExpr walks an expression and rejects any node outside the small
+// grammar derived columns support. known is the set of schema columns; nil
+// skips the column check.
+func checkDerivedExpr(node clickhouseparser.Expr, known map[string]struct{}) error { //nolint:gocyclo // one case per allowed node type
+	switch n := node.(type) {
+	case *clickhouseparser.NumberLiteral, *clickhouseparser.StringLiteral:
+		return nil
+	case *clickhouseparser.Ident:
+		return checkDerivedColumnRef(n.Name, known)
+	case *clickhouseparser.Path:
+		names := make([]string, len(n.Fields))
+		for i, f := range n.Fields {
+			names[i] = f.Name
+		}
+		return checkDerivedColumnRef(strings.Join(names, "."), known)
+	case *clickhouseparser.ColumnExpr:
+		if n.Alias != nil {
+			return fmt.Errorf("aliases are not allowed inside an expression")
+		}
+		return checkDerivedExpr(n.Expr, known)
+	case *clickhouseparser.UnaryExpr:
+		return checkDerivedExpr(n.Expr, known)
+	case *clickhouseparser.BinaryOperation:
+		if _, ok := derivedOperators[n.Operation]; !ok || n.HasGlobal {
+			return fmt.Errorf("operator %s is not allowed", n.Operation)
+		}
+		if err := checkDerivedExpr(n.LeftExpr, known); err != nil {
+			return err
+		}
+		return checkDerivedExpr(n.RightExpr, known)
+	case *clickhouseparser.TernaryOperation:
+		for _, e := range []clickhouseparser.Expr{n.Condition, n.TrueExpr, n.FalseExpr} {
+			if err := checkDerivedExpr(e, known); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *clickhouseparser.IsNullExpr:
+		return checkDerivedExpr(n.Expr, known)
+	case *clickhouseparser.IsNotNullExpr:
+		return checkDerivedExpr(n.Expr, known)
+	case *clickhouseparser.FunctionExpr:
+		if _, ok := derivedFunctions[strings.ToLower(n.Name.Name)]; !ok {
+			return fmt.Errorf("function %s is not allowed", n.Name.Name)
+		}
+		if n.Params == nil {
+			return nil
+		}
+		if n.Params.ColumnArgList != nil {
+			return fmt.Errorf("parametric function %s is not allowed", n.Name.Name)
+		}
+		return checkDerivedList(n.Params.Items, known)
+	case *clickhouseparser.ParamExprList:
+		// A parenthesized sub-expression, e.g. (a + b) * 2.
+		if n.ColumnArgList != nil {
+			return fmt.Errorf("unsupported expression %q", formatSQL(n))
+		}
+		return checkDerivedList(n.Items, known)
+	case *clickhouseparser.ObjectParams:
+		// Map or array element access, e.g. log_attributes['user_id'].
+		if err := checkDerivedExpr(n.Object, known); err != nil {
+			return err
+		}
+		if n.Params == nil {
+			return nil
+		}
+		return checkDerivedList(n.Params.Items, known)
+	default:
+		return fmt.Errorf("unsupported expression %q", formatSQL(node))
+	}
+}
+
+func checkDerivedList(list *clickhouseparser.ColumnExprList, known map[string]struct{}) error {
+	if list == nil {
+		return nil
+	}
+	if list.HasDistinct {
+		return fmt.Errorf("DISTINCT is not allowed")
+	}
+	for _, item := range list.Items {
+		if err := checkDerivedExpr(item, known); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkDerivedColumnRef(name string, known map[string]struct{}) error {
+	if known == nil {
+		return nil
+	}
+	if _, ok := known[name]; !ok {
+		return fmt.Errorf("unknown column %q", name)
+	}
+	return nil
+}
+
+// appendDerivedColumns adds the derived columns to the end of the query's
+// select list. Set operations are refused because only the first branch would
+// gain the columns.
+func appendDerivedColumns(stmt *clickhouseparser.SelectQuery, cols []models.DerivedColumn) error {
+	if len(cols) == 0 {
+		return nil
+	}
+	if stmt.UnionAll != nil || stmt.UnionDistinct != nil || stmt.Except != nil || stmt.Intersect != nil {
+		return &ValidationError{Message: "derived columns are not supported on UNION, EXCEPT or INTERSECT queries"}
+	}
+	items, err := parseDerivedColumns(cols, nil)
+	if err != nil {
+		return err
+	}
+	stmt.SelectItems = append(stmt.SelectItems, items...)
+	return nil
+}
@@ -0,0 +1,99 @@
+package clickhouse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestValidateDerivedColumns(t *testing.T) {
+	schema := []string{"timestamp", "duration_ms", "status", "service", "log_attributes"}
+
+	tests := []struct {
+		name    string
+		cols    []models.DerivedColumn
+		wantErr string
+	}{
+		{name: "arithmetic", cols: []models.DerivedColumn{{Name: "duration_s", Expression: "duration_ms / 1000"}}},
+		{name: "parenthesized", cols: []models.DerivedColumn{{Name: "x", Expression: "(duration_ms + 1) * 2"}}},
+		{name: "allowed functions", cols: []models.DerivedColumn{{Name: "x", Expression: "round(duration_ms / 1000, 2)"}}},
+		{name: "conditional", cols: []models.DerivedColumn{{Name: "outcome", Expression: "if(status >= 500, 'error', 'ok')"}}},
+		{name: "map access", cols: []models.DerivedColumn{{Name: "user", Expression: "lower(log_attributes['user_id'])"}}},
+		{name: "function case", cols: []models.DerivedColumn{{Name: "x", Expression: "toStartOfHour(timestamp)"}}},
+		{name: "unknown column", cols: []models.DerivedColumn{{Name: "x", Expression: "latency / 1000"}}, wantErr: `unknown column "latency"`},
+		{name: "disallowed function", cols: []models.DerivedColumn{{Name: "x", Expression: "sleep(3)"}}, wantErr: "function sleep is not allowed"},
+		{name: "aggregate", cols: []models.DerivedColumn{{Name: "x", Expression: "count()"}}, wantErr: "function count is not allowed"},
+		{name: "table function", cols: []models.DerivedColumn{{Name: "x", Expression: "file('/etc/passwd')"}}, wantErr: "not allowed"},
+		{name: "subquery", cols: []models.DerivedColumn{{Name: "x", Expression: "(SELECT 1)"}}, wantErr: "unsupported expression"},
+		{name: "IN operator", cols: []models.DerivedColumn{{Name: "x", Expression: "status IN (1, 2)"}}, wantErr: "operator IN is not allowed"},
+		{name: "trailing clause", cols: []models.DerivedColumn{{Name: "x", Expression: "status FROM system.users"}}, wantErr: "expected a single expression"},
+		{name: "second item", cols: []models.DerivedColumn{{Name: "x", Expression: "status, service"}}, wantErr: "expected a single expression"},
+		{name: "inner alias", cols: []models.DerivedColumn{{Name: "x", Expression: "status AS s"}}, wantErr: "without an alias"},
+		{name: "empty expression", cols: []models.DerivedColumn{{Name: "x", Expression: "  "}}, wantErr: "expression is required"},
+		{name: "bad name", cols: []models.DerivedColumn{{Name: "a b", Expression: "status"}}, wantErr: "invalid derived column name"},
+		{name: "shadows column", cols: []models.DerivedColumn{{Name: "status", Expression: "status + 1"}}, wantErr: "same name as a source column"},
+		{
+			name:    "duplicate name",
+			cols:    []models.DerivedColumn{{Name: "x", Expression: "status"}, {Name: "x", Expression: "service"}},
+			wantErr: `duplicate derived column "x"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDerivedColumns(tt.cols, schema)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+			}
+			if !IsValidationError(err) {
+				t.Fatalf("error %v is not a ValidationError", err)
+			}
+		})
+	}
+}
+
+func TestValidateDerivedColumnsLimit(t *testing.T) {
+	cols := make([]models.DerivedColumn, MaxDerivedColumns+1)
+	for i := range cols {
+		cols[i] = models.DerivedColumn{Name: "c" + strings.Repeat("x", i), Expression: "1"}
+	}
+	if err := ValidateDerivedColumns(cols, nil); err == nil {
+		t.Fatal("expected an error above MaxDerivedColumns")
+	}
+}
+
+func TestQueryBuilderAppendsDerivedColumns(t *testing.T) {
+	qb := NewExtendedQueryBuilder("mydb.logs", 1000).WithDerivedColumns([]models.DerivedColumn{
+		{Name: "duration_s", Expression: "duration_ms / 1000"},
+		{Name: "svc", Expression: "upper(service)"},
+	})
+
+	result, err := qb.BuildRawQueryWithLimitPolicy("SELECT timestamp, duration_ms FROM mydb.logs WHERE status = 500", 0, 100, 1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"duration_ms / 1000 AS duration_s", "upper(service) AS svc", "LIMIT 100"} {
+		if !strings.Contains(result.SQL, want) {
+			t.Errorf("SQL %q does not contain %q", result.SQL, want)
+		}
+	}
+	if strings.Index(result.SQL, "AS svc") > strings.Index(result.SQL, "FROM") {
+		t.Errorf("derived columns should be in the select list: %q", result.SQL)
+	}
+
+	if _, err := qb.BuildRawQueryWithLimitPolicy("SELECT * FROM mydb.logs UNION ALL SELECT * FROM mydb.logs", 0, 100, 1000); err == nil {
+		t.Fatal("expected derived columns on a UNION query to be rejected")
+	}
+
+	unsafe := NewExtendedQueryBuilder("mydb.logs", 1000).WithDerivedColumns([]models.DerivedColumn{{Name: "x", Expression: "sleep(3)"}})
+	if _, err := unsafe.BuildRawQueryWithLimitPolicy("SELECT * FROM mydb.logs", 0, 100, 1000); !IsValidationError(err) {
+		t.Fatalf("expected a validation error for a disallowed function, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package clickhouse
+
+// Database and table discovery for the source setup wizard. Both listings read
+// system tables, which ClickHouse filters by the connecting user's grants, so
+// only what the user may query is offered.
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// discoveryTimeoutSeconds bounds each discovery query. They back interactive
+// wizard steps, so a slow or overloaded server fails fast.
+const discoveryTimeoutSeconds = 10
+
+// builtinDatabases are ClickHouse's own databases, never a log source.
+var builtinDatabases = []string{"system", "INFORMATION_SCHEMA", "information_schema"}
+
+// ListDatabases returns the databases the user can see, excluding
+// ClickHouse's built-in ones, sorted by name.
+func (c *Client) ListDatabases(ctx context.Context) ([]string, error) {
+	queryCtx, cancel := statsQueryContext(ctx, discoveryTimeoutSeconds)
+	defer cancel()
+
+	rows, err := c.conn.Query(queryCtx, `SHOW DATABASES`)
+	if err != nil {
+		return nil, fmt.Errorf("error listing databases: %w", err)
+	}
+	defer rows.Close()
+
+	databases := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("error scanning database row: %w", err)
+		}
+		if !slices.Contains(builtinDatabases, name) {
+			databases = append(databases, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing databases: %w", err)
+	}
+	slices.Sort(databases)
+	return databases, nil
+}
+
+// ListTables returns the tables and views in database that the user can see,
+// sorted by name. Row and byte totals are nil for engines that don't track
+// them, such as views and Distributed tables.
+func (c *Client) ListTables(ctx context.Context, database string) ([]models.DiscoveredTable, error) {
+	queryCtx, cancel := statsQueryContext(ctx, discoveryTimeoutSeconds)
+	defer cancel()
+
+	rows, err := c.conn.Query(queryCtx, `
+		SELECT name, engine, total_rows, total_bytes
+		FROM system.tables
+		WHERE database = ? AND is_temporary = 0
+		ORDER BY name`, database)
+	if err != nil {
+		return nil, fmt.Errorf("error listing tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := []models.DiscoveredTable{}
+	for rows.Next() {
+		var table models.DiscoveredTable
+		if err := rows.Scan(&table.Name, &table.Engine, &table.TotalRows, &table.TotalBytes); err != nil {
+			return nil, fmt.Errorf("error scanning table row: %w", err)
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing tables: %w", err)
+	}
+	return tables, nil
+}
+
+// TableSample is a table's columns and up to a requested number of its rows.
+type TableSample struct {
+	Columns []models.ColumnInfo
+	Rows    []map[string]any
+}
+
+// SampleTable reads database.table's columns and its first limit rows, in
+// whatever order the server returns them.
+func (c *Client) SampleTable(ctx context.Context, database, table string, limit int) (*TableSample, error) {
+	columns, err := c.getColumns(ctx, database, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s.%s not found or has no columns", database, table)
+	}
+
+	timeout := discoveryTimeoutSeconds
+	query := fmt.Sprintf("SELECT * FROM %s.%s LIMIT %d", quoteIdentifier(database), quoteIdentifier(table), limit)
+	result, err := c.QueryWithOptions(ctx, query, QueryOptions{TimeoutSeconds: &timeout})
+	if err != nil {
+		return nil, err
+	}
+	return &TableSample{Columns: columns, Rows: result.Logs}, nil
+}
@@ -0,0 +1,102 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+
+	clickhouseparser "github.com/AfterShip/clickhouse-sql-parser/parser"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// Distributed tables fan a query out to a local table on every shard of a
+// cluster. Reading a single shard, or pinning which replica answers, shows
+// how rows are spread when one shard holds more than its share.
+
+// ClusterReplica is one replica of a cluster as system.clusters lists it.
+type ClusterReplica struct {
+	Shard       uint32
+	Replica     uint32
+	Host        string
+	Port        uint16
+	IsLocal     bool
+	ErrorsCount uint32
+}
+
+// WithShard makes the builder restrict queries on its Distributed table to
+// the shard numbered shard; see applyShard. Zero leaves every shard in.
+func (qb *QueryBuilder) WithShard(shard int) *QueryBuilder {
+	qb.shard = shard
+	return qb
+}
+
+// applyShard ANDs a condition on the Distributed engine's _shard_num virtual
+// column into stmt's WHERE clause. With optimize_skip_unused_shards set, the
+// other shards aren't queried at all. stmt must read the builder's table
+// directly, since a subquery or another table has no _shard_num to filter.
+func (qb *QueryBuilder) applyShard(stmt *clickhouseparser.SelectQuery) error {
+	if err := qb.validateTableReference(stmt); err != nil {
+		return &ValidationError{Message: "querying a single shard: " + err.Error()}
+	}
+
+	condition := shardCondition(qb.shard)
+	if stmt.Where != nil {
+		condition = "(" + formatSQL(stmt.Where.Expr) + ") AND " + condition
+	}
+	where, err := parseRowFilter(condition)
+	if err != nil {
+		return err
+	}
+	if stmt.Where == nil {
+		stmt.Where = where
+	} else {
+		stmt.Where.Expr = where.Expr
+	}
+	return nil
+}
+
+// shardCondition returns the condition keeping rows of shard.
+func shardCondition(shard int) string {
+	return fmt.Sprintf("(_shard_num = %d)", shard)
+}
+
+// IsDistributed reports whether table uses the Distributed engine.
+func (c *Client) IsDistributed(ctx context.Context, database, table string) (bool, error) {
+	engine, _, _, err := c.getTableEngine(ctx, database, table)
+	if err != nil {
+		return false, err
+	}
+	return engine == "Distributed", nil
+}
+
+// ClusterReplicas lists cluster's replicas by shard, as the connected server
+// sees them in system.clusters.
+func (c *Client) ClusterReplicas(ctx context.Context, cluster string) ([]ClusterReplica, error) {
+	query := `
+		SELECT shard_num, replica_num, host_name, port, is_local, errors_count
+		FROM system.clusters
+		WHERE cluster = ?
+		ORDER BY shard_num, replica_num
+	`
+	var rows driver.Rows
+	err := c.executeQueryWithHooks(ctx, query, func(hookCtx context.Context) error {
+		var err error
+		rows, err = c.conn.Query(hookCtx, query, cluster)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cluster %s: %w", cluster, err)
+	}
+	defer rows.Close()
+
+	var replicas []ClusterReplica
+	for rows.Next() {
+		var replica ClusterReplica
+		var isLocal uint8
+		if err := rows.Scan(&replica.Shard, &replica.Replica, &replica.Host, &replica.Port, &isLocal, &replica.ErrorsCount); err != nil {
+			return nil, fmt.Errorf("failed to scan cluster replica: %w", err)
+		}
+		replica.IsLocal = isLocal == 1
+		replicas = append(replicas, replica)
+	}
+	return replicas, rows.Err()
+}
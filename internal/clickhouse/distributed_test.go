@@ -0,0 +1,56 @@
+package clickhouse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQueryBuilderWithShard(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "adds a WHERE clause",
+			query: "SELECT count() FROM logs.app GROUP BY level",
+			want:  []string{"WHERE (_shard_num = 2)", "GROUP BY level"},
+		},
+		{
+			name:  "ANDs into the existing WHERE",
+			query: "SELECT * FROM logs.app WHERE level = 'error' OR level = 'warn'",
+			want:  []string{"(level = 'error' OR level = 'warn') AND (_shard_num = 2)"},
+		},
+		{
+			name:    "other table is rejected",
+			query:   "SELECT * FROM system.tables",
+			wantErr: true,
+		},
+		{
+			name:    "subquery is rejected",
+			query:   "SELECT * FROM (SELECT * FROM logs.app)",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qb := NewExtendedQueryBuilder("logs.app", 0).WithShard(2)
+			got, err := qb.BuildRawQueryWithLimitPolicy(tt.query, 0, 100, 1000)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildRawQueryWithLimitPolicy() = %q, error = %v, wantErr %v", got.SQL, err, tt.wantErr)
+			}
+			if err != nil {
+				if !IsValidationError(err) {
+					t.Errorf("error %v is not a ValidationError", err)
+				}
+				return
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got.SQL, want) {
+					t.Errorf("SQL = %q, want it to contain %q", got.SQL, want)
+				}
+			}
+		})
+	}
+}
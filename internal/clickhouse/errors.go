@@ -21,4 +21,12 @@ var (
 
 	// ErrInvalidSourceType is returned when the source type is not supported
 	ErrInvalidSourceType = errors.New("invalid source type")
+
+	// ErrSourceBusy is returned when a query gives up waiting for one of the
+	// source's concurrent query slots (connection.pool.max_concurrent_queries).
+	ErrSourceBusy = errors.New("source is busy: too many concurrent queries")
 )
+
+// ErrReadOnly is returned when a DDL operation targets a source whose
+// connection is read-only (a readonly user profile or a read-only replica).
+var ErrReadOnly = errors.New("source connection is read-only")
@@ -0,0 +1,76 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// estimateTimeoutSeconds bounds the EXPLAIN ESTIMATE dry run and the part
+// sizes read alongside it. Both only touch metadata.
+const estimateTimeoutSeconds = 10
+
+// EstimateQuery asks ClickHouse how much a SELECT would read, without running
+// it. EXPLAIN ESTIMATE reports rows, parts and marks per table; bytes are
+// estimated from each table's average uncompressed row size.
+func (c *Client) EstimateQuery(ctx context.Context, query string) (models.QueryCostEstimate, error) {
+	queryCtx, cancel := statsQueryContext(ctx, estimateTimeoutSeconds)
+	defer cancel()
+
+	rows, err := c.conn.Query(queryCtx, "EXPLAIN ESTIMATE "+query)
+	if err != nil {
+		return models.QueryCostEstimate{}, fmt.Errorf("error estimating query: %w", err)
+	}
+	defer rows.Close()
+
+	type tableEstimate struct {
+		database, table string
+		rows            uint64
+	}
+	var (
+		estimate models.QueryCostEstimate
+		tables   []tableEstimate
+	)
+	for rows.Next() {
+		var (
+			t                  tableEstimate
+			parts, rowCount, m uint64
+		)
+		if err := rows.Scan(&t.database, &t.table, &parts, &rowCount, &m); err != nil {
+			return models.QueryCostEstimate{}, fmt.Errorf("error scanning query estimate: %w", err)
+		}
+		t.rows = rowCount
+		tables = append(tables, t)
+		estimate.Rows += rowCount
+		estimate.Parts += parts
+		estimate.Marks += m
+	}
+	if err := rows.Err(); err != nil {
+		return models.QueryCostEstimate{}, fmt.Errorf("error iterating query estimate: %w", err)
+	}
+
+	for _, t := range tables {
+		if t.rows == 0 {
+			continue
+		}
+		var totalRows, totalBytes uint64
+		row := c.conn.QueryRow(queryCtx, `
+			SELECT sum(rows), sum(data_uncompressed_bytes)
+			FROM system.parts
+			WHERE active = 1 AND database = ? AND table = ?`, t.database, t.table)
+		if err := row.Scan(&totalRows, &totalBytes); err != nil {
+			return models.QueryCostEstimate{}, fmt.Errorf("error reading table size for estimate: %w", err)
+		}
+		estimate.Bytes += estimatedBytes(t.rows, totalRows, totalBytes)
+	}
+	return estimate, nil
+}
+
+// estimatedBytes scales rows by the table's average uncompressed row size.
+func estimatedBytes(rows, totalRows, totalBytes uint64) uint64 {
+	if totalRows == 0 {
+		return 0
+	}
+	return uint64(float64(rows) * (float64(totalBytes) / float64(totalRows)))
+}
@@ -0,0 +1,125 @@
+package clickhouse
+
+// Field facets: histogram-style summaries for numeric and date columns, whose
+// top distinct values say little on their own.
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+const (
+	// numericFacetBuckets is how many buckets a numeric facet aims for.
+	numericFacetBuckets = 10
+	// maxDayFacets caps the days a date facet returns, oldest first.
+	maxDayFacets = 366
+)
+
+// NumericBucket counts a numeric field's values between Min and Max.
+// Buckets come from ClickHouse's adaptive histogram, so their bounds follow
+// the data and counts are approximate.
+type NumericBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int64   `json:"count"`
+}
+
+// DayCount counts a date field's values on Day (YYYY-MM-DD, in the request's
+// timezone).
+type DayCount struct {
+	Day   string `json:"day"`
+	Count int64  `json:"count"`
+}
+
+// queryNumericFacet returns about numericFacetBuckets buckets spanning a
+// numeric field's values in the time range. conditions is the " AND (...)"
+// fragment GetFieldDistinctValues filters on.
+func (c *Client) queryNumericFacet(ctx context.Context, database, table string, params FieldValuesParams, timezone, conditions string, timeout *int) ([]NumericBucket, error) {
+	query, args := buildNumericFacetQuery(database, table, params, timezone, conditions)
+	result, err := c.QueryWithArgs(ctx, query, timeout, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query numeric facet for %s: %w", params.FieldName, err)
+	}
+	buckets := make([]NumericBucket, 0, len(result.Logs))
+	for _, row := range result.Logs {
+		lower, okMin := row["bucket_min"].(float64)
+		upper, okMax := row["bucket_max"].(float64)
+		height, okCount := row["bucket_count"].(float64)
+		if !okMin || !okMax || !okCount {
+			continue
+		}
+		buckets = append(buckets, NumericBucket{Min: lower, Max: upper, Count: int64(math.Round(height))})
+	}
+	return buckets, nil
+}
+
+// queryDayFacet counts a date or DateTime field's values per day in the time
+// range, days read in timezone.
+func (c *Client) queryDayFacet(ctx context.Context, database, table string, params FieldValuesParams, timezone, conditions string, timeout *int) ([]DayCount, error) {
+	query, args := buildDayFacetQuery(database, table, params, timezone, conditions)
+	result, err := c.QueryWithArgs(ctx, query, timeout, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query day facet for %s: %w", params.FieldName, err)
+	}
+	days := make([]DayCount, 0, len(result.Logs))
+	for _, row := range result.Logs {
+		value, ok := extractStringFromRow(row, "facet_day")
+		if !ok {
+			continue
+		}
+		count, ok := extractInt64FromRow(row, "facet_count")
+		if !ok {
+			continue
+		}
+		days = append(days, DayCount{Day: value, Count: count})
+	}
+	return days, nil
+}
+
+// buildNumericFacetQuery returns the query for queryNumericFacet: the
+// histogram aggregate, its buckets unrolled into rows.
+func buildNumericFacetQuery(database, table string, params FieldValuesParams, timezone, conditions string) (string, []any) {
+	timeRange, args := timeRangeSQL(params.TimestampField, params.StartTime, params.EndTime, timezone)
+	value := fmt.Sprintf("toFloat64(%s)", quoteIdentifier(params.FieldName))
+	return fmt.Sprintf(`
+		SELECT b.1 AS bucket_min, b.2 AS bucket_max, b.3 AS bucket_count
+		FROM (
+			SELECT histogram(%d)(%s) AS h
+			FROM %s
+			PREWHERE %s
+			WHERE isFinite(%s)%s
+		)
+		ARRAY JOIN h AS b
+		ORDER BY bucket_min
+	`, numericFacetBuckets, value, quoteTable(database, table), timeRange, value, conditions), args
+}
+
+// buildDayFacetQuery returns the query for queryDayFacet. Its aliases keep
+// clear of common column names such as day, which conditions may filter on.
+func buildDayFacetQuery(database, table string, params FieldValuesParams, timezone, conditions string) (string, []any) {
+	timeRange, args := timeRangeSQL(params.TimestampField, params.StartTime, params.EndTime, timezone)
+	field := quoteIdentifier(params.FieldName)
+	day := fmt.Sprintf("toDate(%s, %s)", field, quoteLiteral(timezone))
+	if isPlainDateType(params.FieldType) {
+		// Dates have no time of day to shift into timezone.
+		day = field
+	}
+	return fmt.Sprintf(`
+		SELECT toString(%s) AS facet_day, count() AS facet_count
+		FROM %s
+		PREWHERE %s
+		WHERE %s IS NOT NULL%s
+		GROUP BY facet_day ORDER BY facet_day LIMIT %d
+	`, day, quoteTable(database, table), timeRange, field, conditions, maxDayFacets), args
+}
+
+// isPlainDateType reports whether colType is Date or Date32, optionally
+// Nullable, as opposed to a DateTime.
+func isPlainDateType(colType string) bool {
+	switch colType {
+	case "Date", "Date32", "Nullable(Date)", "Nullable(Date32)":
+		return true
+	}
+	return false
+}
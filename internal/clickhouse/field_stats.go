@@ -0,0 +1,160 @@
+package clickhouse
+
+// Field statistics: min/max/avg/percentiles and null ratio for numeric and
+// datetime columns, computed in a single aggregate query.
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Field stats kinds. Datetime statistics are reported as Unix milliseconds.
+const (
+	FieldStatsKindNumeric  = "numeric"
+	FieldStatsKindDateTime = "datetime"
+)
+
+// FieldStatsParams holds parameters for computing statistics over one field.
+type FieldStatsParams struct {
+	FieldName      string
+	FieldType      string
+	TimestampField string    // Required: timestamp column name for time range filter
+	StartTime      time.Time // Required: start of time range
+	EndTime        time.Time // Required: end of time range
+	Timezone       string    // Optional: timezone for time conversion (defaults to UTC)
+	Timeout        *int      // Optional: query timeout in seconds
+	LogchefQL      string    // Optional: LogchefQL query string narrowing the rows
+}
+
+// FieldStatsResult holds the statistics for a field. The value statistics are
+// nil when no row in the range has a non-null value.
+type FieldStatsResult struct {
+	FieldName string `json:"field_name"`
+	FieldType string `json:"field_type"`
+	// Kind is "numeric" or "datetime"; datetime values are Unix milliseconds.
+	Kind string `json:"kind"`
+	// Count is the number of rows in range; NullCount how many had no value.
+	Count       int64    `json:"count"`
+	NullCount   int64    `json:"null_count"`
+	NullPercent float64  `json:"null_percent"`
+	Min         *float64 `json:"min"`
+	Max         *float64 `json:"max"`
+	Avg         *float64 `json:"avg"`
+	P50         *float64 `json:"p50"`
+	P95         *float64 `json:"p95"`
+	P99         *float64 `json:"p99"`
+}
+
+// FieldStatsKind classifies a column type for statistics, returning "" for
+// types that have none (strings, maps, arrays, ...).
+func FieldStatsKind(colType string) string {
+	if IsNumericColumnType(colType) {
+		return FieldStatsKindNumeric
+	}
+	clean := strings.ToLower(colType)
+	clean = strings.TrimPrefix(clean, "lowcardinality(")
+	clean = strings.TrimPrefix(clean, "nullable(")
+	if strings.HasPrefix(clean, "date") {
+		return FieldStatsKindDateTime
+	}
+	return ""
+}
+
+// GetFieldStats computes statistics for a numeric or datetime field within a
+// time range using one aggregate query with a quantiles combinator.
+func (c *Client) GetFieldStats(ctx context.Context, database, table string, params FieldStatsParams) (*FieldStatsResult, error) {
+	if err := ValidateIdentifier(params.FieldName); err != nil {
+		return nil, fmt.Errorf("invalid field name: %w", err)
+	}
+	if err := ValidateIdentifier(params.TimestampField); err != nil {
+		return nil, fmt.Errorf("invalid timestamp field: %w", err)
+	}
+	kind := FieldStatsKind(params.FieldType)
+	if kind == "" {
+		return nil, fmt.Errorf("statistics are only available for numeric and datetime fields, not %s", params.FieldType)
+	}
+	timezone := params.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if err := ValidateTimezone(timezone); err != nil {
+		return nil, fmt.Errorf("invalid timezone: %w", err)
+	}
+	timeout := params.Timeout
+	if timeout == nil {
+		defaultTimeout := 10
+		timeout = &defaultTimeout
+	}
+
+	query, args := buildFieldStatsQuery(database, table, kind, params, timezone)
+	result, err := c.QueryWithArgs(ctx, query, timeout, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statistics for %s: %w", params.FieldName, err)
+	}
+
+	stats := &FieldStatsResult{FieldName: params.FieldName, FieldType: params.FieldType, Kind: kind}
+	if len(result.Logs) > 0 {
+		fillFieldStats(stats, result.Logs[0])
+	}
+	return stats, nil
+}
+
+// buildFieldStatsQuery renders the aggregate query and its bound arguments.
+// Values are cast to Float64 (datetimes via Unix milliseconds) so every kind
+// shares one result shape; aggregates skip NULLs, so count(v) is the non-null
+// count.
+func buildFieldStatsQuery(database, table, kind string, params FieldStatsParams, timezone string) (string, []any) {
+	value := fmt.Sprintf("toFloat64(%s)", quoteIdentifier(params.FieldName))
+	if kind == FieldStatsKindDateTime {
+		value = fmt.Sprintf("toFloat64(toUnixTimestamp64Milli(toDateTime64(%s, 3)))", quoteIdentifier(params.FieldName))
+	}
+
+	timeRange, args := timeRangeSQL(params.TimestampField, params.StartTime, params.EndTime, timezone)
+	return fmt.Sprintf(`
+		SELECT count() AS total, count(v) AS non_null,
+			min(v) AS min_v, max(v) AS max_v, avg(v) AS avg_v,
+			quantiles(0.5, 0.95, 0.99)(v) AS q
+		FROM (
+			SELECT %s AS v
+			FROM %s
+			PREWHERE %s
+			WHERE 1%s
+		)
+	`, value, quoteTable(database, table), timeRange,
+		buildLogchefQLConditionsSQL(params.LogchefQL)), args
+}
+
+// fillFieldStats copies the aggregate row into stats. With no non-null values
+// ClickHouse returns 0/NaN for the aggregates, so they are left nil.
+func fillFieldStats(stats *FieldStatsResult, row map[string]any) {
+	stats.Count, _ = extractInt64FromRow(row, "total")
+	nonNull, _ := extractInt64FromRow(row, "non_null")
+	stats.NullCount = stats.Count - nonNull
+	if stats.Count > 0 {
+		stats.NullPercent = float64(stats.NullCount) * 100 / float64(stats.Count)
+	}
+	if nonNull == 0 {
+		return
+	}
+
+	stats.Min = finiteFloat(row["min_v"])
+	stats.Max = finiteFloat(row["max_v"])
+	stats.Avg = finiteFloat(row["avg_v"])
+	if q, ok := row["q"].([]float64); ok && len(q) == 3 {
+		stats.P50 = finiteFloat(q[0])
+		stats.P95 = finiteFloat(q[1])
+		stats.P99 = finiteFloat(q[2])
+	}
+}
+
+// finiteFloat returns a pointer to v when it is a finite float64, else nil.
+func finiteFloat(v any) *float64 {
+	f, ok := v.(float64)
+	if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	return &f
+}
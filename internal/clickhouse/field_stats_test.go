@@ -0,0 +1,96 @@
+package clickhouse
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFieldStatsKind(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"UInt64":                              FieldStatsKindNumeric,
+		"Nullable(Float64)":                   FieldStatsKindNumeric,
+		"Decimal(18, 4)":                      FieldStatsKindNumeric,
+		"DateTime":                            FieldStatsKindDateTime,
+		"Nullable(DateTime64(3, 'UTC'))":      FieldStatsKindDateTime,
+		"Date32":                              FieldStatsKindDateTime,
+		"String":                              "",
+		"LowCardinality(String)":              "",
+		"Map(LowCardinality(String), String)": "",
+	}
+	for colType, want := range cases {
+		if got := FieldStatsKind(colType); got != want {
+			t.Errorf("FieldStatsKind(%q) = %q, want %q", colType, got, want)
+		}
+	}
+}
+
+func TestBuildFieldStatsQuery(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	params := FieldStatsParams{
+		FieldName:      "duration_ms",
+		TimestampField: "timestamp",
+		StartTime:      start,
+		EndTime:        start.Add(time.Hour),
+		LogchefQL:      `service="api"`,
+	}
+	query, args := buildFieldStatsQuery("logs", "app", FieldStatsKindNumeric, params, "UTC")
+	for _, want := range []string{
+		"toFloat64(`duration_ms`) AS v",
+		"FROM `logs`.`app`",
+		"quantiles(0.5, 0.95, 0.99)(v) AS q",
+		"`timestamp` BETWEEN toDateTime(?, ?) AND toDateTime(?, ?)",
+		"WHERE 1 AND (",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query missing %q:\n%s", want, query)
+		}
+	}
+	wantArgs := []any{"2026-01-01 00:00:00", "UTC", "2026-01-01 01:00:00", "UTC"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+
+	params.FieldName = "seen_at"
+	query, _ = buildFieldStatsQuery("logs", "app", FieldStatsKindDateTime, params, "UTC")
+	if !strings.Contains(query, "toUnixTimestamp64Milli(toDateTime64(`seen_at`, 3))") {
+		t.Errorf("datetime query does not convert to Unix milliseconds:\n%s", query)
+	}
+}
+
+func TestFillFieldStats(t *testing.T) {
+	t.Parallel()
+
+	stats := &FieldStatsResult{}
+	fillFieldStats(stats, map[string]any{
+		"total":    uint64(200),
+		"non_null": uint64(150),
+		"min_v":    1.0,
+		"max_v":    900.0,
+		"avg_v":    42.5,
+		"q":        []float64{30, 400, 850},
+	})
+	if stats.Count != 200 || stats.NullCount != 50 || stats.NullPercent != 25 {
+		t.Errorf("counts = %d/%d (%v%%), want 200/50 (25%%)", stats.Count, stats.NullCount, stats.NullPercent)
+	}
+	if stats.Min == nil || *stats.Min != 1 || stats.P95 == nil || *stats.P95 != 400 || stats.P99 == nil || *stats.P99 != 850 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	empty := &FieldStatsResult{}
+	fillFieldStats(empty, map[string]any{
+		"total":    uint64(3),
+		"non_null": uint64(0),
+		"avg_v":    math.NaN(),
+		"q":        []float64{math.NaN(), math.NaN(), math.NaN()},
+	})
+	if empty.NullPercent != 100 || empty.Avg != nil || empty.P50 != nil {
+		t.Errorf("all-null stats = %+v, want nil values", empty)
+	}
+}
@@ -6,7 +6,9 @@ package clickhouse
 import (
 	"context"
 	"fmt"
+	"maps"
 	"math"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -28,6 +30,11 @@ type FieldValuesResult struct {
 	IsLowCard     bool             `json:"is_low_cardinality"`
 	Values        []FieldValueInfo `json:"values"`
 	TotalDistinct int64            `json:"total_distinct"`
+	// NumericBuckets summarize a numeric field's spread, and DayCounts a
+	// date field's values per day; see field_facets.go. A date field lists
+	// no Values, its TotalDistinct being the number of days.
+	NumericBuckets []NumericBucket `json:"numeric_buckets,omitempty"`
+	DayCounts      []DayCount      `json:"day_counts,omitempty"`
 }
 
 // FieldValuesParams holds parameters for fetching field distinct values.
@@ -41,6 +48,10 @@ type FieldValuesParams struct {
 	Limit          int       // Optional: max values to return (default 10, max 100)
 	Timeout        *int      // Optional: query timeout in seconds
 	LogchefQL      string    // Optional: LogchefQL query string - parsed on backend for proper SQL generation
+	RowFilter      string    // Optional: the team's row filter, ANDed into the WHERE clause
+	// MaskedColumns are the source's columns masked for the caller; their
+	// values are not listed and LogchefQL can't filter on them.
+	MaskedColumns models.MaskedColumns
 }
 
 // buildLogchefQLConditionsSQL parses a LogchefQL query and returns the SQL WHERE clause fragment.
@@ -56,8 +67,19 @@ func buildLogchefQLConditionsSQL(query string) string {
 		return ""
 	}
 
-	// Return the SQL wrapped as " AND (...)" to be appended to WHERE clause
-	return " AND (" + result.SQL + ")"
+	// Return the SQL wrapped as " AND (...)" to be appended to WHERE clause.
+	// The queries it joins bind `?` arguments, and the driver reads "\?" as an
+	// escaped placeholder and drops the backslash, even inside a string
+	// literal; doubling it keeps a user's "\?" intact.
+	return " AND (" + strings.ReplaceAll(result.SQL, `\?`, `\\?`) + ")"
+}
+
+// timeRangeSQL returns the condition bounding ts to [start, end], read in
+// timezone, and the arguments it binds.
+func timeRangeSQL(ts string, start, end time.Time, timezone string) (string, []any) {
+	const layout = "2006-01-02 15:04:05"
+	return quoteIdentifier(ts) + " BETWEEN toDateTime(?, ?) AND toDateTime(?, ?)",
+		[]any{start.UTC().Format(layout), timezone, end.UTC().Format(layout), timezone}
 }
 
 // GetFieldDistinctValues retrieves the top N distinct values for a field within a time range.
@@ -81,43 +103,74 @@ func (c *Client) GetFieldDistinctValues(ctx context.Context, database, table str
 		"field_type", params.FieldType, "limit", limit)
 
 	isLowCard := strings.Contains(params.FieldType, "LowCardinality")
-	startTimeStr := params.StartTime.UTC().Format("2006-01-02 15:04:05")
-	endTimeStr := params.EndTime.UTC().Format("2006-01-02 15:04:05")
-	additionalConditions := buildLogchefQLConditionsSQL(params.LogchefQL)
+	timeRange, args := timeRangeSQL(params.TimestampField, params.StartTime, params.EndTime, timezone)
+	if _, masked := params.MaskedColumns.Lookup(params.FieldName); masked {
+		return nil, &ValidationError{Message: fmt.Sprintf("field %q is masked for you", params.FieldName)}
+	}
+	queryConditions := buildLogchefQLConditionsSQL(params.LogchefQL)
+	if err := CheckMaskedCondition(queryConditions, params.MaskedColumns); err != nil {
+		return nil, err
+	}
+	additionalConditions := queryConditions + RowFilterConditionSQL(params.RowFilter)
+
+	kind := FieldStatsKind(params.FieldType)
+	if kind == FieldStatsKindDateTime {
+		days, err := c.queryDayFacet(ctx, database, table, params, timezone, additionalConditions, timeoutSeconds)
+		if err != nil {
+			return nil, err
+		}
+		return &FieldValuesResult{
+			FieldName:     params.FieldName,
+			FieldType:     params.FieldType,
+			IsLowCard:     isLowCard,
+			Values:        []FieldValueInfo{},
+			TotalDistinct: int64(len(days)),
+			DayCounts:     days,
+		}, nil
+	}
 
 	quotedField := quoteIdentifier(params.FieldName)
 
 	// For string-like fields, exclude empty strings. For numeric fields, no such filter.
 	emptyFilter := fmt.Sprintf("%s != ''", quotedField)
-	if isNumericColumnType(params.FieldType) {
+	if IsNumericColumnType(params.FieldType) {
 		emptyFilter = "1"
 	}
 
 	query := fmt.Sprintf(`
 		SELECT %s AS value, count() AS cnt
-		FROM %s.%s
-		PREWHERE %s BETWEEN toDateTime('%s', '%s') AND toDateTime('%s', '%s')
+		FROM %s
+		PREWHERE %s
 		WHERE %s%s
 		GROUP BY value ORDER BY cnt DESC LIMIT %d
-	`, quotedField, database, table,
-		params.TimestampField, startTimeStr, timezone, endTimeStr, timezone,
+	`, quotedField, quoteTable(database, table), timeRange,
 		emptyFilter, additionalConditions, limit)
 
-	result, err := c.QueryWithTimeout(ctx, query, timeoutSeconds)
+	result, err := c.QueryWithArgs(ctx, query, timeoutSeconds, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query distinct values for %s: %w", params.FieldName, err)
 	}
 
 	values := extractFieldValues(result)
 
-	totalDistinct := c.queryTotalDistinct(ctx, database, table, params, startTimeStr, endTimeStr, timezone, additionalConditions, timeoutSeconds)
+	totalDistinct := c.queryTotalDistinct(ctx, database, table, params, timezone, additionalConditions, timeoutSeconds)
+
+	var buckets []NumericBucket
+	if kind == FieldStatsKindNumeric {
+		// Best effort, like the distinct count: the values stand on their own.
+		buckets, err = c.queryNumericFacet(ctx, database, table, params, timezone, additionalConditions, timeoutSeconds)
+		if err != nil {
+			c.logger.Debug("skipping numeric facet", "field", params.FieldName, "error", err)
+		}
+	}
 
 	return &FieldValuesResult{
-		FieldName:     params.FieldName,
-		FieldType:     params.FieldType,
-		IsLowCard:     isLowCard,
-		Values:        values,
-		TotalDistinct: totalDistinct,
+		FieldName:      params.FieldName,
+		FieldType:      params.FieldType,
+		IsLowCard:      isLowCard,
+		Values:         values,
+		TotalDistinct:  totalDistinct,
+		NumericBuckets: buckets,
 	}, nil
 }
 
@@ -210,23 +263,23 @@ func extractInt64FromRow(row map[string]any, key string) (int64, bool) {
 	}
 }
 
-func (c *Client) queryTotalDistinct(ctx context.Context, database, table string, params FieldValuesParams, startTimeStr, endTimeStr, timezone, additionalConditions string, timeoutSeconds *int) int64 {
+func (c *Client) queryTotalDistinct(ctx context.Context, database, table string, params FieldValuesParams, timezone, additionalConditions string, timeoutSeconds *int) int64 {
 	quotedField := quoteIdentifier(params.FieldName)
 	emptyFilter := fmt.Sprintf("%s != ''", quotedField)
-	if isNumericColumnType(params.FieldType) {
+	if IsNumericColumnType(params.FieldType) {
 		emptyFilter = "1"
 	}
 
+	timeRange, args := timeRangeSQL(params.TimestampField, params.StartTime, params.EndTime, timezone)
 	query := fmt.Sprintf(`
 		SELECT uniq(%s) AS total
-		FROM %s.%s
-		PREWHERE %s BETWEEN toDateTime('%s', '%s') AND toDateTime('%s', '%s')
+		FROM %s
+		PREWHERE %s
 		WHERE %s%s
-	`, quotedField, database, table,
-		params.TimestampField, startTimeStr, timezone, endTimeStr, timezone,
+	`, quotedField, quoteTable(database, table), timeRange,
 		emptyFilter, additionalConditions)
 
-	result, err := c.QueryWithTimeout(ctx, query, timeoutSeconds)
+	result, err := c.QueryWithArgs(ctx, query, timeoutSeconds, args...)
 	if err != nil || len(result.Logs) == 0 {
 		return 0
 	}
@@ -252,11 +305,15 @@ type AllFieldValuesParams struct {
 	Limit          int       // Optional: max values per field (default 10, max 100)
 	Timeout        *int      // Optional: query timeout in seconds (default 5s for String fields)
 	LogchefQL      string    // Optional: LogchefQL query string - parsed on backend for proper SQL generation
+	RowFilter      string    // Optional: the team's row filter, ANDed into the WHERE clause
+	// MaskedColumns are the source's columns masked for the caller; their
+	// values are not listed and LogchefQL can't filter on them.
+	MaskedColumns models.MaskedColumns
 }
 
-// isNumericColumnType returns true for integer, float, and decimal types.
+// IsNumericColumnType returns true for integer, float, and decimal types.
 // Handles any nesting order of LowCardinality/Nullable wrappers.
-func isNumericColumnType(colType string) bool {
+func IsNumericColumnType(colType string) bool {
 	clean := strings.ToLower(colType)
 	// Strip all wrapper layers regardless of order
 	for {
@@ -275,9 +332,9 @@ func isNumericColumnType(colType string) bool {
 		strings.HasPrefix(clean, "decimal")
 }
 
-// isFilterableColumnType returns true if the column type is suitable for distinct value queries.
+// IsFilterableColumnType returns true if the column type is suitable for distinct value queries.
 // LowCardinality fields are always fast. String and numeric fields are included with timeout protection.
-func isFilterableColumnType(colType string) bool {
+func IsFilterableColumnType(colType string) bool {
 	lowerType := strings.ToLower(colType)
 	if strings.HasPrefix(lowerType, "map(") ||
 		strings.HasPrefix(lowerType, "array(") ||
@@ -304,17 +361,49 @@ func isFilterableColumnType(colType string) bool {
 		return true
 	}
 
-	if isNumericColumnType(colType) {
+	if IsNumericColumnType(colType) {
 		return true
 	}
 
 	return false
 }
 
+// filterableFieldColumns picks the columns GetAllFilterableFieldValues lists
+// values for, split into those read together in one batched query and those
+// queried one at a time. The timestamp column is left out: every row falls
+// in the requested range, so its per-day facet would only repeat the
+// histogram.
+func filterableFieldColumns(columns []models.ColumnInfo, params AllFieldValuesParams) (batched, single []models.ColumnInfo) {
+	for _, col := range columns {
+		// Check if this column type is suitable for distinct value queries,
+		// or gets a per-day facet instead
+		if !IsFilterableColumnType(col.Type) && FieldStatsKind(col.Type) != FieldStatsKindDateTime {
+			continue
+		}
+		if col.Name == params.TimestampField {
+			continue
+		}
+		if _, masked := params.MaskedColumns.Lookup(col.Name); masked {
+			continue
+		}
+		if isBoundedCardinalityType(col.Type) && ValidateIdentifier(col.Name) == nil {
+			batched = append(batched, col)
+		} else {
+			single = append(single, col)
+		}
+	}
+	return batched, single
+}
+
 // GetAllFilterableFieldValues retrieves distinct values for all filterable fields within a time range.
-// Filterable fields include: LowCardinality, String, Nullable(String), and Enum types.
+// Filterable fields include: LowCardinality, String, Nullable(String), Enum and
+// numeric types; date and DateTime fields get per-day counts instead.
 // This is useful for populating a field sidebar with filterable values.
-// For String fields, a shorter timeout is used to gracefully handle high cardinality columns.
+// Fields whose type bounds their cardinality (LowCardinality, Enum) are read
+// together, fieldValuesBatchSize to a query; the rest get a query each, with a
+// shorter timeout for String fields to handle high cardinality columns
+// gracefully. The queries run concurrently, at most fieldValuesConcurrency at a
+// time.
 // IMPORTANT: Time range is required to avoid scanning entire tables.
 func (c *Client) GetAllFilterableFieldValues(ctx context.Context, database, table string, params AllFieldValuesParams) (map[string]*FieldValuesResult, error) {
 	// Reuse existing getColumns function to get column metadata
@@ -323,88 +412,216 @@ func (c *Client) GetAllFilterableFieldValues(ctx context.Context, database, tabl
 		return nil, fmt.Errorf("failed to get columns: %w", err)
 	}
 
+	if err := CheckMaskedCondition(buildLogchefQLConditionsSQL(params.LogchefQL), params.MaskedColumns); err != nil {
+		return nil, err
+	}
+
+	batched, single := filterableFieldColumns(columns, params)
+
 	results := make(map[string]*FieldValuesResult)
+	var retry []models.ColumnInfo
 	var mu sync.Mutex
 
-	// Default timeout for String fields (shorter to fail fast on high cardinality)
-	stringFieldTimeout := 5
-	lowCardTimeout := 10
-
-	// Fan out the per-field distinct-value queries with bounded concurrency. Each
-	// query already carries its own timeout, so one slow field can't stall the
+	// Each query carries its own timeout, so one slow query can't stall the
 	// rest; the semaphore caps how many hit ClickHouse at once.
 	sem := make(chan struct{}, fieldValuesConcurrency)
 	var wg sync.WaitGroup
-
-	for _, col := range columns {
-		// Stop launching new work once the caller's context is done.
-		if ctx.Err() != nil {
-			c.logger.Debug("context cancelled, stopping field value queries", "error", ctx.Err())
-			break
-		}
-
-		// Check if this column type is suitable for distinct value queries
-		if !isFilterableColumnType(col.Type) {
-			continue
-		}
-
-		// Use shorter timeout for regular String fields (may be high cardinality)
-		timeout := params.Timeout
-		if timeout == nil {
-			if strings.Contains(col.Type, "LowCardinality") {
-				timeout = &lowCardTimeout
-			} else {
-				timeout = &stringFieldTimeout
-			}
-		}
-
-		// Build params for individual field query
-		fieldParams := FieldValuesParams{
-			FieldName:      col.Name,
-			FieldType:      col.Type,
-			TimestampField: params.TimestampField,
-			StartTime:      params.StartTime,
-			EndTime:        params.EndTime,
-			Timezone:       params.Timezone,
-			Limit:          params.Limit,
-			Timeout:        timeout,
-			LogchefQL:      params.LogchefQL, // Pass through user's LogchefQL query
-		}
-
-		// Acquire a slot, but honor cancellation while all slots are busy
-		// (otherwise a cancelled request keeps launching fields up to the
-		// per-field timeout).
+	// run starts job once a slot is free. It reports false, starting nothing,
+	// once the caller's context is done (otherwise a cancelled request keeps
+	// launching queries up to their timeout).
+	run := func(job func()) bool {
 		select {
 		case sem <- struct{}{}:
 		case <-ctx.Done():
-			c.logger.Debug("context cancelled while awaiting field-value slot", "error", ctx.Err())
 		}
 		if ctx.Err() != nil {
-			break // exit the loop; wg.Wait below drains in-flight queries
+			c.logger.Debug("context cancelled, stopping field value queries", "error", ctx.Err())
+			return false
 		}
 		wg.Go(func() {
 			defer func() { <-sem }()
+			job()
+		})
+		return true
+	}
+	queryEach := func(cols []models.ColumnInfo) {
+		for _, col := range cols {
+			ok := run(func() {
+				fieldResult, err := c.GetFieldDistinctValues(ctx, database, table, singleFieldValuesParams(params, col))
+				if err != nil {
+					// Log but don't fail - this field just won't have values shown.
+					// Common for high cardinality String fields that timeout.
+					c.logger.Debug("skipping field values (likely timeout or high cardinality)",
+						"field", col.Name, "type", col.Type, "error", err)
+					return
+				}
+				mu.Lock()
+				results[col.Name] = fieldResult
+				mu.Unlock()
+			})
+			if !ok {
+				return
+			}
+		}
+	}
 
-			fieldResult, err := c.GetFieldDistinctValues(ctx, database, table, fieldParams)
+	for batch := range slices.Chunk(batched, fieldValuesBatchSize) {
+		ok := run(func() {
+			batchResults, err := c.getFieldValuesBatch(ctx, database, table, batch, params)
+			mu.Lock()
+			defer mu.Unlock()
 			if err != nil {
-				// Log but don't fail - this field just won't have values shown.
-				// Common for high cardinality String fields that timeout.
-				c.logger.Debug("skipping field values (likely timeout or high cardinality)",
-					"field", fieldParams.FieldName,
-					"type", fieldParams.FieldType,
-					"error", err)
+				// Fall back to a query per field, so one failing batch doesn't
+				// hide all of its fields.
+				c.logger.Debug("field values batch failed, querying its fields one by one",
+					"fields", len(batch), "error", err)
+				retry = append(retry, batch...)
 				return
 			}
-			mu.Lock()
-			results[fieldParams.FieldName] = fieldResult
-			mu.Unlock()
+			maps.Copy(results, batchResults)
 		})
+		if !ok {
+			break
+		}
 	}
-
+	queryEach(single)
 	wg.Wait()
+
+	if len(retry) > 0 {
+		queryEach(retry)
+		wg.Wait()
+	}
+	return results, nil
+}
+
+// singleFieldValuesParams returns the params for col's own distinct-value
+// query within an all-fields request.
+func singleFieldValuesParams(params AllFieldValuesParams, col models.ColumnInfo) FieldValuesParams {
+	// Use shorter timeout for regular String fields (may be high cardinality)
+	timeout := params.Timeout
+	if timeout == nil {
+		t := 5
+		if strings.Contains(col.Type, "LowCardinality") {
+			t = 10
+		}
+		timeout = &t
+	}
+	return FieldValuesParams{
+		FieldName:      col.Name,
+		FieldType:      col.Type,
+		TimestampField: params.TimestampField,
+		StartTime:      params.StartTime,
+		EndTime:        params.EndTime,
+		Timezone:       params.Timezone,
+		Limit:          params.Limit,
+		Timeout:        timeout,
+		LogchefQL:      params.LogchefQL, // Pass through user's LogchefQL query
+		RowFilter:      params.RowFilter,
+		MaskedColumns:  params.MaskedColumns,
+	}
+}
+
+// isBoundedCardinalityType reports whether colType bounds how many distinct
+// values a column can have (LowCardinality and Enum), so batching it with
+// other fields can't blow up the batch's aggregation. Numeric columns are left
+// out: their own query adds a numeric facet.
+func isBoundedCardinalityType(colType string) bool {
+	if IsNumericColumnType(colType) {
+		return false
+	}
+	return strings.Contains(colType, "LowCardinality") ||
+		strings.HasPrefix(colType, "Enum") ||
+		strings.HasPrefix(colType, "Nullable(Enum")
+}
+
+// getFieldValuesBatch reads the top values of several fields with a single
+// query, scanning the time range once instead of twice per field; see
+// buildFieldValuesBatchQuery. Fields without values get an empty result.
+func (c *Client) getFieldValuesBatch(ctx context.Context, database, table string, fields []models.ColumnInfo, params AllFieldValuesParams) (map[string]*FieldValuesResult, error) {
+	if err := ValidateIdentifier(params.TimestampField); err != nil {
+		return nil, fmt.Errorf("invalid timestamp field: %w", err)
+	}
+	limit, _, timezone := normalizeFieldValuesParams(FieldValuesParams{Limit: params.Limit, Timezone: params.Timezone})
+	if err := ValidateTimezone(timezone); err != nil {
+		return nil, fmt.Errorf("invalid timezone: %w", err)
+	}
+	timeout := params.Timeout
+	if timeout == nil {
+		defaultTimeout := 10
+		timeout = &defaultTimeout
+	}
+
+	names := make([]string, len(fields))
+	results := make(map[string]*FieldValuesResult, len(fields))
+	for i, col := range fields {
+		names[i] = col.Name
+		results[col.Name] = &FieldValuesResult{
+			FieldName: col.Name,
+			FieldType: col.Type,
+			IsLowCard: strings.Contains(col.Type, "LowCardinality"),
+			Values:    []FieldValueInfo{},
+		}
+	}
+
+	timeRange, args := timeRangeSQL(params.TimestampField, params.StartTime, params.EndTime, timezone)
+	conditions := buildLogchefQLConditionsSQL(params.LogchefQL) + RowFilterConditionSQL(params.RowFilter)
+	query := buildFieldValuesBatchQuery(quoteTable(database, table), names, timeRange, conditions, limit)
+
+	result, err := c.QueryWithArgs(ctx, query, timeout, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query distinct values for %d fields: %w", len(fields), err)
+	}
+	for _, row := range result.Logs {
+		name, _ := extractStringFromRow(row, "__field")
+		fieldResult, ok := results[name]
+		if !ok {
+			continue
+		}
+		if total, ok := extractInt64FromRow(row, "__total"); ok {
+			fieldResult.TotalDistinct = total
+		}
+		value, ok := extractStringFromRow(row, "__value")
+		if !ok || value == "" {
+			continue
+		}
+		count, ok := extractInt64FromRow(row, "__cnt")
+		if !ok {
+			continue
+		}
+		fieldResult.Values = append(fieldResult.Values, FieldValueInfo{Value: value, Count: count})
+	}
 	return results, nil
 }
 
+// buildFieldValuesBatchQuery returns the query reading the top limit values
+// of each of fields over one scan. The inner query filters the table as
+// GetFieldDistinctValues does; ARRAY JOIN then turns each row into a (field,
+// value) pair per field, the pairs are counted, and LIMIT BY keeps each
+// field's most common values. __total is the field's distinct value count.
+// Empty strings and NULLs are skipped.
+func buildFieldValuesBatchQuery(table string, fields []string, timeRange, conditions string, limit int) string {
+	columns := make([]string, len(fields))
+	pairs := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = quoteIdentifier(field)
+		pairs[i] = fmt.Sprintf("(%s, toString(%s))", quoteLiteral(field), columns[i])
+	}
+	return fmt.Sprintf(`
+		SELECT __pair.1 AS __field, __pair.2 AS __value, count() AS __cnt,
+			count() OVER (PARTITION BY __field) AS __total
+		FROM (
+			SELECT %s FROM %s
+			PREWHERE %s
+			WHERE 1%s
+		)
+		ARRAY JOIN [%s] AS __pair
+		WHERE __value != ''
+		GROUP BY __field, __value
+		ORDER BY __field, __cnt DESC
+		LIMIT %d BY __field
+	`, strings.Join(columns, ", "), table, timeRange, conditions, strings.Join(pairs, ", "), limit)
+}
+
 // GetAllLowCardinalityFieldValues is deprecated, use GetAllFilterableFieldValues instead.
 // Kept for backwards compatibility.
 func (c *Client) GetAllLowCardinalityFieldValues(ctx context.Context, database, table string, params AllFieldValuesParams) (map[string]*FieldValuesResult, error) {
@@ -0,0 +1,122 @@
+package clickhouse
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestBuildFieldValuesBatchQuery(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeRange, _ := timeRangeSQL("timestamp", start, start.Add(time.Hour), "UTC")
+	query := buildFieldValuesBatchQuery("`logs`.`app`", []string{"level", "status"}, timeRange, " AND (`service` = 'api')", 10)
+
+	for _, want := range []string{
+		"SELECT `level`, `status` FROM `logs`.`app`",
+		"PREWHERE `timestamp` BETWEEN toDateTime(?, ?) AND toDateTime(?, ?)",
+		"WHERE 1 AND (`service` = 'api')",
+		"ARRAY JOIN [('level', toString(`level`)), ('status', toString(`status`))] AS __pair",
+		"WHERE __value != ''",
+		"count() OVER (PARTITION BY __field) AS __total",
+		"LIMIT 10 BY __field",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query = %s\nwant it to contain %q", query, want)
+		}
+	}
+}
+
+func TestIsBoundedCardinalityType(t *testing.T) {
+	tests := map[string]bool{
+		"LowCardinality(String)":           true,
+		"LowCardinality(Nullable(String))": true,
+		"Enum8('a' = 1, 'b' = 2)":          true,
+		"Nullable(Enum16('a' = 1))":        true,
+		"String":                           false,
+		"Nullable(String)":                 false,
+		"UInt16":                           false,
+		"LowCardinality(UInt8)":            false,
+	}
+	for colType, want := range tests {
+		if got := isBoundedCardinalityType(colType); got != want {
+			t.Errorf("isBoundedCardinalityType(%q) = %v, want %v", colType, got, want)
+		}
+	}
+}
+
+func TestBuildFacetQueries(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	params := FieldValuesParams{
+		FieldName:      "duration_ms",
+		FieldType:      "UInt32",
+		TimestampField: "timestamp",
+		StartTime:      start,
+		EndTime:        start.Add(time.Hour),
+	}
+	query, args := buildNumericFacetQuery("logs", "app", params, "UTC", " AND (`service` = 'api')")
+	for _, want := range []string{
+		"histogram(10)(toFloat64(`duration_ms`)) AS h",
+		"FROM `logs`.`app`",
+		"PREWHERE `timestamp` BETWEEN toDateTime(?, ?) AND toDateTime(?, ?)",
+		"WHERE isFinite(toFloat64(`duration_ms`)) AND (`service` = 'api')",
+		"ARRAY JOIN h AS b",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("numeric facet query missing %q:\n%s", want, query)
+		}
+	}
+	if len(args) != 4 {
+		t.Errorf("numeric facet args = %v, want the time range's 4", args)
+	}
+
+	params.FieldName, params.FieldType = "seen_at", "DateTime64(3)"
+	query, _ = buildDayFacetQuery("logs", "app", params, "Asia/Kolkata", "")
+	for _, want := range []string{
+		"toString(toDate(`seen_at`, 'Asia/Kolkata')) AS facet_day",
+		"WHERE `seen_at` IS NOT NULL",
+		"GROUP BY facet_day ORDER BY facet_day LIMIT 366",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("day facet query missing %q:\n%s", want, query)
+		}
+	}
+
+	params.FieldType = "Nullable(Date32)"
+	query, _ = buildDayFacetQuery("logs", "app", params, "Asia/Kolkata", "")
+	if !strings.Contains(query, "toString(`seen_at`) AS facet_day") {
+		t.Errorf("day facet query for a Date column shifts it by timezone:\n%s", query)
+	}
+}
+
+func TestFilterableFieldColumns(t *testing.T) {
+	columns := []models.ColumnInfo{
+		{Name: "timestamp", Type: "DateTime64(3)"},
+		{Name: "level", Type: "LowCardinality(String)"},
+		{Name: "email", Type: "LowCardinality(String)"},
+		{Name: "host", Type: "String"},
+		{Name: "received_at", Type: "DateTime"},
+		{Name: "attrs", Type: "Map(String, String)"},
+	}
+	params := AllFieldValuesParams{
+		TimestampField: "timestamp",
+		MaskedColumns:  models.MaskedColumns{{Column: "email", Mode: models.MaskHash}},
+	}
+	names := func(cols []models.ColumnInfo) []string {
+		out := make([]string, 0, len(cols))
+		for _, col := range cols {
+			out = append(out, col.Name)
+		}
+		return out
+	}
+
+	batched, single := filterableFieldColumns(columns, params)
+	if got := names(batched); !slices.Equal(got, []string{"level"}) {
+		t.Errorf("batched = %v, want [level]", got)
+	}
+	if got := names(single); !slices.Equal(got, []string{"host", "received_at"}) {
+		t.Errorf("single = %v, want [host received_at]", got)
+	}
+}
@@ -0,0 +1,66 @@
+package clickhouse
+
+import (
+	"fmt"
+
+	clickhouseparser "github.com/AfterShip/clickhouse-sql-parser/parser"
+)
+
+// FilteredColumns returns the columns a SELECT filters on in its WHERE and
+// PREWHERE clauses, in order of first use. Map and JSON access such as
+// log_attributes['user'] or attrs.user is reported by the top-level column;
+// subqueries are not descended into.
+func FilteredColumns(sql string) ([]string, error) {
+	stmts, err := clickhouseparser.NewParser(sql).ParseStmts()
+	if err != nil {
+		return nil, fmt.Errorf("invalid SQL syntax: %w", err)
+	}
+	if len(stmts) != 1 {
+		return nil, fmt.Errorf("expected one statement, got %d", len(stmts))
+	}
+	stmt, ok := stmts[0].(*clickhouseparser.SelectQuery)
+	if !ok {
+		return nil, fmt.Errorf("only SELECT queries are supported: %w", ErrInvalidQuery)
+	}
+
+	var (
+		columns []string
+		seen    = make(map[string]bool)
+		skip    = make(map[*clickhouseparser.Ident]bool)
+	)
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			columns = append(columns, name)
+		}
+	}
+	visit := func(node clickhouseparser.Expr) bool {
+		switch n := node.(type) {
+		case *clickhouseparser.SelectQuery:
+			return false
+		case *clickhouseparser.FunctionExpr:
+			skip[n.Name] = true
+		case *clickhouseparser.IntervalExpr:
+			skip[n.Unit] = true
+		case *clickhouseparser.Path:
+			for _, f := range n.Fields {
+				skip[f] = true
+			}
+			if len(n.Fields) > 0 {
+				add(n.Fields[0].Name)
+			}
+		case *clickhouseparser.Ident:
+			if !skip[n] {
+				add(n.Name)
+			}
+		}
+		return true
+	}
+	if stmt.Prewhere != nil {
+		clickhouseparser.Walk(stmt.Prewhere.Expr, visit)
+	}
+	if stmt.Where != nil {
+		clickhouseparser.Walk(stmt.Where.Expr, visit)
+	}
+	return columns, nil
+}
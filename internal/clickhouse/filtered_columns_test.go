@@ -0,0 +1,55 @@
+package clickhouse
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFilteredColumns(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "plain comparisons",
+			sql:  "SELECT * FROM logs.app WHERE service_name = 'api' AND severity_number >= 17",
+			want: []string{"service_name", "severity_number"},
+		},
+		{
+			name: "functions, map access and prewhere",
+			sql:  "SELECT body FROM logs PREWHERE timestamp > now() - INTERVAL 1 HOUR WHERE log_attributes['user_id'] = '42' AND lower(body) LIKE '%error%'",
+			want: []string{"timestamp", "log_attributes", "body"},
+		},
+		{
+			name: "repeated columns are reported once",
+			sql:  "SELECT * FROM logs WHERE level = 'error' OR level = 'warn'",
+			want: []string{"level"},
+		},
+		{
+			name: "subqueries are skipped",
+			sql:  "SELECT * FROM logs WHERE trace_id IN (SELECT trace_id FROM spans WHERE duration > 100)",
+			want: []string{"trace_id"},
+		},
+		{
+			name: "no where clause",
+			sql:  "SELECT count() FROM logs",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FilteredColumns(tt.sql)
+			if err != nil {
+				t.Fatalf("FilteredColumns: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("FilteredColumns = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := FilteredColumns("DROP TABLE logs"); err == nil {
+		t.Error("expected error for non-SELECT statement")
+	}
+}
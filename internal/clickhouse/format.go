@@ -0,0 +1,216 @@
+package clickhouse
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	clickhouseparser "github.com/AfterShip/clickhouse-sql-parser/parser"
+
+	"github.com/mr-karan/logchef/internal/logchefql"
+)
+
+// leadingWhere matches the WHERE keyword at the start of a bare filter.
+var leadingWhere = regexp.MustCompile(`(?i)^\s*where\s`)
+
+// FormatSQL pretty-prints sql, one clause per line with indented operands. sql
+// is either a single statement or a bare WHERE filter, with or without the
+// keyword, which is formatted on its own.
+func FormatSQL(sql string) (string, error) {
+	if !isStatement(sql) {
+		return formatFilter(sql)
+	}
+	stmts, err := clickhouseparser.NewParser(sql).ParseStmts()
+	if err != nil {
+		return "", fmt.Errorf("invalid SQL syntax: %w", err)
+	}
+	if len(stmts) != 1 {
+		return "", fmt.Errorf("expected one statement, got %d", len(stmts))
+	}
+	f := clickhouseparser.NewFormatter().WithBeautify()
+	f.WriteExpr(stmts[0])
+	return strings.TrimSpace(f.String()), nil
+}
+
+func formatFilter(filter string) (string, error) {
+	filter = leadingWhere.ReplaceAllString(filter, "")
+	stmts, err := clickhouseparser.NewParser("SELECT 1 WHERE " + filter).ParseStmts()
+	if err != nil {
+		return "", fmt.Errorf("invalid SQL syntax: %w", err)
+	}
+	sel, ok := stmts[0].(*clickhouseparser.SelectQuery)
+	if len(stmts) != 1 || !ok || sel.Where == nil || sel.GroupBy != nil || sel.Having != nil ||
+		sel.OrderBy != nil || sel.LimitBy != nil || sel.Limit != nil || sel.Settings != nil || sel.Format != nil ||
+		sel.UnionAll != nil || sel.UnionDistinct != nil || sel.Except != nil || sel.Intersect != nil {
+		return "", fmt.Errorf("expected a SELECT statement or a filter expression")
+	}
+	f := clickhouseparser.NewFormatter().WithBeautify()
+	f.WriteExpr(sel.Where.Expr)
+	return strings.TrimSpace(f.String()), nil
+}
+
+// isStatement reports whether sql starts with SELECT or WITH rather than
+// being a bare filter.
+func isStatement(sql string) bool {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return false
+	}
+	first := strings.ToUpper(fields[0])
+	return first == "SELECT" || first == "WITH"
+}
+
+// ToLogchefQL converts sql back into LogchefQL. sql is either a bare filter,
+// handled by logchefql.FromSQL, or a simple SELECT from one table such as
+// logchefql.BuildFullQuery generates. For a SELECT, the WHERE conditions that
+// bound timestampField are dropped, since the explorer's time range replaces
+// them; a plain column list becomes a pipe; ORDER BY and LIMIT are ignored.
+// A query that doesn't convert fails with a *logchefql.ParseError using
+// ErrUnsupportedFeature; one that doesn't parse with a plain error.
+func ToLogchefQL(sql, timestampField string) (string, error) {
+	if !isStatement(sql) {
+		return logchefql.FromSQL(sql)
+	}
+
+	stmts, err := clickhouseparser.NewParser(sql).ParseStmts()
+	if err != nil {
+		return "", fmt.Errorf("invalid SQL syntax: %w", err)
+	}
+	if len(stmts) != 1 {
+		return "", fmt.Errorf("expected one statement, got %d", len(stmts))
+	}
+	stmt, ok := stmts[0].(*clickhouseparser.SelectQuery)
+	if !ok || !isSimpleSelect(stmt) {
+		return "", unsupportedConversion("only SELECT ... FROM <table> [WHERE ...] queries convert to LogchefQL")
+	}
+
+	fields, err := pipeFields(stmt.SelectItems, timestampField)
+	if err != nil {
+		return "", err
+	}
+
+	var where logchefql.ASTNode
+	if stmt.Where != nil {
+		var conditions []string
+		for _, cond := range splitConjuncts(stmt.Where.Expr) {
+			if !isTimeBound(cond, timestampField) {
+				conditions = append(conditions, "("+formatSQL(cond)+")")
+			}
+		}
+		where, err = logchefql.ParseSQLFilter(strings.Join(conditions, " AND "))
+		if err != nil {
+			// Positions point into the re-rendered filter, not sql.
+			var parseErr *logchefql.ParseError
+			if errors.As(err, &parseErr) {
+				return "", &logchefql.ParseError{Code: parseErr.Code, Message: parseErr.Message}
+			}
+			return "", err
+		}
+	}
+	if len(fields) == 0 {
+		return logchefql.Render(where), nil
+	}
+	return logchefql.Render(&logchefql.QueryNode{Where: where, Select: fields}), nil
+}
+
+// isSimpleSelect reports whether stmt reads rows from a single table with no
+// clause LogchefQL can't express.
+func isSimpleSelect(stmt *clickhouseparser.SelectQuery) bool {
+	if stmt.With != nil || stmt.Top != nil || stmt.HasDistinct || stmt.DistinctOn != nil ||
+		stmt.Window != nil || stmt.Prewhere != nil || stmt.GroupBy != nil || stmt.Having != nil ||
+		stmt.LimitBy != nil || stmt.UnionAll != nil || stmt.UnionDistinct != nil ||
+		stmt.Except != nil || stmt.Intersect != nil {
+		return false
+	}
+	if stmt.From == nil {
+		return false
+	}
+	switch stmt.From.Expr.(type) {
+	case *clickhouseparser.JoinTableExpr, *clickhouseparser.TableExpr:
+		return true
+	default:
+		return false
+	}
+}
+
+// pipeFields converts the select list to LogchefQL pipe fields. "*" (followed
+// only by aliased virtual columns, which LogchefQL adds back itself) needs no
+// pipe. The timestamp column is dropped since the pipe always includes it.
+func pipeFields(items []*clickhouseparser.SelectItem, timestampField string) ([]logchefql.SelectField, error) {
+	if len(items) > 0 && identName(items[0].Expr) == "*" {
+		for _, item := range items[1:] {
+			if item.Alias == nil {
+				return nil, unsupportedConversion("only * or a plain column list converts to LogchefQL")
+			}
+		}
+		return nil, nil
+	}
+	fields := make([]logchefql.SelectField, 0, len(items))
+	for _, item := range items {
+		name := identName(item.Expr)
+		if name == "" || item.Alias != nil || len(item.Modifiers) > 0 {
+			return nil, unsupportedConversion(fmt.Sprintf("select item %s is not a plain column", formatSQL(item.Expr)))
+		}
+		if name != timestampField {
+			fields = append(fields, logchefql.SelectField{Field: name})
+		}
+	}
+	if len(fields) == 0 && timestampField != "" {
+		fields = append(fields, logchefql.SelectField{Field: timestampField})
+	}
+	return fields, nil
+}
+
+// splitConjuncts flattens a chain of ANDs, looking through parentheses, into
+// its operands.
+func splitConjuncts(expr clickhouseparser.Expr) []clickhouseparser.Expr {
+	switch e := expr.(type) {
+	case *clickhouseparser.BinaryOperation:
+		if e.Operation == clickhouseparser.TokenKind(clickhouseparser.KeywordAnd) && !e.HasNot && !e.HasGlobal {
+			return append(splitConjuncts(e.LeftExpr), splitConjuncts(e.RightExpr)...)
+		}
+	case *clickhouseparser.ParamExprList:
+		if e.Items != nil && len(e.Items.Items) == 1 && e.ColumnArgList == nil {
+			inner := splitConjuncts(e.Items.Items[0])
+			if len(inner) > 1 {
+				return inner
+			}
+		}
+	}
+	return []clickhouseparser.Expr{expr}
+}
+
+// isTimeBound reports whether cond is "ts BETWEEN a AND b" or compares ts
+// with <, <=, > or >=.
+func isTimeBound(cond clickhouseparser.Expr, timestampField string) bool {
+	if timestampField == "" {
+		return false
+	}
+	switch c := cond.(type) {
+	case *clickhouseparser.BetweenClause:
+		return !c.Not && identName(c.Expr) == timestampField
+	case *clickhouseparser.BinaryOperation:
+		switch c.Operation {
+		case clickhouseparser.TokenKindGT, clickhouseparser.TokenKindGE, clickhouseparser.TokenKindLT, clickhouseparser.TokenKindLE:
+			return identName(c.LeftExpr) == timestampField
+		}
+	}
+	return false
+}
+
+// identName returns the column an expression names, or "" if it isn't a bare
+// column reference.
+func identName(expr clickhouseparser.Expr) string {
+	if col, ok := expr.(*clickhouseparser.ColumnExpr); ok && col.Alias == nil {
+		expr = col.Expr
+	}
+	if ident, ok := expr.(*clickhouseparser.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func unsupportedConversion(message string) *logchefql.ParseError {
+	return &logchefql.ParseError{Code: logchefql.ErrUnsupportedFeature, Message: message}
+}
@@ -0,0 +1,87 @@
+package clickhouse
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mr-karan/logchef/internal/logchefql"
+)
+
+func TestFormatSQL(t *testing.T) {
+	got, err := FormatSQL("select `level`, count() from logs.app where `level` = 'it''s' and status >= 500 group by `level` limit 10")
+	if err != nil {
+		t.Fatalf("FormatSQL: %v", err)
+	}
+	for _, want := range []string{"SELECT\n  `level`,", "FROM\n  logs.app", "'it''s'", "GROUP BY\n  `level`", "LIMIT 10"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatted SQL missing %q:\n%s", want, got)
+		}
+	}
+
+	filter, err := FormatSQL("WHERE (level = 'error' OR level = 'warn') AND status >= 500")
+	if err != nil {
+		t.Fatalf("FormatSQL(filter): %v", err)
+	}
+	if want := "(level = 'error'\n  OR\n    level = 'warn')\nAND\n  status >= 500"; filter != want {
+		t.Errorf("formatted filter = %q, want %q", filter, want)
+	}
+
+	for _, sql := range []string{"SELECT FROM WHERE", "level = 'x' LIMIT 5"} {
+		if _, err := FormatSQL(sql); err == nil {
+			t.Errorf("FormatSQL(%q) should fail", sql)
+		}
+	}
+}
+
+func TestToLogchefQL(t *testing.T) {
+	full, err := logchefql.BuildFullQuery(logchefql.QueryBuildParams{
+		LogchefQL:      `(level="error" or level="warn") and body~"timeout"`,
+		TableName:      "logs.app",
+		TimestampField: "timestamp",
+		StartTime:      "2026-01-01 00:00:00",
+		EndTime:        "2026-01-01 01:00:00",
+		Timezone:       "UTC",
+		Limit:          100,
+	})
+	if err != nil {
+		t.Fatalf("BuildFullQuery: %v", err)
+	}
+
+	cases := []struct {
+		name, sql, want string
+	}{
+		{"generated query", full, `(level="error" or level="warn") and body~"timeout"`},
+		{"bare filter", "`level` = 'error'", `level="error"`},
+		{"time range only", "SELECT * FROM logs.app WHERE timestamp >= now() - INTERVAL 1 HOUR ORDER BY timestamp DESC", ``},
+		{"column list", "SELECT timestamp, service, `level` FROM logs.app WHERE service = 'api'", `service="api" | service level`},
+		{"negation", "SELECT * FROM logs.app WHERE NOT (`level` = 'debug') AND NOT ((a = 1) OR (b = 2))", `not level="debug" and not (a=1 or b=2)`},
+		{"virtual columns", "SELECT *, (lower(msg)) AS `msg_lower` FROM logs.app WHERE status > 499", `status>499`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ToLogchefQL(tc.sql, "timestamp")
+			if err != nil || got != tc.want {
+				t.Fatalf("ToLogchefQL = %q, %v; want %q", got, err, tc.want)
+			}
+		})
+	}
+
+	unsupported := []string{
+		"SELECT level, count() FROM logs.app GROUP BY level",
+		"SELECT * FROM logs.app a JOIN logs.other b ON a.id = b.id",
+		"SELECT lower(level) FROM logs.app",
+		"SELECT * FROM logs.app WHERE level IN ('a', 'b')",
+	}
+	for _, sql := range unsupported {
+		if _, err := ToLogchefQL(sql, "timestamp"); err == nil {
+			t.Errorf("ToLogchefQL(%q) should fail", sql)
+		}
+	}
+
+	_, err = ToLogchefQL("SELECT * FROM logs.app WHERE lower(level) = 'x'", "timestamp")
+	var parseErr *logchefql.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Code != logchefql.ErrUnsupportedFeature || parseErr.Position != nil {
+		t.Errorf("err = %#v, want ErrUnsupportedFeature without a position", err)
+	}
+}
@@ -0,0 +1,39 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+// InsertJSONEachRow inserts rows, one JSON object per line, into
+// database.table as an async insert, so many small pushes are batched into
+// few parts by the server. With wait the call returns once ClickHouse has
+// flushed the rows; otherwise once they are buffered. Fields that are not
+// columns of the table are skipped, and timestamps are parsed best-effort so
+// both RFC 3339 and ClickHouse's own formats are accepted.
+func (c *Client) InsertJSONEachRow(ctx context.Context, database, table string, rows []byte, wait bool) error {
+	if err := ValidateIdentifier(database); err != nil {
+		return fmt.Errorf("invalid database: %w", err)
+	}
+	if err := ValidateIdentifier(table); err != nil {
+		return fmt.Errorf("invalid table: %w", err)
+	}
+
+	stmt := fmt.Sprintf("INSERT INTO %s.%s FORMAT JSONEachRow", quoteIdentifier(database), quoteIdentifier(table))
+	// Hooks log the statement, so they see it without the row data.
+	err := c.executeQueryWithHooks(ctx, stmt, func(hookCtx context.Context) error {
+		hookCtx = clickhouse.Context(hookCtx,
+			clickhouse.WithAsync(wait),
+			clickhouse.WithSettings(clickhouse.Settings{
+				"input_format_skip_unknown_fields": 1,
+				"date_time_input_format":           "best_effort",
+			}))
+		return c.conn.Exec(hookCtx, stmt+"\n"+string(rows))
+	})
+	if err != nil {
+		return fmt.Errorf("inserting rows: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package clickhouse
+
+// JSON field discovery: sample recent rows of String columns, detect values
+// holding JSON objects and report their flattened keys with types and
+// frequencies, so users can filter on e.g. body.user.id.
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// JSON sampling limits.
+const (
+	DefaultJSONSampleSize = 1000
+	MaxJSONSampleSize     = 10000
+	maxJSONFieldDepth     = 8
+	maxJSONFields         = 500
+)
+
+// JSON value types reported for discovered keys.
+const (
+	JSONTypeString = "string"
+	JSONTypeNumber = "number"
+	JSONTypeBool   = "bool"
+	JSONTypeArray  = "array"
+	JSONTypeObject = "object" // only for objects nested deeper than maxJSONFieldDepth
+	JSONTypeNull   = "null"
+)
+
+// JSONFieldsParams holds parameters for sampling String columns for JSON.
+type JSONFieldsParams struct {
+	Columns        []string  // Required: String columns to inspect
+	TimestampField string    // Required: timestamp column name for time range filter
+	StartTime      time.Time // Required: start of time range
+	EndTime        time.Time // Required: end of time range
+	Timezone       string    // Optional: timezone for time conversion (defaults to UTC)
+	SampleSize     int       // Optional: most recent rows to sample (default 1000, max 10000)
+	Timeout        *int      // Optional: query timeout in seconds
+	LogchefQL      string    // Optional: LogchefQL query string narrowing the rows
+}
+
+// IsStringColumnType reports whether a column type holds plain strings,
+// ignoring LowCardinality/Nullable wrappers.
+func IsStringColumnType(colType string) bool {
+	clean := strings.ToLower(strings.TrimSpace(colType))
+	for {
+		prev := clean
+		clean = strings.TrimPrefix(clean, "lowcardinality(")
+		clean = strings.TrimPrefix(clean, "nullable(")
+		clean = strings.TrimSuffix(clean, ")")
+		if clean == prev {
+			break
+		}
+	}
+	return clean == "string" || strings.HasPrefix(clean, "fixedstring(")
+}
+
+// GetJSONFields samples the most recent rows in a time range and reports the
+// keys of JSON objects found in the given String columns.
+func (c *Client) GetJSONFields(ctx context.Context, database, table string, params JSONFieldsParams) (*models.JSONFieldsResult, error) {
+	if len(params.Columns) == 0 {
+		return nil, fmt.Errorf("at least one column is required")
+	}
+	for _, col := range params.Columns {
+		if err := ValidateIdentifier(col); err != nil {
+			return nil, fmt.Errorf("invalid column name: %w", err)
+		}
+	}
+	if err := ValidateIdentifier(params.TimestampField); err != nil {
+		return nil, fmt.Errorf("invalid timestamp field: %w", err)
+	}
+	timezone := params.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if err := ValidateTimezone(timezone); err != nil {
+		return nil, fmt.Errorf("invalid timezone: %w", err)
+	}
+	if params.SampleSize <= 0 {
+		params.SampleSize = DefaultJSONSampleSize
+	}
+	params.SampleSize = min(params.SampleSize, MaxJSONSampleSize)
+	timeout := params.Timeout
+	if timeout == nil {
+		defaultTimeout := 10
+		timeout = &defaultTimeout
+	}
+
+	query, args := buildJSONSampleQuery(database, table, params, timezone)
+	result, err := c.QueryWithArgs(ctx, query, timeout, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample rows: %w", err)
+	}
+	return summarizeJSONFields(params.Columns, result.Logs), nil
+}
+
+// buildJSONSampleQuery selects the columns of the most recent rows in range,
+// returning the query and its bound arguments.
+func buildJSONSampleQuery(database, table string, params JSONFieldsParams, timezone string) (string, []any) {
+	cols := make([]string, len(params.Columns))
+	for i, col := range params.Columns {
+		cols[i] = quoteIdentifier(col)
+	}
+	ts := quoteIdentifier(params.TimestampField)
+
+	timeRange, args := timeRangeSQL(params.TimestampField, params.StartTime, params.EndTime, timezone)
+	return fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		PREWHERE %s
+		WHERE 1%s
+		ORDER BY %s DESC
+		LIMIT %d
+	`, strings.Join(cols, ", "), quoteTable(database, table), timeRange,
+		buildLogchefQLConditionsSQL(params.LogchefQL),
+		ts, params.SampleSize), args
+}
+
+type jsonFieldTally struct {
+	column string
+	path   []string
+	count  int64
+	types  map[string]int64
+}
+
+// summarizeJSONFields flattens the JSON objects found in rows and tallies
+// each key once per row.
+func summarizeJSONFields(columns []string, rows []map[string]any) *models.JSONFieldsResult {
+	result := &models.JSONFieldsResult{
+		SampledRows: int64(len(rows)),
+		Columns:     make([]models.JSONColumnSummary, len(columns)),
+		Fields:      []models.JSONField{},
+	}
+	tallies := make(map[string]*jsonFieldTally)
+	for i, col := range columns {
+		result.Columns[i].Column = col
+		for _, row := range rows {
+			obj, ok := parseJSONObject(row[col])
+			if !ok {
+				continue
+			}
+			result.Columns[i].JSONRows++
+			flattenJSON(obj, nil, 0, func(path []string, typ string) {
+				key := col + "\x00" + strings.Join(path, "\x00")
+				t := tallies[key]
+				if t == nil {
+					t = &jsonFieldTally{column: col, path: slices.Clone(path), types: make(map[string]int64)}
+					tallies[key] = t
+				}
+				t.count++
+				t.types[typ]++
+			})
+		}
+	}
+
+	for _, t := range tallies {
+		types := make([]string, 0, len(t.types))
+		for typ := range t.types {
+			types = append(types, typ)
+		}
+		slices.SortFunc(types, func(a, b string) int {
+			return cmp.Or(cmp.Compare(t.types[b], t.types[a]), strings.Compare(a, b))
+		})
+		result.Fields = append(result.Fields, models.JSONField{
+			Column:    t.column,
+			Path:      t.path,
+			Field:     logchefQLFieldRef(t.column, t.path),
+			Type:      types[0],
+			Types:     types,
+			Count:     t.count,
+			Frequency: float64(t.count) / float64(result.SampledRows),
+		})
+	}
+	slices.SortFunc(result.Fields, func(a, b models.JSONField) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Field, b.Field))
+	})
+	if len(result.Fields) > maxJSONFields {
+		result.Fields = result.Fields[:maxJSONFields]
+		result.Truncated = true
+	}
+	return result
+}
+
+// parseJSONObject returns v decoded as a JSON object. Values that are not
+// strings holding an object (arrays, scalars, plain text) are skipped.
+func parseJSONObject(v any) (map[string]any, bool) {
+	var s string
+	switch val := v.(type) {
+	case string:
+		s = val
+	case *string:
+		if val == nil {
+			return nil, false
+		}
+		s = *val
+	default:
+		return nil, false
+	}
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "{") {
+		return nil, false
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(s), &obj); err != nil {
+		return nil, false
+	}
+	return obj, true
+}
+
+// flattenJSON calls emit for every leaf of obj. Arrays are leaves; objects
+// nested beyond maxJSONFieldDepth are reported as "object".
+func flattenJSON(obj map[string]any, prefix []string, depth int, emit func(path []string, typ string)) {
+	for key, v := range obj {
+		path := append(slices.Clip(prefix), key)
+		switch val := v.(type) {
+		case map[string]any:
+			if depth+1 >= maxJSONFieldDepth {
+				emit(path, JSONTypeObject)
+				continue
+			}
+			flattenJSON(val, path, depth+1, emit)
+		case string:
+			emit(path, JSONTypeString)
+		case float64:
+			emit(path, JSONTypeNumber)
+		case bool:
+			emit(path, JSONTypeBool)
+		case []any:
+			emit(path, JSONTypeArray)
+		default:
+			emit(path, JSONTypeNull)
+		}
+	}
+}
+
+var plainJSONKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// logchefQLFieldRef renders a column and key path as a LogchefQL field,
+// quoting keys that aren't plain identifiers.
+func logchefQLFieldRef(column string, path []string) string {
+	var b strings.Builder
+	b.WriteString(column)
+	for _, key := range path {
+		b.WriteByte('.')
+		if plainJSONKey.MatchString(key) {
+			b.WriteString(key)
+			continue
+		}
+		escaped := strings.ReplaceAll(key, `\`, `\\`)
+		b.WriteString(`"` + strings.ReplaceAll(escaped, `"`, `\"`) + `"`)
+	}
+	return b.String()
+}
@@ -0,0 +1,117 @@
+package clickhouse
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestIsStringColumnType(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]bool{
+		"String":                              true,
+		"Nullable(String)":                    true,
+		"LowCardinality(String)":              true,
+		"LowCardinality(Nullable(String))":    true,
+		"FixedString(16)":                     true,
+		"UInt64":                              false,
+		"Map(LowCardinality(String), String)": false,
+		"JSON":                                false,
+	}
+	for colType, want := range cases {
+		if got := IsStringColumnType(colType); got != want {
+			t.Errorf("IsStringColumnType(%q) = %v, want %v", colType, got, want)
+		}
+	}
+}
+
+func TestBuildJSONSampleQuery(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query, args := buildJSONSampleQuery("logs", "app", JSONFieldsParams{
+		Columns:        []string{"body", "message"},
+		TimestampField: "timestamp",
+		StartTime:      start,
+		EndTime:        start.Add(time.Hour),
+		SampleSize:     500,
+		LogchefQL:      `service="api"`,
+	}, "UTC")
+	for _, want := range []string{
+		"SELECT `body`, `message`",
+		"FROM `logs`.`app`",
+		"`timestamp` BETWEEN toDateTime(?, ?) AND toDateTime(?, ?)",
+		"WHERE 1 AND (",
+		"ORDER BY `timestamp` DESC",
+		"LIMIT 500",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query missing %q:\n%s", want, query)
+		}
+	}
+	if len(args) != 4 || args[0] != "2026-01-01 00:00:00" || args[2] != "2026-01-01 01:00:00" {
+		t.Errorf("args = %v", args)
+	}
+}
+
+func TestSummarizeJSONFields(t *testing.T) {
+	t.Parallel()
+
+	msg := `{"level":"warn"}`
+	rows := []map[string]any{
+		{"body": `{"user":{"id":"u1","age":30},"tags":["a"],"my key":1}`, "message": "plain text"},
+		{"body": ` {"user":{"id":42}}`, "message": &msg},
+		{"body": `[1, 2]`, "message": nil},
+		{"body": `{not json`, "message": (*string)(nil)},
+	}
+	result := summarizeJSONFields([]string{"body", "message"}, rows)
+
+	if result.SampledRows != 4 {
+		t.Errorf("SampledRows = %d, want 4", result.SampledRows)
+	}
+	wantColumns := []models.JSONColumnSummary{{Column: "body", JSONRows: 2}, {Column: "message", JSONRows: 1}}
+	if !slices.Equal(result.Columns, wantColumns) {
+		t.Errorf("Columns = %+v, want %+v", result.Columns, wantColumns)
+	}
+
+	got := make(map[string]models.JSONField)
+	var order []string
+	for _, f := range result.Fields {
+		got[f.Field] = f
+		order = append(order, f.Field)
+	}
+	if order[0] != "body.user.id" {
+		t.Errorf("most frequent field = %q, want body.user.id (order %v)", order[0], order)
+	}
+
+	id := got["body.user.id"]
+	if id.Count != 2 || id.Frequency != 0.5 || !slices.Equal(id.Path, []string{"user", "id"}) {
+		t.Errorf("body.user.id = %+v, want count 2, frequency 0.5", id)
+	}
+	// Ties between types break alphabetically.
+	if id.Type != JSONTypeNumber || !slices.Equal(id.Types, []string{JSONTypeNumber, JSONTypeString}) {
+		t.Errorf("body.user.id types = %q %v, want number first of [number string]", id.Type, id.Types)
+	}
+	if f := got["body.tags"]; f.Type != JSONTypeArray {
+		t.Errorf("body.tags type = %q, want array", f.Type)
+	}
+	if _, ok := got[`body."my key"`]; !ok {
+		t.Errorf("key with a space not quoted; fields = %v", order)
+	}
+	if f := got["message.level"]; f.Column != "message" || f.Type != JSONTypeString {
+		t.Errorf("message.level = %+v, want a string key of message", f)
+	}
+}
+
+func TestSummarizeJSONFieldsEmpty(t *testing.T) {
+	t.Parallel()
+
+	result := summarizeJSONFields([]string{"body"}, nil)
+	if result.SampledRows != 0 || len(result.Fields) != 0 || result.Fields == nil {
+		t.Errorf("empty sample = %+v, want no rows and an empty field list", result)
+	}
+}
@@ -0,0 +1,226 @@
+package clickhouse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	clickhouseparser "github.com/AfterShip/clickhouse-sql-parser/parser"
+
+	"github.com/mr-karan/logchef/internal/logchefql"
+)
+
+// DefaultWideTableColumns is the column count above which LintSQL flags
+// SELECT * when SQLLintOptions.WideTableColumns is unset.
+const DefaultWideTableColumns = 50
+
+// regexFunctions are the filtering functions that take a column and a regex.
+var regexFunctions = map[string]struct{}{
+	"match":                {},
+	"multimatchany":        {},
+	"multimatchanyindex":   {},
+	"multimatchallindices": {},
+}
+
+// SQLLintOptions is what LintSQL knows about the source a query will run
+// against. Checks whose option is unset are skipped.
+type SQLLintOptions struct {
+	// Columns are the source's columns, including virtual ones.
+	Columns []string
+	// IndexedColumns are the columns the table is sorted by.
+	IndexedColumns []string
+	// TimestampField is the source's timestamp column.
+	TimestampField string
+	// MaxLimit is the largest LIMIT the explorer will return.
+	MaxLimit int
+	// WideTableColumns is the column count above which SELECT * is flagged;
+	// 0 uses DefaultWideTableColumns.
+	WideTableColumns int
+}
+
+// LintSQL parses sql as a single SELECT and returns warnings about it: columns
+// in WHERE/PREWHERE that aren't in the schema, regex matches on columns that
+// aren't indexed, no filter on the timestamp column, SELECT * on a wide table,
+// and a LIMIT above MaxLimit. Positions are 1-based lines and columns into sql.
+// Subqueries are not descended into. A parse failure is returned as an error.
+func LintSQL(sql string, opts SQLLintOptions) ([]logchefql.Diagnostic, error) {
+	stmts, err := clickhouseparser.NewParser(sql).ParseStmts()
+	if err != nil {
+		return nil, fmt.Errorf("invalid SQL syntax: %w", err)
+	}
+	if len(stmts) != 1 {
+		return nil, fmt.Errorf("expected one statement, got %d", len(stmts))
+	}
+	stmt, ok := stmts[0].(*clickhouseparser.SelectQuery)
+	if !ok {
+		return nil, fmt.Errorf("only SELECT queries are supported: %w", ErrInvalidQuery)
+	}
+
+	l := &sqlLinter{sql: sql, opts: opts, known: toSet(opts.Columns), indexed: toSet(opts.IndexedColumns)}
+	// Aliases defined in SELECT may be used in WHERE.
+	for _, item := range stmt.SelectItems {
+		if item.Alias != nil {
+			l.known[item.Alias.Name] = struct{}{}
+		}
+	}
+	if stmt.Prewhere != nil {
+		l.lintFilter(stmt.Prewhere.Expr)
+	}
+	if stmt.Where != nil {
+		l.lintFilter(stmt.Where.Expr)
+	}
+	if opts.TimestampField != "" && !l.timeBound {
+		l.add(logchefql.WarnMissingTimeBound, fmt.Sprintf("no filter on %q; the query reads the whole table", opts.TimestampField), opts.TimestampField, stmt.Pos())
+	}
+	l.lintSelectStar(stmt)
+	l.lintLimit(stmt)
+	return l.diags, nil
+}
+
+type sqlLinter struct {
+	sql       string
+	opts      SQLLintOptions
+	known     map[string]struct{}
+	indexed   map[string]struct{}
+	timeBound bool
+	diags     []logchefql.Diagnostic
+}
+
+func (l *sqlLinter) add(code, message, field string, pos clickhouseparser.Pos) {
+	l.diags = append(l.diags, logchefql.Diagnostic{
+		Code:     code,
+		Message:  message,
+		Field:    field,
+		Position: positionAt(l.sql, int(pos)),
+	})
+}
+
+// lintFilter checks the columns a WHERE or PREWHERE expression references,
+// using the same rules as FilteredColumns for what counts as a column.
+func (l *sqlLinter) lintFilter(expr clickhouseparser.Expr) {
+	reported := make(map[string]bool)
+	skip := make(map[*clickhouseparser.Ident]bool)
+	column := func(ident *clickhouseparser.Ident) {
+		name := ident.Name
+		if name == l.opts.TimestampField {
+			l.timeBound = true
+		}
+		if len(l.opts.Columns) == 0 || reported[name] {
+			return
+		}
+		if _, ok := l.known[name]; !ok {
+			reported[name] = true
+			l.add(logchefql.WarnUnknownField, fmt.Sprintf("column %q is not in the source schema", name), name, ident.Pos())
+		}
+	}
+	clickhouseparser.Walk(expr, func(node clickhouseparser.Expr) bool {
+		switch n := node.(type) {
+		case *clickhouseparser.SelectQuery:
+			return false
+		case *clickhouseparser.FunctionExpr:
+			skip[n.Name] = true
+			l.lintRegexCall(n)
+		case *clickhouseparser.IntervalExpr:
+			skip[n.Unit] = true
+		case *clickhouseparser.Path:
+			for _, f := range n.Fields {
+				skip[f] = true
+			}
+			if len(n.Fields) > 0 {
+				column(n.Fields[0])
+			}
+		case *clickhouseparser.Ident:
+			if !skip[n] {
+				column(n)
+			}
+		}
+		return true
+	})
+}
+
+// lintRegexCall flags match(col, ...) and friends when col isn't indexed.
+func (l *sqlLinter) lintRegexCall(fn *clickhouseparser.FunctionExpr) {
+	if l.opts.IndexedColumns == nil || fn.Name == nil {
+		return
+	}
+	if _, ok := regexFunctions[strings.ToLower(fn.Name.Name)]; !ok {
+		return
+	}
+	if fn.Params == nil || fn.Params.Items == nil || len(fn.Params.Items.Items) == 0 {
+		return
+	}
+	arg := fn.Params.Items.Items[0]
+	if col, ok := arg.(*clickhouseparser.ColumnExpr); ok {
+		arg = col.Expr
+	}
+	var ident *clickhouseparser.Ident
+	switch a := arg.(type) {
+	case *clickhouseparser.Ident:
+		ident = a
+	case *clickhouseparser.Path:
+		if len(a.Fields) > 0 {
+			ident = a.Fields[0]
+		}
+	}
+	if ident == nil {
+		return
+	}
+	if _, ok := l.indexed[ident.Name]; ok {
+		return
+	}
+	l.add(logchefql.WarnRegexOnUnindexedColumn,
+		fmt.Sprintf("%s() on %q, which is not indexed, scans every row in the time range", fn.Name.Name, ident.Name),
+		ident.Name, fn.Pos())
+}
+
+func (l *sqlLinter) lintSelectStar(stmt *clickhouseparser.SelectQuery) {
+	wide := l.opts.WideTableColumns
+	if wide <= 0 {
+		wide = DefaultWideTableColumns
+	}
+	if len(l.opts.Columns) <= wide {
+		return
+	}
+	for _, item := range stmt.SelectItems {
+		if ident, ok := item.Expr.(*clickhouseparser.Ident); ok && ident.Name == "*" {
+			l.add(logchefql.WarnSelectStarOnWideTable,
+				fmt.Sprintf("SELECT * reads all %d columns; select only the ones you need", len(l.opts.Columns)),
+				"", ident.Pos())
+			return
+		}
+	}
+}
+
+func (l *sqlLinter) lintLimit(stmt *clickhouseparser.SelectQuery) {
+	if l.opts.MaxLimit <= 0 || stmt.Limit == nil || stmt.Limit.Limit == nil {
+		return
+	}
+	limit, err := strconv.Atoi(formatSQL(stmt.Limit.Limit))
+	if err != nil || limit <= l.opts.MaxLimit {
+		return
+	}
+	l.add(logchefql.WarnLimitTooLarge,
+		fmt.Sprintf("LIMIT %d is above the maximum of %d and will be capped", limit, l.opts.MaxLimit),
+		"", stmt.Limit.Limit.Pos())
+}
+
+// positionAt converts a byte offset into sql to a 1-based line and column,
+// counting columns in runes like the LogchefQL lexer does.
+func positionAt(sql string, offset int) *logchefql.Position {
+	if offset < 0 || offset > len(sql) {
+		return nil
+	}
+	before := sql[:offset]
+	line := strings.Count(before, "\n") + 1
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	return &logchefql.Position{Line: line, Column: utf8.RuneCountInString(before[lineStart:]) + 1}
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,78 @@
+package clickhouse
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mr-karan/logchef/internal/logchefql"
+)
+
+func TestLintSQL(t *testing.T) {
+	opts := SQLLintOptions{
+		Columns:        []string{"timestamp", "service", "body", "log_attributes"},
+		IndexedColumns: []string{"service", "timestamp"},
+		TimestampField: "timestamp",
+		MaxLimit:       1000,
+	}
+
+	type warning struct {
+		code         string
+		line, column int
+	}
+	tests := []struct {
+		name string
+		sql  string
+		opts *SQLLintOptions
+		want []warning
+	}{
+		{
+			name: "clean query",
+			sql:  "SELECT timestamp, body FROM logs WHERE timestamp > now() - INTERVAL 1 HOUR AND match(service, '^api') AND log_attributes['user'] = 'bob' LIMIT 100",
+		},
+		{
+			name: "unknown column, regex and no time bound",
+			sql:  "SELECT body AS msg\nFROM logs\nWHERE sevrity = 'error' AND match(body, 'timeout') AND msg != ''",
+			want: []warning{
+				{logchefql.WarnUnknownField, 3, 7},
+				{logchefql.WarnRegexOnUnindexedColumn, 3, 29},
+				{logchefql.WarnMissingTimeBound, 1, 1},
+			},
+		},
+		{
+			name: "limit above the maximum",
+			sql:  "SELECT body FROM logs WHERE timestamp > now() - INTERVAL 1 DAY LIMIT 50000",
+			want: []warning{{logchefql.WarnLimitTooLarge, 1, 70}},
+		},
+		{
+			name: "select star on a wide table",
+			sql:  "SELECT * FROM logs PREWHERE timestamp > now() - INTERVAL 5 MINUTE",
+			opts: &SQLLintOptions{Columns: []string{"timestamp", "a", "b"}, TimestampField: "timestamp", WideTableColumns: 2},
+			want: []warning{{logchefql.WarnSelectStarOnWideTable, 1, 8}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := opts
+			if tt.opts != nil {
+				o = *tt.opts
+			}
+			got, err := LintSQL(tt.sql, o)
+			if err != nil {
+				t.Fatalf("LintSQL: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d warnings, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, w := range tt.want {
+				pos := got[i].Position
+				if got[i].Code != w.code || pos == nil || pos.Line != w.line || pos.Column != w.column {
+					t.Errorf("warning %d = %s at %s, want %s at %d:%d", i, got[i].Code, fmt.Sprint(pos), w.code, w.line, w.column)
+				}
+			}
+		})
+	}
+
+	if _, err := LintSQL("SELECT FROM WHERE", opts); err == nil {
+		t.Error("LintSQL(invalid) returned no error")
+	}
+}
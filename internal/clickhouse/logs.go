@@ -35,6 +35,7 @@ type TimeWindow string
 const (
 	// Second-based windows
 	TimeWindow1s  TimeWindow = "1s"  // 1 second
+	TimeWindow2s  TimeWindow = "2s"  // 2 seconds
 	TimeWindow5s  TimeWindow = "5s"  // 5 seconds
 	TimeWindow10s TimeWindow = "10s" // 10 seconds
 	TimeWindow15s TimeWindow = "15s" // 15 seconds
@@ -42,6 +43,7 @@ const (
 
 	// Minute-based windows
 	TimeWindow1m  TimeWindow = "1m"  // 1 minute
+	TimeWindow2m  TimeWindow = "2m"  // 2 minutes
 	TimeWindow5m  TimeWindow = "5m"  // 5 minutes
 	TimeWindow10m TimeWindow = "10m" // 10 minutes
 	TimeWindow15m TimeWindow = "15m" // 15 minutes
@@ -64,6 +66,9 @@ type LogContextParams struct {
 	BeforeOffset    int  // Offset for before query (for pagination)
 	AfterOffset     int  // Offset for after query (for pagination)
 	ExcludeBoundary bool // When true, use < instead of <= for before query (for pagination)
+	// Scope keeps both queries to rows with these column values, so context
+	// stays within one stream (e.g. the same service and pod).
+	Scope models.LogContextScope
 }
 
 // LogContextResult holds the logs retrieved before, at, and after the target time.
@@ -76,10 +81,19 @@ type LogContextResult struct {
 
 // HistogramParams defines parameters for generating histogram data.
 type HistogramParams struct {
-	Window   TimeWindow
-	Query    string // Raw SQL query to use as base for histogram
-	GroupBy  string // Optional: Field to group by for segmented histograms.
-	Timezone string // Optional: Timezone identifier for time-based operations.
+	Window  TimeWindow
+	Query   string // Raw SQL query to use as base for histogram
+	GroupBy string // Optional: Field to group by for segmented histograms.
+	// GroupByExpr is the SQL expression for GroupBy, such as a Map key, already
+	// validated and quoted by the caller. When empty, GroupBy must be a plain
+	// column name.
+	GroupByExpr string
+	Timezone    string // Optional: Timezone identifier for time-based operations.
+	// RowFilter is the team's row filter, ANDed into Query; see WithRowFilter.
+	RowFilter string
+	// MaskedColumns are the source's columns masked for the caller, which
+	// Query and GroupBy must not filter or group on.
+	MaskedColumns models.MaskedColumns
 	// Query execution timeout in seconds. If not specified, uses default timeout.
 	QueryTimeout *int
 }
@@ -131,10 +145,12 @@ func (c *Client) GetHistogramData(ctx context.Context, tableName, timestampField
 	if err := ValidateTimezone(timezone); err != nil {
 		return nil, fmt.Errorf("invalid timezone: %w", err)
 	}
-	if params.GroupBy != "" {
+	groupByExpr := params.GroupByExpr
+	if params.GroupBy != "" && groupByExpr == "" {
 		if err := ValidateIdentifier(params.GroupBy); err != nil {
 			return nil, fmt.Errorf("invalid group_by field: %w", err)
 		}
+		groupByExpr = quoteIdentifier(params.GroupBy)
 	}
 
 	intervalFunc, err := windowToIntervalFunc(params.Window, timestampField, timezone)
@@ -142,13 +158,19 @@ func (c *Client) GetHistogramData(ctx context.Context, tableName, timestampField
 		return nil, err
 	}
 
-	qb := NewQueryBuilder(tableName, 0)
+	if groupByExpr != "" {
+		if err := CheckMaskedCondition(" AND ("+groupByExpr+")", params.MaskedColumns); err != nil {
+			return nil, err
+		}
+	}
+
+	qb := NewQueryBuilder(tableName, 0).WithRowFilter(params.RowFilter).WithMaskedColumns(params.MaskedColumns, nil)
 	baseQuery, err := qb.RemoveLimitClause(params.Query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to process base query: %w", err)
 	}
 
-	query, err := c.buildHistogramQuery(baseQuery, timestampField, intervalFunc, params.GroupBy)
+	query, err := c.buildHistogramQuery(baseQuery, timestampField, intervalFunc, groupByExpr)
 	if err != nil {
 		return nil, err
 	}
@@ -159,10 +181,10 @@ func (c *Client) GetHistogramData(ctx context.Context, tableName, timestampField
 		return nil, fmt.Errorf("failed to execute histogram query: %w", err)
 	}
 
-	results := c.parseHistogramResults(result, params.GroupBy != "")
+	results := c.parseHistogramResults(result, groupByExpr != "")
 
 	notice := ""
-	if params.GroupBy != "" {
+	if groupByExpr != "" {
 		for _, row := range results {
 			if row.IsOther {
 				notice = fmt.Sprintf("Showing top %d series by %q; the rest are aggregated into an \"Other\" bucket.", defaultHistogramSeriesLimit, params.GroupBy)
@@ -179,38 +201,41 @@ func (c *Client) GetHistogramData(ctx context.Context, tableName, timestampField
 }
 
 func windowToIntervalFunc(window TimeWindow, timestampField, timezone string) (string, error) {
+	ts, tz := quoteIdentifier(timestampField), quoteLiteral(timezone)
 	switch window {
 	case TimeWindow1s:
 		// toStartOfSecond only supports DateTime64 in some ClickHouse builds.
 		// Use toStartOfInterval for 1s so both DateTime and DateTime64 sources work.
-		return fmt.Sprintf("toStartOfInterval(%s, INTERVAL 1 SECOND, '%s')", timestampField, timezone), nil
-	case TimeWindow5s, TimeWindow10s, TimeWindow15s, TimeWindow30s:
+		return fmt.Sprintf("toStartOfInterval(%s, INTERVAL 1 SECOND, %s)", ts, tz), nil
+	case TimeWindow2s, TimeWindow5s, TimeWindow10s, TimeWindow15s, TimeWindow30s:
 		seconds := strings.TrimSuffix(string(window), "s")
-		return fmt.Sprintf("toStartOfInterval(%s, INTERVAL %s SECOND, '%s')", timestampField, seconds, timezone), nil
+		return fmt.Sprintf("toStartOfInterval(%s, INTERVAL %s SECOND, %s)", ts, seconds, tz), nil
 	case TimeWindow1m:
-		return fmt.Sprintf("toStartOfMinute(%s, '%s')", timestampField, timezone), nil
+		return fmt.Sprintf("toStartOfMinute(%s, %s)", ts, tz), nil
 	case TimeWindow5m:
-		return fmt.Sprintf("toStartOfFiveMinute(%s, '%s')", timestampField, timezone), nil
-	case TimeWindow10m, TimeWindow15m, TimeWindow30m:
+		return fmt.Sprintf("toStartOfFiveMinute(%s, %s)", ts, tz), nil
+	case TimeWindow2m, TimeWindow10m, TimeWindow15m, TimeWindow30m:
 		minutes := strings.TrimSuffix(string(window), "m")
-		return fmt.Sprintf("toStartOfInterval(%s, INTERVAL %s MINUTE, '%s')", timestampField, minutes, timezone), nil
+		return fmt.Sprintf("toStartOfInterval(%s, INTERVAL %s MINUTE, %s)", ts, minutes, tz), nil
 	case TimeWindow1h:
-		return fmt.Sprintf("toStartOfHour(%s, '%s')", timestampField, timezone), nil
+		return fmt.Sprintf("toStartOfHour(%s, %s)", ts, tz), nil
 	case TimeWindow2h, TimeWindow3h, TimeWindow6h, TimeWindow12h, TimeWindow24h:
 		hours := strings.TrimSuffix(string(window), "h")
-		return fmt.Sprintf("toStartOfInterval(%s, INTERVAL %s HOUR, '%s')", timestampField, hours, timezone), nil
+		return fmt.Sprintf("toStartOfInterval(%s, INTERVAL %s HOUR, %s)", ts, hours, tz), nil
 	default:
 		return "", fmt.Errorf("invalid time window: %s", window)
 	}
 }
 
-func (c *Client) buildHistogramQuery(baseQuery, timestampField, intervalFunc, groupBy string) (string, error) {
+// buildHistogramQuery counts baseQuery's rows per bucket, per value of
+// groupByExpr when it is set.
+func (c *Client) buildHistogramQuery(baseQuery, timestampField, intervalFunc, groupByExpr string) (string, error) {
 	modifiedQuery, err := c.ensureTimestampInQuery(baseQuery, timestampField)
 	if err != nil {
 		return "", fmt.Errorf("failed to modify query for histogram: %w", err)
 	}
 
-	if groupBy == "" || strings.TrimSpace(groupBy) == "" {
+	if strings.TrimSpace(groupByExpr) == "" {
 		return fmt.Sprintf(`
 			SELECT %s AS bucket, count(*) AS log_count
 			FROM (%s) AS raw_logs
@@ -222,7 +247,6 @@ func (c *Client) buildHistogramQuery(baseQuery, timestampField, intervalFunc, gr
 	// Keep the original group value through the aggregate/rank/join chain so
 	// nullable values retain their identity. Convert only the displayed top-N
 	// value, after the null-safe join has assigned its rank.
-	quotedGroupBy := quoteIdentifier(groupBy)
 	return fmt.Sprintf(`
 		WITH aggregated AS (
 			SELECT
@@ -259,7 +283,7 @@ func (c *Client) buildHistogramQuery(baseQuery, timestampField, intervalFunc, gr
 			ON (a.group_value = r.group_value) OR (isNull(a.group_value) AND isNull(r.group_value))
 		GROUP BY a.bucket, group_value, is_other, is_null
 		ORDER BY a.bucket ASC, log_count DESC
-	`, intervalFunc, quotedGroupBy, modifiedQuery, defaultHistogramSeriesLimit, defaultHistogramSeriesLimit, defaultHistogramSeriesLimit), nil
+	`, intervalFunc, groupByExpr, modifiedQuery, defaultHistogramSeriesLimit, defaultHistogramSeriesLimit, defaultHistogramSeriesLimit), nil
 }
 
 func (c *Client) parseHistogramResults(result *models.QueryResult, hasGroupBy bool) []HistogramData {
@@ -469,6 +493,7 @@ func (c *Client) GetSurroundingLogs(ctx context.Context, tableName, timestampFie
 	var result LogContextResult
 	var totalExecutionMs float64
 
+	ts := quoteIdentifier(timestampField)
 	// Format the target timestamp for ClickHouse DateTime64
 	targetTimeStr := params.TargetTime.UTC().Format("2006-01-02 15:04:05.000")
 
@@ -483,14 +508,18 @@ func (c *Client) GetSurroundingLogs(ctx context.Context, tableName, timestampFie
 	// Use OFFSET for pagination when loading more
 	// Note: Explicitly include timestamp field in SELECT to handle MATERIALIZED columns
 	// (SELECT * doesn't include MATERIALIZED columns in ClickHouse)
+	// Scope values are bound as arguments, after the target time.
+	scope, scopeArgs := contextScopeCondition(params.Scope)
+	args := append([]any{targetTimeStr}, scopeArgs...)
+
 	beforeQuery := fmt.Sprintf(`
 		SELECT %s, * FROM %s
-		WHERE %s %s toDateTime64('%s', 3, 'UTC')
+		WHERE %s %s toDateTime64(?, 3, 'UTC')%s
 		ORDER BY %s DESC
 		LIMIT %d OFFSET %d
-	`, timestampField, tableName, timestampField, beforeOp, targetTimeStr, timestampField, params.BeforeLimit, params.BeforeOffset)
+	`, ts, tableName, ts, beforeOp, scope, ts, params.BeforeLimit, params.BeforeOffset)
 
-	beforeResult, err := c.QueryWithTimeout(ctx, beforeQuery, queryTimeout)
+	beforeResult, err := c.QueryWithArgs(ctx, beforeQuery, queryTimeout, args...)
 	if err != nil {
 		c.logger.Error("failed to query before logs", "error", err)
 		return nil, fmt.Errorf("failed to query logs before target time: %w", err)
@@ -504,12 +533,12 @@ func (c *Client) GetSurroundingLogs(ctx context.Context, tableName, timestampFie
 	// Note: Explicitly include timestamp field in SELECT to handle MATERIALIZED columns
 	afterQuery := fmt.Sprintf(`
 		SELECT %s, * FROM %s
-		WHERE %s > toDateTime64('%s', 3, 'UTC')
+		WHERE %s > toDateTime64(?, 3, 'UTC')%s
 		ORDER BY %s ASC
 		LIMIT %d OFFSET %d
-	`, timestampField, tableName, timestampField, targetTimeStr, timestampField, params.AfterLimit, params.AfterOffset)
+	`, ts, tableName, ts, scope, ts, params.AfterLimit, params.AfterOffset)
 
-	afterResult, err := c.QueryWithTimeout(ctx, afterQuery, queryTimeout)
+	afterResult, err := c.QueryWithArgs(ctx, afterQuery, queryTimeout, args...)
 	if err != nil {
 		c.logger.Error("failed to query after logs", "error", err)
 		return nil, fmt.Errorf("failed to query logs after target time: %w", err)
@@ -534,6 +563,19 @@ func (c *Client) GetSurroundingLogs(ctx context.Context, tableName, timestampFie
 	return &result, nil
 }
 
+// contextScopeCondition returns the " AND `field` = ?" conditions for a log
+// context scope, in field order, with the values to bind to them. ClickHouse
+// converts each string value to its column's type.
+func contextScopeCondition(scope models.LogContextScope) (string, []any) {
+	var b strings.Builder
+	args := make([]any, 0, len(scope))
+	for _, field := range scope.Fields() {
+		fmt.Fprintf(&b, " AND %s = ?", quoteIdentifier(field))
+		args = append(args, scope[field])
+	}
+	return b.String(), args
+}
+
 // reverseLogSlice reverses a slice of log maps in place and returns it.
 func reverseLogSlice(logs []map[string]any) []map[string]any {
 	for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
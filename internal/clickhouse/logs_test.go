@@ -1,8 +1,11 @@
 package clickhouse
 
 import (
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
 )
 
 // TestEnsureTimestampInQuery tests the ensureTimestampInQuery function
@@ -260,8 +263,8 @@ func TestHistogramQueryConstruction(t *testing.T) {
 // TestTimeWindowParsing tests various time window formats
 func TestTimeWindowParsing(t *testing.T) {
 	validWindows := []string{
-		"1s", "5s", "10s", "15s", "30s",
-		"1m", "5m", "10m", "15m", "30m",
+		"1s", "2s", "5s", "10s", "15s", "30s",
+		"1m", "2m", "5m", "10m", "15m", "30m",
 		"1h", "2h", "3h", "6h", "12h", "24h",
 	}
 
@@ -289,6 +292,24 @@ func TestWindowToIntervalFuncUsesGenericSecondBucketFunction(t *testing.T) {
 	}
 }
 
+// Histogram buckets take the zone name as is, so only names ClickHouse knows
+// may pass validation: an Etc/GMT zone rather than "UTC+05:00".
+func TestWindowToIntervalFuncFixedOffsetZone(t *testing.T) {
+	if err := ValidateTimezone("UTC+05:00"); err == nil {
+		t.Fatal("ValidateTimezone(UTC+05:00) = nil, want an error")
+	}
+	if err := ValidateTimezone("Etc/GMT-5"); err != nil {
+		t.Fatalf("ValidateTimezone(Etc/GMT-5): %v", err)
+	}
+	intervalExpr, err := windowToIntervalFunc(TimeWindow1h, "_timestamp", "Etc/GMT-5")
+	if err != nil {
+		t.Fatalf("windowToIntervalFunc returned error: %v", err)
+	}
+	if !strings.HasSuffix(intervalExpr, ", 'Etc/GMT-5')") || !strings.HasPrefix(intervalExpr, "toStartOfHour(") {
+		t.Fatalf("expected toStartOfHour in Etc/GMT-5, got: %s", intervalExpr)
+	}
+}
+
 func TestExtractGroupValueDereferencesPointerTypes(t *testing.T) {
 	stringValue := "checkout"
 	byteValue := []byte("payments")
@@ -450,7 +471,7 @@ func TestBuildGroupedHistogramQueryUsesNullSafeRankedRemainder(t *testing.T) {
 		"SELECT * FROM logs",
 		"timestamp",
 		"toStartOfMinute(timestamp, 'UTC')",
-		"service",
+		"`service`",
 	)
 	if err != nil {
 		t.Fatalf("buildHistogramQuery returned error: %v", err)
@@ -596,3 +617,17 @@ func normalizeWhitespace(s string) string {
 func intPtr(i int) *int {
 	return &i
 }
+
+func TestContextScopeCondition(t *testing.T) {
+	cond, args := contextScopeCondition(models.LogContextScope{"service_name": "api", "pod": "api-7f9c"})
+	if want := " AND `pod` = ? AND `service_name` = ?"; cond != want {
+		t.Errorf("condition = %q, want %q", cond, want)
+	}
+	if len(args) != 2 || args[0] != "api-7f9c" || args[1] != "api" {
+		t.Errorf("args = %v, want [api-7f9c api]", args)
+	}
+
+	if cond, args := contextScopeCondition(nil); cond != "" || len(args) != 0 {
+		t.Errorf("empty scope = %q, %v, want no condition", cond, args)
+	}
+}
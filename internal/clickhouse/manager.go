@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/mr-karan/logchef/internal/secrets"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
@@ -30,6 +31,7 @@ type Manager struct {
 	hooks      []QueryHook    // Hooks applied to all managed clients.
 	stopHealth chan struct{}  // Channel to signal health check goroutine to stop.
 	healthWG   sync.WaitGroup // WaitGroup to wait for health check goroutine to exit.
+	secrets    *secrets.Resolver
 }
 
 // NewManager creates a new ClickHouse connection manager.
@@ -49,6 +51,12 @@ func NewManager(log *slog.Logger) *Manager {
 	return m
 }
 
+// SetSecretResolver sets the resolver for secret references in source hosts
+// and passwords. Call it before any source is added.
+func (m *Manager) SetSecretResolver(resolver *secrets.Resolver) {
+	m.secrets = resolver
+}
+
 // StartBackgroundHealthChecks launches a goroutine to periodically check
 // the health of all managed connections.
 // nolint:contextcheck // Background goroutine intentionally uses its own context
@@ -107,10 +115,12 @@ func (m *Manager) checkAllSourcesHealth() {
 }
 
 // updateHealthStatus is a helper method to update the health status of a source.
-func (m *Manager) updateHealthStatus(sourceID models.SourceID, isHealthy bool, errorMsg string) {
+// A nil checkErr marks the source healthy with the probed capabilities.
+func (m *Manager) updateHealthStatus(sourceID models.SourceID, checkErr error, caps *models.SourceHealthCapabilities) {
 	m.healthMux.Lock()
 	defer m.healthMux.Unlock()
 
+	isHealthy := checkErr == nil
 	newStatus := models.HealthStatusUnhealthy
 	if isHealthy {
 		newStatus = models.HealthStatusHealthy
@@ -119,20 +129,43 @@ func (m *Manager) updateHealthStatus(sourceID models.SourceID, isHealthy bool, e
 	oldHealth, existed := m.health[sourceID]
 	statusChanged := !existed || oldHealth.Status != newStatus
 
-	m.health[sourceID] = models.SourceHealth{
-		SourceID:    sourceID,
-		Status:      newStatus,
-		LastChecked: time.Now(),
-		Error:       errorMsg,
+	health := models.SourceHealth{
+		SourceID:     sourceID,
+		Status:       newStatus,
+		LastChecked:  time.Now(),
+		Capabilities: caps,
+	}
+	if !isHealthy {
+		health.Error = checkErr.Error()
+		health.ErrorKind = ClassifyHealthError(checkErr)
 	}
+	m.health[sourceID] = health
 
 	if statusChanged {
 		if isHealthy {
 			m.logger.Debug("source healthy", "source_id", sourceID)
 		} else {
-			m.logger.Warn("source unhealthy", "source_id", sourceID, "error", errorMsg)
+			m.logger.Warn("source unhealthy", "source_id", sourceID, "error_kind", health.ErrorKind, "error", health.Error)
 		}
 	}
+	wasWritable := oldHealth.Capabilities == nil || oldHealth.Capabilities.CanDDL
+	if caps != nil && !caps.CanDDL && wasWritable {
+		m.logger.Warn("source connection is read-only, DDL flows disabled", "source_id", sourceID, "reason", caps.ReadOnlyReason)
+	}
+}
+
+// probeCapabilities runs the capability probe for a client that just passed
+// its ping, against the source's own table when the client knows it.
+func (m *Manager) probeCapabilities(ctx context.Context, client *Client) *models.SourceHealthCapabilities {
+	probeCtx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+
+	var database, table string
+	if client.source != nil {
+		database, table = client.source.Connection.Database, client.source.Connection.TableName
+	}
+	caps := client.ProbeCapabilities(probeCtx, database, table)
+	return &caps
 }
 
 // checkSource checks a single source and updates the health map.
@@ -143,10 +176,17 @@ func (m *Manager) checkSource(ctx context.Context, sourceID models.SourceID) {
 
 	if err != nil { // Error getting client (e.g., removed during check)
 		m.logger.Warn("client not found during health check", "source_id", sourceID)
-		m.updateHealthStatus(sourceID, false, fmt.Sprintf("failed to get client for health check: %v", err))
+		m.updateHealthStatus(sourceID, fmt.Errorf("failed to get client for health check: %w", err), nil)
 		return
 	}
 
+	// Replicas are probed on their own connections first, so a reconnect
+	// below already prefers the ones that answered.
+	replicaCtx, replicaCancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	replicas := client.CheckReplicas(replicaCtx)
+	replicaCancel()
+	defer m.setReplicaHealth(sourceID, replicas)
+
 	rootCtx, rootCancel := context.WithTimeout(ctx, HealthCheckTimeout*2)
 	defer rootCancel()
 
@@ -185,14 +225,25 @@ func (m *Manager) checkSource(ctx context.Context, sourceID models.SourceID) {
 					"source_id", sourceID,
 					"error", reconnectErr)
 			}
-			m.updateHealthStatus(sourceID, false, fmt.Sprintf("reconnection failed: %v", reconnectErr))
+			m.updateHealthStatus(sourceID, fmt.Errorf("reconnection failed: %w", reconnectErr), nil)
 		} else {
 			m.logger.Debug("reconnected to source", "source_id", sourceID)
-			m.updateHealthStatus(sourceID, true, "")
+			m.updateHealthStatus(sourceID, nil, m.probeCapabilities(rootCtx, client))
 		}
 	} else {
 		// Connection is healthy after ping
-		m.updateHealthStatus(sourceID, true, "")
+		m.updateHealthStatus(sourceID, nil, m.probeCapabilities(rootCtx, client))
+	}
+}
+
+// setReplicaHealth attaches the replicas' statuses from a health check to
+// the source's cached health.
+func (m *Manager) setReplicaHealth(sourceID models.SourceID, replicas []models.ReplicaHealth) {
+	m.healthMux.Lock()
+	defer m.healthMux.Unlock()
+	if health, ok := m.health[sourceID]; ok {
+		health.Replicas = replicas
+		m.health[sourceID] = health
 	}
 }
 
@@ -215,6 +266,13 @@ func (m *Manager) GetCachedHealth(sourceID models.SourceID) models.SourceHealth
 	return health
 }
 
+// RequireDDL reports whether the source's last health check found its
+// connection able to run DDL, returning an ErrReadOnly error if not. Sources
+// whose capabilities haven't been probed yet pass.
+func (m *Manager) RequireDDL(sourceID models.SourceID) error {
+	return RequireDDL(m.GetCachedHealth(sourceID).Capabilities)
+}
+
 // AddSource creates a new ClickHouse client connection based on the source details,
 // applies existing hooks, stores it in the manager pool, and initializes health.
 // Modified to always create a client entry even if initial connection fails.
@@ -249,8 +307,12 @@ func (m *Manager) AddSource(ctx context.Context, source *models.Source) error {
 		Password:      source.Connection.Password,
 		SourceID:      strconv.FormatInt(int64(source.ID), 10), // Convert SourceID to string for metrics
 		Source:        source,                                  // Pass source for enhanced metrics
+		Protocol:      source.Connection.Protocol,
 		TLSEnable:     source.Connection.TLSEnable,
+		TLS:           TLSOptionsFor(source.Connection),
 		QuerySettings: source.Connection.Settings.ToSettingsMap(), // Per-source query settings.
+		Pool:          source.Connection.Pool,
+		Secrets:       m.secrets,
 	}, m.logger)
 
 	if err != nil {
@@ -434,7 +496,11 @@ func (m *Manager) CreateTemporaryClient(ctx context.Context, source *models.Sour
 		Database:  source.Connection.Database,
 		Username:  source.Connection.Username,
 		Password:  source.Connection.Password,
+		Protocol:  source.Connection.Protocol,
 		TLSEnable: source.Connection.TLSEnable,
+		TLS:       TLSOptionsFor(source.Connection),
+		Pool:      source.Connection.Pool,
+		Secrets:   m.secrets,
 	}, m.logger.With("validation", true))
 
 	if err != nil {
@@ -0,0 +1,229 @@
+package clickhouse
+
+import (
+	"fmt"
+	"strings"
+
+	clickhouseparser "github.com/AfterShip/clickhouse-sql-parser/parser"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// Column masking hides a source's sensitive columns from users without the
+// view_unmasked permission: queries may still select them, but each value
+// reads through the column's mask (a hash or a fixed placeholder).
+
+// WithMaskedColumns makes the builder mask the given columns in the query's
+// results; see applyMasking. columns is the table's schema, used to expand
+// SELECT *.
+func (qb *QueryBuilder) WithMaskedColumns(masked models.MaskedColumns, columns []models.ColumnInfo) *QueryBuilder {
+	qb.masked = masked
+	qb.maskSchema = columns
+	return qb
+}
+
+// CheckMaskedCondition returns a ValidationError if condition, a " AND (...)"
+// fragment appended to a WHERE clause, reads a masked column: filtering on a
+// column would reveal its values one guess at a time.
+func CheckMaskedCondition(condition string, masked models.MaskedColumns) error {
+	if len(masked) == 0 || strings.TrimSpace(condition) == "" {
+		return nil
+	}
+	sql := "SELECT 1 FROM t WHERE 1" + strings.ReplaceAll(condition, "''", rowFilterQuotePlaceholder)
+	stmts, err := clickhouseparser.NewParser(sql).ParseStmts()
+	if err != nil || len(stmts) != 1 {
+		return &ValidationError{Message: fmt.Sprintf("invalid condition: %v", err)}
+	}
+	stmt, ok := stmts[0].(*clickhouseparser.SelectQuery)
+	if !ok {
+		return &ValidationError{Message: "invalid condition"}
+	}
+	return checkMaskedReferences(stmt, masked)
+}
+
+// applyMasking rewrites stmt so every masked column it returns reads through
+// its mask: a masked column selected on its own is wrapped in its mask and
+// keeps its name, and SELECT * is expanded from the schema so the masked
+// columns among it can be wrapped too. Any other use of a masked column, in
+// an expression, WHERE, GROUP BY or ORDER BY, would let its values leak and is
+// rejected, as are the forms that select columns without naming them. Like a
+// row filter, masking requires stmt to read the builder's table directly.
+func (qb *QueryBuilder) applyMasking(stmt *clickhouseparser.SelectQuery) error {
+	if err := qb.checkMasking(stmt); err != nil {
+		return err
+	}
+
+	items := make([]*clickhouseparser.SelectItem, 0, len(stmt.SelectItems))
+	for _, item := range stmt.SelectItems {
+		ident, ok := item.Expr.(*clickhouseparser.Ident)
+		switch {
+		case ok && isStarIdent(ident):
+			if len(qb.maskSchema) == 0 {
+				return &ValidationError{Message: "this source has masked columns: SELECT * needs the table's schema, name the columns instead"}
+			}
+			for _, col := range qb.maskSchema {
+				if col.Virtual {
+					continue
+				}
+				expanded, err := qb.maskedSelectItem(col.Name, nil)
+				if err != nil {
+					return err
+				}
+				items = append(items, expanded)
+			}
+		case ok:
+			if _, masked := qb.masked.Lookup(ident.Name); masked {
+				wrapped, err := qb.maskedSelectItem(ident.Name, item.Alias)
+				if err != nil {
+					return err
+				}
+				item = wrapped
+			}
+			items = append(items, item)
+		default:
+			items = append(items, item)
+		}
+	}
+	stmt.SelectItems = items
+	return nil
+}
+
+// checkMasking rejects stmt if it reads around the builder's table or uses a
+// masked column anywhere a mask can't be applied; see applyMasking.
+func (qb *QueryBuilder) checkMasking(stmt *clickhouseparser.SelectQuery) error {
+	if err := qb.validateDirectRead(stmt); err != nil {
+		return &ValidationError{Message: "this source has masked columns: " + err.Error()}
+	}
+	return checkMaskedReferences(stmt, qb.masked)
+}
+
+// maskedSelectItem returns the SELECT item for column: the column itself, or
+// its mask named alias (the column's own name by default) when it is masked.
+func (qb *QueryBuilder) maskedSelectItem(column string, alias *clickhouseparser.Ident) (*clickhouseparser.SelectItem, error) {
+	mask, masked := qb.masked.Lookup(column)
+	if !masked {
+		expr, err := parseDerivedExpression(quoteIdentifier(column))
+		if err != nil {
+			return nil, fmt.Errorf("invalid column %q: %w", column, err)
+		}
+		return &clickhouseparser.SelectItem{Expr: expr, Alias: alias}, nil
+	}
+	expr, err := parseDerivedExpression(mask.Expression())
+	if err != nil {
+		return nil, fmt.Errorf("invalid mask for column %q: %w", column, err)
+	}
+	if alias == nil {
+		alias = &clickhouseparser.Ident{Name: column}
+	}
+	return &clickhouseparser.SelectItem{Expr: expr, Alias: alias}, nil
+}
+
+// checkMaskedReferences returns a ValidationError if stmt reads a masked
+// column other than as a whole top-level SELECT item, or selects columns
+// without naming them: t.*, COLUMNS(), SELECT modifiers such as EXCEPT, and
+// * anywhere but the SELECT list and count(*).
+func checkMaskedReferences(stmt *clickhouseparser.SelectQuery, masked models.MaskedColumns) error {
+	skip := make(map[*clickhouseparser.Ident]bool)
+	for _, item := range stmt.SelectItems {
+		if ident, ok := item.Expr.(*clickhouseparser.Ident); ok && len(item.Modifiers) == 0 {
+			skip[ident] = true
+		}
+	}
+	// The FROM clause names the table, not columns.
+	clickhouseparser.Walk(stmt.From, func(node clickhouseparser.Expr) bool {
+		if ident, ok := node.(*clickhouseparser.Ident); ok {
+			skip[ident] = true
+		}
+		return true
+	})
+	skipAlias := func(alias clickhouseparser.Expr) {
+		if ident, ok := alias.(*clickhouseparser.Ident); ok {
+			skip[ident] = true
+		}
+	}
+	maskedErr := func(column string) error {
+		return &ValidationError{Message: fmt.Sprintf("column %q is masked for you: it can be selected on its own, but not used in expressions, filters, grouping or sorting", column)}
+	}
+
+	var err error
+	clickhouseparser.Walk(stmt, func(node clickhouseparser.Expr) bool {
+		if err != nil {
+			return false
+		}
+		switch n := node.(type) {
+		case *clickhouseparser.SelectItem:
+			if len(n.Modifiers) > 0 {
+				err = &ValidationError{Message: "this source has masked columns: SELECT modifiers such as EXCEPT and REPLACE are not allowed"}
+				return false
+			}
+			if n.Alias != nil {
+				skip[n.Alias] = true
+			}
+		case *clickhouseparser.AliasExpr:
+			skipAlias(n.Alias)
+		case *clickhouseparser.CTEStmt:
+			skipAlias(n.Alias)
+		case *clickhouseparser.IntervalExpr:
+			skip[n.Unit] = true
+		case *clickhouseparser.FunctionExpr:
+			skip[n.Name] = true
+			switch strings.ToLower(n.Name.Name) {
+			case "columns":
+				err = &ValidationError{Message: "this source has masked columns: COLUMNS() is not allowed"}
+				return false
+			case "count":
+				clickhouseparser.Walk(n.Params, func(arg clickhouseparser.Expr) bool {
+					if ident, ok := arg.(*clickhouseparser.Ident); ok && isStarIdent(ident) {
+						skip[ident] = true
+					}
+					return true
+				})
+			}
+		case *clickhouseparser.NestedIdentifier:
+			skip[n.Ident] = true
+			if n.DotIdent != nil {
+				skip[n.DotIdent] = true
+				if isStarIdent(n.DotIdent) {
+					err = &ValidationError{Message: "this source has masked columns: qualified * is not allowed"}
+					return false
+				}
+			}
+			for _, ident := range []*clickhouseparser.Ident{n.Ident, n.DotIdent} {
+				if ident == nil {
+					continue
+				}
+				if _, ok := masked.Lookup(ident.Name); ok {
+					err = maskedErr(ident.Name)
+					return false
+				}
+			}
+		case *clickhouseparser.Path:
+			for _, field := range n.Fields {
+				skip[field] = true
+				if _, ok := masked.Lookup(field.Name); ok {
+					err = maskedErr(field.Name)
+					return false
+				}
+			}
+		case *clickhouseparser.Ident:
+			if skip[n] {
+				return true
+			}
+			if isStarIdent(n) {
+				err = &ValidationError{Message: "this source has masked columns: * is only allowed in the SELECT list and count(*)"}
+				return false
+			}
+			if _, ok := masked.Lookup(n.Name); ok {
+				err = maskedErr(n.Name)
+				return false
+			}
+		}
+		return true
+	})
+	return err
+}
+
+// isStarIdent reports whether ident is an unquoted *.
+func isStarIdent(ident *clickhouseparser.Ident) bool {
+	return ident.Name == "*" && ident.QuoteType != clickhouseparser.BackTicks
+}
@@ -0,0 +1,154 @@
+package clickhouse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+var testMaskedColumns = models.MaskedColumns{
+	{Column: "email", Mode: models.MaskHash},
+	{Column: "ip", Mode: models.MaskRedact},
+}
+
+var testMaskSchema = []models.ColumnInfo{
+	{Name: "timestamp", Type: "DateTime64(3)"},
+	{Name: "email", Type: "String"},
+	{Name: "ip", Type: "String"},
+	{Name: "msg", Type: "String"},
+	{Name: "status", Type: "Int64", Virtual: true, Expression: "toInt64OrNull(extract(msg, 'status=(\\d+)'))"},
+}
+
+func TestQueryBuilderWithMaskedColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		schema  []models.ColumnInfo
+		want    []string
+		notWant []string
+		wantErr bool
+	}{
+		{
+			name:    "expands SELECT *",
+			query:   "SELECT * FROM logs.app WHERE msg LIKE '%it''s%' ORDER BY timestamp DESC",
+			schema:  testMaskSchema,
+			want:    []string{"SELECT `timestamp`, hex(SHA256(toString(`email`))) AS email, '[REDACTED]' AS ip, `msg` FROM", "'%it''s%'"},
+			notWant: []string{"*", "status"},
+		},
+		{
+			name:  "wraps a masked column and keeps its alias",
+			query: "SELECT timestamp, email AS who, ip FROM logs.app",
+			want:  []string{"hex(SHA256(toString(`email`))) AS who", "'[REDACTED]' AS ip"},
+		},
+		{
+			name:  "count(*) is fine",
+			query: "SELECT count(*) FROM logs.app WHERE msg != ''",
+			want:  []string{"count(*)"},
+		},
+		{
+			name:    "SELECT * without a schema",
+			query:   "SELECT * FROM logs.app",
+			wantErr: true,
+		},
+		{
+			name:    "masked column in WHERE",
+			query:   "SELECT msg FROM logs.app WHERE email = 'a@example.com'",
+			wantErr: true,
+		},
+		{
+			name:    "masked column in an expression",
+			query:   "SELECT lower(email) AS e FROM logs.app",
+			wantErr: true,
+		},
+		{
+			name:    "masked column in GROUP BY",
+			query:   "SELECT ip, count() FROM logs.app GROUP BY ip",
+			wantErr: true,
+		},
+		{
+			name:    "qualified masked column",
+			query:   "SELECT a.email FROM logs.app AS a",
+			wantErr: true,
+		},
+		{
+			name:    "COLUMNS()",
+			query:   "SELECT COLUMNS('e.*') FROM logs.app",
+			wantErr: true,
+		},
+		{
+			name:    "star inside a function",
+			query:   "SELECT toJSONString(tuple(*)) FROM logs.app",
+			wantErr: true,
+		},
+		{
+			name:    "EXCEPT modifier",
+			query:   "SELECT * EXCEPT (msg) FROM logs.app",
+			schema:  testMaskSchema,
+			wantErr: true,
+		},
+		{
+			name:    "subquery",
+			query:   "SELECT email FROM (SELECT email FROM logs.app)",
+			wantErr: true,
+		},
+		{
+			name:    "other table",
+			query:   "SELECT email FROM logs.other",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qb := NewExtendedQueryBuilder("logs.app", 0).WithMaskedColumns(testMaskedColumns, tt.schema)
+			got, err := qb.BuildRawQuery(tt.query, 100)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildRawQuery() = %q, error = %v, wantErr %v", got, err, tt.wantErr)
+			}
+			if err != nil && !IsValidationError(err) {
+				t.Errorf("error %v is not a ValidationError", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("BuildRawQuery() = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("BuildRawQuery() = %q, want it not to contain %q", got, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestMaskedColumnsWithDerivedColumns(t *testing.T) {
+	qb := NewExtendedQueryBuilder("logs.app", 0).
+		WithDerivedColumns([]models.DerivedColumn{{Name: "domain", Expression: "domain(email)"}}).
+		WithMaskedColumns(testMaskedColumns, testMaskSchema)
+	if _, err := qb.BuildRawQuery("SELECT msg FROM logs.app", 100); err == nil {
+		t.Fatal("BuildRawQuery() accepted a derived column reading a masked column")
+	}
+}
+
+func TestRemoveLimitClauseWithMaskedColumns(t *testing.T) {
+	qb := NewQueryBuilder("logs.app", 0).WithMaskedColumns(testMaskedColumns, nil)
+	if _, err := qb.RemoveLimitClause("SELECT * FROM logs.app WHERE msg != '' LIMIT 10"); err != nil {
+		t.Fatalf("RemoveLimitClause() error = %v", err)
+	}
+	if _, err := qb.RemoveLimitClause("SELECT * FROM logs.app WHERE ip = '10.0.0.1'"); err == nil {
+		t.Fatal("RemoveLimitClause() accepted a filter on a masked column")
+	}
+}
+
+func TestCheckMaskedCondition(t *testing.T) {
+	if err := CheckMaskedCondition(" AND (msg = 'it''s')", testMaskedColumns); err != nil {
+		t.Errorf("CheckMaskedCondition() on an unmasked column = %v", err)
+	}
+	if err := CheckMaskedCondition(" AND (`email` LIKE '%@example.com')", testMaskedColumns); err == nil {
+		t.Error("CheckMaskedCondition() accepted a condition on a masked column")
+	}
+	if err := CheckMaskedCondition(" AND (email = 'a')", nil); err != nil {
+		t.Errorf("CheckMaskedCondition() without masks = %v", err)
+	}
+}
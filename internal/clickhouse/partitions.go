@@ -0,0 +1,117 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+const (
+	partitionsTimeoutSeconds = 10
+	// dropPartitionTimeout bounds ALTER ... DROP PARTITION. Dropping only
+	// detaches the parts and schedules their removal, so it is fast.
+	dropPartitionTimeout = 60
+)
+
+// partitionIDRe matches system.parts partition_id values: the partition key
+// rendered as digits, or a hash of it, optionally joined by hyphens.
+var partitionIDRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// TableTarget is a MergeTree table to alter. For a Distributed source it is
+// the underlying local table, altered on every node of Cluster.
+type TableTarget struct {
+	Database string
+	Table    string
+	// Cluster adds ON CLUSTER; empty for a single-node table.
+	Cluster string
+}
+
+// alterTable returns the "ALTER TABLE db.table [ON CLUSTER c]" prefix.
+func (t TableTarget) alterTable() (string, error) {
+	if err := ValidateIdentifier(t.Database); err != nil {
+		return "", fmt.Errorf("invalid database: %w", err)
+	}
+	if err := ValidateIdentifier(t.Table); err != nil {
+		return "", fmt.Errorf("invalid table: %w", err)
+	}
+	stmt := fmt.Sprintf("ALTER TABLE %s.%s", quoteIdentifier(t.Database), quoteIdentifier(t.Table))
+	if t.Cluster != "" {
+		stmt += " ON CLUSTER " + quoteIdentifier(t.Cluster)
+	}
+	return stmt, nil
+}
+
+// ListPartitions returns the active partitions of database.table with their
+// sizes, newest first. Sizes come from the connected node's system.parts.
+func (c *Client) ListPartitions(ctx context.Context, database, table string) ([]models.TablePartition, error) {
+	queryCtx, cancel := statsQueryContext(ctx, partitionsTimeoutSeconds)
+	defer cancel()
+
+	rows, err := c.conn.Query(queryCtx, `
+		SELECT
+			partition_id,
+			any(partition),
+			sum(rows),
+			count(),
+			sum(bytes_on_disk),
+			sum(data_uncompressed_bytes),
+			min(min_time),
+			max(max_time)
+		FROM system.parts
+		WHERE active = 1 AND database = ? AND table = ?
+		GROUP BY partition_id
+		ORDER BY max(max_time) DESC, partition_id DESC`, database, table)
+	if err != nil {
+		return nil, fmt.Errorf("error listing partitions: %w", err)
+	}
+	defer rows.Close()
+
+	var partitions []models.TablePartition
+	for rows.Next() {
+		var (
+			p        models.TablePartition
+			min, max time.Time
+		)
+		if err := rows.Scan(&p.ID, &p.Partition, &p.Rows, &p.PartCount, &p.BytesOnDisk, &p.UncompressedBytes, &min, &max); err != nil {
+			return nil, fmt.Errorf("error scanning partition row: %w", err)
+		}
+		// min_time/max_time are only tracked for a DateTime partition key;
+		// otherwise they are the epoch.
+		if min.Unix() > 0 {
+			p.MinTime = &min
+		}
+		if max.Unix() > 0 {
+			p.MaxTime = &max
+		}
+		partitions = append(partitions, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating partition rows: %w", err)
+	}
+	return partitions, nil
+}
+
+// BuildDropPartitionStatement returns the ALTER that drops one partition by
+// its partition_id.
+func BuildDropPartitionStatement(target TableTarget, partitionID string) (string, error) {
+	stmt, err := target.alterTable()
+	if err != nil {
+		return "", err
+	}
+	if !partitionIDRe.MatchString(partitionID) {
+		return "", &ValidationError{Message: fmt.Sprintf("invalid partition id %q", partitionID)}
+	}
+	return fmt.Sprintf("%s DROP PARTITION ID '%s'", stmt, partitionID), nil
+}
+
+// DropPartition runs a statement built by BuildDropPartitionStatement.
+func (c *Client) DropPartition(ctx context.Context, stmt string) error {
+	timeout := dropPartitionTimeout
+	if err := c.Exec(ctx, stmt, &timeout); err != nil {
+		return fmt.Errorf("dropping partition: %w", err)
+	}
+	return nil
+}
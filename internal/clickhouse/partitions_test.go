@@ -0,0 +1,19 @@
+package clickhouse
+
+import "testing"
+
+func TestBuildDropPartitionStatement(t *testing.T) {
+	got, err := BuildDropPartitionStatement(TableTarget{Database: "logs", Table: "app_local", Cluster: "main"}, "20260301")
+	if err != nil {
+		t.Fatalf("BuildDropPartitionStatement: %v", err)
+	}
+	if want := "ALTER TABLE `logs`.`app_local` ON CLUSTER `main` DROP PARTITION ID '20260301'"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	for _, bad := range []string{"", "2026' OR 1", "a b", "x;y"} {
+		if _, err := BuildDropPartitionStatement(TableTarget{Database: "logs", Table: "app"}, bad); err == nil {
+			t.Errorf("accepted partition id %q", bad)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package clickhouse
+
+// Log pattern mining: mask the variable parts of each message (numbers, IDs,
+// addresses, timestamps) and group messages by the resulting template, so
+// noisy and newly appearing log lines stand out.
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// Pattern mining limits.
+const (
+	DefaultPatternLimit  = 50
+	MaxPatternLimit      = 200
+	patternExamples      = 3
+	patternMessagePrefix = 1000 // characters of each message that are templated
+)
+
+// patternMasks are applied in order to every message; earlier masks take the
+// more specific shapes before the generic number mask sees their digits. The
+// expressions are RE2, which ClickHouse's replaceRegexpAll also uses.
+var patternMasks = []struct {
+	expr        string
+	placeholder string
+}{
+	{`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`, "<ts>"},
+	{`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`, "<uuid>"},
+	{`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b`, "<ip>"},
+	{`\b(?:0x[0-9a-fA-F]+|[0-9a-fA-F]{16,})\b`, "<hex>"},
+	{`\b\d+(?:\.\d+)?`, "<num>"},
+}
+
+// PatternsParams holds parameters for grouping messages into patterns.
+type PatternsParams struct {
+	FieldName      string    // Required: String column holding the message
+	TimestampField string    // Required: timestamp column name for time range filter
+	StartTime      time.Time // Required: start of time range
+	EndTime        time.Time // Required: end of time range
+	Timezone       string    // Optional: timezone for time conversion (defaults to UTC)
+	Limit          int       // Optional: patterns to return (default 50, max 200)
+	// Baseline also counts each pattern over the equally long window before
+	// StartTime, so patterns missing from it are reported as new.
+	Baseline  bool
+	Timeout   *int   // Optional: query timeout in seconds
+	LogchefQL string // Optional: LogchefQL query string narrowing the rows
+}
+
+// GetLogPatterns groups the messages in a time range by template and returns
+// the most frequent templates with counts and example messages.
+func (c *Client) GetLogPatterns(ctx context.Context, database, table string, params PatternsParams) (*models.LogPatternsResult, error) {
+	if err := ValidateIdentifier(params.FieldName); err != nil {
+		return nil, fmt.Errorf("invalid field name: %w", err)
+	}
+	if err := ValidateIdentifier(params.TimestampField); err != nil {
+		return nil, fmt.Errorf("invalid timestamp field: %w", err)
+	}
+	timezone := params.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if err := ValidateTimezone(timezone); err != nil {
+		return nil, fmt.Errorf("invalid timezone: %w", err)
+	}
+	if params.Limit <= 0 {
+		params.Limit = DefaultPatternLimit
+	}
+	params.Limit = min(params.Limit, MaxPatternLimit)
+	timeout := params.Timeout
+	if timeout == nil {
+		defaultTimeout := 30
+		timeout = &defaultTimeout
+	}
+
+	query, args := buildPatternsQuery(database, table, params, timezone)
+	result, err := c.QueryWithArgs(ctx, query, timeout, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to group log patterns: %w", err)
+	}
+
+	patterns := &models.LogPatternsResult{Field: params.FieldName, Patterns: []models.LogPattern{}}
+	for _, row := range result.Logs {
+		patterns.Patterns = append(patterns.Patterns, logPatternFromRow(row, params.Baseline))
+	}
+	if len(result.Logs) > 0 {
+		patterns.TotalRows, _ = extractInt64FromRow(result.Logs[0], "total_rows")
+		patterns.TotalPatterns, _ = extractInt64FromRow(result.Logs[0], "total_patterns")
+	}
+	for i := range patterns.Patterns {
+		if patterns.TotalRows > 0 {
+			patterns.Patterns[i].Percent = float64(patterns.Patterns[i].Count) * 100 / float64(patterns.TotalRows)
+		}
+	}
+	return patterns, nil
+}
+
+// patternTemplateSQL nests one replaceRegexpAll per mask around expr.
+func patternTemplateSQL(expr string) string {
+	for _, m := range patternMasks {
+		expr = fmt.Sprintf("replaceRegexpAll(%s, '%s', '%s')", expr, escapeSQLLiteral(m.expr), m.placeholder)
+	}
+	return expr
+}
+
+// buildPatternsQuery renders the grouping query and its bound arguments. With
+// a baseline the scanned range starts one window earlier and rows before
+// StartTime only feed the baseline counts; the window functions total the
+// in-range rows and patterns before LIMIT applies.
+func buildPatternsQuery(database, table string, params PatternsParams, timezone string) (string, []any) {
+	scanStart := params.StartTime
+	if params.Baseline {
+		scanStart = params.StartTime.Add(-params.EndTime.Sub(params.StartTime))
+	}
+	const layout = "2006-01-02 15:04:05"
+	ts := quoteIdentifier(params.TimestampField)
+	field := quoteIdentifier(params.FieldName)
+	timeRange, rangeArgs := timeRangeSQL(params.TimestampField, scanStart, params.EndTime, timezone)
+	args := append([]any{params.StartTime.UTC().Format(layout), timezone}, rangeArgs...)
+
+	return fmt.Sprintf(`
+		SELECT pattern, cnt, baseline_cnt, examples, first_seen, last_seen,
+			sum(cnt) OVER () AS total_rows, count() OVER () AS total_patterns
+		FROM (
+			SELECT %s AS pattern,
+				countIf(__in_range) AS cnt,
+				countIf(NOT __in_range) AS baseline_cnt,
+				groupUniqArrayIf(%d)(__msg, __in_range) AS examples,
+				toUnixTimestamp64Milli(toDateTime64(minIf(__ts, __in_range), 3)) AS first_seen,
+				toUnixTimestamp64Milli(toDateTime64(maxIf(__ts, __in_range), 3)) AS last_seen
+			FROM (
+				SELECT %s AS __ts, substringUTF8(%s, 1, %d) AS __msg,
+					%s >= toDateTime(?, ?) AS __in_range
+				FROM %s
+				PREWHERE %s
+				WHERE %s != ''%s
+			)
+			GROUP BY pattern
+			HAVING cnt > 0
+		)
+		ORDER BY cnt DESC, pattern
+		LIMIT %d
+	`, patternTemplateSQL("__msg"), patternExamples,
+		ts, field, patternMessagePrefix,
+		ts, quoteTable(database, table), timeRange,
+		field, buildLogchefQLConditionsSQL(params.LogchefQL),
+		params.Limit), args
+}
+
+// logPatternFromRow converts one grouped row. Baseline counts are only
+// reported when a baseline was requested.
+func logPatternFromRow(row map[string]any, baseline bool) models.LogPattern {
+	p := models.LogPattern{Examples: []string{}}
+	p.Pattern, _ = row["pattern"].(string)
+	p.Count, _ = extractInt64FromRow(row, "cnt")
+	p.FirstSeen, _ = extractInt64FromRow(row, "first_seen")
+	p.LastSeen, _ = extractInt64FromRow(row, "last_seen")
+	switch examples := row["examples"].(type) {
+	case []string:
+		p.Examples = examples
+	case []any:
+		for _, e := range examples {
+			if s, ok := e.(string); ok {
+				p.Examples = append(p.Examples, s)
+			}
+		}
+	}
+	if baseline {
+		n, _ := extractInt64FromRow(row, "baseline_cnt")
+		p.BaselineCount = &n
+		p.New = n == 0
+	}
+	return p
+}
@@ -0,0 +1,106 @@
+package clickhouse
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// applyPatternMasks templates msg the way the ClickHouse query does; Go's
+// regexp is RE2 like replaceRegexpAll.
+func applyPatternMasks(msg string) string {
+	for _, m := range patternMasks {
+		msg = regexp.MustCompile(m.expr).ReplaceAllLiteralString(msg, m.placeholder)
+	}
+	return msg
+}
+
+func TestPatternMasks(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"GET /users/42 took 12.5ms":                                 "GET /users/<num> took <num>ms",
+		"connection from 10.0.0.12:5432 refused":                    "connection from <ip> refused",
+		"request 3f2b8c1e-9a4d-4e8f-b1c2-7d6e5f4a3b2c failed":       "request <uuid> failed",
+		"2026-01-02T03:04:05.123Z worker 7 started":                 "<ts> worker <num> started",
+		"commit deadbeefcafebabe0123 pushed":                        "commit <hex> pushed",
+		"user42 retried":                                            "user42 retried",
+		"retry 3 of 5 after 2026-01-02 03:04:05 for job 0xffff0000": "retry <num> of <num> after <ts> for job <hex>",
+	}
+	for msg, want := range cases {
+		if got := applyPatternMasks(msg); got != want {
+			t.Errorf("template(%q) = %q, want %q", msg, got, want)
+		}
+	}
+}
+
+func TestBuildPatternsQuery(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	params := PatternsParams{
+		FieldName:      "body",
+		TimestampField: "timestamp",
+		StartTime:      start,
+		EndTime:        start.Add(time.Hour),
+		Limit:          20,
+		LogchefQL:      `service="api"`,
+	}
+	query, args := buildPatternsQuery("logs", "app", params, "UTC")
+	for _, want := range []string{
+		`replaceRegexpAll(__msg, '\\d{4}-\\d{2}-\\d{2}`,
+		"substringUTF8(`body`, 1, 1000) AS __msg",
+		"FROM `logs`.`app`",
+		"`timestamp` BETWEEN toDateTime(?, ?) AND toDateTime(?, ?)",
+		"WHERE `body` != '' AND (",
+		"groupUniqArrayIf(3)(__msg, __in_range)",
+		"sum(cnt) OVER () AS total_rows",
+		"LIMIT 20",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query missing %q:\n%s", want, query)
+		}
+	}
+
+	wantArgs := []any{"2026-01-01 12:00:00", "UTC", "2026-01-01 12:00:00", "UTC", "2026-01-01 13:00:00", "UTC"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+
+	params.Baseline = true
+	query, args = buildPatternsQuery("logs", "app", params, "UTC")
+	if !strings.Contains(query, "`timestamp` >= toDateTime(?, ?) AS __in_range") {
+		t.Errorf("baseline query does not mark in-range rows:\n%s", query)
+	}
+	wantArgs = []any{"2026-01-01 12:00:00", "UTC", "2026-01-01 11:00:00", "UTC", "2026-01-01 13:00:00", "UTC"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("baseline args = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestLogPatternFromRow(t *testing.T) {
+	t.Parallel()
+
+	row := map[string]any{
+		"pattern":      "GET /users/<num>",
+		"cnt":          uint64(10),
+		"baseline_cnt": uint64(0),
+		"examples":     []string{"GET /users/1", "GET /users/2"},
+		"first_seen":   int64(1000),
+		"last_seen":    int64(2000),
+	}
+	p := logPatternFromRow(row, false)
+	if p.Count != 10 || len(p.Examples) != 2 || p.FirstSeen != 1000 || p.LastSeen != 2000 {
+		t.Errorf("logPatternFromRow() = %+v", p)
+	}
+	if p.BaselineCount != nil || p.New {
+		t.Errorf("without baseline: BaselineCount = %v, New = %v, want unset", p.BaselineCount, p.New)
+	}
+
+	p = logPatternFromRow(row, true)
+	if p.BaselineCount == nil || *p.BaselineCount != 0 || !p.New {
+		t.Errorf("with baseline: BaselineCount = %v, New = %v, want 0 and new", p.BaselineCount, p.New)
+	}
+}
@@ -0,0 +1,64 @@
+package clickhouse
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// peakMemoryEvent is the profile event carrying a query's peak memory usage.
+const peakMemoryEvent = "MemoryTrackerPeakUsage"
+
+// queryProgress collects the progress packets and profile events ClickHouse
+// sends while a query runs. Progress packets carry deltas, so rows and bytes
+// are summed. Only the native protocol sends them; over HTTP nothing arrives.
+type queryProgress struct {
+	mu         sync.Mutex
+	reported   bool
+	rowsRead   uint64
+	bytesRead  uint64
+	peakMemory int64
+}
+
+// context returns ctx with the progress and profile event callbacks attached,
+// keeping any query options (such as settings) already set on it.
+func (p *queryProgress) context(ctx context.Context) context.Context {
+	return clickhouse.Context(ctx,
+		clickhouse.WithProgress(p.onProgress),
+		clickhouse.WithProfileEvents(p.onProfileEvents),
+	)
+}
+
+func (p *queryProgress) onProgress(progress *clickhouse.Progress) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reported = true
+	p.rowsRead += progress.Rows
+	p.bytesRead += progress.Bytes
+}
+
+func (p *queryProgress) onProfileEvents(events []clickhouse.ProfileEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range events {
+		if e.Name == peakMemoryEvent {
+			p.peakMemory = max(p.peakMemory, e.Value)
+		}
+	}
+}
+
+// apply fills in what the query read. Without progress packets it keeps the
+// caller's fallback of the returned row count.
+func (p *queryProgress) apply(stats *models.QueryStats) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.reported {
+		stats.RowsRead = int(min(p.rowsRead, uint64(math.MaxInt)))
+		stats.BytesRead = int(min(p.bytesRead, uint64(math.MaxInt)))
+	}
+	stats.PeakMemoryBytes = p.peakMemory
+}
@@ -0,0 +1,45 @@
+package clickhouse
+
+import (
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestQueryProgressSumsDeltas(t *testing.T) {
+	t.Parallel()
+
+	var p queryProgress
+	p.onProgress(&clickhouse.Progress{Rows: 1000, Bytes: 64000})
+	p.onProgress(&clickhouse.Progress{Rows: 500, Bytes: 32000})
+	p.onProfileEvents([]clickhouse.ProfileEvent{
+		{Name: "SelectedRows", Value: 1500},
+		{Name: peakMemoryEvent, Value: 4 << 20},
+	})
+	p.onProfileEvents([]clickhouse.ProfileEvent{{Name: peakMemoryEvent, Value: 2 << 20}})
+
+	stats := models.QueryStats{RowsRead: 10, RowsReturned: 10}
+	p.apply(&stats)
+	if stats.RowsRead != 1500 || stats.BytesRead != 96000 {
+		t.Errorf("read = %d rows / %d bytes, want 1500 / 96000", stats.RowsRead, stats.BytesRead)
+	}
+	if stats.PeakMemoryBytes != 4<<20 {
+		t.Errorf("PeakMemoryBytes = %d, want %d", stats.PeakMemoryBytes, 4<<20)
+	}
+	if stats.RowsReturned != 10 {
+		t.Errorf("RowsReturned changed to %d", stats.RowsReturned)
+	}
+}
+
+func TestQueryProgressWithoutPacketsKeepsFallback(t *testing.T) {
+	t.Parallel()
+
+	var p queryProgress
+	stats := models.QueryStats{RowsRead: 10}
+	p.apply(&stats)
+	if stats.RowsRead != 10 || stats.BytesRead != 0 || stats.PeakMemoryBytes != 0 {
+		t.Errorf("stats = %+v, want the fallback row count only", stats)
+	}
+}
@@ -11,14 +11,19 @@ import (
 	"maps"
 	"net"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/mr-karan/logchef/internal/metrics"
+	"github.com/mr-karan/logchef/internal/timezone"
+	"github.com/mr-karan/logchef/internal/tracing"
 	"github.com/mr-karan/logchef/pkg/models"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // QueryOptions controls ClickHouse execution and LogChef-side result handling.
@@ -29,6 +34,19 @@ type QueryOptions struct {
 	MaxRows          int
 	MaxResponseBytes int
 	Warnings         []models.QueryWarning
+	// MaxRowsToRead and MaxBytesToRead cap what the query may read. They apply
+	// over the source's own settings: whichever cap is stricter wins.
+	MaxRowsToRead  uint64
+	MaxBytesToRead uint64
+	// SampleRatio and SampleMethod flag a sampled query in its stats; see
+	// QueryBuilder.WithSample.
+	SampleRatio  float64
+	SampleMethod string
+	// Location, when set, converts DateTime values in the rows into that
+	// zone and reports it in the stats.
+	Location *time.Location
+	// Args are bound to the query's `?` placeholders.
+	Args []any
 }
 
 // RowStreamWriter receives rows as they are read from ClickHouse.
@@ -51,174 +69,207 @@ func (c *Client) QueryWithTimeout(ctx context.Context, query string, timeoutSeco
 	return c.QueryWithOptions(ctx, query, QueryOptions{TimeoutSeconds: timeoutSeconds})
 }
 
-// QueryWithOptions executes a SELECT query and buffers a bounded result for
-// browser preview style responses.
-func (c *Client) QueryWithOptions(ctx context.Context, query string, opts QueryOptions) (*models.QueryResult, error) {
-	start := time.Now()          // Used for calculating total duration including hook overhead.
-	queryStartTime := time.Now() // Separate timer for actual DB execution
-	var queryDuration time.Duration
+// QueryWithArgs executes a SELECT query with its `?` placeholders bound to
+// args.
+func (c *Client) QueryWithArgs(ctx context.Context, query string, timeoutSeconds *int, args ...any) (*models.QueryResult, error) {
+	return c.QueryWithOptions(ctx, query, QueryOptions{TimeoutSeconds: timeoutSeconds, Args: args})
+}
 
-	// Start query metrics tracking
-	var queryHelper *metrics.QueryMetricsHelper
-	if c.metrics != nil {
-		queryType := metrics.DetermineQueryType(query)
-		queryHelper = c.metrics.StartQuery(queryType, nil) // User context not available in client
-	}
+// RowFunc adapts a per-row callback to RowStreamWriter, for callers that
+// don't need the column list or the final stats:
+//
+//	stats, err := client.QueryStream(ctx, sql, opts, clickhouse.RowFunc(func(row map[string]any) error {
+//		return enc.Encode(row)
+//	}))
+type RowFunc func(row map[string]any) error
 
-	// Ensure timeout is provided (should always be the case now)
-	if opts.TimeoutSeconds == nil {
-		defaultTimeout := DefaultQueryTimeout
-		opts.TimeoutSeconds = &defaultTimeout
-	}
+// Begin implements RowStreamWriter.
+func (f RowFunc) Begin([]models.ColumnInfo) error { return nil }
 
-	// Bound the Go context by the timeout too — max_execution_time only limits
-	// ClickHouse-side execution, not a stalled network read or driver hang.
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(*opts.TimeoutSeconds)*time.Second+queryTimeoutGrace)
-	defer cancel()
-
-	defer func() {
-		c.logger.Debug("query processing complete",
-			"duration_ms", time.Since(start).Milliseconds(),
-			"query", query,
-			"timeout_seconds", *opts.TimeoutSeconds,
-		)
-	}()
+// WriteRow implements RowStreamWriter.
+func (f RowFunc) WriteRow(row map[string]any) error { return f(row) }
 
-	// Delegate DDL statements (CREATE, ALTER, DROP, etc.) to execDDL.
-	if isDDLStatement(query) {
-		return c.execDDLWithTimeout(ctx, query, opts.TimeoutSeconds)
-	}
+// Finish implements RowStreamWriter.
+func (f RowFunc) Finish(models.QueryStats) error { return nil }
 
-	var rows driver.Rows
-	var resultData []map[string]any
-	var columnsInfo []models.ColumnInfo
-	var bytesReturned int
-	truncatedReason := ""
+// errStopRows ends a streamed query early from inside WriteRow without
+// failing it; the writer records why it stopped.
+var errStopRows = errors.New("stop reading rows")
 
-	// Execute the core query logic within the hook wrapper.
-	err := c.executeQueryWithHooks(ctx, query, func(hookCtx context.Context) error {
-		var queryErr error
-		queryStartTime = time.Now() // Reset timer before execution
+// bufferedRows collects a bounded result for QueryWithOptions.
+type bufferedRows struct {
+	columns []models.ColumnInfo
+	rows    []map[string]any
+}
 
-		hookCtx = c.contextWithQuerySettings(hookCtx, opts)
+func (b *bufferedRows) Begin(columns []models.ColumnInfo) error {
+	b.columns = columns
+	return nil
+}
 
-		rows, queryErr = c.conn.Query(hookCtx, query)
-		if queryErr != nil {
-			return queryErr
-		}
+func (b *bufferedRows) WriteRow(row map[string]any) error {
+	b.rows = append(b.rows, row)
+	return nil
+}
 
-		// Close rows when we're done processing them
-		defer func() {
-			if rows != nil {
-				rows.Close()
-			}
-		}()
+func (b *bufferedRows) Finish(models.QueryStats) error { return nil }
 
-		var scanDest []any
-		var scanPtrs []reflect.Value
-		// Assign (not :=) so the outer columnsInfo makes it into the result —
-		// a := here would shadow it and the response would carry no columns.
-		columnsInfo, scanDest, scanPtrs = prepareRowScan(rows)
+// byteBudgetRows passes rows on to a writer until their approximate JSON size
+// would exceed maxBytes, then stops the query and marks its stats truncated
+// with reason "byte_limit".
+type byteBudgetRows struct {
+	RowStreamWriter
+	maxBytes  int
+	bytes     int
+	truncated bool
+}
 
-		// Preallocate to the applied row bound (capped) to avoid repeated slice
-		// regrowth on large result sets, without over-committing on huge limits.
-		resultData = make([]map[string]any, 0, boundedRowCap(opts))
-		for rows.Next() {
-			if opts.MaxRows > 0 && len(resultData) >= opts.MaxRows {
-				truncatedReason = "row_limit"
-				break
-			}
+func (b *byteBudgetRows) WriteRow(row map[string]any) error {
+	// Approximate size instead of marshaling every row: the writer encodes
+	// the rows it keeps once anyway.
+	size := approxJSONSize(row)
+	if b.bytes+size > b.maxBytes {
+		b.truncated = true
+		return errStopRows
+	}
+	b.bytes += size
+	return b.RowStreamWriter.WriteRow(row)
+}
 
-			if err := rows.Scan(scanDest...); err != nil {
-				return fmt.Errorf("scanning row: %w", err)
-			}
+func (b *byteBudgetRows) Finish(stats models.QueryStats) error {
+	b.mark(&stats)
+	return b.RowStreamWriter.Finish(stats)
+}
 
-			rowMap := scanRowMap(scanPtrs, columnsInfo)
-			if opts.MaxResponseBytes > 0 {
-				// Approximate size for the soft byte budget instead of marshaling
-				// every row (the full result is JSON-encoded once for the response).
-				rowSize := approxJSONSize(rowMap)
-				if bytesReturned+rowSize > opts.MaxResponseBytes {
-					truncatedReason = "byte_limit"
-					break
-				}
-				bytesReturned += rowSize
-			}
-			resultData = append(resultData, rowMap)
-		}
-		queryDuration = time.Since(queryStartTime) // Capture DB execution duration
+func (b *byteBudgetRows) mark(stats *models.QueryStats) {
+	stats.BytesReturned = b.bytes
+	if b.truncated {
+		stats.Truncated = true
+		stats.TruncatedReason = "byte_limit"
+	}
+}
 
-		// Check for errors during row iteration.
-		return rows.Err()
-	})
+// ByteLimitWarning explains a result cut short by a response byte budget of
+// maxBytes, with what to do about it.
+func ByteLimitWarning(maxBytes int) models.QueryWarning {
+	return models.QueryWarning{
+		Code: "BYTE_LIMIT",
+		Message: fmt.Sprintf("Results were truncated at about %d MB. Select fewer or smaller columns (large maps and JSON are the usual cause), narrow the time range, or use Download for the full result.",
+			max(1, maxBytes/(1024*1024))),
+	}
+}
 
-	// Complete metrics tracking
-	if queryHelper != nil {
-		success := err == nil
-		rowsReturned := int64(-1)
-		if success && resultData != nil {
-			rowsReturned = int64(len(resultData))
+// QueryWithOptions executes a SELECT query and buffers a bounded result for
+// browser preview style responses. It is QueryStream into an in-memory
+// writer; DDL statements are run through execDDL instead.
+func (c *Client) QueryWithOptions(ctx context.Context, query string, opts QueryOptions) (*models.QueryResult, error) {
+	if isDDLStatement(query) {
+		if opts.TimeoutSeconds == nil {
+			defaultTimeout := DefaultQueryTimeout
+			opts.TimeoutSeconds = &defaultTimeout
 		}
-		errorType := metrics.DetermineErrorType(err)
-		timedOut := isTimeoutError(err)
-		queryHelper.Finish(success, rowsReturned, errorType, timedOut)
+		ctx, cancel := context.WithTimeout(ctx, time.Duration(*opts.TimeoutSeconds)*time.Second+queryTimeoutGrace)
+		defer cancel()
+		return c.execDDLWithTimeout(ctx, query, opts.TimeoutSeconds)
 	}
 
-	// Handle errors from either query execution or row processing.
+	// Preallocate to the applied row bound (capped) to avoid repeated slice
+	// regrowth on large result sets, without over-committing on huge limits.
+	buf := &bufferedRows{rows: make([]map[string]any, 0, boundedRowCap(opts))}
+	stats, err := c.streamRows(ctx, query, opts, buf)
 	if err != nil {
 		return nil, fmt.Errorf("executing query or processing results: %w", err)
 	}
-
-	// Construct the final result.
-	queryResult := &models.QueryResult{
-		Logs:     resultData,
-		Columns:  columnsInfo,
-		Warnings: opts.Warnings,
-		Stats: models.QueryStats{
-			RowsRead:        len(resultData), // Use length of returned data as approximation
-			BytesRead:       0,               // Cannot reliably get BytesRead currently
-			RowsReturned:    len(resultData),
-			BytesReturned:   bytesReturned,
-			LimitApplied:    opts.LimitApplied,
-			Truncated:       truncatedReason != "",
-			TruncatedReason: truncatedReason,
-			ExecutionTimeMs: float64(queryDuration.Milliseconds()),
-		},
+	warnings := opts.Warnings
+	if stats.TruncatedReason == "byte_limit" {
+		warnings = append(slices.Clone(warnings), ByteLimitWarning(opts.MaxResponseBytes))
 	}
 
-	return queryResult, nil
+	return &models.QueryResult{
+		Logs:     buf.rows,
+		Columns:  buf.columns,
+		Warnings: warnings,
+		Stats:    stats,
+	}, nil
 }
 
 // QueryStream executes a SELECT query and streams rows into writer without
-// retaining the full result set in memory.
+// retaining the full result set in memory. Pass a RowFunc to handle rows with
+// a plain callback.
 func (c *Client) QueryStream(ctx context.Context, query string, opts QueryOptions, writer RowStreamWriter) (models.QueryStats, error) {
+	if isDDLStatement(query) {
+		return models.QueryStats{}, fmt.Errorf("streaming DDL statements is not supported")
+	}
+	stats, err := c.streamRows(ctx, query, opts, writer)
+	if err != nil {
+		return stats, fmt.Errorf("streaming query results: %w", err)
+	}
+	return stats, nil
+}
+
+// streamRows runs query and hands each row to writer as it is scanned. It
+// owns everything the query paths share: the timeout, hooks and settings, the
+// MaxRows and MaxResponseBytes bounds, progress stats and query metrics.
+// writer.Finish receives the final stats; errStopRows from WriteRow ends the
+// query early without error.
+func (c *Client) streamRows(ctx context.Context, query string, opts QueryOptions, writer RowStreamWriter) (models.QueryStats, error) {
+	var budget *byteBudgetRows
+	if opts.MaxResponseBytes > 0 {
+		budget = &byteBudgetRows{RowStreamWriter: writer, maxBytes: opts.MaxResponseBytes}
+		writer = budget
+	}
 	start := time.Now()
+	defer func() {
+		c.logger.Debug("query processing complete", "duration_ms", time.Since(start).Milliseconds(), "query", query)
+	}()
+
 	if opts.TimeoutSeconds == nil {
 		defaultTimeout := DefaultQueryTimeout
 		opts.TimeoutSeconds = &defaultTimeout
 	}
 
-	// Bound the Go context by the timeout (backstop for network/driver stalls
-	// beyond ClickHouse's max_execution_time). Safe to cancel on return: rows
-	// are fully consumed within this call.
+	// Bound the Go context by the timeout too — max_execution_time only limits
+	// ClickHouse-side execution, not a stalled network read or driver hang.
+	// Safe to cancel on return: rows are fully consumed within this call.
 	ctx, cancel := context.WithTimeout(ctx, time.Duration(*opts.TimeoutSeconds)*time.Second+queryTimeoutGrace)
 	defer cancel()
 
-	if isDDLStatement(query) {
-		return models.QueryStats{}, fmt.Errorf("streaming DDL statements is not supported")
+	var queryHelper *metrics.QueryMetricsHelper
+	if c.metrics != nil {
+		queryHelper = c.metrics.StartQuery(metrics.DetermineQueryType(query), nil) // User context not available in client
 	}
 
-	var stats models.QueryStats
-	var rowsReturned int
-	err := c.executeQueryWithHooks(ctx, query, func(hookCtx context.Context) error {
-		hookCtx = c.contextWithQuerySettings(hookCtx, opts)
-
-		rows, err := c.conn.Query(hookCtx, query)
+	var (
+		stats        models.QueryStats
+		rowsReturned int
+		progress     queryProgress
+	)
+	err := c.executeQueryWithHooks(ctx, query, func(hookCtx context.Context) (err error) {
+		// Timed from here so waiting for a query slot isn't counted.
+		queryStart := time.Now()
+		execCtx, execSpan := tracing.Start(hookCtx, "clickhouse.execute", trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "clickhouse"),
+				attribute.String("db.operation.name", metrics.DetermineQueryType(query)),
+				attribute.Int("db.query.length", len(query)),
+			))
+		execCtx = progress.context(c.contextWithQuerySettings(execCtx, opts))
+
+		// Query returns once ClickHouse sends the first block, so the execute
+		// span covers planning and time to first row; the scan span the rest.
+		rows, err := c.conn.Query(execCtx, query, opts.Args...)
+		tracing.End(execSpan, err)
 		if err != nil {
 			return err
 		}
 		defer rows.Close()
 
+		_, scanSpan := tracing.Start(hookCtx, "clickhouse.scan")
+		defer func() {
+			scanSpan.SetAttributes(attribute.Int("db.response.returned_rows", rowsReturned))
+			tracing.End(scanSpan, err)
+		}()
+
 		columnsInfo, scanDest, scanPtrs := prepareRowScan(rows)
 		if err := writer.Begin(columnsInfo); err != nil {
 			return err
@@ -234,8 +285,16 @@ func (c *Client) QueryStream(ctx context.Context, query string, opts QueryOption
 			if err := rows.Scan(scanDest...); err != nil {
 				return fmt.Errorf("scanning row: %w", err)
 			}
-			rowMap := scanRowMap(scanPtrs, columnsInfo)
-			if err := writer.WriteRow(rowMap); err != nil {
+			row := scanRowMap(scanPtrs, columnsInfo)
+			if opts.Location != nil {
+				for name, v := range row {
+					row[name] = timezone.Convert(v, opts.Location)
+				}
+			}
+			if err := writer.WriteRow(row); err != nil {
+				if errors.Is(err, errStopRows) {
+					break
+				}
 				return err
 			}
 			rowsReturned++
@@ -244,23 +303,111 @@ func (c *Client) QueryStream(ctx context.Context, query string, opts QueryOption
 			return err
 		}
 
+		// RowsRead falls back to the returned rows when ClickHouse sends no
+		// progress (see queryProgress.apply).
 		stats.RowsRead = rowsReturned
 		stats.RowsReturned = rowsReturned
 		stats.LimitApplied = opts.LimitApplied
-		stats.ExecutionTimeMs = float64(time.Since(start).Milliseconds())
+		if opts.SampleMethod != "" {
+			stats.Sampled = true
+			stats.SampleRatio = opts.SampleRatio
+			stats.SampleMethod = opts.SampleMethod
+		}
+		if opts.Location != nil {
+			stats.Timezone = opts.Location.String()
+			stats.TimezoneOffset = timezone.Offset(opts.Location, queryStart)
+		}
+		stats.ExecutionTimeMs = float64(time.Since(queryStart).Milliseconds())
+		progress.apply(&stats)
 		return writer.Finish(stats)
 	})
-	if err != nil {
-		return stats, fmt.Errorf("streaming query results: %w", err)
-	}
 
-	stats.ExecutionTimeMs = float64(time.Since(start).Milliseconds())
-	return stats, nil
+	if queryHelper != nil {
+		rowsMetric := int64(-1)
+		if err == nil {
+			rowsMetric = int64(rowsReturned)
+		}
+		queryHelper.Finish(err == nil, rowsMetric, metrics.DetermineErrorType(err), isTimeoutError(err))
+	}
+	if budget != nil {
+		budget.mark(&stats)
+	}
+	return stats, err
 }
 
 func (c *Client) contextWithQuerySettings(ctx context.Context, opts QueryOptions) context.Context {
 	settings := buildQuerySettings(*opts.TimeoutSeconds, opts.Settings, c.querySettings)
-	return clickhouse.Context(ctx, clickhouse.WithSettings(settings))
+	applyReadCap(settings, "max_rows_to_read", opts.MaxRowsToRead)
+	applyReadCap(settings, "max_bytes_to_read", opts.MaxBytesToRead)
+	queryOpts := []clickhouse.QueryOption{clickhouse.WithSettings(settings)}
+	if id, ok := QueryIDFromContext(ctx); ok {
+		queryOpts = append(queryOpts, clickhouse.WithQueryID(id))
+	}
+	if sc := tracing.SpanContextFromContext(ctx); sc.IsValid() {
+		setTraceComment(settings, sc)
+		queryOpts = append(queryOpts, clickhouse.WithSpan(sc))
+	}
+	return clickhouse.Context(ctx, queryOpts...)
+}
+
+type queryIDKey struct{}
+
+// ContextWithQueryID makes row queries run under ctx use id as their
+// ClickHouse query_id, so the query can be found in system.processes and
+// stopped with KillQuery. ClickHouse rejects a second query with an ID that is
+// still running, so only use it for contexts that run one query at a time.
+func ContextWithQueryID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, queryIDKey{}, id)
+}
+
+// QueryIDFromContext returns the query ID set by ContextWithQueryID.
+func QueryIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(queryIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// KillQuery asks ClickHouse to stop the query running with queryID. It does
+// not wait for the query to stop; a query that already finished is not an
+// error.
+func (c *Client) KillQuery(ctx context.Context, queryID string) error {
+	if err := c.conn.Exec(ctx, "KILL QUERY WHERE query_id = ? ASYNC", queryID); err != nil {
+		return fmt.Errorf("killing query %s: %w", queryID, err)
+	}
+	return nil
+}
+
+// setTraceComment tags the query with the calling span so it can be found in
+// system.query_log by trace ID. The native protocol also carries the span
+// context itself (WithSpan), which ClickHouse records as the parent of its
+// own opentelemetry_span_log spans. A log_comment set in the source's
+// settings wins.
+func setTraceComment(settings clickhouse.Settings, sc trace.SpanContext) {
+	if _, ok := settings["log_comment"]; ok {
+		return
+	}
+	settings["log_comment"] = fmt.Sprintf(`{"trace_id":%q,"span_id":%q}`, sc.TraceID(), sc.SpanID())
+}
+
+// applyReadCap sets a read cap unless the settings already hold a stricter one.
+func applyReadCap(settings clickhouse.Settings, name string, limit uint64) {
+	if limit == 0 {
+		return
+	}
+	if current, ok := settings[name]; ok {
+		var existing int64
+		switch v := current.(type) {
+		case int:
+			existing = int64(v)
+		case int64:
+			existing = v
+		case uint64:
+			existing = int64(min(v, uint64(1<<63-1)))
+		}
+		if existing > 0 && uint64(existing) <= limit {
+			return
+		}
+	}
+	settings[name] = limit
 }
 
 // buildQuerySettings merges, in increasing precedence: the request timeout,
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	clickhouseparser "github.com/AfterShip/clickhouse-sql-parser/parser"
+
+	"github.com/mr-karan/logchef/pkg/models"
 )
 
 const (
@@ -31,6 +34,16 @@ type QueryBuilder struct {
 	mode         QueryMode
 	defaultLimit int
 	maxLimit     int
+	derived      []models.DerivedColumn
+	sampleRatio  float64
+	sampleClause bool
+	rowFilter    string
+	masked       models.MaskedColumns
+	maskSchema   []models.ColumnInfo
+	timeField    string
+	timeStart    *time.Time
+	timeEnd      *time.Time
+	shard        int
 }
 
 // QueryBuildResult describes the SQL produced by the query builder and the
@@ -44,6 +57,12 @@ type QueryBuildResult struct {
 	LimitCapped      bool
 	ExplicitLimit    bool
 	UnparseableLimit bool
+	// SampleMethod is SampleMethodClause or SampleMethodRandom when the
+	// query was sampled, and empty otherwise.
+	SampleMethod string
+	// TimeBoundAdded is true when the query had no condition on the
+	// timestamp column and the requested time range was ANDed in.
+	TimeBoundAdded bool
 }
 
 // NewQueryBuilder creates a new QueryBuilder for restricted mode.
@@ -69,6 +88,23 @@ func NewExtendedQueryBuilder(tableName string, maxLimit int) *QueryBuilder {
 	}
 }
 
+// WithDerivedColumns makes the builder append the given derived columns to the
+// query's select list. Callers validate them against the source schema first
+// with ValidateDerivedColumns; the builder only re-checks the expressions.
+func (qb *QueryBuilder) WithDerivedColumns(cols []models.DerivedColumn) *QueryBuilder {
+	qb.derived = cols
+	return qb
+}
+
+// WithSample makes the builder restrict the query to roughly ratio of its
+// rows. useClause says the source table has a sampling key, so a query on it
+// can use SAMPLE instead of a random filter; see applySample.
+func (qb *QueryBuilder) WithSample(ratio float64, useClause bool) *QueryBuilder {
+	qb.sampleRatio = ratio
+	qb.sampleClause = useClause
+	return qb
+}
+
 // BuildRawQuery parses, validates, and adds LIMIT to a SQL query.
 func (qb *QueryBuilder) BuildRawQuery(rawSQL string, limit int) (string, error) {
 	result, err := qb.BuildRawQueryWithLimitPolicy(rawSQL, limit, qb.defaultLimit, qb.maxLimit)
@@ -123,7 +159,37 @@ func (qb *QueryBuilder) BuildRawQueryWithLimitPolicy(rawSQL string, requestedLim
 		// No additional validation needed.
 	}
 
+	if err := appendDerivedColumns(selectQuery, qb.derived); err != nil {
+		return QueryBuildResult{}, err
+	}
+	if len(qb.masked) > 0 {
+		if err := qb.applyMasking(selectQuery); err != nil {
+			return QueryBuildResult{}, err
+		}
+	}
+	if qb.rowFilter != "" {
+		if err := qb.applyRowFilter(selectQuery); err != nil {
+			return QueryBuildResult{}, err
+		}
+	}
+
+	if qb.shard != 0 {
+		if err := qb.applyShard(selectQuery); err != nil {
+			return QueryBuildResult{}, err
+		}
+	}
+
 	result := QueryBuildResult{RequestedLimit: requestedLimit}
+	if qb.timeField != "" {
+		if result.TimeBoundAdded, err = qb.applyTimeBound(selectQuery); err != nil {
+			return QueryBuildResult{}, err
+		}
+	}
+	if qb.sampleRatio != 0 {
+		if result.SampleMethod, err = qb.applySample(selectQuery, qb.sampleRatio, qb.sampleClause); err != nil {
+			return QueryBuildResult{}, err
+		}
+	}
 	qb.ensureLimitWithPolicy(selectQuery, requestedLimit, defaultLimit, maxLimit, &result)
 	if result.UnparseableLimit {
 		return QueryBuildResult{}, fmt.Errorf("LIMIT must be a numeric literal")
@@ -310,6 +376,18 @@ func (qb *QueryBuilder) RemoveLimitClause(rawSQL string) (string, error) {
 	}
 
 	selectQuery.Limit = nil
+	// Callers only aggregate the rows, so masked columns need no rewrite,
+	// but they must not be filtered or grouped on.
+	if len(qb.masked) > 0 {
+		if err := qb.checkMasking(selectQuery); err != nil {
+			return "", err
+		}
+	}
+	if qb.rowFilter != "" {
+		if err := qb.applyRowFilter(selectQuery); err != nil {
+			return "", err
+		}
+	}
 
 	result := formatSQL(stmt)
 	result = strings.ReplaceAll(result, placeholder, "''")
@@ -1,9 +1,11 @@
 package clickhouse
 
 import (
+	"context"
 	"testing"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TestBuildQuerySettingsPrecedence verifies the settings merge used for every
@@ -56,6 +58,24 @@ func TestBuildQuerySettingsNilMaps(t *testing.T) {
 	}
 }
 
+func TestApplyReadCapKeepsStricter(t *testing.T) {
+	t.Parallel()
+
+	settings := clickhouse.Settings{"max_rows_to_read": int64(100), "max_bytes_to_read": int64(1 << 40)}
+	applyReadCap(settings, "max_rows_to_read", 500)
+	applyReadCap(settings, "max_bytes_to_read", 1<<20)
+	applyReadCap(settings, "max_result_rows", 0)
+	if settings["max_rows_to_read"] != int64(100) {
+		t.Errorf("max_rows_to_read = %v, want the stricter source cap", settings["max_rows_to_read"])
+	}
+	if settings["max_bytes_to_read"] != uint64(1<<20) {
+		t.Errorf("max_bytes_to_read = %v, want the stricter cost cap", settings["max_bytes_to_read"])
+	}
+	if _, ok := settings["max_result_rows"]; ok {
+		t.Error("a zero cap must not be set")
+	}
+}
+
 // TestClientQuerySettingsAttached verifies a client built with per-source
 // QuerySettings attaches them to the query settings for every query.
 func TestClientQuerySettingsAttached(t *testing.T) {
@@ -70,3 +90,42 @@ func TestClientQuerySettingsAttached(t *testing.T) {
 		t.Fatalf("client cap did not override per-query limit: %#v", got)
 	}
 }
+
+// TestSetTraceComment verifies queries carry the calling span's IDs in
+// log_comment unless the source already sets one.
+func TestSetTraceComment(t *testing.T) {
+	t.Parallel()
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	})
+
+	settings := clickhouse.Settings{}
+	setTraceComment(settings, sc)
+	want := `{"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7"}`
+	if settings["log_comment"] != want {
+		t.Fatalf("log_comment = %v, want %s", settings["log_comment"], want)
+	}
+
+	settings = clickhouse.Settings{"log_comment": "nightly-report"}
+	setTraceComment(settings, sc)
+	if settings["log_comment"] != "nightly-report" {
+		t.Fatalf("log_comment = %v, want the source's value kept", settings["log_comment"])
+	}
+}
+
+func TestContextWithQueryID(t *testing.T) {
+	t.Parallel()
+
+	if _, ok := QueryIDFromContext(context.Background()); ok {
+		t.Fatal("plain context has a query ID")
+	}
+	if _, ok := QueryIDFromContext(ContextWithQueryID(context.Background(), "")); ok {
+		t.Fatal("empty query ID was reported")
+	}
+	id, ok := QueryIDFromContext(ContextWithQueryID(context.Background(), "0b7c9d1e"))
+	if !ok || id != "0b7c9d1e" {
+		t.Fatalf("QueryIDFromContext = %q, %v", id, ok)
+	}
+}
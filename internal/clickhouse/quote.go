@@ -0,0 +1,37 @@
+package clickhouse
+
+// Quoting for the SQL LogChef builds itself. Values go in as bound arguments
+// (QueryOptions.Args, `?` placeholders) wherever the query is entirely ours.
+// Queries that wrap user SQL can't take bound arguments, since `?` is also
+// ClickHouse's ternary operator, so their values go through quoteLiteral
+// instead. Identifiers are always quoted with quoteIdentifier.
+
+import "strings"
+
+// quoteIdentifier backtick-quotes name, escaping any backticks in it. A name
+// that is already a single well-formed quoted identifier is returned as is.
+func quoteIdentifier(name string) string {
+	trimmed := strings.TrimSpace(name)
+	if len(trimmed) >= 2 && strings.HasPrefix(trimmed, "`") && strings.HasSuffix(trimmed, "`") &&
+		!strings.Contains(strings.ReplaceAll(trimmed[1:len(trimmed)-1], "``", ""), "`") {
+		return trimmed
+	}
+	escaped := strings.ReplaceAll(trimmed, "`", "``")
+	return "`" + escaped + "`"
+}
+
+// quoteTable returns the quoted database.table reference.
+func quoteTable(database, table string) string {
+	return quoteIdentifier(database) + "." + quoteIdentifier(table)
+}
+
+// escapeSQLLiteral escapes s for use inside a single-quoted ClickHouse string.
+func escapeSQLLiteral(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, `'`, `\'`)
+}
+
+// quoteLiteral returns s as a single-quoted ClickHouse string literal.
+func quoteLiteral(s string) string {
+	return "'" + escapeSQLLiteral(s) + "'"
+}
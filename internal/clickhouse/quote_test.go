@@ -0,0 +1,53 @@
+package clickhouse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"timestamp":           "`timestamp`",
+		"`timestamp`":         "`timestamp`",
+		"a``b":                "`a````b`",
+		"`a``b`":              "`a``b`",
+		"`a` ; DROP TABLE t`": "```a`` ; DROP TABLE t```",
+		"x`; DROP TABLE t":    "`x``; DROP TABLE t`",
+	}
+	for name, want := range tests {
+		if got := quoteIdentifier(name); got != want {
+			t.Errorf("quoteIdentifier(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if got := quoteTable("logs", "app"); got != "`logs`.`app`" {
+		t.Errorf("quoteTable() = %q", got)
+	}
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"UTC":           `'UTC'`,
+		"x' OR 1=1 --":  `'x\' OR 1=1 --'`,
+		`a\' OR 1=1 --`: `'a\\\' OR 1=1 --'`,
+	}
+	for s, want := range tests {
+		if got := quoteLiteral(s); got != want {
+			t.Errorf("quoteLiteral(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestBuildLogchefQLConditionsSQLKeepsEscapedQuestionMarks(t *testing.T) {
+	t.Parallel()
+
+	// The driver turns "\?" into "?" when binding, so the fragment must
+	// carry "\\?" for the backslash to reach ClickHouse.
+	got := buildLogchefQLConditionsSQL(`msg="a\\?"`)
+	if !strings.Contains(got, `\\\?`) {
+		t.Errorf("buildLogchefQLConditionsSQL() = %q, want the backslash before ? doubled", got)
+	}
+}
@@ -0,0 +1,153 @@
+package clickhouse
+
+// Range comparison: per-field value counts in two time ranges, for "what
+// changed" investigations.
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// RangeComparisonParams describes the fields and the two ranges to compare.
+// The ranges must not overlap.
+type RangeComparisonParams struct {
+	Fields           []string // Required: columns to diff
+	TimestampField   string
+	BaselineStart    time.Time
+	BaselineEnd      time.Time
+	TargetStart      time.Time
+	TargetEnd        time.Time
+	Limit            int     // Optional: values fetched per field (default 20, max 100)
+	ThresholdPercent float64 // Optional: see models.DiffValues (default 100)
+	Timeout          *int    // Optional: query timeout in seconds, per field
+	LogchefQL        string  // Optional: LogchefQL query string narrowing the rows
+}
+
+// CompareRanges counts each field's values in both ranges and reports the
+// values whose frequency changed significantly. Fields are queried in
+// parallel; the first failure fails the comparison.
+func (c *Client) CompareRanges(ctx context.Context, database, table string, params RangeComparisonParams) (*models.RangeComparison, error) {
+	if err := ValidateIdentifier(params.TimestampField); err != nil {
+		return nil, fmt.Errorf("invalid timestamp field: %w", err)
+	}
+	for _, f := range params.Fields {
+		if err := ValidateIdentifier(f); err != nil {
+			return nil, fmt.Errorf("invalid field name: %w", err)
+		}
+	}
+	if params.BaselineStart.Before(params.TargetEnd) && params.TargetStart.Before(params.BaselineEnd) {
+		return nil, fmt.Errorf("the baseline and target ranges must not overlap")
+	}
+	if params.Limit <= 0 {
+		params.Limit = models.DefaultRangeComparisonLimit
+	}
+	params.Limit = min(params.Limit, models.MaxRangeComparisonLimit)
+	if params.ThresholdPercent <= 0 {
+		params.ThresholdPercent = models.DefaultRangeComparisonThreshold
+	}
+	timeout := params.Timeout
+	if timeout == nil {
+		defaultTimeout := 30
+		timeout = &defaultTimeout
+	}
+
+	comparison := &models.RangeComparison{
+		ThresholdPercent: params.ThresholdPercent,
+		Baseline:         models.CompareWindow{Start: params.BaselineStart, End: params.BaselineEnd},
+		Target:           models.CompareWindow{Start: params.TargetStart, End: params.TargetEnd},
+		Fields:           make([]models.FieldDiff, len(params.Fields)),
+	}
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	sem := make(chan struct{}, fieldValuesConcurrency)
+	for i, field := range params.Fields {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Go(func() {
+			defer func() { <-sem }()
+
+			query, args := buildRangeComparisonQuery(database, table, field, params)
+			result, err := c.QueryWithArgs(ctx, query, timeout, args...)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to compare values of %s: %w", field, err)
+				}
+				return
+			}
+			values := make([]models.ValueChange, 0, len(result.Logs))
+			for _, row := range result.Logs {
+				value, _ := extractStringFromRow(row, "value")
+				baselineCount, _ := extractInt64FromRow(row, "baseline_cnt")
+				targetCount, _ := extractInt64FromRow(row, "target_cnt")
+				values = append(values, models.ValueChange{Value: value, BaselineCount: baselineCount, TargetCount: targetCount})
+				// Every row carries the range totals.
+				comparison.Baseline.Rows, _ = extractInt64FromRow(row, "baseline_total")
+				comparison.Target.Rows, _ = extractInt64FromRow(row, "target_total")
+			}
+			comparison.Fields[i] = models.FieldDiff{Field: field, Changes: values}
+		})
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range comparison.Fields {
+		f := &comparison.Fields[i]
+		f.Changes = models.DiffValues(f.Changes, comparison.Baseline.Rows, comparison.Target.Rows, params.ThresholdPercent)
+	}
+	return comparison, nil
+}
+
+// buildRangeComparisonQuery counts a field's values in both ranges in one
+// scan and keeps the ones whose share of rows moved the most. NULLs are
+// counted as the empty string.
+func buildRangeComparisonQuery(database, table, field string, params RangeComparisonParams) (string, []any) {
+	target, targetArgs := timeRangeSQL(params.TimestampField, params.TargetStart, params.TargetEnd, "UTC")
+	baseline, baselineArgs := timeRangeSQL(params.TimestampField, params.BaselineStart, params.BaselineEnd, "UTC")
+	args := append(append(append([]any{}, targetArgs...), baselineArgs...), targetArgs...)
+
+	return fmt.Sprintf(`
+		SELECT value, baseline_cnt, target_cnt, baseline_total, target_total
+		FROM (
+			SELECT
+				ifNull(toString(%s), '') AS value,
+				countIf(NOT __target) AS baseline_cnt,
+				countIf(__target) AS target_cnt,
+				sum(baseline_cnt) OVER () AS baseline_total,
+				sum(target_cnt) OVER () AS target_total
+			FROM (
+				SELECT %s, %s AS __target
+				FROM %s
+				PREWHERE (%s) OR (%s)
+				WHERE 1%s
+			)
+			GROUP BY value
+		)
+		ORDER BY abs(target_cnt / greatest(target_total, 1) - baseline_cnt / greatest(baseline_total, 1)) DESC, value
+		LIMIT %d
+	`, quoteIdentifier(field),
+		quoteIdentifier(field), target,
+		quoteTable(database, table),
+		baseline, target,
+		buildLogchefQLConditionsSQL(params.LogchefQL),
+		params.Limit), args
+}
@@ -0,0 +1,45 @@
+package clickhouse
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildRangeComparisonQuery(t *testing.T) {
+	t.Parallel()
+
+	deploy := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	params := RangeComparisonParams{
+		TimestampField: "timestamp",
+		BaselineStart:  deploy.Add(-time.Hour),
+		BaselineEnd:    deploy,
+		TargetStart:    deploy.Add(time.Minute),
+		TargetEnd:      deploy.Add(time.Hour),
+		Limit:          20,
+		LogchefQL:      `service_name="api"`,
+	}
+	query, args := buildRangeComparisonQuery("logs", "app", "status", params)
+	for _, want := range []string{
+		"ifNull(toString(`status`), '') AS value",
+		"SELECT `status`, `timestamp` BETWEEN toDateTime(?, ?) AND toDateTime(?, ?) AS __target",
+		"FROM `logs`.`app`",
+		"PREWHERE (`timestamp` BETWEEN toDateTime(?, ?) AND toDateTime(?, ?)) OR (",
+		"WHERE 1 AND (",
+		"sum(baseline_cnt) OVER () AS baseline_total",
+		"LIMIT 20",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query missing %q:\n%s", want, query)
+		}
+	}
+	wantArgs := []any{
+		"2026-03-01 12:01:00", "UTC", "2026-03-01 13:00:00", "UTC",
+		"2026-03-01 11:00:00", "UTC", "2026-03-01 12:00:00", "UTC",
+		"2026-03-01 12:01:00", "UTC", "2026-03-01 13:00:00", "UTC",
+	}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+}
@@ -0,0 +1,75 @@
+package clickhouse
+
+import (
+	"strings"
+
+	clickhouseparser "github.com/AfterShip/clickhouse-sql-parser/parser"
+)
+
+// ValidateReadOnly checks that sql is a single read-only statement: a SELECT,
+// optionally led by a WITH clause. It guards the paths that hand user-written
+// SQL to the client as is (native alert queries), where anything else would
+// reach the DDL branch of Query.
+//
+// The parser doesn't cover all of ClickHouse's syntax, so a query it rejects
+// is checked lexically instead: it must be one statement whose first keyword
+// is SELECT or WITH.
+func ValidateReadOnly(sql string) error {
+	const placeholder = "___ESCAPED_QUOTE___"
+	stmts, err := clickhouseparser.NewParser(strings.ReplaceAll(sql, "''", placeholder)).ParseStmts()
+	if err == nil {
+		if len(stmts) != 1 {
+			return &ValidationError{Message: "query must contain exactly one statement"}
+		}
+		if _, ok := stmts[0].(*clickhouseparser.SelectQuery); !ok {
+			return &ValidationError{Message: "only SELECT queries are allowed"}
+		}
+		return nil
+	}
+
+	split := SplitStatements(sql)
+	if len(split) != 1 {
+		return &ValidationError{Message: "query must contain exactly one statement"}
+	}
+	switch strings.ToUpper(firstKeyword(split[0].Text)) {
+	case "SELECT", "WITH":
+		return nil
+	default:
+		return &ValidationError{Message: "only SELECT queries are allowed"}
+	}
+}
+
+// firstKeyword returns the first word of stmt, skipping comments and opening
+// parentheses.
+func firstKeyword(stmt string) string {
+	i := 0
+	for i < len(stmt) {
+		switch {
+		case isSpace(stmt[i]) || stmt[i] == '(':
+			i++
+		case strings.HasPrefix(stmt[i:], "--"):
+			end := strings.IndexByte(stmt[i:], '\n')
+			if end < 0 {
+				return ""
+			}
+			i += end + 1
+		case strings.HasPrefix(stmt[i:], "/*"):
+			end := strings.Index(stmt[i+2:], "*/")
+			if end < 0 {
+				return ""
+			}
+			i += 2 + end + 2
+		default:
+			end := i
+			for end < len(stmt) && isWordByte(stmt[end]) {
+				end++
+			}
+			return stmt[i:end]
+		}
+	}
+	return ""
+}
+
+func isWordByte(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
@@ -0,0 +1,38 @@
+package clickhouse
+
+import "testing"
+
+func TestValidateReadOnly(t *testing.T) {
+	allowed := []string{
+		"SELECT count() FROM logs WHERE level = 'error'",
+		"WITH 5 AS n SELECT n",
+		"SELECT 1;",
+		"SELECT 'it''s'",
+		// Unparseable, but lexically a single SELECT.
+		"SELECT 1 FROM t QUALIFY x > 1",
+		"/* note */ (SELECT 1 FROM t QUALIFY x > 1)",
+	}
+	for _, sql := range allowed {
+		if err := ValidateReadOnly(sql); err != nil {
+			t.Errorf("ValidateReadOnly(%q) = %v, want nil", sql, err)
+		}
+	}
+
+	rejected := []string{
+		"INSERT INTO t SELECT 1",
+		"ALTER TABLE t DELETE WHERE 1",
+		"DROP TABLE logs",
+		"SELECT 1; DROP TABLE t",
+		"-- SELECT\nTRUNCATE TABLE logs",
+		"SELECT 1 FROM t QUALIFY x > 1; DROP TABLE t",
+		"",
+	}
+	for _, sql := range rejected {
+		err := ValidateReadOnly(sql)
+		if err == nil {
+			t.Errorf("ValidateReadOnly(%q) = nil, want an error", sql)
+		} else if !IsValidationError(err) {
+			t.Errorf("ValidateReadOnly(%q) = %v, want a ValidationError", sql, err)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package clickhouse
+
+// Replica failover: a source's host may list several replicas of the same
+// data, comma separated ("ch-1:9000,ch-2:9000"). Connections open to the
+// first replica that answers, preferring ones that answered last time.
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+
+	"github.com/mr-karan/logchef/internal/metrics"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// SplitHosts returns the addresses listed in a connection's host field.
+func SplitHosts(host string) []string {
+	var hosts []string
+	for h := range strings.SplitSeq(host, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// replicaAddrs returns the addresses in host, each given port unless it
+// names one.
+func replicaAddrs(host, port string) []string {
+	hosts := SplitHosts(host)
+	for i, h := range hosts {
+		hosts[i] = withPort(h, port)
+	}
+	return hosts
+}
+
+// replicaHealth remembers which of a client's replicas failed their last
+// dial or probe, so new connections try the others first.
+type replicaHealth struct {
+	mu        sync.Mutex
+	unhealthy map[string]bool
+	logger    *slog.Logger
+	metrics   *metrics.ClickHouseMetrics
+}
+
+func newReplicaHealth(logger *slog.Logger, m *metrics.ClickHouseMetrics) *replicaHealth {
+	return &replicaHealth{unhealthy: make(map[string]bool), logger: logger, metrics: m}
+}
+
+// dialStrategy is the driver's DialStrategy. It dials addrs in order, the
+// replicas that last failed after the rest, and fails over to the next
+// replica when a dial fails. A dial cut short by ctx doesn't count against
+// its replica.
+func (r *replicaHealth) dialStrategy(ctx context.Context, _ int, opt *clickhouse.Options, dial clickhouse.Dial) (clickhouse.DialResult, error) {
+	err := clickhouse.ErrAcquireConnNoAddress
+	for _, addr := range r.order(opt.Addr) {
+		var result clickhouse.DialResult
+		if result, err = dial(ctx, addr, opt); err == nil {
+			r.record(addr, nil, len(opt.Addr) > 1)
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		r.record(addr, err, len(opt.Addr) > 1)
+		if len(opt.Addr) > 1 {
+			r.logger.Warn("clickhouse replica unreachable, failing over", "replica", addr, "error", err)
+			if r.metrics != nil {
+				r.metrics.RecordReplicaFailover(addr)
+			}
+		}
+	}
+	return clickhouse.DialResult{}, err
+}
+
+// order returns addrs with the replicas that last failed moved to the end,
+// keeping the configured order otherwise.
+func (r *replicaHealth) order(addrs []string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ordered := slices.Clone(addrs)
+	slices.SortStableFunc(ordered, func(a, b string) int {
+		switch {
+		case r.unhealthy[a] == r.unhealthy[b]:
+			return 0
+		case r.unhealthy[a]:
+			return 1
+		default:
+			return -1
+		}
+	})
+	return ordered
+}
+
+// record notes whether addr answered, updating its status metric when the
+// client has several replicas.
+func (r *replicaHealth) record(addr string, err error, several bool) {
+	r.mu.Lock()
+	wasUnhealthy := r.unhealthy[addr]
+	if err != nil {
+		r.unhealthy[addr] = true
+	} else {
+		delete(r.unhealthy, addr)
+	}
+	r.mu.Unlock()
+
+	if !several {
+		return
+	}
+	if wasUnhealthy && err == nil {
+		r.logger.Info("clickhouse replica recovered", "replica", addr)
+	}
+	if r.metrics != nil {
+		r.metrics.UpdateReplicaStatus(addr, err == nil)
+	}
+}
+
+// CheckReplicas pings each of the client's replicas on a connection of its
+// own and records which answered, so connections fail over to healthy ones
+// and return to a recovered one. A client with one address returns nil.
+func (c *Client) CheckReplicas(ctx context.Context) []models.ReplicaHealth {
+	if c.opts == nil || c.replicas == nil {
+		return nil
+	}
+	c.mu.Lock()
+	opts := *c.opts
+	c.mu.Unlock()
+	if len(opts.Addr) < 2 {
+		return nil
+	}
+
+	results := make([]models.ReplicaHealth, len(opts.Addr))
+	var wg sync.WaitGroup
+	for i, addr := range opts.Addr {
+		wg.Go(func() {
+			err := pingReplica(ctx, opts, addr)
+			c.replicas.record(addr, err, true)
+			results[i] = models.ReplicaHealth{Host: addr, Status: models.HealthStatusHealthy}
+			if err != nil {
+				results[i].Status = models.HealthStatusUnhealthy
+				results[i].Error = err.Error()
+			}
+		})
+	}
+	wg.Wait()
+	return results
+}
+
+// pingReplica opens a one-off connection to addr with the client's options
+// and pings it.
+func pingReplica(ctx context.Context, opts clickhouse.Options, addr string) error {
+	opts.Addr = []string{addr}
+	opts.DialStrategy = nil
+	opts.MaxOpenConns, opts.MaxIdleConns = 1, 1
+	conn, err := clickhouse.Open(&opts)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	pingCtx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+	return conn.Ping(pingCtx)
+}
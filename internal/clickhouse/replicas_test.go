@@ -0,0 +1,63 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+func TestReplicaAddrs(t *testing.T) {
+	got := replicaAddrs(" ch-1, ch-2:9440 ,,ch-3", "9000")
+	want := []string{"ch-1:9000", "ch-2:9440", "ch-3:9000"}
+	if !slices.Equal(got, want) {
+		t.Errorf("replicaAddrs() = %v, want %v", got, want)
+	}
+}
+
+func TestReplicaHealthDialStrategy(t *testing.T) {
+	r := newReplicaHealth(slog.New(slog.DiscardHandler), nil)
+	opt := &clickhouse.Options{Addr: []string{"ch-1:9000", "ch-2:9000", "ch-3:9000"}}
+	down := map[string]bool{"ch-1:9000": true}
+	var dialed []string
+	dial := func(_ context.Context, addr string, _ *clickhouse.Options) (clickhouse.DialResult, error) {
+		dialed = append(dialed, addr)
+		if down[addr] {
+			return clickhouse.DialResult{}, errors.New("connection refused")
+		}
+		return clickhouse.DialResult{}, nil
+	}
+
+	if _, err := r.dialStrategy(context.Background(), 1, opt, dial); err != nil {
+		t.Fatalf("dialStrategy() error = %v", err)
+	}
+	if want := []string{"ch-1:9000", "ch-2:9000"}; !slices.Equal(dialed, want) {
+		t.Errorf("first dial tried %v, want %v", dialed, want)
+	}
+
+	// The failed replica is now tried last.
+	dialed = nil
+	if _, err := r.dialStrategy(context.Background(), 2, opt, dial); err != nil {
+		t.Fatalf("dialStrategy() error = %v", err)
+	}
+	if want := []string{"ch-2:9000"}; !slices.Equal(dialed, want) {
+		t.Errorf("second dial tried %v, want %v", dialed, want)
+	}
+	if got := r.order(opt.Addr); !slices.Equal(got, []string{"ch-2:9000", "ch-3:9000", "ch-1:9000"}) {
+		t.Errorf("order() = %v", got)
+	}
+
+	// Once it answers again it is back in its configured place.
+	r.record("ch-1:9000", nil, true)
+	if got := r.order(opt.Addr); !slices.Equal(got, opt.Addr) {
+		t.Errorf("order() after recovery = %v, want %v", got, opt.Addr)
+	}
+
+	down = map[string]bool{"ch-1:9000": true, "ch-2:9000": true, "ch-3:9000": true}
+	if _, err := r.dialStrategy(context.Background(), 3, opt, dial); err == nil {
+		t.Error("dialStrategy() with every replica down succeeded")
+	}
+}
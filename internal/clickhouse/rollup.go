@@ -0,0 +1,330 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	clickhouseparser "github.com/AfterShip/clickhouse-sql-parser/parser"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// rollupTimeout bounds rollup DDL and INSERT ... SELECT statements. A run
+// covers at most a few days of hours, so this leaves plenty of headroom.
+const rollupTimeout = 300
+
+// RollupSpec describes how a source table is rolled up: hourly counts grouped
+// by the severity field and the dimension column (either may be empty).
+type RollupSpec struct {
+	// SourceTable and RollupTable are fully qualified (database.table).
+	SourceTable    string
+	RollupTable    string
+	TimestampField string
+	SeverityField  string
+	DimensionField string
+}
+
+// RollupTableName returns the table holding a source's hourly rollup. It lives
+// in the source's database so it shares the source's access grants.
+func RollupTableName(database string, sourceID models.SourceID) string {
+	return fmt.Sprintf("%s.logchef_rollup_%d", database, sourceID)
+}
+
+// TrendQueryParams selects the range and bucketing of a trend query.
+type TrendQueryParams struct {
+	Start       time.Time
+	End         time.Time
+	WindowHours int
+	Timezone    string
+	// GroupBy is "", "severity" or "dimension".
+	GroupBy        string
+	TimeoutSeconds *int
+}
+
+// TrendRow is one (bucket, group) count of a trend query.
+type TrendRow struct {
+	Bucket     time.Time
+	GroupValue string
+	LogCount   int64
+}
+
+func (s RollupSpec) validate() error {
+	for _, field := range []string{s.TimestampField, s.SeverityField, s.DimensionField} {
+		if field == "" {
+			continue
+		}
+		if err := ValidateIdentifier(field); err != nil {
+			return err
+		}
+	}
+	if s.TimestampField == "" {
+		return fmt.Errorf("timestamp field is required")
+	}
+	return nil
+}
+
+// columnOrEmpty renders field as a String expression, or an empty string
+// literal when the spec doesn't roll up by it.
+func columnOrEmpty(field string) string {
+	if field == "" {
+		return "''"
+	}
+	return fmt.Sprintf("toString(%s)", quoteIdentifier(field))
+}
+
+func formatRollupTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// buildRollupTableDDL creates the rollup table. ReplacingMergeTree keyed on
+// the full grouping makes re-rolling an hour idempotent: the newer count
+// replaces the older one, and trend reads use FINAL.
+func buildRollupTableDDL(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+    bucket DateTime('UTC'),
+    severity LowCardinality(String),
+    dimension String,
+    log_count UInt64
+) ENGINE = ReplacingMergeTree
+ORDER BY (bucket, severity, dimension)`, table)
+}
+
+func buildRollupInsert(spec RollupSpec, start, end time.Time) string {
+	ts := quoteIdentifier(spec.TimestampField)
+	return fmt.Sprintf(`INSERT INTO %s (bucket, severity, dimension, log_count)
+SELECT toStartOfHour(%s, 'UTC') AS bucket, %s AS severity, %s AS dimension, count() AS log_count
+FROM %s
+WHERE %s >= toDateTime('%s', 'UTC') AND %s < toDateTime('%s', 'UTC')
+GROUP BY bucket, severity, dimension`,
+		spec.RollupTable,
+		ts, columnOrEmpty(spec.SeverityField), columnOrEmpty(spec.DimensionField),
+		spec.SourceTable,
+		ts, formatRollupTime(start), ts, formatRollupTime(end))
+}
+
+func trendBucketExpr(column string, params TrendQueryParams) string {
+	return fmt.Sprintf("toStartOfInterval(%s, INTERVAL %d HOUR, '%s')", column, params.WindowHours, params.Timezone)
+}
+
+func trendGroupExpr(groupBy, severity, dimension string) string {
+	switch groupBy {
+	case "severity":
+		return severity
+	case "dimension":
+		return dimension
+	default:
+		return "''"
+	}
+}
+
+// buildRollupTrendQuery reads a trend from the rollup table. [Start, End)
+// must be hour-aligned.
+func buildRollupTrendQuery(table string, params TrendQueryParams) string {
+	return fmt.Sprintf(`SELECT %s AS bucket, %s AS group_value, sum(log_count) AS log_count
+FROM %s FINAL
+WHERE bucket >= toDateTime('%s', 'UTC') AND bucket < toDateTime('%s', 'UTC')
+GROUP BY bucket, group_value
+ORDER BY bucket, group_value`,
+		trendBucketExpr("bucket", params), trendGroupExpr(params.GroupBy, "severity", "dimension"),
+		table, formatRollupTime(params.Start), formatRollupTime(params.End))
+}
+
+// buildRawTrendQuery computes the same trend directly from the source table.
+func buildRawTrendQuery(spec RollupSpec, params TrendQueryParams) string {
+	ts := quoteIdentifier(spec.TimestampField)
+	return fmt.Sprintf(`SELECT %s AS bucket, %s AS group_value, count() AS log_count
+FROM %s
+WHERE %s >= toDateTime('%s', 'UTC') AND %s < toDateTime('%s', 'UTC')
+GROUP BY bucket, group_value
+ORDER BY bucket, group_value`,
+		trendBucketExpr(ts, params),
+		trendGroupExpr(params.GroupBy, columnOrEmpty(spec.SeverityField), columnOrEmpty(spec.DimensionField)),
+		spec.SourceTable, ts, formatRollupTime(params.Start), ts, formatRollupTime(params.End))
+}
+
+// TimeRangeOnlyQuery reports whether sql selects every row of table within a
+// time range and nothing else, so counting its rows per bucket is exactly what
+// a rollup stores. It accepts the shape the explorer and LogchefQL write for
+// an empty filter:
+//
+//	SELECT ... FROM table WHERE ts BETWEEN toDateTime('a', 'tz') AND toDateTime('b', 'tz') [ORDER BY ...] [LIMIT n]
+//
+// and returns the range as [start, end). BETWEEN's closing bound is inclusive,
+// so rows stamped exactly at end are the only ones the two disagree on.
+// Anything else (other conditions, grouping, joins, sampling, a bound without
+// an explicit timezone) reports ok=false.
+func TimeRangeOnlyQuery(sql, table, timestampField string) (start, end time.Time, ok bool) {
+	stmts, err := clickhouseparser.NewParser(sql).ParseStmts()
+	if err != nil || len(stmts) != 1 {
+		return time.Time{}, time.Time{}, false
+	}
+	stmt, isSelect := stmts[0].(*clickhouseparser.SelectQuery)
+	if !isSelect || stmt.With != nil || stmt.Top != nil || stmt.HasDistinct || stmt.DistinctOn != nil ||
+		stmt.Window != nil || stmt.Prewhere != nil || stmt.Where == nil || stmt.GroupBy != nil ||
+		stmt.Having != nil || stmt.LimitBy != nil || stmt.UnionAll != nil || stmt.UnionDistinct != nil ||
+		stmt.Except != nil || stmt.Intersect != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	if from, isTable := stmt.From.Expr.(*clickhouseparser.JoinTableExpr); !isTable || from.SampleRatio != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	if NewQueryBuilder(table, 0).validateTableReference(stmt) != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	between, isBetween := unwrapParens(stmt.Where.Expr).(*clickhouseparser.BetweenClause)
+	if !isBetween || between.Not {
+		return time.Time{}, time.Time{}, false
+	}
+	if col, isIdent := between.Expr.(*clickhouseparser.Ident); !isIdent || col.Name != timestampField {
+		return time.Time{}, time.Time{}, false
+	}
+	start, okStart := dateTimeLiteral(between.Between)
+	end, okEnd := dateTimeLiteral(between.And)
+	if !okStart || !okEnd || !end.After(start) {
+		return time.Time{}, time.Time{}, false
+	}
+	return start.UTC(), end.UTC(), true
+}
+
+// unwrapParens strips the parentheses around a single expression.
+func unwrapParens(expr clickhouseparser.Expr) clickhouseparser.Expr {
+	for {
+		list, isList := expr.(*clickhouseparser.ParamExprList)
+		if !isList || list.Items == nil || len(list.Items.Items) != 1 {
+			return expr
+		}
+		expr = list.Items.Items[0]
+		if col, isCol := expr.(*clickhouseparser.ColumnExpr); isCol && col.Alias == nil {
+			expr = col.Expr
+		}
+	}
+}
+
+// dateTimeLiteral evaluates toDateTime('YYYY-MM-DD hh:mm:ss', 'tz').
+func dateTimeLiteral(expr clickhouseparser.Expr) (time.Time, bool) {
+	fn, isFn := expr.(*clickhouseparser.FunctionExpr)
+	if !isFn || fn.Name == nil || !strings.EqualFold(fn.Name.Name, "toDateTime") || fn.Params == nil || fn.Params.Items == nil {
+		return time.Time{}, false
+	}
+	args := fn.Params.Items.Items
+	if len(args) != 2 {
+		return time.Time{}, false
+	}
+	value, okValue := stringArg(args[0])
+	tz, okTZ := stringArg(args[1])
+	if !okValue || !okTZ {
+		return time.Time{}, false
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", value, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func stringArg(expr clickhouseparser.Expr) (string, bool) {
+	if col, isCol := expr.(*clickhouseparser.ColumnExpr); isCol {
+		expr = col.Expr
+	}
+	lit, isLit := expr.(*clickhouseparser.StringLiteral)
+	if !isLit {
+		return "", false
+	}
+	return lit.Literal, true
+}
+
+func (p TrendQueryParams) validate() error {
+	if p.WindowHours <= 0 {
+		return fmt.Errorf("trend window must be at least one hour")
+	}
+	if err := ValidateTimezone(p.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+	switch p.GroupBy {
+	case "", "severity", "dimension":
+	default:
+		return fmt.Errorf("invalid trend group_by %q", p.GroupBy)
+	}
+	return nil
+}
+
+// Exec runs a statement that returns no rows (DDL, INSERT ... SELECT).
+func (c *Client) Exec(ctx context.Context, query string, timeoutSeconds *int) error {
+	_, err := c.execDDLWithTimeout(ctx, query, timeoutSeconds)
+	return err
+}
+
+// EnsureRollupTable creates the rollup table if it doesn't exist yet.
+func (c *Client) EnsureRollupTable(ctx context.Context, table string) error {
+	timeout := rollupTimeout
+	if err := c.Exec(ctx, buildRollupTableDDL(table), &timeout); err != nil {
+		return fmt.Errorf("creating rollup table %s: %w", table, err)
+	}
+	return nil
+}
+
+// DropRollupTable removes a rollup table and everything rolled up into it.
+func (c *Client) DropRollupTable(ctx context.Context, table string) error {
+	timeout := rollupTimeout
+	if err := c.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", table), &timeout); err != nil {
+		return fmt.Errorf("dropping rollup table %s: %w", table, err)
+	}
+	return nil
+}
+
+// InsertRollup rolls up the source rows in [start, end) into the rollup
+// table. start and end must be hour-aligned.
+func (c *Client) InsertRollup(ctx context.Context, spec RollupSpec, start, end time.Time) error {
+	if err := spec.validate(); err != nil {
+		return err
+	}
+	timeout := rollupTimeout
+	if err := c.Exec(ctx, buildRollupInsert(spec, start, end), &timeout); err != nil {
+		return fmt.Errorf("rolling up %s: %w", spec.SourceTable, err)
+	}
+	return nil
+}
+
+// RollupTrend reads a trend from a rollup table over hour-aligned [Start, End).
+func (c *Client) RollupTrend(ctx context.Context, table string, params TrendQueryParams) ([]TrendRow, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	return c.queryTrend(ctx, buildRollupTrendQuery(table, params), params.TimeoutSeconds)
+}
+
+// RawTrend computes a trend directly from the source table.
+func (c *Client) RawTrend(ctx context.Context, spec RollupSpec, params TrendQueryParams) ([]TrendRow, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	return c.queryTrend(ctx, buildRawTrendQuery(spec, params), params.TimeoutSeconds)
+}
+
+func (c *Client) queryTrend(ctx context.Context, query string, timeoutSeconds *int) ([]TrendRow, error) {
+	result, err := c.QueryWithTimeout(ctx, query, timeoutSeconds)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]TrendRow, 0, len(result.Logs))
+	for _, row := range result.Logs {
+		bucket, okB := row["bucket"].(time.Time)
+		count, okC := toInt(row["log_count"])
+		if !okB || !okC {
+			continue
+		}
+		group, _ := row["group_value"].(string)
+		rows = append(rows, TrendRow{Bucket: bucket, GroupValue: strings.TrimSpace(group), LogCount: int64(count)})
+	}
+	return rows, nil
+}
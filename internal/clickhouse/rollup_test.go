@@ -0,0 +1,103 @@
+package clickhouse
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRollupQueries(t *testing.T) {
+	spec := RollupSpec{
+		SourceTable:    "logs.app",
+		RollupTable:    RollupTableName("logs", 7),
+		TimestampField: "timestamp",
+		SeverityField:  "severity_text",
+	}
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+
+	insert := buildRollupInsert(spec, start, end)
+	for _, want := range []string{
+		"INSERT INTO logs.logchef_rollup_7",
+		"toStartOfHour(`timestamp`, 'UTC') AS bucket",
+		"toString(`severity_text`) AS severity",
+		"'' AS dimension",
+		"`timestamp` >= toDateTime('2026-03-01 10:00:00', 'UTC') AND `timestamp` < toDateTime('2026-03-01 12:00:00', 'UTC')",
+	} {
+		if !strings.Contains(insert, want) {
+			t.Errorf("insert missing %q:\n%s", want, insert)
+		}
+	}
+
+	params := TrendQueryParams{Start: start, End: end, WindowHours: 24, Timezone: "Asia/Kolkata", GroupBy: "severity"}
+	rollup := buildRollupTrendQuery(spec.RollupTable, params)
+	for _, want := range []string{
+		"toStartOfInterval(bucket, INTERVAL 24 HOUR, 'Asia/Kolkata') AS bucket",
+		"severity AS group_value",
+		"sum(log_count)",
+		"FROM logs.logchef_rollup_7 FINAL",
+	} {
+		if !strings.Contains(rollup, want) {
+			t.Errorf("rollup trend missing %q:\n%s", want, rollup)
+		}
+	}
+
+	params.GroupBy = "dimension"
+	raw := buildRawTrendQuery(spec, params)
+	for _, want := range []string{
+		"toStartOfInterval(`timestamp`, INTERVAL 24 HOUR, 'Asia/Kolkata') AS bucket",
+		"'' AS group_value",
+		"FROM logs.app",
+	} {
+		if !strings.Contains(raw, want) {
+			t.Errorf("raw trend missing %q:\n%s", want, raw)
+		}
+	}
+}
+
+func TestRollupValidation(t *testing.T) {
+	bad := RollupSpec{SourceTable: "logs.app", TimestampField: "timestamp", DimensionField: "service; DROP TABLE x"}
+	if err := bad.validate(); err == nil {
+		t.Error("expected an invalid dimension to be rejected")
+	}
+	if err := (TrendQueryParams{WindowHours: 1, Timezone: "UTC", GroupBy: "host"}).validate(); err == nil {
+		t.Error("expected an unknown group_by to be rejected")
+	}
+	if err := (TrendQueryParams{WindowHours: 0, Timezone: "UTC"}).validate(); err == nil {
+		t.Error("expected a zero window to be rejected")
+	}
+}
+
+func TestTimeRangeOnlyQuery(t *testing.T) {
+	const timeRange = "`timestamp` BETWEEN toDateTime('2026-03-01 05:30:00', 'Asia/Kolkata') AND toDateTime('2026-03-09 05:30:00', 'Asia/Kolkata')"
+	start, end, ok := TimeRangeOnlyQuery("SELECT *\nFROM logs.app\nWHERE "+timeRange+"\nORDER BY `timestamp` DESC\nLIMIT 100", "logs.app", "timestamp")
+	if !ok {
+		t.Fatal("explorer query without filters was not recognised")
+	}
+	if want := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("start = %s, want %s", start, want)
+	}
+	if want := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("end = %s, want %s", end, want)
+	}
+	if _, _, ok := TimeRangeOnlyQuery("SELECT msg FROM logs.app WHERE ("+timeRange+")", "logs.app", "timestamp"); !ok {
+		t.Error("parenthesised time range was not recognised")
+	}
+
+	for name, sql := range map[string]string{
+		"extra filter":    "SELECT * FROM logs.app WHERE " + timeRange + " AND (`level` = 'error')",
+		"other table":     "SELECT * FROM logs.other WHERE " + timeRange,
+		"group by":        "SELECT count() FROM logs.app WHERE " + timeRange + " GROUP BY msg",
+		"distinct":        "SELECT DISTINCT msg FROM logs.app WHERE " + timeRange,
+		"sample":          "SELECT * FROM logs.app SAMPLE 0.1 WHERE " + timeRange,
+		"no timezone":     "SELECT * FROM logs.app WHERE `timestamp` BETWEEN toDateTime('2026-03-01 00:00:00') AND toDateTime('2026-03-09 00:00:00')",
+		"other column":    "SELECT * FROM logs.app WHERE `ingested_at` BETWEEN toDateTime('2026-03-01 00:00:00', 'UTC') AND toDateTime('2026-03-09 00:00:00', 'UTC')",
+		"no where":        "SELECT * FROM logs.app",
+		"invalid":         "SELECT * FROM",
+		"subquery source": "SELECT * FROM (SELECT * FROM logs.app) WHERE " + timeRange,
+	} {
+		if _, _, ok := TimeRangeOnlyQuery(sql, "logs.app", "timestamp"); ok {
+			t.Errorf("%s: %q was treated as unfiltered", name, sql)
+		}
+	}
+}
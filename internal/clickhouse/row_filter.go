@@ -0,0 +1,164 @@
+package clickhouse
+
+import (
+	"fmt"
+	"strings"
+
+	clickhouseparser "github.com/AfterShip/clickhouse-sql-parser/parser"
+)
+
+// Row filters are row-level access policies: a boolean expression ANDed into
+// every query a team runs on a shared table, so the team only sees the rows
+// that match it.
+
+// ValidateRowFilter checks that filter is a boolean expression that can be
+// ANDed into a WHERE clause on its own: it parses, reads no other table, and
+// binds no "?" placeholders (the queries it joins bind their own).
+func ValidateRowFilter(filter string) error {
+	if strings.TrimSpace(filter) == "" {
+		return &ValidationError{Message: "row filter is empty"}
+	}
+	if strings.Contains(filter, "?") {
+		return &ValidationError{Message: "row filter must not contain '?'"}
+	}
+	where, err := parseRowFilter(strings.ReplaceAll(filter, "''", rowFilterQuotePlaceholder))
+	if err != nil {
+		return err
+	}
+	if containsSubquery(where.Expr) {
+		return &ValidationError{Message: "row filter must not contain subqueries"}
+	}
+	return nil
+}
+
+// WithRowFilter makes the builder AND filter into the query's WHERE clause;
+// see applyRowFilter.
+func (qb *QueryBuilder) WithRowFilter(filter string) *QueryBuilder {
+	qb.rowFilter = filter
+	return qb
+}
+
+// RowFilterConditionSQL returns filter as a " AND (...)" fragment to append
+// to a WHERE clause the caller builds itself, or "" without a filter.
+func RowFilterConditionSQL(filter string) string {
+	if strings.TrimSpace(filter) == "" {
+		return ""
+	}
+	return " AND (" + filter + ")"
+}
+
+// applyRowFilter ANDs the builder's row filter into stmt's WHERE clause. So
+// the filter can't be sidestepped, stmt must read the builder's table
+// directly, without JOINs, subqueries or set operations, and must not define
+// an alias named like a column the filter reads: ClickHouse resolves names in
+// WHERE to SELECT aliases before columns.
+func (qb *QueryBuilder) applyRowFilter(stmt *clickhouseparser.SelectQuery) error {
+	if err := qb.validateDirectRead(stmt); err != nil {
+		return &ValidationError{Message: "this source has a row filter for your team: " + err.Error()}
+	}
+
+	columns, err := FilteredColumns("SELECT 1 FROM t WHERE " + strings.ReplaceAll(qb.rowFilter, "''", rowFilterQuotePlaceholder))
+	if err != nil {
+		return fmt.Errorf("invalid row filter: %w", err)
+	}
+	filtered := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		filtered[column] = true
+	}
+	for _, alias := range definedAliases(stmt) {
+		if filtered[alias] {
+			return &ValidationError{Message: fmt.Sprintf("this source has a row filter for your team: the alias %q shadows a column it filters on", alias)}
+		}
+	}
+
+	filter := "(" + strings.ReplaceAll(qb.rowFilter, "''", rowFilterQuotePlaceholder) + ")"
+	if stmt.Where != nil {
+		filter = "(" + formatSQL(stmt.Where.Expr) + ") AND " + filter
+	}
+	where, err := parseRowFilter(filter)
+	if err != nil {
+		return err
+	}
+	if stmt.Where == nil {
+		stmt.Where = where
+	} else {
+		stmt.Where.Expr = where.Expr
+	}
+	return nil
+}
+
+// validateDirectRead checks that stmt reads the builder's table directly,
+// without JOINs, subqueries or set operations, so an access policy rewritten
+// into it can't be sidestepped.
+func (qb *QueryBuilder) validateDirectRead(stmt *clickhouseparser.SelectQuery) error {
+	if err := qb.validateTableReference(stmt); err != nil {
+		return err
+	}
+	if stmt.UnionAll != nil || stmt.UnionDistinct != nil || stmt.Except != nil || stmt.Intersect != nil {
+		return fmt.Errorf("UNION, EXCEPT and INTERSECT are not allowed")
+	}
+	if containsSubquery(stmt) {
+		return fmt.Errorf("subqueries are not allowed")
+	}
+	return nil
+}
+
+// rowFilterQuotePlaceholder stands in for escaped quotes while parsing, the
+// same placeholder BuildRawQueryWithLimitPolicy uses, so the query and the
+// filter ANDed into it restore alike.
+const rowFilterQuotePlaceholder = "___ESCAPED_QUOTE___"
+
+// parseRowFilter parses filter as a WHERE clause. Escaped quotes in filter
+// must already be replaced with rowFilterQuotePlaceholder.
+func parseRowFilter(filter string) (*clickhouseparser.WhereClause, error) {
+	stmts, err := clickhouseparser.NewParser("SELECT 1 WHERE " + filter).ParseStmts()
+	if err != nil || len(stmts) != 1 {
+		return nil, &ValidationError{Message: fmt.Sprintf("invalid row filter: %v", err)}
+	}
+	stmt, ok := stmts[0].(*clickhouseparser.SelectQuery)
+	if !ok || stmt.Where == nil || stmt.From != nil || stmt.GroupBy != nil || stmt.Limit != nil || stmt.Settings != nil || stmt.UnionAll != nil {
+		return nil, &ValidationError{Message: "invalid row filter: it must be a single boolean expression"}
+	}
+	return stmt.Where, nil
+}
+
+// containsSubquery reports whether node has a SELECT nested anywhere in it.
+func containsSubquery(node clickhouseparser.Expr) bool {
+	found := false
+	clickhouseparser.Walk(node, func(n clickhouseparser.Expr) bool {
+		switch n.(type) {
+		case *clickhouseparser.SelectQuery, *clickhouseparser.SubQuery:
+			if n != node {
+				found = true
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// definedAliases returns the names stmt binds with AS, in SELECT items,
+// expressions, and WITH.
+func definedAliases(stmt *clickhouseparser.SelectQuery) []string {
+	var aliases []string
+	add := func(alias clickhouseparser.Expr) {
+		if ident, ok := alias.(*clickhouseparser.Ident); ok {
+			aliases = append(aliases, ident.Name)
+		}
+	}
+	clickhouseparser.Walk(stmt, func(n clickhouseparser.Expr) bool {
+		switch n := n.(type) {
+		case *clickhouseparser.SelectItem:
+			if n.Alias != nil {
+				add(n.Alias)
+			}
+		case *clickhouseparser.AliasExpr:
+			add(n.Alias)
+		case *clickhouseparser.CTEStmt:
+			add(n.Alias)
+		}
+		return true
+	})
+	return aliases
+}
@@ -0,0 +1,117 @@
+package clickhouse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRowFilter(t *testing.T) {
+	tests := []struct {
+		filter  string
+		wantErr bool
+	}{
+		{filter: "namespace = 'team-a'"},
+		{filter: "namespace IN ('a', 'b') AND log_attributes['env'] != 'dev'"},
+		{filter: "service = 'O''Brien'"},
+		{filter: "", wantErr: true},
+		{filter: "namespace = ?", wantErr: true},
+		{filter: "namespace = 'a' LIMIT 1", wantErr: true},
+		{filter: "namespace IN (SELECT ns FROM allowed)", wantErr: true},
+		{filter: "namespace = 'a' OR", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			err := ValidateRowFilter(tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateRowFilter(%q) error = %v, wantErr %v", tt.filter, err, tt.wantErr)
+			}
+			if err != nil && !IsValidationError(err) {
+				t.Errorf("error %v is not a ValidationError", err)
+			}
+		})
+	}
+}
+
+func TestQueryBuilderWithRowFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "ANDs into the existing WHERE",
+			query: "SELECT * FROM logs.app WHERE level = 'error' OR level = 'warn' LIMIT 10",
+			want:  []string{"(level = 'error' OR level = 'warn') AND (namespace = 'it''s')"},
+		},
+		{
+			name:  "adds a WHERE clause",
+			query: "SELECT count() FROM logs.app GROUP BY level",
+			want:  []string{"WHERE (namespace = 'it''s')", "GROUP BY level"},
+		},
+		{
+			name:    "other table",
+			query:   "SELECT * FROM logs.other",
+			wantErr: true,
+		},
+		{
+			name:    "subquery",
+			query:   "SELECT * FROM logs.app WHERE id IN (SELECT id FROM logs.other)",
+			wantErr: true,
+		},
+		{
+			name:    "union",
+			query:   "SELECT * FROM logs.app UNION ALL SELECT * FROM logs.app",
+			wantErr: true,
+		},
+		{
+			name:    "join",
+			query:   "SELECT * FROM logs.app a JOIN logs.other b ON a.id = b.id",
+			wantErr: true,
+		},
+		{
+			name:    "alias shadows a filtered column",
+			query:   "SELECT 'it''s' AS namespace, msg FROM logs.app",
+			wantErr: true,
+		},
+		{
+			name:    "WITH alias shadows a filtered column",
+			query:   "WITH 'it''s' AS namespace SELECT * FROM logs.app",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qb := NewExtendedQueryBuilder("logs.app", 0).WithRowFilter("namespace = 'it''s'")
+			got, err := qb.BuildRawQuery(tt.query, 100)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildRawQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("BuildRawQuery() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveLimitClauseWithRowFilter(t *testing.T) {
+	got, err := NewQueryBuilder("logs.app", 0).WithRowFilter("namespace = 'a'").
+		RemoveLimitClause("SELECT * FROM logs.app WHERE level = 'error' LIMIT 10")
+	if err != nil {
+		t.Fatalf("RemoveLimitClause() error = %v", err)
+	}
+	if !strings.Contains(got, "(level = 'error') AND (namespace = 'a')") || strings.Contains(got, "LIMIT") {
+		t.Errorf("RemoveLimitClause() = %q", got)
+	}
+}
+
+func TestRowFilterConditionSQL(t *testing.T) {
+	if got := RowFilterConditionSQL(""); got != "" {
+		t.Errorf("RowFilterConditionSQL(\"\") = %q, want empty", got)
+	}
+	if got := RowFilterConditionSQL("namespace = 'a'"); got != " AND (namespace = 'a')" {
+		t.Errorf("RowFilterConditionSQL() = %q", got)
+	}
+}
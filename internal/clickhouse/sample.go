@@ -0,0 +1,127 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	clickhouseparser "github.com/AfterShip/clickhouse-sql-parser/parser"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// Sampling methods reported in QueryStats.SampleMethod.
+const (
+	// SampleMethodClause reads a fraction of the table with SAMPLE, which
+	// needs a table with a sampling key but skips the rest of the data.
+	SampleMethodClause = "sample"
+	// SampleMethodRandom keeps rows whose random bucket falls under the
+	// ratio. It works on any query but still reads every matching row.
+	SampleMethodRandom = "random"
+)
+
+// sampleBuckets is how finely the random filter splits rows, so ratios
+// are honoured to 0.001.
+const sampleBuckets = 1000
+
+// SupportsSampling reports whether table has a sampling key, i.e. whether
+// queries on it can use SAMPLE. A Distributed table is checked through its
+// local table.
+func (c *Client) SupportsSampling(ctx context.Context, database, table string) (bool, error) {
+	engine, params, _, err := c.getTableEngine(ctx, database, table)
+	if err != nil {
+		return false, err
+	}
+	if engine == "Distributed" && len(params) >= 3 {
+		return c.SupportsSampling(ctx, params[1], params[2])
+	}
+
+	query := `SELECT sampling_key FROM system.tables WHERE database = ? AND name = ?`
+	var rows driver.Rows
+	err = c.executeQueryWithHooks(ctx, query, func(hookCtx context.Context) error {
+		rows, err = c.conn.Query(hookCtx, query, database, table)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to query sampling key: %w", err)
+	}
+	defer rows.Close()
+
+	var samplingKey string
+	if rows.Next() {
+		if err := rows.Scan(&samplingKey); err != nil {
+			return false, fmt.Errorf("failed to scan sampling key: %w", err)
+		}
+	}
+	return samplingKey != "", rows.Err()
+}
+
+// ValidateSampleRatio checks that ratio is a fraction of rows to keep,
+// between 0 and 1 exclusive.
+func ValidateSampleRatio(ratio float64) error {
+	if ratio <= 0 || ratio >= 1 {
+		return &ValidationError{Message: fmt.Sprintf("sample must be between 0 and 1 exclusive, got %g", ratio)}
+	}
+	return nil
+}
+
+// applySample restricts stmt to roughly ratio of its rows and returns the
+// method used. With useClause, a query that reads the builder's table
+// directly gets SAMPLE; anything else gets a random-bucket filter in its
+// WHERE clause.
+func (qb *QueryBuilder) applySample(stmt *clickhouseparser.SelectQuery, ratio float64, useClause bool) (string, error) {
+	if err := ValidateSampleRatio(ratio); err != nil {
+		return "", err
+	}
+	if stmt.UnionAll != nil || stmt.UnionDistinct != nil || stmt.Except != nil || stmt.Intersect != nil {
+		return "", &ValidationError{Message: "sampling is not supported on UNION, EXCEPT or INTERSECT queries"}
+	}
+
+	if useClause && stmt.From != nil && qb.validateTableReference(stmt) == nil {
+		if from, ok := stmt.From.Expr.(*clickhouseparser.JoinTableExpr); ok && from.SampleRatio == nil {
+			clause, err := parseSampleClause(ratio)
+			if err != nil {
+				return "", err
+			}
+			from.SampleRatio = clause
+			return SampleMethodClause, nil
+		}
+	}
+
+	filter := fmt.Sprintf("rand() %% %d < %d", sampleBuckets, max(1, int(ratio*sampleBuckets+0.5)))
+	if stmt.Where != nil {
+		filter = "(" + formatSQL(stmt.Where.Expr) + ") AND " + filter
+	}
+	where, err := parseWhere(filter)
+	if err != nil {
+		return "", err
+	}
+	if stmt.Where == nil {
+		stmt.Where = where
+	} else {
+		stmt.Where.Expr = where.Expr
+	}
+	return SampleMethodRandom, nil
+}
+
+// parseSampleClause builds the "SAMPLE ratio" clause by parsing it, which
+// keeps the AST in the shape the parser itself produces.
+func parseSampleClause(ratio float64) (*clickhouseparser.SampleClause, error) {
+	sql := "SELECT 1 FROM t SAMPLE " + strconv.FormatFloat(ratio, 'f', -1, 64)
+	stmts, err := clickhouseparser.NewParser(sql).ParseStmts()
+	if err != nil {
+		return nil, fmt.Errorf("building SAMPLE clause: %w", err)
+	}
+	from, ok := stmts[0].(*clickhouseparser.SelectQuery).From.Expr.(*clickhouseparser.JoinTableExpr)
+	if !ok || from.SampleRatio == nil {
+		return nil, fmt.Errorf("building SAMPLE clause: unexpected AST for %q", sql)
+	}
+	return from.SampleRatio, nil
+}
+
+func parseWhere(filter string) (*clickhouseparser.WhereClause, error) {
+	stmts, err := clickhouseparser.NewParser("SELECT 1 WHERE " + filter).ParseStmts()
+	if err != nil {
+		return nil, fmt.Errorf("building sample filter: %w", err)
+	}
+	return stmts[0].(*clickhouseparser.SelectQuery).Where, nil
+}
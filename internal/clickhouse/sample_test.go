@@ -0,0 +1,88 @@
+package clickhouse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQueryBuilderWithSample(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		ratio      float64
+		useClause  bool
+		wantMethod string
+		want       []string
+		wantErr    bool
+	}{
+		{
+			name:       "sampling key uses SAMPLE",
+			query:      "SELECT * FROM logs.app WHERE level = 'error' LIMIT 10",
+			ratio:      0.1,
+			useClause:  true,
+			wantMethod: SampleMethodClause,
+			want:       []string{"SAMPLE 0.1", "level = 'error'"},
+		},
+		{
+			name:       "no sampling key adds a random filter",
+			query:      "SELECT * FROM logs.app WHERE level = 'error' OR level = 'warn' LIMIT 10",
+			ratio:      0.1,
+			wantMethod: SampleMethodRandom,
+			want:       []string{"(level = 'error' OR level = 'warn') AND rand() % 1000 < 100"},
+		},
+		{
+			name:       "random filter without a WHERE clause",
+			query:      "SELECT * FROM logs.app LIMIT 10",
+			ratio:      0.0001,
+			wantMethod: SampleMethodRandom,
+			want:       []string{"WHERE rand() % 1000 < 1"},
+		},
+		{
+			name:       "other table falls back to a random filter",
+			query:      "SELECT * FROM logs.other LIMIT 10",
+			ratio:      0.5,
+			useClause:  true,
+			wantMethod: SampleMethodRandom,
+			want:       []string{"rand() % 1000 < 500"},
+		},
+		{
+			name:    "ratio out of range",
+			query:   "SELECT * FROM logs.app",
+			ratio:   1.5,
+			wantErr: true,
+		},
+		{
+			name:    "union is rejected",
+			query:   "SELECT * FROM logs.app UNION ALL SELECT * FROM logs.app",
+			ratio:   0.1,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qb := NewExtendedQueryBuilder("logs.app", 1000).WithSample(tt.ratio, tt.useClause)
+			result, err := qb.BuildRawQueryWithLimitPolicy(tt.query, 0, 100, 1000)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got SQL %q", result.SQL)
+				}
+				if !IsValidationError(err) {
+					t.Errorf("err = %v, want a ValidationError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildRawQueryWithLimitPolicy: %v", err)
+			}
+			if result.SampleMethod != tt.wantMethod {
+				t.Errorf("SampleMethod = %q, want %q", result.SampleMethod, tt.wantMethod)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(result.SQL, s) {
+					t.Errorf("SQL = %q, want it to contain %q", result.SQL, s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package clickhouse
+
+import (
+	"fmt"
+	"strings"
+
+	clickhouseparser "github.com/AfterShip/clickhouse-sql-parser/parser"
+)
+
+// Statement is one semicolon-delimited statement within a larger SQL buffer
+// (typically the full query editor content). Start/End are byte offsets into
+// that buffer and bound the statement text with surrounding whitespace trimmed;
+// the terminating semicolon is not included.
+type Statement struct {
+	Index int
+	Start int
+	End   int
+	Text  string
+}
+
+// SplitStatements splits sql into its semicolon-delimited statements. Semicolons
+// inside string literals, quoted identifiers, and comments do not terminate a
+// statement. Segments holding only whitespace or comments are skipped, so
+// trailing semicolons and blank lines between statements don't produce empty
+// statements. Statement indexes are assigned over the non-empty statements.
+func SplitStatements(sql string) []Statement {
+	var stmts []Statement
+	segStart := 0
+	hasCode := false
+
+	emit := func(end int) {
+		if hasCode {
+			start, stop := trimSpan(sql, segStart, end)
+			stmts = append(stmts, Statement{
+				Index: len(stmts),
+				Start: start,
+				End:   stop,
+				Text:  sql[start:stop],
+			})
+		}
+		segStart = end + 1
+		hasCode = false
+	}
+
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			i = skipQuoted(sql, i, ch)
+			hasCode = true
+		case ch == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case ch == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += 2 + end + 1
+			}
+		case ch == ';':
+			emit(i)
+		case isSpace(ch):
+			// Whitespace alone doesn't make a segment a statement.
+		default:
+			hasCode = true
+		}
+	}
+	if segStart < len(sql) {
+		emit(len(sql))
+	}
+	return stmts
+}
+
+// skipQuoted returns the index of the closing quote for the quoted run opening
+// at sql[start], honoring backslash escapes and doubled quotes. An unterminated
+// run extends to the end of the buffer.
+func skipQuoted(sql string, start int, quote byte) int {
+	for i := start + 1; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			i++
+		case quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(sql)
+}
+
+// trimSpan narrows [start, end) of sql to exclude leading and trailing whitespace.
+func trimSpan(sql string, start, end int) (int, int) {
+	if end > len(sql) {
+		end = len(sql)
+	}
+	for start < end && isSpace(sql[start]) {
+		start++
+	}
+	for end > start && isSpace(sql[end-1]) {
+		end--
+	}
+	return start, end
+}
+
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
+
+// ResolveSelection picks the statement to run for an editor "run selection".
+// fullSQL is the complete editor content; start/end are the selection bounds in
+// characters (Unicode code points), as reported by the editor. The selection
+// must hold exactly one statement and lie within a single statement of the full
+// content, so selecting across statements can never smuggle a second statement
+// into the run. The returned Statement carries the index of the enclosing
+// statement in fullSQL and the byte span of the selected text.
+func ResolveSelection(fullSQL string, start, end int) (Statement, error) {
+	byteStart, ok := runeOffsetToByte(fullSQL, start)
+	if !ok {
+		return Statement{}, &ValidationError{Message: fmt.Sprintf("selection start %d is out of range", start)}
+	}
+	byteEnd, ok := runeOffsetToByte(fullSQL, end)
+	if !ok {
+		return Statement{}, &ValidationError{Message: fmt.Sprintf("selection end %d is out of range", end)}
+	}
+	if byteStart >= byteEnd {
+		return Statement{}, &ValidationError{Message: "selection is empty"}
+	}
+
+	selected := SplitStatements(fullSQL[byteStart:byteEnd])
+	switch {
+	case len(selected) == 0:
+		return Statement{}, &ValidationError{Message: "selection does not contain a statement"}
+	case len(selected) > 1:
+		return Statement{}, &ValidationError{Message: fmt.Sprintf("selection contains %d statements; select a single statement to run", len(selected))}
+	}
+
+	absStart := byteStart + selected[0].Start
+	absEnd := byteStart + selected[0].End
+	for _, stmt := range SplitStatements(fullSQL) {
+		if absStart >= stmt.Start && absEnd <= stmt.End {
+			return Statement{
+				Index: stmt.Index,
+				Start: absStart,
+				End:   absEnd,
+				Text:  selected[0].Text,
+			}, nil
+		}
+	}
+	return Statement{}, &ValidationError{Message: "selection must lie within a single statement"}
+}
+
+// ValidateSingleSelect checks that sql parses as exactly one SELECT statement.
+// It is the guard applied to a run-selection before execution; the query
+// builder re-validates when the query is built.
+func ValidateSingleSelect(sql string) error {
+	const placeholder = "___ESCAPED_QUOTE___"
+	stmts, err := clickhouseparser.NewParser(strings.ReplaceAll(sql, "''", placeholder)).ParseStmts()
+	if err != nil {
+		return &ValidationError{Message: fmt.Sprintf("invalid SQL syntax: %v", err)}
+	}
+	if len(stmts) != 1 {
+		return &ValidationError{Message: "selection must contain exactly one statement"}
+	}
+	if _, ok := stmts[0].(*clickhouseparser.SelectQuery); !ok {
+		return &ValidationError{Message: "only SELECT statements can be run from a selection"}
+	}
+	return nil
+}
+
+// runeOffsetToByte converts a code point offset into a byte offset within s.
+// An offset equal to the rune count maps to len(s).
+func runeOffsetToByte(s string, offset int) (int, bool) {
+	if offset < 0 {
+		return 0, false
+	}
+	if offset == 0 {
+		return 0, true
+	}
+	n := 0
+	for i := range s {
+		if n == offset {
+			return i, true
+		}
+		n++
+	}
+	if n == offset {
+		return len(s), true
+	}
+	return 0, false
+}
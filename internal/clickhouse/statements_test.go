@@ -0,0 +1,182 @@
+package clickhouse
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "single statement without semicolon",
+			sql:  "SELECT 1",
+			want: []string{"SELECT 1"},
+		},
+		{
+			name: "trailing semicolon and blank lines",
+			sql:  "SELECT 1;\n\n  SELECT 2 ;\n;",
+			want: []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name: "semicolons inside literals and identifiers",
+			sql:  "SELECT 'a;b', \"c;d\", `e;f` FROM t; SELECT 'it''s;' ",
+			want: []string{"SELECT 'a;b', \"c;d\", `e;f` FROM t", "SELECT 'it''s;'"},
+		},
+		{
+			name: "semicolons inside comments",
+			sql:  "SELECT 1 -- note; here\nFROM t; /* x; y */ SELECT 2",
+			want: []string{"SELECT 1 -- note; here\nFROM t", "/* x; y */ SELECT 2"},
+		},
+		{
+			name: "comment-only segment is skipped",
+			sql:  "SELECT 1; -- done\n",
+			want: []string{"SELECT 1"},
+		},
+		{
+			name: "backslash escaped quote",
+			sql:  `SELECT 'a\';b'; SELECT 2`,
+			want: []string{`SELECT 'a\';b'`, "SELECT 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitStatements(tt.sql)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d statements %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i, stmt := range got {
+				if stmt.Text != tt.want[i] {
+					t.Errorf("statement %d = %q, want %q", i, stmt.Text, tt.want[i])
+				}
+				if stmt.Index != i {
+					t.Errorf("statement %d has index %d", i, stmt.Index)
+				}
+				if tt.sql[stmt.Start:stmt.End] != stmt.Text {
+					t.Errorf("statement %d span [%d,%d) does not match its text", i, stmt.Start, stmt.End)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveSelection(t *testing.T) {
+	full := "SELECT count() FROM logs;\nSELECT * FROM logs WHERE level = 'error' LIMIT 10;\nDROP TABLE logs"
+
+	// sel returns the code point bounds of needle within full.
+	sel := func(needle string) (int, int) {
+		i := strings.Index(full, needle)
+		if i < 0 {
+			t.Fatalf("needle %q not in editor content", needle)
+		}
+		start := utf8.RuneCountInString(full[:i])
+		return start, start + utf8.RuneCountInString(needle)
+	}
+
+	tests := []struct {
+		name      string
+		selection string
+		wantIndex int
+		wantText  string
+		errMsg    string
+	}{
+		{
+			name:      "whole second statement",
+			selection: "SELECT * FROM logs WHERE level = 'error' LIMIT 10",
+			wantIndex: 1,
+			wantText:  "SELECT * FROM logs WHERE level = 'error' LIMIT 10",
+		},
+		{
+			name:      "selection with surrounding whitespace and semicolon",
+			selection: ";\nSELECT * FROM logs WHERE level = 'error' LIMIT 10;\n",
+			wantIndex: 1,
+			wantText:  "SELECT * FROM logs WHERE level = 'error' LIMIT 10",
+		},
+		{
+			name:      "selection spanning two statements",
+			selection: "FROM logs;\nSELECT *",
+			errMsg:    "2 statements",
+		},
+		{
+			name:      "whitespace-only selection",
+			selection: ";\n",
+			errMsg:    "does not contain a statement",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := sel(tt.selection)
+			got, err := ResolveSelection(full, start, end)
+			if tt.errMsg != "" {
+				if err == nil {
+					t.Fatalf("expected error containing %q, got %+v", tt.errMsg, got)
+				}
+				if !IsValidationError(err) {
+					t.Errorf("expected a ValidationError, got %T", err)
+				}
+				if !strings.Contains(err.Error(), tt.errMsg) {
+					t.Errorf("error %q does not contain %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Index != tt.wantIndex || got.Text != tt.wantText {
+				t.Errorf("got index %d text %q, want index %d text %q", got.Index, got.Text, tt.wantIndex, tt.wantText)
+			}
+		})
+	}
+
+	t.Run("out of range", func(t *testing.T) {
+		n := utf8.RuneCountInString(full)
+		if _, err := ResolveSelection(full, 0, n+1); err == nil {
+			t.Fatal("expected an error for an end past the content")
+		}
+		if _, err := ResolveSelection(full, -1, 3); err == nil {
+			t.Fatal("expected an error for a negative start")
+		}
+	})
+
+	t.Run("code point offsets", func(t *testing.T) {
+		multi := "SELECT 'héllo';SELECT 2"
+		start := utf8.RuneCountInString("SELECT 'héllo';")
+		got, err := ResolveSelection(multi, start, utf8.RuneCountInString(multi))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Index != 1 || got.Text != "SELECT 2" {
+			t.Errorf("got %+v, want index 1 text %q", got, "SELECT 2")
+		}
+	})
+}
+
+func TestValidateSingleSelect(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		wantErr bool
+	}{
+		{name: "select", sql: "SELECT * FROM logs LIMIT 10"},
+		{name: "cte select", sql: "WITH x AS (SELECT 1) SELECT * FROM x"},
+		{name: "escaped quote", sql: "SELECT 'it''s' FROM logs"},
+		{name: "drop", sql: "DROP TABLE logs", wantErr: true},
+		{name: "insert", sql: "INSERT INTO logs (msg) VALUES ('x')", wantErr: true},
+		{name: "two statements", sql: "SELECT 1; SELECT 2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSingleSelect(tt.sql)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateSingleSelect(%q) error = %v, wantErr %v", tt.sql, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"math"
-	"strings"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
@@ -60,7 +59,7 @@ type IngestionStats struct {
 // TableStats retrieves overall statistics for a specific table from active parts.
 func (c *Client) TableStats(ctx context.Context, database, table string) (*TableStat, error) {
 	// Query system.parts for aggregated table statistics.
-	query := fmt.Sprintf(`
+	query := `
 		SELECT
 			database,
 			table,
@@ -70,17 +69,17 @@ func (c *Client) TableStats(ctx context.Context, database, table string) (*Table
 			sum(rows) AS rows,
 			count() AS part_count
 		FROM system.parts
-		WHERE (active = 1) AND (database = '%s') AND (table = '%s')
+		WHERE (active = 1) AND (database = ?) AND (table = ?)
 		GROUP BY
 			database,
 			table
 		ORDER BY size DESC
-	`, database, table) // Note: ORDER BY might not be necessary if only one row is expected.
+	` // Note: ORDER BY might not be necessary if only one row is expected.
 
 	queryCtx, cancel := statsQueryContext(ctx, tableStatsTimeoutSeconds)
 	defer cancel()
 
-	rows, err := c.conn.Query(queryCtx, query)
+	rows, err := c.conn.Query(queryCtx, query, database, table)
 	if err != nil {
 		return nil, fmt.Errorf("error executing table stats query: %w", err)
 	}
@@ -130,15 +129,6 @@ func (c *Client) TableStats(ctx context.Context, database, table string) (*Table
 	return &stats[0], nil
 }
 
-func quoteIdentifier(name string) string {
-	trimmed := strings.TrimSpace(name)
-	if strings.HasPrefix(trimmed, "`") && strings.HasSuffix(trimmed, "`") {
-		return trimmed
-	}
-	escaped := strings.ReplaceAll(trimmed, "`", "``")
-	return "`" + escaped + "`"
-}
-
 type ingestionActivityRow struct {
 	bucket time.Time
 	rows   uint64
@@ -0,0 +1,71 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// storageSnapshotTimeoutSeconds bounds the two system-table reads of a
+// storage snapshot. They run in the background, so the budget is looser than
+// the interactive stats queries.
+const storageSnapshotTimeoutSeconds = 30
+
+// TableStorage is a table's storage footprint in bytes, read from active parts
+// (totals) and system.columns (per column).
+type TableStorage struct {
+	Rows              uint64
+	PartCount         uint64
+	CompressedBytes   uint64
+	UncompressedBytes uint64
+	Columns           []models.ColumnStorageStats
+}
+
+// TableStorage reads the storage footprint of database.table.
+func (c *Client) TableStorage(ctx context.Context, database, table string) (*TableStorage, error) {
+	queryCtx, cancel := statsQueryContext(ctx, storageSnapshotTimeoutSeconds)
+	defer cancel()
+
+	storage := &TableStorage{}
+	row := c.conn.QueryRow(queryCtx, `
+		SELECT
+			sum(rows),
+			count(),
+			sum(data_compressed_bytes),
+			sum(data_uncompressed_bytes)
+		FROM system.parts
+		WHERE active = 1 AND database = ? AND table = ?`, database, table)
+	if err := row.Scan(&storage.Rows, &storage.PartCount, &storage.CompressedBytes, &storage.UncompressedBytes); err != nil {
+		return nil, fmt.Errorf("error reading table storage: %w", err)
+	}
+
+	rows, err := c.conn.Query(queryCtx, `
+		SELECT name, data_compressed_bytes, data_uncompressed_bytes
+		FROM system.columns
+		WHERE database = ? AND table = ?
+		ORDER BY position`, database, table)
+	if err != nil {
+		return nil, fmt.Errorf("error reading column storage: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			name                     string
+			compressed, uncompressed uint64
+		)
+		if err := rows.Scan(&name, &compressed, &uncompressed); err != nil {
+			return nil, fmt.Errorf("error scanning column storage row: %w", err)
+		}
+		storage.Columns = append(storage.Columns, models.ColumnStorageStats{
+			Name:              name,
+			CompressedBytes:   int64(compressed),   //nolint:gosec // byte counts fit in int64
+			UncompressedBytes: int64(uncompressed), //nolint:gosec // byte counts fit in int64
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating column storage rows: %w", err)
+	}
+	return storage, nil
+}
@@ -0,0 +1,155 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// fakeConn answers every query with a fixed set of single-column rows.
+type fakeConn struct {
+	driver.Conn
+	values []int64
+}
+
+func (f *fakeConn) Query(context.Context, string, ...any) (driver.Rows, error) {
+	return &fakeRows{values: f.values}, nil
+}
+
+type fakeRows struct {
+	driver.Rows
+	values []int64
+	next   int
+}
+
+func (r *fakeRows) ColumnTypes() []driver.ColumnType { return []driver.ColumnType{fakeColumn{}} }
+func (r *fakeRows) Err() error                       { return nil }
+func (r *fakeRows) Close() error                     { return nil }
+
+func (r *fakeRows) Next() bool {
+	if r.next >= len(r.values) {
+		return false
+	}
+	r.next++
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	*(dest[0].(*int64)) = r.values[r.next-1]
+	return nil
+}
+
+type fakeColumn struct{}
+
+func (fakeColumn) Name() string             { return "n" }
+func (fakeColumn) Nullable() bool           { return false }
+func (fakeColumn) ScanType() reflect.Type   { return reflect.TypeFor[int64]() }
+func (fakeColumn) DatabaseTypeName() string { return "Int64" }
+
+func newFakeClient(values ...int64) *Client {
+	return &Client{
+		conn:   &fakeConn{values: values},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+func TestQueryStreamRowFunc(t *testing.T) {
+	t.Parallel()
+
+	var got []int64
+	stats, err := newFakeClient(1, 2, 3).QueryStream(context.Background(), "SELECT n FROM t", QueryOptions{},
+		RowFunc(func(row map[string]any) error {
+			got = append(got, row["n"].(int64))
+			return nil
+		}))
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+	if !reflect.DeepEqual(got, []int64{1, 2, 3}) || stats.RowsReturned != 3 || stats.RowsRead != 3 {
+		t.Errorf("rows = %v, stats = %+v", got, stats)
+	}
+
+	boom := errors.New("boom")
+	_, err = newFakeClient(1, 2).QueryStream(context.Background(), "SELECT n FROM t", QueryOptions{},
+		RowFunc(func(map[string]any) error { return boom }))
+	if !errors.Is(err, boom) {
+		t.Errorf("QueryStream error = %v, want the callback's error", err)
+	}
+}
+
+func TestQueryWithOptionsBounds(t *testing.T) {
+	t.Parallel()
+
+	result, err := newFakeClient(1, 2, 3, 4).QueryWithOptions(context.Background(), "SELECT n FROM t", QueryOptions{MaxRows: 2, LimitApplied: 2})
+	if err != nil {
+		t.Fatalf("QueryWithOptions: %v", err)
+	}
+	if len(result.Logs) != 2 || result.Stats.TruncatedReason != "row_limit" || result.Stats.LimitApplied != 2 {
+		t.Errorf("row bound: %d rows, stats %+v", len(result.Logs), result.Stats)
+	}
+	if len(result.Columns) != 1 || result.Columns[0].Name != "n" {
+		t.Errorf("columns = %+v", result.Columns)
+	}
+
+	// approxJSONSize puts each {"n":N} row at 27 bytes, so 60 bytes fit two.
+	result, err = newFakeClient(1, 2, 3, 4).QueryWithOptions(context.Background(), "SELECT n FROM t", QueryOptions{MaxResponseBytes: 60})
+	if err != nil {
+		t.Fatalf("QueryWithOptions: %v", err)
+	}
+	if len(result.Logs) != 2 || !result.Stats.Truncated || result.Stats.TruncatedReason != "byte_limit" || result.Stats.RowsReturned != 2 {
+		t.Errorf("byte bound: %d rows, stats %+v", len(result.Logs), result.Stats)
+	}
+}
+
+func TestQueryStreamByteBudget(t *testing.T) {
+	t.Parallel()
+
+	// Each {"n": <int64>} row counts as 27 bytes, so 60 bytes fit two.
+	opts := QueryOptions{MaxResponseBytes: 60}
+	var finished bool
+	var got []int64
+	stats, err := newFakeClient(1, 2, 3, 4).QueryStream(context.Background(), "SELECT n FROM t", opts, &finishRecorder{
+		RowFunc: func(row map[string]any) error {
+			got = append(got, row["n"].(int64))
+			return nil
+		},
+		finish: func(stats models.QueryStats) {
+			finished = stats.Truncated && stats.TruncatedReason == "byte_limit"
+		},
+	})
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+	if !reflect.DeepEqual(got, []int64{1, 2}) || !stats.Truncated || stats.TruncatedReason != "byte_limit" || stats.BytesReturned != 54 {
+		t.Errorf("rows = %v, stats = %+v", got, stats)
+	}
+	if !finished {
+		t.Error("Finish did not receive the byte_limit truncation")
+	}
+
+	result, err := newFakeClient(1, 2, 3, 4).QueryWithOptions(context.Background(), "SELECT n FROM t", opts)
+	if err != nil {
+		t.Fatalf("QueryWithOptions: %v", err)
+	}
+	if len(result.Logs) != 2 || len(result.Warnings) != 1 || result.Warnings[0].Code != "BYTE_LIMIT" {
+		t.Errorf("rows = %d, warnings = %+v", len(result.Logs), result.Warnings)
+	}
+}
+
+// finishRecorder is a RowFunc that also sees the final stats.
+type finishRecorder struct {
+	RowFunc
+	finish func(models.QueryStats)
+}
+
+func (f *finishRecorder) Finish(stats models.QueryStats) error {
+	f.finish(stats)
+	return nil
+}
@@ -0,0 +1,83 @@
+package clickhouse
+
+import (
+	"fmt"
+	"time"
+
+	clickhouseparser "github.com/AfterShip/clickhouse-sql-parser/parser"
+)
+
+// Time bounds guard against accidental full-table scans: a query on a source
+// must filter on the source's timestamp column, or have the requested time
+// range ANDed in for it.
+
+// WithTimeBound makes the builder require a condition on the timestamp column
+// field in queries that read its table, adding start and end as one when the
+// query has none; see applyTimeBound.
+func (qb *QueryBuilder) WithTimeBound(field string, start, end *time.Time) *QueryBuilder {
+	qb.timeField = field
+	qb.timeStart = start
+	qb.timeEnd = end
+	return qb
+}
+
+// applyTimeBound checks that stmt's WHERE or PREWHERE clause reads the
+// builder's timestamp column. If it doesn't, the builder's time range is ANDed
+// into WHERE and applyTimeBound reports true; without a range the query is
+// rejected. Queries that don't read the builder's table directly, such as
+// ones over a subquery or another table, are left alone.
+func (qb *QueryBuilder) applyTimeBound(stmt *clickhouseparser.SelectQuery) (bool, error) {
+	if err := qb.validateTableReference(stmt); err != nil {
+		return false, nil
+	}
+	if filtersOnColumn(stmt, qb.timeField) {
+		return false, nil
+	}
+	if qb.timeStart == nil || qb.timeEnd == nil {
+		return false, &ValidationError{Message: fmt.Sprintf(
+			"query has no time bound: add a condition on %s, e.g. WHERE %s >= now() - INTERVAL 1 HOUR, or pick a time range",
+			quoteIdentifier(qb.timeField), quoteIdentifier(qb.timeField))}
+	}
+
+	bound := timeBoundCondition(qb.timeField, *qb.timeStart, *qb.timeEnd)
+	if stmt.Where != nil {
+		bound = "(" + formatSQL(stmt.Where.Expr) + ") AND " + bound
+	}
+	where, err := parseRowFilter(bound)
+	if err != nil {
+		return false, err
+	}
+	if stmt.Where == nil {
+		stmt.Where = where
+	} else {
+		stmt.Where.Expr = where.Expr
+	}
+	return true, nil
+}
+
+// timeBoundCondition returns the condition keeping field within
+// [start, end], compared in UTC at millisecond precision.
+func timeBoundCondition(field string, start, end time.Time) string {
+	const layout = "2006-01-02 15:04:05.000"
+	return fmt.Sprintf("(%s BETWEEN toDateTime64('%s', 3, 'UTC') AND toDateTime64('%s', 3, 'UTC'))",
+		quoteIdentifier(field), start.UTC().Format(layout), end.UTC().Format(layout))
+}
+
+// filtersOnColumn reports whether stmt's WHERE or PREWHERE clause names column,
+// on its own or qualified by the table (logs.timestamp).
+func filtersOnColumn(stmt *clickhouseparser.SelectQuery, column string) bool {
+	found := false
+	visit := func(node clickhouseparser.Expr) bool {
+		if ident, ok := node.(*clickhouseparser.Ident); ok && ident.Name == column {
+			found = true
+		}
+		return !found
+	}
+	if stmt.Prewhere != nil {
+		clickhouseparser.Walk(stmt.Prewhere.Expr, visit)
+	}
+	if stmt.Where != nil && !found {
+		clickhouseparser.Walk(stmt.Where.Expr, visit)
+	}
+	return found
+}
@@ -0,0 +1,81 @@
+package clickhouse
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueryBuilderWithTimeBound(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	tests := []struct {
+		name      string
+		query     string
+		noRange   bool
+		want      []string
+		wantAdded bool
+		wantErr   bool
+	}{
+		{
+			name:  "existing bound",
+			query: "SELECT * FROM logs.app WHERE timestamp >= now() - INTERVAL 1 HOUR",
+			want:  []string{"timestamp >= now() - INTERVAL 1 HOUR"},
+		},
+		{
+			name:  "bound in PREWHERE",
+			query: "SELECT * FROM logs.app AS a PREWHERE a.timestamp > '2026-01-01'",
+		},
+		{
+			name:      "adds a WHERE clause",
+			query:     "SELECT count() FROM logs.app GROUP BY level",
+			want:      []string{"WHERE (`timestamp` BETWEEN toDateTime64('2026-03-01 10:00:00.000', 3, 'UTC') AND toDateTime64('2026-03-01 11:00:00.000', 3, 'UTC'))", "GROUP BY level"},
+			wantAdded: true,
+		},
+		{
+			name:      "ANDs into the existing WHERE",
+			query:     "SELECT * FROM logs.app WHERE level = 'it''s' OR level = 'warn'",
+			want:      []string{"(level = 'it''s' OR level = 'warn') AND (`timestamp` BETWEEN"},
+			wantAdded: true,
+		},
+		{
+			name:    "no bound and no range",
+			query:   "SELECT * FROM logs.app WHERE level = 'error'",
+			noRange: true,
+			wantErr: true,
+		},
+		{
+			name:    "other table is left alone",
+			query:   "SELECT * FROM system.tables",
+			noRange: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qb := NewExtendedQueryBuilder("logs.app", 0)
+			if tt.noRange {
+				qb.WithTimeBound("timestamp", nil, nil)
+			} else {
+				qb.WithTimeBound("timestamp", &start, &end)
+			}
+			got, err := qb.BuildRawQueryWithLimitPolicy(tt.query, 0, 100, 1000)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildRawQueryWithLimitPolicy() = %q, error = %v, wantErr %v", got.SQL, err, tt.wantErr)
+			}
+			if err != nil {
+				if !IsValidationError(err) {
+					t.Errorf("error %v is not a ValidationError", err)
+				}
+				return
+			}
+			if got.TimeBoundAdded != tt.wantAdded {
+				t.Errorf("TimeBoundAdded = %v, want %v", got.TimeBoundAdded, tt.wantAdded)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got.SQL, want) {
+					t.Errorf("SQL = %q, want it to contain %q", got.SQL, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+)
+
+// ttlTimeout bounds ALTER ... MODIFY TTL. The statement only records the new
+// TTL and queues the rewrite of existing parts as a mutation, so it returns
+// quickly even on large tables.
+const ttlTimeout = 60
+
+// TTLExpression is the row TTL LogChef uses for a retention of days, the same
+// expression its auto-created tables carry.
+func TTLExpression(timestampField string, days int) string {
+	return fmt.Sprintf("toDateTime(%s) + INTERVAL %d DAY", quoteIdentifier(timestampField), days)
+}
+
+// BuildTTLStatement returns the ALTER that sets a retention of days on the
+// target, or removes its TTL when days is negative.
+func BuildTTLStatement(target TableTarget, timestampField string, days int) (string, error) {
+	stmt, err := target.alterTable()
+	if err != nil {
+		return "", err
+	}
+	if days < 0 {
+		return stmt + " REMOVE TTL", nil
+	}
+	if err := ValidateIdentifier(timestampField); err != nil {
+		return "", fmt.Errorf("invalid timestamp field: %w", err)
+	}
+	return stmt + " MODIFY TTL " + TTLExpression(timestampField, days), nil
+}
+
+// ModifyTTL runs a statement built by BuildTTLStatement.
+func (c *Client) ModifyTTL(ctx context.Context, stmt string) error {
+	timeout := ttlTimeout
+	if err := c.Exec(ctx, stmt, &timeout); err != nil {
+		return fmt.Errorf("modifying table TTL: %w", err)
+	}
+	return nil
+}
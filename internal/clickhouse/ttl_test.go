@@ -0,0 +1,30 @@
+package clickhouse
+
+import "testing"
+
+func TestBuildTTLStatement(t *testing.T) {
+	for _, tc := range []struct {
+		target TableTarget
+		days   int
+		want   string
+	}{
+		{TableTarget{Database: "logs", Table: "app"}, 30, "ALTER TABLE `logs`.`app` MODIFY TTL toDateTime(`timestamp`) + INTERVAL 30 DAY"},
+		{TableTarget{Database: "logs", Table: "app_local", Cluster: "main"}, 7, "ALTER TABLE `logs`.`app_local` ON CLUSTER `main` MODIFY TTL toDateTime(`timestamp`) + INTERVAL 7 DAY"},
+		{TableTarget{Database: "logs", Table: "app"}, -1, "ALTER TABLE `logs`.`app` REMOVE TTL"},
+	} {
+		got, err := BuildTTLStatement(tc.target, "timestamp", tc.days)
+		if err != nil {
+			t.Fatalf("BuildTTLStatement(%+v, %d): %v", tc.target, tc.days, err)
+		}
+		if got != tc.want {
+			t.Errorf("BuildTTLStatement(%+v, %d) = %q, want %q", tc.target, tc.days, got, tc.want)
+		}
+	}
+
+	if _, err := BuildTTLStatement(TableTarget{Database: "logs", Table: "app; DROP TABLE x"}, "timestamp", 1); err == nil {
+		t.Error("accepted an invalid table name")
+	}
+	if _, err := BuildTTLStatement(TableTarget{Database: "logs", Table: "app"}, "ts) + 1", 1); err == nil {
+		t.Error("accepted an invalid timestamp field")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+
+	"github.com/mr-karan/logchef/internal/timezone"
 )
 
 // ValidationError is returned for invalid inputs (field names, timezones).
@@ -54,5 +56,8 @@ func ValidateTimezone(tz string) error {
 	if !validTimezoneRe.MatchString(tz) {
 		return &ValidationError{Message: fmt.Sprintf("invalid timezone %q: contains disallowed characters", tz)}
 	}
+	if _, err := timezone.Load(tz); err != nil {
+		return &ValidationError{Message: fmt.Sprintf("unknown timezone %q: must be an IANA name such as Asia/Kolkata", tz)}
+	}
 	return nil
 }
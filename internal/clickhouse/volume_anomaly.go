@@ -0,0 +1,106 @@
+package clickhouse
+
+// Volume comparison for anomaly detection: row and error counts of the
+// current window and of the same window shifted back, in one scan.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// errorSeverityValues are the lower-cased severity values counted as errors.
+var errorSeverityValues = []string{"error", "err", "fatal", "critical", "crit", "alert", "emerg", "emergency", "panic"}
+
+// VolumeComparisonParams describes the two windows to count. The previous
+// window is [Start-Offset, End-Offset]; Offset must not be shorter than the
+// window so the two don't overlap.
+type VolumeComparisonParams struct {
+	TimestampField string
+	SeverityField  string // Optional: enables error counts
+	Start          time.Time
+	End            time.Time
+	Offset         time.Duration
+	Timeout        *int   // Optional: query timeout in seconds
+	LogchefQL      string // Optional: LogchefQL query string narrowing the rows
+}
+
+// VolumeCounts holds the row and error counts of both windows. Error counts
+// are zero without a severity field.
+type VolumeCounts struct {
+	CurrentRows    int64
+	PreviousRows   int64
+	CurrentErrors  int64
+	PreviousErrors int64
+}
+
+// CompareVolume counts rows (and errors) in the current and previous windows.
+func (c *Client) CompareVolume(ctx context.Context, database, table string, params VolumeComparisonParams) (*VolumeCounts, error) {
+	if err := ValidateIdentifier(params.TimestampField); err != nil {
+		return nil, fmt.Errorf("invalid timestamp field: %w", err)
+	}
+	if params.SeverityField != "" {
+		if err := ValidateIdentifier(params.SeverityField); err != nil {
+			return nil, fmt.Errorf("invalid severity field: %w", err)
+		}
+	}
+	if params.Offset < params.End.Sub(params.Start) {
+		return nil, fmt.Errorf("the baseline offset must not be shorter than the window")
+	}
+	timeout := params.Timeout
+	if timeout == nil {
+		defaultTimeout := 30
+		timeout = &defaultTimeout
+	}
+
+	query, args := buildVolumeComparisonQuery(database, table, params)
+	result, err := c.QueryWithArgs(ctx, query, timeout, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare log volume: %w", err)
+	}
+	counts := &VolumeCounts{}
+	if len(result.Logs) > 0 {
+		row := result.Logs[0]
+		counts.CurrentRows, _ = extractInt64FromRow(row, "current_rows")
+		counts.PreviousRows, _ = extractInt64FromRow(row, "previous_rows")
+		counts.CurrentErrors, _ = extractInt64FromRow(row, "current_errors")
+		counts.PreviousErrors, _ = extractInt64FromRow(row, "previous_errors")
+	}
+	return counts, nil
+}
+
+// buildVolumeComparisonQuery scans both windows at once; rows before Start
+// belong to the previous window.
+func buildVolumeComparisonQuery(database, table string, params VolumeComparisonParams) (string, []any) {
+	const layout = "2006-01-02 15:04:05"
+	ts := quoteIdentifier(params.TimestampField)
+	isError := "0"
+	if params.SeverityField != "" {
+		values := make([]string, len(errorSeverityValues))
+		for i, v := range errorSeverityValues {
+			values[i] = quoteLiteral(v)
+		}
+		isError = fmt.Sprintf("lower(toString(%s)) IN (%s)", quoteIdentifier(params.SeverityField), strings.Join(values, ", "))
+	}
+	previous, previousArgs := timeRangeSQL(params.TimestampField, params.Start.Add(-params.Offset), params.End.Add(-params.Offset), "UTC")
+	current, currentArgs := timeRangeSQL(params.TimestampField, params.Start, params.End, "UTC")
+	args := append(append([]any{params.Start.UTC().Format(layout)}, previousArgs...), currentArgs...)
+
+	return fmt.Sprintf(`
+		SELECT
+			countIf(__current) AS current_rows,
+			countIf(NOT __current) AS previous_rows,
+			countIf(__current AND __error) AS current_errors,
+			countIf(NOT __current AND __error) AS previous_errors
+		FROM (
+			SELECT %s >= toDateTime(?, 'UTC') AS __current, %s AS __error
+			FROM %s
+			PREWHERE (%s) OR (%s)
+			WHERE 1%s
+		)
+	`, ts, isError,
+		quoteTable(database, table),
+		previous, current,
+		buildLogchefQLConditionsSQL(params.LogchefQL)), args
+}
@@ -0,0 +1,47 @@
+package clickhouse
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildVolumeComparisonQuery(t *testing.T) {
+	t.Parallel()
+
+	end := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	params := VolumeComparisonParams{
+		TimestampField: "timestamp",
+		SeverityField:  "severity_text",
+		Start:          end.Add(-time.Hour),
+		End:            end,
+		Offset:         24 * time.Hour,
+		LogchefQL:      `service_name="api"`,
+	}
+	query, args := buildVolumeComparisonQuery("logs", "app", params)
+	for _, want := range []string{
+		"`timestamp` >= toDateTime(?, 'UTC') AS __current",
+		"lower(toString(`severity_text`)) IN ('error', 'err', 'fatal'",
+		"FROM `logs`.`app`",
+		"PREWHERE (`timestamp` BETWEEN toDateTime(?, ?) AND toDateTime(?, ?)) OR (`timestamp` BETWEEN toDateTime(?, ?) AND toDateTime(?, ?))",
+		"WHERE 1 AND (",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query missing %q:\n%s", want, query)
+		}
+	}
+	wantArgs := []any{
+		"2026-03-01 11:00:00",
+		"2026-02-28 11:00:00", "UTC", "2026-02-28 12:00:00", "UTC",
+		"2026-03-01 11:00:00", "UTC", "2026-03-01 12:00:00", "UTC",
+	}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+
+	params.SeverityField = ""
+	if query, _ := buildVolumeComparisonQuery("logs", "app", params); !strings.Contains(query, "0 AS __error") {
+		t.Errorf("without a severity field errors should not be counted:\n%s", query)
+	}
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
 	"log"
 	"net"
+	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -12,6 +15,9 @@ import (
 	"github.com/knadh/koanf/providers/env"
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/v2"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/mr-karan/logchef/pkg/models"
 )
 
 // Config represents the application configuration
@@ -20,6 +26,7 @@ type Config struct {
 	Database       DatabaseConfig       `koanf:"database"`
 	SQLite         SQLiteConfig         `koanf:"sqlite"`
 	Postgres       PostgresConfig       `koanf:"postgres"`
+	Secrets        SecretsConfig        `koanf:"secrets"`
 	Clickhouse     ClickhouseConfig     `koanf:"clickhouse"`
 	OIDC           OIDCConfig           `koanf:"oidc"`
 	Auth           AuthConfig           `koanf:"auth"`
@@ -28,11 +35,111 @@ type Config struct {
 	Alerts         AlertsConfig         `koanf:"alerts"`
 	Query          QueryConfig          `koanf:"query"`
 	Export         ExportConfig         `koanf:"export"`
+	Storage        StorageConfig        `koanf:"storage"`
 	Tail           TailConfig           `koanf:"tail"`
 	Shares         SharesConfig         `koanf:"shares"`
 	RateLimit      RateLimitConfig      `koanf:"rate_limit"`
 	DashboardCache DashboardCacheConfig `koanf:"dashboard_cache"`
+	Rollups        RollupsConfig        `koanf:"rollups"`
+	SLOs           SLOsConfig           `koanf:"slos"`
+	SourceStats    SourceStatsConfig    `koanf:"source_stats"`
+	SchemaDrift    SchemaDriftConfig    `koanf:"schema_drift"`
+	SourceAlerts   SourceAlertsConfig   `koanf:"source_alerts"`
+	Webhooks       WebhooksConfig       `koanf:"webhooks"`
+	QueryHistory   QueryHistoryConfig   `koanf:"query_history"`
+	QueryAnalytics QueryAnalyticsConfig `koanf:"query_analytics"`
 	Provisioning   ProvisioningConfig   `koanf:"provisioning"`
+	SCIM           SCIMConfig           `koanf:"scim"`
+	Metrics        MetricsConfig        `koanf:"metrics"`
+	Tracing        TracingConfig        `koanf:"tracing"`
+	Ingest         IngestConfig         `koanf:"ingest"`
+}
+
+// IngestConfig controls log ingestion: the HTTP push endpoint, which inserts
+// NDJSON and OTLP-JSON batches into a source's ClickHouse table, and the
+// syslog listeners.
+type IngestConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// MaxBodyBytes caps a batch after gzip decompression, at most 64 MiB.
+	// The server's request body limit is raised to match when it is above
+	// the 4 MiB default; other routes keep the default.
+	MaxBodyBytes int `koanf:"max_body_bytes"`
+	// MaxRows caps the rows in one batch.
+	MaxRows int `koanf:"max_rows"`
+	// WaitForAsyncInsert makes a push return only once ClickHouse has flushed
+	// the rows, so a 2xx means the batch is stored. Without it ClickHouse
+	// acknowledges as soon as the rows are buffered.
+	WaitForAsyncInsert bool `koanf:"wait_for_async_insert"`
+	// Syslog listeners and the OTLP receiver run independently of Enabled,
+	// which only gates the HTTP push endpoint. MaxBodyBytes and MaxRows apply
+	// to OTLP requests as well.
+	Syslog SyslogConfig       `koanf:"syslog"`
+	OTLP   OTLPReceiverConfig `koanf:"otlp"`
+}
+
+// OTLPReceiverConfig controls the OTLP/HTTP logs receiver. Rows from
+// concurrent requests for a source are merged into one insert of up to
+// MaxBatchRows, at least every FlushInterval. Requests are rejected with 429
+// while a source has MaxPendingRows rows waiting.
+type OTLPReceiverConfig struct {
+	Enabled        bool          `koanf:"enabled"`
+	MaxBatchRows   int           `koanf:"max_batch_rows"`
+	FlushInterval  time.Duration `koanf:"flush_interval"`
+	MaxPendingRows int           `koanf:"max_pending_rows"`
+}
+
+// SyslogConfig configures the syslog listeners. Messages are buffered per
+// listener and inserted in batches of up to BatchSize rows, at least every
+// FlushInterval.
+type SyslogConfig struct {
+	Listeners       []SyslogListenerConfig `koanf:"listeners"`
+	BatchSize       int                    `koanf:"batch_size"`
+	FlushInterval   time.Duration          `koanf:"flush_interval"`
+	MaxMessageBytes int                    `koanf:"max_message_bytes"`
+}
+
+// SyslogListenerConfig binds a UDP and/or TCP syslog listener to a source. The
+// source must be a ClickHouse source whose table LogChef created, so its
+// columns follow the default OTel logs schema.
+type SyslogListenerConfig struct {
+	SourceID   int    `koanf:"source_id"`
+	UDPAddress string `koanf:"udp_address"`
+	TCPAddress string `koanf:"tcp_address"`
+}
+
+// TracingConfig controls OpenTelemetry tracing of the query pipeline. Spans
+// are sent as OTLP/HTTP JSON to Endpoint + "/v1/traces".
+type TracingConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Endpoint is the collector's OTLP/HTTP base URL, e.g.
+	// http://otel-collector:4318.
+	Endpoint string `koanf:"endpoint"`
+	// Headers are sent with every export, e.g. for collector auth.
+	Headers     map[string]string `koanf:"headers"`
+	ServiceName string            `koanf:"service_name"`
+	// SampleRatio is the fraction of new traces recorded, 0 to 1. Requests
+	// that arrive with a traceparent follow the caller's sampling decision.
+	SampleRatio float64 `koanf:"sample_ratio"`
+}
+
+// MetricsConfig controls the Prometheus /metrics endpoint. It is served
+// unauthenticated unless Token is set, in which case scrapers must send it as
+// a bearer token.
+type MetricsConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Token is the static bearer token scrapers present; supply it via
+	// LOGCHEF_METRICS__TOKEN.
+	Token string `koanf:"token"`
+}
+
+// SCIMConfig enables the SCIM 2.0 endpoints under /scim/v2, through which
+// an identity provider creates, updates and deactivates users and manages
+// team membership (SCIM Groups map to teams).
+type SCIMConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Token is the static bearer token the IdP presents. It grants full
+	// control over users and teams; supply it via LOGCHEF_SCIM__TOKEN.
+	Token string `koanf:"token"`
 }
 
 // DashboardCacheConfig controls the per-dashboard server-side result cache, a
@@ -58,10 +165,164 @@ type DashboardCacheConfig struct {
 	MaxConcurrentFills int `koanf:"max_concurrent_fills"`
 }
 
-// RateLimitConfig controls fixed-window request rate limiting for the
-// unauthenticated auth/token endpoints (per client IP, plus an optional global
-// cap) and the authenticated query endpoints (per user). Limiting is skipped
-// entirely when Enabled is false.
+// RollupsConfig controls the hourly rollup pipeline. Sources opt in
+// individually; for those, the scheduler materialises per-hour log counts (by
+// severity and one configured dimension) into a small ClickHouse table, and
+// trend requests spanning more than MinRangeDays are answered from it instead
+// of scanning raw logs. The scheduler is skipped entirely when Enabled is false;
+// trends then always read raw logs.
+type RollupsConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Interval is how often the scheduler looks for newly completed hours.
+	Interval time.Duration `koanf:"interval"`
+	// SettleDelay holds an hour back until it ended at least this long ago, so
+	// rows that arrive late are still counted.
+	SettleDelay time.Duration `koanf:"settle_delay"`
+	// MinRangeDays is the trend range (in days) above which rollups are used.
+	MinRangeDays int `koanf:"min_range_days"`
+	// BackfillDays is how far back a newly enabled rollup starts.
+	BackfillDays int `koanf:"backfill_days"`
+	// MaxHoursPerRun bounds how many hours one scheduler pass rolls up per
+	// source, so a backfill catches up gradually instead of in one huge scan.
+	MaxHoursPerRun int `koanf:"max_hours_per_run"`
+}
+
+// SLOsConfig controls the SLO evaluator. Every Interval it counts each SLO's
+// good and total logs over the SLO's window on the source backend and records
+// compliance and error-budget burn. SLOs keep their definitions but stop being
+// evaluated when Enabled is false.
+type SLOsConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Interval is how often every SLO is evaluated.
+	Interval time.Duration `koanf:"interval"`
+	// HistoryLimit is how many evaluations are kept per SLO; older ones are
+	// pruned after each run.
+	HistoryLimit int `koanf:"history_limit"`
+}
+
+// SourceStatsConfig controls the source stats scheduler. Every Interval it
+// records each ClickHouse source's table size, row count and per-column
+// compression as that day's snapshot, and prunes snapshots older than
+// RetentionDays. No snapshots are taken when Enabled is false.
+type SourceStatsConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Interval is how often snapshots are taken. Snapshots are kept per day, so
+	// a shorter interval only refreshes the current day's snapshot.
+	Interval time.Duration `koanf:"interval"`
+	// RetentionDays is how many days of snapshots are kept.
+	RetentionDays int `koanf:"retention_days"`
+}
+
+// SchemaDriftConfig controls the schema drift scheduler. Every Interval it
+// reads each ClickHouse source's columns, records a snapshot when they differ
+// from the last one (added, dropped or retyped columns) and notifies
+// WebhookURLs and SlackURLs of the change. Snapshots older than RetentionDays are pruned, but
+// each source keeps its latest as the baseline for the next comparison.
+type SchemaDriftConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Interval is how often every source's schema is compared.
+	Interval time.Duration `koanf:"interval"`
+	// RetentionDays is how many days of schema history are kept.
+	RetentionDays int `koanf:"retention_days"`
+	// WebhookURLs receive the alert webhook JSON payload on each change.
+	WebhookURLs []string `koanf:"webhook_urls"`
+	// SlackURLs are Slack incoming-webhook URLs notified on each change.
+	SlackURLs []string `koanf:"slack_urls"`
+}
+
+// Channels returns the configured notification destinations as alert channels.
+func (c SchemaDriftConfig) Channels() []models.AlertChannel {
+	return alertChannels(c.WebhookURLs, c.SlackURLs)
+}
+
+// SourceAlertsConfig controls source degradation alerts, which are evaluated
+// after every source health probe cycle. A source whose last FailureThreshold
+// probes failed is reported unreachable; one where at least ErrorRate of its
+// queries failed over ErrorRateWindow, out of at least MinQueries, is reported
+// as failing queries. Each alert and its recovery is recorded and sent to
+// WebhookURLs and SlackURLs. Nothing is evaluated when Enabled is false.
+type SourceAlertsConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// FailureThreshold is how many consecutive failed probes make a source
+	// unreachable.
+	FailureThreshold int `koanf:"failure_threshold"`
+	// ErrorRate is the fraction of failed queries, above 0 and at most 1,
+	// that raises an error rate alert.
+	ErrorRate float64 `koanf:"error_rate"`
+	// ErrorRateWindow is how far back queries are counted.
+	ErrorRateWindow time.Duration `koanf:"error_rate_window"`
+	// MinQueries is the fewest queries in the window for the error rate to
+	// be judged, so a couple of failures on a quiet source don't alert.
+	MinQueries int `koanf:"min_queries"`
+	// RetentionDays is how many days of alert events are kept.
+	RetentionDays int `koanf:"retention_days"`
+	// WebhookURLs receive the alert webhook JSON payload for each event.
+	WebhookURLs []string `koanf:"webhook_urls"`
+	// SlackURLs are Slack incoming-webhook URLs notified of each event.
+	SlackURLs []string `koanf:"slack_urls"`
+}
+
+// Channels returns the configured notification destinations as alert channels.
+func (c SourceAlertsConfig) Channels() []models.AlertChannel {
+	return alertChannels(c.WebhookURLs, c.SlackURLs)
+}
+
+// WebhooksConfig controls delivery of lifecycle events to the outbound
+// webhooks admins register. A delivery that fails is retried with exponential
+// backoff up to MaxAttempts times. Nothing is delivered when Enabled is false.
+type WebhooksConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Timeout bounds one delivery attempt.
+	Timeout time.Duration `koanf:"timeout"`
+	// MaxAttempts is how many times a delivery is tried before it is dropped.
+	MaxAttempts int `koanf:"max_attempts"`
+	// QueueSize is how many events may wait for delivery before new ones are
+	// dropped.
+	QueueSize int `koanf:"queue_size"`
+	// Workers is how many deliveries run at once. Once all are busy, events
+	// wait in the queue.
+	Workers int `koanf:"workers"`
+}
+
+func alertChannels(webhookURLs, slackURLs []string) []models.AlertChannel {
+	channels := make([]models.AlertChannel, 0, len(webhookURLs)+len(slackURLs))
+	for _, u := range webhookURLs {
+		channels = append(channels, models.AlertChannel{Type: models.AlertChannelWebhook, URL: u})
+	}
+	for _, u := range slackURLs {
+		channels = append(channels, models.AlertChannel{Type: models.AlertChannelSlack, URL: u})
+	}
+	return channels
+}
+
+// QueryHistoryConfig controls how much executed-query history is kept.
+type QueryHistoryConfig struct {
+	// MaxPerUser caps each user's history; older entries are pruned as new
+	// ones are recorded.
+	MaxPerUser int `koanf:"max_per_user"`
+	// Retention drops entries older than this. Zero keeps them until the
+	// per-user cap pushes them out.
+	Retention time.Duration `koanf:"retention"`
+}
+
+// QueryAnalyticsConfig controls the query usage report built from query
+// history. Every Interval the report over the last WindowDays is rebuilt and
+// published as Prometheus gauges; the admin endpoint builds its own on demand.
+// No gauges are published when Enabled is false.
+type QueryAnalyticsConfig struct {
+	Enabled    bool          `koanf:"enabled"`
+	Interval   time.Duration `koanf:"interval"`
+	WindowDays int           `koanf:"window_days"`
+	// SlowQueryThreshold is the duration at which a query counts as slow.
+	SlowQueryThreshold time.Duration `koanf:"slow_query_threshold"`
+	// TopN caps the users, sources, slow queries and fields in a report.
+	TopN int `koanf:"top_n"`
+}
+
+// RateLimitConfig controls request rate limiting for the unauthenticated
+// auth/token endpoints (fixed window per client IP, plus an optional global
+// cap) and the authenticated query endpoints (token buckets per user and per
+// team). Limiting is skipped entirely when Enabled is false.
 type RateLimitConfig struct {
 	Enabled bool `koanf:"enabled"`
 	// AuthPerIPPerMinute caps requests per client IP per minute on the
@@ -70,9 +331,20 @@ type RateLimitConfig struct {
 	// AuthGlobalPerMinute caps total requests per minute across all clients on
 	// the auth/token endpoints. 0 disables the global cap.
 	AuthGlobalPerMinute int `koanf:"auth_global_per_minute"`
-	// QueryPerUserPerMinute caps query-endpoint requests per authenticated user
-	// per minute.
+	// QueryPerUserPerMinute is the sustained query-endpoint request rate per
+	// authenticated user.
 	QueryPerUserPerMinute int `koanf:"query_per_user_per_minute"`
+	// QueryPerUserBurst is how many query requests a user may make back to
+	// back. 0 uses QueryPerUserPerMinute.
+	QueryPerUserBurst int `koanf:"query_per_user_burst"`
+	// QueryPerTeamPerMinute is the sustained query-endpoint request rate shared
+	// by all members of a team. 0 disables the team limit.
+	QueryPerTeamPerMinute int `koanf:"query_per_team_per_minute"`
+	// QueryPerTeamBurst is the team bucket size. 0 uses QueryPerTeamPerMinute.
+	QueryPerTeamBurst int `koanf:"query_per_team_burst"`
+	// QueryMaxWait is how long a query request over its rate is held for the
+	// next token before being rejected with 429. 0 rejects immediately.
+	QueryMaxWait time.Duration `koanf:"query_max_wait"`
 }
 
 // QueryConfig contains settings for query execution
@@ -91,8 +363,110 @@ type QueryConfig struct {
 	MaxTimeoutSeconds int `koanf:"max_timeout_seconds"`
 	// MaxConcurrentPerUser limits active preview queries per user.
 	MaxConcurrentPerUser int `koanf:"max_concurrent_per_user"`
+	// MaxConcurrentPerTeam limits active preview queries per team. 0 is
+	// unlimited.
+	MaxConcurrentPerTeam int `koanf:"max_concurrent_per_team"`
 	// MaxConcurrentGlobal limits active preview queries globally.
 	MaxConcurrentGlobal int `koanf:"max_concurrent_global"`
+	// PersistActiveAfter stores queries running longer than this in the
+	// metadata store, so the next start of this instance can kill what a
+	// crash or restart left running on ClickHouse. 0 disables persistence.
+	PersistActiveAfter time.Duration `koanf:"persist_active_after"`
+	// RequireTimeBound makes ClickHouse queries on a source's table filter on
+	// its timestamp column. A query without such a condition gets the
+	// requested time range ANDed in, or is rejected when there is none.
+	RequireTimeBound bool `koanf:"require_time_bound"`
+	// Cost caps how much data ClickHouse SQL queries may read.
+	Cost QueryCostConfig `koanf:"cost"`
+	// Quota caps how much each team may query per day.
+	Quota QueryQuotaConfig `koanf:"quota"`
+}
+
+// QueryCostConfig caps the rows and bytes a ClickHouse SQL query may read. Zero
+// caps are unlimited. Teams entries override the caps for one team.
+type QueryCostConfig struct {
+	// Explain rejects queries whose EXPLAIN ESTIMATE exceeds a cap before they run.
+	Explain bool `koanf:"explain"`
+	// Enforce sends the caps as max_rows_to_read / max_bytes_to_read so
+	// ClickHouse aborts a query that reads past them.
+	Enforce        bool                  `koanf:"enforce"`
+	MaxRowsToRead  uint64                `koanf:"max_rows_to_read"`
+	MaxBytesToRead uint64                `koanf:"max_bytes_to_read"`
+	Teams          []TeamQueryCostConfig `koanf:"teams"`
+}
+
+// TeamQueryCostConfig overrides the query cost caps for one team. Zero caps
+// keep the global value.
+type TeamQueryCostConfig struct {
+	TeamID         int    `koanf:"team_id"`
+	MaxRowsToRead  uint64 `koanf:"max_rows_to_read"`
+	MaxBytesToRead uint64 `koanf:"max_bytes_to_read"`
+}
+
+// LimitsForTeam resolves the query cost limits for a team. It returns nil when
+// the guardrail is off or nothing is capped.
+func (c QueryCostConfig) LimitsForTeam(teamID models.TeamID) *models.QueryCostLimits {
+	if !c.Explain && !c.Enforce {
+		return nil
+	}
+	limits := &models.QueryCostLimits{
+		MaxRowsToRead:  c.MaxRowsToRead,
+		MaxBytesToRead: c.MaxBytesToRead,
+		Explain:        c.Explain,
+		Enforce:        c.Enforce,
+	}
+	for _, team := range c.Teams {
+		if models.TeamID(team.TeamID) != teamID {
+			continue
+		}
+		if team.MaxRowsToRead > 0 {
+			limits.MaxRowsToRead = team.MaxRowsToRead
+		}
+		if team.MaxBytesToRead > 0 {
+			limits.MaxBytesToRead = team.MaxBytesToRead
+		}
+	}
+	if limits.IsZero() {
+		return nil
+	}
+	return limits
+}
+
+// QueryQuotaConfig caps the queries a team may run and the rows they may read
+// per UTC day. Zero caps are unlimited. Teams entries override the caps for
+// one team.
+type QueryQuotaConfig struct {
+	MaxQueriesPerDay  int64                  `koanf:"max_queries_per_day"`
+	MaxRowsReadPerDay int64                  `koanf:"max_rows_read_per_day"`
+	Teams             []TeamQueryQuotaConfig `koanf:"teams"`
+}
+
+// TeamQueryQuotaConfig overrides the daily quota for one team. Zero caps keep
+// the global value.
+type TeamQueryQuotaConfig struct {
+	TeamID            int   `koanf:"team_id"`
+	MaxQueriesPerDay  int64 `koanf:"max_queries_per_day"`
+	MaxRowsReadPerDay int64 `koanf:"max_rows_read_per_day"`
+}
+
+// QuotaForTeam resolves the daily quota for a team.
+func (c QueryQuotaConfig) QuotaForTeam(teamID models.TeamID) models.TeamQuota {
+	quota := models.TeamQuota{
+		MaxQueriesPerDay:  c.MaxQueriesPerDay,
+		MaxRowsReadPerDay: c.MaxRowsReadPerDay,
+	}
+	for _, team := range c.Teams {
+		if models.TeamID(team.TeamID) != teamID {
+			continue
+		}
+		if team.MaxQueriesPerDay > 0 {
+			quota.MaxQueriesPerDay = team.MaxQueriesPerDay
+		}
+		if team.MaxRowsReadPerDay > 0 {
+			quota.MaxRowsReadPerDay = team.MaxRowsReadPerDay
+		}
+	}
+	return quota
 }
 
 // ExportConfig contains settings for streaming result exports.
@@ -106,6 +480,41 @@ type ExportConfig struct {
 	Formats               []string      `koanf:"formats"`
 }
 
+// StorageConfig selects where large generated artifacts (export results,
+// notebook snapshots) are kept, so they stay out of the metadata database.
+type StorageConfig struct {
+	// Backend is "local" (default) or "s3".
+	Backend string `koanf:"backend"`
+	// LocalDir is the artifact directory for the local backend. Defaults to
+	// an "artifacts" directory next to the SQLite database.
+	LocalDir string `koanf:"local_dir"`
+	// SignedURLTTL is how long signed download URLs stay valid. Only the s3
+	// backend issues them; local artifacts are streamed through the API.
+	SignedURLTTL time.Duration `koanf:"signed_url_ttl"`
+	// SnapshotTTL is how long stored notebook snapshots are kept.
+	SnapshotTTL time.Duration   `koanf:"snapshot_ttl"`
+	S3          S3StorageConfig `koanf:"s3"`
+}
+
+// S3StorageConfig points the s3 storage backend at a bucket on AWS S3 or an
+// S3-compatible service (MinIO, R2, ...).
+type S3StorageConfig struct {
+	Bucket string `koanf:"bucket"`
+	Region string `koanf:"region"`
+	// Endpoint overrides the AWS endpoint for S3-compatible services,
+	// e.g. "http://minio:9000".
+	Endpoint string `koanf:"endpoint"`
+	// Prefix is prepended to every object key, e.g. "logchef/".
+	Prefix string `koanf:"prefix"`
+	// PathStyle addresses objects as endpoint/bucket/key rather than
+	// bucket.endpoint/key. Most S3-compatible services need it.
+	PathStyle bool `koanf:"path_style"`
+	// AccessKey and SecretKey are static credentials. When empty, the
+	// standard AWS credential chain (env, shared config, instance role) is used.
+	AccessKey string `koanf:"access_key"`
+	SecretKey string `koanf:"secret_key"`
+}
+
 // TailConfig contains settings for live log tailing (SSE streams).
 type TailConfig struct {
 	// PollInterval is the ClickHouse poll cadence; VictoriaLogs streams natively.
@@ -136,6 +545,8 @@ type TailConfig struct {
 type SharesConfig struct {
 	DefaultTTL        time.Duration `koanf:"default_ttl"`
 	MaxQueryTextBytes int           `koanf:"max_query_text_bytes"`
+	// AllowPermanent lets users create share links that never expire.
+	AllowPermanent bool `koanf:"allow_permanent"`
 }
 
 // ServerConfig contains HTTP server settings
@@ -155,6 +566,9 @@ type ServerConfig struct {
 	// ProxyHeader is the forwarding header read for the client IP when the
 	// direct peer is a trusted proxy. Defaults to X-Forwarded-For.
 	ProxyHeader string `koanf:"proxy_header"`
+	// DrainTimeout bounds how long shutdown waits for running queries to
+	// finish before cancelling them. Defaults to 30s; 0 cancels them at once.
+	DrainTimeout time.Duration `koanf:"drain_timeout"`
 }
 
 // IsSecureCookie returns whether cookies should have the Secure flag set.
@@ -188,6 +602,35 @@ type PostgresConfig struct {
 	ConnMaxLifetime time.Duration `koanf:"conn_max_lifetime"`
 }
 
+// SecretsConfig controls encryption of source connection secrets (passwords,
+// TLS client keys, tokens) at rest in the metadata store, and how secret
+// references in their place are resolved.
+type SecretsConfig struct {
+	// EncryptionKey is a base64-encoded 32-byte key. Empty stores secrets in
+	// plaintext. Prefer LOGCHEF_SECRETS__ENCRYPTION_KEY to the config file.
+	EncryptionKey string `koanf:"encryption_key"`
+	// PreviousKeys still open secrets sealed before a key rotation, until
+	// "logchef admin secrets encrypt" re-seals them under EncryptionKey.
+	PreviousKeys []string `koanf:"previous_keys"`
+	// CacheTTL is how long a resolved secret reference (env:, file:, vault:)
+	// is reused before it is read again. Zero reads it on every connection.
+	CacheTTL time.Duration `koanf:"cache_ttl"`
+	// Vault is the HashiCorp Vault server vault: references are read from.
+	Vault VaultConfig `koanf:"vault"`
+}
+
+// VaultConfig points vault: secret references at a Vault KV secrets engine.
+type VaultConfig struct {
+	// Address is the Vault server URL, e.g. https://vault.example.com:8200.
+	Address string `koanf:"address"`
+	// Token authenticates to Vault. Prefer LOGCHEF_SECRETS__VAULT__TOKEN.
+	Token string `koanf:"token"`
+	// Namespace is sent as X-Vault-Namespace (Vault Enterprise).
+	Namespace string `koanf:"namespace"`
+	// KVVersion is the KV secrets engine version, 1 or 2.
+	KVVersion int `koanf:"kv_version"`
+}
+
 // ClickhouseConfig contains Clickhouse database settings
 type ClickhouseConfig struct {
 	Host     string `koanf:"host"`
@@ -243,6 +686,37 @@ type AuthConfig struct {
 	// AutoProvision enables just-in-time user creation on first OIDC login
 	// from an allowed company domain, instead of failing with "user not found".
 	AutoProvision AutoProvisionConfig `koanf:"auto_provision"`
+	// GroupSync reconciles team memberships from the OIDC groups claim on
+	// every OIDC login.
+	GroupSync GroupSyncConfig `koanf:"group_sync"`
+}
+
+// GroupSyncConfig maps IdP groups to Logchef teams. On each OIDC login the
+// user's memberships in every mapped team are brought in line with their
+// groups: matching teams are joined (or the role updated), and mapped teams
+// with no matching group are left. Teams that appear in no mapping are never
+// touched, so hand-managed memberships elsewhere are kept.
+type GroupSyncConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Claim names the ID token claim holding the user's groups, as a list of
+	// strings or a single string. A dotted path reaches nested claims
+	// (e.g. "realm_access.roles"). Default: "groups".
+	Claim string `koanf:"claim"`
+	// KeepUnmatched skips the removal half of the sync: users keep mapped
+	// team memberships after leaving the matching group.
+	KeepUnmatched bool `koanf:"keep_unmatched"`
+	// Mappings grant a team role to members of a group. When several of a
+	// user's groups map to the same team, the highest role wins.
+	Mappings []GroupTeamMapping `koanf:"mappings"`
+}
+
+// GroupTeamMapping grants Role in Team to members of Group.
+type GroupTeamMapping struct {
+	Group string `koanf:"group"`
+	// Team is the team name. A team that doesn't exist is logged and skipped.
+	Team string `koanf:"team"`
+	// Role is the team role: admin, editor, member (default) or viewer.
+	Role string `koanf:"role"`
 }
 
 // AutoProvisionConfig controls JIT (just-in-time) user provisioning on first
@@ -306,6 +780,13 @@ type AlertsConfig struct {
 	EvaluationInterval time.Duration `koanf:"evaluation_interval"`
 	DefaultLookback    time.Duration `koanf:"default_lookback"`
 	HistoryLimit       int           `koanf:"history_limit"`
+	// GroupInterval batches notifications for the same webhook or Slack
+	// destination (room) and sends them together at this interval. 0 sends
+	// each notification immediately.
+	GroupInterval time.Duration `koanf:"group_interval"`
+	// RoomMaxPerHour caps the grouped payloads one room receives per hour;
+	// 0 = no limit. Only applies with GroupInterval set.
+	RoomMaxPerHour int `koanf:"room_max_per_hour"`
 }
 
 const (
@@ -314,6 +795,9 @@ const (
 	defaultServerPort         = 8125
 	defaultServerHost         = "0.0.0.0"
 	defaultHTTPServerTimeout  = 15 * time.Minute
+	defaultServerDrainTimeout = 30 * time.Second
+	defaultSecretsCacheTTL    = 5 * time.Minute
+	defaultVaultKVVersion     = 2
 	defaultServerSecureCookie = true
 	defaultDatabaseDriver     = "sqlite"
 	defaultSQLitePath         = "local.db"
@@ -352,6 +836,10 @@ const (
 	defaultExportMaxConcurrentPerUser = 1
 	defaultExportMaxConcurrentGlobal  = 5
 	defaultExportArtifactTTL          = 24 * time.Hour
+	defaultStorageBackend             = "local"
+	defaultStorageLocalDirName        = "artifacts"
+	defaultStorageSignedURLTTL        = 15 * time.Minute
+	defaultStorageSnapshotTTL         = 30 * 24 * time.Hour
 
 	defaultTailPollInterval   = 2 * time.Second
 	defaultTailMaxPerUser     = 2
@@ -371,6 +859,24 @@ const (
 	defaultRateLimitAuthPerIPPerMinute    = 20
 	defaultRateLimitAuthGlobalPerMinute   = 300
 	defaultRateLimitQueryPerUserPerMinute = 120
+	defaultRateLimitQueryPerTeamPerMinute = 600
+	defaultRateLimitQueryMaxWait          = 2 * time.Second
+
+	defaultIngestMaxBodyBytes = 4 * 1024 * 1024
+	maxIngestBodyBytes        = 64 * 1024 * 1024
+	defaultIngestMaxRows      = 10000
+
+	defaultSyslogBatchSize       = 1000
+	defaultSyslogFlushInterval   = time.Second
+	defaultSyslogMaxMessageBytes = 64 * 1024
+
+	defaultOTLPMaxBatchRows   = 5000
+	defaultOTLPFlushInterval  = 200 * time.Millisecond
+	defaultOTLPMaxPendingRows = 100000
+
+	defaultTracingEndpoint    = "http://localhost:4318"
+	defaultTracingServiceName = "logchef"
+	defaultTracingSampleRatio = 1.0
 
 	defaultDashboardCacheEnabled            = true
 	defaultDashboardCacheDefaultTTL         = 10 * time.Minute
@@ -380,6 +886,45 @@ const (
 	defaultDashboardCacheMaxEntries         = 1024
 	defaultDashboardCacheMaxConcurrentFills = 8
 
+	defaultRollupsEnabled        = true
+	defaultRollupsInterval       = 5 * time.Minute
+	defaultRollupsSettleDelay    = 15 * time.Minute
+	defaultRollupsMinRangeDays   = 7
+	defaultRollupsBackfillDays   = 90
+	defaultRollupsMaxHoursPerRun = 168
+
+	defaultSLOsEnabled      = true
+	defaultSLOsInterval     = 5 * time.Minute
+	defaultSLOsHistoryLimit = 2016 // one week at the default interval
+
+	defaultSourceStatsEnabled       = true
+	defaultSourceStatsInterval      = 24 * time.Hour
+	defaultSourceStatsRetentionDays = 365
+
+	defaultSchemaDriftEnabled       = true
+	defaultSchemaDriftInterval      = time.Hour
+	defaultSchemaDriftRetentionDays = 90
+
+	defaultSourceAlertsFailureThreshold = 3
+	defaultSourceAlertsErrorRate        = 0.5
+	defaultSourceAlertsErrorRateWindow  = 5 * time.Minute
+	defaultSourceAlertsMinQueries       = 10
+	defaultSourceAlertsRetentionDays    = 30
+
+	defaultWebhooksEnabled     = true
+	defaultWebhooksTimeout     = 10 * time.Second
+	defaultWebhooksMaxAttempts = 5
+	defaultWebhooksQueueSize   = 1000
+	defaultWebhooksWorkers     = 4
+
+	defaultQueryHistoryMaxPerUser = 200
+
+	defaultQueryAnalyticsEnabled            = true
+	defaultQueryAnalyticsInterval           = 5 * time.Minute
+	defaultQueryAnalyticsWindowDays         = 7
+	defaultQueryAnalyticsSlowQueryThreshold = 10 * time.Second
+	defaultQueryAnalyticsTopN               = 10
+
 	defaultProxyHeader = "X-Forwarded-For"
 )
 
@@ -387,6 +932,8 @@ var defaultExportFormats = []string{"csv", "ndjson"}
 
 var defaultOIDCScopes = []string{"openid", "email", "profile"}
 
+const defaultGroupSyncClaim = "groups"
+
 // Load loads the configuration from a file and environment variables.
 // Environment variables with the prefix LOGCHEF_ can override file values.
 // E.g., LOGCHEF_SERVER__PORT will override server.port
@@ -424,9 +971,19 @@ func Load(path string) (*Config, error) {
 
 	// Load separate provisioning file if specified.
 	if cfg.Provisioning.File != "" {
+		dir := cfg.Provisioning.Dir
 		if err := loadProvisioningFile(&cfg, path); err != nil {
 			return nil, err
 		}
+		if cfg.Provisioning.Dir == "" {
+			cfg.Provisioning.Dir = dir
+		}
+	}
+	// Add the resources declared in the provisioning directory.
+	if cfg.Provisioning.Dir != "" {
+		if err := loadProvisioningDir(&cfg, path); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := validateConfig(&cfg); err != nil {
@@ -459,8 +1016,108 @@ func loadProvisioningFile(cfg *Config, mainConfigPath string) error {
 	return nil
 }
 
-// validateConfig checks the required/interdependent configuration fields
-// once defaults and provisioning have been applied.
+// provisioningResources is what a file in the provisioning directory declares.
+type provisioningResources struct {
+	Sources []ProvisionSource `koanf:"sources"`
+	Teams   []ProvisionTeam   `koanf:"teams"`
+	Alerts  []ProvisionAlert  `koanf:"alerts"`
+}
+
+// loadProvisioningDir reads every YAML and TOML file in cfg.Provisioning.Dir
+// (resolved relative to the main config file's directory), in name order, and
+// appends the resources they declare to cfg.Provisioning.
+func loadProvisioningDir(cfg *Config, mainConfigPath string) error {
+	dir := cfg.Provisioning.Dir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(mainConfigPath), dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("error reading provisioning directory %q: %w", dir, err)
+	}
+	for _, entry := range entries { // ReadDir sorts by name.
+		if entry.IsDir() {
+			continue
+		}
+		var parser koanf.Parser
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml":
+			parser = yamlParser{}
+		case ".toml":
+			parser = toml.Parser()
+		default:
+			continue
+		}
+		filePath := filepath.Join(dir, entry.Name())
+		pk := koanf.New(".")
+		if err := pk.Load(file.Provider(filePath), parser); err != nil {
+			return fmt.Errorf("error loading provisioning file %q: %w", filePath, err)
+		}
+		var res provisioningResources
+		if err := pk.Unmarshal("", &res); err != nil {
+			return fmt.Errorf("error parsing provisioning file %q: %w", filePath, err)
+		}
+		cfg.Provisioning.Sources = append(cfg.Provisioning.Sources, res.Sources...)
+		cfg.Provisioning.Teams = append(cfg.Provisioning.Teams, res.Teams...)
+		cfg.Provisioning.Alerts = append(cfg.Provisioning.Alerts, res.Alerts...)
+		log.Printf("loaded provisioning file: %s", filePath)
+	}
+	cfg.Provisioning.Dir = dir
+	return nil
+}
+
+// yamlParser is a koanf.Parser for YAML provisioning files.
+type yamlParser struct{}
+
+func (yamlParser) Unmarshal(b []byte) (map[string]any, error) {
+	var out map[string]any
+	if err := yaml.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (yamlParser) Marshal(o map[string]any) ([]byte, error) {
+	return yaml.Marshal(o)
+}
+
+// validateSecrets checks the encryption keys are base64-encoded 32-byte keys
+// and the secret reference settings are usable.
+func validateSecrets(cfg SecretsConfig) error {
+	if cfg.EncryptionKey == "" && len(cfg.PreviousKeys) > 0 {
+		return fmt.Errorf("secrets.previous_keys requires secrets.encryption_key")
+	}
+	check := func(name, encoded string) error {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(key) != 32 {
+			return fmt.Errorf("%s must be a base64-encoded 32-byte key", name)
+		}
+		return nil
+	}
+	if cfg.EncryptionKey != "" {
+		if err := check("secrets.encryption_key", cfg.EncryptionKey); err != nil {
+			return err
+		}
+	}
+	for i, key := range cfg.PreviousKeys {
+		if err := check(fmt.Sprintf("secrets.previous_keys[%d]", i), key); err != nil {
+			return err
+		}
+	}
+	if cfg.CacheTTL < 0 {
+		return fmt.Errorf("secrets.cache_ttl must not be negative")
+	}
+	if cfg.Vault.KVVersion != 1 && cfg.Vault.KVVersion != 2 {
+		return fmt.Errorf("secrets.vault.kv_version must be 1 or 2")
+	}
+	if cfg.Vault.Address != "" {
+		if u, err := url.Parse(cfg.Vault.Address); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("secrets.vault.address must be an http(s) URL")
+		}
+	}
+	return nil
+}
+
 // validateTrustedProxies ensures each server.trusted_proxies entry is a valid IP
 // or CIDR and rejects the "trust everyone" wildcards (which would make the
 // forwarding header spoofable). Fiber only warns on a bad CIDR, so fail fast.
@@ -482,6 +1139,36 @@ func validateTrustedProxies(proxies []string) error {
 	return nil
 }
 
+// validateGroupSync checks each auth.group_sync mapping names a group, a team
+// and a known role, defaulting an empty role to "member".
+func validateGroupSync(gs *GroupSyncConfig) error {
+	if !gs.Enabled {
+		return nil
+	}
+	if strings.TrimSpace(gs.Claim) == "" {
+		return fmt.Errorf("auth.group_sync.claim must not be empty")
+	}
+	if len(gs.Mappings) == 0 {
+		return fmt.Errorf("auth.group_sync.mappings must be non-empty when auth.group_sync.enabled is true")
+	}
+	for i := range gs.Mappings {
+		m := &gs.Mappings[i]
+		if strings.TrimSpace(m.Group) == "" || strings.TrimSpace(m.Team) == "" {
+			return fmt.Errorf("auth.group_sync.mappings[%d]: group and team are required", i)
+		}
+		switch m.Role {
+		case "":
+			m.Role = "member"
+		case "admin", "editor", "member", "viewer":
+		default:
+			return fmt.Errorf("auth.group_sync.mappings[%d]: role %q must be one of admin, editor, member, viewer", i, m.Role)
+		}
+	}
+	return nil
+}
+
+// validateConfig checks the required/interdependent configuration fields
+// once defaults and provisioning have been applied.
 func validateConfig(cfg *Config) error { //nolint:gocyclo // config validation is a flat sequence of independent required-field checks
 	// Validate the metadata backend selection.
 	switch cfg.Database.Driver {
@@ -498,6 +1185,89 @@ func validateConfig(cfg *Config) error { //nolint:gocyclo // config validation i
 	if err := validateTrustedProxies(cfg.Server.TrustedProxies); err != nil {
 		return err
 	}
+	if err := validateSecrets(cfg.Secrets); err != nil {
+		return err
+	}
+	if cfg.Server.DrainTimeout < 0 {
+		return fmt.Errorf("server.drain_timeout must not be negative")
+	}
+
+	seenCostTeams := make(map[int]bool, len(cfg.Query.Cost.Teams))
+	for _, team := range cfg.Query.Cost.Teams {
+		if team.TeamID <= 0 {
+			return fmt.Errorf("query.cost.teams: team_id must be positive")
+		}
+		if seenCostTeams[team.TeamID] {
+			return fmt.Errorf("query.cost.teams: team_id %d is listed more than once", team.TeamID)
+		}
+		seenCostTeams[team.TeamID] = true
+	}
+
+	if cfg.Query.Quota.MaxQueriesPerDay < 0 || cfg.Query.Quota.MaxRowsReadPerDay < 0 {
+		return fmt.Errorf("query.quota: caps must not be negative")
+	}
+	seenQuotaTeams := make(map[int]bool, len(cfg.Query.Quota.Teams))
+	for _, team := range cfg.Query.Quota.Teams {
+		if team.TeamID <= 0 {
+			return fmt.Errorf("query.quota.teams: team_id must be positive")
+		}
+		if team.MaxQueriesPerDay < 0 || team.MaxRowsReadPerDay < 0 {
+			return fmt.Errorf("query.quota.teams: caps for team_id %d must not be negative", team.TeamID)
+		}
+		if seenQuotaTeams[team.TeamID] {
+			return fmt.Errorf("query.quota.teams: team_id %d is listed more than once", team.TeamID)
+		}
+		seenQuotaTeams[team.TeamID] = true
+	}
+
+	for i, l := range cfg.Ingest.Syslog.Listeners {
+		if l.SourceID <= 0 {
+			return fmt.Errorf("ingest.syslog.listeners[%d]: source_id must be positive", i)
+		}
+		if l.UDPAddress == "" && l.TCPAddress == "" {
+			return fmt.Errorf("ingest.syslog.listeners[%d]: set udp_address, tcp_address or both", i)
+		}
+	}
+
+	// Ingest raises the server-wide body limit to max_body_bytes, which is
+	// buffered in memory per request.
+	if cfg.Ingest.MaxBodyBytes > maxIngestBodyBytes {
+		return fmt.Errorf("ingest.max_body_bytes (%d) must not exceed %d", cfg.Ingest.MaxBodyBytes, maxIngestBodyBytes)
+	}
+
+	// A request over max_pending_rows could never be accepted.
+	if cfg.Ingest.OTLP.Enabled && cfg.Ingest.OTLP.MaxPendingRows < cfg.Ingest.MaxRows {
+		return fmt.Errorf("ingest.otlp.max_pending_rows (%d) must be at least ingest.max_rows (%d)", cfg.Ingest.OTLP.MaxPendingRows, cfg.Ingest.MaxRows)
+	}
+
+	for _, channel := range cfg.SchemaDrift.Channels() {
+		if err := channel.Validate(); err != nil {
+			return fmt.Errorf("schema_drift: %w", err)
+		}
+	}
+
+	if r := cfg.SourceAlerts.ErrorRate; r > 1 {
+		return fmt.Errorf("source_alerts.error_rate must be above 0 and at most 1, got %g", r)
+	}
+	for _, channel := range cfg.SourceAlerts.Channels() {
+		if err := channel.Validate(); err != nil {
+			return fmt.Errorf("source_alerts: %w", err)
+		}
+	}
+
+	// Validate the artifact storage backend.
+	switch cfg.Storage.Backend {
+	case "local":
+	case "s3":
+		if cfg.Storage.S3.Bucket == "" || cfg.Storage.S3.Region == "" {
+			return fmt.Errorf("storage.s3.bucket and storage.s3.region are required when storage.backend is \"s3\"")
+		}
+		if (cfg.Storage.S3.AccessKey == "") != (cfg.Storage.S3.SecretKey == "") {
+			return fmt.Errorf("storage.s3.access_key and storage.s3.secret_key must be set together")
+		}
+	default:
+		return fmt.Errorf("storage.backend must be \"local\" or \"s3\", got %q", cfg.Storage.Backend)
+	}
 
 	// Validate required configurations
 	if len(cfg.Auth.AdminEmails) == 0 {
@@ -523,6 +1293,24 @@ func validateConfig(cfg *Config) error { //nolint:gocyclo // config validation i
 		return fmt.Errorf("auth.auto_provision.allowed_domains must be non-empty when auth.auto_provision.enabled is true (either in file or %sAUTH__AUTO_PROVISION__ALLOWED_DOMAINS)", envPrefix)
 	}
 
+	if err := validateGroupSync(&cfg.Auth.GroupSync); err != nil {
+		return err
+	}
+	if cfg.SCIM.Enabled && len(cfg.SCIM.Token) < 32 {
+		return fmt.Errorf("scim.token must be at least 32 characters when scim.enabled is true (set it via %sSCIM__TOKEN)", envPrefix)
+	}
+	if cfg.Tracing.Enabled {
+		if u, err := url.Parse(cfg.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("tracing.endpoint must be an http(s) URL, got %q", cfg.Tracing.Endpoint)
+		}
+		if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+			return fmt.Errorf("tracing.sample_ratio must be between 0 and 1, got %g", cfg.Tracing.SampleRatio)
+		}
+	}
+	if cfg.Metrics.Token != "" && len(cfg.Metrics.Token) < 16 {
+		return fmt.Errorf("metrics.token must be at least 16 characters (set it via %sMETRICS__TOKEN)", envPrefix)
+	}
+
 	// Validate AI configuration: the bedrock provider needs an AWS region and an
 	// explicit model id. The default model ("gpt-4o") is only valid for OpenAI;
 	// Bedrock model ids look like "anthropic.claude-3-5-sonnet-20241022-v2:0", so
@@ -571,6 +1359,15 @@ func applyDefaults(k *koanf.Koanf, cfg *Config) { //nolint:gocyclo // config def
 	if !k.Exists("server.http_server_timeout") {
 		cfg.Server.HTTPServerTimeout = defaultHTTPServerTimeout
 	}
+	if !k.Exists("server.drain_timeout") {
+		cfg.Server.DrainTimeout = defaultServerDrainTimeout
+	}
+	if !k.Exists("secrets.cache_ttl") {
+		cfg.Secrets.CacheTTL = defaultSecretsCacheTTL
+	}
+	if !k.Exists("secrets.vault.kv_version") {
+		cfg.Secrets.Vault.KVVersion = defaultVaultKVVersion
+	}
 	if !k.Exists("server.secure_cookie") {
 		defaultVal := defaultServerSecureCookie
 		cfg.Server.SecureCookie = &defaultVal
@@ -597,6 +1394,9 @@ func applyDefaults(k *koanf.Koanf, cfg *Config) { //nolint:gocyclo // config def
 	if !k.Exists("oidc.scopes") {
 		cfg.OIDC.Scopes = append([]string(nil), defaultOIDCScopes...)
 	}
+	if !k.Exists("auth.group_sync.claim") {
+		cfg.Auth.GroupSync.Claim = defaultGroupSyncClaim
+	}
 
 	if !k.Exists("alerts.enabled") {
 		cfg.Alerts.Enabled = defaultAlertsEnabled
@@ -665,6 +1465,12 @@ func applyDefaults(k *koanf.Koanf, cfg *Config) { //nolint:gocyclo // config def
 	if !k.Exists("query.max_concurrent_global") {
 		cfg.Query.MaxConcurrentGlobal = defaultQueryMaxConcurrentGlobal
 	}
+	if cfg.Query.MaxConcurrentPerTeam < 0 {
+		cfg.Query.MaxConcurrentPerTeam = 0
+	}
+	if cfg.Query.PersistActiveAfter < 0 {
+		cfg.Query.PersistActiveAfter = 0
+	}
 	if cfg.Query.MaxLimit == 0 {
 		cfg.Query.MaxLimit = cfg.Query.MaxPreviewLimit
 	}
@@ -727,6 +1533,19 @@ func applyDefaults(k *koanf.Koanf, cfg *Config) { //nolint:gocyclo // config def
 		cfg.Export.Formats = append([]string(nil), defaultExportFormats...)
 	}
 
+	if cfg.Storage.Backend == "" {
+		cfg.Storage.Backend = defaultStorageBackend
+	}
+	if cfg.Storage.LocalDir == "" {
+		cfg.Storage.LocalDir = filepath.Join(filepath.Dir(cfg.SQLite.Path), defaultStorageLocalDirName)
+	}
+	if cfg.Storage.SignedURLTTL <= 0 {
+		cfg.Storage.SignedURLTTL = defaultStorageSignedURLTTL
+	}
+	if cfg.Storage.SnapshotTTL <= 0 {
+		cfg.Storage.SnapshotTTL = defaultStorageSnapshotTTL
+	}
+
 	if !k.Exists("tail.poll_interval") {
 		cfg.Tail.PollInterval = defaultTailPollInterval
 	}
@@ -770,6 +1589,9 @@ func applyDefaults(k *koanf.Koanf, cfg *Config) { //nolint:gocyclo // config def
 	if !k.Exists("shares.max_query_text_bytes") {
 		cfg.Shares.MaxQueryTextBytes = defaultSharesMaxQueryTextBytes
 	}
+	if !k.Exists("shares.allow_permanent") {
+		cfg.Shares.AllowPermanent = true
+	}
 	if cfg.Shares.DefaultTTL <= 0 {
 		cfg.Shares.DefaultTTL = defaultSharesDefaultTTL
 	}
@@ -804,6 +1626,62 @@ func applyDefaults(k *koanf.Koanf, cfg *Config) { //nolint:gocyclo // config def
 	if cfg.RateLimit.QueryPerUserPerMinute <= 0 {
 		cfg.RateLimit.QueryPerUserPerMinute = defaultRateLimitQueryPerUserPerMinute
 	}
+	if cfg.RateLimit.QueryPerUserBurst <= 0 {
+		cfg.RateLimit.QueryPerUserBurst = cfg.RateLimit.QueryPerUserPerMinute
+	}
+	// QueryPerTeamPerMinute == 0 disables the team bucket, like the auth
+	// global cap.
+	if !k.Exists("rate_limit.query_per_team_per_minute") || cfg.RateLimit.QueryPerTeamPerMinute < 0 {
+		cfg.RateLimit.QueryPerTeamPerMinute = defaultRateLimitQueryPerTeamPerMinute
+	}
+	if cfg.RateLimit.QueryPerTeamBurst <= 0 {
+		cfg.RateLimit.QueryPerTeamBurst = cfg.RateLimit.QueryPerTeamPerMinute
+	}
+	if !k.Exists("rate_limit.query_max_wait") || cfg.RateLimit.QueryMaxWait < 0 {
+		cfg.RateLimit.QueryMaxWait = defaultRateLimitQueryMaxWait
+	}
+
+	if !k.Exists("metrics.enabled") {
+		cfg.Metrics.Enabled = true
+	}
+
+	if cfg.Tracing.Endpoint == "" {
+		cfg.Tracing.Endpoint = defaultTracingEndpoint
+	}
+	if cfg.Tracing.ServiceName == "" {
+		cfg.Tracing.ServiceName = defaultTracingServiceName
+	}
+	if !k.Exists("tracing.sample_ratio") {
+		cfg.Tracing.SampleRatio = defaultTracingSampleRatio
+	}
+
+	if cfg.Ingest.MaxBodyBytes <= 0 {
+		cfg.Ingest.MaxBodyBytes = defaultIngestMaxBodyBytes
+	}
+	if cfg.Ingest.MaxRows <= 0 {
+		cfg.Ingest.MaxRows = defaultIngestMaxRows
+	}
+	if !k.Exists("ingest.wait_for_async_insert") {
+		cfg.Ingest.WaitForAsyncInsert = true
+	}
+	if cfg.Ingest.Syslog.BatchSize <= 0 {
+		cfg.Ingest.Syslog.BatchSize = defaultSyslogBatchSize
+	}
+	if cfg.Ingest.Syslog.FlushInterval <= 0 {
+		cfg.Ingest.Syslog.FlushInterval = defaultSyslogFlushInterval
+	}
+	if cfg.Ingest.Syslog.MaxMessageBytes <= 0 {
+		cfg.Ingest.Syslog.MaxMessageBytes = defaultSyslogMaxMessageBytes
+	}
+	if cfg.Ingest.OTLP.MaxBatchRows <= 0 {
+		cfg.Ingest.OTLP.MaxBatchRows = defaultOTLPMaxBatchRows
+	}
+	if cfg.Ingest.OTLP.FlushInterval <= 0 {
+		cfg.Ingest.OTLP.FlushInterval = defaultOTLPFlushInterval
+	}
+	if cfg.Ingest.OTLP.MaxPendingRows <= 0 {
+		cfg.Ingest.OTLP.MaxPendingRows = defaultOTLPMaxPendingRows
+	}
 
 	// enabled defaults to true, so only override when the key is absent (an
 	// explicit false must be preserved).
@@ -828,4 +1706,108 @@ func applyDefaults(k *koanf.Koanf, cfg *Config) { //nolint:gocyclo // config def
 	if cfg.DashboardCache.MaxConcurrentFills <= 0 {
 		cfg.DashboardCache.MaxConcurrentFills = defaultDashboardCacheMaxConcurrentFills
 	}
+
+	if !k.Exists("rollups.enabled") {
+		cfg.Rollups.Enabled = defaultRollupsEnabled
+	}
+	if cfg.Rollups.Interval <= 0 {
+		cfg.Rollups.Interval = defaultRollupsInterval
+	}
+	if !k.Exists("rollups.settle_delay") || cfg.Rollups.SettleDelay < 0 {
+		cfg.Rollups.SettleDelay = defaultRollupsSettleDelay
+	}
+	if cfg.Rollups.MinRangeDays <= 0 {
+		cfg.Rollups.MinRangeDays = defaultRollupsMinRangeDays
+	}
+	if cfg.Rollups.BackfillDays <= 0 {
+		cfg.Rollups.BackfillDays = defaultRollupsBackfillDays
+	}
+	if cfg.Rollups.MaxHoursPerRun <= 0 {
+		cfg.Rollups.MaxHoursPerRun = defaultRollupsMaxHoursPerRun
+	}
+
+	if !k.Exists("slos.enabled") {
+		cfg.SLOs.Enabled = defaultSLOsEnabled
+	}
+	if cfg.SLOs.Interval <= 0 {
+		cfg.SLOs.Interval = defaultSLOsInterval
+	}
+	if cfg.SLOs.HistoryLimit <= 0 {
+		cfg.SLOs.HistoryLimit = defaultSLOsHistoryLimit
+	}
+
+	if !k.Exists("source_stats.enabled") {
+		cfg.SourceStats.Enabled = defaultSourceStatsEnabled
+	}
+	if cfg.SourceStats.Interval <= 0 {
+		cfg.SourceStats.Interval = defaultSourceStatsInterval
+	}
+	if cfg.SourceStats.RetentionDays <= 0 {
+		cfg.SourceStats.RetentionDays = defaultSourceStatsRetentionDays
+	}
+
+	if !k.Exists("schema_drift.enabled") {
+		cfg.SchemaDrift.Enabled = defaultSchemaDriftEnabled
+	}
+	if cfg.SchemaDrift.Interval <= 0 {
+		cfg.SchemaDrift.Interval = defaultSchemaDriftInterval
+	}
+	if cfg.SchemaDrift.RetentionDays <= 0 {
+		cfg.SchemaDrift.RetentionDays = defaultSchemaDriftRetentionDays
+	}
+
+	if cfg.SourceAlerts.FailureThreshold <= 0 {
+		cfg.SourceAlerts.FailureThreshold = defaultSourceAlertsFailureThreshold
+	}
+	if cfg.SourceAlerts.ErrorRate <= 0 {
+		cfg.SourceAlerts.ErrorRate = defaultSourceAlertsErrorRate
+	}
+	if cfg.SourceAlerts.ErrorRateWindow <= 0 {
+		cfg.SourceAlerts.ErrorRateWindow = defaultSourceAlertsErrorRateWindow
+	}
+	if cfg.SourceAlerts.MinQueries <= 0 {
+		cfg.SourceAlerts.MinQueries = defaultSourceAlertsMinQueries
+	}
+	if cfg.SourceAlerts.RetentionDays <= 0 {
+		cfg.SourceAlerts.RetentionDays = defaultSourceAlertsRetentionDays
+	}
+
+	if !k.Exists("webhooks.enabled") {
+		cfg.Webhooks.Enabled = defaultWebhooksEnabled
+	}
+	if cfg.Webhooks.Timeout <= 0 {
+		cfg.Webhooks.Timeout = defaultWebhooksTimeout
+	}
+	if cfg.Webhooks.MaxAttempts <= 0 {
+		cfg.Webhooks.MaxAttempts = defaultWebhooksMaxAttempts
+	}
+	if cfg.Webhooks.QueueSize <= 0 {
+		cfg.Webhooks.QueueSize = defaultWebhooksQueueSize
+	}
+	if cfg.Webhooks.Workers <= 0 {
+		cfg.Webhooks.Workers = defaultWebhooksWorkers
+	}
+
+	if cfg.QueryHistory.MaxPerUser <= 0 {
+		cfg.QueryHistory.MaxPerUser = defaultQueryHistoryMaxPerUser
+	}
+	if cfg.QueryHistory.Retention < 0 {
+		cfg.QueryHistory.Retention = 0
+	}
+
+	if !k.Exists("query_analytics.enabled") {
+		cfg.QueryAnalytics.Enabled = defaultQueryAnalyticsEnabled
+	}
+	if cfg.QueryAnalytics.Interval <= 0 {
+		cfg.QueryAnalytics.Interval = defaultQueryAnalyticsInterval
+	}
+	if cfg.QueryAnalytics.WindowDays <= 0 {
+		cfg.QueryAnalytics.WindowDays = defaultQueryAnalyticsWindowDays
+	}
+	if cfg.QueryAnalytics.SlowQueryThreshold <= 0 {
+		cfg.QueryAnalytics.SlowQueryThreshold = defaultQueryAnalyticsSlowQueryThreshold
+	}
+	if cfg.QueryAnalytics.TopN <= 0 {
+		cfg.QueryAnalytics.TopN = defaultQueryAnalyticsTopN
+	}
 }
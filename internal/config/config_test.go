@@ -3,8 +3,11 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
 )
 
 // baseConfig is a minimal config that passes all the non-database validation,
@@ -125,6 +128,15 @@ func TestLoad_RateLimitDefaults(t *testing.T) {
 	if rl.QueryPerUserPerMinute != 120 {
 		t.Errorf("query_per_user_per_minute = %d, want 120", rl.QueryPerUserPerMinute)
 	}
+	if rl.QueryPerUserBurst != 120 {
+		t.Errorf("query_per_user_burst = %d, want 120 (per-minute rate)", rl.QueryPerUserBurst)
+	}
+	if rl.QueryPerTeamPerMinute != 600 || rl.QueryPerTeamBurst != 600 {
+		t.Errorf("team rate/burst = %d/%d, want 600/600", rl.QueryPerTeamPerMinute, rl.QueryPerTeamBurst)
+	}
+	if rl.QueryMaxWait != 2*time.Second {
+		t.Errorf("query_max_wait = %s, want 2s", rl.QueryMaxWait)
+	}
 }
 
 func TestLoad_RateLimitOverrides(t *testing.T) {
@@ -134,6 +146,9 @@ enabled = false
 auth_per_ip_per_minute = 5
 auth_global_per_minute = 0
 query_per_user_per_minute = 50
+query_per_user_burst = 10
+query_per_team_per_minute = 0
+query_max_wait = "0s"
 `))
 	if err != nil {
 		t.Fatalf("Load: %v", err)
@@ -152,6 +167,15 @@ query_per_user_per_minute = 50
 	if rl.QueryPerUserPerMinute != 50 {
 		t.Errorf("query_per_user_per_minute = %d, want 50", rl.QueryPerUserPerMinute)
 	}
+	if rl.QueryPerUserBurst != 10 {
+		t.Errorf("query_per_user_burst = %d, want 10", rl.QueryPerUserBurst)
+	}
+	if rl.QueryPerTeamPerMinute != 0 {
+		t.Errorf("query_per_team_per_minute = %d, want 0 (team limit disabled)", rl.QueryPerTeamPerMinute)
+	}
+	if rl.QueryMaxWait != 0 {
+		t.Errorf("query_max_wait = %s, want 0 (reject immediately)", rl.QueryMaxWait)
+	}
 }
 
 func TestLoad_DashboardCacheDefaults(t *testing.T) {
@@ -233,6 +257,47 @@ trusted_proxies = ["10.20.30.40/32", "192.168.1.1"]
 	}
 }
 
+func TestLoad_DrainTimeout(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.DrainTimeout != 30*time.Second {
+		t.Errorf("drain_timeout = %s, want 30s (default)", cfg.Server.DrainTimeout)
+	}
+
+	cfg, err = Load(writeConfig(t, "[server]\ndrain_timeout = \"0s\"\n"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.DrainTimeout != 0 {
+		t.Errorf("drain_timeout = %s, want 0 when set explicitly", cfg.Server.DrainTimeout)
+	}
+
+	if _, err := Load(writeConfig(t, "[server]\ndrain_timeout = \"-1s\"\n")); err == nil {
+		t.Error("Load accepted a negative drain_timeout")
+	}
+}
+
+func TestLoad_SecretsKeys(t *testing.T) {
+	key := "\"" + strings.Repeat("A", 43) + "=\""
+	if _, err := Load(writeConfig(t, "[secrets]\nencryption_key = "+key+"\nprevious_keys = ["+key+"]\n")); err != nil {
+		t.Fatalf("Load with valid keys: %v", err)
+	}
+	for name, body := range map[string]string{
+		"not base64":           "[secrets]\nencryption_key = \"not a key!\"\n",
+		"wrong length":         "[secrets]\nencryption_key = \"c2hvcnQ=\"\n",
+		"previous without key": "[secrets]\nprevious_keys = [" + key + "]\n",
+		"negative cache_ttl":   "[secrets]\ncache_ttl = \"-1s\"\n",
+		"bad kv_version":       "[secrets.vault]\nkv_version = 3\n",
+		"bad vault address":    "[secrets.vault]\naddress = \"vault:8200\"\n",
+	} {
+		if _, err := Load(writeConfig(t, body)); err == nil {
+			t.Errorf("Load accepted %s", name)
+		}
+	}
+}
+
 func TestLoad_TrustedProxiesInvalidFailFast(t *testing.T) {
 	for _, bad := range []string{"not-an-ip", "0.0.0.0/0", "::/0", "10.0.0.0/999"} {
 		if _, err := Load(writeConfig(t, "[server]\ntrusted_proxies = [\""+bad+"\"]\n")); err == nil {
@@ -256,3 +321,534 @@ func TestLoad_BedrockRequiresRegionAndModel(t *testing.T) {
 		t.Fatalf("valid bedrock config should load: %v", err)
 	}
 }
+
+func TestLoad_RollupsDefaults(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	r := cfg.Rollups
+	if !r.Enabled || r.Interval != 5*time.Minute || r.SettleDelay != 15*time.Minute {
+		t.Errorf("unexpected scheduler defaults: %+v", r)
+	}
+	if r.MinRangeDays != 7 || r.BackfillDays != 90 || r.MaxHoursPerRun != 168 {
+		t.Errorf("unexpected range defaults: %+v", r)
+	}
+
+	// An explicit zero settle delay is honoured.
+	cfg, err = Load(writeConfig(t, `
+[rollups]
+enabled = false
+settle_delay = "0s"
+min_range_days = 30
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	r = cfg.Rollups
+	if r.Enabled || r.SettleDelay != 0 || r.MinRangeDays != 30 {
+		t.Errorf("overrides not applied: %+v", r)
+	}
+}
+
+func TestLoad_SLOsDefaults(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if s := cfg.SLOs; !s.Enabled || s.Interval != 5*time.Minute || s.HistoryLimit != 2016 {
+		t.Errorf("unexpected defaults: %+v", s)
+	}
+
+	cfg, err = Load(writeConfig(t, `
+[slos]
+enabled = false
+interval = "1m"
+history_limit = 60
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if s := cfg.SLOs; s.Enabled || s.Interval != time.Minute || s.HistoryLimit != 60 {
+		t.Errorf("overrides not applied: %+v", s)
+	}
+}
+
+func TestLoad_SourceStatsDefaults(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if s := cfg.SourceStats; !s.Enabled || s.Interval != 24*time.Hour || s.RetentionDays != 365 {
+		t.Errorf("unexpected defaults: %+v", s)
+	}
+
+	cfg, err = Load(writeConfig(t, `
+[source_stats]
+enabled = false
+interval = "6h"
+retention_days = 30
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if s := cfg.SourceStats; s.Enabled || s.Interval != 6*time.Hour || s.RetentionDays != 30 {
+		t.Errorf("overrides not applied: %+v", s)
+	}
+}
+
+func TestLoad_SchemaDrift(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if d := cfg.SchemaDrift; !d.Enabled || d.Interval != time.Hour || d.RetentionDays != 90 || len(d.Channels()) != 0 {
+		t.Errorf("unexpected defaults: %+v", d)
+	}
+
+	cfg, err = Load(writeConfig(t, `
+[schema_drift]
+interval = "15m"
+webhook_urls = ["https://hooks.example.com/drift"]
+slack_urls = ["https://hooks.slack.com/services/T/B/X"]
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	channels := cfg.SchemaDrift.Channels()
+	if cfg.SchemaDrift.Interval != 15*time.Minute || len(channels) != 2 ||
+		channels[0].Type != models.AlertChannelWebhook || channels[1].Type != models.AlertChannelSlack {
+		t.Errorf("overrides not applied: %+v / %+v", cfg.SchemaDrift, channels)
+	}
+
+	if _, err := Load(writeConfig(t, `
+[schema_drift]
+webhook_urls = ["ftp://example.com/drift"]
+`)); err == nil || !strings.Contains(err.Error(), "schema_drift") {
+		t.Errorf("Load with a non-http webhook URL err = %v, want a schema_drift error", err)
+	}
+}
+
+func TestLoad_SourceAlerts(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if a := cfg.SourceAlerts; a.Enabled || a.FailureThreshold != 3 || a.ErrorRate != 0.5 ||
+		a.ErrorRateWindow != 5*time.Minute || a.MinQueries != 10 || a.RetentionDays != 30 {
+		t.Errorf("unexpected defaults: %+v", a)
+	}
+
+	cfg, err = Load(writeConfig(t, `
+[source_alerts]
+enabled = true
+failure_threshold = 5
+error_rate = 0.2
+error_rate_window = "15m"
+slack_urls = ["https://hooks.slack.com/services/T/B/X"]
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if a := cfg.SourceAlerts; !a.Enabled || a.FailureThreshold != 5 || a.ErrorRate != 0.2 ||
+		a.ErrorRateWindow != 15*time.Minute || len(a.Channels()) != 1 {
+		t.Errorf("overrides not applied: %+v", a)
+	}
+
+	if _, err := Load(writeConfig(t, `
+[source_alerts]
+error_rate = 1.5
+`)); err == nil || !strings.Contains(err.Error(), "source_alerts.error_rate") {
+		t.Errorf("Load with error_rate 1.5 err = %v, want a source_alerts.error_rate error", err)
+	}
+}
+
+func TestLoad_QueryHistory(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if h := cfg.QueryHistory; h.MaxPerUser != 200 || h.Retention != 0 {
+		t.Errorf("unexpected defaults: %+v", h)
+	}
+
+	cfg, err = Load(writeConfig(t, `
+[query_history]
+max_per_user = 50
+retention = "720h"
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if h := cfg.QueryHistory; h.MaxPerUser != 50 || h.Retention != 720*time.Hour {
+		t.Errorf("overrides not applied: %+v", h)
+	}
+}
+
+func TestLoad_QueryPersistActiveAfter(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := cfg.Query.PersistActiveAfter; got != 0 {
+		t.Errorf("default persist_active_after = %v, want 0", got)
+	}
+
+	for raw, want := range map[string]time.Duration{`"30s"`: 30 * time.Second, `"-5s"`: 0} {
+		cfg, err = Load(writeConfig(t, "\n[query]\npersist_active_after = "+raw+"\n"))
+		if err != nil {
+			t.Fatalf("Load(%s): %v", raw, err)
+		}
+		if got := cfg.Query.PersistActiveAfter; got != want {
+			t.Errorf("persist_active_after = %s: got %v, want %v", raw, got, want)
+		}
+	}
+}
+
+func TestLoad_QueryAnalyticsDefaults(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	a := cfg.QueryAnalytics
+	if !a.Enabled || a.Interval != 5*time.Minute || a.WindowDays != 7 || a.SlowQueryThreshold != 10*time.Second || a.TopN != 10 {
+		t.Errorf("unexpected defaults: %+v", a)
+	}
+
+	cfg, err = Load(writeConfig(t, `
+[query_analytics]
+enabled = false
+window_days = 30
+slow_query_threshold = "2s"
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if a := cfg.QueryAnalytics; a.Enabled || a.WindowDays != 30 || a.SlowQueryThreshold != 2*time.Second {
+		t.Errorf("overrides not applied: %+v", a)
+	}
+}
+
+func TestLoad_QueryCostTeams(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+[query.cost]
+explain = true
+max_rows_to_read = 1000
+max_bytes_to_read = 4096
+
+[[query.cost.teams]]
+team_id = 7
+max_rows_to_read = 50
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	limits := cfg.Query.Cost.LimitsForTeam(7)
+	if limits == nil || limits.MaxRowsToRead != 50 || limits.MaxBytesToRead != 4096 || !limits.Explain || limits.Enforce {
+		t.Errorf("team 7 limits = %+v", limits)
+	}
+	if limits := cfg.Query.Cost.LimitsForTeam(8); limits == nil || limits.MaxRowsToRead != 1000 {
+		t.Errorf("team 8 limits = %+v, want the global caps", limits)
+	}
+
+	cfg, err = Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if limits := cfg.Query.Cost.LimitsForTeam(7); limits != nil {
+		t.Errorf("default limits = %+v, want none", limits)
+	}
+
+	if _, err := Load(writeConfig(t, "\n[query.cost]\nexplain = true\n[[query.cost.teams]]\nteam_id = 2\n[[query.cost.teams]]\nteam_id = 2\n")); err == nil {
+		t.Error("expected an error for a duplicate team_id")
+	}
+}
+
+func TestLoad_QueryQuotaTeams(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+[query.quota]
+max_queries_per_day = 1000
+
+[[query.quota.teams]]
+team_id = 7
+max_rows_read_per_day = 5000000
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if quota := cfg.Query.Quota.QuotaForTeam(7); quota.MaxQueriesPerDay != 1000 || quota.MaxRowsReadPerDay != 5000000 {
+		t.Errorf("team 7 quota = %+v", quota)
+	}
+	if quota := cfg.Query.Quota.QuotaForTeam(8); quota.MaxQueriesPerDay != 1000 || quota.MaxRowsReadPerDay != 0 {
+		t.Errorf("team 8 quota = %+v, want the global caps", quota)
+	}
+
+	if _, err := Load(writeConfig(t, "\n[query.quota]\nmax_queries_per_day = -1\n")); err == nil {
+		t.Error("expected an error for a negative cap")
+	}
+	if _, err := Load(writeConfig(t, "\n[[query.quota.teams]]\nteam_id = 2\n[[query.quota.teams]]\nteam_id = 2\n")); err == nil {
+		t.Error("expected an error for a duplicate team_id")
+	}
+}
+
+func TestLoad_Storage(t *testing.T) {
+	cfg, err := Load(writeConfig(t, "\n[sqlite]\npath = \"/var/lib/logchef/logchef.db\"\n"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if s := cfg.Storage; s.Backend != "local" || s.LocalDir != "/var/lib/logchef/artifacts" ||
+		s.SignedURLTTL != defaultStorageSignedURLTTL || s.SnapshotTTL != defaultStorageSnapshotTTL {
+		t.Errorf("unexpected defaults: %+v", s)
+	}
+
+	for name, extra := range map[string]string{
+		"unknown backend":   "\n[storage]\nbackend = \"gcs\"\n",
+		"s3 without bucket": "\n[storage]\nbackend = \"s3\"\n\n[storage.s3]\nregion = \"us-east-1\"\n",
+		"half credentials":  "\n[storage]\nbackend = \"s3\"\n\n[storage.s3]\nbucket = \"b\"\nregion = \"us-east-1\"\naccess_key = \"AKID\"\n",
+	} {
+		if _, err := Load(writeConfig(t, extra)); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+	if _, err := Load(writeConfig(t, "\n[storage]\nbackend = \"s3\"\n\n[storage.s3]\nbucket = \"b\"\nregion = \"us-east-1\"\n")); err != nil {
+		t.Errorf("valid s3 config: %v", err)
+	}
+}
+
+func TestLoad_ProvisioningDir(t *testing.T) {
+	path := writeConfig(t, "\n[provisioning]\nmanage_sources = true\nmanage_alerts = true\ndir = \"provisioning.d\"\n")
+	dir := filepath.Join(filepath.Dir(path), "provisioning.d")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	files := map[string]string{
+		"10-sources.yaml": `
+sources:
+  - name: app
+    source_type: clickhouse
+    connection:
+      host: ch:9000
+      database: logs
+      table_name: app
+`,
+		"20-alerts.yml": `
+alerts:
+  - name: errors
+    source: app
+    query_language: clickhouse-sql
+    query: SELECT count() AS value FROM logs.app
+    lookback_seconds: 300
+    frequency_seconds: 60
+    threshold_operator: gt
+    threshold_value: 5
+    severity: critical
+    recipients: [oncall@example.com]
+`,
+		"30-teams.toml": "[[teams]]\nname = \"platform\"\nsources = [\"app\"]\n",
+		"README.md":     "ignored",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	p := cfg.Provisioning
+	if len(p.Sources) != 1 || p.Sources[0].Connection["table_name"] != "app" {
+		t.Errorf("sources = %+v", p.Sources)
+	}
+	if len(p.Alerts) != 1 || p.Alerts[0].Severity != models.AlertSeverityCritical || p.Alerts[0].ThresholdValue != 5 ||
+		len(p.Alerts[0].Recipients) != 1 {
+		t.Errorf("alerts = %+v", p.Alerts)
+	}
+	if len(p.Teams) != 1 || p.Teams[0].Name != "platform" {
+		t.Errorf("teams = %+v", p.Teams)
+	}
+
+	if _, err := Load(writeConfig(t, "\n[provisioning]\ndir = \"missing\"\n")); err == nil {
+		t.Error("expected an error for a missing provisioning directory")
+	}
+}
+
+func TestLoad_GroupSync(t *testing.T) {
+	extra := "\n[auth.group_sync]\nenabled = true\n\n[[auth.group_sync.mappings]]\ngroup = \"sre\"\nteam = \"platform\"\nrole = \"admin\"\n\n[[auth.group_sync.mappings]]\ngroup = \"eng\"\nteam = \"platform\"\n"
+	cfg, err := Load(writeConfig(t, extra))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	gs := cfg.Auth.GroupSync
+	if gs.Claim != "groups" {
+		t.Errorf("claim = %q, want default \"groups\"", gs.Claim)
+	}
+	if len(gs.Mappings) != 2 || gs.Mappings[0].Role != "admin" || gs.Mappings[1].Role != "member" {
+		t.Errorf("mappings = %+v, want roles admin and defaulted member", gs.Mappings)
+	}
+
+	bad := "\n[auth.group_sync]\nenabled = true\n\n[[auth.group_sync.mappings]]\ngroup = \"sre\"\nteam = \"platform\"\nrole = \"owner\"\n"
+	if _, err := Load(writeConfig(t, bad)); err == nil {
+		t.Error("expected error for unknown group_sync role")
+	}
+	if _, err := Load(writeConfig(t, "\n[auth.group_sync]\nenabled = true\n")); err == nil {
+		t.Error("expected error for group_sync enabled without mappings")
+	}
+}
+
+func TestLoad_SCIMRequiresToken(t *testing.T) {
+	if _, err := Load(writeConfig(t, "\n[scim]\nenabled = true\ntoken = \"short\"\n")); err == nil {
+		t.Error("expected error for scim enabled with a short token")
+	}
+	cfg, err := Load(writeConfig(t, "\n[scim]\nenabled = true\ntoken = \"0123456789abcdef0123456789abcdef\"\n"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !cfg.SCIM.Enabled {
+		t.Error("scim.enabled = false, want true")
+	}
+}
+
+func TestLoad_Metrics(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !cfg.Metrics.Enabled || cfg.Metrics.Token != "" {
+		t.Errorf("metrics = %+v, want enabled without a token", cfg.Metrics)
+	}
+	if _, err := Load(writeConfig(t, "\n[metrics]\ntoken = \"short\"\n")); err == nil {
+		t.Error("expected error for a short metrics token")
+	}
+	cfg, err = Load(writeConfig(t, "\n[metrics]\nenabled = false\n"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Metrics.Enabled {
+		t.Error("metrics.enabled = true, want false (explicitly set)")
+	}
+}
+
+func TestLoad_Tracing(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	tr := cfg.Tracing
+	if tr.Enabled || tr.Endpoint != "http://localhost:4318" || tr.ServiceName != "logchef" || tr.SampleRatio != 1 {
+		t.Errorf("tracing defaults = %+v", tr)
+	}
+	cfg, err = Load(writeConfig(t, "\n[tracing]\nenabled = true\nsample_ratio = 0\n"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Tracing.SampleRatio != 0 {
+		t.Errorf("sample_ratio = %g, want 0 (explicitly set)", cfg.Tracing.SampleRatio)
+	}
+	if _, err := Load(writeConfig(t, "\n[tracing]\nenabled = true\nendpoint = \"collector:4318\"\n")); err == nil {
+		t.Error("expected error for an endpoint without a scheme")
+	}
+	if _, err := Load(writeConfig(t, "\n[tracing]\nenabled = true\nsample_ratio = 2.0\n")); err == nil {
+		t.Error("expected error for sample_ratio above 1")
+	}
+}
+
+func TestLoad_Ingest(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if in := cfg.Ingest; in.Enabled || in.MaxBodyBytes != 4*1024*1024 || in.MaxRows != 10000 || !in.WaitForAsyncInsert {
+		t.Errorf("unexpected defaults: %+v", in)
+	}
+
+	cfg, err = Load(writeConfig(t, `
+[ingest]
+enabled = true
+max_body_bytes = 16777216
+max_rows = 500
+wait_for_async_insert = false
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if in := cfg.Ingest; !in.Enabled || in.MaxBodyBytes != 16*1024*1024 || in.MaxRows != 500 || in.WaitForAsyncInsert {
+		t.Errorf("overrides not applied: %+v", in)
+	}
+
+	if _, err := Load(writeConfig(t, "[ingest]\nmax_body_bytes = 67108865\n")); err == nil || !strings.Contains(err.Error(), "ingest.max_body_bytes") {
+		t.Errorf("Load() error = %v, want max_body_bytes over the cap rejected", err)
+	}
+}
+
+func TestLoad_IngestOTLP(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := OTLPReceiverConfig{MaxBatchRows: 5000, FlushInterval: 200 * time.Millisecond, MaxPendingRows: 100000}
+	if cfg.Ingest.OTLP != want {
+		t.Errorf("defaults = %+v, want %+v", cfg.Ingest.OTLP, want)
+	}
+
+	cfg, err = Load(writeConfig(t, `
+[ingest.otlp]
+enabled = true
+max_batch_rows = 100
+flush_interval = "1s"
+max_pending_rows = 20000
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want = OTLPReceiverConfig{Enabled: true, MaxBatchRows: 100, FlushInterval: time.Second, MaxPendingRows: 20000}
+	if cfg.Ingest.OTLP != want {
+		t.Errorf("overrides = %+v, want %+v", cfg.Ingest.OTLP, want)
+	}
+
+	if _, err := Load(writeConfig(t, "[ingest.otlp]\nenabled = true\nmax_pending_rows = 10\n")); err == nil {
+		t.Error("max_pending_rows below ingest.max_rows: expected a validation error")
+	}
+}
+
+func TestLoad_IngestSyslog(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if sl := cfg.Ingest.Syslog; len(sl.Listeners) != 0 || sl.BatchSize != 1000 || sl.FlushInterval != time.Second || sl.MaxMessageBytes != 64*1024 {
+		t.Errorf("unexpected defaults: %+v", sl)
+	}
+
+	cfg, err = Load(writeConfig(t, `
+[ingest.syslog]
+batch_size = 200
+flush_interval = "5s"
+
+[[ingest.syslog.listeners]]
+source_id = 4
+udp_address = ":5514"
+tcp_address = ":5514"
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	sl := cfg.Ingest.Syslog
+	if sl.BatchSize != 200 || sl.FlushInterval != 5*time.Second {
+		t.Errorf("overrides not applied: %+v", sl)
+	}
+	want := SyslogListenerConfig{SourceID: 4, UDPAddress: ":5514", TCPAddress: ":5514"}
+	if len(sl.Listeners) != 1 || sl.Listeners[0] != want {
+		t.Errorf("listeners = %+v, want [%+v]", sl.Listeners, want)
+	}
+
+	for name, body := range map[string]string{
+		"no source":  "[[ingest.syslog.listeners]]\nudp_address = \":5514\"\n",
+		"no address": "[[ingest.syslog.listeners]]\nsource_id = 4\n",
+	} {
+		if _, err := Load(writeConfig(t, body)); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
@@ -15,6 +15,13 @@ type ProvisioningConfig struct {
 	// When set, the provisioning config is loaded from this file instead of inline.
 	File string `koanf:"file"`
 
+	// Dir is an optional directory of provisioning files (*.yaml, *.yml,
+	// *.toml), read in name order. Each file declares any of sources, teams
+	// and alerts, which are added to those declared inline or in File. If
+	// relative, resolved against the main config.toml directory. The manage_*,
+	// prune and dry_run switches stay in config.toml or File.
+	Dir string `koanf:"dir"`
+
 	// ManageSources enables declarative management of ClickHouse data sources.
 	// When true, sources listed in Sources are created/updated/adopted and marked managed.
 	ManageSources bool `koanf:"manage_sources"`
@@ -23,6 +30,10 @@ type ProvisioningConfig struct {
 	// When true, teams listed in Teams are created/updated/adopted and marked managed.
 	ManageTeams bool `koanf:"manage_teams"`
 
+	// ManageAlerts enables declarative management of alert rules. When true,
+	// alerts listed in Alerts are created/updated/adopted and marked managed.
+	ManageAlerts bool `koanf:"manage_alerts"`
+
 	// Prune removes managed resources that are no longer declared in config.
 	// WARNING: Pruning a team/source cascades to saved queries and alerts via FK constraints.
 	// Default: false (safe mode — orphaned managed resources are logged but not deleted).
@@ -39,11 +50,15 @@ type ProvisioningConfig struct {
 	// Teams declares teams with their memberships and source access.
 	// Each team is identified by its Name (must be unique).
 	Teams []ProvisionTeam `koanf:"teams" json:"teams,omitempty"`
+
+	// Alerts declares alert rules. Each alert is identified by its Source and
+	// Name (unique together).
+	Alerts []ProvisionAlert `koanf:"alerts" json:"alerts,omitempty"`
 }
 
 // Enabled returns true if any provisioning management is configured.
 func (c *ProvisioningConfig) Enabled() bool {
-	return c.ManageSources || c.ManageTeams
+	return c.ManageSources || c.ManageTeams || c.ManageAlerts
 }
 
 // ProvisionSource declares a datasource to manage. New configs should use
@@ -68,6 +83,19 @@ type ProvisionSource struct {
 	MetaTSField       string `koanf:"meta_ts_field" json:"meta_ts_field,omitempty"`
 	MetaSeverityField string `koanf:"meta_severity_field" json:"meta_severity_field,omitempty"`
 
+	// Tags are the source's key/value labels (env = "prod").
+	Tags map[string]string `koanf:"tags" json:"tags,omitempty"`
+
+	// ExtractionRules define the source's virtual columns (ClickHouse only).
+	ExtractionRules models.ExtractionRules `koanf:"extraction_rules" json:"extraction_rules,omitempty"`
+
+	// TraceCorrelation links the source's trace IDs to a tracing backend.
+	TraceCorrelation *models.TraceCorrelation `koanf:"trace_correlation" json:"trace_correlation,omitempty"`
+
+	// MaskedColumns hide sensitive columns from users without view_unmasked
+	// (ClickHouse only).
+	MaskedColumns models.MaskedColumns `koanf:"masked_columns" json:"masked_columns,omitempty"`
+
 	// Legacy detection fields (pre-v2.0 flat schema). These mirror the old
 	// top-level connection keys that v2.0 moved under [sources.connection].
 	// They exist ONLY so the startup guard can detect an un-migrated config and
@@ -184,6 +212,37 @@ type ProvisionTeam struct {
 // ProvisionMember declares a team member by email with a role.
 type ProvisionMember struct {
 	Email string `koanf:"email" json:"email"`
-	// Role is the team-level role: "admin", "editor", or "member".
+	// Role is the team-level role: "admin", "editor", "member", or "viewer".
 	Role string `koanf:"role" json:"role,omitempty"`
 }
+
+// ProvisionAlert declares an alert rule on a source. Fields mirror the alerts
+// API; recipients are given by email instead of user ID.
+type ProvisionAlert struct {
+	Name string `koanf:"name" json:"name"`
+	// Source is the Name of the source the alert evaluates against.
+	Source      string `koanf:"source" json:"source"`
+	Description string `koanf:"description" json:"description,omitempty"`
+
+	QueryLanguage models.QueryLanguage   `koanf:"query_language" json:"query_language,omitempty"`
+	EditorMode    models.AlertEditorMode `koanf:"editor_mode" json:"editor_mode,omitempty"`
+	Query         string                 `koanf:"query" json:"query,omitempty"`
+	ConditionJSON string                 `koanf:"condition_json" json:"condition_json,omitempty"`
+
+	LookbackSeconds   int                           `koanf:"lookback_seconds" json:"lookback_seconds"`
+	ThresholdOperator models.AlertThresholdOperator `koanf:"threshold_operator" json:"threshold_operator"`
+	ThresholdValue    float64                       `koanf:"threshold_value" json:"threshold_value"`
+	FrequencySeconds  int                           `koanf:"frequency_seconds" json:"frequency_seconds"`
+	ForSeconds        int                           `koanf:"for_seconds" json:"for_seconds,omitempty"`
+	Severity          models.AlertSeverity          `koanf:"severity" json:"severity"`
+
+	Labels      map[string]string `koanf:"labels" json:"labels,omitempty"`
+	Annotations map[string]string `koanf:"annotations" json:"annotations,omitempty"`
+
+	// Recipients are the emails of users notified by email.
+	Recipients  []string `koanf:"recipients" json:"recipients,omitempty"`
+	WebhookURLs []string `koanf:"webhook_urls" json:"webhook_urls,omitempty"`
+
+	// Disabled keeps the alert defined but not evaluated.
+	Disabled bool `koanf:"disabled" json:"disabled,omitempty"`
+}
@@ -35,6 +35,8 @@ func LoadRuntimeConfig(ctx context.Context, staticConfig *Config, store Settings
 	cfg.Alerts.EvaluationInterval = store.GetDurationSetting(ctx, "alerts.evaluation_interval", cfg.Alerts.EvaluationInterval)
 	cfg.Alerts.DefaultLookback = store.GetDurationSetting(ctx, "alerts.default_lookback", cfg.Alerts.DefaultLookback)
 	cfg.Alerts.HistoryLimit = store.GetIntSetting(ctx, "alerts.history_limit", cfg.Alerts.HistoryLimit)
+	cfg.Alerts.GroupInterval = store.GetDurationSetting(ctx, "alerts.group_interval", cfg.Alerts.GroupInterval)
+	cfg.Alerts.RoomMaxPerHour = store.GetIntSetting(ctx, "alerts.room_max_per_hour", cfg.Alerts.RoomMaxPerHour)
 
 	// AI configuration
 	cfg.AI.Enabled = store.GetBoolSetting(ctx, "ai.enabled", cfg.AI.Enabled)
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mr-karan/logchef/internal/clickhouse"
 	"github.com/mr-karan/logchef/internal/datasource"
 	"github.com/mr-karan/logchef/internal/store"
 	"github.com/mr-karan/logchef/internal/util"
@@ -21,6 +22,9 @@ var (
 	ErrAlertNotFound = errors.New("alert not found")
 	// ErrInvalidAlertConfiguration indicates the request payload failed validation.
 	ErrInvalidAlertConfiguration = errors.New("invalid alert configuration")
+	// ErrRawSQLDisabled is returned when an alert's query is raw SQL and the
+	// caller's teams have raw SQL switched off.
+	ErrRawSQLDisabled = errors.New("raw SQL is disabled for this team; use LogchefQL instead")
 )
 
 var validOperators = map[models.AlertThresholdOperator]struct{}{
@@ -128,6 +132,27 @@ func validateWebhookURLs(urls []string) error {
 	return nil
 }
 
+func sanitizeAlertChannels(in []models.AlertChannel) []models.AlertChannel {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]models.AlertChannel, len(in))
+	for i, channel := range in {
+		channel.Normalize()
+		out[i] = channel
+	}
+	return out
+}
+
+func validateAlertChannels(channels []models.AlertChannel) error {
+	for i := range channels {
+		if err := channels[i].Validate(); err != nil {
+			return fmt.Errorf("channel %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
 func validateAlertModel(ctx context.Context, ds *datasource.Service, sourceID models.SourceID, alert *models.Alert) error {
 	if alert == nil {
 		return fmt.Errorf("alert payload is required")
@@ -154,18 +179,36 @@ func validateAlertModel(ctx context.Context, ds *datasource.Service, sourceID mo
 	alert.Query = strings.TrimSpace(alert.Query)
 	alert.ConditionJSON = strings.TrimSpace(alert.ConditionJSON)
 
+	var condition *models.AlertCondition
 	switch alert.EditorMode {
 	case models.AlertEditorModeNative:
-		if alert.Query == "" {
+		if alert.Query == "" && alert.SLOID == nil {
 			return fmt.Errorf("query is required for native alerts")
 		}
+		if alert.Query != "" && alert.QueryLanguage == models.QueryLanguageClickHouseSQL {
+			if err := clickhouse.ValidateReadOnly(alert.Query); err != nil {
+				return err
+			}
+		}
 	case models.AlertEditorModeCondition:
+		if alert.SLOID != nil {
+			return fmt.Errorf("burn-rate alerts (slo_id) must use the native editor mode")
+		}
 		if alert.ConditionJSON == "" {
 			return fmt.Errorf("condition_json is required for condition alerts")
 		}
-		if alert.Query == "" {
-			return fmt.Errorf("query is required for condition alerts")
+		cond, structured, err := models.ParseAlertCondition(alert.ConditionJSON)
+		if err != nil {
+			return err
+		}
+		if !structured {
+			// Legacy condition: the bare LogchefQL string plus a client-built query.
+			if alert.Query == "" {
+				return fmt.Errorf("query is required for condition alerts")
+			}
+			break
 		}
+		condition = cond
 	}
 	if _, ok := validOperators[alert.ThresholdOperator]; !ok {
 		return fmt.Errorf("invalid threshold_operator %q", alert.ThresholdOperator)
@@ -173,17 +216,60 @@ func validateAlertModel(ctx context.Context, ds *datasource.Service, sourceID mo
 	if alert.FrequencySeconds <= 0 {
 		return fmt.Errorf("frequency_seconds must be greater than zero")
 	}
+	if alert.ForSeconds < 0 {
+		return fmt.Errorf("for_seconds must not be negative")
+	}
 	if alert.LookbackSeconds <= 0 {
 		return fmt.Errorf("lookback_seconds must be greater than zero")
 	}
 	if _, ok := validSeverities[alert.Severity]; !ok {
 		return fmt.Errorf("invalid severity %q", alert.Severity)
 	}
+	if condition != nil {
+		// Compile once so a bad filter is rejected at save time rather than on
+		// the first evaluation.
+		if _, err := ds.BuildConditionAlertQuery(ctx, sourceID, datasource.ConditionAlertRequest{
+			Condition:       condition,
+			LookbackSeconds: condition.Lookback(alert.LookbackSeconds),
+			Now:             time.Now(),
+		}); err != nil {
+			return fmt.Errorf("invalid condition: %w", err)
+		}
+	}
+	return nil
+}
+
+// alertRunsRawSQL reports whether a validated alert evaluates a query the
+// user wrote in ClickHouse SQL, which the team's raw SQL switch governs.
+func alertRunsRawSQL(alert *models.Alert) bool {
+	return alert.EditorMode == models.AlertEditorModeNative &&
+		alert.QueryLanguage == models.QueryLanguageClickHouseSQL &&
+		alert.Query != ""
+}
+
+// validateAlertSLO checks that a burn-rate alert's SLO exists and measures the
+// alert's own source.
+func validateAlertSLO(ctx context.Context, db store.StoreOps, alert *models.Alert) error {
+	if alert.SLOID == nil {
+		return nil
+	}
+	slo, err := db.GetSLO(ctx, *alert.SLOID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return fmt.Errorf("slo %d not found", *alert.SLOID)
+		}
+		return fmt.Errorf("failed to load slo: %w", err)
+	}
+	if slo.SourceID != alert.SourceID {
+		return fmt.Errorf("slo %d is defined on a different source", slo.ID)
+	}
 	return nil
 }
 
-// CreateAlert creates a new alert rule for the specified source, owned by createdBy.
-func CreateAlert(ctx context.Context, db store.StoreOps, ds *datasource.Service, log *slog.Logger, sourceID models.SourceID, createdBy models.UserID, req *models.CreateAlertRequest) (*models.Alert, error) {
+// CreateAlert creates a new alert rule for the specified source, owned by
+// createdBy. allowRawSQL says whether createdBy may write its query in raw
+// SQL; see UserCanRunRawSQLOnSource.
+func CreateAlert(ctx context.Context, db store.StoreOps, ds *datasource.Service, log *slog.Logger, sourceID models.SourceID, createdBy models.UserID, allowRawSQL bool, req *models.CreateAlertRequest) (*models.Alert, error) {
 	if req == nil {
 		return nil, ErrInvalidAlertConfiguration
 	}
@@ -195,6 +281,10 @@ func CreateAlert(ctx context.Context, db store.StoreOps, ds *datasource.Service,
 	if err := validateWebhookURLs(webhookURLs); err != nil {
 		return nil, fmt.Errorf("%w: %s", ErrInvalidAlertConfiguration, err)
 	}
+	channels := sanitizeAlertChannels(req.Channels)
+	if err := validateAlertChannels(channels); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAlertConfiguration, err)
+	}
 	owner := createdBy
 	alert := &models.Alert{
 		SourceID:          sourceID,
@@ -208,18 +298,30 @@ func CreateAlert(ctx context.Context, db store.StoreOps, ds *datasource.Service,
 		ThresholdOperator: req.ThresholdOperator,
 		ThresholdValue:    req.ThresholdValue,
 		FrequencySeconds:  req.FrequencySeconds,
+		ForSeconds:        req.ForSeconds,
 		Severity:          req.Severity,
 		Labels:            sanitizeStringMap(req.Labels),
 		Annotations:       sanitizeStringMap(req.Annotations),
 		RecipientUserIDs:  recipientUserIDs,
 		WebhookURLs:       webhookURLs,
+		Channels:          channels,
 		GeneratorURL:      strings.TrimSpace(req.GeneratorURL),
 		IsActive:          req.IsActive,
 		CreatedBy:         &owner,
 	}
+	if req.SLOID != nil && *req.SLOID != 0 {
+		sloID := *req.SLOID
+		alert.SLOID = &sloID
+	}
 	if err := validateAlertModel(ctx, ds, sourceID, alert); err != nil {
 		return nil, fmt.Errorf("%w: %s", ErrInvalidAlertConfiguration, err)
 	}
+	if !allowRawSQL && alertRunsRawSQL(alert) {
+		return nil, ErrRawSQLDisabled
+	}
+	if err := validateAlertSLO(ctx, db, alert); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAlertConfiguration, err)
+	}
 
 	if err := db.CreateAlert(ctx, alert); err != nil {
 		log.Error("failed to create alert", "source_id", sourceID, "error", err)
@@ -242,8 +344,10 @@ func GetAlert(ctx context.Context, db store.StoreOps, log *slog.Logger, alertID
 	return alert, nil
 }
 
-// UpdateAlert updates an existing alert rule.
-func UpdateAlert(ctx context.Context, db store.StoreOps, ds *datasource.Service, log *slog.Logger, alertID models.AlertID, req *models.UpdateAlertRequest) (*models.Alert, error) {
+// UpdateAlert updates an existing alert rule. allowRawSQL says whether the
+// caller may write raw SQL; without it an alert already on raw SQL can still
+// be edited as long as its query stays as stored.
+func UpdateAlert(ctx context.Context, db store.StoreOps, ds *datasource.Service, log *slog.Logger, alertID models.AlertID, allowRawSQL bool, req *models.UpdateAlertRequest) (*models.Alert, error) {
 	if req == nil {
 		return nil, ErrInvalidAlertConfiguration
 	}
@@ -257,6 +361,7 @@ func UpdateAlert(ctx context.Context, db store.StoreOps, ds *datasource.Service,
 		return nil, fmt.Errorf("failed to load alert: %w", err)
 	}
 
+	storedQuery, storedLanguage := existing.Query, existing.QueryLanguage
 	if err := applyAlertUpdates(existing, req); err != nil {
 		return nil, err
 	}
@@ -270,9 +375,23 @@ func UpdateAlert(ctx context.Context, db store.StoreOps, ds *datasource.Service,
 			return nil, fmt.Errorf("%w: %s", ErrInvalidAlertConfiguration, err)
 		}
 	}
+	if req.Channels != nil {
+		if err := validateAlertChannels(existing.Channels); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAlertConfiguration, err)
+		}
+	}
 	if err := validateAlertModel(ctx, ds, existing.SourceID, existing); err != nil {
 		return nil, fmt.Errorf("%w: %s", ErrInvalidAlertConfiguration, err)
 	}
+	queryChanged := existing.Query != strings.TrimSpace(storedQuery) || existing.QueryLanguage != storedLanguage
+	if !allowRawSQL && queryChanged && alertRunsRawSQL(existing) {
+		return nil, ErrRawSQLDisabled
+	}
+	if req.SLOID != nil {
+		if err := validateAlertSLO(ctx, db, existing); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAlertConfiguration, err)
+		}
+	}
 
 	if err := db.UpdateAlert(ctx, existing); err != nil {
 		if models.IsNotFound(err) {
@@ -347,6 +466,12 @@ func applyThresholdUpdates(alert *models.Alert, req *models.UpdateAlertRequest)
 		}
 		alert.FrequencySeconds = *req.FrequencySeconds
 	}
+	if req.ForSeconds != nil {
+		if *req.ForSeconds < 0 {
+			return fmt.Errorf("%w: for_seconds must not be negative", ErrInvalidAlertConfiguration)
+		}
+		alert.ForSeconds = *req.ForSeconds
+	}
 	if req.Severity != nil {
 		if _, ok := validSeverities[*req.Severity]; !ok {
 			return fmt.Errorf("%w: invalid severity %q", ErrInvalidAlertConfiguration, *req.Severity)
@@ -369,6 +494,16 @@ func applyMetadataUpdates(alert *models.Alert, req *models.UpdateAlertRequest) {
 	if req.WebhookURLs != nil {
 		alert.WebhookURLs = sanitizeWebhookURLs(*req.WebhookURLs)
 	}
+	if req.Channels != nil {
+		alert.Channels = sanitizeAlertChannels(*req.Channels)
+	}
+	if req.SLOID != nil {
+		alert.SLOID = nil
+		if *req.SLOID != 0 {
+			sloID := *req.SLOID
+			alert.SLOID = &sloID
+		}
+	}
 	if req.GeneratorURL != nil {
 		alert.GeneratorURL = strings.TrimSpace(*req.GeneratorURL)
 	}
@@ -432,6 +567,91 @@ func ListAlertHistory(ctx context.Context, db store.StoreOps, alertID models.Ale
 	return history, nil
 }
 
+// GetAlertState returns the alert's current state, its last recorded value and
+// its state timeline over the window ending at now.
+func GetAlertState(ctx context.Context, db store.StoreOps, alert *models.Alert, window time.Duration, now time.Time) (*models.AlertStateResponse, error) {
+	from := now.Add(-window)
+	history, err := db.ListAlertHistorySince(ctx, alert.ID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert history: %w", err)
+	}
+	resp := &models.AlertStateResponse{
+		Alert:    alert,
+		State:    alert.LastState,
+		From:     from,
+		To:       now,
+		Timeline: BuildAlertTimeline(alert, history, from, now),
+	}
+	for _, entry := range history {
+		if entry.Value != nil {
+			resp.LastValue = entry.Value
+		}
+	}
+	return resp, nil
+}
+
+// BuildAlertTimeline folds an alert's history, oldest first, into the states
+// it went through between from and to. Each triggered entry is firing from
+// its triggered_at until it was resolved, or until to while it is still open;
+// the time in between is resolved. A pending alert is pending from its
+// PendingSince. Time before the alert was created is left out, and error
+// entries don't change the state.
+func BuildAlertTimeline(alert *models.Alert, history []*models.AlertHistoryEntry, from, to time.Time) []models.AlertStateSpan {
+	spans := []models.AlertStateSpan{}
+	cursor := from
+	if alert.CreatedAt.After(cursor) {
+		cursor = alert.CreatedAt
+	}
+	if !to.After(cursor) {
+		return spans
+	}
+
+	for _, entry := range history {
+		if entry.Status == models.AlertStatusError {
+			continue
+		}
+		start, end := entry.TriggeredAt, to
+		if entry.ResolvedAt != nil && entry.ResolvedAt.Before(to) {
+			end = *entry.ResolvedAt
+		}
+		if start.Before(cursor) {
+			start = cursor
+		}
+		if !end.After(start) {
+			continue
+		}
+		spans = appendAlertSpan(spans, models.AlertStateSpan{State: models.AlertStateResolved, Start: cursor, End: start})
+		spans = appendAlertSpan(spans, models.AlertStateSpan{State: models.AlertStateFiring, Start: start, End: end, Value: entry.Value})
+		cursor = end
+	}
+
+	if alert.LastState == models.AlertStatePending && alert.PendingSince != nil {
+		pendingFrom := *alert.PendingSince
+		if pendingFrom.Before(cursor) {
+			pendingFrom = cursor
+		}
+		if pendingFrom.Before(to) {
+			spans = appendAlertSpan(spans, models.AlertStateSpan{State: models.AlertStateResolved, Start: cursor, End: pendingFrom})
+			spans = appendAlertSpan(spans, models.AlertStateSpan{State: models.AlertStatePending, Start: pendingFrom, End: to})
+			return spans
+		}
+	}
+	return appendAlertSpan(spans, models.AlertStateSpan{State: models.AlertStateResolved, Start: cursor, End: to})
+}
+
+// appendAlertSpan appends span, skipping empty spans and merging it into the
+// last one when both are in the same state and meet.
+func appendAlertSpan(spans []models.AlertStateSpan, span models.AlertStateSpan) []models.AlertStateSpan {
+	if !span.End.After(span.Start) {
+		return spans
+	}
+	if n := len(spans); n > 0 && spans[n-1].State == span.State && spans[n-1].End.Equal(span.Start) {
+		spans[n-1].End = span.End
+		return spans
+	}
+	return append(spans, span)
+}
+
 // ResolveAlert manually resolves the most recent triggered history entry.
 func ResolveAlert(ctx context.Context, db store.StoreOps, log *slog.Logger, alertID models.AlertID, message string) error {
 	entry, err := db.GetLatestUnresolvedAlertHistory(ctx, alertID)
@@ -452,7 +672,8 @@ func ResolveAlert(ctx context.Context, db store.StoreOps, log *slog.Logger, aler
 }
 
 // TestAlertQuery executes a test query to validate alert configuration and show performance metrics.
-func TestAlertQuery(ctx context.Context, db store.StoreOps, ds *datasource.Service, sourceID models.SourceID, req *models.TestAlertQueryRequest) (*models.TestAlertQueryResponse, error) {
+// allowRawSQL says whether the caller may run a raw SQL query.
+func TestAlertQuery(ctx context.Context, db store.StoreOps, ds *datasource.Service, sourceID models.SourceID, allowRawSQL bool, req *models.TestAlertQueryRequest) (*models.TestAlertQueryResponse, error) {
 	if req == nil {
 		return nil, fmt.Errorf("test query request is required")
 	}
@@ -491,16 +712,19 @@ func TestAlertQuery(ctx context.Context, db store.StoreOps, ds *datasource.Servi
 	if err := validateAlertModel(ctx, ds, sourceID, tempAlert); err != nil {
 		return nil, fmt.Errorf("%w: %s", ErrInvalidAlertConfiguration, err)
 	}
+	if !allowRawSQL && alertRunsRawSQL(tempAlert) {
+		return nil, ErrRawSQLDisabled
+	}
 
 	// Execute query with timing
+	queryReq, err := ds.ResolveAlertQuery(ctx, tempAlert, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAlertConfiguration, err)
+	}
 	timeout := models.DefaultQueryTimeoutSeconds
+	queryReq.QueryTimeout = &timeout
 	startTime := time.Now()
-	result, err := ds.EvaluateAlert(ctx, sourceID, datasource.AlertQueryRequest{
-		Language:        tempAlert.QueryLanguage,
-		Query:           tempAlert.Query,
-		LookbackSeconds: tempAlert.LookbackSeconds,
-		QueryTimeout:    &timeout,
-	})
+	result, err := ds.EvaluateAlert(ctx, sourceID, queryReq)
 	executionTime := time.Since(startTime)
 
 	if err != nil {
@@ -525,7 +749,7 @@ func TestAlertQuery(ctx context.Context, db store.StoreOps, ds *datasource.Servi
 	thresholdMet := compareAlertThreshold(value, req.ThresholdValue, req.ThresholdOperator)
 
 	// Generate additional warnings
-	warnings = append(warnings, generateQueryWarnings(tempAlert.Query, executionTime, result)...)
+	warnings = append(warnings, generateQueryWarnings(queryReq.Query, executionTime, result)...)
 
 	return &models.TestAlertQueryResponse{
 		Value:           value,
@@ -3,7 +3,9 @@ package core
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/mr-karan/logchef/internal/datasource"
 	"github.com/mr-karan/logchef/pkg/models"
@@ -106,7 +108,7 @@ func TestCreateAlertFullLifecycle(t *testing.T) {
 	ds := newFakeDatasourceService(db, log, nil)
 
 	req := newTestCreateAlertRequest()
-	alert, err := CreateAlert(ctx, db, ds, log, src.ID, owner.ID, req)
+	alert, err := CreateAlert(ctx, db, ds, log, src.ID, owner.ID, true, req)
 	if err != nil {
 		t.Fatalf("CreateAlert: %v", err)
 	}
@@ -124,7 +126,7 @@ func TestCreateAlertFullLifecycle(t *testing.T) {
 
 	newName := "5xx spike (updated)"
 	newThreshold := 20.0
-	updated, err := UpdateAlert(ctx, db, ds, log, alert.ID, &models.UpdateAlertRequest{
+	updated, err := UpdateAlert(ctx, db, ds, log, alert.ID, true, &models.UpdateAlertRequest{
 		Name:           &newName,
 		ThresholdValue: &newThreshold,
 	})
@@ -196,13 +198,29 @@ func TestCreateAlertValidation(t *testing.T) {
 		{name: "webhook URL with unsupported scheme", mutate: func(r *models.CreateAlertRequest) {
 			r.WebhookURLs = []string{"ftp://example.com/hook"}
 		}, wantErr: true},
+		{name: "slack and templated webhook channels", mutate: func(r *models.CreateAlertRequest) {
+			r.Channels = []models.AlertChannel{
+				{Type: "Slack", URL: " https://hooks.slack.com/services/T0/B0/x "},
+				{Type: models.AlertChannelWebhook, URL: "https://example.com/hook", Template: `{"summary":{{json .AlertName}}}`},
+				{Type: models.AlertChannelEmail, Emails: []string{"oncall@example.com"}},
+			}
+		}, wantErr: false},
+		{name: "unknown channel type", mutate: func(r *models.CreateAlertRequest) {
+			r.Channels = []models.AlertChannel{{Type: "pager", URL: "https://example.com"}}
+		}, wantErr: true},
+		{name: "email channel without addresses", mutate: func(r *models.CreateAlertRequest) {
+			r.Channels = []models.AlertChannel{{Type: models.AlertChannelEmail, Emails: []string{" "}}}
+		}, wantErr: true},
+		{name: "webhook channel with broken template", mutate: func(r *models.CreateAlertRequest) {
+			r.Channels = []models.AlertChannel{{Type: models.AlertChannelWebhook, URL: "https://example.com/hook", Template: "{{.AlertName"}}
+		}, wantErr: true},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			req := newTestCreateAlertRequest()
 			tc.mutate(req)
-			_, err := CreateAlert(ctx, db, ds, log, src.ID, owner.ID, req)
+			_, err := CreateAlert(ctx, db, ds, log, src.ID, owner.ID, true, req)
 			if tc.wantErr {
 				if !errors.Is(err, ErrInvalidAlertConfiguration) {
 					t.Errorf("CreateAlert(%s) err = %v, want ErrInvalidAlertConfiguration", tc.name, err)
@@ -233,7 +251,7 @@ func TestCreateAlertUnsupportedByProvider(t *testing.T) {
 	})
 
 	req := newTestCreateAlertRequest()
-	if _, err := CreateAlert(ctx, db, ds, log, src.ID, owner.ID, req); err == nil {
+	if _, err := CreateAlert(ctx, db, ds, log, src.ID, owner.ID, true, req); err == nil {
 		t.Error("expected CreateAlert to fail for a query language the provider does not support")
 	}
 }
@@ -245,7 +263,7 @@ func TestUpdateAlertNotFound(t *testing.T) {
 	ds := newFakeDatasourceService(db, log, nil)
 
 	name := "new name"
-	_, err := UpdateAlert(context.Background(), db, ds, log, models.AlertID(999999), &models.UpdateAlertRequest{Name: &name})
+	_, err := UpdateAlert(context.Background(), db, ds, log, models.AlertID(999999), true, &models.UpdateAlertRequest{Name: &name})
 	if !errors.Is(err, ErrAlertNotFound) {
 		t.Errorf("UpdateAlert(missing) err = %v, want ErrAlertNotFound", err)
 	}
@@ -264,18 +282,18 @@ func TestUpdateAlertRejectsInvalidPartialFields(t *testing.T) {
 	src := newTestSource(t, db, "update-src")
 	ds := newFakeDatasourceService(db, log, nil)
 
-	alert, err := CreateAlert(ctx, db, ds, log, src.ID, owner.ID, newTestCreateAlertRequest())
+	alert, err := CreateAlert(ctx, db, ds, log, src.ID, owner.ID, true, newTestCreateAlertRequest())
 	if err != nil {
 		t.Fatalf("CreateAlert: %v", err)
 	}
 
 	zero := 0
-	if _, err := UpdateAlert(ctx, db, ds, log, alert.ID, &models.UpdateAlertRequest{FrequencySeconds: &zero}); !errors.Is(err, ErrInvalidAlertConfiguration) {
+	if _, err := UpdateAlert(ctx, db, ds, log, alert.ID, true, &models.UpdateAlertRequest{FrequencySeconds: &zero}); !errors.Is(err, ErrInvalidAlertConfiguration) {
 		t.Errorf("UpdateAlert(zero frequency) err = %v, want ErrInvalidAlertConfiguration", err)
 	}
 
 	badOp := models.AlertThresholdOperator("nonsense")
-	if _, err := UpdateAlert(ctx, db, ds, log, alert.ID, &models.UpdateAlertRequest{ThresholdOperator: &badOp}); !errors.Is(err, ErrInvalidAlertConfiguration) {
+	if _, err := UpdateAlert(ctx, db, ds, log, alert.ID, true, &models.UpdateAlertRequest{ThresholdOperator: &badOp}); !errors.Is(err, ErrInvalidAlertConfiguration) {
 		t.Errorf("UpdateAlert(bad operator) err = %v, want ErrInvalidAlertConfiguration", err)
 	}
 
@@ -289,6 +307,45 @@ func TestUpdateAlertRejectsInvalidPartialFields(t *testing.T) {
 	}
 }
 
+// TestAlertRawSQLSwitch covers alerts for a caller whose teams have raw SQL
+// switched off: a new ClickHouse SQL query is refused, while an existing one
+// can still be edited around as long as the query stays as stored.
+func TestAlertRawSQLSwitch(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	owner := newTestUser(t, db, "raw-sql-owner@example.com", "Owner")
+	src := newTestSource(t, db, "raw-sql-src")
+	ds := newFakeDatasourceService(db, log, nil)
+
+	if _, err := CreateAlert(ctx, db, ds, log, src.ID, owner.ID, false, newTestCreateAlertRequest()); !errors.Is(err, ErrRawSQLDisabled) {
+		t.Fatalf("CreateAlert(raw SQL off) err = %v, want ErrRawSQLDisabled", err)
+	}
+	if _, err := TestAlertQuery(ctx, db, ds, src.ID, false, &models.TestAlertQueryRequest{
+		QueryLanguage:     models.QueryLanguageClickHouseSQL,
+		EditorMode:        models.AlertEditorModeNative,
+		Query:             "SELECT count() FROM logs",
+		ThresholdOperator: models.AlertThresholdGreaterThan,
+	}); !errors.Is(err, ErrRawSQLDisabled) {
+		t.Errorf("TestAlertQuery(raw SQL off) err = %v, want ErrRawSQLDisabled", err)
+	}
+
+	alert, err := CreateAlert(ctx, db, ds, log, src.ID, owner.ID, true, newTestCreateAlertRequest())
+	if err != nil {
+		t.Fatalf("CreateAlert: %v", err)
+	}
+	name := "renamed"
+	if _, err := UpdateAlert(ctx, db, ds, log, alert.ID, false, &models.UpdateAlertRequest{Name: &name}); err != nil {
+		t.Errorf("UpdateAlert(rename, raw SQL off): %v", err)
+	}
+	query := "SELECT count() FROM logs WHERE level = 'error'"
+	if _, err := UpdateAlert(ctx, db, ds, log, alert.ID, false, &models.UpdateAlertRequest{Query: &query}); !errors.Is(err, ErrRawSQLDisabled) {
+		t.Errorf("UpdateAlert(new query, raw SQL off) err = %v, want ErrRawSQLDisabled", err)
+	}
+}
+
 func TestDeleteAlertNotFound(t *testing.T) {
 	t.Parallel()
 	db := newTestDB(t)
@@ -307,7 +364,7 @@ func TestResolveAlertNoUnresolvedHistory(t *testing.T) {
 	owner := newTestUser(t, db, "resolve-owner@example.com", "Owner")
 	src := newTestSource(t, db, "resolve-src")
 	ds := newFakeDatasourceService(db, log, nil)
-	alert, err := CreateAlert(ctx, db, ds, log, src.ID, owner.ID, newTestCreateAlertRequest())
+	alert, err := CreateAlert(ctx, db, ds, log, src.ID, owner.ID, true, newTestCreateAlertRequest())
 	if err != nil {
 		t.Fatalf("CreateAlert: %v", err)
 	}
@@ -344,7 +401,7 @@ func TestAlertQueryEvalWiring(t *testing.T) {
 		ThresholdOperator: models.AlertThresholdGreaterThan,
 		ThresholdValue:    10,
 	}
-	resp, err := TestAlertQuery(ctx, db, ds, src.ID, req)
+	resp, err := TestAlertQuery(ctx, db, ds, src.ID, true, req)
 	if err != nil {
 		t.Fatalf("TestAlertQuery: %v", err)
 	}
@@ -386,7 +443,7 @@ func TestAlertQueryNoRowsIsGraceful(t *testing.T) {
 		ThresholdOperator: models.AlertThresholdGreaterThan,
 		ThresholdValue:    10,
 	}
-	resp, err := TestAlertQuery(ctx, db, ds, src.ID, req)
+	resp, err := TestAlertQuery(ctx, db, ds, src.ID, true, req)
 	if err != nil {
 		t.Fatalf("TestAlertQuery: %v", err)
 	}
@@ -435,3 +492,45 @@ func TestCompareAlertThreshold(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildAlertTimeline(t *testing.T) {
+	t.Parallel()
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return from.Add(time.Duration(h) * time.Hour) }
+	ptr := func(t time.Time) *time.Time { return &t }
+	v1, v2 := 5.0, 9.0
+
+	alert := &models.Alert{CreatedAt: at(-48), LastState: models.AlertStatePending, PendingSince: ptr(at(22))}
+	history := []*models.AlertHistoryEntry{
+		// Opened before the window, resolved inside it.
+		{Status: models.AlertStatusResolved, TriggeredAt: at(-2), ResolvedAt: ptr(at(2)), Value: &v1},
+		{Status: models.AlertStatusError, TriggeredAt: at(5)},
+		// Back-to-back firings merge into one span.
+		{Status: models.AlertStatusResolved, TriggeredAt: at(10), ResolvedAt: ptr(at(12)), Value: &v2},
+		{Status: models.AlertStatusResolved, TriggeredAt: at(12), ResolvedAt: ptr(at(14)), Value: &v2},
+	}
+	got := BuildAlertTimeline(alert, history, from, at(24))
+	want := []models.AlertStateSpan{
+		{State: models.AlertStateFiring, Start: at(0), End: at(2), Value: &v1},
+		{State: models.AlertStateResolved, Start: at(2), End: at(10)},
+		{State: models.AlertStateFiring, Start: at(10), End: at(14), Value: &v2},
+		{State: models.AlertStateResolved, Start: at(14), End: at(22)},
+		{State: models.AlertStatePending, Start: at(22), End: at(24)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildAlertTimeline() = %+v, want %+v", got, want)
+	}
+
+	// An open entry fires until the end of the window, and nothing is shown
+	// from before the alert existed.
+	alert = &models.Alert{CreatedAt: at(6), LastState: models.AlertStateFiring}
+	history = []*models.AlertHistoryEntry{{Status: models.AlertStatusTriggered, TriggeredAt: at(20), Value: &v1}}
+	got = BuildAlertTimeline(alert, history, from, at(24))
+	want = []models.AlertStateSpan{
+		{State: models.AlertStateResolved, Start: at(6), End: at(20)},
+		{State: models.AlertStateFiring, Start: at(20), End: at(24), Value: &v1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildAlertTimeline(open) = %+v, want %+v", got, want)
+	}
+}
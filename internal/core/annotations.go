@@ -0,0 +1,221 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+var (
+	// ErrAnnotationNotFound is returned when an annotation cannot be located in
+	// the requested team.
+	ErrAnnotationNotFound = errors.New("annotation not found")
+	// ErrInvalidAnnotation indicates the annotation request failed validation.
+	ErrInvalidAnnotation = errors.New("invalid annotation")
+	// ErrAnnotationForbidden indicates the caller may not modify the annotation.
+	ErrAnnotationForbidden = errors.New("not authorized to edit this annotation")
+)
+
+// AnnotationFilter narrows ListAnnotations. Zero fields match everything.
+type AnnotationFilter struct {
+	// SourceID keeps team-wide annotations and those scoped to this source.
+	SourceID models.SourceID
+	// Start and End keep annotations overlapping the range; either may be nil.
+	Start *time.Time
+	End   *time.Time
+}
+
+// CreateAnnotation validates and stores an annotation on teamID's timeline,
+// owned by the caller.
+func CreateAnnotation(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, teamID models.TeamID, req *models.AnnotationRequest) (*models.Annotation, error) {
+	if req == nil || user == nil {
+		return nil, ErrInvalidAnnotation
+	}
+	owner := user.ID
+	annotation := &models.Annotation{TeamID: teamID, CreatedBy: &owner}
+	if err := applyAnnotationRequest(ctx, db, annotation, req, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	if err := db.CreateAnnotation(ctx, annotation); err != nil {
+		log.Error("failed to create annotation", "error", err, "team_id", teamID)
+		return nil, fmt.Errorf("error creating annotation: %w", err)
+	}
+	return annotation, nil
+}
+
+// GetAnnotation returns an annotation by id. One belonging to another team is
+// reported as not found so ids can't be probed across teams.
+func GetAnnotation(ctx context.Context, db store.StoreOps, teamID models.TeamID, id models.AnnotationID) (*models.Annotation, error) {
+	annotation, err := db.GetAnnotation(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrAnnotationNotFound
+		}
+		return nil, fmt.Errorf("error getting annotation: %w", err)
+	}
+	if annotation.TeamID != teamID {
+		return nil, ErrAnnotationNotFound
+	}
+	return annotation, nil
+}
+
+// ListAnnotations returns a team's annotations that match filter, newest start
+// first.
+func ListAnnotations(ctx context.Context, db store.StoreOps, teamID models.TeamID, filter AnnotationFilter) ([]*models.Annotation, error) {
+	all, err := db.ListAnnotationsByTeam(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing annotations: %w", err)
+	}
+	annotations := make([]*models.Annotation, 0, len(all))
+	for _, annotation := range all {
+		if filter.matches(annotation) {
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations, nil
+}
+
+func (f AnnotationFilter) matches(annotation *models.Annotation) bool {
+	if f.SourceID != 0 && annotation.SourceID != nil && *annotation.SourceID != f.SourceID {
+		return false
+	}
+	last := annotation.StartsAt
+	if annotation.EndsAt != nil {
+		last = *annotation.EndsAt
+	}
+	if f.Start != nil && last.Before(*f.Start) {
+		return false
+	}
+	return f.End == nil || !annotation.StartsAt.After(*f.End)
+}
+
+// ListHistogramAnnotations returns the annotations a histogram of sourceID in
+// teamID over [start, end] should mark, oldest first.
+func ListHistogramAnnotations(ctx context.Context, db store.StoreOps, teamID models.TeamID, sourceID models.SourceID, start, end time.Time) ([]*models.Annotation, error) {
+	annotations, err := db.ListAnnotationsInRange(ctx, teamID, sourceID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error listing histogram annotations: %w", err)
+	}
+	return annotations, nil
+}
+
+// UpdateAnnotation replaces an annotation's note, scope and range.
+func UpdateAnnotation(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, teamID models.TeamID, id models.AnnotationID, req *models.AnnotationRequest) (*models.Annotation, error) {
+	if req == nil || user == nil {
+		return nil, ErrInvalidAnnotation
+	}
+	annotation, err := GetAnnotation(ctx, db, teamID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireAnnotationEdit(ctx, db, annotation, user); err != nil {
+		return nil, err
+	}
+	if req.StartsAt == nil {
+		current := annotation.StartsAt
+		req.StartsAt = &current
+	}
+	if err := applyAnnotationRequest(ctx, db, annotation, req, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	if err := db.UpdateAnnotation(ctx, annotation); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrAnnotationNotFound
+		}
+		log.Error("failed to update annotation", "annotation_id", id, "error", err)
+		return nil, fmt.Errorf("error updating annotation: %w", err)
+	}
+	return GetAnnotation(ctx, db, teamID, id)
+}
+
+// DeleteAnnotation removes an annotation the caller may edit.
+func DeleteAnnotation(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, teamID models.TeamID, id models.AnnotationID) error {
+	annotation, err := GetAnnotation(ctx, db, teamID, id)
+	if err != nil {
+		return err
+	}
+	if err := requireAnnotationEdit(ctx, db, annotation, user); err != nil {
+		return err
+	}
+	if err := db.DeleteAnnotation(ctx, id); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return ErrAnnotationNotFound
+		}
+		log.Error("failed to delete annotation", "annotation_id", id, "error", err)
+		return fmt.Errorf("error deleting annotation: %w", err)
+	}
+	return nil
+}
+
+// UserCanEditAnnotation reports whether user may modify the annotation: global
+// admins always; otherwise the caller's team role must allow annotating, and
+// they must be the creator or a team admin/editor.
+func UserCanEditAnnotation(ctx context.Context, db store.StoreOps, annotation *models.Annotation, user *models.User) (bool, error) {
+	if annotation == nil || user == nil {
+		return false, nil
+	}
+	if user.Role == models.UserRoleAdmin {
+		return true, nil
+	}
+	member, err := db.GetTeamMember(ctx, annotation.TeamID, user.ID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error checking annotation edit access: %w", err)
+	}
+	if member == nil || !member.Role.HasPermission(models.TeamPermissionAnnotate) {
+		return false, nil
+	}
+	if annotation.CreatedBy != nil && *annotation.CreatedBy == user.ID {
+		return true, nil
+	}
+	return member.Role == models.TeamRoleAdmin || member.Role == models.TeamRoleEditor, nil
+}
+
+func requireAnnotationEdit(ctx context.Context, db store.StoreOps, annotation *models.Annotation, user *models.User) error {
+	canEdit, err := UserCanEditAnnotation(ctx, db, annotation, user)
+	if err != nil {
+		return err
+	}
+	if !canEdit {
+		return ErrAnnotationForbidden
+	}
+	return nil
+}
+
+// applyAnnotationRequest copies a request onto annotation and validates it. The
+// start defaults to now, and a source scope must be one of the team's sources.
+func applyAnnotationRequest(ctx context.Context, db store.StoreOps, annotation *models.Annotation, req *models.AnnotationRequest, now time.Time) error {
+	annotation.Text = req.Text
+	annotation.Tags = req.Tags
+	annotation.SourceID = req.SourceID
+	annotation.StartsAt = now
+	if req.StartsAt != nil && !req.StartsAt.IsZero() {
+		annotation.StartsAt = req.StartsAt.UTC()
+	}
+	annotation.EndsAt = nil
+	if req.EndsAt != nil {
+		end := req.EndsAt.UTC()
+		annotation.EndsAt = &end
+	}
+	if err := annotation.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidAnnotation, err)
+	}
+	if annotation.SourceID == nil {
+		return nil
+	}
+	linked, err := db.TeamHasSource(ctx, annotation.TeamID, *annotation.SourceID)
+	if err != nil {
+		return fmt.Errorf("failed to verify team/source link: %w", err)
+	}
+	if !linked {
+		return fmt.Errorf("%w: source %d is not linked to this team", ErrInvalidAnnotation, *annotation.SourceID)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestCreateAnnotationValidates(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	owner := newTestUser(t, db, "annotator@test.dev", "Owner")
+	team, src := seedTeamWithSource(t, db, "team-a", owner)
+	_, foreign := seedTeamWithSource(t, db, "team-b")
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	before := start.Add(-time.Minute)
+
+	cases := []struct {
+		name string
+		req  models.AnnotationRequest
+	}{
+		{"no text", models.AnnotationRequest{Text: "  "}},
+		{"ends before start", models.AnnotationRequest{Text: "deploy", StartsAt: &start, EndsAt: &before}},
+		{"foreign source", models.AnnotationRequest{Text: "deploy", SourceID: &foreign.ID}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := CreateAnnotation(ctx, db, log, owner, team.ID, &tc.req); !errors.Is(err, ErrInvalidAnnotation) {
+				t.Fatalf("err = %v, want ErrInvalidAnnotation", err)
+			}
+		})
+	}
+
+	annotation, err := CreateAnnotation(ctx, db, log, owner, team.ID, &models.AnnotationRequest{
+		SourceID: &src.ID, Text: " deploy v2 ", Tags: []string{"Deploy", "deploy", " "},
+	})
+	if err != nil {
+		t.Fatalf("CreateAnnotation: %v", err)
+	}
+	if annotation.Text != "deploy v2" || len(annotation.Tags) != 1 || annotation.Tags[0] != "deploy" ||
+		annotation.StartsAt.IsZero() || annotation.CreatedBy == nil || *annotation.CreatedBy != owner.ID {
+		t.Fatalf("unexpected annotation: %+v", annotation)
+	}
+}
+
+func TestAnnotationAccessAndFilters(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	owner := newTestUser(t, db, "owner@test.dev", "Owner")
+	colleague := newTestUser(t, db, "colleague@test.dev", "Colleague")
+	viewer := newTestUser(t, db, "viewer@test.dev", "Viewer")
+	team, src := seedTeamWithSource(t, db, "team-a", owner, colleague)
+	if err := AddTeamMember(ctx, db, log, team.ID, viewer.ID, models.TeamRoleViewer); err != nil {
+		t.Fatalf("AddTeamMember: %v", err)
+	}
+	other, _ := seedTeamWithSource(t, db, "team-b", owner)
+
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	ends := start.Add(2 * time.Hour)
+	incident, err := CreateAnnotation(ctx, db, log, owner, team.ID, &models.AnnotationRequest{
+		SourceID: &src.ID, Text: "incident", StartsAt: &start, EndsAt: &ends,
+	})
+	if err != nil {
+		t.Fatalf("CreateAnnotation: %v", err)
+	}
+	deployAt := start.Add(6 * time.Hour)
+	if _, err := CreateAnnotation(ctx, db, log, owner, team.ID, &models.AnnotationRequest{Text: "deploy", StartsAt: &deployAt}); err != nil {
+		t.Fatalf("CreateAnnotation(team-wide): %v", err)
+	}
+
+	// A range filter keeps what overlaps it, including ranges that began earlier.
+	from, to := start.Add(time.Hour), start.Add(3*time.Hour)
+	if list, err := ListAnnotations(ctx, db, team.ID, AnnotationFilter{Start: &from, End: &to}); err != nil || len(list) != 1 || list[0].ID != incident.ID {
+		t.Fatalf("ListAnnotations(range): %v / %+v", err, list)
+	}
+	if list, err := ListAnnotations(ctx, db, team.ID, AnnotationFilter{SourceID: src.ID}); err != nil || len(list) != 2 {
+		t.Fatalf("ListAnnotations(source) = %v / %d, want both", err, len(list))
+	}
+	if list, err := ListHistogramAnnotations(ctx, db, team.ID, src.ID, start.Add(-time.Hour), start); err != nil || len(list) != 1 {
+		t.Fatalf("ListHistogramAnnotations: %v / %+v", err, list)
+	}
+
+	// Annotations are team-scoped, and only the creator (or a team
+	// admin/editor) may change them.
+	if _, err := GetAnnotation(ctx, db, other.ID, incident.ID); !errors.Is(err, ErrAnnotationNotFound) {
+		t.Errorf("GetAnnotation(other team) err = %v, want ErrAnnotationNotFound", err)
+	}
+	if _, err := UpdateAnnotation(ctx, db, log, colleague, team.ID, incident.ID, &models.AnnotationRequest{Text: "mine now"}); !errors.Is(err, ErrAnnotationForbidden) {
+		t.Errorf("UpdateAnnotation(colleague) err = %v, want ErrAnnotationForbidden", err)
+	}
+	if err := DeleteAnnotation(ctx, db, log, viewer, team.ID, incident.ID); !errors.Is(err, ErrAnnotationForbidden) {
+		t.Errorf("DeleteAnnotation(viewer) err = %v, want ErrAnnotationForbidden", err)
+	}
+
+	// Updating without a start keeps the stored one.
+	updated, err := UpdateAnnotation(ctx, db, log, owner, team.ID, incident.ID, &models.AnnotationRequest{Text: "incident resolved", EndsAt: &ends})
+	if err != nil {
+		t.Fatalf("UpdateAnnotation: %v", err)
+	}
+	if updated.Text != "incident resolved" || !updated.StartsAt.Equal(start) || updated.SourceID != nil {
+		t.Fatalf("updated = %+v", updated)
+	}
+
+	if err := DeleteAnnotation(ctx, db, log, owner, team.ID, incident.ID); err != nil {
+		t.Fatalf("DeleteAnnotation: %v", err)
+	}
+	if _, err := GetAnnotation(ctx, db, team.ID, incident.ID); !errors.Is(err, ErrAnnotationNotFound) {
+		t.Errorf("GetAnnotation(deleted) err = %v, want ErrAnnotationNotFound", err)
+	}
+}
@@ -69,6 +69,7 @@ var validTokenScopes = map[models.TokenScope]struct{}{
 	models.TokenScopeSourcesRead:       {},
 	models.TokenScopeSourcesWrite:      {},
 	models.TokenScopeLogsRead:          {},
+	models.TokenScopeLogsWrite:         {},
 	models.TokenScopeSavedQueriesRead:  {},
 	models.TokenScopeSavedQueriesWrite: {},
 	models.TokenScopeCollectionsRead:   {},
@@ -77,10 +78,17 @@ var validTokenScopes = map[models.TokenScope]struct{}{
 	models.TokenScopeAlertsWrite:       {},
 	models.TokenScopeDashboardsRead:    {},
 	models.TokenScopeDashboardsWrite:   {},
+	models.TokenScopeNotebooksRead:     {},
+	models.TokenScopeNotebooksWrite:    {},
 	models.TokenScopeQuerySharesRead:   {},
 	models.TokenScopeQuerySharesWrite:  {},
+	models.TokenScopeAnnotationsRead:   {},
+	models.TokenScopeAnnotationsWrite:  {},
+	models.TokenScopeBookmarksRead:     {},
+	models.TokenScopeBookmarksWrite:    {},
 	models.TokenScopeSettingsRead:      {},
 	models.TokenScopeSettingsWrite:     {},
+	models.TokenScopeAuditRead:         {},
 }
 
 var readOnlyTokenScopes = []models.TokenScope{
@@ -94,8 +102,12 @@ var readOnlyTokenScopes = []models.TokenScope{
 	models.TokenScopeCollectionsRead,
 	models.TokenScopeAlertsRead,
 	models.TokenScopeDashboardsRead,
+	models.TokenScopeNotebooksRead,
 	models.TokenScopeQuerySharesRead,
+	models.TokenScopeAnnotationsRead,
+	models.TokenScopeBookmarksRead,
 	models.TokenScopeSettingsRead,
+	models.TokenScopeAuditRead,
 }
 
 // ReadOnlyTokenScopes returns the common read-only preset used by service tokens.
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+var (
+	// ErrColumnPresetNotFound is returned when there is no preset to remove.
+	ErrColumnPresetNotFound = errors.New("column preset not found")
+	// ErrInvalidColumnPreset indicates the preset request failed validation.
+	ErrInvalidColumnPreset = errors.New("invalid column preset")
+)
+
+// GetColumnPresets returns userID's own column preset for sourceID, teamID's
+// default for it, and the one that applies: the personal preset when there is
+// one, otherwise the team default.
+func GetColumnPresets(ctx context.Context, db store.StoreOps, userID models.UserID, teamID models.TeamID, sourceID models.SourceID) (*models.ColumnPresets, error) {
+	presets := &models.ColumnPresets{}
+	personal, err := db.GetUserColumnPreset(ctx, userID, sourceID)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return nil, fmt.Errorf("error getting user column preset: %w", err)
+	}
+	presets.Personal = personal
+	team, err := db.GetTeamColumnPreset(ctx, teamID, sourceID)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return nil, fmt.Errorf("error getting team column preset: %w", err)
+	}
+	presets.Team = team
+
+	presets.Effective = presets.Personal
+	if presets.Effective == nil {
+		presets.Effective = presets.Team
+	}
+	return presets, nil
+}
+
+// SaveUserColumnPreset validates and stores userID's column layout for
+// sourceID, replacing any earlier one.
+func SaveUserColumnPreset(ctx context.Context, db store.StoreOps, log *slog.Logger, userID models.UserID, sourceID models.SourceID, req *models.ColumnPresetRequest) (*models.ColumnPreset, error) {
+	if req == nil {
+		return nil, ErrInvalidColumnPreset
+	}
+	if err := models.ValidateColumnPresetColumns(req.Columns); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidColumnPreset, err)
+	}
+	preset := &models.ColumnPreset{SourceID: sourceID, UserID: &userID, Columns: req.Columns}
+	if err := db.SaveUserColumnPreset(ctx, preset); err != nil {
+		log.Error("failed to save user column preset", "error", err, "user_id", userID, "source_id", sourceID)
+		return nil, fmt.Errorf("error saving column preset: %w", err)
+	}
+	return preset, nil
+}
+
+// DeleteUserColumnPreset removes userID's column layout for sourceID, so the
+// team default (if any) applies again.
+func DeleteUserColumnPreset(ctx context.Context, db store.StoreOps, log *slog.Logger, userID models.UserID, sourceID models.SourceID) error {
+	if err := db.DeleteUserColumnPreset(ctx, userID, sourceID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return ErrColumnPresetNotFound
+		}
+		log.Error("failed to delete user column preset", "error", err, "user_id", userID, "source_id", sourceID)
+		return fmt.Errorf("error deleting column preset: %w", err)
+	}
+	return nil
+}
+
+// SaveTeamColumnPreset validates and stores teamID's default column layout
+// for sourceID on behalf of user, replacing any earlier one.
+func SaveTeamColumnPreset(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, teamID models.TeamID, sourceID models.SourceID, req *models.ColumnPresetRequest) (*models.ColumnPreset, error) {
+	if req == nil || user == nil {
+		return nil, ErrInvalidColumnPreset
+	}
+	if err := models.ValidateColumnPresetColumns(req.Columns); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidColumnPreset, err)
+	}
+	updatedBy := user.ID
+	preset := &models.ColumnPreset{SourceID: sourceID, TeamID: &teamID, Columns: req.Columns, UpdatedBy: &updatedBy}
+	if err := db.SaveTeamColumnPreset(ctx, preset); err != nil {
+		log.Error("failed to save team column preset", "error", err, "team_id", teamID, "source_id", sourceID)
+		return nil, fmt.Errorf("error saving column preset: %w", err)
+	}
+	return preset, nil
+}
+
+// DeleteTeamColumnPreset removes teamID's default column layout for sourceID.
+func DeleteTeamColumnPreset(ctx context.Context, db store.StoreOps, log *slog.Logger, teamID models.TeamID, sourceID models.SourceID) error {
+	if err := db.DeleteTeamColumnPreset(ctx, teamID, sourceID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return ErrColumnPresetNotFound
+		}
+		log.Error("failed to delete team column preset", "error", err, "team_id", teamID, "source_id", sourceID)
+		return fmt.Errorf("error deleting column preset: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestColumnPresetsPersonalOverTeam(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	user := newTestUser(t, db, "layout@test.dev", "Layout")
+	lead := newTestUser(t, db, "lead@test.dev", "Lead")
+	team, src := seedTeamWithSource(t, db, "team-a", user, lead)
+
+	if _, err := SaveUserColumnPreset(ctx, db, log, user.ID, src.ID, &models.ColumnPresetRequest{
+		Columns: []models.ColumnPresetColumn{{Name: "msg"}, {Name: " msg "}},
+	}); !errors.Is(err, ErrInvalidColumnPreset) {
+		t.Fatalf("SaveUserColumnPreset(duplicate) err = %v, want ErrInvalidColumnPreset", err)
+	}
+	if _, err := SaveUserColumnPreset(ctx, db, log, user.ID, src.ID, &models.ColumnPresetRequest{
+		Columns: []models.ColumnPresetColumn{{Name: "msg", Format: "sparkline"}},
+	}); !errors.Is(err, ErrInvalidColumnPreset) {
+		t.Fatalf("SaveUserColumnPreset(unknown format) err = %v, want ErrInvalidColumnPreset", err)
+	}
+
+	presets, err := GetColumnPresets(ctx, db, user.ID, team.ID, src.ID)
+	if err != nil || presets.Personal != nil || presets.Team != nil || presets.Effective != nil {
+		t.Fatalf("GetColumnPresets(none) = %v / %+v", err, presets)
+	}
+
+	teamDefault, err := SaveTeamColumnPreset(ctx, db, log, lead, team.ID, src.ID, &models.ColumnPresetRequest{
+		Columns: []models.ColumnPresetColumn{{Name: "service"}, {Name: "msg", Wrap: true}},
+	})
+	if err != nil {
+		t.Fatalf("SaveTeamColumnPreset: %v", err)
+	}
+	if presets, _ := GetColumnPresets(ctx, db, user.ID, team.ID, src.ID); presets.Effective == nil || presets.Effective.TeamID == nil {
+		t.Fatalf("team default does not apply: %+v", presets)
+	}
+
+	if _, err := SaveUserColumnPreset(ctx, db, log, user.ID, src.ID, &models.ColumnPresetRequest{
+		Columns: []models.ColumnPresetColumn{{Name: "level", Width: 80}},
+	}); err != nil {
+		t.Fatalf("SaveUserColumnPreset: %v", err)
+	}
+	presets, _ = GetColumnPresets(ctx, db, user.ID, team.ID, src.ID)
+	if presets.Effective == nil || presets.Effective.UserID == nil || presets.Team == nil || len(presets.Team.Columns) != len(teamDefault.Columns) {
+		t.Fatalf("personal preset does not take precedence: %+v", presets)
+	}
+	// Another member still gets the team default.
+	if other, _ := GetColumnPresets(ctx, db, lead.ID, team.ID, src.ID); other.Effective == nil || other.Effective.TeamID == nil {
+		t.Fatalf("GetColumnPresets(lead) = %+v, want the team default", other)
+	}
+
+	// Resetting the personal preset falls back to the team default.
+	if err := DeleteUserColumnPreset(ctx, db, log, user.ID, src.ID); err != nil {
+		t.Fatalf("DeleteUserColumnPreset: %v", err)
+	}
+	if err := DeleteUserColumnPreset(ctx, db, log, user.ID, src.ID); !errors.Is(err, ErrColumnPresetNotFound) {
+		t.Fatalf("DeleteUserColumnPreset(again) err = %v, want ErrColumnPresetNotFound", err)
+	}
+	if presets, _ := GetColumnPresets(ctx, db, user.ID, team.ID, src.ID); presets.Effective == nil || presets.Effective.TeamID == nil {
+		t.Fatalf("after reset: %+v, want the team default", presets)
+	}
+}
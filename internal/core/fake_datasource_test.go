@@ -12,14 +12,16 @@ import (
 // fakeProvider is a minimal datasource.Provider stand-in so alert/saved-query
 // tests can exercise the real validation + eval wiring (datasource.Service)
 // without a live ClickHouse/VictoriaLogs connection. Only the methods actually
-// exercised by CreateAlert/UpdateAlert/TestAlertQuery/CreateSavedQuery have
-// meaningful bodies; the rest satisfy the interface with zero values since the
-// core package never calls them in these tests.
+// exercised by CreateAlert/UpdateAlert/TestAlertQuery/CreateSavedQuery and
+// RunNotebookCell have meaningful bodies; the rest satisfy the interface with
+// zero values since the core package never calls them in these tests.
 type fakeProvider struct {
 	queryLanguages  []models.QueryLanguage
 	savedQueryModes []models.SavedQueryEditorMode
 	alertModes      []models.AlertEditorMode
 	evaluateAlertFn func(ctx context.Context, source *models.Source, req datasource.AlertQueryRequest) (*models.QueryResult, error)
+	queryLogsFn     func(ctx context.Context, source *models.Source, req datasource.QueryRequest) (*models.QueryResult, error)
+	histogramFn     func(ctx context.Context, source *models.Source, req datasource.HistogramRequest) (*datasource.HistogramResult, error)
 }
 
 func (f *fakeProvider) Type() models.SourceType { return models.SourceTypeClickHouse }
@@ -48,7 +50,10 @@ func (f *fakeProvider) UpdateSource(context.Context, *models.Source, *models.Upd
 
 func (f *fakeProvider) PopulateSourceDetails(context.Context, *models.Source) error { return nil }
 
-func (f *fakeProvider) QueryLogs(context.Context, *models.Source, datasource.QueryRequest) (*models.QueryResult, error) {
+func (f *fakeProvider) QueryLogs(ctx context.Context, source *models.Source, req datasource.QueryRequest) (*models.QueryResult, error) {
+	if f.queryLogsFn != nil {
+		return f.queryLogsFn(ctx, source, req)
+	}
 	return nil, nil
 }
 
@@ -56,7 +61,10 @@ func (f *fakeProvider) GetSourceSchema(context.Context, *models.Source) ([]model
 	return nil, nil
 }
 
-func (f *fakeProvider) Histogram(context.Context, *models.Source, datasource.HistogramRequest) (*datasource.HistogramResult, error) {
+func (f *fakeProvider) Histogram(ctx context.Context, source *models.Source, req datasource.HistogramRequest) (*datasource.HistogramResult, error) {
+	if f.histogramFn != nil {
+		return f.histogramFn(ctx, source, req)
+	}
 	return nil, nil
 }
 
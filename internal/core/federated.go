@@ -0,0 +1,213 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// MaxFederatedSources caps how many sources one federated query may fan out to.
+const MaxFederatedSources = 10
+
+// Columns added to every federated row to name the source it came from.
+const (
+	FederatedSourceIDField   = "_source_id"
+	FederatedSourceNameField = "_source"
+)
+
+// FederatedQuery is one source's share of a federated query. Params is the
+// query already compiled for that source.
+type FederatedQuery struct {
+	Source *models.Source
+	Params datasource.QueryRequest
+}
+
+// FederatedSourceResult reports how one source of a federated query fared.
+type FederatedSourceResult struct {
+	SourceID   models.SourceID   `json:"source_id"`
+	SourceName string            `json:"source_name"`
+	Rows       int               `json:"rows"`
+	Stats      models.QueryStats `json:"stats"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// FederatedResult is the merged result of a federated query. Logs are sorted
+// newest first by each source's timestamp field and cut to the limit.
+type FederatedResult struct {
+	Logs     []map[string]any        `json:"logs"`
+	Columns  []models.ColumnInfo     `json:"columns"`
+	Stats    models.QueryStats       `json:"stats"`
+	Sources  []FederatedSourceResult `json:"sources"`
+	Warnings []models.QueryWarning   `json:"warnings,omitempty"`
+}
+
+// ResolveFederatedSources loads the sources of a federated query. It needs
+// between two and MaxFederatedSources distinct sources of one source type, so
+// a single query can be compiled for each of them.
+func ResolveFederatedSources(ctx context.Context, db store.Store, ids []models.SourceID) ([]*models.Source, error) {
+	if len(ids) < 2 {
+		return nil, &ValidationError{Field: "source_ids", Message: "at least two sources are required"}
+	}
+	if len(ids) > MaxFederatedSources {
+		return nil, &ValidationError{Field: "source_ids", Message: fmt.Sprintf("at most %d sources are allowed", MaxFederatedSources)}
+	}
+
+	sources := make([]*models.Source, 0, len(ids))
+	seen := make(map[models.SourceID]struct{}, len(ids))
+	for _, id := range ids {
+		if _, dup := seen[id]; dup {
+			return nil, &ValidationError{Field: "source_ids", Message: fmt.Sprintf("source %d is listed twice", id)}
+		}
+		seen[id] = struct{}{}
+
+		source, err := db.GetSource(ctx, id)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return nil, ErrSourceNotFound
+			}
+			return nil, fmt.Errorf("error getting source %d: %w", id, err)
+		}
+		if len(sources) > 0 && source.SourceType != sources[0].SourceType {
+			return nil, &ValidationError{Field: "source_ids", Message: "all sources must have the same source type"}
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// QueryLogsFederated runs each source's query concurrently and merges the
+// rows. Every row gains FederatedSourceIDField and FederatedSourceNameField.
+// A source that fails is reported in Sources and as a warning; the query only
+// fails when every source does.
+func QueryLogsFederated(ctx context.Context, ds *datasource.Service, queries []FederatedQuery, limit int) (*FederatedResult, error) {
+	start := time.Now()
+	results := make([]*models.QueryResult, len(queries))
+	errs := make([]error, len(queries))
+
+	var wg sync.WaitGroup
+	for i, q := range queries {
+		wg.Go(func() {
+			results[i], errs[i] = QueryLogs(ctx, ds, q.Source.ID, q.Params)
+		})
+	}
+	wg.Wait()
+
+	merged := &FederatedResult{
+		Columns: []models.ColumnInfo{
+			{Name: FederatedSourceIDField, Type: "UInt32"},
+			{Name: FederatedSourceNameField, Type: "String"},
+		},
+		Sources: make([]FederatedSourceResult, len(queries)),
+	}
+	seenColumns := map[string]struct{}{FederatedSourceIDField: {}, FederatedSourceNameField: {}}
+	type stampedRow struct {
+		row map[string]any
+		ts  time.Time
+		ok  bool
+	}
+	var rows []stampedRow
+	failed := 0
+
+	for i, q := range queries {
+		status := FederatedSourceResult{SourceID: q.Source.ID, SourceName: q.Source.Name}
+		if errs[i] != nil {
+			failed++
+			status.Error = errs[i].Error()
+			merged.Sources[i] = status
+			merged.Warnings = append(merged.Warnings, models.QueryWarning{
+				Code:    "FEDERATED_SOURCE_FAILED",
+				Message: fmt.Sprintf("Source %q failed: %v", q.Source.Name, errs[i]),
+			})
+			continue
+		}
+		result := results[i]
+		if result == nil {
+			result = &models.QueryResult{}
+		}
+		status.Rows = len(result.Logs)
+		status.Stats = result.Stats
+		merged.Sources[i] = status
+
+		for _, col := range result.Columns {
+			if _, ok := seenColumns[col.Name]; !ok {
+				seenColumns[col.Name] = struct{}{}
+				merged.Columns = append(merged.Columns, col)
+			}
+		}
+		for _, w := range result.Warnings {
+			w.Message = fmt.Sprintf("%s: %s", q.Source.Name, w.Message)
+			merged.Warnings = append(merged.Warnings, w)
+		}
+		merged.Stats.RowsRead += result.Stats.RowsRead
+		merged.Stats.BytesRead += result.Stats.BytesRead
+		// The sources are queried in parallel, so their peaks overlap.
+		merged.Stats.PeakMemoryBytes += result.Stats.PeakMemoryBytes
+		merged.Stats.Truncated = merged.Stats.Truncated || result.Stats.Truncated
+
+		for _, row := range result.Logs {
+			row[FederatedSourceIDField] = q.Source.ID
+			row[FederatedSourceNameField] = q.Source.Name
+			ts, ok := federatedTimestamp(row[q.Source.MetaTSField])
+			rows = append(rows, stampedRow{row: row, ts: ts, ok: ok})
+		}
+	}
+
+	if len(queries) > 0 && failed == len(queries) {
+		return nil, fmt.Errorf("all federated sources failed: %w", errors.Join(errs...))
+	}
+
+	// Newest first; rows without a readable timestamp go last. The sort is
+	// stable so rows keep their source's order on ties.
+	slices.SortStableFunc(rows, func(a, b stampedRow) int {
+		if a.ok != b.ok {
+			if a.ok {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(b.ts.UnixNano(), a.ts.UnixNano())
+	})
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+		merged.Stats.Truncated = true
+		merged.Stats.TruncatedReason = "row_limit"
+	}
+
+	merged.Logs = make([]map[string]any, len(rows))
+	for i, r := range rows {
+		merged.Logs[i] = r.row
+	}
+	merged.Stats.RowsReturned = len(merged.Logs)
+	merged.Stats.LimitApplied = limit
+	merged.Stats.ExecutionTimeMs = float64(time.Since(start).Milliseconds())
+	return merged, nil
+}
+
+// federatedTimestamp reads a row's timestamp value as returned by the
+// providers: time.Time from ClickHouse, an RFC3339 string from VictoriaLogs.
+func federatedTimestamp(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case *time.Time:
+		if t != nil {
+			return *t, true
+		}
+	case string:
+		if parsed, err := time.Parse(time.RFC3339Nano, t); err == nil {
+			return parsed, true
+		}
+		if parsed, err := time.Parse("2006-01-02 15:04:05.999999999", t); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
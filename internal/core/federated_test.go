@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestResolveFederatedSources(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	prod := newTestSource(t, db, "prod")
+	staging := newTestSource(t, db, "staging")
+	vl := &models.Source{
+		Name:             "vl",
+		SourceType:       models.SourceTypeVictoriaLogs,
+		ConnectionConfig: json.RawMessage(`{"base_url": "http://vl:9428"}`),
+	}
+	if err := db.CreateSource(ctx, vl); err != nil {
+		t.Fatalf("CreateSource(vl): %v", err)
+	}
+
+	sources, err := ResolveFederatedSources(ctx, db, []models.SourceID{staging.ID, prod.ID})
+	if err != nil {
+		t.Fatalf("ResolveFederatedSources: %v", err)
+	}
+	if len(sources) != 2 || sources[0].ID != staging.ID || sources[1].ID != prod.ID {
+		t.Fatalf("sources = %+v, want staging then prod", sources)
+	}
+
+	var validationErr *ValidationError
+	for name, ids := range map[string][]models.SourceID{
+		"single source": {prod.ID},
+		"duplicate":     {prod.ID, prod.ID},
+		"mixed types":   {prod.ID, vl.ID},
+	} {
+		if _, err := ResolveFederatedSources(ctx, db, ids); !errors.As(err, &validationErr) {
+			t.Errorf("%s: err = %v, want ValidationError", name, err)
+		}
+	}
+	if _, err := ResolveFederatedSources(ctx, db, []models.SourceID{prod.ID, 9999}); !errors.Is(err, ErrSourceNotFound) {
+		t.Errorf("missing source: err = %v, want ErrSourceNotFound", err)
+	}
+}
+
+func TestQueryLogsFederatedMergesByTime(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	prod := newTestSource(t, db, "prod")
+	staging := newTestSource(t, db, "staging")
+	broken := newTestSource(t, db, "broken")
+	for _, s := range []*models.Source{prod, staging, broken} {
+		s.MetaTSField = "timestamp"
+	}
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rowsBySource := map[models.SourceID][]map[string]any{
+		prod.ID: {
+			{"timestamp": base.Add(3 * time.Minute), "msg": "p3"},
+			{"timestamp": base.Add(1 * time.Minute), "msg": "p1"},
+		},
+		staging.ID: {
+			{"timestamp": base.Add(4 * time.Minute), "msg": "s4"},
+			{"timestamp": base.Add(2 * time.Minute), "msg": "s2"},
+		},
+	}
+	ds := newFakeDatasourceService(db, discardLogger(), &fakeProvider{
+		queryLogsFn: func(_ context.Context, source *models.Source, _ datasource.QueryRequest) (*models.QueryResult, error) {
+			if source.ID == broken.ID {
+				return nil, errors.New("connection refused")
+			}
+			return &models.QueryResult{
+				Logs:    rowsBySource[source.ID],
+				Columns: []models.ColumnInfo{{Name: "timestamp", Type: "DateTime64(3)"}, {Name: "msg", Type: "String"}},
+				Stats:   models.QueryStats{RowsRead: 10},
+			}, nil
+		},
+	})
+
+	queries := []FederatedQuery{{Source: prod}, {Source: staging}, {Source: broken}}
+	result, err := QueryLogsFederated(ctx, ds, queries, 3)
+	if err != nil {
+		t.Fatalf("QueryLogsFederated: %v", err)
+	}
+
+	var got []string
+	for _, row := range result.Logs {
+		got = append(got, row["msg"].(string))
+	}
+	if want := []string{"s4", "p3", "s2"}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("merged order = %v, want %v", got, want)
+	}
+	if result.Logs[0][FederatedSourceNameField] != "staging" || result.Logs[1][FederatedSourceIDField] != prod.ID {
+		t.Errorf("rows not annotated with their source: %v", result.Logs[:2])
+	}
+	if !result.Stats.Truncated || result.Stats.RowsRead != 20 || result.Stats.RowsReturned != 3 {
+		t.Errorf("unexpected stats: %+v", result.Stats)
+	}
+	if len(result.Columns) != 4 || result.Columns[0].Name != FederatedSourceIDField {
+		t.Errorf("unexpected columns: %+v", result.Columns)
+	}
+	if result.Sources[2].Error == "" || len(result.Warnings) != 1 || result.Warnings[0].Code != "FEDERATED_SOURCE_FAILED" {
+		t.Errorf("failed source not reported: sources=%+v warnings=%+v", result.Sources, result.Warnings)
+	}
+
+	if _, err := QueryLogsFederated(ctx, ds, []FederatedQuery{{Source: broken}, {Source: broken}}, 10); err == nil {
+		t.Fatal("expected an error when every source fails")
+	}
+}
@@ -0,0 +1,198 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+var (
+	// ErrLogBookmarkNotFound is returned when a bookmark doesn't exist or isn't
+	// visible to the caller in the requested team.
+	ErrLogBookmarkNotFound = errors.New("log bookmark not found")
+	// ErrInvalidLogBookmark indicates the bookmark request failed validation.
+	ErrInvalidLogBookmark = errors.New("invalid log bookmark")
+	// ErrLogBookmarkForbidden indicates the caller may not modify the bookmark.
+	ErrLogBookmarkForbidden = errors.New("not authorized to edit this log bookmark")
+	// ErrLogBookmarkExists indicates the caller already pinned this row.
+	ErrLogBookmarkExists = errors.New("log row is already bookmarked")
+)
+
+// CreateLogBookmark pins a log row from one of teamID's sources for the caller.
+// The row is stored as a canonical snapshot and fingerprinted, so pinning the
+// same row twice is rejected with ErrLogBookmarkExists.
+func CreateLogBookmark(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, teamID models.TeamID, req *models.CreateLogBookmarkRequest) (*models.LogBookmark, error) {
+	if req == nil || user == nil {
+		return nil, ErrInvalidLogBookmark
+	}
+	if req.SourceID <= 0 {
+		return nil, fmt.Errorf("%w: source_id is required", ErrInvalidLogBookmark)
+	}
+	if req.Timestamp == nil || req.Timestamp.IsZero() {
+		return nil, fmt.Errorf("%w: timestamp is required", ErrInvalidLogBookmark)
+	}
+	note, err := normalizeLogBookmarkNote(req.Note)
+	if err != nil {
+		return nil, err
+	}
+	payload, fingerprint, err := models.CanonicalLogPayload(req.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidLogBookmark, err)
+	}
+	linked, err := db.TeamHasSource(ctx, teamID, req.SourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify team/source link: %w", err)
+	}
+	if !linked {
+		return nil, fmt.Errorf("%w: source %d is not linked to this team", ErrInvalidLogBookmark, req.SourceID)
+	}
+
+	bookmark := &models.LogBookmark{
+		TeamID:       teamID,
+		SourceID:     req.SourceID,
+		UserID:       user.ID,
+		Shared:       req.Shared,
+		LogTimestamp: req.Timestamp.UTC(),
+		Fingerprint:  fingerprint,
+		Payload:      payload,
+		Note:         note,
+	}
+	if err := db.CreateLogBookmark(ctx, bookmark); err != nil {
+		if errors.Is(err, models.ErrConflict) {
+			return nil, ErrLogBookmarkExists
+		}
+		log.Error("failed to create log bookmark", "error", err, "team_id", teamID, "source_id", req.SourceID)
+		return nil, fmt.Errorf("error creating log bookmark: %w", err)
+	}
+	return bookmark, nil
+}
+
+// GetLogBookmark returns a bookmark the caller can see in teamID: their own or
+// a shared one. Anything else is reported as not found.
+func GetLogBookmark(ctx context.Context, db store.StoreOps, user *models.User, teamID models.TeamID, id models.LogBookmarkID) (*models.LogBookmark, error) {
+	bookmark, err := db.GetLogBookmark(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrLogBookmarkNotFound
+		}
+		return nil, fmt.Errorf("error getting log bookmark: %w", err)
+	}
+	if bookmark.TeamID != teamID || (!bookmark.Shared && bookmark.UserID != user.ID) {
+		return nil, ErrLogBookmarkNotFound
+	}
+	return bookmark, nil
+}
+
+// ListLogBookmarks returns the caller's bookmarks in teamID and the team's
+// shared ones, most recently pinned first. A non-zero sourceID keeps only
+// that source's.
+func ListLogBookmarks(ctx context.Context, db store.StoreOps, user *models.User, teamID models.TeamID, sourceID models.SourceID) ([]*models.LogBookmark, error) {
+	all, err := db.ListLogBookmarksForUser(ctx, teamID, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing log bookmarks: %w", err)
+	}
+	if sourceID == 0 {
+		return all, nil
+	}
+	bookmarks := make([]*models.LogBookmark, 0, len(all))
+	for _, bookmark := range all {
+		if bookmark.SourceID == sourceID {
+			bookmarks = append(bookmarks, bookmark)
+		}
+	}
+	return bookmarks, nil
+}
+
+// UpdateLogBookmark changes a bookmark's note and visibility.
+func UpdateLogBookmark(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, teamID models.TeamID, id models.LogBookmarkID, req *models.UpdateLogBookmarkRequest) (*models.LogBookmark, error) {
+	if req == nil || user == nil {
+		return nil, ErrInvalidLogBookmark
+	}
+	bookmark, err := GetLogBookmark(ctx, db, user, teamID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireLogBookmarkEdit(ctx, db, bookmark, user); err != nil {
+		return nil, err
+	}
+	note, err := normalizeLogBookmarkNote(req.Note)
+	if err != nil {
+		return nil, err
+	}
+	bookmark.Note = note
+	bookmark.Shared = req.Shared
+	if err := db.UpdateLogBookmark(ctx, bookmark); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrLogBookmarkNotFound
+		}
+		log.Error("failed to update log bookmark", "bookmark_id", id, "error", err)
+		return nil, fmt.Errorf("error updating log bookmark: %w", err)
+	}
+	return GetLogBookmark(ctx, db, user, teamID, id)
+}
+
+// DeleteLogBookmark unpins a bookmark the caller may edit.
+func DeleteLogBookmark(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, teamID models.TeamID, id models.LogBookmarkID) error {
+	bookmark, err := GetLogBookmark(ctx, db, user, teamID, id)
+	if err != nil {
+		return err
+	}
+	if err := requireLogBookmarkEdit(ctx, db, bookmark, user); err != nil {
+		return err
+	}
+	if err := db.DeleteLogBookmark(ctx, id); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return ErrLogBookmarkNotFound
+		}
+		log.Error("failed to delete log bookmark", "bookmark_id", id, "error", err)
+		return fmt.Errorf("error deleting log bookmark: %w", err)
+	}
+	return nil
+}
+
+// UserCanEditLogBookmark reports whether user may change or remove the
+// bookmark: whoever pinned it and global admins, plus team admins for shared
+// bookmarks so they can tidy the team's list.
+func UserCanEditLogBookmark(ctx context.Context, db store.StoreOps, bookmark *models.LogBookmark, user *models.User) (bool, error) {
+	if bookmark == nil || user == nil {
+		return false, nil
+	}
+	if bookmark.UserID == user.ID || user.Role == models.UserRoleAdmin {
+		return true, nil
+	}
+	if !bookmark.Shared {
+		return false, nil
+	}
+	member, err := db.GetTeamMember(ctx, bookmark.TeamID, user.ID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error checking log bookmark edit access: %w", err)
+	}
+	return member != nil && member.Role == models.TeamRoleAdmin, nil
+}
+
+func requireLogBookmarkEdit(ctx context.Context, db store.StoreOps, bookmark *models.LogBookmark, user *models.User) error {
+	canEdit, err := UserCanEditLogBookmark(ctx, db, bookmark, user)
+	if err != nil {
+		return err
+	}
+	if !canEdit {
+		return ErrLogBookmarkForbidden
+	}
+	return nil
+}
+
+func normalizeLogBookmarkNote(note string) (string, error) {
+	note = strings.TrimSpace(note)
+	if len(note) > models.MaxLogBookmarkNoteLength {
+		return "", fmt.Errorf("%w: note must be at most %d characters", ErrInvalidLogBookmark, models.MaxLogBookmarkNoteLength)
+	}
+	return note, nil
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestCreateLogBookmark(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	owner := newTestUser(t, db, "pinner@test.dev", "Pinner")
+	team, src := seedTeamWithSource(t, db, "team-a", owner)
+	_, foreign := seedTeamWithSource(t, db, "team-b")
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name string
+		req  models.CreateLogBookmarkRequest
+	}{
+		{"no source", models.CreateLogBookmarkRequest{Timestamp: &at, Payload: json.RawMessage(`{"a":1}`)}},
+		{"no timestamp", models.CreateLogBookmarkRequest{SourceID: src.ID, Payload: json.RawMessage(`{"a":1}`)}},
+		{"payload not an object", models.CreateLogBookmarkRequest{SourceID: src.ID, Timestamp: &at, Payload: json.RawMessage(`[1]`)}},
+		{"foreign source", models.CreateLogBookmarkRequest{SourceID: foreign.ID, Timestamp: &at, Payload: json.RawMessage(`{"a":1}`)}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := CreateLogBookmark(ctx, db, log, owner, team.ID, &tc.req); !errors.Is(err, ErrInvalidLogBookmark) {
+				t.Fatalf("err = %v, want ErrInvalidLogBookmark", err)
+			}
+		})
+	}
+
+	bookmark, err := CreateLogBookmark(ctx, db, log, owner, team.ID, &models.CreateLogBookmarkRequest{
+		SourceID: src.ID, Timestamp: &at, Note: " first panic ",
+		Payload: json.RawMessage(`{"msg":"panic","trace_id":18446744073709551615}`),
+	})
+	if err != nil {
+		t.Fatalf("CreateLogBookmark: %v", err)
+	}
+	if bookmark.Note != "first panic" || bookmark.Fingerprint == "" ||
+		string(bookmark.Payload) != `{"msg":"panic","trace_id":18446744073709551615}` {
+		t.Fatalf("unexpected bookmark: %+v (payload %s)", bookmark, bookmark.Payload)
+	}
+
+	// The same row with its keys in another order is a duplicate.
+	_, err = CreateLogBookmark(ctx, db, log, owner, team.ID, &models.CreateLogBookmarkRequest{
+		SourceID: src.ID, Timestamp: &at,
+		Payload: json.RawMessage(`{"trace_id":18446744073709551615,"msg":"panic"}`),
+	})
+	if !errors.Is(err, ErrLogBookmarkExists) {
+		t.Fatalf("CreateLogBookmark(duplicate) err = %v, want ErrLogBookmarkExists", err)
+	}
+}
+
+func TestLogBookmarkVisibility(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	owner := newTestUser(t, db, "owner@test.dev", "Owner")
+	colleague := newTestUser(t, db, "colleague@test.dev", "Colleague")
+	team, src := seedTeamWithSource(t, db, "team-a", owner, colleague)
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	personal, err := CreateLogBookmark(ctx, db, log, owner, team.ID, &models.CreateLogBookmarkRequest{
+		SourceID: src.ID, Timestamp: &at, Payload: json.RawMessage(`{"msg":"a"}`),
+	})
+	if err != nil {
+		t.Fatalf("CreateLogBookmark: %v", err)
+	}
+
+	// A personal bookmark is invisible to teammates until it is shared.
+	if _, err := GetLogBookmark(ctx, db, colleague, team.ID, personal.ID); !errors.Is(err, ErrLogBookmarkNotFound) {
+		t.Fatalf("GetLogBookmark(colleague) err = %v, want ErrLogBookmarkNotFound", err)
+	}
+	if list, err := ListLogBookmarks(ctx, db, colleague, team.ID, 0); err != nil || len(list) != 0 {
+		t.Fatalf("ListLogBookmarks(colleague) = %v / %+v, want none", err, list)
+	}
+	if _, err := UpdateLogBookmark(ctx, db, log, owner, team.ID, personal.ID, &models.UpdateLogBookmarkRequest{Note: "for the team", Shared: true}); err != nil {
+		t.Fatalf("UpdateLogBookmark(share): %v", err)
+	}
+	list, err := ListLogBookmarks(ctx, db, colleague, team.ID, src.ID)
+	if err != nil || len(list) != 1 || list[0].Note != "for the team" {
+		t.Fatalf("ListLogBookmarks(colleague, shared) = %v / %+v", err, list)
+	}
+
+	// Teammates see a shared bookmark but can't change or remove it.
+	if err := DeleteLogBookmark(ctx, db, log, colleague, team.ID, personal.ID); !errors.Is(err, ErrLogBookmarkForbidden) {
+		t.Fatalf("DeleteLogBookmark(colleague) err = %v, want ErrLogBookmarkForbidden", err)
+	}
+	if err := DeleteLogBookmark(ctx, db, log, owner, team.ID, personal.ID); err != nil {
+		t.Fatalf("DeleteLogBookmark: %v", err)
+	}
+	if _, err := GetLogBookmark(ctx, db, owner, team.ID, personal.ID); !errors.Is(err, ErrLogBookmarkNotFound) {
+		t.Errorf("GetLogBookmark(deleted) err = %v, want ErrLogBookmarkNotFound", err)
+	}
+}
@@ -3,6 +3,8 @@ package core
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/mr-karan/logchef/internal/datasource"
 	"github.com/mr-karan/logchef/pkg/models"
@@ -33,7 +35,88 @@ func GetSourceSchema(ctx context.Context, ds *datasource.Service, sourceID model
 type HistogramParams = datasource.HistogramRequest
 type HistogramResponse = datasource.HistogramResult
 
-func GetHistogramData(ctx context.Context, ds *datasource.Service, sourceID models.SourceID, params HistogramParams) (*HistogramResponse, error) {
+// HistogramRollups answers histograms from precomputed rollups where it can,
+// reporting ok=false when the request has to read the raw logs.
+// *rollups.Manager implements it.
+type HistogramRollups interface {
+	Histogram(ctx context.Context, sourceID models.SourceID, req HistogramParams) (result *HistogramResponse, ok bool, err error)
+}
+
+// HistogramWindowAuto asks GetHistogramData to size buckets from the time range
+// instead of taking a fixed window.
+const HistogramWindowAuto = "auto"
+
+// maxAutoHistogramBuckets caps the bucket count an auto window produces. The
+// window ladder below grows by at most 2.5x a step, so the chosen window lands
+// between 48 and 120 buckets, and between 60 and 120 for most ranges.
+const maxAutoHistogramBuckets = 120
+
+// histogramWindows are the windows every provider accepts, smallest first.
+var histogramWindows = []struct {
+	label string
+	size  time.Duration
+}{
+	{"1s", time.Second}, {"2s", 2 * time.Second}, {"5s", 5 * time.Second},
+	{"10s", 10 * time.Second}, {"15s", 15 * time.Second}, {"30s", 30 * time.Second},
+	{"1m", time.Minute}, {"2m", 2 * time.Minute}, {"5m", 5 * time.Minute}, {"10m", 10 * time.Minute},
+	{"15m", 15 * time.Minute}, {"30m", 30 * time.Minute},
+	{"1h", time.Hour}, {"2h", 2 * time.Hour}, {"3h", 3 * time.Hour},
+	{"6h", 6 * time.Hour}, {"12h", 12 * time.Hour}, {"24h", 24 * time.Hour},
+}
+
+// AutoHistogramWindow returns the smallest supported window that splits
+// [start, end] into at most maxAutoHistogramBuckets buckets, or the largest
+// window for ranges too long for any. Without a complete range it returns
+// "1m", the handler's default window.
+func AutoHistogramWindow(start, end *time.Time) string {
+	if start == nil || end == nil {
+		return "1m"
+	}
+	span := end.Sub(*start)
+	for _, w := range histogramWindows {
+		if span <= w.size*maxAutoHistogramBuckets {
+			return w.label
+		}
+	}
+	return histogramWindows[len(histogramWindows)-1].label
+}
+
+// GetHistogramData buckets log counts over time. A Window of "auto" is
+// replaced by AutoHistogramWindow for the requested range; the result's
+// Granularity reports the window used. With params.SeverityBreakdown
+// and no explicit GroupBy, it groups by the source's MetaSeverityField so one
+// query returns a stacked series per level; sources without a severity field
+// fall back to an ungrouped histogram. The result's GroupBy names the field
+// actually used. When rollups is non-nil it is offered the resolved request
+// first, and the datasource is only queried if it can't answer. Requests with
+// a RowFilter or MaskedColumns always query the datasource: rollups count
+// every row and can group by any column.
+func GetHistogramData(ctx context.Context, ds *datasource.Service, rollups HistogramRollups, sourceID models.SourceID, params HistogramParams) (*HistogramResponse, error) {
+	if strings.EqualFold(strings.TrimSpace(params.Window), HistogramWindowAuto) {
+		params.Window = AutoHistogramWindow(params.StartTime, params.EndTime)
+	}
+	if params.SeverityBreakdown && strings.TrimSpace(params.GroupBy) == "" {
+		source, err := GetSource(ctx, ds, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		params.GroupBy = source.MetaSeverityField
+	}
+
+	if rollups != nil && params.RowFilter == "" && len(params.MaskedColumns) == 0 {
+		result, ok, err := rollups.Histogram(ctx, sourceID, params)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return nil, ErrSourceNotFound
+			}
+			return nil, err
+		}
+		if ok {
+			result.GroupBy = strings.TrimSpace(params.GroupBy)
+			return result, nil
+		}
+	}
+
 	result, err := ds.Histogram(ctx, sourceID, params)
 	if err != nil {
 		if errors.Is(err, models.ErrNotFound) {
@@ -41,6 +124,9 @@ func GetHistogramData(ctx context.Context, ds *datasource.Service, sourceID mode
 		}
 		return nil, err
 	}
+	if result != nil {
+		result.GroupBy = strings.TrimSpace(params.GroupBy)
+	}
 	return result, nil
 }
 
@@ -71,6 +157,86 @@ func GetAllFieldValues(ctx context.Context, ds *datasource.Service, sourceID mod
 	return result, nil
 }
 
+type FieldStatsParams = datasource.FieldStatsRequest
+type FieldStatsResult = datasource.FieldStatsResult
+
+// GetFieldStats returns statistics for a numeric or datetime field. Sources
+// whose provider cannot compute them report datasource.ErrOperationNotSupported.
+func GetFieldStats(ctx context.Context, ds *datasource.Service, sourceID models.SourceID, params FieldStatsParams) (*FieldStatsResult, error) {
+	result, err := ds.GetFieldStats(ctx, sourceID, params)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrSourceNotFound
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+type JSONFieldsParams = datasource.JSONFieldsRequest
+
+// GetJSONFields returns the keys of JSON objects found in a sample of recent
+// rows. Sources whose provider cannot sample them report
+// datasource.ErrOperationNotSupported.
+func GetJSONFields(ctx context.Context, ds *datasource.Service, sourceID models.SourceID, params JSONFieldsParams) (*models.JSONFieldsResult, error) {
+	result, err := ds.GetJSONFields(ctx, sourceID, params)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrSourceNotFound
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+type LogPatternsParams = datasource.LogPatternsRequest
+
+// GetLogPatterns returns the most frequent message templates of a field.
+// Sources whose provider cannot group them report
+// datasource.ErrOperationNotSupported.
+func GetLogPatterns(ctx context.Context, ds *datasource.Service, sourceID models.SourceID, params LogPatternsParams) (*models.LogPatternsResult, error) {
+	result, err := ds.GetLogPatterns(ctx, sourceID, params)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrSourceNotFound
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+type VolumeAnomalyParams = datasource.VolumeAnomalyRequest
+
+// DetectVolumeAnomalies compares a source's recent log volume and error rate
+// with an earlier window. Sources whose provider cannot compare them report
+// datasource.ErrOperationNotSupported.
+func DetectVolumeAnomalies(ctx context.Context, ds *datasource.Service, sourceID models.SourceID, params VolumeAnomalyParams) (*models.VolumeAnomalyReport, error) {
+	result, err := ds.DetectVolumeAnomalies(ctx, sourceID, params)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrSourceNotFound
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+type RangeComparisonParams = datasource.RangeComparisonRequest
+
+// CompareRanges diffs a source's field value distributions between two time
+// ranges. Sources whose provider cannot compare them report
+// datasource.ErrOperationNotSupported.
+func CompareRanges(ctx context.Context, ds *datasource.Service, sourceID models.SourceID, params RangeComparisonParams) (*models.RangeComparison, error) {
+	result, err := ds.CompareRanges(ctx, sourceID, params)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrSourceNotFound
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
 // --- Log Context Functions ---
 
 // LogContextParams defines parameters for the log context query.
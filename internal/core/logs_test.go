@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestGetHistogramDataSeverityBreakdown(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	var gotGroupBy []string
+	ds := newFakeDatasourceService(db, discardLogger(), &fakeProvider{
+		histogramFn: func(_ context.Context, _ *models.Source, req datasource.HistogramRequest) (*datasource.HistogramResult, error) {
+			gotGroupBy = append(gotGroupBy, req.GroupBy)
+			return &datasource.HistogramResult{Granularity: "1m"}, nil
+		},
+	})
+
+	src := newTestSource(t, db, "levels")
+	src.MetaSeverityField = "severity_text"
+	if err := db.UpdateSource(ctx, src); err != nil {
+		t.Fatalf("UpdateSource: %v", err)
+	}
+	plain := newTestSource(t, db, "plain")
+
+	cases := []struct {
+		name     string
+		sourceID models.SourceID
+		params   HistogramParams
+		want     string
+	}{
+		{"breakdown uses severity field", src.ID, HistogramParams{SeverityBreakdown: true}, "severity_text"},
+		{"explicit group-by wins", src.ID, HistogramParams{SeverityBreakdown: true, GroupBy: "host"}, "host"},
+		{"breakdown not requested", src.ID, HistogramParams{}, ""},
+		{"source without severity field", plain.ID, HistogramParams{SeverityBreakdown: true}, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotGroupBy = nil
+			result, err := GetHistogramData(ctx, ds, nil, tc.sourceID, tc.params)
+			if err != nil {
+				t.Fatalf("GetHistogramData: %v", err)
+			}
+			if len(gotGroupBy) != 1 || gotGroupBy[0] != tc.want {
+				t.Errorf("provider GroupBy = %q, want %q", gotGroupBy, tc.want)
+			}
+			if result.GroupBy != tc.want {
+				t.Errorf("result GroupBy = %q, want %q", result.GroupBy, tc.want)
+			}
+		})
+	}
+}
+
+func TestAutoHistogramWindow(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		span time.Duration
+		want string
+	}{
+		{90 * time.Second, "1s"},
+		{15 * time.Minute, "10s"},
+		{time.Hour, "30s"},
+		{2 * time.Hour, "1m"},
+		{3 * time.Hour, "2m"},
+		{5 * time.Hour, "5m"},
+		{4 * time.Minute, "2s"},
+		{24 * time.Hour, "15m"},
+		{7 * 24 * time.Hour, "2h"},
+		{30 * 24 * time.Hour, "6h"},
+		{365 * 24 * time.Hour, "24h"},
+	}
+	for _, tc := range cases {
+		end := start.Add(tc.span)
+		if got := AutoHistogramWindow(&start, &end); got != tc.want {
+			t.Errorf("AutoHistogramWindow(%s) = %q, want %q", tc.span, got, tc.want)
+		}
+	}
+	if got := AutoHistogramWindow(nil, &start); got != "1m" {
+		t.Errorf("AutoHistogramWindow without a start = %q, want 1m", got)
+	}
+}
+
+func TestGetHistogramDataResolvesAutoWindow(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	var gotWindow string
+	ds := newFakeDatasourceService(db, discardLogger(), &fakeProvider{
+		histogramFn: func(_ context.Context, _ *models.Source, req datasource.HistogramRequest) (*datasource.HistogramResult, error) {
+			gotWindow = req.Window
+			return &datasource.HistogramResult{Granularity: req.Window}, nil
+		},
+	})
+	src := newTestSource(t, db, "auto")
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(6 * time.Hour)
+	result, err := GetHistogramData(ctx, ds, nil, src.ID, HistogramParams{StartTime: &start, EndTime: &end, Window: "auto"})
+	if err != nil {
+		t.Fatalf("GetHistogramData: %v", err)
+	}
+	if gotWindow != "5m" || result.Granularity != "5m" {
+		t.Errorf("window = %q, granularity = %q, want 5m", gotWindow, result.Granularity)
+	}
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// MaskedColumnsForUser returns the source's columns the user sees masked when
+// reading it through the team. Global admins and team members holding
+// view_unmasked see every column as is, so for them it returns none.
+func MaskedColumnsForUser(ctx context.Context, db store.StoreOps, user *models.User, teamID models.TeamID, sourceID models.SourceID) (models.MaskedColumns, error) {
+	source, err := db.GetSource(ctx, sourceID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrSourceNotFound
+		}
+		return nil, fmt.Errorf("error getting source: %w", err)
+	}
+	if len(source.MaskedColumns) == 0 {
+		return nil, nil
+	}
+	if user != nil {
+		if user.Role == models.UserRoleAdmin {
+			return nil, nil
+		}
+		unmasked, err := UserHasTeamPermission(ctx, db, teamID, user.ID, models.TeamPermissionViewUnmasked)
+		if err != nil {
+			return nil, err
+		}
+		if unmasked {
+			return nil, nil
+		}
+	}
+	return source.MaskedColumns, nil
+}
+
+// UserSeesSourceUnmasked reports whether the user sees every column of the
+// source as is through at least one of their teams. Reads outside any one
+// team's scope, which can't pick the masks to apply, require it.
+func UserSeesSourceUnmasked(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, sourceID models.SourceID) (bool, error) {
+	if user.Role == models.UserRoleAdmin {
+		return true, nil
+	}
+	teams, err := ListTeamsWithAccessToSource(ctx, db, log, sourceID, user.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, team := range teams {
+		masked, err := MaskedColumnsForUser(ctx, db, user, team.ID, sourceID)
+		if err != nil {
+			return false, err
+		}
+		if len(masked) == 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestMaskedColumnsForUser(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	log := discardLogger()
+
+	src := newTestSource(t, db, "masked")
+	src.MaskedColumns = models.MaskedColumns{{Column: "email", Mode: models.MaskHash}}
+	if err := db.UpdateSource(ctx, src); err != nil {
+		t.Fatalf("UpdateSource: %v", err)
+	}
+	team, err := CreateTeam(ctx, db, log, "team-a", "")
+	if err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+	if err := AddTeamSource(ctx, db, log, team.ID, src.ID); err != nil {
+		t.Fatalf("AddTeamSource: %v", err)
+	}
+
+	member := newTestUser(t, db, "member@example.com", "Member")
+	editor := newTestUser(t, db, "editor@example.com", "Editor")
+	teamAdmin := newTestUser(t, db, "lead@example.com", "Lead")
+	for user, role := range map[*models.User]models.TeamRole{
+		member:    models.TeamRoleMember,
+		editor:    models.TeamRoleEditor,
+		teamAdmin: models.TeamRoleAdmin,
+	} {
+		if err := AddTeamMember(ctx, db, log, team.ID, user.ID, role); err != nil {
+			t.Fatalf("AddTeamMember(%s): %v", role, err)
+		}
+	}
+	globalAdmin := &models.User{ID: member.ID, Role: models.UserRoleAdmin}
+
+	for _, tc := range []struct {
+		name       string
+		user       *models.User
+		wantMasked bool
+	}{
+		{"member", member, true},
+		{"editor", editor, true},
+		{"team admin", teamAdmin, false},
+		{"global admin", globalAdmin, false},
+	} {
+		masked, err := MaskedColumnsForUser(ctx, db, tc.user, team.ID, src.ID)
+		if err != nil {
+			t.Fatalf("%s: MaskedColumnsForUser: %v", tc.name, err)
+		}
+		if (len(masked) > 0) != tc.wantMasked {
+			t.Errorf("%s: masked = %v, want masked %v", tc.name, masked, tc.wantMasked)
+		}
+		unmasked, err := UserSeesSourceUnmasked(ctx, db, log, tc.user, src.ID)
+		if err != nil {
+			t.Fatalf("%s: UserSeesSourceUnmasked: %v", tc.name, err)
+		}
+		if unmasked == tc.wantMasked {
+			t.Errorf("%s: UserSeesSourceUnmasked = %v, want %v", tc.name, unmasked, !tc.wantMasked)
+		}
+	}
+
+	plain := newTestSource(t, db, "plain")
+	if masked, err := MaskedColumnsForUser(ctx, db, member, team.ID, plain.ID); err != nil || masked != nil {
+		t.Errorf("source without masks: masked = %v, err = %v", masked, err)
+	}
+}
@@ -0,0 +1,487 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+var (
+	// ErrNotebookNotFound is returned when a notebook cannot be located in the
+	// requested team.
+	ErrNotebookNotFound = errors.New("notebook not found")
+	// ErrNotebookCellNotFound is returned when a notebook has no cell with the
+	// requested id.
+	ErrNotebookCellNotFound = errors.New("notebook cell not found")
+	// ErrInvalidNotebook indicates the request payload failed validation.
+	ErrInvalidNotebook = errors.New("invalid notebook")
+	// ErrNotebookForbidden indicates the caller may not modify the notebook.
+	ErrNotebookForbidden = errors.New("not authorized to edit this notebook")
+	// ErrNotebookConflict indicates the stored notebook changed since the
+	// client loaded it.
+	ErrNotebookConflict = errors.New("notebook was modified by someone else")
+	// ErrNotebookCellPinned indicates a run result was not cached because the
+	// cell holds a pinned result.
+	ErrNotebookCellPinned = errors.New("notebook cell result is pinned")
+)
+
+// NotebookRunOptions carries the server's query limits into a cell run.
+type NotebookRunOptions struct {
+	DefaultLimit     int
+	MaxLimit         int
+	MaxResponseBytes int
+	QueryTimeout     *int
+	// RowFilter is the notebook team's row filter for the cell's source.
+	RowFilter string
+	// MaskedColumns are the cell source's columns masked for the user.
+	MaskedColumns models.MaskedColumns
+	// RequireTimeBound applies the cell's time range to SQL that doesn't
+	// filter on the timestamp column; see datasource.QueryRequest.
+	RequireTimeBound bool
+}
+
+// CreateNotebook validates and persists a new notebook in teamID, owned by the
+// caller. Any results sent with the cells are dropped: results are only ever
+// produced by running a cell on the server.
+func CreateNotebook(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, teamID models.TeamID, req *models.CreateNotebookRequest) (*models.Notebook, error) {
+	if req == nil || user == nil {
+		return nil, ErrInvalidNotebook
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidNotebook)
+	}
+	cells, err := parseNotebookRequestCells(ctx, db, teamID, req.Cells)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cells {
+		cells[i].Result = nil
+	}
+	raw, err := json.Marshal(cells)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notebook cells: %w", err)
+	}
+
+	owner := user.ID
+	notebook := &models.Notebook{
+		TeamID:      teamID,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		CellsJSON:   raw,
+		CreatedBy:   &owner,
+	}
+	if err := db.CreateNotebook(ctx, notebook); err != nil {
+		log.Error("failed to create notebook", "error", err, "team_id", teamID, "created_by", owner)
+		return nil, fmt.Errorf("failed to create notebook: %w", err)
+	}
+	log.Info("notebook created", "notebook_id", notebook.ID, "team_id", teamID, "created_by", owner)
+	return notebook, nil
+}
+
+// parseNotebookRequestCells decodes and validates submitted cells, and checks
+// that every executable cell targets a source linked to the notebook's team.
+// An absent blob is an empty notebook.
+func parseNotebookRequestCells(ctx context.Context, db store.StoreOps, teamID models.TeamID, raw json.RawMessage) ([]models.NotebookCell, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return []models.NotebookCell{}, nil
+	}
+	cells, err := models.ParseNotebookCells(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidNotebook, err)
+	}
+	if err := models.ValidateNotebookCells(cells); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidNotebook, err)
+	}
+
+	checked := make(map[models.SourceID]struct{})
+	for i := range cells {
+		cell := &cells[i]
+		if !cell.IsExecutable() {
+			continue
+		}
+		if _, ok := checked[cell.SourceID]; ok {
+			continue
+		}
+		linked, err := db.TeamHasSource(ctx, teamID, cell.SourceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify team/source link: %w", err)
+		}
+		if !linked {
+			return nil, fmt.Errorf("%w: cell %q references source %d which is not linked to this team", ErrInvalidNotebook, cell.ID, cell.SourceID)
+		}
+		checked[cell.SourceID] = struct{}{}
+	}
+	return cells, nil
+}
+
+// GetNotebook retrieves a notebook by id. A notebook belonging to another team
+// is reported as not found so ids can't be probed across teams.
+func GetNotebook(ctx context.Context, db store.StoreOps, log *slog.Logger, teamID models.TeamID, id int) (*models.Notebook, error) {
+	notebook, err := db.GetNotebook(ctx, id)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return nil, ErrNotebookNotFound
+		}
+		log.Error("failed to get notebook", "notebook_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get notebook: %w", err)
+	}
+	if notebook.TeamID != teamID {
+		return nil, ErrNotebookNotFound
+	}
+	return notebook, nil
+}
+
+// ListNotebooks returns a team's notebooks (without cells), newest-updated first.
+func ListNotebooks(ctx context.Context, db store.StoreOps, teamID models.TeamID) ([]*models.Notebook, error) {
+	notebooks, err := db.ListNotebooksByTeam(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notebooks: %w", err)
+	}
+	return notebooks, nil
+}
+
+// UpdateNotebook validates and persists changes to an existing notebook. A
+// cell keeps its cached result when its query definition is unchanged, so
+// editing the narrative doesn't discard evidence gathered earlier. Changing
+// the query of a cell that stays pinned is rejected, as is a stale write
+// (req.UpdatedAt older than the stored row).
+func UpdateNotebook(ctx context.Context, db store.StoreOps, log *slog.Logger, teamID models.TeamID, id int, user *models.User, req *models.UpdateNotebookRequest) (*models.Notebook, error) {
+	if req == nil || user == nil {
+		return nil, ErrInvalidNotebook
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidNotebook)
+	}
+
+	existing, err := GetNotebook(ctx, db, log, teamID, id)
+	if err != nil {
+		return nil, err
+	}
+	canEdit, err := UserCanEditNotebook(ctx, db, existing, user)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, ErrNotebookForbidden
+	}
+	if !req.UpdatedAt.IsZero() && existing.UpdatedAt.After(req.UpdatedAt) {
+		return nil, ErrNotebookConflict
+	}
+
+	cells, err := parseNotebookRequestCells(ctx, db, teamID, req.Cells)
+	if err != nil {
+		return nil, err
+	}
+	previous := make(map[string]*models.NotebookCell)
+	if stored, err := models.ParseNotebookCells(existing.CellsJSON); err == nil {
+		for i := range stored {
+			previous[stored[i].ID] = &stored[i]
+		}
+	}
+	for i := range cells {
+		cells[i].Result = nil
+		prev, ok := previous[cells[i].ID]
+		if !ok || !cells[i].IsExecutable() {
+			continue
+		}
+		if prev.SameQuery(&cells[i]) {
+			cells[i].Result = prev.Result
+		} else if prev.Pinned && prev.Result != nil && cells[i].Pinned {
+			return nil, fmt.Errorf("%w: cell %q is pinned; unpin it before changing its query", ErrInvalidNotebook, cells[i].ID)
+		}
+	}
+	raw, err := json.Marshal(cells)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notebook cells: %w", err)
+	}
+
+	existing.Name = name
+	existing.Description = strings.TrimSpace(req.Description)
+	existing.CellsJSON = raw
+	if err := db.UpdateNotebook(ctx, existing); err != nil {
+		if models.IsNotFound(err) {
+			return nil, ErrNotebookNotFound
+		}
+		log.Error("failed to update notebook", "notebook_id", id, "error", err)
+		return nil, fmt.Errorf("failed to update notebook: %w", err)
+	}
+	return GetNotebook(ctx, db, log, teamID, id)
+}
+
+// DeleteNotebook removes a notebook by id.
+func DeleteNotebook(ctx context.Context, db store.StoreOps, log *slog.Logger, id int) error {
+	if err := db.DeleteNotebook(ctx, id); err != nil {
+		if models.IsNotFound(err) {
+			return ErrNotebookNotFound
+		}
+		log.Error("failed to delete notebook", "notebook_id", id, "error", err)
+		return fmt.Errorf("failed to delete notebook: %w", err)
+	}
+	return nil
+}
+
+// UserCanEditNotebook reports whether user may modify the notebook: global
+// admins always; otherwise the caller's team role must allow notebook
+// authoring, and they must be the creator or a team admin/editor.
+func UserCanEditNotebook(ctx context.Context, db store.StoreOps, notebook *models.Notebook, user *models.User) (bool, error) {
+	if notebook == nil || user == nil {
+		return false, nil
+	}
+	if user.Role == models.UserRoleAdmin {
+		return true, nil
+	}
+	member, err := db.GetTeamMember(ctx, notebook.TeamID, user.ID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error checking notebook edit access: %w", err)
+	}
+	if member == nil || !member.Role.HasPermission(models.TeamPermissionManageNotebooks) {
+		return false, nil
+	}
+	if notebook.CreatedBy != nil && *notebook.CreatedBy == user.ID {
+		return true, nil
+	}
+	return member.Role == models.TeamRoleAdmin || member.Role == models.TeamRoleEditor, nil
+}
+
+// FindNotebookCell returns the cell with the given id from a notebook's blob.
+func FindNotebookCell(notebook *models.Notebook, cellID string) (*models.NotebookCell, error) {
+	cells, err := models.ParseNotebookCells(notebook.CellsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode notebook cells: %w", err)
+	}
+	for i := range cells {
+		if cells[i].ID == cellID {
+			return &cells[i], nil
+		}
+	}
+	return nil, ErrNotebookCellNotFound
+}
+
+// RunNotebookCell executes a query or histogram cell against its source over
+// the cell's pinned time range. Query failures (bad syntax, datasource errors,
+// timeouts) are reported in the result's Error field rather than as an error,
+// so they can be cached and shown in place like any other outcome.
+func RunNotebookCell(ctx context.Context, ds *datasource.Service, cell *models.NotebookCell, opts NotebookRunOptions) (*models.NotebookCellResult, error) {
+	if cell == nil || !cell.IsExecutable() {
+		return nil, fmt.Errorf("%w: only query and histogram cells can be run", ErrInvalidNotebook)
+	}
+	result := &models.NotebookCellResult{ExecutedAt: time.Now().UTC()}
+
+	var err error
+	if cell.Type == models.NotebookCellHistogram {
+		err = runNotebookHistogramCell(ctx, ds, cell, opts, result)
+	} else {
+		err = runNotebookQueryCell(ctx, ds, cell, opts, result)
+	}
+	if err != nil {
+		if errors.Is(err, ErrSourceNotFound) {
+			return nil, err
+		}
+		result.Error = err.Error()
+	}
+	return result, nil
+}
+
+// notebookCellQuery resolves the executable query for a cell, compiling
+// LogchefQL into the source's native language. The returned bounds are those
+// the datasource should apply itself (nil when the compiled query already
+// embeds the time range).
+func notebookCellQuery(ctx context.Context, ds *datasource.Service, cell *models.NotebookCell, limit int) (query string, start, end *time.Time, err error) {
+	startTime, err := time.Parse(time.RFC3339, cell.StartTime)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("invalid start_time: %w", err)
+	}
+	endTime, err := time.Parse(time.RFC3339, cell.EndTime)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("invalid end_time: %w", err)
+	}
+
+	if models.NormalizeQueryLanguage(cell.QueryLanguage) != models.QueryLanguageLogchefQL {
+		return cell.Content, &startTime, &endTime, nil
+	}
+
+	compiled, err := ds.CompileLogchefQL(ctx, cell.SourceID, datasource.LogchefQLCompileRequest{
+		Query:     cell.Content,
+		StartTime: cell.StartTime,
+		EndTime:   cell.EndTime,
+		Timezone:  notebookCellTimezone(cell),
+		Limit:     limit,
+	})
+	if compiled == nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return "", nil, nil, ErrSourceNotFound
+		}
+		if errors.Is(err, datasource.ErrOperationNotSupported) {
+			return "", nil, nil, errors.New("LogchefQL is not supported for this source")
+		}
+		return "", nil, nil, fmt.Errorf("failed to compile query: %w", err)
+	}
+	if err != nil || !compiled.Valid {
+		if compiled.Error != nil {
+			return "", nil, nil, compiled.Error
+		}
+		if err != nil {
+			return "", nil, nil, err
+		}
+		return "", nil, nil, errors.New("invalid LogchefQL query")
+	}
+	if compiled.Language == models.QueryLanguageLogsQL {
+		return compiled.Query, &startTime, &endTime, nil
+	}
+	return compiled.Query, nil, nil, nil
+}
+
+func notebookCellTimezone(cell *models.NotebookCell) string {
+	if cell.Timezone == "" {
+		return "UTC"
+	}
+	return cell.Timezone
+}
+
+func runNotebookQueryCell(ctx context.Context, ds *datasource.Service, cell *models.NotebookCell, opts NotebookRunOptions, result *models.NotebookCellResult) error {
+	limit := cell.Limit
+	if limit <= 0 {
+		limit = models.DefaultNotebookCellLimit
+	}
+	if opts.MaxLimit > 0 && limit > opts.MaxLimit {
+		limit = opts.MaxLimit
+	}
+
+	query, start, end, err := notebookCellQuery(ctx, ds, cell, limit)
+	if err != nil {
+		return err
+	}
+	res, err := QueryLogs(ctx, ds, cell.SourceID, datasource.QueryRequest{
+		RawQuery:         query,
+		StartTime:        start,
+		EndTime:          end,
+		Timezone:         notebookCellTimezone(cell),
+		Limit:            limit,
+		DefaultLimit:     opts.DefaultLimit,
+		MaxLimit:         opts.MaxLimit,
+		MaxResponseBytes: opts.MaxResponseBytes,
+		QueryTimeout:     opts.QueryTimeout,
+		RowFilter:        opts.RowFilter,
+		MaskedColumns:    opts.MaskedColumns,
+		RequireTimeBound: opts.RequireTimeBound,
+	})
+	if err != nil {
+		return err
+	}
+
+	stats := res.Stats
+	result.Stats = &stats
+	result.Columns = res.Columns
+	result.Rows = res.Logs
+	if len(result.Rows) > models.MaxNotebookCachedRows {
+		result.Rows = result.Rows[:models.MaxNotebookCachedRows]
+		result.RowsTruncated = true
+	}
+	return nil
+}
+
+func runNotebookHistogramCell(ctx context.Context, ds *datasource.Service, cell *models.NotebookCell, opts NotebookRunOptions, result *models.NotebookCellResult) error {
+	query, _, _, err := notebookCellQuery(ctx, ds, cell, 0)
+	if err != nil {
+		return err
+	}
+	startTime, _ := time.Parse(time.RFC3339, cell.StartTime)
+	endTime, _ := time.Parse(time.RFC3339, cell.EndTime)
+	window := cell.Window
+	if window == "" {
+		window = "1m"
+	}
+
+	hist, err := GetHistogramData(ctx, ds, nil, cell.SourceID, HistogramParams{
+		StartTime:     &startTime,
+		EndTime:       &endTime,
+		Window:        window,
+		Query:         query,
+		GroupBy:       strings.TrimSpace(cell.GroupBy),
+		Timezone:      notebookCellTimezone(cell),
+		QueryTimeout:  opts.QueryTimeout,
+		RowFilter:     opts.RowFilter,
+		MaskedColumns: opts.MaskedColumns,
+	})
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(hist)
+	if err != nil {
+		return fmt.Errorf("failed to encode histogram: %w", err)
+	}
+	result.Histogram = raw
+	return nil
+}
+
+// notebookCacheAttempts bounds how often CacheNotebookCellResult re-reads a
+// notebook whose cells changed under it before giving up.
+const notebookCacheAttempts = 10
+
+// CacheNotebookCellResult stores result as the cached result of cell in the
+// notebook. The write is a compare-and-swap against the cells it read, and is
+// retried on a fresh read when they changed meanwhile, so concurrent cell runs
+// and edits are preserved; if it still loses after notebookCacheAttempts,
+// ErrNotebookConflict is returned. If the cell was removed or its query
+// changed while it ran, the now-stale result is discarded. A pinned cell that
+// already has a result keeps it and ErrNotebookCellPinned is returned.
+// Caching doesn't advance the notebook's updated_at.
+func CacheNotebookCellResult(ctx context.Context, db store.StoreOps, log *slog.Logger, notebookID int, cell *models.NotebookCell, result *models.NotebookCellResult) error {
+	for range notebookCacheAttempts {
+		notebook, err := db.GetNotebook(ctx, notebookID)
+		if err != nil {
+			if models.IsNotFound(err) {
+				return ErrNotebookNotFound
+			}
+			return fmt.Errorf("failed to load notebook: %w", err)
+		}
+		cells, err := models.ParseNotebookCells(notebook.CellsJSON)
+		if err != nil {
+			return fmt.Errorf("failed to decode notebook cells: %w", err)
+		}
+
+		updated := false
+		for i := range cells {
+			if cells[i].ID == cell.ID && cells[i].SameQuery(cell) {
+				if cells[i].Pinned && cells[i].Result != nil {
+					return ErrNotebookCellPinned
+				}
+				cells[i].Result = result
+				updated = true
+				break
+			}
+		}
+		if !updated {
+			return nil
+		}
+
+		raw, err := json.Marshal(cells)
+		if err != nil {
+			return fmt.Errorf("failed to encode notebook cells: %w", err)
+		}
+		err = db.UpdateNotebookCells(ctx, notebookID, notebook.CellsJSON, raw)
+		if err == nil {
+			return nil
+		}
+		if !models.IsNotFound(err) {
+			log.Error("failed to cache notebook cell result", "notebook_id", notebookID, "cell_id", cell.ID, "error", err)
+			return fmt.Errorf("failed to cache notebook cell result: %w", err)
+		}
+		// The notebook was deleted or its cells changed since the read; the
+		// next read tells which.
+	}
+	return ErrNotebookConflict
+}
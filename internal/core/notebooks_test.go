@@ -0,0 +1,357 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// notebookCells builds a cell blob with a markdown cell and one SQL query cell
+// against sourceID.
+func notebookCells(sourceID models.SourceID, query string) json.RawMessage {
+	return json.RawMessage(fmt.Sprintf(`[
+		{"id":"intro","type":"markdown","content":"# Outage"},
+		{"id":"q1","type":"query","content":%q,"source_id":%d,"query_language":"clickhouse-sql",
+		 "start_time":"2026-01-01T00:00:00Z","end_time":"2026-01-01T01:00:00Z"}
+	]`, query, sourceID))
+}
+
+func TestCreateNotebookValidatesCells(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	author := newTestUser(t, db, "author@test.dev", "Author")
+	team, src := seedTeamWithSource(t, db, "team-a", author)
+	orphan := newTestSource(t, db, "orphan-src")
+
+	cases := []struct {
+		name  string
+		cells json.RawMessage
+	}{
+		{"not an array", json.RawMessage(`{"cells":[]}`)},
+		{"unknown type", json.RawMessage(`[{"id":"x","type":"chart","content":"x"}]`)},
+		{"duplicate ids", json.RawMessage(`[{"id":"x","type":"markdown"},{"id":"x","type":"markdown"}]`)},
+		{"pinned markdown", json.RawMessage(`[{"id":"x","type":"markdown","pinned":true}]`)},
+		{"query without time range", json.RawMessage(fmt.Sprintf(`[{"id":"q","type":"query","content":"SELECT 1","source_id":%d,"query_language":"clickhouse-sql"}]`, src.ID))},
+		{"source not linked to team", notebookCells(orphan.ID, "SELECT 1")},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := CreateNotebook(ctx, db, log, author, team.ID, &models.CreateNotebookRequest{Name: "n", Cells: tc.cells})
+			if !errors.Is(err, ErrInvalidNotebook) {
+				t.Fatalf("err = %v, want ErrInvalidNotebook", err)
+			}
+		})
+	}
+
+	nb, err := CreateNotebook(ctx, db, log, author, team.ID, &models.CreateNotebookRequest{Name: "  Postmortem  ", Cells: notebookCells(src.ID, "SELECT 1")})
+	if err != nil {
+		t.Fatalf("CreateNotebook: %v", err)
+	}
+	if nb.Name != "Postmortem" || nb.TeamID != team.ID || nb.CreatedBy == nil || *nb.CreatedBy != author.ID {
+		t.Fatalf("unexpected notebook: %+v", nb)
+	}
+
+	// A notebook is only reachable through its own team.
+	other, _ := seedTeamWithSource(t, db, "team-b")
+	if _, err := GetNotebook(ctx, db, log, other.ID, nb.ID); !errors.Is(err, ErrNotebookNotFound) {
+		t.Fatalf("GetNotebook(other team) err = %v, want ErrNotebookNotFound", err)
+	}
+}
+
+func TestUserCanEditNotebook(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	author := newTestUser(t, db, "author@test.dev", "Author")
+	team, src := seedTeamWithSource(t, db, "team-a", author)
+	nb, err := CreateNotebook(ctx, db, log, author, team.ID, &models.CreateNotebookRequest{Name: "n", Cells: notebookCells(src.ID, "SELECT 1")})
+	if err != nil {
+		t.Fatalf("CreateNotebook: %v", err)
+	}
+
+	member := func(email string, role models.TeamRole) *models.User {
+		u := newTestUser(t, db, email, email)
+		if err := AddTeamMember(ctx, db, log, team.ID, u.ID, role); err != nil {
+			t.Fatalf("AddTeamMember: %v", err)
+		}
+		return u
+	}
+	admin := newTestUser(t, db, "root@test.dev", "Root")
+	admin.Role = models.UserRoleAdmin
+
+	cases := []struct {
+		name string
+		user *models.User
+		want bool
+	}{
+		{"creator", author, true},
+		{"team editor", member("editor@test.dev", models.TeamRoleEditor), true},
+		{"other member", member("member@test.dev", models.TeamRoleMember), false},
+		{"viewer", member("viewer@test.dev", models.TeamRoleViewer), false},
+		{"non-member", newTestUser(t, db, "stranger@test.dev", "Stranger"), false},
+		{"global admin", admin, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := UserCanEditNotebook(ctx, db, nb, tc.user)
+			if err != nil {
+				t.Fatalf("UserCanEditNotebook: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("UserCanEditNotebook = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunAndCacheNotebookCell(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	var gotReq datasource.QueryRequest
+	ds := newFakeDatasourceService(db, log, &fakeProvider{
+		queryLogsFn: func(_ context.Context, _ *models.Source, req datasource.QueryRequest) (*models.QueryResult, error) {
+			gotReq = req
+			if req.RawQuery == "SELECT broken" {
+				return nil, errors.New("syntax error")
+			}
+			rows := make([]map[string]any, models.MaxNotebookCachedRows+5)
+			for i := range rows {
+				rows[i] = map[string]any{"n": i}
+			}
+			return &models.QueryResult{Logs: rows, Columns: []models.ColumnInfo{{Name: "n", Type: "UInt64"}}}, nil
+		},
+	})
+
+	author := newTestUser(t, db, "author@test.dev", "Author")
+	team, src := seedTeamWithSource(t, db, "team-a", author)
+	nb, err := CreateNotebook(ctx, db, log, author, team.ID, &models.CreateNotebookRequest{Name: "n", Cells: notebookCells(src.ID, "SELECT n FROM logs")})
+	if err != nil {
+		t.Fatalf("CreateNotebook: %v", err)
+	}
+
+	cell, err := FindNotebookCell(nb, "q1")
+	if err != nil {
+		t.Fatalf("FindNotebookCell: %v", err)
+	}
+	if _, err := FindNotebookCell(nb, "missing"); !errors.Is(err, ErrNotebookCellNotFound) {
+		t.Fatalf("FindNotebookCell(missing) err = %v", err)
+	}
+	markdown, _ := FindNotebookCell(nb, "intro")
+	if _, err := RunNotebookCell(ctx, ds, markdown, NotebookRunOptions{}); !errors.Is(err, ErrInvalidNotebook) {
+		t.Fatalf("RunNotebookCell(markdown) err = %v, want ErrInvalidNotebook", err)
+	}
+
+	result, err := RunNotebookCell(ctx, ds, cell, NotebookRunOptions{MaxLimit: 1000})
+	if err != nil {
+		t.Fatalf("RunNotebookCell: %v", err)
+	}
+	if gotReq.Limit != models.DefaultNotebookCellLimit || gotReq.StartTime == nil || !gotReq.StartTime.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected query request: %+v", gotReq)
+	}
+	if len(result.Rows) != models.MaxNotebookCachedRows || !result.RowsTruncated || result.Error != "" {
+		t.Fatalf("unexpected result: rows=%d truncated=%v error=%q", len(result.Rows), result.RowsTruncated, result.Error)
+	}
+
+	if err := CacheNotebookCellResult(ctx, db, log, nb.ID, cell, result); err != nil {
+		t.Fatalf("CacheNotebookCellResult: %v", err)
+	}
+	stored, _ := GetNotebook(ctx, db, log, team.ID, nb.ID)
+	if !stored.UpdatedAt.Equal(nb.UpdatedAt) {
+		t.Errorf("caching a result advanced updated_at: %v -> %v", nb.UpdatedAt, stored.UpdatedAt)
+	}
+	if c, _ := FindNotebookCell(stored, "q1"); c.Result == nil || len(c.Result.Rows) != models.MaxNotebookCachedRows {
+		t.Fatalf("result was not cached: %+v", c.Result)
+	}
+
+	// Editing only the narrative keeps the cached result; changing the query
+	// drops it.
+	edited := json.RawMessage(fmt.Sprintf(`[
+		{"id":"intro","type":"markdown","content":"# Outage, revised"},
+		{"id":"q1","type":"query","content":"SELECT n FROM logs","source_id":%d,"query_language":"clickhouse-sql",
+		 "start_time":"2026-01-01T00:00:00Z","end_time":"2026-01-01T01:00:00Z"}
+	]`, src.ID))
+	updated, err := UpdateNotebook(ctx, db, log, team.ID, nb.ID, author, &models.UpdateNotebookRequest{Name: "n", Cells: edited, UpdatedAt: stored.UpdatedAt})
+	if err != nil {
+		t.Fatalf("UpdateNotebook: %v", err)
+	}
+	if c, _ := FindNotebookCell(updated, "q1"); c.Result == nil {
+		t.Error("narrative edit dropped the cached result")
+	}
+
+	updated, err = UpdateNotebook(ctx, db, log, team.ID, nb.ID, author, &models.UpdateNotebookRequest{Name: "n", Cells: notebookCells(src.ID, "SELECT broken")})
+	if err != nil {
+		t.Fatalf("UpdateNotebook: %v", err)
+	}
+	changed, _ := FindNotebookCell(updated, "q1")
+	if changed.Result != nil {
+		t.Error("query edit kept a stale cached result")
+	}
+
+	// A result computed for the old query is not written over the new one.
+	if err := CacheNotebookCellResult(ctx, db, log, nb.ID, cell, result); err != nil {
+		t.Fatalf("CacheNotebookCellResult(stale): %v", err)
+	}
+	if reloaded, _ := GetNotebook(ctx, db, log, team.ID, nb.ID); reloaded != nil {
+		if c, _ := FindNotebookCell(reloaded, "q1"); c.Result != nil {
+			t.Error("stale result was cached over an edited cell")
+		}
+	}
+
+	// Query failures come back as a result carrying the error.
+	failed, err := RunNotebookCell(ctx, ds, changed, NotebookRunOptions{})
+	if err != nil {
+		t.Fatalf("RunNotebookCell(broken): %v", err)
+	}
+	if failed.Error == "" || len(failed.Rows) != 0 {
+		t.Errorf("expected an error result, got %+v", failed)
+	}
+}
+
+func TestUpdateNotebookRejectsStaleWrite(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	author := newTestUser(t, db, "author@test.dev", "Author")
+	team, src := seedTeamWithSource(t, db, "team-a", author)
+	nb, err := CreateNotebook(ctx, db, log, author, team.ID, &models.CreateNotebookRequest{Name: "n", Cells: notebookCells(src.ID, "SELECT 1")})
+	if err != nil {
+		t.Fatalf("CreateNotebook: %v", err)
+	}
+
+	_, err = UpdateNotebook(ctx, db, log, team.ID, nb.ID, author, &models.UpdateNotebookRequest{
+		Name:      "n2",
+		UpdatedAt: nb.UpdatedAt.Add(-time.Hour),
+	})
+	if !errors.Is(err, ErrNotebookConflict) {
+		t.Fatalf("err = %v, want ErrNotebookConflict", err)
+	}
+}
+
+func TestPinnedNotebookCellKeepsResult(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	author := newTestUser(t, db, "author@test.dev", "Author")
+	team, src := seedTeamWithSource(t, db, "team-a", author)
+	pinned := func(query string) json.RawMessage {
+		return json.RawMessage(fmt.Sprintf(`[
+			{"id":"q1","type":"query","content":%q,"source_id":%d,"query_language":"clickhouse-sql",
+			 "start_time":"2026-01-01T00:00:00Z","end_time":"2026-01-01T01:00:00Z",
+			 "pinned":true,"notes":"Errors begin at 00:12."}
+		]`, query, src.ID))
+	}
+	nb, err := CreateNotebook(ctx, db, log, author, team.ID, &models.CreateNotebookRequest{Name: "n", Cells: pinned("SELECT 1")})
+	if err != nil {
+		t.Fatalf("CreateNotebook: %v", err)
+	}
+	cell, _ := FindNotebookCell(nb, "q1")
+	if !cell.Pinned || cell.Notes != "Errors begin at 00:12." {
+		t.Fatalf("cell lost its pin or notes: %+v", cell)
+	}
+
+	// The first run fills a pinned cell; later runs don't replace it.
+	first := &models.NotebookCellResult{ExecutedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	if err := CacheNotebookCellResult(ctx, db, log, nb.ID, cell, first); err != nil {
+		t.Fatalf("CacheNotebookCellResult(first): %v", err)
+	}
+	later := &models.NotebookCellResult{ExecutedAt: first.ExecutedAt.Add(time.Hour)}
+	if err := CacheNotebookCellResult(ctx, db, log, nb.ID, cell, later); !errors.Is(err, ErrNotebookCellPinned) {
+		t.Fatalf("CacheNotebookCellResult(later) err = %v, want ErrNotebookCellPinned", err)
+	}
+	stored, _ := GetNotebook(ctx, db, log, team.ID, nb.ID)
+	if c, _ := FindNotebookCell(stored, "q1"); c.Result == nil || !c.Result.ExecutedAt.Equal(first.ExecutedAt) {
+		t.Fatalf("pinned result was replaced: %+v", c.Result)
+	}
+
+	// A pinned cell's query can't change until it is unpinned.
+	if _, err := UpdateNotebook(ctx, db, log, team.ID, nb.ID, author, &models.UpdateNotebookRequest{Name: "n", Cells: pinned("SELECT 2")}); !errors.Is(err, ErrInvalidNotebook) {
+		t.Fatalf("UpdateNotebook(pinned query change) err = %v, want ErrInvalidNotebook", err)
+	}
+	updated, err := UpdateNotebook(ctx, db, log, team.ID, nb.ID, author, &models.UpdateNotebookRequest{Name: "n", Cells: notebookCells(src.ID, "SELECT 2")})
+	if err != nil {
+		t.Fatalf("UpdateNotebook(unpin): %v", err)
+	}
+	if c, _ := FindNotebookCell(updated, "q1"); c.Pinned || c.Result != nil {
+		t.Errorf("unpinned query edit kept the old result: %+v", c)
+	}
+}
+
+// racingNotebookStore caches a result for another cell right after the first
+// GetNotebook, standing in for a cell run that finishes concurrently.
+type racingNotebookStore struct {
+	store.StoreOps
+	race func()
+}
+
+func (s *racingNotebookStore) GetNotebook(ctx context.Context, id int) (*models.Notebook, error) {
+	nb, err := s.StoreOps.GetNotebook(ctx, id)
+	if s.race != nil {
+		race := s.race
+		s.race = nil
+		race()
+	}
+	return nb, err
+}
+
+func TestCacheNotebookCellResultKeepsConcurrentRuns(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	author := newTestUser(t, db, "author@test.dev", "Author")
+	team, src := seedTeamWithSource(t, db, "team-a", author)
+	cells := json.RawMessage(fmt.Sprintf(`[
+		{"id":"q1","type":"query","content":"SELECT 1","source_id":%[1]d,"query_language":"clickhouse-sql",
+		 "start_time":"2026-01-01T00:00:00Z","end_time":"2026-01-01T01:00:00Z"},
+		{"id":"q2","type":"query","content":"SELECT 2","source_id":%[1]d,"query_language":"clickhouse-sql",
+		 "start_time":"2026-01-01T00:00:00Z","end_time":"2026-01-01T01:00:00Z"}
+	]`, src.ID))
+	nb, err := CreateNotebook(ctx, db, log, author, team.ID, &models.CreateNotebookRequest{Name: "n", Cells: cells})
+	if err != nil {
+		t.Fatalf("CreateNotebook: %v", err)
+	}
+	q1, _ := FindNotebookCell(nb, "q1")
+	q2, _ := FindNotebookCell(nb, "q2")
+	r1 := &models.NotebookCellResult{ExecutedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	r2 := &models.NotebookCellResult{ExecutedAt: r1.ExecutedAt.Add(time.Minute)}
+
+	racing := &racingNotebookStore{StoreOps: db, race: func() {
+		if err := CacheNotebookCellResult(ctx, db, log, nb.ID, q2, r2); err != nil {
+			t.Errorf("CacheNotebookCellResult(q2): %v", err)
+		}
+	}}
+	if err := CacheNotebookCellResult(ctx, racing, log, nb.ID, q1, r1); err != nil {
+		t.Fatalf("CacheNotebookCellResult(q1): %v", err)
+	}
+
+	stored, _ := GetNotebook(ctx, db, log, team.ID, nb.ID)
+	for id, want := range map[string]*models.NotebookCellResult{"q1": r1, "q2": r2} {
+		if c, _ := FindNotebookCell(stored, id); c.Result == nil || !c.Result.ExecutedAt.Equal(want.ExecutedAt) {
+			t.Errorf("cell %s result = %+v, want the one executed at %v", id, c.Result, want.ExecutedAt)
+		}
+	}
+
+	// A notebook deleted mid-run is reported, not retried forever.
+	racing.race = func() {
+		if err := db.DeleteNotebook(ctx, nb.ID); err != nil {
+			t.Errorf("DeleteNotebook: %v", err)
+		}
+	}
+	if err := CacheNotebookCellResult(ctx, racing, log, nb.ID, q1, r2); !errors.Is(err, ErrNotebookNotFound) {
+		t.Errorf("CacheNotebookCellResult(deleted) err = %v, want ErrNotebookNotFound", err)
+	}
+}
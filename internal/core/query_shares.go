@@ -0,0 +1,47 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// maxShareColumns bounds the column selection stored with a share link.
+const maxShareColumns = 200
+
+// ValidateQuerySharePayload checks the explorer state behind a share link:
+// its mode, a relative or absolute time range, and the selected columns.
+// Size limits are configuration and are checked by the caller.
+func ValidateQuerySharePayload(payload *models.QuerySharePayload) error {
+	switch payload.Mode {
+	case "logchefql":
+	case "native", "sql": // "sql" is what older clients sent for native queries
+		if strings.TrimSpace(payload.Query) == "" {
+			return &ValidationError{Field: "payload.query", Message: "is required"}
+		}
+	default:
+		return &ValidationError{Field: "payload.mode", Message: "must be logchefql or native"}
+	}
+	if payload.Limit < 0 {
+		return &ValidationError{Field: "payload.limit", Message: "cannot be negative"}
+	}
+	if err := validateTimeRange(payload.TimeRange); err != nil {
+		return &ValidationError{Field: "payload.time_range", Message: err.Error()}
+	}
+
+	if len(payload.Columns) > maxShareColumns {
+		return &ValidationError{Field: "payload.columns", Message: fmt.Sprintf("at most %d columns can be shared", maxShareColumns)}
+	}
+	seen := make(map[string]struct{}, len(payload.Columns))
+	for _, col := range payload.Columns {
+		if strings.TrimSpace(col) == "" {
+			return &ValidationError{Field: "payload.columns", Message: "column names cannot be empty"}
+		}
+		if _, dup := seen[col]; dup {
+			return &ValidationError{Field: "payload.columns", Message: fmt.Sprintf("column %q is listed twice", col)}
+		}
+		seen[col] = struct{}{}
+	}
+	return nil
+}
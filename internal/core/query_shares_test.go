@@ -0,0 +1,37 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestValidateQuerySharePayload(t *testing.T) {
+	valid := []models.QuerySharePayload{
+		{Mode: "logchefql", TimeRange: models.SavedQueryTimeRange{Relative: "15m"}, Columns: []string{"timestamp", "msg"}},
+		{Mode: "native", Query: "SELECT 1", Limit: 100},
+		{Mode: "sql", Query: "SELECT 1"},
+	}
+	for _, p := range valid {
+		if err := ValidateQuerySharePayload(&p); err != nil {
+			t.Errorf("ValidateQuerySharePayload(%+v) = %v, want nil", p, err)
+		}
+	}
+
+	invalid := map[string]models.QuerySharePayload{
+		"unknown mode":       {Mode: "promql"},
+		"empty native query": {Mode: "native", Query: "  "},
+		"negative limit":     {Mode: "logchefql", Limit: -1},
+		"bad relative range": {Mode: "logchefql", TimeRange: models.SavedQueryTimeRange{Relative: "last hour"}},
+		"empty column":       {Mode: "logchefql", Columns: []string{"msg", ""}},
+		"duplicate column":   {Mode: "logchefql", Columns: []string{"msg", "msg"}},
+		"too many columns":   {Mode: "logchefql", Columns: make([]string, maxShareColumns+1)},
+	}
+	for name, p := range invalid {
+		var verr *ValidationError
+		if err := ValidateQuerySharePayload(&p); !errors.As(err, &verr) {
+			t.Errorf("%s: ValidateQuerySharePayload() = %v, want a *ValidationError", name, err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mr-karan/logchef/internal/logchefql"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+var (
+	// ErrQuerySnippetNotFound is returned when a snippet cannot be located in
+	// the requested team.
+	ErrQuerySnippetNotFound = errors.New("query snippet not found")
+	// ErrInvalidQuerySnippet indicates the snippet request failed validation.
+	ErrInvalidQuerySnippet = errors.New("invalid query snippet")
+	// ErrQuerySnippetExists indicates the team already has a snippet by that name.
+	ErrQuerySnippetExists = errors.New("a query snippet with this name already exists")
+	// ErrQuerySnippetForbidden indicates the caller may not modify the snippet.
+	ErrQuerySnippetForbidden = errors.New("not authorized to edit this query snippet")
+)
+
+// CreateQuerySnippet validates and adds a snippet to teamID's library, owned
+// by the caller.
+func CreateQuerySnippet(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, teamID models.TeamID, req *models.QuerySnippetRequest) (*models.QuerySnippet, error) {
+	if req == nil || user == nil {
+		return nil, ErrInvalidQuerySnippet
+	}
+	owner := user.ID
+	snippet := &models.QuerySnippet{TeamID: teamID, CreatedBy: &owner}
+	if err := applyQuerySnippetRequest(snippet, req); err != nil {
+		return nil, err
+	}
+	if err := db.CreateQuerySnippet(ctx, snippet); err != nil {
+		if errors.Is(err, models.ErrConflict) {
+			return nil, ErrQuerySnippetExists
+		}
+		log.Error("failed to create query snippet", "error", err, "team_id", teamID)
+		return nil, fmt.Errorf("error creating query snippet: %w", err)
+	}
+	return snippet, nil
+}
+
+// GetQuerySnippet returns a snippet by id. One belonging to another team is
+// reported as not found so ids can't be probed across teams.
+func GetQuerySnippet(ctx context.Context, db store.StoreOps, teamID models.TeamID, id models.QuerySnippetID) (*models.QuerySnippet, error) {
+	snippet, err := db.GetQuerySnippet(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrQuerySnippetNotFound
+		}
+		return nil, fmt.Errorf("error getting query snippet: %w", err)
+	}
+	if snippet.TeamID != teamID {
+		return nil, ErrQuerySnippetNotFound
+	}
+	return snippet, nil
+}
+
+// ListQuerySnippets returns teamID's snippets ordered by name. A non-empty
+// search keeps those whose name contains it, ignoring case, as the editor's
+// autocomplete narrows while the user types.
+func ListQuerySnippets(ctx context.Context, db store.StoreOps, teamID models.TeamID, search string) ([]*models.QuerySnippet, error) {
+	all, err := db.ListQuerySnippetsByTeam(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing query snippets: %w", err)
+	}
+	search = strings.ToLower(strings.TrimSpace(search))
+	if search == "" {
+		return all, nil
+	}
+	snippets := make([]*models.QuerySnippet, 0, len(all))
+	for _, snippet := range all {
+		if strings.Contains(strings.ToLower(snippet.Name), search) {
+			snippets = append(snippets, snippet)
+		}
+	}
+	return snippets, nil
+}
+
+// UpdateQuerySnippet replaces a snippet's name, description and query.
+func UpdateQuerySnippet(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, teamID models.TeamID, id models.QuerySnippetID, req *models.QuerySnippetRequest) (*models.QuerySnippet, error) {
+	if req == nil || user == nil {
+		return nil, ErrInvalidQuerySnippet
+	}
+	snippet, err := GetQuerySnippet(ctx, db, teamID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireQuerySnippetEdit(ctx, db, snippet, user); err != nil {
+		return nil, err
+	}
+	if err := applyQuerySnippetRequest(snippet, req); err != nil {
+		return nil, err
+	}
+	if err := db.UpdateQuerySnippet(ctx, snippet); err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrQuerySnippetNotFound
+		case errors.Is(err, models.ErrConflict):
+			return nil, ErrQuerySnippetExists
+		}
+		log.Error("failed to update query snippet", "snippet_id", id, "error", err)
+		return nil, fmt.Errorf("error updating query snippet: %w", err)
+	}
+	return GetQuerySnippet(ctx, db, teamID, id)
+}
+
+// DeleteQuerySnippet removes a snippet the caller may edit.
+func DeleteQuerySnippet(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, teamID models.TeamID, id models.QuerySnippetID) error {
+	snippet, err := GetQuerySnippet(ctx, db, teamID, id)
+	if err != nil {
+		return err
+	}
+	if err := requireQuerySnippetEdit(ctx, db, snippet, user); err != nil {
+		return err
+	}
+	if err := db.DeleteQuerySnippet(ctx, id); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return ErrQuerySnippetNotFound
+		}
+		log.Error("failed to delete query snippet", "snippet_id", id, "error", err)
+		return fmt.Errorf("error deleting query snippet: %w", err)
+	}
+	return nil
+}
+
+// UserCanEditQuerySnippet reports whether user may modify the snippet: global
+// admins always; otherwise the caller's team role must allow managing saved
+// queries, and they must be the creator or a team admin/editor.
+func UserCanEditQuerySnippet(ctx context.Context, db store.StoreOps, snippet *models.QuerySnippet, user *models.User) (bool, error) {
+	if snippet == nil || user == nil {
+		return false, nil
+	}
+	if user.Role == models.UserRoleAdmin {
+		return true, nil
+	}
+	member, err := db.GetTeamMember(ctx, snippet.TeamID, user.ID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error checking query snippet edit access: %w", err)
+	}
+	if member == nil || !member.Role.HasPermission(models.TeamPermissionManageSavedQueries) {
+		return false, nil
+	}
+	if snippet.CreatedBy != nil && *snippet.CreatedBy == user.ID {
+		return true, nil
+	}
+	return member.Role == models.TeamRoleAdmin || member.Role == models.TeamRoleEditor, nil
+}
+
+func requireQuerySnippetEdit(ctx context.Context, db store.StoreOps, snippet *models.QuerySnippet, user *models.User) error {
+	canEdit, err := UserCanEditQuerySnippet(ctx, db, snippet, user)
+	if err != nil {
+		return err
+	}
+	if !canEdit {
+		return ErrQuerySnippetForbidden
+	}
+	return nil
+}
+
+// applyQuerySnippetRequest copies a request onto snippet and validates it,
+// including that the query parses as LogchefQL.
+func applyQuerySnippetRequest(snippet *models.QuerySnippet, req *models.QuerySnippetRequest) error {
+	snippet.Name = req.Name
+	snippet.Description = req.Description
+	snippet.Query = req.Query
+	if err := snippet.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidQuerySnippet, err)
+	}
+	if res := logchefql.Validate(snippet.Query); !res.Valid {
+		detail := "invalid syntax"
+		if res.Error != nil {
+			detail = res.Error.Error()
+		}
+		return fmt.Errorf("%w: query is not valid logchefql: %s", ErrInvalidQuerySnippet, detail)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestCreateQuerySnippetValidates(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	owner := newTestUser(t, db, "snippets@test.dev", "Owner")
+	team, _ := seedTeamWithSource(t, db, "team-a", owner)
+
+	cases := []struct {
+		name string
+		req  models.QuerySnippetRequest
+	}{
+		{"no name", models.QuerySnippetRequest{Name: " ", Query: `level="error"`}},
+		{"no query", models.QuerySnippetRequest{Name: "errors"}},
+		{"bad logchefql", models.QuerySnippetRequest{Name: "errors", Query: `level="error`}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := CreateQuerySnippet(ctx, db, log, owner, team.ID, &tc.req); !errors.Is(err, ErrInvalidQuerySnippet) {
+				t.Fatalf("err = %v, want ErrInvalidQuerySnippet", err)
+			}
+		})
+	}
+
+	snippet, err := CreateQuerySnippet(ctx, db, log, owner, team.ID, &models.QuerySnippetRequest{
+		Name: " Prod errors ", Query: `namespace="prod" and severity_text="error"`,
+	})
+	if err != nil {
+		t.Fatalf("CreateQuerySnippet: %v", err)
+	}
+	if snippet.Name != "Prod errors" || snippet.CreatedBy == nil || *snippet.CreatedBy != owner.ID {
+		t.Fatalf("unexpected snippet: %+v", snippet)
+	}
+	if _, err := CreateQuerySnippet(ctx, db, log, owner, team.ID, &models.QuerySnippetRequest{Name: "Prod errors", Query: `level="warn"`}); !errors.Is(err, ErrQuerySnippetExists) {
+		t.Fatalf("CreateQuerySnippet(duplicate) err = %v, want ErrQuerySnippetExists", err)
+	}
+}
+
+func TestQuerySnippetAccessAndSearch(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	owner := newTestUser(t, db, "owner@test.dev", "Owner")
+	colleague := newTestUser(t, db, "colleague@test.dev", "Colleague")
+	team, _ := seedTeamWithSource(t, db, "team-a", owner, colleague)
+	other, _ := seedTeamWithSource(t, db, "team-b", owner)
+
+	prod, err := CreateQuerySnippet(ctx, db, log, owner, team.ID, &models.QuerySnippetRequest{Name: "Prod errors", Query: `namespace="prod"`})
+	if err != nil {
+		t.Fatalf("CreateQuerySnippet: %v", err)
+	}
+	if _, err := CreateQuerySnippet(ctx, db, log, colleague, team.ID, &models.QuerySnippetRequest{Name: "Slow requests", Query: `duration_ms>1000`}); err != nil {
+		t.Fatalf("CreateQuerySnippet(colleague): %v", err)
+	}
+
+	if list, err := ListQuerySnippets(ctx, db, team.ID, "PROD"); err != nil || len(list) != 1 || list[0].ID != prod.ID {
+		t.Fatalf("ListQuerySnippets(search): %v / %+v", err, list)
+	}
+	if list, err := ListQuerySnippets(ctx, db, team.ID, ""); err != nil || len(list) != 2 {
+		t.Fatalf("ListQuerySnippets = %v / %d, want both", err, len(list))
+	}
+
+	// Snippets are team-scoped, and only the creator (or a team admin/editor)
+	// may change them.
+	if _, err := GetQuerySnippet(ctx, db, other.ID, prod.ID); !errors.Is(err, ErrQuerySnippetNotFound) {
+		t.Errorf("GetQuerySnippet(other team) err = %v, want ErrQuerySnippetNotFound", err)
+	}
+	if _, err := UpdateQuerySnippet(ctx, db, log, colleague, team.ID, prod.ID, &models.QuerySnippetRequest{Name: "mine", Query: `level="error"`}); !errors.Is(err, ErrQuerySnippetForbidden) {
+		t.Errorf("UpdateQuerySnippet(colleague) err = %v, want ErrQuerySnippetForbidden", err)
+	}
+	if _, err := UpdateQuerySnippet(ctx, db, log, owner, team.ID, prod.ID, &models.QuerySnippetRequest{Name: "Slow requests", Query: `level="error"`}); !errors.Is(err, ErrQuerySnippetExists) {
+		t.Errorf("UpdateQuerySnippet(taken name) err = %v, want ErrQuerySnippetExists", err)
+	}
+
+	updated, err := UpdateQuerySnippet(ctx, db, log, owner, team.ID, prod.ID, &models.QuerySnippetRequest{
+		Name: "Prod errors", Description: "errors and fatals in prod", Query: `namespace="prod" and severity_text="error"`,
+	})
+	if err != nil {
+		t.Fatalf("UpdateQuerySnippet: %v", err)
+	}
+	if updated.Description != "errors and fatals in prod" {
+		t.Fatalf("updated = %+v", updated)
+	}
+
+	if err := DeleteQuerySnippet(ctx, db, log, owner, team.ID, prod.ID); err != nil {
+		t.Fatalf("DeleteQuerySnippet: %v", err)
+	}
+	if _, err := GetQuerySnippet(ctx, db, team.ID, prod.ID); !errors.Is(err, ErrQuerySnippetNotFound) {
+		t.Errorf("GetQuerySnippet(deleted) err = %v, want ErrQuerySnippetNotFound", err)
+	}
+}
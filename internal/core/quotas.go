@@ -0,0 +1,61 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// ErrQuotaExceeded is returned when a team has used up its daily query quota.
+var ErrQuotaExceeded = errors.New("team query quota exceeded")
+
+// GetTeamUsage reports a team's queries and rows read on now's UTC day
+// against its quota. Usage comes from the daily stats rollup, so it counts
+// successful queries only.
+func GetTeamUsage(ctx context.Context, db store.StoreOps, teamID models.TeamID, quota models.TeamQuota, now time.Time) (*models.TeamUsage, error) {
+	day := now.UTC().Truncate(24 * time.Hour)
+	used, err := db.GetTeamQueryUsage(ctx, teamID, day.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("error getting team query usage: %w", err)
+	}
+
+	usage := &models.TeamUsage{
+		TeamQueryUsage: *used,
+		TeamID:         teamID,
+		Limits:         quota,
+		ResetsAt:       day.Add(24 * time.Hour),
+	}
+	if quota.MaxQueriesPerDay > 0 {
+		remaining := max(quota.MaxQueriesPerDay-used.Queries, 0)
+		usage.RemainingQueries = &remaining
+		usage.Exceeded = remaining == 0
+	}
+	if quota.MaxRowsReadPerDay > 0 {
+		remaining := max(quota.MaxRowsReadPerDay-used.RowsRead, 0)
+		usage.RemainingRowsRead = &remaining
+		usage.Exceeded = usage.Exceeded || remaining == 0
+	}
+	return usage, nil
+}
+
+// CheckTeamQuota returns an error wrapping ErrQuotaExceeded, with the usage,
+// once the team has reached either daily cap. Rows read are only known after
+// a query finishes, so the query that crosses the rows cap still completes
+// and the next one is rejected. A zero quota is never checked.
+func CheckTeamQuota(ctx context.Context, db store.StoreOps, teamID models.TeamID, quota models.TeamQuota, now time.Time) (*models.TeamUsage, error) {
+	if quota.IsZero() {
+		return nil, nil
+	}
+	usage, err := GetTeamUsage(ctx, db, teamID, quota, now)
+	if err != nil {
+		return nil, err
+	}
+	if usage.Exceeded {
+		return usage, fmt.Errorf("%w: %d queries and %d rows read today", ErrQuotaExceeded, usage.Queries, usage.RowsRead)
+	}
+	return usage, nil
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestCheckTeamQuota(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db, "quota@test.dev", "Quota")
+	team := models.TeamID(3)
+	now := time.Date(2026, 7, 10, 15, 30, 0, 0, time.UTC)
+
+	for range 2 {
+		if err := db.IncrementQueryStats(ctx, "2026-07-10", user.ID, team, 1, models.QueryLanguageLogchefQL, 10, 400); err != nil {
+			t.Fatalf("IncrementQueryStats: %v", err)
+		}
+	}
+
+	if usage, err := CheckTeamQuota(ctx, db, team, models.TeamQuota{}, now); err != nil || usage != nil {
+		t.Fatalf("zero quota: usage = %+v, err = %v; want no check", usage, err)
+	}
+
+	usage, err := CheckTeamQuota(ctx, db, team, models.TeamQuota{MaxQueriesPerDay: 5}, now)
+	if err != nil {
+		t.Fatalf("under quota: %v", err)
+	}
+	if usage.Queries != 2 || usage.RowsRead != 800 || *usage.RemainingQueries != 3 || usage.RemainingRowsRead != nil {
+		t.Errorf("usage = %+v", usage)
+	}
+	if want := time.Date(2026, 7, 11, 0, 0, 0, 0, time.UTC); !usage.ResetsAt.Equal(want) {
+		t.Errorf("ResetsAt = %v, want %v", usage.ResetsAt, want)
+	}
+
+	usage, err = CheckTeamQuota(ctx, db, team, models.TeamQuota{MaxQueriesPerDay: 5, MaxRowsReadPerDay: 500}, now)
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("rows over quota: err = %v, want ErrQuotaExceeded", err)
+	}
+	if !usage.Exceeded || *usage.RemainingRowsRead != 0 {
+		t.Errorf("usage = %+v", usage)
+	}
+
+	// The next UTC day starts from zero.
+	if _, err := CheckTeamQuota(ctx, db, team, models.TeamQuota{MaxRowsReadPerDay: 500}, now.Add(12*time.Hour)); err != nil {
+		t.Errorf("next day: %v", err)
+	}
+}
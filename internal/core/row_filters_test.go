@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestSetTeamSourceRowFilter(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	log := discardLogger()
+	ds := newFakeDatasourceService(db, log, nil)
+
+	src := newTestSource(t, db, "shared")
+	team, err := CreateTeam(ctx, db, log, "team-a", "")
+	if err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+	if err := AddTeamSource(ctx, db, log, team.ID, src.ID); err != nil {
+		t.Fatalf("AddTeamSource: %v", err)
+	}
+
+	policy, err := SetTeamSourceRowFilter(ctx, db, ds, team.ID, src.ID, "  namespace = 'team-a' ")
+	if err != nil {
+		t.Fatalf("SetTeamSourceRowFilter: %v", err)
+	}
+	if policy.RowFilter != "namespace = 'team-a'" {
+		t.Errorf("RowFilter = %q, want it trimmed", policy.RowFilter)
+	}
+	if got, err := GetTeamSourceRowFilter(ctx, db, team.ID, src.ID); err != nil || got != policy.RowFilter {
+		t.Errorf("GetTeamSourceRowFilter = %q, %v", got, err)
+	}
+
+	var validationErr *ValidationError
+	if _, err := SetTeamSourceRowFilter(ctx, db, ds, team.ID, src.ID, "namespace IN (SELECT 1)"); !errors.As(err, &validationErr) {
+		t.Errorf("subquery filter: err = %v, want a ValidationError", err)
+	}
+
+	if _, err := SetTeamSourceRowFilter(ctx, db, ds, team.ID, src.ID, ""); err != nil {
+		t.Fatalf("clearing the filter: %v", err)
+	}
+	if got, _ := GetTeamSourceRowFilter(ctx, db, team.ID, src.ID); got != "" {
+		t.Errorf("filter after clearing = %q", got)
+	}
+
+	other := newTestSource(t, db, "other")
+	if _, err := SetTeamSourceRowFilter(ctx, db, ds, team.ID, other.ID, "namespace = 'a'"); !errors.Is(err, ErrSourceNotLinked) {
+		t.Errorf("unlinked source: err = %v, want ErrSourceNotLinked", err)
+	}
+}
+
+func TestGetHistogramDataRowFilterSkipsRollups(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	var gotFilter string
+	ds := newFakeDatasourceService(db, discardLogger(), &fakeProvider{
+		histogramFn: func(_ context.Context, _ *models.Source, req datasource.HistogramRequest) (*datasource.HistogramResult, error) {
+			gotFilter = req.RowFilter
+			return &datasource.HistogramResult{Granularity: "1m"}, nil
+		},
+	})
+	src := newTestSource(t, db, "rolled-up")
+	rollups := &fakeHistogramRollups{}
+
+	if _, err := GetHistogramData(ctx, ds, rollups, src.ID, HistogramParams{Window: "1m"}); err != nil {
+		t.Fatalf("GetHistogramData: %v", err)
+	}
+	if rollups.calls != 1 {
+		t.Errorf("rollups called %d times without a row filter, want 1", rollups.calls)
+	}
+
+	if _, err := GetHistogramData(ctx, ds, rollups, src.ID, HistogramParams{Window: "1m", RowFilter: "namespace = 'a'"}); err != nil {
+		t.Fatalf("GetHistogramData: %v", err)
+	}
+	if rollups.calls != 1 || gotFilter != "namespace = 'a'" {
+		t.Errorf("rollup calls = %d, provider RowFilter = %q; want the filter sent to the datasource", rollups.calls, gotFilter)
+	}
+}
+
+// fakeHistogramRollups answers every histogram it is asked.
+type fakeHistogramRollups struct {
+	calls int
+}
+
+func (f *fakeHistogramRollups) Histogram(context.Context, models.SourceID, HistogramParams) (*HistogramResponse, bool, error) {
+	f.calls++
+	return &HistogramResponse{Granularity: "1m"}, true, nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/mr-karan/logchef/internal/datasource"
 	"github.com/mr-karan/logchef/internal/logchefql"
 	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/internal/template"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
@@ -63,30 +64,44 @@ func parseAndValidateSavedQueryContent(contentJSON string) (*models.SavedQueryCo
 		return nil, fmt.Errorf("%w: limit must be positive", ErrInvalidQueryContent)
 	}
 
-	hasRelativeTime := queryContent.TimeRange.Relative != ""
-	hasAbsoluteTime := queryContent.TimeRange.Absolute.Start != 0 || queryContent.TimeRange.Absolute.End != 0
+	if err := validateTimeRange(queryContent.TimeRange); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidQueryContent, err)
+	}
+	for _, v := range queryContent.Variables {
+		if template.VariableType(v.Type) == template.TypeEnum && len(v.Options) == 0 && v.SourceQueryID == 0 {
+			return nil, fmt.Errorf("%w: enum variable %s needs options or a source query", ErrInvalidQueryContent, v.Name)
+		}
+	}
+
+	return &queryContent, nil
+}
+
+// validateTimeRange checks the time range of a saved or shared query: a
+// relative window such as "15m" or an absolute range, not both.
+func validateTimeRange(tr models.SavedQueryTimeRange) error {
+	hasRelativeTime := tr.Relative != ""
+	hasAbsoluteTime := tr.Absolute.Start != 0 || tr.Absolute.End != 0
 
 	if hasRelativeTime && hasAbsoluteTime {
-		return nil, fmt.Errorf("%w: cannot specify both relative and absolute time range", ErrInvalidQueryContent)
+		return fmt.Errorf("cannot specify both relative and absolute time range")
 	}
 
-	if hasRelativeTime && !isValidRelativeTimeFormat(queryContent.TimeRange.Relative) {
-		return nil, fmt.Errorf("%w: invalid relative time format (expected e.g. '15m', '1h', '7d')", ErrInvalidQueryContent)
+	if hasRelativeTime && !isValidRelativeTimeFormat(tr.Relative) {
+		return fmt.Errorf("invalid relative time format (expected e.g. '15m', '1h', '7d')")
 	}
 
 	if hasAbsoluteTime {
-		if queryContent.TimeRange.Absolute.Start <= 0 {
-			return nil, fmt.Errorf("%w: absolute start time must be positive", ErrInvalidQueryContent)
+		if tr.Absolute.Start <= 0 {
+			return fmt.Errorf("absolute start time must be positive")
 		}
-		if queryContent.TimeRange.Absolute.End <= 0 {
-			return nil, fmt.Errorf("%w: absolute end time must be positive", ErrInvalidQueryContent)
+		if tr.Absolute.End <= 0 {
+			return fmt.Errorf("absolute end time must be positive")
 		}
-		if queryContent.TimeRange.Absolute.End < queryContent.TimeRange.Absolute.Start {
-			return nil, fmt.Errorf("%w: absolute end time must be after start time", ErrInvalidQueryContent)
+		if tr.Absolute.End < tr.Absolute.Start {
+			return fmt.Errorf("absolute end time must be after start time")
 		}
 	}
-
-	return &queryContent, nil
+	return nil
 }
 
 // ValidateContentMatchesLanguage returns an error when the query content does
@@ -217,6 +232,52 @@ func GetSavedQuery(ctx context.Context, db savedQueryGetter, log *slog.Logger, q
 	return q, nil
 }
 
+// LoadVariableChoices runs a saved query and returns the distinct values of its
+// first column, the choices of enum variables that name it as their source
+// query. Only native queries without variables of their own can list choices.
+func LoadVariableChoices(ctx context.Context, ds *datasource.Service, query *models.SavedQuery) ([]string, error) {
+	if models.NormalizeQueryLanguage(query.QueryLanguage) == models.QueryLanguageLogchefQL {
+		return nil, fmt.Errorf("%w: a source query must be a native query", ErrInvalidQueryDefinition)
+	}
+	content, err := parseAndValidateSavedQueryContent(query.QueryContent)
+	if err != nil {
+		return nil, err
+	}
+	if content == nil {
+		return nil, fmt.Errorf("%w: query content cannot be empty", ErrInvalidQueryContent)
+	}
+	if len(template.ExtractVariableNames(content.Content)) > 0 {
+		return nil, fmt.Errorf("%w: a source query cannot use variables", ErrInvalidQueryDefinition)
+	}
+
+	result, err := QueryLogs(ctx, ds, query.SourceID, datasource.QueryRequest{
+		RawQuery: content.Content,
+		Limit:    template.MaxChoices,
+		MaxLimit: template.MaxChoices,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Columns) == 0 {
+		return []string{}, nil
+	}
+	column := result.Columns[0].Name
+	seen := make(map[string]bool, len(result.Logs))
+	choices := make([]string, 0, len(result.Logs))
+	for _, row := range result.Logs {
+		value, ok := row[column]
+		if !ok || value == nil {
+			continue
+		}
+		choice := fmt.Sprint(value)
+		if !seen[choice] {
+			seen[choice] = true
+			choices = append(choices, choice)
+		}
+	}
+	return choices, nil
+}
+
 // UpdateSavedQuery applies new field values to an existing saved query.
 func UpdateSavedQuery(ctx context.Context, db store.StoreOps, ds *datasource.Service, log *slog.Logger, queryID int, name, description, queryContentJSON string, queryLanguage models.QueryLanguage, editorMode models.SavedQueryEditorMode) (*models.SavedQuery, error) {
 	existing, err := db.GetSavedQuery(ctx, queryID)
@@ -0,0 +1,294 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+var (
+	// ErrSilenceNotFound is returned when a silence cannot be located or the
+	// caller cannot see what it covers.
+	ErrSilenceNotFound = errors.New("alert silence not found")
+	// ErrInvalidSilence indicates the silence request failed validation.
+	ErrInvalidSilence = errors.New("invalid alert silence")
+	// ErrSilenceForbidden is returned when the caller may see a silence but not
+	// manage alerts on what it covers.
+	ErrSilenceForbidden = errors.New("your team role does not permit managing alerts covered by this silence")
+)
+
+// AlertSilenceFilter narrows ListAlertSilences. Zero fields match everything.
+type AlertSilenceFilter struct {
+	AlertID  models.AlertID
+	SourceID models.SourceID
+	TeamID   models.TeamID
+	// ActiveOnly keeps only silences in effect right now.
+	ActiveOnly bool
+}
+
+// CreateAlertSilence validates and stores a silence created by user, who must
+// be allowed to manage alerts on whatever it covers.
+func CreateAlertSilence(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, req *models.CreateAlertSilenceRequest) (*models.AlertSilence, error) {
+	now := time.Now().UTC()
+	silence := &models.AlertSilence{
+		AlertID:    req.AlertID,
+		SourceID:   req.SourceID,
+		TeamID:     req.TeamID,
+		Reason:     req.Reason,
+		Recurrence: req.Recurrence,
+		CreatedBy:  &user.ID,
+	}
+	if err := applySilenceSchedule(silence, now, req.StartsAt, req.EndsAt, req.DurationSeconds); err != nil {
+		return nil, err
+	}
+	if silence.EndsAt != nil && !silence.EndsAt.After(now) {
+		return nil, fmt.Errorf("%w: the silence would already have ended", ErrInvalidSilence)
+	}
+	if err := checkSilenceTarget(ctx, db, silence); err != nil {
+		return nil, err
+	}
+	if err := requireSilenceAccess(ctx, db, user, silence); err != nil {
+		return nil, err
+	}
+
+	if err := db.CreateAlertSilence(ctx, silence); err != nil {
+		log.Error("failed to create alert silence", "error", err)
+		return nil, fmt.Errorf("error creating alert silence: %w", err)
+	}
+	silence.Active = silence.ActiveAt(now)
+	return silence, nil
+}
+
+// GetAlertSilence returns a silence the user can see.
+func GetAlertSilence(ctx context.Context, db store.StoreOps, user *models.User, id models.SilenceID) (*models.AlertSilence, error) {
+	silence, err := db.GetAlertSilence(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrSilenceNotFound
+		}
+		return nil, fmt.Errorf("error getting alert silence: %w", err)
+	}
+	visible, err := UserCanViewSilence(ctx, db, user, silence)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrSilenceNotFound
+	}
+	silence.Active = silence.ActiveAt(time.Now())
+	return silence, nil
+}
+
+// ListAlertSilences returns the silences the user can see that match filter,
+// newest start first.
+func ListAlertSilences(ctx context.Context, db store.StoreOps, user *models.User, filter AlertSilenceFilter) ([]*models.AlertSilence, error) {
+	all, err := db.ListAlertSilences(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing alert silences: %w", err)
+	}
+	now := time.Now()
+	silences := make([]*models.AlertSilence, 0, len(all))
+	for _, silence := range all {
+		if !filter.matches(silence) {
+			continue
+		}
+		silence.Active = silence.ActiveAt(now)
+		if filter.ActiveOnly && !silence.Active {
+			continue
+		}
+		visible, err := UserCanViewSilence(ctx, db, user, silence)
+		if err != nil {
+			return nil, err
+		}
+		if visible {
+			silences = append(silences, silence)
+		}
+	}
+	return silences, nil
+}
+
+func (f AlertSilenceFilter) matches(silence *models.AlertSilence) bool {
+	if f.AlertID != 0 && (silence.AlertID == nil || *silence.AlertID != f.AlertID) {
+		return false
+	}
+	if f.SourceID != 0 && (silence.SourceID == nil || *silence.SourceID != f.SourceID) {
+		return false
+	}
+	if f.TeamID != 0 && (silence.TeamID == nil || *silence.TeamID != f.TeamID) {
+		return false
+	}
+	return true
+}
+
+// UpdateAlertSilence replaces a silence's reason and schedule.
+func UpdateAlertSilence(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, id models.SilenceID, req *models.UpdateAlertSilenceRequest) (*models.AlertSilence, error) {
+	silence, err := GetAlertSilence(ctx, db, user, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireSilenceAccess(ctx, db, user, silence); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	silence.Reason = req.Reason
+	silence.Recurrence = req.Recurrence
+	silence.EndsAt = nil
+	startsAt := req.StartsAt
+	if startsAt == nil {
+		current := silence.StartsAt
+		startsAt = &current
+	}
+	if err := applySilenceSchedule(silence, now, startsAt, req.EndsAt, req.DurationSeconds); err != nil {
+		return nil, err
+	}
+
+	if err := db.UpdateAlertSilence(ctx, silence); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrSilenceNotFound
+		}
+		log.Error("failed to update alert silence", "silence_id", id, "error", err)
+		return nil, fmt.Errorf("error updating alert silence: %w", err)
+	}
+	return GetAlertSilence(ctx, db, user, id)
+}
+
+// DeleteAlertSilence lifts a silence and returns what was deleted.
+func DeleteAlertSilence(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, id models.SilenceID) (*models.AlertSilence, error) {
+	silence, err := GetAlertSilence(ctx, db, user, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireSilenceAccess(ctx, db, user, silence); err != nil {
+		return nil, err
+	}
+	if err := db.DeleteAlertSilence(ctx, id); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrSilenceNotFound
+		}
+		log.Error("failed to delete alert silence", "silence_id", id, "error", err)
+		return nil, fmt.Errorf("error deleting alert silence: %w", err)
+	}
+	return silence, nil
+}
+
+// UserCanViewSilence reports whether the user can see what a silence covers:
+// the alert's or the source's logs, or membership of the team. Like alerts,
+// admins need that access too.
+func UserCanViewSilence(ctx context.Context, db store.StoreOps, user *models.User, silence *models.AlertSilence) (bool, error) {
+	if silence.TeamID != nil {
+		member, err := db.GetTeamMember(ctx, *silence.TeamID, user.ID)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return false, nil
+			}
+			return false, fmt.Errorf("error checking team membership: %w", err)
+		}
+		return member != nil, nil
+	}
+	sourceID, err := silenceSourceID(ctx, db, silence)
+	if err != nil || sourceID == 0 {
+		return false, err
+	}
+	return db.UserHasSourceAccess(ctx, user.ID, sourceID)
+}
+
+// requireSilenceAccess returns ErrSilenceForbidden unless the user may manage
+// alerts on what the silence covers. Global admins always may.
+func requireSilenceAccess(ctx context.Context, db store.StoreOps, user *models.User, silence *models.AlertSilence) error {
+	var allowed bool
+	var err error
+	switch {
+	case user.Role == models.UserRoleAdmin:
+		allowed = true
+	case silence.TeamID != nil:
+		allowed, err = UserHasTeamPermission(ctx, db, *silence.TeamID, user.ID, models.TeamPermissionManageAlerts)
+	default:
+		var sourceID models.SourceID
+		sourceID, err = silenceSourceID(ctx, db, silence)
+		if err == nil && sourceID != 0 {
+			allowed, err = UserHasSourcePermission(ctx, db, user, sourceID, models.TeamPermissionManageAlerts)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrSilenceForbidden
+	}
+	return nil
+}
+
+// silenceSourceID is the source an alert- or source-scoped silence covers, or
+// 0 if its alert no longer exists.
+func silenceSourceID(ctx context.Context, db store.StoreOps, silence *models.AlertSilence) (models.SourceID, error) {
+	if silence.SourceID != nil {
+		return *silence.SourceID, nil
+	}
+	if silence.AlertID == nil {
+		return 0, nil
+	}
+	alert, err := db.GetAlert(ctx, *silence.AlertID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("error getting silenced alert: %w", err)
+	}
+	return alert.SourceID, nil
+}
+
+// checkSilenceTarget confirms the alert, source or team being silenced exists.
+func checkSilenceTarget(ctx context.Context, db store.StoreOps, silence *models.AlertSilence) error {
+	var err error
+	var target string
+	switch {
+	case silence.AlertID != nil:
+		_, err = db.GetAlert(ctx, *silence.AlertID)
+		target = fmt.Sprintf("alert %d", *silence.AlertID)
+	case silence.SourceID != nil:
+		_, err = db.GetSource(ctx, *silence.SourceID)
+		target = fmt.Sprintf("source %d", *silence.SourceID)
+	case silence.TeamID != nil:
+		_, err = db.GetTeam(ctx, *silence.TeamID)
+		target = fmt.Sprintf("team %d", *silence.TeamID)
+	}
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return fmt.Errorf("%w: %s not found", ErrInvalidSilence, target)
+		}
+		return fmt.Errorf("error checking silence target: %w", err)
+	}
+	return nil
+}
+
+// applySilenceSchedule sets the silence's start and end from a request and
+// validates the result. The start defaults to now; the end is endsAt, or the
+// start plus durationSeconds.
+func applySilenceSchedule(silence *models.AlertSilence, now time.Time, startsAt, endsAt *time.Time, durationSeconds int) error {
+	silence.StartsAt = now
+	if startsAt != nil && !startsAt.IsZero() {
+		silence.StartsAt = startsAt.UTC()
+	}
+	switch {
+	case durationSeconds < 0:
+		return fmt.Errorf("%w: duration_seconds must be positive", ErrInvalidSilence)
+	case durationSeconds > 0 && endsAt != nil:
+		return fmt.Errorf("%w: set ends_at or duration_seconds, not both", ErrInvalidSilence)
+	case durationSeconds > 0:
+		end := silence.StartsAt.Add(time.Duration(durationSeconds) * time.Second)
+		silence.EndsAt = &end
+	case endsAt != nil:
+		end := endsAt.UTC()
+		silence.EndsAt = &end
+	}
+	if err := silence.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSilence, err)
+	}
+	return nil
+}
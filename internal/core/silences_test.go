@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestCreateAlertSilenceValidates(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	owner := newTestUser(t, db, "silencer@test.dev", "Owner")
+	_, src := seedTeamWithSource(t, db, "team-a", owner)
+	missing := models.SourceID(9999)
+	past := time.Now().Add(-time.Hour)
+
+	cases := []struct {
+		name string
+		req  models.CreateAlertSilenceRequest
+	}{
+		{"no target", models.CreateAlertSilenceRequest{DurationSeconds: 3600}},
+		{"no end", models.CreateAlertSilenceRequest{SourceID: &src.ID}},
+		{"end and duration", models.CreateAlertSilenceRequest{SourceID: &src.ID, EndsAt: &past, DurationSeconds: 60}},
+		{"already over", models.CreateAlertSilenceRequest{SourceID: &src.ID, EndsAt: &past}},
+		{"missing source", models.CreateAlertSilenceRequest{SourceID: &missing, DurationSeconds: 3600}},
+		{"bad window", models.CreateAlertSilenceRequest{SourceID: &src.ID, Recurrence: &models.SilenceRecurrence{StartTime: "2am", DurationMinutes: 60}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := CreateAlertSilence(ctx, db, log, owner, &tc.req); !errors.Is(err, ErrInvalidSilence) {
+				t.Fatalf("err = %v, want ErrInvalidSilence", err)
+			}
+		})
+	}
+
+	silence, err := CreateAlertSilence(ctx, db, log, owner, &models.CreateAlertSilenceRequest{
+		SourceID: &src.ID, Reason: " deploy ", DurationSeconds: 1800,
+	})
+	if err != nil {
+		t.Fatalf("CreateAlertSilence: %v", err)
+	}
+	if !silence.Active || silence.Reason != "deploy" || silence.EndsAt == nil ||
+		silence.EndsAt.Sub(silence.StartsAt) != 30*time.Minute || silence.CreatedBy == nil || *silence.CreatedBy != owner.ID {
+		t.Fatalf("unexpected silence: %+v", silence)
+	}
+}
+
+func TestAlertSilenceAccess(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	owner := newTestUser(t, db, "silencer@test.dev", "Owner")
+	viewer := newTestUser(t, db, "viewer@test.dev", "Viewer")
+	outsider := newTestUser(t, db, "outsider@test.dev", "Outsider")
+	team, src := seedTeamWithSource(t, db, "team-a", owner)
+	if err := AddTeamMember(ctx, db, log, team.ID, viewer.ID, models.TeamRoleViewer); err != nil {
+		t.Fatalf("AddTeamMember: %v", err)
+	}
+	seedTeamWithSource(t, db, "team-b", outsider)
+
+	// A weekly window on the whole team, open-ended.
+	req := &models.CreateAlertSilenceRequest{
+		TeamID: &team.ID,
+		Recurrence: &models.SilenceRecurrence{
+			Weekdays: []time.Weekday{time.Sunday}, StartTime: "02:00", DurationMinutes: 120,
+		},
+	}
+	if _, err := CreateAlertSilence(ctx, db, log, viewer, req); !errors.Is(err, ErrSilenceForbidden) {
+		t.Fatalf("CreateAlertSilence(viewer) err = %v, want ErrSilenceForbidden", err)
+	}
+	window, err := CreateAlertSilence(ctx, db, log, owner, req)
+	if err != nil {
+		t.Fatalf("CreateAlertSilence: %v", err)
+	}
+	if _, err := CreateAlertSilence(ctx, db, log, outsider, &models.CreateAlertSilenceRequest{SourceID: &src.ID, DurationSeconds: 60}); !errors.Is(err, ErrSilenceForbidden) {
+		t.Fatalf("CreateAlertSilence(outsider) err = %v, want ErrSilenceForbidden", err)
+	}
+
+	// Viewers can see the silence but not change it; outsiders can't see it.
+	if list, err := ListAlertSilences(ctx, db, viewer, AlertSilenceFilter{TeamID: team.ID}); err != nil || len(list) != 1 {
+		t.Fatalf("ListAlertSilences(viewer): %v / %d", err, len(list))
+	}
+	if list, err := ListAlertSilences(ctx, db, outsider, AlertSilenceFilter{}); err != nil || len(list) != 0 {
+		t.Fatalf("ListAlertSilences(outsider): %v / %d", err, len(list))
+	}
+	if _, err := GetAlertSilence(ctx, db, outsider, window.ID); !errors.Is(err, ErrSilenceNotFound) {
+		t.Errorf("GetAlertSilence(outsider) err = %v, want ErrSilenceNotFound", err)
+	}
+	if _, err := DeleteAlertSilence(ctx, db, log, viewer, window.ID); !errors.Is(err, ErrSilenceForbidden) {
+		t.Errorf("DeleteAlertSilence(viewer) err = %v, want ErrSilenceForbidden", err)
+	}
+
+	// Turning the window into a one-off silence keeps its start.
+	ends := time.Now().Add(time.Hour)
+	updated, err := UpdateAlertSilence(ctx, db, log, owner, window.ID, &models.UpdateAlertSilenceRequest{Reason: "db upgrade", EndsAt: &ends})
+	if err != nil {
+		t.Fatalf("UpdateAlertSilence: %v", err)
+	}
+	if updated.Recurrence != nil || updated.Reason != "db upgrade" || !updated.StartsAt.Equal(window.StartsAt) || !updated.Active {
+		t.Fatalf("updated = %+v", updated)
+	}
+
+	deleted, err := DeleteAlertSilence(ctx, db, log, owner, window.ID)
+	if err != nil || deleted.ID != window.ID {
+		t.Fatalf("DeleteAlertSilence: %v / %+v", err, deleted)
+	}
+	if _, err := GetAlertSilence(ctx, db, owner, window.ID); !errors.Is(err, ErrSilenceNotFound) {
+		t.Errorf("GetAlertSilence(deleted) err = %v, want ErrSilenceNotFound", err)
+	}
+}
@@ -0,0 +1,244 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+var (
+	// ErrSLONotFound is returned when an SLO cannot be located in the
+	// requested team.
+	ErrSLONotFound = errors.New("slo not found")
+	// ErrInvalidSLO indicates the request payload failed validation.
+	ErrInvalidSLO = errors.New("invalid slo")
+	// ErrSLOForbidden indicates the caller may not modify the SLO.
+	ErrSLOForbidden = errors.New("not authorized to edit this slo")
+)
+
+// CreateSLO validates and persists a new SLO in teamID, owned by the caller.
+// SLIs in ClickHouse SQL need allowRawSQL, as alert queries do.
+func CreateSLO(ctx context.Context, db store.StoreOps, ds *datasource.Service, log *slog.Logger, user *models.User, teamID models.TeamID, allowRawSQL bool, req *models.CreateSLORequest) (*models.SLO, error) {
+	if req == nil || user == nil {
+		return nil, ErrInvalidSLO
+	}
+	owner := user.ID
+	slo := &models.SLO{
+		TeamID:        teamID,
+		SourceID:      req.SourceID,
+		Name:          req.Name,
+		Description:   req.Description,
+		QueryLanguage: req.QueryLanguage,
+		GoodQuery:     req.GoodQuery,
+		TotalQuery:    req.TotalQuery,
+		Target:        req.Target,
+		WindowSeconds: req.WindowSeconds,
+		CreatedBy:     &owner,
+	}
+	if err := validateSLO(ctx, db, ds, slo); err != nil {
+		return nil, err
+	}
+	if !allowRawSQL && sloRunsRawSQL(slo) {
+		return nil, ErrRawSQLDisabled
+	}
+	if err := db.CreateSLO(ctx, slo); err != nil {
+		log.Error("failed to create slo", "error", err, "team_id", teamID, "source_id", slo.SourceID)
+		return nil, fmt.Errorf("failed to create slo: %w", err)
+	}
+	log.Info("slo created", "slo_id", slo.ID, "team_id", teamID, "source_id", slo.SourceID, "created_by", owner)
+	return slo, nil
+}
+
+// validateSLO checks the SLO's definition, that its source is linked to its
+// team, and that both SLI queries can run there. LogchefQL filters are
+// compiled once, so a bad filter is rejected at save time rather than on the
+// first evaluation.
+func validateSLO(ctx context.Context, db store.StoreOps, ds *datasource.Service, slo *models.SLO) error {
+	if err := slo.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSLO, err)
+	}
+	linked, err := db.TeamHasSource(ctx, slo.TeamID, slo.SourceID)
+	if err != nil {
+		return fmt.Errorf("failed to verify team/source link: %w", err)
+	}
+	if !linked {
+		return fmt.Errorf("%w: source %d is not linked to this team", ErrInvalidSLO, slo.SourceID)
+	}
+	if ds == nil {
+		return fmt.Errorf("%w: datasource service is required", ErrInvalidSLO)
+	}
+
+	if slo.QueryLanguage != models.QueryLanguageLogchefQL {
+		if err := ds.ValidateAlertSupport(ctx, slo.SourceID, slo.QueryLanguage, models.AlertEditorModeNative); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidSLO, err)
+		}
+		return nil
+	}
+	for _, sli := range []struct{ name, filter string }{{"total_query", slo.TotalQuery}, {"good_query", slo.GoodQuery}} {
+		_, err := ds.BuildConditionAlertQuery(ctx, slo.SourceID, datasource.ConditionAlertRequest{
+			Condition:       &models.AlertCondition{Filter: sli.filter, Aggregate: models.AlertAggregateCount},
+			LookbackSeconds: slo.WindowSeconds,
+			Now:             time.Now(),
+		})
+		if errors.Is(err, datasource.ErrOperationNotSupported) {
+			return fmt.Errorf("%w: this source does not support logchefql SLIs; use its native query language", ErrInvalidSLO)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: invalid %s: %s", ErrInvalidSLO, sli.name, err)
+		}
+	}
+	return nil
+}
+
+// sloRunsRawSQL reports whether the SLO's SLIs are ClickHouse SQL, which the
+// team raw SQL switch governs.
+func sloRunsRawSQL(slo *models.SLO) bool {
+	return slo.QueryLanguage == models.QueryLanguageClickHouseSQL
+}
+
+// GetSLO retrieves an SLO by id with its latest evaluation as Status. An SLO
+// belonging to another team is reported as not found so ids can't be probed
+// across teams.
+func GetSLO(ctx context.Context, db store.StoreOps, log *slog.Logger, teamID models.TeamID, id models.SLOID) (*models.SLO, error) {
+	slo, err := db.GetSLO(ctx, id)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return nil, ErrSLONotFound
+		}
+		log.Error("failed to get slo", "slo_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get slo: %w", err)
+	}
+	if slo.TeamID != teamID {
+		return nil, ErrSLONotFound
+	}
+	if err := attachSLOStatus(ctx, db, slo); err != nil {
+		return nil, err
+	}
+	return slo, nil
+}
+
+// ListSLOs returns a team's SLOs, each with its latest evaluation as Status.
+func ListSLOs(ctx context.Context, db store.StoreOps, teamID models.TeamID) ([]*models.SLO, error) {
+	slos, err := db.ListSLOsByTeam(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list slos: %w", err)
+	}
+	for _, slo := range slos {
+		if err := attachSLOStatus(ctx, db, slo); err != nil {
+			return nil, err
+		}
+	}
+	return slos, nil
+}
+
+func attachSLOStatus(ctx context.Context, db store.StoreOps, slo *models.SLO) error {
+	evals, err := db.ListSLOEvaluations(ctx, slo.ID, 1)
+	if err != nil {
+		return fmt.Errorf("failed to load slo status: %w", err)
+	}
+	if len(evals) > 0 {
+		slo.Status = evals[0]
+	}
+	return nil
+}
+
+// UpdateSLO validates and persists changes to an existing SLO. Its history is
+// kept: earlier evaluations stay valid against the definition they ran with.
+// Changing SLIs in ClickHouse SQL needs allowRawSQL; other edits don't.
+func UpdateSLO(ctx context.Context, db store.StoreOps, ds *datasource.Service, log *slog.Logger, teamID models.TeamID, id models.SLOID, user *models.User, allowRawSQL bool, req *models.UpdateSLORequest) (*models.SLO, error) {
+	if req == nil || user == nil {
+		return nil, ErrInvalidSLO
+	}
+	existing, err := GetSLO(ctx, db, log, teamID, id)
+	if err != nil {
+		return nil, err
+	}
+	canEdit, err := UserCanEditSLO(ctx, db, existing, user)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, ErrSLOForbidden
+	}
+
+	storedLanguage, storedGood, storedTotal := existing.QueryLanguage, existing.GoodQuery, existing.TotalQuery
+	existing.Name = req.Name
+	existing.Description = req.Description
+	existing.QueryLanguage = req.QueryLanguage
+	existing.GoodQuery = req.GoodQuery
+	existing.TotalQuery = req.TotalQuery
+	existing.Target = req.Target
+	existing.WindowSeconds = req.WindowSeconds
+	if err := validateSLO(ctx, db, ds, existing); err != nil {
+		return nil, err
+	}
+	queryChanged := existing.QueryLanguage != storedLanguage || existing.GoodQuery != storedGood || existing.TotalQuery != storedTotal
+	if !allowRawSQL && queryChanged && sloRunsRawSQL(existing) {
+		return nil, ErrRawSQLDisabled
+	}
+	if err := db.UpdateSLO(ctx, existing); err != nil {
+		if models.IsNotFound(err) {
+			return nil, ErrSLONotFound
+		}
+		log.Error("failed to update slo", "slo_id", id, "error", err)
+		return nil, fmt.Errorf("failed to update slo: %w", err)
+	}
+	return GetSLO(ctx, db, log, teamID, id)
+}
+
+// DeleteSLO removes an SLO by id, with its history and burn-rate alerts.
+func DeleteSLO(ctx context.Context, db store.StoreOps, log *slog.Logger, id models.SLOID) error {
+	if err := db.DeleteSLO(ctx, id); err != nil {
+		if models.IsNotFound(err) {
+			return ErrSLONotFound
+		}
+		log.Error("failed to delete slo", "slo_id", id, "error", err)
+		return fmt.Errorf("failed to delete slo: %w", err)
+	}
+	log.Info("slo deleted", "slo_id", id)
+	return nil
+}
+
+// ListSLOHistory returns an SLO's most recent evaluations, newest first.
+func ListSLOHistory(ctx context.Context, db store.StoreOps, id models.SLOID, limit int) ([]*models.SLOEvaluation, error) {
+	if limit <= 0 {
+		limit = models.DefaultSLOHistoryLimit
+	}
+	evals, err := db.ListSLOEvaluations(ctx, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list slo history: %w", err)
+	}
+	return evals, nil
+}
+
+// UserCanEditSLO reports whether user may modify the SLO: global admins
+// always; otherwise the caller's team role must allow managing alerts, and
+// they must be the creator or a team admin/editor.
+func UserCanEditSLO(ctx context.Context, db store.StoreOps, slo *models.SLO, user *models.User) (bool, error) {
+	if slo == nil || user == nil {
+		return false, nil
+	}
+	if user.Role == models.UserRoleAdmin {
+		return true, nil
+	}
+	member, err := db.GetTeamMember(ctx, slo.TeamID, user.ID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error checking slo edit access: %w", err)
+	}
+	if member == nil || !member.Role.HasPermission(models.TeamPermissionManageAlerts) {
+		return false, nil
+	}
+	if slo.CreatedBy != nil && *slo.CreatedBy == user.ID {
+		return true, nil
+	}
+	return member.Role == models.TeamRoleAdmin || member.Role == models.TeamRoleEditor, nil
+}
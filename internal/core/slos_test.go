@@ -0,0 +1,227 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// conditionProvider is a fakeProvider that also compiles LogchefQL filters,
+// rejecting any filter equal to "bad".
+type conditionProvider struct {
+	*fakeProvider
+}
+
+func (p conditionProvider) BuildConditionAlertQuery(_ context.Context, _ *models.Source, req datasource.ConditionAlertRequest) (string, error) {
+	if req.Condition.Filter == "bad" {
+		return "", errors.New("syntax error")
+	}
+	return "SELECT count() FROM logs WHERE " + req.Condition.Filter, nil
+}
+
+func newTestSLORequest(sourceID models.SourceID) *models.CreateSLORequest {
+	return &models.CreateSLORequest{
+		SourceID:      sourceID,
+		Name:          "checkout availability",
+		QueryLanguage: models.QueryLanguageLogchefQL,
+		GoodQuery:     "status < 500",
+		TotalQuery:    `service = "checkout"`,
+		Target:        0.999,
+		WindowSeconds: 30 * 24 * 3600,
+	}
+}
+
+func TestCreateSLOValidates(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	owner := newTestUser(t, db, "slo-owner@test.dev", "Owner")
+	team, src := seedTeamWithSource(t, db, "team-a", owner)
+	orphan := newTestSource(t, db, "orphan-src")
+	ds := datasource.NewService(db, log)
+	ds.Register(conditionProvider{&fakeProvider{
+		queryLanguages: []models.QueryLanguage{models.QueryLanguageLogchefQL, models.QueryLanguageClickHouseSQL},
+		alertModes:     []models.AlertEditorMode{models.AlertEditorModeNative, models.AlertEditorModeCondition},
+	}})
+
+	cases := []struct {
+		name   string
+		mutate func(*models.CreateSLORequest)
+	}{
+		{"target of 100%", func(r *models.CreateSLORequest) { r.Target = 1 }},
+		{"target as a percentage", func(r *models.CreateSLORequest) { r.Target = 99.9 }},
+		{"window too short", func(r *models.CreateSLORequest) { r.WindowSeconds = 60 }},
+		{"missing good query", func(r *models.CreateSLORequest) { r.GoodQuery = " " }},
+		{"unknown language", func(r *models.CreateSLORequest) { r.QueryLanguage = "promql" }},
+		{"native sql without window", func(r *models.CreateSLORequest) {
+			r.QueryLanguage = models.QueryLanguageClickHouseSQL
+			r.TotalQuery = "SELECT count() FROM logs"
+		}},
+		{"unsupported native language", func(r *models.CreateSLORequest) { r.QueryLanguage = models.QueryLanguageLogsQL }},
+		{"filter that does not compile", func(r *models.CreateSLORequest) { r.GoodQuery = "bad" }},
+		{"source not linked to team", func(r *models.CreateSLORequest) { r.SourceID = orphan.ID }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := newTestSLORequest(src.ID)
+			tc.mutate(req)
+			if _, err := CreateSLO(ctx, db, ds, log, owner, team.ID, true, req); !errors.Is(err, ErrInvalidSLO) {
+				t.Fatalf("err = %v, want ErrInvalidSLO", err)
+			}
+		})
+	}
+
+	slo, err := CreateSLO(ctx, db, ds, log, owner, team.ID, true, newTestSLORequest(src.ID))
+	if err != nil {
+		t.Fatalf("CreateSLO: %v", err)
+	}
+	if slo.TeamID != team.ID || slo.CreatedBy == nil || *slo.CreatedBy != owner.ID {
+		t.Fatalf("unexpected slo: %+v", slo)
+	}
+
+	// LogchefQL SLIs need a source that can compile them.
+	plain := newFakeDatasourceService(db, log, nil)
+	if _, err := CreateSLO(ctx, db, plain, log, owner, team.ID, true, newTestSLORequest(src.ID)); !errors.Is(err, ErrInvalidSLO) {
+		t.Errorf("CreateSLO(no condition builder) err = %v, want ErrInvalidSLO", err)
+	}
+	native := newTestSLORequest(src.ID)
+	native.QueryLanguage = models.QueryLanguageClickHouseSQL
+	native.GoodQuery = "SELECT countIf(status < 500) FROM logs WHERE timestamp >= {{start}}"
+	native.TotalQuery = "SELECT count() FROM logs WHERE timestamp >= {{ start }}"
+	if _, err := CreateSLO(ctx, db, plain, log, owner, team.ID, false, native); !errors.Is(err, ErrRawSQLDisabled) {
+		t.Errorf("CreateSLO(native, raw SQL off) err = %v, want ErrRawSQLDisabled", err)
+	}
+	nativeSLO, err := CreateSLO(ctx, db, plain, log, owner, team.ID, true, native)
+	if err != nil {
+		t.Fatalf("CreateSLO(native): %v", err)
+	}
+
+	// With raw SQL off, a native SLO can still be retuned but its SLIs can't change.
+	update := &models.UpdateSLORequest{
+		Name: nativeSLO.Name, QueryLanguage: nativeSLO.QueryLanguage, GoodQuery: nativeSLO.GoodQuery, TotalQuery: nativeSLO.TotalQuery,
+		Target: 0.99, WindowSeconds: nativeSLO.WindowSeconds,
+	}
+	if _, err := UpdateSLO(ctx, db, plain, log, team.ID, nativeSLO.ID, owner, false, update); err != nil {
+		t.Errorf("UpdateSLO(target, raw SQL off): %v", err)
+	}
+	update.GoodQuery = "SELECT countIf(status < 400) FROM logs WHERE timestamp >= {{start}}"
+	if _, err := UpdateSLO(ctx, db, plain, log, team.ID, nativeSLO.ID, owner, false, update); !errors.Is(err, ErrRawSQLDisabled) {
+		t.Errorf("UpdateSLO(query, raw SQL off) err = %v, want ErrRawSQLDisabled", err)
+	}
+}
+
+func TestSLOStatusAndTeamScoping(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	owner := newTestUser(t, db, "slo-owner@test.dev", "Owner")
+	team, src := seedTeamWithSource(t, db, "team-a", owner)
+	ds := datasource.NewService(db, log)
+	ds.Register(conditionProvider{&fakeProvider{}})
+
+	slo, err := CreateSLO(ctx, db, ds, log, owner, team.ID, true, newTestSLORequest(src.ID))
+	if err != nil {
+		t.Fatalf("CreateSLO: %v", err)
+	}
+	if got, err := GetSLO(ctx, db, log, team.ID, slo.ID); err != nil || got.Status != nil {
+		t.Fatalf("GetSLO before evaluation: %v / %+v", err, got)
+	}
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := db.InsertSLOEvaluation(ctx, slo.NewEvaluation(999, 1000, at)); err != nil {
+		t.Fatalf("InsertSLOEvaluation: %v", err)
+	}
+	if err := db.InsertSLOEvaluation(ctx, slo.NewEvaluation(990, 1000, at.Add(time.Minute))); err != nil {
+		t.Fatalf("InsertSLOEvaluation: %v", err)
+	}
+	list, err := ListSLOs(ctx, db, team.ID)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ListSLOs: %v / %d", err, len(list))
+	}
+	if status := list[0].Status; status == nil || status.Good != 990 || status.BurnRate == nil {
+		t.Errorf("status = %+v, want the latest evaluation", status)
+	}
+	if history, err := ListSLOHistory(ctx, db, slo.ID, 0); err != nil || len(history) != 2 {
+		t.Errorf("ListSLOHistory: %v / %d", err, len(history))
+	}
+
+	// An SLO is only reachable through its own team.
+	other, _ := seedTeamWithSource(t, db, "team-b")
+	if _, err := GetSLO(ctx, db, log, other.ID, slo.ID); !errors.Is(err, ErrSLONotFound) {
+		t.Fatalf("GetSLO(other team) err = %v, want ErrSLONotFound", err)
+	}
+
+	viewer := newTestUser(t, db, "viewer@test.dev", "Viewer")
+	if err := AddTeamMember(ctx, db, log, team.ID, viewer.ID, models.TeamRoleViewer); err != nil {
+		t.Fatalf("AddTeamMember: %v", err)
+	}
+	update := &models.UpdateSLORequest{
+		Name: slo.Name, QueryLanguage: slo.QueryLanguage, GoodQuery: slo.GoodQuery, TotalQuery: slo.TotalQuery,
+		Target: 0.99, WindowSeconds: slo.WindowSeconds,
+	}
+	if _, err := UpdateSLO(ctx, db, ds, log, team.ID, slo.ID, viewer, true, update); !errors.Is(err, ErrSLOForbidden) {
+		t.Errorf("UpdateSLO(viewer) err = %v, want ErrSLOForbidden", err)
+	}
+	updated, err := UpdateSLO(ctx, db, ds, log, team.ID, slo.ID, owner, true, update)
+	if err != nil || updated.Target != 0.99 || updated.Status == nil {
+		t.Fatalf("UpdateSLO: %v / %+v", err, updated)
+	}
+
+	if err := DeleteSLO(ctx, db, log, slo.ID); err != nil {
+		t.Fatalf("DeleteSLO: %v", err)
+	}
+	if err := DeleteSLO(ctx, db, log, slo.ID); !errors.Is(err, ErrSLONotFound) {
+		t.Errorf("DeleteSLO(deleted) err = %v, want ErrSLONotFound", err)
+	}
+}
+
+func TestBurnRateAlertLinksToSLO(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	owner := newTestUser(t, db, "slo-owner@test.dev", "Owner")
+	team, src := seedTeamWithSource(t, db, "team-a", owner)
+	otherSrc := newTestSource(t, db, "other-src")
+	ds := datasource.NewService(db, log)
+	ds.Register(conditionProvider{&fakeProvider{
+		queryLanguages: []models.QueryLanguage{models.QueryLanguageLogchefQL, models.QueryLanguageClickHouseSQL},
+		alertModes:     []models.AlertEditorMode{models.AlertEditorModeNative, models.AlertEditorModeCondition},
+	}})
+	slo, err := CreateSLO(ctx, db, ds, log, owner, team.ID, true, newTestSLORequest(src.ID))
+	if err != nil {
+		t.Fatalf("CreateSLO: %v", err)
+	}
+
+	req := newTestCreateAlertRequest()
+	req.Query = ""
+	req.ThresholdValue = 14.4
+	req.SLOID = &slo.ID
+	if _, err := CreateAlert(ctx, db, ds, log, otherSrc.ID, owner.ID, true, req); !errors.Is(err, ErrInvalidAlertConfiguration) {
+		t.Errorf("CreateAlert(slo on another source) err = %v, want ErrInvalidAlertConfiguration", err)
+	}
+	alert, err := CreateAlert(ctx, db, ds, log, src.ID, owner.ID, true, req)
+	if err != nil {
+		t.Fatalf("CreateAlert(burn rate): %v", err)
+	}
+	if alert.SLOID == nil || *alert.SLOID != slo.ID {
+		t.Fatalf("SLOID = %v, want %d", alert.SLOID, slo.ID)
+	}
+
+	// Unlinking turns it back into a query alert, which then needs a query.
+	unlink := models.SLOID(0)
+	if _, err := UpdateAlert(ctx, db, ds, log, alert.ID, true, &models.UpdateAlertRequest{SLOID: &unlink}); !errors.Is(err, ErrInvalidAlertConfiguration) {
+		t.Errorf("UpdateAlert(unlink without query) err = %v, want ErrInvalidAlertConfiguration", err)
+	}
+	query := "SELECT count() FROM logs"
+	updated, err := UpdateAlert(ctx, db, ds, log, alert.ID, true, &models.UpdateAlertRequest{SLOID: &unlink, Query: &query})
+	if err != nil || updated.SLOID != nil {
+		t.Fatalf("UpdateAlert(unlink): %v / %+v", err, updated)
+	}
+}
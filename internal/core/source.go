@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/events"
 	"github.com/mr-karan/logchef/internal/store"
 	"github.com/mr-karan/logchef/pkg/models"
 )
@@ -45,7 +46,7 @@ func ListSources(ctx context.Context, db store.Store, ds *datasource.Service) ([
 		wg.Add(1)
 		go func(s *models.Source) {
 			defer wg.Done()
-			s.IsConnected = ds.CheckSourceConnectionStatus(ctx, s)
+			s.IsConnected = ds.SourceConnected(ctx, s)
 		}(source)
 	}
 	wg.Wait()
@@ -74,6 +75,44 @@ func UpdateSource(ctx context.Context, ds *datasource.Service, id models.SourceI
 	return source, nil
 }
 
+// UpdateSourceTTL changes the retention of a source's table, or with DryRun
+// returns the DDL that would do it.
+func UpdateSourceTTL(ctx context.Context, ds *datasource.Service, id models.SourceID, req *models.UpdateSourceTTLRequest) (*models.SourceTTLChange, error) {
+	change, err := ds.UpdateSourceTTL(ctx, id, req)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrSourceNotFound
+		}
+		return nil, normalizeDatasourceError(err)
+	}
+	return change, nil
+}
+
+// ListSourcePartitions lists the partitions of a source's table.
+func ListSourcePartitions(ctx context.Context, ds *datasource.Service, id models.SourceID) ([]models.TablePartition, error) {
+	partitions, err := ds.ListSourcePartitions(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrSourceNotFound
+		}
+		return nil, normalizeDatasourceError(err)
+	}
+	return partitions, nil
+}
+
+// DropSourcePartitions drops partitions of a source's table, or with DryRun
+// returns the DDL that would do it.
+func DropSourcePartitions(ctx context.Context, ds *datasource.Service, id models.SourceID, req *models.DropPartitionsRequest) (*models.DropPartitionsResult, error) {
+	result, err := ds.DropSourcePartitions(ctx, id, req)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrSourceNotFound
+		}
+		return nil, normalizeDatasourceError(err)
+	}
+	return result, nil
+}
+
 // DeleteSource removes a source and its provider state.
 func DeleteSource(ctx context.Context, ds *datasource.Service, id models.SourceID) error {
 	if err := ds.DeleteSource(ctx, id); err != nil {
@@ -82,6 +121,7 @@ func DeleteSource(ctx context.Context, ds *datasource.Service, id models.SourceI
 		}
 		return fmt.Errorf("error deleting source: %w", err)
 	}
+	events.Publish(models.EventSourceDeleted, models.SourceEventData{SourceID: id})
 	return nil
 }
 
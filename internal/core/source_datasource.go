@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/events"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
@@ -50,6 +51,9 @@ func CreateSourceFromRequest(ctx context.Context, ds *datasource.Service, req *m
 	if err != nil {
 		return nil, normalizeDatasourceError(err)
 	}
+	events.Publish(models.EventSourceCreated, models.SourceEventData{
+		SourceID: source.ID, Name: source.Name, SourceType: source.SourceType,
+	})
 	return source, nil
 }
 
@@ -60,3 +64,31 @@ func ValidateSourceConnection(ctx context.Context, ds *datasource.Service, req *
 	}
 	return result, nil
 }
+
+// DiscoverDatabases lists the databases behind the request's connection.
+func DiscoverDatabases(ctx context.Context, ds *datasource.Service, req *models.DiscoveryRequest) ([]string, error) {
+	databases, err := ds.DiscoverDatabases(ctx, req)
+	if err != nil {
+		return nil, normalizeDatasourceError(err)
+	}
+	return databases, nil
+}
+
+// DiscoverTables lists the tables in the request's connection database.
+func DiscoverTables(ctx context.Context, ds *datasource.Service, req *models.DiscoveryRequest) ([]models.DiscoveredTable, error) {
+	tables, err := ds.DiscoverTables(ctx, req)
+	if err != nil {
+		return nil, normalizeDatasourceError(err)
+	}
+	return tables, nil
+}
+
+// PreviewSourceTable samples the request's connection table and suggests its
+// timestamp and severity columns.
+func PreviewSourceTable(ctx context.Context, ds *datasource.Service, req *models.DiscoveryRequest) (*models.TablePreview, error) {
+	preview, err := ds.PreviewTable(ctx, req)
+	if err != nil {
+		return nil, normalizeDatasourceError(err)
+	}
+	return preview, nil
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/events"
 	"github.com/mr-karan/logchef/internal/store"
 	"github.com/mr-karan/logchef/pkg/models"
 )
@@ -63,8 +64,8 @@ func validateTeamMember(teamID models.TeamID, userID models.UserID, role models.
 	if userID <= 0 {
 		return &ValidationError{Field: "userID", Message: "valid user ID is required"}
 	}
-	if role != models.TeamRoleAdmin && role != models.TeamRoleMember && role != models.TeamRoleEditor {
-		return &ValidationError{Field: "role", Message: "role must be 'admin', 'editor', or 'member'"}
+	if !role.Valid() {
+		return &ValidationError{Field: "role", Message: "role must be 'admin', 'editor', 'member', or 'viewer'"}
 	}
 	return nil
 }
@@ -185,6 +186,27 @@ func UpdateTeam(ctx context.Context, db store.StoreOps, log *slog.Logger, teamID
 	return nil
 }
 
+// SetTeamRawSQL turns raw SQL on or off for a team's members.
+func SetTeamRawSQL(ctx context.Context, db store.StoreOps, log *slog.Logger, teamID models.TeamID, enabled bool) error {
+	existing, err := db.GetTeam(ctx, teamID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return ErrTeamNotFound
+		}
+		return fmt.Errorf("error getting team: %w", err)
+	}
+	if existing.RawSQLEnabled == enabled {
+		return nil
+	}
+	existing.RawSQLEnabled = enabled
+	if err := db.UpdateTeam(ctx, existing); err != nil {
+		log.Error("failed to update team raw sql setting", "error", err, "team_id", teamID)
+		return fmt.Errorf("error updating team: %w", err)
+	}
+	log.Info("team raw sql setting changed", "team_id", teamID, "enabled", enabled)
+	return nil
+}
+
 // DeleteTeam deletes a team and its associations (members, sources, queries).
 func DeleteTeam(ctx context.Context, db store.StoreOps, log *slog.Logger, teamID models.TeamID) error {
 	// Validate team exists
@@ -269,6 +291,7 @@ func AddTeamMember(ctx context.Context, db store.StoreOps, log *slog.Logger, tea
 		log.Error("failed to add team member to db", "error", err, "team_id", teamID, "user_id", userID)
 		return fmt.Errorf("error adding team member: %w", err)
 	}
+	events.Publish(models.EventTeamMemberAdded, models.TeamMemberEventData{TeamID: teamID, UserID: userID, Role: role})
 
 	return nil
 }
@@ -298,6 +321,7 @@ func RemoveTeamMember(ctx context.Context, db store.StoreOps, log *slog.Logger,
 		log.Error("failed to remove team member from db", "error", err, "team_id", teamID, "user_id", userID)
 		return fmt.Errorf("error removing team member: %w", err)
 	}
+	events.Publish(models.EventTeamMemberRemoved, models.TeamMemberEventData{TeamID: teamID, UserID: userID})
 
 	return nil
 }
@@ -338,7 +362,7 @@ func ListTeamSources(ctx context.Context, db store.StoreOps, ds *datasource.Serv
 		if err := ds.ApplySourceMetadata(source); err != nil {
 			return nil, fmt.Errorf("error annotating source features: %w", err)
 		}
-		source.IsConnected = ds.CheckSourceConnectionStatus(ctx, source)
+		source.IsConnected = ds.SourceConnected(ctx, source)
 
 		// Optionally log if status is unhealthy for debugging
 		if !source.IsConnected {
@@ -464,7 +488,7 @@ func IsTeamCollectionMutator(ctx context.Context, db store.StoreOps, teamID mode
 	if member == nil {
 		return false, nil
 	}
-	return member.Role == models.TeamRoleAdmin || member.Role == models.TeamRoleEditor, nil
+	return member.Role.HasPermission(models.TeamPermissionManageCollections), nil
 }
 
 // IsAnyTeamCollectionMutator reports whether the user is an admin or editor
@@ -476,7 +500,73 @@ func IsAnyTeamCollectionMutator(ctx context.Context, db store.StoreOps, userID m
 		return false, fmt.Errorf("error listing teams for user: %w", err)
 	}
 	for _, team := range teams {
-		if team.Role == models.TeamRoleAdmin || team.Role == models.TeamRoleEditor {
+		if team.Role.HasPermission(models.TeamPermissionManageCollections) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// UserHasTeamPermission reports whether the user's role in a team grants the
+// permission. Non-members hold no permissions.
+func UserHasTeamPermission(ctx context.Context, db store.StoreOps, teamID models.TeamID, userID models.UserID, perm models.TeamPermission) (bool, error) {
+	member, err := db.GetTeamMember(ctx, teamID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, models.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error checking team permission: %w", err)
+	}
+	return member != nil && member.Role.HasPermission(perm), nil
+}
+
+// UserHasSourcePermission reports whether the user holds the permission on a
+// source through any team that has access to it. A user in several such teams
+// gets the union of their roles' permissions. Global admins hold every
+// permission; callers still enforce source visibility separately.
+func UserHasSourcePermission(ctx context.Context, db store.StoreOps, user *models.User, sourceID models.SourceID, perm models.TeamPermission) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+	if user.Role == models.UserRoleAdmin {
+		return true, nil
+	}
+	roles, err := db.ListUserSourceRoles(ctx, user.ID, sourceID)
+	if err != nil {
+		return false, fmt.Errorf("error checking source permission: %w", err)
+	}
+	for _, role := range roles {
+		if role.HasPermission(perm) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// UserCanRunRawSQLOnSource reports whether the user may run raw SQL against
+// a source, outside any one team's context (alerts belong to a source, not a
+// team). It holds when some team of the user's that has the source keeps its
+// raw SQL switch on. Global admins always may.
+func UserCanRunRawSQLOnSource(ctx context.Context, db store.StoreOps, user *models.User, sourceID models.SourceID) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+	if user.Role == models.UserRoleAdmin {
+		return true, nil
+	}
+	teams, err := db.ListUserTeams(ctx, user.ID)
+	if err != nil {
+		return false, fmt.Errorf("error listing user teams: %w", err)
+	}
+	for _, team := range teams {
+		if !team.RawSQLEnabled {
+			continue
+		}
+		hasSource, err := db.TeamHasSource(ctx, team.ID, sourceID)
+		if err != nil {
+			return false, fmt.Errorf("error checking team source access: %w", err)
+		}
+		if hasSource {
 			return true, nil
 		}
 	}
@@ -420,3 +420,139 @@ func TestListTeamsWithAccessToSourceIntersection(t *testing.T) {
 		t.Errorf("ListTeamsWithAccessToSource = %+v, want exactly [team %d]", accessible, linkedAndMember)
 	}
 }
+
+// TestUserHasSourcePermission pins the role matrix on a source: viewers can
+// only query, members and editors can author saved queries and alerts, and a
+// user in several teams gets the union of their roles' permissions.
+func TestUserHasSourcePermission(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	viewer := newTestUser(t, db, "viewer@example.com", "Viewer")
+	member := newTestUser(t, db, "member@example.com", "Member")
+	stranger := newTestUser(t, db, "stranger@example.com", "Stranger")
+	team, src := seedTeamWithSource(t, db, "perm-team", member)
+	if err := AddTeamMember(ctx, db, log, team.ID, viewer.ID, models.TeamRoleViewer); err != nil {
+		t.Fatalf("AddTeamMember(viewer): %v", err)
+	}
+
+	cases := []struct {
+		name string
+		user *models.User
+		perm models.TeamPermission
+		want bool
+	}{
+		{"viewer can query", viewer, models.TeamPermissionQueryLogs, true},
+		{"viewer cannot manage saved queries", viewer, models.TeamPermissionManageSavedQueries, false},
+		{"viewer cannot manage alerts", viewer, models.TeamPermissionManageAlerts, false},
+		{"member can manage saved queries", member, models.TeamPermissionManageSavedQueries, true},
+		{"member can manage alerts", member, models.TeamPermissionManageAlerts, true},
+		{"member cannot manage sources", member, models.TeamPermissionManageSources, false},
+		{"stranger has no permissions", stranger, models.TeamPermissionQueryLogs, false},
+		{"global admin has every permission", &models.User{ID: stranger.ID, Role: models.UserRoleAdmin}, models.TeamPermissionManageAlerts, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := UserHasSourcePermission(ctx, db, tc.user, src.ID, tc.perm)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("UserHasSourcePermission(user=%d, perm=%s) = %v, want %v", tc.user.ID, tc.perm, got, tc.want)
+			}
+		})
+	}
+
+	// A viewer who is also an editor in another team linked to the same
+	// source can author there.
+	other, err := CreateTeam(ctx, db, log, "perm-team-2", "")
+	if err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+	if err := AddTeamSource(ctx, db, log, other.ID, src.ID); err != nil {
+		t.Fatalf("AddTeamSource: %v", err)
+	}
+	if err := AddTeamMember(ctx, db, log, other.ID, viewer.ID, models.TeamRoleEditor); err != nil {
+		t.Fatalf("AddTeamMember(editor): %v", err)
+	}
+	got, err := UserHasSourcePermission(ctx, db, viewer, src.ID, models.TeamPermissionManageAlerts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got {
+		t.Error("editor role in a second team should grant alert management on the shared source")
+	}
+}
+
+// TestUserHasTeamPermission checks member/source management stays admin-only.
+func TestUserHasTeamPermission(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	adminTeam, adminID := seedTeamWithMember(t, db, "admin-team", "admin@example.com", models.TeamRoleAdmin)
+	editorTeam, editorID := seedTeamWithMember(t, db, "editor-team", "editor@example.com", models.TeamRoleEditor)
+	viewerTeam, viewerID := seedTeamWithMember(t, db, "viewer-team", "viewer@example.com", models.TeamRoleViewer)
+
+	cases := []struct {
+		name   string
+		teamID models.TeamID
+		userID models.UserID
+		perm   models.TeamPermission
+		want   bool
+	}{
+		{"admin manages members", adminTeam, adminID, models.TeamPermissionManageMembers, true},
+		{"admin manages sources", adminTeam, adminID, models.TeamPermissionManageSources, true},
+		{"editor cannot manage members", editorTeam, editorID, models.TeamPermissionManageMembers, false},
+		{"editor manages collections", editorTeam, editorID, models.TeamPermissionManageCollections, true},
+		{"editor ingests logs", editorTeam, editorID, models.TeamPermissionIngestLogs, true},
+		{"viewer cannot ingest logs", viewerTeam, viewerID, models.TeamPermissionIngestLogs, false},
+		{"viewer queries", viewerTeam, viewerID, models.TeamPermissionQueryLogs, true},
+		{"viewer cannot manage collections", viewerTeam, viewerID, models.TeamPermissionManageCollections, false},
+		{"non-member has nothing", adminTeam, viewerID, models.TeamPermissionQueryLogs, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := UserHasTeamPermission(ctx, db, tc.teamID, tc.userID, tc.perm)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("UserHasTeamPermission(team=%d, user=%d, perm=%s) = %v, want %v", tc.teamID, tc.userID, tc.perm, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUserCanRunRawSQLOnSource(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+
+	member := newTestUser(t, db, "raw-sql-member@example.com", "Member")
+	outsider := newTestUser(t, db, "raw-sql-outsider@example.com", "Outsider")
+	admin := newTestAdmin(t, db, "raw-sql-admin@example.com")
+	team, src := seedTeamWithSource(t, db, "raw-sql-team", member)
+
+	check := func(user *models.User, want bool) {
+		t.Helper()
+		got, err := UserCanRunRawSQLOnSource(ctx, db, user, src.ID)
+		if err != nil {
+			t.Fatalf("UserCanRunRawSQLOnSource(%s): %v", user.Email, err)
+		}
+		if got != want {
+			t.Errorf("UserCanRunRawSQLOnSource(%s) = %v, want %v", user.Email, got, want)
+		}
+	}
+	check(member, true)
+	check(outsider, false)
+
+	if err := SetTeamRawSQL(ctx, db, log, team.ID, false); err != nil {
+		t.Fatalf("SetTeamRawSQL: %v", err)
+	}
+	check(member, false)
+	check(admin, true)
+}
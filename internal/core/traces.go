@@ -0,0 +1,67 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// traceIDRe accepts the hex IDs of W3C/OpenTelemetry and Jaeger as well as
+// other opaque IDs, but nothing that could break out of a LogchefQL string.
+var traceIDRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// TraceLink is where a trace can be opened in a source's tracing backend.
+type TraceLink struct {
+	SourceID    models.SourceID `json:"source_id"`
+	SourceName  string          `json:"source_name"`
+	BackendName string          `json:"backend_name,omitempty"`
+	URL         string          `json:"url"`
+}
+
+// ValidateTraceID checks that a trace ID can be looked up.
+func ValidateTraceID(traceID string) error {
+	if !traceIDRe.MatchString(traceID) {
+		return &ValidationError{Field: "trace_id", Message: "trace ID must be 1-128 letters, digits, '-' or '_'"}
+	}
+	return nil
+}
+
+// TraceSources returns the sources with a trace correlation, at most
+// MaxFederatedSources of them, and how many more were left out.
+func TraceSources(sources []*models.Source) (traced []*models.Source, omitted int) {
+	for _, source := range sources {
+		if source == nil || source.TraceCorrelation == nil {
+			continue
+		}
+		if len(traced) == MaxFederatedSources {
+			omitted++
+			continue
+		}
+		traced = append(traced, source)
+	}
+	return traced, omitted
+}
+
+// TraceLogchefQL returns the LogchefQL filter for a trace's rows in a source.
+// The trace ID must have passed ValidateTraceID.
+func TraceLogchefQL(source *models.Source, traceID string) string {
+	return fmt.Sprintf(`%s = "%s"`, source.TraceCorrelation.TraceIDField, traceID)
+}
+
+// TraceLinks returns a link per source whose trace correlation has a URL
+// template.
+func TraceLinks(sources []*models.Source, traceID string) []TraceLink {
+	links := []TraceLink{}
+	for _, source := range sources {
+		if url := source.TraceCorrelation.TraceURL(traceID, ""); url != "" {
+			links = append(links, TraceLink{
+				SourceID:    source.ID,
+				SourceName:  source.Name,
+				BackendName: source.TraceCorrelation.BackendName,
+				URL:         url,
+			})
+		}
+	}
+	return links
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestValidateTraceID(t *testing.T) {
+	for _, id := range []string{"4bf92f3577b34da6a3ce929d0e0e4736", "abc-123_x"} {
+		if err := ValidateTraceID(id); err != nil {
+			t.Errorf("ValidateTraceID(%q) = %v, want nil", id, err)
+		}
+	}
+	for _, id := range []string{"", `abc" OR 1=1`, "a b"} {
+		if err := ValidateTraceID(id); err == nil {
+			t.Errorf("ValidateTraceID(%q) = nil, want an error", id)
+		}
+	}
+}
+
+func TestTraceSourcesAndLinks(t *testing.T) {
+	var sources []*models.Source
+	for i := range MaxFederatedSources + 3 {
+		source := &models.Source{ID: models.SourceID(i + 1), Name: "src"}
+		if i > 0 {
+			source.TraceCorrelation = &models.TraceCorrelation{TraceIDField: "trace_id"}
+		}
+		sources = append(sources, source)
+	}
+	sources[1].TraceCorrelation.URLTemplate = "https://jaeger.example.com/trace/{trace_id}"
+	sources[1].TraceCorrelation.BackendName = "Jaeger"
+
+	traced, omitted := TraceSources(sources)
+	if len(traced) != MaxFederatedSources || omitted != 2 || traced[0].ID != 2 {
+		t.Fatalf("TraceSources() = %d sources starting at %d, %d omitted; want %d from 2, 2 omitted",
+			len(traced), traced[0].ID, omitted, MaxFederatedSources)
+	}
+	if got, want := TraceLogchefQL(traced[0], "abc"), `trace_id = "abc"`; got != want {
+		t.Errorf("TraceLogchefQL() = %q, want %q", got, want)
+	}
+
+	links := TraceLinks(traced, "abc")
+	if len(links) != 1 || links[0].URL != "https://jaeger.example.com/trace/abc" || links[0].BackendName != "Jaeger" {
+		t.Errorf("TraceLinks() = %+v, want one Jaeger link", links)
+	}
+}
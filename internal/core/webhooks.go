@@ -0,0 +1,128 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+var (
+	// ErrWebhookNotFound is returned when a webhook cannot be located.
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrInvalidWebhook indicates the webhook request failed validation.
+	ErrInvalidWebhook = errors.New("invalid webhook")
+)
+
+// ListWebhooks returns every registered webhook.
+func ListWebhooks(ctx context.Context, db store.StoreOps) ([]*models.Webhook, error) {
+	webhooks, err := db.ListWebhooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// GetWebhook returns a webhook by id.
+func GetWebhook(ctx context.Context, db store.StoreOps, id models.WebhookID) (*models.Webhook, error) {
+	webhook, err := db.GetWebhook(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("error getting webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// CreateWebhook validates and stores a webhook registered by user. A secret is
+// generated when the request has none, and the webhook is active by default.
+func CreateWebhook(ctx context.Context, db store.StoreOps, log *slog.Logger, user *models.User, req *models.CreateWebhookRequest) (*models.Webhook, error) {
+	webhook := &models.Webhook{
+		Name:       req.Name,
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+		IsActive:   req.IsActive == nil || *req.IsActive,
+		CreatedBy:  &user.ID,
+	}
+	if webhook.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		webhook.Secret = secret
+	}
+	if err := webhook.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+
+	if err := db.CreateWebhook(ctx, webhook); err != nil {
+		log.Error("failed to create webhook", "error", err)
+		return nil, fmt.Errorf("error creating webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// UpdateWebhook replaces a webhook's settings, generating a new secret when
+// req.RotateSecret is set. rotated reports whether the secret changed.
+func UpdateWebhook(ctx context.Context, db store.StoreOps, log *slog.Logger, id models.WebhookID, req *models.UpdateWebhookRequest) (webhook *models.Webhook, rotated bool, err error) {
+	webhook, err = GetWebhook(ctx, db, id)
+	if err != nil {
+		return nil, false, err
+	}
+	webhook.Name = req.Name
+	webhook.URL = req.URL
+	webhook.EventTypes = req.EventTypes
+	if req.IsActive != nil {
+		webhook.IsActive = *req.IsActive
+	}
+	if req.RotateSecret {
+		if webhook.Secret, err = generateWebhookSecret(); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := webhook.Validate(); err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+
+	if err := db.UpdateWebhook(ctx, webhook); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, false, ErrWebhookNotFound
+		}
+		log.Error("failed to update webhook", "webhook_id", id, "error", err)
+		return nil, false, fmt.Errorf("error updating webhook: %w", err)
+	}
+	webhook, err = GetWebhook(ctx, db, id)
+	return webhook, req.RotateSecret, err
+}
+
+// DeleteWebhook removes a webhook and returns what was deleted.
+func DeleteWebhook(ctx context.Context, db store.StoreOps, log *slog.Logger, id models.WebhookID) (*models.Webhook, error) {
+	webhook, err := GetWebhook(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.DeleteWebhook(ctx, id); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		log.Error("failed to delete webhook", "webhook_id", id, "error", err)
+		return nil, fmt.Errorf("error deleting webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// generateWebhookSecret returns 32 random bytes as hex.
+func generateWebhookSecret() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestWebhookLifecycle(t *testing.T) {
+	db := newTestDB(t)
+	log := discardLogger()
+	ctx := context.Background()
+	admin := newTestUser(t, db, "admin@test.dev", "Admin")
+
+	for name, req := range map[string]models.CreateWebhookRequest{
+		"no name":      {URL: "https://example.com/hook", EventTypes: []models.EventType{models.EventAll}},
+		"bad url":      {Name: "ops", URL: "ftp://example.com", EventTypes: []models.EventType{models.EventAll}},
+		"no events":    {Name: "ops", URL: "https://example.com/hook"},
+		"unknown type": {Name: "ops", URL: "https://example.com/hook", EventTypes: []models.EventType{"source.renamed"}},
+		"short secret": {Name: "ops", URL: "https://example.com/hook", EventTypes: []models.EventType{models.EventAll}, Secret: "short"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := CreateWebhook(ctx, db, log, admin, &req); !errors.Is(err, ErrInvalidWebhook) {
+				t.Fatalf("err = %v, want ErrInvalidWebhook", err)
+			}
+		})
+	}
+
+	webhook, err := CreateWebhook(ctx, db, log, admin, &models.CreateWebhookRequest{
+		Name:       " ops ",
+		URL:        "https://example.com/hook",
+		EventTypes: []models.EventType{models.EventSourceCreated, models.EventSourceCreated, models.EventAlertFired},
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	if webhook.Name != "ops" || !webhook.IsActive || len(webhook.Secret) != 64 || len(webhook.EventTypes) != 2 {
+		t.Fatalf("unexpected webhook: %+v", webhook)
+	}
+
+	inactive := false
+	updated, rotated, err := UpdateWebhook(ctx, db, log, webhook.ID, &models.UpdateWebhookRequest{
+		Name: "ops", URL: "https://example.com/v2", EventTypes: []models.EventType{models.EventAll}, IsActive: &inactive,
+	})
+	if err != nil {
+		t.Fatalf("UpdateWebhook: %v", err)
+	}
+	if rotated || updated.Secret != webhook.Secret || updated.IsActive || updated.URL != "https://example.com/v2" {
+		t.Fatalf("unexpected update: rotated=%v %+v", rotated, updated)
+	}
+
+	updated, rotated, err = UpdateWebhook(ctx, db, log, webhook.ID, &models.UpdateWebhookRequest{
+		Name: "ops", URL: "https://example.com/v2", EventTypes: []models.EventType{models.EventAll}, RotateSecret: true,
+	})
+	if err != nil {
+		t.Fatalf("UpdateWebhook(rotate): %v", err)
+	}
+	if !rotated || updated.Secret == webhook.Secret || updated.IsActive {
+		t.Fatalf("secret not rotated or is_active changed: %+v", updated)
+	}
+
+	if _, err := DeleteWebhook(ctx, db, log, webhook.ID); err != nil {
+		t.Fatalf("DeleteWebhook: %v", err)
+	}
+	if _, err := GetWebhook(ctx, db, webhook.ID); !errors.Is(err, ErrWebhookNotFound) {
+		t.Fatalf("GetWebhook after delete: err = %v, want ErrWebhookNotFound", err)
+	}
+}
@@ -0,0 +1,170 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mr-karan/logchef/internal/clickhouse"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+const (
+	// DefaultPreviewRows is how many rows a table preview samples when the
+	// request doesn't say.
+	DefaultPreviewRows = 10
+	// MaxPreviewRows caps the rows sampled by one table preview.
+	MaxPreviewRows = 100
+)
+
+// timestampColumnNames and severityColumnNames are the usual names of a log
+// table's timestamp and severity columns, most likely first.
+var (
+	timestampColumnNames = []string{"timestamp", "@timestamp", "ts", "time", "_time", "event_time", "log_time", "datetime", "date_time", "created_at"}
+	severityColumnNames  = []string{"severity_text", "severity", "level", "log_level", "loglevel", "severity_level", "levelname", "lvl"}
+)
+
+// severityValues are the level words a severity column holds, lowercased.
+var severityValues = []string{
+	"trace", "debug", "info", "information", "notice", "warn", "warning",
+	"error", "err", "fatal", "critical", "crit", "alert", "panic", "emerg", "emergency",
+}
+
+func (p *ClickHouseProvider) DiscoverDatabases(ctx context.Context, req *models.DiscoveryRequest) ([]string, error) {
+	client, _, err := p.discoveryClient(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	return client.ListDatabases(ctx)
+}
+
+func (p *ClickHouseProvider) DiscoverTables(ctx context.Context, req *models.DiscoveryRequest) ([]models.DiscoveredTable, error) {
+	client, conn, err := p.discoveryClient(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	return client.ListTables(ctx, conn.Database)
+}
+
+func (p *ClickHouseProvider) PreviewTable(ctx context.Context, req *models.DiscoveryRequest) (*models.TablePreview, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = DefaultPreviewRows
+	}
+	if limit < 1 || limit > MaxPreviewRows {
+		return nil, &ValidationError{Field: "limit", Message: fmt.Sprintf("limit must be between 1 and %d", MaxPreviewRows)}
+	}
+
+	client, conn, err := p.discoveryClient(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	sample, err := client.SampleTable(ctx, conn.Database, conn.TableName, limit)
+	if err != nil {
+		return nil, &ValidationError{Field: "table_name", Message: fmt.Sprintf("Connection successful, but table '%s.%s' could not be read", conn.Database, conn.TableName), Err: err}
+	}
+	return &models.TablePreview{
+		Columns:             sample.Columns,
+		Rows:                sample.Rows,
+		TimestampCandidates: timestampCandidates(sample.Columns),
+		SeverityCandidates:  severityCandidates(sample.Columns, sample.Rows),
+	}, nil
+}
+
+// discoveryClient validates the request's connection and opens a temporary
+// client for it. The database defaults to "default" so databases can be
+// listed before one is chosen.
+func (p *ClickHouseProvider) discoveryClient(ctx context.Context, req *models.DiscoveryRequest, requireTable bool) (*clickhouse.Client, models.ConnectionInfo, error) {
+	conn, err := p.connectionFromConfig(req.Connection)
+	if err != nil {
+		return nil, conn, err
+	}
+	if strings.TrimSpace(conn.Database) == "" {
+		conn.Database = "default"
+	}
+	if err := validateClickHouseConnection("", requireTable, conn.Host, conn.Database, conn.TableName); err != nil {
+		return nil, conn, err
+	}
+	if err := validateClickHouseTransport("", conn); err != nil {
+		return nil, conn, err
+	}
+
+	tempSource := &models.Source{SourceType: models.SourceTypeClickHouse, Connection: conn}
+	client, err := p.manager.CreateTemporaryClient(ctx, tempSource)
+	if err != nil {
+		return nil, conn, &ValidationError{Field: "connection", Message: "Failed to connect to the database", Err: err}
+	}
+	return client, conn, nil
+}
+
+// timestampCandidates returns the DateTime columns, those with a usual
+// timestamp name first.
+func timestampCandidates(columns []models.ColumnInfo) []string {
+	var eligible []string
+	for _, col := range columns {
+		if strings.HasPrefix(col.Type, "DateTime") {
+			eligible = append(eligible, col.Name)
+		}
+	}
+	return rankByName(eligible, timestampColumnNames)
+}
+
+// severityCandidates returns the String columns named like a severity field,
+// followed by any other String column whose sampled values are all level
+// words.
+func severityCandidates(columns []models.ColumnInfo, rows []map[string]any) []string {
+	var named, others []string
+	for _, col := range columns {
+		if col.Type != "String" && !strings.Contains(col.Type, "LowCardinality(String)") {
+			continue
+		}
+		if slices.Contains(severityColumnNames, strings.ToLower(col.Name)) {
+			named = append(named, col.Name)
+		} else if holdsSeverityValues(col.Name, rows) {
+			others = append(others, col.Name)
+		}
+	}
+	return append(rankByName(named, severityColumnNames), others...)
+}
+
+// holdsSeverityValues reports whether the rows hold at least one non-empty
+// value for column and every such value is a level word.
+func holdsSeverityValues(column string, rows []map[string]any) bool {
+	seen := false
+	for _, row := range rows {
+		value, _ := row[column].(string)
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		if !slices.Contains(severityValues, value) {
+			return false
+		}
+		seen = true
+	}
+	return seen
+}
+
+// rankByName orders names found in preferred by their position there, then
+// the rest in their original order. Matching ignores case.
+func rankByName(names, preferred []string) []string {
+	ranked := make([]string, 0, len(names))
+	for _, want := range preferred {
+		for _, name := range names {
+			if strings.EqualFold(name, want) {
+				ranked = append(ranked, name)
+			}
+		}
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(preferred, func(want string) bool { return strings.EqualFold(name, want) }) {
+			ranked = append(ranked, name)
+		}
+	}
+	return ranked
+}
@@ -0,0 +1,57 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestTimestampCandidates(t *testing.T) {
+	columns := []models.ColumnInfo{
+		{Name: "inserted_at", Type: "DateTime"},
+		{Name: "msg", Type: "String"},
+		{Name: "Timestamp", Type: "DateTime64(9)"},
+		{Name: "event_time", Type: "DateTime64(3, 'UTC')"},
+		{Name: "time", Type: "String"},
+	}
+	got := timestampCandidates(columns)
+	want := []string{"Timestamp", "event_time", "inserted_at"}
+	if !slices.Equal(got, want) {
+		t.Errorf("timestampCandidates() = %v, want %v", got, want)
+	}
+}
+
+func TestSeverityCandidates(t *testing.T) {
+	columns := []models.ColumnInfo{
+		{Name: "msg", Type: "String"},
+		{Name: "lvl", Type: "LowCardinality(String)"},
+		{Name: "status", Type: "String"},
+		{Name: "severity_text", Type: "LowCardinality(String)"},
+		{Name: "severity_number", Type: "UInt8"},
+		{Name: "stage", Type: "String"},
+	}
+	rows := []map[string]any{
+		{"msg": "started", "status": "WARN", "stage": ""},
+		{"msg": "error", "status": "info", "stage": ""},
+	}
+	got := severityCandidates(columns, rows)
+	// stage holds no values, so it isn't suggested from its values.
+	want := []string{"severity_text", "lvl", "status"}
+	if !slices.Equal(got, want) {
+		t.Errorf("severityCandidates() = %v, want %v", got, want)
+	}
+}
+
+func TestPreviewTableValidatesLimit(t *testing.T) {
+	p := &ClickHouseProvider{}
+	for _, limit := range []int{-1, MaxPreviewRows + 1} {
+		_, err := p.PreviewTable(context.Background(), &models.DiscoveryRequest{Limit: limit})
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Field != "limit" {
+			t.Errorf("PreviewTable(limit=%d) err = %v, want a limit validation error", limit, err)
+		}
+	}
+}
@@ -5,13 +5,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/mr-karan/logchef/internal/clickhouse"
 	"github.com/mr-karan/logchef/internal/logchefql"
+	"github.com/mr-karan/logchef/internal/timezone"
+	"github.com/mr-karan/logchef/internal/tracing"
 	"github.com/mr-karan/logchef/pkg/models"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ClickHouseProvider struct {
@@ -40,6 +46,11 @@ func (p *ClickHouseProvider) Capabilities() []Capability {
 		CapabilityLogContext,
 		CapabilityExports,
 		CapabilityLiveTail,
+		CapabilityFieldStats,
+		CapabilityJSONFields,
+		CapabilityLogPatterns,
+		CapabilityVolumeAnomalies,
+		CapabilityRangeComparison,
 	}
 }
 
@@ -80,6 +91,9 @@ func (p *ClickHouseProvider) PrepareSource(ctx context.Context, req *models.Crea
 	if err := validateClickHouseConnection("connection.", true, conn.Host, conn.Database, conn.TableName); err != nil {
 		return nil, err
 	}
+	if err := validateClickHouseTransport("connection.", conn); err != nil {
+		return nil, err
+	}
 
 	metaTSField := strings.TrimSpace(req.MetaTSField)
 	if metaTSField == "" {
@@ -134,7 +148,14 @@ func (p *ClickHouseProvider) PrepareSource(ctx context.Context, req *models.Crea
 			}
 		}
 
+		caps := client.ProbeCapabilities(ctx, "", "")
+		if err := clickhouse.RequireDDL(&caps); err != nil {
+			return nil, readOnlyAutoCreateError(err)
+		}
 		if _, err := client.Query(ctx, schemaToExecute); err != nil {
+			if clickhouse.IsReadOnlyError(err) {
+				return nil, readOnlyAutoCreateError(err)
+			}
 			return nil, &ValidationError{Field: "connection.table_name", Message: "Failed to create table in ClickHouse", Err: err}
 		}
 	} else {
@@ -161,6 +182,9 @@ func (p *ClickHouseProvider) ValidateConnection(ctx context.Context, req *models
 	if err := validateClickHouseConnection("", false, conn.Host, conn.Database, conn.TableName); err != nil {
 		return nil, err
 	}
+	if err := validateClickHouseTransport("", conn); err != nil {
+		return nil, err
+	}
 
 	tempSource := &models.Source{SourceType: models.SourceTypeClickHouse, Connection: conn}
 	client, err := p.manager.CreateTemporaryClient(ctx, tempSource)
@@ -185,7 +209,7 @@ func (p *ClickHouseProvider) ValidateConnection(ctx context.Context, req *models
 		if err := p.validateColumnTypes(ctx, client, conn.Database, conn.TableName, strings.TrimSpace(req.TimestampField), strings.TrimSpace(req.SeverityField)); err != nil {
 			return nil, err
 		}
-		return &models.ConnectionValidationResult{Message: "Connection and column types validated successfully"}, nil
+		return connectionValidationResult(ctx, client, conn, "Connection and column types validated successfully"), nil
 	}
 
 	if strings.TrimSpace(conn.TableName) != "" {
@@ -194,7 +218,26 @@ func (p *ClickHouseProvider) ValidateConnection(ctx context.Context, req *models
 		}
 	}
 
-	return &models.ConnectionValidationResult{Message: "Connection successful"}, nil
+	return connectionValidationResult(ctx, client, conn, "Connection successful"), nil
+}
+
+// connectionValidationResult reports a successful validation along with the
+// connection's capabilities, noting up front when it is read-only.
+func connectionValidationResult(ctx context.Context, client *clickhouse.Client, conn models.ConnectionInfo, message string) *models.ConnectionValidationResult {
+	caps := client.ProbeCapabilities(ctx, conn.Database, conn.TableName)
+	if !caps.CanDDL {
+		message = fmt.Sprintf("%s (read-only: %s); table auto-creation and rollups won't be available", message, caps.ReadOnlyReason)
+	}
+	return &models.ConnectionValidationResult{Message: message, Capabilities: &caps}
+}
+
+// readOnlyAutoCreateError explains why a source can't auto-create its table.
+func readOnlyAutoCreateError(err error) error {
+	return &ValidationError{
+		Field:   "connection",
+		Message: "Cannot create the table: the ClickHouse connection is read-only. Create the table on a writable node, then add the source without auto-create",
+		Err:     err,
+	}
 }
 
 func (p *ClickHouseProvider) UpdateSource(ctx context.Context, source *models.Source, req *models.UpdateSourceRequest) (*SourceUpdateResult, error) {
@@ -205,11 +248,13 @@ func (p *ClickHouseProvider) UpdateSource(ctx context.Context, source *models.So
 		return nil, fmt.Errorf("update source request is required")
 	}
 
+	previousTTL := source.TTLDays
 	changed, err := ApplyCommonSourceUpdates(source, req)
 	if err != nil {
 		return nil, err
 	}
 
+	ttlChanged := source.TTLDays != previousTTL
 	metaChanged := req.MetaTSField != nil || req.MetaSeverityField != nil
 	connectionChanged := req.HasConnectionChanges()
 	var client *clickhouse.Client
@@ -225,9 +270,17 @@ func (p *ClickHouseProvider) UpdateSource(ctx context.Context, source *models.So
 		if conn.Password == "" && conn.Username != "" {
 			conn.Password = source.Connection.Password
 		}
+		// The client key is withheld the same way; it is kept while the
+		// client certificate is.
+		if conn.TLSClientKey == "" && conn.TLSClientCert != "" && conn.TLSClientCert == source.Connection.TLSClientCert {
+			conn.TLSClientKey = source.Connection.TLSClientKey
+		}
 		if err := validateClickHouseConnection("connection.", true, conn.Host, conn.Database, conn.TableName); err != nil {
 			return nil, err
 		}
+		if err := validateClickHouseTransport("connection.", conn); err != nil {
+			return nil, err
+		}
 
 		tempSource := &models.Source{SourceType: models.SourceTypeClickHouse, Connection: conn}
 		client, err = p.manager.CreateTemporaryClient(ctx, tempSource)
@@ -262,6 +315,19 @@ func (p *ClickHouseProvider) UpdateSource(ctx context.Context, source *models.So
 		}
 	}
 
+	if ttlChanged {
+		if client == nil {
+			existingClient, err := p.manager.GetConnection(source.ID)
+			if err != nil {
+				return nil, fmt.Errorf("get connection for source %d: %w", source.ID, err)
+			}
+			client = existingClient
+		}
+		if _, err := p.applyTTL(ctx, client, source, source.TTLDays, false); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := source.SyncConnectionConfig(); err != nil {
 		return nil, err
 	}
@@ -273,6 +339,171 @@ func (p *ClickHouseProvider) UpdateSource(ctx context.Context, source *models.So
 	}, nil
 }
 
+// ApplyTTL changes the retention of the source's table with ALTER ... MODIFY
+// TTL (or REMOVE TTL for -1). A dry run only builds the statement. On success
+// source.TTLDays is set; saving it is left to the caller.
+func (p *ClickHouseProvider) ApplyTTL(ctx context.Context, source *models.Source, req *models.UpdateSourceTTLRequest) (*models.SourceTTLChange, error) {
+	if source == nil || req == nil {
+		return nil, fmt.Errorf("source and request are required")
+	}
+	client, err := p.manager.GetConnection(source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get connection for source %d: %w", source.ID, err)
+	}
+	return p.applyTTL(ctx, client, source, req.TTLDays, req.DryRun)
+}
+
+func (p *ClickHouseProvider) applyTTL(ctx context.Context, client *clickhouse.Client, source *models.Source, days int, dryRun bool) (*models.SourceTTLChange, error) {
+	// A TTL of 0 days would expire every row, so it is refused here even
+	// though new auto-created tables accept it.
+	if days < -1 || days == 0 {
+		return nil, &ValidationError{Field: "ttl_days", Message: "TTL days must be -1 (no TTL) or at least 1"}
+	}
+
+	tableInfo, err := client.GetTableInfo(ctx, source.Connection.Database, source.Connection.TableName)
+	if err != nil {
+		return nil, fmt.Errorf("inspect table metadata: %w", err)
+	}
+	stmt, err := clickhouse.BuildTTLStatement(storageTarget(source, tableInfo), source.MetaTSField, days)
+	if err != nil {
+		return nil, &ValidationError{Field: "ttl_days", Message: err.Error(), Err: err}
+	}
+
+	change := &models.SourceTTLChange{TTLDays: days, Statement: stmt}
+	if dryRun {
+		change.EffectiveTTL = extractTTLFromTableInfo(ctx, client, tableInfo)
+		return change, nil
+	}
+
+	if caps := p.manager.GetCachedHealth(source.ID).Capabilities; caps != nil && !caps.CanDDL {
+		return nil, &ValidationError{Field: "ttl_days", Message: "Cannot change the TTL: the ClickHouse connection is read-only"}
+	}
+	if err := client.ModifyTTL(ctx, stmt); err != nil {
+		return nil, err
+	}
+	change.Applied = true
+	source.TTLDays = days
+	p.log.Info("modified source table ttl", "source_id", source.ID, "ttl_days", days, "statement", stmt)
+
+	if tableInfo, err := client.GetTableInfo(ctx, source.Connection.Database, source.Connection.TableName); err == nil {
+		change.EffectiveTTL = extractTTLFromTableInfo(ctx, client, tableInfo)
+	}
+	return change, nil
+}
+
+// ListPartitions lists the active partitions of the source's table with their
+// sizes, newest first.
+func (p *ClickHouseProvider) ListPartitions(ctx context.Context, source *models.Source) ([]models.TablePartition, error) {
+	if source == nil {
+		return nil, fmt.Errorf("source is required")
+	}
+	client, err := p.manager.GetConnection(source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get connection for source %d: %w", source.ID, err)
+	}
+	tableInfo, err := client.GetTableInfo(ctx, source.Connection.Database, source.Connection.TableName)
+	if err != nil {
+		return nil, fmt.Errorf("inspect table metadata: %w", err)
+	}
+	target := storageTarget(source, tableInfo)
+	return client.ListPartitions(ctx, target.Database, target.Table)
+}
+
+// DropPartitions drops partitions of the source's table by partition_id. Only
+// tables LogChef created can be purged this way, and every ID must name an
+// existing partition. A dry run only builds the statements.
+func (p *ClickHouseProvider) DropPartitions(ctx context.Context, source *models.Source, req *models.DropPartitionsRequest) (*models.DropPartitionsResult, error) {
+	if source == nil || req == nil {
+		return nil, fmt.Errorf("source and request are required")
+	}
+	if !source.MetaIsAutoCreated {
+		return nil, &ValidationError{Field: "source", Message: "Partitions can only be dropped from tables LogChef created"}
+	}
+	if len(req.PartitionIDs) == 0 {
+		return nil, &ValidationError{Field: "partition_ids", Message: "at least one partition id is required"}
+	}
+
+	client, err := p.manager.GetConnection(source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get connection for source %d: %w", source.ID, err)
+	}
+	tableInfo, err := client.GetTableInfo(ctx, source.Connection.Database, source.Connection.TableName)
+	if err != nil {
+		return nil, fmt.Errorf("inspect table metadata: %w", err)
+	}
+	target := storageTarget(source, tableInfo)
+	existing, err := client.ListPartitions(ctx, target.Database, target.Table)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.TablePartition, len(existing))
+	for _, part := range existing {
+		byID[part.ID] = part
+	}
+
+	result := &models.DropPartitionsResult{Statements: []string{}}
+	seen := make(map[string]bool, len(req.PartitionIDs))
+	for _, id := range req.PartitionIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		part, ok := byID[id]
+		if !ok {
+			return nil, &ValidationError{Field: "partition_ids", Message: fmt.Sprintf("partition %q does not exist", id)}
+		}
+		stmt, err := clickhouse.BuildDropPartitionStatement(target, id)
+		if err != nil {
+			return nil, &ValidationError{Field: "partition_ids", Message: err.Error(), Err: err}
+		}
+		result.Statements = append(result.Statements, stmt)
+		result.Rows += part.Rows
+		result.BytesOnDisk += part.BytesOnDisk
+	}
+	if req.DryRun {
+		return result, nil
+	}
+
+	if caps := p.manager.GetCachedHealth(source.ID).Capabilities; caps != nil && !caps.CanDDL {
+		return nil, &ValidationError{Field: "source", Message: "Cannot drop partitions: the ClickHouse connection is read-only"}
+	}
+	for i, stmt := range result.Statements {
+		if err := client.DropPartition(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("%w (%d of %d partitions dropped)", err, i, len(result.Statements))
+		}
+		p.log.Info("dropped source table partition", "source_id", source.ID, "statement", stmt)
+	}
+	result.Applied = true
+	return result, nil
+}
+
+// IngestLogs inserts JSONEachRow rows into the source's table with an async
+// insert.
+func (p *ClickHouseProvider) IngestLogs(ctx context.Context, source *models.Source, rows []byte, wait bool) error {
+	if source == nil {
+		return fmt.Errorf("source is required")
+	}
+	client, err := p.manager.GetConnection(source.ID)
+	if err != nil {
+		return fmt.Errorf("get connection for source %d: %w", source.ID, err)
+	}
+	return client.InsertJSONEachRow(ctx, source.Connection.Database, source.Connection.TableName, rows, wait)
+}
+
+// storageTarget is the table holding a source's rows. TTL and partitions
+// belong to MergeTree tables, so for a Distributed source they live on its
+// local table, on every node of the cluster.
+func storageTarget(source *models.Source, tableInfo *clickhouse.TableInfo) clickhouse.TableTarget {
+	if tableInfo != nil && tableInfo.Engine == "Distributed" && len(tableInfo.EngineParams) >= 3 {
+		return clickhouse.TableTarget{
+			Database: tableInfo.EngineParams[1],
+			Table:    tableInfo.EngineParams[2],
+			Cluster:  tableInfo.EngineParams[0],
+		}
+	}
+	return clickhouse.TableTarget{Database: source.Connection.Database, Table: source.Connection.TableName}
+}
+
 func (p *ClickHouseProvider) PopulateSourceDetails(ctx context.Context, source *models.Source) error {
 	if source == nil {
 		return fmt.Errorf("source is required")
@@ -283,6 +514,7 @@ func (p *ClickHouseProvider) PopulateSourceDetails(ctx context.Context, source *
 	source.Engine = ""
 	source.EngineParams = nil
 	source.SortKeys = nil
+	source.EffectiveTTL = ""
 
 	if !source.IsConnected {
 		return nil
@@ -300,46 +532,85 @@ func (p *ClickHouseProvider) PopulateSourceDetails(ctx context.Context, source *
 		return nil
 	}
 
-	source.Columns = tableInfo.Columns
+	source.Columns = withVirtualColumns(source, tableInfo.Columns)
 	source.Schema = tableInfo.CreateQuery
 	source.Engine = tableInfo.Engine
 	source.EngineParams = tableInfo.EngineParams
 	source.SortKeys = tableInfo.SortKeys
+	source.EffectiveTTL = extractTTLFromTableInfo(ctx, client, tableInfo)
 	return nil
 }
 
 func (p *ClickHouseProvider) QueryLogs(ctx context.Context, source *models.Source, req QueryRequest) (*models.QueryResult, error) {
-	client, sql, opts, err := p.buildQuery(source, req)
+	client, sql, opts, err := p.buildQuery(ctx, source, req)
 	if err != nil {
 		return nil, err
 	}
+	if err := p.checkQueryCost(ctx, client, sql, req.CostLimits); err != nil {
+		return nil, err
+	}
 	return client.QueryWithOptions(ctx, sql, opts)
 }
 
 // QueryLogsStream executes the query and streams rows into w instead of
-// buffering the full result set. It uses the same limit policy, warnings, and
-// query settings as QueryLogs, so the streamed response is equivalent to the
-// buffered one — only the server-side memory profile differs (bounded, since no
-// []map result slice is materialized). The soft response-byte budget
-// (MaxResponseBytes) is intentionally not applied on the streaming path: it was
-// a memory mitigation for the buffered []map, which streaming removes; row
-// count is still bounded by the applied limit / max_result_rows.
+// buffering the full result set. It uses the same limit policy, byte budget,
+// warnings, and query settings as QueryLogs, so the streamed response is
+// equivalent to the buffered one — only the server-side memory profile differs
+// (bounded, since no []map result slice is materialized). The byte budget
+// (MaxResponseBytes) still matters here: without it a few rows of a huge map
+// column make a response the browser can't handle.
 func (p *ClickHouseProvider) QueryLogsStream(ctx context.Context, source *models.Source, req QueryRequest, w StreamWriter) (models.QueryStats, error) {
-	client, sql, opts, err := p.buildQuery(source, req)
+	client, sql, opts, err := p.buildQuery(ctx, source, req)
 	if err != nil {
 		return models.QueryStats{}, err
 	}
+	if err := p.checkQueryCost(ctx, client, sql, req.CostLimits); err != nil {
+		return models.QueryStats{}, err
+	}
 	// Warnings are known at build time (LIMIT_APPLIED / LIMIT_CAPPED); deliver
 	// them up front so the writer can emit them alongside the streamed body.
 	w.SetWarnings(opts.Warnings)
-	return client.QueryStream(ctx, sql, opts, w)
+	return client.QueryStream(ctx, sql, opts, &byteLimitWarningWriter{StreamWriter: w, warnings: opts.Warnings, maxBytes: opts.MaxResponseBytes})
+}
+
+// byteLimitWarningWriter adds clickhouse.ByteLimitWarning to a streamed
+// response the byte budget truncated. The warning is only known once the
+// rows are written, so it is set just before Finish.
+type byteLimitWarningWriter struct {
+	StreamWriter
+	warnings []models.QueryWarning
+	maxBytes int
+}
+
+func (w *byteLimitWarningWriter) Finish(stats models.QueryStats) error {
+	if stats.TruncatedReason == "byte_limit" {
+		w.SetWarnings(append(slices.Clone(w.warnings), clickhouse.ByteLimitWarning(w.maxBytes)))
+	}
+	return w.StreamWriter.Finish(stats)
+}
+
+// CountQuery counts the rows req's query matches without its limit. The
+// count honours the query timeout and enforced read caps but skips the
+// EXPLAIN cost check: it reads the same data the query itself was allowed to.
+// A sampled query is counted in full, since the count is a total.
+func (p *ClickHouseProvider) CountQuery(ctx context.Context, source *models.Source, req QueryRequest) (int64, error) {
+	req.Sample = 0
+	client, sql, opts, err := p.buildQuery(ctx, source, req)
+	if err != nil {
+		return 0, err
+	}
+	return client.CountRows(ctx, sql, clickhouse.QueryOptions{
+		TimeoutSeconds: opts.TimeoutSeconds,
+		MaxRowsToRead:  opts.MaxRowsToRead,
+		MaxBytesToRead: opts.MaxBytesToRead,
+	})
 }
 
 // buildQuery resolves the source's ClickHouse connection and builds the raw
 // query with the caller's limit policy, returning the connection, the built
 // SQL, and the execution options (settings, limit, warnings) shared by the
 // buffered and streaming query paths.
-func (p *ClickHouseProvider) buildQuery(source *models.Source, req QueryRequest) (*clickhouse.Client, string, clickhouse.QueryOptions, error) {
+func (p *ClickHouseProvider) buildQuery(ctx context.Context, source *models.Source, req QueryRequest) (*clickhouse.Client, string, clickhouse.QueryOptions, error) {
 	if source == nil {
 		return nil, "", clickhouse.QueryOptions{}, fmt.Errorf("source is required")
 	}
@@ -354,9 +625,51 @@ func (p *ClickHouseProvider) buildQuery(source *models.Source, req QueryRequest)
 		return nil, "", clickhouse.QueryOptions{}, fmt.Errorf("error getting database connection for source %d: %w", source.ID, err)
 	}
 
-	qb := clickhouse.NewExtendedQueryBuilder(source.GetFullTableName(), req.MaxLimit)
+	qb := clickhouse.NewExtendedQueryBuilder(source.GetFullTableName(), req.MaxLimit).WithDerivedColumns(req.DerivedColumns).WithRowFilter(req.RowFilter)
+	if len(req.MaskedColumns) > 0 {
+		// The schema expands SELECT * so the masked columns in it can be
+		// wrapped; without it such a query is rejected rather than leaked.
+		columns := source.Columns
+		if len(columns) == 0 {
+			if columns, err = p.GetSourceSchema(ctx, source); err != nil {
+				p.log.Warn("schema lookup for column masking failed", "error", err, "source_id", source.ID)
+			}
+		}
+		qb.WithMaskedColumns(req.MaskedColumns, columns)
+	}
+	if req.RequireTimeBound && source.MetaTSField != "" {
+		qb.WithTimeBound(source.MetaTSField, req.StartTime, req.EndTime)
+	}
+	if req.Sample != 0 {
+		// Without a sampling key the builder falls back to a random filter,
+		// so a failed lookup only costs speed.
+		useClause, err := client.SupportsSampling(ctx, source.Connection.Database, source.Connection.TableName)
+		if err != nil {
+			p.log.Warn("sampling key lookup failed, sampling with a random filter", "error", err, "source_id", source.ID)
+		}
+		qb.WithSample(req.Sample, useClause)
+	}
+	if req.Distributed != nil {
+		distributed, err := client.IsDistributed(ctx, source.Connection.Database, source.Connection.TableName)
+		if err != nil {
+			return nil, "", clickhouse.QueryOptions{}, fmt.Errorf("checking table engine for distributed routing: %w", err)
+		}
+		if !distributed {
+			return nil, "", clickhouse.QueryOptions{}, &clickhouse.ValidationError{Message: "shard routing needs a source whose table is Distributed"}
+		}
+		qb.WithShard(req.Distributed.Shard)
+	}
+	_, span := tracing.Start(ctx, "query.parse", trace.WithAttributes(
+		attribute.Int64("logchef.source_id", int64(source.ID)),
+		attribute.Int("logchef.query.length", len(req.RawQuery)),
+	))
 	buildResult, err := qb.BuildRawQueryWithLimitPolicy(req.RawQuery, req.Limit, req.DefaultLimit, req.MaxLimit)
+	span.SetAttributes(attribute.Int("logchef.query.limit", buildResult.AppliedLimit))
+	tracing.End(span, err)
 	if err != nil {
+		if clickhouse.IsValidationError(err) {
+			return nil, "", clickhouse.QueryOptions{}, err
+		}
 		return nil, "", clickhouse.QueryOptions{}, fmt.Errorf("invalid query syntax: %w", err)
 	}
 
@@ -371,12 +684,59 @@ func (p *ClickHouseProvider) buildQuery(source *models.Source, req QueryRequest)
 		MaxRows:          buildResult.AppliedLimit,
 		MaxResponseBytes: req.MaxResponseBytes,
 		Warnings:         queryWarningsForBuildResult(buildResult),
+		SampleRatio:      req.Sample,
+		SampleMethod:     buildResult.SampleMethod,
+	}
+	if req.MaxResponseBytes > 0 {
+		// Have ClickHouse stop sending blocks past the budget too, so a huge
+		// map or JSON column is cut off before it all reaches Logchef.
+		opts.Settings["max_result_bytes"] = req.MaxResponseBytes
+	}
+	if routing := req.Distributed; routing != nil {
+		if routing.Shard != 0 {
+			// Only the chosen shard is asked; the others would return
+			// nothing past the _shard_num filter anyway.
+			opts.Settings["optimize_skip_unused_shards"] = 1
+		}
+		if prefer := routing.PreferLocalhostReplica; prefer != nil {
+			opts.Settings["prefer_localhost_replica"] = 0
+			if *prefer {
+				opts.Settings["prefer_localhost_replica"] = 1
+			}
+		}
+	}
+	if limits := req.CostLimits; limits != nil && limits.Enforce {
+		opts.MaxRowsToRead = limits.MaxRowsToRead
+		opts.MaxBytesToRead = limits.MaxBytesToRead
+	}
+	if req.Timezone != "" {
+		loc, err := timezone.Load(req.Timezone)
+		if err != nil {
+			return nil, "", clickhouse.QueryOptions{}, &clickhouse.ValidationError{Message: err.Error()}
+		}
+		opts.Location = loc
 	}
 	return client, buildResult.SQL, opts, nil
 }
 
+// checkQueryCost dry-runs sql with EXPLAIN ESTIMATE when the limits ask for it
+// and returns a *models.QueryCostError if the estimate is over a cap. A failed
+// estimate is logged and lets the query through: the guardrail is advisory
+// and Enforce still bounds the real read.
+func (p *ClickHouseProvider) checkQueryCost(ctx context.Context, client *clickhouse.Client, sql string, limits *models.QueryCostLimits) error {
+	if limits.IsZero() || !limits.Explain {
+		return nil
+	}
+	estimate, err := client.EstimateQuery(ctx, sql)
+	if err != nil {
+		p.log.Warn("query cost estimate failed, running query without it", "error", err)
+		return nil
+	}
+	return limits.Check(estimate)
+}
+
 func queryWarningsForBuildResult(result clickhouse.QueryBuildResult) []models.QueryWarning {
-	warnings := make([]models.QueryWarning, 0, 2)
+	warnings := make([]models.QueryWarning, 0, 3)
 	if result.LimitAdded {
 		warnings = append(warnings, models.QueryWarning{
 			Code:    "LIMIT_APPLIED",
@@ -389,6 +749,12 @@ func queryWarningsForBuildResult(result clickhouse.QueryBuildResult) []models.Qu
 			Message: fmt.Sprintf("Result limit capped at %d rows.", result.AppliedLimit),
 		})
 	}
+	if result.TimeBoundAdded {
+		warnings = append(warnings, models.QueryWarning{
+			Code:    "TIME_RANGE_APPLIED",
+			Message: "The query had no condition on the timestamp column, so the selected time range was applied.",
+		})
+	}
 	return warnings
 }
 
@@ -407,7 +773,17 @@ func (p *ClickHouseProvider) GetSourceSchema(ctx context.Context, source *models
 		return nil, fmt.Errorf("error retrieving schema for source %d: %w", source.ID, err)
 	}
 
-	return tableInfo.Columns, nil
+	return withVirtualColumns(source, tableInfo.Columns), nil
+}
+
+// withVirtualColumns appends the source's extraction rules to the table's
+// columns, so the schema, field completion and result typing see them.
+func withVirtualColumns(source *models.Source, columns []models.ColumnInfo) []models.ColumnInfo {
+	virtual := source.ExtractionRules.VirtualColumns(columns)
+	if len(virtual) == 0 {
+		return columns
+	}
+	return append(slices.Clip(columns), virtual...)
 }
 
 func (p *ClickHouseProvider) Histogram(ctx context.Context, source *models.Source, req HistogramRequest) (*HistogramResult, error) {
@@ -435,12 +811,20 @@ func (p *ClickHouseProvider) Histogram(ctx context.Context, source *models.Sourc
 		return nil, err
 	}
 
+	groupByExpr, err := p.histogramGroupByExpr(ctx, source, req.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
 	result, err := client.GetHistogramData(ctx, source.GetFullTableName(), source.MetaTSField, clickhouse.HistogramParams{
-		Window:       window,
-		Query:        req.Query,
-		GroupBy:      req.GroupBy,
-		Timezone:     req.Timezone,
-		QueryTimeout: req.QueryTimeout,
+		Window:        window,
+		Query:         req.Query,
+		GroupBy:       req.GroupBy,
+		GroupByExpr:   groupByExpr,
+		Timezone:      req.Timezone,
+		RowFilter:     req.RowFilter,
+		MaskedColumns: req.MaskedColumns,
+		QueryTimeout:  req.QueryTimeout,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error generating histogram for source %d: %w", source.ID, err)
@@ -464,6 +848,26 @@ func (p *ClickHouseProvider) Histogram(ctx context.Context, source *models.Sourc
 	}, nil
 }
 
+// histogramGroupByExpr resolves a histogram's group-by field against the
+// source's schema like a pipe select field: a column, a Map key or JSON path,
+// or a key of the default Map column. Fetching the schema is best-effort;
+// without it the field is grouped by as a quoted column.
+func (p *ClickHouseProvider) histogramGroupByExpr(ctx context.Context, source *models.Source, groupBy string) (string, error) {
+	if strings.TrimSpace(groupBy) == "" {
+		return "", nil
+	}
+	if len(source.Columns) == 0 {
+		if columns, err := p.GetSourceSchema(ctx, source); err == nil {
+			source.Columns = columns
+		}
+	}
+	expr, parseErr := logchefql.GroupByExpression(groupBy, buildLogchefQLSchema(source))
+	if parseErr != nil {
+		return "", &ValidationError{Field: "group_by", Message: parseErr.Message}
+	}
+	return expr, nil
+}
+
 func (p *ClickHouseProvider) GetFieldValues(ctx context.Context, source *models.Source, req FieldValuesRequest) (*FieldValuesResult, error) {
 	if source == nil {
 		return nil, fmt.Errorf("source is required")
@@ -487,11 +891,18 @@ func (p *ClickHouseProvider) GetFieldValues(ctx context.Context, source *models.
 		Limit:          req.Limit,
 		Timeout:        req.Timeout,
 		LogchefQL:      req.QueryText,
+		RowFilter:      req.RowFilter,
+		MaskedColumns:  req.MaskedColumns,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get field values: %w", err)
 	}
 
+	return fieldValuesFromClickHouse(result), nil
+}
+
+// fieldValuesFromClickHouse converts a ClickHouse field values result.
+func fieldValuesFromClickHouse(result *clickhouse.FieldValuesResult) *FieldValuesResult {
 	values := make([]FieldValueInfo, 0, len(result.Values))
 	for _, value := range result.Values {
 		values = append(values, FieldValueInfo{
@@ -499,6 +910,14 @@ func (p *ClickHouseProvider) GetFieldValues(ctx context.Context, source *models.
 			Count: value.Count,
 		})
 	}
+	var buckets []NumericBucket
+	for _, bucket := range result.NumericBuckets {
+		buckets = append(buckets, NumericBucket(bucket))
+	}
+	var days []DayCount
+	for _, day := range result.DayCounts {
+		days = append(days, DayCount(day))
+	}
 
 	return &FieldValuesResult{
 		FieldName:        result.FieldName,
@@ -506,9 +925,296 @@ func (p *ClickHouseProvider) GetFieldValues(ctx context.Context, source *models.
 		IsLowCardinality: result.IsLowCard,
 		Values:           values,
 		TotalDistinct:    result.TotalDistinct,
+		NumericBuckets:   buckets,
+		DayCounts:        days,
+	}
+}
+
+// GetFieldStats computes min/max/avg/percentiles and the null ratio for a
+// numeric or datetime column in one aggregate query.
+func (p *ClickHouseProvider) GetFieldStats(ctx context.Context, source *models.Source, req FieldStatsRequest) (*FieldStatsResult, error) {
+	if source == nil {
+		return nil, fmt.Errorf("source is required")
+	}
+	if clickhouse.FieldStatsKind(req.FieldType) == "" {
+		return nil, &ValidationError{Field: "type", Message: fmt.Sprintf("statistics are only available for numeric and datetime fields, not %s", req.FieldType)}
+	}
+	if strings.TrimSpace(req.TimestampField) == "" {
+		req.TimestampField = source.MetaTSField
+	}
+
+	client, err := p.manager.GetConnection(source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to source %d: %w", source.ID, err)
+	}
+
+	result, err := client.GetFieldStats(ctx, source.Connection.Database, source.Connection.TableName, clickhouse.FieldStatsParams{
+		FieldName:      req.FieldName,
+		FieldType:      req.FieldType,
+		TimestampField: req.TimestampField,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		Timezone:       req.Timezone,
+		Timeout:        req.Timeout,
+		LogchefQL:      req.QueryText,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get field stats: %w", err)
+	}
+
+	return &FieldStatsResult{
+		FieldName:   result.FieldName,
+		FieldType:   result.FieldType,
+		Kind:        result.Kind,
+		Count:       result.Count,
+		NullCount:   result.NullCount,
+		NullPercent: result.NullPercent,
+		Min:         result.Min,
+		Max:         result.Max,
+		Avg:         result.Avg,
+		P50:         result.P50,
+		P95:         result.P95,
+		P99:         result.P99,
 	}, nil
 }
 
+// GetJSONFields samples recent rows and reports the keys of JSON objects in
+// the requested String columns, or in the body column when none are given.
+func (p *ClickHouseProvider) GetJSONFields(ctx context.Context, source *models.Source, req JSONFieldsRequest) (*models.JSONFieldsResult, error) {
+	if source == nil {
+		return nil, fmt.Errorf("source is required")
+	}
+	if strings.TrimSpace(req.TimestampField) == "" {
+		req.TimestampField = source.MetaTSField
+	}
+
+	client, err := p.manager.GetConnection(source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to source %d: %w", source.ID, err)
+	}
+	tableInfo, err := client.GetTableInfo(ctx, source.Connection.Database, source.Connection.TableName)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving schema for source %d: %w", source.ID, err)
+	}
+	columns, err := stringColumns(tableInfo.Columns, req.Columns, "columns")
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.GetJSONFields(ctx, source.Connection.Database, source.Connection.TableName, clickhouse.JSONFieldsParams{
+		Columns:        columns,
+		TimestampField: req.TimestampField,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		Timezone:       req.Timezone,
+		SampleSize:     req.SampleSize,
+		Timeout:        req.Timeout,
+		LogchefQL:      req.QueryText,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get JSON fields: %w", err)
+	}
+	return result, nil
+}
+
+// GetLogPatterns groups a String column's messages in a time range into
+// templates and returns the most frequent ones.
+func (p *ClickHouseProvider) GetLogPatterns(ctx context.Context, source *models.Source, req LogPatternsRequest) (*models.LogPatternsResult, error) {
+	if source == nil {
+		return nil, fmt.Errorf("source is required")
+	}
+	if strings.TrimSpace(req.TimestampField) == "" {
+		req.TimestampField = source.MetaTSField
+	}
+
+	client, err := p.manager.GetConnection(source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to source %d: %w", source.ID, err)
+	}
+	tableInfo, err := client.GetTableInfo(ctx, source.Connection.Database, source.Connection.TableName)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving schema for source %d: %w", source.ID, err)
+	}
+	var requested []string
+	if req.FieldName != "" {
+		requested = []string{req.FieldName}
+	}
+	columns, err := stringColumns(tableInfo.Columns, requested, "field")
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.GetLogPatterns(ctx, source.Connection.Database, source.Connection.TableName, clickhouse.PatternsParams{
+		FieldName:      columns[0],
+		TimestampField: req.TimestampField,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		Timezone:       req.Timezone,
+		Limit:          req.Limit,
+		Baseline:       req.Baseline,
+		Timeout:        req.Timeout,
+		LogchefQL:      req.QueryText,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log patterns: %w", err)
+	}
+	return result, nil
+}
+
+// DetectVolumeAnomalies compares the source's row count, and its error rate
+// when it has a severity field, with the same window a day or week earlier.
+func (p *ClickHouseProvider) DetectVolumeAnomalies(ctx context.Context, source *models.Source, req VolumeAnomalyRequest) (*models.VolumeAnomalyReport, error) {
+	if source == nil {
+		return nil, fmt.Errorf("source is required")
+	}
+	if req.Baseline == "" {
+		req.Baseline = models.AlertBaselinePreviousDay
+	}
+	offset := req.Baseline.Offset()
+	if offset == 0 {
+		return nil, &ValidationError{Field: "baseline", Message: "baseline must be previous_day or previous_week"}
+	}
+	if req.Window <= 0 || req.Window > offset {
+		return nil, &ValidationError{Field: "window_seconds", Message: fmt.Sprintf("window must be positive and at most the %s offset", req.Baseline)}
+	}
+	if req.ThresholdPercent <= 0 {
+		req.ThresholdPercent = models.DefaultVolumeAnomalyThreshold
+	}
+	if req.MinRows <= 0 {
+		req.MinRows = models.DefaultVolumeAnomalyMinRows
+	}
+
+	client, err := p.manager.GetConnection(source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to source %d: %w", source.ID, err)
+	}
+	end := req.End.UTC().Truncate(time.Second)
+	start := end.Add(-req.Window)
+	counts, err := client.CompareVolume(ctx, source.Connection.Database, source.Connection.TableName, clickhouse.VolumeComparisonParams{
+		TimestampField: source.MetaTSField,
+		SeverityField:  source.MetaSeverityField,
+		Start:          start,
+		End:            end,
+		Offset:         offset,
+		Timeout:        req.Timeout,
+		LogchefQL:      req.QueryText,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare log volume: %w", err)
+	}
+
+	report := &models.VolumeAnomalyReport{
+		Baseline:         req.Baseline,
+		ThresholdPercent: req.ThresholdPercent,
+		MinRows:          req.MinRows,
+		Current:          models.VolumeWindow{Start: start, End: end, Rows: counts.CurrentRows},
+		Previous:         models.VolumeWindow{Start: start.Add(-offset), End: end.Add(-offset), Rows: counts.PreviousRows},
+	}
+	if source.MetaSeverityField != "" {
+		report.Current.SetErrors(counts.CurrentErrors)
+		report.Previous.SetErrors(counts.PreviousErrors)
+	}
+	report.Evaluate()
+	return report, nil
+}
+
+// maxCompareFields caps how many fields one range comparison diffs; each is
+// a separate query.
+const maxCompareFields = 20
+
+// CompareRanges diffs field value distributions between a baseline and a
+// target range, e.g. before and after a deploy.
+func (p *ClickHouseProvider) CompareRanges(ctx context.Context, source *models.Source, req RangeComparisonRequest) (*models.RangeComparison, error) {
+	if source == nil {
+		return nil, fmt.Errorf("source is required")
+	}
+	if !req.BaselineStart.Before(req.BaselineEnd) || !req.TargetStart.Before(req.TargetEnd) {
+		return nil, &ValidationError{Field: "time_range", Message: "each range must start before it ends"}
+	}
+	if req.BaselineStart.Before(req.TargetEnd) && req.TargetStart.Before(req.BaselineEnd) {
+		return nil, &ValidationError{Field: "time_range", Message: "the baseline and target ranges must not overlap"}
+	}
+
+	client, err := p.manager.GetConnection(source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to source %d: %w", source.ID, err)
+	}
+	tableInfo, err := client.GetTableInfo(ctx, source.Connection.Database, source.Connection.TableName)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving schema for source %d: %w", source.ID, err)
+	}
+	fields, err := compareColumns(tableInfo.Columns, req.Fields, source.MetaTSField)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.CompareRanges(ctx, source.Connection.Database, source.Connection.TableName, clickhouse.RangeComparisonParams{
+		Fields:           fields,
+		TimestampField:   source.MetaTSField,
+		BaselineStart:    req.BaselineStart,
+		BaselineEnd:      req.BaselineEnd,
+		TargetStart:      req.TargetStart,
+		TargetEnd:        req.TargetEnd,
+		Limit:            req.Limit,
+		ThresholdPercent: req.ThresholdPercent,
+		Timeout:          req.Timeout,
+		LogchefQL:        req.QueryText,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare ranges: %w", err)
+	}
+	return result, nil
+}
+
+// compareColumns checks that the requested columns exist and have
+// filterable types. Without a request it picks the LowCardinality and Enum
+// columns, which are cheap to group, skipping the timestamp column.
+func compareColumns(schema []models.ColumnInfo, requested []string, tsField string) ([]string, error) {
+	if len(requested) == 0 {
+		for _, col := range schema {
+			if col.Name != tsField && (strings.Contains(col.Type, "LowCardinality") || strings.HasPrefix(col.Type, "Enum")) &&
+				clickhouse.IsFilterableColumnType(col.Type) {
+				requested = append(requested, col.Name)
+			}
+		}
+		if len(requested) == 0 {
+			return nil, &ValidationError{Field: "fields", Message: "the source has no LowCardinality or Enum columns; pass fields explicitly"}
+		}
+		return requested[:min(len(requested), maxCompareFields)], nil
+	}
+	if len(requested) > maxCompareFields {
+		return nil, &ValidationError{Field: "fields", Message: fmt.Sprintf("at most %d fields can be compared at once", maxCompareFields)}
+	}
+	for _, name := range requested {
+		idx := slices.IndexFunc(schema, func(c models.ColumnInfo) bool { return c.Name == name })
+		if idx < 0 {
+			return nil, &ValidationError{Field: "fields", Message: fmt.Sprintf("column %q does not exist", name)}
+		}
+		if !clickhouse.IsFilterableColumnType(schema[idx].Type) {
+			return nil, &ValidationError{Field: "fields", Message: fmt.Sprintf("column %q is %s, which can't be compared", name, schema[idx].Type)}
+		}
+	}
+	return requested, nil
+}
+
+// stringColumns checks that the requested columns exist and hold strings,
+// defaulting to the body column. param names the request field in errors.
+func stringColumns(schema []models.ColumnInfo, requested []string, param string) ([]string, error) {
+	if len(requested) == 0 {
+		requested = []string{"body"}
+	}
+	for _, name := range requested {
+		idx := slices.IndexFunc(schema, func(c models.ColumnInfo) bool { return c.Name == name })
+		if idx < 0 {
+			return nil, &ValidationError{Field: param, Message: fmt.Sprintf("column %q does not exist", name)}
+		}
+		if !clickhouse.IsStringColumnType(schema[idx].Type) {
+			return nil, &ValidationError{Field: param, Message: fmt.Sprintf("column %q is %s, not a String column", name, schema[idx].Type)}
+		}
+	}
+	return requested, nil
+}
+
 func (p *ClickHouseProvider) GetAllFieldValues(ctx context.Context, source *models.Source, req AllFieldValuesRequest) (AllFieldValuesResult, error) {
 	if source == nil {
 		return nil, fmt.Errorf("source is required")
@@ -530,6 +1236,8 @@ func (p *ClickHouseProvider) GetAllFieldValues(ctx context.Context, source *mode
 		Limit:          req.Limit,
 		Timeout:        req.Timeout,
 		LogchefQL:      req.QueryText,
+		RowFilter:      req.RowFilter,
+		MaskedColumns:  req.MaskedColumns,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get field values: %w", err)
@@ -540,20 +1248,7 @@ func (p *ClickHouseProvider) GetAllFieldValues(ctx context.Context, source *mode
 		if fieldResult == nil {
 			continue
 		}
-		values := make([]FieldValueInfo, 0, len(fieldResult.Values))
-		for _, value := range fieldResult.Values {
-			values = append(values, FieldValueInfo{
-				Value: value.Value,
-				Count: value.Count,
-			})
-		}
-		mapped[fieldName] = &FieldValuesResult{
-			FieldName:        fieldResult.FieldName,
-			FieldType:        fieldResult.FieldType,
-			IsLowCardinality: fieldResult.IsLowCard,
-			Values:           values,
-			TotalDistinct:    fieldResult.TotalDistinct,
-		}
+		mapped[fieldName] = fieldValuesFromClickHouse(fieldResult)
 	}
 
 	return mapped, nil
@@ -574,18 +1269,58 @@ func (p *ClickHouseProvider) InspectSource(ctx context.Context, source *models.S
 		return nil, fmt.Errorf("inspect table metadata: %w", err)
 	}
 	ttlExpr := extractTTLFromTableInfo(ctx, client, tableInfo)
-	statsDB, statsTable := getStatsTableLocation(source, tableInfo)
-	tableStats, statsErr := client.TableStats(ctx, statsDB, statsTable)
-	if statsErr != nil {
-		p.log.Warn("failed to inspect table storage", "source_id", source.ID, "error", statsErr)
+	// Without system.parts access the stats query can only fail; the health
+	// check's capabilities already say so, so skip it quietly.
+	var tableStats *clickhouse.TableStat
+	if caps := p.manager.GetCachedHealth(source.ID).Capabilities; caps == nil || caps.CanStats {
+		statsDB, statsTable := getStatsTableLocation(source, tableInfo)
+		var statsErr error
+		tableStats, statsErr = client.TableStats(ctx, statsDB, statsTable)
+		if statsErr != nil {
+			p.log.Warn("failed to inspect table storage", "source_id", source.ID, "error", statsErr)
+		}
+	}
+	var cluster *ClusterInspection
+	if tableInfo.Engine == "Distributed" && len(tableInfo.EngineParams) >= 3 {
+		replicas, clusterErr := client.ClusterReplicas(ctx, tableInfo.EngineParams[0])
+		if clusterErr != nil {
+			p.log.Warn("failed to inspect cluster", "source_id", source.ID, "cluster", tableInfo.EngineParams[0], "error", clusterErr)
+		}
+		cluster = mapClusterInspection(tableInfo, replicas)
 	}
 	return &SourceInspection{
 		Details: buildClickHouseInspectionDetails(source, tableInfo),
 		Storage: buildClickHouseStorageMetrics(tableStats),
 		Schema:  mapClickHouseSchemaInspection(tableInfo, source, ttlExpr),
+		Cluster: cluster,
 	}, nil
 }
 
+// mapClusterInspection describes the cluster of a Distributed table from its
+// engine parameters (cluster, local database, local table) and replicas.
+func mapClusterInspection(tableInfo *clickhouse.TableInfo, replicas []clickhouse.ClusterReplica) *ClusterInspection {
+	cluster := &ClusterInspection{
+		Name:          tableInfo.EngineParams[0],
+		LocalDatabase: tableInfo.EngineParams[1],
+		LocalTable:    tableInfo.EngineParams[2],
+		Replicas:      make([]ClusterReplica, 0, len(replicas)),
+	}
+	shards := make(map[uint32]struct{})
+	for _, replica := range replicas {
+		shards[replica.Shard] = struct{}{}
+		cluster.Replicas = append(cluster.Replicas, ClusterReplica{
+			Shard:       replica.Shard,
+			Replica:     replica.Replica,
+			Host:        replica.Host,
+			Port:        replica.Port,
+			IsLocal:     replica.IsLocal,
+			ErrorsCount: replica.ErrorsCount,
+		})
+	}
+	cluster.Shards = len(shards)
+	return cluster
+}
+
 func (p *ClickHouseProvider) InspectSourceActivity(ctx context.Context, source *models.Source) (*SourceActivity, error) {
 	if source == nil {
 		return nil, fmt.Errorf("source is required")
@@ -623,6 +1358,11 @@ func (p *ClickHouseProvider) EvaluateAlert(ctx context.Context, source *models.S
 	if language := models.NormalizeQueryLanguage(req.Language); language != "" && language != models.QueryLanguageClickHouseSQL {
 		return nil, fmt.Errorf("clickhouse alerts require %q, got %q", models.QueryLanguageClickHouseSQL, language)
 	}
+	// The query goes to the client as written, so anything but a SELECT would
+	// run with the source's credentials; alerts never need more.
+	if err := clickhouse.ValidateReadOnly(req.Query); err != nil {
+		return nil, err
+	}
 	if req.QueryTimeout == nil {
 		defaultTimeout := models.DefaultQueryTimeoutSeconds
 		req.QueryTimeout = &defaultTimeout
@@ -667,15 +1407,15 @@ func (p *ClickHouseProvider) connectionFromConfig(raw json.RawMessage) (models.C
 	if err := conn.Settings.Validate(); err != nil {
 		return conn, &ValidationError{Field: "connection.settings", Message: err.Error()}
 	}
+	if err := conn.Pool.Validate(); err != nil {
+		return conn, &ValidationError{Field: "connection.pool", Message: err.Error()}
+	}
 	return conn, nil
 }
 
 func (p *ClickHouseProvider) validateColumnTypes(ctx context.Context, client *clickhouse.Client, database, tableName, tsField, severityField string) error {
-	tsQuery := fmt.Sprintf(
-		`SELECT type FROM system.columns WHERE database = '%s' AND table = '%s' AND name = '%s'`,
-		database, tableName, tsField,
-	)
-	tsResult, err := client.Query(ctx, tsQuery)
+	const columnTypeQuery = `SELECT type FROM system.columns WHERE database = ? AND table = ? AND name = ?`
+	tsResult, err := client.QueryWithArgs(ctx, columnTypeQuery, nil, database, tableName, tsField)
 	if err != nil {
 		p.log.Error("failed to query timestamp column type during validation", "error", err, "database", database, "table", tableName, "ts_field", tsField)
 		return &ValidationError{Field: "meta_ts_field", Message: "Failed to query timestamp column type", Err: err}
@@ -695,11 +1435,7 @@ func (p *ClickHouseProvider) validateColumnTypes(ctx context.Context, client *cl
 		return nil
 	}
 
-	sevQuery := fmt.Sprintf(
-		`SELECT type FROM system.columns WHERE database = '%s' AND table = '%s' AND name = '%s'`,
-		database, tableName, severityField,
-	)
-	sevResult, err := client.Query(ctx, sevQuery)
+	sevResult, err := client.QueryWithArgs(ctx, columnTypeQuery, nil, database, tableName, severityField)
 	if err != nil {
 		p.log.Error("failed to query severity column type during validation", "error", err, "database", database, "table", tableName, "severity_field", severityField)
 		return &ValidationError{Field: "meta_severity_field", Message: "Failed to query severity column type", Err: err}
@@ -720,9 +1456,9 @@ func (p *ClickHouseProvider) validateColumnTypes(ctx context.Context, client *cl
 
 func parseTimeWindow(window string) (clickhouse.TimeWindow, error) {
 	windowMap := map[string]clickhouse.TimeWindow{
-		"1s": clickhouse.TimeWindow1s, "5s": clickhouse.TimeWindow5s,
+		"1s": clickhouse.TimeWindow1s, "2s": clickhouse.TimeWindow2s, "5s": clickhouse.TimeWindow5s,
 		"10s": clickhouse.TimeWindow10s, "15s": clickhouse.TimeWindow15s, "30s": clickhouse.TimeWindow30s,
-		"1m": clickhouse.TimeWindow1m, "5m": clickhouse.TimeWindow5m,
+		"1m": clickhouse.TimeWindow1m, "2m": clickhouse.TimeWindow2m, "5m": clickhouse.TimeWindow5m,
 		"10m": clickhouse.TimeWindow10m, "15m": clickhouse.TimeWindow15m, "30m": clickhouse.TimeWindow30m,
 		"1h": clickhouse.TimeWindow1h, "2h": clickhouse.TimeWindow2h, "3h": clickhouse.TimeWindow3h,
 		"6h": clickhouse.TimeWindow6h, "12h": clickhouse.TimeWindow12h,
@@ -858,6 +1594,12 @@ func buildClickHouseInspectionDetails(source *models.Source, tableInfo *clickhou
 	}
 	if tableInfo != nil && tableInfo.Engine == "Distributed" {
 		details = append(details, InspectionDetail{Key: "storage_scope", Label: "Storage totals", Value: "Connected local replica only, not cluster-wide"})
+		if len(tableInfo.EngineParams) >= 3 {
+			details = append(details,
+				InspectionDetail{Key: "cluster", Label: "Cluster", Value: tableInfo.EngineParams[0], Monospace: true},
+				InspectionDetail{Key: "local_table", Label: "Local Table", Value: tableInfo.EngineParams[1] + "." + tableInfo.EngineParams[2], Monospace: true},
+			)
+		}
 	}
 	return details
 }
@@ -958,6 +1700,7 @@ func (p *ClickHouseProvider) GetLogContext(ctx context.Context, source *models.S
 		BeforeOffset:    req.BeforeOffset,
 		AfterOffset:     req.AfterOffset,
 		ExcludeBoundary: req.ExcludeBoundary,
+		Scope:           req.Scope,
 	}, req.QueryTimeout)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching log context for source %d: %w", source.ID, err)
@@ -973,21 +1716,115 @@ func (p *ClickHouseProvider) GetLogContext(ctx context.Context, source *models.S
 }
 
 // buildLogchefQLSchema builds a LogchefQL schema (name -> type) from the
-// source's columns for type-aware SQL generation. Returns nil when no column
-// metadata is available; the translator handles a nil schema gracefully.
+// source's columns for type-aware SQL generation. Virtual columns from
+// extraction rules carry their expression, which the translator filters and
+// selects on in place of a column. Returns nil when no column metadata is
+// available; the translator handles a nil schema gracefully.
 func buildLogchefQLSchema(source *models.Source) *logchefql.Schema {
 	if source == nil || len(source.Columns) == 0 {
 		return nil
 	}
 
-	columns := make([]logchefql.ColumnInfo, len(source.Columns))
-	for i, col := range source.Columns {
-		columns[i] = logchefql.ColumnInfo{
-			Name: col.Name,
-			Type: col.Type,
+	columns := withVirtualColumns(source, source.Columns)
+	schema := &logchefql.Schema{Columns: make([]logchefql.ColumnInfo, len(columns))}
+	for i, col := range columns {
+		schema.Columns[i] = logchefql.ColumnInfo{
+			Name:       col.Name,
+			Type:       col.Type,
+			Expression: col.Expression,
+		}
+	}
+	return schema
+}
+
+// LintQuery checks a LogchefQL or ClickHouse SQL query against the source's
+// table. The table's sort key columns count as indexed. Fetching the table is
+// best-effort: without it only the checks that need no schema run. Templated
+// SQL ({{variables}}) can't be parsed before substitution and is not linted.
+func (p *ClickHouseProvider) LintQuery(ctx context.Context, source *models.Source, req LintRequest) (*LintResult, error) {
+	if source == nil {
+		return nil, fmt.Errorf("source is required")
+	}
+
+	var sortKeys []string
+	if client, err := p.manager.GetConnection(source.ID); err == nil {
+		if tableInfo, err := client.GetTableInfo(ctx, source.Connection.Database, source.Connection.TableName); err == nil {
+			source.Columns = withVirtualColumns(source, tableInfo.Columns)
+			sortKeys = tableInfo.SortKeys
+		}
+	}
+
+	result := &LintResult{Valid: true, Warnings: []logchefql.Diagnostic{}}
+	switch models.NormalizeQueryLanguage(req.Language) {
+	case models.QueryLanguageLogchefQL:
+		opts := logchefql.LintOptions{Schema: buildLogchefQLSchema(source)}
+		if opts.Schema != nil {
+			opts.IndexedColumns = sortKeys
+		}
+		validated := logchefql.Lint(req.Query, opts)
+		result.Valid, result.Error = validated.Valid, validated.Error
+		result.Warnings = append(result.Warnings, validated.Warnings...)
+		if result.Valid && req.MaxLimit > 0 && req.Limit > req.MaxLimit {
+			result.Warnings = append(result.Warnings, logchefql.Diagnostic{
+				Code:    logchefql.WarnLimitTooLarge,
+				Message: fmt.Sprintf("limit %d is above the maximum of %d and will be capped", req.Limit, req.MaxLimit),
+			})
+		}
+	case models.QueryLanguageClickHouseSQL:
+		if strings.Contains(req.Query, "{{") {
+			return result, nil
+		}
+		opts := clickhouse.SQLLintOptions{
+			TimestampField: source.MetaTSField,
+			MaxLimit:       req.MaxLimit,
 		}
+		if len(source.Columns) > 0 {
+			opts.IndexedColumns = sortKeys
+			for _, col := range source.Columns {
+				opts.Columns = append(opts.Columns, col.Name)
+			}
+		}
+		warnings, err := clickhouse.LintSQL(req.Query, opts)
+		if err != nil {
+			result.Valid = false
+			result.Error = &logchefql.ParseError{Code: logchefql.ErrUnexpectedToken, Message: err.Error()}
+			return result, nil
+		}
+		result.Warnings = append(result.Warnings, warnings...)
+	default:
+		return nil, ErrOperationNotSupported
 	}
-	return &logchefql.Schema{Columns: columns}
+	return result, nil
+}
+
+// FormatQuery pretty-prints a ClickHouse SQL query or filter and converts it
+// back to LogchefQL when it is simple enough; see clickhouse.ToLogchefQL.
+// Templated SQL ({{variables}}) can't be parsed before substitution and is
+// returned as-is.
+func (p *ClickHouseProvider) FormatQuery(_ context.Context, source *models.Source, req FormatRequest) (*FormatResult, error) {
+	if source == nil {
+		return nil, fmt.Errorf("source is required")
+	}
+	if strings.Contains(req.Query, "{{") {
+		return &FormatResult{
+			Valid:          true,
+			FormattedQuery: req.Query,
+			Reason:         "templated SQL can't be converted before its variables are filled in",
+		}, nil
+	}
+
+	formatted, err := clickhouse.FormatSQL(req.Query)
+	if err != nil {
+		return &FormatResult{Error: &logchefql.ParseError{Code: logchefql.ErrUnexpectedToken, Message: err.Error()}}, nil
+	}
+	result := &FormatResult{Valid: true, FormattedQuery: formatted}
+	converted, err := clickhouse.ToLogchefQL(req.Query, source.MetaTSField)
+	if err != nil {
+		result.Reason = err.Error()
+		return result, nil
+	}
+	result.Convertible, result.LogchefQL = true, converted
+	return result, nil
 }
 
 // CompileLogchefQL compiles a LogchefQL query into executable ClickHouse SQL.
@@ -1011,7 +1848,16 @@ func (p *ClickHouseProvider) CompileLogchefQL(ctx context.Context, source *model
 	}
 	schema := buildLogchefQLSchema(source)
 
+	_, span := tracing.Start(ctx, "logchefql.translate", trace.WithAttributes(
+		attribute.Int64("logchef.source_id", int64(source.ID)),
+		attribute.Int("logchef.query.length", len(req.Query)),
+	))
 	translateResult := logchefql.Translate(req.Query, schema)
+	if translateResult.Error != nil {
+		tracing.End(span, translateResult.Error)
+	} else {
+		span.End()
+	}
 	compiled := &CompiledLogchefQL{
 		Language:   models.QueryLanguageClickHouseSQL,
 		Valid:      translateResult.Valid,
@@ -1050,3 +1896,110 @@ func (p *ClickHouseProvider) CompileLogchefQL(ctx context.Context, source *model
 
 	return compiled, nil
 }
+
+// conditionFieldValue returns the numeric SQL expression a condition alert
+// aggregates for field. Numeric columns are read as they are; anything else (a
+// String column, a Map key, a JSON path) is parsed with toFloat64OrNull, so
+// values that aren't numbers are skipped. The schema is required: without it
+// a typo or a dotted path would only fail when the alert is evaluated.
+func conditionFieldValue(schema *logchefql.Schema, field string) (string, error) {
+	if schema == nil {
+		return "", fmt.Errorf("source schema is unavailable; can't resolve condition field %q", field)
+	}
+	expr, parseErr := logchefql.FieldExpression(field, schema)
+	if parseErr != nil {
+		return "", fmt.Errorf("invalid condition field: %s", parseErr.Message)
+	}
+	for _, col := range schema.Columns {
+		if col.Name == field {
+			if clickhouse.IsNumericColumnType(col.Type) {
+				return expr, nil
+			}
+			break
+		}
+	}
+	return fmt.Sprintf("toFloat64OrNull(toString(%s))", expr), nil
+}
+
+// BuildConditionAlertQuery compiles a structured alert condition into a
+// ClickHouse aggregate. The filter goes through logchefql.BuildFullQuery with
+// the lookback window ending at req.Now (UTC), and the aggregate is applied
+// over that row query as a subquery; ClickHouse drops the subquery's ORDER BY
+// as redundant under an aggregate. The subquery projects only the aggregated
+// value, so a "| select" pipe in the filter can't drop the field. The field is
+// resolved against the source schema like a LogchefQL select field, so dotted
+// paths read Map keys or JSON.
+// With a baseline the value is instead the percent change of that aggregate
+// against the same window shifted back by the baseline offset.
+func (p *ClickHouseProvider) BuildConditionAlertQuery(ctx context.Context, source *models.Source, req ConditionAlertRequest) (string, error) {
+	if source == nil {
+		return "", fmt.Errorf("source is required")
+	}
+	cond := req.Condition
+	if cond == nil {
+		return "", fmt.Errorf("alert condition is required")
+	}
+	if req.LookbackSeconds <= 0 {
+		return "", fmt.Errorf("lookback_seconds must be greater than zero")
+	}
+
+	// Best-effort schema fetch for type-aware filters, as in CompileLogchefQL.
+	if len(source.Columns) == 0 {
+		if columns, err := p.GetSourceSchema(ctx, source); err == nil {
+			source.Columns = columns
+		}
+	}
+
+	lookback := time.Duration(req.LookbackSeconds) * time.Second
+	offset := cond.Baseline.Offset()
+	if offset > 0 && lookback > offset {
+		return "", fmt.Errorf("lookback must not exceed the %s baseline offset", cond.Baseline)
+	}
+
+	schema := buildLogchefQLSchema(source)
+	aggregate, projection := "count()", "1"
+	if cond.Aggregate != models.AlertAggregateCount {
+		value, err := conditionFieldValue(schema, cond.Field)
+		if err != nil {
+			return "", err
+		}
+		aggregate = fmt.Sprintf("%s(condition_value)", cond.Aggregate)
+		projection = value + " AS condition_value"
+	}
+	// windowValue aggregates the filtered rows of the lookback window ending
+	// at end.
+	windowValue := func(end time.Time) (string, error) {
+		const layout = "2006-01-02 15:04:05"
+		rows, err := logchefql.BuildFullQuery(logchefql.QueryBuildParams{
+			LogchefQL:      cond.Filter,
+			Schema:         schema,
+			TableName:      source.GetFullTableName(),
+			TimestampField: source.MetaTSField,
+			StartTime:      end.Add(-lookback).Format(layout),
+			EndTime:        end.Format(layout),
+			Timezone:       "UTC",
+			Projection:     projection,
+		})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("SELECT %s AS value\nFROM (\n%s\n)", aggregate, strings.TrimSpace(rows)), nil
+	}
+
+	end := req.Now.UTC()
+	current, err := windowValue(end)
+	if err != nil || offset == 0 {
+		return current, err
+	}
+	baseline, err := windowValue(end.Add(-offset))
+	if err != nil {
+		return "", err
+	}
+	// Percent change against the baseline window. The divisor is at least 1
+	// so a baseline of zero still yields a finite value, and an empty window
+	// (NaN avg) counts as no change.
+	return fmt.Sprintf(`WITH
+	toFloat64((%s)) AS current_value,
+	toFloat64((%s)) AS baseline_value
+SELECT ifNotFinite((current_value - baseline_value) * 100 / greatest(abs(baseline_value), 1), 0) AS value`, current, baseline), nil
+}
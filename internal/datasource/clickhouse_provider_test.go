@@ -1,9 +1,14 @@
 package datasource
 
 import (
+	"context"
+	"log/slog"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mr-karan/logchef/internal/clickhouse"
+	"github.com/mr-karan/logchef/pkg/models"
 )
 
 func TestHasLeadingTimestampSortKey(t *testing.T) {
@@ -26,3 +31,168 @@ func TestHasLeadingTimestampSortKey(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildConditionAlertQuery(t *testing.T) {
+	p := NewClickHouseProvider(nil, slog.New(slog.DiscardHandler))
+	source := &models.Source{
+		MetaTSField: "timestamp",
+		Connection:  models.ConnectionInfo{Database: "logs", TableName: "app"},
+		Columns:     []models.ColumnInfo{{Name: "timestamp", Type: "DateTime"}, {Name: "level", Type: "String"}, {Name: "latency_ms", Type: "Float64"}},
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	query, err := p.BuildConditionAlertQuery(context.Background(), source, ConditionAlertRequest{
+		Condition:       &models.AlertCondition{Filter: `level="error"`, Aggregate: models.AlertAggregateAvg, Field: "latency_ms"},
+		LookbackSeconds: 300,
+		Now:             now,
+	})
+	if err != nil {
+		t.Fatalf("BuildConditionAlertQuery: %v", err)
+	}
+	for _, want := range []string{
+		"SELECT avg(condition_value) AS value",
+		"SELECT `latency_ms` AS condition_value",
+		"FROM logs.app",
+		"BETWEEN toDateTime('2026-03-01 11:55:00', 'UTC') AND toDateTime('2026-03-01 12:00:00', 'UTC')",
+		"`level` = 'error'",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query missing %q:\n%s", want, query)
+		}
+	}
+
+	if _, err := p.BuildConditionAlertQuery(context.Background(), source, ConditionAlertRequest{
+		Condition:       &models.AlertCondition{Filter: `level=`, Aggregate: models.AlertAggregateCount},
+		LookbackSeconds: 300,
+		Now:             now,
+	}); err == nil {
+		t.Fatal("expected an error for an invalid filter")
+	}
+}
+
+func TestBuildConditionAlertQueryField(t *testing.T) {
+	p := NewClickHouseProvider(nil, slog.New(slog.DiscardHandler))
+	source := &models.Source{
+		MetaTSField: "timestamp",
+		Connection:  models.ConnectionInfo{Database: "logs", TableName: "app"},
+		Columns: []models.ColumnInfo{
+			{Name: "timestamp", Type: "DateTime"},
+			{Name: "level", Type: "String"},
+			{Name: "latency_ms", Type: "Float64"},
+			{Name: "attrs", Type: "Map(String, String)"},
+		},
+	}
+	build := func(filter, field string) (string, error) {
+		return p.BuildConditionAlertQuery(context.Background(), source, ConditionAlertRequest{
+			Condition:       &models.AlertCondition{Filter: filter, Aggregate: models.AlertAggregateMax, Field: field},
+			LookbackSeconds: 300,
+			Now:             time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		})
+	}
+
+	// A select pipe without the field still aggregates it: the inner query
+	// projects only the value.
+	query, err := build(`level="error" | select level`, "latency_ms")
+	if err != nil {
+		t.Fatalf("BuildConditionAlertQuery(select pipe): %v", err)
+	}
+	if !strings.Contains(query, "SELECT `latency_ms` AS condition_value\nFROM logs.app") {
+		t.Errorf("inner query doesn't project the field:\n%s", query)
+	}
+
+	// A dotted path reads the Map key, parsed as a number.
+	query, err = build(`level="error"`, "attrs.duration")
+	if err != nil {
+		t.Fatalf("BuildConditionAlertQuery(map path): %v", err)
+	}
+	if !strings.Contains(query, "SELECT toFloat64OrNull(toString(`attrs`['duration'])) AS condition_value") {
+		t.Errorf("map path not resolved:\n%s", query)
+	}
+
+	// Without a Map column to fall back on, unknown fields are rejected, so
+	// the alert fails when saved rather than when evaluated.
+	source.Columns = source.Columns[:3]
+	for _, field := range []string{"latency", "attrs.duration"} {
+		if _, err := build(`level="error"`, field); err == nil {
+			t.Errorf("BuildConditionAlertQuery(%q) succeeded, want an unknown field error", field)
+		}
+	}
+}
+
+func TestBuildConditionAlertQueryBaseline(t *testing.T) {
+	p := NewClickHouseProvider(nil, slog.New(slog.DiscardHandler))
+	source := &models.Source{
+		MetaTSField: "timestamp",
+		Connection:  models.ConnectionInfo{Database: "logs", TableName: "app"},
+		Columns:     []models.ColumnInfo{{Name: "timestamp", Type: "DateTime"}, {Name: "level", Type: "String"}},
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cond := &models.AlertCondition{Filter: `level="error"`, Aggregate: models.AlertAggregateCount, Baseline: models.AlertBaselinePreviousDay}
+
+	query, err := p.BuildConditionAlertQuery(context.Background(), source, ConditionAlertRequest{
+		Condition:       cond,
+		LookbackSeconds: 600,
+		Now:             now,
+	})
+	if err != nil {
+		t.Fatalf("BuildConditionAlertQuery: %v", err)
+	}
+	for _, want := range []string{
+		"BETWEEN toDateTime('2026-03-01 11:50:00', 'UTC') AND toDateTime('2026-03-01 12:00:00', 'UTC')",
+		"BETWEEN toDateTime('2026-02-28 11:50:00', 'UTC') AND toDateTime('2026-02-28 12:00:00', 'UTC')",
+		"(current_value - baseline_value) * 100 / greatest(abs(baseline_value), 1)",
+		"AS value",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query missing %q:\n%s", want, query)
+		}
+	}
+
+	if _, err := p.BuildConditionAlertQuery(context.Background(), source, ConditionAlertRequest{
+		Condition:       cond,
+		LookbackSeconds: 2 * 86400,
+		Now:             now,
+	}); err == nil {
+		t.Fatal("expected an error for a lookback longer than the baseline offset")
+	}
+}
+
+func TestTableTargetAndExtraction(t *testing.T) {
+	source := &models.Source{Connection: models.ConnectionInfo{Database: "logs", TableName: "app"}}
+
+	if got := storageTarget(source, &clickhouse.TableInfo{Engine: "MergeTree"}); got != (clickhouse.TableTarget{Database: "logs", Table: "app"}) {
+		t.Fatalf("MergeTree target = %+v", got)
+	}
+	dist := &clickhouse.TableInfo{Engine: "Distributed", EngineParams: []string{"main", "logs", "app_local", "rand()"}}
+	if got := storageTarget(source, dist); got != (clickhouse.TableTarget{Database: "logs", Table: "app_local", Cluster: "main"}) {
+		t.Fatalf("Distributed target = %+v", got)
+	}
+
+	create := "CREATE TABLE logs.app (`timestamp` DateTime) ENGINE = MergeTree ORDER BY timestamp TTL toDateTime(timestamp) + toIntervalDay(30) SETTINGS index_granularity = 8192"
+	if got := extractTTLFromCreateQuery(create); got != "toDateTime(timestamp) + toIntervalDay(30)" {
+		t.Fatalf("extractTTLFromCreateQuery = %q", got)
+	}
+	if got := extractTTLFromCreateQuery("CREATE TABLE logs.app (`timestamp` DateTime) ENGINE = MergeTree ORDER BY timestamp"); got != "" {
+		t.Fatalf("extractTTLFromCreateQuery without TTL = %q", got)
+	}
+}
+
+func TestApplyTTLRejectsZeroDays(t *testing.T) {
+	p := &ClickHouseProvider{log: slog.New(slog.DiscardHandler)}
+	_, err := p.applyTTL(context.Background(), nil, &models.Source{}, 0, true)
+	if ve, ok := err.(*ValidationError); !ok || ve.Field != "ttl_days" {
+		t.Fatalf("applyTTL(0) err = %v, want ttl_days validation error", err)
+	}
+}
+
+func TestDropPartitionsRequiresAutoCreatedTable(t *testing.T) {
+	p := &ClickHouseProvider{log: slog.New(slog.DiscardHandler)}
+	_, err := p.DropPartitions(context.Background(), &models.Source{}, &models.DropPartitionsRequest{PartitionIDs: []string{"20260301"}})
+	if ve, ok := err.(*ValidationError); !ok || ve.Field != "source" {
+		t.Fatalf("DropPartitions on an existing table err = %v, want source validation error", err)
+	}
+	_, err = p.DropPartitions(context.Background(), &models.Source{MetaIsAutoCreated: true}, &models.DropPartitionsRequest{})
+	if ve, ok := err.(*ValidationError); !ok || ve.Field != "partition_ids" {
+		t.Fatalf("DropPartitions without ids err = %v, want partition_ids validation error", err)
+	}
+}
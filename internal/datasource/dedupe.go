@@ -0,0 +1,141 @@
+package datasource
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// Dedupe collapses runs of consecutive identical rows after the provider has
+// read them, so it works the same for every source type. Only adjacent rows
+// are compared: the query's ORDER BY decides which repeats collapse.
+
+// rowDeduper folds consecutive rows with equal keys into the first of them,
+// counting the repeats in models.DedupeCountColumn.
+type rowDeduper struct {
+	fields   []string
+	skip     string
+	prev     map[string]any
+	prevKey  string
+	repeats  uint64
+	returned int
+	folded   int
+}
+
+// newRowDeduper returns a deduper comparing opts.Fields, or every column but
+// the source's timestamp field when opts names none. Fields missing from a row
+// compare as null.
+func newRowDeduper(opts *models.DedupeOptions, source *models.Source) *rowDeduper {
+	return &rowDeduper{fields: opts.Fields, skip: source.MetaTSField}
+}
+
+// key encodes the values row is compared on.
+func (d *rowDeduper) key(row map[string]any) string {
+	var values any
+	if len(d.fields) > 0 {
+		picked := make([]any, len(d.fields))
+		for i, field := range d.fields {
+			picked[i] = row[field]
+		}
+		values = picked
+	} else {
+		rest := make(map[string]any, len(row))
+		for name, value := range row {
+			if name != d.skip {
+				rest[name] = value
+			}
+		}
+		values = rest
+	}
+	// encoding/json sorts map keys, so equal rows encode equally.
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		// Values that don't encode never match, so the row is kept.
+		return ""
+	}
+	return string(encoded)
+}
+
+// add takes the next row, returning the previous run's first row once row
+// starts a new run.
+func (d *rowDeduper) add(row map[string]any) map[string]any {
+	key := d.key(row)
+	if d.prev != nil && key != "" && key == d.prevKey {
+		d.repeats++
+		d.folded++
+		return nil
+	}
+	done := d.flush()
+	d.prev, d.prevKey, d.repeats = row, key, 1
+	return done
+}
+
+// flush returns the pending run's first row with its repeat count, if any.
+func (d *rowDeduper) flush() map[string]any {
+	if d.prev == nil {
+		return nil
+	}
+	row := d.prev
+	row[models.DedupeCountColumn] = d.repeats
+	d.prev = nil
+	d.returned++
+	return row
+}
+
+// stats returns stats updated for the collapsed rows.
+func (d *rowDeduper) stats(stats models.QueryStats) models.QueryStats {
+	stats.RowsReturned = d.returned
+	stats.DedupedRows = d.folded
+	return stats
+}
+
+// dedupeColumns returns columns with the repeat count column added.
+func dedupeColumns(columns []models.ColumnInfo) []models.ColumnInfo {
+	return append(slices.Clone(columns), models.ColumnInfo{Name: models.DedupeCountColumn, Type: "UInt64"})
+}
+
+// dedupeResult collapses result's rows in place.
+func dedupeResult(result *models.QueryResult, opts *models.DedupeOptions, source *models.Source) {
+	d := newRowDeduper(opts, source)
+	logs := make([]map[string]any, 0, len(result.Logs))
+	for _, row := range result.Logs {
+		if done := d.add(row); done != nil {
+			logs = append(logs, done)
+		}
+	}
+	if done := d.flush(); done != nil {
+		logs = append(logs, done)
+	}
+	result.Logs = logs
+	result.Columns = dedupeColumns(result.Columns)
+	result.Stats = d.stats(result.Stats)
+}
+
+// dedupeStreamWriter collapses a streamed result's rows before they reach
+// the wrapped writer, holding back one row until the next one shows whether
+// it repeats.
+type dedupeStreamWriter struct {
+	StreamWriter
+	deduper *rowDeduper
+}
+
+func (w *dedupeStreamWriter) Begin(columns []models.ColumnInfo) error {
+	return w.StreamWriter.Begin(dedupeColumns(columns))
+}
+
+func (w *dedupeStreamWriter) WriteRow(row map[string]any) error {
+	if done := w.deduper.add(row); done != nil {
+		return w.StreamWriter.WriteRow(done)
+	}
+	return nil
+}
+
+func (w *dedupeStreamWriter) Finish(stats models.QueryStats) error {
+	if done := w.deduper.flush(); done != nil {
+		if err := w.StreamWriter.WriteRow(done); err != nil {
+			return err
+		}
+	}
+	return w.StreamWriter.Finish(w.deduper.stats(stats))
+}
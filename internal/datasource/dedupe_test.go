@@ -0,0 +1,112 @@
+package datasource
+
+import (
+	"testing"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+type recordingStreamWriter struct {
+	columns []models.ColumnInfo
+	rows    []map[string]any
+	stats   models.QueryStats
+}
+
+func (w *recordingStreamWriter) SetWarnings([]models.QueryWarning) {}
+
+func (w *recordingStreamWriter) Begin(columns []models.ColumnInfo) error {
+	w.columns = columns
+	return nil
+}
+
+func (w *recordingStreamWriter) WriteRow(row map[string]any) error {
+	w.rows = append(w.rows, row)
+	return nil
+}
+
+func (w *recordingStreamWriter) Finish(stats models.QueryStats) error {
+	w.stats = stats
+	return nil
+}
+
+func dedupeTestRows() []map[string]any {
+	return []map[string]any{
+		{"ts": "10:00:01", "level": "error", "msg": "timeout"},
+		{"ts": "10:00:02", "level": "error", "msg": "timeout"},
+		{"ts": "10:00:03", "level": "warn", "msg": "timeout"},
+		{"ts": "10:00:04", "level": "error", "msg": "timeout"},
+		{"ts": "10:00:05", "level": "error", "msg": "timeout"},
+		{"ts": "10:00:06", "level": "error", "msg": "timeout"},
+	}
+}
+
+func TestDedupeResult(t *testing.T) {
+	source := &models.Source{MetaTSField: "ts"}
+	tests := []struct {
+		name   string
+		fields []string
+		want   []uint64
+		wantTS []string
+	}{
+		{name: "every column but the timestamp", want: []uint64{2, 1, 3}, wantTS: []string{"10:00:01", "10:00:03", "10:00:04"}},
+		{name: "selected fields", fields: []string{"msg"}, want: []uint64{6}, wantTS: []string{"10:00:01"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &models.QueryResult{
+				Logs:    dedupeTestRows(),
+				Columns: []models.ColumnInfo{{Name: "ts"}, {Name: "level"}, {Name: "msg"}},
+				Stats:   models.QueryStats{RowsReturned: 6},
+			}
+			dedupeResult(result, &models.DedupeOptions{Fields: tt.fields}, source)
+
+			if len(result.Logs) != len(tt.want) {
+				t.Fatalf("got %d rows, want %d: %v", len(result.Logs), len(tt.want), result.Logs)
+			}
+			for i, row := range result.Logs {
+				if row[models.DedupeCountColumn] != tt.want[i] || row["ts"] != tt.wantTS[i] {
+					t.Errorf("row %d = %v, want ts %s repeated %d times", i, row, tt.wantTS[i], tt.want[i])
+				}
+			}
+			if got := result.Columns[len(result.Columns)-1].Name; got != models.DedupeCountColumn {
+				t.Errorf("last column = %q, want %q", got, models.DedupeCountColumn)
+			}
+			if result.Stats.RowsReturned != len(tt.want) || result.Stats.DedupedRows != 6-len(tt.want) {
+				t.Errorf("stats = %+v", result.Stats)
+			}
+		})
+	}
+}
+
+func TestDedupeStreamWriter(t *testing.T) {
+	inner := &recordingStreamWriter{}
+	w := &dedupeStreamWriter{StreamWriter: inner, deduper: newRowDeduper(&models.DedupeOptions{}, &models.Source{MetaTSField: "ts"})}
+
+	if err := w.Begin([]models.ColumnInfo{{Name: "ts"}, {Name: "level"}, {Name: "msg"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range dedupeTestRows() {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Finish(models.QueryStats{RowsReturned: 6}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(inner.columns) != 4 || inner.columns[3].Name != models.DedupeCountColumn {
+		t.Errorf("columns = %v", inner.columns)
+	}
+	want := []uint64{2, 1, 3}
+	if len(inner.rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(inner.rows), len(want))
+	}
+	for i, row := range inner.rows {
+		if row[models.DedupeCountColumn] != want[i] {
+			t.Errorf("row %d repeat count = %v, want %d", i, row[models.DedupeCountColumn], want[i])
+		}
+	}
+	if inner.stats.RowsReturned != 3 || inner.stats.DedupedRows != 3 {
+		t.Errorf("stats = %+v", inner.stats)
+	}
+}
@@ -17,6 +17,24 @@ type FieldValuesResult struct {
 	IsLowCardinality bool             `json:"is_low_cardinality"`
 	Values           []FieldValueInfo `json:"values"`
 	TotalDistinct    int64            `json:"total_distinct"`
+	// NumericBuckets summarize a numeric field's spread and DayCounts a date
+	// field's values per day, for sidebars to chart instead of listing
+	// values.
+	NumericBuckets []NumericBucket `json:"numeric_buckets,omitempty"`
+	DayCounts      []DayCount      `json:"day_counts,omitempty"`
+}
+
+// NumericBucket counts a numeric field's values between Min and Max.
+type NumericBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int64   `json:"count"`
+}
+
+// DayCount counts a date field's values on Day (YYYY-MM-DD).
+type DayCount struct {
+	Day   string `json:"day"`
+	Count int64  `json:"count"`
 }
 
 type FieldValuesRequest struct {
@@ -30,6 +48,12 @@ type FieldValuesRequest struct {
 	Limit          int
 	Timeout        *int
 	QueryText      string
+	// RowFilter is the team's row filter for the source; see
+	// QueryRequest.RowFilter.
+	RowFilter string
+	// MaskedColumns are the source's columns masked for the caller; see
+	// QueryRequest.MaskedColumns.
+	MaskedColumns models.MaskedColumns
 }
 
 type AllFieldValuesRequest struct {
@@ -41,6 +65,100 @@ type AllFieldValuesRequest struct {
 	Limit          int
 	Timeout        *int
 	QueryText      string
+	// RowFilter is the team's row filter for the source; see
+	// QueryRequest.RowFilter.
+	RowFilter string
+	// MaskedColumns are the source's columns masked for the caller; see
+	// QueryRequest.MaskedColumns.
+	MaskedColumns models.MaskedColumns
 }
 
 type AllFieldValuesResult map[string]*FieldValuesResult
+
+// FieldStatsRequest asks for statistics over one numeric or datetime field.
+type FieldStatsRequest struct {
+	FieldName      string
+	FieldType      string
+	Language       models.QueryLanguage
+	TimestampField string
+	StartTime      time.Time
+	EndTime        time.Time
+	Timezone       string
+	Timeout        *int
+	QueryText      string
+}
+
+// FieldStatsResult summarises a field's values. Kind is "numeric" or
+// "datetime"; datetime statistics are Unix milliseconds. Value statistics are
+// nil when the range holds no non-null value.
+type FieldStatsResult struct {
+	FieldName   string   `json:"field_name"`
+	FieldType   string   `json:"field_type"`
+	Kind        string   `json:"kind"`
+	Count       int64    `json:"count"`
+	NullCount   int64    `json:"null_count"`
+	NullPercent float64  `json:"null_percent"`
+	Min         *float64 `json:"min"`
+	Max         *float64 `json:"max"`
+	Avg         *float64 `json:"avg"`
+	P50         *float64 `json:"p50"`
+	P95         *float64 `json:"p95"`
+	P99         *float64 `json:"p99"`
+}
+
+// JSONFieldsRequest asks for the JSON keys found in a sample of recent rows.
+// Columns defaults to the source's body column.
+type JSONFieldsRequest struct {
+	Columns        []string
+	Language       models.QueryLanguage
+	TimestampField string
+	StartTime      time.Time
+	EndTime        time.Time
+	Timezone       string
+	SampleSize     int
+	Timeout        *int
+	QueryText      string
+}
+
+// LogPatternsRequest asks for the most frequent message templates of a
+// String field, defaulting to the source's body column.
+type LogPatternsRequest struct {
+	FieldName      string
+	Language       models.QueryLanguage
+	TimestampField string
+	StartTime      time.Time
+	EndTime        time.Time
+	Timezone       string
+	Limit          int
+	Baseline       bool
+	Timeout        *int
+	QueryText      string
+}
+
+// VolumeAnomalyRequest compares the window ending at End with the same window
+// Baseline's offset earlier. ThresholdPercent and MinRows default to
+// models.DefaultVolumeAnomalyThreshold and models.DefaultVolumeAnomalyMinRows.
+type VolumeAnomalyRequest struct {
+	Window           time.Duration
+	End              time.Time
+	Baseline         models.AlertBaseline
+	ThresholdPercent float64
+	MinRows          int64
+	Timeout          *int
+	QueryText        string
+}
+
+// RangeComparisonRequest diffs the value distributions of Fields between the
+// baseline and target ranges. Without Fields, the source's LowCardinality and
+// Enum columns are compared.
+type RangeComparisonRequest struct {
+	Fields           []string
+	BaselineStart    time.Time
+	BaselineEnd      time.Time
+	TargetStart      time.Time
+	TargetEnd        time.Time
+	Limit            int
+	ThresholdPercent float64
+	Timeout          *int
+	QueryText        string
+}
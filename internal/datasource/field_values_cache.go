@@ -0,0 +1,130 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// The field sidebar asks for every field's values each time it refreshes,
+// usually over a relative range ("last 15 minutes") whose ends move with the
+// clock. Results are cached per source, filter and time bucket: for
+// fieldValuesFreshTTL they are served as is, and until fieldValuesStaleTTL
+// they are still served while a background fill replaces them.
+const (
+	fieldValuesFreshTTL = 30 * time.Second
+	fieldValuesStaleTTL = 5 * time.Minute
+	// fieldValuesTimeBucket is how finely a long range is keyed, so refreshes
+	// within the same minute share an entry. Shorter ranges are keyed at
+	// 1/fieldValuesSpanBuckets of their span, and to the exact bounds once
+	// that drops under a second.
+	fieldValuesTimeBucket  = time.Minute
+	fieldValuesSpanBuckets = 10
+	// fieldValuesFillTimeout bounds a shared fill, which runs on its own
+	// deadline so one caller giving up doesn't cancel it for every waiter.
+	fieldValuesFillTimeout = 15 * time.Second
+	fieldValuesMaxEntries  = 256
+)
+
+type fieldValuesCacheEntry struct {
+	created time.Time
+	value   AllFieldValuesResult
+}
+
+// fieldValuesCacheKey identifies req's result for source. The query text is
+// part of it, so changing the sidebar's filter misses the cache instead of
+// serving values for the old filter; source.UpdatedAt drops every entry of a
+// reconfigured source.
+func fieldValuesCacheKey(source *models.Source, req AllFieldValuesRequest) string {
+	bucket := min(fieldValuesTimeBucket, req.EndTime.Sub(req.StartTime)/fieldValuesSpanBuckets)
+	if bucket < time.Second {
+		bucket = 0 // Truncate(0) keeps the exact bounds.
+	}
+	return strings.Join([]string{
+		fmt.Sprint(source.ID),
+		source.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		req.StartTime.UTC().Truncate(bucket).Format(time.RFC3339Nano),
+		req.EndTime.UTC().Truncate(bucket).Format(time.RFC3339Nano),
+		req.Timezone,
+		fmt.Sprint(req.Limit),
+		req.TimestampField,
+		string(req.Language),
+		req.QueryText,
+		req.RowFilter,
+		fmt.Sprint(req.MaskedColumns),
+	}, "\x00")
+}
+
+// cachedAllFieldValues returns req's field values from the cache when fresh
+// enough, filling it from provider otherwise. A stale entry is returned
+// straight away and refreshed in the background.
+func (s *Service) cachedAllFieldValues(ctx context.Context, source *models.Source, provider Provider, req AllFieldValuesRequest) (AllFieldValuesResult, error) {
+	key := fieldValuesCacheKey(source, req)
+	fill := func() (any, error) {
+		fillCtx, cancel := context.WithTimeout(context.Background(), fieldValuesFillTimeout)
+		defer cancel()
+		value, err := provider.GetAllFieldValues(fillCtx, source, req)
+		if err != nil {
+			return nil, err
+		}
+		s.storeFieldValues(key, value)
+		return value, nil
+	}
+
+	s.fieldValuesMu.Lock()
+	entry, ok := s.fieldValues[key]
+	s.fieldValuesMu.Unlock()
+	if ok {
+		age := time.Since(entry.created)
+		if age < fieldValuesFreshTTL {
+			return entry.value, nil
+		}
+		if age < fieldValuesStaleTTL {
+			//nolint:contextcheck // The background fill outlives the request by design.
+			s.fieldValuesFill.DoChan(key, fill)
+			return entry.value, nil
+		}
+	}
+
+	//nolint:contextcheck // A shared fill has its own deadline so one caller cannot cancel every waiter.
+	result := s.fieldValuesFill.DoChan(key, fill)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-result:
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		return r.Val.(AllFieldValuesResult), nil
+	}
+}
+
+// storeFieldValues caches value under key, first dropping expired entries
+// and, when the cache is still full, the oldest one.
+func (s *Service) storeFieldValues(key string, value AllFieldValuesResult) {
+	s.fieldValuesMu.Lock()
+	defer s.fieldValuesMu.Unlock()
+	if s.fieldValues == nil {
+		s.fieldValues = make(map[string]fieldValuesCacheEntry)
+	}
+	if len(s.fieldValues) >= fieldValuesMaxEntries {
+		var oldestKey string
+		var oldest time.Time
+		for k, entry := range s.fieldValues {
+			if time.Since(entry.created) >= fieldValuesStaleTTL {
+				delete(s.fieldValues, k)
+				continue
+			}
+			if oldestKey == "" || entry.created.Before(oldest) {
+				oldestKey, oldest = k, entry.created
+			}
+		}
+		if len(s.fieldValues) >= fieldValuesMaxEntries {
+			delete(s.fieldValues, oldestKey)
+		}
+	}
+	s.fieldValues[key] = fieldValuesCacheEntry{created: time.Now(), value: value}
+}
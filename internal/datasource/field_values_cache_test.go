@@ -0,0 +1,101 @@
+package datasource
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+type countingFieldValuesProvider struct {
+	Provider
+	calls atomic.Int32
+}
+
+func (p *countingFieldValuesProvider) GetAllFieldValues(_ context.Context, _ *models.Source, req AllFieldValuesRequest) (AllFieldValuesResult, error) {
+	n := p.calls.Add(1)
+	return AllFieldValuesResult{"level": {FieldName: "level", TotalDistinct: int64(n), Values: []FieldValueInfo{{Value: req.QueryText}}}}, nil
+}
+
+func TestCachedAllFieldValues(t *testing.T) {
+	s := &Service{}
+	provider := &countingFieldValuesProvider{}
+	source := &models.Source{ID: 7}
+	start := time.Date(2026, 3, 1, 10, 0, 5, 0, time.UTC)
+	req := AllFieldValuesRequest{StartTime: start, EndTime: start.Add(15 * time.Minute), QueryText: `level="error"`}
+
+	get := func(req AllFieldValuesRequest) AllFieldValuesResult {
+		t.Helper()
+		result, err := s.cachedAllFieldValues(context.Background(), source, provider, req)
+		if err != nil {
+			t.Fatalf("cachedAllFieldValues() error = %v", err)
+		}
+		return result
+	}
+
+	get(req)
+	// The same range shifted within its minute shares the entry.
+	shifted := req
+	shifted.StartTime = req.StartTime.Add(20 * time.Second)
+	shifted.EndTime = req.EndTime.Add(20 * time.Second)
+	get(shifted)
+	if got := provider.calls.Load(); got != 1 {
+		t.Fatalf("provider called %d times for a cached range, want 1", got)
+	}
+
+	// Short ranges are keyed more finely, so two inside one minute don't
+	// share an entry, while a sub-second shift of the same one still does.
+	short := req
+	short.StartTime = start.Add(10 * time.Second)
+	short.EndTime = start.Add(40 * time.Second)
+	later := short
+	later.StartTime = start.Add(25 * time.Second)
+	later.EndTime = start.Add(55 * time.Second)
+	get(short)
+	get(later)
+	if got := provider.calls.Load(); got != 3 {
+		t.Fatalf("provider called %d times for two sub-minute ranges, want 3", got)
+	}
+	nudged := short
+	nudged.StartTime = short.StartTime.Add(500 * time.Millisecond)
+	nudged.EndTime = short.EndTime.Add(500 * time.Millisecond)
+	get(nudged)
+	if got := provider.calls.Load(); got != 3 {
+		t.Fatalf("provider called %d times for a nudged short range, want 3", got)
+	}
+
+	filtered := req
+	filtered.QueryText = `level="warn"`
+	if got := get(filtered)["level"].Values[0].Value; got != `level="warn"` {
+		t.Errorf("values for a changed filter = %q, want the new filter's", got)
+	}
+	if got := provider.calls.Load(); got != 4 {
+		t.Fatalf("provider called %d times after the filter changed, want 4", got)
+	}
+
+	// A stale entry is served while it is refreshed in the background.
+	key := fieldValuesCacheKey(source, req)
+	s.fieldValuesMu.Lock()
+	entry := s.fieldValues[key]
+	entry.created = time.Now().Add(-fieldValuesFreshTTL)
+	s.fieldValues[key] = entry
+	s.fieldValuesMu.Unlock()
+	if got := get(req)["level"].TotalDistinct; got != 1 {
+		t.Errorf("stale read returned fill %d, want the cached fill 1", got)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.fieldValuesMu.Lock()
+		refreshed := s.fieldValues[key].value["level"].TotalDistinct == 5
+		s.fieldValuesMu.Unlock()
+		if refreshed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stale entry was not refreshed in the background")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,289 @@
+package datasource
+
+// Background health probing. Every source is checked on an interval and its
+// recent results kept in memory, so source listings read cached status
+// instead of checking each source per request. After each cycle an optional
+// HealthObserver sees every source's probe and query outcomes. A probe whose
+// status differs from the source's previous one publishes
+// source.health_changed.
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/events"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+const (
+	// DefaultHealthProbeInterval is how often sources are probed when
+	// StartHealthProbes is given no interval.
+	DefaultHealthProbeInterval = 30 * time.Second
+	// healthProbeTimeout bounds one source's probe.
+	healthProbeTimeout = 5 * time.Second
+	// healthProbeConcurrency caps the sources probed at once.
+	healthProbeConcurrency = 8
+	// healthHistorySize is the probes kept per source: an hour at the
+	// default interval.
+	healthHistorySize = 120
+	// healthStaleAfter is how many intervals a cached result stays usable,
+	// so one slow probe cycle doesn't send listings back to live checks.
+	healthStaleAfter = 3
+)
+
+// QueryOutcomes counts a source's queries that finished during a probe cycle
+// and how many of them failed.
+type QueryOutcomes struct {
+	Total  int
+	Failed int
+}
+
+// HealthObserver is told each source's probe result and query outcomes after
+// every probe cycle, e.g. to raise source degradation alerts. It is called
+// from the probe loop, one source at a time.
+type HealthObserver interface {
+	ObserveSource(ctx context.Context, source *models.Source, health models.SourceHealth, queries QueryOutcomes)
+}
+
+// healthProbes holds the prober's state. latest, history and queries are
+// keyed by source; interval is zero until probing starts.
+type healthProbes struct {
+	mu       sync.RWMutex
+	interval time.Duration
+	latest   map[models.SourceID]models.SourceHealth
+	history  map[models.SourceID][]models.HealthCheck
+	queries  map[models.SourceID]QueryOutcomes
+	observer HealthObserver
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// StartHealthProbes checks every source now and then every interval until
+// StopHealthProbes is called or ctx ends. Zero uses
+// DefaultHealthProbeInterval.
+func (s *Service) StartHealthProbes(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultHealthProbeInterval
+	}
+	s.health.mu.Lock()
+	s.health.interval = interval
+	s.health.stop = make(chan struct{})
+	stop := s.health.stop
+	s.health.mu.Unlock()
+	s.log.Debug("starting source health probes", "interval", interval)
+
+	s.health.wg.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		s.probeAllSources(ctx)
+		for {
+			select {
+			case <-ticker.C:
+				s.probeAllSources(ctx)
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// SetHealthObserver sets the observer told of every probe cycle; nil removes
+// it.
+func (s *Service) SetHealthObserver(observer HealthObserver) {
+	s.health.mu.Lock()
+	s.health.observer = observer
+	s.health.mu.Unlock()
+}
+
+// StopHealthProbes stops the probe loop and waits for a running cycle to end.
+func (s *Service) StopHealthProbes() {
+	s.health.mu.Lock()
+	stop := s.health.stop
+	s.health.stop = nil
+	s.health.interval = 0
+	s.health.mu.Unlock()
+	if stop != nil {
+		close(stop)
+	}
+	s.health.wg.Wait()
+}
+
+func (s *Service) probeAllSources(ctx context.Context) {
+	sources, err := s.db.ListSources(ctx)
+	if err != nil {
+		s.log.Error("failed to list sources for health probes", "error", err)
+		return
+	}
+
+	live := make(map[models.SourceID]bool, len(sources))
+	results := make([]models.SourceHealth, len(sources))
+	slots := make(chan struct{}, healthProbeConcurrency)
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		if source == nil {
+			continue
+		}
+		live[source.ID] = true
+		slots <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			results[i] = s.probeSource(ctx, source)
+		})
+	}
+	wg.Wait()
+
+	// Forget deleted sources and take this cycle's query counts.
+	s.health.mu.Lock()
+	for id := range s.health.latest {
+		if !live[id] {
+			delete(s.health.latest, id)
+			delete(s.health.history, id)
+		}
+	}
+	queries := s.health.queries
+	s.health.queries = nil
+	observer := s.health.observer
+	s.health.mu.Unlock()
+
+	if observer == nil {
+		return
+	}
+	for i, source := range sources {
+		if source != nil {
+			observer.ObserveSource(ctx, source, results[i], queries[source.ID])
+		}
+	}
+}
+
+// recordQueryOutcome counts a finished query towards its source's outcomes
+// for the current probe cycle. Queries rejected as invalid or cancelled by
+// the caller say nothing about the source and are not counted.
+func (s *Service) recordQueryOutcome(sourceID models.SourceID, err error) {
+	if err != nil && (IsValidationError(err) || errors.Is(err, context.Canceled)) {
+		return
+	}
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+	if s.health.interval == 0 {
+		return
+	}
+	if s.health.queries == nil {
+		s.health.queries = make(map[models.SourceID]QueryOutcomes)
+	}
+	outcomes := s.health.queries[sourceID]
+	outcomes.Total++
+	if err != nil {
+		outcomes.Failed++
+	}
+	s.health.queries[sourceID] = outcomes
+}
+
+// probeSource checks source's connection, records the result and returns it.
+// Whether the source is healthy comes from the live check; the provider's
+// own health report adds the error detail and capabilities.
+func (s *Service) probeSource(ctx context.Context, source *models.Source) models.SourceHealth {
+	probeCtx, cancel := context.WithTimeout(ctx, healthProbeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	health := models.SourceHealth{SourceID: source.ID, Status: models.HealthStatusUnhealthy}
+	provider, err := s.ProviderForSource(source)
+	if err != nil {
+		health.Error = err.Error()
+	} else {
+		connected := provider.CheckSourceConnectionStatus(probeCtx, source)
+		latency := time.Since(start)
+		health = provider.GetSourceHealth(probeCtx, source.ID)
+		health.LatencyMs = latency.Milliseconds()
+		switch {
+		case connected:
+			health.Status, health.Error, health.ErrorKind = models.HealthStatusHealthy, "", ""
+		case health.Status == models.HealthStatusHealthy:
+			health.Status, health.Error = models.HealthStatusUnhealthy, "connection check failed"
+		}
+	}
+	health.LastChecked = start
+
+	if previous := s.recordHealth(health); previous != "" && previous != health.Status {
+		events.Publish(models.EventSourceHealthChanged, models.SourceHealthEventData{
+			SourceID:       source.ID,
+			Name:           source.Name,
+			Status:         health.Status,
+			PreviousStatus: previous,
+			Error:          health.Error,
+		})
+	}
+	return health
+}
+
+// recordHealth stores a probe result and returns the status of the one before
+// it, empty when the source has none.
+func (s *Service) recordHealth(health models.SourceHealth) (previous models.HealthStatus) {
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+	if s.health.latest == nil {
+		s.health.latest = make(map[models.SourceID]models.SourceHealth)
+		s.health.history = make(map[models.SourceID][]models.HealthCheck)
+	}
+	previous = s.health.latest[health.SourceID].Status
+	s.health.latest[health.SourceID] = health
+	checks := append(s.health.history[health.SourceID], models.HealthCheck{
+		CheckedAt: health.LastChecked,
+		Status:    health.Status,
+		LatencyMs: health.LatencyMs,
+		Error:     health.Error,
+	})
+	if len(checks) > healthHistorySize {
+		checks = checks[len(checks)-healthHistorySize:]
+	}
+	s.health.history[health.SourceID] = checks
+	return previous
+}
+
+// forgetHealth drops a source's probe results, after its connection
+// settings change or it is deleted.
+func (s *Service) forgetHealth(sourceID models.SourceID) {
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+	delete(s.health.latest, sourceID)
+	delete(s.health.history, sourceID)
+}
+
+// cachedHealth returns the source's latest probe result while probing is
+// running and the result is recent enough to trust.
+func (s *Service) cachedHealth(sourceID models.SourceID) (models.SourceHealth, bool) {
+	s.health.mu.RLock()
+	defer s.health.mu.RUnlock()
+	health, ok := s.health.latest[sourceID]
+	if !ok || s.health.interval == 0 || time.Since(health.LastChecked) > healthStaleAfter*s.health.interval {
+		return models.SourceHealth{}, false
+	}
+	return health, true
+}
+
+// SourceConnected reports whether source is reachable, from the latest probe
+// when there is a recent one and from a live check otherwise, e.g. for a
+// source created since the last cycle.
+func (s *Service) SourceConnected(ctx context.Context, source *models.Source) bool {
+	if health, ok := s.cachedHealth(source.ID); ok {
+		return health.Status == models.HealthStatusHealthy
+	}
+	return s.CheckSourceConnectionStatus(ctx, source)
+}
+
+// SourceHealthHistory returns the source's recent probes, oldest first.
+func (s *Service) SourceHealthHistory(ctx context.Context, sourceID models.SourceID) (*models.SourceHealthHistory, error) {
+	if _, err := s.db.GetSource(ctx, sourceID); err != nil {
+		return nil, err
+	}
+	s.health.mu.RLock()
+	checks := append([]models.HealthCheck(nil), s.health.history[sourceID]...)
+	s.health.mu.RUnlock()
+	return models.NewSourceHealthHistory(sourceID, checks), nil
+}
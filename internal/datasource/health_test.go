@@ -0,0 +1,162 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/events"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+type healthProbeProvider struct {
+	Provider
+	connected bool
+	health    models.SourceHealth
+}
+
+func (p *healthProbeProvider) CheckSourceConnectionStatus(_ context.Context, _ *models.Source) bool {
+	return p.connected
+}
+
+func (p *healthProbeProvider) GetSourceHealth(_ context.Context, sourceID models.SourceID) models.SourceHealth {
+	health := p.health
+	health.SourceID = sourceID
+	return health
+}
+
+func newHealthProbeService(provider Provider) *Service {
+	return &Service{
+		log:       slog.New(slog.DiscardHandler),
+		providers: map[models.SourceType]Provider{models.SourceTypeClickHouse: provider},
+	}
+}
+
+func TestProbeSource(t *testing.T) {
+	source := &models.Source{ID: 7, SourceType: models.SourceTypeClickHouse}
+
+	t.Run("connected source is healthy", func(t *testing.T) {
+		s := newHealthProbeService(&healthProbeProvider{
+			connected: true,
+			health:    models.SourceHealth{Status: models.HealthStatusUnhealthy, Error: "stale failure"},
+		})
+		health := s.probeSource(context.Background(), source)
+		if health.Status != models.HealthStatusHealthy || health.Error != "" {
+			t.Fatalf("health = %+v, want healthy with no error", health)
+		}
+	})
+
+	t.Run("failed connection is unhealthy even if provider says healthy", func(t *testing.T) {
+		s := newHealthProbeService(&healthProbeProvider{
+			health: models.SourceHealth{Status: models.HealthStatusHealthy},
+		})
+		health := s.probeSource(context.Background(), source)
+		if health.Status != models.HealthStatusUnhealthy || health.Error == "" {
+			t.Fatalf("health = %+v, want unhealthy with an error", health)
+		}
+	})
+
+	t.Run("unknown source type is unhealthy", func(t *testing.T) {
+		s := newHealthProbeService(&healthProbeProvider{connected: true})
+		health := s.probeSource(context.Background(), &models.Source{ID: 8, SourceType: "unknown"})
+		if health.Status != models.HealthStatusUnhealthy || health.Error == "" {
+			t.Fatalf("health = %+v, want unhealthy with an error", health)
+		}
+	})
+}
+
+func TestProbeSourcePublishesHealthChanges(t *testing.T) {
+	source := &models.Source{ID: 9001, Name: "flaky", SourceType: models.SourceTypeClickHouse}
+	var changes []models.SourceHealthEventData
+	unsubscribe := events.On(events.Default(), models.EventSourceHealthChanged, func(_ models.Event, data models.SourceHealthEventData) {
+		if data.SourceID == source.ID {
+			changes = append(changes, data)
+		}
+	})
+	defer unsubscribe()
+
+	provider := &healthProbeProvider{
+		connected: true,
+		health:    models.SourceHealth{Status: models.HealthStatusUnhealthy, Error: "connection refused"},
+	}
+	s := newHealthProbeService(provider)
+	ctx := context.Background()
+	s.probeSource(ctx, source) // first probe: no previous status
+	s.probeSource(ctx, source) // unchanged
+	provider.connected = false
+	s.probeSource(ctx, source)
+	provider.connected = true
+	s.probeSource(ctx, source)
+
+	if len(changes) != 2 {
+		t.Fatalf("got %d health changes, want 2: %+v", len(changes), changes)
+	}
+	if c := changes[0]; c.Status != models.HealthStatusUnhealthy || c.PreviousStatus != models.HealthStatusHealthy || c.Error == "" || c.Name != "flaky" {
+		t.Errorf("first change = %+v, want healthy -> unhealthy with an error", c)
+	}
+	if c := changes[1]; c.Status != models.HealthStatusHealthy || c.PreviousStatus != models.HealthStatusUnhealthy {
+		t.Errorf("second change = %+v, want unhealthy -> healthy", c)
+	}
+}
+
+func TestRecordHealthCapsHistory(t *testing.T) {
+	s := &Service{}
+	for i := range healthHistorySize + 5 {
+		s.recordHealth(models.SourceHealth{
+			SourceID:    1,
+			Status:      models.HealthStatusHealthy,
+			LatencyMs:   int64(i),
+			LastChecked: time.Now(),
+		})
+	}
+
+	checks := s.health.history[1]
+	if len(checks) != healthHistorySize {
+		t.Fatalf("history has %d checks, want %d", len(checks), healthHistorySize)
+	}
+	if checks[0].LatencyMs != 5 || checks[len(checks)-1].LatencyMs != healthHistorySize+4 {
+		t.Fatalf("history kept checks %d..%d, want the most recent", checks[0].LatencyMs, checks[len(checks)-1].LatencyMs)
+	}
+}
+
+func TestCachedHealth(t *testing.T) {
+	s := &Service{}
+	s.recordHealth(models.SourceHealth{SourceID: 1, Status: models.HealthStatusHealthy, LastChecked: time.Now()})
+	s.recordHealth(models.SourceHealth{SourceID: 2, Status: models.HealthStatusHealthy, LastChecked: time.Now().Add(-time.Hour)})
+
+	if _, ok := s.cachedHealth(1); ok {
+		t.Fatal("cachedHealth returned a result while probing is stopped")
+	}
+
+	s.health.interval = time.Minute
+	if _, ok := s.cachedHealth(1); !ok {
+		t.Fatal("cachedHealth missed a fresh result")
+	}
+	if _, ok := s.cachedHealth(2); ok {
+		t.Fatal("cachedHealth returned a stale result")
+	}
+
+	s.forgetHealth(1)
+	if _, ok := s.cachedHealth(1); ok {
+		t.Fatal("cachedHealth returned a forgotten result")
+	}
+}
+
+func TestRecordQueryOutcome(t *testing.T) {
+	s := &Service{}
+	s.recordQueryOutcome(1, nil)
+	if s.health.queries != nil {
+		t.Fatal("query counted while probing is stopped")
+	}
+
+	s.health.interval = time.Minute
+	s.recordQueryOutcome(1, nil)
+	s.recordQueryOutcome(1, errors.New("connection reset"))
+	s.recordQueryOutcome(1, &ValidationError{Message: "bad query"})
+	s.recordQueryOutcome(1, context.Canceled)
+	if got := s.health.queries[1]; got != (QueryOutcomes{Total: 2, Failed: 1}) {
+		t.Fatalf("outcomes = %+v, want 2 queries with 1 failure", got)
+	}
+}
@@ -52,4 +52,24 @@ type SourceInspection struct {
 	Storage  []InspectionMetric      `json:"storage,omitempty"`
 	Activity *SourceActivity         `json:"activity,omitempty"`
 	Schema   *SourceSchemaInspection `json:"schema,omitempty"`
+	Cluster  *ClusterInspection      `json:"cluster,omitempty"`
+}
+
+// ClusterInspection describes the cluster behind a Distributed-table source:
+// the local table each shard stores rows in, and the shards' replicas.
+type ClusterInspection struct {
+	Name          string           `json:"name"`
+	LocalDatabase string           `json:"local_database"`
+	LocalTable    string           `json:"local_table"`
+	Shards        int              `json:"shards"`
+	Replicas      []ClusterReplica `json:"replicas"`
+}
+
+type ClusterReplica struct {
+	Shard       uint32 `json:"shard"`
+	Replica     uint32 `json:"replica"`
+	Host        string `json:"host"`
+	Port        uint16 `json:"port"`
+	IsLocal     bool   `json:"is_local,omitempty"`
+	ErrorsCount uint32 `json:"errors_count,omitempty"`
 }
@@ -19,6 +19,32 @@ type QueryRequest struct {
 	MaxLimit         int
 	MaxResponseBytes int
 	QueryTimeout     *int
+	DerivedColumns   []models.DerivedColumn
+	// CostLimits guards ClickHouse queries against reading too much data; nil
+	// leaves them uncapped. Other source types ignore it.
+	CostLimits *models.QueryCostLimits
+	// Sample, when non-zero, restricts a ClickHouse query to roughly that
+	// fraction of rows; see clickhouse.QueryBuilder.WithSample.
+	Sample float64
+	// RowFilter is the team's row-level access policy for the source, ANDed
+	// into the query; see clickhouse.QueryBuilder.WithRowFilter. Only
+	// ClickHouse sources support it.
+	RowFilter string
+	// MaskedColumns are the source's masked columns when the caller can't see
+	// them unmasked; see clickhouse.QueryBuilder.WithMaskedColumns.
+	MaskedColumns models.MaskedColumns
+	// RequireTimeBound makes a ClickHouse query on the source's table filter
+	// on its timestamp column: StartTime and EndTime are ANDed in when it
+	// doesn't, and it is rejected without them. See
+	// clickhouse.QueryBuilder.WithTimeBound.
+	RequireTimeBound bool
+	// Dedupe collapses consecutive identical rows of the result; see
+	// models.DedupeOptions. The service applies it for every provider.
+	Dedupe *models.DedupeOptions
+	// Distributed routes a ClickHouse query on a Distributed table to one
+	// shard or sets prefer_localhost_replica; see models.DistributedRouting.
+	// A source whose table isn't Distributed rejects it.
+	Distributed *models.DistributedRouting
 }
 
 type HistogramRequest struct {
@@ -29,6 +55,16 @@ type HistogramRequest struct {
 	GroupBy      string
 	Timezone     string
 	QueryTimeout *int
+	// SeverityBreakdown asks core.GetHistogramData to group by the source's
+	// MetaSeverityField when GroupBy is empty. It is resolved into GroupBy
+	// before the provider runs, so providers can ignore it.
+	SeverityBreakdown bool
+	// RowFilter is the team's row filter for the source; see
+	// QueryRequest.RowFilter.
+	RowFilter string
+	// MaskedColumns are the source's columns masked for the caller; see
+	// QueryRequest.MaskedColumns.
+	MaskedColumns models.MaskedColumns
 }
 
 type HistogramBucket struct {
@@ -48,6 +84,15 @@ type HistogramResult struct {
 	// Notice carries a non-fatal message (e.g. group-by series were capped to
 	// a top-N set). Empty when there is nothing to surface.
 	Notice string `json:"notice,omitempty"`
+	// GroupBy is the field the buckets were grouped by, including one picked
+	// by a severity breakdown. Empty for an ungrouped histogram.
+	GroupBy string `json:"group_by,omitempty"`
+	// FromRollup is true when the counts were read from the source's hourly
+	// rollup instead of the raw logs.
+	FromRollup bool `json:"from_rollup,omitempty"`
+	// Annotations are the team's timeline markers overlapping the requested
+	// range, for the frontend to draw over the buckets.
+	Annotations []*models.Annotation `json:"annotations,omitempty"`
 }
 
 type AlertQueryRequest struct {
@@ -57,6 +102,15 @@ type AlertQueryRequest struct {
 	QueryTimeout    *int
 }
 
+// ConditionAlertRequest asks a provider to compile a structured alert
+// condition into a native query returning a single numeric "value" over the
+// LookbackSeconds window ending at Now.
+type ConditionAlertRequest struct {
+	Condition       *models.AlertCondition
+	LookbackSeconds int
+	Now             time.Time
+}
+
 // LogContextRequest asks for logs surrounding a specific timestamp.
 type LogContextRequest struct {
 	TargetTimestamp int64 // Unix timestamp in milliseconds
@@ -65,5 +119,11 @@ type LogContextRequest struct {
 	BeforeOffset    int
 	AfterOffset     int
 	ExcludeBoundary bool
-	QueryTimeout    *int
+	// Stream optionally narrows context to the target row's stream (the
+	// VictoriaLogs `_stream` value). Providers without streams ignore it.
+	Stream string
+	// Scope narrows context to rows with the given field values; see
+	// models.LogContextRequest.Scope.
+	Scope        models.LogContextScope
+	QueryTimeout *int
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
@@ -48,6 +49,11 @@ type Service struct {
 	inspectionFill singleflight.Group
 	activityFill   singleflight.Group
 	activitySlots  chan struct{}
+	health         healthProbes
+
+	fieldValuesMu   sync.Mutex
+	fieldValues     map[string]fieldValuesCacheEntry
+	fieldValuesFill singleflight.Group
 }
 
 type Capability string
@@ -61,6 +67,11 @@ const (
 	CapabilityLogContext       Capability = "log_context"
 	CapabilityExports          Capability = "exports"
 	CapabilityLiveTail         Capability = "live_tail"
+	CapabilityFieldStats       Capability = "field_stats"
+	CapabilityJSONFields       Capability = "json_fields"
+	CapabilityLogPatterns      Capability = "log_patterns"
+	CapabilityVolumeAnomalies  Capability = "volume_anomalies"
+	CapabilityRangeComparison  Capability = "range_comparison"
 )
 
 func NewService(db store.Store, log *slog.Logger) *Service {
@@ -71,6 +82,7 @@ func NewService(db store.Store, log *slog.Logger) *Service {
 		inspections:   make(map[models.SourceID]inspectionCacheEntry),
 		activities:    make(map[models.SourceID]activityCacheEntry),
 		activitySlots: make(chan struct{}, 2),
+		fieldValues:   make(map[string]fieldValuesCacheEntry),
 	}
 }
 
@@ -115,10 +127,30 @@ func (s *Service) CreateSource(ctx context.Context, req *models.CreateSourceRequ
 		return nil, err
 	}
 
+	if err := req.Tags.Validate(); err != nil {
+		return nil, &ValidationError{Field: "tags", Message: err.Error()}
+	}
+	rules, err := normalizeExtractionRules(req.SourceType, req.ExtractionRules)
+	if err != nil {
+		return nil, err
+	}
+	tracing, err := normalizeTraceCorrelation(req.TraceCorrelation)
+	if err != nil {
+		return nil, err
+	}
+	masked, err := normalizeMaskedColumns(req.SourceType, req.MaskedColumns)
+	if err != nil {
+		return nil, err
+	}
+
 	source, err := provider.PrepareSource(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+	source.Tags = req.Tags
+	source.ExtractionRules = rules
+	source.TraceCorrelation = tracing
+	source.MaskedColumns = masked
 
 	existingSource, err := s.db.GetSourceByIdentityKey(ctx, source.IdentityKey)
 	if err == nil && existingSource != nil {
@@ -169,6 +201,55 @@ func (s *Service) ValidateConnection(ctx context.Context, req *models.ValidateCo
 	return provider.ValidateConnection(ctx, req)
 }
 
+// TableDiscoverer is an optional interface for providers that can list the
+// databases and tables behind a connection and preview a table, for guided
+// source setup. Providers that don't implement it are reported via
+// ErrOperationNotSupported.
+type TableDiscoverer interface {
+	DiscoverDatabases(ctx context.Context, req *models.DiscoveryRequest) ([]string, error)
+	DiscoverTables(ctx context.Context, req *models.DiscoveryRequest) ([]models.DiscoveredTable, error)
+	PreviewTable(ctx context.Context, req *models.DiscoveryRequest) (*models.TablePreview, error)
+}
+
+func (s *Service) tableDiscoverer(req *models.DiscoveryRequest) (TableDiscoverer, error) {
+	if req == nil {
+		return nil, fmt.Errorf("discovery request is required")
+	}
+	provider, err := s.ProviderForSourceType(req.SourceType)
+	if err != nil {
+		return nil, err
+	}
+	discoverer, ok := provider.(TableDiscoverer)
+	if !ok {
+		return nil, ErrOperationNotSupported
+	}
+	return discoverer, nil
+}
+
+func (s *Service) DiscoverDatabases(ctx context.Context, req *models.DiscoveryRequest) ([]string, error) {
+	discoverer, err := s.tableDiscoverer(req)
+	if err != nil {
+		return nil, err
+	}
+	return discoverer.DiscoverDatabases(ctx, req)
+}
+
+func (s *Service) DiscoverTables(ctx context.Context, req *models.DiscoveryRequest) ([]models.DiscoveredTable, error) {
+	discoverer, err := s.tableDiscoverer(req)
+	if err != nil {
+		return nil, err
+	}
+	return discoverer.DiscoverTables(ctx, req)
+}
+
+func (s *Service) PreviewTable(ctx context.Context, req *models.DiscoveryRequest) (*models.TablePreview, error) {
+	discoverer, err := s.tableDiscoverer(req)
+	if err != nil {
+		return nil, err
+	}
+	return discoverer.PreviewTable(ctx, req)
+}
+
 func (s *Service) ValidateSavedQuerySupport(ctx context.Context, sourceID models.SourceID, language models.QueryLanguage, mode models.SavedQueryEditorMode) error {
 	source, provider, err := s.sourceAndProvider(ctx, sourceID)
 	if err != nil {
@@ -212,7 +293,37 @@ func (s *Service) QueryLogs(ctx context.Context, sourceID models.SourceID, req Q
 	if err != nil {
 		return nil, err
 	}
-	return provider.QueryLogs(ctx, source, req)
+	if err := checkRowFilter(source, req.RowFilter); err != nil {
+		return nil, err
+	}
+	result, err := provider.QueryLogs(ctx, source, req)
+	s.recordQueryOutcome(sourceID, err)
+	if err == nil && req.Dedupe != nil {
+		dedupeResult(result, req.Dedupe, source)
+	}
+	return result, err
+}
+
+// QueryCounter is an optional interface for providers that can count the rows
+// a query matches regardless of its limit, for "showing 100 of N". Providers
+// that don't implement it are reported via ErrOperationNotSupported.
+type QueryCounter interface {
+	CountQuery(ctx context.Context, source *models.Source, req QueryRequest) (int64, error)
+}
+
+func (s *Service) CountQuery(ctx context.Context, sourceID models.SourceID, req QueryRequest) (int64, error) {
+	source, provider, err := s.sourceAndProvider(ctx, sourceID)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkRowFilter(source, req.RowFilter); err != nil {
+		return 0, err
+	}
+	counter, ok := provider.(QueryCounter)
+	if !ok {
+		return 0, ErrOperationNotSupported
+	}
+	return counter.CountQuery(ctx, source, req)
 }
 
 // StreamWriter receives query results as they are read, so the response body
@@ -243,11 +354,22 @@ func (s *Service) QueryLogsStream(ctx context.Context, sourceID models.SourceID,
 	if err != nil {
 		return models.QueryStats{}, err
 	}
+	if err := checkRowFilter(source, req.RowFilter); err != nil {
+		return models.QueryStats{}, err
+	}
 	streamer, ok := provider.(LogStreamer)
 	if !ok {
 		return models.QueryStats{}, ErrOperationNotSupported
 	}
-	return streamer.QueryLogsStream(ctx, source, req, w)
+	if req.Dedupe != nil {
+		dw := &dedupeStreamWriter{StreamWriter: w, deduper: newRowDeduper(req.Dedupe, source)}
+		stats, err := streamer.QueryLogsStream(ctx, source, req, dw)
+		s.recordQueryOutcome(sourceID, err)
+		return dw.deduper.stats(stats), err
+	}
+	stats, err := streamer.QueryLogsStream(ctx, source, req, w)
+	s.recordQueryOutcome(sourceID, err)
+	return stats, err
 }
 
 func (s *Service) GetSourceSchema(ctx context.Context, sourceID models.SourceID) ([]models.ColumnInfo, error) {
@@ -263,9 +385,103 @@ func (s *Service) Histogram(ctx context.Context, sourceID models.SourceID, req H
 	if err != nil {
 		return nil, err
 	}
+	if err := checkRowFilter(source, req.RowFilter); err != nil {
+		return nil, err
+	}
 	return provider.Histogram(ctx, source, req)
 }
 
+// FieldStatsProvider is an optional interface for providers that can compute
+// per-field statistics (min/max/avg/percentiles, null ratio). Providers that
+// don't implement it are reported via ErrOperationNotSupported.
+type FieldStatsProvider interface {
+	GetFieldStats(ctx context.Context, source *models.Source, req FieldStatsRequest) (*FieldStatsResult, error)
+}
+
+func (s *Service) GetFieldStats(ctx context.Context, sourceID models.SourceID, req FieldStatsRequest) (*FieldStatsResult, error) {
+	source, provider, err := s.sourceAndProvider(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	fsp, ok := provider.(FieldStatsProvider)
+	if !ok {
+		return nil, ErrOperationNotSupported
+	}
+	return fsp.GetFieldStats(ctx, source, req)
+}
+
+// JSONFieldsProvider is an optional interface for providers that can sample
+// recent rows and report the keys of JSON objects stored in string columns.
+type JSONFieldsProvider interface {
+	GetJSONFields(ctx context.Context, source *models.Source, req JSONFieldsRequest) (*models.JSONFieldsResult, error)
+}
+
+func (s *Service) GetJSONFields(ctx context.Context, sourceID models.SourceID, req JSONFieldsRequest) (*models.JSONFieldsResult, error) {
+	source, provider, err := s.sourceAndProvider(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	jfp, ok := provider.(JSONFieldsProvider)
+	if !ok {
+		return nil, ErrOperationNotSupported
+	}
+	return jfp.GetJSONFields(ctx, source, req)
+}
+
+// LogPatternsProvider is an optional interface for providers that can group
+// messages into templates (log pattern mining).
+type LogPatternsProvider interface {
+	GetLogPatterns(ctx context.Context, source *models.Source, req LogPatternsRequest) (*models.LogPatternsResult, error)
+}
+
+func (s *Service) GetLogPatterns(ctx context.Context, sourceID models.SourceID, req LogPatternsRequest) (*models.LogPatternsResult, error) {
+	source, provider, err := s.sourceAndProvider(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	lpp, ok := provider.(LogPatternsProvider)
+	if !ok {
+		return nil, ErrOperationNotSupported
+	}
+	return lpp.GetLogPatterns(ctx, source, req)
+}
+
+// VolumeAnomalyProvider is an optional interface for providers that can
+// compare a source's log volume and error rate against an earlier window.
+type VolumeAnomalyProvider interface {
+	DetectVolumeAnomalies(ctx context.Context, source *models.Source, req VolumeAnomalyRequest) (*models.VolumeAnomalyReport, error)
+}
+
+func (s *Service) DetectVolumeAnomalies(ctx context.Context, sourceID models.SourceID, req VolumeAnomalyRequest) (*models.VolumeAnomalyReport, error) {
+	source, provider, err := s.sourceAndProvider(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	vap, ok := provider.(VolumeAnomalyProvider)
+	if !ok {
+		return nil, ErrOperationNotSupported
+	}
+	return vap.DetectVolumeAnomalies(ctx, source, req)
+}
+
+// RangeComparisonProvider is an optional interface for providers that can
+// diff field value distributions between two time ranges.
+type RangeComparisonProvider interface {
+	CompareRanges(ctx context.Context, source *models.Source, req RangeComparisonRequest) (*models.RangeComparison, error)
+}
+
+func (s *Service) CompareRanges(ctx context.Context, sourceID models.SourceID, req RangeComparisonRequest) (*models.RangeComparison, error) {
+	source, provider, err := s.sourceAndProvider(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	rcp, ok := provider.(RangeComparisonProvider)
+	if !ok {
+		return nil, ErrOperationNotSupported
+	}
+	return rcp.CompareRanges(ctx, source, req)
+}
+
 // LogContextProvider is an optional interface for providers that can fetch
 // the logs surrounding a specific timestamp (grep -C for logs). Providers that
 // don't implement it are reported via ErrOperationNotSupported.
@@ -323,6 +539,25 @@ func (s *Service) TailLogs(ctx context.Context, sourceID models.SourceID, req Ta
 	return tailer.TailLogs(ctx, source, req, emit)
 }
 
+// LogIngester is an optional interface for providers that accept pushed logs
+// as JSONEachRow rows. Providers that don't implement it are reported via
+// ErrOperationNotSupported.
+type LogIngester interface {
+	IngestLogs(ctx context.Context, source *models.Source, rows []byte, wait bool) error
+}
+
+func (s *Service) IngestLogs(ctx context.Context, sourceID models.SourceID, rows []byte, wait bool) error {
+	source, provider, err := s.sourceAndProvider(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	ingester, ok := provider.(LogIngester)
+	if !ok {
+		return ErrOperationNotSupported
+	}
+	return ingester.IngestLogs(ctx, source, rows, wait)
+}
+
 // LogchefQLCompileRequest carries the raw LogchefQL query and the transport
 // values needed to build an executable native query. Times are passed through
 // as accepted by the HTTP handlers (they may be empty for preview-only
@@ -366,6 +601,13 @@ type LogchefQLCompiler interface {
 	CompileLogchefQL(ctx context.Context, source *models.Source, req LogchefQLCompileRequest) (*CompiledLogchefQL, error)
 }
 
+// ConditionAlertBuilder is an optional interface for providers that can
+// evaluate structured (LogchefQL-based) alert conditions. Providers that don't
+// implement it are reported via ErrOperationNotSupported.
+type ConditionAlertBuilder interface {
+	BuildConditionAlertQuery(ctx context.Context, source *models.Source, req ConditionAlertRequest) (string, error)
+}
+
 func (s *Service) CompileLogchefQL(ctx context.Context, sourceID models.SourceID, req LogchefQLCompileRequest) (*CompiledLogchefQL, error) {
 	source, provider, err := s.sourceAndProvider(ctx, sourceID)
 	if err != nil {
@@ -378,6 +620,126 @@ func (s *Service) CompileLogchefQL(ctx context.Context, sourceID models.SourceID
 	return compiler.CompileLogchefQL(ctx, source, req)
 }
 
+// LintRequest is a query to check against a source's schema. Limit is the row
+// limit a LogchefQL query will run with (SQL carries its own LIMIT); MaxLimit
+// is the largest the explorer allows.
+type LintRequest struct {
+	Query    string
+	Language models.QueryLanguage
+	Limit    int
+	MaxLimit int
+}
+
+// LintResult reports whether a query parses and, if it does, the warnings
+// found. Warnings is never nil.
+type LintResult struct {
+	Valid    bool                   `json:"valid"`
+	Error    *logchefql.ParseError  `json:"error,omitempty"`
+	Warnings []logchefql.Diagnostic `json:"warnings"`
+}
+
+// QueryLinter is an optional interface for providers that can lint queries
+// against a source's schema. Providers that don't implement it are reported
+// via ErrOperationNotSupported.
+type QueryLinter interface {
+	LintQuery(ctx context.Context, source *models.Source, req LintRequest) (*LintResult, error)
+}
+
+// LintQuery checks a LogchefQL or native query against the source's schema.
+func (s *Service) LintQuery(ctx context.Context, sourceID models.SourceID, req LintRequest) (*LintResult, error) {
+	source, provider, err := s.sourceAndProvider(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	linter, ok := provider.(QueryLinter)
+	if !ok {
+		return nil, ErrOperationNotSupported
+	}
+	return linter.LintQuery(ctx, source, req)
+}
+
+// FormatRequest is a native query to pretty-print and convert back to
+// LogchefQL.
+type FormatRequest struct {
+	Query string
+}
+
+// FormatResult is a pretty-printed native query and, when it has a LogchefQL
+// equivalent, that query. Reason says why a valid query didn't convert.
+type FormatResult struct {
+	Valid          bool                  `json:"valid"`
+	Error          *logchefql.ParseError `json:"error,omitempty"`
+	FormattedQuery string                `json:"formatted_query,omitempty"`
+	Convertible    bool                  `json:"convertible"`
+	LogchefQL      string                `json:"logchefql"`
+	Reason         string                `json:"reason,omitempty"`
+}
+
+// QueryFormatter is an optional interface for providers that can format their
+// native queries and convert them back to LogchefQL. Providers that don't
+// implement it are reported via ErrOperationNotSupported.
+type QueryFormatter interface {
+	FormatQuery(ctx context.Context, source *models.Source, req FormatRequest) (*FormatResult, error)
+}
+
+// FormatQuery pretty-prints a native query and converts it to LogchefQL where
+// possible.
+func (s *Service) FormatQuery(ctx context.Context, sourceID models.SourceID, req FormatRequest) (*FormatResult, error) {
+	source, provider, err := s.sourceAndProvider(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	formatter, ok := provider.(QueryFormatter)
+	if !ok {
+		return nil, ErrOperationNotSupported
+	}
+	return formatter.FormatQuery(ctx, source, req)
+}
+
+// BuildConditionAlertQuery compiles a structured alert condition into the
+// source's native aggregate query.
+func (s *Service) BuildConditionAlertQuery(ctx context.Context, sourceID models.SourceID, req ConditionAlertRequest) (string, error) {
+	source, provider, err := s.sourceAndProvider(ctx, sourceID)
+	if err != nil {
+		return "", err
+	}
+	builder, ok := provider.(ConditionAlertBuilder)
+	if !ok {
+		return "", ErrOperationNotSupported
+	}
+	return builder.BuildConditionAlertQuery(ctx, source, req)
+}
+
+// ResolveAlertQuery returns the query request that evaluates alert at now.
+// Native alerts, and condition alerts saved before conditions were structured,
+// run their stored Query; structured condition alerts are compiled from
+// ConditionJSON on every evaluation so the window always ends at now.
+func (s *Service) ResolveAlertQuery(ctx context.Context, alert *models.Alert, now time.Time) (AlertQueryRequest, error) {
+	req := AlertQueryRequest{
+		Language:        alert.QueryLanguage,
+		Query:           strings.TrimSpace(alert.Query),
+		LookbackSeconds: alert.LookbackSeconds,
+	}
+	if models.NormalizeAlertEditorMode(alert.EditorMode) != models.AlertEditorModeCondition {
+		return req, nil
+	}
+	cond, ok, err := models.ParseAlertCondition(alert.ConditionJSON)
+	if err != nil || !ok {
+		return req, err
+	}
+	req.LookbackSeconds = cond.Lookback(alert.LookbackSeconds)
+	query, err := s.BuildConditionAlertQuery(ctx, alert.SourceID, ConditionAlertRequest{
+		Condition:       cond,
+		LookbackSeconds: req.LookbackSeconds,
+		Now:             now,
+	})
+	if err != nil {
+		return req, fmt.Errorf("compiling alert condition: %w", err)
+	}
+	req.Query = query
+	return req, nil
+}
+
 func (s *Service) EvaluateAlert(ctx context.Context, sourceID models.SourceID, req AlertQueryRequest) (*models.QueryResult, error) {
 	source, provider, err := s.sourceAndProvider(ctx, sourceID)
 	if err != nil {
@@ -391,6 +753,9 @@ func (s *Service) GetFieldValues(ctx context.Context, sourceID models.SourceID,
 	if err != nil {
 		return nil, err
 	}
+	if err := checkRowFilter(source, req.RowFilter); err != nil {
+		return nil, err
+	}
 	return provider.GetFieldValues(ctx, source, req)
 }
 
@@ -399,7 +764,10 @@ func (s *Service) GetAllFieldValues(ctx context.Context, sourceID models.SourceI
 	if err != nil {
 		return nil, err
 	}
-	return provider.GetAllFieldValues(ctx, source, req)
+	if err := checkRowFilter(source, req.RowFilter); err != nil {
+		return nil, err
+	}
+	return s.cachedAllFieldValues(ctx, source, provider, req)
 }
 
 func (s *Service) InspectSource(ctx context.Context, sourceID models.SourceID) (*SourceInspection, error) {
@@ -457,6 +825,10 @@ func (s *Service) GetSourceHealth(ctx context.Context, sourceID models.SourceID)
 		return models.SourceHealth{}, err
 	}
 
+	if health, ok := s.cachedHealth(sourceID); ok {
+		return health, nil
+	}
+
 	provider, err := s.ProviderForSource(source)
 	if err != nil {
 		return models.SourceHealth{
@@ -563,6 +935,16 @@ func (s *Service) sourceAndProvider(ctx context.Context, sourceID models.SourceI
 	return source, provider, nil
 }
 
+// checkRowFilter reports ErrOperationNotSupported for a team row filter on a
+// source that can't apply one. Only ClickHouse sources take row filters, so
+// any other source must fail closed rather than run the query unfiltered.
+func checkRowFilter(source *models.Source, rowFilter string) error {
+	if rowFilter != "" && !source.IsClickHouse() {
+		return ErrOperationNotSupported
+	}
+	return nil
+}
+
 func supportsQueryLanguage(supported []models.QueryLanguage, language models.QueryLanguage) bool {
 	for _, candidate := range supported {
 		if models.NormalizeQueryLanguage(candidate) == language {
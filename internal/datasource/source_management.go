@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 
 	"github.com/mr-karan/logchef/pkg/models"
 )
@@ -18,7 +20,7 @@ func (s *Service) GetSource(ctx context.Context, sourceID models.SourceID) (*mod
 		return nil, err
 	}
 
-	source.IsConnected = provider.CheckSourceConnectionStatus(ctx, source)
+	source.IsConnected = s.SourceConnected(ctx, source)
 	if err := provider.PopulateSourceDetails(ctx, source); err != nil {
 		return nil, fmt.Errorf("populate source details: %w", err)
 	}
@@ -88,12 +90,95 @@ func (s *Service) UpdateSource(ctx context.Context, sourceID models.SourceID, re
 			}
 			return nil, fmt.Errorf("initialize updated source: %w", err)
 		}
+		s.forgetHealth(sourceID)
 	}
 
 	s.invalidateInspectionCache(sourceID)
 	return s.GetSource(ctx, sourceID)
 }
 
+// TTLManager is implemented by providers that can change a source table's
+// retention. Providers that don't are reported via ErrOperationNotSupported.
+type TTLManager interface {
+	ApplyTTL(ctx context.Context, source *models.Source, req *models.UpdateSourceTTLRequest) (*models.SourceTTLChange, error)
+}
+
+// UpdateSourceTTL changes the retention of a source's table and saves the new
+// ttl_days. A dry run returns the DDL without running it.
+func (s *Service) UpdateSourceTTL(ctx context.Context, sourceID models.SourceID, req *models.UpdateSourceTTLRequest) (*models.SourceTTLChange, error) {
+	if req == nil {
+		return nil, fmt.Errorf("update source ttl request is required")
+	}
+
+	source, provider, err := s.sourceAndProvider(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	manager, ok := provider.(TTLManager)
+	if !ok {
+		return nil, ErrOperationNotSupported
+	}
+
+	change, err := manager.ApplyTTL(ctx, source, req)
+	if err != nil || !change.Applied {
+		return change, err
+	}
+	if err := source.SyncConnectionConfig(); err != nil {
+		return nil, err
+	}
+	if err := s.db.UpdateSource(ctx, source); err != nil {
+		return nil, fmt.Errorf("update source configuration: %w", err)
+	}
+	s.invalidateInspectionCache(sourceID)
+	return change, nil
+}
+
+// PartitionManager is implemented by providers whose tables are partitioned
+// and can be purged a partition at a time. Providers that don't are reported
+// via ErrOperationNotSupported.
+type PartitionManager interface {
+	ListPartitions(ctx context.Context, source *models.Source) ([]models.TablePartition, error)
+	DropPartitions(ctx context.Context, source *models.Source, req *models.DropPartitionsRequest) (*models.DropPartitionsResult, error)
+}
+
+func (s *Service) partitionManager(ctx context.Context, sourceID models.SourceID) (*models.Source, PartitionManager, error) {
+	source, provider, err := s.sourceAndProvider(ctx, sourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	manager, ok := provider.(PartitionManager)
+	if !ok {
+		return nil, nil, ErrOperationNotSupported
+	}
+	return source, manager, nil
+}
+
+// ListSourcePartitions lists the partitions of a source's table.
+func (s *Service) ListSourcePartitions(ctx context.Context, sourceID models.SourceID) ([]models.TablePartition, error) {
+	source, manager, err := s.partitionManager(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	return manager.ListPartitions(ctx, source)
+}
+
+// DropSourcePartitions drops partitions of a source's table. A dry run
+// returns the DDL without running it.
+func (s *Service) DropSourcePartitions(ctx context.Context, sourceID models.SourceID, req *models.DropPartitionsRequest) (*models.DropPartitionsResult, error) {
+	if req == nil {
+		return nil, fmt.Errorf("drop partitions request is required")
+	}
+	source, manager, err := s.partitionManager(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := manager.DropPartitions(ctx, source, req)
+	if err == nil && result.Applied {
+		s.invalidateInspectionCache(sourceID)
+	}
+	return result, err
+}
+
 func (s *Service) DeleteSource(ctx context.Context, sourceID models.SourceID) error {
 	source, provider, err := s.sourceAndProvider(ctx, sourceID)
 	if err != nil {
@@ -111,6 +196,7 @@ func (s *Service) DeleteSource(ctx context.Context, sourceID models.SourceID) er
 	}
 
 	s.invalidateInspectionCache(sourceID)
+	s.forgetHealth(sourceID)
 	return nil
 }
 
@@ -132,6 +218,13 @@ func cloneSource(source *models.Source) *models.Source {
 	if source.SortKeys != nil {
 		cloned.SortKeys = append([]string(nil), source.SortKeys...)
 	}
+	cloned.Tags = maps.Clone(source.Tags)
+	cloned.ExtractionRules = slices.Clone(source.ExtractionRules)
+	cloned.MaskedColumns = slices.Clone(source.MaskedColumns)
+	if source.TraceCorrelation != nil {
+		tracing := *source.TraceCorrelation
+		cloned.TraceCorrelation = &tracing
+	}
 
 	return &cloned
 }
@@ -57,6 +57,50 @@ func ApplyCommonSourceUpdates(source *models.Source, req *models.UpdateSourceReq
 		}
 	}
 
+	if req.Tags != nil {
+		tags := *req.Tags
+		if err := tags.Validate(); err != nil {
+			return false, &ValidationError{Field: "tags", Message: err.Error()}
+		}
+		if !tags.Equal(source.Tags) {
+			source.Tags = tags
+			changed = true
+		}
+	}
+
+	if req.ExtractionRules != nil {
+		rules, err := normalizeExtractionRules(source.SourceType, *req.ExtractionRules)
+		if err != nil {
+			return false, err
+		}
+		if !rules.Equal(source.ExtractionRules) {
+			source.ExtractionRules = rules
+			changed = true
+		}
+	}
+
+	if req.TraceCorrelation != nil {
+		tracing, err := normalizeTraceCorrelation(req.TraceCorrelation)
+		if err != nil {
+			return false, err
+		}
+		if !tracing.Equal(source.TraceCorrelation) {
+			source.TraceCorrelation = tracing
+			changed = true
+		}
+	}
+
+	if req.MaskedColumns != nil {
+		masked, err := normalizeMaskedColumns(source.SourceType, *req.MaskedColumns)
+		if err != nil {
+			return false, err
+		}
+		if !masked.Equal(source.MaskedColumns) {
+			source.MaskedColumns = masked
+			changed = true
+		}
+	}
+
 	if req.MetaTSField != nil {
 		metaTSField := strings.TrimSpace(*req.MetaTSField)
 		if err := validateColumnName("meta_ts_field", metaTSField); err != nil {
@@ -81,3 +125,48 @@ func ApplyCommonSourceUpdates(source *models.Source, req *models.UpdateSourceReq
 
 	return changed, nil
 }
+
+// normalizeExtractionRules fills in rule defaults and validates them.
+// Extraction rules compile to ClickHouse expressions, so other source types
+// can't have any.
+func normalizeExtractionRules(sourceType models.SourceType, rules models.ExtractionRules) (models.ExtractionRules, error) {
+	rules = rules.Normalize()
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	if models.NormalizeSourceType(sourceType) != models.SourceTypeClickHouse {
+		return nil, &ValidationError{Field: "extraction_rules", Message: "extraction rules are only supported for ClickHouse sources"}
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, &ValidationError{Field: "extraction_rules", Message: err.Error()}
+	}
+	return rules, nil
+}
+
+// normalizeTraceCorrelation trims and validates a trace correlation. Unlike
+// extraction rules it only names fields and a link, so every source type can
+// have one.
+func normalizeTraceCorrelation(tracing *models.TraceCorrelation) (*models.TraceCorrelation, error) {
+	tracing = tracing.Normalize()
+	if err := tracing.Validate(); err != nil {
+		return nil, &ValidationError{Field: "trace_correlation", Message: err.Error()}
+	}
+	return tracing, nil
+}
+
+// normalizeMaskedColumns fills in masking defaults and validates them. Masks
+// are applied by rewriting ClickHouse SQL, so other source types can't have
+// any.
+func normalizeMaskedColumns(sourceType models.SourceType, masked models.MaskedColumns) (models.MaskedColumns, error) {
+	masked = masked.Normalize()
+	if len(masked) == 0 {
+		return nil, nil
+	}
+	if models.NormalizeSourceType(sourceType) != models.SourceTypeClickHouse {
+		return nil, &ValidationError{Field: "masked_columns", Message: "masked columns are only supported for ClickHouse sources"}
+	}
+	if err := masked.Validate(); err != nil {
+		return nil, &ValidationError{Field: "masked_columns", Message: err.Error()}
+	}
+	return masked, nil
+}
@@ -1,6 +1,8 @@
 package datasource
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net"
@@ -10,6 +12,8 @@ import (
 	"unicode"
 
 	"github.com/mr-karan/logchef/internal/clickhouse"
+	"github.com/mr-karan/logchef/internal/secrets"
+	"github.com/mr-karan/logchef/pkg/models"
 )
 
 var ErrSourceAlreadyExists = errors.New("source already exists")
@@ -118,15 +122,22 @@ func validateClickHouseConnection(connFieldPrefix string, requireTable bool, con
 		return &ValidationError{Field: connFieldPrefix + "host", Message: "host is required"}
 	}
 
-	_, portStr, err := net.SplitHostPort(connHost)
-	if err != nil {
-		if !strings.Contains(err.Error(), "missing port in address") {
-			return &ValidationError{Field: connFieldPrefix + "host", Message: "invalid host format", Err: err}
+	// A secret reference is only checked once resolved, on connecting.
+	// Otherwise the host may list several replicas, comma separated.
+	if !secrets.IsRef(connHost) {
+		hosts := clickhouse.SplitHosts(connHost)
+		if len(hosts) == 0 {
+			return &ValidationError{Field: connFieldPrefix + "host", Message: "host is required"}
 		}
-	} else {
-		port, convErr := strconv.Atoi(portStr)
-		if convErr != nil || port <= 0 || port > 65535 {
-			return &ValidationError{Field: connFieldPrefix + "host", Message: "port must be between 1 and 65535"}
+		seen := make(map[string]bool, len(hosts))
+		for _, host := range hosts {
+			if err := validateClickHouseHost(connFieldPrefix, host); err != nil {
+				return err
+			}
+			if seen[host] {
+				return &ValidationError{Field: connFieldPrefix + "host", Message: fmt.Sprintf("host %q is listed twice", host)}
+			}
+			seen[host] = true
 		}
 	}
 
@@ -147,11 +158,64 @@ func validateClickHouseConnection(connFieldPrefix string, requireTable bool, con
 	return nil
 }
 
+// validateClickHouseHost checks one host:port address of a connection's host.
+func validateClickHouseHost(connFieldPrefix, host string) error {
+	_, portStr, err := net.SplitHostPort(host)
+	if err != nil {
+		if !strings.Contains(err.Error(), "missing port in address") {
+			return &ValidationError{Field: connFieldPrefix + "host", Message: "invalid host format", Err: err}
+		}
+		return nil
+	}
+	port, convErr := strconv.Atoi(portStr)
+	if convErr != nil || port <= 0 || port > 65535 {
+		return &ValidationError{Field: connFieldPrefix + "host", Message: "port must be between 1 and 65535"}
+	}
+	return nil
+}
+
+// validateClickHouseTransport checks a connection's protocol and TLS options.
+// Certificates are parsed here so a bad PEM is reported against its field
+// rather than as a failed connection.
+func validateClickHouseTransport(connFieldPrefix string, conn models.ConnectionInfo) error {
+	switch conn.ClickHouseProtocol() {
+	case models.ClickHouseProtocolNative, models.ClickHouseProtocolHTTP:
+	default:
+		return &ValidationError{
+			Field:   connFieldPrefix + "protocol",
+			Message: fmt.Sprintf("protocol must be %q or %q (enable TLS for HTTPS)", models.ClickHouseProtocolNative, models.ClickHouseProtocolHTTP),
+		}
+	}
+
+	if !conn.TLSEnable {
+		if conn.TLSCACert != "" || conn.TLSClientCert != "" || conn.TLSClientKey != "" || conn.TLSSkipVerify {
+			return &ValidationError{Field: connFieldPrefix + "tls_enable", Message: "TLS options require tls_enable"}
+		}
+		return nil
+	}
+	if strings.TrimSpace(conn.TLSCACert) != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(conn.TLSCACert)) {
+		return &ValidationError{Field: connFieldPrefix + "tls_ca_cert", Message: "CA certificate contains no valid PEM certificates"}
+	}
+	hasCert, hasKey := strings.TrimSpace(conn.TLSClientCert) != "", strings.TrimSpace(conn.TLSClientKey) != ""
+	if hasCert != hasKey {
+		return &ValidationError{Field: connFieldPrefix + "tls_client_key", Message: "client certificate and client key must be set together"}
+	}
+	if hasCert {
+		if _, err := tls.X509KeyPair([]byte(conn.TLSClientCert), []byte(conn.TLSClientKey)); err != nil {
+			return &ValidationError{Field: connFieldPrefix + "tls_client_cert", Message: "invalid client certificate or key", Err: err}
+		}
+	}
+	return nil
+}
+
 func ValidateVictoriaLogsConnection(connFieldPrefix, baseURL string) error {
 	trimmed := strings.TrimSpace(baseURL)
 	if trimmed == "" {
 		return &ValidationError{Field: connFieldPrefix + "base_url", Message: "base_url is required"}
 	}
+	if secrets.IsRef(trimmed) {
+		return nil
+	}
 	parsed, err := url.ParseRequestURI(trimmed)
 	if err != nil {
 		return &ValidationError{Field: connFieldPrefix + "base_url", Message: "base_url must be a valid URL", Err: err}
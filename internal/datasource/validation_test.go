@@ -1,6 +1,11 @@
 package datasource
 
-import "testing"
+import (
+	"errors"
+	"testing"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
 
 func TestValidateClickHouseConnection_AllowsEmptyPassword(t *testing.T) {
 	// validateClickHouseConnection does not take a password argument at all
@@ -30,3 +35,49 @@ func TestValidateClickHouseConnection_RequiresDatabase(t *testing.T) {
 		t.Fatal("expected validation error for missing database")
 	}
 }
+
+func TestValidateClickHouseConnection_Replicas(t *testing.T) {
+	tests := map[string]bool{
+		"ch-1:9000, ch-2:9000,ch-3": true,
+		"ch-1:9000,ch-2:99999":      false,
+		"ch-1:9000,ch-1:9000":       false,
+		" , ":                       false,
+	}
+	for host, valid := range tests {
+		err := validateClickHouseConnection("connection.", true, host, "default", "logs")
+		if (err == nil) != valid {
+			t.Errorf("validateClickHouseConnection(%q) = %v, want valid %v", host, err, valid)
+		}
+	}
+}
+
+func TestValidateClickHouseTransport(t *testing.T) {
+	valid := []models.ConnectionInfo{
+		{},
+		{Protocol: "HTTP"},
+		{Protocol: models.ClickHouseProtocolHTTP, TLSEnable: true, TLSSkipVerify: true},
+	}
+	for _, conn := range valid {
+		if err := validateClickHouseTransport("connection.", conn); err != nil {
+			t.Errorf("%+v: unexpected error %v", conn, err)
+		}
+	}
+
+	invalid := map[string]struct {
+		conn  models.ConnectionInfo
+		field string
+	}{
+		"unknown protocol": {models.ConnectionInfo{Protocol: "https"}, "connection.protocol"},
+		"options sans TLS": {models.ConnectionInfo{TLSSkipVerify: true}, "connection.tls_enable"},
+		"bad CA":           {models.ConnectionInfo{TLSEnable: true, TLSCACert: "nope"}, "connection.tls_ca_cert"},
+		"cert without key": {models.ConnectionInfo{TLSEnable: true, TLSClientCert: "cert"}, "connection.tls_client_key"},
+		"unparseable pair": {models.ConnectionInfo{TLSEnable: true, TLSClientCert: "cert", TLSClientKey: "key"}, "connection.tls_client_cert"},
+	}
+	for name, tc := range invalid {
+		err := validateClickHouseTransport("connection.", tc.conn)
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Field != tc.field {
+			t.Errorf("%s: err = %v, want ValidationError on %s", name, err, tc.field)
+		}
+	}
+}
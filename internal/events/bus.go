@@ -0,0 +1,106 @@
+// Package events is the in-process bus events are published on: a source
+// created, deleted or changing health, an alert firing or resolving, a member
+// added to or removed from a team, a query executed.
+//
+// Code that makes something happen publishes on the default bus
+// unconditionally; with nobody subscribed, publishing does nothing.
+// Cross-cutting subsystems (webhook delivery, metrics, the audit trail)
+// subscribe instead of being called from each place an event happens.
+// Subscribers are called synchronously on the publisher's goroutine, so they
+// must be quick and never block: the webhook dispatcher only queues the event.
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// Handler receives published events.
+type Handler func(models.Event)
+
+// Bus fans events out to its subscribers.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[int]Handler
+	next     int
+}
+
+// NewBus creates a bus with no subscribers.
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[int]Handler)}
+}
+
+// Subscribe registers h for every event published from now on. The returned
+// function removes it.
+func (b *Bus) Subscribe(h Handler) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.next
+	b.next++
+	b.handlers[id] = h
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers, id)
+	}
+}
+
+// Publish stamps an event of type t carrying data with an ID and the current
+// time, and hands it to every subscriber.
+func (b *Bus) Publish(t models.EventType, data any) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.handlers) == 0 {
+		return
+	}
+	event := New(t, data)
+	for _, h := range b.handlers {
+		h(event)
+	}
+}
+
+// On subscribes fn to events of type t, with their data as a T. Events of t
+// carrying some other data are skipped.
+func On[T any](b *Bus, t models.EventType, fn func(models.Event, T)) (unsubscribe func()) {
+	return b.Subscribe(func(event models.Event) {
+		if event.Type != t {
+			return
+		}
+		if data, ok := event.Data.(T); ok {
+			fn(event, data)
+		}
+	})
+}
+
+// New builds an event of type t without publishing it.
+func New(t models.EventType, data any) models.Event {
+	return models.Event{
+		ID:         newID(),
+		Type:       t,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// defaultBus is the process-wide bus core code publishes on.
+var defaultBus = NewBus()
+
+// Default returns the process-wide bus.
+func Default() *Bus {
+	return defaultBus
+}
+
+// Publish publishes on the process-wide bus.
+func Publish(t models.EventType, data any) {
+	defaultBus.Publish(t, data)
+}
@@ -0,0 +1,35 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestBusSubscribe(t *testing.T) {
+	bus := NewBus()
+	var all []models.EventType
+	unsubscribe := bus.Subscribe(func(event models.Event) { all = append(all, event.Type) })
+
+	var sources []models.SourceEventData
+	On(bus, models.EventSourceCreated, func(event models.Event, data models.SourceEventData) {
+		if event.ID == "" || event.OccurredAt.IsZero() {
+			t.Errorf("event not stamped: %+v", event)
+		}
+		sources = append(sources, data)
+	})
+
+	bus.Publish(models.EventSourceCreated, models.SourceEventData{SourceID: 1})
+	bus.Publish(models.EventSourceDeleted, models.SourceEventData{SourceID: 2})
+	// Wrong data for the type is skipped by typed subscribers.
+	bus.Publish(models.EventSourceCreated, models.TeamMemberEventData{TeamID: 3})
+	unsubscribe()
+	bus.Publish(models.EventSourceCreated, models.SourceEventData{SourceID: 4})
+
+	if len(all) != 3 {
+		t.Errorf("Subscribe got %v, want the 3 events before unsubscribing", all)
+	}
+	if len(sources) != 2 || sources[0].SourceID != 1 || sources[1].SourceID != 4 {
+		t.Errorf("On got %+v, want sources 1 and 4", sources)
+	}
+}
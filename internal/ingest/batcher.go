@@ -0,0 +1,168 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// insertTimeout bounds one batch insert.
+const insertTimeout = 30 * time.Second
+
+// ErrOverloaded is returned by Batcher.Add when a source already has the
+// maximum number of rows waiting to be inserted. Callers should ask the
+// sender to retry later.
+var ErrOverloaded = errors.New("ingest queue for this source is full")
+
+// ErrBatcherClosed is returned by Batcher.Add after Close.
+var ErrBatcherClosed = errors.New("ingest batcher is closed")
+
+// InsertFunc inserts JSONEachRow data into a source.
+type InsertFunc func(ctx context.Context, sourceID models.SourceID, data []byte) error
+
+// BatcherOptions configures a Batcher.
+type BatcherOptions struct {
+	// MaxBatchRows triggers an insert as soon as a batch reaches it.
+	MaxBatchRows int
+	// FlushInterval is the longest a row waits for its batch to fill.
+	FlushInterval time.Duration
+	// MaxPendingRows caps the rows per source that are buffered or being
+	// inserted; Add fails with ErrOverloaded beyond it.
+	MaxPendingRows int
+	Insert         InsertFunc
+}
+
+// Batcher merges rows from concurrent requests into one insert per source
+// and interval, so many small pushes don't each become an insert. Add returns
+// once the batch holding the rows has been inserted, so callers still
+// acknowledge only stored data.
+type Batcher struct {
+	opts BatcherOptions
+
+	mu     sync.Mutex
+	queues map[models.SourceID]*sourceQueue
+	closed bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+type sourceQueue struct {
+	open    *pendingBatch // the batch Add appends to; nil when empty
+	pending int           // rows in open plus rows being inserted
+	full    chan struct{} // signals that open reached MaxBatchRows
+}
+
+type pendingBatch struct {
+	data []byte
+	rows int
+	done chan struct{} // closed once err is set
+	err  error
+}
+
+// NewBatcher constructs a Batcher. Per-source flush loops start on first use.
+func NewBatcher(opts BatcherOptions) *Batcher {
+	return &Batcher{
+		opts:   opts,
+		queues: make(map[models.SourceID]*sourceQueue),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Add queues batch for sourceID and waits for it to be inserted. If ctx ends
+// first Add returns ctx.Err(), but the rows are still inserted with their
+// batch.
+func (b *Batcher) Add(ctx context.Context, sourceID models.SourceID, batch *Batch) error {
+	if batch.Rows == 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBatcherClosed
+	}
+	q, ok := b.queues[sourceID]
+	if !ok {
+		q = &sourceQueue{full: make(chan struct{}, 1)}
+		b.queues[sourceID] = q
+		b.wg.Go(func() { b.run(sourceID, q) })
+	}
+	if q.pending+batch.Rows > b.opts.MaxPendingRows {
+		b.mu.Unlock()
+		return ErrOverloaded
+	}
+	if q.open == nil {
+		q.open = &pendingBatch{done: make(chan struct{})}
+	}
+	pb := q.open
+	pb.data = append(pb.data, batch.Data...)
+	pb.rows += batch.Rows
+	q.pending += batch.Rows
+	if pb.rows >= b.opts.MaxBatchRows {
+		select {
+		case q.full <- struct{}{}:
+		default:
+		}
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-pb.done:
+		return pb.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting rows, inserts what is buffered and waits for the
+// flush loops to exit.
+func (b *Batcher) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	b.mu.Unlock()
+	close(b.stop)
+	b.wg.Wait()
+}
+
+func (b *Batcher) run(sourceID models.SourceID, q *sourceQueue) {
+	ticker := time.NewTicker(b.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.full:
+			b.flush(sourceID, q)
+		case <-ticker.C:
+			b.flush(sourceID, q)
+		case <-b.stop:
+			b.flush(sourceID, q)
+			return
+		}
+	}
+}
+
+func (b *Batcher) flush(sourceID models.SourceID, q *sourceQueue) {
+	b.mu.Lock()
+	pb := q.open
+	q.open = nil
+	b.mu.Unlock()
+	if pb == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), insertTimeout)
+	pb.err = b.opts.Insert(ctx, sourceID, pb.data)
+	cancel()
+	close(pb.done)
+
+	b.mu.Lock()
+	q.pending -= pb.rows
+	b.mu.Unlock()
+}
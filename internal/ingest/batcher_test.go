@@ -0,0 +1,103 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+type recordingInserter struct {
+	mu      sync.Mutex
+	inserts []string
+	started chan struct{} // when set, receives once per insert
+	release chan struct{} // when set, inserts block until it is closed
+	err     error
+}
+
+func (r *recordingInserter) insert(_ context.Context, _ models.SourceID, data []byte) error {
+	if r.started != nil {
+		r.started <- struct{}{}
+	}
+	if r.release != nil {
+		<-r.release
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inserts = append(r.inserts, string(data))
+	return r.err
+}
+
+func rowsBatch(n int) *Batch {
+	return &Batch{Data: []byte(strings.Repeat("{}\n", n)), Rows: n}
+}
+
+func TestBatcherMergesConcurrentRequests(t *testing.T) {
+	rec := &recordingInserter{}
+	b := NewBatcher(BatcherOptions{MaxBatchRows: 4, FlushInterval: time.Hour, MaxPendingRows: 100, Insert: rec.insert})
+	defer b.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for range 4 {
+		wg.Go(func() { errs <- b.Add(context.Background(), 1, rowsBatch(1)) })
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	if len(rec.inserts) != 1 || rec.inserts[0] != strings.Repeat("{}\n", 4) {
+		t.Errorf("inserts = %q, want one insert of 4 rows", rec.inserts)
+	}
+}
+
+func TestBatcherFlushesOnInterval(t *testing.T) {
+	rec := &recordingInserter{err: errors.New("clickhouse down")}
+	b := NewBatcher(BatcherOptions{MaxBatchRows: 100, FlushInterval: 10 * time.Millisecond, MaxPendingRows: 100, Insert: rec.insert})
+	defer b.Close()
+
+	if err := b.Add(context.Background(), 1, rowsBatch(2)); err == nil || err.Error() != "clickhouse down" {
+		t.Errorf("Add() error = %v, want the insert error", err)
+	}
+}
+
+func TestBatcherBackpressure(t *testing.T) {
+	rec := &recordingInserter{started: make(chan struct{}, 2), release: make(chan struct{})}
+	b := NewBatcher(BatcherOptions{MaxBatchRows: 3, FlushInterval: time.Hour, MaxPendingRows: 5, Insert: rec.insert})
+
+	first := make(chan error, 1)
+	go func() { first <- b.Add(context.Background(), 1, rowsBatch(3)) }()
+
+	// The first batch is full and stuck inserting; 3 more rows would exceed 5.
+	<-rec.started
+	if err := b.Add(context.Background(), 1, rowsBatch(3)); !errors.Is(err, ErrOverloaded) {
+		t.Fatalf("Add() error = %v, want ErrOverloaded", err)
+	}
+
+	// Other sources have their own budget. Cancel the wait; the rows are
+	// still inserted on Close.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.Add(ctx, 2, rowsBatch(3)); !errors.Is(err, context.Canceled) {
+		t.Errorf("other source: Add() error = %v, want context.Canceled", err)
+	}
+
+	close(rec.release)
+	if err := <-first; err != nil {
+		t.Errorf("first Add() error = %v", err)
+	}
+	b.Close()
+	if err := b.Add(context.Background(), 1, rowsBatch(1)); !errors.Is(err, ErrBatcherClosed) {
+		t.Errorf("after Close: Add() error = %v, want ErrBatcherClosed", err)
+	}
+	if len(rec.inserts) != 2 {
+		t.Errorf("inserts = %d, want 2 (both sources flushed)", len(rec.inserts))
+	}
+}
@@ -0,0 +1,113 @@
+// Package ingest decodes log batches pushed to LogChef into ClickHouse
+// JSONEachRow data for a source's table.
+//
+// Two formats are accepted. NDJSON passes each object through as a row, so
+// its keys must match the table's columns (other keys are skipped at insert).
+// OTLP-JSON, the JSON encoding of an OpenTelemetry ExportLogsServiceRequest,
+// is mapped onto the columns of LogChef's default OTel logs table.
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// Format is the encoding of a pushed batch.
+type Format string
+
+const (
+	FormatNDJSON Format = "ndjson"
+	FormatOTLP   Format = "otlp"
+)
+
+// ErrTooManyRows is returned when a batch holds more rows than allowed.
+var ErrTooManyRows = errors.New("batch exceeds the maximum number of rows")
+
+// DecodeError reports a malformed batch. Line is the 1-based NDJSON line, or
+// 0 for OTLP-JSON.
+type DecodeError struct {
+	Line    int
+	Message string
+}
+
+func (e *DecodeError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return e.Message
+}
+
+// Batch is a decoded batch: JSONEachRow data, one object per line.
+type Batch struct {
+	Data []byte
+	Rows int
+}
+
+// FormatForContentType picks the format for a request's Content-Type:
+// NDJSON for application/x-ndjson (and its jsonl aliases), OTLP-JSON for
+// application/json.
+func FormatForContentType(contentType string) (Format, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(mediaType) {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return FormatNDJSON, true
+	case "application/json":
+		return FormatOTLP, true
+	}
+	return "", false
+}
+
+// Decode converts body into JSONEachRow data. Empty batches decode to zero
+// rows.
+func Decode(format Format, body []byte, maxRows int) (*Batch, error) {
+	switch format {
+	case FormatNDJSON:
+		return decodeNDJSON(body, maxRows)
+	case FormatOTLP:
+		rows, err := DecodeOTLPJSON(body, maxRows)
+		if err != nil {
+			return nil, err
+		}
+		return EncodeRows(rows), nil
+	}
+	return nil, fmt.Errorf("unsupported ingest format %q", format)
+}
+
+func decodeNDJSON(body []byte, maxRows int) (*Batch, error) {
+	batch := &Batch{Data: make([]byte, 0, len(body))}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(body)+1)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		if raw[0] != '{' {
+			return nil, &DecodeError{Line: line, Message: "each line must be a JSON object"}
+		}
+		if maxRows > 0 && batch.Rows >= maxRows {
+			return nil, ErrTooManyRows
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, raw); err != nil {
+			return nil, &DecodeError{Line: line, Message: "invalid JSON: " + err.Error()}
+		}
+		batch.Data = append(batch.Data, compact.Bytes()...)
+		batch.Data = append(batch.Data, '\n')
+		batch.Rows++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &DecodeError{Line: line + 1, Message: err.Error()}
+	}
+	return batch, nil
+}
@@ -0,0 +1,176 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatForContentType(t *testing.T) {
+	cases := []struct {
+		contentType string
+		want        Format
+		ok          bool
+	}{
+		{"application/x-ndjson", FormatNDJSON, true},
+		{"application/jsonl; charset=utf-8", FormatNDJSON, true},
+		{"application/json", FormatOTLP, true},
+		{"Application/JSON; charset=utf-8", FormatOTLP, true},
+		{"text/plain", "", false},
+		{"", "", false},
+	}
+	for _, tc := range cases {
+		got, ok := FormatForContentType(tc.contentType)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("FormatForContentType(%q) = %q, %v; want %q, %v", tc.contentType, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestDecodeNDJSON(t *testing.T) {
+	body := "{\"msg\": \"a\", \"level\": \"info\"}\n\n  {\"msg\":\"b\"}  \r\n"
+	batch, err := Decode(FormatNDJSON, []byte(body), 10)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if batch.Rows != 2 {
+		t.Fatalf("Rows = %d, want 2", batch.Rows)
+	}
+	want := "{\"msg\":\"a\",\"level\":\"info\"}\n{\"msg\":\"b\"}\n"
+	if string(batch.Data) != want {
+		t.Fatalf("Data = %q, want %q", batch.Data, want)
+	}
+}
+
+func TestDecodeNDJSONErrors(t *testing.T) {
+	_, err := Decode(FormatNDJSON, []byte("{\"a\":1}\n[1,2]\n"), 10)
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Line != 2 {
+		t.Fatalf("non-object line: err = %v, want DecodeError on line 2", err)
+	}
+
+	_, err = Decode(FormatNDJSON, []byte("{\"a\":1}\n{\"a\":\n"), 10)
+	if !errors.As(err, &decodeErr) || decodeErr.Line != 2 {
+		t.Fatalf("truncated line: err = %v, want DecodeError on line 2", err)
+	}
+
+	_, err = Decode(FormatNDJSON, []byte(strings.Repeat("{}\n", 3)), 2)
+	if !errors.Is(err, ErrTooManyRows) {
+		t.Fatalf("row cap: err = %v, want ErrTooManyRows", err)
+	}
+}
+
+func TestDecodeOTLPJSON(t *testing.T) {
+	body := `{
+	  "resourceLogs": [{
+	    "resource": {"attributes": [
+	      {"key": "service.name", "value": {"stringValue": "checkout"}},
+	      {"key": "k8s.namespace.name", "value": {"stringValue": "shop"}},
+	      {"key": "host.name", "value": {"stringValue": "node-1"}}
+	    ]},
+	    "scopeLogs": [{
+	      "scope": {"name": "app.logger"},
+	      "logRecords": [
+	        {
+	          "timeUnixNano": "1700000000123456789",
+	          "severityNumber": 17,
+	          "body": {"stringValue": "payment failed"},
+	          "traceId": "5B8EFFF798038103D269B633813FC60C",
+	          "spanId": "EEE19B7EC3C1B174",
+	          "flags": 1,
+	          "attributes": [
+	            {"key": "http.status_code", "value": {"intValue": "502"}},
+	            {"key": "host.name", "value": {"stringValue": "override"}}
+	          ]
+	        },
+	        {
+	          "observedTimeUnixNano": 1700000001000000000,
+	          "severityText": "Notice",
+	          "body": {"kvlistValue": {"values": [{"key": "k", "value": {"boolValue": true}}]}}
+	        }
+	      ]
+	    }]
+	  }]
+	}`
+
+	rows, err := DecodeOTLPJSON([]byte(body), 10)
+	if err != nil {
+		t.Fatalf("DecodeOTLPJSON() error = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("len(rows) = %d, want 2", len(rows))
+	}
+
+	first := rows[0]
+	if !first.Timestamp.Equal(time.Unix(0, 1700000000123456789)) {
+		t.Errorf("Timestamp = %v", first.Timestamp)
+	}
+	if first.ServiceName != "checkout" || first.Namespace != "shop" {
+		t.Errorf("ServiceName, Namespace = %q, %q", first.ServiceName, first.Namespace)
+	}
+	if first.SeverityText != "ERROR" || first.SeverityNumber != 17 {
+		t.Errorf("severity = %q/%d, want ERROR/17", first.SeverityText, first.SeverityNumber)
+	}
+	if first.TraceID != "5b8efff798038103d269b633813fc60c" || first.SpanID != "eee19b7ec3c1b174" || first.TraceFlags != 1 {
+		t.Errorf("trace context = %q %q %d", first.TraceID, first.SpanID, first.TraceFlags)
+	}
+	if first.Body != "payment failed" {
+		t.Errorf("Body = %q", first.Body)
+	}
+	wantAttrs := map[string]string{
+		"k8s.namespace.name": "shop",
+		"host.name":          "override",
+		"scope.name":         "app.logger",
+		"http.status_code":   "502",
+	}
+	if len(first.LogAttributes) != len(wantAttrs) {
+		t.Errorf("LogAttributes = %v, want %v", first.LogAttributes, wantAttrs)
+	}
+	for k, v := range wantAttrs {
+		if first.LogAttributes[k] != v {
+			t.Errorf("LogAttributes[%q] = %q, want %q", k, first.LogAttributes[k], v)
+		}
+	}
+
+	second := rows[1]
+	if !second.Timestamp.Equal(time.Unix(0, 1700000001000000000)) {
+		t.Errorf("observed-time fallback: Timestamp = %v", second.Timestamp)
+	}
+	if second.SeverityText != "Notice" {
+		t.Errorf("SeverityText = %q, want sender's text", second.SeverityText)
+	}
+	if second.Body != `{"k":true}` {
+		t.Errorf("kvlist Body = %q", second.Body)
+	}
+
+	if _, err := DecodeOTLPJSON([]byte(body), 1); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("row cap: err = %v, want ErrTooManyRows", err)
+	}
+	var decodeErr *DecodeError
+	if _, err := DecodeOTLPJSON([]byte(`{"resourceLogs": 3}`), 10); !errors.As(err, &decodeErr) {
+		t.Errorf("malformed body: err = %v, want DecodeError", err)
+	}
+}
+
+func TestEncodeRows(t *testing.T) {
+	batch := EncodeRows([]Row{{
+		Timestamp:    time.Date(2026, 1, 2, 3, 4, 5, 600, time.FixedZone("IST", 19800)),
+		SeverityText: "INFO",
+		Body:         "hello",
+	}})
+	if batch.Rows != 1 {
+		t.Fatalf("Rows = %d, want 1", batch.Rows)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(batch.Data, &got); err != nil {
+		t.Fatalf("row is not JSON: %v (%q)", err, batch.Data)
+	}
+	if got["timestamp"] != "2026-01-01T21:34:05.0000006Z" {
+		t.Errorf("timestamp = %v, want UTC RFC 3339", got["timestamp"])
+	}
+	if attrs, ok := got["log_attributes"].(map[string]any); !ok || len(attrs) != 0 {
+		t.Errorf("log_attributes = %v, want empty object", got["log_attributes"])
+	}
+}
@@ -0,0 +1,246 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The OTLP/JSON encoding of an ExportLogsServiceRequest. 64-bit integers may
+// be sent as strings or numbers, trace and span IDs are hex, and enums are
+// numbers.
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	} `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpScopeLogs struct {
+	Scope struct {
+		Name       string         `json:"name"`
+		Attributes []otlpKeyValue `json:"attributes"`
+	} `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         otlpInt        `json:"timeUnixNano"`
+	ObservedTimeUnixNano otlpInt        `json:"observedTimeUnixNano"`
+	SeverityNumber       int32          `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes"`
+	Flags                uint32         `json:"flags"`
+	TraceID              string         `json:"traceId"`
+	SpanID               string         `json:"spanId"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string          `json:"stringValue"`
+	BoolValue   *bool            `json:"boolValue"`
+	IntValue    *otlpInt         `json:"intValue"`
+	DoubleValue *float64         `json:"doubleValue"`
+	BytesValue  *string          `json:"bytesValue"`
+	ArrayValue  *otlpArrayValue  `json:"arrayValue"`
+	KvlistValue *otlpKvlistValue `json:"kvlistValue"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+type otlpKvlistValue struct {
+	Values []otlpKeyValue `json:"values"`
+}
+
+// otlpInt is an int64 sent either as a JSON string or number.
+type otlpInt int64
+
+func (n *otlpInt) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		*n = 0
+		return nil
+	}
+	v, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return err
+	}
+	*n = otlpInt(v)
+	return nil
+}
+
+// String renders a value for a String column or a Map(String, String) value:
+// scalars as text, arrays and maps as JSON.
+func (v otlpAnyValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BytesValue != nil:
+		return *v.BytesValue
+	case v.ArrayValue == nil && v.KvlistValue == nil:
+		if native := v.native(); native != nil {
+			return scalarString(native)
+		}
+		return ""
+	}
+	data, _ := json.Marshal(v.native())
+	return string(data)
+}
+
+func (v otlpAnyValue) native() any {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return *v.BoolValue
+	case v.IntValue != nil:
+		return int64(*v.IntValue)
+	case v.DoubleValue != nil:
+		return *v.DoubleValue
+	case v.BytesValue != nil:
+		return *v.BytesValue
+	case v.ArrayValue != nil:
+		values := make([]any, len(v.ArrayValue.Values))
+		for i, item := range v.ArrayValue.Values {
+			values[i] = item.native()
+		}
+		return values
+	case v.KvlistValue != nil:
+		values := make(map[string]any, len(v.KvlistValue.Values))
+		for _, kv := range v.KvlistValue.Values {
+			values[kv.Key] = kv.Value.native()
+		}
+		return values
+	}
+	return nil
+}
+
+func scalarString(v any) string {
+	switch v := v.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return ""
+}
+
+// DecodeOTLPJSON maps an OTLP/JSON logs request onto OTel table rows.
+func DecodeOTLPJSON(body []byte, maxRows int) ([]Row, error) {
+	var req otlpLogsRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, &DecodeError{Message: "invalid OTLP-JSON: " + err.Error()}
+	}
+	return req.rows(maxRows)
+}
+
+// rows maps a decoded request onto OTel table rows. Resource, scope and log
+// record attributes are merged into log_attributes, the most specific
+// winning; service.name and the namespace fill their own columns.
+func (req *otlpLogsRequest) rows(maxRows int) ([]Row, error) {
+	var rows []Row
+	now := time.Now()
+	for _, rl := range req.ResourceLogs {
+		resource := attributeMap(rl.Resource.Attributes)
+		serviceName := resource["service.name"]
+		namespace := resource["service.namespace"]
+		if namespace == "" {
+			namespace = resource["k8s.namespace.name"]
+		}
+		delete(resource, "service.name")
+
+		for _, sl := range rl.ScopeLogs {
+			scope := attributeMap(sl.Scope.Attributes)
+			if sl.Scope.Name != "" {
+				scope["scope.name"] = sl.Scope.Name
+			}
+			for _, rec := range sl.LogRecords {
+				if maxRows > 0 && len(rows) >= maxRows {
+					return nil, ErrTooManyRows
+				}
+				attrs := make(map[string]string, len(resource)+len(scope)+len(rec.Attributes))
+				for k, v := range resource {
+					attrs[k] = v
+				}
+				for k, v := range scope {
+					attrs[k] = v
+				}
+				for _, kv := range rec.Attributes {
+					attrs[kv.Key] = kv.Value.String()
+				}
+
+				rows = append(rows, Row{
+					Timestamp:      recordTime(rec, now),
+					TraceID:        strings.ToLower(rec.TraceID),
+					SpanID:         strings.ToLower(rec.SpanID),
+					TraceFlags:     rec.Flags,
+					SeverityText:   severityText(rec.SeverityText, rec.SeverityNumber),
+					SeverityNumber: rec.SeverityNumber,
+					ServiceName:    serviceName,
+					Namespace:      namespace,
+					Body:           rec.Body.String(),
+					LogAttributes:  attrs,
+				})
+			}
+		}
+	}
+	return rows, nil
+}
+
+func attributeMap(kvs []otlpKeyValue) map[string]string {
+	m := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value.String()
+	}
+	return m
+}
+
+// recordTime is the event time, falling back to when the collector observed
+// the record and then to now.
+func recordTime(rec otlpLogRecord, now time.Time) time.Time {
+	if rec.TimeUnixNano > 0 {
+		return time.Unix(0, int64(rec.TimeUnixNano))
+	}
+	if rec.ObservedTimeUnixNano > 0 {
+		return time.Unix(0, int64(rec.ObservedTimeUnixNano))
+	}
+	return now
+}
+
+// severityText keeps the sender's text, or derives the OTel short name from
+// the severity number's range.
+func severityText(text string, number int32) string {
+	if text != "" {
+		return text
+	}
+	switch {
+	case number >= 21:
+		return "FATAL"
+	case number >= 17:
+		return "ERROR"
+	case number >= 13:
+		return "WARN"
+	case number >= 9:
+		return "INFO"
+	case number >= 5:
+		return "DEBUG"
+	case number >= 1:
+		return "TRACE"
+	}
+	return ""
+}
@@ -0,0 +1,245 @@
+package ingest
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// DecodeOTLPProto maps a binary protobuf ExportLogsServiceRequest, as sent by
+// OTLP/HTTP exporters, onto OTel table rows. Only the fields LogChef stores
+// are read; the rest are skipped. It decodes into the same structure as
+// DecodeOTLPJSON, with trace and span IDs hex-encoded and bytes values
+// base64-encoded as in OTLP/JSON.
+func DecodeOTLPProto(body []byte, maxRows int) ([]Row, error) {
+	var req otlpLogsRequest
+	err := walkMessage(body, func(field int, v wireValue) error {
+		if field != 1 {
+			return nil
+		}
+		var rl otlpResourceLogs
+		if err := decodeResourceLogs(v.bytes, &rl); err != nil {
+			return err
+		}
+		req.ResourceLogs = append(req.ResourceLogs, rl)
+		return nil
+	})
+	if err != nil {
+		return nil, &DecodeError{Message: "invalid OTLP protobuf: " + err.Error()}
+	}
+	return req.rows(maxRows)
+}
+
+// Field numbers below follow opentelemetry/proto/logs/v1/logs.proto and
+// opentelemetry/proto/common/v1/common.proto.
+
+func decodeResourceLogs(b []byte, rl *otlpResourceLogs) error {
+	return walkMessage(b, func(field int, v wireValue) error {
+		switch field {
+		case 1: // resource
+			return walkMessage(v.bytes, func(field int, v wireValue) error {
+				if field == 1 {
+					return appendKeyValue(v.bytes, &rl.Resource.Attributes, 0)
+				}
+				return nil
+			})
+		case 2: // scope_logs
+			var sl otlpScopeLogs
+			if err := decodeScopeLogs(v.bytes, &sl); err != nil {
+				return err
+			}
+			rl.ScopeLogs = append(rl.ScopeLogs, sl)
+		}
+		return nil
+	})
+}
+
+func decodeScopeLogs(b []byte, sl *otlpScopeLogs) error {
+	return walkMessage(b, func(field int, v wireValue) error {
+		switch field {
+		case 1: // scope
+			return walkMessage(v.bytes, func(field int, v wireValue) error {
+				switch field {
+				case 1:
+					sl.Scope.Name = string(v.bytes)
+				case 3:
+					return appendKeyValue(v.bytes, &sl.Scope.Attributes, 0)
+				}
+				return nil
+			})
+		case 2: // log_records
+			var rec otlpLogRecord
+			if err := decodeLogRecord(v.bytes, &rec); err != nil {
+				return err
+			}
+			sl.LogRecords = append(sl.LogRecords, rec)
+		}
+		return nil
+	})
+}
+
+func decodeLogRecord(b []byte, rec *otlpLogRecord) error {
+	return walkMessage(b, func(field int, v wireValue) error {
+		switch field {
+		case 1:
+			rec.TimeUnixNano = otlpInt(v.num)
+		case 11:
+			rec.ObservedTimeUnixNano = otlpInt(v.num)
+		case 2:
+			rec.SeverityNumber = int32(v.num)
+		case 3:
+			rec.SeverityText = string(v.bytes)
+		case 5:
+			return decodeAnyValue(v.bytes, &rec.Body, 0)
+		case 6:
+			return appendKeyValue(v.bytes, &rec.Attributes, 0)
+		case 8:
+			rec.Flags = uint32(v.num)
+		case 9:
+			rec.TraceID = hex.EncodeToString(v.bytes)
+		case 10:
+			rec.SpanID = hex.EncodeToString(v.bytes)
+		}
+		return nil
+	})
+}
+
+func appendKeyValue(b []byte, kvs *[]otlpKeyValue, depth int) error {
+	var kv otlpKeyValue
+	err := walkMessage(b, func(field int, v wireValue) error {
+		switch field {
+		case 1:
+			kv.Key = string(v.bytes)
+		case 2:
+			return decodeAnyValue(v.bytes, &kv.Value, depth)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*kvs = append(*kvs, kv)
+	return nil
+}
+
+// maxValueDepth bounds nested array and kvlist values, so a crafted body
+// can't recurse without limit.
+const maxValueDepth = 32
+
+func decodeAnyValue(b []byte, av *otlpAnyValue, depth int) error {
+	if depth > maxValueDepth {
+		return errors.New("values nested too deeply")
+	}
+	return walkMessage(b, func(field int, v wireValue) error {
+		switch field {
+		case 1:
+			s := string(v.bytes)
+			av.StringValue = &s
+		case 2:
+			bv := v.num != 0
+			av.BoolValue = &bv
+		case 3:
+			iv := otlpInt(int64(v.num))
+			av.IntValue = &iv
+		case 4:
+			dv := math.Float64frombits(v.num)
+			av.DoubleValue = &dv
+		case 5:
+			av.ArrayValue = &otlpArrayValue{}
+			return walkMessage(v.bytes, func(field int, v wireValue) error {
+				if field != 1 {
+					return nil
+				}
+				var item otlpAnyValue
+				if err := decodeAnyValue(v.bytes, &item, depth+1); err != nil {
+					return err
+				}
+				av.ArrayValue.Values = append(av.ArrayValue.Values, item)
+				return nil
+			})
+		case 6:
+			av.KvlistValue = &otlpKvlistValue{}
+			return walkMessage(v.bytes, func(field int, v wireValue) error {
+				if field == 1 {
+					return appendKeyValue(v.bytes, &av.KvlistValue.Values, depth+1)
+				}
+				return nil
+			})
+		case 7:
+			s := base64.StdEncoding.EncodeToString(v.bytes)
+			av.BytesValue = &s
+		}
+		return nil
+	})
+}
+
+// wireValue is one decoded field: num holds varint and fixed-width values,
+// bytes holds length-delimited ones.
+type wireValue struct {
+	num   uint64
+	bytes []byte
+}
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("truncated message")
+
+// walkMessage calls fn for each field of a protobuf message in order.
+// Repeated fields are reported once per element.
+func walkMessage(b []byte, fn func(field int, v wireValue) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errTruncated
+		}
+		b = b[n:]
+		field, wireType := int(tag>>3), int(tag&7)
+		if field <= 0 {
+			return fmt.Errorf("invalid field number %d", field)
+		}
+
+		var v wireValue
+		switch wireType {
+		case wireVarint:
+			v.num, n = binary.Uvarint(b)
+			if n <= 0 {
+				return errTruncated
+			}
+			b = b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return errTruncated
+			}
+			v.num = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return errTruncated
+			}
+			v.num = uint64(binary.LittleEndian.Uint32(b))
+			b = b[4:]
+		case wireBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || size > uint64(len(b)-n) {
+				return errTruncated
+			}
+			v.bytes = b[n : n+int(size)]
+			b = b[n+int(size):]
+		default:
+			return fmt.Errorf("unsupported wire type %d", wireType)
+		}
+		if err := fn(field, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
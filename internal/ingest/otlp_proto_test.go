@@ -0,0 +1,125 @@
+package ingest
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// Minimal protobuf encoders for building test requests.
+
+func pbTag(b []byte, field, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wireType))
+}
+
+func pbBytes(b []byte, field int, v []byte) []byte {
+	b = pbTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func pbString(b []byte, field int, v string) []byte { return pbBytes(b, field, []byte(v)) }
+
+func pbVarint(b []byte, field int, v uint64) []byte {
+	return binary.AppendUvarint(pbTag(b, field, wireVarint), v)
+}
+
+func pbFixed64(b []byte, field int, v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(pbTag(b, field, wireFixed64), v)
+}
+
+func pbFixed32(b []byte, field int, v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(pbTag(b, field, wireFixed32), v)
+}
+
+func pbKeyValue(key string, value []byte) []byte {
+	return pbBytes(pbString(nil, 1, key), 2, value)
+}
+
+func TestDecodeOTLPProto(t *testing.T) {
+	resource := pbBytes(nil, 1, pbKeyValue("service.name", pbString(nil, 1, "checkout")))
+	resource = pbBytes(resource, 1, pbKeyValue("service.namespace", pbString(nil, 1, "shop")))
+
+	scope := pbString(nil, 1, "app.logger")
+	scope = pbString(scope, 2, "1.0.0") // version: not stored
+
+	nested := pbBytes(nil, 1, pbKeyValue("ok", pbVarint(nil, 2, 1)))
+	record := pbFixed64(nil, 1, 1700000000123456789)
+	record = pbVarint(record, 2, 13)
+	record = pbBytes(record, 5, pbString(nil, 1, "slow request"))
+	record = pbBytes(record, 6, pbKeyValue("http.status_code", pbVarint(nil, 3, 200)))
+	record = pbBytes(record, 6, pbKeyValue("latency", pbFixed64(nil, 4, math.Float64bits(1.5))))
+	record = pbBytes(record, 6, pbKeyValue("detail", pbBytes(nil, 6, nested)))
+	record = pbFixed32(record, 8, 1)
+	record = pbBytes(record, 9, []byte{0x5b, 0x8e, 0xff, 0xf7, 0x98, 0x03, 0x81, 0x03, 0xd2, 0x69, 0xb6, 0x33, 0x81, 0x3f, 0xc6, 0x0c})
+	record = pbBytes(record, 10, []byte{0xee, 0xe1, 0x9b, 0x7e, 0xc3, 0xc1, 0xb1, 0x74})
+	record = pbString(record, 12, "event.name") // event_name: not stored
+
+	scopeLogs := pbBytes(nil, 1, scope)
+	scopeLogs = pbBytes(scopeLogs, 2, record)
+	resourceLogs := pbBytes(nil, 1, resource)
+	resourceLogs = pbBytes(resourceLogs, 2, scopeLogs)
+	body := pbBytes(nil, 1, resourceLogs)
+
+	rows, err := DecodeOTLPProto(body, 10)
+	if err != nil {
+		t.Fatalf("DecodeOTLPProto() error = %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("len(rows) = %d, want 1", len(rows))
+	}
+	row := rows[0]
+	if !row.Timestamp.Equal(time.Unix(0, 1700000000123456789)) {
+		t.Errorf("Timestamp = %v", row.Timestamp)
+	}
+	if row.ServiceName != "checkout" || row.Namespace != "shop" {
+		t.Errorf("ServiceName, Namespace = %q, %q", row.ServiceName, row.Namespace)
+	}
+	if row.SeverityText != "WARN" || row.SeverityNumber != 13 {
+		t.Errorf("severity = %q/%d, want WARN/13", row.SeverityText, row.SeverityNumber)
+	}
+	if row.TraceID != "5b8efff798038103d269b633813fc60c" || row.SpanID != "eee19b7ec3c1b174" || row.TraceFlags != 1 {
+		t.Errorf("trace context = %q %q %d", row.TraceID, row.SpanID, row.TraceFlags)
+	}
+	if row.Body != "slow request" {
+		t.Errorf("Body = %q", row.Body)
+	}
+	want := map[string]string{
+		"service.namespace": "shop",
+		"scope.name":        "app.logger",
+		"http.status_code":  "200",
+		"latency":           "1.5",
+		"detail":            `{"ok":true}`,
+	}
+	if len(row.LogAttributes) != len(want) {
+		t.Errorf("LogAttributes = %v, want %v", row.LogAttributes, want)
+	}
+	for k, v := range want {
+		if row.LogAttributes[k] != v {
+			t.Errorf("LogAttributes[%q] = %q, want %q", k, row.LogAttributes[k], v)
+		}
+	}
+
+	if _, err := DecodeOTLPProto(body, 0); err != nil {
+		t.Errorf("no row cap: err = %v", err)
+	}
+}
+
+func TestDecodeOTLPProtoErrors(t *testing.T) {
+	var decodeErr *DecodeError
+	if _, err := DecodeOTLPProto([]byte{0x0a, 0x05, 0x01}, 10); !errors.As(err, &decodeErr) {
+		t.Errorf("truncated body: err = %v, want DecodeError", err)
+	}
+
+	value := pbString(nil, 1, "leaf")
+	for range maxValueDepth + 2 {
+		value = pbBytes(nil, 5, pbBytes(nil, 1, value))
+	}
+	record := pbBytes(nil, 5, value)
+	body := pbBytes(nil, 1, pbBytes(nil, 2, pbBytes(nil, 2, record)))
+	if _, err := DecodeOTLPProto(body, 10); !errors.As(err, &decodeErr) {
+		t.Errorf("deep nesting: err = %v, want DecodeError", err)
+	}
+}
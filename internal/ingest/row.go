@@ -0,0 +1,68 @@
+package ingest
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Row is one log in the layout of models.OTELLogsTableSchema.
+type Row struct {
+	Timestamp      time.Time
+	TraceID        string
+	SpanID         string
+	TraceFlags     uint32
+	SeverityText   string
+	SeverityNumber int32
+	ServiceName    string
+	Namespace      string
+	Body           string
+	LogAttributes  map[string]string
+}
+
+// rowJSON is Row with the OTel table's column names.
+type rowJSON struct {
+	Timestamp      string            `json:"timestamp"`
+	TraceID        string            `json:"trace_id"`
+	SpanID         string            `json:"span_id"`
+	TraceFlags     uint32            `json:"trace_flags"`
+	SeverityText   string            `json:"severity_text"`
+	SeverityNumber int32             `json:"severity_number"`
+	ServiceName    string            `json:"service_name"`
+	Namespace      string            `json:"namespace"`
+	Body           string            `json:"body"`
+	LogAttributes  map[string]string `json:"log_attributes"`
+}
+
+// MarshalJSON renders the row as a JSONEachRow object.
+func (r Row) MarshalJSON() ([]byte, error) {
+	attrs := r.LogAttributes
+	if attrs == nil {
+		attrs = map[string]string{}
+	}
+	return json.Marshal(rowJSON{
+		Timestamp:      r.Timestamp.UTC().Format(time.RFC3339Nano),
+		TraceID:        r.TraceID,
+		SpanID:         r.SpanID,
+		TraceFlags:     r.TraceFlags,
+		SeverityText:   r.SeverityText,
+		SeverityNumber: r.SeverityNumber,
+		ServiceName:    r.ServiceName,
+		Namespace:      r.Namespace,
+		Body:           r.Body,
+		LogAttributes:  attrs,
+	})
+}
+
+// EncodeRows renders rows as JSONEachRow data.
+func EncodeRows(rows []Row) *Batch {
+	batch := &Batch{}
+	for _, row := range rows {
+		// Row holds only strings, numbers and a string map, so encoding
+		// cannot fail.
+		data, _ := row.MarshalJSON()
+		batch.Data = append(batch.Data, data...)
+		batch.Data = append(batch.Data, '\n')
+		batch.Rows++
+	}
+	return batch
+}
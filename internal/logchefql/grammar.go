@@ -2,6 +2,8 @@ package logchefql
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/alecthomas/participle/v2"
@@ -74,8 +76,12 @@ type PAndTail struct {
 	Right *PTerm `parser:"'and':Ident @@"`
 }
 
-// PTerm is either a grouped expression or a comparison
+// PTerm is either a grouped expression or a comparison, optionally negated
+// with "not". Not binds tighter than and/or and isn't repeatable without
+// parentheses, so it adds no recursion beyond what maxParenNestingDepth bounds.
+// A "not" followed by an operator or a dot is a field named not.
 type PTerm struct {
+	Not        bool         `parser:"( @'not':Ident (?! Operator | Dot) )?"`
 	Group      *POrExpr     `parser:"( LParen @@ RParen"`
 	Comparison *PComparison `parser:"| @@ )"`
 }
@@ -87,6 +93,8 @@ type PComparison struct {
 }
 
 type PFieldPath struct {
+	// Pos is filled in by participle and locates the field for diagnostics.
+	Pos   lexer.Position
 	First *PPathSegment   `parser:"@@"`
 	Rest  []*PPathSegment `parser:"( Dot @@ )*"`
 }
@@ -254,12 +262,16 @@ func convertTerm(term *PTerm) ASTNode {
 		return nil
 	}
 
+	var node ASTNode
 	if term.Group != nil {
-		inner := convertOrExpr(term.Group)
-		return &GroupNode{Children: []ASTNode{inner}}
+		node = &GroupNode{Children: []ASTNode{convertOrExpr(term.Group)}}
+	} else {
+		node = convertComparison(term.Comparison)
 	}
-
-	return convertComparison(term.Comparison)
+	if term.Not {
+		return &NotNode{Child: node}
+	}
+	return node
 }
 
 func convertComparison(cmp *PComparison) ASTNode {
@@ -270,6 +282,11 @@ func convertComparison(cmp *PComparison) ASTNode {
 	key := convertFieldPath(cmp.Field)
 	op, _ := ParseOperator(cmp.Operator)
 	value, quoted := convertValue(cmp.Value)
+	if s, ok := value.(string); ok && !quoted && isRangeOperator(op) {
+		if rt, ok := parseRelativeTime(s); ok {
+			value = rt
+		}
+	}
 
 	return &ExpressionNode{
 		Key:      key,
@@ -348,6 +365,24 @@ func convertValue(v *PValue) (any, bool) {
 	return nil, false
 }
 
+// relativeTime matches the unquoted relative time values: now, or now minus
+// an amount of seconds, minutes, hours, days or weeks.
+var relativeTime = regexp.MustCompile(`^now(?:-([0-9]{1,6})([smhdw]))?$`)
+
+// parseRelativeTime parses now or now-<amount><unit>. Only range comparisons
+// use it, so bare values such as status=now stay strings.
+func parseRelativeTime(s string) (RelativeTime, bool) {
+	m := relativeTime.FindStringSubmatch(s)
+	if m == nil {
+		return RelativeTime{}, false
+	}
+	if m[1] == "" {
+		return RelativeTime{}, true
+	}
+	amount, _ := strconv.Atoi(m[1])
+	return RelativeTime{Amount: amount, Unit: m[2]}, true
+}
+
 // unescapeString handles escape sequences in string literals
 func unescapeString(s string) string {
 	var result strings.Builder
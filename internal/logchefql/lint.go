@@ -0,0 +1,115 @@
+package logchefql
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// LintOptions is what Lint knows about the source a query will run against.
+type LintOptions struct {
+	// Schema lists the columns fields must name. Nil skips the check.
+	Schema *Schema
+	// IndexedColumns are the columns the table is sorted by. Nil skips the
+	// regex check.
+	IndexedColumns []string
+}
+
+// lintQuery walks a parsed query's comparisons and pipe selections, checking
+// each field against opts.
+func lintQuery(pq *PQuery, opts LintOptions) []Diagnostic {
+	if pq == nil || (opts.Schema == nil && opts.IndexedColumns == nil) {
+		return nil
+	}
+
+	known := make(map[string]struct{})
+	if opts.Schema != nil {
+		for _, col := range opts.Schema.Columns {
+			known[col.Name] = struct{}{}
+		}
+	}
+	indexed := make(map[string]struct{}, len(opts.IndexedColumns))
+	for _, col := range opts.IndexedColumns {
+		indexed[col] = struct{}{}
+	}
+
+	var diags []Diagnostic
+	checkField := func(fp *PFieldPath) (string, bool) {
+		if fp == nil || fp.First == nil {
+			return "", false
+		}
+		name := getSegmentValue(fp.First)
+		if opts.Schema == nil {
+			return name, true
+		}
+		if _, ok := known[name]; !ok {
+			diags = append(diags, Diagnostic{
+				Code:     WarnUnknownField,
+				Message:  fmt.Sprintf("field %q is not in the source schema", name),
+				Field:    name,
+				Position: positionOf(fp.Pos),
+			})
+			return name, false
+		}
+		return name, true
+	}
+
+	walkComparisons(pq.Where, func(cmp *PComparison) {
+		name, ok := checkField(cmp.Field)
+		if !ok || opts.IndexedColumns == nil {
+			return
+		}
+		if op, _ := ParseOperator(cmp.Operator); op != OpRegex && op != OpNotRegex {
+			return
+		}
+		if _, ok := indexed[name]; ok {
+			return
+		}
+		diags = append(diags, Diagnostic{
+			Code:     WarnRegexOnUnindexedColumn,
+			Message:  fmt.Sprintf("regex match on %q, which is not indexed, scans every row in the time range", name),
+			Field:    name,
+			Position: positionOf(cmp.Field.Pos),
+		})
+	})
+	for _, item := range pq.Select {
+		checkField(item.Field)
+	}
+	return diags
+}
+
+// walkComparisons calls fn for each comparison in expr, left to right.
+func walkComparisons(expr *POrExpr, fn func(*PComparison)) {
+	if expr == nil {
+		return
+	}
+	ands := []*PAndExpr{expr.Left}
+	for _, tail := range expr.Right {
+		ands = append(ands, tail.Right)
+	}
+	for _, and := range ands {
+		if and == nil {
+			continue
+		}
+		terms := []*PTerm{and.Left}
+		for _, tail := range and.Right {
+			terms = append(terms, tail.Right)
+		}
+		for _, term := range terms {
+			switch {
+			case term == nil:
+			case term.Group != nil:
+				walkComparisons(term.Group, fn)
+			case term.Comparison != nil:
+				fn(term.Comparison)
+			}
+		}
+	}
+}
+
+func positionOf(pos lexer.Position) *Position {
+	if pos.Line == 0 {
+		return nil
+	}
+	return &Position{Line: pos.Line, Column: pos.Column}
+}
@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/mr-karan/logchef/internal/timezone"
 )
 
 // Translate parses a LogchefQL query and returns the SQL translation with metadata.
@@ -52,6 +54,14 @@ func Translate(query string, schema *Schema) *TranslateResult {
 
 // Validate checks if a LogchefQL query is syntactically valid.
 func Validate(query string) *ValidateResult {
+	return Lint(query, LintOptions{})
+}
+
+// Lint validates a LogchefQL query like Validate and, when it parses, checks
+// it against opts, adding a Warning for each field missing from the schema
+// and each regex match on a column that isn't indexed. Checks whose option is
+// unset are skipped.
+func Lint(query string, opts LintOptions) *ValidateResult {
 	result := &ValidateResult{Valid: false}
 
 	if query == "" || strings.TrimSpace(query) == "" {
@@ -59,16 +69,30 @@ func Validate(query string) *ValidateResult {
 		return result
 	}
 
-	_, err := ParseLogchefQL(query)
+	pq, err := ParseLogchefQL(query)
 	if err != nil {
 		result.Error = convertParticipleError(err)
 		return result
 	}
 
 	result.Valid = true
+	result.Warnings = lintQuery(pq, opts)
 	return result
 }
 
+// FilteredFields returns the fields a LogchefQL query filters on, in order of
+// first use. Nested keys are reported by their top-level field.
+func FilteredFields(query string) ([]string, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+	pq, err := ParseLogchefQL(query)
+	if err != nil {
+		return nil, err
+	}
+	return extractFieldsFromAST(ConvertToAST(pq)), nil
+}
+
 func convertParticipleError(err error) *ParseError {
 	if err == nil {
 		return nil
@@ -155,6 +179,8 @@ func extractFieldsFromAST(node ASTNode) []string {
 			for _, child := range v.Children {
 				walk(child)
 			}
+		case *NotNode:
+			walk(v.Child)
 		case *QueryNode:
 			walk(v.Where)
 		}
@@ -193,6 +219,17 @@ func extractConditionsFromAST(node ASTNode) []FilterCondition {
 			for _, child := range v.Children {
 				walk(child)
 			}
+		case *NotNode:
+			// A negated comparison is reported with the opposite operator;
+			// conditions under a negated and/or have no single-field
+			// equivalent and are left out.
+			if expr, ok := unwrapNegated(v.Child); ok {
+				if op, ok := negateOperator(expr.Operator); ok {
+					negated := *expr
+					negated.Operator = op
+					walk(&negated)
+				}
+			}
 		case *QueryNode:
 			walk(v.Where)
 		}
@@ -202,6 +239,48 @@ func extractConditionsFromAST(node ASTNode) []FilterCondition {
 	return conditions
 }
 
+// unwrapNegated returns the comparison a NotNode negates, looking through
+// single-child groups, or ok=false if it negates an and/or.
+func unwrapNegated(node ASTNode) (*ExpressionNode, bool) {
+	for {
+		switch n := node.(type) {
+		case *ExpressionNode:
+			return n, true
+		case *GroupNode:
+			if len(n.Children) != 1 {
+				return nil, false
+			}
+			node = n.Children[0]
+		default:
+			return nil, false
+		}
+	}
+}
+
+// negateOperator returns the operator matching what op doesn't.
+func negateOperator(op Operator) (Operator, bool) {
+	switch op {
+	case OpEquals:
+		return OpNotEquals, true
+	case OpNotEquals:
+		return OpEquals, true
+	case OpRegex:
+		return OpNotRegex, true
+	case OpNotRegex:
+		return OpRegex, true
+	case OpGT:
+		return OpLTE, true
+	case OpLTE:
+		return OpGT, true
+	case OpLT:
+		return OpGTE, true
+	case OpGTE:
+		return OpLT, true
+	default:
+		return "", false
+	}
+}
+
 func getFieldName(key any) string {
 	switch k := key.(type) {
 	case string:
@@ -277,6 +356,12 @@ func validateTimezone(tz string) *ParseError {
 			Message: "invalid timezone: too long",
 		}
 	}
+	if _, err := timezone.Load(tz); err != nil {
+		return &ParseError{
+			Code:    ErrInvalidTimezone,
+			Message: fmt.Sprintf("unknown timezone %q: must be an IANA name such as Asia/Kolkata", tz),
+		}
+	}
 	return nil
 }
 
@@ -330,14 +415,17 @@ func BuildFullQuery(params QueryBuildParams) (string, error) {
 	var query strings.Builder
 
 	query.WriteString("SELECT ")
-	if translateResult.SelectClause != "" {
+	if params.Projection != "" {
+		query.WriteString(params.Projection)
+	} else if translateResult.SelectClause != "" {
 		timestampInSelect := strings.Contains(translateResult.SelectClause, "`"+params.TimestampField+"`")
 		if params.TimestampField != "" && !timestampInSelect {
 			fmt.Fprintf(&query, "`%s`, ", params.TimestampField)
 		}
 		query.WriteString(translateResult.SelectClause)
 	} else {
-		query.WriteString("*")
+		// Virtual columns aren't part of *, so list them after it.
+		query.WriteString(strings.Join(append([]string{"*"}, NewSQLGenerator(params.Schema).VirtualColumns()...), ", "))
 	}
 	query.WriteString("\n")
 
@@ -390,4 +478,7 @@ type QueryBuildParams struct {
 	EndTime        string  // End time in format "2006-01-02 15:04:05"
 	Timezone       string  // Timezone for time conversion
 	Limit          int     // Result limit
+	// Projection, when set, replaces the SELECT list, including any
+	// "| select" pipe; the filter still applies.
+	Projection string
 }
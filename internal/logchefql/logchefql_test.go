@@ -1,6 +1,7 @@
 package logchefql
 
 import (
+	"errors"
 	"slices"
 	"strings"
 	"testing"
@@ -588,6 +589,168 @@ func TestComplexQueries(t *testing.T) {
 	})
 }
 
+func TestNotOperator(t *testing.T) {
+	cases := []struct {
+		query, sql string
+	}{
+		{`not (level = "error" or level = "warn")`, "NOT ((`level` = 'error') OR (`level` = 'warn'))"},
+		// not binds tighter than and.
+		{`not level = "debug" and status >= 500`, "(NOT (`level` = 'debug')) AND (`status` >= 500)"},
+		{`a = "1" or not (b = "2" and not c ~ "x")`, "(`a` = '1') OR (NOT ((`b` = '2') AND (NOT (positionCaseInsensitive(`c`, 'x') > 0))))"},
+		// A field named not still works.
+		{`not = "x"`, "`not` = 'x'"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			result := Translate(tc.query, nil)
+			if !result.Valid {
+				t.Fatalf("expected valid result, got error: %v", result.Error)
+			}
+			if result.SQL != tc.sql {
+				t.Errorf("SQL = %q, want %q", result.SQL, tc.sql)
+			}
+		})
+	}
+
+	t.Run("repeated not needs parentheses", func(t *testing.T) {
+		if result := Validate(`not not a = "1"`); result.Valid {
+			t.Error("expected not not to be rejected")
+		}
+		if result := Validate(`not (not a = "1")`); !result.Valid {
+			t.Errorf("expected valid result, got error: %v", result.Error)
+		}
+	})
+
+	t.Run("conditions and fields", func(t *testing.T) {
+		result := Translate(`not status >= 500 and not (a = "1" or b = "2")`, nil)
+		if !result.Valid {
+			t.Fatalf("expected valid result, got error: %v", result.Error)
+		}
+		want := []FilterCondition{{Field: "status", Operator: "<", Value: "500"}}
+		if !slices.Equal(result.Conditions, want) {
+			t.Errorf("Conditions = %+v, want %+v", result.Conditions, want)
+		}
+		if want := []string{"status", "a", "b"}; !slices.Equal(result.FieldsUsed, want) {
+			t.Errorf("FieldsUsed = %v, want %v", result.FieldsUsed, want)
+		}
+	})
+}
+
+func TestMapValueCasts(t *testing.T) {
+	schema := &Schema{Columns: []ColumnInfo{
+		{Name: "log_attributes", Type: "Map(LowCardinality(String), String)"},
+		{Name: "metrics", Type: "Map(String, Float64)"},
+	}}
+	cases := []struct {
+		query, sql string
+	}{
+		{`log_attributes.latency > 100`, "toFloat64OrNull(`log_attributes`['latency']) > 100"},
+		{`log_attributes.code = 404`, "toFloat64OrNull(`log_attributes`['code']) = 404"},
+		{`log_attributes.code = "404"`, "`log_attributes`['code'] = '404'"},
+		{`log_attributes.seen_at >= "2026-01-02T03:04:05Z"`, "parseDateTime64BestEffortOrNull(`log_attributes`['seen_at'], 3) >= parseDateTime64BestEffort('2026-01-02T03:04:05Z', 3)"},
+		{`log_attributes.day < "2026-01-02"`, "parseDateTime64BestEffortOrNull(`log_attributes`['day'], 3) < parseDateTime64BestEffort('2026-01-02', 3)"},
+		// Equality on a datetime string and ordering on other strings stay textual.
+		{`log_attributes.day = "2026-01-02"`, "`log_attributes`['day'] = '2026-01-02'"},
+		{`log_attributes.version > "v2"`, "`log_attributes`['version'] > 'v2'"},
+		{`log_attributes.latency ~ "10"`, "positionCaseInsensitive(`log_attributes`['latency'], '10') > 0"},
+		// Numeric map values need no cast.
+		{`metrics.cpu > 0.5`, "`metrics`['cpu'] > 0.5"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			result := Translate(tc.query, schema)
+			if !result.Valid {
+				t.Fatalf("expected valid result, got error: %v", result.Error)
+			}
+			if result.SQL != tc.sql {
+				t.Errorf("SQL = %q, want %q", result.SQL, tc.sql)
+			}
+		})
+	}
+}
+
+func TestTimeLiterals(t *testing.T) {
+	schema := &Schema{Columns: []ColumnInfo{
+		{Name: "timestamp", Type: "DateTime64(3, 'UTC')"},
+		{Name: "@timestamp", Type: "DateTime"},
+		{Name: "day", Type: "Nullable(Date)"},
+		{Name: "body", Type: "String"},
+		{Name: "log_attributes", Type: "Map(String, String)"},
+	}}
+	cases := []struct {
+		query, sql string
+	}{
+		{`timestamp > now-15m`, "`timestamp` > now() - INTERVAL 15 MINUTE"},
+		{`timestamp <= now`, "`timestamp` <= now()"},
+		{`timestamp >= now-2w and timestamp < now-1d`, "(`timestamp` >= now() - INTERVAL 2 WEEK) AND (`timestamp` < now() - INTERVAL 1 DAY)"},
+		{`@timestamp >= "2024-01-01T10:00:00Z"`, "`@timestamp` >= toDateTime64('2024-01-01 10:00:00.000', 3, 'UTC')"},
+		{`timestamp < "2024-01-01T10:00:00.123456+05:30"`, "`timestamp` < toDateTime64('2024-01-01 04:30:00.123456', 6, 'UTC')"},
+		{`timestamp = "2024-01-01 10:00:00"`, "`timestamp` = toDateTime64('2024-01-01 10:00:00', 3)"},
+		{`day >= "2024-01-01"`, "`day` >= toDateTime64('2024-01-01 00:00:00', 3)"},
+		{`log_attributes.seen_at > now-1h`, "parseDateTime64BestEffortOrNull(`log_attributes`['seen_at'], 3) > now() - INTERVAL 1 HOUR"},
+		{`body.ts > now-30s`, "parseDateTime64BestEffortOrNull(JSONExtractString(`body`, 'ts'), 3) > now() - INTERVAL 30 SECOND"},
+		// Outside range comparisons, and on string columns, times stay strings.
+		{`body = now-15m`, "`body` = 'now-15m'"},
+		{`timestamp > "now-15m"`, "`timestamp` > 'now-15m'"},
+		{`body >= "2024-01-01"`, "`body` >= '2024-01-01'"},
+		{`timestamp ~ "2024-01-01"`, "positionCaseInsensitive(`timestamp`, '2024-01-01') > 0"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			result := Translate(tc.query, schema)
+			if !result.Valid {
+				t.Fatalf("expected valid result, got error: %v", result.Error)
+			}
+			if result.SQL != tc.sql {
+				t.Errorf("SQL = %q, want %q", result.SQL, tc.sql)
+			}
+		})
+	}
+
+	if result := TranslateToLogsQL(`timestamp > now-15m`, nil); result.Valid || result.Error.Code != ErrUnsupportedFeature {
+		t.Errorf("TranslateToLogsQL(relative time) = %+v, want ErrUnsupportedFeature", result)
+	}
+}
+
+func TestGroupByExpression(t *testing.T) {
+	schema := &Schema{Columns: []ColumnInfo{
+		{Name: "service", Type: "LowCardinality(String)"},
+		{Name: "body", Type: "String"},
+		{Name: "log_attributes", Type: "Map(String, String)"},
+		{Name: "region", Type: "String", Expression: "log_attributes['cloud.region']"},
+	}}
+	cases := []struct {
+		field, sql string
+	}{
+		{"service", "`service`"},
+		{"log_attributes.http.method", "`log_attributes`['http.method']"},
+		{"body.user.id", "JSONExtractString(`body`, 'user', 'id')"},
+		{"region", "(log_attributes['cloud.region'])"},
+		// Fields that aren't columns fall back to the default map column.
+		{"user_id", "`log_attributes`['user_id']"},
+		{"x'] OR 1=1 --", "`log_attributes`['x''] OR 1=1 --']"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.field, func(t *testing.T) {
+			got, err := GroupByExpression(tc.field, schema)
+			if err != nil {
+				t.Fatalf("GroupByExpression: %v", err)
+			}
+			if got != tc.sql {
+				t.Errorf("GroupByExpression(%q) = %q, want %q", tc.field, got, tc.sql)
+			}
+		})
+	}
+
+	noMap := &Schema{Columns: []ColumnInfo{{Name: "service", Type: "String"}}}
+	if _, err := GroupByExpression("user_id", noMap); err == nil || err.Code != ErrInvalidIdentifier {
+		t.Errorf("GroupByExpression(unknown) err = %v, want ErrInvalidIdentifier", err)
+	}
+	if got, err := GroupByExpression("a`b", nil); err != nil || got != "`a``b`" {
+		t.Errorf("GroupByExpression(no schema) = %q, %v; want quoted column", got, err)
+	}
+}
+
 func TestSQLGenerator(t *testing.T) {
 	t.Run("simple equals expression", func(t *testing.T) {
 		result := Translate(`severity_text = "error"`, testSchema)
@@ -825,6 +988,153 @@ func TestValidate(t *testing.T) {
 	})
 }
 
+func TestLint(t *testing.T) {
+	opts := LintOptions{
+		Schema: &Schema{Columns: []ColumnInfo{
+			{Name: "timestamp", Type: "DateTime64(3)"},
+			{Name: "service", Type: "LowCardinality(String)"},
+			{Name: "body", Type: "String"},
+			{Name: "log_attributes", Type: "Map(String, String)"},
+		}},
+		IndexedColumns: []string{"service", "timestamp"},
+	}
+
+	t.Run("clean query", func(t *testing.T) {
+		result := Lint(`service~"api-.*" and log_attributes.user="bob"`, opts)
+		if !result.Valid || len(result.Warnings) != 0 {
+			t.Fatalf("expected no warnings, got %+v", result)
+		}
+	})
+
+	t.Run("unknown fields with positions", func(t *testing.T) {
+		result := Lint("service=\"api\"\n  and (sevrity=\"error\" or body~\"timeout\") | hostname", opts)
+		if !result.Valid {
+			t.Fatalf("expected valid result, got error: %v", result.Error)
+		}
+		want := []struct {
+			code, field  string
+			line, column int
+		}{
+			{WarnUnknownField, "sevrity", 2, 8},
+			{WarnRegexOnUnindexedColumn, "body", 2, 27},
+			{WarnUnknownField, "hostname", 2, 45},
+		}
+		if len(result.Warnings) != len(want) {
+			t.Fatalf("got %d warnings, want %d: %+v", len(result.Warnings), len(want), result.Warnings)
+		}
+		for i, w := range want {
+			got := result.Warnings[i]
+			if got.Code != w.code || got.Field != w.field || got.Position == nil ||
+				got.Position.Line != w.line || got.Position.Column != w.column {
+				t.Errorf("warning %d = %+v (position %+v), want %s on %s at %d:%d", i, got, got.Position, w.code, w.field, w.line, w.column)
+			}
+		}
+	})
+
+	t.Run("checks are skipped without options", func(t *testing.T) {
+		if result := Validate(`nope~"x"`); !result.Valid || len(result.Warnings) != 0 {
+			t.Fatalf("Validate should not lint, got %+v", result)
+		}
+	})
+}
+
+func TestFromSQLRoundTrip(t *testing.T) {
+	schema := &Schema{Columns: []ColumnInfo{
+		{Name: "timestamp", Type: "DateTime64(3)"},
+		{Name: "level", Type: "LowCardinality(String)"},
+		{Name: "status", Type: "UInt16"},
+		{Name: "body", Type: "String"},
+		{Name: "log_attributes", Type: "Map(String, String)"},
+	}}
+
+	// Each query is already in the form FromSQL renders, so the round trip
+	// through SQL must give it back unchanged.
+	queries := []string{
+		`level="error"`,
+		`status>=500 and status!=503`,
+		`level="error" or level="warn" and body~"time\"out"`,
+		`(level="error" or level="warn") and body!~"health"`,
+		`log_attributes.user.id="42" and log_attributes.region~"eu"`,
+		`body.request.path="/api" and body.duration>1.5`,
+		`"odd field"="it's"`,
+		`not (level="error" or level="warn") and not body~"health"`,
+		`level="error" or not (status>=500 and not body~"x")`,
+		`log_attributes.latency>100 and log_attributes.seen_at>="2026-01-02 03:04:05"`,
+		`timestamp>now-15m and timestamp<="2026-01-02T03:04:05Z" and log_attributes.seen_at<now`,
+		`body.ts>=now-1h and body.day<"2026-01-02"`,
+	}
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			translated := Translate(query, schema)
+			if !translated.Valid {
+				t.Fatalf("Translate: %v", translated.Error)
+			}
+			got, err := FromSQL(translated.SQL)
+			if err != nil {
+				t.Fatalf("FromSQL(%s): %v", translated.SQL, err)
+			}
+			if got != query {
+				t.Errorf("FromSQL(%s) = %s, want %s", translated.SQL, got, query)
+			}
+		})
+	}
+}
+
+func TestFromSQL(t *testing.T) {
+	cases := []struct {
+		sql, want string
+	}{
+		{"", ""},
+		{"WHERE `level` == 'error' AND status <> 200", `level="error" and status!=200`},
+		{"body ILIKE '%timeout%' and host not ilike '%canary%'", `body~"timeout" and host!~"canary"`},
+		{"((a = 1) OR (b = 2)) AND c = 'x\\\\y''z'", `(a=1 or b=2) and c="x\\y'z"`},
+		{"flag = true and deleted = NULL", `flag=true and deleted=null`},
+	}
+	for _, tc := range cases {
+		got, err := FromSQL(tc.sql)
+		if err != nil || got != tc.want {
+			t.Errorf("FromSQL(%q) = %q, %v; want %q", tc.sql, got, err, tc.want)
+		}
+	}
+
+	unsupported := []string{
+		"body LIKE '%timeout%'",
+		"body ILIKE 'time%'",
+		"lower(level) = 'error'",
+		"a = b",
+		"JSONExtractString(body, 'n') > 5",
+		"positionCaseInsensitive(body, 'x') > 1",
+	}
+	for _, sql := range unsupported {
+		_, err := FromSQL(sql)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || parseErr.Code != ErrUnsupportedFeature {
+			t.Errorf("FromSQL(%q) err = %v, want ErrUnsupportedFeature", sql, err)
+		}
+	}
+
+	if _, err := FromSQL("level IN ('a', 'b')"); err == nil {
+		t.Error("FromSQL(IN) should fail to parse")
+	}
+}
+
+func TestFilteredFields(t *testing.T) {
+	fields, err := FilteredFields(`service_name = "api" and (log_attributes.user_id = "1" or severity_text = "error") and service_name != "web"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"service_name", "log_attributes", "severity_text"}; !slices.Equal(fields, want) {
+		t.Errorf("FilteredFields = %v, want %v", fields, want)
+	}
+
+	if fields, err := FilteredFields("  "); err != nil || len(fields) != 0 {
+		t.Errorf("FilteredFields(blank) = %v / %v, want none", fields, err)
+	}
+	if _, err := FilteredFields(`severity_text =`); err == nil {
+		t.Error("expected error for invalid query")
+	}
+}
+
 func TestBuildFullQuery(t *testing.T) {
 	t.Run("builds complete query", func(t *testing.T) {
 		params := QueryBuildParams{
@@ -1145,7 +1455,9 @@ func TestTimeValidation(t *testing.T) {
 		}
 	})
 
-	t.Run("accepts timezone with colon offset", func(t *testing.T) {
+	// ClickHouse doesn't know "UTC+05:30" as a zone name, so it must not
+	// reach toDateTime; fixed offsets go through the Etc/GMT zones instead.
+	t.Run("rejects UTC offset name", func(t *testing.T) {
 		params := QueryBuildParams{
 			LogchefQL:      `field="value"`,
 			Schema:         testSchema,
@@ -1158,8 +1470,47 @@ func TestTimeValidation(t *testing.T) {
 		}
 
 		_, err := BuildFullQuery(params)
+		if err == nil || !strings.Contains(err.Error(), "unknown timezone") {
+			t.Errorf("expected 'unknown timezone' error for UTC+05:30, got: %v", err)
+		}
+	})
+
+	t.Run("builds SQL with Etc/GMT fixed offset", func(t *testing.T) {
+		params := QueryBuildParams{
+			LogchefQL:      `field="value"`,
+			Schema:         testSchema,
+			TableName:      "logs.test",
+			TimestampField: "timestamp",
+			StartTime:      "2024-01-01 00:00:00",
+			EndTime:        "2024-01-01 23:59:59",
+			Timezone:       "Etc/GMT-5",
+			Limit:          100,
+		}
+
+		sql, err := BuildFullQuery(params)
 		if err != nil {
-			t.Errorf("expected no error for timezone with colon offset, got: %v", err)
+			t.Fatalf("expected no error for Etc/GMT-5, got: %v", err)
+		}
+		if !strings.Contains(sql, "toDateTime('2024-01-01 00:00:00', 'Etc/GMT-5')") {
+			t.Errorf("expected the time range in Etc/GMT-5, got: %s", sql)
+		}
+	})
+
+	t.Run("rejects unknown timezone", func(t *testing.T) {
+		params := QueryBuildParams{
+			LogchefQL:      `field="value"`,
+			Schema:         testSchema,
+			TableName:      "logs.test",
+			TimestampField: "timestamp",
+			StartTime:      "2024-01-01 00:00:00",
+			EndTime:        "2024-01-01 23:59:59",
+			Timezone:       "Mars/Olympus_Mons",
+			Limit:          100,
+		}
+
+		_, err := BuildFullQuery(params)
+		if err == nil {
+			t.Error("expected error for unknown timezone")
 		}
 	})
 
@@ -1442,3 +1793,91 @@ func TestMapColumnFallback(t *testing.T) {
 		}
 	})
 }
+
+func TestVirtualColumns(t *testing.T) {
+	schema := &Schema{
+		Columns: []ColumnInfo{
+			{Name: "timestamp", Type: "DateTime"},
+			{Name: "body", Type: "String"},
+			{Name: "status", Type: "Nullable(Int64)", Expression: "toInt64OrNull(extract(`body`, 'status=(\\\\d+)'))"},
+			{Name: "payload", Type: "String", Expression: "extract(`body`, 'payload=(\\\\{.*\\\\})')"},
+		},
+	}
+	build := func(t *testing.T, query string) string {
+		t.Helper()
+		sql, err := BuildFullQuery(QueryBuildParams{
+			LogchefQL:      query,
+			Schema:         schema,
+			TableName:      "logs.test",
+			TimestampField: "timestamp",
+			StartTime:      "2024-01-01 00:00:00",
+			EndTime:        "2024-01-01 23:59:59",
+			Timezone:       "UTC",
+			Limit:          100,
+		})
+		if err != nil {
+			t.Fatalf("BuildFullQuery(%q) error = %v", query, err)
+		}
+		return sql
+	}
+
+	t.Run("filters on the expression", func(t *testing.T) {
+		sql := build(t, `status>=500`)
+		if !strings.Contains(sql, "(toInt64OrNull(extract(`body`, 'status=(\\\\d+)'))) >= 500") {
+			t.Errorf("expected filter on the status expression, got:\n%s", sql)
+		}
+	})
+
+	t.Run("lists virtual columns after star", func(t *testing.T) {
+		sql := build(t, `body~"error"`)
+		want := "SELECT *, (toInt64OrNull(extract(`body`, 'status=(\\\\d+)'))) AS `status`, (extract(`body`, 'payload=(\\\\{.*\\\\})')) AS `payload`\n"
+		if !strings.HasPrefix(sql, want) {
+			t.Errorf("expected virtual columns in SELECT, got:\n%s", sql)
+		}
+	})
+
+	t.Run("selects the expression under its name", func(t *testing.T) {
+		sql := build(t, `body~"error" | status`)
+		if !strings.HasPrefix(sql, "SELECT `timestamp`, (toInt64OrNull(extract(`body`, 'status=(\\\\d+)'))) AS `status`\n") {
+			t.Errorf("expected aliased status expression, got:\n%s", sql)
+		}
+	})
+
+	t.Run("nested access reads JSON from the expression", func(t *testing.T) {
+		sql := build(t, `payload.user="alice"`)
+		if !strings.Contains(sql, "JSONExtractString((extract(`body`, 'payload=(\\\\{.*\\\\})')), 'user') = 'alice'") {
+			t.Errorf("expected JSON extraction over the payload expression, got:\n%s", sql)
+		}
+	})
+}
+
+func TestJSONBodyFields(t *testing.T) {
+	cases := []struct {
+		query string
+		want  string
+	}{
+		{`body.user.id = "x"`, "JSONExtractString(`body`, 'user', 'id') = 'x'"},
+		{`body."http status" != "ok"`, "JSONExtractString(`body`, 'http status') != 'ok'"},
+		{`body.latency_ms > 250`, "JSONExtractFloat(`body`, 'latency_ms') > 250"},
+		{`body.user.id = 42`, "JSONExtractFloat(`body`, 'user', 'id') = 42"},
+		{`body.msg ~ "timeout"`, "positionCaseInsensitive(JSONExtractString(`body`, 'msg'), 'timeout') > 0"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			result := Translate(tc.query, testSchema)
+			if !result.Valid {
+				t.Fatalf("Translate(%q) error = %v", tc.query, result.Error)
+			}
+			if result.SQL != tc.want {
+				t.Errorf("Translate(%q) = %s, want %s", tc.query, result.SQL, tc.want)
+			}
+		})
+	}
+
+	t.Run("selects a body key under a flattened alias", func(t *testing.T) {
+		result := Translate(`namespace="prod" | body.user.id`, testSchema)
+		if want := "JSONExtractString(`body`, 'user', 'id') AS `body_user_id`"; result.SelectClause != want {
+			t.Errorf("SelectClause = %s, want %s", result.SelectClause, want)
+		}
+	})
+}
@@ -155,6 +155,8 @@ func (g *LogsQLGenerator) visit(node ASTNode) (string, *ParseError) {
 		return g.visitLogical(n)
 	case *GroupNode:
 		return g.visitGroup(n)
+	case *NotNode:
+		return g.visitNot(n)
 	case *QueryNode:
 		return g.visitQuery(n)
 	default:
@@ -245,6 +247,18 @@ func (g *LogsQLGenerator) visitGroup(node *GroupNode) (string, *ParseError) {
 	return fmt.Sprintf("(%s)", strings.Join(parts, " AND ")), nil
 }
 
+func (g *LogsQLGenerator) visitNot(node *NotNode) (string, *ParseError) {
+	part, err := g.visit(node.Child)
+	if err != nil || strings.TrimSpace(part) == "" {
+		return part, err
+	}
+	// visitGroup already parenthesizes.
+	if _, ok := node.Child.(*GroupNode); ok {
+		return "NOT " + part, nil
+	}
+	return fmt.Sprintf("NOT (%s)", part), nil
+}
+
 func (g *LogsQLGenerator) visitExpression(node *ExpressionNode) (string, *ParseError) {
 	fieldName := g.formatFieldName(getFieldName(node.Key))
 	if fieldName == "" {
@@ -353,6 +367,8 @@ func (g *LogsQLGenerator) formatValue(value interface{}) (string, *ParseError) {
 		return fmt.Sprintf("%v", v), nil
 	case string:
 		return strconv.Quote(v), nil
+	case RelativeTime:
+		return "", &ParseError{Code: ErrUnsupportedFeature, Message: fmt.Sprintf("relative time %s is not supported for LogsQL translation", v)}
 	default:
 		return strconv.Quote(fmt.Sprintf("%v", v)), nil
 	}
@@ -69,6 +69,17 @@ func TestTranslateToLogsQL(t *testing.T) {
 		}
 	})
 
+	t.Run("translates not", func(t *testing.T) {
+		result := TranslateToLogsQL(`not (a = "1" or b = "2") and c = "3"`, nil)
+		if !result.Valid {
+			t.Fatalf("expected valid result, got error: %v", result.Error)
+		}
+		expected := `(NOT ((a:="1") OR (b:="2"))) AND (c:="3")`
+		if result.Query != expected {
+			t.Fatalf("expected %q, got %q", expected, result.Query)
+		}
+	})
+
 	t.Run("quotes special field names", func(t *testing.T) {
 		result := TranslateToLogsQL(`log_attributes."foo bar" = "value"`, nil)
 		if !result.Valid {
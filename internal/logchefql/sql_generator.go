@@ -2,7 +2,9 @@ package logchefql
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 )
 
 // SQLGenerator converts an AST into ClickHouse SQL
@@ -13,6 +15,8 @@ type SQLGenerator struct {
 	// expression/select field.
 	colTypes      map[string]string
 	defaultMapCol string
+	// virtual maps virtual column names to their expressions.
+	virtual map[string]string
 }
 
 // NewSQLGenerator creates a new SQL generator with optional schema
@@ -23,9 +27,15 @@ func NewSQLGenerator(schema *Schema) *SQLGenerator {
 		for _, col := range schema.Columns {
 			if _, ok := g.colTypes[col.Name]; !ok {
 				g.colTypes[col.Name] = col.Type
+				if col.Expression != "" {
+					if g.virtual == nil {
+						g.virtual = make(map[string]string)
+					}
+					g.virtual[col.Name] = col.Expression
+				}
 			}
 			// First Map(...) column in schema order is the default map column.
-			if g.defaultMapCol == "" && g.isMapType(col.Type) {
+			if g.defaultMapCol == "" && col.Expression == "" && g.isMapType(col.Type) {
 				g.defaultMapCol = col.Name
 			}
 		}
@@ -77,6 +87,8 @@ func (g *SQLGenerator) visit(node ASTNode) string {
 		return g.visitLogical(n)
 	case *GroupNode:
 		return g.visitGroup(n)
+	case *NotNode:
+		return g.visitNot(n)
 	case *QueryNode:
 		return g.visitQuery(n)
 	default:
@@ -104,8 +116,12 @@ func (g *SQLGenerator) visitExpression(node *ExpressionNode) string {
 		return ""
 	}
 
-	column := g.escapeIdentifier(key)
+	column := g.columnRef(key)
 	value := g.formatValue(node.Value, node.Operator)
+	if isDateTimeValue(node.Value) && isDateTimeType(g.getColumnType(key)) &&
+		node.Operator != OpRegex && node.Operator != OpNotRegex {
+		value = formatDateTimeLiteral(node.Value.(string))
+	}
 
 	switch node.Operator {
 	case OpRegex:
@@ -192,12 +208,44 @@ func (g *SQLGenerator) visitGroup(node *GroupNode) string {
 	return fmt.Sprintf("(%s)", strings.Join(conditions, " AND "))
 }
 
+func (g *SQLGenerator) visitNot(node *NotNode) string {
+	inner := g.visit(node.Child)
+	if inner == "" {
+		return ""
+	}
+	return fmt.Sprintf("NOT (%s)", inner)
+}
+
 func (g *SQLGenerator) escapeIdentifier(identifier string) string {
 	// Escape backticks by doubling them
 	escaped := strings.ReplaceAll(identifier, "`", "``")
 	return fmt.Sprintf("`%s`", escaped)
 }
 
+// columnRef returns the SQL for a field: its expression for a virtual
+// column, otherwise the escaped identifier.
+func (g *SQLGenerator) columnRef(name string) string {
+	if expr, ok := g.virtual[name]; ok {
+		return "(" + expr + ")"
+	}
+	return g.escapeIdentifier(name)
+}
+
+// VirtualColumns returns the select items for the schema's virtual columns,
+// as "(expr) AS `name`", in schema order.
+func (g *SQLGenerator) VirtualColumns() []string {
+	if g.schema == nil || len(g.virtual) == 0 {
+		return nil
+	}
+	var items []string
+	for _, col := range g.schema.Columns {
+		if expr, ok := g.virtual[col.Name]; ok && expr == col.Expression {
+			items = append(items, fmt.Sprintf("(%s) AS %s", expr, g.escapeIdentifier(col.Name)))
+		}
+	}
+	return items
+}
+
 func (g *SQLGenerator) escapeSQLString(value string) string {
 	// Escape backslashes first, then single quotes
 	result := strings.ReplaceAll(value, "\\", "\\\\")
@@ -224,6 +272,8 @@ func (g *SQLGenerator) formatValue(value any, _ Operator) string {
 	case string:
 		escaped := g.escapeSQLString(v)
 		return fmt.Sprintf("'%s'", escaped)
+	case RelativeTime:
+		return relativeTimeSQL(v)
 	default:
 		escaped := g.escapeSQLString(fmt.Sprintf("%v", v))
 		return fmt.Sprintf("'%s'", escaped)
@@ -266,24 +316,28 @@ func (g *SQLGenerator) generateNestedFieldAccess(baseColumn string, path []strin
 
 	// If no schema info, fallback to JSON extraction
 	if columnType == "" {
-		return g.generateJsonExtraction(baseColumn, path, operator, formattedValue)
+		return g.generateJsonExtraction(baseColumn, path, operator, value, formattedValue)
 	}
 
 	// Handle different column types
 	switch {
 	case g.isMapType(columnType):
-		return g.generateMapAccess(baseColumn, path, operator, formattedValue)
+		return g.generateMapAccess(baseColumn, path, columnType, operator, value, formattedValue)
 	case g.isJsonType(columnType):
-		return g.generateJsonExtraction(baseColumn, path, operator, formattedValue)
+		return g.generateJsonExtraction(baseColumn, path, operator, value, formattedValue)
 	case g.isStringType(columnType):
-		return g.generateJsonExtraction(baseColumn, path, operator, formattedValue)
+		return g.generateJsonExtraction(baseColumn, path, operator, value, formattedValue)
 	default:
-		return g.generateJsonExtraction(baseColumn, path, operator, formattedValue)
+		return g.generateJsonExtraction(baseColumn, path, operator, value, formattedValue)
 	}
 }
 
-func (g *SQLGenerator) generateMapAccess(baseColumn string, path []string, operator Operator, formattedValue string) string {
-	escapedColumn := g.escapeIdentifier(baseColumn)
+// generateMapAccess compares a key of a Map column. String map values are
+// cast to follow the literal: numbers compare via toFloat64OrNull and, for
+// range operators, times via castDateTimeComparison, so ordering isn't
+// lexical. Values that don't parse become NULL and don't match.
+func (g *SQLGenerator) generateMapAccess(baseColumn string, path []string, columnType string, operator Operator, value any, formattedValue string) string {
+	escapedColumn := g.columnRef(baseColumn)
 
 	// For ClickHouse Maps, access nested keys using dot notation as a single key
 	var escapedPath []string
@@ -298,11 +352,144 @@ func (g *SQLGenerator) generateMapAccess(baseColumn string, path []string, opera
 	fullKey := strings.Join(escapedPath, ".")
 	mapAccess := fmt.Sprintf("%s['%s']", escapedColumn, fullKey)
 
+	if g.isStringType(mapValueType(columnType)) {
+		if isNumericValue(value) && operator != OpRegex && operator != OpNotRegex {
+			mapAccess = fmt.Sprintf("toFloat64OrNull(%s)", mapAccess)
+		} else {
+			mapAccess, formattedValue = castDateTimeComparison(mapAccess, operator, value, formattedValue)
+		}
+	}
+
 	return g.generateComparisonExpression(mapAccess, operator, formattedValue)
 }
 
-func (g *SQLGenerator) generateJsonExtraction(baseColumn string, path []string, operator Operator, formattedValue string) string {
-	escapedColumn := g.escapeIdentifier(baseColumn)
+// dateTimeLiteral matches the date and datetime strings compared as times: a
+// date, optionally followed by a time with fractional seconds and a zone. The
+// groups are the date, hours and minutes, seconds, fraction and zone.
+var dateTimeLiteral = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})(?:[ T](\d{2}:\d{2})(?::(\d{2})(\.\d{1,9})?)?(Z|[+-]\d{2}:?\d{2})?)?$`)
+
+// intervalUnits maps RelativeTime units to ClickHouse interval units.
+var intervalUnits = map[string]string{
+	"s": "SECOND",
+	"m": "MINUTE",
+	"h": "HOUR",
+	"d": "DAY",
+	"w": "WEEK",
+}
+
+func relativeTimeSQL(r RelativeTime) string {
+	if r.Unit == "" {
+		return "now()"
+	}
+	return fmt.Sprintf("now() - INTERVAL %d %s", r.Amount, intervalUnits[r.Unit])
+}
+
+// formatDateTimeLiteral renders a dateTimeLiteral string as a DateTime64 for
+// comparison with a date or time column. A literal with a zone is converted
+// to UTC; one without is read in the server's timezone. A literal that isn't
+// a real time stays a string for ClickHouse to reject.
+func formatDateTimeLiteral(s string) string {
+	m := dateTimeLiteral.FindStringSubmatch(s)
+	date, clock, seconds, fraction, zone := m[1], m[2], m[3], m[4], m[5]
+	if clock == "" {
+		clock = "00:00"
+	}
+	if seconds == "" {
+		seconds = "00"
+	}
+	precision := max(3, len(fraction)-1)
+	if zone == "" {
+		return fmt.Sprintf("toDateTime64('%s %s:%s%s', %d)", date, clock, seconds, fraction, precision)
+	}
+	if len(zone) == len("+0000") {
+		zone = zone[:3] + ":" + zone[3:]
+	}
+	t, err := time.Parse(time.RFC3339Nano, date+"T"+clock+":"+seconds+fraction+zone)
+	if err != nil {
+		return fmt.Sprintf("'%s'", s)
+	}
+	layout := "2006-01-02 15:04:05." + strings.Repeat("0", precision)
+	return fmt.Sprintf("toDateTime64('%s', %d, 'UTC')", t.UTC().Format(layout), precision)
+}
+
+// castDateTimeComparison parses a String expression as a time when a range
+// operator compares it with a time: a relative time as is, or a datetime
+// literal parsed the same way. Other comparisons are returned unchanged.
+func castDateTimeComparison(expression string, operator Operator, value any, formattedValue string) (string, string) {
+	if !isRangeOperator(operator) {
+		return expression, formattedValue
+	}
+	switch {
+	case isRelativeTime(value):
+		return fmt.Sprintf("parseDateTime64BestEffortOrNull(%s, 3)", expression), formattedValue
+	case isDateTimeValue(value):
+		return fmt.Sprintf("parseDateTime64BestEffortOrNull(%s, 3)", expression),
+			fmt.Sprintf("parseDateTime64BestEffort(%s, 3)", formattedValue)
+	}
+	return expression, formattedValue
+}
+
+// isDateTimeType reports whether columnType is a Date or DateTime type,
+// possibly Nullable or LowCardinality.
+func isDateTimeType(columnType string) bool {
+	lower := strings.ToLower(strings.TrimSpace(columnType))
+	for _, wrapper := range []string{"nullable(", "lowcardinality("} {
+		if inner, ok := strings.CutPrefix(lower, wrapper); ok {
+			lower = inner
+		}
+	}
+	return strings.HasPrefix(lower, "date")
+}
+
+func isRelativeTime(value any) bool {
+	_, ok := value.(RelativeTime)
+	return ok
+}
+
+func isNumericValue(value any) bool {
+	_, ok := value.(float64)
+	return ok
+}
+
+func isDateTimeValue(value any) bool {
+	s, ok := value.(string)
+	return ok && dateTimeLiteral.MatchString(s)
+}
+
+func isRangeOperator(op Operator) bool {
+	return op == OpGT || op == OpLT || op == OpGTE || op == OpLTE
+}
+
+// mapValueType returns V from a Map(K, V) column type, or "" if columnType
+// isn't a map.
+func mapValueType(columnType string) string {
+	columnType = strings.TrimSpace(columnType)
+	if len(columnType) < len("map(") || !strings.EqualFold(columnType[:len("map(")], "map(") {
+		return ""
+	}
+	inner := strings.TrimSuffix(columnType[len("map("):], ")")
+	depth := 0
+	for i, r := range inner {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				return strings.TrimSpace(inner[i+1:])
+			}
+		}
+	}
+	return ""
+}
+
+// generateJsonExtraction compares a key of a JSON string column. Numeric
+// literals compare against JSONExtractFloat, which also parses numbers stored
+// as JSON strings; times compare via castDateTimeComparison; everything else
+// compares as a string.
+func (g *SQLGenerator) generateJsonExtraction(baseColumn string, path []string, operator Operator, value any, formattedValue string) string {
+	escapedColumn := g.columnRef(baseColumn)
 
 	// ClickHouse JSONExtractString requires separate parameters for nested access
 	var pathParams []string
@@ -315,7 +502,14 @@ func (g *SQLGenerator) generateJsonExtraction(baseColumn string, path []string,
 		pathParams = append(pathParams, fmt.Sprintf("'%s'", g.escapeSQLString(s)))
 	}
 
-	jsonExtract := fmt.Sprintf("JSONExtractString(%s, %s)", escapedColumn, strings.Join(pathParams, ", "))
+	extractFunc := "JSONExtractString"
+	if _, numeric := value.(float64); numeric && operator != OpRegex && operator != OpNotRegex {
+		extractFunc = "JSONExtractFloat"
+	}
+	jsonExtract := fmt.Sprintf("%s(%s, %s)", extractFunc, escapedColumn, strings.Join(pathParams, ", "))
+	if extractFunc == "JSONExtractString" {
+		jsonExtract, formattedValue = castDateTimeComparison(jsonExtract, operator, value, formattedValue)
+	}
 	return g.generateComparisonExpression(jsonExtract, operator, formattedValue)
 }
 
@@ -343,17 +537,38 @@ func (g *SQLGenerator) generateComparisonExpression(columnExpression string, ope
 }
 
 func (g *SQLGenerator) generateSelectFieldExpression(selectField SelectField) string {
-	var columnExpression string
-	var nestedField *NestedField
-	var simpleFieldName string
+	columnExpression := g.fieldExpression(selectField.Field)
+	if columnExpression == "" {
+		return ""
+	}
 
-	switch f := selectField.Field.(type) {
+	// Add alias if provided, or generate one for nested/map fields
+	nestedField, isNested := selectField.Field.(NestedField)
+	simpleFieldName, _ := selectField.Field.(string)
+	switch {
+	case selectField.Alias != "":
+		return fmt.Sprintf("%s AS %s", columnExpression, g.escapeIdentifier(selectField.Alias))
+	case isNested:
+		autoAlias := nestedField.Base + "_" + strings.Join(nestedField.Path, "_")
+		return fmt.Sprintf("%s AS %s", columnExpression, g.escapeIdentifier(autoAlias))
+	case simpleFieldName != "" && (!g.columnExists(simpleFieldName) || g.virtual[simpleFieldName] != ""):
+		return fmt.Sprintf("%s AS %s", columnExpression, g.escapeIdentifier(simpleFieldName))
+	default:
+		return columnExpression
+	}
+}
+
+// fieldExpression returns the SQL reading a select field: a column, a Map key
+// or a JSON path. A name that isn't a column is read from the default Map
+// column when the schema has one.
+func (g *SQLGenerator) fieldExpression(field any) string {
+	var columnExpression string
+	switch f := field.(type) {
 	case NestedField:
-		nestedField = &f
 		columnType := g.getColumnType(f.Base)
 
 		if columnType != "" && g.isMapType(columnType) {
-			escapedColumn := g.escapeIdentifier(f.Base)
+			escapedColumn := g.columnRef(f.Base)
 			var escapedPath []string
 			for _, segment := range f.Path {
 				s := strings.TrimPrefix(segment, "\"")
@@ -365,7 +580,7 @@ func (g *SQLGenerator) generateSelectFieldExpression(selectField SelectField) st
 			fullKey := strings.Join(escapedPath, ".")
 			columnExpression = fmt.Sprintf("%s['%s']", escapedColumn, fullKey)
 		} else {
-			escapedColumn := g.escapeIdentifier(f.Base)
+			escapedColumn := g.columnRef(f.Base)
 			var pathParams []string
 			for _, segment := range f.Path {
 				s := strings.TrimPrefix(segment, "\"")
@@ -377,9 +592,8 @@ func (g *SQLGenerator) generateSelectFieldExpression(selectField SelectField) st
 			columnExpression = fmt.Sprintf("JSONExtractString(%s, %s)", escapedColumn, strings.Join(pathParams, ", "))
 		}
 	case string:
-		simpleFieldName = f
 		if g.columnExists(f) {
-			columnExpression = g.escapeIdentifier(f)
+			columnExpression = g.columnRef(f)
 		} else if mapCol := g.findDefaultMapColumn(); mapCol != "" {
 			columnExpression = fmt.Sprintf("%s['%s']", g.escapeIdentifier(mapCol), g.escapeSQLString(f))
 		} else {
@@ -388,17 +602,39 @@ func (g *SQLGenerator) generateSelectFieldExpression(selectField SelectField) st
 	default:
 		return ""
 	}
+	return columnExpression
+}
 
-	// Add alias if provided, or generate one for nested/map fields
-	switch {
-	case selectField.Alias != "":
-		return fmt.Sprintf("%s AS %s", columnExpression, g.escapeIdentifier(selectField.Alias))
-	case nestedField != nil:
-		autoAlias := nestedField.Base + "_" + strings.Join(nestedField.Path, "_")
-		return fmt.Sprintf("%s AS %s", columnExpression, g.escapeIdentifier(autoAlias))
-	case simpleFieldName != "" && !g.columnExists(simpleFieldName):
-		return fmt.Sprintf("%s AS %s", columnExpression, g.escapeIdentifier(simpleFieldName))
-	default:
-		return columnExpression
+// GroupByExpression returns the SQL expression a histogram groups by for
+// field: a column, or a dotted path into a Map or JSON column. It resolves
+// field the way the pipe operator resolves select fields, so a name that isn't
+// a column is read from the default Map column. Given a schema, a field that
+// resolves to neither is an ErrInvalidIdentifier error.
+func GroupByExpression(field string, schema *Schema) (string, *ParseError) {
+	return resolveFieldExpression(field, schema, "group-by field")
+}
+
+// FieldExpression returns the SQL expression that reads field, resolved like
+// GroupByExpression: a column, or a dotted path into a Map or JSON column.
+// Given a schema, a field that resolves to neither is an
+// ErrInvalidIdentifier error.
+func FieldExpression(field string, schema *Schema) (string, *ParseError) {
+	return resolveFieldExpression(field, schema, "field")
+}
+
+// resolveFieldExpression implements GroupByExpression and FieldExpression;
+// what names the field in error messages.
+func resolveFieldExpression(field string, schema *Schema, what string) (string, *ParseError) {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return "", &ParseError{Code: ErrInvalidIdentifier, Message: what + " is required"}
+	}
+	g := NewSQLGenerator(schema)
+	var ref any = field
+	if base, rest, ok := strings.Cut(field, "."); ok && !g.columnExists(field) && g.columnExists(base) {
+		ref = NestedField{Base: base, Path: strings.Split(rest, ".")}
+	} else if len(g.colTypes) > 0 && !g.columnExists(field) && g.findDefaultMapColumn() == "" {
+		return "", &ParseError{Code: ErrInvalidIdentifier, Message: fmt.Sprintf("unknown %s %q", what, field)}
 	}
+	return g.fieldExpression(ref), nil
 }
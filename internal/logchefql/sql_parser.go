@@ -0,0 +1,636 @@
+package logchefql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// sqlFilterLexer tokenizes the subset of ClickHouse SQL that SQLGenerator
+// emits for filters.
+var sqlFilterLexer = lexer.MustSimple([]lexer.SimpleRule{
+	{Name: "Whitespace", Pattern: `[ \t\n\r]+`},
+
+	{Name: "String", Pattern: `'(?:[^'\\]|\\.|'')*'`},
+	{Name: "Backtick", Pattern: "`(?:[^`]|``)*`"},
+
+	{Name: "Operator", Pattern: `!=|<>|>=|<=|==|[=><]`},
+
+	{Name: "LParen", Pattern: `\(`},
+	{Name: "RParen", Pattern: `\)`},
+	{Name: "LBracket", Pattern: `\[`},
+	{Name: "RBracket", Pattern: `\]`},
+	{Name: "Comma", Pattern: `,`},
+
+	{Name: "Number", Pattern: `[-+]?[0-9]*\.?[0-9]+(?:[eE][-+]?[0-9]+)?`},
+	{Name: "Minus", Pattern: `-`},
+
+	{Name: "Ident", Pattern: `[a-zA-Z_][a-zA-Z0-9_]*`},
+})
+
+// sqlFilter is a WHERE expression, optionally led by the WHERE keyword.
+type sqlFilter struct {
+	Where *sqlOrExpr `parser:"'where':Ident? @@"`
+}
+
+type sqlOrExpr struct {
+	Left  *sqlAndExpr   `parser:"@@"`
+	Right []*sqlAndExpr `parser:"( 'or':Ident @@ )*"`
+}
+
+type sqlAndExpr struct {
+	Left  *sqlTerm   `parser:"@@"`
+	Right []*sqlTerm `parser:"( 'and':Ident @@ )*"`
+}
+
+type sqlTerm struct {
+	Not        bool           `parser:"( @'not':Ident (?! Operator | LBracket) )?"`
+	Group      *sqlOrExpr     `parser:"( LParen @@ RParen"`
+	Comparison *sqlComparison `parser:"| @@ )"`
+}
+
+// sqlComparison is "operand <op> value" or "operand [NOT] ILIKE 'pattern'".
+type sqlComparison struct {
+	Pos      lexer.Position
+	Operand  *sqlOperand `parser:"@@"`
+	Operator string      `parser:"( @Operator"`
+	Value    *sqlValue   `parser:"  @@"`
+	Not      bool        `parser:"| @'not':Ident?"`
+	Like     string      `parser:"  @( 'ilike':Ident | 'like':Ident )"`
+	Pattern  *string     `parser:"  @String )"`
+}
+
+type sqlOperand struct {
+	Call   *sqlCall      `parser:"( @@"`
+	Column *sqlColumnRef `parser:"| @@ )"`
+}
+
+type sqlCall struct {
+	Name string    `parser:"@Ident LParen"`
+	Args []*sqlArg `parser:"( @@ ( Comma @@ )* )? RParen"`
+}
+
+type sqlArg struct {
+	String *string       `parser:"( @String"`
+	Number *float64      `parser:"| @Number"`
+	Call   *sqlCall      `parser:"| @@"`
+	Column *sqlColumnRef `parser:"| @@ )"`
+}
+
+// sqlColumnRef is a column, optionally indexed by a map key: `col`['key'].
+type sqlColumnRef struct {
+	Bare   *string `parser:"( @Ident"`
+	Quoted *string `parser:"| @Backtick )"`
+	Key    *string `parser:"( LBracket @String RBracket )?"`
+}
+
+// sqlValue is a literal or call, optionally minus an interval as in
+// now() - INTERVAL 15 MINUTE.
+type sqlValue struct {
+	String   *string      `parser:"( @String"`
+	Number   *float64     `parser:"| @Number"`
+	Call     *sqlCall     `parser:"| @@"`
+	Ident    *string      `parser:"| @Ident )"`
+	Interval *sqlInterval `parser:"( Minus 'interval':Ident @@ )?"`
+}
+
+type sqlInterval struct {
+	Amount float64 `parser:"@Number"`
+	Unit   string  `parser:"@Ident"`
+}
+
+var sqlFilterParser = participle.MustBuild[sqlFilter](
+	participle.Lexer(sqlFilterLexer),
+	participle.CaseInsensitive("Ident"),
+	participle.Elide("Whitespace"),
+	participle.UseLookahead(2),
+)
+
+// bareFieldName matches field names LogchefQL accepts without quoting.
+var bareFieldName = regexp.MustCompile(`^@?[a-zA-Z_][a-zA-Z0-9_:@-]*$`)
+
+// FromSQL converts a ClickHouse SQL filter back into LogchefQL text; see
+// ParseSQLFilter for what converts.
+func FromSQL(sql string) (string, error) {
+	node, err := ParseSQLFilter(sql)
+	if err != nil {
+		return "", err
+	}
+	return Render(node), nil
+}
+
+// ParseSQLFilter parses a ClickHouse SQL filter into a LogchefQL AST. It
+// accepts what SQLGenerator emits: comparisons, positionCaseInsensitive
+// substring matches, map keys and JSONExtractString/JSONExtractFloat paths,
+// now() - INTERVAL and toDateTime64 times, joined with AND, OR, NOT and
+// parentheses. "col [NOT] ILIKE '%text%'" converts to
+// a substring match too. A leading WHERE is ignored and an empty filter yields
+// a nil node. Anything else fails with a *ParseError; constructs that parse
+// but have no LogchefQL equivalent use ErrUnsupportedFeature.
+func ParseSQLFilter(sql string) (ASTNode, error) {
+	if strings.TrimSpace(sql) == "" {
+		return nil, nil
+	}
+	if err := checkQueryLimits(sql); err != nil {
+		return nil, err
+	}
+	parsed, err := sqlFilterParser.ParseString("", sql)
+	if err != nil {
+		return nil, convertParticipleError(err)
+	}
+	node, err := convertSQLOrExpr(parsed.Where)
+	if err != nil {
+		return nil, err
+	}
+	return unwrapGroup(node, ""), nil
+}
+
+func convertSQLOrExpr(or *sqlOrExpr) (ASTNode, error) {
+	terms := append([]*sqlAndExpr{or.Left}, or.Right...)
+	children := make([]ASTNode, 0, len(terms))
+	for _, term := range terms {
+		child, err := convertSQLAndExpr(term)
+		if err != nil {
+			return nil, err
+		}
+		children = appendFlattened(children, unwrapGroup(child, BoolOr), BoolOr)
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return &LogicalNode{Operator: BoolOr, Children: children}, nil
+}
+
+func convertSQLAndExpr(and *sqlAndExpr) (ASTNode, error) {
+	terms := append([]*sqlTerm{and.Left}, and.Right...)
+	children := make([]ASTNode, 0, len(terms))
+	for _, term := range terms {
+		var child ASTNode
+		var err error
+		if term.Group != nil {
+			child, err = convertSQLOrExpr(term.Group)
+			child = &GroupNode{Children: []ASTNode{child}}
+		} else {
+			child, err = convertSQLComparison(term.Comparison)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if term.Not {
+			child = negate(child)
+		}
+		children = appendFlattened(children, unwrapGroup(child, BoolAnd), BoolAnd)
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return &LogicalNode{Operator: BoolAnd, Children: children}, nil
+}
+
+// unwrapGroup drops the parentheses SQLGenerator puts around every operand
+// where LogchefQL's precedence makes them redundant inside a parent chain of
+// parentOp ("" at the top level).
+func unwrapGroup(node ASTNode, parentOp BoolOperator) ASTNode {
+	group, ok := node.(*GroupNode)
+	if !ok || len(group.Children) != 1 {
+		return node
+	}
+	inner := unwrapGroup(group.Children[0], parentOp)
+	logical, ok := inner.(*LogicalNode)
+	if !ok || parentOp == "" || logical.Operator == parentOp || logical.Operator == BoolAnd {
+		return inner
+	}
+	return &GroupNode{Children: []ASTNode{inner}}
+}
+
+// negate wraps node in a NotNode, keeping parentheses unless it negates a
+// single comparison.
+func negate(node ASTNode) ASTNode {
+	inner := unwrapGroup(node, "")
+	if _, ok := inner.(*ExpressionNode); !ok {
+		inner = &GroupNode{Children: []ASTNode{inner}}
+	}
+	return &NotNode{Child: inner}
+}
+
+// appendFlattened appends child to a chain of op, splicing in the children of
+// a nested chain of the same operator.
+func appendFlattened(children []ASTNode, child ASTNode, op BoolOperator) []ASTNode {
+	if logical, ok := child.(*LogicalNode); ok && logical.Operator == op {
+		return append(children, logical.Children...)
+	}
+	return append(children, child)
+}
+
+func convertSQLComparison(cmp *sqlComparison) (ASTNode, error) {
+	if cmp.Like != "" {
+		return convertSQLLike(cmp)
+	}
+
+	// positionCaseInsensitive(x, 'text') > 0 is LogchefQL's substring match.
+	if call := cmp.Operand.Call; call != nil && strings.EqualFold(call.Name, "positionCaseInsensitive") {
+		if len(call.Args) != 2 || call.Args[1].String == nil || cmp.Value == nil || cmp.Value.Number == nil || *cmp.Value.Number != 0 {
+			return nil, unsupportedSQL(cmp.Pos, "positionCaseInsensitive must compare a column and a string literal with 0")
+		}
+		var op Operator
+		switch cmp.Operator {
+		case ">", "!=", "<>":
+			op = OpRegex
+		case "=", "==":
+			op = OpNotRegex
+		default:
+			return nil, unsupportedSQL(cmp.Pos, "positionCaseInsensitive must be compared with > 0 or = 0")
+		}
+		key, err := convertSQLArgField(call.Args[0], true, cmp.Pos)
+		if err != nil {
+			return nil, err
+		}
+		return &ExpressionNode{Key: key, Operator: op, Value: unescapeSQLString(*call.Args[1].String), Quoted: true}, nil
+	}
+
+	op, ok := sqlComparisonOperator(cmp.Operator)
+	if !ok {
+		return nil, unsupportedSQL(cmp.Pos, fmt.Sprintf("operator %s has no LogchefQL equivalent", cmp.Operator))
+	}
+	operand, rawValue := uncastComparison(cmp.Operand, cmp.Value, op)
+	value, quoted, err := convertSQLValue(rawValue, op, cmp.Pos)
+	if err != nil {
+		return nil, err
+	}
+	_, numeric := value.(float64)
+	key, err := convertSQLOperandField(operand, numeric, cmp.Pos)
+	if err != nil {
+		return nil, err
+	}
+	return &ExpressionNode{Key: key, Operator: op, Value: value, Quoted: quoted}, nil
+}
+
+// convertSQLLike converts "x [NOT] ILIKE '%text%'" to a substring match. LIKE
+// is case-sensitive and other patterns use wildcards LogchefQL can't express.
+func convertSQLLike(cmp *sqlComparison) (ASTNode, error) {
+	if !strings.EqualFold(cmp.Like, "ilike") {
+		return nil, unsupportedSQL(cmp.Pos, "LIKE is case-sensitive; only ILIKE '%text%' converts to ~")
+	}
+	pattern := unescapeSQLString(*cmp.Pattern)
+	text, ok := strings.CutPrefix(pattern, "%")
+	if ok {
+		text, ok = strings.CutSuffix(text, "%")
+	}
+	if !ok || text == "" || strings.ContainsAny(text, `%_\`) {
+		return nil, unsupportedSQL(cmp.Pos, "only ILIKE '%text%' without other wildcards converts to ~")
+	}
+	key, err := convertSQLOperandField(cmp.Operand, false, cmp.Pos)
+	if err != nil {
+		return nil, err
+	}
+	op := OpRegex
+	if cmp.Not {
+		op = OpNotRegex
+	}
+	return &ExpressionNode{Key: key, Operator: op, Value: text, Quoted: true}, nil
+}
+
+// uncastComparison undoes the casts SQLGenerator wraps map values and
+// JSONExtractString paths in: toFloat64OrNull(m['k']) compared with a number,
+// and parseDateTime64BestEffortOrNull(x, 3) compared by a range operator with
+// now() or parseDateTime64BestEffort('<datetime>', 3). Anything else is
+// returned unchanged.
+func uncastComparison(operand *sqlOperand, value *sqlValue, op Operator) (*sqlOperand, *sqlValue) {
+	call := operand.Call
+	if call == nil || len(call.Args) == 0 || !hasPrecisionArg(call.Args[1:]) {
+		return operand, value
+	}
+	var inner *sqlOperand
+	switch arg := call.Args[0]; {
+	case arg.Column != nil && arg.Column.Key != nil:
+		inner = &sqlOperand{Column: arg.Column}
+	case arg.Call != nil && strings.EqualFold(arg.Call.Name, "JSONExtractString"):
+		inner = &sqlOperand{Call: arg.Call}
+	default:
+		return operand, value
+	}
+	switch {
+	case strings.EqualFold(call.Name, "toFloat64OrNull") && len(call.Args) == 1 && inner.Column != nil && value.Number != nil:
+		return inner, value
+	case strings.EqualFold(call.Name, "parseDateTime64BestEffortOrNull") && isRangeOperator(op) && value.Call != nil:
+		if isSQLNow(value.Call) {
+			return inner, value
+		}
+		parse := value.Call
+		if !strings.EqualFold(parse.Name, "parseDateTime64BestEffort") || len(parse.Args) == 0 ||
+			parse.Args[0].String == nil || !hasPrecisionArg(parse.Args[1:]) || value.Interval != nil {
+			return operand, value
+		}
+		if !isDateTimeValue(unescapeSQLString(*parse.Args[0].String)) {
+			return operand, value
+		}
+		return inner, &sqlValue{String: parse.Args[0].String}
+	}
+	return operand, value
+}
+
+// hasPrecisionArg reports whether rest is empty or a single number, the
+// optional precision of the datetime casts.
+func hasPrecisionArg(rest []*sqlArg) bool {
+	return len(rest) == 0 || (len(rest) == 1 && rest[0].Number != nil)
+}
+
+func sqlComparisonOperator(op string) (Operator, bool) {
+	switch op {
+	case "==":
+		return OpEquals, true
+	case "<>":
+		return OpNotEquals, true
+	default:
+		return ParseOperator(op)
+	}
+}
+
+// convertSQLValue converts a literal. Bare identifiers are only accepted as
+// true, false and NULL; anything else would be a column reference. Range
+// comparisons also accept the times SQLGenerator emits.
+func convertSQLValue(v *sqlValue, op Operator, pos lexer.Position) (value any, quoted bool, err error) {
+	if v.Call != nil && isRangeOperator(op) {
+		if value, quoted, ok := convertSQLTime(v); ok {
+			return value, quoted, nil
+		}
+	}
+	if v.Interval != nil {
+		return nil, false, unsupportedSQL(pos, "only now() - INTERVAL converts to a relative time such as now-15m")
+	}
+	switch {
+	case v.String != nil:
+		return unescapeSQLString(*v.String), true, nil
+	case v.Number != nil:
+		return *v.Number, false, nil
+	case v.Call != nil:
+		return nil, false, unsupportedSQL(pos, fmt.Sprintf("comparing against %s() is not supported; the right-hand side must be a literal", v.Call.Name))
+	}
+	switch strings.ToLower(*v.Ident) {
+	case "true":
+		return true, false, nil
+	case "false":
+		return false, false, nil
+	case "null":
+		return nil, false, nil
+	}
+	return nil, false, unsupportedSQL(pos, fmt.Sprintf("comparing against %s is not supported; the right-hand side must be a literal", *v.Ident))
+}
+
+// sqlIntervalUnits maps ClickHouse interval units back to RelativeTime units.
+var sqlIntervalUnits = map[string]string{
+	"SECOND": "s",
+	"MINUTE": "m",
+	"HOUR":   "h",
+	"DAY":    "d",
+	"WEEK":   "w",
+}
+
+// convertSQLTime converts now() [- INTERVAL n UNIT] to a RelativeTime and
+// toDateTime64('<datetime>', p[, 'UTC']) to a datetime string, written in
+// RFC 3339 when it is in UTC. ok is false for anything else.
+func convertSQLTime(v *sqlValue) (value any, quoted bool, ok bool) {
+	call := v.Call
+	if isSQLNow(call) {
+		if v.Interval == nil {
+			return RelativeTime{}, false, true
+		}
+		unit, known := sqlIntervalUnits[strings.ToUpper(v.Interval.Unit)]
+		amount := v.Interval.Amount
+		if !known || amount < 0 || amount != float64(int(amount)) {
+			return nil, false, false
+		}
+		return RelativeTime{Amount: int(amount), Unit: unit}, false, true
+	}
+	if !strings.EqualFold(call.Name, "toDateTime64") || v.Interval != nil ||
+		len(call.Args) < 2 || len(call.Args) > 3 || call.Args[0].String == nil || call.Args[1].Number == nil {
+		return nil, false, false
+	}
+	literal := unescapeSQLString(*call.Args[0].String)
+	if !isDateTimeValue(literal) {
+		return nil, false, false
+	}
+	if len(call.Args) == 2 {
+		return literal, true, true
+	}
+	if call.Args[2].String == nil || unescapeSQLString(*call.Args[2].String) != "UTC" {
+		return nil, false, false
+	}
+	t, err := time.Parse("2006-01-02 15:04:05.999999999", literal)
+	if err != nil {
+		return nil, false, false
+	}
+	return t.Format(time.RFC3339Nano), true, true
+}
+
+func isSQLNow(call *sqlCall) bool {
+	return call != nil && strings.EqualFold(call.Name, "now") && len(call.Args) == 0
+}
+
+// convertSQLOperandField converts the left side of a comparison to a field.
+// numeric reports whether it is compared with a number, which decides which
+// JSONExtract function SQLGenerator would have used.
+func convertSQLOperandField(operand *sqlOperand, numeric bool, pos lexer.Position) (any, error) {
+	if operand.Column != nil {
+		return convertSQLColumnRef(operand.Column), nil
+	}
+	return convertSQLJSONExtract(operand.Call, numeric, pos)
+}
+
+func convertSQLArgField(arg *sqlArg, stringMatch bool, pos lexer.Position) (any, error) {
+	switch {
+	case arg.Column != nil:
+		return convertSQLColumnRef(arg.Column), nil
+	case arg.Call != nil:
+		return convertSQLJSONExtract(arg.Call, !stringMatch, pos)
+	default:
+		return nil, unsupportedSQL(pos, "the first argument must be a column")
+	}
+}
+
+// convertSQLJSONExtract converts JSONExtractString(col, 'a', 'b') to col.a.b.
+// SQLGenerator picks JSONExtractFloat for numeric comparisons and
+// JSONExtractString otherwise, so any other pairing would change the query.
+func convertSQLJSONExtract(call *sqlCall, numeric bool, pos lexer.Position) (any, error) {
+	want := "JSONExtractString"
+	if numeric {
+		want = "JSONExtractFloat"
+	}
+	if !strings.EqualFold(call.Name, want) {
+		return nil, unsupportedSQL(pos, fmt.Sprintf("function %s has no LogchefQL equivalent here", call.Name))
+	}
+	if len(call.Args) < 2 || call.Args[0].Column == nil || call.Args[0].Column.Key != nil {
+		return nil, unsupportedSQL(pos, fmt.Sprintf("%s must take a column and one or more keys", call.Name))
+	}
+	path := make([]string, 0, len(call.Args)-1)
+	for _, arg := range call.Args[1:] {
+		if arg.String == nil {
+			return nil, unsupportedSQL(pos, fmt.Sprintf("%s keys must be string literals", call.Name))
+		}
+		path = append(path, unescapeSQLString(*arg.String))
+	}
+	return NestedField{Base: sqlColumnName(call.Args[0].Column), Path: path}, nil
+}
+
+// convertSQLColumnRef converts a column to a field. A map key becomes a
+// nested path split on dots, which SQLGenerator joins back into one key.
+func convertSQLColumnRef(ref *sqlColumnRef) any {
+	name := sqlColumnName(ref)
+	if ref.Key == nil {
+		return name
+	}
+	return NestedField{Base: name, Path: strings.Split(unescapeSQLString(*ref.Key), ".")}
+}
+
+func sqlColumnName(ref *sqlColumnRef) string {
+	if ref.Bare != nil {
+		return *ref.Bare
+	}
+	quoted := *ref.Quoted
+	return strings.ReplaceAll(quoted[1:len(quoted)-1], "``", "`")
+}
+
+// unescapeSQLString strips the quotes from a SQL string literal and resolves
+// backslash escapes and doubled quotes.
+func unescapeSQLString(literal string) string {
+	s := literal[1 : len(literal)-1]
+	var result strings.Builder
+	result.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			result.WriteByte('\'')
+			i++
+		case s[i] == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				result.WriteByte('\n')
+			case 't':
+				result.WriteByte('\t')
+			case 'r':
+				result.WriteByte('\r')
+			case '0':
+				result.WriteByte(0)
+			default:
+				result.WriteByte(s[i])
+			}
+		default:
+			result.WriteByte(s[i])
+		}
+	}
+	return result.String()
+}
+
+func unsupportedSQL(pos lexer.Position, message string) *ParseError {
+	return &ParseError{
+		Code:     ErrUnsupportedFeature,
+		Message:  message,
+		Position: &Position{Line: pos.Line, Column: pos.Column},
+	}
+}
+
+// Render writes an AST back out as LogchefQL text.
+func Render(node ASTNode) string {
+	switch n := node.(type) {
+	case *ExpressionNode:
+		return renderField(n.Key) + string(n.Operator) + renderValue(n.Value)
+	case *LogicalNode:
+		parts := make([]string, len(n.Children))
+		for i, child := range n.Children {
+			parts[i] = Render(child)
+		}
+		return strings.Join(parts, " "+strings.ToLower(string(n.Operator))+" ")
+	case *NotNode:
+		return "not " + Render(n.Child)
+	case *GroupNode:
+		parts := make([]string, len(n.Children))
+		for i, child := range n.Children {
+			parts[i] = Render(child)
+		}
+		return "(" + strings.Join(parts, " and ") + ")"
+	case *QueryNode:
+		query := Render(n.Where)
+		if len(n.Select) == 0 {
+			return query
+		}
+		fields := make([]string, len(n.Select))
+		for i, sf := range n.Select {
+			fields[i] = renderField(sf.Field)
+		}
+		return strings.TrimSpace(query + " | " + strings.Join(fields, " "))
+	default:
+		return ""
+	}
+}
+
+func renderField(field any) string {
+	switch f := field.(type) {
+	case string:
+		return renderFieldSegment(f)
+	case NestedField:
+		segments := make([]string, 0, len(f.Path)+1)
+		segments = append(segments, renderFieldSegment(f.Base))
+		for _, seg := range f.Path {
+			segments = append(segments, renderFieldSegment(seg))
+		}
+		return strings.Join(segments, ".")
+	default:
+		return ""
+	}
+}
+
+func renderFieldSegment(name string) string {
+	lower := strings.ToLower(name)
+	if bareFieldName.MatchString(name) && lower != "and" && lower != "or" {
+		return name
+	}
+	return quoteLogchefQLString(name)
+}
+
+func renderValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return quoteLogchefQLString(v)
+	case RelativeTime:
+		return v.String()
+	default:
+		return quoteLogchefQLString(fmt.Sprintf("%v", v))
+	}
+}
+
+// quoteLogchefQLString quotes s as a LogchefQL string literal; unescapeString
+// reverses it.
+func quoteLogchefQLString(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -3,6 +3,8 @@
 // while translating to efficient ClickHouse SQL queries.
 package logchefql
 
+import "fmt"
+
 // Operator represents comparison operators in LogchefQL
 type Operator string
 
@@ -58,6 +60,20 @@ type NestedField struct {
 	Path []string `json:"path"`
 }
 
+// RelativeTime is a comparison value relative to when the query runs: now, or
+// now minus Amount of Unit (s, m, h, d or w), written now-15m.
+type RelativeTime struct {
+	Amount int    `json:"amount,omitempty"`
+	Unit   string `json:"unit,omitempty"`
+}
+
+func (r RelativeTime) String() string {
+	if r.Unit == "" {
+		return "now"
+	}
+	return fmt.Sprintf("now-%d%s", r.Amount, r.Unit)
+}
+
 // SelectField represents a field selection with optional alias
 type SelectField struct {
 	Field any    `json:"field"` // string or NestedField
@@ -73,7 +89,7 @@ type ASTNode interface {
 type ExpressionNode struct {
 	Key      any      `json:"key"` // string or NestedField
 	Operator Operator `json:"operator"`
-	Value    any      `json:"value"` // string, number, bool, or nil
+	Value    any      `json:"value"` // string, number, bool, RelativeTime, or nil
 	Quoted   bool     `json:"quoted,omitempty"`
 }
 
@@ -94,6 +110,13 @@ type GroupNode struct {
 
 func (g *GroupNode) nodeType() string { return "group" }
 
+// NotNode negates its child (e.g., not (a="1" or b="2"))
+type NotNode struct {
+	Child ASTNode `json:"child"`
+}
+
+func (n *NotNode) nodeType() string { return "not" }
+
 // QueryNode represents the top-level query with optional WHERE and SELECT
 type QueryNode struct {
 	Where  ASTNode       `json:"where,omitempty"`
@@ -138,6 +161,9 @@ const (
 type ColumnInfo struct {
 	Name string `json:"name"`
 	Type string `json:"type"`
+	// Expression, when set, makes this a virtual column: filters and selects
+	// use the expression instead of a column reference.
+	Expression string `json:"expression,omitempty"`
 }
 
 // Schema represents table schema information for type-aware SQL generation
@@ -168,8 +194,28 @@ type TranslateResult struct {
 type ValidateResult struct {
 	Valid bool        `json:"valid"`
 	Error *ParseError `json:"error,omitempty"`
+	// Warnings are lint findings for a query that parses; see Lint.
+	Warnings []Diagnostic `json:"warnings,omitempty"`
 }
 
+// Diagnostic is a lint finding about a query that is valid but likely slow or
+// wrong. Position, when set, points at the offending field or clause.
+type Diagnostic struct {
+	Code     string    `json:"code"`
+	Message  string    `json:"message"`
+	Field    string    `json:"field,omitempty"`
+	Position *Position `json:"position,omitempty"`
+}
+
+// Lint warning codes
+const (
+	WarnUnknownField           = "UNKNOWN_FIELD"
+	WarnRegexOnUnindexedColumn = "REGEX_ON_UNINDEXED_COLUMN"
+	WarnMissingTimeBound       = "MISSING_TIME_BOUND"
+	WarnSelectStarOnWideTable  = "SELECT_STAR_ON_WIDE_TABLE"
+	WarnLimitTooLarge          = "LIMIT_TOO_LARGE"
+)
+
 // ParseOperator converts a string to an Operator, returning ok=false if invalid
 func ParseOperator(s string) (Operator, bool) {
 	switch s {
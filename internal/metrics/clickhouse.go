@@ -65,6 +65,16 @@ func (m *ClickHouseMetrics) UpdateConnectionStatus(healthy bool) {
 	RecordClickHouseConnectionStatus(m.source, healthy)
 }
 
+// UpdateReplicaStatus updates the health status of one of the source's replicas
+func (m *ClickHouseMetrics) UpdateReplicaStatus(replica string, healthy bool) {
+	RecordClickHouseReplicaStatus(m.source, replica, healthy)
+}
+
+// RecordReplicaFailover records a connection failing over from replica
+func (m *ClickHouseMetrics) RecordReplicaFailover(replica string) {
+	RecordClickHouseReplicaFailover(m.source, replica)
+}
+
 // QueryMetricsHelper provides a helper for timing query operations
 type QueryMetricsHelper struct {
 	metrics   *ClickHouseMetrics
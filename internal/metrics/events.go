@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/VictoriaMetrics/metrics"
+
+	"github.com/mr-karan/logchef/internal/events"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// ObserveEvents exports what is published on bus: every event counts towards
+// logchef_events_total{type}, and source health changes towards
+// logchef_source_health_changes_total{source_id,source_name,status}.
+func ObserveEvents(bus *events.Bus) (unsubscribe func()) {
+	stopCounting := bus.Subscribe(func(event models.Event) {
+		metrics.GetOrCreateCounter(fmt.Sprintf(`logchef_events_total{type=%q}`, event.Type)).Inc()
+	})
+	stopHealth := events.On(bus, models.EventSourceHealthChanged, func(_ models.Event, data models.SourceHealthEventData) {
+		labels := fmt.Sprintf(`logchef_source_health_changes_total{source_id="%d",source_name=%q,status=%q}`, data.SourceID, data.Name, data.Status)
+		metrics.GetOrCreateCounter(labels).Inc()
+	})
+	return func() {
+		stopCounting()
+		stopHealth()
+	}
+}
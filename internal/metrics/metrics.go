@@ -3,6 +3,7 @@ package metrics
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/VictoriaMetrics/metrics"
@@ -125,6 +126,27 @@ func RecordClickHouseReconnection(source *models.Source, success bool) {
 	metrics.GetOrCreateCounter(labels).Inc()
 }
 
+// RecordClickHouseReplicaStatus sets the status of one replica of a source
+// whose host lists several.
+func RecordClickHouseReplicaStatus(source *models.Source, replica string, healthy bool) {
+	status := 0.0
+	if healthy {
+		status = 1.0
+	}
+
+	labels := fmt.Sprintf(`logchef_clickhouse_replica_status{source_id="%d",source_name=%q,database=%q,table=%q,replica=%q}`,
+		source.ID, source.Name, source.Connection.Database, source.Connection.TableName, replica)
+	metrics.GetOrCreateGauge(labels, nil).Set(status)
+}
+
+// RecordClickHouseReplicaFailover counts connections that failed to open on
+// replica and moved on to the source's next replica.
+func RecordClickHouseReplicaFailover(source *models.Source, replica string) {
+	labels := fmt.Sprintf(`logchef_clickhouse_replica_failovers_total{source_id="%d",source_name=%q,database=%q,table=%q,replica=%q}`,
+		source.ID, source.Name, source.Connection.Database, source.Connection.TableName, replica)
+	metrics.GetOrCreateCounter(labels).Inc()
+}
+
 // RecordAuthAttempt records authentication attempt metrics
 func RecordAuthAttempt(method string, success bool, user *models.User) {
 	result := "success"
@@ -175,8 +197,8 @@ func RecordAuthorizationFailure(endpoint string, user *models.User, reason strin
 }
 
 // RecordRateLimitRejection records a request rejected by a rate limiter.
-// scope is "auth" (unauthenticated auth/token endpoints) or "query"
-// (per-user query endpoints).
+// scope is "auth" (unauthenticated auth/token endpoints), "query" (per-user
+// query endpoints) or "query_team" (per-team query endpoints).
 func RecordRateLimitRejection(scope string) {
 	labels := fmt.Sprintf(`logchef_rate_limit_rejections_total{scope=%q}`, scope)
 	metrics.GetOrCreateCounter(labels).Inc()
@@ -207,6 +229,87 @@ func SetDashboardCacheEntries(n int) {
 	metrics.GetOrCreateGauge("logchef_dashboard_cache_entries", nil).Set(float64(n))
 }
 
+// RecordSyslogMessage records a message received by a syslog listener.
+// result is "received", "invalid" (empty) or "too_large".
+func RecordSyslogMessage(sourceID models.SourceID, result string) {
+	labels := fmt.Sprintf(`logchef_syslog_messages_total{source_id="%d",result=%q}`, sourceID, result)
+	metrics.GetOrCreateCounter(labels).Inc()
+}
+
+// RecordSyslogInsert records a batch insert from a syslog listener and its
+// row count.
+func RecordSyslogInsert(sourceID models.SourceID, success bool, rows int) {
+	result := "success"
+	if !success {
+		result = "failure"
+	}
+	metrics.GetOrCreateCounter(fmt.Sprintf(`logchef_syslog_inserts_total{source_id="%d",result=%q}`, sourceID, result)).Inc()
+	metrics.GetOrCreateCounter(fmt.Sprintf(`logchef_syslog_inserted_rows_total{source_id="%d",result=%q}`, sourceID, result)).Add(rows)
+}
+
+// queryUsageSet holds the query usage gauges. Each report replaces the whole
+// set, so users, sources and fields that drop out of the report stop being
+// exported.
+var (
+	queryUsageMu  sync.Mutex
+	queryUsageSet *metrics.Set
+)
+
+// SetQueryUsage publishes a query usage report as gauges.
+func SetQueryUsage(report *models.QueryUsageReport) {
+	set := metrics.NewSet()
+	set.GetOrCreateGauge("logchef_query_usage_queries", nil).Set(float64(report.TotalQueries))
+	set.GetOrCreateGauge("logchef_query_usage_failed_queries", nil).Set(float64(report.FailedQueries))
+	set.GetOrCreateGauge("logchef_query_usage_slow_queries", nil).Set(float64(report.SlowQueries))
+	for _, u := range report.TopUsers {
+		labels := fmt.Sprintf(`logchef_query_usage_user_queries{user_email=%q}`, u.UserEmail)
+		set.GetOrCreateGauge(labels, nil).Set(float64(u.Queries))
+	}
+	for _, src := range report.TopSources {
+		source := fmt.Sprintf(`source_id="%d",source_name=%q`, src.SourceID, src.SourceName)
+		set.GetOrCreateGauge(`logchef_query_usage_source_queries{`+source+`}`, nil).Set(float64(src.Queries))
+		set.GetOrCreateGauge(`logchef_query_usage_source_failed_queries{`+source+`}`, nil).Set(float64(src.FailedQueries))
+		set.GetOrCreateGauge(`logchef_query_usage_source_duration_avg_seconds{`+source+`}`, nil).Set(float64(src.AvgDurationMs) / 1000)
+		set.GetOrCreateGauge(`logchef_query_usage_source_duration_p95_seconds{`+source+`}`, nil).Set(float64(src.P95DurationMs) / 1000)
+	}
+	for _, f := range report.TopFields {
+		labels := fmt.Sprintf(`logchef_query_usage_field_filters{source_id="%d",source_name=%q,field=%q}`, f.SourceID, f.SourceName, f.Field)
+		set.GetOrCreateGauge(labels, nil).Set(float64(f.Queries))
+	}
+
+	queryUsageMu.Lock()
+	defer queryUsageMu.Unlock()
+	if queryUsageSet != nil {
+		metrics.UnregisterSet(queryUsageSet, true)
+	}
+	metrics.RegisterSet(set)
+	queryUsageSet = set
+}
+
+// RegisterActiveQueries exports the number of running queries of a class
+// (preview, export, tail) as logchef_active_queries{class}. count is called at
+// scrape time. Registering a class again keeps the first callback.
+func RegisterActiveQueries(class string, count func() int) {
+	labels := fmt.Sprintf(`logchef_active_queries{class=%q}`, class)
+	metrics.GetOrCreateGauge(labels, func() float64 { return float64(count()) })
+}
+
+// RecordAlertEvaluation records one alert evaluation. kind is "query" or
+// "burn_rate"; result is "success", "failure" or "timeout".
+func RecordAlertEvaluation(kind, result string, duration time.Duration) {
+	metrics.GetOrCreateCounter(fmt.Sprintf(`logchef_alert_evaluations_total{kind=%q,result=%q}`, kind, result)).Inc()
+	metrics.GetOrCreateHistogram(fmt.Sprintf(`logchef_alert_evaluation_duration_seconds{kind=%q}`, kind)).Update(duration.Seconds())
+}
+
+// RecordSQLiteQuery records a metadata store query. name is the sqlc query
+// name, or "other" for ad-hoc statements.
+func RecordSQLiteQuery(name string, success bool, duration time.Duration) {
+	metrics.GetOrCreateHistogram(fmt.Sprintf(`logchef_sqlite_query_duration_seconds{query=%q}`, name)).Update(duration.Seconds())
+	if !success {
+		metrics.GetOrCreateCounter(fmt.Sprintf(`logchef_sqlite_query_errors_total{query=%q}`, name)).Inc()
+	}
+}
+
 func IncrementActiveRequests() {
 	metrics.GetOrCreateGauge("logchef_http_active_requests", nil).Inc()
 }
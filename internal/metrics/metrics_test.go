@@ -1,9 +1,14 @@
 package metrics
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/VictoriaMetrics/metrics"
+
+	"github.com/mr-karan/logchef/internal/events"
+	"github.com/mr-karan/logchef/pkg/models"
 )
 
 func TestRecordRateLimitRejection(t *testing.T) {
@@ -27,3 +32,53 @@ func TestRecordRateLimitRejection(t *testing.T) {
 		t.Fatalf("query counter = %d, want %d", got, queryBefore+1)
 	}
 }
+
+func TestSetQueryUsageReplacesSeries(t *testing.T) {
+	export := func() string {
+		var buf bytes.Buffer
+		metrics.WritePrometheus(&buf, false)
+		return buf.String()
+	}
+
+	SetQueryUsage(&models.QueryUsageReport{
+		TotalQueries: 3,
+		TopSources:   []models.QueryUsageSource{{SourceID: 1, SourceName: "app", Queries: 3, P95DurationMs: 1500}},
+	})
+	out := export()
+	for _, want := range []string{
+		"logchef_query_usage_queries 3",
+		`logchef_query_usage_source_queries{source_id="1",source_name="app"} 3`,
+		`logchef_query_usage_source_duration_p95_seconds{source_id="1",source_name="app"} 1.5`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("export missing %q", want)
+		}
+	}
+
+	SetQueryUsage(&models.QueryUsageReport{TotalQueries: 1})
+	out = export()
+	if !strings.Contains(out, "logchef_query_usage_queries 1") {
+		t.Error("total not updated")
+	}
+	if strings.Contains(out, `source_name="app"`) {
+		t.Error("dropped source is still exported")
+	}
+}
+
+func TestObserveEvents(t *testing.T) {
+	const total = `logchef_events_total{type="source.health_changed"}`
+	const changes = `logchef_source_health_changes_total{source_id="5",source_name="edge",status="unhealthy"}`
+
+	bus := events.NewBus()
+	unsubscribe := ObserveEvents(bus)
+	bus.Publish(models.EventSourceHealthChanged, models.SourceHealthEventData{SourceID: 5, Name: "edge", Status: models.HealthStatusUnhealthy})
+	unsubscribe()
+	bus.Publish(models.EventSourceHealthChanged, models.SourceHealthEventData{SourceID: 5, Name: "edge", Status: models.HealthStatusUnhealthy})
+
+	if got := metrics.GetOrCreateCounter(total).Get(); got != 1 {
+		t.Errorf("%s = %d, want 1", total, got)
+	}
+	if got := metrics.GetOrCreateCounter(changes).Get(); got != 1 {
+		t.Errorf("%s = %d, want 1", changes, got)
+	}
+}
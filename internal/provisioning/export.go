@@ -11,11 +11,14 @@ import (
 )
 
 // ExportConfig reads the current database state and produces a ProvisioningConfig.
-// Passwords are replaced with secret_ref placeholders (never exported).
+// Passwords are replaced with secret_ref placeholders (never exported). SLO
+// burn-rate alerts are left out and alert channels are dropped, as neither can
+// be declared in provisioning config.
 func ExportConfig(ctx context.Context, db store.StoreOps) (*config.ProvisioningConfig, error) {
 	cfg := &config.ProvisioningConfig{
 		ManageSources: true,
 		ManageTeams:   true,
+		ManageAlerts:  true,
 		Prune:         false,
 		DryRun:        false,
 	}
@@ -34,6 +37,10 @@ func ExportConfig(ctx context.Context, db store.StoreOps) (*config.ProvisioningC
 			TTLDays:           src.TTLDays,
 			MetaTSField:       src.MetaTSField,
 			MetaSeverityField: src.MetaSeverityField,
+			Tags:              src.Tags,
+			ExtractionRules:   src.ExtractionRules,
+			TraceCorrelation:  src.TraceCorrelation,
+			MaskedColumns:     src.MaskedColumns,
 		}
 
 		switch models.NormalizeSourceType(src.SourceType) {
@@ -78,6 +85,12 @@ func ExportConfig(ctx context.Context, db store.StoreOps) (*config.ProvisioningC
 		}
 
 		cfg.Sources = append(cfg.Sources, provisioned)
+
+		alerts, err := exportAlerts(ctx, db, src)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Alerts = append(cfg.Alerts, alerts...)
 	}
 
 	// Export teams
@@ -119,6 +132,47 @@ func ExportConfig(ctx context.Context, db store.StoreOps) (*config.ProvisioningC
 	return cfg, nil
 }
 
+// exportAlerts returns the provisionable alerts of src, with recipients as
+// emails.
+func exportAlerts(ctx context.Context, db store.StoreOps, src *models.Source) ([]config.ProvisionAlert, error) {
+	alerts, err := db.ListAlertsBySource(ctx, src.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alerts for source %q: %w", src.Name, err)
+	}
+	var out []config.ProvisionAlert
+	for _, alert := range alerts {
+		if alert.SLOID != nil {
+			continue
+		}
+		pa := config.ProvisionAlert{
+			Name:              alert.Name,
+			Source:            src.Name,
+			Description:       alert.Description,
+			QueryLanguage:     alert.QueryLanguage,
+			EditorMode:        alert.EditorMode,
+			Query:             alert.Query,
+			ConditionJSON:     alert.ConditionJSON,
+			LookbackSeconds:   alert.LookbackSeconds,
+			ThresholdOperator: alert.ThresholdOperator,
+			ThresholdValue:    alert.ThresholdValue,
+			FrequencySeconds:  alert.FrequencySeconds,
+			ForSeconds:        alert.ForSeconds,
+			Severity:          alert.Severity,
+			Labels:            alert.Labels,
+			Annotations:       alert.Annotations,
+			WebhookURLs:       alert.WebhookURLs,
+			Disabled:          !alert.IsActive,
+		}
+		for _, id := range alert.RecipientUserIDs {
+			if user, err := db.GetUser(ctx, id); err == nil {
+				pa.Recipients = append(pa.Recipients, user.Email)
+			}
+		}
+		out = append(out, pa)
+	}
+	return out, nil
+}
+
 func sanitizeEnvName(name string) string {
 	var result []byte
 	for _, c := range []byte(strings.ToUpper(name)) {
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
@@ -60,6 +62,13 @@ func Reconcile(ctx context.Context, cfg *config.ProvisioningConfig, db store.Sto
 			}
 		}
 
+		// Phase 3: Alerts (depends on sources being reconciled)
+		if cfg.ManageAlerts {
+			if err := reconcileAlerts(ctx, tx, cfg, log); err != nil {
+				return fmt.Errorf("alert reconciliation failed: %w", err)
+			}
+		}
+
 		if cfg.DryRun {
 			return errDryRun
 		}
@@ -432,6 +441,174 @@ func reconcileTeamSources(ctx context.Context, tx store.StoreOps, teamID models.
 	return nil
 }
 
+// alertKey identifies an alert for provisioning: its name is unique per
+// source.
+type alertKey struct {
+	sourceID models.SourceID
+	name     string
+}
+
+func reconcileAlerts(ctx context.Context, tx store.StoreOps, cfg *config.ProvisioningConfig, log *slog.Logger) error {
+	existingManaged, err := tx.ListManagedAlerts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list managed alerts: %w", err)
+	}
+
+	managedByKey := make(map[alertKey]*models.Alert, len(existingManaged))
+	for _, alert := range existingManaged {
+		managedByKey[alertKey{alert.SourceID, alert.Name}] = alert
+	}
+
+	desiredIDs := make(map[models.AlertID]bool)
+
+	for i := range cfg.Alerts {
+		cfgAlert := cfg.Alerts[i]
+		src, err := tx.GetSourceByNameForProvisioning(ctx, cfgAlert.Source)
+		if err != nil {
+			return fmt.Errorf("source %q referenced by alert %q not found", cfgAlert.Source, cfgAlert.Name)
+		}
+		desired, err := alertFromConfig(ctx, tx, log, cfgAlert, src.ID)
+		if err != nil {
+			return fmt.Errorf("failed to build provisioned alert %q: %w", cfgAlert.Name, err)
+		}
+
+		if existing, isManaged := managedByKey[alertKey{src.ID, desired.Name}]; isManaged {
+			desiredIDs[existing.ID] = true
+			if !alertNeedsUpdate(existing, desired) {
+				continue
+			}
+			log.Info("updating managed alert", "name", desired.Name, "source", cfgAlert.Source)
+			desired.ID = existing.ID
+			if err := tx.UpdateAlert(ctx, desired); err != nil {
+				return fmt.Errorf("failed to update alert %q: %w", desired.Name, err)
+			}
+			continue
+		}
+
+		// Adopt an unmanaged alert of the same name on the source, otherwise
+		// create a new one.
+		onSource, err := tx.ListAlertsBySource(ctx, src.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list alerts of source %q: %w", cfgAlert.Source, err)
+		}
+		var adopt *models.Alert
+		for _, alert := range onSource {
+			if alert.Name == desired.Name {
+				adopt = alert
+				break
+			}
+		}
+		if adopt != nil {
+			log.Info("adopting existing alert as managed", "name", desired.Name, "source", cfgAlert.Source, "id", adopt.ID)
+			desired.ID = adopt.ID
+			if err := tx.UpdateAlert(ctx, desired); err != nil {
+				return fmt.Errorf("failed to adopt alert %q: %w", desired.Name, err)
+			}
+		} else {
+			log.Info("creating managed alert", "name", desired.Name, "source", cfgAlert.Source)
+			if err := tx.CreateAlert(ctx, desired); err != nil {
+				return fmt.Errorf("failed to create alert %q: %w", desired.Name, err)
+			}
+		}
+		if err := tx.SetAlertManaged(ctx, desired.ID, true); err != nil {
+			return fmt.Errorf("failed to set alert %q as managed: %w", desired.Name, err)
+		}
+		desiredIDs[desired.ID] = true
+	}
+
+	// Prune alerts not in config.
+	for _, alert := range existingManaged {
+		if desiredIDs[alert.ID] {
+			continue
+		}
+		if cfg.Prune {
+			log.Warn("pruning managed alert not in config", "name", alert.Name, "id", alert.ID)
+			if err := tx.DeleteAlert(ctx, alert.ID); err != nil {
+				return fmt.Errorf("failed to prune alert %q: %w", alert.Name, err)
+			}
+		} else {
+			log.Warn("managed alert not in config (prune=false, keeping)", "name", alert.Name, "id", alert.ID)
+		}
+	}
+	return nil
+}
+
+// alertFromConfig builds an Alert model for sourceID from provisioning config.
+// Recipients without a user account are skipped with a warning; they are
+// picked up on a later reconcile once the user has signed in.
+func alertFromConfig(ctx context.Context, tx store.StoreOps, log *slog.Logger, cfgAlert config.ProvisionAlert, sourceID models.SourceID) (*models.Alert, error) {
+	queryLanguage, editorMode, err := models.ResolveAlertMetadata(cfgAlert.QueryLanguage, cfgAlert.EditorMode)
+	if err != nil {
+		return nil, err
+	}
+
+	var recipients []models.UserID
+	for _, email := range cfgAlert.Recipients {
+		email = strings.ToLower(strings.TrimSpace(email))
+		user, err := tx.GetUserByEmail(ctx, email)
+		if err != nil {
+			log.Warn("alert recipient has no user account, skipping", "alert", cfgAlert.Name, "email", email)
+			continue
+		}
+		if !slices.Contains(recipients, user.ID) {
+			recipients = append(recipients, user.ID)
+		}
+	}
+
+	return &models.Alert{
+		SourceID:          sourceID,
+		Name:              strings.TrimSpace(cfgAlert.Name),
+		Description:       strings.TrimSpace(cfgAlert.Description),
+		QueryLanguage:     queryLanguage,
+		EditorMode:        editorMode,
+		Query:             strings.TrimSpace(cfgAlert.Query),
+		ConditionJSON:     strings.TrimSpace(cfgAlert.ConditionJSON),
+		LookbackSeconds:   cfgAlert.LookbackSeconds,
+		ThresholdOperator: cfgAlert.ThresholdOperator,
+		ThresholdValue:    cfgAlert.ThresholdValue,
+		FrequencySeconds:  cfgAlert.FrequencySeconds,
+		ForSeconds:        cfgAlert.ForSeconds,
+		Severity:          cfgAlert.Severity,
+		Labels:            nonEmptyMap(cfgAlert.Labels),
+		Annotations:       nonEmptyMap(cfgAlert.Annotations),
+		RecipientUserIDs:  recipients,
+		WebhookURLs:       cfgAlert.WebhookURLs,
+		IsActive:          !cfgAlert.Disabled,
+		Managed:           true,
+	}, nil
+}
+
+// alertNeedsUpdate reports whether a managed alert's definition diverges from
+// the desired one. Evaluation state (last_state, timestamps) is not compared.
+func alertNeedsUpdate(existing, desired *models.Alert) bool {
+	return existing.Description != desired.Description ||
+		existing.QueryLanguage != desired.QueryLanguage ||
+		existing.EditorMode != desired.EditorMode ||
+		existing.Query != desired.Query ||
+		existing.ConditionJSON != desired.ConditionJSON ||
+		existing.LookbackSeconds != desired.LookbackSeconds ||
+		existing.ThresholdOperator != desired.ThresholdOperator ||
+		existing.ThresholdValue != desired.ThresholdValue ||
+		existing.FrequencySeconds != desired.FrequencySeconds ||
+		existing.ForSeconds != desired.ForSeconds ||
+		existing.Severity != desired.Severity ||
+		!maps.Equal(existing.Labels, desired.Labels) ||
+		!maps.Equal(existing.Annotations, desired.Annotations) ||
+		!slices.Equal(existing.RecipientUserIDs, desired.RecipientUserIDs) ||
+		!slices.Equal(existing.WebhookURLs, desired.WebhookURLs) ||
+		len(existing.Channels) > 0 ||
+		existing.SLOID != nil ||
+		existing.GeneratorURL != "" ||
+		existing.IsActive != desired.IsActive
+}
+
+func nonEmptyMap(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
 // Helper functions
 
 func validateSourceConnection(ctx context.Context, ds *datasource.Service, src config.ProvisionSource, log *slog.Logger) error {
@@ -465,13 +642,18 @@ func sourceFromConfig(src config.ProvisionSource, id models.SourceID) (*models.S
 }
 
 // updateTeamDescription updates a team's name/description, stamping updated_at.
+// updateTeamDescription sets the team's description from config. UpdateTeam
+// writes every team column, so the rest are carried over from the stored
+// team rather than reset; provisioning doesn't manage the raw SQL switch.
 func updateTeamDescription(ctx context.Context, tx store.StoreOps, teamID models.TeamID, cfgTeam config.ProvisionTeam) error {
-	return tx.UpdateTeam(ctx, &models.Team{
-		ID:          teamID,
-		Name:        cfgTeam.Name,
-		Description: cfgTeam.Description,
-		Timestamps:  models.Timestamps{UpdatedAt: time.Now()},
-	})
+	team, err := tx.GetTeam(ctx, teamID)
+	if err != nil {
+		return fmt.Errorf("failed to load team: %w", err)
+	}
+	team.Name = cfgTeam.Name
+	team.Description = cfgTeam.Description
+	team.UpdatedAt = time.Now()
+	return tx.UpdateTeam(ctx, team)
 }
 
 func sourceNeedsUpdate(existing *models.Source, desired config.ProvisionSource) (bool, error) {
@@ -487,6 +669,10 @@ func sourceNeedsUpdate(existing *models.Source, desired config.ProvisionSource)
 		existing.TTLDays != desired.TTLDays ||
 		existing.MetaTSField != desired.MetaTSField ||
 		existing.MetaSeverityField != desired.MetaSeverityField ||
+		!existing.Tags.Equal(desired.Tags) ||
+		!existing.ExtractionRules.Equal(source.ExtractionRules) ||
+		!existing.TraceCorrelation.Equal(source.TraceCorrelation) ||
+		!existing.MaskedColumns.Equal(source.MaskedColumns) ||
 		existing.SecretRef != desired.SecretRef, nil
 }
 
@@ -505,6 +691,10 @@ func buildProvisionedSourceModel(src config.ProvisionSource) (models.Source, err
 		ConnectionConfig:  connectionPayload,
 		Description:       src.Description,
 		TTLDays:           src.TTLDays,
+		Tags:              src.Tags,
+		ExtractionRules:   src.ExtractionRules.Normalize(),
+		TraceCorrelation:  src.TraceCorrelation.Normalize(),
+		MaskedColumns:     src.MaskedColumns.Normalize(),
 		Managed:           true,
 		SecretRef:         src.SecretRef,
 	}
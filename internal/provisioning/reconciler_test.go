@@ -2,6 +2,7 @@ package provisioning
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
 	"path/filepath"
@@ -257,6 +258,82 @@ func TestReconcile_SourcePrune(t *testing.T) {
 	}
 }
 
+// TestReconcile_Alerts covers creating, updating, adopting and pruning
+// managed alerts.
+func TestReconcile_Alerts(t *testing.T) {
+	db := newReconcileTestDB(t)
+	ctx := context.Background()
+	ds := newTestDatasourceService(db)
+
+	src := &models.Source{
+		Name:        "src1",
+		Connection:  models.ConnectionInfo{Host: "localhost:9000", Database: "default", TableName: "logs"},
+		MetaTSField: "timestamp",
+	}
+	if err := db.CreateSource(ctx, src); err != nil {
+		t.Fatalf("seed source: %v", err)
+	}
+	// An unmanaged alert of the same name is adopted.
+	legacy := &models.Alert{
+		SourceID: src.ID, Name: "errors", QueryLanguage: models.QueryLanguageClickHouseSQL,
+		EditorMode: models.AlertEditorModeNative, Query: "SELECT 1", LookbackSeconds: 60,
+		ThresholdOperator: models.AlertThresholdGreaterThan, FrequencySeconds: 60,
+		Severity: models.AlertSeverityInfo, IsActive: true,
+	}
+	if err := db.CreateAlert(ctx, legacy); err != nil {
+		t.Fatalf("seed alert: %v", err)
+	}
+
+	alert := config.ProvisionAlert{
+		Name: "errors", Source: "src1",
+		QueryLanguage: models.QueryLanguageClickHouseSQL, Query: "SELECT count() AS value FROM logs",
+		LookbackSeconds: 300, FrequencySeconds: 60,
+		ThresholdOperator: models.AlertThresholdGreaterThan, ThresholdValue: 10,
+		Severity: models.AlertSeverityCritical, Labels: map[string]string{"team": "platform"},
+	}
+	slow := alert
+	slow.Name = "slow"
+	slow.Disabled = true
+	cfg := &config.ProvisioningConfig{ManageAlerts: true, Alerts: []config.ProvisionAlert{alert, slow}}
+	if err := Reconcile(ctx, cfg, db, ds, quietLogger(), nil); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	managed, err := db.ListManagedAlerts(ctx)
+	if err != nil {
+		t.Fatalf("ListManagedAlerts: %v", err)
+	}
+	if len(managed) != 2 {
+		t.Fatalf("managed alerts = %d, want 2", len(managed))
+	}
+	adopted, err := db.GetAlert(ctx, legacy.ID)
+	if err != nil {
+		t.Fatalf("GetAlert: %v", err)
+	}
+	if !adopted.Managed || adopted.Severity != models.AlertSeverityCritical || adopted.Labels["team"] != "platform" {
+		t.Errorf("adopted alert = %+v", adopted)
+	}
+	for _, a := range managed {
+		if a.Name == "slow" && a.IsActive {
+			t.Error("disabled alert should not be active")
+		}
+	}
+
+	// Changing the threshold updates in place; dropping "slow" prunes it.
+	alert.ThresholdValue = 50
+	cfg = &config.ProvisioningConfig{ManageAlerts: true, Prune: true, Alerts: []config.ProvisionAlert{alert}}
+	if err := Reconcile(ctx, cfg, db, ds, quietLogger(), nil); err != nil {
+		t.Fatalf("Reconcile update: %v", err)
+	}
+	managed, err = db.ListManagedAlerts(ctx)
+	if err != nil {
+		t.Fatalf("ListManagedAlerts: %v", err)
+	}
+	if len(managed) != 1 || managed[0].ID != legacy.ID || managed[0].ThresholdValue != 50 {
+		t.Fatalf("after update managed = %+v", managed)
+	}
+}
+
 // TestReconcile_TeamAdopt covers adopting an existing unmanaged team.
 func TestReconcile_TeamAdopt(t *testing.T) {
 	db := newReconcileTestDB(t)
@@ -290,6 +367,42 @@ func TestReconcile_TeamAdopt(t *testing.T) {
 	}
 }
 
+func TestReconcile_TeamDescriptionKeepsRawSQLSwitch(t *testing.T) {
+	db := newReconcileTestDB(t)
+	ctx := context.Background()
+
+	seed := &models.Team{Name: "locked", Description: "old desc"}
+	if err := db.CreateTeam(ctx, seed); err != nil {
+		t.Fatalf("seed team: %v", err)
+	}
+
+	for _, enabled := range []bool{false, true} {
+		seed.RawSQLEnabled = enabled
+		if err := db.UpdateTeam(ctx, seed); err != nil {
+			t.Fatalf("set raw SQL switch: %v", err)
+		}
+		desc := fmt.Sprintf("desc with raw SQL %v", enabled)
+		cfg := &config.ProvisioningConfig{
+			ManageTeams: true,
+			Teams:       []config.ProvisionTeam{{Name: "locked", Description: desc}},
+		}
+		if err := Reconcile(ctx, cfg, db, newTestDatasourceService(db), quietLogger(), nil); err != nil {
+			t.Fatalf("Reconcile: %v", err)
+		}
+
+		team, err := db.GetTeamByName(ctx, "locked")
+		if err != nil {
+			t.Fatalf("team missing: %v", err)
+		}
+		if team.Description != desc {
+			t.Errorf("team description = %q, want %q", team.Description, desc)
+		}
+		if team.RawSQLEnabled != enabled {
+			t.Errorf("raw SQL switch = %v after a description change, want %v", team.RawSQLEnabled, enabled)
+		}
+	}
+}
+
 // TestReconcile_MemberRoleUpdateAndPrune runs two passes: the second changes a
 // member's role, removes another member, and unlinks a source — exercising the
 // role-update, member-prune, and source-unlink-prune paths.
@@ -2,6 +2,7 @@ package provisioning
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"regexp"
 	"strings"
@@ -26,6 +27,9 @@ func ValidateConfig(cfg *config.ProvisioningConfig) error {
 	if cfg.ManageTeams {
 		errs = append(errs, validateTeams(cfg)...)
 	}
+	if cfg.ManageAlerts {
+		errs = append(errs, validateAlerts(cfg)...)
+	}
 
 	if len(errs) > 0 {
 		return fmt.Errorf("provisioning config validation failed:\n  - %s", strings.Join(errs, "\n  - "))
@@ -91,6 +95,27 @@ func validateOneSource(i int, src config.ProvisionSource, seen map[string]bool)
 		return errs
 	}
 
+	if err := models.SourceTags(src.Tags).Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("%s: %v", prefix, err))
+	}
+	if len(src.ExtractionRules) > 0 {
+		if sourceType != models.SourceTypeClickHouse {
+			errs = append(errs, fmt.Sprintf("%s: extraction_rules are only supported for ClickHouse sources", prefix))
+		} else if err := src.ExtractionRules.Normalize().Validate(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", prefix, err))
+		}
+	}
+	if len(src.MaskedColumns) > 0 {
+		if sourceType != models.SourceTypeClickHouse {
+			errs = append(errs, fmt.Sprintf("%s: masked_columns are only supported for ClickHouse sources", prefix))
+		} else if err := src.MaskedColumns.Normalize().Validate(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", prefix, err))
+		}
+	}
+	if err := src.TraceCorrelation.Normalize().Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("%s: trace_correlation: %v", prefix, err))
+	}
+
 	if src.SecretRef != "" && sourceSecretValueMissing(src, sourceType) {
 		if val := os.Getenv(src.SecretRef); val == "" {
 			errs = append(errs, fmt.Sprintf("%s: secret_ref %q env var is empty or not set", prefix, src.SecretRef))
@@ -131,6 +156,9 @@ func validateClickHouseSource(prefix string, src config.ProvisionSource) (errs [
 	if err := conn.Settings.Validate(); err != nil {
 		errs = append(errs, fmt.Sprintf("%s: connection.settings: %v", prefix, err))
 	}
+	if err := conn.Pool.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("%s: connection.pool: %v", prefix, err))
+	}
 	return errs, true
 }
 
@@ -214,14 +242,124 @@ func validateTeams(cfg *config.ProvisioningConfig) []string {
 			if role == "" {
 				role = "member"
 			}
-			if role != "admin" && role != "editor" && role != "member" {
-				errs = append(errs, fmt.Sprintf("%s: invalid role %q (must be admin, editor, or member)", memberPrefix, member.Role))
+			if !models.TeamRole(role).Valid() {
+				errs = append(errs, fmt.Sprintf("%s: invalid role %q (must be admin, editor, member, or viewer)", memberPrefix, member.Role))
 			}
 		}
 	}
 	return errs
 }
 
+// validAlertOperators and validAlertSeverities mirror what the alerts API
+// accepts.
+var validAlertOperators = map[models.AlertThresholdOperator]bool{
+	models.AlertThresholdGreaterThan:        true,
+	models.AlertThresholdGreaterThanOrEqual: true,
+	models.AlertThresholdLessThan:           true,
+	models.AlertThresholdLessThanOrEqual:    true,
+	models.AlertThresholdEqual:              true,
+	models.AlertThresholdNotEqual:           true,
+}
+
+var validAlertSeverities = map[models.AlertSeverity]bool{
+	models.AlertSeverityInfo:     true,
+	models.AlertSeverityWarning:  true,
+	models.AlertSeverityCritical: true,
+}
+
+func validateAlerts(cfg *config.ProvisioningConfig) []string {
+	var errs []string
+	seen := make(map[string]bool)
+
+	sourceNames := make(map[string]bool)
+	for i := range cfg.Sources {
+		sourceNames[cfg.Sources[i].Name] = true
+	}
+
+	for i := range cfg.Alerts {
+		alert := cfg.Alerts[i]
+		prefix := fmt.Sprintf("alerts[%d] (%q)", i, alert.Name)
+
+		if strings.TrimSpace(alert.Name) == "" {
+			errs = append(errs, fmt.Sprintf("alerts[%d]: name is required", i))
+			continue
+		}
+		if alert.Source == "" {
+			errs = append(errs, fmt.Sprintf("%s: source is required", prefix))
+			continue
+		}
+		// Sources not declared here must already exist; that is checked
+		// during reconciliation.
+		if cfg.ManageSources && !sourceNames[alert.Source] {
+			errs = append(errs, fmt.Sprintf("%s: references unknown source %q", prefix, alert.Source))
+		}
+		key := alert.Source + "\x00" + alert.Name
+		if seen[key] {
+			errs = append(errs, fmt.Sprintf("%s: duplicate alert name on source %q", prefix, alert.Source))
+		}
+		seen[key] = true
+
+		errs = append(errs, validateAlertRule(prefix, alert)...)
+	}
+	return errs
+}
+
+// validateAlertRule checks the fields of one alert that don't depend on its
+// source's connection.
+func validateAlertRule(prefix string, alert config.ProvisionAlert) []string {
+	var errs []string
+
+	_, editorMode, err := models.ResolveAlertMetadata(alert.QueryLanguage, alert.EditorMode)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("%s: %v", prefix, err))
+	}
+	switch editorMode {
+	case models.AlertEditorModeNative:
+		if strings.TrimSpace(alert.Query) == "" {
+			errs = append(errs, fmt.Sprintf("%s: query is required for native alerts", prefix))
+		}
+	case models.AlertEditorModeCondition:
+		if strings.TrimSpace(alert.ConditionJSON) == "" {
+			errs = append(errs, fmt.Sprintf("%s: condition_json is required for condition alerts", prefix))
+			break
+		}
+		_, structured, err := models.ParseAlertCondition(strings.TrimSpace(alert.ConditionJSON))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: condition_json: %v", prefix, err))
+		} else if !structured && strings.TrimSpace(alert.Query) == "" {
+			errs = append(errs, fmt.Sprintf("%s: query is required for condition alerts", prefix))
+		}
+	}
+
+	if !validAlertOperators[alert.ThresholdOperator] {
+		errs = append(errs, fmt.Sprintf("%s: invalid threshold_operator %q (must be gt, gte, lt, lte, eq, or neq)", prefix, alert.ThresholdOperator))
+	}
+	if alert.FrequencySeconds <= 0 {
+		errs = append(errs, fmt.Sprintf("%s: frequency_seconds must be greater than zero", prefix))
+	}
+	if alert.ForSeconds < 0 {
+		errs = append(errs, fmt.Sprintf("%s: for_seconds must not be negative", prefix))
+	}
+	if alert.LookbackSeconds <= 0 {
+		errs = append(errs, fmt.Sprintf("%s: lookback_seconds must be greater than zero", prefix))
+	}
+	if !validAlertSeverities[alert.Severity] {
+		errs = append(errs, fmt.Sprintf("%s: invalid severity %q (must be info, warning, or critical)", prefix, alert.Severity))
+	}
+	for _, raw := range alert.WebhookURLs {
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			errs = append(errs, fmt.Sprintf("%s: invalid webhook URL %q (must be http or https)", prefix, raw))
+		}
+	}
+	for j, email := range alert.Recipients {
+		if strings.TrimSpace(email) == "" {
+			errs = append(errs, fmt.Sprintf("%s.recipients[%d]: email is required", prefix, j))
+		}
+	}
+	return errs
+}
+
 // ResolveSecrets resolves password values from environment variables.
 // Must be called after ValidateConfig.
 func ResolveSecrets(cfg *config.ProvisioningConfig) {
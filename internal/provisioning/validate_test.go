@@ -379,6 +379,44 @@ func TestValidateConfig_SourcesNotManagedTeamRefsIgnored(t *testing.T) {
 	}
 }
 
+func TestValidateConfig_Alerts(t *testing.T) {
+	valid := config.ProvisionAlert{
+		Name: "errors", Source: "src1",
+		QueryLanguage: "clickhouse-sql", Query: "SELECT count() AS value FROM logs",
+		LookbackSeconds: 300, FrequencySeconds: 60,
+		ThresholdOperator: "gt", ThresholdValue: 10, Severity: "critical",
+	}
+	sources := []config.ProvisionSource{
+		{Name: "src1", Connection: map[string]any{"host": "host:9000", "database": "db", "table_name": "tbl"}},
+	}
+	cfg := &config.ProvisioningConfig{ManageSources: true, ManageAlerts: true, Sources: sources, Alerts: []config.ProvisionAlert{valid}}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Fatalf("valid alert config should pass: %v", err)
+	}
+
+	for name, mutate := range map[string]func(a *config.ProvisionAlert){
+		"unknown source":  func(a *config.ProvisionAlert) { a.Source = "nope" },
+		"missing query":   func(a *config.ProvisionAlert) { a.Query = "" },
+		"bad operator":    func(a *config.ProvisionAlert) { a.ThresholdOperator = ">" },
+		"bad severity":    func(a *config.ProvisionAlert) { a.Severity = "page" },
+		"zero frequency":  func(a *config.ProvisionAlert) { a.FrequencySeconds = 0 },
+		"bad webhook":     func(a *config.ProvisionAlert) { a.WebhookURLs = []string{"ftp://hooks"} },
+		"empty condition": func(a *config.ProvisionAlert) { a.EditorMode = "condition"; a.Query = "" },
+	} {
+		alert := valid
+		mutate(&alert)
+		cfg := &config.ProvisioningConfig{ManageSources: true, ManageAlerts: true, Sources: sources, Alerts: []config.ProvisionAlert{alert}}
+		if err := ValidateConfig(cfg); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+
+	dup := &config.ProvisioningConfig{ManageSources: true, ManageAlerts: true, Sources: sources, Alerts: []config.ProvisionAlert{valid, valid}}
+	if err := ValidateConfig(dup); err == nil || !strings.Contains(err.Error(), "duplicate alert name") {
+		t.Errorf("duplicate alerts should fail, got %v", err)
+	}
+}
+
 func TestResolveSecrets_DefaultMetaTSField(t *testing.T) {
 	cfg := &config.ProvisioningConfig{
 		ManageSources: true,
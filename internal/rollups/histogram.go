@@ -0,0 +1,81 @@
+package rollups
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mr-karan/logchef/internal/clickhouse"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// Histogram answers a histogram from the source's rollup when the rollup can
+// give the same counts: the query filters on nothing but its time range (see
+// clickhouse.TimeRangeOnlyQuery), the window is a trend window of an hour or
+// more, the histogram is ungrouped or grouped by the severity field, and the
+// range is long enough and covered by the rollup as for Trend. ok is false
+// when any of that doesn't hold, and the caller should query the raw logs.
+func (m *Manager) Histogram(ctx context.Context, sourceID models.SourceID, req datasource.HistogramRequest) (result *datasource.HistogramResult, ok bool, err error) {
+	if !m.cfg.Enabled {
+		return nil, false, nil
+	}
+	if _, err := models.TrendWindowHours(req.Window); err != nil {
+		return nil, false, nil
+	}
+	source, err := m.db.GetSource(ctx, sourceID)
+	if err != nil {
+		return nil, false, err
+	}
+	if models.NormalizeSourceType(source.SourceType) != models.SourceTypeClickHouse {
+		return nil, false, nil
+	}
+
+	groupBy := models.TrendGroupByNone
+	switch req.GroupBy {
+	case "":
+	case source.MetaSeverityField:
+		groupBy = models.TrendGroupBySeverity
+	default:
+		return nil, false, nil
+	}
+
+	start, end, ok := clickhouse.TimeRangeOnlyQuery(req.Query, source.GetFullTableName(), source.MetaTSField)
+	if !ok {
+		return nil, false, nil
+	}
+	rollup, err := m.db.GetSourceRollup(ctx, sourceID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if _, _, ok := m.rollupSpan(rollup, start, end); !ok {
+		return nil, false, nil
+	}
+
+	trend, err := m.Trend(ctx, sourceID, TrendRequest{
+		Start:        start,
+		End:          end,
+		Window:       req.Window,
+		GroupBy:      groupBy,
+		Timezone:     req.Timezone,
+		QueryTimeout: req.QueryTimeout,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	data := make([]datasource.HistogramBucket, 0, len(trend.Data))
+	for _, bucket := range trend.Data {
+		data = append(data, datasource.HistogramBucket{
+			Bucket:     bucket.Bucket,
+			LogCount:   int(bucket.LogCount),
+			GroupValue: bucket.GroupValue,
+		})
+	}
+	return &datasource.HistogramResult{
+		Granularity: req.Window,
+		Data:        data,
+		FromRollup:  trend.FromRollup,
+	}, true, nil
+}
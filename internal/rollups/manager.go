@@ -0,0 +1,306 @@
+// Package rollups maintains per-source hourly rollups of log counts and serves
+// long-range trends from them.
+//
+// A source opts in through SourceRollup configuration in the metadata store.
+// The Manager's scheduler then rolls completed hours up into a small
+// ClickHouse table (counts by severity and one dimension column) with an
+// INSERT ... SELECT per run, and Trend answers ranges longer than
+// RollupsConfig.MinRangeDays from that table, reading only the uncovered edges
+// from the raw logs.
+package rollups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/clickhouse"
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+var (
+	// ErrRollupNotFound is returned when a source has no rollup configured.
+	ErrRollupNotFound = errors.New("rollup not configured for this source")
+	// ErrInvalidRequest wraps rollup configuration and trend request errors.
+	ErrInvalidRequest = errors.New("invalid rollup request")
+)
+
+// runTimeout bounds one source's rollup run, so a wedged source can't stall
+// the sequential scheduler loop.
+const runTimeout = 6 * time.Minute
+
+// backend is the ClickHouse surface the manager needs. *clickhouse.Client
+// implements it; tests substitute a fake.
+type backend interface {
+	GetTableInfo(ctx context.Context, database, table string) (*clickhouse.TableInfo, error)
+	EnsureRollupTable(ctx context.Context, table string) error
+	DropRollupTable(ctx context.Context, table string) error
+	InsertRollup(ctx context.Context, spec clickhouse.RollupSpec, start, end time.Time) error
+	RollupTrend(ctx context.Context, table string, params clickhouse.TrendQueryParams) ([]clickhouse.TrendRow, error)
+	RawTrend(ctx context.Context, spec clickhouse.RollupSpec, params clickhouse.TrendQueryParams) ([]clickhouse.TrendRow, error)
+}
+
+// Options encapsulates the dependencies of the rollup manager.
+type Options struct {
+	Config     config.RollupsConfig
+	DB         store.Store
+	ClickHouse *clickhouse.Manager
+	Logger     *slog.Logger
+}
+
+// Manager runs the rollup scheduler and serves trend queries.
+type Manager struct {
+	cfg config.RollupsConfig
+	db  store.Store
+	log *slog.Logger
+
+	// clientFor, requireDDL and now are seams for tests.
+	clientFor  func(models.SourceID) (backend, error)
+	requireDDL func(models.SourceID) error
+	now        func() time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewManager constructs a rollup manager.
+func NewManager(opts Options) *Manager {
+	return &Manager{
+		cfg: opts.Config,
+		db:  opts.DB,
+		log: opts.Logger.With("component", "rollup_manager"),
+		clientFor: func(id models.SourceID) (backend, error) {
+			return opts.ClickHouse.GetConnection(id)
+		},
+		requireDDL: func(id models.SourceID) error {
+			return opts.ClickHouse.RequireDDL(id)
+		},
+		now:  time.Now,
+		stop: make(chan struct{}),
+	}
+}
+
+// Start launches the scheduler loop. It is a no-op when rollups are disabled.
+func (m *Manager) Start(ctx context.Context) {
+	if !m.cfg.Enabled {
+		m.log.Debug("rollups disabled")
+		return
+	}
+	m.log.Debug("starting rollup manager", "interval", m.cfg.Interval)
+
+	m.wg.Go(func() {
+		ticker := time.NewTicker(m.cfg.Interval)
+		defer ticker.Stop()
+
+		m.runCycle(ctx)
+		for {
+			select {
+			case <-ticker.C:
+				m.runCycle(ctx)
+			case <-m.stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// Stop signals the scheduler to stop and waits for the current run to end.
+func (m *Manager) Stop() {
+	close(m.stop)
+	m.wg.Wait()
+}
+
+func (m *Manager) runCycle(ctx context.Context) {
+	rollups, err := m.db.ListSourceRollups(ctx)
+	if err != nil {
+		m.log.Error("failed to list source rollups", "error", err)
+		return
+	}
+	for _, rollup := range rollups {
+		select {
+		case <-m.stop:
+			return
+		default:
+		}
+		runCtx, cancel := context.WithTimeout(ctx, runTimeout)
+		m.rollUp(runCtx, rollup)
+		cancel()
+	}
+}
+
+// rollUp materialises the next batch of settled hours for one source and
+// records the outcome. Hours become eligible once they ended SettleDelay ago;
+// a run covers at most MaxHoursPerRun of them, so a fresh rollup backfills
+// BackfillDays gradually over several runs.
+func (m *Manager) rollUp(ctx context.Context, rollup *models.SourceRollup) {
+	now := m.now().UTC()
+	target := now.Add(-m.cfg.SettleDelay).Truncate(time.Hour)
+	start := target.Add(-time.Duration(m.cfg.BackfillDays) * 24 * time.Hour)
+	if rollup.RolledUpUntil != nil {
+		start = rollup.RolledUpUntil.UTC()
+	}
+	if !start.Before(target) {
+		return
+	}
+	end := start.Add(time.Duration(m.cfg.MaxHoursPerRun) * time.Hour)
+	if end.After(target) {
+		end = target
+	}
+
+	progress := *rollup
+	progress.LastRunAt = &now
+	if err := m.insertRollup(ctx, rollup, start, end); err != nil {
+		m.log.Warn("rollup run failed", "source_id", rollup.SourceID, "error", err)
+		progress.LastError = err.Error()
+	} else {
+		m.log.Debug("rolled up source", "source_id", rollup.SourceID, "start", start, "end", end)
+		progress.LastError = ""
+		if progress.RolledUpFrom == nil {
+			progress.RolledUpFrom = &start
+		}
+		progress.RolledUpUntil = &end
+	}
+
+	if err := m.db.UpdateSourceRollupProgress(ctx, &progress); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			m.log.Debug("rollup reconfigured during run, discarding progress", "source_id", rollup.SourceID)
+			return
+		}
+		m.log.Error("failed to record rollup progress", "source_id", rollup.SourceID, "error", err)
+	}
+}
+
+func (m *Manager) insertRollup(ctx context.Context, rollup *models.SourceRollup, start, end time.Time) error {
+	source, client, err := m.clickHouseSource(ctx, rollup.SourceID)
+	if err != nil {
+		return err
+	}
+	if err := m.requireDDL(rollup.SourceID); err != nil {
+		return err
+	}
+	spec := rollupSpec(source, rollup)
+	if err := client.EnsureRollupTable(ctx, spec.RollupTable); err != nil {
+		return err
+	}
+	return client.InsertRollup(ctx, spec, start, end)
+}
+
+// GetRollup returns a source's rollup state.
+func (m *Manager) GetRollup(ctx context.Context, sourceID models.SourceID) (*models.SourceRollup, error) {
+	rollup, err := m.db.GetSourceRollup(ctx, sourceID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, ErrRollupNotFound
+	}
+	return rollup, err
+}
+
+// Configure enables a source's rollup or changes its dimension. A changed
+// dimension drops the rolled-up data so the next runs backfill it afresh.
+// Rollups write their own table, so read-only sources are refused up front
+// with clickhouse.ErrReadOnly.
+func (m *Manager) Configure(ctx context.Context, sourceID models.SourceID, dimension string) (*models.SourceRollup, error) {
+	source, client, err := m.clickHouseSource(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.requireDDL(sourceID); err != nil {
+		return nil, err
+	}
+
+	dimension = strings.TrimSpace(dimension)
+	if dimension != "" {
+		if err := clickhouse.ValidateIdentifier(dimension); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+		info, err := client.GetTableInfo(ctx, source.Connection.Database, source.Connection.TableName)
+		if err != nil {
+			return nil, fmt.Errorf("inspecting source table: %w", err)
+		}
+		if !hasColumn(info.Columns, dimension) {
+			return nil, fmt.Errorf("%w: column %q does not exist in %s", ErrInvalidRequest, dimension, source.GetFullTableName())
+		}
+	}
+
+	existing, err := m.db.GetSourceRollup(ctx, sourceID)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return nil, err
+	}
+	if existing != nil && existing.Dimension != dimension {
+		// Old rows are keyed by values of the old dimension and would be
+		// summed into new trends, so they must go before progress resets.
+		if err := client.DropRollupTable(ctx, clickhouse.RollupTableName(source.Connection.Database, sourceID)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.db.UpsertSourceRollup(ctx, sourceID, dimension); err != nil {
+		return nil, err
+	}
+	return m.db.GetSourceRollup(ctx, sourceID)
+}
+
+// Disable removes a source's rollup configuration and drops its rollup table.
+func (m *Manager) Disable(ctx context.Context, sourceID models.SourceID) error {
+	if _, err := m.GetRollup(ctx, sourceID); err != nil {
+		return err
+	}
+	source, client, err := m.clickHouseSource(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	if err := m.requireDDL(sourceID); err != nil {
+		return err
+	}
+	if err := client.DropRollupTable(ctx, clickhouse.RollupTableName(source.Connection.Database, sourceID)); err != nil {
+		return err
+	}
+	return m.db.DeleteSourceRollup(ctx, sourceID)
+}
+
+// clickHouseSource loads a source and its connection. Rollups are
+// ClickHouse-only; other source types report datasource.ErrOperationNotSupported.
+func (m *Manager) clickHouseSource(ctx context.Context, sourceID models.SourceID) (*models.Source, backend, error) {
+	source, err := m.db.GetSource(ctx, sourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if models.NormalizeSourceType(source.SourceType) != models.SourceTypeClickHouse {
+		return nil, nil, datasource.ErrOperationNotSupported
+	}
+	client, err := m.clientFor(sourceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting database connection for source %d: %w", sourceID, err)
+	}
+	return source, client, nil
+}
+
+func rollupSpec(source *models.Source, rollup *models.SourceRollup) clickhouse.RollupSpec {
+	spec := clickhouse.RollupSpec{
+		SourceTable:    source.GetFullTableName(),
+		RollupTable:    clickhouse.RollupTableName(source.Connection.Database, source.ID),
+		TimestampField: source.MetaTSField,
+		SeverityField:  source.MetaSeverityField,
+	}
+	if rollup != nil {
+		spec.DimensionField = rollup.Dimension
+	}
+	return spec
+}
+
+func hasColumn(columns []models.ColumnInfo, name string) bool {
+	for _, col := range columns {
+		if col.Name == name {
+			return true
+		}
+	}
+	return false
+}
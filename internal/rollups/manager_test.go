@@ -0,0 +1,320 @@
+package rollups
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/clickhouse"
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/store/sqlite"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+type span struct{ start, end time.Time }
+
+// fakeBackend records rollup runs and answers trend queries with one row per
+// queried segment, counting the segment's hours so merges are checkable.
+type fakeBackend struct {
+	columns   []string
+	insertErr error
+	inserts   []span
+	dropped   []string
+	rollupQ   []clickhouse.TrendQueryParams
+	rawQ      []clickhouse.TrendQueryParams
+}
+
+func (f *fakeBackend) GetTableInfo(_ context.Context, _, _ string) (*clickhouse.TableInfo, error) {
+	info := &clickhouse.TableInfo{}
+	for _, c := range f.columns {
+		info.Columns = append(info.Columns, models.ColumnInfo{Name: c, Type: "String"})
+	}
+	return info, nil
+}
+
+func (f *fakeBackend) EnsureRollupTable(context.Context, string) error { return nil }
+
+func (f *fakeBackend) DropRollupTable(_ context.Context, table string) error {
+	f.dropped = append(f.dropped, table)
+	return nil
+}
+
+func (f *fakeBackend) InsertRollup(_ context.Context, _ clickhouse.RollupSpec, start, end time.Time) error {
+	if f.insertErr != nil {
+		return f.insertErr
+	}
+	f.inserts = append(f.inserts, span{start, end})
+	return nil
+}
+
+func (f *fakeBackend) RollupTrend(_ context.Context, _ string, p clickhouse.TrendQueryParams) ([]clickhouse.TrendRow, error) {
+	f.rollupQ = append(f.rollupQ, p)
+	return []clickhouse.TrendRow{{Bucket: p.Start.Truncate(24 * time.Hour), LogCount: int64(p.End.Sub(p.Start).Hours())}}, nil
+}
+
+func (f *fakeBackend) RawTrend(_ context.Context, _ clickhouse.RollupSpec, p clickhouse.TrendQueryParams) ([]clickhouse.TrendRow, error) {
+	f.rawQ = append(f.rawQ, p)
+	return []clickhouse.TrendRow{{Bucket: p.Start.Truncate(24 * time.Hour), LogCount: 1}}, nil
+}
+
+func newTestManager(t *testing.T, now time.Time) (*Manager, *sqlite.DB, *fakeBackend, *models.Source) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := sqlite.New(context.Background(), sqlite.Options{
+		Logger: logger,
+		Config: config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")},
+	})
+	if err != nil {
+		t.Fatalf("sqlite.New failed: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	source := &models.Source{
+		Name:              "app",
+		MetaTSField:       "timestamp",
+		MetaSeverityField: "severity_text",
+		Connection:        models.ConnectionInfo{Host: "ch:9000", Username: "default", Database: "logs", TableName: "app"},
+	}
+	if err := db.CreateSource(context.Background(), source); err != nil {
+		t.Fatalf("CreateSource: %v", err)
+	}
+
+	fake := &fakeBackend{columns: []string{"timestamp", "severity_text", "service"}}
+	m := NewManager(Options{
+		Config: config.RollupsConfig{
+			Enabled:        true,
+			Interval:       time.Minute,
+			SettleDelay:    15 * time.Minute,
+			MinRangeDays:   7,
+			BackfillDays:   2,
+			MaxHoursPerRun: 24,
+		},
+		DB:     db,
+		Logger: logger,
+	})
+	m.clientFor = func(models.SourceID) (backend, error) { return fake, nil }
+	m.requireDDL = func(models.SourceID) error { return nil }
+	m.now = func() time.Time { return now }
+	return m, db, fake, source
+}
+
+func TestRollUpBackfillsInBatches(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 20, 0, 0, time.UTC)
+	m, db, fake, source := newTestManager(t, now)
+	ctx := context.Background()
+
+	if _, err := m.Configure(ctx, source.ID, "service"); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+
+	// 12:20 minus the settle delay is 12:05, so hours up to 12:00 are eligible
+	// and the two-day backfill starts at 2026-03-08 12:00.
+	m.runCycle(ctx)
+	m.runCycle(ctx)
+	m.runCycle(ctx)
+
+	target := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	want := []span{
+		{target.Add(-48 * time.Hour), target.Add(-24 * time.Hour)},
+		{target.Add(-24 * time.Hour), target},
+	}
+	if len(fake.inserts) != len(want) {
+		t.Fatalf("inserts = %v, want %v", fake.inserts, want)
+	}
+	for i := range want {
+		if !fake.inserts[i].start.Equal(want[i].start) || !fake.inserts[i].end.Equal(want[i].end) {
+			t.Errorf("insert %d = %v, want %v", i, fake.inserts[i], want[i])
+		}
+	}
+
+	rollup, err := db.GetSourceRollup(ctx, source.ID)
+	if err != nil {
+		t.Fatalf("GetSourceRollup: %v", err)
+	}
+	if !rollup.Covers(want[0].start, target) || rollup.LastError != "" {
+		t.Fatalf("unexpected rollup state: %+v", rollup)
+	}
+
+	// A failed run records the error without moving progress.
+	fake.insertErr = errors.New("boom")
+	m.now = func() time.Time { return now.Add(time.Hour) }
+	m.runCycle(ctx)
+	rollup, _ = db.GetSourceRollup(ctx, source.ID)
+	if rollup.LastError != "boom" || !rollup.RolledUpUntil.Equal(target) {
+		t.Fatalf("unexpected rollup state after failure: %+v", rollup)
+	}
+}
+
+func TestConfigureRollup(t *testing.T) {
+	m, db, fake, source := newTestManager(t, time.Now())
+	ctx := context.Background()
+
+	if _, err := m.Configure(ctx, source.ID, "missing_column"); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("Configure(missing column) err = %v, want ErrInvalidRequest", err)
+	}
+	if _, err := m.Configure(ctx, source.ID, "service"); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	until := time.Now().UTC().Truncate(time.Hour)
+	if err := db.UpdateSourceRollupProgress(ctx, &models.SourceRollup{SourceID: source.ID, Dimension: "service", RolledUpFrom: &until, RolledUpUntil: &until}); err != nil {
+		t.Fatalf("UpdateSourceRollupProgress: %v", err)
+	}
+
+	// Same dimension keeps the rolled-up data; a new one drops and resets it.
+	if _, err := m.Configure(ctx, source.ID, "service"); err != nil || len(fake.dropped) != 0 {
+		t.Fatalf("reconfigure same dimension: err=%v dropped=%v", err, fake.dropped)
+	}
+	rollup, err := m.Configure(ctx, source.ID, "")
+	if err != nil {
+		t.Fatalf("Configure(no dimension): %v", err)
+	}
+	if len(fake.dropped) != 1 || rollup.RolledUpUntil != nil || rollup.Dimension != "" {
+		t.Fatalf("dimension change did not reset: dropped=%v rollup=%+v", fake.dropped, rollup)
+	}
+
+	if err := m.Disable(ctx, source.ID); err != nil {
+		t.Fatalf("Disable: %v", err)
+	}
+	if _, err := m.GetRollup(ctx, source.ID); !errors.Is(err, ErrRollupNotFound) {
+		t.Fatalf("GetRollup after Disable err = %v, want ErrRollupNotFound", err)
+	}
+}
+
+func TestRollupRefusesReadOnlySource(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 20, 0, 0, time.UTC)
+	m, db, fake, source := newTestManager(t, now)
+	ctx := context.Background()
+
+	if _, err := m.Configure(ctx, source.ID, ""); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	readOnly := clickhouse.RequireDDL(&models.SourceHealthCapabilities{CanQuery: true, ReadOnlyReason: "readonly=1"})
+	m.requireDDL = func(models.SourceID) error { return readOnly }
+
+	if _, err := m.Configure(ctx, source.ID, "service"); !errors.Is(err, clickhouse.ErrReadOnly) {
+		t.Fatalf("Configure on read-only source err = %v, want ErrReadOnly", err)
+	}
+	m.runCycle(ctx)
+	if len(fake.inserts) != 0 {
+		t.Fatalf("rolled up a read-only source: %v", fake.inserts)
+	}
+	rollup, _ := db.GetSourceRollup(ctx, source.ID)
+	if rollup.LastError != readOnly.Error() {
+		t.Fatalf("LastError = %q, want %q", rollup.LastError, readOnly.Error())
+	}
+	if err := m.Disable(ctx, source.ID); !errors.Is(err, clickhouse.ErrReadOnly) || len(fake.dropped) != 0 {
+		t.Fatalf("Disable on read-only source: err=%v dropped=%v", err, fake.dropped)
+	}
+}
+
+func TestTrendStitchesRollupAndRaw(t *testing.T) {
+	m, db, fake, source := newTestManager(t, time.Now())
+	ctx := context.Background()
+
+	if _, err := m.Configure(ctx, source.ID, "service"); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	if err := db.UpdateSourceRollupProgress(ctx, &models.SourceRollup{SourceID: source.ID, Dimension: "service", RolledUpFrom: &from, RolledUpUntil: &until}); err != nil {
+		t.Fatalf("UpdateSourceRollupProgress: %v", err)
+	}
+
+	start := time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)
+	end := time.Date(2026, 3, 21, 6, 0, 0, 0, time.UTC)
+	res, err := m.Trend(ctx, source.ID, TrendRequest{Start: start, End: end, GroupBy: models.TrendGroupByDimension})
+	if err != nil {
+		t.Fatalf("Trend: %v", err)
+	}
+	if !res.FromRollup || res.Granularity != "24h" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if len(fake.rollupQ) != 1 || !fake.rollupQ[0].Start.Equal(start.Add(30*time.Minute)) || !fake.rollupQ[0].End.Equal(until) {
+		t.Fatalf("rollup queries = %+v", fake.rollupQ)
+	}
+	if len(fake.rawQ) != 2 || !fake.rawQ[0].End.Equal(start.Add(30*time.Minute)) || !fake.rawQ[1].Start.Equal(until) || !fake.rawQ[1].End.Equal(end) {
+		t.Fatalf("raw queries = %+v", fake.rawQ)
+	}
+	// The head edge and the rollup segment land in the same day bucket.
+	if len(res.Data) != 2 || res.Data[0].LogCount != int64(until.Sub(start.Add(30*time.Minute)).Hours())+1 {
+		t.Fatalf("unexpected merged data: %+v", res.Data)
+	}
+
+	// Short ranges and ranges starting before the rollup read raw logs.
+	fake.rollupQ, fake.rawQ = nil, nil
+	for _, r := range []TrendRequest{
+		{Start: start, End: start.Add(48 * time.Hour)},
+		{Start: from.Add(-24 * time.Hour), End: end},
+	} {
+		res, err := m.Trend(ctx, source.ID, r)
+		if err != nil {
+			t.Fatalf("Trend: %v", err)
+		}
+		if res.FromRollup {
+			t.Errorf("range %v-%v unexpectedly used the rollup", r.Start, r.End)
+		}
+	}
+	if len(fake.rollupQ) != 0 || len(fake.rawQ) != 2 {
+		t.Fatalf("rollup=%d raw=%d queries, want 0 and 2", len(fake.rollupQ), len(fake.rawQ))
+	}
+
+	for _, r := range []TrendRequest{
+		{Start: end, End: start},
+		{Start: start, End: end, Window: "5m"},
+		{Start: start, End: end, GroupBy: "host"},
+		{Start: start, End: end, Timezone: "UTC'); DROP"},
+	} {
+		if _, err := m.Trend(ctx, source.ID, r); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("Trend(%+v) err = %v, want ErrInvalidRequest", r, err)
+		}
+	}
+}
+
+func TestHistogramUsesRollupForUnfilteredQueries(t *testing.T) {
+	m, db, fake, source := newTestManager(t, time.Now())
+	ctx := context.Background()
+
+	if _, err := m.Configure(ctx, source.ID, ""); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	if err := db.UpdateSourceRollupProgress(ctx, &models.SourceRollup{SourceID: source.ID, RolledUpFrom: &from, RolledUpUntil: &until}); err != nil {
+		t.Fatalf("UpdateSourceRollupProgress: %v", err)
+	}
+
+	const timeRange = "`timestamp` BETWEEN toDateTime('2026-03-02 00:00:00', 'UTC') AND toDateTime('2026-03-19 00:00:00', 'UTC')"
+	unfiltered := "SELECT * FROM logs.app WHERE " + timeRange + " ORDER BY `timestamp` DESC LIMIT 100"
+	res, ok, err := m.Histogram(ctx, source.ID, datasource.HistogramRequest{Query: unfiltered, Window: "24h", GroupBy: "severity_text"})
+	if err != nil || !ok {
+		t.Fatalf("Histogram = %v, %v; want an answer from the rollup", ok, err)
+	}
+	if !res.FromRollup || res.Granularity != "24h" || len(res.Data) != 1 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if len(fake.rollupQ) != 1 || fake.rollupQ[0].GroupBy != string(models.TrendGroupBySeverity) ||
+		!fake.rollupQ[0].Start.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("rollup queries = %+v", fake.rollupQ)
+	}
+
+	fake.rollupQ, fake.rawQ = nil, nil
+	for name, req := range map[string]datasource.HistogramRequest{
+		"filtered":          {Query: "SELECT * FROM logs.app WHERE " + timeRange + " AND (`service` = 'api')", Window: "24h"},
+		"sub-hour window":   {Query: unfiltered, Window: "5m"},
+		"other group-by":    {Query: unfiltered, Window: "24h", GroupBy: "service"},
+		"short range":       {Query: "SELECT * FROM logs.app WHERE `timestamp` BETWEEN toDateTime('2026-03-02 00:00:00', 'UTC') AND toDateTime('2026-03-04 00:00:00', 'UTC')", Window: "1h"},
+		"before the rollup": {Query: "SELECT * FROM logs.app WHERE `timestamp` BETWEEN toDateTime('2026-02-01 00:00:00', 'UTC') AND toDateTime('2026-03-19 00:00:00', 'UTC')", Window: "24h"},
+	} {
+		if _, ok, err := m.Histogram(ctx, source.ID, req); ok || err != nil {
+			t.Errorf("%s: Histogram = %v, %v; want a fallback to raw logs", name, ok, err)
+		}
+	}
+	if len(fake.rollupQ) != 0 || len(fake.rawQ) != 0 {
+		t.Errorf("ineligible requests queried ClickHouse: rollup=%d raw=%d", len(fake.rollupQ), len(fake.rawQ))
+	}
+}
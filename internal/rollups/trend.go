@@ -0,0 +1,171 @@
+package rollups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/clickhouse"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// TrendRequest asks for log volume over [Start, End) in Window-sized buckets.
+type TrendRequest struct {
+	Start time.Time
+	End   time.Time
+	// Window is a models.TrendWindowHours value; empty picks one from the span.
+	Window  string
+	GroupBy models.TrendGroupBy
+	// Timezone aligns buckets of 24h windows to local days; defaults to UTC.
+	Timezone     string
+	QueryTimeout *int
+}
+
+// Trend computes a log-volume trend for a source. Ranges longer than
+// MinRangeDays are answered from the source's rollup where it has been
+// materialised; the partial hours at either edge, and anything the rollup
+// hasn't caught up with yet, are read from the raw table and merged in.
+func (m *Manager) Trend(ctx context.Context, sourceID models.SourceID, req TrendRequest) (*models.TrendResult, error) {
+	if req.Start.IsZero() || req.End.IsZero() || !req.End.After(req.Start) {
+		return nil, fmt.Errorf("%w: end_time must be after start_time", ErrInvalidRequest)
+	}
+	if req.Window == "" {
+		req.Window = models.DefaultTrendWindow(req.End.Sub(req.Start))
+	}
+	windowHours, err := models.TrendWindowHours(req.Window)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if err := clickhouse.ValidateTimezone(req.Timezone); err != nil {
+		return nil, fmt.Errorf("%w: invalid timezone: %v", ErrInvalidRequest, err)
+	}
+
+	source, client, err := m.clickHouseSource(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	rollup, err := m.db.GetSourceRollup(ctx, sourceID)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return nil, err
+	}
+
+	switch req.GroupBy {
+	case models.TrendGroupByNone:
+	case models.TrendGroupBySeverity:
+		if source.MetaSeverityField == "" {
+			return nil, fmt.Errorf("%w: source has no severity field", ErrInvalidRequest)
+		}
+	case models.TrendGroupByDimension:
+		if rollup == nil || rollup.Dimension == "" {
+			return nil, fmt.Errorf("%w: source rollup has no dimension configured", ErrInvalidRequest)
+		}
+	default:
+		return nil, fmt.Errorf("%w: invalid group_by %q", ErrInvalidRequest, req.GroupBy)
+	}
+
+	spec := rollupSpec(source, rollup)
+	params := clickhouse.TrendQueryParams{
+		WindowHours:    windowHours,
+		Timezone:       req.Timezone,
+		GroupBy:        string(req.GroupBy),
+		TimeoutSeconds: req.QueryTimeout,
+	}
+	raw := func(start, end time.Time) ([]clickhouse.TrendRow, error) {
+		p := params
+		p.Start, p.End = start, end
+		return client.RawTrend(ctx, spec, p)
+	}
+
+	start, end := req.Start.UTC(), req.End.UTC()
+	result := &models.TrendResult{Granularity: req.Window, GroupBy: req.GroupBy}
+
+	head, rollEnd, ok := m.rollupSpan(rollup, start, end)
+	if !ok {
+		rows, err := raw(start, end)
+		if err != nil {
+			return nil, err
+		}
+		result.Data = mergeTrendRows(rows)
+		return result, nil
+	}
+
+	p := params
+	p.Start, p.End = head, rollEnd
+	rows, err := client.RollupTrend(ctx, spec.RollupTable, p)
+	if err != nil {
+		return nil, err
+	}
+	if start.Before(head) {
+		edge, err := raw(start, head)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, edge...)
+	}
+	if rollEnd.Before(end) {
+		edge, err := raw(rollEnd, end)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, edge...)
+	}
+	result.Data = mergeTrendRows(rows)
+	result.FromRollup = true
+	return result, nil
+}
+
+// rollupSpan returns the hour-aligned part of [start, end) the rollup can
+// answer, or ok=false when the range should be read from raw logs: it is too
+// short to benefit, or the rollup doesn't cover its start.
+func (m *Manager) rollupSpan(rollup *models.SourceRollup, start, end time.Time) (head, rollEnd time.Time, ok bool) {
+	if !m.cfg.Enabled || rollup == nil || rollup.RolledUpFrom == nil || rollup.RolledUpUntil == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	if end.Sub(start) <= time.Duration(m.cfg.MinRangeDays)*24*time.Hour {
+		return time.Time{}, time.Time{}, false
+	}
+	head = start.Truncate(time.Hour)
+	if head.Before(start) {
+		head = head.Add(time.Hour)
+	}
+	rollEnd = end.Truncate(time.Hour)
+	if until := rollup.RolledUpUntil.UTC(); until.Before(rollEnd) {
+		rollEnd = until
+	}
+	if head.Before(rollup.RolledUpFrom.UTC()) || !head.Before(rollEnd) {
+		return time.Time{}, time.Time{}, false
+	}
+	return head, rollEnd, true
+}
+
+// mergeTrendRows sums rows from the rollup and raw segments that landed in
+// the same bucket and group, ordered by bucket then group.
+func mergeTrendRows(rows []clickhouse.TrendRow) []models.TrendBucket {
+	type key struct {
+		bucket int64
+		group  string
+	}
+	index := make(map[key]int, len(rows))
+	data := make([]models.TrendBucket, 0, len(rows))
+	for _, row := range rows {
+		k := key{row.Bucket.Unix(), row.GroupValue}
+		if i, ok := index[k]; ok {
+			data[i].LogCount += row.LogCount
+			continue
+		}
+		index[k] = len(data)
+		data = append(data, models.TrendBucket{Bucket: row.Bucket, LogCount: row.LogCount, GroupValue: row.GroupValue})
+	}
+	sort.Slice(data, func(i, j int) bool {
+		if !data[i].Bucket.Equal(data[j].Bucket) {
+			return data[i].Bucket.Before(data[j].Bucket)
+		}
+		return data[i].GroupValue < data[j].GroupValue
+	})
+	return data
+}
@@ -0,0 +1,242 @@
+// Package schemadrift keeps a history of each source's columns and reports
+// drift: columns added, dropped or retyped by the ingestion pipeline.
+//
+// The scheduler compares every ClickHouse source's current schema with its
+// latest snapshot and records a new snapshot, with the changes, only when they
+// differ. VictoriaLogs sources are skipped: their fields are inferred from
+// recent logs, so they come and go without the pipeline changing. Configured
+// webhook and Slack channels are notified of each change through the alert
+// delivery path.
+package schemadrift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/alerts"
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// ErrInvalidRequest wraps history request errors.
+var ErrInvalidRequest = errors.New("invalid schema history request")
+
+// checkTimeout bounds one source's schema check, so a wedged source can't
+// stall the sequential scheduler loop.
+const checkTimeout = time.Minute
+
+const (
+	// DefaultHistoryLimit is how many snapshots a history request returns
+	// when it doesn't say.
+	DefaultHistoryLimit = 50
+	// MaxHistoryLimit caps the snapshots returned by one history request.
+	MaxHistoryLimit = 500
+)
+
+// Options encapsulates the dependencies of the schema drift manager.
+type Options struct {
+	Config      config.SchemaDriftConfig
+	DB          store.Store
+	Datasources *datasource.Service
+	// Sender delivers drift notifications; nil disables them.
+	Sender alerts.AlertSender
+	Logger *slog.Logger
+}
+
+// Manager runs the schema drift scheduler and serves schema history.
+type Manager struct {
+	cfg      config.SchemaDriftConfig
+	db       store.Store
+	sender   alerts.AlertSender
+	channels []models.AlertChannel
+	log      *slog.Logger
+
+	// schemaFor and now are seams for tests.
+	schemaFor func(ctx context.Context, sourceID models.SourceID) ([]models.ColumnInfo, error)
+	now       func() time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewManager constructs a schema drift manager.
+func NewManager(opts Options) *Manager {
+	return &Manager{
+		cfg:       opts.Config,
+		db:        opts.DB,
+		sender:    opts.Sender,
+		channels:  opts.Config.Channels(),
+		log:       opts.Logger.With("component", "schema_drift_manager"),
+		schemaFor: opts.Datasources.GetSourceSchema,
+		now:       time.Now,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start launches the scheduler loop. It is a no-op when drift detection is
+// disabled.
+func (m *Manager) Start(ctx context.Context) {
+	if !m.cfg.Enabled {
+		m.log.Debug("schema drift detection disabled")
+		return
+	}
+	m.log.Debug("starting schema drift manager", "interval", m.cfg.Interval, "channels", len(m.channels))
+
+	m.wg.Go(func() {
+		ticker := time.NewTicker(m.cfg.Interval)
+		defer ticker.Stop()
+
+		m.runCycle(ctx)
+		for {
+			select {
+			case <-ticker.C:
+				m.runCycle(ctx)
+			case <-m.stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// Stop signals the scheduler to stop and waits for the current run to end.
+func (m *Manager) Stop() {
+	close(m.stop)
+	m.wg.Wait()
+}
+
+func (m *Manager) runCycle(ctx context.Context) {
+	sources, err := m.db.ListSources(ctx)
+	if err != nil {
+		m.log.Error("failed to list sources", "error", err)
+		return
+	}
+	for _, source := range sources {
+		if models.NormalizeSourceType(source.SourceType) != models.SourceTypeClickHouse {
+			continue
+		}
+		select {
+		case <-m.stop:
+			return
+		default:
+		}
+		runCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		if err := m.check(runCtx, source); err != nil {
+			m.log.Warn("schema drift check failed", "source_id", source.ID, "error", err)
+		}
+		cancel()
+	}
+
+	cutoff := m.now().UTC().AddDate(0, 0, -m.cfg.RetentionDays)
+	pruned, err := m.db.DeleteSourceSchemaSnapshotsBefore(ctx, cutoff)
+	if err != nil {
+		m.log.Error("failed to prune schema snapshots", "error", err)
+		return
+	}
+	if pruned > 0 {
+		m.log.Debug("pruned schema snapshots", "count", pruned, "before", cutoff)
+	}
+}
+
+// check compares the source's current columns with its latest snapshot. The
+// first check records a baseline; later ones record a snapshot and notify only
+// when the columns changed.
+func (m *Manager) check(ctx context.Context, source *models.Source) error {
+	columns, err := m.schemaFor(ctx, source.ID)
+	if err != nil {
+		return err
+	}
+	// A table that reports no columns is unreadable rather than emptied; treat
+	// it as a failed check instead of every column being dropped.
+	if len(columns) == 0 {
+		return fmt.Errorf("source %d reported no columns", source.ID)
+	}
+
+	snapshot := &models.SourceSchemaSnapshot{
+		SourceID:   source.ID,
+		Columns:    columns,
+		Changes:    []models.SchemaChange{},
+		CapturedAt: m.now().UTC(),
+	}
+	latest, err := m.db.GetLatestSourceSchemaSnapshot(ctx, source.ID)
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return m.db.InsertSourceSchemaSnapshot(ctx, snapshot)
+	case err != nil:
+		return err
+	}
+
+	snapshot.Changes = models.DiffSchemas(latest.Columns, columns)
+	if len(snapshot.Changes) == 0 {
+		return nil
+	}
+	if err := m.db.InsertSourceSchemaSnapshot(ctx, snapshot); err != nil {
+		return err
+	}
+	m.log.Info("schema drift detected", "source_id", source.ID, "source", source.Name, "changes", len(snapshot.Changes))
+	m.notify(ctx, source, snapshot)
+	return nil
+}
+
+// notify delivers a drift notification to the configured channels. Delivery
+// failures are logged; the snapshot is already recorded.
+func (m *Manager) notify(ctx context.Context, source *models.Source, snapshot *models.SourceSchemaSnapshot) {
+	if m.sender == nil || len(m.channels) == 0 {
+		return
+	}
+	notification := alerts.AlertNotification{
+		AlertName:   fmt.Sprintf("Schema drift on %s", source.Name),
+		Description: fmt.Sprintf("The columns of source %q changed.", source.Name),
+		Status:      models.AlertStatusTriggered,
+		Severity:    models.AlertSeverityWarning,
+		SourceID:    source.ID,
+		SourceName:  source.Name,
+		Value:       float64(len(snapshot.Changes)),
+		Labels:      map[string]string{"event": "schema_drift"},
+		TriggeredAt: snapshot.CapturedAt,
+		Message:     DescribeChanges(snapshot.Changes),
+		Channels:    m.channels,
+	}
+	for _, delivery := range m.sender.Deliver(ctx, notification) {
+		if delivery.Status == models.AlertDeliveryFailed {
+			m.log.Warn("schema drift notification failed", "source_id", source.ID, "channel", delivery.Channel, "error", delivery.Error)
+		}
+	}
+}
+
+// DescribeChanges renders changes as one line each, e.g.
+// "added column trace_id (String)".
+func DescribeChanges(changes []models.SchemaChange) string {
+	lines := make([]string, 0, len(changes))
+	for _, change := range changes {
+		switch change.Kind {
+		case models.SchemaChangeAdded:
+			lines = append(lines, fmt.Sprintf("added column %s (%s)", change.Column, change.NewType))
+		case models.SchemaChangeDropped:
+			lines = append(lines, fmt.Sprintf("dropped column %s (%s)", change.Column, change.OldType))
+		case models.SchemaChangeTypeChanged:
+			lines = append(lines, fmt.Sprintf("column %s changed type from %s to %s", change.Column, change.OldType, change.NewType))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// History returns up to limit of the source's schema snapshots, newest first.
+// limit must be between 1 and MaxHistoryLimit.
+func (m *Manager) History(ctx context.Context, sourceID models.SourceID, limit int) ([]*models.SourceSchemaSnapshot, error) {
+	if limit < 1 || limit > MaxHistoryLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidRequest, MaxHistoryLimit)
+	}
+	if _, err := m.db.GetSource(ctx, sourceID); err != nil {
+		return nil, err
+	}
+	return m.db.ListSourceSchemaSnapshots(ctx, sourceID, limit)
+}
@@ -0,0 +1,152 @@
+package schemadrift
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/alerts"
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/store/sqlite"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// recordingSender captures delivered notifications.
+type recordingSender struct {
+	sent []alerts.AlertNotification
+}
+
+func (r *recordingSender) Deliver(_ context.Context, notification alerts.AlertNotification) []models.AlertChannelDelivery {
+	r.sent = append(r.sent, notification)
+	return nil
+}
+
+type testEnv struct {
+	m       *Manager
+	db      *sqlite.DB
+	sender  *recordingSender
+	source  *models.Source
+	columns []models.ColumnInfo
+	err     error
+	now     time.Time
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := sqlite.New(context.Background(), sqlite.Options{
+		Logger: logger,
+		Config: config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")},
+	})
+	if err != nil {
+		t.Fatalf("sqlite.New failed: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	source := &models.Source{
+		Name:        "app",
+		MetaTSField: "timestamp",
+		Connection:  models.ConnectionInfo{Host: "ch:9000", Username: "default", Database: "logs", TableName: "app"},
+	}
+	if err := db.CreateSource(context.Background(), source); err != nil {
+		t.Fatalf("CreateSource: %v", err)
+	}
+
+	env := &testEnv{
+		db:     db,
+		sender: &recordingSender{},
+		source: source,
+		now:    time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC),
+	}
+	env.m = NewManager(Options{
+		Config: config.SchemaDriftConfig{
+			Enabled: true, Interval: time.Hour, RetentionDays: 3,
+			WebhookURLs: []string{"https://hooks.example.com/drift"},
+		},
+		DB:     db,
+		Sender: env.sender,
+		Logger: logger,
+	})
+	env.m.schemaFor = func(context.Context, models.SourceID) ([]models.ColumnInfo, error) {
+		return env.columns, env.err
+	}
+	env.m.now = func() time.Time { return env.now }
+	return env
+}
+
+func TestRunCycleRecordsDrift(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	env.columns = []models.ColumnInfo{{Name: "timestamp", Type: "DateTime64(3)"}, {Name: "msg", Type: "String"}}
+	env.m.runCycle(ctx)
+	// Unchanged columns record nothing new.
+	env.now = env.now.Add(time.Hour)
+	env.m.runCycle(ctx)
+
+	history, err := env.m.History(ctx, env.source.ID, DefaultHistoryLimit)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) != 1 || len(history[0].Changes) != 0 {
+		t.Fatalf("history = %+v, want only the baseline", history)
+	}
+	if len(env.sender.sent) != 0 {
+		t.Errorf("baseline sent %d notifications, want none", len(env.sender.sent))
+	}
+
+	env.columns = []models.ColumnInfo{{Name: "timestamp", Type: "DateTime64(3)"}, {Name: "trace_id", Type: "String"}}
+	env.now = env.now.Add(time.Hour)
+	env.m.runCycle(ctx)
+
+	history, _ = env.m.History(ctx, env.source.ID, DefaultHistoryLimit)
+	if len(history) != 2 || len(history[0].Changes) != 2 {
+		t.Fatalf("history = %+v, want a second snapshot with two changes", history)
+	}
+	if len(env.sender.sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(env.sender.sent))
+	}
+	notification := env.sender.sent[0]
+	if notification.SourceID != env.source.ID || len(notification.Channels) != 1 ||
+		!strings.Contains(notification.Message, "dropped column msg (String)") ||
+		!strings.Contains(notification.Message, "added column trace_id (String)") {
+		t.Errorf("unexpected notification: %+v", notification)
+	}
+
+	// Failed and empty reads are not mistaken for dropped columns.
+	env.err = errors.New("connection refused")
+	env.m.runCycle(ctx)
+	env.err, env.columns = nil, nil
+	env.m.runCycle(ctx)
+	if history, _ = env.m.History(ctx, env.source.ID, DefaultHistoryLimit); len(history) != 2 {
+		t.Errorf("history after failed reads = %d snapshots, want 2", len(history))
+	}
+
+	// Four days on, retention drops the baseline but keeps the latest.
+	env.columns = []models.ColumnInfo{{Name: "timestamp", Type: "DateTime64(3)"}, {Name: "trace_id", Type: "String"}}
+	env.now = env.now.Add(4 * 24 * time.Hour)
+	env.m.runCycle(ctx)
+	history, _ = env.m.History(ctx, env.source.ID, DefaultHistoryLimit)
+	if len(history) != 1 || len(history[0].Changes) != 2 {
+		t.Errorf("history after pruning = %+v, want only the latest snapshot", history)
+	}
+}
+
+func TestHistoryValidatesRequest(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	for _, limit := range []int{0, MaxHistoryLimit + 1} {
+		if _, err := env.m.History(ctx, env.source.ID, limit); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("History(limit=%d) err = %v, want ErrInvalidRequest", limit, err)
+		}
+	}
+	if _, err := env.m.History(ctx, env.source.ID+100, 10); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("History for a missing source err = %v, want ErrNotFound", err)
+	}
+}
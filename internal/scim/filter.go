@@ -0,0 +1,37 @@
+package scim
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidFilter is returned for a filter outside the supported subset.
+var ErrInvalidFilter = errors.New("unsupported filter; only `attribute eq \"value\"` is supported")
+
+// Filter is a parsed `attribute eq "value"` expression, the form IdPs use to
+// look up an existing resource before creating it. Attr is lowercased, as
+// SCIM attribute names are case-insensitive.
+type Filter struct {
+	Attr  string
+	Value string
+}
+
+var filterRe = regexp.MustCompile(`(?i)^\s*([a-z][a-z0-9.]*)\s+eq\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// ParseFilter parses a filter query parameter. An empty filter returns nil.
+func ParseFilter(s string) (*Filter, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	m := filterRe.FindStringSubmatch(s)
+	if m == nil {
+		return nil, ErrInvalidFilter
+	}
+	value, err := strconv.Unquote(m[2])
+	if err != nil {
+		return nil, ErrInvalidFilter
+	}
+	return &Filter{Attr: strings.ToLower(m[1]), Value: value}, nil
+}
@@ -0,0 +1,197 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidPatch is returned for a malformed PATCH operation.
+var ErrInvalidPatch = errors.New("invalid patch operation")
+
+// ApplyUserPatch applies PATCH operations to u. add and replace set
+// active, displayName, name (or name.*), userName and externalId, either by
+// path or as a path-less object of attributes. Other attributes and remove
+// operations on them are accepted and ignored.
+func ApplyUserPatch(u *User, ops []PatchOperation) error {
+	for _, op := range ops {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			if op.Path != "" {
+				if err := setUserAttr(u, op.Path, op.Value); err != nil {
+					return err
+				}
+				continue
+			}
+			var attrs map[string]json.RawMessage
+			if err := json.Unmarshal(op.Value, &attrs); err != nil {
+				return fmt.Errorf("%w: path-less %s needs an object value", ErrInvalidPatch, op.Op)
+			}
+			for path, value := range attrs {
+				if err := setUserAttr(u, path, value); err != nil {
+					return err
+				}
+			}
+		case "remove":
+			if op.Path == "" {
+				return fmt.Errorf("%w: remove requires a path", ErrInvalidPatch)
+			}
+		default:
+			return fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.Op)
+		}
+	}
+	return nil
+}
+
+func setUserAttr(u *User, path string, value json.RawMessage) error {
+	switch strings.ToLower(path) {
+	case "active":
+		active, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		u.Active = &active
+	case "displayname":
+		return unmarshalString(value, &u.DisplayName)
+	case "username":
+		return unmarshalString(value, &u.UserName)
+	case "externalid":
+		return unmarshalString(value, &u.ExternalID)
+	case "name":
+		var name Name
+		if err := json.Unmarshal(value, &name); err != nil {
+			return fmt.Errorf("%w: name must be an object", ErrInvalidPatch)
+		}
+		u.Name = &name
+	case "name.formatted", "name.givenname", "name.familyname":
+		if u.Name == nil {
+			u.Name = &Name{}
+		}
+		field := map[string]*string{
+			"name.formatted":  &u.Name.Formatted,
+			"name.givenname":  &u.Name.GivenName,
+			"name.familyname": &u.Name.FamilyName,
+		}[strings.ToLower(path)]
+		return unmarshalString(value, field)
+	}
+	return nil
+}
+
+// ApplyGroupPatch applies PATCH operations to g: displayName replace, and
+// members add, remove and replace, including the `members[value eq "id"]`
+// path form for removing one member.
+func ApplyGroupPatch(g *Group, ops []PatchOperation) error {
+	for _, op := range ops {
+		verb := strings.ToLower(op.Op)
+		if verb != "add" && verb != "remove" && verb != "replace" {
+			return fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.Op)
+		}
+
+		if op.Path == "" {
+			if verb == "remove" {
+				return fmt.Errorf("%w: remove requires a path", ErrInvalidPatch)
+			}
+			var attrs map[string]json.RawMessage
+			if err := json.Unmarshal(op.Value, &attrs); err != nil {
+				return fmt.Errorf("%w: path-less %s needs an object value", ErrInvalidPatch, op.Op)
+			}
+			for path, value := range attrs {
+				if err := patchGroupAttr(g, verb, path, value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := patchGroupAttr(g, verb, op.Path, op.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func patchGroupAttr(g *Group, verb, path string, value json.RawMessage) error {
+	lower := strings.ToLower(path)
+	switch {
+	case lower == "displayname":
+		if verb == "remove" {
+			return fmt.Errorf("%w: displayName is required", ErrInvalidPatch)
+		}
+		return unmarshalString(value, &g.DisplayName)
+
+	case lower == "members":
+		var members []Member
+		if len(value) > 0 {
+			if err := json.Unmarshal(value, &members); err != nil {
+				return fmt.Errorf("%w: members must be a list of {\"value\": id}", ErrInvalidPatch)
+			}
+		}
+		switch verb {
+		case "replace":
+			g.Members = nil
+			addMembers(g, members)
+		case "add":
+			addMembers(g, members)
+		case "remove":
+			if len(value) == 0 {
+				g.Members = nil
+			} else {
+				removeMembers(g, members)
+			}
+		}
+		return nil
+
+	case strings.HasPrefix(lower, "members[") && strings.HasSuffix(lower, "]"):
+		if verb != "remove" {
+			return fmt.Errorf("%w: only remove supports a members filter path", ErrInvalidPatch)
+		}
+		f, err := ParseFilter(path[len("members[") : len(path)-1])
+		if err != nil || f == nil || f.Attr != "value" {
+			return fmt.Errorf("%w: member path must be members[value eq \"id\"]", ErrInvalidPatch)
+		}
+		removeMembers(g, []Member{{Value: f.Value}})
+		return nil
+	}
+	return nil
+}
+
+func addMembers(g *Group, members []Member) {
+	for _, m := range members {
+		if !slices.ContainsFunc(g.Members, func(e Member) bool { return e.Value == m.Value }) {
+			g.Members = append(g.Members, m)
+		}
+	}
+}
+
+func removeMembers(g *Group, members []Member) {
+	g.Members = slices.DeleteFunc(g.Members, func(e Member) bool {
+		return slices.ContainsFunc(members, func(m Member) bool { return m.Value == e.Value })
+	})
+}
+
+func unmarshalString(value json.RawMessage, dst *string) error {
+	if err := json.Unmarshal(value, dst); err != nil {
+		return fmt.Errorf("%w: expected a string value", ErrInvalidPatch)
+	}
+	return nil
+}
+
+// parseBool accepts a JSON boolean or the "True"/"False" strings some IdPs
+// send.
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		switch strings.ToLower(s) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("%w: expected a boolean value", ErrInvalidPatch)
+}
@@ -0,0 +1,146 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    *Filter
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: `userName eq "alice@example.com"`, want: &Filter{Attr: "username", Value: "alice@example.com"}},
+		{in: `  displayName EQ "SRE \"core\""  `, want: &Filter{Attr: "displayname", Value: `SRE "core"`}},
+		{in: `emails.value eq "a@b.c"`, want: &Filter{Attr: "emails.value", Value: "a@b.c"}},
+		{in: `userName co "alice"`, wantErr: true},
+		{in: `userName eq "a" and active eq true`, wantErr: true},
+		{in: `userName eq alice`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFilter(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("ParseFilter(%q) err = %v, want ErrInvalidFilter", tt.in, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseFilter(%q): %v", tt.in, err)
+			continue
+		}
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("ParseFilter(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func ops(t *testing.T, body string) []PatchOperation {
+	t.Helper()
+	var req PatchRequest
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("unmarshal patch: %v", err)
+	}
+	return req.Operations
+}
+
+func TestApplyUserPatch(t *testing.T) {
+	u := &User{UserName: "alice@example.com", DisplayName: "Alice"}
+
+	// Azure AD style: capitalised op, string boolean, dotted keys in a
+	// path-less value.
+	err := ApplyUserPatch(u, ops(t, `{"Operations":[
+		{"op":"Replace","path":"active","value":"False"},
+		{"op":"replace","value":{"name.givenName":"Alice","name.familyName":"Smith","displayName":"Alice Smith","title":"ignored"}}
+	]}`))
+	if err != nil {
+		t.Fatalf("ApplyUserPatch: %v", err)
+	}
+	if u.IsActive() {
+		t.Error("active = true, want false")
+	}
+	if u.FullName() != "Alice Smith" || u.Name == nil || u.Name.FamilyName != "Smith" {
+		t.Errorf("name = %q / %+v", u.FullName(), u.Name)
+	}
+
+	if err := ApplyUserPatch(u, ops(t, `{"Operations":[{"op":"replace","path":"active","value":"maybe"}]}`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("bad boolean err = %v, want ErrInvalidPatch", err)
+	}
+	if err := ApplyUserPatch(u, ops(t, `{"Operations":[{"op":"move","path":"active","value":true}]}`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("unknown op err = %v, want ErrInvalidPatch", err)
+	}
+}
+
+func TestApplyGroupPatch(t *testing.T) {
+	g := &Group{DisplayName: "sre", Members: []Member{{Value: "1"}, {Value: "2"}}}
+
+	err := ApplyGroupPatch(g, ops(t, `{"Operations":[
+		{"op":"add","path":"members","value":[{"value":"2"},{"value":"3"}]},
+		{"op":"remove","path":"members[value eq \"1\"]"},
+		{"op":"replace","value":{"displayName":"platform"}}
+	]}`))
+	if err != nil {
+		t.Fatalf("ApplyGroupPatch: %v", err)
+	}
+	if g.DisplayName != "platform" {
+		t.Errorf("displayName = %q, want platform", g.DisplayName)
+	}
+	if got := memberValues(g); got != "2,3" {
+		t.Errorf("members = %s, want 2,3", got)
+	}
+
+	if err := ApplyGroupPatch(g, ops(t, `{"Operations":[{"op":"remove","path":"members","value":[{"value":"3"}]}]}`)); err != nil {
+		t.Fatalf("remove by value: %v", err)
+	}
+	if got := memberValues(g); got != "2" {
+		t.Errorf("members = %s, want 2", got)
+	}
+
+	if err := ApplyGroupPatch(g, ops(t, `{"Operations":[{"op":"replace","path":"members","value":[{"value":"9"}]}]}`)); err != nil {
+		t.Fatalf("replace members: %v", err)
+	}
+	if got := memberValues(g); got != "9" {
+		t.Errorf("members = %s, want 9", got)
+	}
+
+	if err := ApplyGroupPatch(g, ops(t, `{"Operations":[{"op":"remove","path":"members"}]}`)); err != nil {
+		t.Fatalf("remove all: %v", err)
+	}
+	if len(g.Members) != 0 {
+		t.Errorf("members = %v, want none", g.Members)
+	}
+
+	if err := ApplyGroupPatch(g, ops(t, `{"Operations":[{"op":"add","path":"members[value eq \"1\"]"}]}`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("add with filter path err = %v, want ErrInvalidPatch", err)
+	}
+}
+
+func memberValues(g *Group) string {
+	s := ""
+	for i, m := range g.Members {
+		if i > 0 {
+			s += ","
+		}
+		s += m.Value
+	}
+	return s
+}
+
+func TestPage(t *testing.T) {
+	tests := []struct{ start, count, total, from, to int }{
+		{1, 100, 5, 0, 5},
+		{2, 2, 5, 1, 3},
+		{0, 2, 5, 0, 2},
+		{10, 2, 5, 5, 5},
+		{1, 5000, 2000, 0, 1000},
+	}
+	for _, tt := range tests {
+		from, to := Page(tt.start, tt.count, tt.total, 1000)
+		if from != tt.from || to != tt.to {
+			t.Errorf("Page(%d, %d, %d) = %d, %d; want %d, %d", tt.start, tt.count, tt.total, from, to, tt.from, tt.to)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package secrets
+
+// Secret references. A source's password, token or URL can name a secret kept
+// outside the metadata store instead of holding it:
+//
+//	env:NAME              the environment variable NAME
+//	file:/run/secrets/x   the file's contents, without the trailing newline
+//	vault:kv/path#field   field of the Vault KV secret path under mount kv
+//
+// References are resolved when a connection is made, so only the reference is
+// ever stored. Resolved values are cached for the configured TTL and forgotten
+// when a connection using them fails, so a rotated credential is picked up on
+// the next attempt.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/config"
+)
+
+const (
+	refEnv   = "env:"
+	refFile  = "file:"
+	refVault = "vault:"
+)
+
+// vaultTimeout bounds one read from Vault.
+const vaultTimeout = 10 * time.Second
+
+// ErrInvalidRef means a secret reference is malformed.
+var ErrInvalidRef = errors.New("invalid secret reference")
+
+// IsRef reports whether value is a secret reference.
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, refEnv) || strings.HasPrefix(value, refFile) || strings.HasPrefix(value, refVault)
+}
+
+// Resolver resolves secret references and caches their values. A nil
+// *Resolver reads env: and file: references uncached and fails vault: ones.
+type Resolver struct {
+	ttl    time.Duration
+	vault  config.VaultConfig
+	client *http.Client
+
+	// now is a seam for tests.
+	now func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+}
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// NewResolver returns a Resolver for the configured cache TTL and Vault.
+func NewResolver(cfg config.SecretsConfig) *Resolver {
+	return &Resolver{
+		ttl:    cfg.CacheTTL,
+		vault:  cfg.Vault,
+		client: &http.Client{Timeout: vaultTimeout},
+		now:    time.Now,
+		cache:  make(map[string]cachedSecret),
+	}
+}
+
+// Resolve returns the secret value names. Values that aren't references are
+// returned unchanged.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+	if r == nil {
+		return readRef(ctx, nil, config.VaultConfig{}, value)
+	}
+
+	r.mu.Lock()
+	cached, ok := r.cache[value]
+	r.mu.Unlock()
+	if ok && r.now().Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	resolved, err := readRef(ctx, r.client, r.vault, value)
+	if err != nil {
+		return "", err
+	}
+	if r.ttl > 0 {
+		r.mu.Lock()
+		r.cache[value] = cachedSecret{value: resolved, expires: r.now().Add(r.ttl)}
+		r.mu.Unlock()
+	}
+	return resolved, nil
+}
+
+// ResolveFields resolves each field that holds a reference in place.
+func (r *Resolver) ResolveFields(ctx context.Context, fields ...*string) error {
+	for _, field := range fields {
+		resolved, err := r.Resolve(ctx, *field)
+		if err != nil {
+			return err
+		}
+		*field = resolved
+	}
+	return nil
+}
+
+// Forget drops the cached values of refs, so they are read again next time.
+// Values that aren't references are ignored.
+func (r *Resolver) Forget(refs ...string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ref := range refs {
+		delete(r.cache, ref)
+	}
+}
+
+func readRef(ctx context.Context, client *http.Client, vault config.VaultConfig, ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, refEnv):
+		name := strings.TrimPrefix(ref, refEnv)
+		if name == "" {
+			return "", fmt.Errorf("%w %q: missing variable name", ErrInvalidRef, ref)
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret %s: environment variable is not set", ref)
+		}
+		return value, nil
+	case strings.HasPrefix(ref, refFile):
+		path := strings.TrimPrefix(ref, refFile)
+		if path == "" {
+			return "", fmt.Errorf("%w %q: missing file path", ErrInvalidRef, ref)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("secret %s: %w", ref, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	default:
+		return readVault(ctx, client, vault, ref)
+	}
+}
+
+// readVault reads "vault:<mount>/<path>#<field>" from the KV secrets engine.
+func readVault(ctx context.Context, client *http.Client, vault config.VaultConfig, ref string) (string, error) {
+	secretPath, field, ok := strings.Cut(strings.TrimPrefix(ref, refVault), "#")
+	mount, path, hasPath := strings.Cut(secretPath, "/")
+	if !ok || field == "" || mount == "" || !hasPath || path == "" {
+		return "", fmt.Errorf("%w %q: want vault:<mount>/<path>#<field>", ErrInvalidRef, ref)
+	}
+	if client == nil || vault.Address == "" {
+		return "", fmt.Errorf("secret %s: secrets.vault.address is not configured", ref)
+	}
+
+	apiPath := mount + "/" + path
+	if vault.KVVersion != 1 {
+		apiPath = mount + "/data/" + path
+	}
+	endpoint, err := url.JoinPath(vault.Address, "v1", apiPath)
+	if err != nil {
+		return "", fmt.Errorf("secret %s: %w", ref, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("secret %s: %w", ref, err)
+	}
+	req.Header.Set("X-Vault-Token", vault.Token)
+	if vault.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", vault.Namespace)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("secret %s: reading from vault: %w", ref, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("secret %s: vault returned status %d", ref, resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("secret %s: decoding vault response: %w", ref, err)
+	}
+	data := body.Data
+	if vault.KVVersion != 1 {
+		data, _ = data["data"].(map[string]any)
+	}
+	value, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("secret %s: field %q not found", ref, field)
+	}
+	return value, nil
+}
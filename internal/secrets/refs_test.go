@@ -0,0 +1,122 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/config"
+)
+
+func TestResolveEnvAndFile(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("LOGCHEF_TEST_SECRET", "from-env")
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A nil Resolver still reads env: and file: references.
+	var nilResolver *Resolver
+	for _, r := range []*Resolver{nilResolver, NewResolver(config.SecretsConfig{})} {
+		tests := map[string]string{
+			"env:LOGCHEF_TEST_SECRET": "from-env",
+			"file:" + path:            "from-file",
+			"plain":                   "plain",
+		}
+		for ref, want := range tests {
+			if got, err := r.Resolve(ctx, ref); err != nil || got != want {
+				t.Errorf("Resolve(%q) = %q, %v; want %q", ref, got, err, want)
+			}
+		}
+		if _, err := r.Resolve(ctx, "env:LOGCHEF_TEST_UNSET"); err == nil {
+			t.Error("Resolve of an unset variable succeeded")
+		}
+		if _, err := r.Resolve(ctx, "file:"+path+".missing"); err == nil {
+			t.Error("Resolve of a missing file succeeded")
+		}
+	}
+	if _, err := nilResolver.Resolve(ctx, "vault:kv/app#password"); err == nil {
+		t.Error("nil Resolver read a vault: reference")
+	}
+}
+
+func TestResolveCaching(t *testing.T) {
+	ctx := context.Background()
+	r := NewResolver(config.SecretsConfig{CacheTTL: time.Minute})
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	t.Setenv("LOGCHEF_TEST_SECRET", "v1")
+	if got, _ := r.Resolve(ctx, "env:LOGCHEF_TEST_SECRET"); got != "v1" {
+		t.Fatalf("Resolve = %q, want v1", got)
+	}
+	t.Setenv("LOGCHEF_TEST_SECRET", "v2")
+	if got, _ := r.Resolve(ctx, "env:LOGCHEF_TEST_SECRET"); got != "v1" {
+		t.Errorf("Resolve within the TTL = %q, want the cached v1", got)
+	}
+	r.Forget("env:LOGCHEF_TEST_SECRET")
+	if got, _ := r.Resolve(ctx, "env:LOGCHEF_TEST_SECRET"); got != "v2" {
+		t.Errorf("Resolve after Forget = %q, want v2", got)
+	}
+	t.Setenv("LOGCHEF_TEST_SECRET", "v3")
+	now = now.Add(2 * time.Minute)
+	if got, _ := r.Resolve(ctx, "env:LOGCHEF_TEST_SECRET"); got != "v3" {
+		t.Errorf("Resolve after the TTL = %q, want v3", got)
+	}
+}
+
+func TestResolveVault(t *testing.T) {
+	ctx := context.Background()
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("X-Vault-Token") != "root" || r.Header.Get("X-Vault-Namespace") != "team" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/data/logchef/clickhouse":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"hunter2"},"metadata":{"version":3}}}`))
+		case "/v1/secret/logchef/clickhouse":
+			_, _ = w.Write([]byte(`{"data":{"password":"hunter1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	vault := config.VaultConfig{Address: server.URL, Token: "root", Namespace: "team", KVVersion: 2}
+	r := NewResolver(config.SecretsConfig{CacheTTL: time.Minute, Vault: vault})
+	for range 2 {
+		if got, err := r.Resolve(ctx, "vault:kv/logchef/clickhouse#password"); err != nil || got != "hunter2" {
+			t.Fatalf("Resolve(kv v2) = %q, %v; want hunter2", got, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("vault requests = %d, want 1 with the value cached", requests)
+	}
+	if _, err := r.Resolve(ctx, "vault:kv/logchef/clickhouse#username"); err == nil {
+		t.Error("Resolve of a missing field succeeded")
+	}
+	if _, err := r.Resolve(ctx, "vault:kv/logchef/missing#password"); err == nil {
+		t.Error("Resolve of a missing secret succeeded")
+	}
+
+	vault.KVVersion = 1
+	r = NewResolver(config.SecretsConfig{Vault: vault})
+	if got, err := r.Resolve(ctx, "vault:secret/logchef/clickhouse#password"); err != nil || got != "hunter1" {
+		t.Errorf("Resolve(kv v1) = %q, %v; want hunter1", got, err)
+	}
+
+	for _, ref := range []string{"vault:kv/logchef", "vault:kv#password", "vault:kv/logchef#", "env:"} {
+		if _, err := r.Resolve(ctx, ref); !errors.Is(err, ErrInvalidRef) {
+			t.Errorf("Resolve(%q) err = %v, want ErrInvalidRef", ref, err)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+// Package secrets encrypts source connection secrets (passwords, TLS client
+// keys, tokens and header values) at rest in the metadata store.
+//
+// Values are sealed with envelope encryption: each one gets a fresh data key
+// under AES-256-GCM, and the data key is itself sealed by a key encryption key
+// (a KeyWrapper). Sealed values name their wrapper, so after a key rotation
+// values sealed under a previous key still open until they are re-sealed.
+// Only the secret fields of a connection config are sealed; the rest of the
+// JSON, which identity keys and listings read, stays as it was.
+//
+// A secret can also be kept out of the store altogether by a reference to
+// where it lives, resolved when a connection is made; see Resolver.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// sealedPrefix marks a sealed value: "enc:v1:<key id>:<wrapped data key>:<ciphertext>",
+// both base64.
+const sealedPrefix = "enc:v1:"
+
+// KeySize is the length of encryption and data keys, for AES-256.
+const KeySize = 32
+
+var (
+	// ErrNoKey means a sealed value was read, or re-sealing was asked for,
+	// without an encryption key configured.
+	ErrNoKey = errors.New("no secrets encryption key configured")
+	// ErrUnknownKey means a value was sealed under a key that is neither the
+	// current nor a previous one.
+	ErrUnknownKey = errors.New("secret sealed under an unknown key")
+	// ErrCorrupt means a sealed value is malformed or fails authentication.
+	ErrCorrupt = errors.New("sealed secret is corrupt")
+)
+
+// KeyWrapper seals and opens data keys with a key encryption key. The local
+// key from config implements it; a KMS-backed key would too.
+type KeyWrapper interface {
+	// ID names the key in the values it seals.
+	ID() string
+	WrapKey(dataKey []byte) ([]byte, error)
+	UnwrapKey(wrapped []byte) ([]byte, error)
+}
+
+// localKey is a key encryption key held in memory.
+type localKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewLocalKey returns a KeyWrapper for a KeySize-byte key. Its ID is derived
+// from the key, so the same key always has the same ID.
+func NewLocalKey(key []byte) (KeyWrapper, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(key)
+	return &localKey{id: hex.EncodeToString(sum[:4]), aead: aead}, nil
+}
+
+func (k *localKey) ID() string { return k.id }
+
+func (k *localKey) WrapKey(dataKey []byte) ([]byte, error) {
+	return seal(k.aead, dataKey, []byte(k.id))
+}
+
+func (k *localKey) UnwrapKey(wrapped []byte) ([]byte, error) {
+	return open(k.aead, wrapped, []byte(k.id))
+}
+
+// Box seals and opens secrets. A nil *Box stores secrets in plaintext and
+// fails to open sealed ones with ErrNoKey.
+type Box struct {
+	primary KeyWrapper
+	keys    map[string]KeyWrapper
+}
+
+// New returns a Box sealing under primary and opening values sealed under
+// primary or any of previous.
+func New(primary KeyWrapper, previous ...KeyWrapper) *Box {
+	b := &Box{primary: primary, keys: map[string]KeyWrapper{primary.ID(): primary}}
+	for _, key := range previous {
+		if _, ok := b.keys[key.ID()]; !ok {
+			b.keys[key.ID()] = key
+		}
+	}
+	return b
+}
+
+// FromConfig builds a Box from the configured keys, or returns nil when no
+// encryption key is set.
+func FromConfig(cfg config.SecretsConfig) (*Box, error) {
+	if cfg.EncryptionKey == "" {
+		return nil, nil
+	}
+	primary, err := decodeLocalKey(cfg.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("secrets.encryption_key: %w", err)
+	}
+	previous := make([]KeyWrapper, 0, len(cfg.PreviousKeys))
+	for i, encoded := range cfg.PreviousKeys {
+		key, err := decodeLocalKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("secrets.previous_keys[%d]: %w", i, err)
+		}
+		previous = append(previous, key)
+	}
+	return New(primary, previous...), nil
+}
+
+func decodeLocalKey(encoded string) (KeyWrapper, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	return NewLocalKey(key)
+}
+
+// IsSealed reports whether value was sealed by a Box.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// Seal encrypts value, bound to field so a sealed value can't be moved to
+// another field. Empty and already sealed values are returned unchanged, as is
+// everything when b is nil.
+func (b *Box) Seal(field, value string) (string, error) {
+	if b == nil || value == "" || IsSealed(value) {
+		return value, nil
+	}
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+	wrapped, err := b.primary.WrapKey(dataKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(aead, []byte(value), []byte(field))
+	if err != nil {
+		return "", err
+	}
+	return sealedPrefix + b.primary.ID() + ":" +
+		base64.StdEncoding.EncodeToString(wrapped) + ":" +
+		base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Open reverses Seal. Values that aren't sealed are returned unchanged.
+func (b *Box) Open(field, value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+	if b == nil {
+		return "", ErrNoKey
+	}
+	parts := strings.Split(strings.TrimPrefix(value, sealedPrefix), ":")
+	if len(parts) != 3 {
+		return "", ErrCorrupt
+	}
+	key, ok := b.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownKey, parts[0])
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrCorrupt
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrCorrupt
+	}
+	dataKey, err := key.UnwrapKey(wrapped)
+	if err != nil {
+		return "", ErrCorrupt
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", ErrCorrupt
+	}
+	plaintext, err := open(aead, ciphertext, []byte(field))
+	if err != nil {
+		return "", ErrCorrupt
+	}
+	return string(plaintext), nil
+}
+
+// SealConnection seals the secret fields of a source's connection config.
+func (b *Box) SealConnection(sourceType models.SourceType, raw json.RawMessage) (json.RawMessage, error) {
+	if b == nil {
+		return raw, nil
+	}
+	return rewriteSecrets(sourceType, raw, b.Seal)
+}
+
+// OpenConnection opens the sealed secret fields of a source's connection
+// config.
+func (b *Box) OpenConnection(sourceType models.SourceType, raw json.RawMessage) (json.RawMessage, error) {
+	return rewriteSecrets(sourceType, raw, b.Open)
+}
+
+// NeedsSeal reports whether a stored connection config has secrets in
+// plaintext or sealed under a previous key, i.e. whether re-sealing it would
+// change it. It is false for a nil Box.
+func (b *Box) NeedsSeal(sourceType models.SourceType, raw json.RawMessage) bool {
+	if b == nil {
+		return false
+	}
+	needed := false
+	_, _ = rewriteSecrets(sourceType, raw, func(_, value string) (string, error) {
+		if value != "" && !strings.HasPrefix(value, sealedPrefix+b.primary.ID()+":") {
+			needed = true
+		}
+		return value, nil
+	})
+	return needed
+}
+
+// rewriteSecrets applies fn to each non-empty secret field of raw, named by
+// its path, and returns raw re-encoded if any changed.
+func rewriteSecrets(sourceType models.SourceType, raw json.RawMessage, fn func(field, value string) (string, error)) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return raw, nil
+	}
+	var conn map[string]any
+	if err := json.Unmarshal(raw, &conn); err != nil {
+		return nil, fmt.Errorf("unmarshal connection config: %w", err)
+	}
+
+	changed := false
+	rewrite := func(obj map[string]any, key, field string) error {
+		value, ok := obj[key].(string)
+		if !ok || value == "" {
+			return nil
+		}
+		rewritten, err := fn(field, value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+		if rewritten != value {
+			obj[key] = rewritten
+			changed = true
+		}
+		return nil
+	}
+
+	switch models.NormalizeSourceType(sourceType) {
+	case models.SourceTypeClickHouse:
+		for _, key := range []string{"password", "tls_client_key"} {
+			if err := rewrite(conn, key, key); err != nil {
+				return nil, err
+			}
+		}
+	case models.SourceTypeVictoriaLogs:
+		if auth, ok := conn["auth"].(map[string]any); ok {
+			for _, key := range []string{"password", "token"} {
+				if err := rewrite(auth, key, "auth."+key); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if headers, ok := conn["headers"].(map[string]any); ok {
+			for key := range headers {
+				if err := rewrite(headers, key, "headers."+key); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if !changed {
+		return raw, nil
+	}
+	return json.Marshal(conn)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal returns nonce || ciphertext.
+func seal(aead cipher.AEAD, plaintext, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+func open(aead cipher.AEAD, sealed, additional []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrCorrupt
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additional)
+}
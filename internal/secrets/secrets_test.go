@@ -0,0 +1,161 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func newTestKey(t *testing.T, b byte) KeyWrapper {
+	t.Helper()
+	key, err := NewLocalKey(bytes.Repeat([]byte{b}, KeySize))
+	if err != nil {
+		t.Fatalf("NewLocalKey: %v", err)
+	}
+	return key
+}
+
+func TestSealOpen(t *testing.T) {
+	box := New(newTestKey(t, 1))
+
+	sealed, err := box.Seal("password", "hunter2")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if !IsSealed(sealed) || strings.Contains(sealed, "hunter2") {
+		t.Fatalf("Seal = %q, want a sealed value without the plaintext", sealed)
+	}
+	if again, _ := box.Seal("password", sealed); again != sealed {
+		t.Error("Seal re-sealed an already sealed value")
+	}
+	if other, _ := box.Seal("password", "hunter2"); other == sealed {
+		t.Error("Seal returned the same value twice")
+	}
+
+	opened, err := box.Open("password", sealed)
+	if err != nil || opened != "hunter2" {
+		t.Fatalf("Open = %q, %v; want hunter2", opened, err)
+	}
+	if _, err := box.Open("auth.token", sealed); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Open under another field err = %v, want ErrCorrupt", err)
+	}
+	if _, err := box.Open("password", sealed[:len(sealed)-4]); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Open of a truncated value err = %v, want ErrCorrupt", err)
+	}
+	if plain, err := box.Open("password", "plain"); err != nil || plain != "plain" {
+		t.Errorf("Open(plaintext) = %q, %v; want it unchanged", plain, err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey, newKey := newTestKey(t, 1), newTestKey(t, 2)
+	sealed, err := New(oldKey).Seal("password", "hunter2")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	if _, err := New(newKey).Open("password", sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Open without the old key err = %v, want ErrUnknownKey", err)
+	}
+	rotated := New(newKey, oldKey)
+	if opened, err := rotated.Open("password", sealed); err != nil || opened != "hunter2" {
+		t.Errorf("Open with the old key as previous = %q, %v", opened, err)
+	}
+
+	raw := json.RawMessage(`{"host":"ch:9000","password":"` + sealed + `"}`)
+	if !rotated.NeedsSeal(models.SourceTypeClickHouse, raw) {
+		t.Error("NeedsSeal = false for a value sealed under the previous key")
+	}
+	opened, err := rotated.OpenConnection(models.SourceTypeClickHouse, raw)
+	if err != nil {
+		t.Fatalf("OpenConnection: %v", err)
+	}
+	resealed, err := rotated.SealConnection(models.SourceTypeClickHouse, opened)
+	if err != nil {
+		t.Fatalf("SealConnection: %v", err)
+	}
+	if rotated.NeedsSeal(models.SourceTypeClickHouse, resealed) {
+		t.Error("NeedsSeal = true after re-sealing under the current key")
+	}
+}
+
+func TestSealConnection(t *testing.T) {
+	box := New(newTestKey(t, 1))
+
+	t.Run("clickhouse", func(t *testing.T) {
+		raw := json.RawMessage(`{"host":"ch:9000","username":"default","password":"hunter2","tls_client_key":"PEM"}`)
+		if !box.NeedsSeal(models.SourceTypeClickHouse, raw) {
+			t.Error("NeedsSeal = false for plaintext secrets")
+		}
+		sealed, err := box.SealConnection(models.SourceTypeClickHouse, raw)
+		if err != nil {
+			t.Fatalf("SealConnection: %v", err)
+		}
+		var conn map[string]string
+		if err := json.Unmarshal(sealed, &conn); err != nil {
+			t.Fatalf("sealed config is not JSON: %v", err)
+		}
+		if conn["host"] != "ch:9000" || conn["username"] != "default" {
+			t.Errorf("non-secret fields changed: %v", conn)
+		}
+		if !IsSealed(conn["password"]) || !IsSealed(conn["tls_client_key"]) {
+			t.Errorf("secret fields not sealed: %v", conn)
+		}
+
+		opened, err := box.OpenConnection(models.SourceTypeClickHouse, sealed)
+		if err != nil {
+			t.Fatalf("OpenConnection: %v", err)
+		}
+		var round map[string]string
+		_ = json.Unmarshal(opened, &round)
+		if round["password"] != "hunter2" || round["tls_client_key"] != "PEM" {
+			t.Errorf("OpenConnection = %s", opened)
+		}
+	})
+
+	t.Run("victorialogs", func(t *testing.T) {
+		raw := json.RawMessage(`{"base_url":"http://vl:9428","auth":{"mode":"bearer","token":"tok"},"headers":{"X-Api-Key":"k"}}`)
+		sealed, err := box.SealConnection(models.SourceTypeVictoriaLogs, raw)
+		if err != nil {
+			t.Fatalf("SealConnection: %v", err)
+		}
+		if bytes.Contains(sealed, []byte(`"tok"`)) || bytes.Contains(sealed, []byte(`"k"`)) ||
+			!bytes.Contains(sealed, []byte("http://vl:9428")) || !bytes.Contains(sealed, []byte("bearer")) {
+			t.Errorf("SealConnection = %s", sealed)
+		}
+		opened, err := box.OpenConnection(models.SourceTypeVictoriaLogs, sealed)
+		if err != nil || !bytes.Contains(opened, []byte(`"tok"`)) || !bytes.Contains(opened, []byte(`"k"`)) {
+			t.Errorf("OpenConnection = %s, %v", opened, err)
+		}
+	})
+
+	t.Run("no secrets", func(t *testing.T) {
+		raw := json.RawMessage(`{"host":"ch:9000"}`)
+		if box.NeedsSeal(models.SourceTypeClickHouse, raw) {
+			t.Error("NeedsSeal = true without secrets")
+		}
+		if sealed, _ := box.SealConnection(models.SourceTypeClickHouse, raw); !bytes.Equal(sealed, raw) {
+			t.Errorf("SealConnection rewrote a config without secrets: %s", sealed)
+		}
+	})
+}
+
+func TestNilBox(t *testing.T) {
+	var box *Box
+	raw := json.RawMessage(`{"password":"hunter2"}`)
+	if sealed, err := box.SealConnection(models.SourceTypeClickHouse, raw); err != nil || !bytes.Equal(sealed, raw) {
+		t.Errorf("nil SealConnection = %s, %v; want it unchanged", sealed, err)
+	}
+	if box.NeedsSeal(models.SourceTypeClickHouse, raw) {
+		t.Error("nil NeedsSeal = true")
+	}
+
+	sealed, _ := New(newTestKey(t, 1)).Seal("password", "hunter2")
+	if _, err := box.Open("password", sealed); !errors.Is(err, ErrNoKey) {
+		t.Errorf("nil Open err = %v, want ErrNoKey", err)
+	}
+}
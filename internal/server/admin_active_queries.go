@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// activeQueryResponse is one in-flight query in the admin active-queries
+// view. Names are resolved when listing and are empty for deleted users,
+// teams or sources.
+type activeQueryResponse struct {
+	QueryID    string          `json:"query_id"`
+	Class      QueryClass      `json:"class"`
+	UserID     models.UserID   `json:"user_id"`
+	UserEmail  string          `json:"user_email"`
+	TeamID     models.TeamID   `json:"team_id,omitempty"`
+	TeamName   string          `json:"team_name,omitempty"`
+	SourceID   models.SourceID `json:"source_id"`
+	SourceName string          `json:"source_name"`
+	SQL        string          `json:"sql"`
+	StartedAt  time.Time       `json:"started_at"`
+	ElapsedMs  int64           `json:"elapsed_ms"`
+}
+
+// handleListActiveQueries lists every query the tracker holds, across users:
+// previews, exports and live tails, oldest first.
+// URL: GET /api/v1/admin/active-queries
+// Requires: admin (requireAuth + requireAdmin) and logs:read token scope.
+func (s *Server) handleListActiveQueries(c *fiber.Ctx) error {
+	queries := s.queries.List()
+	now := time.Now()
+	names := newActiveQueryNames(s)
+	out := make([]activeQueryResponse, 0, len(queries))
+	for _, q := range queries {
+		out = append(out, activeQueryResponse{
+			QueryID:    q.ID,
+			Class:      q.Class,
+			UserID:     q.UserID,
+			UserEmail:  names.user(c.Context(), q.UserID),
+			TeamID:     q.TeamID,
+			TeamName:   names.team(c.Context(), q.TeamID),
+			SourceID:   q.SourceID,
+			SourceName: names.source(c.Context(), q.SourceID),
+			SQL:        q.QueryText,
+			StartedAt:  q.StartTime.UTC(),
+			ElapsedMs:  now.Sub(q.StartTime).Milliseconds(),
+		})
+	}
+	return SendSuccess(c, fiber.StatusOK, out)
+}
+
+// handleKillActiveQuery cancels any user's query and kills it on ClickHouse,
+// like the owner's own cancel does.
+// URL: POST /api/v1/admin/active-queries/:queryID/kill
+// Requires: admin (requireAuth + requireAdmin) and settings:write token scope.
+func (s *Server) handleKillActiveQuery(c *fiber.Ctx) error {
+	queryID := c.Params("queryID")
+	query, ok := s.queries.KillQuery(queryID)
+	if !ok {
+		return SendErrorWithType(c, fiber.StatusNotFound, "Query not found or already completed", models.NotFoundErrorType)
+	}
+	s.killClickHouseQuery(c.Context(), query.SourceID, query.ID)
+
+	var teamID *models.TeamID
+	if query.TeamID != 0 {
+		teamID = &query.TeamID
+	}
+	s.recordAudit(c, models.AuditActionQueryKill, models.AuditResourceQuery, query.ID, teamID, map[string]any{
+		"class":      query.Class,
+		"user_id":    query.UserID,
+		"source_id":  query.SourceID,
+		"elapsed_ms": time.Since(query.StartTime).Milliseconds(),
+	})
+	s.log.Info("query killed by admin", "query_id", query.ID, "owner_user_id", query.UserID, "source_id", query.SourceID)
+
+	return SendSuccess(c, fiber.StatusOK, map[string]any{
+		"message":  "Query killed",
+		"query_id": query.ID,
+	})
+}
+
+// activeQueryNames resolves the IDs in a listing, looking each one up once.
+type activeQueryNames struct {
+	s       *Server
+	users   map[models.UserID]string
+	teams   map[models.TeamID]string
+	sources map[models.SourceID]string
+}
+
+func newActiveQueryNames(s *Server) *activeQueryNames {
+	return &activeQueryNames{
+		s:       s,
+		users:   make(map[models.UserID]string),
+		teams:   make(map[models.TeamID]string),
+		sources: make(map[models.SourceID]string),
+	}
+}
+
+func (n *activeQueryNames) user(ctx context.Context, id models.UserID) string {
+	if name, ok := n.users[id]; ok {
+		return name
+	}
+	if u, err := n.s.sqlite.GetUser(ctx, id); err == nil {
+		n.users[id] = u.Email
+	} else {
+		n.users[id] = ""
+	}
+	return n.users[id]
+}
+
+func (n *activeQueryNames) team(ctx context.Context, id models.TeamID) string {
+	if id == 0 {
+		return ""
+	}
+	if name, ok := n.teams[id]; ok {
+		return name
+	}
+	if t, err := n.s.sqlite.GetTeam(ctx, id); err == nil {
+		n.teams[id] = t.Name
+	} else {
+		n.teams[id] = ""
+	}
+	return n.teams[id]
+}
+
+func (n *activeQueryNames) source(ctx context.Context, id models.SourceID) string {
+	if name, ok := n.sources[id]; ok {
+		return name
+	}
+	if src, err := n.s.sqlite.GetSource(ctx, id); err == nil {
+		n.sources[id] = src.Name
+	} else {
+		n.sources[id] = ""
+	}
+	return n.sources[id]
+}
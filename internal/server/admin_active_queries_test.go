@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestAdminActiveQueries(t *testing.T) {
+	s := newDashboardTestServer(t)
+	s.queries = newQueryTracker(nil, "test", 0)
+	admin := mkTestUser(t, s.sqlite, "admin@test.dev", models.UserRoleAdmin)
+	owner := mkTestUser(t, s.sqlite, "owner@test.dev", models.UserRoleMember)
+	team, src := mkTestTeam(t, s.sqlite, "payments", owner)
+
+	cancelled := false
+	const queryID = "test-admin-active-query"
+	if err := s.queries.StartQueryWithID(queryID, QueryClassPreview, owner.ID, src.ID, team.ID, "SELECT 1", func() { cancelled = true }, 0, 0, 0); err != nil {
+		t.Fatalf("StartQueryWithID: %v", err)
+	}
+	t.Cleanup(func() { s.queries.RemoveQuery(queryID) })
+
+	app := fiber.New()
+	withUser(app, http.MethodGet, "/admin/active-queries", admin, s.handleListActiveQueries)
+	withUser(app, http.MethodPost, "/admin/active-queries/:queryID/kill", admin, s.handleKillActiveQuery)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/admin/active-queries", http.NoBody))
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var body struct {
+		Data []activeQueryResponse `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding list: %v", err)
+	}
+	resp.Body.Close()
+	var got *activeQueryResponse
+	for i := range body.Data {
+		if body.Data[i].QueryID == queryID {
+			got = &body.Data[i]
+		}
+	}
+	if got == nil {
+		t.Fatalf("query %s missing from %+v", queryID, body.Data)
+	}
+	if got.UserEmail != owner.Email || got.TeamName != team.Name || got.SourceName != src.Name || got.SQL != "SELECT 1" || got.Class != QueryClassPreview {
+		t.Fatalf("listed query = %+v", got)
+	}
+
+	// Admins can kill another user's query.
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, "/admin/active-queries/"+queryID+"/kill", http.NoBody))
+	if err != nil {
+		t.Fatalf("kill: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK || !cancelled {
+		t.Fatalf("kill: status %d, cancelled %v", resp.StatusCode, cancelled)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, "/admin/active-queries/"+queryID+"/kill", http.NoBody))
+	if err != nil {
+		t.Fatalf("second kill: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Fatalf("second kill: status %d, want 404", resp.StatusCode)
+	}
+}
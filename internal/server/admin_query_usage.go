@@ -0,0 +1,34 @@
+package server
+
+import (
+	"strconv"
+
+	"github.com/mr-karan/logchef/pkg/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// handleAdminQueryUsage returns a usage report over the query history of the
+// last `days` days (default query_analytics.window_days, clamped 1..365): top
+// users and sources, the slowest queries, the slow-query count and the most
+// filtered fields per source. Like /admin/query-activity it reads the per-user
+// capped query_history, so it reflects recent usage.
+// URL: GET /api/v1/admin/query-usage?days=<N>
+// Requires: admin (requireAuth + requireAdmin) and logs:read token scope.
+func (s *Server) handleAdminQueryUsage(c *fiber.Ctx) error {
+	days := s.analytics.WindowDays()
+	if raw := c.Query("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid days", models.ValidationErrorType)
+		}
+		days = min(max(parsed, queryStatsMinDays), queryStatsMaxDays)
+	}
+
+	report, err := s.analytics.Report(c.Context(), days)
+	if err != nil {
+		s.log.Error("failed to build query usage report", "error", err)
+		return SendError(c, fiber.StatusInternalServerError, "Error building query usage report")
+	}
+	return SendSuccess(c, fiber.StatusOK, report)
+}
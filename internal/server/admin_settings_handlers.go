@@ -235,6 +235,7 @@ var specificSettingValidators = map[string]func(string) error{
 	"ai.base_url":                  validateOptionalURL,
 	"alerts.smtp_port":             validateNonNegativeInt,
 	"alerts.history_limit":         validatePositiveInt,
+	"alerts.room_max_per_hour":     validateNonNegativeInt,
 	"auth.max_concurrent_sessions": validatePositiveInt,
 	"ai.max_tokens":                validatePositiveInt,
 	"alerts.smtp_security":         validateSMTPSecurity,
@@ -1,10 +1,10 @@
 package server
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
@@ -21,6 +21,12 @@ import (
 // EvaluateAlert.
 const TestAlertTimeout = 30 * time.Second
 
+// Bounds of the window handleGetAlertState builds a timeline over.
+const (
+	alertStateDefaultWindow = 24 * time.Hour
+	alertStateMaxWindow     = 30 * 24 * time.Hour
+)
+
 // requireAlertsEnabled is route-group middleware that short-circuits with 503
 // when the alerts subsystem is disabled in config, and otherwise passes the
 // request through. The flag is read from the config snapshot at request time;
@@ -79,10 +85,46 @@ func (s *Server) loadAlertWithVisibility(c *fiber.Ctx) (*models.Alert, *models.U
 	return alert, user, nil
 }
 
-// handleListAlerts lists alerts the caller can see. Optional ?source_id filter.
+// alertSeverityRank orders severities from least to most severe.
+var alertSeverityRank = map[models.AlertSeverity]int{
+	models.AlertSeverityInfo:     0,
+	models.AlertSeverityWarning:  1,
+	models.AlertSeverityCritical: 2,
+}
+
+// alertListSpec is the sort and filter allow-list of the alert list. source_id
+// is not among the filters: it is checked for access before listing.
+var alertListSpec = listSpec[*models.Alert]{
+	sorts: map[string]func(a, b *models.Alert) int{
+		"name": func(a, b *models.Alert) int { return compareFold(a.Name, b.Name) },
+		"severity": func(a, b *models.Alert) int {
+			return cmp.Compare(alertSeverityRank[a.Severity], alertSeverityRank[b.Severity])
+		},
+		"created_at":        func(a, b *models.Alert) int { return compareTime(a.CreatedAt, b.CreatedAt) },
+		"updated_at":        func(a, b *models.Alert) int { return compareTime(a.UpdatedAt, b.UpdatedAt) },
+		"last_triggered_at": func(a, b *models.Alert) int { return compareTimePtr(a.LastTriggeredAt, b.LastTriggeredAt) },
+	},
+	filters: map[string]func(a *models.Alert, value string) bool{
+		"severity":   func(a *models.Alert, v string) bool { return string(a.Severity) == v },
+		"last_state": func(a *models.Alert, v string) bool { return string(a.LastState) == v },
+		"is_active":  func(a *models.Alert, v string) bool { return matchBool(a.IsActive, v) },
+		"q":          func(a *models.Alert, v string) bool { return containsFold(v, a.Name, a.Description) },
+	},
+	defaultSort: "updated_at",
+	defaultDesc: true,
+}
+
+// handleListAlerts lists alerts the caller can see, paginated. Optional
+// ?source_id filter plus the alertListSpec sorts and filters.
 func (s *Server) handleListAlerts(c *fiber.Ctx) error {
 	user := c.Locals("user").(*models.User)
 
+	q, err := parseListQuery(c, alertListSpec)
+	if err != nil {
+		return sendListError(c, err)
+	}
+
+	var alerts []*models.Alert
 	if sourceParam := c.Query("source_id"); sourceParam != "" {
 		sourceID, err := core.ParseSourceID(sourceParam)
 		if err != nil {
@@ -95,18 +137,30 @@ func (s *Server) handleListAlerts(c *fiber.Ctx) error {
 		if !hasAccess {
 			return SendErrorWithType(c, fiber.StatusForbidden, "No team you belong to has access to this source", models.AuthorizationErrorType)
 		}
-		alerts, err := core.ListAlertsBySource(c.Context(), s.sqlite, sourceID)
+		alerts, err = core.ListAlertsBySource(c.Context(), s.sqlite, sourceID)
+		if err != nil {
+			return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to list alerts", models.GeneralErrorType)
+		}
+	} else {
+		alerts, err = core.ListAlertsForUser(c.Context(), s.sqlite, user.ID)
 		if err != nil {
 			return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to list alerts", models.GeneralErrorType)
 		}
-		return SendSuccess(c, fiber.StatusOK, alerts)
 	}
+	page, pagination := applyListQuery(alerts, q, alertListSpec)
+	return SendList(c, page, pagination)
+}
 
-	alerts, err := core.ListAlertsForUser(c.Context(), s.sqlite, user.ID)
+// alertRawSQLAllowed reports whether user may give a source's alerts raw SQL
+// queries. It writes the error response itself and reports ok=false on
+// failure.
+func (s *Server) alertRawSQLAllowed(c *fiber.Ctx, user *models.User, sourceID models.SourceID) (allowed, ok bool, err error) {
+	allowed, err = core.UserCanRunRawSQLOnSource(c.Context(), s.sqlite, user, sourceID)
 	if err != nil {
-		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to list alerts", models.GeneralErrorType)
+		s.log.Error("failed to check raw sql access", "error", err, "source_id", sourceID)
+		return false, false, SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to check raw SQL access", models.DatabaseErrorType)
 	}
-	return SendSuccess(c, fiber.StatusOK, alerts)
+	return allowed, true, nil
 }
 
 // handleCreateAlert creates a new alert against the source in the request body.
@@ -133,15 +187,33 @@ func (s *Server) handleCreateAlert(c *fiber.Ctx) error {
 	if !hasAccess {
 		return SendErrorWithType(c, fiber.StatusForbidden, "No team you belong to has access to this source", models.AuthorizationErrorType)
 	}
+	if ok, err := s.checkSourcePermission(c, user, req.SourceID, models.TeamPermissionManageAlerts); !ok {
+		return err
+	}
+	if ok, err := s.checkWholeSourceAccess(c, user, req.SourceID); !ok {
+		return err
+	}
 
-	alert, err := core.CreateAlert(c.Context(), s.sqlite, s.datasources, s.log, req.SourceID, user.ID, &req)
+	allowRawSQL, ok, err := s.alertRawSQLAllowed(c, user, req.SourceID)
+	if !ok {
+		return err
+	}
+
+	alert, err := core.CreateAlert(c.Context(), s.sqlite, s.datasources, s.log, req.SourceID, user.ID, allowRawSQL, &req)
 	if err != nil {
+		if errors.Is(err, core.ErrRawSQLDisabled) {
+			return SendErrorWithType(c, fiber.StatusForbidden, "Raw SQL is disabled for this team; use LogchefQL instead", models.AuthorizationErrorType)
+		}
 		if errors.Is(err, core.ErrInvalidAlertConfiguration) {
 			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
 		}
 		s.log.Error("failed to create alert", "source_id", req.SourceID, "error", err)
 		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to create alert", models.GeneralErrorType)
 	}
+	s.recordAudit(c, models.AuditActionAlertCreate, models.AuditResourceAlert, auditID(alert.ID), nil, map[string]any{
+		"name":      alert.Name,
+		"source_id": alert.SourceID,
+	})
 	return SendSuccess(c, fiber.StatusCreated, alert)
 }
 
@@ -163,15 +235,28 @@ func (s *Server) handleUpdateAlert(c *fiber.Ctx) error {
 	if !core.UserCanEditAlert(alert, user) {
 		return SendErrorWithType(c, fiber.StatusForbidden, "Only the creator or a global admin can edit this alert", models.AuthorizationErrorType)
 	}
+	if ok, err := s.checkSourcePermission(c, user, alert.SourceID, models.TeamPermissionManageAlerts); !ok {
+		return err
+	}
+	if ok, err := s.checkWholeSourceAccess(c, user, alert.SourceID); !ok {
+		return err
+	}
 
 	var req models.UpdateAlertRequest
 	if err := c.BodyParser(&req); err != nil {
 		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
 	}
 
-	updated, updateErr := core.UpdateAlert(c.Context(), s.sqlite, s.datasources, s.log, alert.ID, &req)
+	allowRawSQL, ok, err := s.alertRawSQLAllowed(c, user, alert.SourceID)
+	if !ok {
+		return err
+	}
+
+	updated, updateErr := core.UpdateAlert(c.Context(), s.sqlite, s.datasources, s.log, alert.ID, allowRawSQL, &req)
 	if updateErr != nil {
 		switch {
+		case errors.Is(updateErr, core.ErrRawSQLDisabled):
+			return SendErrorWithType(c, fiber.StatusForbidden, "Raw SQL is disabled for this team; use LogchefQL instead", models.AuthorizationErrorType)
 		case errors.Is(updateErr, core.ErrInvalidAlertConfiguration):
 			return SendErrorWithType(c, fiber.StatusBadRequest, updateErr.Error(), models.ValidationErrorType)
 		case errors.Is(updateErr, core.ErrAlertNotFound):
@@ -181,6 +266,10 @@ func (s *Server) handleUpdateAlert(c *fiber.Ctx) error {
 			return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to update alert", models.GeneralErrorType)
 		}
 	}
+	s.recordAudit(c, models.AuditActionAlertUpdate, models.AuditResourceAlert, auditID(alert.ID), nil, map[string]any{
+		"name":      updated.Name,
+		"source_id": updated.SourceID,
+	})
 	return SendSuccess(c, fiber.StatusOK, updated)
 }
 
@@ -193,6 +282,9 @@ func (s *Server) handleDeleteAlert(c *fiber.Ctx) error {
 	if !core.UserCanEditAlert(alert, user) {
 		return SendErrorWithType(c, fiber.StatusForbidden, "Only the creator or a global admin can delete this alert", models.AuthorizationErrorType)
 	}
+	if ok, err := s.checkSourcePermission(c, user, alert.SourceID, models.TeamPermissionManageAlerts); !ok {
+		return err
+	}
 
 	if delErr := core.DeleteAlert(c.Context(), s.sqlite, s.log, alert.ID); delErr != nil {
 		if errors.Is(delErr, core.ErrAlertNotFound) {
@@ -200,6 +292,10 @@ func (s *Server) handleDeleteAlert(c *fiber.Ctx) error {
 		}
 		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to delete alert", models.GeneralErrorType)
 	}
+	s.recordAudit(c, models.AuditActionAlertDelete, models.AuditResourceAlert, auditID(alert.ID), nil, map[string]any{
+		"name":      alert.Name,
+		"source_id": alert.SourceID,
+	})
 	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Alert deleted"})
 }
 
@@ -212,6 +308,9 @@ func (s *Server) handleResolveAlert(c *fiber.Ctx) error {
 	if !core.UserCanEditAlert(alert, user) {
 		return SendErrorWithType(c, fiber.StatusForbidden, "Only the creator or a global admin can resolve this alert", models.AuthorizationErrorType)
 	}
+	if ok, err := s.checkSourcePermission(c, user, alert.SourceID, models.TeamPermissionManageAlerts); !ok {
+		return err
+	}
 
 	var req models.ResolveAlertRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -234,34 +333,74 @@ func (s *Server) handleResolveAlert(c *fiber.Ctx) error {
 			return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to resolve alert", models.GeneralErrorType)
 		}
 	}
+	s.recordAudit(c, models.AuditActionAlertResolve, models.AuditResourceAlert, auditID(alert.ID), nil, map[string]any{
+		"source_id": alert.SourceID,
+	})
 	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Alert resolved"})
 }
 
-// handleListAlertHistory returns recent history entries for an alert.
+// alertHistoryListSpec is the sort and filter allow-list of an alert's history.
+var alertHistoryListSpec = listSpec[*models.AlertHistoryEntry]{
+	sorts: map[string]func(a, b *models.AlertHistoryEntry) int{
+		"triggered_at": func(a, b *models.AlertHistoryEntry) int {
+			return cmp.Or(compareTime(a.TriggeredAt, b.TriggeredAt), cmp.Compare(a.ID, b.ID))
+		},
+	},
+	filters: map[string]func(e *models.AlertHistoryEntry, value string) bool{
+		"status": func(e *models.AlertHistoryEntry, v string) bool { return string(e.Status) == v },
+	},
+	defaultSort: "triggered_at",
+	defaultDesc: true,
+}
+
+// handleListAlertHistory lists an alert's retained history, newest first and
+// paginated. History is pruned to alerts.history_limit entries per alert, so
+// the whole of it is loaded and paged in memory.
 func (s *Server) handleListAlertHistory(c *fiber.Ctx) error {
 	alert, _, err := s.loadAlertWithVisibility(c)
 	if err != nil {
 		return err
 	}
+	q, err := parseListQuery(c, alertHistoryListSpec)
+	if err != nil {
+		return sendListError(c, err)
+	}
 
-	limit := s.config.Alerts.HistoryLimit
-	if limit <= 0 {
-		limit = models.DefaultAlertHistoryLimit
+	retained := s.config.Alerts.HistoryLimit
+	if retained <= 0 {
+		retained = models.DefaultAlertHistoryLimit
 	}
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
-			if parsed < limit {
-				limit = parsed
-			}
+	history, err := core.ListAlertHistory(c.Context(), s.sqlite, alert.ID, retained)
+	if err != nil {
+		s.log.Error("failed to list alert history", "alert_id", alert.ID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to list alert history", models.GeneralErrorType)
+	}
+	page, pagination := applyListQuery(history, q, alertHistoryListSpec)
+	return SendList(c, page, pagination)
+}
+
+// handleGetAlertState returns an alert with its current state, last value and
+// state timeline over ?window= (a duration, default 24h, at most 30 days).
+func (s *Server) handleGetAlertState(c *fiber.Ctx) error {
+	alert, _, err := s.loadAlertWithVisibility(c)
+	if err != nil {
+		return err
+	}
+	window := alertStateDefaultWindow
+	if raw := c.Query("window"); raw != "" {
+		window, err = time.ParseDuration(raw)
+		if err != nil || window <= 0 {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid window", models.ValidationErrorType)
 		}
+		window = min(window, alertStateMaxWindow)
 	}
 
-	history, err := core.ListAlertHistory(c.Context(), s.sqlite, alert.ID, limit)
+	state, err := core.GetAlertState(c.Context(), s.sqlite, alert, window, time.Now().UTC())
 	if err != nil {
-		s.log.Error("failed to list alert history", "alert_id", alert.ID, "error", err)
-		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to list alert history", models.GeneralErrorType)
+		s.log.Error("failed to build alert state", "alert_id", alert.ID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get alert state", models.GeneralErrorType)
 	}
-	return SendSuccess(c, fiber.StatusOK, history)
+	return SendSuccess(c, fiber.StatusOK, state)
 }
 
 // handleTestAlertQuery executes a test query against the source in the request body.
@@ -285,6 +424,17 @@ func (s *Server) handleTestAlertQuery(c *fiber.Ctx) error {
 	if !hasAccess {
 		return SendErrorWithType(c, fiber.StatusForbidden, "No team you belong to has access to this source", models.AuthorizationErrorType)
 	}
+	if ok, err := s.checkSourcePermission(c, user, req.SourceID, models.TeamPermissionManageAlerts); !ok {
+		return err
+	}
+	if ok, err := s.checkWholeSourceAccess(c, user, req.SourceID); !ok {
+		return err
+	}
+
+	allowRawSQL, ok, err := s.alertRawSQLAllowed(c, user, req.SourceID)
+	if !ok {
+		return err
+	}
 
 	if req.LookbackSeconds <= 0 {
 		req.LookbackSeconds = int(s.config.Alerts.DefaultLookback.Seconds())
@@ -295,8 +445,11 @@ func (s *Server) handleTestAlertQuery(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(c.Context(), TestAlertTimeout)
 	defer cancel()
 
-	result, err := core.TestAlertQuery(ctx, s.sqlite, s.datasources, req.SourceID, &req.TestAlertQueryRequest)
+	result, err := core.TestAlertQuery(ctx, s.sqlite, s.datasources, req.SourceID, allowRawSQL, &req.TestAlertQueryRequest)
 	if err != nil {
+		if errors.Is(err, core.ErrRawSQLDisabled) {
+			return SendErrorWithType(c, fiber.StatusForbidden, "Raw SQL is disabled for this team; use LogchefQL instead", models.AuthorizationErrorType)
+		}
 		if ctx.Err() == context.Canceled {
 			return SendErrorWithType(c, fiber.StatusRequestTimeout, "Request cancelled", models.ExternalServiceErrorType)
 		}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/alerttransfer"
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+type importAlertsRequest struct {
+	// Document is the YAML or JSON text of an export.
+	Document string `json:"document"`
+	DryRun   bool   `json:"dry_run"`
+}
+
+// handleExportTeamAlerts downloads the alerts on a team's sources as a YAML
+// or JSON document (?format=, default yaml).
+// URL: GET /api/v1/teams/:teamID/alerts/export
+// Requires: team membership
+func (s *Server) handleExportTeamAlerts(c *fiber.Ctx) error {
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	format := c.Query("format", alerttransfer.FormatYAML)
+	if format != alerttransfer.FormatYAML && format != alerttransfer.FormatJSON {
+		return SendErrorWithType(c, fiber.StatusBadRequest, `format must be "yaml" or "json"`, models.ValidationErrorType)
+	}
+
+	doc, err := alerttransfer.Export(c.Context(), s.sqlite, teamID)
+	if err != nil {
+		s.log.Error("failed to export alerts", "team_id", teamID, "error", err)
+		return SendError(c, fiber.StatusInternalServerError, "Failed to export alerts")
+	}
+	body, err := alerttransfer.Marshal(doc, format)
+	if err != nil {
+		s.log.Error("failed to encode alert export", "team_id", teamID, "error", err)
+		return SendError(c, fiber.StatusInternalServerError, "Failed to export alerts")
+	}
+
+	contentType := "application/yaml"
+	if format == alerttransfer.FormatJSON {
+		contentType = fiber.MIMEApplicationJSON
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="logchef-alerts-team-%d-%s.%s"`, teamID, doc.ExportedAt.Format("20060102-150405"), format))
+	return c.Status(fiber.StatusOK).Send(body)
+}
+
+// handleImportTeamAlerts creates or updates the alerts of an exported
+// document on the team's sources.
+// URL: POST /api/v1/teams/:teamID/alerts/import
+// Requires: team role with the manage-alerts permission
+func (s *Server) handleImportTeamAlerts(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	var req importAlertsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	doc, err := alerttransfer.Parse([]byte(req.Document))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+	result, err := alerttransfer.Import(c.Context(), s.sqlite, s.datasources, s.log, teamID, user, doc, alerttransfer.ImportOptions{
+		DryRun: req.DryRun,
+	})
+	if err != nil {
+		if errors.Is(err, alerttransfer.ErrInvalidDocument) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+		s.log.Error("failed to import alerts", "team_id", teamID, "error", err)
+		return SendError(c, fiber.StatusInternalServerError, "Failed to import alerts")
+	}
+
+	for _, imported := range result.Alerts {
+		var action models.AuditAction
+		switch imported.Status {
+		case alerttransfer.StatusCreated:
+			action = models.AuditActionAlertCreate
+		case alerttransfer.StatusUpdated:
+			action = models.AuditActionAlertUpdate
+		default:
+			continue
+		}
+		s.recordAudit(c, action, models.AuditResourceAlert, auditID(imported.AlertID), nil, map[string]any{
+			"name":   imported.Name,
+			"source": imported.Source,
+			"via":    "import",
+		})
+	}
+	if !req.DryRun {
+		s.log.Info("alert.import", "team_id", teamID, "created", result.Created, "updated", result.Updated,
+			"unchanged", result.Unchanged, "failed", result.Failed)
+	}
+	return SendSuccess(c, fiber.StatusOK, result)
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/pkg/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sendAnnotationError maps core annotation errors onto responses.
+func (s *Server) sendAnnotationError(c *fiber.Ctx, err error, action string) error {
+	switch {
+	case errors.Is(err, core.ErrInvalidAnnotation):
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	case errors.Is(err, core.ErrAnnotationForbidden):
+		return SendErrorWithType(c, fiber.StatusForbidden, err.Error(), models.AuthorizationErrorType)
+	case errors.Is(err, core.ErrAnnotationNotFound):
+		return SendErrorWithType(c, fiber.StatusNotFound, "Annotation not found", models.NotFoundErrorType)
+	default:
+		s.log.Error("failed to "+action+" annotation", "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to "+action+" annotation", models.GeneralErrorType)
+	}
+}
+
+// parseAnnotationRoute reads the :teamID and :annotationID route params. When
+// ok is false the error response has already been written and err should be
+// returned as-is.
+func parseAnnotationRoute(c *fiber.Ctx) (teamID models.TeamID, id models.AnnotationID, ok bool, err error) {
+	teamID, err = core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return 0, 0, false, SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	raw, err := parsePositiveIntParam(c, "annotationID")
+	if err != nil {
+		return 0, 0, false, SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+	return teamID, models.AnnotationID(raw), true, nil
+}
+
+// handleListAnnotations lists a team's annotations, newest first. Optional
+// filters: ?source_id (team-wide annotations plus that source's own) and
+// ?start_time/?end_time (RFC3339, both required) for those overlapping a range.
+func (s *Server) handleListAnnotations(c *fiber.Ctx) error {
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+
+	var filter core.AnnotationFilter
+	if raw := c.Query("source_id"); raw != "" {
+		id, err := core.ParseSourceID(raw)
+		if err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source_id parameter", models.ValidationErrorType)
+		}
+		filter.SourceID = id
+	}
+	filter.Start, filter.End, err = parseRFC3339TimeRange(c.Query("start_time"), c.Query("end_time"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+
+	annotations, err := core.ListAnnotations(c.Context(), s.sqlite, teamID, filter)
+	if err != nil {
+		return s.sendAnnotationError(c, err, "list")
+	}
+	return SendSuccess(c, fiber.StatusOK, annotations)
+}
+
+// handleCreateAnnotation marks a moment or range on the team's timeline.
+func (s *Server) handleCreateAnnotation(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+
+	var req models.AnnotationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	annotation, err := core.CreateAnnotation(c.Context(), s.sqlite, s.log, user, teamID, &req)
+	if err != nil {
+		return s.sendAnnotationError(c, err, "create")
+	}
+	return SendSuccess(c, fiber.StatusCreated, annotation)
+}
+
+// handleGetAnnotation returns one annotation.
+func (s *Server) handleGetAnnotation(c *fiber.Ctx) error {
+	teamID, id, ok, err := parseAnnotationRoute(c)
+	if !ok {
+		return err
+	}
+
+	annotation, err := core.GetAnnotation(c.Context(), s.sqlite, teamID, id)
+	if err != nil {
+		return s.sendAnnotationError(c, err, "load")
+	}
+	return SendSuccess(c, fiber.StatusOK, annotation)
+}
+
+// handleUpdateAnnotation replaces an annotation's note, scope and range.
+func (s *Server) handleUpdateAnnotation(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, id, ok, err := parseAnnotationRoute(c)
+	if !ok {
+		return err
+	}
+
+	var req models.AnnotationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	annotation, err := core.UpdateAnnotation(c.Context(), s.sqlite, s.log, user, teamID, id, &req)
+	if err != nil {
+		return s.sendAnnotationError(c, err, "update")
+	}
+	return SendSuccess(c, fiber.StatusOK, annotation)
+}
+
+// handleDeleteAnnotation removes an annotation.
+func (s *Server) handleDeleteAnnotation(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, id, ok, err := parseAnnotationRoute(c)
+	if !ok {
+		return err
+	}
+
+	if err := core.DeleteAnnotation(c.Context(), s.sqlite, s.log, user, teamID, id); err != nil {
+		return s.sendAnnotationError(c, err, "delete")
+	}
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Annotation deleted"})
+}
+
+// attachHistogramAnnotations adds the annotations overlapping the histogram's
+// range to result. Annotations are decoration: without a complete range there
+// is nothing to overlap, and a failed lookup is logged and the histogram
+// returned without them.
+func (s *Server) attachHistogramAnnotations(ctx context.Context, teamID models.TeamID, sourceID models.SourceID, params core.HistogramParams, result *core.HistogramResponse) {
+	if result == nil || params.StartTime == nil || params.EndTime == nil {
+		return
+	}
+	annotations, err := core.ListHistogramAnnotations(ctx, s.sqlite, teamID, sourceID, *params.StartTime, *params.EndTime)
+	if err != nil {
+		s.log.Warn("failed to load histogram annotations", "error", err, "team_id", teamID, "source_id", sourceID)
+		return
+	}
+	result.Annotations = annotations
+}
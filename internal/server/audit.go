@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// auditQueryTextLimit caps the query text copied into a query.execute_sql
+// event's details so one huge query can't bloat the trail.
+const auditQueryTextLimit = 4096
+
+// recordAudit queues an audit event for the calling user. It never blocks or
+// fails the request: the write happens asynchronously and errors are logged by
+// the audit writer. teamID may be nil for resources that aren't team-scoped;
+// details, when non-nil, is stored as a JSON object.
+func (s *Server) recordAudit(c *fiber.Ctx, action models.AuditAction, resourceType, resourceID string, teamID *models.TeamID, details map[string]any) {
+	if s.audit == nil {
+		return
+	}
+	event := &models.AuditEvent{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		TeamID:       teamID,
+		IPAddress:    c.IP(),
+		CreatedAt:    time.Now().UTC(),
+	}
+	if user, ok := c.Locals("user").(*models.User); ok && user != nil {
+		userID := user.ID
+		event.UserID = &userID
+		event.UserEmail = user.Email
+	}
+	if len(details) > 0 {
+		raw, err := json.Marshal(details)
+		if err != nil {
+			s.log.Warn("failed to encode audit details", "error", err, "action", action)
+		} else {
+			event.Details = raw
+		}
+	}
+	s.audit.Record(event)
+}
+
+// auditTeamMemberID is the resource id of a team membership, "<team>:<user>".
+func auditTeamMemberID(teamID models.TeamID, userID models.UserID) string {
+	return fmt.Sprintf("%d:%d", teamID, userID)
+}
+
+// auditTeamSourceID is the resource ID of a team-source link, "<team>:<source>".
+func auditTeamSourceID(teamID models.TeamID, sourceID models.SourceID) string {
+	return fmt.Sprintf("%d:%d", teamID, sourceID)
+}
+
+// truncateAuditText caps query text at auditQueryTextLimit bytes, backing off
+// to a rune boundary.
+func truncateAuditText(text string) string {
+	if len(text) <= auditQueryTextLimit {
+		return text
+	}
+	cut := auditQueryTextLimit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "…"
+}
+
+// auditID formats a numeric resource id.
+func auditID[T ~int | ~int64](id T) string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// handleListAuditEvents lists audit events newest first, optionally filtered.
+// URL: GET /api/v1/admin/audit-events?user_id=&action=&resource_type=&team_id=&since=&until=&limit=&offset=
+// since/until are RFC3339 timestamps (since inclusive, until exclusive).
+// Requires: admin (requireAuth + requireAdmin) and audit:read token scope.
+func (s *Server) handleListAuditEvents(c *fiber.Ctx) error {
+	filter := models.AuditEventFilter{
+		Action:       models.AuditAction(c.Query("action")),
+		ResourceType: c.Query("resource_type"),
+		Limit:        models.AuditEventsDefaultLimit,
+	}
+
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid user_id", models.ValidationErrorType)
+		}
+		userID := models.UserID(id)
+		filter.UserID = &userID
+	}
+	if raw := c.Query("team_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team_id", models.ValidationErrorType)
+		}
+		teamID := models.TeamID(id)
+		filter.TeamID = &teamID
+	}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		raw := c.Query(p.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, fmt.Sprintf("Invalid %s: must be an RFC3339 timestamp", p.name), models.ValidationErrorType)
+		}
+		*p.dst = t
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid limit", models.ValidationErrorType)
+		}
+		filter.Limit = min(limit, models.AuditEventsMaxLimit)
+	}
+	if raw := c.Query("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid offset", models.ValidationErrorType)
+		}
+		filter.Offset = offset
+	}
+
+	events, err := s.sqlite.ListAuditEvents(c.Context(), filter)
+	if err != nil {
+		s.log.Error("failed to list audit events", "error", err)
+		return SendError(c, fiber.StatusInternalServerError, "Error listing audit events")
+	}
+	return SendSuccess(c, fiber.StatusOK, events)
+}
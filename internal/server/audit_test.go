@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/audit"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+type memoryAuditStore struct {
+	mu     sync.Mutex
+	events []*models.AuditEvent
+}
+
+func (m *memoryAuditStore) InsertAuditEvent(_ context.Context, e *models.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, e)
+	return nil
+}
+
+func (m *memoryAuditStore) ListAuditEvents(context.Context, models.AuditEventFilter) ([]*models.AuditEvent, error) {
+	return nil, nil
+}
+
+func TestRecordAuditCapturesActor(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mem := &memoryAuditStore{}
+	w := audit.NewWriter(audit.Options{Store: mem, Logger: logger})
+	w.Start()
+	s := &Server{audit: w, log: logger}
+
+	teamID := models.TeamID(3)
+	app := fiber.New()
+	app.Post("/probe", func(c *fiber.Ctx) error {
+		c.Locals("user", &models.User{ID: 42, Email: "ops@test.dev"})
+		s.recordAudit(c, models.AuditActionTeamMemberAdd, models.AuditResourceTeamMember, auditTeamMemberID(teamID, 7), &teamID, map[string]any{"role": "editor"})
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/probe", http.NoBody))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	resp.Body.Close()
+	w.Stop()
+
+	if len(mem.events) != 1 {
+		t.Fatalf("recorded %d events, want 1", len(mem.events))
+	}
+	e := mem.events[0]
+	if e.UserID == nil || *e.UserID != 42 || e.UserEmail != "ops@test.dev" {
+		t.Errorf("actor not captured: %+v", e)
+	}
+	if e.ResourceID != "3:7" || e.TeamID == nil || *e.TeamID != teamID || e.IPAddress == "" || e.CreatedAt.IsZero() {
+		t.Errorf("unexpected event: %+v", e)
+	}
+	if string(e.Details) != `{"role":"editor"}` {
+		t.Errorf("details = %s", e.Details)
+	}
+}
+
+func TestTruncateAuditText(t *testing.T) {
+	t.Parallel()
+
+	if got := truncateAuditText("SELECT 1"); got != "SELECT 1" {
+		t.Fatalf("short text changed: %q", got)
+	}
+	long := strings.Repeat("é", auditQueryTextLimit) // 2 bytes per rune
+	got := truncateAuditText(long)
+	if len(got) > auditQueryTextLimit+len("…") || !utf8.ValidString(got) {
+		t.Fatalf("truncated to %d bytes, valid=%v", len(got), utf8.ValidString(got))
+	}
+}
+
+func TestListAuditEventsRejectsBadFilters(t *testing.T) {
+	t.Parallel()
+
+	s := &Server{}
+	app := fiber.New()
+	app.Get("/audit-events", s.handleListAuditEvents)
+
+	for _, q := range []string{"user_id=abc", "team_id=0", "since=yesterday", "until=2026-01-01", "limit=-1", "offset=x"} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/audit-events?"+q, http.NoBody))
+		if err != nil {
+			t.Fatalf("app.Test(%s): %v", q, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, resp.StatusCode)
+		}
+	}
+}
@@ -378,6 +378,8 @@ func (s *Server) handleCLITokenExchange(c *fiber.Ctx) error {
 		return SendErrorWithType(c, fiber.StatusUnauthorized, "User account is inactive", models.AuthenticationErrorType)
 	}
 
+	auth.SyncGroupTeams(c.Context(), s.sqlite, s.log, s.config.Auth.GroupSync, user, idToken)
+
 	// Create a new API token for CLI use
 	tokenName := fmt.Sprintf("CLI Token (created %s)", time.Now().Format("2006-01-02 15:04"))
 	// Set expiration to 30 days from now
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// handleBackupDatabase downloads a consistent snapshot of the metadata
+// database, taken online without pausing the server.
+// URL: POST /api/v1/admin/backup
+// Requires: Admin privileges
+func (s *Server) handleBackupDatabase(c *fiber.Ctx) error {
+	backuper, ok := s.sqlite.(store.Backuper)
+	if !ok {
+		return SendErrorWithType(c, fiber.StatusNotImplemented, "Online backup is only available for the SQLite backend; back up Postgres with pg_dump", models.ValidationErrorType)
+	}
+
+	dir, err := os.MkdirTemp("", "logchef-backup-")
+	if err != nil {
+		s.log.Error("failed to create backup directory", "error", err)
+		return SendError(c, fiber.StatusInternalServerError, "Failed to back up database")
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Now().UTC()
+	name := fmt.Sprintf("logchef-%s.db", now.Format("20060102-150405"))
+	path := filepath.Join(dir, name)
+	if err := backuper.Backup(c.Context(), path); err != nil {
+		s.log.Error("failed to back up database", "error", err)
+		return SendError(c, fiber.StatusInternalServerError, "Failed to back up database")
+	}
+
+	// Stream from an open handle: the temp directory is removed when the
+	// handler returns, but the body is written after that.
+	f, err := os.Open(path)
+	if err != nil {
+		s.log.Error("failed to open database backup", "error", err)
+		return SendError(c, fiber.StatusInternalServerError, "Failed to back up database")
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		s.log.Error("failed to stat database backup", "error", err)
+		return SendError(c, fiber.StatusInternalServerError, "Failed to back up database")
+	}
+
+	s.recordAudit(c, models.AuditActionDatabaseBackup, models.AuditResourceDatabase, "", nil, map[string]any{
+		"bytes": info.Size(),
+	})
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEOctetStream)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, name))
+	return c.Status(fiber.StatusOK).SendStream(f, int(info.Size()))
+}
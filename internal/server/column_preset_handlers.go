@@ -0,0 +1,124 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/pkg/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sendColumnPresetError maps core column preset errors onto responses.
+func (s *Server) sendColumnPresetError(c *fiber.Ctx, err error, action string) error {
+	switch {
+	case errors.Is(err, core.ErrInvalidColumnPreset):
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	case errors.Is(err, core.ErrColumnPresetNotFound):
+		return SendErrorWithType(c, fiber.StatusNotFound, "Column preset not found", models.NotFoundErrorType)
+	default:
+		s.log.Error("failed to "+action+" column preset", "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to "+action+" column preset", models.GeneralErrorType)
+	}
+}
+
+// parseColumnPresetRoute reads the :teamID and :sourceID route params. When ok
+// is false the error response has already been written and err should be
+// returned as-is.
+func parseColumnPresetRoute(c *fiber.Ctx) (teamID models.TeamID, sourceID models.SourceID, ok bool, err error) {
+	teamID, err = core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return 0, 0, false, SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	sourceID, err = core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return 0, 0, false, SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID format", models.ValidationErrorType)
+	}
+	return teamID, sourceID, true, nil
+}
+
+// handleGetColumnPresets returns the caller's column layout for a source, the
+// team's default, and which of the two applies.
+func (s *Server) handleGetColumnPresets(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, sourceID, ok, err := parseColumnPresetRoute(c)
+	if !ok {
+		return err
+	}
+
+	presets, err := core.GetColumnPresets(c.Context(), s.sqlite, user.ID, teamID, sourceID)
+	if err != nil {
+		return s.sendColumnPresetError(c, err, "load")
+	}
+	return SendSuccess(c, fiber.StatusOK, presets)
+}
+
+// handleSaveUserColumnPreset replaces the caller's column layout for a source.
+func (s *Server) handleSaveUserColumnPreset(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	_, sourceID, ok, err := parseColumnPresetRoute(c)
+	if !ok {
+		return err
+	}
+
+	var req models.ColumnPresetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	preset, err := core.SaveUserColumnPreset(c.Context(), s.sqlite, s.log, user.ID, sourceID, &req)
+	if err != nil {
+		return s.sendColumnPresetError(c, err, "save")
+	}
+	return SendSuccess(c, fiber.StatusOK, preset)
+}
+
+// handleDeleteUserColumnPreset resets the caller's column layout for a source
+// back to the team default.
+func (s *Server) handleDeleteUserColumnPreset(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	_, sourceID, ok, err := parseColumnPresetRoute(c)
+	if !ok {
+		return err
+	}
+
+	if err := core.DeleteUserColumnPreset(c.Context(), s.sqlite, s.log, user.ID, sourceID); err != nil {
+		return s.sendColumnPresetError(c, err, "delete")
+	}
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Column preset deleted"})
+}
+
+// handleSaveTeamColumnPreset replaces the team's default column layout for a
+// source.
+func (s *Server) handleSaveTeamColumnPreset(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, sourceID, ok, err := parseColumnPresetRoute(c)
+	if !ok {
+		return err
+	}
+
+	var req models.ColumnPresetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	preset, err := core.SaveTeamColumnPreset(c.Context(), s.sqlite, s.log, user, teamID, sourceID, &req)
+	if err != nil {
+		return s.sendColumnPresetError(c, err, "save")
+	}
+	return SendSuccess(c, fiber.StatusOK, preset)
+}
+
+// handleDeleteTeamColumnPreset removes the team's default column layout for a
+// source.
+func (s *Server) handleDeleteTeamColumnPreset(c *fiber.Ctx) error {
+	teamID, sourceID, ok, err := parseColumnPresetRoute(c)
+	if !ok {
+		return err
+	}
+
+	if err := core.DeleteTeamColumnPreset(c.Context(), s.sqlite, s.log, teamID, sourceID); err != nil {
+		return s.sendColumnPresetError(c, err, "delete")
+	}
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Column preset deleted"})
+}
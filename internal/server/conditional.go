@@ -0,0 +1,92 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// SendSuccessCacheable sends a success response that clients can revalidate
+// with a conditional request instead of downloading it again. It is meant for
+// metadata the UI refetches on every navigation (teams, sources, schemas), not
+// for query results.
+//
+// The ETag hashes the encoded body, so any change to the payload changes it,
+// including changes that don't move an updated_at (a deleted row, a source's
+// live connection status). lastModified is the newest updated_at behind the
+// payload, or the zero time when there is none. A deletion doesn't move it,
+// which is why If-None-Match (browsers send both) takes precedence. Responses
+// are marked no-cache, so clients revalidate every time rather than reuse a
+// stale copy.
+func SendSuccessCacheable(c *fiber.Ctx, data any, lastModified time.Time) error {
+	return sendCacheable(c, NewSuccessResponse(data), lastModified)
+}
+
+// SendListCacheable is SendList with SendSuccessCacheable's revalidation.
+func SendListCacheable(c *fiber.Ctx, items any, page *models.Pagination, lastModified time.Time) error {
+	resp := NewSuccessResponse(items)
+	resp.Pagination = page
+	return sendCacheable(c, resp, lastModified)
+}
+
+func sendCacheable(c *fiber.Ctx, resp Response, lastModified time.Time) error {
+	body, err := c.App().Config().JSONEncoder(resp)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+	if !lastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+	if notModified(c, etag, lastModified) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(fiber.StatusOK).Send(body)
+}
+
+// notModified evaluates the request's preconditions against the response's
+// validators. If-None-Match wins when present (RFC 9110 §13.2.2); only
+// without it is If-Modified-Since consulted.
+func notModified(c *fiber.Ctx, etag string, lastModified time.Time) bool {
+	if noneMatch := c.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		for candidate := range strings.SplitSeq(noneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(c.Get(fiber.HeaderIfModifiedSince))
+	if err != nil {
+		return false
+	}
+	// HTTP dates have second precision.
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// latestUpdate returns the newest of the items' update times.
+func latestUpdate[T any](items []T, updatedAt func(T) time.Time) time.Time {
+	var latest time.Time
+	for _, item := range items {
+		if t := updatedAt(item); t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSendSuccessCacheable(t *testing.T) {
+	t.Parallel()
+
+	updated := time.Date(2026, 3, 1, 12, 0, 0, 500_000_000, time.UTC)
+	payload := []string{"team-a"}
+	app := fiber.New()
+	app.Get("/teams", func(c *fiber.Ctx) error {
+		return SendSuccessCacheable(c, payload, updated)
+	})
+	get := func(headers map[string]string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/teams", http.NoBody)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	first := get(nil)
+	etag := first.Header.Get(fiber.HeaderETag)
+	if first.StatusCode != fiber.StatusOK || etag == "" {
+		t.Fatalf("first response: status %d, etag %q", first.StatusCode, etag)
+	}
+	if got := first.Header.Get(fiber.HeaderLastModified); got != "Sun, 01 Mar 2026 12:00:00 GMT" {
+		t.Errorf("Last-Modified = %q", got)
+	}
+
+	cases := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"matching etag", map[string]string{fiber.HeaderIfNoneMatch: etag}, fiber.StatusNotModified},
+		{"etag in a list", map[string]string{fiber.HeaderIfNoneMatch: `"other", ` + etag}, fiber.StatusNotModified},
+		{"stale etag", map[string]string{fiber.HeaderIfNoneMatch: `W/"other"`}, fiber.StatusOK},
+		{"not modified since", map[string]string{fiber.HeaderIfModifiedSince: "Sun, 01 Mar 2026 12:00:00 GMT"}, fiber.StatusNotModified},
+		{"modified since", map[string]string{fiber.HeaderIfModifiedSince: "Sun, 01 Mar 2026 11:59:59 GMT"}, fiber.StatusOK},
+		// If-None-Match wins: a stale etag is a miss even if the date matches.
+		{"stale etag, fresh date", map[string]string{
+			fiber.HeaderIfNoneMatch:     `W/"other"`,
+			fiber.HeaderIfModifiedSince: "Sun, 01 Mar 2026 12:00:00 GMT",
+		}, fiber.StatusOK},
+	}
+	for _, tc := range cases {
+		if got := get(tc.headers).StatusCode; got != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.name, got, tc.want)
+		}
+	}
+
+	// A change to the payload (say, a deleted team) misses the old etag.
+	payload = []string{}
+	if got := get(map[string]string{fiber.HeaderIfNoneMatch: etag}).StatusCode; got != fiber.StatusOK {
+		t.Errorf("changed payload: status %d, want 200", got)
+	}
+}
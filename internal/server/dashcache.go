@@ -5,7 +5,9 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -62,6 +64,47 @@ func canonCacheTime(t *time.Time) string {
 	return t.UTC().Format(time.RFC3339Nano)
 }
 
+// derivedColumnsCacheSuffix encodes a request's derived columns for the cache
+// key. They change the executed SELECT, so they are part of the finalized
+// query; a request without them keeps its key unchanged.
+func derivedColumnsCacheSuffix(cols []models.DerivedColumn) string {
+	var b strings.Builder
+	for _, col := range cols {
+		fmt.Fprintf(&b, "\x00%d:%s%d:%s", len(col.Name), col.Name, len(col.Expression), col.Expression)
+	}
+	return b.String()
+}
+
+// sampleCacheSuffix keys a sampled query apart from its unsampled run.
+func sampleCacheSuffix(ratio float64) string {
+	if ratio == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\x00sample=%g", ratio)
+}
+
+// dedupeCacheSuffix keys a deduped query apart from its plain run and from
+// runs deduped on other fields.
+func dedupeCacheSuffix(opts *models.DedupeOptions) string {
+	if opts == nil {
+		return ""
+	}
+	return "\x00dedupe=" + strings.Join(opts.Fields, ",")
+}
+
+// distributedCacheSuffix keys a query routed to one shard or replica apart
+// from its run across the whole cluster.
+func distributedCacheSuffix(routing *models.DistributedRouting) string {
+	if routing == nil {
+		return ""
+	}
+	prefer := "default"
+	if routing.PreferLocalhostReplica != nil {
+		prefer = strconv.FormatBool(*routing.PreferLocalhostReplica)
+	}
+	return fmt.Sprintf("\x00shard=%d,prefer_localhost_replica=%s", routing.Shard, prefer)
+}
+
 // writeCachedBytes writes an already-encoded JSON response body with the cache
 // status header, adding Age on a HIT. The body is byte-identical to what the
 // uncached path for the same backend would have produced.
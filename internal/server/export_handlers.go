@@ -17,7 +17,6 @@ import (
 	"github.com/mr-karan/logchef/internal/clickhouse"
 	"github.com/mr-karan/logchef/internal/core"
 	"github.com/mr-karan/logchef/internal/datasource"
-	"github.com/mr-karan/logchef/internal/template"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
@@ -40,6 +39,34 @@ type exportLogsRequest struct {
 	Limit        int                       `json:"limit"`
 	QueryTimeout *int                      `json:"query_timeout,omitempty"`
 	Variables    []models.TemplateVariable `json:"variables,omitempty"`
+	// StartTime and EndTime (RFC3339) are the range ANDed into a query that
+	// doesn't filter on the source's timestamp when query.require_time_bound
+	// is set.
+	StartTime string `json:"start_time,omitempty"`
+	EndTime   string `json:"end_time,omitempty"`
+}
+
+// buildExportQuery builds an export of query on source, capped at the
+// configured export rows. With query.require_time_bound set, the query must
+// filter on the source's timestamp column or gets req's time range ANDed in.
+func (s *Server) buildExportQuery(source *models.Source, query string, req exportLogsRequest) (clickhouse.QueryBuildResult, error) {
+	exportLimit := req.Limit
+	if exportLimit <= 0 {
+		exportLimit = s.config.Export.MaxRows
+	}
+	if exportLimit > s.config.Export.MaxRows {
+		exportLimit = s.config.Export.MaxRows
+	}
+
+	qb := clickhouse.NewExtendedQueryBuilder(source.GetFullTableName(), s.config.Export.MaxRows)
+	if s.config.Query.RequireTimeBound && source.MetaTSField != "" {
+		startTime, endTime, err := parseRFC3339TimeRange(req.StartTime, req.EndTime)
+		if err != nil {
+			return clickhouse.QueryBuildResult{}, err
+		}
+		qb.WithTimeBound(source.MetaTSField, startTime, endTime)
+	}
+	return qb.BuildRawQueryWithLimitPolicy(query, req.Limit, exportLimit, s.config.Export.MaxRows)
 }
 
 func (s *Server) handleExportLogs(c *fiber.Ctx) error { //nolint:gocyclo // request handler, inherently branchy
@@ -70,6 +97,11 @@ func (s *Server) handleExportLogs(c *fiber.Ctx) error { //nolint:gocyclo // requ
 	if !source.HasCapability(string(datasource.CapabilityExports)) {
 		return SendErrorWithType(c, fiber.StatusBadRequest, "Exports are not supported for this source type yet", models.ValidationErrorType)
 	}
+	// Exports run the caller's SQL as written, so they follow the team's raw
+	// SQL switch like /logs/query.
+	if denied, err := s.denyRawSQL(c, teamID); denied {
+		return err
+	}
 
 	var req exportLogsRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -113,47 +145,26 @@ func (s *Server) handleExportLogs(c *fiber.Ctx) error { //nolint:gocyclo // requ
 			models.ValidationErrorType)
 	}
 
-	processedSQL := req.RawSQL
-	if len(req.Variables) > 0 {
-		vars := make([]template.Variable, len(req.Variables))
-		for i, v := range req.Variables {
-			vars[i] = template.Variable{
-				Name:  v.Name,
-				Type:  template.VariableType(v.Type),
-				Value: v.Value,
-			}
-		}
-		substituted, err := template.SubstituteVariables(req.RawSQL, vars)
-		if err != nil {
-			return SendErrorWithType(c, fiber.StatusBadRequest,
-				fmt.Sprintf("Variable substitution failed: %v", err), models.ValidationErrorType)
-		}
-		processedSQL = substituted
-	}
-
-	client, err := s.clickhouse.GetConnection(sourceID)
+	processedSQL, err := s.substituteVariables(c.Context(), sourceID, req.RawSQL, req.Variables)
 	if err != nil {
-		s.log.Error("failed to get clickhouse client for export", "source_id", sourceID, "error", err)
-		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get source connection", models.DatabaseErrorType)
+		return SendErrorWithType(c, fiber.StatusBadRequest,
+			fmt.Sprintf("Variable substitution failed: %v", err), models.ValidationErrorType)
 	}
 
-	exportLimit := req.Limit
-	if exportLimit <= 0 {
-		exportLimit = s.config.Export.MaxRows
-	}
-	if exportLimit > s.config.Export.MaxRows {
-		exportLimit = s.config.Export.MaxRows
+	buildResult, err := s.buildExportQuery(source, processedSQL, req)
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err), models.ValidationErrorType)
 	}
 
-	qb := clickhouse.NewExtendedQueryBuilder(source.GetFullTableName(), s.config.Export.MaxRows)
-	buildResult, err := qb.BuildRawQueryWithLimitPolicy(processedSQL, req.Limit, exportLimit, s.config.Export.MaxRows)
+	client, err := s.clickhouse.GetConnection(sourceID)
 	if err != nil {
-		return SendErrorWithType(c, fiber.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err), models.ValidationErrorType)
+		s.log.Error("failed to get clickhouse client for export", "source_id", sourceID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get source connection", models.DatabaseErrorType)
 	}
 
 	queryID := uuid.New().String()
 	streamCtx, cancel := context.WithCancel(c.Context())
-	if err := queryTracker.StartQueryWithID(
+	if err := s.queries.StartQueryWithID(
 		queryID,
 		QueryClassExport,
 		user.ID,
@@ -162,15 +173,13 @@ func (s *Server) handleExportLogs(c *fiber.Ctx) error { //nolint:gocyclo // requ
 		req.RawSQL,
 		cancel,
 		s.config.Export.MaxConcurrentPerUser,
+		0,
 		s.config.Export.MaxConcurrentGlobal,
 	); err != nil {
 		cancel()
-		var admissionErr *QueryAdmissionError
-		if errors.As(err, &admissionErr) {
-			return SendErrorWithType(c, fiber.StatusTooManyRequests, admissionErr.Message, models.ValidationErrorType)
-		}
-		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to track export query", models.GeneralErrorType)
+		return sendAdmissionError(c, err, "Failed to track export query")
 	}
+	streamCtx = clickhouse.ContextWithQueryID(streamCtx, queryID)
 
 	opts := clickhouse.QueryOptions{
 		TimeoutSeconds: req.QueryTimeout,
@@ -193,7 +202,7 @@ func (s *Server) handleExportLogs(c *fiber.Ctx) error { //nolint:gocyclo // requ
 
 	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
 		defer cancel()
-		defer queryTracker.RemoveQuery(queryID)
+		defer s.queries.RemoveQuery(queryID)
 
 		writer := newExportRowWriter(format, w, queryID, buildResult.AppliedLimit)
 		stats, err := client.QueryStream(streamCtx, buildResult.SQL, opts, writer)
@@ -1,11 +1,17 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/config"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
@@ -139,3 +145,78 @@ func TestExportJobURLsAreRelativePaths(t *testing.T) {
 		t.Fatalf("DownloadURL should be a relative path, got %q", resp.DownloadURL)
 	}
 }
+
+func TestBuildExportQueryTimeBound(t *testing.T) {
+	t.Parallel()
+
+	start := "2026-01-01T00:00:00Z"
+	end := "2026-01-01T01:00:00Z"
+	bound := "(`timestamp` BETWEEN toDateTime64('2026-01-01 00:00:00.000', 3, 'UTC') AND toDateTime64('2026-01-01 01:00:00.000', 3, 'UTC'))"
+	tests := []struct {
+		name     string
+		required bool
+		query    string
+		req      exportLogsRequest
+		want     string
+		wantNot  string
+		wantErr  bool
+	}{
+		{name: "not required", query: "SELECT * FROM logs.app", req: exportLogsRequest{StartTime: start, EndTime: end}, wantNot: "BETWEEN"},
+		{name: "range injected", required: true, query: "SELECT * FROM logs.app WHERE level = 'error'", req: exportLogsRequest{StartTime: start, EndTime: end}, want: "(level = 'error') AND " + bound},
+		{name: "own bound kept", required: true, query: "SELECT * FROM logs.app WHERE timestamp > now() - INTERVAL 1 HOUR", req: exportLogsRequest{StartTime: start, EndTime: end}, wantNot: "BETWEEN"},
+		{name: "no range rejected", required: true, query: "SELECT * FROM logs.app", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Server{config: &config.Config{
+				Export: config.ExportConfig{MaxRows: 1000},
+				Query:  config.QueryConfig{RequireTimeBound: tc.required},
+			}}
+			source := &models.Source{MetaTSField: "timestamp", Connection: models.ConnectionInfo{Database: "logs", TableName: "app"}}
+
+			got, err := s.buildExportQuery(source, tc.query, tc.req)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("buildExportQuery() = %q, error = %v, wantErr %v", got.SQL, err, tc.wantErr)
+			}
+			if tc.want != "" && !strings.Contains(got.SQL, tc.want) {
+				t.Errorf("SQL = %q, want it to contain %q", got.SQL, tc.want)
+			}
+			if tc.wantNot != "" && strings.Contains(got.SQL, tc.wantNot) {
+				t.Errorf("SQL = %q, want no %q", got.SQL, tc.wantNot)
+			}
+		})
+	}
+}
+
+// Both export paths refuse a query without a time bound before touching
+// ClickHouse or queueing a job.
+func TestExportsRejectUnboundedQuery(t *testing.T) {
+	for _, route := range []struct {
+		name    string
+		handler func(*Server) fiber.Handler
+	}{
+		{"logs export", func(s *Server) fiber.Handler { return s.handleExportLogs }},
+		{"export job", func(s *Server) fiber.Handler { return s.handleCreateExportJob }},
+	} {
+		t.Run(route.name, func(t *testing.T) {
+			s, member, team, src := newRawSQLTestServer(t, true)
+			s.config = &config.Config{
+				Export: config.ExportConfig{MaxRows: 1000, DefaultTimeoutSeconds: 30, Formats: []string{"csv", "ndjson"}},
+				Query:  config.QueryConfig{RequireTimeBound: true},
+			}
+			src.MetaTSField = "timestamp"
+			if err := s.sqlite.UpdateSource(context.Background(), src); err != nil {
+				t.Fatalf("UpdateSource: %v", err)
+			}
+			app := fiber.New()
+			withUser(app, http.MethodPost, "/teams/:teamID/sources/:sourceID/export", member, route.handler(s))
+
+			path := fmt.Sprintf("/teams/%d/sources/%d/export", team.ID, src.ID)
+			body := fmt.Sprintf(`{"raw_sql":"SELECT * FROM %s","format":"ndjson"}`, src.GetFullTableName())
+			if got := doRawSQLRequest(t, app, http.MethodPost, path, body); got != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", got, http.StatusBadRequest)
+			}
+		})
+	}
+}
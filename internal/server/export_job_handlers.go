@@ -7,16 +7,17 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
+	"github.com/mr-karan/logchef/internal/artifacts"
 	"github.com/mr-karan/logchef/internal/clickhouse"
 	"github.com/mr-karan/logchef/internal/core"
 	"github.com/mr-karan/logchef/internal/datasource"
-	"github.com/mr-karan/logchef/internal/template"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
@@ -48,6 +49,9 @@ func (s *Server) handleCreateExportJob(c *fiber.Ctx) error {
 	if !source.HasCapability(string(datasource.CapabilityExports)) {
 		return SendErrorWithType(c, fiber.StatusBadRequest, "Exports are not supported for this source type yet", models.ValidationErrorType)
 	}
+	if denied, err := s.denyRawSQL(c, teamID); denied {
+		return err
+	}
 
 	var req models.CreateExportJobRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -86,6 +90,26 @@ func (s *Server) handleCreateExportJob(c *fiber.Ctx) error {
 			models.ValidationErrorType)
 	}
 
+	runReq := exportLogsRequest{
+		RawSQL:       req.RawSQL,
+		Format:       format,
+		Limit:        req.Limit,
+		QueryTimeout: req.QueryTimeout,
+		Variables:    req.Variables,
+		StartTime:    req.StartTime,
+		EndTime:      req.EndTime,
+	}
+	// Build the query once up front so a query the job could never run, such
+	// as one missing a required time bound, is a 400 rather than a failed job.
+	processedSQL, err := s.substituteVariables(c.Context(), sourceID, req.RawSQL, req.Variables)
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest,
+			fmt.Sprintf("Variable substitution failed: %v", err), models.ValidationErrorType)
+	}
+	if _, err := s.buildExportQuery(source, processedSQL, runReq); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err), models.ValidationErrorType)
+	}
+
 	payload, err := json.Marshal(req)
 	if err != nil {
 		s.log.Error("failed to marshal export job request", "error", err)
@@ -108,7 +132,7 @@ func (s *Server) handleCreateExportJob(c *fiber.Ctx) error {
 	// Admit synchronously so a saturated cap returns 429 to the client
 	// instead of accepting the job and async-failing it.
 	queryCtx, cancel := context.WithCancel(context.Background())
-	if err := queryTracker.StartQueryWithID(
+	if err := s.queries.StartQueryWithID(
 		job.ID,
 		QueryClassExport,
 		user.ID,
@@ -117,30 +141,21 @@ func (s *Server) handleCreateExportJob(c *fiber.Ctx) error {
 		req.RawSQL,
 		cancel,
 		s.config.Export.MaxConcurrentPerUser,
+		0,
 		s.config.Export.MaxConcurrentGlobal,
 	); err != nil {
 		cancel()
-		var admissionErr *QueryAdmissionError
-		if errors.As(err, &admissionErr) {
-			return SendErrorWithType(c, fiber.StatusTooManyRequests, admissionErr.Message, models.ValidationErrorType)
-		}
-		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to track export query", models.GeneralErrorType)
+		return sendAdmissionError(c, err, "Failed to track export query")
 	}
+	queryCtx = clickhouse.ContextWithQueryID(queryCtx, job.ID)
 
 	if err := s.sqlite.CreateExportJob(c.Context(), job); err != nil {
-		queryTracker.RemoveQuery(job.ID)
+		s.queries.RemoveQuery(job.ID)
 		cancel()
 		s.log.Error("failed to persist export job", "error", err, "job_id", job.ID)
 		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to create export job", models.GeneralErrorType)
 	}
 
-	runReq := exportLogsRequest{
-		RawSQL:       req.RawSQL,
-		Format:       format,
-		Limit:        req.Limit,
-		QueryTimeout: req.QueryTimeout,
-		Variables:    req.Variables,
-	}
 	go s.runExportJob(job.ID, queryCtx, cancel, teamID, sourceID, user.Email, runReq)
 
 	return SendSuccess(c, fiber.StatusAccepted, exportJobResponse(teamID, job))
@@ -186,6 +201,26 @@ func (s *Server) handleDownloadExportJob(c *fiber.Ctx) error {
 		_ = s.sqlite.FailExportJob(c.Context(), job.ID, "export artifact is unavailable", time.Now().UTC())
 		return SendErrorWithType(c, fiber.StatusInternalServerError, "Export artifact is unavailable", models.GeneralErrorType)
 	}
+	if filepath.IsAbs(job.FilePath) {
+		return s.downloadLegacyExportFile(c, job)
+	}
+
+	contentType, _ := exportContentType(job.Format)
+	err = s.sendArtifact(c, job.FilePath, job.FileName, contentType, job.BytesWritten)
+	if errors.Is(err, artifacts.ErrNotFound) {
+		_ = s.sqlite.FailExportJob(c.Context(), job.ID, "export artifact is unavailable", time.Now().UTC())
+	}
+	if err != nil {
+		s.log.Error("failed to serve export artifact", "error", err, "job_id", job.ID, "key", job.FilePath)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Export artifact is unavailable", models.GeneralErrorType)
+	}
+	return nil
+}
+
+// downloadLegacyExportFile serves an artifact written before exports moved to
+// artifact storage, when file_path held an absolute temp-file path. Such rows
+// age out within export.artifact_ttl of upgrading.
+func (s *Server) downloadLegacyExportFile(c *fiber.Ctx, job *models.ExportJob) error {
 	if _, err := os.Stat(job.FilePath); err != nil {
 		s.log.Error("failed to stat export artifact", "error", err, "job_id", job.ID, "path", job.FilePath)
 		if errors.Is(err, os.ErrNotExist) {
@@ -193,7 +228,6 @@ func (s *Server) handleDownloadExportJob(c *fiber.Ctx) error {
 		}
 		return SendErrorWithType(c, fiber.StatusInternalServerError, "Export artifact is unavailable", models.GeneralErrorType)
 	}
-
 	return c.Download(job.FilePath, job.FileName)
 }
 
@@ -234,11 +268,11 @@ func (s *Server) authorizeExportJob(c *fiber.Ctx) (*models.ExportJob, error) {
 }
 
 // runExportJob runs the export pipeline. The caller must have already
-// reserved an admission slot via queryTracker.StartQueryWithID — this
+// reserved an admission slot via s.queries.StartQueryWithID — this
 // function takes ownership and releases it on exit.
 func (s *Server) runExportJob(jobID string, queryCtx context.Context, cancel context.CancelFunc, teamID models.TeamID, sourceID models.SourceID, userEmail string, req exportLogsRequest) {
 	defer cancel()
-	defer queryTracker.RemoveQuery(jobID)
+	defer s.queries.RemoveQuery(jobID)
 	// This runs in its own goroutine: a panic here would crash the whole
 	// process (Fiber's recover middleware only guards request handlers).
 	// Recover, log, and mark the job failed instead.
@@ -259,22 +293,10 @@ func (s *Server) runExportJob(jobID string, queryCtx context.Context, cancel con
 		return
 	}
 
-	processedSQL := req.RawSQL
-	if len(req.Variables) > 0 {
-		vars := make([]template.Variable, len(req.Variables))
-		for i, v := range req.Variables {
-			vars[i] = template.Variable{
-				Name:  v.Name,
-				Type:  template.VariableType(v.Type),
-				Value: v.Value,
-			}
-		}
-		substituted, err := template.SubstituteVariables(req.RawSQL, vars)
-		if err != nil {
-			s.failExportJob(bgCtx, jobID, "", fmt.Sprintf("Variable substitution failed: %v", err))
-			return
-		}
-		processedSQL = substituted
+	processedSQL, err := s.substituteVariables(bgCtx, sourceID, req.RawSQL, req.Variables)
+	if err != nil {
+		s.failExportJob(bgCtx, jobID, "", fmt.Sprintf("Variable substitution failed: %v", err))
+		return
 	}
 
 	source, err := s.sqlite.GetSource(bgCtx, sourceID)
@@ -289,16 +311,7 @@ func (s *Server) runExportJob(jobID string, queryCtx context.Context, cancel con
 		return
 	}
 
-	exportLimit := req.Limit
-	if exportLimit <= 0 {
-		exportLimit = s.config.Export.MaxRows
-	}
-	if exportLimit > s.config.Export.MaxRows {
-		exportLimit = s.config.Export.MaxRows
-	}
-
-	qb := clickhouse.NewExtendedQueryBuilder(source.GetFullTableName(), s.config.Export.MaxRows)
-	buildResult, err := qb.BuildRawQueryWithLimitPolicy(processedSQL, req.Limit, exportLimit, s.config.Export.MaxRows)
+	buildResult, err := s.buildExportQuery(source, processedSQL, req)
 	if err != nil {
 		s.failExportJob(bgCtx, jobID, "", fmt.Sprintf("Invalid request: %v", err))
 		return
@@ -339,17 +352,23 @@ func (s *Server) runExportJob(jobID string, queryCtx context.Context, cancel con
 		return
 	}
 
-	info, err := os.Stat(filePath)
+	// The query streams into a local temp file; only the finished artifact
+	// goes to artifact storage.
+	key := fmt.Sprintf("exports/%s.%s", jobID, extension)
+	size, err := s.storeExportArtifact(bgCtx, filePath, key, req.Format)
+	if removeErr := os.Remove(filePath); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+		s.log.Warn("failed to remove export temp file", "error", removeErr, "job_id", jobID, "path", filePath)
+	}
 	if err != nil {
-		s.log.Error("failed to stat export artifact", "error", err, "job_id", jobID, "path", filePath)
-		s.failExportJob(bgCtx, jobID, filePath, "Failed to finalize export artifact")
+		s.log.Error("failed to store export artifact", "error", err, "job_id", jobID, "backend", s.artifacts.Name())
+		s.failExportJob(bgCtx, jobID, key, "Failed to store export artifact")
 		return
 	}
 
 	completedAt := time.Now().UTC()
-	if err := s.sqlite.CompleteExportJob(bgCtx, jobID, fileName, filePath, stats.RowsReturned, info.Size(), completedAt); err != nil {
+	if err := s.sqlite.CompleteExportJob(bgCtx, jobID, fileName, key, stats.RowsReturned, size, completedAt); err != nil {
 		s.log.Error("failed to complete export job", "error", err, "job_id", jobID)
-		s.failExportJob(bgCtx, jobID, filePath, "Failed to persist export metadata")
+		s.failExportJob(bgCtx, jobID, key, "Failed to persist export metadata")
 		return
 	}
 
@@ -362,13 +381,32 @@ func (s *Server) runExportJob(jobID string, queryCtx context.Context, cancel con
 		"rows", stats.RowsReturned,
 		"duration_ms", stats.ExecutionTimeMs,
 		"limit_applied", stats.LimitApplied,
-		"bytes_written", info.Size(),
+		"bytes_written", size,
 	)
 }
 
+// storeExportArtifact uploads the finished export at path to artifact storage
+// under key and returns its size.
+func (s *Server) storeExportArtifact(ctx context.Context, path, key, format string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	contentType, _ := exportContentType(format)
+	if err := s.artifacts.Put(ctx, key, f, info.Size(), contentType); err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
 func (s *Server) failExportJob(ctx context.Context, jobID, filePath, message string) {
 	if filePath != "" {
-		if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		if err := s.removeExportArtifact(ctx, filePath); err != nil {
 			s.log.Warn("failed to remove partial export artifact", "error", err, "job_id", jobID, "path", filePath)
 		}
 	}
@@ -377,6 +415,19 @@ func (s *Server) failExportJob(ctx context.Context, jobID, filePath, message str
 	}
 }
 
+// removeExportArtifact deletes an export artifact: an artifact storage key, or
+// a local temp file (an in-progress export, or a row written before exports
+// moved to artifact storage).
+func (s *Server) removeExportArtifact(ctx context.Context, path string) error {
+	if filepath.IsAbs(path) {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	return s.artifacts.Delete(ctx, path)
+}
+
 func exportFailureMessage(err error) string {
 	var admissionErr *QueryAdmissionError
 	if errors.As(err, &admissionErr) {
@@ -438,24 +489,41 @@ func (s *Server) cleanupExpiredBackgroundState() {
 		s.log.Warn("failed to prune expired query shares", "error", err)
 	}
 
-	// Unlink files first, then delete rows. If the process dies between
-	// the two steps, the next cycle re-lists the same rows and ignores
-	// already-removed files (ErrNotExist), so no artifact is orphaned.
+	// Delete artifacts first, then rows. If the process dies between the
+	// two steps, the next cycle re-lists the same rows and deleting an
+	// already-removed artifact is a no-op, so no artifact is orphaned.
+	s.cleanupExpiredExports(ctx, now)
+	s.cleanupExpiredNotebookSnapshots(ctx, now)
+
+	if err := s.sqlite.DeleteExpiredSessions(ctx, now); err != nil {
+		s.log.Warn("failed to delete expired sessions", "error", err)
+	}
+
+	if retention := s.config.QueryHistory.Retention; retention > 0 {
+		if _, err := s.sqlite.DeleteQueryHistoryBefore(ctx, now.Add(-retention)); err != nil {
+			s.log.Warn("failed to delete old query history", "error", err)
+		}
+	}
+
+	// Rows left by instances that never restarted. A live instance never
+	// keeps a query past staleQueryAge, so anything older is an orphan.
+	if _, err := s.sqlite.DeleteTrackedQueriesBefore(ctx, now.Add(-2*staleQueryAge)); err != nil {
+		s.log.Warn("failed to prune tracked queries", "error", err)
+	}
+}
+
+func (s *Server) cleanupExpiredExports(ctx context.Context, now time.Time) {
 	paths, err := s.sqlite.ListExpiredExportJobPaths(ctx, now)
 	if err != nil {
 		s.log.Warn("failed to list expired export jobs", "error", err)
 		return
 	}
 	for _, path := range paths {
-		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		if err := s.removeExportArtifact(ctx, path); err != nil {
 			s.log.Warn("failed to remove expired export artifact", "error", err, "path", path)
 		}
 	}
 	if err := s.sqlite.DeleteExpiredExportJobs(ctx, now); err != nil {
 		s.log.Warn("failed to delete expired export job rows", "error", err)
 	}
-
-	if err := s.sqlite.DeleteExpiredSessions(ctx, now); err != nil {
-		s.log.Warn("failed to delete expired sessions", "error", err)
-	}
 }
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// federatedQueryRequest is the body of a federated LogchefQL query. The same
+// LogchefQL query is compiled for each source, so the sources only need the
+// fields the query uses in common, not identical schemas.
+type federatedQueryRequest struct {
+	SourceIDs    []models.SourceID `json:"source_ids"`
+	Query        string            `json:"query"`
+	StartTime    string            `json:"start_time"`
+	EndTime      string            `json:"end_time"`
+	Timezone     string            `json:"timezone"`
+	Limit        int               `json:"limit"`
+	QueryTimeout *int              `json:"query_timeout"`
+}
+
+// handleFederatedLogchefQLQuery runs one LogchefQL query across several
+// sources of the same type linked to the team and merges the rows by time.
+// URL: POST /api/v1/teams/:teamID/federated/logchefql/query
+func (s *Server) handleFederatedLogchefQLQuery(c *fiber.Ctx) error { //nolint:gocyclo // request handler, inherently branchy
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	user, ok := c.Locals("user").(*models.User)
+	if !ok || user == nil {
+		return SendErrorWithType(c, fiber.StatusUnauthorized, "User context not found", models.AuthenticationErrorType)
+	}
+
+	var req federatedQueryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+	if req.StartTime == "" || req.EndTime == "" {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "start_time and end_time are required", models.ValidationErrorType)
+	}
+	if req.Limit <= 0 {
+		req.Limit = s.config.Query.DefaultPreviewLimit
+	}
+	if req.Limit > s.config.Query.MaxPreviewLimit {
+		req.Limit = s.config.Query.MaxPreviewLimit
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if req.QueryTimeout == nil {
+		defaultTimeout := s.config.Query.DefaultTimeoutSeconds
+		req.QueryTimeout = &defaultTimeout
+	}
+	if err := models.ValidateQueryTimeout(req.QueryTimeout); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+	if s.config.Query.MaxTimeoutSeconds > 0 && *req.QueryTimeout > s.config.Query.MaxTimeoutSeconds {
+		return SendErrorWithType(c, fiber.StatusBadRequest,
+			fmt.Sprintf("Query timeout cannot exceed %d seconds for Run", s.config.Query.MaxTimeoutSeconds),
+			models.ValidationErrorType)
+	}
+
+	sources, err := core.ResolveFederatedSources(c.Context(), s.sqlite, req.SourceIDs)
+	if err != nil {
+		var validationErr *core.ValidationError
+		if errors.As(err, &validationErr) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, validationErr.Error(), models.ValidationErrorType)
+		}
+		if errors.Is(err, core.ErrSourceNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		s.log.Error("failed to resolve federated sources", "error", err, "team_id", teamID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get sources", models.DatabaseErrorType)
+	}
+
+	queries := make([]core.FederatedQuery, 0, len(sources))
+	for _, source := range sources {
+		// Same check as requireTeamHasSource on the per-source routes.
+		hasAccess, err := core.TeamHasSourceAccess(c.Context(), s.sqlite, teamID, source.ID)
+		if err != nil {
+			s.log.Error("failed to check team source access", "error", err, "team_id", teamID, "source_id", source.ID)
+			return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to check source access", models.DatabaseErrorType)
+		}
+		if !hasAccess {
+			return SendErrorWithType(c, fiber.StatusForbidden,
+				fmt.Sprintf("Team does not have access to source %d", source.ID), models.AuthorizationErrorType)
+		}
+
+		params, ok, err := s.compileFederatedQuery(c, source, req)
+		if !ok {
+			return err
+		}
+		params.CostLimits = s.config.Query.Cost.LimitsForTeam(teamID)
+		if params.RowFilter, err = core.GetTeamSourceRowFilter(c.Context(), s.sqlite, teamID, source.ID); err != nil {
+			s.log.Error("failed to get team source row filter", "error", err, "team_id", teamID, "source_id", source.ID)
+			return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to check source access", models.DatabaseErrorType)
+		}
+		if params.MaskedColumns, ok, err = s.sourceMaskedColumnsForUser(c, teamID, source.ID); !ok {
+			return err
+		}
+		queries = append(queries, core.FederatedQuery{Source: source, Params: params})
+	}
+
+	queryCtx, cancel := context.WithCancel(c.Context())
+	defer cancel()
+
+	// One federated query takes one admission slot; it is tracked under its
+	// first source so it can be listed and cancelled like any other query.
+	queryID, err := s.queries.StartQuery(
+		QueryClassPreview,
+		user.ID,
+		sources[0].ID,
+		teamID,
+		req.Query,
+		cancel,
+		s.config.Query.MaxConcurrentPerUser,
+		s.config.Query.MaxConcurrentPerTeam,
+		s.config.Query.MaxConcurrentGlobal,
+	)
+	if err != nil {
+		return sendAdmissionError(c, err, "Failed to track query")
+	}
+	defer s.queries.RemoveQuery(queryID)
+
+	result, err := core.QueryLogsFederated(queryCtx, s.datasources, queries, req.Limit)
+	if err != nil {
+		s.log.Error("failed to execute federated query", "error", err, "team_id", teamID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Query execution failed: "+err.Error(), models.DatabaseErrorType)
+	}
+
+	sourceIDs := make([]string, len(sources))
+	for i, source := range sources {
+		sourceIDs[i] = fmt.Sprint(source.ID)
+	}
+	s.log.Info("query.execute",
+		"user", user.Email,
+		"team_id", teamID,
+		"source_ids", strings.Join(sourceIDs, ","),
+		"mode", "logchefql-federated",
+		"query_id", queryID,
+		"rows", len(result.Logs),
+		"duration_ms", result.Stats.ExecutionTimeMs,
+		"limit_requested", req.Limit,
+		"truncated", result.Stats.Truncated,
+	)
+
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{
+		"logs":     result.Logs,
+		"columns":  result.Columns,
+		"stats":    result.Stats,
+		"sources":  result.Sources,
+		"query_id": queryID,
+		"warnings": result.Warnings,
+	})
+}
+
+// compileFederatedQuery compiles the federated LogchefQL query for one source,
+// mirroring handleLogchefQLQuery. It writes the error response itself and
+// reports ok=false when the query can't run on that source.
+func (s *Server) compileFederatedQuery(c *fiber.Ctx, source *models.Source, req federatedQueryRequest) (datasource.QueryRequest, bool, error) {
+	if err := s.datasources.ApplySourceMetadata(source); err != nil {
+		s.log.Error("failed to apply source metadata", "error", err, "source_id", source.ID)
+		return datasource.QueryRequest{}, false, SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get source", models.DatabaseErrorType)
+	}
+	if !source.SupportsQueryLanguage(models.QueryLanguageLogchefQL) {
+		return datasource.QueryRequest{}, false, SendErrorWithType(c, fiber.StatusBadRequest,
+			fmt.Sprintf("LogchefQL is not supported for source %q", source.Name), models.ValidationErrorType)
+	}
+
+	compiled, compileErr := s.datasources.CompileLogchefQL(c.Context(), source.ID, datasource.LogchefQLCompileRequest{
+		Query:     req.Query,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Timezone:  req.Timezone,
+		Limit:     req.Limit,
+	})
+	if compiled == nil {
+		if errors.Is(compileErr, datasource.ErrOperationNotSupported) {
+			return datasource.QueryRequest{}, false, SendErrorWithType(c, fiber.StatusBadRequest,
+				fmt.Sprintf("LogchefQL is not supported for source %q", source.Name), models.ValidationErrorType)
+		}
+		s.log.Error("failed to compile logchefql query", "error", compileErr, "source_id", source.ID)
+		return datasource.QueryRequest{}, false, SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to compile query", models.GeneralErrorType)
+	}
+	if compileErr != nil || !compiled.Valid {
+		message := "invalid LogchefQL query"
+		if compileErr != nil {
+			message = compileErr.Error()
+		}
+		if compiled.Error != nil {
+			message = compiled.Error.Error()
+		}
+		return datasource.QueryRequest{}, false, SendErrorWithType(c, fiber.StatusBadRequest,
+			fmt.Sprintf("%s: %s", source.Name, message), models.ValidationErrorType)
+	}
+
+	params := datasource.QueryRequest{
+		RawQuery:         compiled.Query,
+		Timezone:         req.Timezone,
+		Limit:            req.Limit,
+		DefaultLimit:     s.config.Query.DefaultPreviewLimit,
+		MaxLimit:         s.config.Query.MaxPreviewLimit,
+		MaxResponseBytes: s.config.Query.MaxResponseBytes,
+		QueryTimeout:     req.QueryTimeout,
+		RequireTimeBound: s.config.Query.RequireTimeBound,
+	}
+	// As in handleLogchefQLQuery, LogsQL carries no time range of its own.
+	if compiled.Language == models.QueryLanguageLogsQL {
+		startTime, endTime, err := parseLogchefQLTimeRange(req.StartTime, req.EndTime, req.Timezone)
+		if err != nil {
+			return datasource.QueryRequest{}, false, SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+		params.StartTime = startTime
+		params.EndTime = endTime
+	}
+	return params, true, nil
+}
@@ -1,9 +1,13 @@
 package server
 
 import (
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/pkg/models"
 )
 
 // @Summary Health check endpoint
@@ -12,13 +16,42 @@ import (
 // @Accept json
 // @Produce json
 // @Success 200 {object} map[string]interface{} "Server status information"
+// @Failure 503 {object} map[string]interface{} "Server is draining for shutdown"
 // @Router /health [get]
 // handleHealth responds to the health check endpoint, returning the server status,
-// current time, and build information.
+// current time, and build information. While the server drains for shutdown it
+// answers 503 with status "draining".
 func (s *Server) handleHealth(c *fiber.Ctx) error {
+	if s.queries.Draining() {
+		return SendSuccess(c, fiber.StatusServiceUnavailable, fiber.Map{
+			"status":    "draining",
+			"time":      time.Now(),
+			"buildInfo": s.buildInfo,
+		})
+	}
 	return SendSuccess(c, fiber.StatusOK, fiber.Map{
 		"status":    "ok",
 		"time":      time.Now(),
 		"buildInfo": s.buildInfo,
 	})
 }
+
+// handleGetSourceHealthHistory handles GET /admin/sources/:sourceID/health/history.
+// It returns the source's recent background health probes, oldest first, and
+// the share of them that found it healthy.
+func (s *Server) handleGetSourceHealthHistory(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID", models.ValidationErrorType)
+	}
+
+	history, err := s.datasources.SourceHealthHistory(c.Context(), sourceID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		s.log.Error("failed to get source health history", "error", err, "source_id", sourceID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get source health history", models.GeneralErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, history)
+}
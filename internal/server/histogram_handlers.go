@@ -45,7 +45,7 @@ func (s *Server) handleGetHistogram(c *fiber.Ctx) error {
 		return SendErrorWithType(c, fiber.StatusBadRequest, "query_text parameter is required", models.ValidationErrorType)
 	}
 
-	processedQuery, errMsg := resolveHistogramQueryText(req)
+	processedQuery, errMsg := s.resolveHistogramQueryText(c.Context(), sourceID, req)
 	if errMsg != "" {
 		return SendErrorWithType(c, fiber.StatusBadRequest, errMsg, models.ValidationErrorType)
 	}
@@ -54,6 +54,8 @@ func (s *Server) handleGetHistogram(c *fiber.Ctx) error {
 	if errMsg != "" {
 		return SendErrorWithType(c, fiber.StatusBadRequest, errMsg, models.ValidationErrorType)
 	}
+	params.RowFilter = teamRowFilter(c)
+	params.MaskedColumns = teamMaskedColumns(c)
 
 	// Dashboard panel requests may opt into the per-dashboard result cache.
 	// Histogram results always buffer (no streaming path), so the whole response
@@ -62,6 +64,13 @@ func (s *Server) handleGetHistogram(c *fiber.Ctx) error {
 	if effTTL, ok := s.dashboardCacheParams(req.Cache); ok {
 		if source, serr := core.GetSource(c.Context(), s.datasources, sourceID); serr == nil {
 			if teamID, terr := core.ParseTeamID(c.Params("teamID")); terr == nil {
+				// Key on the group-by the query will actually use, so a
+				// severity breakdown never shares an entry with the plain
+				// histogram.
+				groupBy := params.GroupBy
+				if params.SeverityBreakdown && groupBy == "" {
+					groupBy = source.MetaSeverityField
+				}
 				key := dashcache.ComputeKey(dashcache.KeyInput{
 					EndpointKind:     "histogram",
 					TeamID:           int64(teamID),
@@ -74,18 +83,23 @@ func (s *Server) handleGetHistogram(c *fiber.Ctx) error {
 					Timezone:         params.Timezone,
 					EffectiveLimit:   0, // histogram ignores limit; keep it out of the key
 					HistogramWindow:  params.Window,
-					HistogramGroupBy: params.GroupBy,
+					HistogramGroupBy: groupBy,
 					// Key on the effective per-request execution timeout (what
 					// actually governs the query), not the fixed outer wrapper —
 					// otherwise requests with different timeouts collide. Limit
 					// is omitted: histogram execution ignores it.
 					QueryTimeoutSecs: int64(*params.QueryTimeout),
+					RowFilter:        params.RowFilter,
+					Masked:           len(params.MaskedColumns) > 0,
 				})
+				// Annotations are cached with the buckets, so a new marker
+				// shows on a cached panel once its entry expires.
 				fill := func(ctx context.Context) ([]byte, error) {
-					result, err := core.GetHistogramData(ctx, s.datasources, sourceID, params)
+					result, err := core.GetHistogramData(ctx, s.datasources, s.histogramRollups(), sourceID, params)
 					if err != nil {
 						return nil, err
 					}
+					s.attachHistogramAnnotations(ctx, teamID, sourceID, params, result)
 					return json.Marshal(NewSuccessResponse(result))
 				}
 				if handled, err := s.tryServeDashboardCache(c, key, effTTL, HistogramTimeout, fill); handled {
@@ -100,7 +114,7 @@ func (s *Server) handleGetHistogram(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(c.Context(), HistogramTimeout)
 	defer cancel()
 
-	result, err := core.GetHistogramData(ctx, s.datasources, sourceID, params)
+	result, err := core.GetHistogramData(ctx, s.datasources, s.histogramRollups(), sourceID, params)
 	if err != nil {
 		if ctx.Err() == context.Canceled {
 			return SendErrorWithType(c, fiber.StatusRequestTimeout, "Request cancelled", models.ExternalServiceErrorType)
@@ -111,37 +125,37 @@ func (s *Server) handleGetHistogram(c *fiber.Ctx) error {
 		}
 		return s.handleHistogramError(c, sourceID, err)
 	}
+	if teamID, err := core.ParseTeamID(c.Params("teamID")); err == nil {
+		s.attachHistogramAnnotations(ctx, teamID, sourceID, params, result)
+	}
 
 	return SendSuccess(c, fiber.StatusOK, result)
 }
 
+// histogramRollups returns the rollup manager for histogram reads, or nil when
+// the server runs without one (a nil *rollups.Manager must not become a
+// non-nil interface).
+func (s *Server) histogramRollups() core.HistogramRollups {
+	if s.rollups == nil {
+		return nil
+	}
+	return s.rollups
+}
+
 // resolveHistogramQueryText validates that all template variables referenced
 // in the histogram query are provided, then applies substitution. errMsg is
 // non-empty (and query empty) on failure.
-func resolveHistogramQueryText(req models.APIHistogramRequest) (query, errMsg string) {
-	// Check if the query contains variable placeholders.
-	requiredVars := template.ExtractVariableNames(req.QueryText)
+func (s *Server) resolveHistogramQueryText(ctx context.Context, sourceID models.SourceID, req models.APIHistogramRequest) (query, errMsg string) {
+	// Check if the query contains variable placeholders without defaults.
+	requiredVars := template.RequiredVariableNames(req.QueryText)
 
 	// Validate that all required variables are provided.
 	if len(requiredVars) > 0 && len(req.Variables) == 0 {
 		return "", fmt.Sprintf("Query contains template variables (%s) but no variables were provided. Please define variable values before executing.", strings.Join(requiredVars, ", "))
 	}
 
-	// Perform template variable substitution if variables are provided.
-	if len(req.Variables) == 0 {
-		return req.QueryText, ""
-	}
-
-	vars := make([]template.Variable, len(req.Variables))
-	for i, v := range req.Variables {
-		vars[i] = template.Variable{
-			Name:  v.Name,
-			Type:  template.VariableType(v.Type),
-			Value: v.Value,
-		}
-	}
-
-	substituted, err := template.SubstituteVariables(req.QueryText, vars)
+	// Perform template variable substitution, falling back to inline defaults.
+	substituted, err := s.substituteVariables(ctx, sourceID, req.QueryText, req.Variables)
 	if err != nil {
 		return "", fmt.Sprintf("Variable substitution failed: %v", err)
 	}
@@ -160,9 +174,10 @@ func buildHistogramParams(req models.APIHistogramRequest, processedQuery string)
 
 	// Prepare parameters for the core histogram function.
 	params = core.HistogramParams{
-		Window:   window,
-		Query:    processedQuery, // Pass processed query text containing filters and time conditions
-		Timezone: req.Timezone,
+		Window:            window,
+		Query:             processedQuery, // Pass processed query text containing filters and time conditions
+		Timezone:          req.Timezone,
+		SeverityBreakdown: req.SeverityBreakdown,
 	}
 
 	startTime, endTime, err := parseHistogramTimeRange(&req)
@@ -208,6 +223,9 @@ func (s *Server) handleHistogramError(c *fiber.Ctx, sourceID models.SourceID, er
 	if errors.Is(err, datasource.ErrOperationNotSupported) {
 		return SendErrorWithType(c, fiber.StatusBadRequest, "Histogram is not supported for this source type yet", models.ValidationErrorType)
 	}
+	if datasource.IsValidationError(err) {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
 
 	// Check for specific error types
 	switch {
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/ingest"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+var (
+	// errIngestBodyTooLarge is returned when a batch exceeds ingest.max_body_bytes.
+	errIngestBodyTooLarge = errors.New("request body exceeds the ingest size limit")
+	// errIngestEncoding is returned for a Content-Encoding other than gzip.
+	errIngestEncoding = errors.New("unsupported Content-Encoding, use gzip or identity")
+)
+
+// handleIngestLogs inserts a pushed batch of logs into a source's table.
+// Content-Type selects the format: application/x-ndjson rows go in as-is,
+// application/json is read as OTLP-JSON and mapped onto the OTel schema.
+// Bodies may be gzip-compressed.
+// URL: POST /api/v1/ingest/:sourceID
+// Requires: ingest_logs on the source (team editor or admin, or global admin);
+// API tokens also need the logs:write scope.
+func (s *Server) handleIngestLogs(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+	user, ok := c.Locals("user").(*models.User)
+	if !ok || user == nil {
+		return SendErrorWithType(c, fiber.StatusUnauthorized, "User context not found", models.AuthenticationErrorType)
+	}
+	if ok, err := s.checkSourcePermission(c, user, sourceID, models.TeamPermissionIngestLogs); !ok {
+		return err
+	}
+
+	format, ok := ingest.FormatForContentType(string(c.Request().Header.ContentType()))
+	if !ok {
+		return SendErrorWithType(c, fiber.StatusUnsupportedMediaType,
+			"Content-Type must be application/x-ndjson or application/json (OTLP-JSON)", models.ValidationErrorType)
+	}
+	body, err := s.readIngestBody(c)
+	if err != nil {
+		return sendIngestBodyError(c, err)
+	}
+
+	batch, err := ingest.Decode(format, body, s.config.Ingest.MaxRows)
+	if err != nil {
+		return sendIngestDecodeError(c, err)
+	}
+	if batch.Rows == 0 {
+		return SendSuccess(c, fiber.StatusOK, fiber.Map{"rows": 0})
+	}
+
+	if err := s.datasources.IngestLogs(c.Context(), sourceID, batch.Data, s.config.Ingest.WaitForAsyncInsert); err != nil {
+		return s.sendIngestInsertError(c, sourceID, batch.Rows, err)
+	}
+
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{"rows": batch.Rows})
+}
+
+func sendIngestBodyError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, errIngestBodyTooLarge):
+		return SendErrorWithType(c, fiber.StatusRequestEntityTooLarge, err.Error(), models.ValidationErrorType)
+	case errors.Is(err, errIngestEncoding):
+		return SendErrorWithType(c, fiber.StatusUnsupportedMediaType, err.Error(), models.ValidationErrorType)
+	}
+	return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+}
+
+func sendIngestDecodeError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, ingest.ErrTooManyRows) {
+		return SendErrorWithType(c, fiber.StatusRequestEntityTooLarge, err.Error(), models.ValidationErrorType)
+	}
+	return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid batch: "+err.Error(), models.ValidationErrorType)
+}
+
+func (s *Server) sendIngestInsertError(c *fiber.Ctx, sourceID models.SourceID, rows int, err error) error {
+	if errors.Is(err, models.ErrNotFound) {
+		return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+	}
+	if errors.Is(err, datasource.ErrOperationNotSupported) {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Ingestion is not supported for this source type", models.ValidationErrorType)
+	}
+	s.log.Error("failed to ingest logs", "error", err, "source_id", sourceID, "rows", rows)
+	return SendErrorWithType(c, fiber.StatusBadGateway, "Error inserting logs: "+err.Error(), models.ExternalServiceErrorType)
+}
+
+// readIngestBody returns the request body, gunzipping it when sent with
+// Content-Encoding: gzip. The decompressed size is capped as well, so a small
+// compressed body cannot expand without bound.
+func (s *Server) readIngestBody(c *fiber.Ctx) ([]byte, error) {
+	limit := s.config.Ingest.MaxBodyBytes
+	raw := c.Request().Body()
+	if len(raw) > limit {
+		return nil, errIngestBodyTooLarge
+	}
+
+	switch strings.ToLower(strings.TrimSpace(c.Get(fiber.HeaderContentEncoding))) {
+	case "", "identity":
+		return raw, nil
+	case "gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, errors.New("invalid gzip body")
+		}
+		defer zr.Close()
+		body, err := io.ReadAll(io.LimitReader(zr, int64(limit)+1))
+		if err != nil {
+			return nil, errors.New("invalid gzip body")
+		}
+		if len(body) > limit {
+			return nil, errIngestBodyTooLarge
+		}
+		return body, nil
+	default:
+		return nil, errIngestEncoding
+	}
+}
+
+// defaultBodyLimit caps request bodies on every route other than ingest.
+const defaultBodyLimit = 4 * 1024 * 1024 // 4MB
+
+// bodyLimit is the transport-level body cap. Fiber applies it app-wide, so
+// when an ingest endpoint is enabled it is raised to ingest.max_body_bytes and
+// limitBody holds every other route to defaultBodyLimit. The ingest handlers
+// enforce their exact limit themselves.
+func bodyLimit(cfg *config.Config) int {
+	if (cfg.Ingest.Enabled || cfg.Ingest.OTLP.Enabled) && cfg.Ingest.MaxBodyBytes > defaultBodyLimit {
+		return cfg.Ingest.MaxBodyBytes
+	}
+	return defaultBodyLimit
+}
+
+// isIngestPath reports whether path is an ingest or OTLP endpoint.
+func isIngestPath(path string) bool {
+	return strings.HasPrefix(path, "/api/v1/ingest/") || strings.HasPrefix(path, "/api/v1/otlp/")
+}
+
+// limitBody rejects bodies over limit on every route but ingest. It is only
+// installed when bodyLimit has raised the app-wide cap above limit. The
+// raw body is measured, so a compressed body isn't inflated to check it.
+func limitBody(limit int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if isIngestPath(c.Path()) {
+			return c.Next()
+		}
+		if c.Request().Header.ContentLength() > limit || len(c.Request().Body()) > limit {
+			return SendErrorWithType(c, fiber.StatusRequestEntityTooLarge, "Request body too large", models.ValidationErrorType)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/config"
+)
+
+// TestLimitBody checks that raising the app-wide body limit for ingest leaves
+// every other route at the default.
+func TestLimitBody(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Ingest.Enabled = true
+	cfg.Ingest.MaxBodyBytes = 2 * defaultBodyLimit
+	if got := bodyLimit(cfg); got != 2*defaultBodyLimit {
+		t.Fatalf("bodyLimit() = %d, want the ingest limit", got)
+	}
+
+	app := fiber.New(fiber.Config{BodyLimit: bodyLimit(cfg)})
+	app.Use(limitBody(defaultBodyLimit))
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) }
+	app.Post("/api/v1/ingest/:sourceID", ok)
+	app.Post("/api/v1/otlp/:sourceID/v1/logs", ok)
+	app.Post("/api/v1/teams/:teamID/sources/:sourceID/logs/query", ok)
+
+	tests := []struct {
+		path string
+		size int
+		want int
+	}{
+		{"/api/v1/ingest/1", defaultBodyLimit + 1, fiber.StatusNoContent},
+		{"/api/v1/otlp/1/v1/logs", defaultBodyLimit + 1, fiber.StatusNoContent},
+		{"/api/v1/teams/1/sources/1/logs/query", defaultBodyLimit, fiber.StatusNoContent},
+		{"/api/v1/teams/1/sources/1/logs/query", defaultBodyLimit + 1, fiber.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewReader(make([]byte, tt.size)))
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("POST %s: %v", tt.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("POST %s with %d bytes = %d, want %d", tt.path, tt.size, resp.StatusCode, tt.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// listSpec declares what a list endpoint accepts: the fields it sorts by, the
+// filters it understands and its default order. Sort and filter names are the
+// JSON field names of the listed items.
+type listSpec[T any] struct {
+	sorts       map[string]func(a, b T) int
+	filters     map[string]func(item T, value string) bool
+	defaultSort string
+	defaultDesc bool
+	// defaultLimit and maxLimit override models.DefaultListLimit and
+	// models.MaxListLimit when set.
+	defaultLimit int
+	maxLimit     int
+}
+
+// parseListQuery reads ?limit, ?offset, ?sort and the spec's filters, rejecting
+// unknown sort fields and malformed paging values.
+func parseListQuery[T any](c *fiber.Ctx, spec listSpec[T]) (models.ListQuery, error) {
+	defaultLimit, maxLimit := models.DefaultListLimit, models.MaxListLimit
+	if spec.defaultLimit > 0 {
+		defaultLimit = spec.defaultLimit
+	}
+	if spec.maxLimit > 0 {
+		maxLimit = spec.maxLimit
+	}
+	q := models.ListQuery{
+		Limit:   defaultLimit,
+		Sort:    spec.defaultSort,
+		Desc:    spec.defaultDesc,
+		Filters: map[string]string{},
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return q, fmt.Errorf("invalid limit")
+		}
+		q.Limit = min(limit, maxLimit)
+	}
+	if raw := c.Query("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return q, fmt.Errorf("invalid offset")
+		}
+		q.Offset = offset
+	}
+	if raw := c.Query("sort"); raw != "" {
+		field := strings.TrimPrefix(raw, "-")
+		if _, ok := spec.sorts[field]; !ok {
+			return q, fmt.Errorf("cannot sort by %q; allowed: %s", field, strings.Join(sortedKeys(spec.sorts), ", "))
+		}
+		q.Sort, q.Desc = field, strings.HasPrefix(raw, "-")
+	}
+	for name := range spec.filters {
+		if value := c.Query(name); value != "" {
+			q.Filters[name] = value
+		}
+	}
+	return q, nil
+}
+
+// applyListQuery filters, sorts and pages items in memory. It suits the admin
+// lists, which are small enough to load whole; lists backed by large tables
+// page in SQL instead.
+func applyListQuery[T any](items []T, q models.ListQuery, spec listSpec[T]) ([]T, *models.Pagination) {
+	matched := make([]T, 0, len(items))
+	for _, item := range items {
+		keep := true
+		for name, value := range q.Filters {
+			if !spec.filters[name](item, value) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			matched = append(matched, item)
+		}
+	}
+	if compare, ok := spec.sorts[q.Sort]; ok {
+		slices.SortStableFunc(matched, func(a, b T) int {
+			if q.Desc {
+				return compare(b, a)
+			}
+			return compare(a, b)
+		})
+	}
+
+	total := len(matched)
+	start := min(q.Offset, total)
+	end := min(start+q.Limit, total)
+	return matched[start:end], models.NewPagination(q, total)
+}
+
+// sendListError answers a malformed list request.
+func sendListError(c *fiber.Ctx, err error) error {
+	return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Comparators and filters shared by the list specs.
+
+func compareFold(a, b string) int {
+	return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func compareTime(a, b time.Time) int {
+	return a.Compare(b)
+}
+
+// compareTimePtr orders unset times first.
+func compareTimePtr(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return a.Compare(*b)
+}
+
+// containsFold reports whether any of the fields contains value, ignoring case.
+func containsFold(value string, fields ...string) bool {
+	value = strings.ToLower(value)
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchBool compares a boolean field with a "true"/"false" filter value.
+func matchBool(field bool, value string) bool {
+	want, err := strconv.ParseBool(value)
+	return err == nil && field == want
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestListQuery(t *testing.T) {
+	t.Parallel()
+
+	users := []*models.User{
+		{ID: 1, Email: "carol@example.com", Role: models.UserRoleAdmin},
+		{ID: 2, Email: "alice@example.com", Role: models.UserRoleMember},
+		{ID: 3, Email: "Bob@example.com", Role: models.UserRoleMember},
+		{ID: 4, Email: "dave@example.com", Role: models.UserRoleMember},
+	}
+	app := fiber.New()
+	app.Get("/users", func(c *fiber.Ctx) error {
+		q, err := parseListQuery(c, userListSpec)
+		if err != nil {
+			return sendListError(c, err)
+		}
+		page, pagination := applyListQuery(users, q, userListSpec)
+		return SendList(c, page, pagination)
+	})
+
+	type listResponse struct {
+		Data       []*models.User     `json:"data"`
+		Pagination *models.Pagination `json:"pagination"`
+	}
+	get := func(query string) (int, listResponse) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/users"+query, http.NoBody))
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		defer resp.Body.Close()
+		var body listResponse
+		if resp.StatusCode == fiber.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+		}
+		return resp.StatusCode, body
+	}
+
+	status, body := get("?role=member&sort=-email&limit=2")
+	if status != fiber.StatusOK || len(body.Data) != 2 || body.Data[0].ID != 4 || body.Data[1].ID != 3 {
+		t.Fatalf("first page = %d %+v", status, body.Data)
+	}
+	if p := body.Pagination; p.Total != 3 || p.Limit != 2 || p.Offset != 0 || p.Sort != "email" || p.Order != "desc" || !p.HasMore {
+		t.Errorf("first page pagination = %+v", p)
+	}
+
+	_, body = get("?role=member&sort=-email&limit=2&offset=2")
+	if len(body.Data) != 1 || body.Data[0].ID != 2 || body.Pagination.HasMore {
+		t.Errorf("second page = %+v / %+v", body.Data, body.Pagination)
+	}
+
+	_, body = get("?offset=10")
+	if len(body.Data) != 0 || body.Pagination.Total != 4 {
+		t.Errorf("page past the end = %+v / %+v", body.Data, body.Pagination)
+	}
+
+	for _, query := range []string{"?sort=password", "?limit=0", "?limit=x", "?offset=-1"} {
+		if status, _ := get(query); status != fiber.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, status)
+		}
+	}
+}
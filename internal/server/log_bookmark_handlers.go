@@ -0,0 +1,136 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/pkg/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sendLogBookmarkError maps core log bookmark errors onto responses.
+func (s *Server) sendLogBookmarkError(c *fiber.Ctx, err error, action string) error {
+	switch {
+	case errors.Is(err, core.ErrInvalidLogBookmark):
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	case errors.Is(err, core.ErrLogBookmarkExists):
+		return SendErrorWithType(c, fiber.StatusConflict, err.Error(), models.ConflictErrorType)
+	case errors.Is(err, core.ErrLogBookmarkForbidden):
+		return SendErrorWithType(c, fiber.StatusForbidden, err.Error(), models.AuthorizationErrorType)
+	case errors.Is(err, core.ErrLogBookmarkNotFound):
+		return SendErrorWithType(c, fiber.StatusNotFound, "Log bookmark not found", models.NotFoundErrorType)
+	default:
+		s.log.Error("failed to "+action+" log bookmark", "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to "+action+" log bookmark", models.GeneralErrorType)
+	}
+}
+
+// parseLogBookmarkRoute reads the :teamID and :bookmarkID route params. When
+// ok is false the error response has already been written and err should be
+// returned as-is.
+func parseLogBookmarkRoute(c *fiber.Ctx) (teamID models.TeamID, id models.LogBookmarkID, ok bool, err error) {
+	teamID, err = core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return 0, 0, false, SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	raw, err := parsePositiveIntParam(c, "bookmarkID")
+	if err != nil {
+		return 0, 0, false, SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+	return teamID, models.LogBookmarkID(raw), true, nil
+}
+
+// handleListLogBookmarks lists the caller's bookmarks in the team and the
+// team's shared ones, most recently pinned first. ?source_id narrows the list
+// to one source.
+func (s *Server) handleListLogBookmarks(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+
+	var sourceID models.SourceID
+	if raw := c.Query("source_id"); raw != "" {
+		sourceID, err = core.ParseSourceID(raw)
+		if err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source_id parameter", models.ValidationErrorType)
+		}
+	}
+
+	bookmarks, err := core.ListLogBookmarks(c.Context(), s.sqlite, user, teamID, sourceID)
+	if err != nil {
+		return s.sendLogBookmarkError(c, err, "list")
+	}
+	return SendSuccess(c, fiber.StatusOK, bookmarks)
+}
+
+// handleCreateLogBookmark pins a log row for the caller.
+func (s *Server) handleCreateLogBookmark(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+
+	var req models.CreateLogBookmarkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	bookmark, err := core.CreateLogBookmark(c.Context(), s.sqlite, s.log, user, teamID, &req)
+	if err != nil {
+		return s.sendLogBookmarkError(c, err, "create")
+	}
+	return SendSuccess(c, fiber.StatusCreated, bookmark)
+}
+
+// handleGetLogBookmark returns one bookmark visible to the caller.
+func (s *Server) handleGetLogBookmark(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, id, ok, err := parseLogBookmarkRoute(c)
+	if !ok {
+		return err
+	}
+
+	bookmark, err := core.GetLogBookmark(c.Context(), s.sqlite, user, teamID, id)
+	if err != nil {
+		return s.sendLogBookmarkError(c, err, "load")
+	}
+	return SendSuccess(c, fiber.StatusOK, bookmark)
+}
+
+// handleUpdateLogBookmark replaces a bookmark's note and visibility.
+func (s *Server) handleUpdateLogBookmark(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, id, ok, err := parseLogBookmarkRoute(c)
+	if !ok {
+		return err
+	}
+
+	var req models.UpdateLogBookmarkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	bookmark, err := core.UpdateLogBookmark(c.Context(), s.sqlite, s.log, user, teamID, id, &req)
+	if err != nil {
+		return s.sendLogBookmarkError(c, err, "update")
+	}
+	return SendSuccess(c, fiber.StatusOK, bookmark)
+}
+
+// handleDeleteLogBookmark unpins a bookmark.
+func (s *Server) handleDeleteLogBookmark(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, id, ok, err := parseLogBookmarkRoute(c)
+	if !ok {
+		return err
+	}
+
+	if err := core.DeleteLogBookmark(c.Context(), s.sqlite, s.log, user, teamID, id); err != nil {
+		return s.sendLogBookmarkError(c, err, "delete")
+	}
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Log bookmark deleted"})
+}
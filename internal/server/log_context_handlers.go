@@ -31,6 +31,9 @@ func (s *Server) handleGetLogContext(c *fiber.Ctx) error {
 	if req.Timestamp <= 0 {
 		return SendErrorWithType(c, fiber.StatusBadRequest, "Timestamp is required and must be positive", models.ValidationErrorType)
 	}
+	if err := req.Scope.Validate(); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
 
 	beforeLimit := req.BeforeLimit
 	if beforeLimit <= 0 {
@@ -55,6 +58,8 @@ func (s *Server) handleGetLogContext(c *fiber.Ctx) error {
 		BeforeOffset:    req.BeforeOffset,
 		AfterOffset:     req.AfterOffset,
 		ExcludeBoundary: req.ExcludeBoundary,
+		Stream:          req.Stream,
+		Scope:           req.Scope,
 	})
 	if err != nil {
 		if errors.Is(err, core.ErrSourceNotFound) {
@@ -63,6 +68,9 @@ func (s *Server) handleGetLogContext(c *fiber.Ctx) error {
 		if errors.Is(err, datasource.ErrOperationNotSupported) {
 			return SendErrorWithType(c, fiber.StatusBadRequest, "Log context is not supported for this source type", models.ValidationErrorType)
 		}
+		if datasource.IsValidationError(err) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err), models.ValidationErrorType)
+		}
 		s.log.Error("failed to get log context", "error", err, "source_id", sourceID)
 		return SendErrorWithType(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to retrieve log context: %v", err), models.DatabaseErrorType)
 	}
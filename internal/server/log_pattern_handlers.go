@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// PatternsTimeout bounds a pattern mining request. Grouping templates every
+// message in the range, so it gets the same budget as histograms.
+const PatternsTimeout = 30 * time.Second
+
+// handleGetLogPatterns groups the messages in a time range into templates and
+// returns the most frequent ones with counts and examples.
+// Access is controlled by the requireSourceAccess middleware.
+// Query params:
+//   - field: String column holding the message (default "body")
+//   - start_time: ISO8601 start time (required)
+//   - end_time: ISO8601 end time (required)
+//   - timezone: timezone for time conversion (optional, defaults to UTC)
+//   - limit: patterns to return (default 50, max 200)
+//   - baseline: "true" to compare with the preceding window and flag new patterns
+//   - query: datasource-native query string (optional, narrows the rows)
+func (s *Server) handleGetLogPatterns(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID format", models.ValidationErrorType)
+	}
+
+	startTimeStr := c.Query("start_time", "")
+	endTimeStr := c.Query("end_time", "")
+	if startTimeStr == "" || endTimeStr == "" {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Time range (start_time, end_time) is required for performance", models.ValidationErrorType)
+	}
+	startTime, err := time.Parse(time.RFC3339, startTimeStr)
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid start_time format (use ISO8601/RFC3339)", models.ValidationErrorType)
+	}
+	endTime, err := time.Parse(time.RFC3339, endTimeStr)
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid end_time format (use ISO8601/RFC3339)", models.ValidationErrorType)
+	}
+	if !startTime.Before(endTime) {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "start_time must be before end_time", models.ValidationErrorType)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), PatternsTimeout)
+	defer cancel()
+
+	result, err := core.GetLogPatterns(ctx, s.datasources, sourceID, core.LogPatternsParams{
+		FieldName: c.Query("field", ""),
+		Language:  models.QueryLanguage(c.Query("query_language", "")),
+		StartTime: startTime,
+		EndTime:   endTime,
+		Timezone:  c.Query("timezone", "UTC"),
+		Limit:     c.QueryInt("limit", 0),
+		Baseline:  c.QueryBool("baseline", false),
+		QueryText: c.Query("query", ""),
+	})
+	if err != nil {
+		if ctx.Err() == context.Canceled {
+			return SendErrorWithType(c, fiber.StatusRequestTimeout, "Request cancelled", models.ExternalServiceErrorType)
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			s.log.Warn("log patterns request timed out", "source_id", sourceID, "timeout", PatternsTimeout)
+			return SendErrorWithType(c, fiber.StatusRequestTimeout, "Request timed out", models.ExternalServiceErrorType)
+		}
+		if errors.Is(err, core.ErrSourceNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		if errors.Is(err, datasource.ErrOperationNotSupported) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Log patterns are not supported for this source type yet", models.ValidationErrorType)
+		}
+		if datasource.IsValidationError(err) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err), models.ValidationErrorType)
+		}
+		s.log.Error("failed to get log patterns", "error", err, "source_id", sourceID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to get log patterns: %v", err), models.DatabaseErrorType)
+	}
+
+	return SendSuccess(c, fiber.StatusOK, result)
+}
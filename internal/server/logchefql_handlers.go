@@ -11,10 +11,11 @@ import (
 	"github.com/google/uuid"
 
 	dashcache "github.com/mr-karan/logchef/internal/cache"
+	"github.com/mr-karan/logchef/internal/clickhouse"
 	"github.com/mr-karan/logchef/internal/core"
 	"github.com/mr-karan/logchef/internal/datasource"
 	"github.com/mr-karan/logchef/internal/logchefql"
-	"github.com/mr-karan/logchef/internal/template"
+	"github.com/mr-karan/logchef/internal/timezone"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
@@ -50,13 +51,13 @@ type ValidateResponse struct {
 	Error *logchefql.ParseError `json:"error,omitempty"`
 }
 
-func parseLogchefQLTimeRange(startTime, endTime, timezone string) (startPtr, endPtr *time.Time, err error) {
-	locationName := timezone
+func parseLogchefQLTimeRange(startTime, endTime, tz string) (startPtr, endPtr *time.Time, err error) {
+	locationName := tz
 	if locationName == "" {
 		locationName = "UTC"
 	}
 
-	loc, err := time.LoadLocation(locationName)
+	loc, err := timezone.Load(locationName)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -290,6 +291,14 @@ func (s *Server) handleLogchefQLQuery(c *fiber.Ctx) error { //nolint:gocyclo //
 		// Cache opts this request into the dashboard result cache. Omitted for
 		// explorer/ad-hoc queries so they are never cached.
 		Cache *models.CacheDirective `json:"cache,omitempty"`
+		// IncludeCount adds total_count; see models.APIQueryRequest.
+		IncludeCount bool `json:"include_count,omitempty"`
+		// Sample runs the query over a fraction of rows; see models.APIQueryRequest.
+		Sample float64 `json:"sample,omitempty"`
+		// Dedupe collapses consecutive identical rows; see models.APIQueryRequest.
+		Dedupe *models.DedupeOptions `json:"dedupe,omitempty"`
+		// Distributed routes the query to one shard; see models.APIQueryRequest.
+		Distributed *models.DistributedRouting `json:"distributed,omitempty"`
 	}
 	if err := c.BodyParser(&req); err != nil {
 		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
@@ -324,6 +333,9 @@ func (s *Server) handleLogchefQLQuery(c *fiber.Ctx) error { //nolint:gocyclo //
 			fmt.Sprintf("Query timeout cannot exceed %d seconds for Run", s.config.Query.MaxTimeoutSeconds),
 			models.ValidationErrorType)
 	}
+	if _, err := timezone.Load(req.Timezone); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
 
 	// Get source information
 	source, err := core.GetSource(c.Context(), s.datasources, sourceID)
@@ -337,23 +349,32 @@ func (s *Server) handleLogchefQLQuery(c *fiber.Ctx) error { //nolint:gocyclo //
 	if !source.SupportsQueryLanguage(models.QueryLanguageLogchefQL) {
 		return SendErrorWithType(c, fiber.StatusBadRequest, "LogchefQL is not supported for this source", models.ValidationErrorType)
 	}
-
-	// Substitute variables in the query if provided
-	query := req.Query
-	if len(req.Variables) > 0 {
-		vars := make([]template.Variable, len(req.Variables))
-		for i, v := range req.Variables {
-			vars[i] = template.Variable{
-				Name:  v.Name,
-				Type:  template.VariableType(v.Type),
-				Value: v.Value,
-			}
+	if req.Sample != 0 {
+		if !source.IsClickHouse() {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Sampling is only supported for ClickHouse sources", models.ValidationErrorType)
 		}
-		substituted, err := template.SubstituteVariables(query, vars)
-		if err != nil {
-			return SendErrorWithType(c, fiber.StatusBadRequest, "Variable substitution failed: "+err.Error(), models.ValidationErrorType)
+		if err := clickhouse.ValidateSampleRatio(req.Sample); err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+	}
+	if req.Dedupe != nil {
+		if err := req.Dedupe.Validate(); err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+	}
+	if req.Distributed != nil {
+		if !source.IsClickHouse() {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Shard routing is only supported for ClickHouse sources", models.ValidationErrorType)
 		}
-		query = substituted
+		if err := req.Distributed.Validate(); err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+	}
+
+	// Substitute variables in the query if provided
+	query, err := s.substituteVariables(c.Context(), sourceID, req.Query, req.Variables)
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Variable substitution failed: "+err.Error(), models.ValidationErrorType)
 	}
 
 	// Compile the query into the source's native language behind the
@@ -432,6 +453,13 @@ func (s *Server) handleLogchefQLQuery(c *fiber.Ctx) error { //nolint:gocyclo //
 		MaxLimit:         s.config.Query.MaxPreviewLimit,
 		MaxResponseBytes: s.config.Query.MaxResponseBytes,
 		QueryTimeout:     req.QueryTimeout,
+		CostLimits:       s.config.Query.Cost.LimitsForTeam(teamID),
+		Sample:           req.Sample,
+		RowFilter:        teamRowFilter(c),
+		MaskedColumns:    teamMaskedColumns(c),
+		RequireTimeBound: s.config.Query.RequireTimeBound,
+		Dedupe:           req.Dedupe,
+		Distributed:      req.Distributed,
 	}
 
 	// Dashboard panel requests may opt into the per-dashboard result cache. The
@@ -448,12 +476,14 @@ func (s *Server) handleLogchefQLQuery(c *fiber.Ctx) error { //nolint:gocyclo //
 			SourceRevision:   source.UpdatedAt.UnixNano(),
 			EffTTLSeconds:    int64(effTTL / time.Second),
 			Language:         string(executableQueryLanguage),
-			FinalizedQuery:   executableQuery,
+			FinalizedQuery:   executableQuery + sampleCacheSuffix(req.Sample) + dedupeCacheSuffix(req.Dedupe) + distributedCacheSuffix(req.Distributed),
 			CanonicalStart:   canonCacheTime(queryStartTime),
 			CanonicalEnd:     canonCacheTime(queryEndTime),
 			Timezone:         req.Timezone,
 			EffectiveLimit:   int64(req.Limit),
 			QueryTimeoutSecs: int64(*req.QueryTimeout),
+			RowFilter:        queryParams.RowFilter,
+			Masked:           len(queryParams.MaskedColumns) > 0,
 		})
 	}
 
@@ -467,6 +497,7 @@ func (s *Server) handleLogchefQLQuery(c *fiber.Ctx) error { //nolint:gocyclo //
 			generatedSQL:      executableQuery,
 			generatedQuery:    executableQuery,
 			generatedLanguage: executableQueryLanguage,
+			includeCount:      req.IncludeCount,
 		}
 		// OOM guardrail: only the dashboard-directive path buffers (bounded by
 		// max_entry_bytes); on overflow the fill errors and we fall through to the
@@ -514,7 +545,7 @@ func (s *Server) handleLogchefQLQuery(c *fiber.Ctx) error { //nolint:gocyclo //
 	defer cancel() // Ensure cleanup
 
 	// Add query to tracker atomically with admission control.
-	queryID, err := queryTracker.StartQuery(
+	queryID, err := s.queries.StartQuery(
 		QueryClassPreview,
 		user.ID,
 		sourceID,
@@ -522,23 +553,35 @@ func (s *Server) handleLogchefQLQuery(c *fiber.Ctx) error { //nolint:gocyclo //
 		executableQuery,
 		cancel,
 		s.config.Query.MaxConcurrentPerUser,
+		s.config.Query.MaxConcurrentPerTeam,
 		s.config.Query.MaxConcurrentGlobal,
 	)
 	if err != nil {
-		var admissionErr *QueryAdmissionError
-		if errors.As(err, &admissionErr) {
-			return SendErrorWithType(c, fiber.StatusTooManyRequests, admissionErr.Message, models.ValidationErrorType)
-		}
-		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to track query", models.GeneralErrorType)
+		return sendAdmissionError(c, err, "Failed to track query")
+	}
+	defer s.queries.RemoveQuery(queryID)
+	queryCtx = clickhouse.ContextWithQueryID(queryCtx, queryID)
+
+	var totalCount <-chan int64
+	if req.IncludeCount {
+		totalCount = s.startTotalCount(queryCtx, sourceID, queryParams)
 	}
-	defer queryTracker.RemoveQuery(queryID)
 
 	// Execute via core function
+	start := time.Now()
 	result, err := core.QueryLogs(queryCtx, s.datasources, sourceID, queryParams)
 	if err != nil {
 		if errors.Is(err, datasource.ErrOperationNotSupported) {
 			return SendErrorWithType(c, fiber.StatusBadRequest, "Querying is not supported for this source type yet", models.ValidationErrorType)
 		}
+		if errors.Is(err, clickhouse.ErrSourceBusy) {
+			return SendErrorWithType(c, fiber.StatusServiceUnavailable, "Source is busy running other queries, try again shortly", models.ExternalServiceErrorType)
+		}
+		s.recordFailedQuery(user, teamID, sourceID, req.Query, models.QueryLanguageLogchefQL, time.Since(start).Milliseconds(), err)
+		var costErr *models.QueryCostError
+		if errors.As(err, &costErr) {
+			return sendQueryCostError(c, costErr)
+		}
 		s.log.Error("failed to execute logchefql query", "error", err, "source_id", sourceID)
 		return SendErrorWithType(c, fiber.StatusInternalServerError, "Query execution failed: "+err.Error(), models.DatabaseErrorType)
 	}
@@ -559,7 +602,7 @@ func (s *Server) handleLogchefQLQuery(c *fiber.Ctx) error { //nolint:gocyclo //
 			"truncated", result.Stats.Truncated,
 		)
 		s.recordQueryHistory(user, teamID, sourceID, req.Query, models.QueryLanguageLogchefQL,
-			int64(result.Stats.ExecutionTimeMs), int64(len(result.Logs)))
+			int64(result.Stats.ExecutionTimeMs), int64(len(result.Logs)), int64(result.Stats.RowsRead))
 	}
 
 	// Add query_id and generated SQL to response
@@ -574,6 +617,9 @@ func (s *Server) handleLogchefQLQuery(c *fiber.Ctx) error { //nolint:gocyclo //
 		"generated_query_language": executableQueryLanguage,
 		"warnings":                 result.Warnings,
 	}
+	if total, ok := <-totalCount; ok {
+		responseData["total_count"] = total
+	}
 
 	return SendSuccess(c, fiber.StatusOK, responseData)
 }
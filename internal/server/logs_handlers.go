@@ -7,16 +7,17 @@ import (
 	"fmt"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
 	dashcache "github.com/mr-karan/logchef/internal/cache"
+	"github.com/mr-karan/logchef/internal/clickhouse"
 	"github.com/mr-karan/logchef/internal/core"
 	"github.com/mr-karan/logchef/internal/datasource"
 	"github.com/mr-karan/logchef/internal/template"
+	"github.com/mr-karan/logchef/internal/timezone"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
@@ -36,55 +37,6 @@ const (
 	FieldValuesTimeout = 15 * time.Second
 )
 
-// QueryTracker manages active queries for cancellation support
-type QueryTracker struct {
-	mu      sync.RWMutex
-	queries map[string]*ActiveQuery
-}
-
-type QueryClass string
-
-const (
-	QueryClassPreview QueryClass = "preview"
-	QueryClassExport  QueryClass = "export"
-	QueryClassTail    QueryClass = "tail"
-)
-
-type QueryAdmissionError struct {
-	Message string
-}
-
-func (e *QueryAdmissionError) Error() string {
-	return e.Message
-}
-
-// ActiveQuery represents an active query with its context for cancellation
-type ActiveQuery struct {
-	ID        string
-	Class     QueryClass
-	UserID    models.UserID
-	SourceID  models.SourceID
-	TeamID    models.TeamID
-	StartTime time.Time
-	QueryText string
-	Cancel    context.CancelFunc
-}
-
-// Global query tracker instance
-var queryTracker = &QueryTracker{
-	queries: make(map[string]*ActiveQuery),
-}
-
-func init() {
-	// Periodic cleanup of stale query tracker entries every 5 minutes
-	go func() {
-		ticker := time.NewTicker(5 * time.Minute)
-		for range ticker.C {
-			queryTracker.Cleanup()
-		}
-	}()
-}
-
 func inferResponseColumnType(value any) string {
 	switch v := value.(type) {
 	case nil:
@@ -177,95 +129,33 @@ func normalizeResultColumns(source *models.Source, result *models.QueryResult) [
 	return columns
 }
 
-// StartQuery registers a new active query atomically with admission control.
-func (qt *QueryTracker) StartQuery(class QueryClass, userID models.UserID, sourceID models.SourceID, teamID models.TeamID, sql string, cancel context.CancelFunc, maxPerUser, maxGlobal int) (string, error) {
-	queryID := uuid.New().String()
-	if err := qt.StartQueryWithID(queryID, class, userID, sourceID, teamID, sql, cancel, maxPerUser, maxGlobal); err != nil {
-		return "", err
-	}
-	return queryID, nil
-}
-
-// StartQueryWithID registers a new active query using a caller-provided ID.
-func (qt *QueryTracker) StartQueryWithID(queryID string, class QueryClass, userID models.UserID, sourceID models.SourceID, teamID models.TeamID, sql string, cancel context.CancelFunc, maxPerUser, maxGlobal int) error {
-	qt.mu.Lock()
-	defer qt.mu.Unlock()
-
-	userActive := 0
-	classActive := 0
-	for _, query := range qt.queries {
-		if query.Class != class {
-			continue
-		}
-		classActive++
-		if query.UserID == userID {
-			userActive++
-		}
-	}
-
-	if maxPerUser > 0 && userActive >= maxPerUser {
-		return &QueryAdmissionError{Message: fmt.Sprintf("Too many active %s queries for this user. Limit is %d.", class, maxPerUser)}
+// rawSQLAllowed reports whether the requesting user may run raw SQL against
+// the team's sources. Admins always may; everyone else follows the team's
+// raw_sql_enabled switch.
+func (s *Server) rawSQLAllowed(c *fiber.Ctx, teamID models.TeamID) (bool, error) {
+	if isUserAdmin(c) {
+		return true, nil
 	}
-	if maxGlobal > 0 && classActive >= maxGlobal {
-		return &QueryAdmissionError{Message: fmt.Sprintf("Too many active %s queries globally. Limit is %d.", class, maxGlobal)}
-	}
-
-	qt.queries[queryID] = &ActiveQuery{
-		ID:        queryID,
-		Class:     class,
-		UserID:    userID,
-		SourceID:  sourceID,
-		TeamID:    teamID,
-		StartTime: time.Now(),
-		QueryText: sql,
-		Cancel:    cancel,
+	team, err := core.GetTeam(c.Context(), s.sqlite, teamID)
+	if err != nil {
+		return false, err
 	}
-	return nil
+	return team.RawSQLEnabled, nil
 }
 
-// RemoveQuery removes a query from the tracker
-func (qt *QueryTracker) RemoveQuery(queryID string) {
-	qt.mu.Lock()
-	defer qt.mu.Unlock()
-	delete(qt.queries, queryID)
-}
-
-// CancelQuery cancels a query if it exists and belongs to the user
-func (qt *QueryTracker) CancelQuery(queryID string, userID models.UserID) bool {
-	qt.mu.Lock()
-	defer qt.mu.Unlock()
-
-	query, exists := qt.queries[queryID]
-	if !exists {
-		return false
-	}
-
-	// Only allow users to cancel their own queries
-	if query.UserID != userID {
-		return false
+// denyRawSQL answers the request when the user may not run raw SQL for the
+// team, with a 403 or, when the check fails, a 500, and reports whether it
+// did. Callers return its error as the handler's result.
+func (s *Server) denyRawSQL(c *fiber.Ctx, teamID models.TeamID) (bool, error) {
+	allowed, err := s.rawSQLAllowed(c, teamID)
+	if err != nil {
+		s.log.Error("failed to check raw sql access", "error", err, "team_id", teamID)
+		return true, SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to check raw SQL access", models.DatabaseErrorType)
 	}
-
-	// Cancel the context
-	query.Cancel()
-
-	// Remove from tracker
-	delete(qt.queries, queryID)
-
-	return true
-}
-
-// Cleanup removes queries that have been running for too long (over 1 hour)
-func (qt *QueryTracker) Cleanup() {
-	qt.mu.Lock()
-	defer qt.mu.Unlock()
-
-	cutoff := time.Now().Add(-1 * time.Hour)
-	for queryID, query := range qt.queries {
-		if query.StartTime.Before(cutoff) {
-			query.Cancel()
-			delete(qt.queries, queryID)
-		}
+	if !allowed {
+		return true, SendErrorWithType(c, fiber.StatusForbidden, "Raw SQL is disabled for this team; use LogchefQL instead", models.AuthorizationErrorType)
 	}
+	return false, nil
 }
 
 // handleQueryLogs handles requests to query logs for a specific source.
@@ -295,6 +185,9 @@ func (s *Server) handleQueryLogs(c *fiber.Ctx) error { //nolint:gocyclo // reque
 			fmt.Sprintf("Query timeout cannot exceed %d seconds for Run", s.config.Query.MaxTimeoutSeconds),
 			models.ValidationErrorType)
 	}
+	if _, err := timezone.Load(req.Timezone); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
 
 	// Get user information for query tracking
 	user := c.Locals("user").(*models.User)
@@ -309,8 +202,21 @@ func (s *Server) handleQueryLogs(c *fiber.Ctx) error { //nolint:gocyclo // reque
 		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
 	}
 
-	// Check if the query contains variable placeholders.
-	requiredVars := template.ExtractVariableNames(req.QueryText)
+	// Run selection: QueryText is the full editor content and only the selected
+	// statement executes. From here on QueryText is that statement, so tracking
+	// and history record what actually ran.
+	var executedStatement *models.ExecutedStatement
+	if req.Selection != nil {
+		stmt, err := clickhouse.ResolveSelection(req.QueryText, req.Selection.Start, req.Selection.End)
+		if err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+		executedStatement = &models.ExecutedStatement{Index: stmt.Index, Text: stmt.Text}
+		req.QueryText = stmt.Text
+	}
+
+	// Check if the query contains variable placeholders without defaults.
+	requiredVars := template.RequiredVariableNames(req.QueryText)
 
 	// Validate that all required variables are provided.
 	if len(requiredVars) > 0 && len(req.Variables) == 0 {
@@ -319,24 +225,11 @@ func (s *Server) handleQueryLogs(c *fiber.Ctx) error { //nolint:gocyclo // reque
 			models.ValidationErrorType)
 	}
 
-	// Perform template variable substitution if variables are provided.
-	processedQuery := req.QueryText
-	if len(req.Variables) > 0 {
-		vars := make([]template.Variable, len(req.Variables))
-		for i, v := range req.Variables {
-			vars[i] = template.Variable{
-				Name:  v.Name,
-				Type:  template.VariableType(v.Type),
-				Value: v.Value,
-			}
-		}
-
-		substituted, err := template.SubstituteVariables(req.QueryText, vars)
-		if err != nil {
-			return SendErrorWithType(c, fiber.StatusBadRequest,
-				fmt.Sprintf("Variable substitution failed: %v", err), models.ValidationErrorType)
-		}
-		processedQuery = substituted
+	// Perform template variable substitution, falling back to inline defaults.
+	processedQuery, err := s.substituteVariables(c.Context(), sourceID, req.QueryText, req.Variables)
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest,
+			fmt.Sprintf("Variable substitution failed: %v", err), models.ValidationErrorType)
 	}
 
 	// Prepare parameters for the core query function.
@@ -348,6 +241,14 @@ func (s *Server) handleQueryLogs(c *fiber.Ctx) error { //nolint:gocyclo // reque
 		MaxLimit:         s.config.Query.MaxPreviewLimit,
 		MaxResponseBytes: s.config.Query.MaxResponseBytes,
 		QueryTimeout:     req.QueryTimeout,
+		DerivedColumns:   req.DerivedColumns,
+		CostLimits:       s.config.Query.Cost.LimitsForTeam(teamID),
+		Sample:           req.Sample,
+		RowFilter:        teamRowFilter(c),
+		MaskedColumns:    teamMaskedColumns(c),
+		RequireTimeBound: s.config.Query.RequireTimeBound,
+		Dedupe:           req.Dedupe,
+		Distributed:      req.Distributed,
 	}
 	if req.StartTime != "" || req.EndTime != "" {
 		startTime, endTime, err := parseRFC3339TimeRange(req.StartTime, req.EndTime)
@@ -370,6 +271,56 @@ func (s *Server) handleQueryLogs(c *fiber.Ctx) error { //nolint:gocyclo // reque
 		s.log.Error("failed to get source", "error", err, "source_id", sourceID)
 		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get source", models.DatabaseErrorType)
 	}
+	if source.IsClickHouse() {
+		if denied, err := s.denyRawSQL(c, teamID); denied {
+			return err
+		}
+	}
+	if executedStatement != nil {
+		if !source.IsClickHouse() {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Run selection is only supported for ClickHouse sources", models.ValidationErrorType)
+		}
+		if err := clickhouse.ValidateSingleSelect(processedQuery); err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+	}
+	// Derived columns are checked against the source schema up front so a bad
+	// expression is a 400 rather than an error inside the streamed body.
+	if len(req.DerivedColumns) > 0 {
+		if !source.IsClickHouse() {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Derived columns are only supported for ClickHouse sources", models.ValidationErrorType)
+		}
+		schema := make([]string, 0, len(source.Columns))
+		for _, col := range source.Columns {
+			if !col.Virtual {
+				schema = append(schema, col.Name)
+			}
+		}
+		if err := clickhouse.ValidateDerivedColumns(req.DerivedColumns, schema); err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+	}
+	if req.Sample != 0 {
+		if !source.IsClickHouse() {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Sampling is only supported for ClickHouse sources", models.ValidationErrorType)
+		}
+		if err := clickhouse.ValidateSampleRatio(req.Sample); err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+	}
+	if req.Dedupe != nil {
+		if err := req.Dedupe.Validate(); err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+	}
+	if req.Distributed != nil {
+		if !source.IsClickHouse() {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Shard routing is only supported for ClickHouse sources", models.ValidationErrorType)
+		}
+		if err := req.Distributed.Validate(); err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+	}
 	// Dashboard panel requests may opt into the per-dashboard result cache. The
 	// cache key is computed from the finalized (post-substitution) executable
 	// query and the resolved parameters; source.UpdatedAt invalidates entries on
@@ -392,17 +343,22 @@ func (s *Server) handleQueryLogs(c *fiber.Ctx) error { //nolint:gocyclo // reque
 			SourceRevision:   source.UpdatedAt.UnixNano(),
 			EffTTLSeconds:    int64(effTTL / time.Second),
 			Language:         string(models.QueryLanguageClickHouseSQL),
-			FinalizedQuery:   processedQuery,
+			FinalizedQuery:   processedQuery + derivedColumnsCacheSuffix(req.DerivedColumns) + sampleCacheSuffix(req.Sample) + dedupeCacheSuffix(req.Dedupe) + distributedCacheSuffix(req.Distributed),
 			CanonicalStart:   canonCacheTime(params.StartTime),
 			CanonicalEnd:     canonCacheTime(params.EndTime),
 			Timezone:         req.Timezone,
 			EffectiveLimit:   int64(effLimit),
 			QueryTimeoutSecs: int64(*req.QueryTimeout),
+			RowFilter:        params.RowFilter,
+			Masked:           len(params.MaskedColumns) > 0,
 		})
 	}
 
 	if source.IsClickHouse() {
-		cfg := queryStreamConfig{logsKey: "data"}
+		s.recordAudit(c, models.AuditActionQueryExecuteSQL, models.AuditResourceSource, auditID(sourceID), &teamID, map[string]any{
+			"query_text": truncateAuditText(processedQuery),
+		})
+		cfg := queryStreamConfig{logsKey: "data", executedStatement: executedStatement, includeCount: req.IncludeCount}
 		// OOM guardrail: only the dashboard-directive path buffers (bounded by
 		// max_entry_bytes); on overflow the fill errors and we fall through to the
 		// unbuffered streaming path below, which is left byte-for-byte unchanged.
@@ -446,7 +402,7 @@ func (s *Server) handleQueryLogs(c *fiber.Ctx) error { //nolint:gocyclo // reque
 	defer cancel() // Ensure cleanup
 
 	// Add query to tracker atomically with admission control.
-	queryID, err := queryTracker.StartQuery(
+	queryID, err := s.queries.StartQuery(
 		QueryClassPreview,
 		user.ID,
 		sourceID,
@@ -454,18 +410,22 @@ func (s *Server) handleQueryLogs(c *fiber.Ctx) error { //nolint:gocyclo // reque
 		req.QueryText,
 		cancel,
 		s.config.Query.MaxConcurrentPerUser,
+		s.config.Query.MaxConcurrentPerTeam,
 		s.config.Query.MaxConcurrentGlobal,
 	)
 	if err != nil {
-		var admissionErr *QueryAdmissionError
-		if errors.As(err, &admissionErr) {
-			return SendErrorWithType(c, fiber.StatusTooManyRequests, admissionErr.Message, models.ValidationErrorType)
-		}
-		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to track query", models.GeneralErrorType)
+		return sendAdmissionError(c, err, "Failed to track query")
+	}
+	defer s.queries.RemoveQuery(queryID) // Ensure cleanup
+	queryCtx = clickhouse.ContextWithQueryID(queryCtx, queryID)
+
+	var totalCount <-chan int64
+	if req.IncludeCount {
+		totalCount = s.startTotalCount(queryCtx, sourceID, params)
 	}
-	defer queryTracker.RemoveQuery(queryID) // Ensure cleanup
 
 	// Execute query via core function with cancellable context.
+	start := time.Now()
 	result, err := core.QueryLogs(queryCtx, s.datasources, sourceID, params)
 	if err != nil {
 		if errors.Is(err, core.ErrSourceNotFound) {
@@ -477,6 +437,15 @@ func (s *Server) handleQueryLogs(c *fiber.Ctx) error { //nolint:gocyclo // reque
 		if datasource.IsValidationError(err) {
 			return SendErrorWithType(c, fiber.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err), models.ValidationErrorType)
 		}
+		if errors.Is(err, clickhouse.ErrSourceBusy) {
+			return SendErrorWithType(c, fiber.StatusServiceUnavailable, "Source is busy running other queries, try again shortly", models.ExternalServiceErrorType)
+		}
+		user, _ := c.Locals("user").(*models.User)
+		s.recordFailedQuery(user, teamID, sourceID, req.QueryText, models.QueryLanguageClickHouseSQL, time.Since(start).Milliseconds(), err)
+		var costErr *models.QueryCostError
+		if errors.As(err, &costErr) {
+			return sendQueryCostError(c, costErr)
+		}
 		s.log.Error("failed to query logs", "error", err, "source_id", sourceID)
 		return SendErrorWithType(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to query logs: %v", err), models.DatabaseErrorType)
 	}
@@ -497,7 +466,7 @@ func (s *Server) handleQueryLogs(c *fiber.Ctx) error { //nolint:gocyclo // reque
 			"truncated", result.Stats.Truncated,
 		)
 		s.recordQueryHistory(user, teamID, sourceID, req.QueryText, models.QueryLanguageClickHouseSQL,
-			int64(result.Stats.ExecutionTimeMs), int64(len(result.Logs)))
+			int64(result.Stats.ExecutionTimeMs), int64(len(result.Logs)), int64(result.Stats.RowsRead))
 	}
 
 	// Add query ID to the response for frontend tracking
@@ -511,6 +480,9 @@ func (s *Server) handleQueryLogs(c *fiber.Ctx) error { //nolint:gocyclo // reque
 			"columns":  columns,
 			"warnings": result.Warnings,
 		}
+		if total, ok := <-totalCount; ok {
+			responseWithQueryID["total_count"] = total
+		}
 		return SendSuccess(c, fiber.StatusOK, responseWithQueryID)
 	}
 
@@ -532,10 +504,11 @@ func (s *Server) handleCancelQuery(c *fiber.Ctx) error {
 	}
 
 	// Try to cancel the query
-	cancelled := queryTracker.CancelQuery(queryID, user.ID)
+	query, cancelled := s.queries.CancelQuery(queryID, user.ID)
 	if !cancelled {
 		return SendErrorWithType(c, fiber.StatusNotFound, "Query not found or already completed", models.NotFoundErrorType)
 	}
+	s.killClickHouseQuery(c.Context(), query.SourceID, query.ID)
 
 	s.log.Debug("query cancelled", "query_id", queryID, "user_id", user.ID)
 
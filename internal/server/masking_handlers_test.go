@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestRejectMasked(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		masked models.MaskedColumns
+		want   int
+	}{
+		{name: "no masks", want: fiber.StatusNoContent},
+		{name: "masked columns", masked: models.MaskedColumns{{Column: "email", Mode: models.MaskHash}}, want: fiber.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			app := fiber.New()
+			s := &Server{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+			app.Get("/tail", func(c *fiber.Ctx) error {
+				c.Locals("maskedColumns", tc.masked)
+				return c.Next()
+			}, s.rejectMasked, func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusNoContent)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/tail", http.NoBody))
+			if err != nil {
+				t.Fatalf("app.Test: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tc.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tc.want)
+			}
+		})
+	}
+}
+
+// Alerts evaluate the unmasked data, so a member who sees the source masked
+// can't create, update or test one.
+func TestAlertsRequireUnmaskedSource(t *testing.T) {
+	s, member, _, src := newRawSQLTestServer(t, true)
+	app := fiber.New()
+	withUser(app, http.MethodPost, "/alerts", member, s.handleCreateAlert)
+	withUser(app, http.MethodPost, "/alerts/test", member, s.handleTestAlertQuery)
+	withUser(app, http.MethodPut, "/alerts/:alertID", member, s.handleUpdateAlert)
+
+	body := fmt.Sprintf(`{"source_id":%d,"name":"errors","query_language":"clickhouse-sql","editor_mode":"native","query":"SELECT count() FROM logs","lookback_seconds":300,"threshold_operator":"gt","threshold_value":1,"frequency_seconds":60,"severity":"warning"}`, src.ID)
+	if got := doRawSQLRequest(t, app, http.MethodPost, "/alerts", body); got != http.StatusCreated {
+		t.Fatalf("unmasked create status = %d, want %d", got, http.StatusCreated)
+	}
+
+	src.MaskedColumns = models.MaskedColumns{{Column: "email", Mode: models.MaskHash}}
+	if err := s.sqlite.UpdateSource(context.Background(), src); err != nil {
+		t.Fatalf("UpdateSource: %v", err)
+	}
+	for _, req := range []struct {
+		method, path, body string
+	}{
+		{http.MethodPost, "/alerts", body},
+		{http.MethodPost, "/alerts/test", body},
+		{http.MethodPut, "/alerts/1", `{"name":"renamed"}`},
+	} {
+		if got := doRawSQLRequest(t, app, req.method, req.path, req.body); got != http.StatusForbidden {
+			t.Errorf("%s %s status = %d, want %d", req.method, req.path, got, http.StatusForbidden)
+		}
+	}
+}
+
+// rowsProvider answers every query with one row holding an email.
+type rowsProvider struct {
+	fakeSQLProvider
+}
+
+func (rowsProvider) QueryLogs(context.Context, *models.Source, datasource.QueryRequest) (*models.QueryResult, error) {
+	return &models.QueryResult{
+		Columns: []models.ColumnInfo{{Name: "email", Type: "String"}},
+		Logs:    []map[string]any{{"email": "jane@example.com"}},
+	}, nil
+}
+
+// A notebook cell run that skips the source's masks isn't cached, since the
+// notebook's other readers may see those columns masked.
+func TestRunNotebookCellSkipsCachingUnmaskedValues(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		masked     models.MaskedColumns
+		wantCached bool
+	}{
+		{name: "no masks", wantCached: true},
+		{name: "masked columns", masked: models.MaskedColumns{{Column: "email", Mode: models.MaskHash}}, wantCached: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, _, team, src := newRawSQLTestServer(t, true)
+			s.datasources.Register(rowsProvider{})
+			s.queries = newQueryTracker(nil, "test", 0)
+			s.config.Query.DefaultTimeoutSeconds = 30
+			ctx := context.Background()
+			src.MaskedColumns = tc.masked
+			if err := s.sqlite.UpdateSource(ctx, src); err != nil {
+				t.Fatalf("UpdateSource: %v", err)
+			}
+			admin := mkTestUser(t, s.sqlite, "admin@test.dev", models.UserRoleAdmin)
+			if err := s.sqlite.AddTeamMember(ctx, team.ID, admin.ID, models.TeamRoleAdmin); err != nil {
+				t.Fatalf("AddTeamMember: %v", err)
+			}
+			cells := fmt.Sprintf(`[{"id":"sql","type":"query","content":"SELECT email FROM logs","source_id":%d,"query_language":"clickhouse-sql","start_time":"2026-01-01T00:00:00Z","end_time":"2026-01-01T01:00:00Z"}]`, src.ID)
+			notebook := &models.Notebook{TeamID: team.ID, Name: "Outage", CellsJSON: json.RawMessage(cells), CreatedBy: &admin.ID}
+			if err := s.sqlite.CreateNotebook(ctx, notebook); err != nil {
+				t.Fatalf("CreateNotebook: %v", err)
+			}
+
+			app := fiber.New()
+			withUser(app, http.MethodPost, "/teams/:teamID/notebooks/:notebookID/cells/:cellID/run", admin, s.handleRunNotebookCell)
+			path := fmt.Sprintf("/teams/%d/notebooks/%d/cells/sql/run", team.ID, notebook.ID)
+			resp, err := app.Test(httptest.NewRequest(http.MethodPost, path, http.NoBody))
+			if err != nil {
+				t.Fatalf("app.Test: %v", err)
+			}
+			defer resp.Body.Close()
+			var body struct {
+				Data struct {
+					Cached bool `json:"cached"`
+				} `json:"data"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK || body.Data.Cached != tc.wantCached {
+				t.Fatalf("status = %d, cached = %v, want 200 and %v", resp.StatusCode, body.Data.Cached, tc.wantCached)
+			}
+
+			stored, err := s.sqlite.GetNotebook(ctx, notebook.ID)
+			if err != nil {
+				t.Fatalf("GetNotebook: %v", err)
+			}
+			if hasResult := strings.Contains(string(stored.CellsJSON), "jane@example.com"); hasResult != tc.wantCached {
+				t.Errorf("stored cells = %s, want result cached = %v", stored.CellsJSON, tc.wantCached)
+			}
+		})
+	}
+}
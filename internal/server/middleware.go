@@ -5,6 +5,7 @@ import (
 	"github.com/mr-karan/logchef/internal/metrics"
 	"github.com/mr-karan/logchef/pkg/models"
 
+	"crypto/subtle"
 	"errors"
 	"strings"
 
@@ -138,6 +139,21 @@ func (s *Server) authenticateWithSession(c *fiber.Ctx) error {
 
 // requireAdmin is middleware that ensures the authenticated user has the global 'admin' role.
 // It assumes requireAuth has already run and placed the user in the context.
+// requireMetricsToken guards /metrics with metrics.token when one is set. The
+// endpoint is scraped by Prometheus rather than a user, so it takes the static
+// token instead of sessions or API tokens.
+func (s *Server) requireMetricsToken(c *fiber.Ctx) error {
+	if s.config.Metrics.Token == "" {
+		return c.Next()
+	}
+	token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Metrics.Token)) != 1 {
+		c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+		return SendErrorWithType(c, fiber.StatusUnauthorized, "Invalid or missing metrics token", models.AuthenticationErrorType)
+	}
+	return c.Next()
+}
+
 func (s *Server) requireAdmin(c *fiber.Ctx) error {
 	user, ok := c.Locals("user").(*models.User)
 	if !ok || user == nil {
@@ -211,6 +227,21 @@ func (s *Server) requireTeamNotManaged(c *fiber.Ctx) error {
 	return c.Next()
 }
 
+// requireAlertNotManaged rejects mutations on config-managed alerts.
+func (s *Server) requireAlertNotManaged(c *fiber.Ctx) error {
+	alertID, err := parseAlertID(c)
+	if err != nil {
+		return c.Next()
+	}
+	managed, err := s.sqlite.IsAlertManaged(c.Context(), alertID)
+	if err == nil && managed {
+		return SendErrorWithType(c, fiber.StatusForbidden,
+			"This alert is managed by provisioning config and cannot be modified via API",
+			models.ManagedResourceErrorType)
+	}
+	return c.Next()
+}
+
 // requireAnyTeamAdmin is middleware that ensures the authenticated user is an admin of at least one team,
 // or is a global admin. This is used for endpoints that should be accessible to team admins
 // without requiring a specific team context (e.g., listing users to add to teams).
@@ -319,6 +350,81 @@ func (s *Server) requireTeamAdminOrGlobalAdmin(c *fiber.Ctx) error {
 	return c.Next()
 }
 
+// requireTeamPermission returns middleware that ensures the authenticated
+// user's role in the ':teamID' team grants perm. Global admins bypass the check.
+// It assumes requireAuth has already run.
+func (s *Server) requireTeamPermission(perm models.TeamPermission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(*models.User)
+		if !ok || user == nil {
+			return SendErrorWithType(c, fiber.StatusUnauthorized, "Authentication context missing", models.AuthenticationErrorType)
+		}
+		if user.Role == models.UserRoleAdmin {
+			return c.Next()
+		}
+
+		teamID, err := core.ParseTeamID(c.Params("teamID"))
+		if err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+		}
+
+		allowed, err := core.UserHasTeamPermission(c.Context(), s.sqlite, teamID, user.ID, perm)
+		if err != nil {
+			s.log.Error("failed to check team permission", "error", err, "team_id", teamID, "user_id", user.ID, "permission", perm)
+			return SendError(c, fiber.StatusInternalServerError, "Failed to verify team permissions")
+		}
+		if !allowed {
+			metrics.RecordAuthorizationFailure(c.Route().Path, user, "insufficient_team_role")
+			return SendErrorWithType(c, fiber.StatusForbidden, "Your team role does not permit this action", models.AuthorizationErrorType)
+		}
+		return c.Next()
+	}
+}
+
+// checkSourcePermission verifies the caller's team roles on a source grant
+// perm, sending a 403 (or 500 on lookup failure) when they don't. It reports
+// whether the handler may proceed; when it returns false the response has
+// already been written and the returned error should be returned as-is.
+func (s *Server) checkSourcePermission(c *fiber.Ctx, user *models.User, sourceID models.SourceID, perm models.TeamPermission) (bool, error) {
+	allowed, err := core.UserHasSourcePermission(c.Context(), s.sqlite, user, sourceID, perm)
+	if err != nil {
+		s.log.Error("failed to check source permission", "error", err, "source_id", sourceID, "permission", perm)
+		return false, SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to verify permissions", models.GeneralErrorType)
+	}
+	if !allowed {
+		metrics.RecordAuthorizationFailure(c.Route().Path, user, "insufficient_team_role")
+		return false, SendErrorWithType(c, fiber.StatusForbidden, "Your team role on this source does not permit this action", models.AuthorizationErrorType)
+	}
+	return true, nil
+}
+
+// checkWholeSourceAccess verifies the caller reads the source without a row
+// filter and sees it unmasked through one of their teams, sending a 403 (or
+// 500 on lookup failure) when they don't. It guards queries that run outside
+// any one team's scope, such as alerts, which evaluate the whole source. It
+// reports whether the handler may proceed, as checkSourcePermission does.
+func (s *Server) checkWholeSourceAccess(c *fiber.Ctx, user *models.User, sourceID models.SourceID) (bool, error) {
+	unfiltered, err := core.UserHasUnfilteredSourceAccess(c.Context(), s.sqlite, s.log, user.ID, sourceID)
+	if err != nil {
+		s.log.Error("failed to check source row filters", "error", err, "source_id", sourceID)
+		return false, SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to verify access", models.GeneralErrorType)
+	}
+	if !unfiltered {
+		return false, SendErrorWithType(c, fiber.StatusForbidden,
+			"This isn't available for a source with a row filter for your team", models.AuthorizationErrorType)
+	}
+	unmasked, err := core.UserSeesSourceUnmasked(c.Context(), s.sqlite, s.log, user, sourceID)
+	if err != nil {
+		s.log.Error("failed to check source masked columns", "error", err, "source_id", sourceID)
+		return false, SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to verify access", models.GeneralErrorType)
+	}
+	if !unmasked {
+		return false, SendErrorWithType(c, fiber.StatusForbidden,
+			"This isn't available for a source with columns masked for you", models.AuthorizationErrorType)
+	}
+	return true, nil
+}
+
 // requireTeamHasSource is a middleware that verifies if the requested team has access to the specified source.
 // This must be used after requireTeamMember to ensure team membership is already verified.
 func (s *Server) requireTeamHasSource(c *fiber.Ctx) error {
@@ -349,6 +455,23 @@ func (s *Server) requireTeamHasSource(c *fiber.Ctx) error {
 		return SendError(c, fiber.StatusForbidden, "Team does not have access to this source")
 	}
 
+	// Carry the link's row filter to the handlers; see teamRowFilter.
+	rowFilter, err := core.GetTeamSourceRowFilter(c.Context(), s.sqlite, teamID, sourceID)
+	if err != nil {
+		s.log.Error("Error getting team-source row filter", "error", err, "team_id", teamID, "source_id", sourceID)
+		return SendError(c, fiber.StatusInternalServerError, "Failed to verify team source access")
+	}
+	c.Locals("rowFilter", rowFilter)
+
+	// Carry the columns masked for the user too; see teamMaskedColumns.
+	user, _ := c.Locals("user").(*models.User)
+	masked, err := core.MaskedColumnsForUser(c.Context(), s.sqlite, user, teamID, sourceID)
+	if err != nil {
+		s.log.Error("Error getting masked columns", "error", err, "team_id", teamID, "source_id", sourceID)
+		return SendError(c, fiber.StatusInternalServerError, "Failed to verify team source access")
+	}
+	c.Locals("maskedColumns", masked)
+
 	// Team has access to the source, continue with the request
 	return c.Next()
 }
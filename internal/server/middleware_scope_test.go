@@ -9,6 +9,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/mr-karan/logchef/internal/config"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
@@ -77,3 +78,35 @@ func TestRequireTokenScopeSkipsSessions(t *testing.T) {
 		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusNoContent)
 	}
 }
+
+func TestRequireMetricsToken(t *testing.T) {
+	t.Parallel()
+	const token = "metrics-token-0123456789"
+	tests := []struct {
+		configured, auth string
+		want             int
+	}{
+		{"", "", fiber.StatusOK},
+		{token, "", fiber.StatusUnauthorized},
+		{token, "Bearer wrong", fiber.StatusUnauthorized},
+		{token, token, fiber.StatusUnauthorized},
+		{token, "Bearer " + token, fiber.StatusOK},
+	}
+	for _, tt := range tests {
+		s := &Server{config: &config.Config{Metrics: config.MetricsConfig{Enabled: true, Token: tt.configured}}}
+		app := fiber.New()
+		app.Get("/metrics", s.requireMetricsToken, func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+		req := httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody)
+		if tt.auth != "" {
+			req.Header.Set(fiber.HeaderAuthorization, tt.auth)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("token %q, Authorization %q: status = %d, want %d", tt.configured, tt.auth, resp.StatusCode, tt.want)
+		}
+	}
+}
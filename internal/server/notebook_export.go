@@ -0,0 +1,126 @@
+package server
+
+// Static notebook report rendering (JSON and self-contained HTML).
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// notebookReport is the exported form of a notebook: its metadata plus the
+// decoded cells with their cached results.
+type notebookReport struct {
+	ID             int                   `json:"id"`
+	TeamID         models.TeamID         `json:"team_id"`
+	Name           string                `json:"name"`
+	Description    string                `json:"description,omitempty"`
+	CreatedByName  string                `json:"created_by_name,omitempty"`
+	CreatedByEmail string                `json:"created_by_email,omitempty"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+	ExportedAt     time.Time             `json:"exported_at"`
+	Cells          []models.NotebookCell `json:"cells"`
+}
+
+func renderNotebookJSON(report *notebookReport) ([]byte, error) {
+	return json.MarshalIndent(report, "", "  ")
+}
+
+// notebookHTMLCell is a cell flattened for the HTML template: result rows are
+// pre-rendered to strings in column order.
+type notebookHTMLCell struct {
+	models.NotebookCell
+	Columns   []string
+	Rows      [][]string
+	Histogram *datasource.HistogramResult
+}
+
+// Markdown cells and cell notes are rendered as escaped pre-wrapped text rather
+// than HTML so the report stays dependency-free and can't carry injected markup.
+var notebookHTMLTemplate = template.Must(template.New("notebook").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} · LogChef notebook</title>
+<style>
+body{font-family:system-ui,-apple-system,sans-serif;max-width:1100px;margin:2rem auto;padding:0 1rem;color:#1f2328}
+header{border-bottom:1px solid #d0d7de;margin-bottom:1.5rem}
+.meta{color:#656d76;font-size:.85rem}
+.cell{margin:1.25rem 0}
+.markdown{white-space:pre-wrap;line-height:1.5}
+.notes{border-left:3px solid #d0d7de;padding-left:.75rem;margin-top:.75rem}
+pre.query{background:#f6f8fa;padding:.75rem;overflow-x:auto;border-radius:6px}
+table{border-collapse:collapse;font-size:.8rem;width:100%;display:block;overflow-x:auto}
+th,td{border:1px solid #d0d7de;padding:.25rem .5rem;text-align:left;vertical-align:top;white-space:pre-wrap}
+th{background:#f6f8fa}
+.error{color:#cf222e}
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Report.Name}}</h1>
+{{with .Report.Description}}<p>{{.}}</p>{{end}}
+<p class="meta">{{with .Report.CreatedByName}}By {{.}} · {{end}}Last updated {{.Report.UpdatedAt.UTC.Format "2006-01-02 15:04:05 MST"}} · Exported {{.Report.ExportedAt.Format "2006-01-02 15:04:05 MST"}}</p>
+</header>
+{{range .Cells}}<section class="cell">
+{{if eq .Type "markdown"}}<div class="markdown">{{.Content}}</div>
+{{else}}<p class="meta">{{.Type}}{{if .Pinned}} · pinned{{end}} · source {{.SourceID}} · {{.QueryLanguage}} · {{.StartTime}} → {{.EndTime}}{{with .Window}} · window {{.}}{{end}}{{with .GroupBy}} · group by {{.}}{{end}}</p>
+<pre class="query">{{.Content}}</pre>
+{{with .Result}}<p class="meta">Executed {{.ExecutedAt.UTC.Format "2006-01-02 15:04:05 MST"}}{{if .RowsTruncated}} · showing first rows only{{end}}</p>
+{{with .Error}}<p class="error">{{.}}</p>{{end}}{{else}}<p class="meta">Not run yet.</p>
+{{end}}{{if .Columns}}<table><thead><tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr></thead><tbody>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</tbody></table>
+{{end}}{{with .Histogram}}<table><thead><tr><th>bucket ({{.Granularity}})</th><th>group</th><th>count</th></tr></thead><tbody>
+{{range .Data}}<tr><td>{{.Bucket.UTC.Format "2006-01-02 15:04:05"}}</td><td>{{.GroupValue}}</td><td>{{.LogCount}}</td></tr>
+{{end}}</tbody></table>
+{{end}}{{with .Notes}}<div class="markdown notes">{{.}}</div>
+{{end}}{{end}}</section>
+{{end}}</body>
+</html>
+`))
+
+func renderNotebookHTML(report *notebookReport) ([]byte, error) {
+	cells := make([]notebookHTMLCell, 0, len(report.Cells))
+	for _, cell := range report.Cells {
+		hc := notebookHTMLCell{NotebookCell: cell}
+		if res := cell.Result; res != nil {
+			for _, col := range res.Columns {
+				hc.Columns = append(hc.Columns, col.Name)
+			}
+			for _, row := range res.Rows {
+				values := make([]string, len(hc.Columns))
+				for i, name := range hc.Columns {
+					if v, ok := row[name]; ok && v != nil {
+						values[i] = fmt.Sprint(v)
+					}
+				}
+				hc.Rows = append(hc.Rows, values)
+			}
+			if len(res.Histogram) > 0 {
+				var hist datasource.HistogramResult
+				if err := json.Unmarshal(res.Histogram, &hist); err != nil {
+					return nil, fmt.Errorf("decoding histogram of cell %q: %w", cell.ID, err)
+				}
+				hc.Histogram = &hist
+			}
+		}
+		cells = append(cells, hc)
+	}
+
+	var buf bytes.Buffer
+	err := notebookHTMLTemplate.Execute(&buf, struct {
+		Name   string
+		Report *notebookReport
+		Cells  []notebookHTMLCell
+	}{Name: report.Name, Report: report, Cells: cells})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
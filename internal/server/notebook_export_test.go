@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestRenderNotebookHTML(t *testing.T) {
+	report := &notebookReport{
+		ID:         7,
+		Name:       "API outage <postmortem>",
+		UpdatedAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		ExportedAt: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC),
+		Cells: []models.NotebookCell{
+			{ID: "md", Type: models.NotebookCellMarkdown, Content: "Root cause: <script>alert(1)</script>"},
+			{
+				ID: "q", Type: models.NotebookCellQuery, Content: "SELECT level, msg FROM logs", SourceID: 1,
+				QueryLanguage: models.QueryLanguageClickHouseSQL, Pinned: true,
+				Notes: "Timeouts start <here>",
+				Result: &models.NotebookCellResult{
+					ExecutedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+					Columns:    []models.ColumnInfo{{Name: "level"}, {Name: "msg"}},
+					Rows:       []map[string]any{{"level": "error", "msg": "upstream <timeout>"}},
+				},
+			},
+			{
+				ID: "h", Type: models.NotebookCellHistogram, Content: "SELECT 1", SourceID: 1,
+				Result: &models.NotebookCellResult{
+					Histogram: json.RawMessage(`{"granularity":"1m","data":[{"bucket":"2026-01-02T00:00:00Z","log_count":42}]}`),
+				},
+			},
+			{ID: "pending", Type: models.NotebookCellQuery, Content: "SELECT 2", SourceID: 1},
+		},
+	}
+
+	body, err := renderNotebookHTML(report)
+	if err != nil {
+		t.Fatalf("renderNotebookHTML: %v", err)
+	}
+	html := string(body)
+
+	for _, want := range []string{
+		"API outage &lt;postmortem&gt;",
+		"Root cause: &lt;script&gt;alert(1)&lt;/script&gt;",
+		"<th>level</th><th>msg</th>",
+		"<td>error</td><td>upstream &lt;timeout&gt;</td>",
+		"<td>42</td>",
+		"query · pinned · source 1",
+		`<div class="markdown notes">Timeouts start &lt;here&gt;</div>`,
+		"Not run yet.",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("rendered HTML missing %q", want)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("markdown content was not escaped")
+	}
+}
@@ -0,0 +1,383 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/clickhouse"
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/pkg/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func parseNotebookID(c *fiber.Ctx) (int, error) {
+	id, err := parsePositiveIntParam(c, "notebookID")
+	return int(id), err
+}
+
+// loadTeamNotebook resolves the :teamID/:notebookID route params to a notebook
+// of that team. When ok is false the error response has already been written
+// and err should be returned as-is.
+func (s *Server) loadTeamNotebook(c *fiber.Ctx) (*models.Notebook, bool, error) {
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return nil, false, SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	id, err := parseNotebookID(c)
+	if err != nil {
+		return nil, false, SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+	notebook, err := core.GetNotebook(c.Context(), s.sqlite, s.log, teamID, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotebookNotFound) {
+			return nil, false, SendErrorWithType(c, fiber.StatusNotFound, "Notebook not found", models.NotFoundErrorType)
+		}
+		return nil, false, SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to load notebook", models.GeneralErrorType)
+	}
+	return notebook, true, nil
+}
+
+// setNotebookCanEdit populates the per-request CanEdit UI hint for the caller.
+func (s *Server) setNotebookCanEdit(c *fiber.Ctx, notebook *models.Notebook, user *models.User) error {
+	canEdit, err := core.UserCanEditNotebook(c.Context(), s.sqlite, notebook, user)
+	if err != nil {
+		return err
+	}
+	notebook.CanEdit = &canEdit
+	return nil
+}
+
+// handleListNotebooks lists a team's notebooks (without cells), newest-updated first.
+func (s *Server) handleListNotebooks(c *fiber.Ctx) error {
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	notebooks, err := core.ListNotebooks(c.Context(), s.sqlite, teamID)
+	if err != nil {
+		s.log.Error("failed to list notebooks", "team_id", teamID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to list notebooks", models.GeneralErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, notebooks)
+}
+
+// handleCreateNotebook creates a notebook in the team, owned by the caller.
+// The route requires the notebook-authoring team permission.
+func (s *Server) handleCreateNotebook(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+
+	var req models.CreateNotebookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	notebook, err := core.CreateNotebook(c.Context(), s.sqlite, s.log, user, teamID, &req)
+	if err != nil {
+		if errors.Is(err, core.ErrInvalidNotebook) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+		s.log.Error("failed to create notebook", "team_id", teamID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to create notebook", models.GeneralErrorType)
+	}
+	canEdit := true
+	notebook.CanEdit = &canEdit
+	return SendSuccess(c, fiber.StatusCreated, notebook)
+}
+
+// handleGetNotebook returns a notebook with its cells and cached results.
+func (s *Server) handleGetNotebook(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	notebook, ok, err := s.loadTeamNotebook(c)
+	if !ok {
+		return err
+	}
+	if err := s.setNotebookCanEdit(c, notebook, user); err != nil {
+		s.log.Error("failed to check notebook edit access", "notebook_id", notebook.ID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to load notebook", models.GeneralErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, notebook)
+}
+
+// handleUpdateNotebook replaces a notebook's name, description and cells.
+// Editing is allowed for the creator, team admins/editors and global admins;
+// a stale write (updated_at older than the stored row) is rejected with 409.
+func (s *Server) handleUpdateNotebook(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	id, err := parseNotebookID(c)
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+
+	var req models.UpdateNotebookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	updated, updateErr := core.UpdateNotebook(c.Context(), s.sqlite, s.log, teamID, id, user, &req)
+	if updateErr != nil {
+		switch {
+		case errors.Is(updateErr, core.ErrInvalidNotebook):
+			return SendErrorWithType(c, fiber.StatusBadRequest, updateErr.Error(), models.ValidationErrorType)
+		case errors.Is(updateErr, core.ErrNotebookForbidden):
+			return SendErrorWithType(c, fiber.StatusForbidden, updateErr.Error(), models.AuthorizationErrorType)
+		case errors.Is(updateErr, core.ErrNotebookConflict):
+			return SendErrorWithType(c, fiber.StatusConflict, "Notebook was modified by someone else; reload and reapply your changes", models.ConflictErrorType)
+		case errors.Is(updateErr, core.ErrNotebookNotFound):
+			return SendErrorWithType(c, fiber.StatusNotFound, "Notebook not found", models.NotFoundErrorType)
+		default:
+			s.log.Error("failed to update notebook", "notebook_id", id, "error", updateErr)
+			return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to update notebook", models.GeneralErrorType)
+		}
+	}
+	canEdit := true
+	updated.CanEdit = &canEdit
+	return SendSuccess(c, fiber.StatusOK, updated)
+}
+
+// handleDeleteNotebook removes a notebook (same edit rule as update).
+func (s *Server) handleDeleteNotebook(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	notebook, ok, err := s.loadTeamNotebook(c)
+	if !ok {
+		return err
+	}
+	canEdit, err := core.UserCanEditNotebook(c.Context(), s.sqlite, notebook, user)
+	if err != nil {
+		s.log.Error("failed to check notebook edit access", "notebook_id", notebook.ID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to verify edit access", models.GeneralErrorType)
+	}
+	if !canEdit {
+		return SendErrorWithType(c, fiber.StatusForbidden, core.ErrNotebookForbidden.Error(), models.AuthorizationErrorType)
+	}
+
+	if err := core.DeleteNotebook(c.Context(), s.sqlite, s.log, notebook.ID); err != nil {
+		if errors.Is(err, core.ErrNotebookNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Notebook not found", models.NotFoundErrorType)
+		}
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to delete notebook", models.GeneralErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Notebook deleted"})
+}
+
+// handleRunNotebookCell executes one query or histogram cell on the server.
+// Any team member may run a cell; the result is cached into the notebook only
+// when the caller can edit it, so viewers get live results without writing,
+// and never over a pinned result or with values of masked columns.
+// Runs go through the query tracker, so they count against the same
+// concurrency limits and can be cancelled like explorer queries.
+func (s *Server) handleRunNotebookCell(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	notebook, ok, err := s.loadTeamNotebook(c)
+	if !ok {
+		return err
+	}
+
+	cell, err := core.FindNotebookCell(notebook, c.Params("cellID"))
+	if err != nil {
+		if errors.Is(err, core.ErrNotebookCellNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Notebook cell not found", models.NotFoundErrorType)
+		}
+		s.log.Error("failed to read notebook cells", "notebook_id", notebook.ID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to load notebook", models.GeneralErrorType)
+	}
+	if !cell.IsExecutable() {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Only query and histogram cells can be run", models.ValidationErrorType)
+	}
+
+	// The team/source link was checked when the cell was saved, but the source
+	// may have been unlinked since.
+	linked, err := core.TeamHasSourceAccess(c.Context(), s.sqlite, notebook.TeamID, cell.SourceID)
+	if err != nil {
+		s.log.Error("failed to check notebook cell source access", "notebook_id", notebook.ID, "source_id", cell.SourceID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to verify source access", models.GeneralErrorType)
+	}
+	if !linked {
+		return SendErrorWithType(c, fiber.StatusForbidden, "This cell's source is no longer linked to the team", models.AuthorizationErrorType)
+	}
+	// A cell written in the source's own SQL follows the team's raw SQL
+	// switch like /logs/query; LogchefQL cells always run.
+	if models.NormalizeQueryLanguage(cell.QueryLanguage) != models.QueryLanguageLogchefQL {
+		source, err := core.GetSource(c.Context(), s.datasources, cell.SourceID)
+		if err != nil {
+			if errors.Is(err, core.ErrSourceNotFound) {
+				return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+			}
+			s.log.Error("failed to get notebook cell source", "notebook_id", notebook.ID, "source_id", cell.SourceID, "error", err)
+			return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get source", models.DatabaseErrorType)
+		}
+		if source.IsClickHouse() {
+			if denied, err := s.denyRawSQL(c, notebook.TeamID); denied {
+				return err
+			}
+		}
+	}
+
+	rowFilter, err := core.GetTeamSourceRowFilter(c.Context(), s.sqlite, notebook.TeamID, cell.SourceID)
+	if err != nil {
+		s.log.Error("failed to get notebook cell row filter", "notebook_id", notebook.ID, "source_id", cell.SourceID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to verify source access", models.GeneralErrorType)
+	}
+	masked, ok, err := s.sourceMaskedColumnsForUser(c, notebook.TeamID, cell.SourceID)
+	if !ok {
+		return err
+	}
+	// The source's masks as anyone without view_unmasked sees them. A run
+	// that skipped them holds raw values the notebook's other readers may not
+	// see, so its result isn't cached.
+	sourceMasked, err := core.MaskedColumnsForUser(c.Context(), s.sqlite, nil, notebook.TeamID, cell.SourceID)
+	if err != nil {
+		s.log.Error("failed to get notebook cell masked columns", "notebook_id", notebook.ID, "source_id", cell.SourceID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to verify source access", models.GeneralErrorType)
+	}
+	unmaskedRun := len(masked) == 0 && len(sourceMasked) > 0
+
+	timeout := s.config.Query.DefaultTimeoutSeconds
+	opts := core.NotebookRunOptions{
+		DefaultLimit:     s.config.Query.DefaultPreviewLimit,
+		MaxLimit:         s.config.Query.MaxPreviewLimit,
+		MaxResponseBytes: s.config.Query.MaxResponseBytes,
+		QueryTimeout:     &timeout,
+		RowFilter:        rowFilter,
+		MaskedColumns:    masked,
+		RequireTimeBound: s.config.Query.RequireTimeBound,
+	}
+
+	runCtx, cancel := context.WithTimeout(c.Context(), time.Duration(timeout)*time.Second)
+	defer cancel()
+	queryID, err := s.queries.StartQuery(
+		QueryClassPreview,
+		user.ID,
+		cell.SourceID,
+		notebook.TeamID,
+		cell.Content,
+		cancel,
+		s.config.Query.MaxConcurrentPerUser,
+		s.config.Query.MaxConcurrentPerTeam,
+		s.config.Query.MaxConcurrentGlobal,
+	)
+	if err != nil {
+		return sendAdmissionError(c, err, "Failed to track query")
+	}
+	defer s.queries.RemoveQuery(queryID)
+	runCtx = clickhouse.ContextWithQueryID(runCtx, queryID)
+
+	result, err := core.RunNotebookCell(runCtx, s.datasources, cell, opts)
+	if err != nil {
+		if errors.Is(err, core.ErrSourceNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		s.log.Error("failed to run notebook cell", "notebook_id", notebook.ID, "cell_id", cell.ID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to run notebook cell", models.GeneralErrorType)
+	}
+	s.log.Info("notebook.cell.run",
+		"user", user.Email,
+		"team_id", notebook.TeamID,
+		"notebook_id", notebook.ID,
+		"cell_id", cell.ID,
+		"source_id", cell.SourceID,
+		"query_id", queryID,
+		"failed", result.Error != "",
+	)
+
+	canEdit, err := core.UserCanEditNotebook(c.Context(), s.sqlite, notebook, user)
+	if err != nil {
+		s.log.Error("failed to check notebook edit access", "notebook_id", notebook.ID, "error", err)
+	}
+	cached := false
+	if canEdit && !unmaskedRun {
+		switch err := core.CacheNotebookCellResult(c.Context(), s.sqlite, s.log, notebook.ID, cell, result); {
+		case errors.Is(err, core.ErrNotebookCellPinned):
+			// The pinned sample stays; the caller still sees the live result.
+		case err != nil:
+			s.log.Error("failed to cache notebook cell result", "notebook_id", notebook.ID, "cell_id", cell.ID, "error", err)
+		default:
+			cached = true
+		}
+	}
+
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{
+		"query_id": queryID,
+		"cell_id":  cell.ID,
+		"result":   result,
+		"cached":   cached,
+	})
+}
+
+// handleExportNotebook renders a notebook with its cached results as a
+// static report: JSON (default) or a self-contained HTML page (?format=html).
+// Nothing is executed; export reflects the results as last refreshed.
+func (s *Server) handleExportNotebook(c *fiber.Ctx) error {
+	notebook, ok, err := s.loadTeamNotebook(c)
+	if !ok {
+		return err
+	}
+	report, ok, err := s.renderNotebookReport(c, notebook)
+	if !ok {
+		return err
+	}
+	c.Set("Content-Type", report.contentType)
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", report.fileName))
+	return c.Status(fiber.StatusOK).Send(report.body)
+}
+
+// renderedNotebook is a notebook report ready to download or store.
+type renderedNotebook struct {
+	format      string
+	fileName    string
+	contentType string
+	body        []byte
+}
+
+// renderNotebookReport renders the notebook in the ?format the request asks
+// for (json by default). When ok is false the error response has already been
+// written and err should be returned as-is.
+func (s *Server) renderNotebookReport(c *fiber.Ctx, notebook *models.Notebook) (*renderedNotebook, bool, error) {
+	cells, err := models.ParseNotebookCells(notebook.CellsJSON)
+	if err != nil {
+		s.log.Error("failed to read notebook cells", "notebook_id", notebook.ID, "error", err)
+		return nil, false, SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to export notebook", models.GeneralErrorType)
+	}
+
+	report := notebookReport{
+		ID:             notebook.ID,
+		TeamID:         notebook.TeamID,
+		Name:           notebook.Name,
+		Description:    notebook.Description,
+		CreatedByName:  notebook.CreatedByName,
+		CreatedByEmail: notebook.CreatedByEmail,
+		UpdatedAt:      notebook.UpdatedAt,
+		ExportedAt:     time.Now().UTC(),
+		Cells:          cells,
+	}
+
+	rendered := &renderedNotebook{format: c.Query("format", "json")}
+	var extension string
+	switch rendered.format {
+	case "json":
+		rendered.body, err = renderNotebookJSON(&report)
+		rendered.contentType, extension = fiber.MIMEApplicationJSONCharsetUTF8, "json"
+	case "html":
+		rendered.body, err = renderNotebookHTML(&report)
+		rendered.contentType, extension = fiber.MIMETextHTMLCharsetUTF8, "html"
+	default:
+		return nil, false, SendErrorWithType(c, fiber.StatusBadRequest, fmt.Sprintf("Unsupported export format %q (use json or html)", rendered.format), models.ValidationErrorType)
+	}
+	if err != nil {
+		s.log.Error("failed to render notebook export", "notebook_id", notebook.ID, "error", err)
+		return nil, false, SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to export notebook", models.GeneralErrorType)
+	}
+
+	rendered.fileName = fmt.Sprintf("logchef-notebook-%d-%s.%s", notebook.ID, report.ExportedAt.Format("20060102-150405"), extension)
+	return rendered, true, nil
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/mr-karan/logchef/internal/artifacts"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// Notebook snapshots are rendered notebook reports kept in artifact storage
+// for [storage] snapshot_ttl, so an investigation can be shared as it stood
+// at a point in time.
+
+// handleCreateNotebookSnapshot renders the notebook like the export endpoint
+// (?format=json|html) and stores the result.
+func (s *Server) handleCreateNotebookSnapshot(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	notebook, ok, err := s.loadTeamNotebook(c)
+	if !ok {
+		return err
+	}
+	report, ok, err := s.renderNotebookReport(c, notebook)
+	if !ok {
+		return err
+	}
+
+	now := time.Now().UTC()
+	snapshot := &models.NotebookSnapshot{
+		ID:         uuid.New().String(),
+		NotebookID: notebook.ID,
+		TeamID:     notebook.TeamID,
+		Format:     report.format,
+		FileName:   report.fileName,
+		SizeBytes:  int64(len(report.body)),
+		CreatedBy:  &user.ID,
+		ExpiresAt:  now.Add(s.config.Storage.SnapshotTTL),
+		CreatedAt:  now,
+	}
+	snapshot.ObjectKey = fmt.Sprintf("notebooks/%d/%s.%s", notebook.ID, snapshot.ID, report.format)
+
+	if err := s.artifacts.Put(c.Context(), snapshot.ObjectKey, bytes.NewReader(report.body), snapshot.SizeBytes, report.contentType); err != nil {
+		s.log.Error("failed to store notebook snapshot", "notebook_id", notebook.ID, "backend", s.artifacts.Name(), "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to store snapshot", models.GeneralErrorType)
+	}
+	if err := s.sqlite.CreateNotebookSnapshot(c.Context(), snapshot); err != nil {
+		if delErr := s.artifacts.Delete(context.WithoutCancel(c.Context()), snapshot.ObjectKey); delErr != nil {
+			s.log.Warn("failed to remove unrecorded notebook snapshot", "key", snapshot.ObjectKey, "error", delErr)
+		}
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to store snapshot", models.GeneralErrorType)
+	}
+	snapshot.DownloadURL = notebookSnapshotDownloadURL(snapshot)
+	return SendSuccess(c, fiber.StatusCreated, snapshot)
+}
+
+// handleListNotebookSnapshots lists a notebook's unexpired snapshots, newest first.
+func (s *Server) handleListNotebookSnapshots(c *fiber.Ctx) error {
+	notebook, ok, err := s.loadTeamNotebook(c)
+	if !ok {
+		return err
+	}
+	all, err := s.sqlite.ListNotebookSnapshots(c.Context(), notebook.ID)
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to list snapshots", models.GeneralErrorType)
+	}
+	now := time.Now()
+	snapshots := make([]*models.NotebookSnapshot, 0, len(all))
+	for _, snapshot := range all {
+		if now.After(snapshot.ExpiresAt) {
+			continue // awaiting cleanup
+		}
+		snapshot.DownloadURL = notebookSnapshotDownloadURL(snapshot)
+		snapshots = append(snapshots, snapshot)
+	}
+	return SendSuccess(c, fiber.StatusOK, snapshots)
+}
+
+// handleDownloadNotebookSnapshot serves a stored snapshot.
+func (s *Server) handleDownloadNotebookSnapshot(c *fiber.Ctx) error {
+	snapshot, ok, err := s.loadNotebookSnapshot(c)
+	if !ok {
+		return err
+	}
+	if time.Now().After(snapshot.ExpiresAt) {
+		return SendErrorWithType(c, fiber.StatusGone, "Snapshot has expired", models.NotFoundErrorType)
+	}
+
+	contentType := fiber.MIMEApplicationJSONCharsetUTF8
+	if snapshot.Format == "html" {
+		contentType = fiber.MIMETextHTMLCharsetUTF8
+	}
+	if err := s.sendArtifact(c, snapshot.ObjectKey, snapshot.FileName, contentType, snapshot.SizeBytes); err != nil {
+		if errors.Is(err, artifacts.ErrNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Snapshot is no longer available", models.NotFoundErrorType)
+		}
+		s.log.Error("failed to serve notebook snapshot", "snapshot_id", snapshot.ID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to download snapshot", models.GeneralErrorType)
+	}
+	return nil
+}
+
+// handleDeleteNotebookSnapshot removes a snapshot before it expires.
+func (s *Server) handleDeleteNotebookSnapshot(c *fiber.Ctx) error {
+	snapshot, ok, err := s.loadNotebookSnapshot(c)
+	if !ok {
+		return err
+	}
+	if err := s.deleteNotebookSnapshot(c.Context(), snapshot); err != nil {
+		if models.IsNotFound(err) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Snapshot not found", models.NotFoundErrorType)
+		}
+		s.log.Error("failed to delete notebook snapshot", "snapshot_id", snapshot.ID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to delete snapshot", models.GeneralErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Snapshot deleted"})
+}
+
+// loadNotebookSnapshot resolves :snapshotID to a snapshot of the notebook in
+// the route. When ok is false the error response has already been written.
+func (s *Server) loadNotebookSnapshot(c *fiber.Ctx) (*models.NotebookSnapshot, bool, error) {
+	notebook, ok, err := s.loadTeamNotebook(c)
+	if !ok {
+		return nil, false, err
+	}
+	id := strings.TrimSpace(c.Params("snapshotID"))
+	snapshot, err := s.sqlite.GetNotebookSnapshot(c.Context(), id)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return nil, false, SendErrorWithType(c, fiber.StatusNotFound, "Snapshot not found", models.NotFoundErrorType)
+		}
+		return nil, false, SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to load snapshot", models.GeneralErrorType)
+	}
+	if snapshot.NotebookID != notebook.ID || snapshot.TeamID != notebook.TeamID {
+		return nil, false, SendErrorWithType(c, fiber.StatusNotFound, "Snapshot not found", models.NotFoundErrorType)
+	}
+	return snapshot, true, nil
+}
+
+// deleteNotebookSnapshot deletes the stored object, then the row, so a
+// failure in between leaves the row for the cleanup loop to retry.
+func (s *Server) deleteNotebookSnapshot(ctx context.Context, snapshot *models.NotebookSnapshot) error {
+	if err := s.artifacts.Delete(ctx, snapshot.ObjectKey); err != nil {
+		return err
+	}
+	return s.sqlite.DeleteNotebookSnapshot(ctx, snapshot.ID)
+}
+
+func (s *Server) cleanupExpiredNotebookSnapshots(ctx context.Context, now time.Time) {
+	expired, err := s.sqlite.ListExpiredNotebookSnapshots(ctx, now)
+	if err != nil {
+		s.log.Warn("failed to list expired notebook snapshots", "error", err)
+		return
+	}
+	for _, snapshot := range expired {
+		if err := s.deleteNotebookSnapshot(ctx, snapshot); err != nil && !models.IsNotFound(err) {
+			s.log.Warn("failed to delete expired notebook snapshot", "snapshot_id", snapshot.ID, "error", err)
+		}
+	}
+}
+
+func notebookSnapshotDownloadURL(snapshot *models.NotebookSnapshot) string {
+	return fmt.Sprintf("/api/v1/teams/%d/notebooks/%d/snapshots/%s/download", snapshot.TeamID, snapshot.NotebookID, snapshot.ID)
+}
+
+// sendArtifact serves a stored artifact as a download named fileName: a
+// redirect to a short-lived signed URL when the backend issues them, so large
+// files don't pass through Logchef, otherwise the object streamed through the
+// API. size is the stored size, or <= 0 if unknown.
+func (s *Server) sendArtifact(c *fiber.Ctx, key, fileName, contentType string, size int64) error {
+	signed, err := s.artifacts.SignedURL(c.Context(), key, fileName, s.config.Storage.SignedURLTTL)
+	if err != nil {
+		return err
+	}
+	if signed != "" {
+		return c.Redirect(signed, fiber.StatusFound)
+	}
+
+	body, err := s.artifacts.Open(c.Context(), key)
+	if err != nil {
+		return err
+	}
+	c.Attachment(fileName)
+	c.Set(fiber.HeaderContentType, contentType)
+	if size <= 0 {
+		size = -1
+	}
+	return c.SendStream(body, int(size))
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/artifacts"
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestNotebookSnapshots(t *testing.T) {
+	s := newDashboardTestServer(t)
+	store, err := artifacts.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	s.artifacts = store
+	s.config = &config.Config{Storage: config.StorageConfig{SnapshotTTL: time.Hour, SignedURLTTL: time.Minute}}
+
+	ctx := context.Background()
+	user := mkTestUser(t, s.sqlite, "author@test.dev", models.UserRoleMember)
+	team, _ := mkTestTeam(t, s.sqlite, "snapshots", user)
+	notebook := &models.Notebook{TeamID: team.ID, Name: "Outage", CellsJSON: json.RawMessage(`[{"id":"c1","type":"markdown","content":"# Outage"}]`), CreatedBy: &user.ID}
+	if err := s.sqlite.CreateNotebook(ctx, notebook); err != nil {
+		t.Fatalf("CreateNotebook: %v", err)
+	}
+	other := &models.Notebook{TeamID: team.ID, Name: "Other", CellsJSON: json.RawMessage(`[]`), CreatedBy: &user.ID}
+	if err := s.sqlite.CreateNotebook(ctx, other); err != nil {
+		t.Fatalf("CreateNotebook: %v", err)
+	}
+
+	app := fiber.New()
+	base := "/teams/:teamID/notebooks/:notebookID/snapshots"
+	withUser(app, http.MethodPost, base, user, s.handleCreateNotebookSnapshot)
+	withUser(app, http.MethodGet, base, user, s.handleListNotebookSnapshots)
+	withUser(app, http.MethodGet, base+"/:snapshotID/download", user, s.handleDownloadNotebookSnapshot)
+	withUser(app, http.MethodDelete, base+"/:snapshotID", user, s.handleDeleteNotebookSnapshot)
+	do := func(method, path string) (*http.Response, []byte) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(method, path, http.NoBody))
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return resp, body
+	}
+	prefix := fmt.Sprintf("/teams/%d/notebooks/%d/snapshots", team.ID, notebook.ID)
+
+	resp, body := do(http.MethodPost, prefix+"?format=html")
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create: %d %s", resp.StatusCode, body)
+	}
+	var created struct {
+		Data models.NotebookSnapshot `json:"data"`
+	}
+	if err := json.Unmarshal(body, &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+	snap := created.Data
+	if snap.Format != "html" || snap.SizeBytes == 0 || !strings.HasSuffix(snap.DownloadURL, "/snapshots/"+snap.ID+"/download") {
+		t.Fatalf("created snapshot = %+v", snap)
+	}
+
+	resp, body = do(http.MethodGet, prefix)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), snap.ID) {
+		t.Fatalf("list: %d %s", resp.StatusCode, body)
+	}
+
+	// Local storage has no signed URLs, so the API streams the report.
+	resp, body = do(http.MethodGet, prefix+"/"+snap.ID+"/download")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "Outage") ||
+		!strings.Contains(resp.Header.Get(fiber.HeaderContentDisposition), snap.FileName) {
+		t.Fatalf("download: %d %q %s", resp.StatusCode, resp.Header.Get(fiber.HeaderContentDisposition), body)
+	}
+
+	// A snapshot is only reachable through its own notebook.
+	otherPrefix := fmt.Sprintf("/teams/%d/notebooks/%d/snapshots", team.ID, other.ID)
+	if resp, _ := do(http.MethodGet, otherPrefix+"/"+snap.ID+"/download"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("download via another notebook: %d, want 404", resp.StatusCode)
+	}
+
+	// Expired snapshots are swept: object first, then row.
+	row, err := s.sqlite.GetNotebookSnapshot(ctx, snap.ID)
+	if err != nil {
+		t.Fatalf("GetNotebookSnapshot: %v", err)
+	}
+	s.cleanupExpiredNotebookSnapshots(ctx, row.ExpiresAt.Add(time.Second))
+	if _, err := s.sqlite.GetNotebookSnapshot(ctx, snap.ID); !models.IsNotFound(err) {
+		t.Errorf("snapshot row after cleanup: %v", err)
+	}
+	if _, err := store.Open(ctx, row.ObjectKey); !errors.Is(err, artifacts.ErrNotFound) {
+		t.Errorf("snapshot object after cleanup: %v", err)
+	}
+
+	resp, body = do(http.MethodPost, prefix+"?format=json")
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create json: %d %s", resp.StatusCode, body)
+	}
+	if err := json.Unmarshal(body, &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+	if resp, body := do(http.MethodDelete, prefix+"/"+created.Data.ID); resp.StatusCode != http.StatusOK {
+		t.Fatalf("delete: %d %s", resp.StatusCode, body)
+	}
+	if resp, _ := do(http.MethodGet, prefix+"/"+created.Data.ID+"/download"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("download after delete: %d, want 404", resp.StatusCode)
+	}
+	if resp, _ := do(http.MethodPost, prefix+"?format=pdf"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unsupported format: %d, want 400", resp.StatusCode)
+	}
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"errors"
+	"mime"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/ingest"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	// otlpRetryAfter is the Retry-After, in seconds, sent when a source's
+	// ingest queue is full. OTLP exporters back off and retry on 429.
+	otlpRetryAfter = "1"
+)
+
+// handleOTLPLogs is the OTLP/HTTP logs receiver. Exporters configured with
+// endpoint .../api/v1/otlp/:sourceID append /v1/logs themselves. Both the
+// binary protobuf and JSON encodings are accepted, optionally gzipped, and
+// the response uses the request's encoding. Rows are merged with other
+// requests for the source and acknowledged once inserted.
+// URL: POST /api/v1/otlp/:sourceID/v1/logs
+// Requires: ingest_logs on the source; API tokens also need logs:write.
+func (s *Server) handleOTLPLogs(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+	user, ok := c.Locals("user").(*models.User)
+	if !ok || user == nil {
+		return SendErrorWithType(c, fiber.StatusUnauthorized, "User context not found", models.AuthenticationErrorType)
+	}
+	if ok, err := s.checkSourcePermission(c, user, sourceID, models.TeamPermissionIngestLogs); !ok {
+		return err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(string(c.Request().Header.ContentType()))
+	mediaType = strings.ToLower(mediaType)
+	if mediaType != contentTypeProtobuf && mediaType != fiber.MIMEApplicationJSON {
+		return SendErrorWithType(c, fiber.StatusUnsupportedMediaType,
+			"Content-Type must be application/x-protobuf or application/json", models.ValidationErrorType)
+	}
+	body, err := s.readIngestBody(c)
+	if err != nil {
+		return sendIngestBodyError(c, err)
+	}
+
+	var rows []ingest.Row
+	if mediaType == contentTypeProtobuf {
+		rows, err = ingest.DecodeOTLPProto(body, s.config.Ingest.MaxRows)
+	} else {
+		rows, err = ingest.DecodeOTLPJSON(body, s.config.Ingest.MaxRows)
+	}
+	if err != nil {
+		return sendIngestDecodeError(c, err)
+	}
+
+	if len(rows) > 0 {
+		batch := ingest.EncodeRows(rows)
+		if err := s.otlpBatcher.Add(c.Context(), sourceID, batch); err != nil {
+			switch {
+			case errors.Is(err, ingest.ErrOverloaded):
+				c.Set(fiber.HeaderRetryAfter, otlpRetryAfter)
+				return SendErrorWithType(c, fiber.StatusTooManyRequests, err.Error(), models.ValidationErrorType)
+			case errors.Is(err, ingest.ErrBatcherClosed):
+				c.Set(fiber.HeaderRetryAfter, otlpRetryAfter)
+				return SendErrorWithType(c, fiber.StatusServiceUnavailable, "Server is shutting down", models.GeneralErrorType)
+			}
+			return s.sendIngestInsertError(c, sourceID, batch.Rows, err)
+		}
+	}
+
+	// An empty ExportLogsServiceResponse: full success.
+	if mediaType == contentTypeProtobuf {
+		c.Set(fiber.HeaderContentType, contentTypeProtobuf)
+		return c.Status(fiber.StatusOK).Send(nil)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/clickhouse"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// totalCountTimeout bounds the count behind include_count. It runs alongside
+// the query, so rows are never held up; only the end of the response waits
+// for the count, and never longer than this.
+const totalCountTimeout = 5 * time.Second
+
+// startTotalCount counts the rows params' query matches, ignoring its limit,
+// in the background. The channel yields the count and closes, or closes
+// without one when counting fails, times out or isn't supported by the
+// source: the count is a hint and never fails the query.
+func (s *Server) startTotalCount(ctx context.Context, sourceID models.SourceID, params datasource.QueryRequest) <-chan int64 {
+	timeout := int(totalCountTimeout / time.Second)
+	if params.QueryTimeout != nil && *params.QueryTimeout < timeout {
+		timeout = *params.QueryTimeout
+	}
+	params.QueryTimeout = &timeout
+	// ClickHouse rejects a second running query with the same ID.
+	if id, ok := clickhouse.QueryIDFromContext(ctx); ok {
+		ctx = clickhouse.ContextWithQueryID(ctx, id+"-count")
+	}
+
+	ch := make(chan int64, 1)
+	go func() {
+		defer close(ch)
+		countCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+		total, err := s.datasources.CountQuery(countCtx, sourceID, params)
+		if err != nil {
+			if !errors.Is(err, datasource.ErrOperationNotSupported) {
+				s.log.Debug("failed to count query rows", "error", err, "source_id", sourceID)
+			}
+			return
+		}
+		ch <- total
+	}()
+	return ch
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// FormatRequest is the body for formatting a native query.
+type FormatRequest struct {
+	// Query is a SQL query or a bare WHERE filter.
+	Query string `json:"query"`
+}
+
+// handleFormatQuery pretty-prints a SQL query and, where it is a simple filter
+// over the source's table, converts it back to LogchefQL so the explorer can
+// switch from SQL mode to LogchefQL without losing the query. A query that
+// doesn't parse is reported as valid=false; one that parses but doesn't
+// convert comes back with convertible=false and the reason.
+//
+// POST /api/v1/teams/:teamID/sources/:sourceID/logs/format
+func (s *Server) handleFormatQuery(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID format", models.ValidationErrorType)
+	}
+
+	var req FormatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	result, err := s.datasources.FormatQuery(c.Context(), sourceID, datasource.FormatRequest{Query: req.Query})
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		if errors.Is(err, datasource.ErrOperationNotSupported) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Query formatting is not supported for this source type", models.ValidationErrorType)
+		}
+		s.log.Error("failed to format query", "error", err, "source_id", sourceID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to format query", models.GeneralErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, result)
+}
@@ -2,8 +2,14 @@ package server
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/events"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
@@ -11,12 +17,13 @@ import (
 // non-blocking: it fires a goroutine with its own short-lived context so a slow
 // or failing write never delays or fails the user's query. Errors are logged
 // and swallowed. Called only after a query executed successfully on the preview
-// paths.
-func (s *Server) recordQueryHistory(user *models.User, teamID models.TeamID, sourceID models.SourceID, queryText string, language models.QueryLanguage, durationMs, rowCount int64) {
+// paths; failures go through recordFailedQuery. rowsRead is what the query
+// scanned and counts toward the team's daily quota.
+func (s *Server) recordQueryHistory(user *models.User, teamID models.TeamID, sourceID models.SourceID, queryText string, language models.QueryLanguage, durationMs, rowCount, rowsRead int64) {
 	if user == nil {
 		return
 	}
-	entry := &models.QueryHistory{
+	s.persistQueryHistory(&models.QueryHistory{
 		UserID:        user.ID,
 		TeamID:        teamID,
 		SourceID:      sourceID,
@@ -24,19 +31,124 @@ func (s *Server) recordQueryHistory(user *models.User, teamID models.TeamID, sou
 		QueryLanguage: models.NormalizeQueryLanguage(language),
 		DurationMs:    durationMs,
 		RowCount:      rowCount,
+		RowsRead:      rowsRead,
+		Status:        models.QueryHistoryStatusSuccess,
+	})
+}
+
+// recordFailedQuery records a query that failed on a preview path, so users
+// can find and fix it later. Like recordQueryHistory it never blocks the
+// response. Failed queries stay out of the daily usage rollup.
+func (s *Server) recordFailedQuery(user *models.User, teamID models.TeamID, sourceID models.SourceID, queryText string, language models.QueryLanguage, durationMs int64, queryErr error) {
+	if user == nil || queryErr == nil {
+		return
 	}
+	s.persistQueryHistory(&models.QueryHistory{
+		UserID:        user.ID,
+		TeamID:        teamID,
+		SourceID:      sourceID,
+		QueryText:     queryText,
+		QueryLanguage: models.NormalizeQueryLanguage(language),
+		DurationMs:    durationMs,
+		Status:        models.QueryHistoryStatusError,
+		ErrorMessage:  queryErr.Error(),
+	})
+}
+
+// persistQueryHistory stores the record in the background and publishes it
+// as a query.executed event.
+func (s *Server) persistQueryHistory(entry *models.QueryHistory) {
+	events.Publish(models.EventQueryExecuted, models.QueryEventData{
+		UserID:     entry.UserID,
+		TeamID:     entry.TeamID,
+		SourceID:   entry.SourceID,
+		Language:   entry.QueryLanguage,
+		Status:     entry.Status,
+		DurationMs: entry.DurationMs,
+		RowCount:   entry.RowCount,
+		RowsRead:   entry.RowsRead,
+		Error:      entry.ErrorMessage,
+	})
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		if err := s.sqlite.RecordQueryHistory(ctx, entry); err != nil {
-			s.log.Warn("failed to record query history", "error", err, "user_id", entry.UserID, "source_id", sourceID)
+		if err := s.sqlite.RecordQueryHistory(ctx, entry, s.config.QueryHistory.MaxPerUser); err != nil {
+			s.log.Warn("failed to record query history", "error", err, "user_id", entry.UserID, "source_id", entry.SourceID)
+		}
+		if entry.Status != models.QueryHistoryStatusSuccess {
+			return
 		}
 		// Also increment the non-pruned daily rollup so all-time usage analytics
 		// stay correct even after query_history is pruned per user. Best-effort:
 		// log and swallow so recording never blocks or fails the query path.
 		bucketDate := time.Now().UTC().Format("2006-01-02")
-		if err := s.sqlite.IncrementQueryStats(ctx, bucketDate, entry.UserID, teamID, sourceID, entry.QueryLanguage, durationMs); err != nil {
-			s.log.Warn("failed to increment query stats", "error", err, "user_id", entry.UserID, "source_id", sourceID)
+		if err := s.sqlite.IncrementQueryStats(ctx, bucketDate, entry.UserID, entry.TeamID, entry.SourceID, entry.QueryLanguage, entry.DurationMs, entry.RowsRead); err != nil {
+			s.log.Warn("failed to increment query stats", "error", err, "user_id", entry.UserID, "source_id", entry.SourceID)
 		}
 	}()
 }
+
+// parseQueryHistoryFilter reads the history list filters: ?search= (query
+// text, ignoring case), ?source_id=, ?status= and, on the team view, ?user_id=.
+func parseQueryHistoryFilter(c *fiber.Ctx, q models.ListQuery, allowUser bool) (models.QueryHistoryFilter, error) {
+	filter := models.QueryHistoryFilter{
+		Search: c.Query("search"),
+		Limit:  q.Limit,
+		Offset: q.Offset,
+	}
+	if raw := c.Query("source_id"); raw != "" {
+		id, err := core.ParseSourceID(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid source_id")
+		}
+		filter.SourceID = &id
+	}
+	switch status := models.QueryHistoryStatus(c.Query("status")); status {
+	case "", models.QueryHistoryStatusSuccess, models.QueryHistoryStatusError:
+		filter.Status = status
+	default:
+		return filter, fmt.Errorf("invalid status; allowed: success, error")
+	}
+	if raw := c.Query("user_id"); allowUser && raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			return filter, fmt.Errorf("invalid user_id")
+		}
+		userID := models.UserID(id)
+		filter.UserID = &userID
+	}
+	return filter, nil
+}
+
+// handleListTeamQueryHistory returns a page of the query history of every
+// member of a team, newest first. It takes the same paging and filters as
+// /me/query-history plus ?user_id=.
+// URL: GET /api/v1/teams/:teamID/query-history
+// Requires: team admin or global admin
+func (s *Server) handleListTeamQueryHistory(c *fiber.Ctx) error {
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	q, err := parseListQuery(c, queryHistoryListSpec)
+	if err != nil {
+		return sendListError(c, err)
+	}
+	filter, err := parseQueryHistoryFilter(c, q, true)
+	if err != nil {
+		return sendListError(c, err)
+	}
+	filter.TeamID = &teamID
+
+	history, err := s.sqlite.ListQueryHistory(c.Context(), filter)
+	if err != nil {
+		s.log.Error("failed to list team query history", "error", err, "team_id", teamID)
+		return SendError(c, fiber.StatusInternalServerError, "Error listing query history")
+	}
+	total, err := s.sqlite.CountQueryHistory(c.Context(), filter)
+	if err != nil {
+		s.log.Error("failed to count team query history", "error", err, "team_id", teamID)
+		return SendError(c, fiber.StatusInternalServerError, "Error listing query history")
+	}
+	return SendList(c, history, models.NewPagination(q, total))
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// LintRequest is the body for linting a query against a source.
+type LintRequest struct {
+	Query string `json:"query"`
+	// QueryLanguage is logchefql (the default) or clickhouse-sql.
+	QueryLanguage models.QueryLanguage `json:"query_language"`
+	// Limit is the row limit a LogchefQL query will run with.
+	Limit int `json:"limit"`
+}
+
+// handleLintQuery checks a LogchefQL or SQL query against the source's schema
+// without running it. A query that doesn't parse is reported as valid=false
+// with the parse error; one that does comes back with warnings about unknown
+// fields, regex on columns outside the sort key, a missing time filter, SELECT *
+// on a wide table and a limit above the maximum.
+//
+// POST /api/v1/teams/:teamID/sources/:sourceID/logs/lint
+func (s *Server) handleLintQuery(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID format", models.ValidationErrorType)
+	}
+
+	var req LintRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+	language := models.NormalizeQueryLanguage(req.QueryLanguage)
+	if language == "" {
+		language = models.QueryLanguageLogchefQL
+	}
+	if language != models.QueryLanguageLogchefQL && language != models.QueryLanguageClickHouseSQL {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "query_language must be logchefql or clickhouse-sql", models.ValidationErrorType)
+	}
+
+	// Linting fetches the table's schema, so bound it like the schema endpoint.
+	ctx, cancel := context.WithTimeout(c.Context(), SchemaTimeout)
+	defer cancel()
+
+	result, err := s.datasources.LintQuery(ctx, sourceID, datasource.LintRequest{
+		Query:    req.Query,
+		Language: language,
+		Limit:    req.Limit,
+		MaxLimit: s.config.Query.MaxPreviewLimit,
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		if errors.Is(err, datasource.ErrOperationNotSupported) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Query linting is not supported for this source type", models.ValidationErrorType)
+		}
+		s.log.Error("failed to lint query", "error", err, "source_id", sourceID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to lint query", models.GeneralErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, result)
+}
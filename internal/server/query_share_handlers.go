@@ -50,23 +50,24 @@ func (s *Server) handleCreateQueryShare(c *fiber.Ctx) error {
 	if err := json.Unmarshal(payloadBytes, &payload); err != nil {
 		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid share payload", models.ValidationErrorType)
 	}
-	if payload.Mode != "logchefql" && payload.Mode != "sql" {
-		return SendErrorWithType(c, fiber.StatusBadRequest, "payload.mode must be logchefql or sql", models.ValidationErrorType)
-	}
-	if payload.Mode == "sql" && strings.TrimSpace(payload.Query) == "" {
-		return SendErrorWithType(c, fiber.StatusBadRequest, "payload.query is required", models.ValidationErrorType)
+	if err := core.ValidateQuerySharePayload(&payload); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
 	}
 	if len(payload.Query) > s.config.Shares.MaxQueryTextBytes {
 		return SendErrorWithType(c, fiber.StatusBadRequest,
 			fmt.Sprintf("Shared query text cannot exceed %d bytes", s.config.Shares.MaxQueryTextBytes),
 			models.ValidationErrorType)
 	}
-	if payload.Limit < 0 {
-		return SendErrorWithType(c, fiber.StatusBadRequest, "payload.limit cannot be negative", models.ValidationErrorType)
-	}
 
 	ttl := s.config.Shares.DefaultTTL
-	if req.ExpiresInSeconds > 0 {
+	if req.Permanent {
+		if !s.config.Shares.AllowPermanent {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Permanent share links are disabled on this server", models.ValidationErrorType)
+		}
+		if req.ExpiresInSeconds > 0 {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "permanent and expires_in_seconds cannot be combined", models.ValidationErrorType)
+		}
+	} else if req.ExpiresInSeconds > 0 {
 		requested := time.Duration(req.ExpiresInSeconds) * time.Second
 		if requested > s.config.Shares.DefaultTTL {
 			return SendErrorWithType(c, fiber.StatusBadRequest,
@@ -83,13 +84,18 @@ func (s *Server) handleCreateQueryShare(c *fiber.Ctx) error {
 	}
 
 	now := time.Now().UTC()
+	var expiresAt *time.Time
+	if !req.Permanent {
+		t := now.Add(ttl)
+		expiresAt = &t
+	}
 	share := &models.QueryShare{
 		Token:     token,
 		SourceID:  sourceID,
 		TeamID:    &teamID,
 		CreatedBy: user.ID,
 		Payload:   append([]byte(nil), payloadBytes...),
-		ExpiresAt: now.Add(ttl),
+		ExpiresAt: expiresAt,
 		CreatedAt: now,
 	}
 	if err := s.sqlite.CreateQueryShare(c.Context(), share); err != nil {
@@ -118,7 +124,7 @@ func (s *Server) handleGetQueryShare(c *fiber.Ctx) error {
 		s.log.Error("failed to get query share", "error", err, "token", token)
 		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get share link", models.GeneralErrorType)
 	}
-	if time.Now().UTC().After(share.ExpiresAt) {
+	if share.ExpiresAt != nil && time.Now().UTC().After(*share.ExpiresAt) {
 		_ = s.sqlite.DeleteQueryShare(c.Context(), token)
 		return SendErrorWithType(c, fiber.StatusGone, "Share link has expired", models.NotFoundErrorType)
 	}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/pkg/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sendQuerySnippetError maps core query snippet errors onto responses.
+func (s *Server) sendQuerySnippetError(c *fiber.Ctx, err error, action string) error {
+	switch {
+	case errors.Is(err, core.ErrInvalidQuerySnippet):
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	case errors.Is(err, core.ErrQuerySnippetExists):
+		return SendErrorWithType(c, fiber.StatusConflict, err.Error(), models.ConflictErrorType)
+	case errors.Is(err, core.ErrQuerySnippetForbidden):
+		return SendErrorWithType(c, fiber.StatusForbidden, err.Error(), models.AuthorizationErrorType)
+	case errors.Is(err, core.ErrQuerySnippetNotFound):
+		return SendErrorWithType(c, fiber.StatusNotFound, "Query snippet not found", models.NotFoundErrorType)
+	default:
+		s.log.Error("failed to "+action+" query snippet", "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to "+action+" query snippet", models.GeneralErrorType)
+	}
+}
+
+// parseQuerySnippetRoute reads the :teamID and :snippetID route params. When
+// ok is false the error response has already been written and err should be
+// returned as-is.
+func parseQuerySnippetRoute(c *fiber.Ctx) (teamID models.TeamID, id models.QuerySnippetID, ok bool, err error) {
+	teamID, err = core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return 0, 0, false, SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	raw, err := parsePositiveIntParam(c, "snippetID")
+	if err != nil {
+		return 0, 0, false, SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+	return teamID, models.QuerySnippetID(raw), true, nil
+}
+
+// handleListQuerySnippets lists a team's snippets by name. ?q keeps those whose
+// name contains it, for the query editor's autocomplete.
+func (s *Server) handleListQuerySnippets(c *fiber.Ctx) error {
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+
+	snippets, err := core.ListQuerySnippets(c.Context(), s.sqlite, teamID, c.Query("q"))
+	if err != nil {
+		return s.sendQuerySnippetError(c, err, "list")
+	}
+	return SendSuccess(c, fiber.StatusOK, snippets)
+}
+
+// handleCreateQuerySnippet adds a snippet to the team's library.
+func (s *Server) handleCreateQuerySnippet(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+
+	var req models.QuerySnippetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	snippet, err := core.CreateQuerySnippet(c.Context(), s.sqlite, s.log, user, teamID, &req)
+	if err != nil {
+		return s.sendQuerySnippetError(c, err, "create")
+	}
+	return SendSuccess(c, fiber.StatusCreated, snippet)
+}
+
+// handleGetQuerySnippet returns one snippet.
+func (s *Server) handleGetQuerySnippet(c *fiber.Ctx) error {
+	teamID, id, ok, err := parseQuerySnippetRoute(c)
+	if !ok {
+		return err
+	}
+
+	snippet, err := core.GetQuerySnippet(c.Context(), s.sqlite, teamID, id)
+	if err != nil {
+		return s.sendQuerySnippetError(c, err, "load")
+	}
+	return SendSuccess(c, fiber.StatusOK, snippet)
+}
+
+// handleUpdateQuerySnippet replaces a snippet's name, description and query.
+func (s *Server) handleUpdateQuerySnippet(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, id, ok, err := parseQuerySnippetRoute(c)
+	if !ok {
+		return err
+	}
+
+	var req models.QuerySnippetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	snippet, err := core.UpdateQuerySnippet(c.Context(), s.sqlite, s.log, user, teamID, id, &req)
+	if err != nil {
+		return s.sendQuerySnippetError(c, err, "update")
+	}
+	return SendSuccess(c, fiber.StatusOK, snippet)
+}
+
+// handleDeleteQuerySnippet removes a snippet.
+func (s *Server) handleDeleteQuerySnippet(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, id, ok, err := parseQuerySnippetRoute(c)
+	if !ok {
+		return err
+	}
+
+	if err := core.DeleteQuerySnippet(c.Context(), s.sqlite, s.log, user, teamID, id); err != nil {
+		return s.sendQuerySnippetError(c, err, "delete")
+	}
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Query snippet deleted"})
+}
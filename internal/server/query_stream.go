@@ -5,10 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
+	"github.com/mr-karan/logchef/internal/clickhouse"
 	"github.com/mr-karan/logchef/internal/datasource"
 	"github.com/mr-karan/logchef/pkg/models"
 )
@@ -18,12 +20,16 @@ import (
 // responses. logsKey is the JSON key the log rows array is written under
 // ("data" for /logs/query, "logs" for /logchefql/query). The generated* fields
 // are emitted only for the LogchefQL endpoint (includeGenerated).
+// executedStatement is emitted only for "run selection" requests.
+// includeCount adds total_count when the request asked for it.
 type queryStreamConfig struct {
 	logsKey           string
 	includeGenerated  bool
 	generatedSQL      string
 	generatedQuery    string
 	generatedLanguage models.QueryLanguage
+	executedStatement *models.ExecutedStatement
+	includeCount      bool
 }
 
 // queryStreamWriter incrementally writes a success envelope
@@ -44,10 +50,13 @@ type queryStreamWriter struct {
 	queryID string
 
 	warnings []models.QueryWarning
-	begun    bool
-	firstRow bool
-	rows     int
-	closed   bool
+	// totalCount, when set, delivers the include_count result; see
+	// startTotalCount.
+	totalCount <-chan int64
+	begun      bool
+	firstRow   bool
+	rows       int
+	closed     bool
 }
 
 const queryStreamFlushEvery = 100
@@ -132,6 +141,11 @@ func (w *queryStreamWriter) WriteError(streamErr error) error {
 			"message":    streamErr.Error(),
 			"error_type": string(models.DatabaseErrorType),
 		}
+		var costErr *models.QueryCostError
+		if errors.As(streamErr, &costErr) {
+			payload["error_type"] = string(models.QueryCostErrorType)
+			payload["data"] = costErr
+		}
 		enc, err := json.Marshal(payload)
 		if err != nil {
 			return err
@@ -189,6 +203,20 @@ func (w *queryStreamWriter) closeEnvelope(stats models.QueryStats, streamErr err
 		}
 	}
 
+	if w.cfg.executedStatement != nil {
+		if err := w.writeJSONField("executed_statement", w.cfg.executedStatement); err != nil {
+			return err
+		}
+	}
+
+	if w.totalCount != nil && streamErr == nil {
+		if total, ok := <-w.totalCount; ok {
+			if err := w.writeJSONField("total_count", total); err != nil {
+				return err
+			}
+		}
+	}
+
 	if streamErr != nil {
 		if err := w.writeStringField("error", streamErr.Error()); err != nil {
 			return err
@@ -245,7 +273,7 @@ func (s *Server) streamPreviewQuery(
 ) error {
 	queryID := uuid.New().String()
 	streamCtx, cancel := context.WithCancel(c.Context())
-	if err := queryTracker.StartQueryWithID(
+	if err := s.queries.StartQueryWithID(
 		queryID,
 		QueryClassPreview,
 		user.ID,
@@ -254,28 +282,34 @@ func (s *Server) streamPreviewQuery(
 		trackerQueryText,
 		cancel,
 		s.config.Query.MaxConcurrentPerUser,
+		s.config.Query.MaxConcurrentPerTeam,
 		s.config.Query.MaxConcurrentGlobal,
 	); err != nil {
 		cancel()
-		var admissionErr *QueryAdmissionError
-		if errors.As(err, &admissionErr) {
-			return SendErrorWithType(c, fiber.StatusTooManyRequests, admissionErr.Message, models.ValidationErrorType)
-		}
-		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to track query", models.GeneralErrorType)
+		return sendAdmissionError(c, err, "Failed to track query")
 	}
 
+	streamCtx = clickhouse.ContextWithQueryID(streamCtx, queryID)
+
 	c.Status(fiber.StatusOK)
 	c.Set("Content-Type", "application/json; charset=utf-8")
 	c.Set("X-LogChef-Query-ID", queryID)
 
 	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
 		defer cancel()
-		defer queryTracker.RemoveQuery(queryID)
+		defer s.queries.RemoveQuery(queryID)
 
 		writer := newQueryStreamWriter(w, cfg, queryID)
+		if cfg.includeCount {
+			writer.totalCount = s.startTotalCount(streamCtx, sourceID, params)
+		}
+		start := time.Now()
 		stats, err := s.datasources.QueryLogsStream(streamCtx, sourceID, params, writer)
 		if err != nil {
 			s.log.Error("failed to stream query", "error", err, "source_id", sourceID, "query_id", queryID, "mode", logMode)
+			if !errors.Is(err, clickhouse.ErrSourceBusy) {
+				s.recordFailedQuery(user, teamID, sourceID, historyQueryText, historyLanguage, time.Since(start).Milliseconds(), err) //nolint:contextcheck // detached best-effort write
+			}
 			_ = writer.WriteError(err)
 			_ = w.Flush()
 			return
@@ -296,7 +330,7 @@ func (s *Server) streamPreviewQuery(
 		// Fire-and-forget with its own background context on purpose: the stream
 		// context is canceled once the response finishes writing.
 		s.recordQueryHistory(user, teamID, sourceID, historyQueryText, historyLanguage, //nolint:contextcheck // detached best-effort write
-			int64(stats.ExecutionTimeMs), int64(stats.RowsReturned))
+			int64(stats.ExecutionTimeMs), int64(stats.RowsReturned), int64(stats.RowsRead))
 		_ = w.Flush()
 	})
 
@@ -0,0 +1,411 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+const (
+	// staleQueryAge is how long a query may stay tracked before Cleanup
+	// cancels it.
+	staleQueryAge = time.Hour
+	// queryTrackerInterval is how often stale queries are cleaned up and
+	// long-running ones persisted.
+	queryTrackerInterval = 15 * time.Second
+	// killQueryTimeout bounds the KILL QUERY issued when a query is cancelled.
+	killQueryTimeout = 5 * time.Second
+)
+
+// QueryTracker manages active queries for admission control and
+// cancellation. With persistence enabled, queries that run longer than
+// persistAfter are also written to the metadata store (see Sync), so a
+// restarted instance can kill the ClickHouse queries it left behind.
+type QueryTracker struct {
+	mu      sync.RWMutex
+	queries map[string]*ActiveQuery
+	// draining is set once shutdown begins; no query is admitted after.
+	draining bool
+
+	store        store.TrackedQueryStore
+	instance     string
+	persistAfter time.Duration
+	// persisted holds the IDs currently stored. Only Sync touches it.
+	persisted map[string]struct{}
+}
+
+// newQueryTracker creates a tracker. persistAfter 0 keeps it in memory only.
+func newQueryTracker(st store.TrackedQueryStore, instance string, persistAfter time.Duration) *QueryTracker {
+	return &QueryTracker{
+		queries:      make(map[string]*ActiveQuery),
+		store:        st,
+		instance:     instance,
+		persistAfter: persistAfter,
+		persisted:    make(map[string]struct{}),
+	}
+}
+
+type QueryClass string
+
+const (
+	QueryClassPreview QueryClass = "preview"
+	QueryClassExport  QueryClass = "export"
+	QueryClassTail    QueryClass = "tail"
+)
+
+type QueryAdmissionError struct {
+	Message string
+}
+
+func (e *QueryAdmissionError) Error() string {
+	return e.Message
+}
+
+// ErrDraining is returned when a query is started after the server began
+// shutting down.
+var ErrDraining = errors.New("server is shutting down")
+
+// sendAdmissionError answers a request whose query was not admitted: 503
+// while draining, so the client retries elsewhere, 429 over a concurrency
+// cap, and 500 with message for anything else.
+func sendAdmissionError(c *fiber.Ctx, err error, message string) error {
+	var admissionErr *QueryAdmissionError
+	switch {
+	case errors.Is(err, ErrDraining):
+		return SendErrorWithType(c, fiber.StatusServiceUnavailable, "Server is shutting down, retry the query", models.GeneralErrorType)
+	case errors.As(err, &admissionErr):
+		return SendErrorWithType(c, fiber.StatusTooManyRequests, admissionErr.Message, models.ValidationErrorType)
+	}
+	return SendErrorWithType(c, fiber.StatusInternalServerError, message, models.GeneralErrorType)
+}
+
+// ActiveQuery represents an active query with its context for cancellation
+type ActiveQuery struct {
+	ID        string
+	Class     QueryClass
+	UserID    models.UserID
+	SourceID  models.SourceID
+	TeamID    models.TeamID
+	StartTime time.Time
+	QueryText string
+	Cancel    context.CancelFunc
+}
+
+// StartQuery registers a new active query atomically with admission control.
+func (qt *QueryTracker) StartQuery(class QueryClass, userID models.UserID, sourceID models.SourceID, teamID models.TeamID, sql string, cancel context.CancelFunc, maxPerUser, maxPerTeam, maxGlobal int) (string, error) {
+	queryID := uuid.New().String()
+	if err := qt.StartQueryWithID(queryID, class, userID, sourceID, teamID, sql, cancel, maxPerUser, maxPerTeam, maxGlobal); err != nil {
+		return "", err
+	}
+	return queryID, nil
+}
+
+// StartQueryWithID registers a new active query using a caller-provided ID.
+// Zero caps are unlimited; the team cap only applies when teamID is set.
+func (qt *QueryTracker) StartQueryWithID(queryID string, class QueryClass, userID models.UserID, sourceID models.SourceID, teamID models.TeamID, sql string, cancel context.CancelFunc, maxPerUser, maxPerTeam, maxGlobal int) error {
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+
+	if qt.draining {
+		return ErrDraining
+	}
+
+	userActive := 0
+	teamActive := 0
+	classActive := 0
+	for _, query := range qt.queries {
+		if query.Class != class {
+			continue
+		}
+		classActive++
+		if query.UserID == userID {
+			userActive++
+		}
+		if teamID != 0 && query.TeamID == teamID {
+			teamActive++
+		}
+	}
+
+	if maxPerUser > 0 && userActive >= maxPerUser {
+		return &QueryAdmissionError{Message: fmt.Sprintf("Too many active %s queries for this user. Limit is %d.", class, maxPerUser)}
+	}
+	if maxPerTeam > 0 && teamID != 0 && teamActive >= maxPerTeam {
+		return &QueryAdmissionError{Message: fmt.Sprintf("Too many active %s queries for this team. Limit is %d.", class, maxPerTeam)}
+	}
+	if maxGlobal > 0 && classActive >= maxGlobal {
+		return &QueryAdmissionError{Message: fmt.Sprintf("Too many active %s queries globally. Limit is %d.", class, maxGlobal)}
+	}
+
+	qt.queries[queryID] = &ActiveQuery{
+		ID:        queryID,
+		Class:     class,
+		UserID:    userID,
+		SourceID:  sourceID,
+		TeamID:    teamID,
+		StartTime: time.Now(),
+		QueryText: sql,
+		Cancel:    cancel,
+	}
+	return nil
+}
+
+// ActiveCount returns the number of running queries of class.
+func (qt *QueryTracker) ActiveCount(class QueryClass) int {
+	qt.mu.RLock()
+	defer qt.mu.RUnlock()
+	n := 0
+	for _, query := range qt.queries {
+		if query.Class == class {
+			n++
+		}
+	}
+	return n
+}
+
+// Len returns the number of running queries of any class.
+func (qt *QueryTracker) Len() int {
+	qt.mu.RLock()
+	defer qt.mu.RUnlock()
+	return len(qt.queries)
+}
+
+// StartDraining stops admitting queries; running ones are unaffected.
+func (qt *QueryTracker) StartDraining() {
+	qt.mu.Lock()
+	qt.draining = true
+	qt.mu.Unlock()
+}
+
+// Draining reports whether StartDraining was called.
+func (qt *QueryTracker) Draining() bool {
+	qt.mu.RLock()
+	defer qt.mu.RUnlock()
+	return qt.draining
+}
+
+// RemoveQuery removes a query from the tracker
+func (qt *QueryTracker) RemoveQuery(queryID string) {
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+	delete(qt.queries, queryID)
+}
+
+// List returns a snapshot of the running queries, oldest first.
+func (qt *QueryTracker) List() []ActiveQuery {
+	qt.mu.RLock()
+	defer qt.mu.RUnlock()
+	out := make([]ActiveQuery, 0, len(qt.queries))
+	for _, query := range qt.queries {
+		out = append(out, *query)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartTime.Before(out[j].StartTime) })
+	return out
+}
+
+// CancelQuery cancels a query if it exists and belongs to the user, returning
+// the cancelled query.
+func (qt *QueryTracker) CancelQuery(queryID string, userID models.UserID) (*ActiveQuery, bool) {
+	return qt.cancel(queryID, func(query *ActiveQuery) bool { return query.UserID == userID })
+}
+
+// KillQuery cancels any user's query. It backs the admin active-queries API.
+func (qt *QueryTracker) KillQuery(queryID string) (*ActiveQuery, bool) {
+	return qt.cancel(queryID, func(*ActiveQuery) bool { return true })
+}
+
+func (qt *QueryTracker) cancel(queryID string, allowed func(*ActiveQuery) bool) (*ActiveQuery, bool) {
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+
+	query, exists := qt.queries[queryID]
+	if !exists || !allowed(query) {
+		return nil, false
+	}
+
+	// Cancel the context
+	query.Cancel()
+
+	// Remove from tracker
+	delete(qt.queries, queryID)
+
+	return query, true
+}
+
+// Cleanup cancels and removes queries running longer than staleQueryAge and
+// returns them, so the caller can stop them on ClickHouse too.
+func (qt *QueryTracker) Cleanup() []*ActiveQuery {
+	cutoff := time.Now().Add(-staleQueryAge)
+	return qt.cancelWhere(func(query *ActiveQuery) bool { return query.StartTime.Before(cutoff) })
+}
+
+// CancelAll cancels and removes the running queries of the given classes, or
+// of every class when none are given, and returns them.
+func (qt *QueryTracker) CancelAll(classes ...QueryClass) []*ActiveQuery {
+	return qt.cancelWhere(func(query *ActiveQuery) bool {
+		return len(classes) == 0 || slices.Contains(classes, query.Class)
+	})
+}
+
+func (qt *QueryTracker) cancelWhere(match func(*ActiveQuery) bool) []*ActiveQuery {
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+
+	var removed []*ActiveQuery
+	for queryID, query := range qt.queries {
+		if match(query) {
+			query.Cancel()
+			delete(qt.queries, queryID)
+			removed = append(removed, query)
+		}
+	}
+	return removed
+}
+
+// Sync persists queries that have been running longer than persistAfter and
+// deletes the rows of persisted queries that have since finished. It is a
+// no-op without persistence. Sync must not be called concurrently.
+func (qt *QueryTracker) Sync(ctx context.Context) error {
+	if qt.store == nil || qt.persistAfter <= 0 {
+		return nil
+	}
+
+	var add []*models.TrackedQuery
+	var remove []string
+	cutoff := time.Now().Add(-qt.persistAfter)
+	qt.mu.RLock()
+	for id, query := range qt.queries {
+		if _, ok := qt.persisted[id]; !ok && query.StartTime.Before(cutoff) {
+			add = append(add, &models.TrackedQuery{
+				ID:        id,
+				Instance:  qt.instance,
+				Class:     string(query.Class),
+				UserID:    query.UserID,
+				TeamID:    query.TeamID,
+				SourceID:  query.SourceID,
+				QueryText: query.QueryText,
+				StartedAt: query.StartTime,
+			})
+		}
+	}
+	for id := range qt.persisted {
+		if _, ok := qt.queries[id]; !ok {
+			remove = append(remove, id)
+		}
+	}
+	qt.mu.RUnlock()
+
+	var errs []error
+	for _, query := range add {
+		if err := qt.store.UpsertTrackedQuery(ctx, query); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		qt.persisted[query.ID] = struct{}{}
+	}
+	for _, id := range remove {
+		if err := qt.store.DeleteTrackedQuery(ctx, id); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(qt.persisted, id)
+	}
+	return errors.Join(errs...)
+}
+
+// trackerInstance names this process's rows in tracked_queries. The hostname
+// is stable across restarts of the same host or container, which is what lets
+// a restarted instance recognise its own orphans.
+func trackerInstance() string {
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return "logchef"
+}
+
+// startQueryTrackerMaintenance cancels stale queries and, when persistence is
+// enabled, first kills the queries this instance's previous process left
+// running, then keeps the persisted set in sync.
+func (s *Server) startQueryTrackerMaintenance() {
+	s.wg.Go(func() {
+		s.recoverOrphanedQueries()
+
+		ticker := time.NewTicker(queryTrackerInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.maintainQueryTracker()
+			case <-s.stop:
+				return
+			}
+		}
+	})
+}
+
+func (s *Server) maintainQueryTracker() {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTrackerInterval)
+	defer cancel()
+
+	for _, query := range s.queries.Cleanup() {
+		s.log.Warn("cancelled stale query", "query_id", query.ID, "source_id", query.SourceID, "started_at", query.StartTime)
+		s.killClickHouseQuery(ctx, query.SourceID, query.ID)
+	}
+	if err := s.queries.Sync(ctx); err != nil {
+		s.log.Warn("failed to persist tracked queries", "error", err)
+	}
+}
+
+// recoverOrphanedQueries kills the queries persisted by this instance before
+// it restarted. Their requests died with the old process, but ClickHouse
+// keeps running them until they finish or time out.
+func (s *Server) recoverOrphanedQueries() {
+	if s.queries.store == nil || s.queries.persistAfter <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	orphans, err := s.sqlite.ListTrackedQueries(ctx, s.queries.instance)
+	if err != nil {
+		s.log.Warn("failed to list orphaned queries", "error", err)
+		return
+	}
+	for _, query := range orphans {
+		s.log.Info("killing query orphaned by restart", "query_id", query.ID, "source_id", query.SourceID, "user_id", query.UserID, "started_at", query.StartedAt)
+		s.killClickHouseQuery(ctx, query.SourceID, query.ID)
+		if err := s.sqlite.DeleteTrackedQuery(ctx, query.ID); err != nil {
+			s.log.Warn("failed to delete orphaned query", "query_id", query.ID, "error", err)
+		}
+	}
+}
+
+// killClickHouseQuery stops a cancelled query on the ClickHouse server.
+// Cancelling the context only stops LogChef waiting for rows; the server keeps
+// executing until it notices the dropped connection, which for a heavy scan
+// can take a long time. Failures are logged: the query is cancelled either way.
+func (s *Server) killClickHouseQuery(ctx context.Context, sourceID models.SourceID, queryID string) {
+	if s.clickhouse == nil {
+		return
+	}
+	client, err := s.clickhouse.GetConnection(sourceID)
+	if err != nil {
+		// Not a ClickHouse source, or it is disconnected.
+		return
+	}
+	killCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), killQueryTimeout)
+	defer cancel()
+	if err := client.KillQuery(killCtx, queryID); err != nil {
+		s.log.Warn("failed to kill query on clickhouse", "query_id", queryID, "source_id", sourceID, "error", err)
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestQueryTrackerSync(t *testing.T) {
+	s := newDashboardTestServer(t)
+	ctx := context.Background()
+	owner := mkTestUser(t, s.sqlite, "owner@test.dev", models.UserRoleMember)
+	team, src := mkTestTeam(t, s.sqlite, "payments", owner)
+
+	qt := newQueryTracker(s.sqlite, "host-a", time.Minute)
+	cancel := func() {}
+	if err := qt.StartQueryWithID("long", QueryClassExport, owner.ID, src.ID, team.ID, "SELECT 1", cancel, 0, 0, 0); err != nil {
+		t.Fatalf("StartQueryWithID: %v", err)
+	}
+	if err := qt.StartQueryWithID("short", QueryClassPreview, owner.ID, src.ID, team.ID, "SELECT 2", cancel, 0, 0, 0); err != nil {
+		t.Fatalf("StartQueryWithID: %v", err)
+	}
+	qt.queries["long"].StartTime = time.Now().Add(-2 * time.Minute)
+
+	// Only the query past persistAfter is stored.
+	if err := qt.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	got, err := s.sqlite.ListTrackedQueries(ctx, "host-a")
+	if err != nil {
+		t.Fatalf("ListTrackedQueries: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "long" || got[0].Class != string(QueryClassExport) || got[0].QueryText != "SELECT 1" || got[0].SourceID != src.ID {
+		t.Fatalf("persisted = %+v, want the long export", got)
+	}
+
+	// A finished query's row is removed on the next sync.
+	qt.RemoveQuery("long")
+	if err := qt.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if got, err := s.sqlite.ListTrackedQueries(ctx, "host-a"); err != nil || len(got) != 0 {
+		t.Fatalf("after finish: %+v, %v", got, err)
+	}
+}
+
+func TestQueryTrackerCleanupReturnsStale(t *testing.T) {
+	qt := newQueryTracker(nil, "host-a", 0)
+	cancelled := false
+	if err := qt.StartQueryWithID("stale", QueryClassTail, 1, 1, 0, "q", func() { cancelled = true }, 0, 0, 0); err != nil {
+		t.Fatalf("StartQueryWithID: %v", err)
+	}
+	if err := qt.StartQueryWithID("fresh", QueryClassTail, 1, 1, 0, "q", func() {}, 0, 0, 0); err != nil {
+		t.Fatalf("StartQueryWithID: %v", err)
+	}
+	qt.queries["stale"].StartTime = time.Now().Add(-staleQueryAge - time.Minute)
+
+	removed := qt.Cleanup()
+	if len(removed) != 1 || removed[0].ID != "stale" || !cancelled {
+		t.Fatalf("Cleanup removed %+v (cancelled %v), want only the stale query", removed, cancelled)
+	}
+	if qt.ActiveCount(QueryClassTail) != 1 {
+		t.Fatalf("active tails = %d, want 1", qt.ActiveCount(QueryClassTail))
+	}
+	// Without a store Sync does nothing.
+	if err := qt.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+}
+
+func TestServerDrain(t *testing.T) {
+	s := &Server{queries: newQueryTracker(nil, "host-a", 0), log: slog.New(slog.DiscardHandler)}
+	var tailCancelled, previewCancelled bool
+	if err := s.queries.StartQueryWithID("tail", QueryClassTail, 1, 1, 0, "q", func() { tailCancelled = true }, 0, 0, 0); err != nil {
+		t.Fatalf("StartQueryWithID: %v", err)
+	}
+	if err := s.queries.StartQueryWithID("preview", QueryClassPreview, 1, 1, 0, "q", func() { previewCancelled = true }, 0, 0, 0); err != nil {
+		t.Fatalf("StartQueryWithID: %v", err)
+	}
+
+	// The preview finishes while draining; the tail is cancelled at once.
+	time.AfterFunc(3*drainPollInterval, func() { s.queries.RemoveQuery("preview") })
+	s.Drain(context.Background())
+	if !tailCancelled || previewCancelled {
+		t.Fatalf("tail cancelled %v, preview cancelled %v; want only the tail cancelled", tailCancelled, previewCancelled)
+	}
+	if err := s.queries.StartQueryWithID("late", QueryClassPreview, 1, 1, 0, "q", func() {}, 0, 0, 0); !errors.Is(err, ErrDraining) {
+		t.Fatalf("StartQueryWithID while draining err = %v, want ErrDraining", err)
+	}
+}
+
+func TestServerDrainCancelsAtDeadline(t *testing.T) {
+	s := &Server{queries: newQueryTracker(nil, "host-a", 0), log: slog.New(slog.DiscardHandler)}
+	cancelled := false
+	if err := s.queries.StartQueryWithID("export", QueryClassExport, 1, 1, 0, "q", func() { cancelled = true }, 0, 0, 0); err != nil {
+		t.Fatalf("StartQueryWithID: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*drainPollInterval)
+	defer cancel()
+	s.Drain(ctx)
+	if !cancelled || s.queries.Len() != 0 {
+		t.Fatalf("cancelled %v with %d queries left, want the export cancelled", cancelled, s.queries.Len())
+	}
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/pkg/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// requireTeamQuota rejects a query with 429 once the team has used up its
+// daily quota, with Retry-After set to the next UTC midnight. It must run
+// after requireTeamMember. A failed usage lookup lets the query through:
+// quotas are a guardrail, not worth failing queries over.
+func (s *Server) requireTeamQuota(c *fiber.Ctx) error {
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	if usage, exceeded := s.teamQuotaExceeded(c, teamID); exceeded {
+		return sendQuotaExceeded(c, usage)
+	}
+	return c.Next()
+}
+
+// requireSourceQuota is requireTeamQuota for routes outside a team's scope
+// that run a query on a source. sourceOf reads the source from the request,
+// and the query is refused once every team the user reaches that source
+// through has used up its quota. A request whose source or user can't be
+// resolved is passed on for the handler to reject.
+func (s *Server) requireSourceQuota(sourceOf func(*fiber.Ctx) (models.SourceID, bool)) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, _ := c.Locals("user").(*models.User)
+		sourceID, ok := sourceOf(c)
+		if user == nil || !ok {
+			return c.Next()
+		}
+		teams, err := core.ListTeamsWithAccessToSource(c.Context(), s.sqlite, s.log, sourceID, user.ID)
+		if err != nil {
+			s.log.Warn("failed to list source teams for quota check", "error", err, "source_id", sourceID)
+			return c.Next()
+		}
+		var usage *models.TeamUsage
+		for _, team := range teams {
+			teamUsage, exceeded := s.teamQuotaExceeded(c, team.ID)
+			if !exceeded {
+				return c.Next()
+			}
+			usage = teamUsage
+		}
+		if usage == nil {
+			return c.Next()
+		}
+		return sendQuotaExceeded(c, usage)
+	}
+}
+
+// teamQuotaExceeded reports whether the team has used up its daily quota,
+// with its usage when it has.
+func (s *Server) teamQuotaExceeded(c *fiber.Ctx, teamID models.TeamID) (*models.TeamUsage, bool) {
+	usage, err := core.CheckTeamQuota(c.Context(), s.sqlite, teamID, s.config.Query.Quota.QuotaForTeam(teamID), time.Now())
+	if errors.Is(err, core.ErrQuotaExceeded) {
+		return usage, true
+	}
+	if err != nil {
+		s.log.Warn("failed to check team query quota", "error", err, "team_id", teamID)
+	}
+	return nil, false
+}
+
+func sendQuotaExceeded(c *fiber.Ctx, usage *models.TeamUsage) error {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(time.Until(usage.ResetsAt).Seconds()))))
+	return SendErrorWithType(c, fiber.StatusTooManyRequests,
+		"Team has used up its daily query quota; it resets at "+usage.ResetsAt.Format(time.RFC3339), models.QuotaExceededErrorType)
+}
+
+// alertTestSource reads the source of an alert test query from its body.
+func alertTestSource(c *fiber.Ctx) (models.SourceID, bool) {
+	var req struct {
+		SourceID models.SourceID `json:"source_id"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.SourceID == 0 {
+		return 0, false
+	}
+	return req.SourceID, true
+}
+
+// savedQuerySource reads the source of the saved query in the route.
+func (s *Server) savedQuerySource(c *fiber.Ctx) (models.SourceID, bool) {
+	queryID, err := parseSavedQueryID(c)
+	if err != nil {
+		return 0, false
+	}
+	query, err := core.GetSavedQuery(c.Context(), s.sqlite, s.log, queryID)
+	if err != nil {
+		return 0, false
+	}
+	return query.SourceID, true
+}
+
+// handleGetTeamUsage returns the team's query usage today (UTC) against its
+// daily quota.
+// URL: GET /api/v1/teams/:teamID/usage
+// Requires: team membership
+func (s *Server) handleGetTeamUsage(c *fiber.Ctx) error {
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	usage, err := core.GetTeamUsage(c.Context(), s.sqlite, teamID, s.config.Query.Quota.QuotaForTeam(teamID), time.Now())
+	if err != nil {
+		s.log.Error("failed to get team usage", "error", err, "team_id", teamID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get team usage", models.DatabaseErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, usage)
+}
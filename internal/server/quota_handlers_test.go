@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// TestQueryRoutesEnforceTeamQuota runs requests through the real router,
+// authenticated with an API token, for a team that has used up its daily
+// query quota. Every endpoint that runs a query must answer 429 before its
+// handler touches ClickHouse.
+func TestQueryRoutesEnforceTeamQuota(t *testing.T) {
+	ctx := context.Background()
+	s, member, team, src := newRawSQLTestServer(t, true)
+	s.app = fiber.New()
+	s.fs = http.Dir(t.TempDir())
+	s.config.Auth.APITokenSecret = "0123456789abcdef0123456789abcdef"
+	s.config.Query.Quota.MaxQueriesPerDay = 1
+	s.config.Alerts.Enabled = true
+	s.setupRoutes()
+
+	token, err := core.CreateAPIToken(ctx, s.sqlite, s.log, &s.config.Auth, member.ID, "quota", nil,
+		[]models.TokenScope{models.TokenScopeLogsRead, models.TokenScopeNotebooksRead, models.TokenScopeAlertsWrite, models.TokenScopeSavedQueriesRead})
+	if err != nil {
+		t.Fatalf("CreateAPIToken: %v", err)
+	}
+	saved, err := s.sqlite.CreateSavedQuery(ctx, src.ID, &team.ID, "levels", "", models.QueryLanguageClickHouseSQL,
+		models.SavedQueryEditorModeNative, `{"content":"SELECT DISTINCT level FROM logs"}`, &member.ID)
+	if err != nil {
+		t.Fatalf("CreateSavedQuery: %v", err)
+	}
+	do := func(method, path, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+		resp, err := s.app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+
+	teamPath := fmt.Sprintf("/api/v1/teams/%d", team.ID)
+	source := fmt.Sprintf("%s/sources/%d", teamPath, src.ID)
+	routes := []struct{ method, path, body string }{
+		{http.MethodPost, source + "/logs/query", ""},
+		{http.MethodPost, source + "/logchefql/query", ""},
+		{http.MethodPost, source + "/logs/export", ""},
+		{http.MethodPost, source + "/exports", ""},
+		{http.MethodGet, source + "/logs/tail", ""},
+		{http.MethodPost, source + "/logs/histogram", ""},
+		{http.MethodPost, source + "/logs/context", ""},
+		{http.MethodGet, source + "/trends", ""},
+		{http.MethodGet, source + "/fields/values", ""},
+		{http.MethodGet, source + "/fields/level/values", ""},
+		{http.MethodGet, source + "/fields/level/stats", ""},
+		{http.MethodGet, source + "/fields/json", ""},
+		{http.MethodGet, source + "/patterns", ""},
+		{http.MethodGet, source + "/anomalies/volume", ""},
+		{http.MethodGet, source + "/compare", ""},
+		{http.MethodPost, teamPath + "/federated/logchefql/query", ""},
+		{http.MethodGet, teamPath + "/traces/abc/logs", ""},
+		{http.MethodPost, teamPath + "/notebooks/1/cells/a/run", ""},
+		// Alert tests and saved-query choices aren't under a team; they're
+		// charged to the user's teams with the source.
+		{http.MethodPost, "/api/v1/alerts/test", fmt.Sprintf(`{"source_id":%d}`, src.ID)},
+		{http.MethodGet, fmt.Sprintf("/api/v1/saved-queries/%d/choices", saved.ID), ""},
+	}
+
+	// Under quota, the export request gets past the check and fails its own
+	// validation instead.
+	if resp := do(http.MethodPost, source+"/logs/export", `{}`); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("export under quota: status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+
+	today := time.Now().UTC().Format("2006-01-02")
+	if err := s.sqlite.IncrementQueryStats(ctx, today, member.ID, team.ID, src.ID, models.QueryLanguageLogchefQL, 10, 100); err != nil {
+		t.Fatalf("IncrementQueryStats: %v", err)
+	}
+	for _, route := range routes {
+		body := route.body
+		if body == "" {
+			body = `{}`
+		}
+		resp := do(route.method, route.path, body)
+		if resp.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("%s %s: status = %d, want %d", route.method, route.path, resp.StatusCode, http.StatusTooManyRequests)
+			continue
+		}
+		if resp.Header.Get(fiber.HeaderRetryAfter) == "" {
+			t.Errorf("%s %s: missing Retry-After", route.method, route.path)
+		}
+	}
+}
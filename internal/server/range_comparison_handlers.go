@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// handleCompareRanges runs the same filter over a baseline and a target time
+// range and reports the field values that appeared, disappeared, or changed
+// frequency significantly between them.
+// Access is controlled by the requireSourceAccess middleware.
+// Query params:
+//   - baseline_start, baseline_end: ISO8601 baseline range (required)
+//   - start_time, end_time: ISO8601 target range (required)
+//   - fields: comma-separated columns to diff (optional, defaults to LowCardinality/Enum columns)
+//   - limit: values fetched per field (default 20, max 100)
+//   - threshold_percent: share change that counts (default 100, i.e. 2x)
+//   - query: datasource-native query string (optional, narrows the rows)
+func (s *Server) handleCompareRanges(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID format", models.ValidationErrorType)
+	}
+
+	var times [4]time.Time
+	for i, param := range []string{"baseline_start", "baseline_end", "start_time", "end_time"} {
+		v := c.Query(param, "")
+		if v == "" {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Both ranges (baseline_start, baseline_end, start_time, end_time) are required", models.ValidationErrorType)
+		}
+		if times[i], err = time.Parse(time.RFC3339, v); err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, fmt.Sprintf("Invalid %s format (use ISO8601/RFC3339)", param), models.ValidationErrorType)
+		}
+	}
+	threshold := 0.0
+	if v := c.Query("threshold_percent", ""); v != "" {
+		if threshold, err = strconv.ParseFloat(v, 64); err != nil || threshold <= 0 {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "threshold_percent must be a positive number", models.ValidationErrorType)
+		}
+	}
+	var fields []string
+	for field := range strings.SplitSeq(c.Query("fields", ""), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), HistogramTimeout)
+	defer cancel()
+
+	result, err := core.CompareRanges(ctx, s.datasources, sourceID, core.RangeComparisonParams{
+		Fields:           fields,
+		BaselineStart:    times[0],
+		BaselineEnd:      times[1],
+		TargetStart:      times[2],
+		TargetEnd:        times[3],
+		Limit:            c.QueryInt("limit", 0),
+		ThresholdPercent: threshold,
+		QueryText:        c.Query("query", ""),
+	})
+	if err != nil {
+		if ctx.Err() == context.Canceled {
+			return SendErrorWithType(c, fiber.StatusRequestTimeout, "Request cancelled", models.ExternalServiceErrorType)
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			s.log.Warn("range comparison request timed out", "source_id", sourceID, "timeout", HistogramTimeout)
+			return SendErrorWithType(c, fiber.StatusRequestTimeout, "Request timed out", models.ExternalServiceErrorType)
+		}
+		if errors.Is(err, core.ErrSourceNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		if errors.Is(err, datasource.ErrOperationNotSupported) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Range comparison is not supported for this source type yet", models.ValidationErrorType)
+		}
+		if datasource.IsValidationError(err) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err), models.ValidationErrorType)
+		}
+		s.log.Error("failed to compare ranges", "error", err, "source_id", sourceID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to compare ranges: %v", err), models.DatabaseErrorType)
+	}
+
+	return SendSuccess(c, fiber.StatusOK, result)
+}
@@ -1,12 +1,14 @@
 package server
 
 import (
+	"math"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/mr-karan/logchef/internal/config"
 	"github.com/mr-karan/logchef/internal/metrics"
 	"github.com/mr-karan/logchef/pkg/models"
 )
@@ -67,10 +69,98 @@ func (l *windowLimiter) pruneLocked(now time.Time) {
 	}
 }
 
-// tooManyRequests writes the shared 429 response used by both limiter
-// middlewares after recording the rejection metric for scope.
-func tooManyRequests(c *fiber.Ctx, scope string) error {
+// tokenBucketLimiter is a per-key token bucket. Each key starts with burst
+// tokens and refills at rate tokens per second. Unlike windowLimiter it can
+// reserve a token that is not available yet, which lets callers queue a
+// request for a short wait instead of rejecting it outright.
+type tokenBucketLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucketLimiter creates a limiter refilling perMinute tokens a minute
+// into buckets of size burst.
+func newTokenBucketLimiter(perMinute, burst int) *tokenBucketLimiter {
+	return &tokenBucketLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Reserve takes a token for key. When the bucket is empty it reserves the next
+// token if it arrives within maxWait, returning how long the caller must wait
+// before proceeding. Otherwise it takes nothing and returns ok=false with the
+// time until a token would be available. An empty key is always allowed.
+func (l *tokenBucketLimiter) Reserve(key string, maxWait time.Duration) (wait time.Duration, ok bool) {
+	if key == "" {
+		return 0, true
+	}
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pruneLocked(now)
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	// Tokens go negative while requests are queued, so each later waiter is
+	// pushed back by one more refill interval.
+	wait = time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	if wait > maxWait {
+		return wait, false
+	}
+	b.tokens--
+	return wait, true
+}
+
+// Refund returns a token taken by Reserve, for when a later check rejects the
+// request it was taken for.
+func (l *tokenBucketLimiter) Refund(key string) {
+	if key == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if b, ok := l.buckets[key]; ok {
+		b.tokens = math.Min(l.burst, b.tokens+1)
+	}
+}
+
+// pruneLocked drops buckets that have refilled completely, since a fresh
+// bucket is equivalent. Caller must hold l.mu.
+func (l *tokenBucketLimiter) pruneLocked(now time.Time) {
+	for k, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// tooManyRequests writes the shared 429 response used by the limiter
+// middlewares after recording the rejection metric for scope. A positive
+// retryAfter is sent as a Retry-After header, rounded up to whole seconds.
+func tooManyRequests(c *fiber.Ctx, scope string, retryAfter time.Duration) error {
 	metrics.RecordRateLimitRejection(scope)
+	if retryAfter > 0 {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
 	return SendErrorWithType(c, fiber.StatusTooManyRequests, "Too many requests, please slow down", models.ValidationErrorType)
 }
 
@@ -87,30 +177,71 @@ func authRateLimitMiddleware(perIPPerMinute, globalPerMinute int) fiber.Handler
 
 	return func(c *fiber.Ctx) error {
 		if global != nil && !global.Allow("global") {
-			return tooManyRequests(c, "auth")
+			return tooManyRequests(c, "auth", 0)
 		}
 		if !perIP.Allow(c.IP()) {
-			return tooManyRequests(c, "auth")
+			return tooManyRequests(c, "auth", 0)
 		}
 		return c.Next()
 	}
 }
 
-// queryRateLimitMiddleware limits the authenticated query endpoints per user
-// within a one-minute window. It keys on the authenticated user id (falling
-// back to the client IP if the user context is somehow absent) and must run
-// after requireAuth so the user is populated.
-func queryRateLimitMiddleware(perUserPerMinute int) fiber.Handler {
-	perUser := newWindowLimiter(time.Minute, perUserPerMinute)
+// queryRateLimitMiddleware limits the authenticated query endpoints with a
+// token bucket per user and, when QueryPerTeamPerMinute > 0, one shared by each
+// team (keyed on the :teamID route param). A request over either rate is held
+// for up to QueryMaxWait for its token; beyond that it gets a 429 with
+// Retry-After. It keys on the authenticated user id (falling back to the
+// client IP if the user context is somehow absent) and must run after
+// requireAuth so the user is populated.
+func queryRateLimitMiddleware(cfg config.RateLimitConfig) fiber.Handler {
+	perUser := newTokenBucketLimiter(cfg.QueryPerUserPerMinute, cfg.QueryPerUserBurst)
+
+	var perTeam *tokenBucketLimiter
+	if cfg.QueryPerTeamPerMinute > 0 {
+		perTeam = newTokenBucketLimiter(cfg.QueryPerTeamPerMinute, cfg.QueryPerTeamBurst)
+	}
 
 	return func(c *fiber.Ctx) error {
-		key := c.IP()
+		userKey := c.IP()
 		if user, ok := c.Locals("user").(*models.User); ok && user != nil {
-			key = "user:" + strconv.Itoa(int(user.ID))
+			userKey = "user:" + strconv.Itoa(int(user.ID))
+		}
+		wait, ok := perUser.Reserve(userKey, cfg.QueryMaxWait)
+		if !ok {
+			return tooManyRequests(c, "query", wait)
+		}
+		teamKey := c.Params("teamID")
+		if perTeam != nil {
+			teamWait, ok := perTeam.Reserve(teamKey, cfg.QueryMaxWait)
+			if !ok {
+				perUser.Refund(userKey)
+				return tooManyRequests(c, "query_team", teamWait)
+			}
+			wait = max(wait, teamWait)
 		}
-		if !perUser.Allow(key) {
-			return tooManyRequests(c, "query")
+		// Both tokens are reserved, so the wait is bounded by QueryMaxWait.
+		// The request's context is done once the server shuts down; a request
+		// given up on while it waits hands its tokens back.
+		if wait > 0 && !waitFor(c.Context().Done(), wait) {
+			perUser.Refund(userKey)
+			if perTeam != nil {
+				perTeam.Refund(teamKey)
+			}
+			return SendErrorWithType(c, fiber.StatusServiceUnavailable, "Request cancelled while waiting for its rate limit", models.GeneralErrorType)
 		}
 		return c.Next()
 	}
 }
+
+// waitFor blocks for d and reports true, or reports false as soon as done is
+// closed. A nil done never closes.
+func waitFor(done <-chan struct{}, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-done:
+		return false
+	}
+}
@@ -1,8 +1,17 @@
 package server
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/pkg/models"
 )
 
 func TestWindowLimiterAllowsUpToLimit(t *testing.T) {
@@ -74,3 +83,161 @@ func TestWindowLimiterPrunesStaleKeys(t *testing.T) {
 		t.Fatal("fresh key missing after insert")
 	}
 }
+
+func TestTokenBucketLimiterQueuesWithinMaxWait(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newTokenBucketLimiter(60, 2) // one token a second
+	l.now = func() time.Time { return now }
+
+	for i := 1; i <= 2; i++ {
+		if wait, ok := l.Reserve("k", 0); !ok || wait != 0 {
+			t.Fatalf("burst request %d = %s, %v; want immediate", i, wait, ok)
+		}
+	}
+	if wait, ok := l.Reserve("k", 0); ok || wait != time.Second {
+		t.Fatalf("over-burst with no wait = %s, %v; want rejected, retry in 1s", wait, ok)
+	}
+	// Queued requests reserve successive future tokens.
+	if wait, ok := l.Reserve("k", 2*time.Second); !ok || wait != time.Second {
+		t.Fatalf("first queued = %s, %v; want 1s", wait, ok)
+	}
+	if wait, ok := l.Reserve("k", 2*time.Second); !ok || wait != 2*time.Second {
+		t.Fatalf("second queued = %s, %v; want 2s", wait, ok)
+	}
+	if wait, ok := l.Reserve("k", 2*time.Second); ok || wait != 3*time.Second {
+		t.Fatalf("third queued = %s, %v; want rejected, retry in 3s", wait, ok)
+	}
+
+	// By t=3s the two queued waiters have used the tokens for seconds 1
+	// and 2, and the third has refilled.
+	now = now.Add(3 * time.Second)
+	if _, ok := l.Reserve("k", 0); !ok {
+		t.Fatal("request after refill rejected")
+	}
+}
+
+func TestTokenBucketLimiterRefundAndPrune(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newTokenBucketLimiter(60, 1)
+	l.now = func() time.Time { return now }
+
+	l.Reserve("k", 0)
+	l.Refund("k")
+	if _, ok := l.Reserve("k", 0); !ok {
+		t.Fatal("refunded token not available")
+	}
+	if _, ok := l.Reserve("other", 0); !ok {
+		t.Fatal("keys are not isolated")
+	}
+
+	now = now.Add(time.Second)
+	l.Reserve("fresh", 0)
+	if _, ok := l.buckets["k"]; ok {
+		t.Fatal("refilled bucket was not pruned")
+	}
+}
+
+func TestQueryRateLimitMiddlewareTeamBucket(t *testing.T) {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		id, _ := strconv.Atoi(c.Get("X-User"))
+		c.Locals("user", &models.User{ID: models.UserID(id)})
+		return c.Next()
+	})
+	app.Get("/teams/:teamID/q", queryRateLimitMiddleware(config.RateLimitConfig{
+		QueryPerUserPerMinute: 60, QueryPerUserBurst: 5,
+		QueryPerTeamPerMinute: 1, QueryPerTeamBurst: 2,
+	}), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	do := func(user, team string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/teams/"+team+"/q", nil)
+		req.Header.Set("X-User", user)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	// Two users share team 1's burst of two.
+	for _, user := range []string{"1", "2"} {
+		if resp := do(user, "1"); resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("user %s: status %d, want 200", user, resp.StatusCode)
+		}
+	}
+	resp := do("3", "1")
+	if resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Fatalf("over team burst: status %d, want 429", resp.StatusCode)
+	}
+	if got := resp.Header.Get(fiber.HeaderRetryAfter); got != "60" {
+		t.Errorf("Retry-After = %q, want 60", got)
+	}
+	// Another team has its own bucket, and the rejected request's user token
+	// was refunded.
+	if resp := do("3", "2"); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("other team: status %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestWaitForStopsWhenDone(t *testing.T) {
+	if !waitFor(nil, time.Millisecond) {
+		t.Fatal("waitFor(nil) = false, want true once the wait elapses")
+	}
+	done := make(chan struct{})
+	close(done)
+	start := time.Now()
+	if waitFor(done, time.Minute) {
+		t.Fatal("waitFor(closed) = true, want false")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waitFor(closed) took %s, want it to return at once", elapsed)
+	}
+}
+
+func TestQueryTrackerTeamAdmission(t *testing.T) {
+	qt := &QueryTracker{queries: make(map[string]*ActiveQuery)}
+	cancel := func() {}
+
+	if _, err := qt.StartQuery(QueryClassPreview, 1, 1, 7, "q", cancel, 0, 2, 0); err != nil {
+		t.Fatalf("first team query: %v", err)
+	}
+	if _, err := qt.StartQuery(QueryClassPreview, 2, 1, 7, "q", cancel, 0, 2, 0); err != nil {
+		t.Fatalf("second team query: %v", err)
+	}
+	_, err := qt.StartQuery(QueryClassPreview, 3, 1, 7, "q", cancel, 0, 2, 0)
+	var admissionErr *QueryAdmissionError
+	if !errors.As(err, &admissionErr) {
+		t.Fatalf("third team query err = %v, want QueryAdmissionError", err)
+	}
+	// Other teams and other query classes are counted separately.
+	if _, err := qt.StartQuery(QueryClassPreview, 3, 1, 8, "q", cancel, 0, 2, 0); err != nil {
+		t.Fatalf("other team: %v", err)
+	}
+	if _, err := qt.StartQuery(QueryClassTail, 3, 1, 7, "q", cancel, 0, 2, 0); err != nil {
+		t.Fatalf("other class: %v", err)
+	}
+}
+
+func TestQueryTrackerCancelQuery(t *testing.T) {
+	qt := &QueryTracker{queries: make(map[string]*ActiveQuery)}
+	cancelled := false
+	queryID, err := qt.StartQuery(QueryClassPreview, 1, 3, 7, "q", func() { cancelled = true }, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("StartQuery: %v", err)
+	}
+
+	if _, ok := qt.CancelQuery(queryID, 2); ok || cancelled {
+		t.Fatal("another user cancelled the query")
+	}
+	query, ok := qt.CancelQuery(queryID, 1)
+	if !ok || !cancelled {
+		t.Fatal("owner could not cancel the query")
+	}
+	// The handler kills the ClickHouse query by this ID on this source.
+	if query.ID != queryID || query.SourceID != 3 {
+		t.Fatalf("cancelled query = %+v", query)
+	}
+	if _, ok := qt.CancelQuery(queryID, 1); ok {
+		t.Fatal("query was cancelled twice")
+	}
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// fakeSQLProvider is a ClickHouse-like datasource.Provider that answers the
+// source metadata calls handlers make before running anything.
+type fakeSQLProvider struct {
+	datasource.Provider
+}
+
+func (fakeSQLProvider) Type() models.SourceType { return models.SourceTypeClickHouse }
+func (fakeSQLProvider) Capabilities() []datasource.Capability {
+	return []datasource.Capability{datasource.CapabilityExports}
+}
+func (fakeSQLProvider) SupportedQueryLanguages() []models.QueryLanguage {
+	return []models.QueryLanguage{models.QueryLanguageLogchefQL, models.QueryLanguageClickHouseSQL}
+}
+func (fakeSQLProvider) SupportedSavedQueryEditorModes() []models.SavedQueryEditorMode { return nil }
+func (fakeSQLProvider) SupportedAlertEditorModes() []models.AlertEditorMode {
+	return []models.AlertEditorMode{models.AlertEditorModeNative, models.AlertEditorModeCondition}
+}
+func (fakeSQLProvider) CheckSourceConnectionStatus(context.Context, *models.Source) bool { return true }
+func (fakeSQLProvider) PopulateSourceDetails(context.Context, *models.Source) error      { return nil }
+
+// newRawSQLTestServer returns a server whose only team has a linked source,
+// member as a member and the raw SQL switch set to rawSQL.
+func newRawSQLTestServer(t *testing.T, rawSQL bool) (s *Server, member *models.User, team *models.Team, src *models.Source) {
+	t.Helper()
+	s = newDashboardTestServer(t)
+	s.config = &config.Config{}
+	s.datasources = datasource.NewService(s.sqlite, s.log)
+	s.datasources.Register(fakeSQLProvider{})
+
+	member = mkTestUser(t, s.sqlite, "member@test.dev", models.UserRoleMember)
+	team, src = mkTestTeam(t, s.sqlite, "team", member)
+	team.RawSQLEnabled = rawSQL
+	if err := s.sqlite.UpdateTeam(context.Background(), team); err != nil {
+		t.Fatalf("UpdateTeam: %v", err)
+	}
+	return s, member, team, src
+}
+
+func doRawSQLRequest(t *testing.T, app *fiber.App, method, path, body string) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+// TestExportsFollowRawSQLSwitch checks both export paths refuse a team with
+// raw SQL off. The bodies are missing their query, so a request that gets
+// past the switch stops at validation instead of reaching ClickHouse.
+func TestExportsFollowRawSQLSwitch(t *testing.T) {
+	for _, route := range []struct {
+		name    string
+		handler func(*Server) fiber.Handler
+	}{
+		{"logs export", func(s *Server) fiber.Handler { return s.handleExportLogs }},
+		{"export job", func(s *Server) fiber.Handler { return s.handleCreateExportJob }},
+	} {
+		for _, tc := range []struct {
+			rawSQL bool
+			want   int
+		}{
+			{rawSQL: false, want: http.StatusForbidden},
+			{rawSQL: true, want: http.StatusBadRequest},
+		} {
+			t.Run(fmt.Sprintf("%s/raw_sql=%v", route.name, tc.rawSQL), func(t *testing.T) {
+				s, member, team, src := newRawSQLTestServer(t, tc.rawSQL)
+				app := fiber.New()
+				withUser(app, http.MethodPost, "/teams/:teamID/sources/:sourceID/export", member, route.handler(s))
+
+				path := fmt.Sprintf("/teams/%d/sources/%d/export", team.ID, src.ID)
+				if got := doRawSQLRequest(t, app, http.MethodPost, path, `{}`); got != tc.want {
+					t.Errorf("status = %d, want %d", got, tc.want)
+				}
+			})
+		}
+	}
+}
+
+func TestRunNotebookCellFollowsRawSQLSwitch(t *testing.T) {
+	s, member, team, src := newRawSQLTestServer(t, false)
+	cells := fmt.Sprintf(`[{"id":"sql","type":"query","content":"SELECT 1","source_id":%d,"query_language":"clickhouse-sql","start_time":"2026-01-01T00:00:00Z","end_time":"2026-01-01T01:00:00Z"}]`, src.ID)
+	notebook := &models.Notebook{TeamID: team.ID, Name: "Outage", CellsJSON: json.RawMessage(cells), CreatedBy: &member.ID}
+	if err := s.sqlite.CreateNotebook(context.Background(), notebook); err != nil {
+		t.Fatalf("CreateNotebook: %v", err)
+	}
+
+	app := fiber.New()
+	withUser(app, http.MethodPost, "/teams/:teamID/notebooks/:notebookID/cells/:cellID/run", member, s.handleRunNotebookCell)
+
+	path := fmt.Sprintf("/teams/%d/notebooks/%d/cells/sql/run", team.ID, notebook.ID)
+	if got := doRawSQLRequest(t, app, http.MethodPost, path, ``); got != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", got, http.StatusForbidden)
+	}
+}
+
+func TestAlertsFollowRawSQLSwitch(t *testing.T) {
+	alertBody := func(sourceID models.SourceID) string {
+		return fmt.Sprintf(`{"source_id":%d,"name":"errors","query_language":"clickhouse-sql","editor_mode":"native","query":"SELECT count() FROM logs","lookback_seconds":300,"threshold_operator":"gt","threshold_value":1,"frequency_seconds":60,"severity":"warning"}`, sourceID)
+	}
+
+	for _, tc := range []struct {
+		rawSQL bool
+		want   int
+	}{
+		{rawSQL: false, want: http.StatusForbidden},
+		{rawSQL: true, want: http.StatusCreated},
+	} {
+		t.Run(fmt.Sprintf("create/raw_sql=%v", tc.rawSQL), func(t *testing.T) {
+			s, member, _, src := newRawSQLTestServer(t, tc.rawSQL)
+			app := fiber.New()
+			withUser(app, http.MethodPost, "/alerts", member, s.handleCreateAlert)
+
+			if got := doRawSQLRequest(t, app, http.MethodPost, "/alerts", alertBody(src.ID)); got != tc.want {
+				t.Errorf("status = %d, want %d", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("update query", func(t *testing.T) {
+		s, member, team, src := newRawSQLTestServer(t, true)
+		app := fiber.New()
+		withUser(app, http.MethodPost, "/alerts", member, s.handleCreateAlert)
+		withUser(app, http.MethodPut, "/alerts/:alertID", member, s.handleUpdateAlert)
+		if got := doRawSQLRequest(t, app, http.MethodPost, "/alerts", alertBody(src.ID)); got != http.StatusCreated {
+			t.Fatalf("create status = %d, want %d", got, http.StatusCreated)
+		}
+
+		team.RawSQLEnabled = false
+		if err := s.sqlite.UpdateTeam(context.Background(), team); err != nil {
+			t.Fatalf("UpdateTeam: %v", err)
+		}
+		if got := doRawSQLRequest(t, app, http.MethodPut, "/alerts/1", `{"name":"renamed"}`); got != http.StatusOK {
+			t.Errorf("rename status = %d, want %d", got, http.StatusOK)
+		}
+		if got := doRawSQLRequest(t, app, http.MethodPut, "/alerts/1", `{"query":"SELECT count() FROM logs WHERE level = 'error'"}`); got != http.StatusForbidden {
+			t.Errorf("query change status = %d, want %d", got, http.StatusForbidden)
+		}
+	})
+
+	t.Run("test query", func(t *testing.T) {
+		s, member, _, src := newRawSQLTestServer(t, false)
+		app := fiber.New()
+		withUser(app, http.MethodPost, "/alerts/test", member, s.handleTestAlertQuery)
+
+		if got := doRawSQLRequest(t, app, http.MethodPost, "/alerts/test", alertBody(src.ID)); got != http.StatusForbidden {
+			t.Errorf("status = %d, want %d", got, http.StatusForbidden)
+		}
+	})
+}
+
+// Admins aren't held to a team's raw SQL switch.
+func TestRawSQLSwitchSparesAdmins(t *testing.T) {
+	s, _, team, src := newRawSQLTestServer(t, false)
+	admin := mkTestUser(t, s.sqlite, "admin@test.dev", models.UserRoleAdmin)
+	app := fiber.New()
+	withUser(app, http.MethodPost, "/teams/:teamID/sources/:sourceID/export", admin, s.handleExportLogs)
+
+	path := fmt.Sprintf("/teams/%d/sources/%d/export", team.ID, src.ID)
+	if got := doRawSQLRequest(t, app, http.MethodPost, path, `{}`); got != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", got, http.StatusBadRequest)
+	}
+}
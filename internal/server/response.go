@@ -26,10 +26,12 @@ func parsePositiveIntParam(c *fiber.Ctx, paramName string) (int64, error) {
 
 // Response defines the standard JSON structure for API responses.
 type Response struct {
-	Status    string `json:"status"` // "success" or "error"
-	Data      any    `json:"data,omitempty"`
-	Message   string `json:"message,omitempty"`
-	ErrorType string `json:"error_type,omitempty"` // Application-specific error type code.
+	Status string `json:"status"` // "success" or "error"
+	Data   any    `json:"data,omitempty"`
+	// Pagination accompanies list responses; Data then holds the page's items.
+	Pagination *models.Pagination `json:"pagination,omitempty"`
+	Message    string             `json:"message,omitempty"`
+	ErrorType  string             `json:"error_type,omitempty"` // Application-specific error type code.
 }
 
 // NewSuccessResponse creates a standard success response structure.
@@ -78,6 +80,13 @@ func SendSuccess(c *fiber.Ctx, status int, data any) error {
 	return c.Status(status).JSON(NewSuccessResponse(data))
 }
 
+// SendList sends one page of a list endpoint with its pagination details.
+func SendList(c *fiber.Ctx, items any, page *models.Pagination) error {
+	resp := NewSuccessResponse(items)
+	resp.Pagination = page
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
 // SendError is a helper function to easily send a JSON error response
 // with the given HTTP status code and error message.
 // It uses the GeneralErrorType by default.
@@ -91,3 +100,11 @@ func SendError(c *fiber.Ctx, status int, err any) error {
 func SendErrorWithType(c *fiber.Ctx, status int, err any, errorType models.ErrorType) error {
 	return c.Status(status).JSON(NewErrorResponse(err, errorType))
 }
+
+// sendQueryCostError rejects a query over the query cost limits. Data carries
+// the estimate and the cap that tripped so the UI can explain the rejection.
+func sendQueryCostError(c *fiber.Ctx, err *models.QueryCostError) error {
+	resp := NewErrorResponse(err, models.QueryCostErrorType)
+	resp.Data = err
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(resp)
+}
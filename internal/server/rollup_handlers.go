@@ -0,0 +1,120 @@
+package server
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/clickhouse"
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/rollups"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// sendRollupError maps rollup manager errors to API responses.
+func (s *Server) sendRollupError(c *fiber.Ctx, err error, sourceID models.SourceID, action string) error {
+	switch {
+	case errors.Is(err, rollups.ErrRollupNotFound):
+		return SendErrorWithType(c, fiber.StatusNotFound, err.Error(), models.NotFoundErrorType)
+	case errors.Is(err, models.ErrNotFound):
+		return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+	case errors.Is(err, rollups.ErrInvalidRequest):
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	case errors.Is(err, clickhouse.ErrReadOnly):
+		return SendErrorWithType(c, fiber.StatusConflict, "Rollups need DDL access, but this "+err.Error()+". Point the source at a writable node to use rollups", models.ValidationErrorType)
+	case errors.Is(err, datasource.ErrOperationNotSupported):
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Rollups are not supported for this source type yet", models.ValidationErrorType)
+	}
+	s.log.Error("failed to "+action, "error", err, "source_id", sourceID)
+	return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to "+action, models.GeneralErrorType)
+}
+
+// handleGetSourceRollup handles GET /admin/sources/:sourceID/rollup.
+func (s *Server) handleGetSourceRollup(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID", models.ValidationErrorType)
+	}
+	rollup, err := s.rollups.GetRollup(c.Context(), sourceID)
+	if err != nil {
+		return s.sendRollupError(c, err, sourceID, "get source rollup")
+	}
+	return SendSuccess(c, fiber.StatusOK, rollup)
+}
+
+// handleUpdateSourceRollup handles PUT /admin/sources/:sourceID/rollup. It
+// enables the source's rollup or changes its dimension; a new dimension
+// discards the rolled-up hours so they are backfilled again.
+func (s *Server) handleUpdateSourceRollup(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID", models.ValidationErrorType)
+	}
+	var req models.UpdateSourceRollupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+	rollup, err := s.rollups.Configure(c.Context(), sourceID, req.Dimension)
+	if err != nil {
+		return s.sendRollupError(c, err, sourceID, "configure source rollup")
+	}
+	return SendSuccess(c, fiber.StatusOK, rollup)
+}
+
+// handleDeleteSourceRollup handles DELETE /admin/sources/:sourceID/rollup and
+// drops the rollup table.
+func (s *Server) handleDeleteSourceRollup(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID", models.ValidationErrorType)
+	}
+	if err := s.rollups.Disable(c.Context(), sourceID); err != nil {
+		return s.sendRollupError(c, err, sourceID, "disable source rollup")
+	}
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Source rollup disabled"})
+}
+
+// handleGetSourceTrends handles GET /teams/:teamID/sources/:sourceID/trends.
+// Query parameters:
+//   - start_time, end_time: RFC3339 range (required)
+//   - window: bucket size, one of 1h, 3h, 6h, 12h, 24h (optional, picked from the range)
+//   - group_by: "severity" or "dimension" (optional)
+//   - timezone: aligns day buckets (optional, defaults to UTC)
+//
+// Ranges longer than rollups.min_range_days are served from the source's
+// rollup when one is configured and caught up.
+func (s *Server) handleGetSourceTrends(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID format", models.ValidationErrorType)
+	}
+
+	startTimeStr, endTimeStr := c.Query("start_time"), c.Query("end_time")
+	if startTimeStr == "" || endTimeStr == "" {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Time range (start_time, end_time) is required", models.ValidationErrorType)
+	}
+	startTime, err := time.Parse(time.RFC3339, startTimeStr)
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid start_time format (use ISO8601/RFC3339)", models.ValidationErrorType)
+	}
+	endTime, err := time.Parse(time.RFC3339, endTimeStr)
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid end_time format (use ISO8601/RFC3339)", models.ValidationErrorType)
+	}
+
+	timeout := models.DefaultQueryTimeoutSeconds
+	result, err := s.rollups.Trend(c.Context(), sourceID, rollups.TrendRequest{
+		Start:        startTime,
+		End:          endTime,
+		Window:       c.Query("window"),
+		GroupBy:      models.TrendGroupBy(c.Query("group_by")),
+		Timezone:     c.Query("timezone"),
+		QueryTimeout: &timeout,
+	})
+	if err != nil {
+		return s.sendRollupError(c, err, sourceID, "get source trends")
+	}
+	return SendSuccess(c, fiber.StatusOK, result)
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/pkg/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// teamRowFilter returns the row filter on the request's team-source link, as
+// loaded by requireTeamHasSource. Handlers that read logs pass it on to the
+// datasource so the team only sees the rows it matches.
+func teamRowFilter(c *fiber.Ctx) string {
+	filter, _ := c.Locals("rowFilter").(string)
+	return filter
+}
+
+// rejectRowFiltered refuses a request with 403 when the team's link to the
+// source has a row filter. It guards the endpoints that read logs without
+// applying one, so a policy fails closed. It must run after
+// requireTeamHasSource.
+func (s *Server) rejectRowFiltered(c *fiber.Ctx) error {
+	if teamRowFilter(c) != "" {
+		return SendErrorWithType(c, fiber.StatusForbidden,
+			"This isn't available for a source with a row filter for your team", models.AuthorizationErrorType)
+	}
+	return c.Next()
+}
+
+// handleGetTeamSourcePolicy returns the row-level access policy on a team's
+// link to a source.
+// URL: GET /api/v1/admin/teams/:teamID/sources/:sourceID/policy
+// Requires: Admin privileges
+func (s *Server) handleGetTeamSourcePolicy(c *fiber.Ctx) error {
+	teamID, sourceID, ok, err := parseTeamSourceParams(c)
+	if !ok {
+		return err
+	}
+	filter, err := core.GetTeamSourceRowFilter(c.Context(), s.sqlite, teamID, sourceID)
+	if err != nil {
+		if errors.Is(err, core.ErrSourceNotLinked) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source is not linked to this team", models.NotFoundErrorType)
+		}
+		s.log.Error("failed to get team source policy", "error", err, "team_id", teamID, "source_id", sourceID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get team source policy", models.DatabaseErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, models.TeamSourcePolicy{TeamID: teamID, SourceID: sourceID, RowFilter: filter})
+}
+
+// handleUpdateTeamSourcePolicy sets the row filter on a team's link to a
+// source; an empty row_filter removes it.
+// URL: PUT /api/v1/admin/teams/:teamID/sources/:sourceID/policy
+// Requires: Admin privileges
+func (s *Server) handleUpdateTeamSourcePolicy(c *fiber.Ctx) error {
+	teamID, sourceID, ok, err := parseTeamSourceParams(c)
+	if !ok {
+		return err
+	}
+	var req struct {
+		RowFilter string `json:"row_filter"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	policy, err := core.SetTeamSourceRowFilter(c.Context(), s.sqlite, s.datasources, teamID, sourceID, req.RowFilter)
+	if err != nil {
+		var validationErr *core.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			return SendErrorWithType(c, fiber.StatusBadRequest, validationErr.Error(), models.ValidationErrorType)
+		case errors.Is(err, core.ErrSourceNotFound):
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		case errors.Is(err, core.ErrSourceNotLinked):
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source is not linked to this team", models.NotFoundErrorType)
+		}
+		s.log.Error("failed to update team source policy", "error", err, "team_id", teamID, "source_id", sourceID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to update team source policy", models.DatabaseErrorType)
+	}
+
+	s.recordAudit(c, models.AuditActionTeamSourcePolicy, models.AuditResourceTeamSource, auditTeamSourceID(teamID, sourceID), &teamID, map[string]any{
+		"row_filter": policy.RowFilter,
+	})
+	return SendSuccess(c, fiber.StatusOK, policy)
+}
+
+// parseTeamSourceParams parses the :teamID and :sourceID route parameters.
+// It writes the error response itself and reports ok=false when either is
+// invalid.
+func parseTeamSourceParams(c *fiber.Ctx) (models.TeamID, models.SourceID, bool, error) {
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return 0, 0, false, SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return 0, 0, false, SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID format", models.ValidationErrorType)
+	}
+	return teamID, sourceID, true, nil
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+func TestRejectRowFiltered(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name      string
+		rowFilter string
+		want      int
+	}{
+		{name: "no filter", want: fiber.StatusNoContent},
+		{name: "filtered link", rowFilter: "namespace = 'team-a'", want: fiber.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			app := fiber.New()
+			s := &Server{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+			app.Get("/tail", func(c *fiber.Ctx) error {
+				c.Locals("rowFilter", tc.rowFilter)
+				return c.Next()
+			}, s.rejectRowFiltered, func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusNoContent)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/tail", http.NoBody))
+			if err != nil {
+				t.Fatalf("app.Test: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tc.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tc.want)
+			}
+		})
+	}
+}
+
+// Alerts evaluate the whole source, so a member whose only team reads it
+// through a row filter can't create, update or test one.
+func TestAlertsRequireUnfilteredSource(t *testing.T) {
+	s, member, team, src := newRawSQLTestServer(t, true)
+	app := fiber.New()
+	withUser(app, http.MethodPost, "/alerts", member, s.handleCreateAlert)
+	withUser(app, http.MethodPost, "/alerts/test", member, s.handleTestAlertQuery)
+	withUser(app, http.MethodPut, "/alerts/:alertID", member, s.handleUpdateAlert)
+
+	body := fmt.Sprintf(`{"source_id":%d,"name":"errors","query_language":"clickhouse-sql","editor_mode":"native","query":"SELECT count() FROM logs","lookback_seconds":300,"threshold_operator":"gt","threshold_value":1,"frequency_seconds":60,"severity":"warning"}`, src.ID)
+	if got := doRawSQLRequest(t, app, http.MethodPost, "/alerts", body); got != http.StatusCreated {
+		t.Fatalf("unfiltered create status = %d, want %d", got, http.StatusCreated)
+	}
+
+	if err := s.sqlite.SetTeamSourceRowFilter(context.Background(), team.ID, src.ID, "namespace = 'team-a'"); err != nil {
+		t.Fatalf("SetTeamSourceRowFilter: %v", err)
+	}
+	for _, req := range []struct {
+		method, path, body string
+	}{
+		{http.MethodPost, "/alerts", body},
+		{http.MethodPost, "/alerts/test", body},
+		{http.MethodPut, "/alerts/1", `{"name":"renamed"}`},
+	} {
+		if got := doRawSQLRequest(t, app, req.method, req.path, req.body); got != http.StatusForbidden {
+			t.Errorf("%s %s status = %d, want %d", req.method, req.path, got, http.StatusForbidden)
+		}
+	}
+}
+
+// SLOs evaluate the whole source too, so the same member can't create or
+// update one.
+func TestSLOsRequireUnfilteredSource(t *testing.T) {
+	s, member, team, src := newRawSQLTestServer(t, true)
+	ctx := context.Background()
+	slo := &models.SLO{
+		TeamID: team.ID, SourceID: src.ID, Name: "availability", QueryLanguage: models.QueryLanguageLogchefQL,
+		GoodQuery: "status < 500", TotalQuery: "status > 0", Target: 0.99, WindowSeconds: 7 * 24 * 3600, CreatedBy: &member.ID,
+	}
+	if err := s.sqlite.CreateSLO(ctx, slo); err != nil {
+		t.Fatalf("CreateSLO: %v", err)
+	}
+	if err := s.sqlite.SetTeamSourceRowFilter(ctx, team.ID, src.ID, "namespace = 'team-a'"); err != nil {
+		t.Fatalf("SetTeamSourceRowFilter: %v", err)
+	}
+
+	app := fiber.New()
+	withUser(app, http.MethodPost, "/teams/:teamID/slos", member, s.handleCreateSLO)
+	withUser(app, http.MethodPut, "/teams/:teamID/slos/:sloID", member, s.handleUpdateSLO)
+	body := fmt.Sprintf(`{"source_id":%d,"name":"availability","query_language":"logchefql","good_query":"status < 500","total_query":"status > 0","target":0.99,"window_seconds":604800}`, src.ID)
+	for _, req := range []struct {
+		method, path string
+	}{
+		{http.MethodPost, fmt.Sprintf("/teams/%d/slos", team.ID)},
+		{http.MethodPut, fmt.Sprintf("/teams/%d/slos/%d", team.ID, slo.ID)},
+	} {
+		if got := doRawSQLRequest(t, app, req.method, req.path, body); got != http.StatusForbidden {
+			t.Errorf("%s %s status = %d, want %d", req.method, req.path, got, http.StatusForbidden)
+		}
+	}
+}
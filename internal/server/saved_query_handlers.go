@@ -142,6 +142,9 @@ func (s *Server) handleCreateSavedQuery(c *fiber.Ctx) error {
 	if !hasAccess {
 		return SendErrorWithType(c, fiber.StatusForbidden, "No team you belong to has access to this source", models.AuthorizationErrorType)
 	}
+	if ok, err := s.checkSourcePermission(c, user, req.SourceID, models.TeamPermissionManageSavedQueries); !ok {
+		return err
+	}
 
 	if req.CreatedFromTeamID != nil {
 		isMember, memberErr := core.IsTeamMember(c.Context(), s.sqlite, *req.CreatedFromTeamID, user.ID)
@@ -198,6 +201,9 @@ func (s *Server) handleUpdateSavedQuery(c *fiber.Ctx) error {
 	if !canEdit {
 		return SendErrorWithType(c, fiber.StatusForbidden, "You don't have permission to edit this query. You must be its creator, a global admin, or an owner/editor of a collection it belongs to.", models.AuthorizationErrorType)
 	}
+	if ok, err := s.checkSourcePermission(c, user, query.SourceID, models.TeamPermissionManageSavedQueries); !ok {
+		return err
+	}
 
 	var req struct {
 		Name          *string                      `json:"name"`
@@ -253,6 +259,9 @@ func (s *Server) handleDeleteSavedQuery(c *fiber.Ctx) error {
 	if !core.UserCanDeleteSavedQuery(query, user) {
 		return SendErrorWithType(c, fiber.StatusForbidden, "Only the creator or a global admin can delete this query", models.AuthorizationErrorType)
 	}
+	if ok, err := s.checkSourcePermission(c, user, query.SourceID, models.TeamPermissionManageSavedQueries); !ok {
+		return err
+	}
 
 	if delErr := core.DeleteSavedQuery(c.Context(), s.sqlite, s.log, query.ID); delErr != nil {
 		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to delete saved query", models.GeneralErrorType)
@@ -312,3 +321,42 @@ func (s *Server) handleResolveSavedQuery(c *fiber.Ctx) error {
 		ResolvedTeamID: resolvedTeamID,
 	})
 }
+
+// handleGetSavedQueryChoices runs a saved query and returns the values of its
+// first column, for enum template variables that use it as their source query.
+func (s *Server) handleGetSavedQueryChoices(c *fiber.Ctx) error {
+	query, user, err := s.loadSavedQueryWithVisibility(c)
+	if err != nil {
+		return err
+	}
+	// Choices aren't read through a team, so no row filter or masks apply to
+	// them.
+	unfiltered, err := core.UserHasUnfilteredSourceAccess(c.Context(), s.sqlite, s.log, user.ID, query.SourceID)
+	if err != nil {
+		s.log.Error("failed to check source row filters", "error", err, "query_id", query.ID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to verify access", models.GeneralErrorType)
+	}
+	if !unfiltered {
+		return SendErrorWithType(c, fiber.StatusForbidden,
+			"Choices aren't available for a source with a row filter for your team", models.AuthorizationErrorType)
+	}
+	unmasked, err := core.UserSeesSourceUnmasked(c.Context(), s.sqlite, s.log, user, query.SourceID)
+	if err != nil {
+		s.log.Error("failed to check source masked columns", "error", err, "query_id", query.ID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to verify access", models.GeneralErrorType)
+	}
+	if !unmasked {
+		return SendErrorWithType(c, fiber.StatusForbidden,
+			"Choices aren't available for a source with columns masked for you", models.AuthorizationErrorType)
+	}
+
+	choices, err := core.LoadVariableChoices(c.Context(), s.datasources, query)
+	if err != nil {
+		if errors.Is(err, core.ErrInvalidQueryDefinition) || errors.Is(err, core.ErrInvalidQueryContent) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+		s.log.Error("failed to load variable choices", "error", err, "query_id", query.ID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to load choices", models.GeneralErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{"choices": choices})
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/schemadrift"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// handleGetSchemaHistory handles GET /admin/sources/:sourceID/schema/history
+// and GET /teams/:teamID/sources/:sourceID/schema/history. It returns the
+// source's schema snapshots newest first, each with its column changes against
+// the one before. Query parameters:
+//   - limit: how many snapshots to return (optional, defaults to 50, at most 500)
+func (s *Server) handleGetSchemaHistory(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID", models.ValidationErrorType)
+	}
+
+	history, err := s.schemaDrift.History(c.Context(), sourceID, c.QueryInt("limit", schemadrift.DefaultHistoryLimit))
+	if err != nil {
+		switch {
+		case errors.Is(err, schemadrift.ErrInvalidRequest):
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		case errors.Is(err, models.ErrNotFound):
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		s.log.Error("failed to get schema history", "error", err, "source_id", sourceID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get schema history", models.GeneralErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, history)
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -52,7 +53,8 @@ func (s *Server) handleGetSourceSchema(c *fiber.Ctx) error {
 		return SendErrorWithType(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to retrieve source schema: %v", err), models.DatabaseErrorType)
 	}
 
-	return SendSuccess(c, fiber.StatusOK, schema)
+	// The schema comes from the datasource, so there's no updated_at to offer.
+	return SendSuccessCacheable(c, schema, time.Time{})
 }
 
 // handleGetFieldValues retrieves distinct values for a specific field within a time range.
@@ -126,15 +128,17 @@ func (s *Server) handleGetFieldValues(c *fiber.Ctx) error {
 	defer cancel()
 
 	result, err := core.GetFieldValues(ctx, s.datasources, sourceID, core.FieldValuesParams{
-		FieldName: fieldName,
-		FieldType: fieldType,
-		Language:  queryLanguage,
-		StartTime: startTime,
-		EndTime:   endTime,
-		Timezone:  timezone,
-		Limit:     limit,
-		Timeout:   nil,
-		QueryText: filterQuery,
+		FieldName:     fieldName,
+		FieldType:     fieldType,
+		Language:      queryLanguage,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		Timezone:      timezone,
+		Limit:         limit,
+		Timeout:       nil,
+		QueryText:     filterQuery,
+		RowFilter:     teamRowFilter(c),
+		MaskedColumns: teamMaskedColumns(c),
 	})
 	if err != nil {
 		// Check if the error was due to context cancellation (client disconnected)
@@ -220,13 +224,15 @@ func (s *Server) handleGetAllFieldValues(c *fiber.Ctx) error {
 	defer cancel()
 
 	result, err := core.GetAllFieldValues(ctx, s.datasources, sourceID, core.AllFieldValuesParams{
-		Language:  queryLanguage,
-		StartTime: startTime,
-		EndTime:   endTime,
-		Timezone:  timezone,
-		Limit:     limit,
-		Timeout:   nil,
-		QueryText: filterQuery,
+		Language:      queryLanguage,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		Timezone:      timezone,
+		Limit:         limit,
+		Timeout:       nil,
+		QueryText:     filterQuery,
+		RowFilter:     teamRowFilter(c),
+		MaskedColumns: teamMaskedColumns(c),
 	})
 	if err != nil {
 		// Check if the error was due to context cancellation (client disconnected)
@@ -252,3 +258,153 @@ func (s *Server) handleGetAllFieldValues(c *fiber.Ctx) error {
 
 	return SendSuccess(c, fiber.StatusOK, result)
 }
+
+// handleGetFieldStats returns statistics for a numeric or datetime field within
+// a time range: min, max, avg, p50/p95/p99 and the null percentage.
+// Access is controlled by the requireSourceAccess middleware.
+// Query params:
+//   - type: the field type from source schema (required)
+//   - start_time: ISO8601 start time (required for performance)
+//   - end_time: ISO8601 end time (required for performance)
+//   - timezone: timezone for time conversion (optional, defaults to UTC)
+//   - query: datasource-native query string (optional, narrows the rows)
+func (s *Server) handleGetFieldStats(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID format", models.ValidationErrorType)
+	}
+
+	fieldName := c.Params("fieldName")
+	if fieldName == "" {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Field name is required", models.ValidationErrorType)
+	}
+	fieldType := c.Query("type", "")
+	if fieldType == "" {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Field type is required (pass from source schema)", models.ValidationErrorType)
+	}
+
+	startTimeStr := c.Query("start_time", "")
+	endTimeStr := c.Query("end_time", "")
+	if startTimeStr == "" || endTimeStr == "" {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Time range (start_time, end_time) is required for performance", models.ValidationErrorType)
+	}
+	startTime, err := time.Parse(time.RFC3339, startTimeStr)
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid start_time format (use ISO8601/RFC3339)", models.ValidationErrorType)
+	}
+	endTime, err := time.Parse(time.RFC3339, endTimeStr)
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid end_time format (use ISO8601/RFC3339)", models.ValidationErrorType)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), FieldValuesTimeout)
+	defer cancel()
+
+	result, err := core.GetFieldStats(ctx, s.datasources, sourceID, core.FieldStatsParams{
+		FieldName: fieldName,
+		FieldType: fieldType,
+		Language:  models.QueryLanguage(c.Query("query_language", "")),
+		StartTime: startTime,
+		EndTime:   endTime,
+		Timezone:  c.Query("timezone", "UTC"),
+		QueryText: c.Query("query", ""),
+	})
+	if err != nil {
+		if ctx.Err() == context.Canceled {
+			return SendErrorWithType(c, fiber.StatusRequestTimeout, "Request cancelled", models.ExternalServiceErrorType)
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			s.log.Warn("field stats request timed out", "source_id", sourceID, "field", fieldName, "timeout", FieldValuesTimeout)
+			return SendErrorWithType(c, fiber.StatusRequestTimeout, "Request timed out", models.ExternalServiceErrorType)
+		}
+		if errors.Is(err, core.ErrSourceNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		if errors.Is(err, datasource.ErrOperationNotSupported) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Field statistics are not supported for this source type yet", models.ValidationErrorType)
+		}
+		if datasource.IsValidationError(err) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err), models.ValidationErrorType)
+		}
+		s.log.Error("failed to get field stats", "error", err, "source_id", sourceID, "field", fieldName)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to get field stats: %v", err), models.DatabaseErrorType)
+	}
+
+	return SendSuccess(c, fiber.StatusOK, result)
+}
+
+// handleGetJSONFields samples recent rows and returns the keys of JSON objects
+// stored in String columns, with their types and frequencies, so they can be
+// filtered as e.g. body.user.id.
+// Access is controlled by the requireSourceAccess middleware.
+// Query params:
+//   - columns: comma-separated String columns to inspect (default "body")
+//   - sample_size: most recent rows to sample (default 1000, max 10000)
+//   - start_time: ISO8601 start time (optional, defaults to an hour before end_time)
+//   - end_time: ISO8601 end time (optional, defaults to now)
+//   - timezone: timezone for time conversion (optional, defaults to UTC)
+//   - query: datasource-native query string (optional, narrows the rows)
+func (s *Server) handleGetJSONFields(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID format", models.ValidationErrorType)
+	}
+
+	endTime := time.Now().UTC()
+	if v := c.Query("end_time", ""); v != "" {
+		if endTime, err = time.Parse(time.RFC3339, v); err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid end_time format (use ISO8601/RFC3339)", models.ValidationErrorType)
+		}
+	}
+	startTime := endTime.Add(-time.Hour)
+	if v := c.Query("start_time", ""); v != "" {
+		if startTime, err = time.Parse(time.RFC3339, v); err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid start_time format (use ISO8601/RFC3339)", models.ValidationErrorType)
+		}
+	}
+	if !startTime.Before(endTime) {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "start_time must be before end_time", models.ValidationErrorType)
+	}
+
+	var columns []string
+	for col := range strings.SplitSeq(c.Query("columns", ""), ",") {
+		if col = strings.TrimSpace(col); col != "" {
+			columns = append(columns, col)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), FieldValuesTimeout)
+	defer cancel()
+
+	result, err := core.GetJSONFields(ctx, s.datasources, sourceID, core.JSONFieldsParams{
+		Columns:    columns,
+		Language:   models.QueryLanguage(c.Query("query_language", "")),
+		StartTime:  startTime,
+		EndTime:    endTime,
+		Timezone:   c.Query("timezone", "UTC"),
+		SampleSize: c.QueryInt("sample_size", 0),
+		QueryText:  c.Query("query", ""),
+	})
+	if err != nil {
+		if ctx.Err() == context.Canceled {
+			return SendErrorWithType(c, fiber.StatusRequestTimeout, "Request cancelled", models.ExternalServiceErrorType)
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			s.log.Warn("JSON fields request timed out", "source_id", sourceID, "timeout", FieldValuesTimeout)
+			return SendErrorWithType(c, fiber.StatusRequestTimeout, "Request timed out", models.ExternalServiceErrorType)
+		}
+		if errors.Is(err, core.ErrSourceNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		if errors.Is(err, datasource.ErrOperationNotSupported) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "JSON field exploration is not supported for this source type yet", models.ValidationErrorType)
+		}
+		if datasource.IsValidationError(err) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err), models.ValidationErrorType)
+		}
+		s.log.Error("failed to get JSON fields", "error", err, "source_id", sourceID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to get JSON fields: %v", err), models.DatabaseErrorType)
+	}
+
+	return SendSuccess(c, fiber.StatusOK, result)
+}
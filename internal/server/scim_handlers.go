@@ -0,0 +1,558 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/scim"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// scimMaxPageSize caps the count parameter of SCIM list requests.
+const scimMaxPageSize = 1000
+
+// setupSCIMRoutes registers the SCIM 2.0 endpoints when [scim] is enabled.
+// They authenticate with the static scim.token, not user sessions or API
+// tokens, since the caller is an identity provider rather than a user.
+func (s *Server) setupSCIMRoutes() {
+	if !s.config.SCIM.Enabled {
+		return
+	}
+	g := s.app.Group("/scim/v2", s.requireSCIMToken)
+	g.Get("/ServiceProviderConfig", s.handleSCIMServiceProviderConfig)
+	g.Get("/ResourceTypes", s.handleSCIMResourceTypes)
+
+	g.Get("/Users", s.handleSCIMListUsers)
+	g.Post("/Users", s.handleSCIMCreateUser)
+	g.Get("/Users/:id", s.handleSCIMGetUser)
+	g.Put("/Users/:id", s.handleSCIMReplaceUser)
+	g.Patch("/Users/:id", s.handleSCIMPatchUser)
+	g.Delete("/Users/:id", s.handleSCIMDeleteUser)
+
+	g.Get("/Groups", s.handleSCIMListGroups)
+	g.Post("/Groups", s.handleSCIMCreateGroup)
+	g.Get("/Groups/:id", s.handleSCIMGetGroup)
+	g.Put("/Groups/:id", s.handleSCIMReplaceGroup)
+	g.Patch("/Groups/:id", s.handleSCIMPatchGroup)
+	g.Delete("/Groups/:id", s.handleSCIMDeleteGroup)
+}
+
+// requireSCIMToken checks the bearer token against scim.token in constant time.
+func (s *Server) requireSCIMToken(c *fiber.Ctx) error {
+	token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.config.SCIM.Token)) != 1 {
+		return sendSCIMError(c, fiber.StatusUnauthorized, "", "Invalid or missing bearer token")
+	}
+	return c.Next()
+}
+
+func sendSCIM(c *fiber.Ctx, status int, body any) error {
+	c.Set(fiber.HeaderContentType, scim.ContentType)
+	return c.Status(status).JSON(body, scim.ContentType)
+}
+
+func sendSCIMError(c *fiber.Ctx, status int, scimType, detail string) error {
+	return sendSCIM(c, status, scim.NewError(status, scimType, detail))
+}
+
+// parseSCIMBody decodes a request body. BodyParser can't be used: IdPs send
+// application/scim+json, which it doesn't recognise.
+func parseSCIMBody(c *fiber.Ctx, v any) error {
+	if err := json.Unmarshal(c.Body(), v); err != nil {
+		return sendSCIMError(c, fiber.StatusBadRequest, scim.ErrTypeInvalidSyntax, "Invalid JSON body")
+	}
+	return nil
+}
+
+// scimCoreError maps a core error from a user or team write to a SCIM error.
+func (s *Server) scimCoreError(c *fiber.Ctx, err error, what string) error {
+	var verr *core.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		return sendSCIMError(c, fiber.StatusBadRequest, scim.ErrTypeInvalidValue, verr.Error())
+	case errors.Is(err, core.ErrUserAlreadyExists), errors.Is(err, core.ErrTeamAlreadyExists):
+		return sendSCIMError(c, fiber.StatusConflict, scim.ErrTypeUniqueness, err.Error())
+	case errors.Is(err, core.ErrUserNotFound), errors.Is(err, core.ErrTeamNotFound):
+		return sendSCIMError(c, fiber.StatusNotFound, "", err.Error())
+	case errors.Is(err, core.ErrCannotDeleteLastAdmin):
+		return sendSCIMError(c, fiber.StatusBadRequest, scim.ErrTypeMutability, err.Error())
+	default:
+		s.log.Error("scim: failed to "+what, "error", err)
+		return sendSCIMError(c, fiber.StatusInternalServerError, "", "Failed to "+what)
+	}
+}
+
+func (s *Server) handleSCIMServiceProviderConfig(c *fiber.Ctx) error {
+	return sendSCIM(c, fiber.StatusOK, fiber.Map{
+		"schemas":        []string{scim.SchemaServiceProviderConfig},
+		"patch":          fiber.Map{"supported": true},
+		"bulk":           fiber.Map{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         fiber.Map{"supported": true, "maxResults": scimMaxPageSize},
+		"changePassword": fiber.Map{"supported": false},
+		"sort":           fiber.Map{"supported": false},
+		"etag":           fiber.Map{"supported": false},
+		"authenticationSchemes": []fiber.Map{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer token",
+			"description": "Static token configured as scim.token",
+		}},
+	})
+}
+
+func (s *Server) handleSCIMResourceTypes(c *fiber.Ctx) error {
+	types := []any{
+		fiber.Map{"schemas": []string{scim.SchemaResourceType}, "id": "User", "name": "User", "endpoint": "/Users", "schema": scim.SchemaUser},
+		fiber.Map{"schemas": []string{scim.SchemaResourceType}, "id": "Group", "name": "Group", "endpoint": "/Groups", "schema": scim.SchemaGroup},
+	}
+	return sendSCIM(c, fiber.StatusOK, scim.NewListResponse(types, len(types), 1))
+}
+
+// --- Users ---
+
+func (s *Server) scimUser(c *fiber.Ctx, u *models.User) *scim.User {
+	active := u.Status == models.UserStatusActive
+	created, updated := u.CreatedAt, u.UpdatedAt
+	id := strconv.FormatInt(int64(u.ID), 10)
+	return &scim.User{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          id,
+		UserName:    u.Email,
+		DisplayName: u.FullName,
+		Name:        &scim.Name{Formatted: u.FullName},
+		Emails:      []scim.Email{{Value: u.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      &created,
+			LastModified: &updated,
+			Location:     c.BaseURL() + "/scim/v2/Users/" + id,
+		},
+	}
+}
+
+// scimLookupUser loads the user named by :id. Service accounts are not
+// visible over SCIM. It returns a nil user after sending the error response.
+func (s *Server) scimLookupUser(c *fiber.Ctx) (*models.User, error) {
+	id, err := core.ParseUserID(c.Params("id"))
+	if err != nil {
+		return nil, sendSCIMError(c, fiber.StatusNotFound, "", "User not found")
+	}
+	user, err := core.GetUser(c.Context(), s.sqlite, id)
+	if err != nil || user.AccountType == models.UserAccountTypeService {
+		if err == nil || errors.Is(err, core.ErrUserNotFound) {
+			return nil, sendSCIMError(c, fiber.StatusNotFound, "", "User not found")
+		}
+		return nil, s.scimCoreError(c, err, "get user")
+	}
+	return user, nil
+}
+
+func (s *Server) handleSCIMListUsers(c *fiber.Ctx) error {
+	filter, err := scim.ParseFilter(c.Query("filter"))
+	if err != nil {
+		return sendSCIMError(c, fiber.StatusBadRequest, scim.ErrTypeInvalidFilter, err.Error())
+	}
+	if filter != nil && filter.Attr != "username" && filter.Attr != "emails.value" && filter.Attr != "emails" {
+		return sendSCIMError(c, fiber.StatusBadRequest, scim.ErrTypeInvalidFilter, "Users can be filtered by userName or emails.value only")
+	}
+
+	users, err := core.ListUsers(c.Context(), s.sqlite)
+	if err != nil {
+		return s.scimCoreError(c, err, "list users")
+	}
+	matched := make([]*models.User, 0, len(users))
+	for _, u := range users {
+		if u.AccountType == models.UserAccountTypeService {
+			continue
+		}
+		if filter != nil && !strings.EqualFold(u.Email, filter.Value) {
+			continue
+		}
+		matched = append(matched, u)
+	}
+
+	start := c.QueryInt("startIndex", 1)
+	from, to := scim.Page(start, c.QueryInt("count", scimMaxPageSize), len(matched), scimMaxPageSize)
+	resources := make([]any, 0, to-from)
+	for _, u := range matched[from:to] {
+		resources = append(resources, s.scimUser(c, u))
+	}
+	return sendSCIM(c, fiber.StatusOK, scim.NewListResponse(resources, len(matched), max(start, 1)))
+}
+
+func (s *Server) handleSCIMGetUser(c *fiber.Ctx) error {
+	user, err := s.scimLookupUser(c)
+	if user == nil {
+		return err
+	}
+	return sendSCIM(c, fiber.StatusOK, s.scimUser(c, user))
+}
+
+// handleSCIMCreateUser creates a member user. SCIM never grants the global
+// admin role; that stays with auth.admin_emails and the admin UI.
+// URL: POST /scim/v2/Users
+func (s *Server) handleSCIMCreateUser(c *fiber.Ctx) error {
+	var req scim.User
+	if err := parseSCIMBody(c, &req); err != nil {
+		return err
+	}
+	email := strings.ToLower(strings.TrimSpace(req.UserName))
+	if email == "" {
+		return sendSCIMError(c, fiber.StatusBadRequest, scim.ErrTypeInvalidValue, "userName is required")
+	}
+	status := models.UserStatusActive
+	if !req.IsActive() {
+		status = models.UserStatusInactive
+	}
+
+	user, err := core.CreateUser(c.Context(), s.sqlite, s.log, email, req.FullName(), models.UserRoleMember, status)
+	if err != nil {
+		return s.scimCoreError(c, err, "create user")
+	}
+	s.log.Info("scim: user created", "user_id", user.ID, "email", user.Email)
+	return sendSCIM(c, fiber.StatusCreated, s.scimUser(c, user))
+}
+
+// handleSCIMReplaceUser replaces the user's name and active state.
+// URL: PUT /scim/v2/Users/:id
+func (s *Server) handleSCIMReplaceUser(c *fiber.Ctx) error {
+	user, err := s.scimLookupUser(c)
+	if user == nil {
+		return err
+	}
+	var req scim.User
+	if err := parseSCIMBody(c, &req); err != nil {
+		return err
+	}
+	return s.scimApplyUser(c, user, &req)
+}
+
+// handleSCIMPatchUser applies PATCH operations to the user. Setting active to
+// false deactivates the user and ends their sessions.
+// URL: PATCH /scim/v2/Users/:id
+func (s *Server) handleSCIMPatchUser(c *fiber.Ctx) error {
+	user, err := s.scimLookupUser(c)
+	if user == nil {
+		return err
+	}
+	var req scim.PatchRequest
+	if err := parseSCIMBody(c, &req); err != nil {
+		return err
+	}
+	patched := s.scimUser(c, user)
+	if err := scim.ApplyUserPatch(patched, req.Operations); err != nil {
+		return sendSCIMError(c, fiber.StatusBadRequest, scim.ErrTypeInvalidValue, err.Error())
+	}
+	return s.scimApplyUser(c, user, patched)
+}
+
+// scimApplyUser writes the name and active state of want onto user.
+// userName is the email address, which logchef doesn't allow to change.
+func (s *Server) scimApplyUser(c *fiber.Ctx, user *models.User, want *scim.User) error {
+	if want.UserName != "" && !strings.EqualFold(strings.TrimSpace(want.UserName), user.Email) {
+		return sendSCIMError(c, fiber.StatusBadRequest, scim.ErrTypeMutability, "userName (email) cannot be changed")
+	}
+	if user.Managed {
+		return sendSCIMError(c, fiber.StatusForbidden, scim.ErrTypeMutability, "User is managed by provisioning config")
+	}
+
+	update := models.User{FullName: want.FullName(), Status: models.UserStatusActive}
+	if !want.IsActive() {
+		update.Status = models.UserStatusInactive
+	}
+	if err := core.UpdateUser(c.Context(), s.sqlite, s.log, user.ID, update); err != nil {
+		return s.scimCoreError(c, err, "update user")
+	}
+	if update.Status != user.Status {
+		s.log.Info("scim: user status changed", "user_id", user.ID, "status", update.Status)
+	}
+
+	updated, err := core.GetUser(c.Context(), s.sqlite, user.ID)
+	if err != nil {
+		return s.scimCoreError(c, err, "get user")
+	}
+	return sendSCIM(c, fiber.StatusOK, s.scimUser(c, updated))
+}
+
+// handleSCIMDeleteUser deletes the user and their sessions.
+// URL: DELETE /scim/v2/Users/:id
+func (s *Server) handleSCIMDeleteUser(c *fiber.Ctx) error {
+	user, err := s.scimLookupUser(c)
+	if user == nil {
+		return err
+	}
+	if user.Managed {
+		return sendSCIMError(c, fiber.StatusForbidden, scim.ErrTypeMutability, "User is managed by provisioning config")
+	}
+	if err := core.DeleteUser(c.Context(), s.sqlite, s.log, user.ID); err != nil {
+		return s.scimCoreError(c, err, "delete user")
+	}
+	s.log.Info("scim: user deleted", "user_id", user.ID, "email", user.Email)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// --- Groups (teams) ---
+
+func (s *Server) scimGroup(c *fiber.Ctx, team *models.Team, members []*models.TeamMember) *scim.Group {
+	created, updated := team.CreatedAt, team.UpdatedAt
+	id := strconv.FormatInt(int64(team.ID), 10)
+	g := &scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          id,
+		DisplayName: team.Name,
+		Meta: &scim.Meta{
+			ResourceType: "Group",
+			Created:      &created,
+			LastModified: &updated,
+			Location:     c.BaseURL() + "/scim/v2/Groups/" + id,
+		},
+	}
+	for _, m := range members {
+		if m.AccountType == models.UserAccountTypeService {
+			continue
+		}
+		uid := strconv.FormatInt(int64(m.UserID), 10)
+		g.Members = append(g.Members, scim.Member{
+			Value:   uid,
+			Display: m.Email,
+			Ref:     c.BaseURL() + "/scim/v2/Users/" + uid,
+		})
+	}
+	return g
+}
+
+// scimLookupGroup loads the team named by :id and its members. It returns a
+// nil team after sending the error response.
+func (s *Server) scimLookupGroup(c *fiber.Ctx) (*models.Team, []*models.TeamMember, error) {
+	id, err := core.ParseTeamID(c.Params("id"))
+	if err != nil {
+		return nil, nil, sendSCIMError(c, fiber.StatusNotFound, "", "Group not found")
+	}
+	team, err := core.GetTeam(c.Context(), s.sqlite, id)
+	if err != nil {
+		if errors.Is(err, core.ErrTeamNotFound) {
+			return nil, nil, sendSCIMError(c, fiber.StatusNotFound, "", "Group not found")
+		}
+		return nil, nil, s.scimCoreError(c, err, "get group")
+	}
+	members, err := core.ListTeamMembers(c.Context(), s.sqlite, id)
+	if err != nil {
+		return nil, nil, s.scimCoreError(c, err, "list group members")
+	}
+	return team, members, nil
+}
+
+func (s *Server) handleSCIMListGroups(c *fiber.Ctx) error {
+	filter, err := scim.ParseFilter(c.Query("filter"))
+	if err != nil {
+		return sendSCIMError(c, fiber.StatusBadRequest, scim.ErrTypeInvalidFilter, err.Error())
+	}
+	if filter != nil && filter.Attr != "displayname" {
+		return sendSCIMError(c, fiber.StatusBadRequest, scim.ErrTypeInvalidFilter, "Groups can be filtered by displayName only")
+	}
+
+	teams, err := core.ListTeams(c.Context(), s.sqlite)
+	if err != nil {
+		return s.scimCoreError(c, err, "list groups")
+	}
+	matched := make([]*models.Team, 0, len(teams))
+	for _, t := range teams {
+		if filter == nil || strings.EqualFold(t.Name, filter.Value) {
+			matched = append(matched, t)
+		}
+	}
+
+	// IdPs that only need ids and names ask to skip the member lists.
+	withMembers := !strings.Contains(strings.ToLower(c.Query("excludedAttributes")), "members")
+	start := c.QueryInt("startIndex", 1)
+	from, to := scim.Page(start, c.QueryInt("count", scimMaxPageSize), len(matched), scimMaxPageSize)
+	resources := make([]any, 0, to-from)
+	for _, t := range matched[from:to] {
+		var members []*models.TeamMember
+		if withMembers {
+			if members, err = core.ListTeamMembers(c.Context(), s.sqlite, t.ID); err != nil {
+				return s.scimCoreError(c, err, "list group members")
+			}
+		}
+		resources = append(resources, s.scimGroup(c, t, members))
+	}
+	return sendSCIM(c, fiber.StatusOK, scim.NewListResponse(resources, len(matched), max(start, 1)))
+}
+
+func (s *Server) handleSCIMGetGroup(c *fiber.Ctx) error {
+	team, members, err := s.scimLookupGroup(c)
+	if team == nil {
+		return err
+	}
+	return sendSCIM(c, fiber.StatusOK, s.scimGroup(c, team, members))
+}
+
+// handleSCIMCreateGroup creates a team and adds the listed users as members.
+// URL: POST /scim/v2/Groups
+func (s *Server) handleSCIMCreateGroup(c *fiber.Ctx) error {
+	var req scim.Group
+	if err := parseSCIMBody(c, &req); err != nil {
+		return err
+	}
+	userIDs, err := s.scimMemberIDs(c, req.Members)
+	if userIDs == nil {
+		return err
+	}
+
+	team, err := core.CreateTeam(c.Context(), s.sqlite, s.log, strings.TrimSpace(req.DisplayName), "")
+	if err != nil {
+		return s.scimCoreError(c, err, "create group")
+	}
+	if err := s.scimSyncMembers(c, team.ID, nil, userIDs); err != nil {
+		return s.scimCoreError(c, err, "add group members")
+	}
+	s.log.Info("scim: group created", "team_id", team.ID, "name", team.Name)
+
+	team, members, err := s.scimLookupGroupByID(c, team.ID)
+	if err != nil {
+		return s.scimCoreError(c, err, "get group")
+	}
+	return sendSCIM(c, fiber.StatusCreated, s.scimGroup(c, team, members))
+}
+
+// handleSCIMReplaceGroup renames the team and replaces its member list.
+// URL: PUT /scim/v2/Groups/:id
+func (s *Server) handleSCIMReplaceGroup(c *fiber.Ctx) error {
+	team, members, err := s.scimLookupGroup(c)
+	if team == nil {
+		return err
+	}
+	var req scim.Group
+	if err := parseSCIMBody(c, &req); err != nil {
+		return err
+	}
+	return s.scimApplyGroup(c, team, members, &req)
+}
+
+// handleSCIMPatchGroup applies PATCH operations to the team's name and
+// members.
+// URL: PATCH /scim/v2/Groups/:id
+func (s *Server) handleSCIMPatchGroup(c *fiber.Ctx) error {
+	team, members, err := s.scimLookupGroup(c)
+	if team == nil {
+		return err
+	}
+	var req scim.PatchRequest
+	if err := parseSCIMBody(c, &req); err != nil {
+		return err
+	}
+	patched := s.scimGroup(c, team, members)
+	if err := scim.ApplyGroupPatch(patched, req.Operations); err != nil {
+		return sendSCIMError(c, fiber.StatusBadRequest, scim.ErrTypeInvalidValue, err.Error())
+	}
+	return s.scimApplyGroup(c, team, members, patched)
+}
+
+// scimApplyGroup renames team to want's displayName and makes its members
+// exactly want's members. New members join with the "member" role; existing
+// members keep their role.
+func (s *Server) scimApplyGroup(c *fiber.Ctx, team *models.Team, members []*models.TeamMember, want *scim.Group) error {
+	if team.Managed {
+		return sendSCIMError(c, fiber.StatusForbidden, scim.ErrTypeMutability, "Group is managed by provisioning config")
+	}
+	userIDs, err := s.scimMemberIDs(c, want.Members)
+	if userIDs == nil {
+		return err
+	}
+
+	if name := strings.TrimSpace(want.DisplayName); name != "" && name != team.Name {
+		if err := core.UpdateTeam(c.Context(), s.sqlite, s.log, team.ID, models.Team{Name: name, Description: team.Description}); err != nil {
+			return s.scimCoreError(c, err, "rename group")
+		}
+	}
+	if err := s.scimSyncMembers(c, team.ID, members, userIDs); err != nil {
+		return s.scimCoreError(c, err, "update group members")
+	}
+
+	team, members, err = s.scimLookupGroupByID(c, team.ID)
+	if err != nil {
+		return s.scimCoreError(c, err, "get group")
+	}
+	return sendSCIM(c, fiber.StatusOK, s.scimGroup(c, team, members))
+}
+
+// handleSCIMDeleteGroup deletes the team.
+// URL: DELETE /scim/v2/Groups/:id
+func (s *Server) handleSCIMDeleteGroup(c *fiber.Ctx) error {
+	team, _, err := s.scimLookupGroup(c)
+	if team == nil {
+		return err
+	}
+	if team.Managed {
+		return sendSCIMError(c, fiber.StatusForbidden, scim.ErrTypeMutability, "Group is managed by provisioning config")
+	}
+	if err := core.DeleteTeam(c.Context(), s.sqlite, s.log, team.ID); err != nil {
+		return s.scimCoreError(c, err, "delete group")
+	}
+	s.log.Info("scim: group deleted", "team_id", team.ID, "name", team.Name)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (s *Server) scimLookupGroupByID(c *fiber.Ctx, id models.TeamID) (*models.Team, []*models.TeamMember, error) {
+	team, err := core.GetTeam(c.Context(), s.sqlite, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	members, err := core.ListTeamMembers(c.Context(), s.sqlite, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return team, members, nil
+}
+
+// scimMemberIDs resolves member values to existing user ids, so a bad id
+// is rejected before anything is written. It returns a nil map after
+// sending the error response.
+func (s *Server) scimMemberIDs(c *fiber.Ctx, members []scim.Member) (map[models.UserID]bool, error) {
+	ids := make(map[models.UserID]bool, len(members))
+	for _, m := range members {
+		id, err := core.ParseUserID(m.Value)
+		if err != nil {
+			return nil, sendSCIMError(c, fiber.StatusBadRequest, scim.ErrTypeInvalidValue, fmt.Sprintf("Invalid member %q", m.Value))
+		}
+		user, err := core.GetUser(c.Context(), s.sqlite, id)
+		if err != nil || user.AccountType == models.UserAccountTypeService {
+			return nil, sendSCIMError(c, fiber.StatusBadRequest, scim.ErrTypeNoTarget, fmt.Sprintf("Unknown member %q", m.Value))
+		}
+		ids[id] = true
+	}
+	return ids, nil
+}
+
+// scimSyncMembers adds the users in want that aren't in current and removes
+// the current non-service members not in want, auditing each change.
+func (s *Server) scimSyncMembers(c *fiber.Ctx, teamID models.TeamID, current []*models.TeamMember, want map[models.UserID]bool) error {
+	have := make(map[models.UserID]bool, len(current))
+	for _, m := range current {
+		have[m.UserID] = true
+		if m.AccountType == models.UserAccountTypeService || want[m.UserID] {
+			continue
+		}
+		if err := core.RemoveTeamMember(c.Context(), s.sqlite, s.log, teamID, m.UserID); err != nil {
+			return err
+		}
+		s.recordAudit(c, models.AuditActionTeamMemberRemove, models.AuditResourceTeamMember, auditTeamMemberID(teamID, m.UserID), &teamID, map[string]any{"via": "scim"})
+	}
+	for id := range want {
+		if have[id] {
+			continue
+		}
+		if err := core.AddTeamMember(c.Context(), s.sqlite, s.log, teamID, id, models.TeamRoleMember); err != nil {
+			return err
+		}
+		s.recordAudit(c, models.AuditActionTeamMemberAdd, models.AuditResourceTeamMember, auditTeamMemberID(teamID, id), &teamID, map[string]any{"role": models.TeamRoleMember, "via": "scim"})
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/scim"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+const testSCIMToken = "scim-test-token-0123456789abcdef0123"
+
+func newSCIMTestApp(t *testing.T) (*Server, *fiber.App) {
+	t.Helper()
+	s := newDashboardTestServer(t)
+	s.config = &config.Config{SCIM: config.SCIMConfig{Enabled: true, Token: testSCIMToken}}
+	s.app = fiber.New()
+	s.setupSCIMRoutes()
+	return s, s.app
+}
+
+// scimDo sends a SCIM request and decodes the response body into out (when
+// non-nil), returning the status code.
+func scimDo(t *testing.T, app *fiber.App, method, path, body string, out any) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testSCIMToken)
+	req.Header.Set("Content-Type", scim.ContentType)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+	if out != nil && len(raw) > 0 {
+		if err := json.Unmarshal(raw, out); err != nil {
+			t.Fatalf("%s %s: decode %s: %v", method, path, raw, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestSCIM_RequiresToken(t *testing.T) {
+	_, app := newSCIMTestApp(t)
+	for _, auth := range []string{"", "Bearer wrong", testSCIMToken} {
+		req := httptest.NewRequest(http.MethodGet, "/scim/v2/Users", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status = %d, want 401", auth, resp.StatusCode)
+		}
+	}
+}
+
+// TestSCIM_UserLifecycle provisions a user the way an IdP does (look up by
+// userName, create, deactivate, delete) and checks deactivation ends the
+// user's sessions.
+func TestSCIM_UserLifecycle(t *testing.T) {
+	s, app := newSCIMTestApp(t)
+	ctx := context.Background()
+
+	var list scim.ListResponse
+	if code := scimDo(t, app, http.MethodGet, `/scim/v2/Users?filter=userName%20eq%20%22alice@example.com%22`, "", &list); code != http.StatusOK || list.TotalResults != 0 {
+		t.Fatalf("lookup before create: status %d, total %d", code, list.TotalResults)
+	}
+
+	var created scim.User
+	body := `{"schemas":["` + scim.SchemaUser + `"],"userName":"Alice@Example.com","name":{"givenName":"Alice","familyName":"Smith"},"active":true}`
+	if code := scimDo(t, app, http.MethodPost, "/scim/v2/Users", body, &created); code != http.StatusCreated {
+		t.Fatalf("create: status %d", code)
+	}
+	if created.UserName != "alice@example.com" || created.DisplayName != "Alice Smith" || !created.IsActive() {
+		t.Fatalf("created = %+v", created)
+	}
+	if code := scimDo(t, app, http.MethodPost, "/scim/v2/Users", body, nil); code != http.StatusConflict {
+		t.Errorf("duplicate create: status %d, want 409", code)
+	}
+	if code := scimDo(t, app, http.MethodGet, `/scim/v2/Users?filter=userName%20eq%20%22alice@example.com%22`, "", &list); code != http.StatusOK || list.TotalResults != 1 {
+		t.Fatalf("lookup after create: status %d, total %d", code, list.TotalResults)
+	}
+
+	id, _ := core.ParseUserID(created.ID)
+	session, err := core.CreateSession(ctx, s.sqlite, s.log, id, time.Hour, 5)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	var patched scim.User
+	patch := `{"schemas":["` + scim.SchemaPatchOp + `"],"Operations":[{"op":"Replace","path":"active","value":"False"}]}`
+	if code := scimDo(t, app, http.MethodPatch, "/scim/v2/Users/"+created.ID, patch, &patched); code != http.StatusOK {
+		t.Fatalf("deactivate: status %d", code)
+	}
+	if patched.IsActive() {
+		t.Error("user still active after PATCH active=false")
+	}
+	if _, err := core.ValidateSession(ctx, s.sqlite, s.log, session.ID); err == nil {
+		t.Error("session still valid after deactivation")
+	}
+
+	rename := `{"userName":"alice@example.com","displayName":"Alice Jones","active":true}`
+	if code := scimDo(t, app, http.MethodPut, "/scim/v2/Users/"+created.ID, rename, &patched); code != http.StatusOK {
+		t.Fatalf("replace: status %d", code)
+	}
+	if patched.DisplayName != "Alice Jones" || !patched.IsActive() {
+		t.Errorf("after PUT = %+v", patched)
+	}
+	if code := scimDo(t, app, http.MethodPut, "/scim/v2/Users/"+created.ID, `{"userName":"bob@example.com"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("userName change: status %d, want 400", code)
+	}
+
+	if code := scimDo(t, app, http.MethodDelete, "/scim/v2/Users/"+created.ID, "", nil); code != http.StatusNoContent {
+		t.Fatalf("delete: status %d", code)
+	}
+	if code := scimDo(t, app, http.MethodGet, "/scim/v2/Users/"+created.ID, "", nil); code != http.StatusNotFound {
+		t.Errorf("get after delete: status %d, want 404", code)
+	}
+}
+
+func TestSCIM_GroupMembership(t *testing.T) {
+	s, app := newSCIMTestApp(t)
+	ctx := context.Background()
+	alice := mkTestUser(t, s.sqlite, "alice@example.com", models.UserRoleMember)
+	bob := mkTestUser(t, s.sqlite, "bob@example.com", models.UserRoleMember)
+	aliceID, bobID := strconv.FormatInt(int64(alice.ID), 10), strconv.FormatInt(int64(bob.ID), 10)
+
+	var group scim.Group
+	body := `{"schemas":["` + scim.SchemaGroup + `"],"displayName":"sre","members":[{"value":"` + aliceID + `"}]}`
+	if code := scimDo(t, app, http.MethodPost, "/scim/v2/Groups", body, &group); code != http.StatusCreated {
+		t.Fatalf("create group: status %d", code)
+	}
+	if group.DisplayName != "sre" || len(group.Members) != 1 || group.Members[0].Value != aliceID {
+		t.Fatalf("created group = %+v", group)
+	}
+	if code := scimDo(t, app, http.MethodPost, "/scim/v2/Groups", `{"displayName":"x","members":[{"value":"9999"}]}`, nil); code != http.StatusBadRequest {
+		t.Errorf("unknown member: status %d, want 400", code)
+	}
+
+	teamID, _ := core.ParseTeamID(group.ID)
+	// A role granted in logchef survives SCIM membership updates.
+	if err := core.AddTeamMember(ctx, s.sqlite, s.log, teamID, alice.ID, models.TeamRoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	patch := `{"schemas":["` + scim.SchemaPatchOp + `"],"Operations":[
+		{"op":"add","path":"members","value":[{"value":"` + bobID + `"}]},
+		{"op":"replace","path":"displayName","value":"platform"}]}`
+	if code := scimDo(t, app, http.MethodPatch, "/scim/v2/Groups/"+group.ID, patch, &group); code != http.StatusOK {
+		t.Fatalf("patch group: status %d", code)
+	}
+	if group.DisplayName != "platform" || len(group.Members) != 2 {
+		t.Fatalf("patched group = %+v", group)
+	}
+	alicem, err := s.sqlite.GetTeamMember(ctx, teamID, alice.ID)
+	if err != nil || alicem.Role != models.TeamRoleAdmin {
+		t.Errorf("alice role = %v, %v; want admin kept", alicem, err)
+	}
+
+	remove := `{"Operations":[{"op":"remove","path":"members[value eq \"` + aliceID + `\"]"}]}`
+	if code := scimDo(t, app, http.MethodPatch, "/scim/v2/Groups/"+group.ID, remove, &group); code != http.StatusOK {
+		t.Fatalf("remove member: status %d", code)
+	}
+	if len(group.Members) != 1 || group.Members[0].Value != bobID {
+		t.Errorf("members after remove = %+v", group.Members)
+	}
+
+	var list scim.ListResponse
+	if code := scimDo(t, app, http.MethodGet, `/scim/v2/Groups?filter=displayName%20eq%20%22platform%22&excludedAttributes=members`, "", &list); code != http.StatusOK || list.TotalResults != 1 {
+		t.Fatalf("group lookup: status %d, total %d", code, list.TotalResults)
+	}
+
+	if code := scimDo(t, app, http.MethodDelete, "/scim/v2/Groups/"+group.ID, "", nil); code != http.StatusNoContent {
+		t.Fatalf("delete group: status %d", code)
+	}
+}
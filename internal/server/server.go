@@ -11,13 +11,23 @@ import (
 	"time"
 
 	"github.com/mr-karan/logchef/internal/alerts"
+	"github.com/mr-karan/logchef/internal/analytics"
+	"github.com/mr-karan/logchef/internal/artifacts"
+	"github.com/mr-karan/logchef/internal/audit"
 	"github.com/mr-karan/logchef/internal/auth"
 	dashcache "github.com/mr-karan/logchef/internal/cache"
 	"github.com/mr-karan/logchef/internal/clickhouse"
 	"github.com/mr-karan/logchef/internal/config"
 	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/ingest"
 	"github.com/mr-karan/logchef/internal/metrics"
+	"github.com/mr-karan/logchef/internal/rollups"
+	"github.com/mr-karan/logchef/internal/schemadrift"
+	"github.com/mr-karan/logchef/internal/sourcealerts"
+	"github.com/mr-karan/logchef/internal/sourcestats"
 	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/internal/tracing"
+	"github.com/mr-karan/logchef/internal/webhooks"
 	"github.com/mr-karan/logchef/pkg/models"
 
 	"github.com/gofiber/fiber/v2"
@@ -37,9 +47,17 @@ type ServerOptions struct {
 	SQLite        store.Store
 	ClickHouse    *clickhouse.Manager
 	Datasources   *datasource.Service
-	AlertsManager *alerts.Manager    // Alerts manager for manual resolution and notifications.
-	OIDCProvider  *auth.OIDCProvider // OIDC provider for authentication flows.
-	FS            http.FileSystem    // Filesystem for serving static assets (frontend).
+	AlertsManager *alerts.Manager       // Alerts manager for manual resolution and notifications.
+	Audit         *audit.Writer         // Records sensitive operations; nil disables auditing.
+	Rollups       *rollups.Manager      // Source rollup configuration and trend queries.
+	SourceStats   *sourcestats.Manager  // Daily source storage snapshots.
+	SchemaDrift   *schemadrift.Manager  // Source column history.
+	SourceAlerts  *sourcealerts.Manager // Source degradation alert history.
+	Analytics     *analytics.Manager    // Query usage reports.
+	Artifacts     artifacts.Store       // Export results and notebook snapshots.
+	Webhooks      *webhooks.Dispatcher  // Outbound lifecycle event webhooks.
+	OIDCProvider  *auth.OIDCProvider    // OIDC provider for authentication flows.
+	FS            http.FileSystem       // Filesystem for serving static assets (frontend).
 	Logger        *slog.Logger
 	BuildInfo     string
 	Version       string
@@ -53,13 +71,23 @@ type Server struct {
 	sqlite        store.Store
 	clickhouse    *clickhouse.Manager
 	datasources   *datasource.Service
-	alertsManager *alerts.Manager    // Alerts manager for manual resolution and notifications.
-	oidcProvider  *auth.OIDCProvider // Handles OIDC authentication logic.
+	alertsManager *alerts.Manager       // Alerts manager for manual resolution and notifications.
+	audit         *audit.Writer         // Async audit trail writer (nil-safe).
+	rollups       *rollups.Manager      // Source rollups and long-range trends.
+	sourceStats   *sourcestats.Manager  // Source storage growth history.
+	schemaDrift   *schemadrift.Manager  // Source column history and drift.
+	sourceAlerts  *sourcealerts.Manager // Source degradation alert events.
+	analytics     *analytics.Manager    // Query usage and slow-query reports.
+	artifacts     artifacts.Store       // Export results and notebook snapshots.
+	webhooks      *webhooks.Dispatcher  // Sends test events to registered webhooks.
+	oidcProvider  *auth.OIDCProvider    // Handles OIDC authentication logic.
 	fs            http.FileSystem
 	log           *slog.Logger
 	buildInfo     string
 	version       string
 	dashCache     *dashcache.Cache // per-dashboard TTL result cache
+	queries       *QueryTracker    // In-flight queries for admission and cancellation.
+	otlpBatcher   *ingest.Batcher  // Merges OTLP requests into per-source inserts; nil unless enabled.
 
 	stop chan struct{} // closed by Shutdown to stop background maintenance loops
 	wg   sync.WaitGroup
@@ -98,7 +126,9 @@ func New(opts ServerOptions) *Server {
 		// data, so a few MB is generous. This is a coarse transport-level
 		// backstop; the LogchefQL parser additionally enforces its own
 		// (much smaller) query length/nesting limits regardless of this cap.
-		BodyLimit: 4 * 1024 * 1024, // 4MB
+		// An enabled ingest endpoint may raise it to its own limit, in which
+		// case limitBody below keeps every other route at the default.
+		BodyLimit: bodyLimit(opts.Config),
 		// Client-IP resolution behind a reverse proxy. The check is always on:
 		// with an empty TrustedProxies list Fiber returns the direct peer IP
 		// (default/current behavior); it reads ProxyHeader only when the direct
@@ -135,9 +165,16 @@ func New(opts ServerOptions) *Server {
 	// Add metrics middleware
 	app.Use(metrics.Middleware())
 
+	// Add tracing middleware (a no-op unless [tracing] is enabled)
+	app.Use(tracing.Middleware())
+
 	// Add request logging middleware
 	app.Use(requestLogger(log))
 
+	if bodyLimit(opts.Config) > defaultBodyLimit {
+		app.Use(limitBody(defaultBodyLimit))
+	}
+
 	// Create the Server instance, injecting dependencies.
 	s := &Server{
 		app:           app,
@@ -146,6 +183,14 @@ func New(opts ServerOptions) *Server {
 		clickhouse:    opts.ClickHouse,
 		datasources:   opts.Datasources,
 		alertsManager: opts.AlertsManager,
+		audit:         opts.Audit,
+		rollups:       opts.Rollups,
+		sourceStats:   opts.SourceStats,
+		schemaDrift:   opts.SchemaDrift,
+		sourceAlerts:  opts.SourceAlerts,
+		analytics:     opts.Analytics,
+		artifacts:     opts.Artifacts,
+		webhooks:      opts.Webhooks,
 		oidcProvider:  opts.OIDCProvider,
 		fs:            opts.FS,
 		log:           opts.Logger,
@@ -160,12 +205,25 @@ func New(opts ServerOptions) *Server {
 			MaxEntries:         opts.Config.DashboardCache.MaxEntries,
 			MaxConcurrentFills: opts.Config.DashboardCache.MaxConcurrentFills,
 		}),
-		stop: make(chan struct{}),
+		queries: newQueryTracker(opts.SQLite, trackerInstance(), opts.Config.Query.PersistActiveAfter),
+		stop:    make(chan struct{}),
+	}
+
+	if opts.Config.Ingest.OTLP.Enabled {
+		s.otlpBatcher = ingest.NewBatcher(ingest.BatcherOptions{
+			MaxBatchRows:   opts.Config.Ingest.OTLP.MaxBatchRows,
+			FlushInterval:  opts.Config.Ingest.OTLP.FlushInterval,
+			MaxPendingRows: opts.Config.Ingest.OTLP.MaxPendingRows,
+			Insert: func(ctx context.Context, sourceID models.SourceID, data []byte) error {
+				return s.datasources.IngestLogs(ctx, sourceID, data, opts.Config.Ingest.WaitForAsyncInsert)
+			},
+		})
 	}
 
 	// Register all application routes.
 	s.setupRoutes()
 	s.startBackgroundCleanup()
+	s.startQueryTrackerMaintenance()
 
 	return s
 }
@@ -189,8 +247,16 @@ func (s *Server) setupRoutes() {
 	// Swagger documentation route
 	s.app.Get("/swagger/*", swagger.HandlerDefault)
 
-	// Metrics endpoint
-	s.app.Get("/metrics", metrics.MetricsHandler())
+	// Prometheus metrics, optionally behind metrics.token.
+	if s.config.Metrics.Enabled {
+		for _, class := range []QueryClass{QueryClassPreview, QueryClassExport, QueryClassTail} {
+			metrics.RegisterActiveQueries(string(class), func() int { return s.queries.ActiveCount(class) })
+		}
+		s.app.Get("/metrics", s.requireMetricsToken, metrics.MetricsHandler())
+	}
+
+	// SCIM 2.0 provisioning for identity providers (opt-in via [scim]).
+	s.setupSCIMRoutes()
 
 	api := s.app.Group("/api/v1")
 
@@ -199,7 +265,7 @@ func (s *Server) setupRoutes() {
 	var authLimiter, queryLimiter fiber.Handler
 	if s.config.RateLimit.Enabled {
 		authLimiter = authRateLimitMiddleware(s.config.RateLimit.AuthPerIPPerMinute, s.config.RateLimit.AuthGlobalPerMinute)
-		queryLimiter = queryRateLimitMiddleware(s.config.RateLimit.QueryPerUserPerMinute)
+		queryLimiter = queryRateLimitMiddleware(s.config.RateLimit)
 	}
 	// withAuthLimit / withQueryLimit prepend the relevant limiter to a route's
 	// handler chain when limiting is enabled, and are no-ops otherwise. The
@@ -250,6 +316,15 @@ func (s *Server) setupRoutes() {
 	api.Post("/me/tokens", s.requireAuth, s.requireTokenScope(models.TokenScopeTokensWrite), s.handleCreateAPIToken)
 	api.Delete("/me/tokens/:tokenID", s.requireAuth, s.requireTokenScope(models.TokenScopeTokensWrite), s.handleDeleteAPIToken)
 
+	// Log ingestion. Opt-in via [ingest] and [ingest.otlp]; source access is
+	// checked in the handlers against the ingest_logs team permission.
+	if s.config.Ingest.Enabled {
+		api.Post("/ingest/:sourceID", s.requireAuth, s.requireTokenScope(models.TokenScopeLogsWrite), s.handleIngestLogs)
+	}
+	if s.otlpBatcher != nil {
+		api.Post("/otlp/:sourceID/v1/logs", s.requireAuth, s.requireTokenScope(models.TokenScopeLogsWrite), s.handleOTLPLogs)
+	}
+
 	// --- User Listing (for team admins to add members) ---
 	// This endpoint is accessible to team admins (users who are admin of at least one team)
 	// or global admins, to allow them to select users when adding members to their teams.
@@ -283,15 +358,38 @@ func (s *Server) setupRoutes() {
 	admin.Get("/teams", s.requireTokenScope(models.TokenScopeTeamsRead), s.handleListTeams)
 	admin.Post("/teams", s.requireTokenScope(models.TokenScopeTeamsWrite), s.handleCreateTeam)
 	admin.Delete("/teams/:teamID", s.requireTokenScope(models.TokenScopeTeamsWrite), s.requireTeamNotManaged, s.handleDeleteTeam)
+	admin.Get("/teams/:teamID/sources/:sourceID/policy", s.requireTokenScope(models.TokenScopeTeamsRead), s.handleGetTeamSourcePolicy)
+	admin.Put("/teams/:teamID/sources/:sourceID/policy", s.requireTokenScope(models.TokenScopeTeamsWrite), s.handleUpdateTeamSourcePolicy)
 
 	// Global Source Management
 	admin.Get("/sources", s.requireTokenScope(models.TokenScopeSourcesRead), s.handleListSources) // Admin endpoint for listing all sources
 	admin.Post("/sources", s.requireTokenScope(models.TokenScopeSourcesWrite), s.handleCreateSource)
 	admin.Post("/sources/validate", s.requireTokenScope(models.TokenScopeSourcesWrite), s.handleValidateSourceConnection)
+	admin.Post("/sources/export", s.requireTokenScope(models.TokenScopeSourcesWrite), s.handleExportSources)
+	admin.Post("/sources/import", s.requireTokenScope(models.TokenScopeSourcesWrite), s.handleImportSources)
+	admin.Post("/sources/discover/databases", s.requireTokenScope(models.TokenScopeSourcesWrite), s.handleDiscoverDatabases)
+	admin.Post("/sources/discover/tables", s.requireTokenScope(models.TokenScopeSourcesWrite), s.handleDiscoverTables)
+	admin.Post("/sources/discover/preview", s.requireTokenScope(models.TokenScopeSourcesWrite), s.handlePreviewSourceTable)
 	admin.Put("/sources/:sourceID", s.requireTokenScope(models.TokenScopeSourcesWrite), s.requireSourceNotManaged, s.handleUpdateSource)
+	admin.Put("/sources/:sourceID/masked-columns", s.requireTokenScope(models.TokenScopeSourcesWrite), s.requireSourceNotManaged, s.handleUpdateSourceMaskedColumns)
+	admin.Put("/sources/:sourceID/ttl", s.requireTokenScope(models.TokenScopeSourcesWrite), s.requireSourceNotManaged, s.handleUpdateSourceTTL)
+	admin.Get("/sources/:sourceID/partitions", s.requireTokenScope(models.TokenScopeSourcesRead), s.handleListSourcePartitions)
+	admin.Post("/sources/:sourceID/partitions/drop", s.requireTokenScope(models.TokenScopeSourcesWrite), s.handleDropSourcePartitions)
 	admin.Delete("/sources/:sourceID", s.requireTokenScope(models.TokenScopeSourcesWrite), s.requireSourceNotManaged, s.handleDeleteSource)
 	admin.Get("/sources/:sourceID/stats", s.requireTokenScope(models.TokenScopeSourcesRead), s.handleGetSourceStats)
 	admin.Get("/sources/:sourceID/activity", s.requireTokenScope(models.TokenScopeSourcesRead), s.handleGetSourceActivity) // Admin-only recent activity
+	admin.Get("/sources/:sourceID/rollup", s.requireTokenScope(models.TokenScopeSourcesRead), s.handleGetSourceRollup)
+	admin.Put("/sources/:sourceID/rollup", s.requireTokenScope(models.TokenScopeSourcesWrite), s.handleUpdateSourceRollup)
+	admin.Delete("/sources/:sourceID/rollup", s.requireTokenScope(models.TokenScopeSourcesWrite), s.handleDeleteSourceRollup)
+
+	// Storage growth and compression history from daily snapshots.
+	admin.Get("/sources/:sourceID/stats/history", s.requireTokenScope(models.TokenScopeSourcesRead), s.handleGetSourceStatsHistory)
+	// Recent results of the background source health probes.
+	admin.Get("/sources/:sourceID/health/history", s.requireTokenScope(models.TokenScopeSourcesRead), s.handleGetSourceHealthHistory)
+	// Unreachable and error-rate alerts raised about the source itself.
+	admin.Get("/sources/:sourceID/alerts/history", s.requireTokenScope(models.TokenScopeSourcesRead), s.handleGetSourceAlertHistory)
+	// Column changes recorded by the schema drift scheduler.
+	admin.Get("/sources/:sourceID/schema/history", s.requireTokenScope(models.TokenScopeSourcesRead), s.handleGetSchemaHistory)
 
 	// Recent query activity (admin recent-activity view over query_history).
 	admin.Get("/query-activity", s.requireTokenScope(models.TokenScopeLogsRead), s.handleAdminQueryActivity)
@@ -299,6 +397,19 @@ func (s *Server) setupRoutes() {
 	// Authoritative all-time usage analytics over the non-pruned query_stats_daily rollup.
 	admin.Get("/query-stats", s.requireTokenScope(models.TokenScopeLogsRead), s.handleAdminQueryStats)
 
+	// Usage and slow-query report over recent query history.
+	admin.Get("/query-usage", s.requireTokenScope(models.TokenScopeLogsRead), s.handleAdminQueryUsage)
+
+	// Queries in flight across all users, and killing any of them.
+	admin.Get("/active-queries", s.requireTokenScope(models.TokenScopeLogsRead), s.handleListActiveQueries)
+	admin.Post("/active-queries/:queryID/kill", s.requireTokenScope(models.TokenScopeSettingsWrite), s.handleKillActiveQuery)
+
+	// Audit trail of sensitive operations (source, membership, alert changes and raw SQL runs).
+	admin.Get("/audit-events", s.requireTokenScope(models.TokenScopeAuditRead), s.handleListAuditEvents)
+
+	// Online snapshot of the metadata database (SQLite only).
+	admin.Post("/backup", s.requireTokenScope(models.TokenScopeSettingsWrite), s.handleBackupDatabase)
+
 	// Provisioning Export
 	admin.Get("/provisioning/export", s.requireTokenScope(models.TokenScopeSettingsRead), s.handleExportProvisioning)
 
@@ -311,22 +422,34 @@ func (s *Server) setupRoutes() {
 	admin.Post("/settings/test-email", s.requireTokenScope(models.TokenScopeSettingsWrite), s.requireAlertsEnabled, s.handleTestEmail)
 	admin.Post("/settings/test-webhook", s.requireTokenScope(models.TokenScopeSettingsWrite), s.requireAlertsEnabled, s.handleTestWebhook)
 
+	// Outbound webhooks for lifecycle events (sources, alerts, team membership).
+	admin.Get("/webhooks", s.requireTokenScope(models.TokenScopeSettingsRead), s.handleListWebhooks)
+	admin.Post("/webhooks", s.requireTokenScope(models.TokenScopeSettingsWrite), s.handleCreateWebhook)
+	admin.Get("/webhooks/:webhookID", s.requireTokenScope(models.TokenScopeSettingsRead), s.handleGetWebhook)
+	admin.Put("/webhooks/:webhookID", s.requireTokenScope(models.TokenScopeSettingsWrite), s.handleUpdateWebhook)
+	admin.Delete("/webhooks/:webhookID", s.requireTokenScope(models.TokenScopeSettingsWrite), s.handleDeleteWebhook)
+	admin.Post("/webhooks/:webhookID/test", s.requireTokenScope(models.TokenScopeSettingsWrite), s.handleTestWebhookEndpoint)
+
 	// --- Team Routes (Access controlled by team membership) ---
 	// Regular users can view teams they belong to, team admins can manage membership and linked sources
 
 	// Team details and members (requires team membership)
 	api.Get("/teams/:teamID", s.requireAuth, s.requireTokenScope(models.TokenScopeTeamsRead), s.requireTeamMember, s.handleGetTeam)
+	api.Get("/teams/:teamID/usage", s.requireAuth, s.requireTokenScope(models.TokenScopeTeamsRead), s.requireTeamMember, s.handleGetTeamUsage)
 
 	// Team member management (requires team admin or global admin)
 	teamMembers := api.Group("/teams/:teamID/members", s.requireAuth, s.requireTeamMember)
 	teamMembers.Get("/", s.requireTokenScope(models.TokenScopeTeamsRead), s.handleListTeamMembers) // Any team member can view
 	// Team admins can add/remove members even on managed teams (day-to-day operations)
-	teamMembers.Post("/", s.requireTokenScope(models.TokenScopeTeamsWrite), s.requireTeamAdminOrGlobalAdmin, s.handleAddTeamMember)
-	teamMembers.Delete("/:userID", s.requireTokenScope(models.TokenScopeTeamsWrite), s.requireTeamAdminOrGlobalAdmin, s.handleRemoveTeamMember)
+	teamMembers.Post("/", s.requireTokenScope(models.TokenScopeTeamsWrite), s.requireTeamPermission(models.TeamPermissionManageMembers), s.handleAddTeamMember)
+	teamMembers.Delete("/:userID", s.requireTokenScope(models.TokenScopeTeamsWrite), s.requireTeamPermission(models.TeamPermissionManageMembers), s.handleRemoveTeamMember)
 
 	// Team settings — managed guard only on structural changes (rename/description)
 	api.Put("/teams/:teamID", s.requireAuth, s.requireTokenScope(models.TokenScopeTeamsWrite), s.requireTeamNotManaged, s.requireTeamAdminOrGlobalAdmin, s.handleUpdateTeam)
 
+	// Team-wide query history (team admin or global admin)
+	api.Get("/teams/:teamID/query-history", s.requireAuth, s.requireTokenScope(models.TokenScopeLogsRead), s.requireTeamAdminOrGlobalAdmin, s.handleListTeamQueryHistory)
+
 	// Collections (cross-team curation lists). Each user gets an auto-created
 	// personal collection on first GET /api/v1/collections. Other collections
 	// are invite-only with two roles: owner (full control) and member (read).
@@ -349,7 +472,8 @@ func (s *Server) setupRoutes() {
 
 	// Saved Queries (cross-team, source-scoped). Visibility: any user with source
 	// access via any team. Edit/delete: creator + global admin (legacy queries
-	// without created_by are global-admin-only).
+	// without created_by are global-admin-only). Mutations also need a team role
+	// on the source that can manage saved queries (viewers are read-only).
 	savedQueries := api.Group("/saved-queries", s.requireAuth)
 	savedQueries.Get("/", s.requireTokenScope(models.TokenScopeSavedQueriesRead), s.handleListSavedQueries)
 	savedQueries.Post("/", s.requireTokenScope(models.TokenScopeSavedQueriesWrite), s.handleCreateSavedQuery)
@@ -357,14 +481,24 @@ func (s *Server) setupRoutes() {
 	savedQueries.Put("/:queryID", s.requireTokenScope(models.TokenScopeSavedQueriesWrite), s.handleUpdateSavedQuery)
 	savedQueries.Delete("/:queryID", s.requireTokenScope(models.TokenScopeSavedQueriesWrite), s.handleDeleteSavedQuery)
 	savedQueries.Get("/:queryID/resolve", s.requireTokenScope(models.TokenScopeSavedQueriesRead), s.handleResolveSavedQuery)
+	savedQueries.Get("/:queryID/choices", s.requireTokenScope(models.TokenScopeSavedQueriesRead), s.requireSourceQuota(s.savedQuerySource), s.handleGetSavedQueryChoices)
 
 	// Team Source Management (linking/unlinking)
 	teamSources := api.Group("/teams/:teamID/sources", s.requireAuth, s.requireTeamMember)
 	teamSources.Get("/", s.requireTokenScope(models.TokenScopeSourcesRead), s.handleListTeamSources)
 
 	// Only team admins can link/unlink sources
-	teamSources.Post("/", s.requireTokenScope(models.TokenScopeTeamsWrite), s.requireTeamAdminOrGlobalAdmin, s.handleLinkSourceToTeam)
-	teamSources.Delete("/:sourceID", s.requireTokenScope(models.TokenScopeTeamsWrite), s.requireTeamAdminOrGlobalAdmin, s.handleUnlinkSourceFromTeam)
+	teamSources.Post("/", s.requireTokenScope(models.TokenScopeTeamsWrite), s.requireTeamPermission(models.TeamPermissionManageSources), s.handleLinkSourceToTeam)
+	teamSources.Delete("/:sourceID", s.requireTokenScope(models.TokenScopeTeamsWrite), s.requireTeamPermission(models.TeamPermissionManageSources), s.handleUnlinkSourceFromTeam)
+
+	// Federated queries run one LogchefQL query across several of the team's
+	// sources; the handler checks the team's access to each of them.
+	teamFederated := api.Group("/teams/:teamID/federated", s.requireAuth, s.requireTeamMember)
+	teamFederated.Post("/logchefql/query", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.requireTeamQuota, s.handleFederatedLogchefQLQuery)...)
+
+	// Trace lookups search every team source with a trace correlation.
+	teamTraces := api.Group("/teams/:teamID/traces", s.requireAuth, s.requireTeamMember)
+	teamTraces.Get("/:traceID/logs", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.requireTeamQuota, s.handleGetTraceLogs)...)
 
 	// --- Team Source Operations (requires team membership) ---
 	// These endpoints allow team members to interact with a specific source linked to their team
@@ -376,42 +510,80 @@ func (s *Server) setupRoutes() {
 
 	// Query and explore logs. The heavy query/exploration endpoints are
 	// rate-limited per authenticated user (queryLimiter runs after the group's
-	// requireAuth, so the user context is available).
-	teamSourceOps.Post("/logs/query", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.handleQueryLogs)...)
-	teamSourceOps.Get("/logs/tail", s.requireTokenScope(models.TokenScopeLogsRead), s.handleTailLogs)
-	teamSourceOps.Post("/logs/export", s.requireTokenScope(models.TokenScopeLogsRead), s.handleExportLogs)
+	// requireAuth, so the user context is available). Query execution also
+	// counts toward the team's daily quota, and every endpoint that runs a
+	// query is refused once the team has used it up (requireTeamQuota).
+	// Endpoints that can't apply the team's row filter refuse links that have
+	// one (rejectRowFiltered), and those that can't mask columns refuse users
+	// with masked ones (rejectMasked).
+	teamSourceOps.Post("/logs/query", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.requireTeamQuota, s.handleQueryLogs)...)
+	teamSourceOps.Get("/logs/tail", s.requireTokenScope(models.TokenScopeLogsRead), s.rejectRowFiltered, s.rejectMasked, s.requireTeamQuota, s.handleTailLogs)
+	teamSourceOps.Post("/logs/export", s.requireTokenScope(models.TokenScopeLogsRead), s.rejectRowFiltered, s.rejectMasked, s.requireTeamQuota, s.handleExportLogs)
 	teamSourceOps.Post("/logs/query/:queryID/cancel", s.requireTokenScope(models.TokenScopeLogsRead), s.handleCancelQuery)
-	teamSourceOps.Post("/exports", s.requireTokenScope(models.TokenScopeLogsRead), s.handleCreateExportJob)
-	teamSourceOps.Get("/exports/:exportID", s.requireTokenScope(models.TokenScopeLogsRead), s.handleGetExportJob)
-	teamSourceOps.Get("/exports/:exportID/download", s.requireTokenScope(models.TokenScopeLogsRead), s.handleDownloadExportJob)
+	teamSourceOps.Post("/exports", s.requireTokenScope(models.TokenScopeLogsRead), s.rejectRowFiltered, s.rejectMasked, s.requireTeamQuota, s.handleCreateExportJob)
+	teamSourceOps.Get("/exports/:exportID", s.requireTokenScope(models.TokenScopeLogsRead), s.rejectRowFiltered, s.rejectMasked, s.handleGetExportJob)
+	teamSourceOps.Get("/exports/:exportID/download", s.requireTokenScope(models.TokenScopeLogsRead), s.rejectRowFiltered, s.rejectMasked, s.handleDownloadExportJob)
 	teamSourceOps.Get("/schema", s.requireTokenScope(models.TokenScopeSourcesRead), s.handleGetSourceSchema)
-	teamSourceOps.Post("/logs/histogram", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.handleGetHistogram)...)
-	teamSourceOps.Post("/logs/context", s.requireTokenScope(models.TokenScopeLogsRead), s.handleGetLogContext)
+	teamSourceOps.Get("/schema/history", s.requireTokenScope(models.TokenScopeSourcesRead), s.handleGetSchemaHistory)
+	teamSourceOps.Put("/extraction-rules", s.requireTokenScope(models.TokenScopeSourcesWrite), s.requireTeamPermission(models.TeamPermissionManageSources), s.requireSourceNotManaged, s.handleUpdateSourceExtractionRules)
+	teamSourceOps.Put("/trace-correlation", s.requireTokenScope(models.TokenScopeSourcesWrite), s.requireTeamPermission(models.TeamPermissionManageSources), s.requireSourceNotManaged, s.handleUpdateSourceTraceCorrelation)
+	teamSourceOps.Post("/logs/histogram", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.requireTeamQuota, s.handleGetHistogram)...)
+	teamSourceOps.Get("/trends", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.rejectRowFiltered, s.rejectMasked, s.requireTeamQuota, s.handleGetSourceTrends)...)
+	teamSourceOps.Post("/logs/context", s.requireTokenScope(models.TokenScopeLogsRead), s.rejectRowFiltered, s.rejectMasked, s.requireTeamQuota, s.handleGetLogContext)
+	teamSourceOps.Post("/logs/lint", s.requireTokenScope(models.TokenScopeLogsRead), s.handleLintQuery)
+	teamSourceOps.Post("/logs/format", s.requireTokenScope(models.TokenScopeLogsRead), s.handleFormatQuery)
+	teamSourceOps.Post("/logs/variables", s.requireTokenScope(models.TokenScopeLogsRead), s.handleParseVariables)
 	teamSourceOps.Post("/generate-sql", s.requireTokenScope(models.TokenScopeLogsRead), s.handleGenerateAISQL)
 	teamSourceOps.Post("/query-shares", s.requireTokenScope(models.TokenScopeQuerySharesWrite), s.handleCreateQueryShare)
 
+	// Column layouts: a personal preset overrides the team default.
+	teamSourceOps.Get("/column-presets", s.requireTokenScope(models.TokenScopeSourcesRead), s.handleGetColumnPresets)
+	teamSourceOps.Put("/column-presets/personal", s.requireTokenScope(models.TokenScopeProfileWrite), s.handleSaveUserColumnPreset)
+	teamSourceOps.Delete("/column-presets/personal", s.requireTokenScope(models.TokenScopeProfileWrite), s.handleDeleteUserColumnPreset)
+	teamSourceOps.Put("/column-presets/team", s.requireTokenScope(models.TokenScopeTeamsWrite), s.requireTeamPermission(models.TeamPermissionManageColumnPresets), s.handleSaveTeamColumnPreset)
+	teamSourceOps.Delete("/column-presets/team", s.requireTokenScope(models.TokenScopeTeamsWrite), s.requireTeamPermission(models.TeamPermissionManageColumnPresets), s.handleDeleteTeamColumnPreset)
+
 	// LogchefQL endpoints - query language parsing and translation
-	teamSourceOps.Post("/logchefql/translate", s.requireTokenScope(models.TokenScopeLogsRead), s.handleLogchefQLTranslate) // Translate LogchefQL to SQL
-	teamSourceOps.Post("/logchefql/validate", s.requireTokenScope(models.TokenScopeLogsRead), s.handleLogchefQLValidate)   // Validate LogchefQL syntax
-	teamSourceOps.Post("/logchefql/query", s.requireTokenScope(models.TokenScopeLogsRead), s.handleLogchefQLQuery)         // Execute LogchefQL query directly
+	teamSourceOps.Post("/logchefql/translate", s.requireTokenScope(models.TokenScopeLogsRead), s.handleLogchefQLTranslate)             // Translate LogchefQL to SQL
+	teamSourceOps.Post("/logchefql/validate", s.requireTokenScope(models.TokenScopeLogsRead), s.handleLogchefQLValidate)               // Validate LogchefQL syntax
+	teamSourceOps.Post("/logchefql/query", s.requireTokenScope(models.TokenScopeLogsRead), s.requireTeamQuota, s.handleLogchefQLQuery) // Execute LogchefQL query directly
 
 	// Field value exploration for sidebar
-	teamSourceOps.Get("/fields/values", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.handleGetAllFieldValues)...)         // Get all LowCardinality field values
-	teamSourceOps.Get("/fields/:fieldName/values", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.handleGetFieldValues)...) // Get values for a specific field
+	teamSourceOps.Get("/fields/values", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.requireTeamQuota, s.handleGetAllFieldValues)...)                                            // Get all LowCardinality field values
+	teamSourceOps.Get("/fields/:fieldName/values", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.requireTeamQuota, s.handleGetFieldValues)...)                                    // Get values for a specific field
+	teamSourceOps.Get("/fields/:fieldName/stats", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.rejectRowFiltered, s.rejectMasked, s.requireTeamQuota, s.handleGetFieldStats)...) // Numeric/datetime field statistics
+	teamSourceOps.Get("/fields/json", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.rejectRowFiltered, s.rejectMasked, s.requireTeamQuota, s.handleGetJSONFields)...)             // JSON keys sampled from String columns
+
+	// Log analysis
+	teamSourceOps.Get("/patterns", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.rejectRowFiltered, s.rejectMasked, s.requireTeamQuota, s.handleGetLogPatterns)...)             // Group messages into templates with counts
+	teamSourceOps.Get("/anomalies/volume", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.rejectRowFiltered, s.rejectMasked, s.requireTeamQuota, s.handleGetVolumeAnomalies)...) // Volume/error rate vs. an earlier window
+	teamSourceOps.Get("/compare", withQueryLimit(s.requireTokenScope(models.TokenScopeLogsRead), s.rejectRowFiltered, s.rejectMasked, s.requireTeamQuota, s.handleCompareRanges)...)               // Field value diffs between two time ranges
 
 	// Alerts (cross-team, source-scoped). Visibility: any user with source
 	// access via any team. Edit/delete/resolve: creator + global admin
-	// (legacy alerts without created_by are global-admin-only).
+	// (legacy alerts without created_by are global-admin-only). Mutations also
+	// need a team role on the source that can manage alerts.
 	alertRoutes := api.Group("/alerts", s.requireAuth, s.requireAlertsEnabled)
 	alertRoutes.Get("/", s.requireTokenScope(models.TokenScopeAlertsRead), s.handleListAlerts)
 	alertRoutes.Post("/", s.requireTokenScope(models.TokenScopeAlertsWrite), s.handleCreateAlert)
-	alertRoutes.Post("/test", s.requireTokenScope(models.TokenScopeAlertsWrite), s.handleTestAlertQuery)
+	alertRoutes.Post("/test", s.requireTokenScope(models.TokenScopeAlertsWrite), s.requireSourceQuota(alertTestSource), s.handleTestAlertQuery)
 	alertRoutes.Get("/:alertID", s.requireTokenScope(models.TokenScopeAlertsRead), s.handleGetAlert)
-	alertRoutes.Put("/:alertID", s.requireTokenScope(models.TokenScopeAlertsWrite), s.handleUpdateAlert)
-	alertRoutes.Delete("/:alertID", s.requireTokenScope(models.TokenScopeAlertsWrite), s.handleDeleteAlert)
+	alertRoutes.Put("/:alertID", s.requireTokenScope(models.TokenScopeAlertsWrite), s.requireAlertNotManaged, s.handleUpdateAlert)
+	alertRoutes.Delete("/:alertID", s.requireTokenScope(models.TokenScopeAlertsWrite), s.requireAlertNotManaged, s.handleDeleteAlert)
 	alertRoutes.Get("/:alertID/history", s.requireTokenScope(models.TokenScopeAlertsRead), s.handleListAlertHistory)
+	alertRoutes.Get("/:alertID/state", s.requireTokenScope(models.TokenScopeAlertsRead), s.handleGetAlertState)
 	alertRoutes.Post("/:alertID/resolve", s.requireTokenScope(models.TokenScopeAlertsWrite), s.handleResolveAlert)
 
+	// Silences hold back notifications for an alert, a source or a team, once
+	// or as a recurring maintenance window. Visibility follows what they cover;
+	// mutations need a team role that can manage alerts there, and are audited.
+	silenceRoutes := api.Group("/silences", s.requireAuth, s.requireAlertsEnabled)
+	silenceRoutes.Get("/", s.requireTokenScope(models.TokenScopeAlertsRead), s.handleListAlertSilences)
+	silenceRoutes.Post("/", s.requireTokenScope(models.TokenScopeAlertsWrite), s.handleCreateAlertSilence)
+	silenceRoutes.Get("/:silenceID", s.requireTokenScope(models.TokenScopeAlertsRead), s.handleGetAlertSilence)
+	silenceRoutes.Put("/:silenceID", s.requireTokenScope(models.TokenScopeAlertsWrite), s.handleUpdateAlertSilence)
+	silenceRoutes.Delete("/:silenceID", s.requireTokenScope(models.TokenScopeAlertsWrite), s.handleDeleteAlertSilence)
+
 	// Dashboards (saved grids of visualization panels). Visibility: any
 	// authenticated user can list/view. Edit/delete: creator + global admin
 	// (dashboards whose author was deleted are global-admin-only). Panel data is
@@ -424,6 +596,72 @@ func (s *Server) setupRoutes() {
 	dashboardRoutes.Put("/:dashboardID", s.requireTokenScope(models.TokenScopeDashboardsWrite), s.handleUpdateDashboard)
 	dashboardRoutes.Delete("/:dashboardID", s.requireTokenScope(models.TokenScopeDashboardsWrite), s.handleDeleteDashboard)
 
+	// --- Notebooks (team-scoped analysis documents) ---
+	// Any team member can read, export and run cells; authoring (including
+	// stored snapshots) requires the notebook team permission, and per-notebook edit rules are checked in the
+	// handlers (creator, team admin/editor, or global admin).
+	notebookRoutes := api.Group("/teams/:teamID/notebooks", s.requireAuth, s.requireTeamMember)
+	notebookRoutes.Get("/", s.requireTokenScope(models.TokenScopeNotebooksRead), s.handleListNotebooks)
+	notebookRoutes.Post("/", s.requireTokenScope(models.TokenScopeNotebooksWrite), s.requireTeamPermission(models.TeamPermissionManageNotebooks), s.handleCreateNotebook)
+	notebookRoutes.Get("/:notebookID", s.requireTokenScope(models.TokenScopeNotebooksRead), s.handleGetNotebook)
+	notebookRoutes.Put("/:notebookID", s.requireTokenScope(models.TokenScopeNotebooksWrite), s.handleUpdateNotebook)
+	notebookRoutes.Delete("/:notebookID", s.requireTokenScope(models.TokenScopeNotebooksWrite), s.handleDeleteNotebook)
+	notebookRoutes.Get("/:notebookID/export", s.requireTokenScope(models.TokenScopeNotebooksRead), s.handleExportNotebook)
+	notebookRoutes.Get("/:notebookID/snapshots", s.requireTokenScope(models.TokenScopeNotebooksRead), s.handleListNotebookSnapshots)
+	notebookRoutes.Post("/:notebookID/snapshots", s.requireTokenScope(models.TokenScopeNotebooksWrite), s.requireTeamPermission(models.TeamPermissionManageNotebooks), s.handleCreateNotebookSnapshot)
+	notebookRoutes.Get("/:notebookID/snapshots/:snapshotID/download", s.requireTokenScope(models.TokenScopeNotebooksRead), s.handleDownloadNotebookSnapshot)
+	notebookRoutes.Delete("/:notebookID/snapshots/:snapshotID", s.requireTokenScope(models.TokenScopeNotebooksWrite), s.requireTeamPermission(models.TeamPermissionManageNotebooks), s.handleDeleteNotebookSnapshot)
+	notebookRoutes.Post("/:notebookID/cells/:cellID/run", s.requireTokenScope(models.TokenScopeNotebooksRead), s.requireTokenScope(models.TokenScopeLogsRead), s.requireTeamQuota, s.handleRunNotebookCell)
+
+	// Annotations mark deploys and incidents on a team's timelines and come back
+	// with overlapping histograms. Any team member can read them; creating needs
+	// the annotate team permission, and editing is limited to the creator, team
+	// admins/editors and global admins (checked in core).
+	annotationRoutes := api.Group("/teams/:teamID/annotations", s.requireAuth, s.requireTeamMember)
+	annotationRoutes.Get("/", s.requireTokenScope(models.TokenScopeAnnotationsRead), s.handleListAnnotations)
+	annotationRoutes.Post("/", s.requireTokenScope(models.TokenScopeAnnotationsWrite), s.requireTeamPermission(models.TeamPermissionAnnotate), s.handleCreateAnnotation)
+	annotationRoutes.Get("/:annotationID", s.requireTokenScope(models.TokenScopeAnnotationsRead), s.handleGetAnnotation)
+	annotationRoutes.Put("/:annotationID", s.requireTokenScope(models.TokenScopeAnnotationsWrite), s.handleUpdateAnnotation)
+	annotationRoutes.Delete("/:annotationID", s.requireTokenScope(models.TokenScopeAnnotationsWrite), s.handleDeleteAnnotation)
+
+	// Log bookmarks pin individual rows from a team's sources. A bookmark is
+	// personal unless shared with the team; visibility and edit rights are
+	// checked in core.
+	bookmarkRoutes := api.Group("/teams/:teamID/bookmarks", s.requireAuth, s.requireTeamMember)
+	bookmarkRoutes.Get("/", s.requireTokenScope(models.TokenScopeBookmarksRead), s.handleListLogBookmarks)
+	bookmarkRoutes.Post("/", s.requireTokenScope(models.TokenScopeBookmarksWrite), s.handleCreateLogBookmark)
+	bookmarkRoutes.Get("/:bookmarkID", s.requireTokenScope(models.TokenScopeBookmarksRead), s.handleGetLogBookmark)
+	bookmarkRoutes.Put("/:bookmarkID", s.requireTokenScope(models.TokenScopeBookmarksWrite), s.handleUpdateLogBookmark)
+	bookmarkRoutes.Delete("/:bookmarkID", s.requireTokenScope(models.TokenScopeBookmarksWrite), s.handleDeleteLogBookmark)
+
+	// Query snippets are a team's library of reusable LogchefQL filters for the
+	// editor's autocomplete. Managed like saved queries: any team member can
+	// read them; creating needs the saved-query team permission, and editing is
+	// limited to the creator, team admins/editors and global admins (checked in
+	// core).
+	snippetRoutes := api.Group("/teams/:teamID/snippets", s.requireAuth, s.requireTeamMember)
+	snippetRoutes.Get("/", s.requireTokenScope(models.TokenScopeSavedQueriesRead), s.handleListQuerySnippets)
+	snippetRoutes.Post("/", s.requireTokenScope(models.TokenScopeSavedQueriesWrite), s.requireTeamPermission(models.TeamPermissionManageSavedQueries), s.handleCreateQuerySnippet)
+	snippetRoutes.Get("/:snippetID", s.requireTokenScope(models.TokenScopeSavedQueriesRead), s.handleGetQuerySnippet)
+	snippetRoutes.Put("/:snippetID", s.requireTokenScope(models.TokenScopeSavedQueriesWrite), s.handleUpdateQuerySnippet)
+	snippetRoutes.Delete("/:snippetID", s.requireTokenScope(models.TokenScopeSavedQueriesWrite), s.handleDeleteQuerySnippet)
+
+	// SLOs are team-scoped and managed like alerts, which link to them for
+	// burn-rate alerting.
+	sloRoutes := api.Group("/teams/:teamID/slos", s.requireAuth, s.requireTeamMember)
+	sloRoutes.Get("/", s.requireTokenScope(models.TokenScopeAlertsRead), s.handleListSLOs)
+	sloRoutes.Post("/", s.requireTokenScope(models.TokenScopeAlertsWrite), s.requireTeamPermission(models.TeamPermissionManageAlerts), s.handleCreateSLO)
+	sloRoutes.Get("/:sloID", s.requireTokenScope(models.TokenScopeAlertsRead), s.handleGetSLO)
+	sloRoutes.Put("/:sloID", s.requireTokenScope(models.TokenScopeAlertsWrite), s.handleUpdateSLO)
+	sloRoutes.Delete("/:sloID", s.requireTokenScope(models.TokenScopeAlertsWrite), s.handleDeleteSLO)
+	sloRoutes.Get("/:sloID/history", s.requireTokenScope(models.TokenScopeAlertsRead), s.handleListSLOHistory)
+
+	// A team's alerts as a YAML/JSON document, for review in Git and for
+	// moving them between instances.
+	teamAlertRoutes := api.Group("/teams/:teamID/alerts", s.requireAuth, s.requireAlertsEnabled, s.requireTeamMember)
+	teamAlertRoutes.Get("/export", s.requireTokenScope(models.TokenScopeAlertsRead), s.handleExportTeamAlerts)
+	teamAlertRoutes.Post("/import", s.requireTokenScope(models.TokenScopeAlertsWrite), s.requireTeamPermission(models.TeamPermissionManageAlerts), s.handleImportTeamAlerts)
+
 	// --- Static Asset and SPA Handling ---
 	s.app.Use("/api/*", s.notFoundHandler) // Catch-all for API 404s
 	s.app.Use("/assets", filesystem.New(filesystem.Config{
@@ -447,6 +685,39 @@ func (s *Server) Start() error {
 	return s.app.Listen(addr)
 }
 
+// drainPollInterval is how often Drain checks for queries still running.
+const drainPollInterval = 100 * time.Millisecond
+
+// Drain stops admitting queries and waits for running ones to finish until ctx
+// ends, then cancels the rest and kills them on ClickHouse. Live tails never
+// finish on their own, so they are cancelled right away. The health endpoint
+// reports draining from here on, so load balancers stop routing to this
+// instance. Call it before Shutdown.
+func (s *Server) Drain(ctx context.Context) {
+	s.queries.StartDraining()
+	s.log.Info("draining queries", "active", s.queries.Len())
+
+	killCtx := context.WithoutCancel(ctx)
+	for _, query := range s.queries.CancelAll(QueryClassTail) {
+		s.killClickHouseQuery(killCtx, query.SourceID, query.ID)
+	}
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for s.queries.Len() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			for _, query := range s.queries.CancelAll() {
+				s.log.Warn("cancelled query still running at shutdown", "query_id", query.ID, "source_id", query.SourceID, "user_id", query.UserID, "started_at", query.StartTime)
+				s.killClickHouseQuery(killCtx, query.SourceID, query.ID)
+			}
+			return
+		}
+	}
+	s.log.Info("queries drained")
+}
+
 // Shutdown gracefully shuts down the Fiber server within the given context timeout.
 // It also stops background maintenance loops (e.g. the expired-session/export-job
 // sweeper) and waits for them to exit before returning.
@@ -457,5 +728,10 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.dashCache.Close()
 	}
 	s.wg.Wait()
-	return s.app.ShutdownWithContext(ctx)
+	err := s.app.ShutdownWithContext(ctx)
+	// After in-flight requests: they wait on the batches being flushed.
+	if s.otlpBatcher != nil {
+		s.otlpBatcher.Close()
+	}
+	return err
 }
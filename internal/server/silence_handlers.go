@@ -0,0 +1,152 @@
+package server
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/pkg/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sendSilenceError maps core silence errors onto responses.
+func (s *Server) sendSilenceError(c *fiber.Ctx, err error, action string) error {
+	switch {
+	case errors.Is(err, core.ErrInvalidSilence):
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	case errors.Is(err, core.ErrSilenceForbidden):
+		return SendErrorWithType(c, fiber.StatusForbidden, err.Error(), models.AuthorizationErrorType)
+	case errors.Is(err, core.ErrSilenceNotFound):
+		return SendErrorWithType(c, fiber.StatusNotFound, "Silence not found", models.NotFoundErrorType)
+	default:
+		s.log.Error("failed to "+action+" alert silence", "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to "+action+" silence", models.GeneralErrorType)
+	}
+}
+
+// silenceAuditDetails records what a silence covers and for how long.
+func silenceAuditDetails(silence *models.AlertSilence) map[string]any {
+	details := map[string]any{
+		"reason":    silence.Reason,
+		"starts_at": silence.StartsAt,
+		"recurring": silence.Recurrence != nil,
+	}
+	if silence.EndsAt != nil {
+		details["ends_at"] = *silence.EndsAt
+	}
+	switch {
+	case silence.AlertID != nil:
+		details["alert_id"] = *silence.AlertID
+	case silence.SourceID != nil:
+		details["source_id"] = *silence.SourceID
+	case silence.TeamID != nil:
+		details["team_id"] = *silence.TeamID
+	}
+	return details
+}
+
+// handleListAlertSilences lists the silences the caller can see. Optional
+// filters: ?alert_id, ?source_id, ?team_id and ?active=true.
+func (s *Server) handleListAlertSilences(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	filter := core.AlertSilenceFilter{ActiveOnly: c.QueryBool("active")}
+	if raw := c.Query("alert_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid alert_id parameter", models.ValidationErrorType)
+		}
+		filter.AlertID = models.AlertID(id)
+	}
+	if raw := c.Query("source_id"); raw != "" {
+		id, err := core.ParseSourceID(raw)
+		if err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source_id parameter", models.ValidationErrorType)
+		}
+		filter.SourceID = id
+	}
+	if raw := c.Query("team_id"); raw != "" {
+		id, err := core.ParseTeamID(raw)
+		if err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team_id parameter", models.ValidationErrorType)
+		}
+		filter.TeamID = id
+	}
+
+	silences, err := core.ListAlertSilences(c.Context(), s.sqlite, user, filter)
+	if err != nil {
+		return s.sendSilenceError(c, err, "list")
+	}
+	return SendSuccess(c, fiber.StatusOK, silences)
+}
+
+// handleCreateAlertSilence silences an alert, a source or a team. The caller
+// needs a team role that can manage alerts on whatever is silenced.
+func (s *Server) handleCreateAlertSilence(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	var req models.CreateAlertSilenceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	silence, err := core.CreateAlertSilence(c.Context(), s.sqlite, s.log, user, &req)
+	if err != nil {
+		return s.sendSilenceError(c, err, "create")
+	}
+	s.recordAudit(c, models.AuditActionSilenceCreate, models.AuditResourceSilence, auditID(silence.ID), silence.TeamID, silenceAuditDetails(silence))
+	return SendSuccess(c, fiber.StatusCreated, silence)
+}
+
+// handleGetAlertSilence returns one silence.
+func (s *Server) handleGetAlertSilence(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	id, err := parsePositiveIntParam(c, "silenceID")
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+
+	silence, err := core.GetAlertSilence(c.Context(), s.sqlite, user, models.SilenceID(id))
+	if err != nil {
+		return s.sendSilenceError(c, err, "load")
+	}
+	return SendSuccess(c, fiber.StatusOK, silence)
+}
+
+// handleUpdateAlertSilence replaces a silence's reason and schedule.
+func (s *Server) handleUpdateAlertSilence(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	id, err := parsePositiveIntParam(c, "silenceID")
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+
+	var req models.UpdateAlertSilenceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	silence, err := core.UpdateAlertSilence(c.Context(), s.sqlite, s.log, user, models.SilenceID(id), &req)
+	if err != nil {
+		return s.sendSilenceError(c, err, "update")
+	}
+	s.recordAudit(c, models.AuditActionSilenceUpdate, models.AuditResourceSilence, auditID(silence.ID), silence.TeamID, silenceAuditDetails(silence))
+	return SendSuccess(c, fiber.StatusOK, silence)
+}
+
+// handleDeleteAlertSilence lifts a silence.
+func (s *Server) handleDeleteAlertSilence(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	id, err := parsePositiveIntParam(c, "silenceID")
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+
+	silence, err := core.DeleteAlertSilence(c.Context(), s.sqlite, s.log, user, models.SilenceID(id))
+	if err != nil {
+		return s.sendSilenceError(c, err, "delete")
+	}
+	s.recordAudit(c, models.AuditActionSilenceDelete, models.AuditResourceSilence, auditID(silence.ID), silence.TeamID, silenceAuditDetails(silence))
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Silence deleted"})
+}
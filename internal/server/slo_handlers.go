@@ -0,0 +1,192 @@
+package server
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/pkg/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// loadTeamSLO resolves the :teamID/:sloID route params to an SLO of that team.
+// When ok is false the error response has already been written and err should
+// be returned as-is.
+func (s *Server) loadTeamSLO(c *fiber.Ctx) (*models.SLO, bool, error) {
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return nil, false, SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	id, err := parsePositiveIntParam(c, "sloID")
+	if err != nil {
+		return nil, false, SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+	slo, err := core.GetSLO(c.Context(), s.sqlite, s.log, teamID, models.SLOID(id))
+	if err != nil {
+		if errors.Is(err, core.ErrSLONotFound) {
+			return nil, false, SendErrorWithType(c, fiber.StatusNotFound, "SLO not found", models.NotFoundErrorType)
+		}
+		return nil, false, SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to load SLO", models.GeneralErrorType)
+	}
+	return slo, true, nil
+}
+
+// handleListSLOs lists a team's SLOs with their current status.
+func (s *Server) handleListSLOs(c *fiber.Ctx) error {
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	slos, err := core.ListSLOs(c.Context(), s.sqlite, teamID)
+	if err != nil {
+		s.log.Error("failed to list slos", "team_id", teamID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to list SLOs", models.GeneralErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, slos)
+}
+
+// handleCreateSLO creates an SLO in the team, owned by the caller. The route
+// requires the alert-management team permission.
+func (s *Server) handleCreateSLO(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+
+	var req models.CreateSLORequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+	// SLIs run as their own queries over the whole source, like alerts.
+	if ok, err := s.checkWholeSourceAccess(c, user, req.SourceID); !ok {
+		return err
+	}
+	allowRawSQL, ok, err := s.alertRawSQLAllowed(c, user, req.SourceID)
+	if !ok {
+		return err
+	}
+
+	slo, err := core.CreateSLO(c.Context(), s.sqlite, s.datasources, s.log, user, teamID, allowRawSQL, &req)
+	if err != nil {
+		if errors.Is(err, core.ErrRawSQLDisabled) {
+			return SendErrorWithType(c, fiber.StatusForbidden, "Raw SQL is disabled for this team; use LogchefQL instead", models.AuthorizationErrorType)
+		}
+		if errors.Is(err, core.ErrInvalidSLO) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+		s.log.Error("failed to create slo", "team_id", teamID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to create SLO", models.GeneralErrorType)
+	}
+	canEdit := true
+	slo.CanEdit = &canEdit
+	return SendSuccess(c, fiber.StatusCreated, slo)
+}
+
+// handleGetSLO returns an SLO with its current status.
+func (s *Server) handleGetSLO(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	slo, ok, err := s.loadTeamSLO(c)
+	if !ok {
+		return err
+	}
+	canEdit, err := core.UserCanEditSLO(c.Context(), s.sqlite, slo, user)
+	if err != nil {
+		s.log.Error("failed to check slo edit access", "slo_id", slo.ID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to load SLO", models.GeneralErrorType)
+	}
+	slo.CanEdit = &canEdit
+	return SendSuccess(c, fiber.StatusOK, slo)
+}
+
+// handleUpdateSLO replaces an SLO's definition. Editing is allowed for the
+// creator, team admins/editors and global admins.
+func (s *Server) handleUpdateSLO(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	slo, ok, err := s.loadTeamSLO(c)
+	if !ok {
+		return err
+	}
+
+	var req models.UpdateSLORequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+	if ok, err := s.checkWholeSourceAccess(c, user, slo.SourceID); !ok {
+		return err
+	}
+	allowRawSQL, ok, err := s.alertRawSQLAllowed(c, user, slo.SourceID)
+	if !ok {
+		return err
+	}
+
+	updated, updateErr := core.UpdateSLO(c.Context(), s.sqlite, s.datasources, s.log, slo.TeamID, slo.ID, user, allowRawSQL, &req)
+	if updateErr != nil {
+		switch {
+		case errors.Is(updateErr, core.ErrRawSQLDisabled):
+			return SendErrorWithType(c, fiber.StatusForbidden, "Raw SQL is disabled for this team; use LogchefQL instead", models.AuthorizationErrorType)
+		case errors.Is(updateErr, core.ErrInvalidSLO):
+			return SendErrorWithType(c, fiber.StatusBadRequest, updateErr.Error(), models.ValidationErrorType)
+		case errors.Is(updateErr, core.ErrSLOForbidden):
+			return SendErrorWithType(c, fiber.StatusForbidden, updateErr.Error(), models.AuthorizationErrorType)
+		case errors.Is(updateErr, core.ErrSLONotFound):
+			return SendErrorWithType(c, fiber.StatusNotFound, "SLO not found", models.NotFoundErrorType)
+		default:
+			s.log.Error("failed to update slo", "slo_id", slo.ID, "error", updateErr)
+			return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to update SLO", models.GeneralErrorType)
+		}
+	}
+	canEdit := true
+	updated.CanEdit = &canEdit
+	return SendSuccess(c, fiber.StatusOK, updated)
+}
+
+// handleDeleteSLO removes an SLO, its history and its burn-rate alerts (same
+// edit rule as update).
+func (s *Server) handleDeleteSLO(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+	slo, ok, err := s.loadTeamSLO(c)
+	if !ok {
+		return err
+	}
+	canEdit, err := core.UserCanEditSLO(c.Context(), s.sqlite, slo, user)
+	if err != nil {
+		s.log.Error("failed to check slo edit access", "slo_id", slo.ID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to verify edit access", models.GeneralErrorType)
+	}
+	if !canEdit {
+		return SendErrorWithType(c, fiber.StatusForbidden, core.ErrSLOForbidden.Error(), models.AuthorizationErrorType)
+	}
+
+	if err := core.DeleteSLO(c.Context(), s.sqlite, s.log, slo.ID); err != nil {
+		if errors.Is(err, core.ErrSLONotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "SLO not found", models.NotFoundErrorType)
+		}
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to delete SLO", models.GeneralErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "SLO deleted"})
+}
+
+// handleListSLOHistory returns an SLO's recent evaluations, newest first. The
+// optional limit query parameter is capped at the configured history limit.
+func (s *Server) handleListSLOHistory(c *fiber.Ctx) error {
+	slo, ok, err := s.loadTeamSLO(c)
+	if !ok {
+		return err
+	}
+
+	limit := models.DefaultSLOHistoryLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = min(parsed, max(s.config.SLOs.HistoryLimit, models.DefaultSLOHistoryLimit))
+		}
+	}
+
+	history, err := core.ListSLOHistory(c.Context(), s.sqlite, slo.ID, limit)
+	if err != nil {
+		s.log.Error("failed to list slo history", "slo_id", slo.ID, "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to load SLO history", models.GeneralErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, history)
+}
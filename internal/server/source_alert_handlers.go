@@ -0,0 +1,36 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/sourcealerts"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// handleGetSourceAlertHistory handles GET /admin/sources/:sourceID/alerts/history.
+// It returns the source degradation alerts raised for the source, newest
+// first: each time it became unreachable or its queries started failing, and
+// each recovery. Query parameters:
+//   - limit: how many events to return (optional, defaults to 50, at most 500)
+func (s *Server) handleGetSourceAlertHistory(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID", models.ValidationErrorType)
+	}
+
+	events, err := s.sourceAlerts.History(c.Context(), sourceID, c.QueryInt("limit", sourcealerts.DefaultHistoryLimit))
+	if err != nil {
+		switch {
+		case errors.Is(err, sourcealerts.ErrInvalidRequest):
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		case errors.Is(err, models.ErrNotFound):
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		s.log.Error("failed to get source alert history", "error", err, "source_id", sourceID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get source alert history", models.GeneralErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, events)
+}
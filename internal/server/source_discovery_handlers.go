@@ -0,0 +1,69 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// handleDiscoverDatabases lists the databases on a connection that isn't a
+// source yet.
+// URL: POST /api/v1/admin/sources/discover/databases
+// Requires: Admin privileges
+func (s *Server) handleDiscoverDatabases(c *fiber.Ctx) error {
+	var req models.DiscoveryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	databases, err := core.DiscoverDatabases(c.Context(), s.datasources, &req)
+	if err != nil {
+		return s.sendDiscoveryError(c, &req, err)
+	}
+	return SendSuccess(c, fiber.StatusOK, databases)
+}
+
+// handleDiscoverTables lists the tables in the connection's database.
+// URL: POST /api/v1/admin/sources/discover/tables
+// Requires: Admin privileges
+func (s *Server) handleDiscoverTables(c *fiber.Ctx) error {
+	var req models.DiscoveryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	tables, err := core.DiscoverTables(c.Context(), s.datasources, &req)
+	if err != nil {
+		return s.sendDiscoveryError(c, &req, err)
+	}
+	return SendSuccess(c, fiber.StatusOK, tables)
+}
+
+// handlePreviewSourceTable samples the connection's table and suggests its
+// timestamp and severity columns.
+// URL: POST /api/v1/admin/sources/discover/preview
+// Requires: Admin privileges
+func (s *Server) handlePreviewSourceTable(c *fiber.Ctx) error {
+	var req models.DiscoveryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	preview, err := core.PreviewSourceTable(c.Context(), s.datasources, &req)
+	if err != nil {
+		return s.sendDiscoveryError(c, &req, err)
+	}
+	return SendSuccess(c, fiber.StatusOK, preview)
+}
+
+func (s *Server) sendDiscoveryError(c *fiber.Ctx, req *models.DiscoveryRequest, err error) error {
+	if validationErr, ok := err.(*core.ValidationError); ok {
+		return SendErrorWithType(c, fiber.StatusBadRequest, validationErr.Error(), models.ValidationErrorType)
+	}
+	if errors.Is(err, datasource.ErrOperationNotSupported) {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Table discovery is not supported for this source type", models.ValidationErrorType)
+	}
+	s.log.Error("source discovery failed", "error", err, "source_type", req.SourceType)
+	return SendErrorWithType(c, fiber.StatusInternalServerError, "Error discovering tables: "+err.Error(), models.ExternalServiceErrorType)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/mr-karan/logchef/internal/core"
 	"github.com/mr-karan/logchef/internal/datasource"
@@ -14,10 +15,36 @@ import (
 
 // --- Admin Source Management Handlers ---
 
-// handleListSources is an admin-only endpoint to list all configured sources.
-// URL: GET /api/v1/admin/sources
+// sourceListSpec is the sort and filter allow-list of the source list.
+var sourceListSpec = listSpec[*models.SourceResponse]{
+	sorts: map[string]func(a, b *models.SourceResponse) int{
+		"name":        func(a, b *models.SourceResponse) int { return compareFold(a.Name, b.Name) },
+		"source_type": func(a, b *models.SourceResponse) int { return compareFold(string(a.SourceType), string(b.SourceType)) },
+		"created_at":  func(a, b *models.SourceResponse) int { return compareTime(a.CreatedAt, b.CreatedAt) },
+		"updated_at":  func(a, b *models.SourceResponse) int { return compareTime(a.UpdatedAt, b.UpdatedAt) },
+	},
+	filters: map[string]func(src *models.SourceResponse, value string) bool{
+		"source_type":  func(src *models.SourceResponse, v string) bool { return string(src.SourceType) == v },
+		"is_connected": func(src *models.SourceResponse, v string) bool { return matchBool(src.IsConnected, v) },
+		"q":            func(src *models.SourceResponse, v string) bool { return containsFold(v, src.Name, src.Description) },
+	},
+	defaultSort: "created_at",
+	defaultDesc: true,
+}
+
+// handleListSources is an admin-only endpoint to list the configured sources,
+// paginated.
+// URL: GET /api/v1/admin/sources[?tag=env=prod&tag=region&limit=&offset=&sort=&source_type=&is_connected=&q=]
 // Requires: Admin privileges
 func (s *Server) handleListSources(c *fiber.Ctx) error {
+	selector, err := sourceTagSelector(c)
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+	q, err := parseListQuery(c, sourceListSpec)
+	if err != nil {
+		return sendListError(c, err)
+	}
 	sources, err := core.ListSources(c.Context(), s.sqlite, s.datasources)
 	if err != nil {
 		s.log.Error("failed to list sources", "error", err)
@@ -25,12 +52,25 @@ func (s *Server) handleListSources(c *fiber.Ctx) error {
 	}
 
 	// Convert sources to response objects to avoid exposing sensitive information.
-	sourceResponses := make([]*models.SourceResponse, len(sources))
-	for i, src := range sources {
-		sourceResponses[i] = src.ToResponse()
+	sourceResponses := make([]*models.SourceResponse, 0, len(sources))
+	for _, src := range sources {
+		if selector.Matches(src.Tags) {
+			sourceResponses = append(sourceResponses, src.ToResponse())
+		}
 	}
 
-	return SendSuccess(c, fiber.StatusOK, sourceResponses)
+	page, pagination := applyListQuery(sourceResponses, q, sourceListSpec)
+	return SendListCacheable(c, page, pagination, latestUpdate(sourceResponses, func(s *models.SourceResponse) time.Time { return s.UpdatedAt }))
+}
+
+// sourceTagSelector parses the repeatable ?tag= filter of the source lists.
+// Each value is "key=value" or a bare "key"; sources must match all of them.
+func sourceTagSelector(c *fiber.Ctx) (models.TagSelector, error) {
+	var terms []string
+	for _, value := range c.Context().QueryArgs().PeekMulti("tag") {
+		terms = append(terms, string(value))
+	}
+	return models.ParseTagSelector(terms...)
 }
 
 // handleCreateSource creates a new data source.
@@ -66,6 +106,10 @@ func (s *Server) handleCreateSource(c *fiber.Ctx) error {
 		}
 		s.log.Info("source.create", attrs...)
 	}
+	s.recordAudit(c, models.AuditActionSourceCreate, models.AuditResourceSource, auditID(createdSource.ID), nil, map[string]any{
+		"name":        createdSource.Name,
+		"source_type": createdSource.SourceType,
+	})
 	return SendSuccess(c, fiber.StatusCreated, createdSource.ToResponse())
 }
 
@@ -91,6 +135,7 @@ func (s *Server) handleDeleteSource(c *fiber.Ctx) error {
 		return SendError(c, fiber.StatusInternalServerError, "Error deleting source: "+err.Error())
 	}
 
+	s.recordAudit(c, models.AuditActionSourceDelete, models.AuditResourceSource, auditID(sourceID), nil, nil)
 	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Source deleted successfully"})
 }
 
@@ -130,6 +175,118 @@ func (s *Server) handleUpdateSource(c *fiber.Ctx) error {
 	return SendSuccess(c, fiber.StatusOK, updatedSource.ToResponse())
 }
 
+// handleUpdateSourceExtractionRules replaces a source's field extraction
+// rules. Each rule becomes a virtual column in the source schema that
+// LogchefQL can filter and select on.
+// URL: PUT /api/v1/teams/:teamID/sources/:sourceID/extraction-rules
+// Requires: manage_sources on the team
+func (s *Server) handleUpdateSourceExtractionRules(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+
+	var req models.UpdateSourceExtractionRulesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+	rules := req.Rules
+	if rules == nil {
+		rules = models.ExtractionRules{}
+	}
+
+	updatedSource, err := core.UpdateSource(c.Context(), s.datasources, sourceID, &models.UpdateSourceRequest{ExtractionRules: &rules})
+	if err != nil {
+		if errors.Is(err, core.ErrSourceNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		if validationErr, ok := err.(*core.ValidationError); ok {
+			return SendErrorWithType(c, fiber.StatusBadRequest, validationErr.Error(), models.ValidationErrorType)
+		}
+		s.log.Error("failed to update source extraction rules", "error", err, "source_id", sourceID)
+		return SendError(c, fiber.StatusInternalServerError, "Error updating extraction rules: "+err.Error())
+	}
+
+	s.recordAudit(c, models.AuditActionSourceExtraction, models.AuditResourceSource, auditID(sourceID), nil, map[string]any{
+		"rules": len(updatedSource.ExtractionRules),
+	})
+	return SendSuccess(c, fiber.StatusOK, updatedSource.ToResponse())
+}
+
+// handleUpdateSourceTraceCorrelation sets or clears a source's trace
+// correlation: its trace/span ID columns and the URL template linking them
+// to a tracing backend. An object without trace_id_field clears it.
+// URL: PUT /api/v1/teams/:teamID/sources/:sourceID/trace-correlation
+// Requires: manage_sources on the team
+func (s *Server) handleUpdateSourceTraceCorrelation(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+
+	var req models.TraceCorrelation
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	updatedSource, err := core.UpdateSource(c.Context(), s.datasources, sourceID, &models.UpdateSourceRequest{TraceCorrelation: &req})
+	if err != nil {
+		if errors.Is(err, core.ErrSourceNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		if validationErr, ok := err.(*core.ValidationError); ok {
+			return SendErrorWithType(c, fiber.StatusBadRequest, validationErr.Error(), models.ValidationErrorType)
+		}
+		s.log.Error("failed to update source trace correlation", "error", err, "source_id", sourceID)
+		return SendError(c, fiber.StatusInternalServerError, "Error updating trace correlation: "+err.Error())
+	}
+
+	s.recordAudit(c, models.AuditActionSourceTracing, models.AuditResourceSource, auditID(sourceID), nil, map[string]any{
+		"enabled": updatedSource.TraceCorrelation != nil,
+	})
+	return SendSuccess(c, fiber.StatusOK, updatedSource.ToResponse())
+}
+
+// handleUpdateSourceTTL changes a source table's retention with ALTER TABLE
+// ... MODIFY TTL. With dry_run it only returns the statement, so the change
+// can be reviewed before it rewrites existing parts.
+// URL: PUT /api/v1/admin/sources/:sourceID/ttl
+// Requires: Admin privileges
+func (s *Server) handleUpdateSourceTTL(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+
+	var req models.UpdateSourceTTLRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	change, err := core.UpdateSourceTTL(c.Context(), s.datasources, sourceID, &req)
+	if err != nil {
+		if errors.Is(err, core.ErrSourceNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		if errors.Is(err, datasource.ErrOperationNotSupported) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "TTL changes are not supported for this source type", models.ValidationErrorType)
+		}
+		if validationErr, ok := err.(*core.ValidationError); ok {
+			return SendErrorWithType(c, fiber.StatusBadRequest, validationErr.Error(), models.ValidationErrorType)
+		}
+		s.log.Error("failed to update source ttl", "error", err, "source_id", sourceID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Error updating source TTL: "+err.Error(), models.ExternalServiceErrorType)
+	}
+
+	if change.Applied {
+		s.recordAudit(c, models.AuditActionSourceTTLUpdate, models.AuditResourceSource, auditID(sourceID), nil, map[string]any{
+			"ttl_days":  change.TTLDays,
+			"statement": change.Statement,
+		})
+	}
+	return SendSuccess(c, fiber.StatusOK, change)
+}
+
 // handleValidateSourceConnection validates datasource connection details provided in the request body.
 // URL: POST /api/v1/admin/sources/validate
 // Requires: Admin privileges
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// handleListSourcePartitions lists the active partitions of a source's table
+// with their row counts and sizes, newest first.
+// URL: GET /api/v1/admin/sources/:sourceID/partitions
+// Requires: Admin privileges
+func (s *Server) handleListSourcePartitions(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+
+	partitions, err := core.ListSourcePartitions(c.Context(), s.datasources, sourceID)
+	if err != nil {
+		return s.sendPartitionError(c, sourceID, "listing", err)
+	}
+	if partitions == nil {
+		partitions = []models.TablePartition{}
+	}
+	return SendSuccess(c, fiber.StatusOK, partitions)
+}
+
+// handleDropSourcePartitions drops partitions of an auto-created source table
+// by partition_id. With dry_run it only returns the ALTER statements and the
+// rows and bytes they would free.
+// URL: POST /api/v1/admin/sources/:sourceID/partitions/drop
+// Requires: Admin privileges
+func (s *Server) handleDropSourcePartitions(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+
+	var req models.DropPartitionsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	result, err := core.DropSourcePartitions(c.Context(), s.datasources, sourceID, &req)
+	if err != nil {
+		return s.sendPartitionError(c, sourceID, "dropping", err)
+	}
+
+	if result.Applied {
+		s.recordAudit(c, models.AuditActionSourcePartDrop, models.AuditResourceSource, auditID(sourceID), nil, map[string]any{
+			"partition_ids": req.PartitionIDs,
+			"rows":          result.Rows,
+			"bytes_on_disk": result.BytesOnDisk,
+		})
+	}
+	return SendSuccess(c, fiber.StatusOK, result)
+}
+
+func (s *Server) sendPartitionError(c *fiber.Ctx, sourceID models.SourceID, op string, err error) error {
+	if errors.Is(err, core.ErrSourceNotFound) {
+		return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+	}
+	if errors.Is(err, datasource.ErrOperationNotSupported) {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Partitions are not supported for this source type", models.ValidationErrorType)
+	}
+	var validationErr *core.ValidationError
+	if errors.As(err, &validationErr) {
+		return SendErrorWithType(c, fiber.StatusBadRequest, validationErr.Error(), models.ValidationErrorType)
+	}
+	s.log.Error(op+" source partitions failed", "error", err, "source_id", sourceID)
+	return SendErrorWithType(c, fiber.StatusInternalServerError, "Error "+op+" partitions: "+err.Error(), models.ExternalServiceErrorType)
+}
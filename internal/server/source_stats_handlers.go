@@ -0,0 +1,36 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/sourcestats"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// handleGetSourceStatsHistory handles GET /admin/sources/:sourceID/stats/history.
+// It returns the source's daily storage snapshots and their change over the
+// range. Query parameters:
+//   - days: how many days back to go (optional, defaults to 30, at most
+//     source_stats.retention_days)
+func (s *Server) handleGetSourceStatsHistory(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID", models.ValidationErrorType)
+	}
+
+	trend, err := s.sourceStats.Trend(c.Context(), sourceID, c.QueryInt("days", sourcestats.DefaultTrendDays))
+	if err != nil {
+		switch {
+		case errors.Is(err, sourcestats.ErrInvalidRequest):
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		case errors.Is(err, models.ErrNotFound):
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		s.log.Error("failed to get source stats history", "error", err, "source_id", sourceID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get source stats history", models.GeneralErrorType)
+	}
+	return SendSuccess(c, fiber.StatusOK, trend)
+}
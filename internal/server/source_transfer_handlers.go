@@ -0,0 +1,110 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/sourcetransfer"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+type exportSourcesRequest struct {
+	// Format is "yaml" (default) or "json".
+	Format string `json:"format"`
+	// Passphrase, when set, seals credentials into the export instead of
+	// dropping them.
+	Passphrase string `json:"passphrase"`
+}
+
+type importSourcesRequest struct {
+	// Document is the YAML or JSON text of an export.
+	Document   string `json:"document"`
+	Passphrase string `json:"passphrase"`
+	DryRun     bool   `json:"dry_run"`
+}
+
+// handleExportSources downloads every source definition as a YAML or JSON
+// document.
+// URL: POST /api/v1/admin/sources/export
+// Requires: Admin privileges
+func (s *Server) handleExportSources(c *fiber.Ctx) error {
+	var req exportSourcesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+	if req.Format == "" {
+		req.Format = sourcetransfer.FormatYAML
+	}
+	if req.Format != sourcetransfer.FormatYAML && req.Format != sourcetransfer.FormatJSON {
+		return SendErrorWithType(c, fiber.StatusBadRequest, `format must be "yaml" or "json"`, models.ValidationErrorType)
+	}
+
+	doc, err := sourcetransfer.Export(c.Context(), s.sqlite, req.Passphrase)
+	if err != nil {
+		if errors.Is(err, sourcetransfer.ErrShortPassphrase) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+		s.log.Error("failed to export sources", "error", err)
+		return SendError(c, fiber.StatusInternalServerError, "Failed to export sources")
+	}
+	body, err := sourcetransfer.Marshal(doc, req.Format)
+	if err != nil {
+		s.log.Error("failed to encode source export", "error", err)
+		return SendError(c, fiber.StatusInternalServerError, "Failed to export sources")
+	}
+
+	s.recordAudit(c, models.AuditActionSourceExport, models.AuditResourceSource, "", nil, map[string]any{
+		"sources":   len(doc.Sources),
+		"encrypted": doc.Encryption != nil,
+	})
+
+	contentType := "application/yaml"
+	if req.Format == sourcetransfer.FormatJSON {
+		contentType = fiber.MIMEApplicationJSON
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="logchef-sources-%s.%s"`, doc.ExportedAt.Format("20060102-150405"), req.Format))
+	return c.Status(fiber.StatusOK).Send(body)
+}
+
+// handleImportSources creates the sources of an exported document.
+// URL: POST /api/v1/admin/sources/import
+// Requires: Admin privileges
+func (s *Server) handleImportSources(c *fiber.Ctx) error {
+	var req importSourcesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	doc, err := sourcetransfer.Parse([]byte(req.Document))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+	result, err := sourcetransfer.Import(c.Context(), s.sqlite, s.datasources, doc, sourcetransfer.ImportOptions{
+		Passphrase: req.Passphrase,
+		DryRun:     req.DryRun,
+	})
+	if err != nil {
+		if errors.Is(err, sourcetransfer.ErrInvalidDocument) || errors.Is(err, sourcetransfer.ErrWrongPassphrase) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+		}
+		s.log.Error("failed to import sources", "error", err)
+		return SendError(c, fiber.StatusInternalServerError, "Failed to import sources")
+	}
+
+	for _, imported := range result.Sources {
+		if imported.Status != sourcetransfer.StatusCreated {
+			continue
+		}
+		s.recordAudit(c, models.AuditActionSourceCreate, models.AuditResourceSource, auditID(imported.SourceID), nil, map[string]any{
+			"name": imported.Name,
+			"via":  "import",
+		})
+	}
+	if !req.DryRun {
+		s.log.Info("source.import", "created", result.Created, "exists", result.Exists, "failed", result.Failed)
+	}
+	return SendSuccess(c, fiber.StatusOK, result)
+}
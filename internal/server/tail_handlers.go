@@ -77,7 +77,7 @@ func (s *Server) handleTailLogs(c *fiber.Ctx) error { //nolint:gocyclo // reques
 
 	// Admission control: class tail, per-user and global caps → 429.
 	streamCtx, cancel := context.WithCancel(c.Context())
-	queryID, err := queryTracker.StartQuery(
+	queryID, err := s.queries.StartQuery(
 		QueryClassTail,
 		user.ID,
 		sourceID,
@@ -85,15 +85,12 @@ func (s *Server) handleTailLogs(c *fiber.Ctx) error { //nolint:gocyclo // reques
 		nativeQuery,
 		cancel,
 		s.config.Tail.MaxPerUser,
+		0,
 		s.config.Tail.MaxGlobal,
 	)
 	if err != nil {
 		cancel()
-		var admissionErr *QueryAdmissionError
-		if errors.As(err, &admissionErr) {
-			return SendErrorWithType(c, fiber.StatusTooManyRequests, admissionErr.Message, models.ValidationErrorType)
-		}
-		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to track tail query", models.GeneralErrorType)
+		return sendAdmissionError(c, err, "Failed to track tail query")
 	}
 
 	c.Status(fiber.StatusOK)
@@ -116,7 +113,7 @@ func (s *Server) handleTailLogs(c *fiber.Ctx) error { //nolint:gocyclo // reques
 
 	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
 		defer cancel()
-		defer queryTracker.RemoveQuery(queryID)
+		defer s.queries.RemoveQuery(queryID)
 
 		writeFrame := func(event string, data []byte) bool {
 			if event != "" {
@@ -1,7 +1,9 @@
 package server
 
 import (
+	"cmp"
 	"errors"
+	"time"
 
 	"github.com/mr-karan/logchef/internal/core"
 	"github.com/mr-karan/logchef/pkg/models"
@@ -11,16 +13,37 @@ import (
 
 // --- Team Management Handlers ---
 
-// handleListTeams lists all teams.
-// URL: GET /api/v1/admin/teams
+// teamListSpec is the sort and filter allow-list of the team list.
+var teamListSpec = listSpec[*models.Team]{
+	sorts: map[string]func(a, b *models.Team) int{
+		"name":         func(a, b *models.Team) int { return compareFold(a.Name, b.Name) },
+		"member_count": func(a, b *models.Team) int { return cmp.Compare(a.MemberCount, b.MemberCount) },
+		"created_at":   func(a, b *models.Team) int { return compareTime(a.CreatedAt, b.CreatedAt) },
+		"updated_at":   func(a, b *models.Team) int { return compareTime(a.UpdatedAt, b.UpdatedAt) },
+	},
+	filters: map[string]func(t *models.Team, value string) bool{
+		"managed": func(t *models.Team, v string) bool { return matchBool(t.Managed, v) },
+		"q":       func(t *models.Team, v string) bool { return containsFold(v, t.Name, t.Description) },
+	},
+	defaultSort: "created_at",
+	defaultDesc: true,
+}
+
+// handleListTeams lists the teams, paginated.
+// URL: GET /api/v1/admin/teams[?limit=&offset=&sort=&managed=&q=]
 // Requires: Admin privileges (requireAdmin middleware)
 func (s *Server) handleListTeams(c *fiber.Ctx) error {
+	q, err := parseListQuery(c, teamListSpec)
+	if err != nil {
+		return sendListError(c, err)
+	}
 	teams, err := core.ListTeams(c.Context(), s.sqlite)
 	if err != nil {
 		s.log.Error("failed to list teams", "error", err)
 		return SendError(c, fiber.StatusInternalServerError, "Error listing teams")
 	}
-	return SendSuccess(c, fiber.StatusOK, teams)
+	page, pagination := applyListQuery(teams, q, teamListSpec)
+	return SendListCacheable(c, page, pagination, latestUpdate(teams, func(t *models.Team) time.Time { return t.UpdatedAt }))
 }
 
 // handleGetTeam retrieves details for a specific team.
@@ -41,7 +64,7 @@ func (s *Server) handleGetTeam(c *fiber.Ctx) error {
 		s.log.Error("failed to get team", "error", err, "team_id", teamID)
 		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get team", models.DatabaseErrorType)
 	}
-	return SendSuccess(c, fiber.StatusOK, team)
+	return SendSuccessCacheable(c, team, team.UpdatedAt)
 }
 
 // handleCreateTeam creates a new team.
@@ -84,12 +107,17 @@ func (s *Server) handleUpdateTeam(c *fiber.Ctx) error {
 	}
 
 	var req struct {
-		Name        *string `json:"name"`
-		Description *string `json:"description"`
+		Name          *string `json:"name"`
+		Description   *string `json:"description"`
+		RawSQLEnabled *bool   `json:"raw_sql_enabled"`
 	}
 	if err := c.BodyParser(&req); err != nil {
 		return SendError(c, fiber.StatusBadRequest, "Invalid request body")
 	}
+	// The raw SQL switch is a guard on the team, so its own admins can't lift it.
+	if req.RawSQLEnabled != nil && !isUserAdmin(c) {
+		return SendErrorWithType(c, fiber.StatusForbidden, "Only admins can change raw SQL access", models.AuthorizationErrorType)
+	}
 
 	// Construct update DTO.
 	updateData := models.Team{}
@@ -114,6 +142,12 @@ func (s *Server) handleUpdateTeam(c *fiber.Ctx) error {
 		s.log.Error("failed to update team", "error", err, "team_id", teamID)
 		return SendError(c, fiber.StatusInternalServerError, "Failed to update team")
 	}
+	if req.RawSQLEnabled != nil {
+		if err := core.SetTeamRawSQL(c.Context(), s.sqlite, s.log, teamID, *req.RawSQLEnabled); err != nil {
+			s.log.Error("failed to update team raw sql setting", "error", err, "team_id", teamID)
+			return SendError(c, fiber.StatusInternalServerError, "Failed to update team")
+		}
+	}
 
 	// Fetch and return updated team.
 	updatedTeam, err := core.GetTeam(c.Context(), s.sqlite, teamID)
@@ -199,6 +233,7 @@ func (s *Server) handleAddTeamMember(c *fiber.Ctx) error {
 	if actor, ok := c.Locals("user").(*models.User); ok {
 		s.log.Info("team.member.add", "actor", actor.Email, "team_id", teamID, "member_id", req.UserID, "role", req.Role)
 	}
+	s.recordAudit(c, models.AuditActionTeamMemberAdd, models.AuditResourceTeamMember, auditTeamMemberID(teamID, req.UserID), &teamID, map[string]any{"role": req.Role})
 	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Team member added successfully"})
 }
 
@@ -223,13 +258,14 @@ func (s *Server) handleRemoveTeamMember(c *fiber.Ctx) error {
 		s.log.Error("failed to remove team member", "error", err, "team_id", teamID, "user_id", userID)
 		return SendError(c, fiber.StatusInternalServerError, "Failed to remove team member")
 	}
+	s.recordAudit(c, models.AuditActionTeamMemberRemove, models.AuditResourceTeamMember, auditTeamMemberID(teamID, userID), &teamID, nil)
 	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Team member removed successfully"})
 }
 
 // --- Team Source Handlers ---
 
 // handleListTeamSources lists sources linked to a specific team, including their connection status.
-// URL: GET /api/v1/teams/:teamID/sources
+// URL: GET /api/v1/teams/:teamID/sources[?tag=env=prod]
 // Requires: Team membership (requireTeamMember middleware)
 func (s *Server) handleListTeamSources(c *fiber.Ctx) error {
 	idStr := c.Params("teamID")
@@ -238,12 +274,17 @@ func (s *Server) handleListTeamSources(c *fiber.Ctx) error {
 		return SendError(c, fiber.StatusBadRequest, "Invalid team ID: "+err.Error())
 	}
 
+	selector, err := sourceTagSelector(c)
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+
 	// Get source info, including connection status, linked to the team using the updated core function.
 	sources, err := core.ListTeamSources(c.Context(), s.sqlite, s.datasources, s.log, teamID)
 	if err != nil {
 		if errors.Is(err, core.ErrTeamNotFound) {
 			// Team not found is a valid case, return empty list.
-			return SendSuccess(c, fiber.StatusOK, []*models.SourceResponse{})
+			return SendSuccessCacheable(c, []*models.SourceResponse{}, time.Time{})
 		}
 		s.log.Error("failed to list team sources", "error", err, "team_id", teamID)
 		return SendError(c, fiber.StatusInternalServerError, "Failed to list team sources")
@@ -253,11 +294,11 @@ func (s *Server) handleListTeamSources(c *fiber.Ctx) error {
 	sourceResponses := make([]*models.SourceResponse, 0, len(sources))
 	for _, src := range sources {
 		// Check for nil just in case (core function should prevent this)
-		if src != nil {
+		if src != nil && selector.Matches(src.Tags) {
 			sourceResponses = append(sourceResponses, src.ToResponse())
 		}
 	}
-	return SendSuccess(c, fiber.StatusOK, sourceResponses)
+	return SendSuccessCacheable(c, sourceResponses, latestUpdate(sourceResponses, func(s *models.SourceResponse) time.Time { return s.UpdatedAt }))
 }
 
 // handleGetTeamSource retrieves detailed information for a specific source within a team context.
@@ -298,7 +339,7 @@ func (s *Server) handleGetTeamSource(c *fiber.Ctx) error {
 	}
 
 	// Convert to response object.
-	return SendSuccess(c, fiber.StatusOK, sourceDetails.ToResponse())
+	return SendSuccessCacheable(c, sourceDetails.ToResponse(), sourceDetails.UpdatedAt)
 }
 
 // handleLinkSourceToTeam links an existing source to a team.
@@ -350,6 +391,19 @@ func (s *Server) handleUnlinkSourceFromTeam(c *fiber.Ctx) error {
 		return SendError(c, fiber.StatusBadRequest, "Invalid source ID: "+err.Error())
 	}
 
+	// Relinking a source drops its row filter, so only admins may unlink one
+	// that has a filter.
+	if !isUserAdmin(c) {
+		rowFilter, err := core.GetTeamSourceRowFilter(c.Context(), s.sqlite, teamID, sourceID)
+		if err != nil && !errors.Is(err, core.ErrSourceNotLinked) {
+			s.log.Error("failed to get team source row filter", "error", err, "team_id", teamID, "source_id", sourceID)
+			return SendError(c, fiber.StatusInternalServerError, "Failed to remove team source link")
+		}
+		if rowFilter != "" {
+			return SendErrorWithType(c, fiber.StatusForbidden, "Only an admin can unlink a source with a row filter", models.AuthorizationErrorType)
+		}
+	}
+
 	// Call core function to remove the link.
 	if err := core.RemoveTeamSource(c.Context(), s.sqlite, s.log, teamID, sourceID); err != nil {
 		// Core function likely doesn't error if link doesn't exist, log unexpected errors.
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/template"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// substituteVariables applies a request's template variables, and the
+// template's inline defaults, to sql. An enum the template declares takes its
+// choices from the declaration rather than the request. Enum variables that
+// name a source query are checked against the choices that query returns, so
+// the saved query must be on the same source. sql without variables or
+// placeholders is returned as is.
+func (s *Server) substituteVariables(ctx context.Context, sourceID models.SourceID, sql string, reqVars []models.TemplateVariable) (string, error) {
+	if len(reqVars) == 0 && len(template.ExtractVariableNames(sql)) == 0 {
+		return sql, nil
+	}
+	vars := make([]template.Variable, len(reqVars))
+	for i, v := range reqVars {
+		vars[i] = template.Variable{
+			Name:          v.Name,
+			Type:          template.VariableType(v.Type),
+			Value:         v.Value,
+			AllowedValues: v.AllowedValues,
+			SourceQueryID: v.SourceQueryID,
+		}
+	}
+	vars, err := template.ApplyDeclaredEnums(sql, vars)
+	if err != nil {
+		return "", err
+	}
+	if err := template.ResolveChoices(ctx, vars, s.variableChoiceLoader(sourceID)); err != nil {
+		return "", err
+	}
+	return template.SubstituteVariables(sql, vars)
+}
+
+func (s *Server) variableChoiceLoader(sourceID models.SourceID) template.ChoiceLoader {
+	return func(ctx context.Context, queryID int) ([]string, error) {
+		query, err := core.GetSavedQuery(ctx, s.sqlite, s.log, queryID)
+		if err != nil {
+			return nil, err
+		}
+		if query.SourceID != sourceID {
+			return nil, fmt.Errorf("saved query %d is on another source", queryID)
+		}
+		return core.LoadVariableChoices(ctx, s.datasources, query)
+	}
+}
+
+// ParseVariablesRequest is the body of the variables endpoint.
+type ParseVariablesRequest struct {
+	Query string `json:"query"`
+}
+
+// handleParseVariables lists the template variables a query declares, with
+// their types and inline defaults, so the variable form can be built without
+// parsing the template client-side.
+//
+// POST /api/v1/teams/:teamID/sources/:sourceID/logs/variables
+func (s *Server) handleParseVariables(c *fiber.Ctx) error {
+	var req ParseVariablesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	decls, err := template.ParseVariables(req.Query)
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+	if decls == nil {
+		decls = []template.Declaration{}
+	}
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{"variables": decls})
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// defaultTraceLookback is the range searched for a trace's logs when the
+// request gives none.
+const defaultTraceLookback = 24 * time.Hour
+
+// handleGetTraceLogs fetches the logs of one trace from every team source
+// with a trace correlation, merged by time, along with links to the trace in
+// the sources' tracing backends.
+// URL: GET /api/v1/teams/:teamID/traces/:traceID/logs
+// Query params:
+//   - start_time, end_time: range to search (optional, defaults to the last 24 hours)
+//   - timezone: timezone of the time range (optional, defaults to UTC)
+//   - limit: rows to return across all sources
+func (s *Server) handleGetTraceLogs(c *fiber.Ctx) error {
+	teamID, err := core.ParseTeamID(c.Params("teamID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid team ID format", models.ValidationErrorType)
+	}
+	user, ok := c.Locals("user").(*models.User)
+	if !ok || user == nil {
+		return SendErrorWithType(c, fiber.StatusUnauthorized, "User context not found", models.AuthenticationErrorType)
+	}
+	traceID := c.Params("traceID")
+	if err := core.ValidateTraceID(traceID); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+
+	req := federatedQueryRequest{
+		StartTime: c.Query("start_time", ""),
+		EndTime:   c.Query("end_time", ""),
+		Timezone:  c.Query("timezone", "UTC"),
+		Limit:     c.QueryInt("limit", s.config.Query.DefaultPreviewLimit),
+	}
+	if (req.StartTime == "") != (req.EndTime == "") {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "start_time and end_time must be given together", models.ValidationErrorType)
+	}
+	if req.StartTime == "" {
+		now := time.Now().UTC()
+		req.StartTime = now.Add(-defaultTraceLookback).Format(time.RFC3339)
+		req.EndTime = now.Format(time.RFC3339)
+		req.Timezone = "UTC"
+	}
+	if req.Limit <= 0 || req.Limit > s.config.Query.MaxPreviewLimit {
+		req.Limit = s.config.Query.MaxPreviewLimit
+	}
+	defaultTimeout := s.config.Query.DefaultTimeoutSeconds
+	req.QueryTimeout = &defaultTimeout
+
+	teamSources, err := core.ListTeamSources(c.Context(), s.sqlite, s.datasources, s.log, teamID)
+	if err != nil {
+		if errors.Is(err, core.ErrTeamNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Team not found", models.NotFoundErrorType)
+		}
+		s.log.Error("failed to list team sources", "error", err, "team_id", teamID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to get sources", models.DatabaseErrorType)
+	}
+	sources, omitted := core.TraceSources(teamSources)
+	if len(sources) == 0 {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "No source of this team has trace correlation configured", models.ValidationErrorType)
+	}
+
+	queries := make([]core.FederatedQuery, 0, len(sources))
+	for _, source := range sources {
+		sourceReq := req
+		sourceReq.Query = core.TraceLogchefQL(source, traceID)
+		params, ok, err := s.compileFederatedQuery(c, source, sourceReq)
+		if !ok {
+			return err
+		}
+		params.CostLimits = s.config.Query.Cost.LimitsForTeam(teamID)
+		if params.RowFilter, err = core.GetTeamSourceRowFilter(c.Context(), s.sqlite, teamID, source.ID); err != nil {
+			s.log.Error("failed to get team source row filter", "error", err, "team_id", teamID, "source_id", source.ID)
+			return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to check source access", models.DatabaseErrorType)
+		}
+		if params.MaskedColumns, ok, err = s.sourceMaskedColumnsForUser(c, teamID, source.ID); !ok {
+			return err
+		}
+		queries = append(queries, core.FederatedQuery{Source: source, Params: params})
+	}
+
+	queryCtx, cancel := context.WithCancel(c.Context())
+	defer cancel()
+
+	// Tracked like a federated query: one admission slot, under the first source.
+	queryID, err := s.queries.StartQuery(
+		QueryClassPreview,
+		user.ID,
+		sources[0].ID,
+		teamID,
+		"trace:"+traceID,
+		cancel,
+		s.config.Query.MaxConcurrentPerUser,
+		s.config.Query.MaxConcurrentPerTeam,
+		s.config.Query.MaxConcurrentGlobal,
+	)
+	if err != nil {
+		return sendAdmissionError(c, err, "Failed to track query")
+	}
+	defer s.queries.RemoveQuery(queryID)
+
+	result, err := core.QueryLogsFederated(queryCtx, s.datasources, queries, req.Limit)
+	if err != nil {
+		s.log.Error("failed to fetch trace logs", "error", err, "team_id", teamID, "trace_id", traceID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Query execution failed: "+err.Error(), models.DatabaseErrorType)
+	}
+	if omitted > 0 {
+		result.Warnings = append(result.Warnings, models.QueryWarning{
+			Code:    "TRACE_SOURCES_OMITTED",
+			Message: fmt.Sprintf("%d more sources with trace correlation were not searched; at most %d are", omitted, core.MaxFederatedSources),
+		})
+	}
+
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{
+		"trace_id": traceID,
+		"logs":     result.Logs,
+		"columns":  result.Columns,
+		"stats":    result.Stats,
+		"sources":  result.Sources,
+		"links":    core.TraceLinks(sources, traceID),
+		"query_id": queryID,
+		"warnings": result.Warnings,
+	})
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"errors"
 	"strconv"
+	"time"
 
 	"github.com/mr-karan/logchef/internal/core"
 	"github.com/mr-karan/logchef/pkg/models"
@@ -14,16 +15,39 @@ import (
 
 // --- Admin User Management Handlers ---
 
-// handleListUsers lists all users in the system.
-// URL: GET /api/v1/admin/users
+// userListSpec is the sort and filter allow-list of the user list.
+var userListSpec = listSpec[*models.User]{
+	sorts: map[string]func(a, b *models.User) int{
+		"email":         func(a, b *models.User) int { return compareFold(a.Email, b.Email) },
+		"full_name":     func(a, b *models.User) int { return compareFold(a.FullName, b.FullName) },
+		"role":          func(a, b *models.User) int { return compareFold(string(a.Role), string(b.Role)) },
+		"status":        func(a, b *models.User) int { return compareFold(string(a.Status), string(b.Status)) },
+		"created_at":    func(a, b *models.User) int { return compareTime(a.CreatedAt, b.CreatedAt) },
+		"last_login_at": func(a, b *models.User) int { return compareTimePtr(a.LastLoginAt, b.LastLoginAt) },
+	},
+	filters: map[string]func(u *models.User, value string) bool{
+		"role":   func(u *models.User, v string) bool { return string(u.Role) == v },
+		"status": func(u *models.User, v string) bool { return string(u.Status) == v },
+		"q":      func(u *models.User, v string) bool { return containsFold(v, u.Email, u.FullName) },
+	},
+	defaultSort: "created_at",
+}
+
+// handleListUsers lists the users in the system, paginated.
+// URL: GET /api/v1/admin/users[?limit=&offset=&sort=&role=&status=&q=]
 // Requires: Admin privileges (requireAdmin middleware)
 func (s *Server) handleListUsers(c *fiber.Ctx) error {
+	q, err := parseListQuery(c, userListSpec)
+	if err != nil {
+		return sendListError(c, err)
+	}
 	users, err := core.ListUsers(c.Context(), s.sqlite)
 	if err != nil {
 		s.log.Error("failed to list users", "error", err)
 		return SendError(c, fiber.StatusInternalServerError, "Error listing users")
 	}
-	return SendSuccess(c, fiber.StatusOK, users)
+	page, pagination := applyListQuery(users, q, userListSpec)
+	return SendList(c, page, pagination)
 }
 
 // handleGetUser gets a specific user by ID.
@@ -216,17 +240,25 @@ func (s *Server) handleListCurrentUserTeams(c *fiber.Ctx) error {
 
 	if userTeamDetails == nil {
 		// Return empty list if user has no teams, to be consistent.
-		return SendSuccess(c, fiber.StatusOK, []*models.UserTeamDetails{})
+		return SendSuccessCacheable(c, []*models.UserTeamDetails{}, time.Time{})
 	}
 
-	return SendSuccess(c, fiber.StatusOK, userTeamDetails)
+	return SendSuccessCacheable(c, userTeamDetails, latestUpdate(userTeamDetails, func(t *models.UserTeamDetails) time.Time { return t.UpdatedAt }))
 }
 
 // --- Current User Query History Handlers ---
 
-// handleListQueryHistory returns the authenticated user's recent query history,
-// newest first. Supports ?limit= (default models.QueryHistoryDefaultLimit,
-// capped at models.QueryHistoryMaxLimit).
+// queryHistoryListSpec allows no sorts: history is filtered and paged in SQL,
+// always newest first. See parseQueryHistoryFilter for the filters.
+var queryHistoryListSpec = listSpec[*models.QueryHistory]{
+	defaultLimit: models.QueryHistoryDefaultLimit,
+	maxLimit:     models.QueryHistoryMaxLimit,
+}
+
+// handleListQueryHistory returns a page of the authenticated user's query
+// history, newest first. Supports ?limit= (default
+// models.QueryHistoryDefaultLimit, capped at models.QueryHistoryMaxLimit),
+// ?offset=, ?search=, ?source_id= and ?status=.
 // URL: GET /api/v1/me/query-history
 // Requires: User authentication (requireAuth middleware)
 func (s *Server) handleListQueryHistory(c *fiber.Ctx) error {
@@ -236,25 +268,29 @@ func (s *Server) handleListQueryHistory(c *fiber.Ctx) error {
 		return SendError(c, fiber.StatusInternalServerError, "Error retrieving user context")
 	}
 
-	limit := models.QueryHistoryDefaultLimit
-	if raw := c.Query("limit"); raw != "" {
-		parsed, err := strconv.Atoi(raw)
-		if err != nil || parsed <= 0 {
-			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid limit", models.ValidationErrorType)
-		}
-		limit = parsed
+	q, err := parseListQuery(c, queryHistoryListSpec)
+	if err != nil {
+		return sendListError(c, err)
 	}
-	if limit > models.QueryHistoryMaxLimit {
-		limit = models.QueryHistoryMaxLimit
+
+	filter, err := parseQueryHistoryFilter(c, q, false)
+	if err != nil {
+		return sendListError(c, err)
 	}
+	filter.UserID = &user.ID
 
-	history, err := s.sqlite.ListQueryHistory(c.Context(), user.ID, limit)
+	history, err := s.sqlite.ListQueryHistory(c.Context(), filter)
 	if err != nil {
 		s.log.Error("failed to list query history", "error", err, "user_id", user.ID)
 		return SendError(c, fiber.StatusInternalServerError, "Error listing query history")
 	}
+	total, err := s.sqlite.CountQueryHistory(c.Context(), filter)
+	if err != nil {
+		s.log.Error("failed to count query history", "error", err, "user_id", user.ID)
+		return SendError(c, fiber.StatusInternalServerError, "Error listing query history")
+	}
 
-	return SendSuccess(c, fiber.StatusOK, history)
+	return SendList(c, history, models.NewPagination(q, total))
 }
 
 // --- API Token Management Handlers ---
@@ -478,6 +514,7 @@ func (s *Server) handleAddServiceAccountToTeam(c *fiber.Ctx) error {
 		s.log.Error("failed to add service account to team", "error", err, "user_id", account.ID, "team_id", req.TeamID)
 		return SendError(c, fiber.StatusInternalServerError, "Error adding service account to team")
 	}
+	s.recordAudit(c, models.AuditActionTeamMemberAdd, models.AuditResourceTeamMember, auditTeamMemberID(req.TeamID, account.ID), &req.TeamID, map[string]any{"role": req.Role, "service_account": true})
 	return SendSuccess(c, fiber.StatusCreated, fiber.Map{"message": "Service account added to team"})
 }
 
@@ -494,6 +531,7 @@ func (s *Server) handleRemoveServiceAccountFromTeam(c *fiber.Ctx) error {
 		s.log.Error("failed to remove service account from team", "error", err, "user_id", account.ID, "team_id", teamID)
 		return SendError(c, fiber.StatusInternalServerError, "Error removing service account from team")
 	}
+	s.recordAudit(c, models.AuditActionTeamMemberRemove, models.AuditResourceTeamMember, auditTeamMemberID(teamID, account.ID), &teamID, map[string]any{"service_account": true})
 	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Service account removed from team"})
 }
 
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// handleGetVolumeAnomalies compares a source's log volume and error rate over
+// a recent window with the same window a day or a week earlier and flags
+// significant deviations.
+// Access is controlled by the requireSourceAccess middleware.
+// Query params:
+//   - window_seconds: length of the compared windows (default 3600)
+//   - end_time: ISO8601 end of the current window (optional, defaults to now)
+//   - baseline: previous_day (default) or previous_week
+//   - threshold_percent: change that counts as an anomaly (default 100, i.e. 2x)
+//   - min_rows: windows with fewer rows on both sides aren't judged (default 100)
+//   - query: datasource-native query string (optional, narrows the rows)
+func (s *Server) handleGetVolumeAnomalies(c *fiber.Ctx) error {
+	sourceID, err := core.ParseSourceID(c.Params("sourceID"))
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid source ID format", models.ValidationErrorType)
+	}
+
+	end := time.Now()
+	if v := c.Query("end_time", ""); v != "" {
+		if end, err = time.Parse(time.RFC3339, v); err != nil {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid end_time format (use ISO8601/RFC3339)", models.ValidationErrorType)
+		}
+	}
+	threshold := 0.0
+	if v := c.Query("threshold_percent", ""); v != "" {
+		if threshold, err = strconv.ParseFloat(v, 64); err != nil || threshold <= 0 {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "threshold_percent must be a positive number", models.ValidationErrorType)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), HistogramTimeout)
+	defer cancel()
+
+	report, err := core.DetectVolumeAnomalies(ctx, s.datasources, sourceID, core.VolumeAnomalyParams{
+		Window:           time.Duration(c.QueryInt("window_seconds", 3600)) * time.Second,
+		End:              end,
+		Baseline:         models.AlertBaseline(c.Query("baseline", "")),
+		ThresholdPercent: threshold,
+		MinRows:          int64(c.QueryInt("min_rows", 0)),
+		QueryText:        c.Query("query", ""),
+	})
+	if err != nil {
+		if ctx.Err() == context.Canceled {
+			return SendErrorWithType(c, fiber.StatusRequestTimeout, "Request cancelled", models.ExternalServiceErrorType)
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			s.log.Warn("volume anomaly request timed out", "source_id", sourceID, "timeout", HistogramTimeout)
+			return SendErrorWithType(c, fiber.StatusRequestTimeout, "Request timed out", models.ExternalServiceErrorType)
+		}
+		if errors.Is(err, core.ErrSourceNotFound) {
+			return SendErrorWithType(c, fiber.StatusNotFound, "Source not found", models.NotFoundErrorType)
+		}
+		if errors.Is(err, datasource.ErrOperationNotSupported) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, "Volume anomaly detection is not supported for this source type yet", models.ValidationErrorType)
+		}
+		if datasource.IsValidationError(err) {
+			return SendErrorWithType(c, fiber.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err), models.ValidationErrorType)
+		}
+		s.log.Error("failed to detect volume anomalies", "error", err, "source_id", sourceID)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to detect volume anomalies: %v", err), models.DatabaseErrorType)
+	}
+
+	return SendSuccess(c, fiber.StatusOK, report)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mr-karan/logchef/internal/core"
+	"github.com/mr-karan/logchef/pkg/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sendWebhookError maps core webhook errors onto responses.
+func (s *Server) sendWebhookError(c *fiber.Ctx, err error, action string) error {
+	switch {
+	case errors.Is(err, core.ErrInvalidWebhook):
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	case errors.Is(err, core.ErrWebhookNotFound):
+		return SendErrorWithType(c, fiber.StatusNotFound, "Webhook not found", models.NotFoundErrorType)
+	default:
+		s.log.Error("failed to "+action+" webhook", "error", err)
+		return SendErrorWithType(c, fiber.StatusInternalServerError, "Failed to "+action+" webhook", models.GeneralErrorType)
+	}
+}
+
+// webhookAuditDetails records where a webhook delivers and what it receives.
+// The secret is never recorded.
+func webhookAuditDetails(webhook *models.Webhook) map[string]any {
+	return map[string]any{
+		"name":        webhook.Name,
+		"url":         webhook.URL,
+		"event_types": webhook.EventTypes,
+		"is_active":   webhook.IsActive,
+	}
+}
+
+// handleListWebhooks lists every registered webhook.
+func (s *Server) handleListWebhooks(c *fiber.Ctx) error {
+	webhooks, err := core.ListWebhooks(c.Context(), s.sqlite)
+	if err != nil {
+		return s.sendWebhookError(c, err, "list")
+	}
+	return SendSuccess(c, fiber.StatusOK, webhooks)
+}
+
+// handleGetWebhook returns one webhook, without its secret.
+func (s *Server) handleGetWebhook(c *fiber.Ctx) error {
+	id, err := parsePositiveIntParam(c, "webhookID")
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+	webhook, err := core.GetWebhook(c.Context(), s.sqlite, models.WebhookID(id))
+	if err != nil {
+		return s.sendWebhookError(c, err, "load")
+	}
+	return SendSuccess(c, fiber.StatusOK, webhook)
+}
+
+// handleCreateWebhook registers a webhook. The response is the only time its
+// signing secret is shown, apart from a later rotation.
+func (s *Server) handleCreateWebhook(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	var req models.CreateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	webhook, err := core.CreateWebhook(c.Context(), s.sqlite, s.log, user, &req)
+	if err != nil {
+		return s.sendWebhookError(c, err, "create")
+	}
+	s.recordAudit(c, models.AuditActionWebhookCreate, models.AuditResourceWebhook, auditID(webhook.ID), nil, webhookAuditDetails(webhook))
+	return SendSuccess(c, fiber.StatusCreated, models.WebhookWithSecret{Webhook: webhook, Secret: webhook.Secret})
+}
+
+// handleUpdateWebhook replaces a webhook's settings. With rotate_secret the
+// response carries the new secret.
+func (s *Server) handleUpdateWebhook(c *fiber.Ctx) error {
+	id, err := parsePositiveIntParam(c, "webhookID")
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, "Invalid request body", models.ValidationErrorType)
+	}
+
+	webhook, rotated, err := core.UpdateWebhook(c.Context(), s.sqlite, s.log, models.WebhookID(id), &req)
+	if err != nil {
+		return s.sendWebhookError(c, err, "update")
+	}
+	details := webhookAuditDetails(webhook)
+	details["secret_rotated"] = rotated
+	s.recordAudit(c, models.AuditActionWebhookUpdate, models.AuditResourceWebhook, auditID(webhook.ID), nil, details)
+	if rotated {
+		return SendSuccess(c, fiber.StatusOK, models.WebhookWithSecret{Webhook: webhook, Secret: webhook.Secret})
+	}
+	return SendSuccess(c, fiber.StatusOK, webhook)
+}
+
+// handleDeleteWebhook removes a webhook.
+func (s *Server) handleDeleteWebhook(c *fiber.Ctx) error {
+	id, err := parsePositiveIntParam(c, "webhookID")
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+
+	webhook, err := core.DeleteWebhook(c.Context(), s.sqlite, s.log, models.WebhookID(id))
+	if err != nil {
+		return s.sendWebhookError(c, err, "delete")
+	}
+	s.recordAudit(c, models.AuditActionWebhookDelete, models.AuditResourceWebhook, auditID(webhook.ID), nil, webhookAuditDetails(webhook))
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Webhook deleted successfully"})
+}
+
+// handleTestWebhookEndpoint sends a registered webhook a signed ping event,
+// once, so admins can check the endpoint and their signature verification.
+func (s *Server) handleTestWebhookEndpoint(c *fiber.Ctx) error {
+	if s.webhooks == nil {
+		return SendErrorWithType(c, fiber.StatusServiceUnavailable, "Webhook delivery is not available", models.GeneralErrorType)
+	}
+	id, err := parsePositiveIntParam(c, "webhookID")
+	if err != nil {
+		return SendErrorWithType(c, fiber.StatusBadRequest, err.Error(), models.ValidationErrorType)
+	}
+	webhook, err := core.GetWebhook(c.Context(), s.sqlite, models.WebhookID(id))
+	if err != nil {
+		return s.sendWebhookError(c, err, "load")
+	}
+
+	if err := s.webhooks.Ping(c.Context(), webhook); err != nil {
+		return SendError(c, fiber.StatusBadGateway, fmt.Sprintf("Failed to deliver test event: %v", err))
+	}
+	return SendSuccess(c, fiber.StatusOK, fiber.Map{"message": "Test event delivered"})
+}
@@ -0,0 +1,228 @@
+// Package slo evaluates service-level objectives against their source
+// backends.
+//
+// An SLO's SLI is the ratio of two log counts over a rolling window. LogchefQL
+// SLIs are filters, compiled to count queries the same way condition alerts
+// are; native SLIs are queries returning the count themselves. The Manager's
+// scheduler evaluates every SLO each interval and records compliance and
+// error-budget burn in the metadata store; BurnRate serves burn-rate alerts
+// over their own (usually much shorter) lookback.
+package slo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/internal/template"
+	"github.com/mr-karan/logchef/internal/util"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// evaluationTimeout bounds one SLO's evaluation (both counts), so a wedged
+// source can't stall the sequential scheduler loop.
+const evaluationTimeout = 2 * time.Minute
+
+// backend is the datasource surface the manager needs. *datasource.Service
+// implements it; tests substitute a fake.
+type backend interface {
+	BuildConditionAlertQuery(ctx context.Context, sourceID models.SourceID, req datasource.ConditionAlertRequest) (string, error)
+	EvaluateAlert(ctx context.Context, sourceID models.SourceID, req datasource.AlertQueryRequest) (*models.QueryResult, error)
+}
+
+// Options encapsulates the dependencies of the SLO manager.
+type Options struct {
+	Config      config.SLOsConfig
+	DB          store.Store
+	Datasources *datasource.Service
+	Logger      *slog.Logger
+}
+
+// Manager runs the SLO evaluation scheduler and computes burn rates.
+type Manager struct {
+	cfg config.SLOsConfig
+	db  store.Store
+	log *slog.Logger
+
+	// backend and now are seams for tests.
+	backend backend
+	now     func() time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewManager constructs an SLO manager.
+func NewManager(opts Options) *Manager {
+	m := &Manager{
+		cfg:  opts.Config,
+		db:   opts.DB,
+		log:  opts.Logger.With("component", "slo_manager"),
+		now:  time.Now,
+		stop: make(chan struct{}),
+	}
+	if opts.Datasources != nil {
+		m.backend = opts.Datasources
+	}
+	return m
+}
+
+// Start launches the scheduler loop. It is a no-op when SLO evaluation is
+// disabled.
+func (m *Manager) Start(ctx context.Context) {
+	if !m.cfg.Enabled {
+		m.log.Debug("slo evaluation disabled")
+		return
+	}
+	m.log.Debug("starting slo manager", "interval", m.cfg.Interval)
+
+	m.wg.Go(func() {
+		ticker := time.NewTicker(m.cfg.Interval)
+		defer ticker.Stop()
+
+		m.runCycle(ctx)
+		for {
+			select {
+			case <-ticker.C:
+				m.runCycle(ctx)
+			case <-m.stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// Stop signals the scheduler to stop and waits for the current run to end.
+func (m *Manager) Stop() {
+	close(m.stop)
+	m.wg.Wait()
+}
+
+func (m *Manager) runCycle(ctx context.Context) {
+	slos, err := m.db.ListSLOs(ctx)
+	if err != nil {
+		m.log.Error("failed to list slos", "error", err)
+		return
+	}
+	for _, slo := range slos {
+		select {
+		case <-m.stop:
+			return
+		default:
+		}
+		m.record(ctx, slo)
+	}
+}
+
+// record evaluates slo over its window, stores the evaluation (failures
+// included, so the history shows the gap) and prunes old evaluations.
+func (m *Manager) record(ctx context.Context, slo *models.SLO) {
+	evalCtx, cancel := context.WithTimeout(ctx, evaluationTimeout)
+	defer cancel()
+
+	now := m.now().UTC()
+	good, total, err := m.Counts(evalCtx, slo, slo.WindowSeconds, now)
+	eval := slo.NewEvaluation(good, total, now)
+	if err != nil {
+		m.log.Warn("slo evaluation failed", "slo_id", slo.ID, "source_id", slo.SourceID, "error", err)
+		eval = &models.SLOEvaluation{SLOID: slo.ID, Error: err.Error(), EvaluatedAt: now}
+	}
+
+	if err := m.db.InsertSLOEvaluation(ctx, eval); err != nil {
+		m.log.Error("failed to record slo evaluation", "slo_id", slo.ID, "error", err)
+		return
+	}
+	if err := m.db.PruneSLOEvaluations(ctx, slo.ID, m.cfg.HistoryLimit); err != nil {
+		m.log.Warn("failed to prune slo evaluations", "slo_id", slo.ID, "error", err)
+	}
+}
+
+// BurnRate returns the error-budget burn rate of an SLO over the
+// lookbackSeconds ending now; burn-rate alerts compare it to their threshold.
+// A window with no matching logs burns nothing.
+func (m *Manager) BurnRate(ctx context.Context, id models.SLOID, lookbackSeconds int) (float64, error) {
+	slo, err := m.db.GetSLO(ctx, id)
+	if err != nil {
+		return 0, fmt.Errorf("loading slo %d: %w", id, err)
+	}
+	good, total, err := m.Counts(ctx, slo, lookbackSeconds, m.now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	burn, _ := slo.BurnRate(good, total)
+	return burn, nil
+}
+
+// Counts runs the SLO's good and total queries over the windowSeconds ending
+// at now.
+func (m *Manager) Counts(ctx context.Context, slo *models.SLO, windowSeconds int, now time.Time) (good, total float64, err error) {
+	if m.backend == nil {
+		return 0, 0, fmt.Errorf("datasource service is not configured")
+	}
+	total, err = m.count(ctx, slo, slo.TotalQuery, windowSeconds, now)
+	if err != nil {
+		return 0, 0, fmt.Errorf("total query: %w", err)
+	}
+	good, err = m.count(ctx, slo, slo.GoodQuery, windowSeconds, now)
+	if err != nil {
+		return 0, 0, fmt.Errorf("good query: %w", err)
+	}
+	return good, total, nil
+}
+
+func (m *Manager) count(ctx context.Context, slo *models.SLO, query string, windowSeconds int, now time.Time) (float64, error) {
+	req, err := m.countRequest(ctx, slo, query, windowSeconds, now)
+	if err != nil {
+		return 0, err
+	}
+	timeout := models.DefaultQueryTimeoutSeconds
+	req.QueryTimeout = &timeout
+	result, err := m.backend.EvaluateAlert(ctx, slo.SourceID, req)
+	if err != nil {
+		return 0, err
+	}
+	return util.ExtractFirstNumeric(result)
+}
+
+// countRequest builds the native query counting query's logs over the window.
+// LogchefQL filters compile to count queries scoped to the window; native
+// ClickHouse SQL has {{start}} and {{end}} substituted; LogsQL is scoped by
+// the backend through LookbackSeconds.
+func (m *Manager) countRequest(ctx context.Context, slo *models.SLO, query string, windowSeconds int, now time.Time) (datasource.AlertQueryRequest, error) {
+	req := datasource.AlertQueryRequest{LookbackSeconds: windowSeconds}
+	switch models.NormalizeQueryLanguage(slo.QueryLanguage) {
+	case models.QueryLanguageLogchefQL:
+		compiled, err := m.backend.BuildConditionAlertQuery(ctx, slo.SourceID, datasource.ConditionAlertRequest{
+			Condition:       &models.AlertCondition{Filter: query, Aggregate: models.AlertAggregateCount},
+			LookbackSeconds: windowSeconds,
+			Now:             now,
+		})
+		if err != nil {
+			return req, fmt.Errorf("compiling filter: %w", err)
+		}
+		req.Query = compiled
+	case models.QueryLanguageClickHouseSQL:
+		start := now.Add(-time.Duration(windowSeconds) * time.Second)
+		substituted, err := template.SubstituteVariables(query, []template.Variable{
+			{Name: "start", Type: template.TypeDate, Value: start},
+			{Name: "end", Type: template.TypeDate, Value: now},
+		})
+		if err != nil {
+			return req, fmt.Errorf("substituting window: %w", err)
+		}
+		req.Language = models.QueryLanguageClickHouseSQL
+		req.Query = substituted
+	default:
+		req.Language = slo.QueryLanguage
+		req.Query = strings.TrimSpace(query)
+	}
+	return req, nil
+}
@@ -0,0 +1,180 @@
+package slo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/store/sqlite"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// fakeBackend compiles a LogchefQL filter to "count:<filter>" and answers each
+// query with its entry in counts, recording what it ran.
+type fakeBackend struct {
+	counts  map[string]float64
+	queries []datasource.AlertQueryRequest
+}
+
+func (f *fakeBackend) BuildConditionAlertQuery(_ context.Context, _ models.SourceID, req datasource.ConditionAlertRequest) (string, error) {
+	return "count:" + req.Condition.Filter, nil
+}
+
+func (f *fakeBackend) EvaluateAlert(_ context.Context, _ models.SourceID, req datasource.AlertQueryRequest) (*models.QueryResult, error) {
+	f.queries = append(f.queries, req)
+	value, ok := f.counts[req.Query]
+	if !ok {
+		return nil, errors.New("unknown query")
+	}
+	return &models.QueryResult{
+		Columns: []models.ColumnInfo{{Name: "value", Type: "UInt64"}},
+		Logs:    []map[string]any{{"value": value}},
+	}, nil
+}
+
+func newTestManager(t *testing.T, now time.Time) (*Manager, *sqlite.DB, *fakeBackend, *models.SLO) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := sqlite.New(context.Background(), sqlite.Options{
+		Logger: logger,
+		Config: config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")},
+	})
+	if err != nil {
+		t.Fatalf("sqlite.New failed: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx := context.Background()
+	source := &models.Source{
+		Name:        "app",
+		MetaTSField: "timestamp",
+		Connection:  models.ConnectionInfo{Host: "ch:9000", Username: "default", Database: "logs", TableName: "app"},
+	}
+	if err := db.CreateSource(ctx, source); err != nil {
+		t.Fatalf("CreateSource: %v", err)
+	}
+	team := &models.Team{Name: "payments"}
+	if err := db.CreateTeam(ctx, team); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+	slo := &models.SLO{
+		TeamID:        team.ID,
+		SourceID:      source.ID,
+		Name:          "checkout",
+		QueryLanguage: models.QueryLanguageLogchefQL,
+		GoodQuery:     "status < 500",
+		TotalQuery:    `service = "checkout"`,
+		Target:        0.99,
+		WindowSeconds: 24 * 3600,
+	}
+	if err := db.CreateSLO(ctx, slo); err != nil {
+		t.Fatalf("CreateSLO: %v", err)
+	}
+
+	fake := &fakeBackend{counts: map[string]float64{}}
+	m := NewManager(Options{
+		Config: config.SLOsConfig{Enabled: true, Interval: time.Minute, HistoryLimit: 2},
+		DB:     db,
+		Logger: logger,
+	})
+	m.backend = fake
+	m.now = func() time.Time { return now }
+	return m, db, fake, slo
+}
+
+func TestRunCycleRecordsEvaluations(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	m, db, fake, slo := newTestManager(t, now)
+	ctx := context.Background()
+
+	fake.counts[`count:service = "checkout"`] = 1000
+	fake.counts["count:status < 500"] = 995
+	m.runCycle(ctx)
+
+	evals, err := db.ListSLOEvaluations(ctx, slo.ID, 10)
+	if err != nil || len(evals) != 1 {
+		t.Fatalf("ListSLOEvaluations: %v / %d", err, len(evals))
+	}
+	eval := evals[0]
+	if eval.Good != 995 || eval.Total != 1000 || eval.Error != "" || !eval.EvaluatedAt.Equal(now) {
+		t.Fatalf("evaluation = %+v", eval)
+	}
+	// 0.5% errors against a 1% budget burns at half the sustainable rate.
+	if eval.BurnRate == nil || *eval.BurnRate < 0.4999 || *eval.BurnRate > 0.5001 {
+		t.Errorf("burn rate = %v, want 0.5", eval.BurnRate)
+	}
+	if eval.ErrorBudgetRemaining == nil || *eval.ErrorBudgetRemaining < 0.4999 || *eval.ErrorBudgetRemaining > 0.5001 {
+		t.Errorf("error budget remaining = %v, want 0.5", eval.ErrorBudgetRemaining)
+	}
+	for _, q := range fake.queries {
+		if q.LookbackSeconds != slo.WindowSeconds {
+			t.Errorf("query %q ran over %ds, want the SLO window", q.Query, q.LookbackSeconds)
+		}
+	}
+
+	// A failing query is recorded, and history is pruned to the limit.
+	delete(fake.counts, "count:status < 500")
+	m.runCycle(ctx)
+	m.runCycle(ctx)
+	evals, _ = db.ListSLOEvaluations(ctx, slo.ID, 10)
+	if len(evals) != 2 {
+		t.Fatalf("kept %d evaluations, want 2", len(evals))
+	}
+	if !strings.Contains(evals[0].Error, "good query") || evals[0].Compliance != nil {
+		t.Errorf("failed evaluation = %+v", evals[0])
+	}
+}
+
+func TestBurnRateAndNativeWindow(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	m, db, fake, slo := newTestManager(t, now)
+	ctx := context.Background()
+
+	fake.counts[`count:service = "checkout"`] = 200
+	fake.counts["count:status < 500"] = 180
+	burn, err := m.BurnRate(ctx, slo.ID, 3600)
+	if err != nil {
+		t.Fatalf("BurnRate: %v", err)
+	}
+	// 10% errors against a 1% budget.
+	if burn < 9.999 || burn > 10.001 {
+		t.Errorf("burn rate = %v, want 10", burn)
+	}
+	if fake.queries[0].LookbackSeconds != 3600 {
+		t.Errorf("burn rate ran over %ds, want the alert lookback", fake.queries[0].LookbackSeconds)
+	}
+
+	// No logs in the window burns nothing.
+	fake.counts[`count:service = "checkout"`] = 0
+	fake.counts["count:status < 500"] = 0
+	if burn, err := m.BurnRate(ctx, slo.ID, 3600); err != nil || burn != 0 {
+		t.Errorf("BurnRate(empty window) = %v, %v", burn, err)
+	}
+
+	// Native ClickHouse SLIs get the window substituted.
+	slo.QueryLanguage = models.QueryLanguageClickHouseSQL
+	slo.TotalQuery = "SELECT count() FROM logs WHERE ts >= {{start}} AND ts < {{end}}"
+	slo.GoodQuery = "SELECT countIf(status < 500) FROM logs WHERE ts >= {{start}}"
+	if err := db.UpdateSLO(ctx, slo); err != nil {
+		t.Fatalf("UpdateSLO: %v", err)
+	}
+	fake.counts["SELECT count() FROM logs WHERE ts >= '2026-03-10 11:00:00' AND ts < '2026-03-10 12:00:00'"] = 100
+	fake.counts["SELECT countIf(status < 500) FROM logs WHERE ts >= '2026-03-10 11:00:00'"] = 100
+	if burn, err := m.BurnRate(ctx, slo.ID, 3600); err != nil || burn != 0 {
+		t.Errorf("BurnRate(native) = %v, %v", burn, err)
+	}
+	if got := fake.queries[len(fake.queries)-1].Language; got != models.QueryLanguageClickHouseSQL {
+		t.Errorf("native query language = %q", got)
+	}
+
+	if _, err := m.BurnRate(ctx, slo.ID+100, 3600); !models.IsNotFound(err) {
+		t.Errorf("BurnRate(missing slo) err = %v, want not found", err)
+	}
+}
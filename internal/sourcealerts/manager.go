@@ -0,0 +1,360 @@
+// Package sourcealerts raises alerts about the sources themselves rather than
+// the logs in them: a source that stops answering health probes, or whose
+// queries start failing.
+//
+// The manager observes the datasource health prober. After every probe cycle
+// it updates each source's consecutive probe failures and its query error rate
+// over the configured window, and records an event when a source crosses a
+// threshold or recovers. Events are kept apart from user alerts' history and
+// delivered to the configured webhook and Slack channels through the alert
+// delivery path.
+//
+// All of this state lives in the manager's memory and, unlike user alerts,
+// isn't leased through the store, so only one replica should run it.
+package sourcealerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/alerts"
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// ErrInvalidRequest wraps history request errors.
+var ErrInvalidRequest = errors.New("invalid source alert history request")
+
+const (
+	// DefaultHistoryLimit is how many events a history request returns when
+	// it doesn't say.
+	DefaultHistoryLimit = 50
+	// MaxHistoryLimit caps the events returned by one history request.
+	MaxHistoryLimit = 500
+)
+
+// pruneInterval is how often events past the retention are removed.
+const pruneInterval = time.Hour
+
+// restoreEvents is how many of a source's latest events are read to restore
+// which of its alerts are firing, e.g. after a restart.
+const restoreEvents = 20
+
+// Options encapsulates the dependencies of the source alert manager.
+type Options struct {
+	Config      config.SourceAlertsConfig
+	DB          store.Store
+	Datasources *datasource.Service
+	// Sender delivers alert notifications; nil disables them.
+	Sender alerts.AlertSender
+	Logger *slog.Logger
+}
+
+// Manager evaluates source degradation alerts and serves their history.
+type Manager struct {
+	cfg         config.SourceAlertsConfig
+	db          store.Store
+	datasources *datasource.Service
+	sender      alerts.AlertSender
+	channels    []models.AlertChannel
+	log         *slog.Logger
+
+	// now is a seam for tests.
+	now func() time.Time
+
+	// states is only touched by ObserveSource, which the prober calls one
+	// source at a time.
+	states map[models.SourceID]*sourceState
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// sourceState is what the manager tracks per source. A zero since time means
+// that alert is not firing.
+type sourceState struct {
+	failures         int
+	unreachableSince time.Time
+	failingSince     time.Time
+	queries          []queryCycle
+}
+
+// queryCycle is one probe cycle's query outcomes.
+type queryCycle struct {
+	at       time.Time
+	outcomes datasource.QueryOutcomes
+}
+
+// NewManager constructs a source alert manager.
+func NewManager(opts Options) *Manager {
+	return &Manager{
+		cfg:         opts.Config,
+		db:          opts.DB,
+		datasources: opts.Datasources,
+		sender:      opts.Sender,
+		channels:    opts.Config.Channels(),
+		log:         opts.Logger.With("component", "source_alert_manager"),
+		now:         time.Now,
+		states:      make(map[models.SourceID]*sourceState),
+		stop:        make(chan struct{}),
+	}
+}
+
+// Start registers the manager with the health prober and launches the prune
+// loop. It is a no-op when source alerts are disabled.
+func (m *Manager) Start(ctx context.Context) {
+	if !m.cfg.Enabled {
+		m.log.Debug("source alerts disabled")
+		return
+	}
+	m.log.Debug("starting source alert manager", "channels", len(m.channels))
+	m.datasources.SetHealthObserver(m)
+
+	m.wg.Go(func() {
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+
+		m.prune(ctx)
+		for {
+			select {
+			case <-ticker.C:
+				m.prune(ctx)
+			case <-m.stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// Stop unregisters the manager from the health prober and waits for the prune
+// loop to end.
+func (m *Manager) Stop() {
+	if m.cfg.Enabled {
+		m.datasources.SetHealthObserver(nil)
+	}
+	close(m.stop)
+	m.wg.Wait()
+}
+
+func (m *Manager) prune(ctx context.Context) {
+	cutoff := m.now().UTC().AddDate(0, 0, -m.cfg.RetentionDays)
+	pruned, err := m.db.DeleteSourceAlertEventsBefore(ctx, cutoff)
+	if err != nil {
+		m.log.Error("failed to prune source alert events", "error", err)
+		return
+	}
+	if pruned > 0 {
+		m.log.Debug("pruned source alert events", "count", pruned, "before", cutoff)
+	}
+}
+
+// ObserveSource implements datasource.HealthObserver: it updates the source's
+// state with one probe cycle and records and sends any alert that fired or
+// resolved.
+func (m *Manager) ObserveSource(ctx context.Context, source *models.Source, health models.SourceHealth, queries datasource.QueryOutcomes) {
+	state := m.stateFor(ctx, source.ID)
+	now := m.now().UTC()
+
+	var events []*models.SourceAlertEvent
+	if event := m.evaluateReachability(state, source, health, now); event != nil {
+		events = append(events, event)
+	}
+	if event := m.evaluateErrorRate(state, source, queries, now); event != nil {
+		events = append(events, event)
+	}
+	for _, event := range events {
+		m.raise(ctx, source, state, event)
+	}
+}
+
+// stateFor returns the source's state, restoring which of its alerts are
+// firing from its latest events the first time the source is seen.
+func (m *Manager) stateFor(ctx context.Context, sourceID models.SourceID) *sourceState {
+	if state, ok := m.states[sourceID]; ok {
+		return state
+	}
+	state := &sourceState{}
+	m.states[sourceID] = state
+
+	events, err := m.db.ListSourceAlertEvents(ctx, sourceID, restoreEvents)
+	if err != nil {
+		m.log.Warn("failed to restore source alert state", "source_id", sourceID, "error", err)
+		return state
+	}
+	seen := make(map[models.SourceAlertKind]bool, 2)
+	for _, event := range events {
+		if seen[event.Kind] {
+			continue
+		}
+		seen[event.Kind] = true
+		if event.Status != models.AlertStatusTriggered {
+			continue
+		}
+		switch event.Kind {
+		case models.SourceAlertUnreachable:
+			state.unreachableSince = event.CreatedAt
+			state.failures = m.cfg.FailureThreshold
+		case models.SourceAlertErrorRate:
+			state.failingSince = event.CreatedAt
+		}
+	}
+	return state
+}
+
+// evaluateReachability fires once FailureThreshold probes in a row failed
+// and resolves on the next healthy probe.
+func (m *Manager) evaluateReachability(state *sourceState, source *models.Source, health models.SourceHealth, now time.Time) *models.SourceAlertEvent {
+	if health.Status == models.HealthStatusHealthy {
+		state.failures = 0
+		if state.unreachableSince.IsZero() {
+			return nil
+		}
+		return &models.SourceAlertEvent{
+			SourceID:  source.ID,
+			Kind:      models.SourceAlertUnreachable,
+			Status:    models.AlertStatusResolved,
+			Message:   fmt.Sprintf("Source %q is reachable again.", source.Name),
+			CreatedAt: now,
+		}
+	}
+
+	state.failures++
+	if !state.unreachableSince.IsZero() || state.failures < m.cfg.FailureThreshold {
+		return nil
+	}
+	message := fmt.Sprintf("Source %q failed %d health checks in a row.", source.Name, state.failures)
+	if health.Error != "" {
+		message += " Last error: " + health.Error
+	}
+	return &models.SourceAlertEvent{
+		SourceID:  source.ID,
+		Kind:      models.SourceAlertUnreachable,
+		Status:    models.AlertStatusTriggered,
+		Value:     float64(state.failures),
+		Message:   message,
+		CreatedAt: now,
+	}
+}
+
+// evaluateErrorRate fires when at least ErrorRate of the window's queries
+// failed, out of at least MinQueries, and resolves once the rate drops below
+// ErrorRate, including when the window has no queries left.
+func (m *Manager) evaluateErrorRate(state *sourceState, source *models.Source, queries datasource.QueryOutcomes, now time.Time) *models.SourceAlertEvent {
+	if queries.Total > 0 {
+		state.queries = append(state.queries, queryCycle{at: now, outcomes: queries})
+	}
+	cutoff := now.Add(-m.cfg.ErrorRateWindow)
+	kept := state.queries[:0]
+	var total, failed int
+	for _, cycle := range state.queries {
+		if cycle.at.After(cutoff) {
+			kept = append(kept, cycle)
+			total += cycle.outcomes.Total
+			failed += cycle.outcomes.Failed
+		}
+	}
+	state.queries = kept
+
+	var rate float64
+	if total > 0 {
+		rate = float64(failed) / float64(total)
+	}
+	switch {
+	case state.failingSince.IsZero() && total >= m.cfg.MinQueries && rate >= m.cfg.ErrorRate:
+		return &models.SourceAlertEvent{
+			SourceID: source.ID,
+			Kind:     models.SourceAlertErrorRate,
+			Status:   models.AlertStatusTriggered,
+			Value:    rate,
+			Message: fmt.Sprintf("%d of %d queries on source %q failed in the last %s.",
+				failed, total, source.Name, m.cfg.ErrorRateWindow),
+			CreatedAt: now,
+		}
+	case !state.failingSince.IsZero() && rate < m.cfg.ErrorRate:
+		return &models.SourceAlertEvent{
+			SourceID:  source.ID,
+			Kind:      models.SourceAlertErrorRate,
+			Status:    models.AlertStatusResolved,
+			Value:     rate,
+			Message:   fmt.Sprintf("Queries on source %q are succeeding again.", source.Name),
+			CreatedAt: now,
+		}
+	}
+	return nil
+}
+
+// raise records event, updates the firing state and notifies the configured
+// channels. Delivery failures are logged; the event is already recorded.
+func (m *Manager) raise(ctx context.Context, source *models.Source, state *sourceState, event *models.SourceAlertEvent) {
+	since := &state.unreachableSince
+	if event.Kind == models.SourceAlertErrorRate {
+		since = &state.failingSince
+	}
+	triggeredAt := *since
+	if event.Status == models.AlertStatusTriggered {
+		triggeredAt = event.CreatedAt
+		*since = event.CreatedAt
+	} else {
+		*since = time.Time{}
+	}
+
+	if err := m.db.InsertSourceAlertEvent(ctx, event); err != nil {
+		m.log.Error("failed to record source alert event", "source_id", source.ID, "kind", event.Kind, "error", err)
+	}
+	m.log.Info("source alert", "source_id", source.ID, "source", source.Name, "kind", event.Kind, "status", event.Status)
+
+	if m.sender == nil || len(m.channels) == 0 {
+		return
+	}
+	notification := alerts.AlertNotification{
+		Status:      event.Status,
+		SourceID:    source.ID,
+		SourceName:  source.Name,
+		Value:       event.Value,
+		ThresholdOp: models.AlertThresholdGreaterThanOrEqual,
+		Labels:      map[string]string{"event": "source_" + string(event.Kind)},
+		TriggeredAt: triggeredAt,
+		Message:     event.Message,
+		Channels:    m.channels,
+	}
+	if event.Status == models.AlertStatusResolved {
+		notification.ResolvedAt = &event.CreatedAt
+	}
+	switch event.Kind {
+	case models.SourceAlertUnreachable:
+		notification.AlertName = fmt.Sprintf("Source %s unreachable", source.Name)
+		notification.Description = fmt.Sprintf("Source %q is failing its health checks.", source.Name)
+		notification.Severity = models.AlertSeverityCritical
+		notification.ThresholdValue = float64(m.cfg.FailureThreshold)
+	case models.SourceAlertErrorRate:
+		notification.AlertName = fmt.Sprintf("Queries failing on %s", source.Name)
+		notification.Description = fmt.Sprintf("Too many queries on source %q are failing.", source.Name)
+		notification.Severity = models.AlertSeverityWarning
+		notification.ThresholdValue = m.cfg.ErrorRate
+	}
+	for _, delivery := range m.sender.Deliver(ctx, notification) {
+		if delivery.Status == models.AlertDeliveryFailed {
+			m.log.Warn("source alert notification failed", "source_id", source.ID, "channel", delivery.Channel, "error", delivery.Error)
+		}
+	}
+}
+
+// History returns up to limit of the source's alert events, newest first.
+// limit must be between 1 and MaxHistoryLimit.
+func (m *Manager) History(ctx context.Context, sourceID models.SourceID, limit int) ([]*models.SourceAlertEvent, error) {
+	if limit < 1 || limit > MaxHistoryLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidRequest, MaxHistoryLimit)
+	}
+	if _, err := m.db.GetSource(ctx, sourceID); err != nil {
+		return nil, err
+	}
+	return m.db.ListSourceAlertEvents(ctx, sourceID, limit)
+}
@@ -0,0 +1,185 @@
+package sourcealerts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/alerts"
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/store/sqlite"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// recordingSender captures delivered notifications.
+type recordingSender struct {
+	sent []alerts.AlertNotification
+}
+
+func (r *recordingSender) Deliver(_ context.Context, notification alerts.AlertNotification) []models.AlertChannelDelivery {
+	r.sent = append(r.sent, notification)
+	return nil
+}
+
+type testEnv struct {
+	m      *Manager
+	db     *sqlite.DB
+	sender *recordingSender
+	source *models.Source
+	now    time.Time
+}
+
+var testConfig = config.SourceAlertsConfig{
+	Enabled:          true,
+	FailureThreshold: 2,
+	ErrorRate:        0.5,
+	ErrorRateWindow:  5 * time.Minute,
+	MinQueries:       10,
+	RetentionDays:    30,
+	WebhookURLs:      []string{"https://hooks.example.com/sources"},
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := sqlite.New(context.Background(), sqlite.Options{
+		Logger: logger,
+		Config: config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")},
+	})
+	if err != nil {
+		t.Fatalf("sqlite.New failed: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	source := &models.Source{
+		Name:        "app",
+		MetaTSField: "timestamp",
+		Connection:  models.ConnectionInfo{Host: "ch:9000", Username: "default", Database: "logs", TableName: "app"},
+	}
+	if err := db.CreateSource(context.Background(), source); err != nil {
+		t.Fatalf("CreateSource: %v", err)
+	}
+
+	env := &testEnv{
+		db:     db,
+		sender: &recordingSender{},
+		source: source,
+		now:    time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC),
+	}
+	env.m = env.newManager(logger)
+	return env
+}
+
+func (e *testEnv) newManager(logger *slog.Logger) *Manager {
+	m := NewManager(Options{Config: testConfig, DB: e.db, Sender: e.sender, Logger: logger})
+	m.now = func() time.Time { return e.now }
+	return m
+}
+
+// cycle feeds one probe cycle to the manager and advances the clock.
+func (e *testEnv) cycle(healthy bool, queries datasource.QueryOutcomes) {
+	health := models.SourceHealth{SourceID: e.source.ID, Status: models.HealthStatusHealthy}
+	if !healthy {
+		health.Status = models.HealthStatusUnhealthy
+		health.Error = "connection refused"
+	}
+	e.m.ObserveSource(context.Background(), e.source, health, queries)
+	e.now = e.now.Add(30 * time.Second)
+}
+
+func (e *testEnv) events(t *testing.T) []*models.SourceAlertEvent {
+	t.Helper()
+	events, err := e.m.History(context.Background(), e.source.ID, MaxHistoryLimit)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	return events
+}
+
+func TestUnreachableAlert(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.cycle(false, datasource.QueryOutcomes{})
+	if len(env.events(t)) != 0 {
+		t.Fatal("alert fired before the failure threshold")
+	}
+	env.cycle(false, datasource.QueryOutcomes{})
+	env.cycle(false, datasource.QueryOutcomes{})
+
+	events := env.events(t)
+	if len(events) != 1 || events[0].Kind != models.SourceAlertUnreachable ||
+		events[0].Status != models.AlertStatusTriggered || events[0].Value != 2 {
+		t.Fatalf("events after 3 failed probes = %+v, want one unreachable trigger", events)
+	}
+	if len(env.sender.sent) != 1 || env.sender.sent[0].Severity != models.AlertSeverityCritical ||
+		env.sender.sent[0].Labels["event"] != "source_unreachable" {
+		t.Fatalf("notifications = %+v", env.sender.sent)
+	}
+
+	env.cycle(true, datasource.QueryOutcomes{})
+	events = env.events(t)
+	if len(events) != 2 || events[0].Status != models.AlertStatusResolved {
+		t.Fatalf("events after recovery = %+v, want a resolution", events)
+	}
+	if n := env.sender.sent[1]; n.Status != models.AlertStatusResolved || n.ResolvedAt == nil ||
+		!n.TriggeredAt.Equal(events[1].CreatedAt) {
+		t.Errorf("resolution notification = %+v", n)
+	}
+}
+
+func TestErrorRateAlert(t *testing.T) {
+	env := newTestEnv(t)
+
+	// Enough failures, but too few queries to judge.
+	env.cycle(true, datasource.QueryOutcomes{Total: 4, Failed: 4})
+	if len(env.events(t)) != 0 {
+		t.Fatal("alert fired below min_queries")
+	}
+	env.cycle(true, datasource.QueryOutcomes{Total: 8, Failed: 3})
+
+	events := env.events(t)
+	if len(events) != 1 || events[0].Kind != models.SourceAlertErrorRate ||
+		events[0].Status != models.AlertStatusTriggered || events[0].Value != 7.0/12 {
+		t.Fatalf("events = %+v, want one error rate trigger at 7/12", events)
+	}
+
+	// The failing cycles age out of the window with no new queries.
+	env.now = env.now.Add(10 * time.Minute)
+	env.cycle(true, datasource.QueryOutcomes{})
+	events = env.events(t)
+	if len(events) != 2 || events[0].Status != models.AlertStatusResolved || events[0].Value != 0 {
+		t.Fatalf("events after the window = %+v, want a resolution", events)
+	}
+}
+
+func TestStateRestoredFromHistory(t *testing.T) {
+	env := newTestEnv(t)
+	env.cycle(false, datasource.QueryOutcomes{})
+	env.cycle(false, datasource.QueryOutcomes{})
+
+	// A fresh manager, as after a restart, doesn't fire again but resolves.
+	env.m = env.newManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	env.cycle(false, datasource.QueryOutcomes{})
+	if events := env.events(t); len(events) != 1 {
+		t.Fatalf("events after restart = %+v, want the original trigger only", events)
+	}
+	env.cycle(true, datasource.QueryOutcomes{})
+	if events := env.events(t); len(events) != 2 || events[0].Status != models.AlertStatusResolved {
+		t.Fatalf("events after recovery = %+v, want a resolution", events)
+	}
+}
+
+func TestHistoryValidation(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.m.History(context.Background(), env.source.ID, 0); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("History(limit 0) err = %v, want ErrInvalidRequest", err)
+	}
+	if _, err := env.m.History(context.Background(), env.source.ID+100, 10); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("History(unknown source) err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,179 @@
+// Package sourcestats records a daily snapshot of each ClickHouse source's
+// storage footprint and serves growth trends from the snapshots.
+//
+// GetSourceStats answers "how big is this table now"; the snapshots kept here
+// answer "how has it grown", including how column compression has changed.
+package sourcestats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/clickhouse"
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// ErrInvalidRequest wraps trend request errors.
+var ErrInvalidRequest = errors.New("invalid source stats request")
+
+// snapshotTimeout bounds one source's snapshot, so a wedged source can't stall
+// the sequential scheduler loop.
+const snapshotTimeout = time.Minute
+
+// DefaultTrendDays is the trend range when a request doesn't give one.
+const DefaultTrendDays = 30
+
+// backend is the ClickHouse surface the manager needs. *clickhouse.Client
+// implements it; tests substitute a fake.
+type backend interface {
+	TableStorage(ctx context.Context, database, table string) (*clickhouse.TableStorage, error)
+}
+
+// Options encapsulates the dependencies of the source stats manager.
+type Options struct {
+	Config     config.SourceStatsConfig
+	DB         store.Store
+	ClickHouse *clickhouse.Manager
+	Logger     *slog.Logger
+}
+
+// Manager runs the snapshot scheduler and serves stats trends.
+type Manager struct {
+	cfg config.SourceStatsConfig
+	db  store.Store
+	log *slog.Logger
+
+	// clientFor and now are seams for tests.
+	clientFor func(models.SourceID) (backend, error)
+	now       func() time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewManager constructs a source stats manager.
+func NewManager(opts Options) *Manager {
+	return &Manager{
+		cfg: opts.Config,
+		db:  opts.DB,
+		log: opts.Logger.With("component", "source_stats_manager"),
+		clientFor: func(id models.SourceID) (backend, error) {
+			return opts.ClickHouse.GetConnection(id)
+		},
+		now:  time.Now,
+		stop: make(chan struct{}),
+	}
+}
+
+// Start launches the scheduler loop. It is a no-op when snapshots are disabled.
+func (m *Manager) Start(ctx context.Context) {
+	if !m.cfg.Enabled {
+		m.log.Debug("source stats snapshots disabled")
+		return
+	}
+	m.log.Debug("starting source stats manager", "interval", m.cfg.Interval)
+
+	m.wg.Go(func() {
+		ticker := time.NewTicker(m.cfg.Interval)
+		defer ticker.Stop()
+
+		m.runCycle(ctx)
+		for {
+			select {
+			case <-ticker.C:
+				m.runCycle(ctx)
+			case <-m.stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// Stop signals the scheduler to stop and waits for the current run to end.
+func (m *Manager) Stop() {
+	close(m.stop)
+	m.wg.Wait()
+}
+
+func (m *Manager) runCycle(ctx context.Context) {
+	sources, err := m.db.ListSources(ctx)
+	if err != nil {
+		m.log.Error("failed to list sources", "error", err)
+		return
+	}
+	for _, source := range sources {
+		if models.NormalizeSourceType(source.SourceType) != models.SourceTypeClickHouse {
+			continue
+		}
+		select {
+		case <-m.stop:
+			return
+		default:
+		}
+		runCtx, cancel := context.WithTimeout(ctx, snapshotTimeout)
+		if err := m.snapshot(runCtx, source); err != nil {
+			m.log.Warn("source stats snapshot failed", "source_id", source.ID, "error", err)
+		}
+		cancel()
+	}
+
+	cutoff := m.now().UTC().AddDate(0, 0, -m.cfg.RetentionDays).Format(models.SourceStatsDateLayout)
+	pruned, err := m.db.DeleteSourceStatsSnapshotsBefore(ctx, cutoff)
+	if err != nil {
+		m.log.Error("failed to prune source stats snapshots", "error", err)
+		return
+	}
+	if pruned > 0 {
+		m.log.Debug("pruned source stats snapshots", "count", pruned, "before", cutoff)
+	}
+}
+
+// snapshot records the source's storage as today's snapshot, replacing an
+// earlier one from the same day.
+func (m *Manager) snapshot(ctx context.Context, source *models.Source) error {
+	client, err := m.clientFor(source.ID)
+	if err != nil {
+		return fmt.Errorf("error getting database connection for source %d: %w", source.ID, err)
+	}
+	storage, err := client.TableStorage(ctx, source.Connection.Database, source.Connection.TableName)
+	if err != nil {
+		return err
+	}
+
+	now := m.now().UTC()
+	return m.db.UpsertSourceStatsSnapshot(ctx, &models.SourceStatsSnapshot{
+		SourceID:          source.ID,
+		SnapshotDate:      now.Format(models.SourceStatsDateLayout),
+		Rows:              int64(storage.Rows),              //nolint:gosec // row counts fit in int64
+		PartCount:         int64(storage.PartCount),         //nolint:gosec // part counts fit in int64
+		CompressedBytes:   int64(storage.CompressedBytes),   //nolint:gosec // byte counts fit in int64
+		UncompressedBytes: int64(storage.UncompressedBytes), //nolint:gosec // byte counts fit in int64
+		Columns:           storage.Columns,
+		CapturedAt:        now,
+	})
+}
+
+// Trend returns the source's snapshots from the last days days, oldest first.
+// days must be between 1 and the configured retention.
+func (m *Manager) Trend(ctx context.Context, sourceID models.SourceID, days int) (*models.SourceStatsTrend, error) {
+	if days < 1 || days > m.cfg.RetentionDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidRequest, m.cfg.RetentionDays)
+	}
+	if _, err := m.db.GetSource(ctx, sourceID); err != nil {
+		return nil, err
+	}
+	since := m.now().UTC().AddDate(0, 0, -(days - 1)).Format(models.SourceStatsDateLayout)
+	snapshots, err := m.db.ListSourceStatsSnapshots(ctx, sourceID, since)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewSourceStatsTrend(sourceID, snapshots), nil
+}
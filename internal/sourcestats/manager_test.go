@@ -0,0 +1,131 @@
+package sourcestats
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/clickhouse"
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/store/sqlite"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// fakeBackend reports a fixed storage footprint.
+type fakeBackend struct {
+	storage *clickhouse.TableStorage
+	err     error
+}
+
+func (f *fakeBackend) TableStorage(context.Context, string, string) (*clickhouse.TableStorage, error) {
+	return f.storage, f.err
+}
+
+func newTestManager(t *testing.T, now *time.Time) (*Manager, *sqlite.DB, *fakeBackend, *models.Source) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := sqlite.New(context.Background(), sqlite.Options{
+		Logger: logger,
+		Config: config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")},
+	})
+	if err != nil {
+		t.Fatalf("sqlite.New failed: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	source := &models.Source{
+		Name:        "app",
+		MetaTSField: "timestamp",
+		Connection:  models.ConnectionInfo{Host: "ch:9000", Username: "default", Database: "logs", TableName: "app"},
+	}
+	if err := db.CreateSource(context.Background(), source); err != nil {
+		t.Fatalf("CreateSource: %v", err)
+	}
+
+	fake := &fakeBackend{}
+	m := NewManager(Options{
+		Config: config.SourceStatsConfig{Enabled: true, Interval: time.Hour, RetentionDays: 3},
+		DB:     db,
+		Logger: logger,
+	})
+	m.clientFor = func(models.SourceID) (backend, error) { return fake, nil }
+	m.now = func() time.Time { return *now }
+	return m, db, fake, source
+}
+
+func TestRunCycleRecordsDailySnapshots(t *testing.T) {
+	now := time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC)
+	m, db, fake, source := newTestManager(t, &now)
+	ctx := context.Background()
+
+	fake.storage = &clickhouse.TableStorage{
+		Rows: 1000, PartCount: 4, CompressedBytes: 100, UncompressedBytes: 400,
+		Columns: []models.ColumnStorageStats{{Name: "msg", CompressedBytes: 80, UncompressedBytes: 360}},
+	}
+	m.runCycle(ctx)
+
+	// A second run on the same day replaces that day's snapshot.
+	fake.storage = &clickhouse.TableStorage{Rows: 1500, PartCount: 5, CompressedBytes: 120, UncompressedBytes: 600}
+	now = now.Add(6 * time.Hour)
+	m.runCycle(ctx)
+
+	now = now.Add(24 * time.Hour)
+	fake.storage = &clickhouse.TableStorage{Rows: 3000, PartCount: 6, CompressedBytes: 200, UncompressedBytes: 1200}
+	m.runCycle(ctx)
+
+	trend, err := m.Trend(ctx, source.ID, 3)
+	if err != nil {
+		t.Fatalf("Trend: %v", err)
+	}
+	if len(trend.Snapshots) != 2 {
+		t.Fatalf("snapshots = %+v, want two days", trend.Snapshots)
+	}
+	if trend.Snapshots[0].SnapshotDate != "2026-03-10" || trend.Snapshots[0].Rows != 1500 {
+		t.Errorf("first snapshot = %+v, want the later 2026-03-10 run", trend.Snapshots[0])
+	}
+	if trend.RowsChange != 1500 || trend.CompressedBytesChange != 80 || trend.CompressionRatioChange != 1 {
+		t.Errorf("unexpected changes: %+v", trend)
+	}
+
+	// Failed snapshots leave the stored ones alone.
+	fake.err = errors.New("connection refused")
+	now = now.Add(24 * time.Hour)
+	m.runCycle(ctx)
+	if snapshots, _ := db.ListSourceStatsSnapshots(ctx, source.ID, "2026-03-01"); len(snapshots) != 2 {
+		t.Errorf("snapshots after failed run = %d, want 2", len(snapshots))
+	}
+
+	// Three days of retention on 2026-03-14 drops 2026-03-10.
+	now = now.Add(48 * time.Hour)
+	m.runCycle(ctx)
+	snapshots, err := db.ListSourceStatsSnapshots(ctx, source.ID, "2026-03-01")
+	if err != nil {
+		t.Fatalf("ListSourceStatsSnapshots: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].SnapshotDate != "2026-03-11" {
+		t.Errorf("snapshots after pruning = %+v, want only 2026-03-11", snapshots)
+	}
+}
+
+func TestTrendValidatesRange(t *testing.T) {
+	now := time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC)
+	m, _, _, source := newTestManager(t, &now)
+	ctx := context.Background()
+
+	for _, days := range []int{0, 4} {
+		if _, err := m.Trend(ctx, source.ID, days); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("days=%d: err = %v, want ErrInvalidRequest", days, err)
+		}
+	}
+	if _, err := m.Trend(ctx, 9999, 1); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("missing source: err = %v, want ErrNotFound", err)
+	}
+	trend, err := m.Trend(ctx, source.ID, 1)
+	if err != nil || len(trend.Snapshots) != 0 {
+		t.Errorf("empty trend = %+v, %v", trend, err)
+	}
+}
@@ -0,0 +1,124 @@
+package sourcetransfer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Supported encryption parameters.
+const (
+	cipherAES256GCM = "aes-256-gcm"
+	kdfScrypt       = "scrypt"
+)
+
+// scrypt cost parameters (the package's recommended interactive values).
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+const saltBytes = 16
+
+// MinPassphraseLength is the shortest passphrase accepted for an export.
+const MinPassphraseLength = 12
+
+// ErrShortPassphrase rejects an export passphrase under MinPassphraseLength.
+var ErrShortPassphrase = fmt.Errorf("passphrase must be at least %d characters", MinPassphraseLength)
+
+// ErrWrongPassphrase means sealed credentials could not be opened: the
+// passphrase differs from the export's, or the document was altered.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted credentials")
+
+// Encryption records how a document's credentials were sealed.
+type Encryption struct {
+	Cipher string `json:"cipher" yaml:"cipher"`
+	KDF    string `json:"kdf" yaml:"kdf"`
+	// Salt is the base64-encoded scrypt salt.
+	Salt string `json:"salt" yaml:"salt"`
+}
+
+// sealer seals and opens one document's credentials.
+type sealer struct {
+	aead cipher.AEAD
+}
+
+// newEncryption picks a fresh salt for an export.
+func newEncryption(passphrase string) (*Encryption, *sealer, error) {
+	if len(passphrase) < MinPassphraseLength {
+		return nil, nil, ErrShortPassphrase
+	}
+	salt := make([]byte, saltBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	enc := &Encryption{Cipher: cipherAES256GCM, KDF: kdfScrypt, Salt: base64.StdEncoding.EncodeToString(salt)}
+	s, err := enc.sealer(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	return enc, s, nil
+}
+
+func (e *Encryption) sealer(passphrase string) (*sealer, error) {
+	if e.Cipher != cipherAES256GCM || e.KDF != kdfScrypt {
+		return nil, fmt.Errorf("%w: unsupported encryption %s/%s", ErrInvalidDocument, e.Cipher, e.KDF)
+	}
+	salt, err := base64.StdEncoding.DecodeString(e.Salt)
+	if err != nil || len(salt) == 0 {
+		return nil, fmt.Errorf("%w: invalid encryption salt", ErrInvalidDocument)
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sealer{aead: aead}, nil
+}
+
+// seal encrypts secrets, bound to the source name so sealed credentials can't
+// be moved to another source, and returns base64(nonce || ciphertext).
+func (s *sealer) seal(name string, secrets map[string]any) (string, error) {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, plaintext, []byte(name))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open reverses seal.
+func (s *sealer) open(name, sealed string) (map[string]any, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(raw) < s.aead.NonceSize() {
+		return nil, fmt.Errorf("%w: source %q has malformed secrets", ErrInvalidDocument, name)
+	}
+	nonce, ciphertext := raw[:s.aead.NonceSize()], raw[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("source %q: %w", name, ErrWrongPassphrase)
+	}
+	var secrets map[string]any
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("%w: source %q has malformed secrets", ErrInvalidDocument, name)
+	}
+	return secrets, nil
+}
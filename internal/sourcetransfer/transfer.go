@@ -0,0 +1,406 @@
+// Package sourcetransfer exports source definitions to a YAML or JSON
+// document and imports them into another Logchef instance, for disaster
+// recovery and for promoting sources between deployments.
+//
+// Credentials (passwords, tokens, TLS client keys, VictoriaLogs headers) are
+// left out of an export unless a passphrase is given, in which case each
+// source's credentials are sealed with AES-256-GCM under a key derived from
+// the passphrase with scrypt. The same passphrase is needed to import them.
+package sourcetransfer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/events"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// DocumentVersion is the version of the document format written by Export.
+const DocumentVersion = 1
+
+// Document formats.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// ErrInvalidDocument wraps errors in an import document or request.
+var ErrInvalidDocument = errors.New("invalid source document")
+
+// Document is an exported set of sources.
+type Document struct {
+	Version    int         `json:"version" yaml:"version"`
+	ExportedAt time.Time   `json:"exported_at" yaml:"exported_at"`
+	Encryption *Encryption `json:"encryption,omitempty" yaml:"encryption,omitempty"`
+	Sources    []Source    `json:"sources" yaml:"sources"`
+}
+
+// Source is one source definition. Secrets holds the sealed credentials
+// removed from Connection, and is empty when the export had no passphrase or
+// the source has no credentials.
+type Source struct {
+	Name              string                   `json:"name" yaml:"name"`
+	SourceType        models.SourceType        `json:"source_type" yaml:"source_type"`
+	Description       string                   `json:"description,omitempty" yaml:"description,omitempty"`
+	TTLDays           int                      `json:"ttl_days" yaml:"ttl_days"`
+	MetaTSField       string                   `json:"meta_ts_field" yaml:"meta_ts_field"`
+	MetaSeverityField string                   `json:"meta_severity_field,omitempty" yaml:"meta_severity_field,omitempty"`
+	Tags              models.SourceTags        `json:"tags,omitempty" yaml:"tags,omitempty"`
+	ExtractionRules   models.ExtractionRules   `json:"extraction_rules,omitempty" yaml:"extraction_rules,omitempty"`
+	TraceCorrelation  *models.TraceCorrelation `json:"trace_correlation,omitempty" yaml:"trace_correlation,omitempty"`
+	MaskedColumns     models.MaskedColumns     `json:"masked_columns,omitempty" yaml:"masked_columns,omitempty"`
+	Connection        map[string]any           `json:"connection" yaml:"connection"`
+	Secrets           string                   `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+}
+
+// secretPaths lists the dotted connection keys holding credentials, by source
+// type.
+var secretPaths = map[models.SourceType][]string{
+	models.SourceTypeClickHouse:   {"password", "tls_client_key"},
+	models.SourceTypeVictoriaLogs: {"auth.password", "auth.token", "headers"},
+}
+
+// Export builds a document of every source. With a passphrase, credentials
+// are sealed into each source's Secrets; without one they are dropped.
+func Export(ctx context.Context, db store.Store, passphrase string) (*Document, error) {
+	sources, err := db.ListSources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sources: %w", err)
+	}
+
+	doc := &Document{
+		Version:    DocumentVersion,
+		ExportedAt: time.Now().UTC(),
+		Sources:    make([]Source, 0, len(sources)),
+	}
+	var sealer *sealer
+	if passphrase != "" {
+		doc.Encryption, sealer, err = newEncryption(passphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, src := range sources {
+		sourceType := models.NormalizeSourceType(src.SourceType)
+		var conn map[string]any
+		if err := json.Unmarshal(src.ConnectionConfig, &conn); err != nil {
+			return nil, fmt.Errorf("failed to decode connection of source %q: %w", src.Name, err)
+		}
+		integralNumbers(conn)
+
+		exported := Source{
+			Name:              src.Name,
+			SourceType:        sourceType,
+			Description:       src.Description,
+			TTLDays:           src.TTLDays,
+			MetaTSField:       src.MetaTSField,
+			MetaSeverityField: src.MetaSeverityField,
+			Tags:              src.Tags,
+			ExtractionRules:   src.ExtractionRules,
+			TraceCorrelation:  src.TraceCorrelation,
+			MaskedColumns:     src.MaskedColumns,
+			Connection:        conn,
+		}
+		secrets := extractSecrets(conn, secretPaths[sourceType])
+		if sealer != nil && len(secrets) > 0 {
+			if exported.Secrets, err = sealer.seal(src.Name, secrets); err != nil {
+				return nil, fmt.Errorf("failed to seal credentials of source %q: %w", src.Name, err)
+			}
+		}
+		doc.Sources = append(doc.Sources, exported)
+	}
+	return doc, nil
+}
+
+// Marshal encodes doc in format, FormatYAML or FormatJSON.
+func Marshal(doc *Document, format string) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		return yaml.Marshal(doc)
+	case FormatJSON:
+		return json.MarshalIndent(doc, "", "  ")
+	default:
+		return nil, fmt.Errorf("%w: format must be %q or %q", ErrInvalidDocument, FormatYAML, FormatJSON)
+	}
+}
+
+// Parse decodes a YAML or JSON document (JSON is valid YAML) and checks its
+// version and sources.
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	if doc.Version != DocumentVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d (expected %d)", ErrInvalidDocument, doc.Version, DocumentVersion)
+	}
+	for i, src := range doc.Sources {
+		if strings.TrimSpace(src.Name) == "" {
+			return nil, fmt.Errorf("%w: sources[%d]: name is required", ErrInvalidDocument, i)
+		}
+		if len(src.Connection) == 0 {
+			return nil, fmt.Errorf("%w: source %q: connection is required", ErrInvalidDocument, src.Name)
+		}
+		if src.Secrets != "" && doc.Encryption == nil {
+			return nil, fmt.Errorf("%w: source %q has secrets but the document has no encryption block", ErrInvalidDocument, src.Name)
+		}
+	}
+	return &doc, nil
+}
+
+// Import statuses of a source.
+const (
+	StatusCreated     = "created"
+	StatusWouldCreate = "would_create"
+	StatusExists      = "exists"
+	StatusFailed      = "failed"
+)
+
+// ImportOptions control an import.
+type ImportOptions struct {
+	// Passphrase opens the document's sealed credentials. It is required
+	// when any source has them.
+	Passphrase string
+	// DryRun reports what would be created without connecting to or saving
+	// anything.
+	DryRun bool
+}
+
+// ImportedSource is the outcome for one source of a document.
+type ImportedSource struct {
+	Name     string          `json:"name"`
+	Status   string          `json:"status"`
+	SourceID models.SourceID `json:"source_id,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// ImportResult summarises an import.
+type ImportResult struct {
+	DryRun  bool             `json:"dry_run"`
+	Created int              `json:"created"`
+	Exists  int              `json:"exists"`
+	Failed  int              `json:"failed"`
+	Sources []ImportedSource `json:"sources"`
+}
+
+// Import creates the document's sources. A source whose connection already
+// backs a source (same identity: host, database and table, or VictoriaLogs
+// URL, tenant and scope) is reported as existing and left unchanged. Each
+// source is created on its own, so one that fails to validate or connect
+// doesn't stop the rest. A wrong or missing passphrase fails the whole import
+// before anything is created.
+func Import(ctx context.Context, db store.Store, ds *datasource.Service, doc *Document, opts ImportOptions) (*ImportResult, error) {
+	secrets, err := openSecrets(doc, opts.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{DryRun: opts.DryRun, Sources: make([]ImportedSource, 0, len(doc.Sources))}
+	for i, src := range doc.Sources {
+		outcome := importSource(ctx, db, ds, src, secrets[i], opts.DryRun)
+		switch outcome.Status {
+		case StatusCreated, StatusWouldCreate:
+			result.Created++
+		case StatusExists:
+			result.Exists++
+		case StatusFailed:
+			result.Failed++
+		}
+		result.Sources = append(result.Sources, outcome)
+	}
+	return result, nil
+}
+
+// openSecrets unseals every source's credentials up front, indexed like
+// doc.Sources.
+func openSecrets(doc *Document, passphrase string) ([]map[string]any, error) {
+	secrets := make([]map[string]any, len(doc.Sources))
+	var opener *sealer
+	for i, src := range doc.Sources {
+		if src.Secrets == "" {
+			continue
+		}
+		if opener == nil {
+			if passphrase == "" {
+				return nil, fmt.Errorf("%w: the document has encrypted credentials; a passphrase is required", ErrInvalidDocument)
+			}
+			var err error
+			if opener, err = doc.Encryption.sealer(passphrase); err != nil {
+				return nil, err
+			}
+		}
+		opened, err := opener.open(src.Name, src.Secrets)
+		if err != nil {
+			return nil, err
+		}
+		secrets[i] = opened
+	}
+	return secrets, nil
+}
+
+func importSource(ctx context.Context, db store.Store, ds *datasource.Service, src Source, secrets map[string]any, dryRun bool) ImportedSource {
+	outcome := ImportedSource{Name: src.Name}
+	fail := func(err error) ImportedSource {
+		outcome.Status = StatusFailed
+		outcome.Error = err.Error()
+		return outcome
+	}
+
+	connection, err := connectionWithSecrets(src.Connection, secrets)
+	if err != nil {
+		return fail(fmt.Errorf("encode connection: %w", err))
+	}
+	sourceType := models.NormalizeSourceType(src.SourceType)
+	identityKey, err := models.BuildIdentityKey(sourceType, connection)
+	if err != nil {
+		return fail(err)
+	}
+	existing, err := db.GetSourceByIdentityKey(ctx, identityKey)
+	switch {
+	case err == nil:
+		outcome.Status = StatusExists
+		outcome.SourceID = existing.ID
+		return outcome
+	case !errors.Is(err, models.ErrNotFound):
+		return fail(err)
+	}
+	if dryRun {
+		outcome.Status = StatusWouldCreate
+		return outcome
+	}
+
+	created, err := ds.CreateSource(ctx, &models.CreateSourceRequest{
+		Name:              src.Name,
+		SourceType:        sourceType,
+		MetaTSField:       src.MetaTSField,
+		MetaSeverityField: src.MetaSeverityField,
+		Connection:        connection,
+		Description:       src.Description,
+		TTLDays:           src.TTLDays,
+		Tags:              src.Tags,
+		ExtractionRules:   src.ExtractionRules,
+		TraceCorrelation:  src.TraceCorrelation,
+		MaskedColumns:     src.MaskedColumns,
+	})
+	if err != nil {
+		if errors.Is(err, datasource.ErrSourceAlreadyExists) {
+			outcome.Status = StatusExists
+			return outcome
+		}
+		return fail(err)
+	}
+	events.Publish(models.EventSourceCreated, models.SourceEventData{
+		SourceID: created.ID, Name: created.Name, SourceType: created.SourceType,
+	})
+	outcome.Status = StatusCreated
+	outcome.SourceID = created.ID
+	return outcome
+}
+
+// connectionWithSecrets encodes conn with secrets restored, leaving conn
+// itself unchanged.
+func connectionWithSecrets(conn map[string]any, secrets map[string]any) (json.RawMessage, error) {
+	if len(secrets) == 0 {
+		return json.Marshal(conn)
+	}
+	payload, err := json.Marshal(conn)
+	if err != nil {
+		return nil, err
+	}
+	var merged map[string]any
+	if err := json.Unmarshal(payload, &merged); err != nil {
+		return nil, err
+	}
+	restoreSecrets(merged, secrets)
+	return json.Marshal(merged)
+}
+
+// extractSecrets removes the non-empty values at paths from conn and returns
+// them keyed by path.
+func extractSecrets(conn map[string]any, paths []string) map[string]any {
+	secrets := map[string]any{}
+	for _, path := range paths {
+		parent, key := walk(conn, path, false)
+		if parent == nil {
+			continue
+		}
+		value, ok := parent[key]
+		if !ok {
+			continue
+		}
+		delete(parent, key)
+		if !isEmpty(value) {
+			secrets[path] = value
+		}
+	}
+	return secrets
+}
+
+// restoreSecrets puts secrets extracted by extractSecrets back into conn.
+func restoreSecrets(conn map[string]any, secrets map[string]any) {
+	for path, value := range secrets {
+		if parent, key := walk(conn, path, true); parent != nil {
+			parent[key] = value
+		}
+	}
+}
+
+// walk returns the map holding the last key of a dotted path and that key.
+// With create, missing intermediate maps are added; otherwise parent is nil
+// when one is missing.
+func walk(conn map[string]any, path string, create bool) (parent map[string]any, key string) {
+	keys := strings.Split(path, ".")
+	parent = conn
+	for _, k := range keys[:len(keys)-1] {
+		next, ok := parent[k].(map[string]any)
+		if !ok {
+			if !create {
+				return nil, ""
+			}
+			next = map[string]any{}
+			parent[k] = next
+		}
+		parent = next
+	}
+	return parent, keys[len(keys)-1]
+}
+
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case map[string]any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// integralNumbers turns whole float64 values decoded from JSON into int64, so
+// YAML writes 1000000 rather than 1e+06, which would not decode back into an
+// integer setting.
+func integralNumbers(value map[string]any) {
+	for key, v := range value {
+		switch v := v.(type) {
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+				value[key] = int64(v)
+			}
+		case map[string]any:
+			integralNumbers(v)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package sourcetransfer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/store/sqlite"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+const passphrase = "correct horse battery"
+
+func newTestDB(t *testing.T) *sqlite.DB {
+	t.Helper()
+	db, err := sqlite.New(context.Background(), sqlite.Options{
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Config: config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "test.db")},
+	})
+	if err != nil {
+		t.Fatalf("sqlite.New failed: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+// seedSources adds a ClickHouse source with a password and a VictoriaLogs
+// source with a bearer token.
+func seedSources(t *testing.T, db *sqlite.DB) {
+	t.Helper()
+	ctx := context.Background()
+	maxRows := int64(1_000_000)
+	sources := []*models.Source{
+		{
+			Name:        "app",
+			SourceType:  models.SourceTypeClickHouse,
+			MetaTSField: "timestamp",
+			TTLDays:     30,
+			Tags:        models.SourceTags{"env": "prod"},
+			Connection: models.ConnectionInfo{
+				Host: "ch:9000", Username: "reader", Password: "ch-secret", Database: "logs", TableName: "app",
+				Settings: &models.ClickHouseQuerySettings{MaxResultRows: &maxRows},
+			},
+		},
+		{
+			Name:             "edge",
+			SourceType:       models.SourceTypeVictoriaLogs,
+			MetaTSField:      "_time",
+			ConnectionConfig: []byte(`{"base_url":"http://vl:9428","auth":{"mode":"bearer","token":"vl-secret"}}`),
+		},
+	}
+	for _, src := range sources {
+		if err := db.CreateSource(ctx, src); err != nil {
+			t.Fatalf("CreateSource(%s): %v", src.Name, err)
+		}
+	}
+}
+
+func TestExportDropsCredentialsWithoutPassphrase(t *testing.T) {
+	db := newTestDB(t)
+	seedSources(t, db)
+
+	doc, err := Export(context.Background(), db, "")
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	out, err := Marshal(doc, FormatYAML)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	text := string(out)
+	if strings.Contains(text, "ch-secret") || strings.Contains(text, "vl-secret") || strings.Contains(text, "secrets:") {
+		t.Errorf("export leaked credentials:\n%s", text)
+	}
+	if !strings.Contains(text, "max_result_rows: 1000000") {
+		t.Errorf("export lost the integer setting:\n%s", text)
+	}
+	if doc.Encryption != nil {
+		t.Errorf("Encryption = %+v, want nil", doc.Encryption)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := newTestDB(t)
+	seedSources(t, src)
+	ctx := context.Background()
+
+	if _, err := Export(ctx, src, "short"); !errors.Is(err, ErrShortPassphrase) {
+		t.Fatalf("Export with a short passphrase err = %v, want ErrShortPassphrase", err)
+	}
+	exported, err := Export(ctx, src, passphrase)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	for _, format := range []string{FormatYAML, FormatJSON} {
+		out, err := Marshal(exported, format)
+		if err != nil {
+			t.Fatalf("Marshal(%s): %v", format, err)
+		}
+		if strings.Contains(string(out), "ch-secret") {
+			t.Fatalf("%s export holds a plaintext password", format)
+		}
+		doc, err := Parse(out)
+		if err != nil {
+			t.Fatalf("Parse(%s): %v", format, err)
+		}
+
+		secrets, err := openSecrets(doc, passphrase)
+		if err != nil {
+			t.Fatalf("openSecrets(%s): %v", format, err)
+		}
+		byName := map[string]map[string]any{}
+		for i, s := range doc.Sources {
+			byName[s.Name] = secrets[i]
+		}
+		if byName["app"]["password"] != "ch-secret" || byName["edge"]["auth.token"] != "vl-secret" {
+			t.Errorf("%s secrets = %v", format, byName)
+		}
+		if _, err := openSecrets(doc, "not the passphrase"); !errors.Is(err, ErrWrongPassphrase) {
+			t.Errorf("%s: wrong passphrase err = %v, want ErrWrongPassphrase", format, err)
+		}
+		if _, err := Import(ctx, src, nil, doc, ImportOptions{DryRun: true}); !errors.Is(err, ErrInvalidDocument) {
+			t.Errorf("%s: import without passphrase err = %v, want ErrInvalidDocument", format, err)
+		}
+
+		// Into an empty instance both would be created; into the source
+		// instance both already exist.
+		result, err := Import(ctx, newTestDB(t), nil, doc, ImportOptions{Passphrase: passphrase, DryRun: true})
+		if err != nil {
+			t.Fatalf("Import(%s) into an empty instance: %v", format, err)
+		}
+		if result.Created != 2 || result.Sources[0].Status != StatusWouldCreate || result.Sources[1].Status != StatusWouldCreate {
+			t.Errorf("%s: import into an empty instance = %+v", format, result)
+		}
+		result, err = Import(ctx, src, nil, doc, ImportOptions{Passphrase: passphrase, DryRun: true})
+		if err != nil {
+			t.Fatalf("Import(%s) into the source instance: %v", format, err)
+		}
+		if result.Exists != 2 || result.Sources[0].SourceID == 0 || result.Sources[1].SourceID == 0 {
+			t.Errorf("%s: import into the source instance = %+v", format, result)
+		}
+	}
+}
+
+func TestParseRejectsInvalidDocuments(t *testing.T) {
+	for name, doc := range map[string]string{
+		"not a document":  "- just\n- a list\n",
+		"unknown version": "version: 2\nsources: []\n",
+		"missing name":    "version: 1\nsources:\n  - connection: {host: ch}\n",
+		"secrets without encryption": "version: 1\nsources:\n" +
+			"  - name: app\n    connection: {host: ch}\n    secrets: abc\n",
+	} {
+		if _, err := Parse([]byte(doc)); !errors.Is(err, ErrInvalidDocument) {
+			t.Errorf("%s: Parse err = %v, want ErrInvalidDocument", name, err)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 
@@ -52,11 +53,14 @@ func (s *Store) UpdateAlert(ctx context.Context, alert *models.Alert) error {
 		ThresholdOperator:    createParams.ThresholdOperator,
 		ThresholdValue:       createParams.ThresholdValue,
 		FrequencySeconds:     createParams.FrequencySeconds,
+		ForSeconds:           createParams.ForSeconds,
 		Severity:             createParams.Severity,
 		LabelsJson:           createParams.LabelsJson,
 		AnnotationsJson:      createParams.AnnotationsJson,
 		RecipientUserIdsJson: createParams.RecipientUserIdsJson,
 		WebhookUrlsJson:      createParams.WebhookUrlsJson,
+		ChannelsJson:         createParams.ChannelsJson,
+		SloID:                createParams.SloID,
 		GeneratorUrl:         createParams.GeneratorUrl,
 		IsActive:             createParams.IsActive,
 		ID:                   int64(alert.ID),
@@ -128,6 +132,40 @@ func (s *Store) MarkAlertEvaluated(ctx context.Context, alertID models.AlertID)
 	return nil
 }
 
+// AcquireAlertLease claims the alert's evaluation lease for holder until
+// expiresAt and reports whether holder now has it.
+func (s *Store) AcquireAlertLease(ctx context.Context, alertID models.AlertID, holder string, now, expiresAt time.Time) (bool, error) {
+	n, err := s.q.AcquireAlertLease(ctx, sqlc.AcquireAlertLeaseParams{
+		AlertID:   int64(alertID),
+		Holder:    holder,
+		ExpiresAt: ts(expiresAt),
+		Now:       ts(now),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire alert lease: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ReleaseAlertLeases drops every evaluation lease holder has.
+func (s *Store) ReleaseAlertLeases(ctx context.Context, holder string) error {
+	if err := s.q.DeleteAlertLeasesByHolder(ctx, holder); err != nil {
+		return fmt.Errorf("failed to release alert leases: %w", err)
+	}
+	return nil
+}
+
+// MarkAlertPending records a breach of an alert that is waiting out its
+// for_seconds, returning when the current run of breaches started and how
+// many evaluations it has lasted.
+func (s *Store) MarkAlertPending(ctx context.Context, alertID models.AlertID) (time.Time, int, error) {
+	row, err := s.q.MarkAlertPending(ctx, int64(alertID))
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("failed to mark alert pending: %w", err)
+	}
+	return row.PendingSince.Time, int(row.BreachCount), nil
+}
+
 // MarkAlertTriggered updates state when an alert fires.
 func (s *Store) MarkAlertTriggered(ctx context.Context, alertID models.AlertID) error {
 	if err := s.q.MarkAlertTriggered(ctx, int64(alertID)); err != nil {
@@ -220,6 +258,27 @@ func (s *Store) ListAlertHistory(ctx context.Context, alertID models.AlertID, li
 	return history, nil
 }
 
+// ListAlertHistorySince returns the alert's history entries that were still
+// open at or after since, oldest first.
+func (s *Store) ListAlertHistorySince(ctx context.Context, alertID models.AlertID, since time.Time) ([]*models.AlertHistoryEntry, error) {
+	rows, err := s.q.ListAlertHistorySince(ctx, sqlc.ListAlertHistorySinceParams{
+		AlertID: int64(alertID),
+		Since:   ts(since),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert history: %w", err)
+	}
+	history := make([]*models.AlertHistoryEntry, 0, len(rows))
+	for i := range rows {
+		entry, err := alertHistoryFromSQLC(rows[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode alert history: %w", err)
+		}
+		history = append(history, entry)
+	}
+	return history, nil
+}
+
 // PruneAlertHistory keeps the most recent N entries for an alert.
 func (s *Store) PruneAlertHistory(ctx context.Context, alertID models.AlertID, keep int) error {
 	if keep <= 0 {
@@ -254,6 +313,10 @@ func alertCreateParams(alert *models.Alert) (sqlc.CreateAlertParams, error) {
 	if err != nil {
 		return sqlc.CreateAlertParams{}, fmt.Errorf("failed to marshal webhook URLs: %w", err)
 	}
+	channelsJSON, err := marshalAlertChannels(alert.Channels)
+	if err != nil {
+		return sqlc.CreateAlertParams{}, fmt.Errorf("failed to marshal channels: %w", err)
+	}
 
 	params := sqlc.CreateAlertParams{
 		SourceID:             int64(alert.SourceID),
@@ -267,17 +330,22 @@ func alertCreateParams(alert *models.Alert) (sqlc.CreateAlertParams, error) {
 		ThresholdOperator:    string(alert.ThresholdOperator),
 		ThresholdValue:       alert.ThresholdValue,
 		FrequencySeconds:     int64(alert.FrequencySeconds),
+		ForSeconds:           int64(alert.ForSeconds),
 		Severity:             string(alert.Severity),
 		LabelsJson:           text(labelsJSON),
 		AnnotationsJson:      text(annotationsJSON),
 		RecipientUserIdsJson: text(recipientUserIDsJSON),
 		WebhookUrlsJson:      text(webhookURLsJSON),
+		ChannelsJson:         text(channelsJSON),
 		GeneratorUrl:         text(alert.GeneratorURL),
 		IsActive:             alert.IsActive,
 	}
 	if alert.CreatedBy != nil {
 		params.CreatedBy = int8Val(int64(*alert.CreatedBy))
 	}
+	if alert.SLOID != nil {
+		params.SloID = int8Val(int64(*alert.SLOID))
+	}
 	return params, nil
 }
 
@@ -311,6 +379,10 @@ func alertFromSQLC(row sqlc.Alert) (*models.Alert, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode webhook URLs: %w", err)
 	}
+	channels, err := unmarshalAlertChannels(row.ChannelsJson)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode channels: %w", err)
+	}
 
 	alert := &models.Alert{
 		ID:                models.AlertID(row.ID),
@@ -325,20 +397,32 @@ func alertFromSQLC(row sqlc.Alert) (*models.Alert, error) {
 		ThresholdOperator: models.AlertThresholdOperator(row.ThresholdOperator),
 		ThresholdValue:    row.ThresholdValue,
 		FrequencySeconds:  int(row.FrequencySeconds),
+		ForSeconds:        int(row.ForSeconds),
 		Severity:          models.AlertSeverity(row.Severity),
 		Labels:            labels,
 		Annotations:       annotations,
 		RecipientUserIDs:  recipientUserIDs,
 		WebhookURLs:       webhookURLs,
+		Channels:          channels,
 		GeneratorURL:      textStr(row.GeneratorUrl),
 		IsActive:          row.IsActive,
 		LastState:         models.AlertState(row.LastState),
+		BreachCount:       int(row.BreachCount),
+		Managed:           row.Managed,
 		LastEvaluatedAt:   tsPtr(row.LastEvaluatedAt),
 		LastTriggeredAt:   tsPtr(row.LastTriggeredAt),
+		PendingSince:      tsPtr(row.PendingSince),
 		CreatedBy:         userIDPtr(row.CreatedBy),
 		CreatedAt:         row.CreatedAt.Time,
 		UpdatedAt:         row.UpdatedAt.Time,
 	}
+	if alert.PendingSince != nil && alert.LastState == models.AlertStateResolved {
+		alert.LastState = models.AlertStatePending
+	}
+	if row.SloID.Valid {
+		sloID := models.SLOID(row.SloID.Int64)
+		alert.SLOID = &sloID
+	}
 	return alert, nil
 }
 
@@ -382,6 +466,14 @@ func unmarshalUserIDs(raw pgtype.Text) ([]models.UserID, error) {
 	return alertjson.Decode[[]models.UserID](textStr(raw))
 }
 
+func marshalAlertChannels(channels []models.AlertChannel) (string, error) {
+	return alertjson.Encode(channels, len(channels) == 0)
+}
+
+func unmarshalAlertChannels(raw pgtype.Text) ([]models.AlertChannel, error) {
+	return alertjson.Decode[[]models.AlertChannel](textStr(raw))
+}
+
 func marshalStringSlice(values []string) (string, error) {
 	return alertjson.Encode(values, len(values) == 0)
 }
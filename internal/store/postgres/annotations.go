@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store/alertjson"
+	"github.com/mr-karan/logchef/internal/store/postgres/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// CreateAnnotation inserts a new annotation and repopulates the model with the
+// persisted row (id and timestamps).
+func (s *Store) CreateAnnotation(ctx context.Context, annotation *models.Annotation) error {
+	if annotation == nil {
+		return fmt.Errorf("annotation payload is required")
+	}
+	tags, err := alertjson.Encode(annotation.Tags, len(annotation.Tags) == 0)
+	if err != nil {
+		return fmt.Errorf("error encoding annotation tags: %w", err)
+	}
+	params := sqlc.CreateAnnotationParams{
+		TeamID:   int64(annotation.TeamID),
+		Text:     annotation.Text,
+		TagsJson: tags,
+		StartsAt: ts(annotation.StartsAt),
+		EndsAt:   tsFromPtr(annotation.EndsAt),
+	}
+	if annotation.SourceID != nil {
+		params.SourceID = int8Val(int64(*annotation.SourceID))
+	}
+	if annotation.CreatedBy != nil {
+		params.CreatedBy = int8Val(int64(*annotation.CreatedBy))
+	}
+
+	id, err := s.q.CreateAnnotation(ctx, params)
+	if err != nil {
+		s.log.Error("failed to create annotation", "error", err, "team_id", annotation.TeamID)
+		return fmt.Errorf("error creating annotation: %w", err)
+	}
+
+	created, err := s.GetAnnotation(ctx, models.AnnotationID(id))
+	if err != nil {
+		return err
+	}
+	*annotation = *created
+	return nil
+}
+
+// GetAnnotation returns an annotation by id, or models.ErrNotFound if missing.
+func (s *Store) GetAnnotation(ctx context.Context, id models.AnnotationID) (*models.Annotation, error) {
+	row, err := s.q.GetAnnotation(ctx, int64(id))
+	if err != nil {
+		if notFound(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting annotation id %d: %w", id, err)
+	}
+	return mapAnnotationRow(row)
+}
+
+// ListAnnotationsByTeam returns a team's annotations, newest start first.
+func (s *Store) ListAnnotationsByTeam(ctx context.Context, teamID models.TeamID) ([]*models.Annotation, error) {
+	rows, err := s.q.ListAnnotationsByTeam(ctx, int64(teamID))
+	if err != nil {
+		s.log.Error("failed to list annotations", "error", err, "team_id", teamID)
+		return nil, fmt.Errorf("error listing annotations: %w", err)
+	}
+	return mapAnnotationRows(rows)
+}
+
+// ListAnnotationsInRange returns the team's annotations that overlap
+// [start, end] and are either team-wide or scoped to sourceID, oldest first.
+func (s *Store) ListAnnotationsInRange(ctx context.Context, teamID models.TeamID, sourceID models.SourceID, start, end time.Time) ([]*models.Annotation, error) {
+	rows, err := s.q.ListAnnotationsInRange(ctx, sqlc.ListAnnotationsInRangeParams{
+		TeamID:     int64(teamID),
+		SourceID:   int8Val(int64(sourceID)),
+		RangeEnd:   ts(end),
+		RangeStart: ts(start),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing annotations in range: %w", err)
+	}
+	return mapAnnotationRows(rows)
+}
+
+// UpdateAnnotation overwrites an annotation's note, scope and range. Returns
+// models.ErrNotFound when the id does not exist.
+func (s *Store) UpdateAnnotation(ctx context.Context, annotation *models.Annotation) error {
+	if annotation == nil {
+		return fmt.Errorf("annotation payload is required")
+	}
+	tags, err := alertjson.Encode(annotation.Tags, len(annotation.Tags) == 0)
+	if err != nil {
+		return fmt.Errorf("error encoding annotation tags: %w", err)
+	}
+	params := sqlc.UpdateAnnotationParams{
+		Text:     annotation.Text,
+		TagsJson: tags,
+		StartsAt: ts(annotation.StartsAt),
+		EndsAt:   tsFromPtr(annotation.EndsAt),
+		ID:       int64(annotation.ID),
+	}
+	if annotation.SourceID != nil {
+		params.SourceID = int8Val(int64(*annotation.SourceID))
+	}
+	if _, err := s.q.UpdateAnnotation(ctx, params); err != nil {
+		if notFound(err) {
+			return models.ErrNotFound
+		}
+		s.log.Error("failed to update annotation", "error", err, "annotation_id", annotation.ID)
+		return fmt.Errorf("error updating annotation: %w", err)
+	}
+	return nil
+}
+
+// DeleteAnnotation removes an annotation. Returns models.ErrNotFound when the
+// id does not exist.
+func (s *Store) DeleteAnnotation(ctx context.Context, id models.AnnotationID) error {
+	if _, err := s.q.DeleteAnnotation(ctx, int64(id)); err != nil {
+		if notFound(err) {
+			return models.ErrNotFound
+		}
+		s.log.Error("failed to delete annotation", "error", err, "annotation_id", id)
+		return fmt.Errorf("error deleting annotation: %w", err)
+	}
+	return nil
+}
+
+func mapAnnotationRows(rows []sqlc.Annotation) ([]*models.Annotation, error) {
+	annotations := make([]*models.Annotation, 0, len(rows))
+	for _, row := range rows {
+		annotation, err := mapAnnotationRow(row)
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations, nil
+}
+
+func mapAnnotationRow(row sqlc.Annotation) (*models.Annotation, error) {
+	tags, err := alertjson.Decode[[]string](row.TagsJson)
+	if err != nil {
+		return nil, fmt.Errorf("decoding tags of annotation %d: %w", row.ID, err)
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	annotation := &models.Annotation{
+		ID:       models.AnnotationID(row.ID),
+		TeamID:   models.TeamID(row.TeamID),
+		Text:     row.Text,
+		Tags:     tags,
+		StartsAt: row.StartsAt.Time,
+		EndsAt:   tsPtr(row.EndsAt),
+		Timestamps: models.Timestamps{
+			CreatedAt: row.CreatedAt.Time,
+			UpdatedAt: row.UpdatedAt.Time,
+		},
+	}
+	if row.SourceID.Valid {
+		id := models.SourceID(row.SourceID.Int64)
+		annotation.SourceID = &id
+	}
+	if row.CreatedBy.Valid {
+		uid := models.UserID(row.CreatedBy.Int64)
+		annotation.CreatedBy = &uid
+	}
+	return annotation, nil
+}
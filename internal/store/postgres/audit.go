@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store/postgres/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// InsertAuditEvent appends one audit event and populates its ID. A zero
+// CreatedAt is stamped with the current time.
+func (s *Store) InsertAuditEvent(ctx context.Context, event *models.AuditEvent) error {
+	if event == nil {
+		return fmt.Errorf("audit event is required")
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	event.CreatedAt = event.CreatedAt.UTC()
+
+	params := sqlc.InsertAuditEventParams{
+		UserEmail:    event.UserEmail,
+		Action:       string(event.Action),
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		IpAddress:    event.IPAddress,
+		Details:      text(string(event.Details)),
+		CreatedAt:    ts(event.CreatedAt),
+	}
+	if event.UserID != nil {
+		params.UserID = int8Val(int64(*event.UserID))
+	}
+	if event.TeamID != nil {
+		params.TeamID = int8Val(int64(*event.TeamID))
+	}
+
+	id, err := s.q.InsertAuditEvent(ctx, params)
+	if err != nil {
+		s.log.Error("failed to insert audit event", "error", err, "action", event.Action)
+		return fmt.Errorf("error inserting audit event: %w", err)
+	}
+	event.ID = id
+	return nil
+}
+
+// ListAuditEvents returns audit events matching filter, newest first.
+func (s *Store) ListAuditEvents(ctx context.Context, filter models.AuditEventFilter) ([]*models.AuditEvent, error) {
+	params := sqlc.ListAuditEventsParams{
+		Action:       text(string(filter.Action)),
+		ResourceType: text(filter.ResourceType),
+		Limit:        int32(filter.Limit),  //nolint:gosec // G115: limit is clamped by the caller
+		Offset:       int32(filter.Offset), //nolint:gosec // G115: offset is a small page offset
+	}
+	if filter.UserID != nil {
+		params.UserID = int8Val(int64(*filter.UserID))
+	}
+	if filter.TeamID != nil {
+		params.TeamID = int8Val(int64(*filter.TeamID))
+	}
+	if !filter.Since.IsZero() {
+		params.Since = ts(filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		params.Until = ts(filter.Until)
+	}
+
+	rows, err := s.q.ListAuditEvents(ctx, params)
+	if err != nil {
+		s.log.Error("failed to list audit events", "error", err)
+		return nil, fmt.Errorf("error listing audit events: %w", err)
+	}
+
+	events := make([]*models.AuditEvent, 0, len(rows))
+	for i := range rows {
+		r := rows[i]
+		event := &models.AuditEvent{
+			ID:           r.ID,
+			UserEmail:    r.UserEmail,
+			Action:       models.AuditAction(r.Action),
+			ResourceType: r.ResourceType,
+			ResourceID:   r.ResourceID,
+			IPAddress:    r.IpAddress,
+			CreatedAt:    r.CreatedAt.Time,
+		}
+		event.UserID = userIDPtr(r.UserID)
+		event.TeamID = teamIDPtr(r.TeamID)
+		if details := textStr(r.Details); details != "" {
+			event.Details = json.RawMessage(details)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
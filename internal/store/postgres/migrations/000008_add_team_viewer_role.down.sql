@@ -0,0 +1,6 @@
+-- Remove the 'viewer' role. Viewers are demoted to 'member' (the closest
+-- pre-existing role) so no memberships are lost.
+UPDATE team_members SET role = 'member' WHERE role = 'viewer';
+ALTER TABLE team_members DROP CONSTRAINT team_members_role_check;
+ALTER TABLE team_members ADD CONSTRAINT team_members_role_check
+    CHECK (role IN ('admin', 'member', 'editor'));
@@ -0,0 +1,4 @@
+-- Add the read-only 'viewer' role to the team_members role constraint.
+ALTER TABLE team_members DROP CONSTRAINT team_members_role_check;
+ALTER TABLE team_members ADD CONSTRAINT team_members_role_check
+    CHECK (role IN ('admin', 'member', 'editor', 'viewer'));
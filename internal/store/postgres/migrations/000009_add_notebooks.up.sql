@@ -0,0 +1,15 @@
+-- Notebooks: team-scoped analysis documents. See the SQLite twin
+-- (000034_add_notebooks) for the design; this is the Postgres translation.
+CREATE TABLE notebooks (
+    id          BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    team_id     BIGINT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name        TEXT NOT NULL,
+    description TEXT,
+    cells_json  TEXT NOT NULL,
+    created_by  BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_notebooks_team_id ON notebooks(team_id);
+CREATE INDEX idx_notebooks_updated_at ON notebooks(updated_at);
@@ -0,0 +1,18 @@
+-- Audit events: append-only trail of sensitive operations. See the SQLite twin
+-- (000035_add_audit_events) for the design; this is the Postgres translation.
+CREATE TABLE audit_events (
+    id            BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id       BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    user_email    TEXT NOT NULL DEFAULT '',
+    action        TEXT NOT NULL,
+    resource_type TEXT NOT NULL,
+    resource_id   TEXT NOT NULL DEFAULT '',
+    team_id       BIGINT,
+    ip_address    TEXT NOT NULL DEFAULT '',
+    details       TEXT,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events(created_at);
+CREATE INDEX idx_audit_events_user_created ON audit_events(user_id, created_at);
+CREATE INDEX idx_audit_events_action_created ON audit_events(action, created_at);
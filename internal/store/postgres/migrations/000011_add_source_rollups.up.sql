@@ -0,0 +1,12 @@
+-- Source rollups: opt-in per-source hourly rollup state. See the SQLite twin
+-- (000036_add_source_rollups) for the design; this is the Postgres translation.
+CREATE TABLE source_rollups (
+    source_id       BIGINT PRIMARY KEY REFERENCES sources(id) ON DELETE CASCADE,
+    dimension       TEXT NOT NULL DEFAULT '',
+    rolled_up_from  TIMESTAMPTZ,
+    rolled_up_until TIMESTAMPTZ,
+    last_run_at     TIMESTAMPTZ,
+    last_error      TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,37 @@
+-- SLOs and their evaluation history. See the SQLite twin (000038_add_slos)
+-- for the design; this is the Postgres translation.
+CREATE TABLE slos (
+    id             BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    team_id        BIGINT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    source_id      BIGINT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    name           TEXT NOT NULL,
+    description    TEXT NOT NULL DEFAULT '',
+    query_language TEXT NOT NULL,
+    good_query     TEXT NOT NULL,
+    total_query    TEXT NOT NULL,
+    target         DOUBLE PRECISION NOT NULL,
+    window_seconds BIGINT NOT NULL,
+    created_by     BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_slos_team_id ON slos(team_id);
+
+CREATE TABLE slo_evaluations (
+    id                     BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    slo_id                 BIGINT NOT NULL REFERENCES slos(id) ON DELETE CASCADE,
+    good                   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    total                  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    compliance             DOUBLE PRECISION,
+    burn_rate              DOUBLE PRECISION,
+    error_budget_remaining DOUBLE PRECISION,
+    error                  TEXT NOT NULL DEFAULT '',
+    evaluated_at           TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_slo_evaluations_slo_id ON slo_evaluations(slo_id, evaluated_at);
+
+ALTER TABLE alerts ADD COLUMN slo_id BIGINT REFERENCES slos(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_alerts_slo_id ON alerts(slo_id);
@@ -0,0 +1,17 @@
+-- Alert silences. See the SQLite twin (000039_add_alert_silences) for the
+-- design; this is the Postgres translation.
+CREATE TABLE alert_silences (
+    id              BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    alert_id        BIGINT REFERENCES alerts(id) ON DELETE CASCADE,
+    source_id       BIGINT REFERENCES sources(id) ON DELETE CASCADE,
+    team_id         BIGINT REFERENCES teams(id) ON DELETE CASCADE,
+    reason          TEXT NOT NULL DEFAULT '',
+    starts_at       TIMESTAMPTZ NOT NULL,
+    ends_at         TIMESTAMPTZ,
+    recurrence_json TEXT NOT NULL DEFAULT '',
+    created_by      BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_alert_silences_ends_at ON alert_silences(ends_at);
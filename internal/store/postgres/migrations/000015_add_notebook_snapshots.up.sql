@@ -0,0 +1,17 @@
+-- Notebook snapshots. See the SQLite twin (000040_add_notebook_snapshots)
+-- for the design; this is the Postgres translation.
+CREATE TABLE notebook_snapshots (
+    id          TEXT PRIMARY KEY,
+    notebook_id BIGINT NOT NULL,
+    team_id     BIGINT NOT NULL,
+    format      TEXT NOT NULL,
+    file_name   TEXT NOT NULL,
+    object_key  TEXT NOT NULL,
+    size_bytes  BIGINT NOT NULL DEFAULT 0,
+    created_by  BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_notebook_snapshots_notebook ON notebook_snapshots(notebook_id);
+CREATE INDEX idx_notebook_snapshots_expires_at ON notebook_snapshots(expires_at);
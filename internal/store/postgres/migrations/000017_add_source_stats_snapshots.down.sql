@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_source_stats_snapshots_date;
+DROP TABLE IF EXISTS source_stats_snapshots;
@@ -0,0 +1,16 @@
+-- Daily storage statistics per source. See the SQLite twin
+-- (000042_add_source_stats_snapshots) for the design; this is the Postgres
+-- translation.
+CREATE TABLE source_stats_snapshots (
+    source_id          BIGINT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    snapshot_date      TEXT NOT NULL,
+    total_rows         BIGINT NOT NULL DEFAULT 0,
+    part_count         BIGINT NOT NULL DEFAULT 0,
+    compressed_bytes   BIGINT NOT NULL DEFAULT 0,
+    uncompressed_bytes BIGINT NOT NULL DEFAULT 0,
+    columns            JSONB NOT NULL DEFAULT '[]'::jsonb,
+    captured_at        TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (source_id, snapshot_date)
+);
+
+CREATE INDEX idx_source_stats_snapshots_date ON source_stats_snapshots(snapshot_date);
@@ -0,0 +1,6 @@
+-- Query history records failed queries too: status is "success" or "error",
+-- with the failure in error_message. The team index backs the team-wide view.
+ALTER TABLE query_history ADD COLUMN status TEXT NOT NULL DEFAULT 'success';
+ALTER TABLE query_history ADD COLUMN error_message TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_query_history_team_created ON query_history(team_id, created_at DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_source_schema_snapshots_source;
+DROP TABLE IF EXISTS source_schema_snapshots;
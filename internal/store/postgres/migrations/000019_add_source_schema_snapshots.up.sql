@@ -0,0 +1,12 @@
+-- Schema history per source. See the SQLite twin
+-- (000044_add_source_schema_snapshots) for the design; this is the Postgres
+-- translation.
+CREATE TABLE source_schema_snapshots (
+    id          BIGSERIAL PRIMARY KEY,
+    source_id   BIGINT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    columns     JSONB NOT NULL DEFAULT '[]'::jsonb,
+    changes     JSONB NOT NULL DEFAULT '[]'::jsonb,
+    captured_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_source_schema_snapshots_source ON source_schema_snapshots(source_id, id DESC);
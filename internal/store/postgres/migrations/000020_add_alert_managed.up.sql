@@ -0,0 +1,2 @@
+-- Alerts can be declared in provisioning config like sources and teams.
+ALTER TABLE alerts ADD COLUMN managed BOOLEAN NOT NULL DEFAULT FALSE;
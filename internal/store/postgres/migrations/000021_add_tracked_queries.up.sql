@@ -0,0 +1,15 @@
+-- Long-running queries persisted by the query tracker. See the SQLite twin
+-- (000046_add_tracked_queries) for the design; this is the Postgres
+-- translation.
+CREATE TABLE tracked_queries (
+    id          TEXT PRIMARY KEY,
+    instance    TEXT NOT NULL,
+    class       TEXT NOT NULL,
+    user_id     BIGINT NOT NULL,
+    team_id     BIGINT NOT NULL DEFAULT 0,
+    source_id   BIGINT NOT NULL,
+    query_text  TEXT NOT NULL DEFAULT '',
+    started_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_tracked_queries_instance ON tracked_queries(instance);
@@ -0,0 +1,3 @@
+-- Per-source trace correlation: the trace/span ID columns and a URL template
+-- for an external tracing backend, stored as a JSON object ('{}' when unset).
+ALTER TABLE sources ADD COLUMN trace_correlation JSONB NOT NULL DEFAULT '{}'::jsonb;
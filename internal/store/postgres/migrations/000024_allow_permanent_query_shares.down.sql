@@ -0,0 +1,2 @@
+DELETE FROM query_shares WHERE expires_at IS NULL;
+ALTER TABLE query_shares ALTER COLUMN expires_at SET NOT NULL;
@@ -0,0 +1,2 @@
+-- A NULL expires_at marks a permanent share link.
+ALTER TABLE query_shares ALTER COLUMN expires_at DROP NOT NULL;
@@ -0,0 +1,16 @@
+-- Timeline annotations. See the SQLite twin (000050_add_annotations) for the
+-- design; this is the Postgres translation.
+CREATE TABLE annotations (
+    id          BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    team_id     BIGINT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    source_id   BIGINT REFERENCES sources(id) ON DELETE CASCADE,
+    text        TEXT NOT NULL,
+    tags_json   TEXT NOT NULL DEFAULT '',
+    starts_at   TIMESTAMPTZ NOT NULL,
+    ends_at     TIMESTAMPTZ,
+    created_by  BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_annotations_team_starts_at ON annotations(team_id, starts_at);
@@ -0,0 +1,18 @@
+-- Log bookmarks. See the SQLite twin (000051_add_log_bookmarks) for the
+-- design; this is the Postgres translation.
+CREATE TABLE log_bookmarks (
+    id             BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    team_id        BIGINT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    source_id      BIGINT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    user_id        BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    shared         BOOLEAN NOT NULL DEFAULT FALSE,
+    log_timestamp  TIMESTAMPTZ NOT NULL,
+    fingerprint    TEXT NOT NULL,
+    payload_json   TEXT NOT NULL,
+    note           TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX idx_log_bookmarks_user_row ON log_bookmarks(user_id, source_id, fingerprint);
+CREATE INDEX idx_log_bookmarks_team ON log_bookmarks(team_id, created_at);
@@ -0,0 +1,20 @@
+-- Column presets. See the SQLite twin (000052_add_column_presets) for the
+-- design; this is the Postgres translation.
+CREATE TABLE user_column_presets (
+    user_id       BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source_id     BIGINT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    columns_json  TEXT NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, source_id)
+);
+
+CREATE TABLE team_column_presets (
+    team_id       BIGINT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    source_id     BIGINT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    columns_json  TEXT NOT NULL,
+    updated_by    BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, source_id)
+);
@@ -0,0 +1,14 @@
+-- Query snippets. See the SQLite twin (000053_add_query_snippets) for the
+-- design; this is the Postgres translation.
+CREATE TABLE query_snippets (
+    id           BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    team_id      BIGINT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name         TEXT NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    query        TEXT NOT NULL,
+    created_by   BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX idx_query_snippets_team_name ON query_snippets(team_id, name);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_source_alert_events_created_at;
+DROP INDEX IF EXISTS idx_source_alert_events_source;
+DROP TABLE IF EXISTS source_alert_events;
@@ -0,0 +1,15 @@
+-- Source degradation alert events. See the SQLite twin
+-- (000054_add_source_alert_events) for the design; this is the Postgres
+-- translation.
+CREATE TABLE source_alert_events (
+    id         BIGSERIAL PRIMARY KEY,
+    source_id  BIGINT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    kind       TEXT NOT NULL CHECK (kind IN ('unreachable', 'error_rate')),
+    status     TEXT NOT NULL CHECK (status IN ('triggered', 'resolved')),
+    value      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    message    TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_source_alert_events_source ON source_alert_events(source_id, id DESC);
+CREATE INDEX idx_source_alert_events_created_at ON source_alert_events(created_at);
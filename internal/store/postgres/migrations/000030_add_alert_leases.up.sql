@@ -0,0 +1,9 @@
+-- Per-alert evaluation leases. See the SQLite twin (000055_add_alert_leases)
+-- for the design; this is the Postgres translation.
+CREATE TABLE alert_leases (
+    alert_id   BIGINT PRIMARY KEY REFERENCES alerts(id) ON DELETE CASCADE,
+    holder     TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_alert_leases_holder ON alert_leases(holder);
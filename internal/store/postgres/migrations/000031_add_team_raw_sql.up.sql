@@ -0,0 +1,2 @@
+-- Per-team switch for raw SQL. See the SQLite twin (000056_add_team_raw_sql).
+ALTER TABLE teams ADD COLUMN raw_sql_enabled BOOLEAN NOT NULL DEFAULT true;
@@ -0,0 +1,3 @@
+ALTER TABLE alerts DROP COLUMN IF EXISTS breach_count;
+ALTER TABLE alerts DROP COLUMN IF EXISTS pending_since;
+ALTER TABLE alerts DROP COLUMN IF EXISTS for_seconds;
@@ -0,0 +1,4 @@
+-- Pending state for alerts with a for-duration. See the SQLite twin (000057_add_alert_for_duration).
+ALTER TABLE alerts ADD COLUMN for_seconds BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE alerts ADD COLUMN pending_since TIMESTAMPTZ;
+ALTER TABLE alerts ADD COLUMN breach_count BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,12 @@
+-- Outbound webhooks. See the SQLite twin (000058_add_webhooks) for the design.
+CREATE TABLE webhooks (
+    id               BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name             TEXT NOT NULL,
+    url              TEXT NOT NULL,
+    event_types_json TEXT NOT NULL DEFAULT '[]',
+    secret           TEXT NOT NULL,
+    is_active        BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by       BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1 @@
+ALTER TABLE query_stats_daily DROP COLUMN IF EXISTS rows_read;
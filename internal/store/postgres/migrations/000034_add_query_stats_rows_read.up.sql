@@ -0,0 +1,2 @@
+-- Rows read per day in the query stats rollup. See the SQLite twin (000059_add_query_stats_rows_read).
+ALTER TABLE query_stats_daily ADD COLUMN rows_read BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE team_sources DROP COLUMN IF EXISTS row_filter;
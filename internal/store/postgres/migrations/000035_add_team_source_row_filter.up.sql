@@ -0,0 +1,2 @@
+-- Row-level access policy on a team-source link. See the SQLite twin (000060_add_team_source_row_filter).
+ALTER TABLE team_sources ADD COLUMN row_filter TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Per-source column masking. See the SQLite twin (000061_add_source_masked_columns).
+ALTER TABLE sources ADD COLUMN masked_columns JSONB NOT NULL DEFAULT '[]'::jsonb;
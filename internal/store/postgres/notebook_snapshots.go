@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store/postgres/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// CreateNotebookSnapshot records a stored notebook report.
+func (s *Store) CreateNotebookSnapshot(ctx context.Context, snapshot *models.NotebookSnapshot) error {
+	params := sqlc.CreateNotebookSnapshotParams{
+		ID:         snapshot.ID,
+		NotebookID: int64(snapshot.NotebookID),
+		TeamID:     int64(snapshot.TeamID),
+		Format:     snapshot.Format,
+		FileName:   snapshot.FileName,
+		ObjectKey:  snapshot.ObjectKey,
+		SizeBytes:  snapshot.SizeBytes,
+		ExpiresAt:  ts(snapshot.ExpiresAt),
+		CreatedAt:  ts(snapshot.CreatedAt),
+	}
+	if snapshot.CreatedBy != nil {
+		params.CreatedBy = int8Val(int64(*snapshot.CreatedBy))
+	}
+	if err := s.q.CreateNotebookSnapshot(ctx, params); err != nil {
+		s.log.Error("failed to create notebook snapshot", "error", err, "notebook_id", snapshot.NotebookID)
+		return fmt.Errorf("error creating notebook snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetNotebookSnapshot returns a snapshot by id, or models.ErrNotFound if missing.
+func (s *Store) GetNotebookSnapshot(ctx context.Context, id string) (*models.NotebookSnapshot, error) {
+	row, err := s.q.GetNotebookSnapshot(ctx, id)
+	if err != nil {
+		if notFound(err) {
+			return nil, models.ErrNotFound
+		}
+		s.log.Error("failed to get notebook snapshot", "error", err, "snapshot_id", id)
+		return nil, fmt.Errorf("error getting notebook snapshot: %w", err)
+	}
+	return mapNotebookSnapshotRow(row), nil
+}
+
+// ListNotebookSnapshots returns a notebook's snapshots, newest first.
+func (s *Store) ListNotebookSnapshots(ctx context.Context, notebookID int) ([]*models.NotebookSnapshot, error) {
+	rows, err := s.q.ListNotebookSnapshots(ctx, int64(notebookID))
+	if err != nil {
+		s.log.Error("failed to list notebook snapshots", "error", err, "notebook_id", notebookID)
+		return nil, fmt.Errorf("error listing notebook snapshots: %w", err)
+	}
+	return mapNotebookSnapshotRows(rows), nil
+}
+
+// ListExpiredNotebookSnapshots returns snapshots whose expires_at is before
+// the given time.
+func (s *Store) ListExpiredNotebookSnapshots(ctx context.Context, before time.Time) ([]*models.NotebookSnapshot, error) {
+	rows, err := s.q.ListExpiredNotebookSnapshots(ctx, ts(before))
+	if err != nil {
+		s.log.Error("failed to list expired notebook snapshots", "error", err)
+		return nil, fmt.Errorf("error listing expired notebook snapshots: %w", err)
+	}
+	return mapNotebookSnapshotRows(rows), nil
+}
+
+// DeleteNotebookSnapshot removes a snapshot row, or returns models.ErrNotFound.
+func (s *Store) DeleteNotebookSnapshot(ctx context.Context, id string) error {
+	if _, err := s.q.DeleteNotebookSnapshot(ctx, id); err != nil {
+		if notFound(err) {
+			return models.ErrNotFound
+		}
+		s.log.Error("failed to delete notebook snapshot", "error", err, "snapshot_id", id)
+		return fmt.Errorf("error deleting notebook snapshot: %w", err)
+	}
+	return nil
+}
+
+func mapNotebookSnapshotRows(rows []sqlc.NotebookSnapshot) []*models.NotebookSnapshot {
+	snapshots := make([]*models.NotebookSnapshot, 0, len(rows))
+	for _, row := range rows {
+		snapshots = append(snapshots, mapNotebookSnapshotRow(row))
+	}
+	return snapshots
+}
+
+func mapNotebookSnapshotRow(row sqlc.NotebookSnapshot) *models.NotebookSnapshot {
+	snapshot := &models.NotebookSnapshot{
+		ID:         row.ID,
+		NotebookID: int(row.NotebookID),
+		TeamID:     models.TeamID(row.TeamID),
+		Format:     row.Format,
+		FileName:   row.FileName,
+		ObjectKey:  row.ObjectKey,
+		SizeBytes:  row.SizeBytes,
+		ExpiresAt:  row.ExpiresAt.Time,
+		CreatedAt:  row.CreatedAt.Time,
+	}
+	if row.CreatedBy.Valid {
+		createdBy := models.UserID(row.CreatedBy.Int64)
+		snapshot.CreatedBy = &createdBy
+	}
+	return snapshot
+}
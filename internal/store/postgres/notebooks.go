@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mr-karan/logchef/internal/store/postgres/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// CreateNotebook inserts a new notebook and repopulates the model with the
+// persisted row (id and timestamps).
+func (s *Store) CreateNotebook(ctx context.Context, notebook *models.Notebook) error {
+	if notebook == nil {
+		return fmt.Errorf("notebook payload is required")
+	}
+	params := sqlc.CreateNotebookParams{
+		TeamID:      int64(notebook.TeamID),
+		Name:        notebook.Name,
+		Description: text(notebook.Description),
+		CellsJson:   string(notebook.CellsJSON),
+	}
+	if notebook.CreatedBy != nil {
+		params.CreatedBy = int8Val(int64(*notebook.CreatedBy))
+	}
+
+	id, err := s.q.CreateNotebook(ctx, params)
+	if err != nil {
+		s.log.Error("failed to create notebook", "error", err, "team_id", notebook.TeamID)
+		return fmt.Errorf("error creating notebook: %w", err)
+	}
+
+	created, err := s.GetNotebook(ctx, int(id))
+	if err != nil {
+		return err
+	}
+	*notebook = *created
+	return nil
+}
+
+// GetNotebook returns a notebook with its cells by id, or models.ErrNotFound
+// if missing.
+func (s *Store) GetNotebook(ctx context.Context, id int) (*models.Notebook, error) {
+	row, err := s.q.GetNotebook(ctx, int64(id))
+	if err != nil {
+		if notFound(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting notebook id %d: %w", id, err)
+	}
+	return &models.Notebook{
+		ID:          int(row.ID),
+		TeamID:      models.TeamID(row.TeamID),
+		Name:        row.Name,
+		Description: textStr(row.Description),
+		CellsJSON:   json.RawMessage(row.CellsJson),
+		CreatedBy:   userIDPtr(row.CreatedBy),
+		Timestamps: models.Timestamps{
+			CreatedAt: row.CreatedAt.Time,
+			UpdatedAt: row.UpdatedAt.Time,
+		},
+		CreatedByEmail: textStr(row.CreatedByEmail),
+		CreatedByName:  textStr(row.CreatedByName),
+	}, nil
+}
+
+// ListNotebooksByTeam returns a team's notebooks, newest-updated first, with
+// creator info but without cells.
+func (s *Store) ListNotebooksByTeam(ctx context.Context, teamID models.TeamID) ([]*models.Notebook, error) {
+	rows, err := s.q.ListNotebooksByTeam(ctx, int64(teamID))
+	if err != nil {
+		s.log.Error("failed to list notebooks", "error", err, "team_id", teamID)
+		return nil, fmt.Errorf("error listing notebooks: %w", err)
+	}
+
+	notebooks := make([]*models.Notebook, 0, len(rows))
+	for i := range rows {
+		r := rows[i]
+		notebooks = append(notebooks, &models.Notebook{
+			ID:          int(r.ID),
+			TeamID:      models.TeamID(r.TeamID),
+			Name:        r.Name,
+			Description: textStr(r.Description),
+			CreatedBy:   userIDPtr(r.CreatedBy),
+			Timestamps: models.Timestamps{
+				CreatedAt: r.CreatedAt.Time,
+				UpdatedAt: r.UpdatedAt.Time,
+			},
+			CreatedByEmail: textStr(r.CreatedByEmail),
+			CreatedByName:  textStr(r.CreatedByName),
+		})
+	}
+	return notebooks, nil
+}
+
+// UpdateNotebook overwrites a notebook's mutable fields. Returns
+// models.ErrNotFound when the id does not exist.
+func (s *Store) UpdateNotebook(ctx context.Context, notebook *models.Notebook) error {
+	if notebook == nil {
+		return fmt.Errorf("notebook payload is required")
+	}
+	_, err := s.q.UpdateNotebook(ctx, sqlc.UpdateNotebookParams{
+		Name:        notebook.Name,
+		Description: text(notebook.Description),
+		CellsJson:   string(notebook.CellsJSON),
+		ID:          int64(notebook.ID),
+	})
+	if err != nil {
+		if notFound(err) {
+			return models.ErrNotFound
+		}
+		s.log.Error("failed to update notebook", "error", err, "notebook_id", notebook.ID)
+		return fmt.Errorf("error updating notebook: %w", err)
+	}
+	return nil
+}
+
+// UpdateNotebookCells replaces a notebook's cell blob without touching
+// updated_at, provided it still holds previous. Returns models.ErrNotFound
+// when the id does not exist or its cells no longer match previous.
+func (s *Store) UpdateNotebookCells(ctx context.Context, id int, previous, cells json.RawMessage) error {
+	_, err := s.q.UpdateNotebookCells(ctx, sqlc.UpdateNotebookCellsParams{
+		CellsJson:         string(cells),
+		ID:                int64(id),
+		PreviousCellsJson: string(previous),
+	})
+	if err != nil {
+		if notFound(err) {
+			return models.ErrNotFound
+		}
+		s.log.Error("failed to update notebook cells", "error", err, "notebook_id", id)
+		return fmt.Errorf("error updating notebook cells: %w", err)
+	}
+	return nil
+}
+
+// DeleteNotebook removes a notebook. Returns models.ErrNotFound when the id
+// does not exist.
+func (s *Store) DeleteNotebook(ctx context.Context, id int) error {
+	if _, err := s.q.DeleteNotebook(ctx, int64(id)); err != nil {
+		if notFound(err) {
+			return models.ErrNotFound
+		}
+		s.log.Error("failed to delete notebook", "error", err, "notebook_id", id)
+		return fmt.Errorf("error deleting notebook: %w", err)
+	}
+	return nil
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/secrets"
 	"github.com/mr-karan/logchef/internal/store"
 	"github.com/mr-karan/logchef/internal/store/postgres/sqlc"
 )
@@ -39,6 +40,9 @@ const migrationLockKey int64 = 0x10_9C_4E_F0_01
 type Options struct {
 	Logger *slog.Logger
 	Config config.PostgresConfig
+	// Secrets seals source connection secrets at rest; nil stores them in
+	// plaintext.
+	Secrets *secrets.Box
 }
 
 // Store is the Postgres-backed implementation of store.Store.
@@ -47,9 +51,10 @@ type Options struct {
 // WithTx callback, to that transaction — so every read and write in a tx shares
 // it. pool is nil on a tx-scoped Store (it must not Close or start a nested tx).
 type Store struct {
-	pool *pgxpool.Pool
-	q    sqlc.Querier
-	log  *slog.Logger
+	pool    *pgxpool.Pool
+	q       sqlc.Querier
+	log     *slog.Logger
+	secrets *secrets.Box
 }
 
 // Compile-time guarantee that the Postgres backend satisfies the full contract:
@@ -89,7 +94,7 @@ func New(ctx context.Context, opts Options) (*Store, error) {
 		return nil, fmt.Errorf("running postgres migrations: %w", err)
 	}
 
-	return &Store{pool: pool, q: sqlc.New(pool), log: log}, nil
+	return &Store{pool: pool, q: sqlc.New(pool), log: log, secrets: opts.Secrets}, nil
 }
 
 // Close releases the connection pool.
@@ -116,7 +121,7 @@ func (s *Store) WithTx(ctx context.Context, fn func(tx store.StoreOps) error) (e
 		return fmt.Errorf("begin transaction: %w", err)
 	}
 
-	txStore := &Store{q: sqlc.New(tx), log: s.log} // pool nil: no Close/nested tx
+	txStore := &Store{q: sqlc.New(tx), log: s.log, secrets: s.secrets} // pool nil: no Close/nested tx
 
 	defer func() {
 		if p := recover(); p != nil {
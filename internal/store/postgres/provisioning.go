@@ -35,6 +35,15 @@ func (s *Store) IsUserManaged(ctx context.Context, id models.UserID) (bool, erro
 	return managed, nil
 }
 
+// IsAlertManaged returns true if the alert is managed by provisioning config.
+func (s *Store) IsAlertManaged(ctx context.Context, id models.AlertID) (bool, error) {
+	managed, err := s.q.IsAlertManaged(ctx, int64(id))
+	if err != nil {
+		return false, err
+	}
+	return managed, nil
+}
+
 // ListManagedSources returns all sources currently marked managed.
 func (s *Store) ListManagedSources(ctx context.Context) ([]*models.Source, error) {
 	rows, err := s.q.ListManagedSources(ctx)
@@ -45,7 +54,7 @@ func (s *Store) ListManagedSources(ctx context.Context) ([]*models.Source, error
 	sources := make([]*models.Source, 0, len(rows))
 	for i := range rows {
 		r := rows[i]
-		sources = append(sources, sourceToModel(r))
+		sources = append(sources, s.sourceToModel(r))
 	}
 	return sources, nil
 }
@@ -64,6 +73,16 @@ func (s *Store) ListManagedTeams(ctx context.Context) ([]*models.Team, error) {
 	return teams, nil
 }
 
+// ListManagedAlerts returns all alerts currently marked managed.
+func (s *Store) ListManagedAlerts(ctx context.Context) ([]*models.Alert, error) {
+	rows, err := s.q.ListManagedAlerts(ctx)
+	if err != nil {
+		s.log.Error("failed to list managed alerts", "error", err)
+		return nil, fmt.Errorf("error listing managed alerts: %w", err)
+	}
+	return alertsFromSQLC(rows)
+}
+
 // GetSourceByNameForProvisioning looks a source up by name (managed or not).
 // Returns models.ErrNotFound when no source has that name.
 func (s *Store) GetSourceByNameForProvisioning(ctx context.Context, name string) (*models.Source, error) {
@@ -75,7 +94,7 @@ func (s *Store) GetSourceByNameForProvisioning(ctx context.Context, name string)
 		s.log.Error("failed to get source by name for provisioning", "error", err, "name", name)
 		return nil, fmt.Errorf("error getting source by name: %w", err)
 	}
-	return sourceToModel(row), nil
+	return s.sourceToModel(row), nil
 }
 
 // SetSourceManaged marks a source managed/unmanaged, recording the secret ref.
@@ -111,3 +130,13 @@ func (s *Store) SetUserManaged(ctx context.Context, id models.UserID, managed bo
 	}
 	return nil
 }
+
+// SetAlertManaged marks an alert managed/unmanaged.
+func (s *Store) SetAlertManaged(ctx context.Context, id models.AlertID, managed bool) error {
+	err := s.q.SetAlertManaged(ctx, sqlc.SetAlertManagedParams{Managed: managed, ID: int64(id)})
+	if err != nil {
+		s.log.Error("failed to set alert managed flag", "error", err, "alert_id", id)
+		return fmt.Errorf("error setting alert managed: %w", err)
+	}
+	return nil
+}
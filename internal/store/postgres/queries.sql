@@ -3,8 +3,8 @@
 -- name: CreateSource :one
 -- Create a new source entry
 INSERT INTO sources (
-    name, _meta_is_auto_created, source_type, _meta_ts_field, _meta_severity_field, connection_config, identity_key, description, ttl_days, managed, secret_ref, created_at, updated_at
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, now(), now())
+    name, _meta_is_auto_created, source_type, _meta_ts_field, _meta_severity_field, connection_config, identity_key, description, ttl_days, managed, secret_ref, tags, extraction_rules, trace_correlation, masked_columns, created_at, updated_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, now(), now())
 RETURNING id;
 
 -- name: GetSource :one
@@ -33,8 +33,17 @@ SET name = $1,
     ttl_days = $9,
     managed = $10,
     secret_ref = $11,
+    tags = $12,
+    extraction_rules = $13,
+    trace_correlation = $14,
+    masked_columns = $15,
     updated_at = now()
-WHERE id = $12;
+WHERE id = $16;
+
+-- name: UpdateSourceConnectionConfig :exec
+-- Rewrite a source's connection config in place, e.g. to re-seal its secrets,
+-- without touching updated_at.
+UPDATE sources SET connection_config = $1 WHERE id = $2;
 
 -- name: DeleteSource :exec
 -- Delete a source by ID
@@ -146,8 +155,9 @@ SELECT * FROM teams WHERE id = $1;
 UPDATE teams
 SET name = $1,
     description = $2,
-    updated_at = $3
-WHERE id = $4;
+    raw_sql_enabled = $3,
+    updated_at = $4
+WHERE id = $5;
 
 -- name: DeleteTeam :exec
 -- Delete a team by ID
@@ -473,6 +483,16 @@ SELECT EXISTS(
     WHERE team_id = $1 AND source_id = $2
 );
 
+-- name: GetTeamSourceRowFilter :one
+-- Get the row filter a team's queries on a linked source must match
+SELECT row_filter FROM team_sources
+WHERE team_id = $1 AND source_id = $2;
+
+-- name: SetTeamSourceRowFilter :execrows
+-- Set the row filter on a team-source link; '' removes it
+UPDATE team_sources SET row_filter = $1
+WHERE team_id = $2 AND source_id = $3;
+
 -- name: UserHasSourceAccess :one
 -- Check if a user has access to a source through any team
 SELECT EXISTS(
@@ -481,6 +501,12 @@ SELECT EXISTS(
     WHERE tm.user_id = $1 AND ts.source_id = $2
 );
 
+-- name: ListUserSourceRoles :many
+-- List the distinct roles a user holds across the teams that have access to a source
+SELECT DISTINCT tm.role FROM team_members tm
+JOIN team_sources ts ON tm.team_id = ts.team_id
+WHERE tm.user_id = $1 AND ts.source_id = $2;
+
 -- name: GetUserTeamForSource :one
 -- Get a team ID that the user belongs to and that has access to the source
 SELECT tm.team_id FROM team_members tm
@@ -496,6 +522,7 @@ SELECT
     t.description,
     t.created_at,
     t.updated_at,
+    t.raw_sql_enabled,
     tm.role,  -- The current user's role in this team
     (SELECT COUNT(*) FROM team_members sub_tm WHERE sub_tm.team_id = t.id) as member_count
 FROM
@@ -570,11 +597,14 @@ INSERT INTO alerts (
     annotations_json,
     recipient_user_ids_json,
     webhook_urls_json,
+    channels_json,
+    slo_id,
     generator_url,
     is_active,
-    created_by
+    created_by,
+    for_seconds
 )
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
 RETURNING *;
 
 -- name: GetAlert :one
@@ -614,10 +644,13 @@ SET name = $1,
     annotations_json = $13,
     recipient_user_ids_json = $14,
     webhook_urls_json = $15,
-    generator_url = $16,
-    is_active = $17,
+    channels_json = $16,
+    slo_id = $17,
+    generator_url = $18,
+    is_active = $19,
+    for_seconds = $20,
     updated_at = now()
-WHERE id = $18
+WHERE id = $21
 RETURNING id;
 
 -- name: DeleteAlert :one
@@ -625,16 +658,32 @@ DELETE FROM alerts WHERE id = $1
 RETURNING id;
 
 -- name: MarkAlertEvaluated :exec
+-- Resolve the alert and end any pending run of breaches.
 UPDATE alerts
 SET last_state = 'resolved',
+    pending_since = NULL,
+    breach_count = 0,
     last_evaluated_at = now(),
     updated_at = now()
 WHERE id = $1;
 
+-- name: MarkAlertPending :one
+-- Record a breach of an alert that has not fired yet, starting the pending
+-- run on its first breach.
+UPDATE alerts
+SET pending_since = COALESCE(pending_since, now()),
+    breach_count = breach_count + 1,
+    last_evaluated_at = now(),
+    updated_at = now()
+WHERE id = $1
+RETURNING pending_since, breach_count;
+
 -- name: MarkAlertTriggered :exec
 UPDATE alerts
 SET last_state = 'firing',
     last_triggered_at = CASE WHEN last_state = 'firing' THEN last_triggered_at ELSE now() END,
+    pending_since = NULL,
+    breach_count = breach_count + 1,
     last_evaluated_at = now(),
     updated_at = now()
 WHERE id = $1;
@@ -686,6 +735,15 @@ WHERE alert_id = $1
 ORDER BY triggered_at DESC, id DESC
 LIMIT $2;
 
+-- name: ListAlertHistorySince :many
+-- List an alert's history entries still open at or after since, oldest first.
+SELECT * FROM alert_history
+WHERE alert_id = sqlc.arg('alert_id')
+  AND (triggered_at >= sqlc.arg('since')
+    OR resolved_at >= sqlc.arg('since')
+    OR (resolved_at IS NULL AND status = 'triggered'))
+ORDER BY triggered_at ASC, id ASC;
+
 -- name: PruneAlertHistory :exec
 DELETE FROM alert_history AS target
 WHERE target.alert_id = $1
@@ -740,6 +798,10 @@ SELECT * FROM teams WHERE managed = true ORDER BY id;
 -- Get all users managed by provisioning config
 SELECT * FROM users WHERE managed = true ORDER BY id;
 
+-- name: ListManagedAlerts :many
+-- Get all alerts managed by provisioning config
+SELECT * FROM alerts WHERE managed = true ORDER BY id;
+
 -- name: SetSourceManaged :exec
 -- Mark a source as managed/unmanaged and set secret_ref
 UPDATE sources SET managed = $1, secret_ref = $2, updated_at = now() WHERE id = $3;
@@ -764,6 +826,14 @@ SELECT managed FROM teams WHERE id = $1;
 -- Check if a user is managed
 SELECT managed FROM users WHERE id = $1;
 
+-- name: SetAlertManaged :exec
+-- Mark an alert as managed/unmanaged
+UPDATE alerts SET managed = $1, updated_at = now() WHERE id = $2;
+
+-- name: IsAlertManaged :one
+-- Check if an alert is managed
+SELECT managed FROM alerts WHERE id = $1;
+
 -- name: GetSourceByNameForProvisioning :one
 -- Get source by name for provisioning lookup
 SELECT * FROM sources WHERE name = $1;
@@ -944,12 +1014,188 @@ RETURNING id;
 DELETE FROM dashboards WHERE id = $1
 RETURNING id;
 
+-- Notebooks ------------------------------------------------------------------
+
+-- name: CreateNotebook :one
+-- Insert a new notebook and return its id.
+INSERT INTO notebooks (team_id, name, description, cells_json, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id;
+
+-- name: GetNotebook :one
+-- Look up one notebook by id, including its cells and creator identity.
+SELECT
+    n.id,
+    n.team_id,
+    n.name,
+    n.description,
+    n.cells_json,
+    n.created_by,
+    n.created_at,
+    n.updated_at,
+    u.email AS created_by_email,
+    u.full_name AS created_by_name
+FROM notebooks n
+LEFT JOIN users u ON u.id = n.created_by
+WHERE n.id = $1;
+
+-- name: ListNotebooksByTeam :many
+-- List a team's notebooks, newest-updated first. Cells are omitted: they carry
+-- cached results and are only needed when a single notebook is opened.
+SELECT
+    n.id,
+    n.team_id,
+    n.name,
+    n.description,
+    n.created_by,
+    n.created_at,
+    n.updated_at,
+    u.email AS created_by_email,
+    u.full_name AS created_by_name
+FROM notebooks n
+LEFT JOIN users u ON u.id = n.created_by
+WHERE n.team_id = $1
+ORDER BY n.updated_at DESC, n.id DESC;
+
+-- name: UpdateNotebook :one
+-- Update a notebook's mutable fields; RETURNING lets callers detect not-found.
+UPDATE notebooks
+SET name = $1,
+    description = $2,
+    cells_json = $3,
+    updated_at = now()
+WHERE id = $4
+RETURNING id;
+
+-- name: UpdateNotebookCells :one
+-- Replace only the cell blob, leaving updated_at alone: refreshing a cell's
+-- cached result is not an edit and must not trip the optimistic-concurrency
+-- check of someone editing the notebook. The previous-cells guard makes it a
+-- compare-and-swap: a write based on a stale read matches no row instead of
+-- clobbering a concurrent change.
+UPDATE notebooks
+SET cells_json = sqlc.arg('cells_json')
+WHERE id = sqlc.arg('id') AND cells_json = sqlc.arg('previous_cells_json')
+RETURNING id;
+
+-- name: DeleteNotebook :one
+-- Delete a notebook; RETURNING lets callers detect not-found.
+DELETE FROM notebooks WHERE id = $1
+RETURNING id;
+
+-- Audit events ---------------------------------------------------------------
+
+-- name: InsertAuditEvent :one
+-- Append one audit event and return its id. created_at is supplied by the
+-- caller (the time of the action, not of the asynchronous write).
+INSERT INTO audit_events (user_id, user_email, action, resource_type, resource_id, team_id, ip_address, details, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id;
+
+-- name: ListAuditEvents :many
+-- List audit events newest first. Every filter is optional: a NULL argument
+-- matches all rows.
+SELECT *
+FROM audit_events
+WHERE (user_id = sqlc.narg('user_id') OR sqlc.narg('user_id') IS NULL)
+  AND (action = sqlc.narg('action') OR sqlc.narg('action') IS NULL)
+  AND (resource_type = sqlc.narg('resource_type') OR sqlc.narg('resource_type') IS NULL)
+  AND (team_id = sqlc.narg('team_id') OR sqlc.narg('team_id') IS NULL)
+  AND (created_at >= sqlc.narg('since') OR sqlc.narg('since') IS NULL)
+  AND (created_at < sqlc.narg('until') OR sqlc.narg('until') IS NULL)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- Source rollups ---------------------------------------------------------------
+
+-- name: GetSourceRollup :one
+SELECT * FROM source_rollups WHERE source_id = $1;
+
+-- name: ListSourceRollups :many
+SELECT * FROM source_rollups ORDER BY source_id;
+
+-- name: UpsertSourceRollup :exec
+-- Enable or reconfigure a source's rollup. Changing the dimension invalidates
+-- the materialised hours, so progress is reset and the rollup backfills again.
+INSERT INTO source_rollups (source_id, dimension)
+VALUES (sqlc.arg('source_id'), sqlc.arg('dimension'))
+ON CONFLICT (source_id) DO UPDATE SET
+    rolled_up_from = CASE WHEN source_rollups.dimension = excluded.dimension THEN source_rollups.rolled_up_from END,
+    rolled_up_until = CASE WHEN source_rollups.dimension = excluded.dimension THEN source_rollups.rolled_up_until END,
+    dimension = excluded.dimension,
+    updated_at = now();
+
+-- name: UpdateSourceRollupProgress :execrows
+-- Record the outcome of a rollup run. The dimension guard drops the update when
+-- the rollup was reconfigured while the run was in flight.
+UPDATE source_rollups
+SET rolled_up_from = sqlc.narg('rolled_up_from'),
+    rolled_up_until = sqlc.narg('rolled_up_until'),
+    last_run_at = sqlc.arg('last_run_at'),
+    last_error = sqlc.arg('last_error'),
+    updated_at = now()
+WHERE source_id = sqlc.arg('source_id') AND dimension = sqlc.arg('dimension');
+
+-- name: DeleteSourceRollup :exec
+DELETE FROM source_rollups WHERE source_id = $1;
+
+-- Source stats snapshots ------------------------------------------------------
+
+-- name: UpsertSourceStatsSnapshot :exec
+-- Record a source's storage statistics for a day, replacing an earlier
+-- snapshot taken the same day.
+INSERT INTO source_stats_snapshots (
+    source_id, snapshot_date, total_rows, part_count, compressed_bytes, uncompressed_bytes, columns, captured_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT(source_id, snapshot_date) DO UPDATE SET
+    total_rows = excluded.total_rows,
+    part_count = excluded.part_count,
+    compressed_bytes = excluded.compressed_bytes,
+    uncompressed_bytes = excluded.uncompressed_bytes,
+    columns = excluded.columns,
+    captured_at = excluded.captured_at;
+
+-- name: ListSourceStatsSnapshots :many
+SELECT * FROM source_stats_snapshots
+WHERE source_id = sqlc.arg('source_id') AND snapshot_date >= sqlc.arg('since_date')
+ORDER BY snapshot_date;
+
+-- name: DeleteSourceStatsSnapshotsBefore :execrows
+DELETE FROM source_stats_snapshots WHERE snapshot_date < $1;
+
+-- Source schema snapshots -----------------------------------------------------
+
+-- name: InsertSourceSchemaSnapshot :one
+-- Record a source's column list and its changes since the previous snapshot.
+INSERT INTO source_schema_snapshots (source_id, columns, changes, captured_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id;
+
+-- name: GetLatestSourceSchemaSnapshot :one
+SELECT * FROM source_schema_snapshots
+WHERE source_id = $1
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: ListSourceSchemaSnapshots :many
+SELECT * FROM source_schema_snapshots
+WHERE source_id = sqlc.arg('source_id')
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: DeleteSourceSchemaSnapshotsBefore :execrows
+-- Drop snapshots older than the retention window, keeping each source's
+-- latest one as the baseline the next run diffs against.
+DELETE FROM source_schema_snapshots
+WHERE source_schema_snapshots.captured_at < sqlc.arg('before')
+  AND source_schema_snapshots.id NOT IN (SELECT MAX(latest.id) FROM source_schema_snapshots AS latest GROUP BY latest.source_id);
+
 -- Query history ---------------------------------------------------------------
 
 -- name: InsertQueryHistory :one
 -- Record one executed query and return its id.
-INSERT INTO query_history (user_id, team_id, source_id, query_text, query_language, duration_ms, row_count)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO query_history (user_id, team_id, source_id, query_text, query_language, duration_ms, row_count, status, error_message)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING id;
 
 -- name: PruneQueryHistoryForUser :exec
@@ -964,21 +1210,48 @@ WHERE id IN (
 );
 
 -- name: ListQueryHistory :many
--- List one user's recent history, newest first.
+-- List a page of query history newest first, with the user's email and the
+-- source's name (empty once the source is deleted). Every filter is optional:
+-- a NULL argument matches all rows. search matches query text, ignoring case.
 SELECT
-    id,
-    user_id,
-    team_id,
-    source_id,
-    query_text,
-    query_language,
-    duration_ms,
-    row_count,
-    created_at
-FROM query_history
-WHERE user_id = $1
-ORDER BY created_at DESC, id DESC
-LIMIT $2;
+    qh.id,
+    qh.user_id,
+    qh.team_id,
+    qh.source_id,
+    qh.query_text,
+    qh.query_language,
+    qh.duration_ms,
+    qh.row_count,
+    qh.status,
+    qh.error_message,
+    qh.created_at,
+    u.email AS user_email,
+    COALESCE(s.name, '') AS source_name
+FROM query_history qh
+JOIN users u ON u.id = qh.user_id
+LEFT JOIN sources s ON s.id = qh.source_id
+WHERE (qh.user_id = sqlc.narg('user_id') OR sqlc.narg('user_id') IS NULL)
+  AND (qh.team_id = sqlc.narg('team_id') OR sqlc.narg('team_id') IS NULL)
+  AND (qh.source_id = sqlc.narg('source_id') OR sqlc.narg('source_id') IS NULL)
+  AND (qh.status = sqlc.narg('status') OR sqlc.narg('status') IS NULL)
+  AND (strpos(lower(qh.query_text), lower(sqlc.narg('search'))) > 0 OR sqlc.narg('search') IS NULL)
+  AND (qh.created_at >= sqlc.narg('since') OR sqlc.narg('since') IS NULL)
+ORDER BY qh.created_at DESC, qh.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountQueryHistory :one
+-- Count the history entries matching ListQueryHistory's filters.
+SELECT COUNT(*) FROM query_history qh
+WHERE (qh.user_id = sqlc.narg('user_id') OR sqlc.narg('user_id') IS NULL)
+  AND (qh.team_id = sqlc.narg('team_id') OR sqlc.narg('team_id') IS NULL)
+  AND (qh.source_id = sqlc.narg('source_id') OR sqlc.narg('source_id') IS NULL)
+  AND (qh.status = sqlc.narg('status') OR sqlc.narg('status') IS NULL)
+  AND (strpos(lower(qh.query_text), lower(sqlc.narg('search'))) > 0 OR sqlc.narg('search') IS NULL)
+  AND (qh.created_at >= sqlc.narg('since') OR sqlc.narg('since') IS NULL);
+
+-- name: DeleteQueryHistoryBefore :execrows
+-- Drop history entries older than the retention window.
+DELETE FROM query_history WHERE created_at < $1;
 
 -- name: ListQueryActivity :many
 -- Most recent query_history rows across all users, newest first, enriched with
@@ -1000,13 +1273,23 @@ LIMIT $1;
 
 -- name: IncrementQueryStats :exec
 -- Upsert one executed query into the non-pruned daily rollup: add 1 to
--- query_count and the given duration to total_duration_ms for the composite key.
-INSERT INTO query_stats_daily (bucket_date, user_id, team_id, source_id, query_language, query_count, total_duration_ms)
-VALUES ($1, $2, $3, $4, $5, 1, $6)
+-- query_count, and the given duration and rows read to total_duration_ms and
+-- rows_read, for the composite key.
+INSERT INTO query_stats_daily (bucket_date, user_id, team_id, source_id, query_language, query_count, total_duration_ms, rows_read)
+VALUES ($1, $2, $3, $4, $5, 1, $6, $7)
 ON CONFLICT (bucket_date, user_id, team_id, source_id, query_language)
 DO UPDATE SET
     query_count = query_stats_daily.query_count + 1,
-    total_duration_ms = query_stats_daily.total_duration_ms + EXCLUDED.total_duration_ms;
+    total_duration_ms = query_stats_daily.total_duration_ms + EXCLUDED.total_duration_ms,
+    rows_read = query_stats_daily.rows_read + EXCLUDED.rows_read;
+
+-- name: GetTeamQueryUsage :one
+-- Sum a team's queries and rows read on one day of the rollup.
+SELECT
+    COALESCE(SUM(query_count), 0)::bigint AS query_count,
+    COALESCE(SUM(rows_read), 0)::bigint AS rows_read
+FROM query_stats_daily
+WHERE bucket_date = $1 AND team_id = $2;
 
 -- name: TopSourcesByQueries :many
 -- Top sources by total query count over rollup rows on/after `since`, with the
@@ -1049,3 +1332,346 @@ FROM query_stats_daily qsd
 WHERE qsd.bucket_date >= $1
 GROUP BY qsd.bucket_date
 ORDER BY qsd.bucket_date ASC;
+
+-- SLOs ------------------------------------------------------------------------
+
+-- name: CreateSLO :one
+-- Insert a new SLO and return its id.
+INSERT INTO slos (team_id, source_id, name, description, query_language, good_query, total_query, target, window_seconds, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id;
+
+-- name: GetSLO :one
+SELECT * FROM slos WHERE id = $1;
+
+-- name: ListSLOsByTeam :many
+-- List a team's SLOs by name.
+SELECT * FROM slos WHERE team_id = $1 ORDER BY name, id;
+
+-- name: ListSLOs :many
+-- List every SLO, for the periodic evaluator.
+SELECT * FROM slos ORDER BY id;
+
+-- name: UpdateSLO :one
+-- Update an SLO's mutable fields; RETURNING lets callers detect not-found.
+UPDATE slos
+SET name = $1,
+    description = $2,
+    query_language = $3,
+    good_query = $4,
+    total_query = $5,
+    target = $6,
+    window_seconds = $7,
+    updated_at = now()
+WHERE id = $8
+RETURNING id;
+
+-- name: DeleteSLO :one
+-- Delete an SLO; its evaluations and burn-rate alerts cascade.
+DELETE FROM slos WHERE id = $1
+RETURNING id;
+
+-- name: InsertSLOEvaluation :one
+-- Append one evaluation and return its id.
+INSERT INTO slo_evaluations (slo_id, good, total, compliance, burn_rate, error_budget_remaining, error, evaluated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id;
+
+-- name: ListSLOEvaluations :many
+-- An SLO's evaluations, newest first.
+SELECT * FROM slo_evaluations
+WHERE slo_id = $1
+ORDER BY evaluated_at DESC, id DESC
+LIMIT $2;
+
+-- name: PruneSLOEvaluations :exec
+-- Keep only an SLO's newest evaluations.
+DELETE FROM slo_evaluations AS target
+WHERE target.slo_id = $1
+  AND target.id NOT IN (
+    SELECT keep.id
+    FROM slo_evaluations AS keep
+    WHERE keep.slo_id = $2
+    ORDER BY keep.evaluated_at DESC, keep.id DESC
+    LIMIT $3
+ );
+
+-- Alert silences ---------------------------------------------------------------
+
+-- name: CreateAlertSilence :one
+-- Insert a new silence and return its id.
+INSERT INTO alert_silences (alert_id, source_id, team_id, reason, starts_at, ends_at, recurrence_json, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id;
+
+-- name: GetAlertSilence :one
+SELECT * FROM alert_silences WHERE id = $1;
+
+-- name: ListAlertSilences :many
+-- List every silence, latest-starting first.
+SELECT * FROM alert_silences ORDER BY starts_at DESC, id DESC;
+
+-- name: ListUnexpiredAlertSilences :many
+-- Silences that have not ended by the given time, for the alert evaluator.
+SELECT * FROM alert_silences
+WHERE ends_at IS NULL OR ends_at > $1
+ORDER BY id;
+
+-- name: UpdateAlertSilence :one
+-- Update a silence's reason and schedule; RETURNING lets callers detect not-found.
+UPDATE alert_silences
+SET reason = $1,
+    starts_at = $2,
+    ends_at = $3,
+    recurrence_json = $4,
+    updated_at = now()
+WHERE id = $5
+RETURNING id;
+
+-- name: DeleteAlertSilence :one
+DELETE FROM alert_silences WHERE id = $1
+RETURNING id;
+
+-- Notebook snapshots -----------------------------------------------------------
+
+-- name: CreateNotebookSnapshot :exec
+INSERT INTO notebook_snapshots (id, notebook_id, team_id, format, file_name, object_key, size_bytes, created_by, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: GetNotebookSnapshot :one
+SELECT * FROM notebook_snapshots WHERE id = $1;
+
+-- name: ListNotebookSnapshots :many
+-- A notebook's snapshots, newest first.
+SELECT * FROM notebook_snapshots
+WHERE notebook_id = $1
+ORDER BY created_at DESC, id;
+
+-- name: ListExpiredNotebookSnapshots :many
+-- Snapshots past their expiry, for the cleanup loop.
+SELECT * FROM notebook_snapshots
+WHERE expires_at < $1
+ORDER BY expires_at;
+
+-- name: DeleteNotebookSnapshot :one
+DELETE FROM notebook_snapshots WHERE id = $1
+RETURNING id;
+
+-- Tracked queries --------------------------------------------------------------
+
+-- name: UpsertTrackedQuery :exec
+INSERT INTO tracked_queries (id, instance, class, user_id, team_id, source_id, query_text, started_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (id) DO NOTHING;
+
+-- name: DeleteTrackedQuery :exec
+DELETE FROM tracked_queries WHERE id = $1;
+
+-- name: ListTrackedQueries :many
+-- One instance's persisted queries, oldest first.
+SELECT * FROM tracked_queries
+WHERE instance = $1
+ORDER BY started_at, id;
+
+-- name: DeleteTrackedQueriesBefore :execrows
+-- Rows no live query can still own, whichever instance wrote them.
+DELETE FROM tracked_queries WHERE started_at < $1;
+
+-- Annotations ------------------------------------------------------------------
+
+-- name: CreateAnnotation :one
+-- Insert a new annotation and return its id.
+INSERT INTO annotations (team_id, source_id, text, tags_json, starts_at, ends_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id;
+
+-- name: GetAnnotation :one
+SELECT * FROM annotations WHERE id = $1;
+
+-- name: ListAnnotationsByTeam :many
+-- A team's annotations, latest-starting first.
+SELECT * FROM annotations
+WHERE team_id = $1
+ORDER BY starts_at DESC, id DESC;
+
+-- name: ListAnnotationsInRange :many
+-- A team's annotations that touch [range_start, range_end] and apply to the
+-- given source: team-wide ones and the source's own. Oldest first.
+SELECT * FROM annotations
+WHERE team_id = sqlc.arg('team_id')
+  AND (source_id IS NULL OR source_id = sqlc.arg('source_id'))
+  AND starts_at <= sqlc.arg('range_end')
+  AND (starts_at >= sqlc.arg('range_start') OR ends_at >= sqlc.arg('range_start'))
+ORDER BY starts_at, id;
+
+-- name: UpdateAnnotation :one
+-- Update an annotation's note, scope and range; RETURNING lets callers detect not-found.
+UPDATE annotations
+SET source_id = $1,
+    text = $2,
+    tags_json = $3,
+    starts_at = $4,
+    ends_at = $5,
+    updated_at = now()
+WHERE id = $6
+RETURNING id;
+
+-- name: DeleteAnnotation :one
+DELETE FROM annotations WHERE id = $1
+RETURNING id;
+
+-- Log bookmarks ----------------------------------------------------------------
+
+-- name: CreateLogBookmark :one
+-- Pin a log row and return the bookmark id.
+INSERT INTO log_bookmarks (team_id, source_id, user_id, shared, log_timestamp, fingerprint, payload_json, note)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id;
+
+-- name: GetLogBookmark :one
+SELECT * FROM log_bookmarks WHERE id = $1;
+
+-- name: ListLogBookmarksForUser :many
+-- The bookmarks a user sees in a team: their own and the team's shared ones,
+-- most recently pinned first.
+SELECT * FROM log_bookmarks
+WHERE team_id = sqlc.arg('team_id')
+  AND (user_id = sqlc.arg('user_id') OR shared)
+ORDER BY created_at DESC, id DESC;
+
+-- name: UpdateLogBookmark :one
+-- Update a bookmark's note and visibility; RETURNING lets callers detect not-found.
+UPDATE log_bookmarks
+SET note = $1,
+    shared = $2,
+    updated_at = now()
+WHERE id = $3
+RETURNING id;
+
+-- name: DeleteLogBookmark :one
+DELETE FROM log_bookmarks WHERE id = $1
+RETURNING id;
+
+-- Column presets ---------------------------------------------------------------
+
+-- name: GetUserColumnPreset :one
+SELECT * FROM user_column_presets WHERE user_id = $1 AND source_id = $2;
+
+-- name: UpsertUserColumnPreset :exec
+-- Save a user's column layout for a source, replacing any earlier one.
+INSERT INTO user_column_presets (user_id, source_id, columns_json)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, source_id) DO UPDATE SET
+    columns_json = excluded.columns_json,
+    updated_at = now();
+
+-- name: DeleteUserColumnPreset :one
+DELETE FROM user_column_presets WHERE user_id = $1 AND source_id = $2
+RETURNING source_id;
+
+-- name: GetTeamColumnPreset :one
+SELECT * FROM team_column_presets WHERE team_id = $1 AND source_id = $2;
+
+-- name: UpsertTeamColumnPreset :exec
+-- Save a team's default column layout for a source, replacing any earlier one.
+INSERT INTO team_column_presets (team_id, source_id, columns_json, updated_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (team_id, source_id) DO UPDATE SET
+    columns_json = excluded.columns_json,
+    updated_by = excluded.updated_by,
+    updated_at = now();
+
+-- name: DeleteTeamColumnPreset :one
+DELETE FROM team_column_presets WHERE team_id = $1 AND source_id = $2
+RETURNING source_id;
+
+-- Query snippets ---------------------------------------------------------------
+
+-- name: CreateQuerySnippet :one
+-- Insert a new query snippet and return its id.
+INSERT INTO query_snippets (team_id, name, description, query, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id;
+
+-- name: GetQuerySnippet :one
+SELECT * FROM query_snippets WHERE id = $1;
+
+-- name: ListQuerySnippetsByTeam :many
+-- A team's snippets, by name.
+SELECT * FROM query_snippets WHERE team_id = $1 ORDER BY name, id;
+
+-- name: UpdateQuerySnippet :one
+-- Update a snippet's name, description and query; RETURNING lets callers detect not-found.
+UPDATE query_snippets
+SET name = $1,
+    description = $2,
+    query = $3,
+    updated_at = now()
+WHERE id = $4
+RETURNING id;
+
+-- name: DeleteQuerySnippet :one
+DELETE FROM query_snippets WHERE id = $1
+RETURNING id;
+
+-- Source alert events ----------------------------------------------------------
+
+-- name: InsertSourceAlertEvent :one
+-- Record a source degradation alert firing or resolving.
+INSERT INTO source_alert_events (source_id, kind, status, value, message, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id;
+
+-- name: ListSourceAlertEvents :many
+SELECT * FROM source_alert_events
+WHERE source_id = sqlc.arg('source_id')
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: DeleteSourceAlertEventsBefore :execrows
+DELETE FROM source_alert_events WHERE created_at < $1;
+
+-- Alert evaluation leases -------------------------------------------------------
+
+-- name: AcquireAlertLease :execrows
+-- Claim an alert for evaluation, or extend the holder's own claim. Another
+-- instance's lease is only taken over once it has expired.
+INSERT INTO alert_leases (alert_id, holder, expires_at)
+VALUES (sqlc.arg('alert_id'), sqlc.arg('holder'), sqlc.arg('expires_at'))
+ON CONFLICT(alert_id) DO UPDATE
+SET holder = excluded.holder, expires_at = excluded.expires_at
+WHERE alert_leases.holder = excluded.holder OR alert_leases.expires_at < sqlc.arg('now');
+
+-- name: DeleteAlertLeasesByHolder :exec
+DELETE FROM alert_leases WHERE holder = $1;
+
+-- Webhooks ---------------------------------------------------------------------
+
+-- name: CreateWebhook :one
+-- Register an outbound webhook and return its id.
+INSERT INTO webhooks (name, url, event_types_json, secret, is_active, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id;
+
+-- name: GetWebhook :one
+SELECT * FROM webhooks WHERE id = $1;
+
+-- name: ListWebhooks :many
+-- List every webhook by name.
+SELECT * FROM webhooks ORDER BY name, id;
+
+-- name: UpdateWebhook :one
+-- Replace a webhook's settings; RETURNING lets callers detect not-found.
+UPDATE webhooks
+SET name = $1,
+    url = $2,
+    event_types_json = $3,
+    secret = $4,
+    is_active = $5,
+    updated_at = now()
+WHERE id = $6
+RETURNING id;
+
+-- name: DeleteWebhook :one
+DELETE FROM webhooks WHERE id = $1
+RETURNING id;
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"time"
@@ -23,9 +24,9 @@ func bucketDateParam(bucketDate string) pgtype.Date {
 }
 
 // RecordQueryHistory inserts one executed-query record, then prunes the user's
-// history down to models.QueryHistoryPerUserCap so it stays bounded. The insert
-// populates the entry's ID.
-func (s *Store) RecordQueryHistory(ctx context.Context, entry *models.QueryHistory) error {
+// history down to the newest keepPerUser entries so it stays bounded (no cap
+// when keepPerUser <= 0). The insert populates the entry's ID.
+func (s *Store) RecordQueryHistory(ctx context.Context, entry *models.QueryHistory, keepPerUser int) error {
 	if entry == nil {
 		return fmt.Errorf("query history entry is required")
 	}
@@ -38,16 +39,21 @@ func (s *Store) RecordQueryHistory(ctx context.Context, entry *models.QueryHisto
 		QueryLanguage: string(entry.QueryLanguage),
 		DurationMs:    entry.DurationMs,
 		RowCount:      entry.RowCount,
+		Status:        string(cmp.Or(entry.Status, models.QueryHistoryStatusSuccess)),
+		ErrorMessage:  entry.ErrorMessage,
 	})
 	if err != nil {
 		s.log.Error("failed to record query history", "error", err, "user_id", entry.UserID)
 		return fmt.Errorf("error recording query history: %w", err)
 	}
 	entry.ID = id
+	if keepPerUser <= 0 {
+		return nil
+	}
 
 	if err := s.q.PruneQueryHistoryForUser(ctx, sqlc.PruneQueryHistoryForUserParams{
 		UserID: int64(entry.UserID),
-		Offset: models.QueryHistoryPerUserCap,
+		Offset: int32(keepPerUser), //nolint:gosec // G115: the per-user cap is a small configured count
 	}); err != nil {
 		s.log.Error("failed to prune query history", "error", err, "user_id", entry.UserID)
 		return fmt.Errorf("error pruning query history: %w", err)
@@ -55,15 +61,29 @@ func (s *Store) RecordQueryHistory(ctx context.Context, entry *models.QueryHisto
 	return nil
 }
 
-// ListQueryHistory returns a user's recent query history, newest first, capped
-// at limit.
-func (s *Store) ListQueryHistory(ctx context.Context, userID models.UserID, limit int) ([]*models.QueryHistory, error) {
-	rows, err := s.q.ListQueryHistory(ctx, sqlc.ListQueryHistoryParams{
-		UserID: int64(userID),
-		Limit:  int32(limit), //nolint:gosec // G115: limit is a small bounded page size
-	})
+// ListQueryHistory returns a page of the history matching filter, newest
+// first, with user emails and source names filled in.
+func (s *Store) ListQueryHistory(ctx context.Context, filter models.QueryHistoryFilter) ([]*models.QueryHistory, error) {
+	params := sqlc.ListQueryHistoryParams{
+		Status: text(string(filter.Status)),
+		Search: text(filter.Search),
+		Limit:  int32(filter.Limit),  //nolint:gosec // G115: limit is a small bounded page size
+		Offset: int32(filter.Offset), //nolint:gosec // G115: history is capped, so offsets are small
+	}
+	if filter.UserID != nil {
+		params.UserID = int8Val(int64(*filter.UserID))
+	}
+	if filter.TeamID != nil {
+		params.TeamID = int8Val(int64(*filter.TeamID))
+	}
+	if filter.SourceID != nil {
+		params.SourceID = int8Val(int64(*filter.SourceID))
+	}
+	params.Since = tsFromPtr(filter.Since)
+
+	rows, err := s.q.ListQueryHistory(ctx, params)
 	if err != nil {
-		s.log.Error("failed to list query history", "error", err, "user_id", userID)
+		s.log.Error("failed to list query history", "error", err)
 		return nil, fmt.Errorf("error listing query history: %w", err)
 	}
 
@@ -79,12 +99,53 @@ func (s *Store) ListQueryHistory(ctx context.Context, userID models.UserID, limi
 			QueryLanguage: models.QueryLanguage(r.QueryLanguage),
 			DurationMs:    r.DurationMs,
 			RowCount:      r.RowCount,
+			Status:        models.QueryHistoryStatus(r.Status),
+			ErrorMessage:  r.ErrorMessage,
 			CreatedAt:     r.CreatedAt.Time,
+			UserEmail:     r.UserEmail,
+			SourceName:    r.SourceName,
 		})
 	}
 	return history, nil
 }
 
+// CountQueryHistory returns how many history entries match filter, ignoring
+// its paging.
+func (s *Store) CountQueryHistory(ctx context.Context, filter models.QueryHistoryFilter) (int, error) {
+	params := sqlc.CountQueryHistoryParams{
+		Status: text(string(filter.Status)),
+		Search: text(filter.Search),
+	}
+	if filter.UserID != nil {
+		params.UserID = int8Val(int64(*filter.UserID))
+	}
+	if filter.TeamID != nil {
+		params.TeamID = int8Val(int64(*filter.TeamID))
+	}
+	if filter.SourceID != nil {
+		params.SourceID = int8Val(int64(*filter.SourceID))
+	}
+	params.Since = tsFromPtr(filter.Since)
+
+	n, err := s.q.CountQueryHistory(ctx, params)
+	if err != nil {
+		s.log.Error("failed to count query history", "error", err)
+		return 0, fmt.Errorf("error counting query history: %w", err)
+	}
+	return int(n), nil
+}
+
+// DeleteQueryHistoryBefore drops history entries recorded before the cutoff
+// and returns how many were removed.
+func (s *Store) DeleteQueryHistoryBefore(ctx context.Context, before time.Time) (int64, error) {
+	n, err := s.q.DeleteQueryHistoryBefore(ctx, ts(before))
+	if err != nil {
+		s.log.Error("failed to delete old query history", "error", err)
+		return 0, fmt.Errorf("error deleting old query history: %w", err)
+	}
+	return n, nil
+}
+
 // ListQueryActivity returns the most recent query_history rows across all
 // users, newest first, capped at limit, enriched with user email and source
 // name. Because query_history is capped per user, this is a recent window
@@ -117,9 +178,9 @@ func (s *Store) ListQueryActivity(ctx context.Context, limit int) ([]models.Quer
 }
 
 // IncrementQueryStats upserts one executed query into the non-pruned
-// query_stats_daily rollup, adding 1 to query_count and durationMs to
-// total_duration_ms for the composite key.
-func (s *Store) IncrementQueryStats(ctx context.Context, bucketDate string, userID models.UserID, teamID models.TeamID, sourceID models.SourceID, language models.QueryLanguage, durationMs int64) error {
+// query_stats_daily rollup, adding 1 to query_count, durationMs to
+// total_duration_ms and rowsRead to rows_read for the composite key.
+func (s *Store) IncrementQueryStats(ctx context.Context, bucketDate string, userID models.UserID, teamID models.TeamID, sourceID models.SourceID, language models.QueryLanguage, durationMs, rowsRead int64) error {
 	if err := s.q.IncrementQueryStats(ctx, sqlc.IncrementQueryStatsParams{
 		BucketDate:      bucketDateParam(bucketDate),
 		UserID:          int64(userID),
@@ -127,6 +188,7 @@ func (s *Store) IncrementQueryStats(ctx context.Context, bucketDate string, user
 		SourceID:        int64(sourceID),
 		QueryLanguage:   string(models.NormalizeQueryLanguage(language)),
 		TotalDurationMs: durationMs,
+		RowsRead:        rowsRead,
 	}); err != nil {
 		s.log.Error("failed to increment query stats", "error", err, "user_id", userID, "source_id", sourceID)
 		return fmt.Errorf("error incrementing query stats: %w", err)
@@ -134,6 +196,19 @@ func (s *Store) IncrementQueryStats(ctx context.Context, bucketDate string, user
 	return nil
 }
 
+// GetTeamQueryUsage sums a team's queries and rows read on bucketDate.
+func (s *Store) GetTeamQueryUsage(ctx context.Context, teamID models.TeamID, bucketDate string) (*models.TeamQueryUsage, error) {
+	row, err := s.q.GetTeamQueryUsage(ctx, sqlc.GetTeamQueryUsageParams{
+		BucketDate: bucketDateParam(bucketDate),
+		TeamID:     int64(teamID),
+	})
+	if err != nil {
+		s.log.Error("failed to get team query usage", "error", err, "team_id", teamID)
+		return nil, fmt.Errorf("error getting team query usage: %w", err)
+	}
+	return &models.TeamQueryUsage{Date: bucketDate, Queries: row.QueryCount, RowsRead: row.RowsRead}, nil
+}
+
 // TopSourcesByQueries returns sources ordered by total query count desc (capped
 // at limit) over rollup rows with bucket_date >= since.
 func (s *Store) TopSourcesByQueries(ctx context.Context, since string, limit int) ([]models.SourceQueryStat, error) {
@@ -22,7 +22,7 @@ func (s *Store) CreateQueryShare(ctx context.Context, share *models.QueryShare)
 		TeamID:      int8FromPtr(teamID),
 		CreatedBy:   int64(share.CreatedBy),
 		PayloadJson: string(share.Payload),
-		ExpiresAt:   ts(share.ExpiresAt),
+		ExpiresAt:   tsFromPtr(share.ExpiresAt),
 	})
 	if err != nil {
 		s.log.Error("failed to create query share", "error", err, "source_id", share.SourceID)
@@ -48,7 +48,7 @@ func (s *Store) GetQueryShare(ctx context.Context, token string) (*models.QueryS
 		SourceID:       models.SourceID(row.SourceID),
 		CreatedBy:      models.UserID(row.CreatedBy),
 		Payload:        []byte(row.PayloadJson),
-		ExpiresAt:      row.ExpiresAt.Time,
+		ExpiresAt:      tsPtr(row.ExpiresAt),
 		CreatedAt:      row.CreatedAt.Time,
 		CreatedByEmail: row.Email,
 		CreatedByName:  row.FullName,
@@ -101,7 +101,8 @@ func (s *Store) GetUserTeamForSource(ctx context.Context, userID models.UserID,
 	return models.TeamID(teamID), nil
 }
 
-// PruneExpiredQueryShares removes expired query shares.
+// PruneExpiredQueryShares removes expired query shares. Permanent shares
+// have no expiry and are kept.
 func (s *Store) PruneExpiredQueryShares(ctx context.Context, before time.Time) error {
 	if err := s.q.PruneExpiredQueryShares(ctx, ts(before)); err != nil {
 		s.log.Error("failed to prune expired query shares", "error", err)
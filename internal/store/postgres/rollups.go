@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mr-karan/logchef/internal/store/postgres/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// GetSourceRollup returns a source's rollup state, or models.ErrNotFound if the
+// source has no rollup.
+func (s *Store) GetSourceRollup(ctx context.Context, sourceID models.SourceID) (*models.SourceRollup, error) {
+	row, err := s.q.GetSourceRollup(ctx, int64(sourceID))
+	if err != nil {
+		if notFound(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting rollup for source %d: %w", sourceID, err)
+	}
+	return mapSourceRollupRow(row), nil
+}
+
+// ListSourceRollups returns every configured rollup.
+func (s *Store) ListSourceRollups(ctx context.Context) ([]*models.SourceRollup, error) {
+	rows, err := s.q.ListSourceRollups(ctx)
+	if err != nil {
+		s.log.Error("failed to list source rollups", "error", err)
+		return nil, fmt.Errorf("error listing source rollups: %w", err)
+	}
+	rollups := make([]*models.SourceRollup, 0, len(rows))
+	for _, row := range rows {
+		rollups = append(rollups, mapSourceRollupRow(row))
+	}
+	return rollups, nil
+}
+
+// UpsertSourceRollup enables a source's rollup or changes its dimension.
+func (s *Store) UpsertSourceRollup(ctx context.Context, sourceID models.SourceID, dimension string) error {
+	err := s.q.UpsertSourceRollup(ctx, sqlc.UpsertSourceRollupParams{
+		SourceID:  int64(sourceID),
+		Dimension: dimension,
+	})
+	if err != nil {
+		s.log.Error("failed to upsert source rollup", "error", err, "source_id", sourceID)
+		return fmt.Errorf("error saving rollup for source %d: %w", sourceID, err)
+	}
+	return nil
+}
+
+// UpdateSourceRollupProgress records the outcome of a rollup run.
+func (s *Store) UpdateSourceRollupProgress(ctx context.Context, rollup *models.SourceRollup) error {
+	n, err := s.q.UpdateSourceRollupProgress(ctx, sqlc.UpdateSourceRollupProgressParams{
+		RolledUpFrom:  tsFromPtr(rollup.RolledUpFrom),
+		RolledUpUntil: tsFromPtr(rollup.RolledUpUntil),
+		LastRunAt:     tsFromPtr(rollup.LastRunAt),
+		LastError:     rollup.LastError,
+		SourceID:      int64(rollup.SourceID),
+		Dimension:     rollup.Dimension,
+	})
+	if err != nil {
+		s.log.Error("failed to update source rollup progress", "error", err, "source_id", rollup.SourceID)
+		return fmt.Errorf("error updating rollup progress for source %d: %w", rollup.SourceID, err)
+	}
+	if n == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteSourceRollup removes a source's rollup configuration.
+func (s *Store) DeleteSourceRollup(ctx context.Context, sourceID models.SourceID) error {
+	if err := s.q.DeleteSourceRollup(ctx, int64(sourceID)); err != nil {
+		s.log.Error("failed to delete source rollup", "error", err, "source_id", sourceID)
+		return fmt.Errorf("error deleting rollup for source %d: %w", sourceID, err)
+	}
+	return nil
+}
+
+func mapSourceRollupRow(row sqlc.SourceRollup) *models.SourceRollup {
+	return &models.SourceRollup{
+		SourceID:      models.SourceID(row.SourceID),
+		Dimension:     row.Dimension,
+		RolledUpFrom:  tsPtr(row.RolledUpFrom),
+		RolledUpUntil: tsPtr(row.RolledUpUntil),
+		LastRunAt:     tsPtr(row.LastRunAt),
+		LastError:     row.LastError,
+		Timestamps: models.Timestamps{
+			CreatedAt: row.CreatedAt.Time,
+			UpdatedAt: row.UpdatedAt.Time,
+		},
+	}
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store/postgres/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// InsertSourceSchemaSnapshot stores a source's column list and its changes.
+func (s *Store) InsertSourceSchemaSnapshot(ctx context.Context, snapshot *models.SourceSchemaSnapshot) error {
+	columns, changes, err := encodeSchemaSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	id, err := s.q.InsertSourceSchemaSnapshot(ctx, sqlc.InsertSourceSchemaSnapshotParams{
+		SourceID:   int64(snapshot.SourceID),
+		Columns:    columns,
+		Changes:    changes,
+		CapturedAt: ts(snapshot.CapturedAt),
+	})
+	if err != nil {
+		s.log.Error("failed to insert source schema snapshot", "error", err, "source_id", snapshot.SourceID)
+		return fmt.Errorf("error saving schema snapshot for source %d: %w", snapshot.SourceID, err)
+	}
+	snapshot.ID = id
+	return nil
+}
+
+// GetLatestSourceSchemaSnapshot returns a source's most recent schema snapshot.
+func (s *Store) GetLatestSourceSchemaSnapshot(ctx context.Context, sourceID models.SourceID) (*models.SourceSchemaSnapshot, error) {
+	row, err := s.q.GetLatestSourceSchemaSnapshot(ctx, int64(sourceID))
+	if err != nil {
+		if notFound(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting latest schema snapshot for source %d: %w", sourceID, err)
+	}
+	return mapSourceSchemaSnapshotRow(row), nil
+}
+
+// ListSourceSchemaSnapshots returns up to limit schema snapshots, newest first.
+func (s *Store) ListSourceSchemaSnapshots(ctx context.Context, sourceID models.SourceID, limit int) ([]*models.SourceSchemaSnapshot, error) {
+	rows, err := s.q.ListSourceSchemaSnapshots(ctx, sqlc.ListSourceSchemaSnapshotsParams{
+		SourceID: int64(sourceID),
+		Limit:    int32(limit), //nolint:gosec // G115: history limit, small bounded value
+	})
+	if err != nil {
+		s.log.Error("failed to list source schema snapshots", "error", err, "source_id", sourceID)
+		return nil, fmt.Errorf("error listing schema snapshots for source %d: %w", sourceID, err)
+	}
+	snapshots := make([]*models.SourceSchemaSnapshot, 0, len(rows))
+	for _, row := range rows {
+		snapshots = append(snapshots, mapSourceSchemaSnapshotRow(row))
+	}
+	return snapshots, nil
+}
+
+// DeleteSourceSchemaSnapshotsBefore removes snapshots captured before the
+// cutoff, keeping each source's latest.
+func (s *Store) DeleteSourceSchemaSnapshotsBefore(ctx context.Context, before time.Time) (int64, error) {
+	n, err := s.q.DeleteSourceSchemaSnapshotsBefore(ctx, ts(before))
+	if err != nil {
+		s.log.Error("failed to prune source schema snapshots", "error", err)
+		return 0, fmt.Errorf("error pruning source schema snapshots: %w", err)
+	}
+	return n, nil
+}
+
+func mapSourceSchemaSnapshotRow(row sqlc.SourceSchemaSnapshot) *models.SourceSchemaSnapshot {
+	return &models.SourceSchemaSnapshot{
+		ID:         row.ID,
+		SourceID:   models.SourceID(row.SourceID),
+		Columns:    decodeSchemaColumns(row.Columns),
+		Changes:    decodeSchemaChanges(row.Changes),
+		CapturedAt: row.CapturedAt.Time,
+	}
+}
+
+func encodeSchemaSnapshot(snapshot *models.SourceSchemaSnapshot) (columns, changes []byte, err error) {
+	cols := snapshot.Columns
+	if cols == nil {
+		cols = []models.ColumnInfo{}
+	}
+	if columns, err = json.Marshal(cols); err != nil {
+		return nil, nil, fmt.Errorf("encoding schema columns: %w", err)
+	}
+	diff := snapshot.Changes
+	if diff == nil {
+		diff = []models.SchemaChange{}
+	}
+	if changes, err = json.Marshal(diff); err != nil {
+		return nil, nil, fmt.Errorf("encoding schema changes: %w", err)
+	}
+	return columns, changes, nil
+}
+
+// decodeSchemaColumns and decodeSchemaChanges read the stored JSON arrays; a
+// malformed value yields an empty list rather than failing the whole listing.
+func decodeSchemaColumns(data []byte) []models.ColumnInfo {
+	var columns []models.ColumnInfo
+	if err := json.Unmarshal(data, &columns); err != nil || columns == nil {
+		return []models.ColumnInfo{}
+	}
+	return columns
+}
+
+func decodeSchemaChanges(data []byte) []models.SchemaChange {
+	var changes []models.SchemaChange
+	if err := json.Unmarshal(data, &changes); err != nil || changes == nil {
+		return []models.SchemaChange{}
+	}
+	return changes
+}
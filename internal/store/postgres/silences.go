@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store/alertjson"
+	"github.com/mr-karan/logchef/internal/store/postgres/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// CreateAlertSilence inserts a new silence and repopulates the model with the
+// persisted row (id and timestamps).
+func (s *Store) CreateAlertSilence(ctx context.Context, silence *models.AlertSilence) error {
+	if silence == nil {
+		return fmt.Errorf("silence payload is required")
+	}
+	recurrence, err := alertjson.Encode(silence.Recurrence, silence.Recurrence == nil)
+	if err != nil {
+		return fmt.Errorf("error encoding silence recurrence: %w", err)
+	}
+	params := sqlc.CreateAlertSilenceParams{
+		Reason:         silence.Reason,
+		StartsAt:       ts(silence.StartsAt),
+		EndsAt:         tsFromPtr(silence.EndsAt),
+		RecurrenceJson: recurrence,
+	}
+	if silence.AlertID != nil {
+		params.AlertID = int8Val(int64(*silence.AlertID))
+	}
+	if silence.SourceID != nil {
+		params.SourceID = int8Val(int64(*silence.SourceID))
+	}
+	if silence.TeamID != nil {
+		params.TeamID = int8Val(int64(*silence.TeamID))
+	}
+	if silence.CreatedBy != nil {
+		params.CreatedBy = int8Val(int64(*silence.CreatedBy))
+	}
+
+	id, err := s.q.CreateAlertSilence(ctx, params)
+	if err != nil {
+		s.log.Error("failed to create alert silence", "error", err)
+		return fmt.Errorf("error creating alert silence: %w", err)
+	}
+
+	created, err := s.GetAlertSilence(ctx, models.SilenceID(id))
+	if err != nil {
+		return err
+	}
+	*silence = *created
+	return nil
+}
+
+// GetAlertSilence returns a silence by id, or models.ErrNotFound if missing.
+func (s *Store) GetAlertSilence(ctx context.Context, id models.SilenceID) (*models.AlertSilence, error) {
+	row, err := s.q.GetAlertSilence(ctx, int64(id))
+	if err != nil {
+		if notFound(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting alert silence id %d: %w", id, err)
+	}
+	return mapAlertSilenceRow(row)
+}
+
+// ListAlertSilences returns every silence, newest start first.
+func (s *Store) ListAlertSilences(ctx context.Context) ([]*models.AlertSilence, error) {
+	rows, err := s.q.ListAlertSilences(ctx)
+	if err != nil {
+		s.log.Error("failed to list alert silences", "error", err)
+		return nil, fmt.Errorf("error listing alert silences: %w", err)
+	}
+	return mapAlertSilenceRows(rows)
+}
+
+// ListUnexpiredAlertSilences returns the silences that are open-ended or end
+// after now.
+func (s *Store) ListUnexpiredAlertSilences(ctx context.Context, now time.Time) ([]*models.AlertSilence, error) {
+	rows, err := s.q.ListUnexpiredAlertSilences(ctx, ts(now))
+	if err != nil {
+		return nil, fmt.Errorf("error listing unexpired alert silences: %w", err)
+	}
+	return mapAlertSilenceRows(rows)
+}
+
+// UpdateAlertSilence overwrites a silence's reason and schedule. Returns
+// models.ErrNotFound when the id does not exist.
+func (s *Store) UpdateAlertSilence(ctx context.Context, silence *models.AlertSilence) error {
+	if silence == nil {
+		return fmt.Errorf("silence payload is required")
+	}
+	recurrence, err := alertjson.Encode(silence.Recurrence, silence.Recurrence == nil)
+	if err != nil {
+		return fmt.Errorf("error encoding silence recurrence: %w", err)
+	}
+	_, err = s.q.UpdateAlertSilence(ctx, sqlc.UpdateAlertSilenceParams{
+		Reason:         silence.Reason,
+		StartsAt:       ts(silence.StartsAt),
+		EndsAt:         tsFromPtr(silence.EndsAt),
+		RecurrenceJson: recurrence,
+		ID:             int64(silence.ID),
+	})
+	if err != nil {
+		if notFound(err) {
+			return models.ErrNotFound
+		}
+		s.log.Error("failed to update alert silence", "error", err, "silence_id", silence.ID)
+		return fmt.Errorf("error updating alert silence: %w", err)
+	}
+	return nil
+}
+
+// DeleteAlertSilence removes a silence. Returns models.ErrNotFound when the id
+// does not exist.
+func (s *Store) DeleteAlertSilence(ctx context.Context, id models.SilenceID) error {
+	if _, err := s.q.DeleteAlertSilence(ctx, int64(id)); err != nil {
+		if notFound(err) {
+			return models.ErrNotFound
+		}
+		s.log.Error("failed to delete alert silence", "error", err, "silence_id", id)
+		return fmt.Errorf("error deleting alert silence: %w", err)
+	}
+	return nil
+}
+
+func mapAlertSilenceRows(rows []sqlc.AlertSilence) ([]*models.AlertSilence, error) {
+	silences := make([]*models.AlertSilence, 0, len(rows))
+	for _, row := range rows {
+		silence, err := mapAlertSilenceRow(row)
+		if err != nil {
+			return nil, err
+		}
+		silences = append(silences, silence)
+	}
+	return silences, nil
+}
+
+func mapAlertSilenceRow(row sqlc.AlertSilence) (*models.AlertSilence, error) {
+	recurrence, err := alertjson.Decode[*models.SilenceRecurrence](row.RecurrenceJson)
+	if err != nil {
+		return nil, fmt.Errorf("decoding recurrence of alert silence %d: %w", row.ID, err)
+	}
+	silence := &models.AlertSilence{
+		ID:         models.SilenceID(row.ID),
+		Reason:     row.Reason,
+		StartsAt:   row.StartsAt.Time,
+		EndsAt:     tsPtr(row.EndsAt),
+		Recurrence: recurrence,
+		Timestamps: models.Timestamps{
+			CreatedAt: row.CreatedAt.Time,
+			UpdatedAt: row.UpdatedAt.Time,
+		},
+	}
+	if row.AlertID.Valid {
+		id := models.AlertID(row.AlertID.Int64)
+		silence.AlertID = &id
+	}
+	if row.SourceID.Valid {
+		id := models.SourceID(row.SourceID.Int64)
+		silence.SourceID = &id
+	}
+	if row.TeamID.Valid {
+		id := models.TeamID(row.TeamID.Int64)
+		silence.TeamID = &id
+	}
+	if row.CreatedBy.Valid {
+		uid := models.UserID(row.CreatedBy.Int64)
+		silence.CreatedBy = &uid
+	}
+	return silence, nil
+}
@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/mr-karan/logchef/internal/store/postgres/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// CreateSLO inserts a new SLO and repopulates the model with the persisted row
+// (id and timestamps).
+func (s *Store) CreateSLO(ctx context.Context, slo *models.SLO) error {
+	if slo == nil {
+		return fmt.Errorf("slo payload is required")
+	}
+	params := sqlc.CreateSLOParams{
+		TeamID:        int64(slo.TeamID),
+		SourceID:      int64(slo.SourceID),
+		Name:          slo.Name,
+		Description:   slo.Description,
+		QueryLanguage: string(slo.QueryLanguage),
+		GoodQuery:     slo.GoodQuery,
+		TotalQuery:    slo.TotalQuery,
+		Target:        slo.Target,
+		WindowSeconds: int64(slo.WindowSeconds),
+	}
+	if slo.CreatedBy != nil {
+		params.CreatedBy = int8Val(int64(*slo.CreatedBy))
+	}
+
+	id, err := s.q.CreateSLO(ctx, params)
+	if err != nil {
+		s.log.Error("failed to create slo", "error", err, "team_id", slo.TeamID)
+		return fmt.Errorf("error creating slo: %w", err)
+	}
+
+	created, err := s.GetSLO(ctx, models.SLOID(id))
+	if err != nil {
+		return err
+	}
+	*slo = *created
+	return nil
+}
+
+// GetSLO returns an SLO by id, or models.ErrNotFound if missing.
+func (s *Store) GetSLO(ctx context.Context, id models.SLOID) (*models.SLO, error) {
+	row, err := s.q.GetSLO(ctx, int64(id))
+	if err != nil {
+		if notFound(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting slo id %d: %w", id, err)
+	}
+	return mapSLORow(row), nil
+}
+
+// ListSLOsByTeam returns a team's SLOs ordered by name.
+func (s *Store) ListSLOsByTeam(ctx context.Context, teamID models.TeamID) ([]*models.SLO, error) {
+	rows, err := s.q.ListSLOsByTeam(ctx, int64(teamID))
+	if err != nil {
+		s.log.Error("failed to list slos", "error", err, "team_id", teamID)
+		return nil, fmt.Errorf("error listing slos: %w", err)
+	}
+	return mapSLORows(rows), nil
+}
+
+// ListSLOs returns every SLO.
+func (s *Store) ListSLOs(ctx context.Context) ([]*models.SLO, error) {
+	rows, err := s.q.ListSLOs(ctx)
+	if err != nil {
+		s.log.Error("failed to list slos", "error", err)
+		return nil, fmt.Errorf("error listing slos: %w", err)
+	}
+	return mapSLORows(rows), nil
+}
+
+// UpdateSLO overwrites an SLO's mutable fields. Returns models.ErrNotFound
+// when the id does not exist.
+func (s *Store) UpdateSLO(ctx context.Context, slo *models.SLO) error {
+	if slo == nil {
+		return fmt.Errorf("slo payload is required")
+	}
+	_, err := s.q.UpdateSLO(ctx, sqlc.UpdateSLOParams{
+		Name:          slo.Name,
+		Description:   slo.Description,
+		QueryLanguage: string(slo.QueryLanguage),
+		GoodQuery:     slo.GoodQuery,
+		TotalQuery:    slo.TotalQuery,
+		Target:        slo.Target,
+		WindowSeconds: int64(slo.WindowSeconds),
+		ID:            int64(slo.ID),
+	})
+	if err != nil {
+		if notFound(err) {
+			return models.ErrNotFound
+		}
+		s.log.Error("failed to update slo", "error", err, "slo_id", slo.ID)
+		return fmt.Errorf("error updating slo: %w", err)
+	}
+	return nil
+}
+
+// DeleteSLO removes an SLO together with its evaluations and burn-rate
+// alerts. Returns models.ErrNotFound when the id does not exist.
+func (s *Store) DeleteSLO(ctx context.Context, id models.SLOID) error {
+	if _, err := s.q.DeleteSLO(ctx, int64(id)); err != nil {
+		if notFound(err) {
+			return models.ErrNotFound
+		}
+		s.log.Error("failed to delete slo", "error", err, "slo_id", id)
+		return fmt.Errorf("error deleting slo: %w", err)
+	}
+	return nil
+}
+
+// InsertSLOEvaluation appends one evaluation and populates its ID.
+func (s *Store) InsertSLOEvaluation(ctx context.Context, eval *models.SLOEvaluation) error {
+	id, err := s.q.InsertSLOEvaluation(ctx, sqlc.InsertSLOEvaluationParams{
+		SloID:                int64(eval.SLOID),
+		Good:                 eval.Good,
+		Total:                eval.Total,
+		Compliance:           float8FromPtr(eval.Compliance),
+		BurnRate:             float8FromPtr(eval.BurnRate),
+		ErrorBudgetRemaining: float8FromPtr(eval.ErrorBudgetRemaining),
+		Error:                eval.Error,
+		EvaluatedAt:          ts(eval.EvaluatedAt),
+	})
+	if err != nil {
+		return fmt.Errorf("error inserting slo evaluation: %w", err)
+	}
+	eval.ID = id
+	return nil
+}
+
+// ListSLOEvaluations returns an SLO's newest evaluations, newest first.
+func (s *Store) ListSLOEvaluations(ctx context.Context, id models.SLOID, limit int) ([]*models.SLOEvaluation, error) {
+	rows, err := s.q.ListSLOEvaluations(ctx, sqlc.ListSLOEvaluationsParams{
+		SloID: int64(id),
+		Limit: int32(limit), //nolint:gosec // G115: query limit, small bounded value
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing slo evaluations: %w", err)
+	}
+	evals := make([]*models.SLOEvaluation, 0, len(rows))
+	for _, row := range rows {
+		evals = append(evals, &models.SLOEvaluation{
+			ID:                   row.ID,
+			SLOID:                models.SLOID(row.SloID),
+			Good:                 row.Good,
+			Total:                row.Total,
+			Compliance:           float8Ptr(row.Compliance),
+			BurnRate:             float8Ptr(row.BurnRate),
+			ErrorBudgetRemaining: float8Ptr(row.ErrorBudgetRemaining),
+			Error:                row.Error,
+			EvaluatedAt:          row.EvaluatedAt.Time,
+		})
+	}
+	return evals, nil
+}
+
+// PruneSLOEvaluations keeps the most recent keep evaluations of an SLO.
+func (s *Store) PruneSLOEvaluations(ctx context.Context, id models.SLOID, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	if err := s.q.PruneSLOEvaluations(ctx, sqlc.PruneSLOEvaluationsParams{
+		SloID:   int64(id),
+		SloID_2: int64(id),
+		Limit:   int32(keep), //nolint:gosec // G115: retention count, small bounded value
+	}); err != nil {
+		return fmt.Errorf("error pruning slo evaluations: %w", err)
+	}
+	return nil
+}
+
+func mapSLORows(rows []sqlc.Slo) []*models.SLO {
+	slos := make([]*models.SLO, 0, len(rows))
+	for _, row := range rows {
+		slos = append(slos, mapSLORow(row))
+	}
+	return slos
+}
+
+func mapSLORow(row sqlc.Slo) *models.SLO {
+	return &models.SLO{
+		ID:            models.SLOID(row.ID),
+		TeamID:        models.TeamID(row.TeamID),
+		SourceID:      models.SourceID(row.SourceID),
+		Name:          row.Name,
+		Description:   row.Description,
+		QueryLanguage: models.QueryLanguage(row.QueryLanguage),
+		GoodQuery:     row.GoodQuery,
+		TotalQuery:    row.TotalQuery,
+		Target:        row.Target,
+		WindowSeconds: int(row.WindowSeconds),
+		CreatedBy:     userIDPtr(row.CreatedBy),
+		Timestamps: models.Timestamps{
+			CreatedAt: row.CreatedAt.Time,
+			UpdatedAt: row.UpdatedAt.Time,
+		},
+	}
+}
+
+func float8Ptr(v pgtype.Float8) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Float64
+}
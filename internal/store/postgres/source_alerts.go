@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store/postgres/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// InsertSourceAlertEvent stores a source degradation alert event.
+func (s *Store) InsertSourceAlertEvent(ctx context.Context, event *models.SourceAlertEvent) error {
+	id, err := s.q.InsertSourceAlertEvent(ctx, sqlc.InsertSourceAlertEventParams{
+		SourceID:  int64(event.SourceID),
+		Kind:      string(event.Kind),
+		Status:    string(event.Status),
+		Value:     event.Value,
+		Message:   event.Message,
+		CreatedAt: ts(event.CreatedAt),
+	})
+	if err != nil {
+		s.log.Error("failed to insert source alert event", "error", err, "source_id", event.SourceID)
+		return fmt.Errorf("error saving alert event for source %d: %w", event.SourceID, err)
+	}
+	event.ID = id
+	return nil
+}
+
+// ListSourceAlertEvents returns up to limit alert events, newest first.
+func (s *Store) ListSourceAlertEvents(ctx context.Context, sourceID models.SourceID, limit int) ([]*models.SourceAlertEvent, error) {
+	rows, err := s.q.ListSourceAlertEvents(ctx, sqlc.ListSourceAlertEventsParams{
+		SourceID: int64(sourceID),
+		Limit:    int32(limit), //nolint:gosec // G115: history limit, small bounded value
+	})
+	if err != nil {
+		s.log.Error("failed to list source alert events", "error", err, "source_id", sourceID)
+		return nil, fmt.Errorf("error listing alert events for source %d: %w", sourceID, err)
+	}
+	events := make([]*models.SourceAlertEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, &models.SourceAlertEvent{
+			ID:        row.ID,
+			SourceID:  models.SourceID(row.SourceID),
+			Kind:      models.SourceAlertKind(row.Kind),
+			Status:    models.AlertStatus(row.Status),
+			Value:     row.Value,
+			Message:   row.Message,
+			CreatedAt: row.CreatedAt.Time,
+		})
+	}
+	return events, nil
+}
+
+// DeleteSourceAlertEventsBefore removes alert events created before the cutoff.
+func (s *Store) DeleteSourceAlertEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	n, err := s.q.DeleteSourceAlertEventsBefore(ctx, ts(before))
+	if err != nil {
+		s.log.Error("failed to prune source alert events", "error", err)
+		return 0, fmt.Errorf("error pruning source alert events: %w", err)
+	}
+	return n, nil
+}
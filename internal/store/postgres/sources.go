@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/mr-karan/logchef/internal/secrets"
 	"github.com/mr-karan/logchef/internal/store/postgres/sqlc"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
-func sourceToModel(r sqlc.Source) *models.Source {
+// sourceToModel maps a row to a source, opening its sealed connection
+// secrets. A secret that can't be opened is logged and left sealed, so the
+// source fails to connect rather than failing every listing.
+func (s *Store) sourceToModel(r sqlc.Source) *models.Source {
 	source := &models.Source{
 		ID:                models.SourceID(r.ID),
 		Name:              r.Name,
@@ -20,10 +24,19 @@ func sourceToModel(r sqlc.Source) *models.Source {
 		TTLDays:           int(r.TtlDays),
 		ConnectionConfig:  r.ConnectionConfig,
 		IdentityKey:       r.IdentityKey,
+		Tags:              models.DecodeSourceTags(r.Tags),
+		ExtractionRules:   models.DecodeExtractionRules(r.ExtractionRules),
+		TraceCorrelation:  models.DecodeTraceCorrelation(r.TraceCorrelation),
+		MaskedColumns:     models.DecodeMaskedColumns(r.MaskedColumns),
 		Timestamps:        models.Timestamps{CreatedAt: r.CreatedAt.Time, UpdatedAt: r.UpdatedAt.Time},
 		Managed:           r.Managed,
 		SecretRef:         textStr(r.SecretRef),
 	}
+	if conn, err := s.secrets.OpenConnection(source.SourceType, source.ConnectionConfig); err != nil {
+		s.log.Error("failed to decrypt source secrets", "error", err, "source_id", r.ID)
+	} else {
+		source.ConnectionConfig = conn
+	}
 	_ = source.HydrateConnection()
 	return source
 }
@@ -33,18 +46,26 @@ func (s *Store) CreateSource(ctx context.Context, source *models.Source) error {
 	if err := source.SyncConnectionConfig(); err != nil {
 		return fmt.Errorf("prepare source connection config: %w", err)
 	}
+	connectionConfig, err := s.secrets.SealConnection(source.SourceType, source.ConnectionConfig)
+	if err != nil {
+		return fmt.Errorf("encrypt source secrets: %w", err)
+	}
 	id, err := s.q.CreateSource(ctx, sqlc.CreateSourceParams{
 		Name:              source.Name,
 		MetaIsAutoCreated: source.MetaIsAutoCreated,
 		SourceType:        source.SourceType.String(),
 		MetaTsField:       source.MetaTSField,
 		MetaSeverityField: text(source.MetaSeverityField),
-		ConnectionConfig:  source.ConnectionConfig,
+		ConnectionConfig:  connectionConfig,
 		IdentityKey:       source.IdentityKey,
 		Description:       text(source.Description),
 		TtlDays:           int64(source.TTLDays),
 		Managed:           source.Managed,
 		SecretRef:         text(source.SecretRef),
+		Tags:              []byte(source.Tags.Encode()),
+		ExtractionRules:   []byte(source.ExtractionRules.Encode()),
+		TraceCorrelation:  []byte(source.TraceCorrelation.Encode()),
+		MaskedColumns:     []byte(source.MaskedColumns.Encode()),
 	})
 	if err != nil {
 		if isUniqueViolation(err) {
@@ -70,7 +91,7 @@ func (s *Store) GetSource(ctx context.Context, id models.SourceID) (*models.Sour
 		}
 		return nil, fmt.Errorf("getting source id %d: %w", id, err)
 	}
-	return sourceToModel(row), nil
+	return s.sourceToModel(row), nil
 }
 
 // GetSourceByIdentityKey retrieves a source by its provider-computed identity
@@ -84,7 +105,7 @@ func (s *Store) GetSourceByIdentityKey(ctx context.Context, identityKey string)
 		s.log.Error("failed to get source by identity key from db", "error", err, "identity_key", identityKey)
 		return nil, fmt.Errorf("error getting source by identity key: %w", err)
 	}
-	return sourceToModel(row), nil
+	return s.sourceToModel(row), nil
 }
 
 // ListSources retrieves all sources, ordered by creation date.
@@ -96,7 +117,7 @@ func (s *Store) ListSources(ctx context.Context) ([]*models.Source, error) {
 	}
 	sources := make([]*models.Source, 0, len(rows))
 	for i := range rows {
-		sources = append(sources, sourceToModel(rows[i]))
+		sources = append(sources, s.sourceToModel(rows[i]))
 	}
 	return sources, nil
 }
@@ -106,18 +127,26 @@ func (s *Store) UpdateSource(ctx context.Context, source *models.Source) error {
 	if err := source.SyncConnectionConfig(); err != nil {
 		return fmt.Errorf("prepare source connection config: %w", err)
 	}
-	err := s.q.UpdateSource(ctx, sqlc.UpdateSourceParams{
+	connectionConfig, err := s.secrets.SealConnection(source.SourceType, source.ConnectionConfig)
+	if err != nil {
+		return fmt.Errorf("encrypt source secrets: %w", err)
+	}
+	err = s.q.UpdateSource(ctx, sqlc.UpdateSourceParams{
 		Name:              source.Name,
 		MetaIsAutoCreated: source.MetaIsAutoCreated,
 		SourceType:        source.SourceType.String(),
 		MetaTsField:       source.MetaTSField,
 		MetaSeverityField: text(source.MetaSeverityField),
-		ConnectionConfig:  source.ConnectionConfig,
+		ConnectionConfig:  connectionConfig,
 		IdentityKey:       source.IdentityKey,
 		Description:       text(source.Description),
 		TtlDays:           int64(source.TTLDays),
 		Managed:           source.Managed,
 		SecretRef:         text(source.SecretRef),
+		Tags:              []byte(source.Tags.Encode()),
+		ExtractionRules:   []byte(source.ExtractionRules.Encode()),
+		TraceCorrelation:  []byte(source.TraceCorrelation.Encode()),
+		MaskedColumns:     []byte(source.MaskedColumns.Encode()),
 		ID:                int64(source.ID),
 	})
 	if err != nil {
@@ -135,3 +164,37 @@ func (s *Store) DeleteSource(ctx context.Context, id models.SourceID) error {
 	}
 	return nil
 }
+
+// SealSourceSecrets re-seals the connection secrets of every source that has
+// them in plaintext or under a previous key.
+func (s *Store) SealSourceSecrets(ctx context.Context) (int, error) {
+	if s.secrets == nil {
+		return 0, secrets.ErrNoKey
+	}
+	rows, err := s.q.ListSources(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error listing sources: %w", err)
+	}
+	sealed := 0
+	for _, row := range rows {
+		sourceType := models.SourceType(row.SourceType)
+		if !s.secrets.NeedsSeal(sourceType, row.ConnectionConfig) {
+			continue
+		}
+		conn, err := s.secrets.OpenConnection(sourceType, row.ConnectionConfig)
+		if err != nil {
+			return sealed, fmt.Errorf("source %d: %w", row.ID, err)
+		}
+		if conn, err = s.secrets.SealConnection(sourceType, conn); err != nil {
+			return sealed, fmt.Errorf("source %d: %w", row.ID, err)
+		}
+		if err := s.q.UpdateSourceConnectionConfig(ctx, sqlc.UpdateSourceConnectionConfigParams{
+			ConnectionConfig: conn,
+			ID:               row.ID,
+		}); err != nil {
+			return sealed, fmt.Errorf("error updating source %d: %w", row.ID, err)
+		}
+		sealed++
+	}
+	return sealed, nil
+}
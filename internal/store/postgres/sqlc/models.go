@@ -34,6 +34,12 @@ type Alert struct {
 	UpdatedAt            pgtype.Timestamptz `json:"updated_at"`
 	QueryLanguage        string             `json:"query_language"`
 	EditorMode           string             `json:"editor_mode"`
+	ChannelsJson         pgtype.Text        `json:"channels_json"`
+	SloID                pgtype.Int8        `json:"slo_id"`
+	Managed              bool               `json:"managed"`
+	ForSeconds           int64              `json:"for_seconds"`
+	PendingSince         pgtype.Timestamptz `json:"pending_since"`
+	BreachCount          int64              `json:"breach_count"`
 }
 
 type AlertHistory struct {
@@ -48,6 +54,39 @@ type AlertHistory struct {
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 }
 
+type AlertLease struct {
+	AlertID   int64              `json:"alert_id"`
+	Holder    string             `json:"holder"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+}
+
+type AlertSilence struct {
+	ID             int64              `json:"id"`
+	AlertID        pgtype.Int8        `json:"alert_id"`
+	SourceID       pgtype.Int8        `json:"source_id"`
+	TeamID         pgtype.Int8        `json:"team_id"`
+	Reason         string             `json:"reason"`
+	StartsAt       pgtype.Timestamptz `json:"starts_at"`
+	EndsAt         pgtype.Timestamptz `json:"ends_at"`
+	RecurrenceJson string             `json:"recurrence_json"`
+	CreatedBy      pgtype.Int8        `json:"created_by"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Annotation struct {
+	ID        int64              `json:"id"`
+	TeamID    int64              `json:"team_id"`
+	SourceID  pgtype.Int8        `json:"source_id"`
+	Text      string             `json:"text"`
+	TagsJson  string             `json:"tags_json"`
+	StartsAt  pgtype.Timestamptz `json:"starts_at"`
+	EndsAt    pgtype.Timestamptz `json:"ends_at"`
+	CreatedBy pgtype.Int8        `json:"created_by"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
 type ApiToken struct {
 	ID         int64              `json:"id"`
 	UserID     int64              `json:"user_id"`
@@ -61,6 +100,19 @@ type ApiToken struct {
 	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
 }
 
+type AuditEvent struct {
+	ID           int64              `json:"id"`
+	UserID       pgtype.Int8        `json:"user_id"`
+	UserEmail    string             `json:"user_email"`
+	Action       string             `json:"action"`
+	ResourceType string             `json:"resource_type"`
+	ResourceID   string             `json:"resource_id"`
+	TeamID       pgtype.Int8        `json:"team_id"`
+	IpAddress    string             `json:"ip_address"`
+	Details      pgtype.Text        `json:"details"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
 type Collection struct {
 	ID          int64              `json:"id"`
 	Name        string             `json:"name"`
@@ -115,6 +167,44 @@ type ExportJob struct {
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 }
 
+type LogBookmark struct {
+	ID           int64              `json:"id"`
+	TeamID       int64              `json:"team_id"`
+	SourceID     int64              `json:"source_id"`
+	UserID       int64              `json:"user_id"`
+	Shared       bool               `json:"shared"`
+	LogTimestamp pgtype.Timestamptz `json:"log_timestamp"`
+	Fingerprint  string             `json:"fingerprint"`
+	PayloadJson  string             `json:"payload_json"`
+	Note         string             `json:"note"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Notebook struct {
+	ID          int64              `json:"id"`
+	TeamID      int64              `json:"team_id"`
+	Name        string             `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	CellsJson   string             `json:"cells_json"`
+	CreatedBy   pgtype.Int8        `json:"created_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type NotebookSnapshot struct {
+	ID         string             `json:"id"`
+	NotebookID int64              `json:"notebook_id"`
+	TeamID     int64              `json:"team_id"`
+	Format     string             `json:"format"`
+	FileName   string             `json:"file_name"`
+	ObjectKey  string             `json:"object_key"`
+	SizeBytes  int64              `json:"size_bytes"`
+	CreatedBy  pgtype.Int8        `json:"created_by"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
 type QueryHistory struct {
 	ID            int64              `json:"id"`
 	UserID        int64              `json:"user_id"`
@@ -125,6 +215,8 @@ type QueryHistory struct {
 	DurationMs    int64              `json:"duration_ms"`
 	RowCount      int64              `json:"row_count"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	Status        string             `json:"status"`
+	ErrorMessage  string             `json:"error_message"`
 }
 
 type QueryShare struct {
@@ -138,6 +230,17 @@ type QueryShare struct {
 	CreatedAt      pgtype.Timestamptz `json:"created_at"`
 }
 
+type QuerySnippet struct {
+	ID          int64              `json:"id"`
+	TeamID      int64              `json:"team_id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Query       string             `json:"query"`
+	CreatedBy   pgtype.Int8        `json:"created_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
 type QueryStatsDaily struct {
 	BucketDate      pgtype.Date `json:"bucket_date"`
 	UserID          int64       `json:"user_id"`
@@ -146,6 +249,7 @@ type QueryStatsDaily struct {
 	QueryLanguage   string      `json:"query_language"`
 	QueryCount      int64       `json:"query_count"`
 	TotalDurationMs int64       `json:"total_duration_ms"`
+	RowsRead        int64       `json:"rows_read"`
 }
 
 type SavedQuery struct {
@@ -169,6 +273,34 @@ type Session struct {
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
+type Slo struct {
+	ID            int64              `json:"id"`
+	TeamID        int64              `json:"team_id"`
+	SourceID      int64              `json:"source_id"`
+	Name          string             `json:"name"`
+	Description   string             `json:"description"`
+	QueryLanguage string             `json:"query_language"`
+	GoodQuery     string             `json:"good_query"`
+	TotalQuery    string             `json:"total_query"`
+	Target        float64            `json:"target"`
+	WindowSeconds int64              `json:"window_seconds"`
+	CreatedBy     pgtype.Int8        `json:"created_by"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+}
+
+type SloEvaluation struct {
+	ID                   int64              `json:"id"`
+	SloID                int64              `json:"slo_id"`
+	Good                 float64            `json:"good"`
+	Total                float64            `json:"total"`
+	Compliance           pgtype.Float8      `json:"compliance"`
+	BurnRate             pgtype.Float8      `json:"burn_rate"`
+	ErrorBudgetRemaining pgtype.Float8      `json:"error_budget_remaining"`
+	Error                string             `json:"error"`
+	EvaluatedAt          pgtype.Timestamptz `json:"evaluated_at"`
+}
+
 type Source struct {
 	ID                int64              `json:"id"`
 	Name              string             `json:"name"`
@@ -184,6 +316,50 @@ type Source struct {
 	SourceType        string             `json:"source_type"`
 	ConnectionConfig  []byte             `json:"connection_config"`
 	IdentityKey       string             `json:"identity_key"`
+	Tags              []byte             `json:"tags"`
+	ExtractionRules   []byte             `json:"extraction_rules"`
+	TraceCorrelation  []byte             `json:"trace_correlation"`
+	MaskedColumns     []byte             `json:"masked_columns"`
+}
+
+type SourceAlertEvent struct {
+	ID        int64              `json:"id"`
+	SourceID  int64              `json:"source_id"`
+	Kind      string             `json:"kind"`
+	Status    string             `json:"status"`
+	Value     float64            `json:"value"`
+	Message   string             `json:"message"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type SourceRollup struct {
+	SourceID      int64              `json:"source_id"`
+	Dimension     string             `json:"dimension"`
+	RolledUpFrom  pgtype.Timestamptz `json:"rolled_up_from"`
+	RolledUpUntil pgtype.Timestamptz `json:"rolled_up_until"`
+	LastRunAt     pgtype.Timestamptz `json:"last_run_at"`
+	LastError     string             `json:"last_error"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+}
+
+type SourceSchemaSnapshot struct {
+	ID         int64              `json:"id"`
+	SourceID   int64              `json:"source_id"`
+	Columns    []byte             `json:"columns"`
+	Changes    []byte             `json:"changes"`
+	CapturedAt pgtype.Timestamptz `json:"captured_at"`
+}
+
+type SourceStatsSnapshot struct {
+	SourceID          int64              `json:"source_id"`
+	SnapshotDate      string             `json:"snapshot_date"`
+	TotalRows         int64              `json:"total_rows"`
+	PartCount         int64              `json:"part_count"`
+	CompressedBytes   int64              `json:"compressed_bytes"`
+	UncompressedBytes int64              `json:"uncompressed_bytes"`
+	Columns           []byte             `json:"columns"`
+	CapturedAt        pgtype.Timestamptz `json:"captured_at"`
 }
 
 type SystemSetting struct {
@@ -198,10 +374,20 @@ type SystemSetting struct {
 }
 
 type Team struct {
-	ID          int64              `json:"id"`
-	Name        string             `json:"name"`
-	Description pgtype.Text        `json:"description"`
-	Managed     bool               `json:"managed"`
+	ID            int64              `json:"id"`
+	Name          string             `json:"name"`
+	Description   pgtype.Text        `json:"description"`
+	Managed       bool               `json:"managed"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	RawSqlEnabled bool               `json:"raw_sql_enabled"`
+}
+
+type TeamColumnPreset struct {
+	TeamID      int64              `json:"team_id"`
+	SourceID    int64              `json:"source_id"`
+	ColumnsJson string             `json:"columns_json"`
+	UpdatedBy   pgtype.Int8        `json:"updated_by"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 }
@@ -217,6 +403,18 @@ type TeamSource struct {
 	TeamID    int64              `json:"team_id"`
 	SourceID  int64              `json:"source_id"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	RowFilter string             `json:"row_filter"`
+}
+
+type TrackedQuery struct {
+	ID        string             `json:"id"`
+	Instance  string             `json:"instance"`
+	Class     string             `json:"class"`
+	UserID    int64              `json:"user_id"`
+	TeamID    int64              `json:"team_id"`
+	SourceID  int64              `json:"source_id"`
+	QueryText string             `json:"query_text"`
+	StartedAt pgtype.Timestamptz `json:"started_at"`
 }
 
 type User struct {
@@ -234,9 +432,29 @@ type User struct {
 	PasswordHash pgtype.Text        `json:"password_hash"`
 }
 
+type UserColumnPreset struct {
+	UserID      int64              `json:"user_id"`
+	SourceID    int64              `json:"source_id"`
+	ColumnsJson string             `json:"columns_json"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
 type UserPreference struct {
 	UserID          int64              `json:"user_id"`
 	PreferencesJson string             `json:"preferences_json"`
 	CreatedAt       pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
 }
+
+type Webhook struct {
+	ID             int64              `json:"id"`
+	Name           string             `json:"name"`
+	Url            string             `json:"url"`
+	EventTypesJson string             `json:"event_types_json"`
+	Secret         string             `json:"secret"`
+	IsActive       bool               `json:"is_active"`
+	CreatedBy      pgtype.Int8        `json:"created_by"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
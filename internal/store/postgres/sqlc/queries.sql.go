@@ -11,6 +11,38 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const acquireAlertLease = `-- name: AcquireAlertLease :execrows
+
+INSERT INTO alert_leases (alert_id, holder, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT(alert_id) DO UPDATE
+SET holder = excluded.holder, expires_at = excluded.expires_at
+WHERE alert_leases.holder = excluded.holder OR alert_leases.expires_at < $4
+`
+
+type AcquireAlertLeaseParams struct {
+	AlertID   int64              `json:"alert_id"`
+	Holder    string             `json:"holder"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	Now       pgtype.Timestamptz `json:"now"`
+}
+
+// Alert evaluation leases -------------------------------------------------------
+// Claim an alert for evaluation, or extend the holder's own claim. Another
+// instance's lease is only taken over once it has expired.
+func (q *Queries) AcquireAlertLease(ctx context.Context, arg AcquireAlertLeaseParams) (int64, error) {
+	result, err := q.db.Exec(ctx, acquireAlertLease,
+		arg.AlertID,
+		arg.Holder,
+		arg.ExpiresAt,
+		arg.Now,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const addCollectionItem = `-- name: AddCollectionItem :exec
 INSERT INTO collection_items (collection_id, saved_query_id, sort_order, added_by)
 VALUES ($1, $2, $3, $4)
@@ -156,6 +188,40 @@ func (q *Queries) CountAdminUsers(ctx context.Context, arg CountAdminUsersParams
 	return count, err
 }
 
+const countQueryHistory = `-- name: CountQueryHistory :one
+SELECT COUNT(*) FROM query_history qh
+WHERE (qh.user_id = $1 OR $1 IS NULL)
+  AND (qh.team_id = $2 OR $2 IS NULL)
+  AND (qh.source_id = $3 OR $3 IS NULL)
+  AND (qh.status = $4 OR $4 IS NULL)
+  AND (strpos(lower(qh.query_text), lower($5)) > 0 OR $5 IS NULL)
+  AND (qh.created_at >= $6 OR $6 IS NULL)
+`
+
+type CountQueryHistoryParams struct {
+	UserID   pgtype.Int8        `json:"user_id"`
+	TeamID   pgtype.Int8        `json:"team_id"`
+	SourceID pgtype.Int8        `json:"source_id"`
+	Status   pgtype.Text        `json:"status"`
+	Search   pgtype.Text        `json:"search"`
+	Since    pgtype.Timestamptz `json:"since"`
+}
+
+// Count the history entries matching ListQueryHistory's filters.
+func (q *Queries) CountQueryHistory(ctx context.Context, arg CountQueryHistoryParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countQueryHistory,
+		arg.UserID,
+		arg.TeamID,
+		arg.SourceID,
+		arg.Status,
+		arg.Search,
+		arg.Since,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countSharedCollectionEditAccess = `-- name: CountSharedCollectionEditAccess :one
 SELECT COUNT(*)
 FROM collection_items ci
@@ -250,12 +316,15 @@ INSERT INTO alerts (
     annotations_json,
     recipient_user_ids_json,
     webhook_urls_json,
+    channels_json,
+    slo_id,
     generator_url,
     is_active,
-    created_by
+    created_by,
+    for_seconds
 )
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
-RETURNING id, source_id, name, description, query, condition_json, lookback_seconds, threshold_operator, threshold_value, frequency_seconds, severity, labels_json, annotations_json, generator_url, is_active, last_state, last_evaluated_at, last_triggered_at, recipient_user_ids_json, webhook_urls_json, created_by, created_at, updated_at, query_language, editor_mode
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+RETURNING id, source_id, name, description, query, condition_json, lookback_seconds, threshold_operator, threshold_value, frequency_seconds, severity, labels_json, annotations_json, generator_url, is_active, last_state, last_evaluated_at, last_triggered_at, recipient_user_ids_json, webhook_urls_json, created_by, created_at, updated_at, query_language, editor_mode, channels_json, slo_id, managed, for_seconds, pending_since, breach_count
 `
 
 type CreateAlertParams struct {
@@ -275,9 +344,12 @@ type CreateAlertParams struct {
 	AnnotationsJson      pgtype.Text `json:"annotations_json"`
 	RecipientUserIdsJson pgtype.Text `json:"recipient_user_ids_json"`
 	WebhookUrlsJson      pgtype.Text `json:"webhook_urls_json"`
+	ChannelsJson         pgtype.Text `json:"channels_json"`
+	SloID                pgtype.Int8 `json:"slo_id"`
 	GeneratorUrl         pgtype.Text `json:"generator_url"`
 	IsActive             bool        `json:"is_active"`
 	CreatedBy            pgtype.Int8 `json:"created_by"`
+	ForSeconds           int64       `json:"for_seconds"`
 }
 
 // Alerts
@@ -299,9 +371,12 @@ func (q *Queries) CreateAlert(ctx context.Context, arg CreateAlertParams) (Alert
 		arg.AnnotationsJson,
 		arg.RecipientUserIdsJson,
 		arg.WebhookUrlsJson,
+		arg.ChannelsJson,
+		arg.SloID,
 		arg.GeneratorUrl,
 		arg.IsActive,
 		arg.CreatedBy,
+		arg.ForSeconds,
 	)
 	var i Alert
 	err := row.Scan(
@@ -330,10 +405,86 @@ func (q *Queries) CreateAlert(ctx context.Context, arg CreateAlertParams) (Alert
 		&i.UpdatedAt,
 		&i.QueryLanguage,
 		&i.EditorMode,
+		&i.ChannelsJson,
+		&i.SloID,
+		&i.Managed,
+		&i.ForSeconds,
+		&i.PendingSince,
+		&i.BreachCount,
 	)
 	return i, err
 }
 
+const createAlertSilence = `-- name: CreateAlertSilence :one
+
+INSERT INTO alert_silences (alert_id, source_id, team_id, reason, starts_at, ends_at, recurrence_json, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id
+`
+
+type CreateAlertSilenceParams struct {
+	AlertID        pgtype.Int8        `json:"alert_id"`
+	SourceID       pgtype.Int8        `json:"source_id"`
+	TeamID         pgtype.Int8        `json:"team_id"`
+	Reason         string             `json:"reason"`
+	StartsAt       pgtype.Timestamptz `json:"starts_at"`
+	EndsAt         pgtype.Timestamptz `json:"ends_at"`
+	RecurrenceJson string             `json:"recurrence_json"`
+	CreatedBy      pgtype.Int8        `json:"created_by"`
+}
+
+// Alert silences ---------------------------------------------------------------
+// Insert a new silence and return its id.
+func (q *Queries) CreateAlertSilence(ctx context.Context, arg CreateAlertSilenceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createAlertSilence,
+		arg.AlertID,
+		arg.SourceID,
+		arg.TeamID,
+		arg.Reason,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.RecurrenceJson,
+		arg.CreatedBy,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createAnnotation = `-- name: CreateAnnotation :one
+
+INSERT INTO annotations (team_id, source_id, text, tags_json, starts_at, ends_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id
+`
+
+type CreateAnnotationParams struct {
+	TeamID    int64              `json:"team_id"`
+	SourceID  pgtype.Int8        `json:"source_id"`
+	Text      string             `json:"text"`
+	TagsJson  string             `json:"tags_json"`
+	StartsAt  pgtype.Timestamptz `json:"starts_at"`
+	EndsAt    pgtype.Timestamptz `json:"ends_at"`
+	CreatedBy pgtype.Int8        `json:"created_by"`
+}
+
+// Annotations ------------------------------------------------------------------
+// Insert a new annotation and return its id.
+func (q *Queries) CreateAnnotation(ctx context.Context, arg CreateAnnotationParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createAnnotation,
+		arg.TeamID,
+		arg.SourceID,
+		arg.Text,
+		arg.TagsJson,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.CreatedBy,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const createCollection = `-- name: CreateCollection :one
 
 INSERT INTO collections (name, description, is_personal, created_by)
@@ -440,6 +591,108 @@ func (q *Queries) CreateExportJob(ctx context.Context, arg CreateExportJobParams
 	return err
 }
 
+const createLogBookmark = `-- name: CreateLogBookmark :one
+
+INSERT INTO log_bookmarks (team_id, source_id, user_id, shared, log_timestamp, fingerprint, payload_json, note)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id
+`
+
+type CreateLogBookmarkParams struct {
+	TeamID       int64              `json:"team_id"`
+	SourceID     int64              `json:"source_id"`
+	UserID       int64              `json:"user_id"`
+	Shared       bool               `json:"shared"`
+	LogTimestamp pgtype.Timestamptz `json:"log_timestamp"`
+	Fingerprint  string             `json:"fingerprint"`
+	PayloadJson  string             `json:"payload_json"`
+	Note         string             `json:"note"`
+}
+
+// Log bookmarks ----------------------------------------------------------------
+// Pin a log row and return the bookmark id.
+func (q *Queries) CreateLogBookmark(ctx context.Context, arg CreateLogBookmarkParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createLogBookmark,
+		arg.TeamID,
+		arg.SourceID,
+		arg.UserID,
+		arg.Shared,
+		arg.LogTimestamp,
+		arg.Fingerprint,
+		arg.PayloadJson,
+		arg.Note,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createNotebook = `-- name: CreateNotebook :one
+
+INSERT INTO notebooks (team_id, name, description, cells_json, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id
+`
+
+type CreateNotebookParams struct {
+	TeamID      int64       `json:"team_id"`
+	Name        string      `json:"name"`
+	Description pgtype.Text `json:"description"`
+	CellsJson   string      `json:"cells_json"`
+	CreatedBy   pgtype.Int8 `json:"created_by"`
+}
+
+// Notebooks ------------------------------------------------------------------
+// Insert a new notebook and return its id.
+func (q *Queries) CreateNotebook(ctx context.Context, arg CreateNotebookParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createNotebook,
+		arg.TeamID,
+		arg.Name,
+		arg.Description,
+		arg.CellsJson,
+		arg.CreatedBy,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createNotebookSnapshot = `-- name: CreateNotebookSnapshot :exec
+
+INSERT INTO notebook_snapshots (id, notebook_id, team_id, format, file_name, object_key, size_bytes, created_by, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+`
+
+type CreateNotebookSnapshotParams struct {
+	ID         string             `json:"id"`
+	NotebookID int64              `json:"notebook_id"`
+	TeamID     int64              `json:"team_id"`
+	Format     string             `json:"format"`
+	FileName   string             `json:"file_name"`
+	ObjectKey  string             `json:"object_key"`
+	SizeBytes  int64              `json:"size_bytes"`
+	CreatedBy  pgtype.Int8        `json:"created_by"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+// Notebook snapshots -----------------------------------------------------------
+func (q *Queries) CreateNotebookSnapshot(ctx context.Context, arg CreateNotebookSnapshotParams) error {
+	_, err := q.db.Exec(ctx, createNotebookSnapshot,
+		arg.ID,
+		arg.NotebookID,
+		arg.TeamID,
+		arg.Format,
+		arg.FileName,
+		arg.ObjectKey,
+		arg.SizeBytes,
+		arg.CreatedBy,
+		arg.ExpiresAt,
+		arg.CreatedAt,
+	)
+	return err
+}
+
 const createQueryShare = `-- name: CreateQueryShare :exec
 
 INSERT INTO query_shares (
@@ -475,6 +728,76 @@ func (q *Queries) CreateQueryShare(ctx context.Context, arg CreateQuerySharePara
 	return err
 }
 
+const createQuerySnippet = `-- name: CreateQuerySnippet :one
+
+INSERT INTO query_snippets (team_id, name, description, query, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id
+`
+
+type CreateQuerySnippetParams struct {
+	TeamID      int64       `json:"team_id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Query       string      `json:"query"`
+	CreatedBy   pgtype.Int8 `json:"created_by"`
+}
+
+// Query snippets ---------------------------------------------------------------
+// Insert a new query snippet and return its id.
+func (q *Queries) CreateQuerySnippet(ctx context.Context, arg CreateQuerySnippetParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createQuerySnippet,
+		arg.TeamID,
+		arg.Name,
+		arg.Description,
+		arg.Query,
+		arg.CreatedBy,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createSLO = `-- name: CreateSLO :one
+
+INSERT INTO slos (team_id, source_id, name, description, query_language, good_query, total_query, target, window_seconds, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id
+`
+
+type CreateSLOParams struct {
+	TeamID        int64       `json:"team_id"`
+	SourceID      int64       `json:"source_id"`
+	Name          string      `json:"name"`
+	Description   string      `json:"description"`
+	QueryLanguage string      `json:"query_language"`
+	GoodQuery     string      `json:"good_query"`
+	TotalQuery    string      `json:"total_query"`
+	Target        float64     `json:"target"`
+	WindowSeconds int64       `json:"window_seconds"`
+	CreatedBy     pgtype.Int8 `json:"created_by"`
+}
+
+// SLOs ------------------------------------------------------------------------
+// Insert a new SLO and return its id.
+func (q *Queries) CreateSLO(ctx context.Context, arg CreateSLOParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createSLO,
+		arg.TeamID,
+		arg.SourceID,
+		arg.Name,
+		arg.Description,
+		arg.QueryLanguage,
+		arg.GoodQuery,
+		arg.TotalQuery,
+		arg.Target,
+		arg.WindowSeconds,
+		arg.CreatedBy,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const createSavedQuery = `-- name: CreateSavedQuery :one
 
 INSERT INTO saved_queries (source_id, created_from_team_id, name, description, query_language, editor_mode, query_content, created_by)
@@ -539,8 +862,8 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) er
 const createSource = `-- name: CreateSource :one
 
 INSERT INTO sources (
-    name, _meta_is_auto_created, source_type, _meta_ts_field, _meta_severity_field, connection_config, identity_key, description, ttl_days, managed, secret_ref, created_at, updated_at
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, now(), now())
+    name, _meta_is_auto_created, source_type, _meta_ts_field, _meta_severity_field, connection_config, identity_key, description, ttl_days, managed, secret_ref, tags, extraction_rules, trace_correlation, masked_columns, created_at, updated_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, now(), now())
 RETURNING id
 `
 
@@ -556,6 +879,10 @@ type CreateSourceParams struct {
 	TtlDays           int64       `json:"ttl_days"`
 	Managed           bool        `json:"managed"`
 	SecretRef         pgtype.Text `json:"secret_ref"`
+	Tags              []byte      `json:"tags"`
+	ExtractionRules   []byte      `json:"extraction_rules"`
+	TraceCorrelation  []byte      `json:"trace_correlation"`
+	MaskedColumns     []byte      `json:"masked_columns"`
 }
 
 // Sources
@@ -573,6 +900,10 @@ func (q *Queries) CreateSource(ctx context.Context, arg CreateSourceParams) (int
 		arg.TtlDays,
 		arg.Managed,
 		arg.SecretRef,
+		arg.Tags,
+		arg.ExtractionRules,
+		arg.TraceCorrelation,
+		arg.MaskedColumns,
 	)
 	var id int64
 	err := row.Scan(&id)
@@ -632,6 +963,37 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (int64,
 	return id, err
 }
 
+const createWebhook = `-- name: CreateWebhook :one
+INSERT INTO webhooks (name, url, event_types_json, secret, is_active, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id
+`
+
+type CreateWebhookParams struct {
+	Name           string      `json:"name"`
+	Url            string      `json:"url"`
+	EventTypesJson string      `json:"event_types_json"`
+	Secret         string      `json:"secret"`
+	IsActive       bool        `json:"is_active"`
+	CreatedBy      pgtype.Int8 `json:"created_by"`
+}
+
+// Webhooks ---------------------------------------------------------------------
+// Register an outbound webhook and return its id.
+func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createWebhook,
+		arg.Name,
+		arg.Url,
+		arg.EventTypesJson,
+		arg.Secret,
+		arg.IsActive,
+		arg.CreatedBy,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const deleteAPIToken = `-- name: DeleteAPIToken :exec
 DELETE FROM api_tokens WHERE id = $1 AND user_id = $2
 `
@@ -659,6 +1021,39 @@ func (q *Queries) DeleteAlert(ctx context.Context, id int64) (int64, error) {
 	return id_2, err
 }
 
+const deleteAlertLeasesByHolder = `-- name: DeleteAlertLeasesByHolder :exec
+DELETE FROM alert_leases WHERE holder = $1
+`
+
+func (q *Queries) DeleteAlertLeasesByHolder(ctx context.Context, holder string) error {
+	_, err := q.db.Exec(ctx, deleteAlertLeasesByHolder, holder)
+	return err
+}
+
+const deleteAlertSilence = `-- name: DeleteAlertSilence :one
+DELETE FROM alert_silences WHERE id = $1
+RETURNING id
+`
+
+func (q *Queries) DeleteAlertSilence(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRow(ctx, deleteAlertSilence, id)
+	var id_2 int64
+	err := row.Scan(&id_2)
+	return id_2, err
+}
+
+const deleteAnnotation = `-- name: DeleteAnnotation :one
+DELETE FROM annotations WHERE id = $1
+RETURNING id
+`
+
+func (q *Queries) DeleteAnnotation(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRow(ctx, deleteAnnotation, id)
+	var id_2 int64
+	err := row.Scan(&id_2)
+	return id_2, err
+}
+
 const deleteCollection = `-- name: DeleteCollection :exec
 DELETE FROM collections WHERE id = $1
 `
@@ -703,6 +1098,56 @@ func (q *Queries) DeleteExpiredSessions(ctx context.Context, expiresAt pgtype.Ti
 	return err
 }
 
+const deleteLogBookmark = `-- name: DeleteLogBookmark :one
+DELETE FROM log_bookmarks WHERE id = $1
+RETURNING id
+`
+
+func (q *Queries) DeleteLogBookmark(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRow(ctx, deleteLogBookmark, id)
+	var id_2 int64
+	err := row.Scan(&id_2)
+	return id_2, err
+}
+
+const deleteNotebook = `-- name: DeleteNotebook :one
+DELETE FROM notebooks WHERE id = $1
+RETURNING id
+`
+
+// Delete a notebook; RETURNING lets callers detect not-found.
+func (q *Queries) DeleteNotebook(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRow(ctx, deleteNotebook, id)
+	var id_2 int64
+	err := row.Scan(&id_2)
+	return id_2, err
+}
+
+const deleteNotebookSnapshot = `-- name: DeleteNotebookSnapshot :one
+DELETE FROM notebook_snapshots WHERE id = $1
+RETURNING id
+`
+
+func (q *Queries) DeleteNotebookSnapshot(ctx context.Context, id string) (string, error) {
+	row := q.db.QueryRow(ctx, deleteNotebookSnapshot, id)
+	var id_2 string
+	err := row.Scan(&id_2)
+	return id_2, err
+}
+
+const deleteQueryHistoryBefore = `-- name: DeleteQueryHistoryBefore :execrows
+DELETE FROM query_history WHERE created_at < $1
+`
+
+// Drop history entries older than the retention window.
+func (q *Queries) DeleteQueryHistoryBefore(ctx context.Context, createdAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteQueryHistoryBefore, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteQueryShare = `-- name: DeleteQueryShare :one
 DELETE FROM query_shares
 WHERE token = $1
@@ -717,6 +1162,31 @@ func (q *Queries) DeleteQueryShare(ctx context.Context, token string) (string, e
 	return token_2, err
 }
 
+const deleteQuerySnippet = `-- name: DeleteQuerySnippet :one
+DELETE FROM query_snippets WHERE id = $1
+RETURNING id
+`
+
+func (q *Queries) DeleteQuerySnippet(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRow(ctx, deleteQuerySnippet, id)
+	var id_2 int64
+	err := row.Scan(&id_2)
+	return id_2, err
+}
+
+const deleteSLO = `-- name: DeleteSLO :one
+DELETE FROM slos WHERE id = $1
+RETURNING id
+`
+
+// Delete an SLO; its evaluations and burn-rate alerts cascade.
+func (q *Queries) DeleteSLO(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRow(ctx, deleteSLO, id)
+	var id_2 int64
+	err := row.Scan(&id_2)
+	return id_2, err
+}
+
 const deleteSavedQuery = `-- name: DeleteSavedQuery :exec
 DELETE FROM saved_queries WHERE id = $1
 `
@@ -747,36 +1217,141 @@ func (q *Queries) DeleteSource(ctx context.Context, id int64) error {
 	return err
 }
 
-const deleteSystemSetting = `-- name: DeleteSystemSetting :exec
-DELETE FROM system_settings
-WHERE key = $1
+const deleteSourceAlertEventsBefore = `-- name: DeleteSourceAlertEventsBefore :execrows
+DELETE FROM source_alert_events WHERE created_at < $1
 `
 
-func (q *Queries) DeleteSystemSetting(ctx context.Context, key string) error {
-	_, err := q.db.Exec(ctx, deleteSystemSetting, key)
-	return err
+func (q *Queries) DeleteSourceAlertEventsBefore(ctx context.Context, createdAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSourceAlertEventsBefore, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const deleteTeam = `-- name: DeleteTeam :exec
-DELETE FROM teams WHERE id = $1
+const deleteSourceRollup = `-- name: DeleteSourceRollup :exec
+DELETE FROM source_rollups WHERE source_id = $1
 `
 
-// Delete a team by ID
-func (q *Queries) DeleteTeam(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteTeam, id)
+func (q *Queries) DeleteSourceRollup(ctx context.Context, sourceID int64) error {
+	_, err := q.db.Exec(ctx, deleteSourceRollup, sourceID)
 	return err
 }
 
-const deleteUser = `-- name: DeleteUser :exec
-DELETE FROM users WHERE id = $1
+const deleteSourceSchemaSnapshotsBefore = `-- name: DeleteSourceSchemaSnapshotsBefore :execrows
+DELETE FROM source_schema_snapshots
+WHERE source_schema_snapshots.captured_at < $1
+  AND source_schema_snapshots.id NOT IN (SELECT MAX(latest.id) FROM source_schema_snapshots AS latest GROUP BY latest.source_id)
 `
 
-// Delete a user by ID
-func (q *Queries) DeleteUser(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteUser, id)
+// Drop snapshots older than the retention window, keeping each source's
+// latest one as the baseline the next run diffs against.
+func (q *Queries) DeleteSourceSchemaSnapshotsBefore(ctx context.Context, before pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSourceSchemaSnapshotsBefore, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteSourceStatsSnapshotsBefore = `-- name: DeleteSourceStatsSnapshotsBefore :execrows
+DELETE FROM source_stats_snapshots WHERE snapshot_date < $1
+`
+
+func (q *Queries) DeleteSourceStatsSnapshotsBefore(ctx context.Context, snapshotDate string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSourceStatsSnapshotsBefore, snapshotDate)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteSystemSetting = `-- name: DeleteSystemSetting :exec
+DELETE FROM system_settings
+WHERE key = $1
+`
+
+func (q *Queries) DeleteSystemSetting(ctx context.Context, key string) error {
+	_, err := q.db.Exec(ctx, deleteSystemSetting, key)
+	return err
+}
+
+const deleteTeam = `-- name: DeleteTeam :exec
+DELETE FROM teams WHERE id = $1
+`
+
+// Delete a team by ID
+func (q *Queries) DeleteTeam(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteTeam, id)
+	return err
+}
+
+const deleteTeamColumnPreset = `-- name: DeleteTeamColumnPreset :one
+DELETE FROM team_column_presets WHERE team_id = $1 AND source_id = $2
+RETURNING source_id
+`
+
+type DeleteTeamColumnPresetParams struct {
+	TeamID   int64 `json:"team_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+func (q *Queries) DeleteTeamColumnPreset(ctx context.Context, arg DeleteTeamColumnPresetParams) (int64, error) {
+	row := q.db.QueryRow(ctx, deleteTeamColumnPreset, arg.TeamID, arg.SourceID)
+	var source_id int64
+	err := row.Scan(&source_id)
+	return source_id, err
+}
+
+const deleteTrackedQueriesBefore = `-- name: DeleteTrackedQueriesBefore :execrows
+DELETE FROM tracked_queries WHERE started_at < $1
+`
+
+// Rows no live query can still own, whichever instance wrote them.
+func (q *Queries) DeleteTrackedQueriesBefore(ctx context.Context, startedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTrackedQueriesBefore, startedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteTrackedQuery = `-- name: DeleteTrackedQuery :exec
+DELETE FROM tracked_queries WHERE id = $1
+`
+
+func (q *Queries) DeleteTrackedQuery(ctx context.Context, id string) error {
+	_, err := q.db.Exec(ctx, deleteTrackedQuery, id)
+	return err
+}
+
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users WHERE id = $1
+`
+
+// Delete a user by ID
+func (q *Queries) DeleteUser(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteUser, id)
 	return err
 }
 
+const deleteUserColumnPreset = `-- name: DeleteUserColumnPreset :one
+DELETE FROM user_column_presets WHERE user_id = $1 AND source_id = $2
+RETURNING source_id
+`
+
+type DeleteUserColumnPresetParams struct {
+	UserID   int64 `json:"user_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+func (q *Queries) DeleteUserColumnPreset(ctx context.Context, arg DeleteUserColumnPresetParams) (int64, error) {
+	row := q.db.QueryRow(ctx, deleteUserColumnPreset, arg.UserID, arg.SourceID)
+	var source_id int64
+	err := row.Scan(&source_id)
+	return source_id, err
+}
+
 const deleteUserSessions = `-- name: DeleteUserSessions :exec
 DELETE FROM sessions WHERE user_id = $1
 `
@@ -787,6 +1362,18 @@ func (q *Queries) DeleteUserSessions(ctx context.Context, userID int64) error {
 	return err
 }
 
+const deleteWebhook = `-- name: DeleteWebhook :one
+DELETE FROM webhooks WHERE id = $1
+RETURNING id
+`
+
+func (q *Queries) DeleteWebhook(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRow(ctx, deleteWebhook, id)
+	var id_2 int64
+	err := row.Scan(&id_2)
+	return id_2, err
+}
+
 const failExportJob = `-- name: FailExportJob :one
 UPDATE export_jobs
 SET
@@ -867,7 +1454,7 @@ func (q *Queries) GetAPITokenByHash(ctx context.Context, tokenHash string) (ApiT
 }
 
 const getAlert = `-- name: GetAlert :one
-SELECT id, source_id, name, description, query, condition_json, lookback_seconds, threshold_operator, threshold_value, frequency_seconds, severity, labels_json, annotations_json, generator_url, is_active, last_state, last_evaluated_at, last_triggered_at, recipient_user_ids_json, webhook_urls_json, created_by, created_at, updated_at, query_language, editor_mode FROM alerts WHERE id = $1
+SELECT id, source_id, name, description, query, condition_json, lookback_seconds, threshold_operator, threshold_value, frequency_seconds, severity, labels_json, annotations_json, generator_url, is_active, last_state, last_evaluated_at, last_triggered_at, recipient_user_ids_json, webhook_urls_json, created_by, created_at, updated_at, query_language, editor_mode, channels_json, slo_id, managed, for_seconds, pending_since, breach_count FROM alerts WHERE id = $1
 `
 
 func (q *Queries) GetAlert(ctx context.Context, id int64) (Alert, error) {
@@ -899,6 +1486,57 @@ func (q *Queries) GetAlert(ctx context.Context, id int64) (Alert, error) {
 		&i.UpdatedAt,
 		&i.QueryLanguage,
 		&i.EditorMode,
+		&i.ChannelsJson,
+		&i.SloID,
+		&i.Managed,
+		&i.ForSeconds,
+		&i.PendingSince,
+		&i.BreachCount,
+	)
+	return i, err
+}
+
+const getAlertSilence = `-- name: GetAlertSilence :one
+SELECT id, alert_id, source_id, team_id, reason, starts_at, ends_at, recurrence_json, created_by, created_at, updated_at FROM alert_silences WHERE id = $1
+`
+
+func (q *Queries) GetAlertSilence(ctx context.Context, id int64) (AlertSilence, error) {
+	row := q.db.QueryRow(ctx, getAlertSilence, id)
+	var i AlertSilence
+	err := row.Scan(
+		&i.ID,
+		&i.AlertID,
+		&i.SourceID,
+		&i.TeamID,
+		&i.Reason,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.RecurrenceJson,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getAnnotation = `-- name: GetAnnotation :one
+SELECT id, team_id, source_id, text, tags_json, starts_at, ends_at, created_by, created_at, updated_at FROM annotations WHERE id = $1
+`
+
+func (q *Queries) GetAnnotation(ctx context.Context, id int64) (Annotation, error) {
+	row := q.db.QueryRow(ctx, getAnnotation, id)
+	var i Annotation
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.SourceID,
+		&i.Text,
+		&i.TagsJson,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -1039,6 +1677,26 @@ func (q *Queries) GetExportJob(ctx context.Context, id string) (ExportJob, error
 	return i, err
 }
 
+const getLatestSourceSchemaSnapshot = `-- name: GetLatestSourceSchemaSnapshot :one
+SELECT id, source_id, columns, changes, captured_at FROM source_schema_snapshots
+WHERE source_id = $1
+ORDER BY id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestSourceSchemaSnapshot(ctx context.Context, sourceID int64) (SourceSchemaSnapshot, error) {
+	row := q.db.QueryRow(ctx, getLatestSourceSchemaSnapshot, sourceID)
+	var i SourceSchemaSnapshot
+	err := row.Scan(
+		&i.ID,
+		&i.SourceID,
+		&i.Columns,
+		&i.Changes,
+		&i.CapturedAt,
+	)
+	return i, err
+}
+
 const getLatestUnresolvedAlertHistory = `-- name: GetLatestUnresolvedAlertHistory :one
 SELECT id, alert_id, status, triggered_at, resolved_at, value, message, payload_json, created_at FROM alert_history
 WHERE alert_id = $1 AND status = 'triggered'
@@ -1063,6 +1721,100 @@ func (q *Queries) GetLatestUnresolvedAlertHistory(ctx context.Context, alertID i
 	return i, err
 }
 
+const getLogBookmark = `-- name: GetLogBookmark :one
+SELECT id, team_id, source_id, user_id, shared, log_timestamp, fingerprint, payload_json, note, created_at, updated_at FROM log_bookmarks WHERE id = $1
+`
+
+func (q *Queries) GetLogBookmark(ctx context.Context, id int64) (LogBookmark, error) {
+	row := q.db.QueryRow(ctx, getLogBookmark, id)
+	var i LogBookmark
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.SourceID,
+		&i.UserID,
+		&i.Shared,
+		&i.LogTimestamp,
+		&i.Fingerprint,
+		&i.PayloadJson,
+		&i.Note,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getNotebook = `-- name: GetNotebook :one
+SELECT
+    n.id,
+    n.team_id,
+    n.name,
+    n.description,
+    n.cells_json,
+    n.created_by,
+    n.created_at,
+    n.updated_at,
+    u.email AS created_by_email,
+    u.full_name AS created_by_name
+FROM notebooks n
+LEFT JOIN users u ON u.id = n.created_by
+WHERE n.id = $1
+`
+
+type GetNotebookRow struct {
+	ID             int64              `json:"id"`
+	TeamID         int64              `json:"team_id"`
+	Name           string             `json:"name"`
+	Description    pgtype.Text        `json:"description"`
+	CellsJson      string             `json:"cells_json"`
+	CreatedBy      pgtype.Int8        `json:"created_by"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	CreatedByEmail pgtype.Text        `json:"created_by_email"`
+	CreatedByName  pgtype.Text        `json:"created_by_name"`
+}
+
+// Look up one notebook by id, including its cells and creator identity.
+func (q *Queries) GetNotebook(ctx context.Context, id int64) (GetNotebookRow, error) {
+	row := q.db.QueryRow(ctx, getNotebook, id)
+	var i GetNotebookRow
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.Name,
+		&i.Description,
+		&i.CellsJson,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedByEmail,
+		&i.CreatedByName,
+	)
+	return i, err
+}
+
+const getNotebookSnapshot = `-- name: GetNotebookSnapshot :one
+SELECT id, notebook_id, team_id, format, file_name, object_key, size_bytes, created_by, expires_at, created_at FROM notebook_snapshots WHERE id = $1
+`
+
+func (q *Queries) GetNotebookSnapshot(ctx context.Context, id string) (NotebookSnapshot, error) {
+	row := q.db.QueryRow(ctx, getNotebookSnapshot, id)
+	var i NotebookSnapshot
+	err := row.Scan(
+		&i.ID,
+		&i.NotebookID,
+		&i.TeamID,
+		&i.Format,
+		&i.FileName,
+		&i.ObjectKey,
+		&i.SizeBytes,
+		&i.CreatedBy,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getPersonalCollection = `-- name: GetPersonalCollection :one
 SELECT id, name, description, is_personal, created_by, created_at, updated_at FROM collections WHERE created_by = $1 AND is_personal = true
 `
@@ -1132,6 +1884,51 @@ func (q *Queries) GetQueryShare(ctx context.Context, token string) (GetQueryShar
 	return i, err
 }
 
+const getQuerySnippet = `-- name: GetQuerySnippet :one
+SELECT id, team_id, name, description, query, created_by, created_at, updated_at FROM query_snippets WHERE id = $1
+`
+
+func (q *Queries) GetQuerySnippet(ctx context.Context, id int64) (QuerySnippet, error) {
+	row := q.db.QueryRow(ctx, getQuerySnippet, id)
+	var i QuerySnippet
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.Name,
+		&i.Description,
+		&i.Query,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getSLO = `-- name: GetSLO :one
+SELECT id, team_id, source_id, name, description, query_language, good_query, total_query, target, window_seconds, created_by, created_at, updated_at FROM slos WHERE id = $1
+`
+
+func (q *Queries) GetSLO(ctx context.Context, id int64) (Slo, error) {
+	row := q.db.QueryRow(ctx, getSLO, id)
+	var i Slo
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.SourceID,
+		&i.Name,
+		&i.Description,
+		&i.QueryLanguage,
+		&i.GoodQuery,
+		&i.TotalQuery,
+		&i.Target,
+		&i.WindowSeconds,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getSavedQuery = `-- name: GetSavedQuery :one
 SELECT id, source_id, name, description, query_content, created_by, created_from_team_id, created_at, updated_at, query_language, editor_mode FROM saved_queries WHERE id = $1
 `
@@ -1174,7 +1971,7 @@ func (q *Queries) GetSession(ctx context.Context, id string) (Session, error) {
 }
 
 const getSource = `-- name: GetSource :one
-SELECT id, name, _meta_is_auto_created, _meta_ts_field, _meta_severity_field, description, ttl_days, managed, secret_ref, created_at, updated_at, source_type, connection_config, identity_key FROM sources WHERE id = $1
+SELECT id, name, _meta_is_auto_created, _meta_ts_field, _meta_severity_field, description, ttl_days, managed, secret_ref, created_at, updated_at, source_type, connection_config, identity_key, tags, extraction_rules, trace_correlation, masked_columns FROM sources WHERE id = $1
 `
 
 // Get a single source by ID
@@ -1196,12 +1993,16 @@ func (q *Queries) GetSource(ctx context.Context, id int64) (Source, error) {
 		&i.SourceType,
 		&i.ConnectionConfig,
 		&i.IdentityKey,
+		&i.Tags,
+		&i.ExtractionRules,
+		&i.TraceCorrelation,
+		&i.MaskedColumns,
 	)
 	return i, err
 }
 
 const getSourceByIdentityKey = `-- name: GetSourceByIdentityKey :one
-SELECT id, name, _meta_is_auto_created, _meta_ts_field, _meta_severity_field, description, ttl_days, managed, secret_ref, created_at, updated_at, source_type, connection_config, identity_key FROM sources WHERE identity_key = $1
+SELECT id, name, _meta_is_auto_created, _meta_ts_field, _meta_severity_field, description, ttl_days, managed, secret_ref, created_at, updated_at, source_type, connection_config, identity_key, tags, extraction_rules, trace_correlation, masked_columns FROM sources WHERE identity_key = $1
 `
 
 // Get a single source by provider-computed identity key
@@ -1223,12 +2024,16 @@ func (q *Queries) GetSourceByIdentityKey(ctx context.Context, identityKey string
 		&i.SourceType,
 		&i.ConnectionConfig,
 		&i.IdentityKey,
+		&i.Tags,
+		&i.ExtractionRules,
+		&i.TraceCorrelation,
+		&i.MaskedColumns,
 	)
 	return i, err
 }
 
 const getSourceByNameForProvisioning = `-- name: GetSourceByNameForProvisioning :one
-SELECT id, name, _meta_is_auto_created, _meta_ts_field, _meta_severity_field, description, ttl_days, managed, secret_ref, created_at, updated_at, source_type, connection_config, identity_key FROM sources WHERE name = $1
+SELECT id, name, _meta_is_auto_created, _meta_ts_field, _meta_severity_field, description, ttl_days, managed, secret_ref, created_at, updated_at, source_type, connection_config, identity_key, tags, extraction_rules, trace_correlation, masked_columns FROM sources WHERE name = $1
 `
 
 // Get source by name for provisioning lookup
@@ -1250,6 +2055,32 @@ func (q *Queries) GetSourceByNameForProvisioning(ctx context.Context, name strin
 		&i.SourceType,
 		&i.ConnectionConfig,
 		&i.IdentityKey,
+		&i.Tags,
+		&i.ExtractionRules,
+		&i.TraceCorrelation,
+		&i.MaskedColumns,
+	)
+	return i, err
+}
+
+const getSourceRollup = `-- name: GetSourceRollup :one
+
+SELECT source_id, dimension, rolled_up_from, rolled_up_until, last_run_at, last_error, created_at, updated_at FROM source_rollups WHERE source_id = $1
+`
+
+// Source rollups ---------------------------------------------------------------
+func (q *Queries) GetSourceRollup(ctx context.Context, sourceID int64) (SourceRollup, error) {
+	row := q.db.QueryRow(ctx, getSourceRollup, sourceID)
+	var i SourceRollup
+	err := row.Scan(
+		&i.SourceID,
+		&i.Dimension,
+		&i.RolledUpFrom,
+		&i.RolledUpUntil,
+		&i.LastRunAt,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -1278,7 +2109,7 @@ func (q *Queries) GetSystemSetting(ctx context.Context, key string) (SystemSetti
 }
 
 const getTeam = `-- name: GetTeam :one
-SELECT id, name, description, managed, created_at, updated_at FROM teams WHERE id = $1
+SELECT id, name, description, managed, created_at, updated_at, raw_sql_enabled FROM teams WHERE id = $1
 `
 
 // Get a team by ID
@@ -1292,12 +2123,13 @@ func (q *Queries) GetTeam(ctx context.Context, id int64) (Team, error) {
 		&i.Managed,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RawSqlEnabled,
 	)
 	return i, err
 }
 
 const getTeamByName = `-- name: GetTeamByName :one
-SELECT id, name, description, managed, created_at, updated_at FROM teams WHERE name = $1
+SELECT id, name, description, managed, created_at, updated_at, raw_sql_enabled FROM teams WHERE name = $1
 `
 
 // Get a team by its name
@@ -1311,6 +2143,30 @@ func (q *Queries) GetTeamByName(ctx context.Context, name string) (Team, error)
 		&i.Managed,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RawSqlEnabled,
+	)
+	return i, err
+}
+
+const getTeamColumnPreset = `-- name: GetTeamColumnPreset :one
+SELECT team_id, source_id, columns_json, updated_by, created_at, updated_at FROM team_column_presets WHERE team_id = $1 AND source_id = $2
+`
+
+type GetTeamColumnPresetParams struct {
+	TeamID   int64 `json:"team_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+func (q *Queries) GetTeamColumnPreset(ctx context.Context, arg GetTeamColumnPresetParams) (TeamColumnPreset, error) {
+	row := q.db.QueryRow(ctx, getTeamColumnPreset, arg.TeamID, arg.SourceID)
+	var i TeamColumnPreset
+	err := row.Scan(
+		&i.TeamID,
+		&i.SourceID,
+		&i.ColumnsJson,
+		&i.UpdatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -1337,6 +2193,50 @@ func (q *Queries) GetTeamMember(ctx context.Context, arg GetTeamMemberParams) (T
 	return i, err
 }
 
+const getTeamQueryUsage = `-- name: GetTeamQueryUsage :one
+SELECT
+    COALESCE(SUM(query_count), 0)::bigint AS query_count,
+    COALESCE(SUM(rows_read), 0)::bigint AS rows_read
+FROM query_stats_daily
+WHERE bucket_date = $1 AND team_id = $2
+`
+
+type GetTeamQueryUsageParams struct {
+	BucketDate pgtype.Date `json:"bucket_date"`
+	TeamID     int64       `json:"team_id"`
+}
+
+type GetTeamQueryUsageRow struct {
+	QueryCount int64 `json:"query_count"`
+	RowsRead   int64 `json:"rows_read"`
+}
+
+// Sum a team's queries and rows read on one day of the rollup.
+func (q *Queries) GetTeamQueryUsage(ctx context.Context, arg GetTeamQueryUsageParams) (GetTeamQueryUsageRow, error) {
+	row := q.db.QueryRow(ctx, getTeamQueryUsage, arg.BucketDate, arg.TeamID)
+	var i GetTeamQueryUsageRow
+	err := row.Scan(&i.QueryCount, &i.RowsRead)
+	return i, err
+}
+
+const getTeamSourceRowFilter = `-- name: GetTeamSourceRowFilter :one
+SELECT row_filter FROM team_sources
+WHERE team_id = $1 AND source_id = $2
+`
+
+type GetTeamSourceRowFilterParams struct {
+	TeamID   int64 `json:"team_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+// Get the row filter a team's queries on a linked source must match
+func (q *Queries) GetTeamSourceRowFilter(ctx context.Context, arg GetTeamSourceRowFilterParams) (string, error) {
+	row := q.db.QueryRow(ctx, getTeamSourceRowFilter, arg.TeamID, arg.SourceID)
+	var row_filter string
+	err := row.Scan(&row_filter)
+	return row_filter, err
+}
+
 const getUser = `-- name: GetUser :one
 SELECT id, email, full_name, role, status, last_login_at, last_active_at, managed, account_type, created_at, updated_at, password_hash FROM users WHERE id = $1
 `
@@ -1387,16 +2287,39 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 	return i, err
 }
 
-const getUserPreferences = `-- name: GetUserPreferences :one
-
-SELECT user_id, preferences_json, created_at, updated_at FROM user_preferences WHERE user_id = $1
+const getUserColumnPreset = `-- name: GetUserColumnPreset :one
+SELECT user_id, source_id, columns_json, created_at, updated_at FROM user_column_presets WHERE user_id = $1 AND source_id = $2
 `
 
-// User Preferences
-// Get user preferences by user ID
-func (q *Queries) GetUserPreferences(ctx context.Context, userID int64) (UserPreference, error) {
-	row := q.db.QueryRow(ctx, getUserPreferences, userID)
-	var i UserPreference
+type GetUserColumnPresetParams struct {
+	UserID   int64 `json:"user_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+// Column presets ---------------------------------------------------------------
+func (q *Queries) GetUserColumnPreset(ctx context.Context, arg GetUserColumnPresetParams) (UserColumnPreset, error) {
+	row := q.db.QueryRow(ctx, getUserColumnPreset, arg.UserID, arg.SourceID)
+	var i UserColumnPreset
+	err := row.Scan(
+		&i.UserID,
+		&i.SourceID,
+		&i.ColumnsJson,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserPreferences = `-- name: GetUserPreferences :one
+
+SELECT user_id, preferences_json, created_at, updated_at FROM user_preferences WHERE user_id = $1
+`
+
+// User Preferences
+// Get user preferences by user ID
+func (q *Queries) GetUserPreferences(ctx context.Context, userID int64) (UserPreference, error) {
+	row := q.db.QueryRow(ctx, getUserPreferences, userID)
+	var i UserPreference
 	err := row.Scan(
 		&i.UserID,
 		&i.PreferencesJson,
@@ -1426,14 +2349,36 @@ func (q *Queries) GetUserTeamForSource(ctx context.Context, arg GetUserTeamForSo
 	return team_id, err
 }
 
+const getWebhook = `-- name: GetWebhook :one
+SELECT id, name, url, event_types_json, secret, is_active, created_by, created_at, updated_at FROM webhooks WHERE id = $1
+`
+
+func (q *Queries) GetWebhook(ctx context.Context, id int64) (Webhook, error) {
+	row := q.db.QueryRow(ctx, getWebhook, id)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Url,
+		&i.EventTypesJson,
+		&i.Secret,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const incrementQueryStats = `-- name: IncrementQueryStats :exec
 
-INSERT INTO query_stats_daily (bucket_date, user_id, team_id, source_id, query_language, query_count, total_duration_ms)
-VALUES ($1, $2, $3, $4, $5, 1, $6)
+INSERT INTO query_stats_daily (bucket_date, user_id, team_id, source_id, query_language, query_count, total_duration_ms, rows_read)
+VALUES ($1, $2, $3, $4, $5, 1, $6, $7)
 ON CONFLICT (bucket_date, user_id, team_id, source_id, query_language)
 DO UPDATE SET
     query_count = query_stats_daily.query_count + 1,
-    total_duration_ms = query_stats_daily.total_duration_ms + EXCLUDED.total_duration_ms
+    total_duration_ms = query_stats_daily.total_duration_ms + EXCLUDED.total_duration_ms,
+    rows_read = query_stats_daily.rows_read + EXCLUDED.rows_read
 `
 
 type IncrementQueryStatsParams struct {
@@ -1443,11 +2388,13 @@ type IncrementQueryStatsParams struct {
 	SourceID        int64       `json:"source_id"`
 	QueryLanguage   string      `json:"query_language"`
 	TotalDurationMs int64       `json:"total_duration_ms"`
+	RowsRead        int64       `json:"rows_read"`
 }
 
 // Query stats daily rollup -----------------------------------------------------
 // Upsert one executed query into the non-pruned daily rollup: add 1 to
-// query_count and the given duration to total_duration_ms for the composite key.
+// query_count, and the given duration and rows read to total_duration_ms and
+// rows_read, for the composite key.
 func (q *Queries) IncrementQueryStats(ctx context.Context, arg IncrementQueryStatsParams) error {
 	_, err := q.db.Exec(ctx, incrementQueryStats,
 		arg.BucketDate,
@@ -1456,6 +2403,7 @@ func (q *Queries) IncrementQueryStats(ctx context.Context, arg IncrementQuerySta
 		arg.SourceID,
 		arg.QueryLanguage,
 		arg.TotalDurationMs,
+		arg.RowsRead,
 	)
 	return err
 }
@@ -1505,10 +2453,49 @@ func (q *Queries) InsertAlertHistory(ctx context.Context, arg InsertAlertHistory
 	return i, err
 }
 
+const insertAuditEvent = `-- name: InsertAuditEvent :one
+
+INSERT INTO audit_events (user_id, user_email, action, resource_type, resource_id, team_id, ip_address, details, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id
+`
+
+type InsertAuditEventParams struct {
+	UserID       pgtype.Int8        `json:"user_id"`
+	UserEmail    string             `json:"user_email"`
+	Action       string             `json:"action"`
+	ResourceType string             `json:"resource_type"`
+	ResourceID   string             `json:"resource_id"`
+	TeamID       pgtype.Int8        `json:"team_id"`
+	IpAddress    string             `json:"ip_address"`
+	Details      pgtype.Text        `json:"details"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+// Audit events ---------------------------------------------------------------
+// Append one audit event and return its id. created_at is supplied by the
+// caller (the time of the action, not of the asynchronous write).
+func (q *Queries) InsertAuditEvent(ctx context.Context, arg InsertAuditEventParams) (int64, error) {
+	row := q.db.QueryRow(ctx, insertAuditEvent,
+		arg.UserID,
+		arg.UserEmail,
+		arg.Action,
+		arg.ResourceType,
+		arg.ResourceID,
+		arg.TeamID,
+		arg.IpAddress,
+		arg.Details,
+		arg.CreatedAt,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const insertQueryHistory = `-- name: InsertQueryHistory :one
 
-INSERT INTO query_history (user_id, team_id, source_id, query_text, query_language, duration_ms, row_count)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO query_history (user_id, team_id, source_id, query_text, query_language, duration_ms, row_count, status, error_message)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING id
 `
 
@@ -1520,6 +2507,8 @@ type InsertQueryHistoryParams struct {
 	QueryLanguage string `json:"query_language"`
 	DurationMs    int64  `json:"duration_ms"`
 	RowCount      int64  `json:"row_count"`
+	Status        string `json:"status"`
+	ErrorMessage  string `json:"error_message"`
 }
 
 // Query history ---------------------------------------------------------------
@@ -1533,12 +2522,120 @@ func (q *Queries) InsertQueryHistory(ctx context.Context, arg InsertQueryHistory
 		arg.QueryLanguage,
 		arg.DurationMs,
 		arg.RowCount,
+		arg.Status,
+		arg.ErrorMessage,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertSLOEvaluation = `-- name: InsertSLOEvaluation :one
+INSERT INTO slo_evaluations (slo_id, good, total, compliance, burn_rate, error_budget_remaining, error, evaluated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id
+`
+
+type InsertSLOEvaluationParams struct {
+	SloID                int64              `json:"slo_id"`
+	Good                 float64            `json:"good"`
+	Total                float64            `json:"total"`
+	Compliance           pgtype.Float8      `json:"compliance"`
+	BurnRate             pgtype.Float8      `json:"burn_rate"`
+	ErrorBudgetRemaining pgtype.Float8      `json:"error_budget_remaining"`
+	Error                string             `json:"error"`
+	EvaluatedAt          pgtype.Timestamptz `json:"evaluated_at"`
+}
+
+// Append one evaluation and return its id.
+func (q *Queries) InsertSLOEvaluation(ctx context.Context, arg InsertSLOEvaluationParams) (int64, error) {
+	row := q.db.QueryRow(ctx, insertSLOEvaluation,
+		arg.SloID,
+		arg.Good,
+		arg.Total,
+		arg.Compliance,
+		arg.BurnRate,
+		arg.ErrorBudgetRemaining,
+		arg.Error,
+		arg.EvaluatedAt,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertSourceAlertEvent = `-- name: InsertSourceAlertEvent :one
+
+INSERT INTO source_alert_events (source_id, kind, status, value, message, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id
+`
+
+type InsertSourceAlertEventParams struct {
+	SourceID  int64              `json:"source_id"`
+	Kind      string             `json:"kind"`
+	Status    string             `json:"status"`
+	Value     float64            `json:"value"`
+	Message   string             `json:"message"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+// Source alert events ----------------------------------------------------------
+// Record a source degradation alert firing or resolving.
+func (q *Queries) InsertSourceAlertEvent(ctx context.Context, arg InsertSourceAlertEventParams) (int64, error) {
+	row := q.db.QueryRow(ctx, insertSourceAlertEvent,
+		arg.SourceID,
+		arg.Kind,
+		arg.Status,
+		arg.Value,
+		arg.Message,
+		arg.CreatedAt,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertSourceSchemaSnapshot = `-- name: InsertSourceSchemaSnapshot :one
+
+INSERT INTO source_schema_snapshots (source_id, columns, changes, captured_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id
+`
+
+type InsertSourceSchemaSnapshotParams struct {
+	SourceID   int64              `json:"source_id"`
+	Columns    []byte             `json:"columns"`
+	Changes    []byte             `json:"changes"`
+	CapturedAt pgtype.Timestamptz `json:"captured_at"`
+}
+
+// Source schema snapshots -----------------------------------------------------
+// Record a source's column list and its changes since the previous snapshot.
+func (q *Queries) InsertSourceSchemaSnapshot(ctx context.Context, arg InsertSourceSchemaSnapshotParams) (int64, error) {
+	row := q.db.QueryRow(ctx, insertSourceSchemaSnapshot,
+		arg.SourceID,
+		arg.Columns,
+		arg.Changes,
+		arg.CapturedAt,
 	)
 	var id int64
 	err := row.Scan(&id)
 	return id, err
 }
 
+const isAlertManaged = `-- name: IsAlertManaged :one
+SELECT managed FROM alerts WHERE id = $1
+`
+
+// Check if an alert is managed
+func (q *Queries) IsAlertManaged(ctx context.Context, id int64) (bool, error) {
+	row := q.db.QueryRow(ctx, isAlertManaged, id)
+	var managed bool
+	err := row.Scan(&managed)
+	return managed, err
+}
+
 const isSourceManaged = `-- name: IsSourceManaged :one
 SELECT managed FROM sources WHERE id = $1
 `
@@ -1612,7 +2709,7 @@ func (q *Queries) ListAPITokensForUser(ctx context.Context, userID int64) ([]Api
 }
 
 const listActiveAlertsDue = `-- name: ListActiveAlertsDue :many
-SELECT id, source_id, name, description, query, condition_json, lookback_seconds, threshold_operator, threshold_value, frequency_seconds, severity, labels_json, annotations_json, generator_url, is_active, last_state, last_evaluated_at, last_triggered_at, recipient_user_ids_json, webhook_urls_json, created_by, created_at, updated_at, query_language, editor_mode FROM alerts
+SELECT id, source_id, name, description, query, condition_json, lookback_seconds, threshold_operator, threshold_value, frequency_seconds, severity, labels_json, annotations_json, generator_url, is_active, last_state, last_evaluated_at, last_triggered_at, recipient_user_ids_json, webhook_urls_json, created_by, created_at, updated_at, query_language, editor_mode, channels_json, slo_id, managed, for_seconds, pending_since, breach_count FROM alerts
 WHERE is_active = true
   AND (
         last_evaluated_at IS NULL
@@ -1655,6 +2752,12 @@ func (q *Queries) ListActiveAlertsDue(ctx context.Context) ([]Alert, error) {
 			&i.UpdatedAt,
 			&i.QueryLanguage,
 			&i.EditorMode,
+			&i.ChannelsJson,
+			&i.SloID,
+			&i.Managed,
+			&i.ForSeconds,
+			&i.PendingSince,
+			&i.BreachCount,
 		); err != nil {
 			return nil, err
 		}
@@ -1708,8 +2811,90 @@ func (q *Queries) ListAlertHistory(ctx context.Context, arg ListAlertHistoryPara
 	return items, nil
 }
 
+const listAlertHistorySince = `-- name: ListAlertHistorySince :many
+SELECT id, alert_id, status, triggered_at, resolved_at, value, message, payload_json, created_at FROM alert_history
+WHERE alert_id = $1
+  AND (triggered_at >= $2
+    OR resolved_at >= $2
+    OR (resolved_at IS NULL AND status = 'triggered'))
+ORDER BY triggered_at ASC, id ASC
+`
+
+type ListAlertHistorySinceParams struct {
+	AlertID int64              `json:"alert_id"`
+	Since   pgtype.Timestamptz `json:"since"`
+}
+
+// List an alert's history entries still open at or after since, oldest first.
+func (q *Queries) ListAlertHistorySince(ctx context.Context, arg ListAlertHistorySinceParams) ([]AlertHistory, error) {
+	rows, err := q.db.Query(ctx, listAlertHistorySince, arg.AlertID, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AlertHistory{}
+	for rows.Next() {
+		var i AlertHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.AlertID,
+			&i.Status,
+			&i.TriggeredAt,
+			&i.ResolvedAt,
+			&i.Value,
+			&i.Message,
+			&i.PayloadJson,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAlertSilences = `-- name: ListAlertSilences :many
+SELECT id, alert_id, source_id, team_id, reason, starts_at, ends_at, recurrence_json, created_by, created_at, updated_at FROM alert_silences ORDER BY starts_at DESC, id DESC
+`
+
+// List every silence, latest-starting first.
+func (q *Queries) ListAlertSilences(ctx context.Context) ([]AlertSilence, error) {
+	rows, err := q.db.Query(ctx, listAlertSilences)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AlertSilence{}
+	for rows.Next() {
+		var i AlertSilence
+		if err := rows.Scan(
+			&i.ID,
+			&i.AlertID,
+			&i.SourceID,
+			&i.TeamID,
+			&i.Reason,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.RecurrenceJson,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listAlertsBySource = `-- name: ListAlertsBySource :many
-SELECT id, source_id, name, description, query, condition_json, lookback_seconds, threshold_operator, threshold_value, frequency_seconds, severity, labels_json, annotations_json, generator_url, is_active, last_state, last_evaluated_at, last_triggered_at, recipient_user_ids_json, webhook_urls_json, created_by, created_at, updated_at, query_language, editor_mode FROM alerts
+SELECT id, source_id, name, description, query, condition_json, lookback_seconds, threshold_operator, threshold_value, frequency_seconds, severity, labels_json, annotations_json, generator_url, is_active, last_state, last_evaluated_at, last_triggered_at, recipient_user_ids_json, webhook_urls_json, created_by, created_at, updated_at, query_language, editor_mode, channels_json, slo_id, managed, for_seconds, pending_since, breach_count FROM alerts
 WHERE source_id = $1
 ORDER BY updated_at DESC, created_at DESC
 `
@@ -1750,6 +2935,12 @@ func (q *Queries) ListAlertsBySource(ctx context.Context, sourceID int64) ([]Ale
 			&i.UpdatedAt,
 			&i.QueryLanguage,
 			&i.EditorMode,
+			&i.ChannelsJson,
+			&i.SloID,
+			&i.Managed,
+			&i.ForSeconds,
+			&i.PendingSince,
+			&i.BreachCount,
 		); err != nil {
 			return nil, err
 		}
@@ -1762,7 +2953,7 @@ func (q *Queries) ListAlertsBySource(ctx context.Context, sourceID int64) ([]Ale
 }
 
 const listAlertsForUser = `-- name: ListAlertsForUser :many
-SELECT a.id, a.source_id, a.name, a.description, a.query, a.condition_json, a.lookback_seconds, a.threshold_operator, a.threshold_value, a.frequency_seconds, a.severity, a.labels_json, a.annotations_json, a.generator_url, a.is_active, a.last_state, a.last_evaluated_at, a.last_triggered_at, a.recipient_user_ids_json, a.webhook_urls_json, a.created_by, a.created_at, a.updated_at, a.query_language, a.editor_mode FROM alerts a
+SELECT a.id, a.source_id, a.name, a.description, a.query, a.condition_json, a.lookback_seconds, a.threshold_operator, a.threshold_value, a.frequency_seconds, a.severity, a.labels_json, a.annotations_json, a.generator_url, a.is_active, a.last_state, a.last_evaluated_at, a.last_triggered_at, a.recipient_user_ids_json, a.webhook_urls_json, a.created_by, a.created_at, a.updated_at, a.query_language, a.editor_mode, a.channels_json, a.slo_id, a.managed, a.for_seconds, a.pending_since, a.breach_count FROM alerts a
 WHERE a.source_id IN (
     SELECT DISTINCT ts.source_id
     FROM team_sources ts
@@ -1808,6 +2999,12 @@ func (q *Queries) ListAlertsForUser(ctx context.Context, userID int64) ([]Alert,
 			&i.UpdatedAt,
 			&i.QueryLanguage,
 			&i.EditorMode,
+			&i.ChannelsJson,
+			&i.SloID,
+			&i.Managed,
+			&i.ForSeconds,
+			&i.PendingSince,
+			&i.BreachCount,
 		); err != nil {
 			return nil, err
 		}
@@ -1888,6 +3085,164 @@ func (q *Queries) ListAllSavedQueries(ctx context.Context) ([]ListAllSavedQuerie
 	return items, nil
 }
 
+const listAnnotationsByTeam = `-- name: ListAnnotationsByTeam :many
+SELECT id, team_id, source_id, text, tags_json, starts_at, ends_at, created_by, created_at, updated_at FROM annotations
+WHERE team_id = $1
+ORDER BY starts_at DESC, id DESC
+`
+
+// A team's annotations, latest-starting first.
+func (q *Queries) ListAnnotationsByTeam(ctx context.Context, teamID int64) ([]Annotation, error) {
+	rows, err := q.db.Query(ctx, listAnnotationsByTeam, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Annotation{}
+	for rows.Next() {
+		var i Annotation
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.SourceID,
+			&i.Text,
+			&i.TagsJson,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAnnotationsInRange = `-- name: ListAnnotationsInRange :many
+SELECT id, team_id, source_id, text, tags_json, starts_at, ends_at, created_by, created_at, updated_at FROM annotations
+WHERE team_id = $1
+  AND (source_id IS NULL OR source_id = $2)
+  AND starts_at <= $3
+  AND (starts_at >= $4 OR ends_at >= $4)
+ORDER BY starts_at, id
+`
+
+type ListAnnotationsInRangeParams struct {
+	TeamID     int64              `json:"team_id"`
+	SourceID   pgtype.Int8        `json:"source_id"`
+	RangeEnd   pgtype.Timestamptz `json:"range_end"`
+	RangeStart pgtype.Timestamptz `json:"range_start"`
+}
+
+// A team's annotations that touch [range_start, range_end] and apply to the
+// given source: team-wide ones and the source's own. Oldest first.
+func (q *Queries) ListAnnotationsInRange(ctx context.Context, arg ListAnnotationsInRangeParams) ([]Annotation, error) {
+	rows, err := q.db.Query(ctx, listAnnotationsInRange,
+		arg.TeamID,
+		arg.SourceID,
+		arg.RangeEnd,
+		arg.RangeStart,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Annotation{}
+	for rows.Next() {
+		var i Annotation
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.SourceID,
+			&i.Text,
+			&i.TagsJson,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditEvents = `-- name: ListAuditEvents :many
+SELECT id, user_id, user_email, action, resource_type, resource_id, team_id, ip_address, details, created_at
+FROM audit_events
+WHERE (user_id = $1 OR $1 IS NULL)
+  AND (action = $2 OR $2 IS NULL)
+  AND (resource_type = $3 OR $3 IS NULL)
+  AND (team_id = $4 OR $4 IS NULL)
+  AND (created_at >= $5 OR $5 IS NULL)
+  AND (created_at < $6 OR $6 IS NULL)
+ORDER BY created_at DESC, id DESC
+LIMIT $8 OFFSET $7
+`
+
+type ListAuditEventsParams struct {
+	UserID       pgtype.Int8        `json:"user_id"`
+	Action       pgtype.Text        `json:"action"`
+	ResourceType pgtype.Text        `json:"resource_type"`
+	TeamID       pgtype.Int8        `json:"team_id"`
+	Since        pgtype.Timestamptz `json:"since"`
+	Until        pgtype.Timestamptz `json:"until"`
+	Offset       int32              `json:"offset"`
+	Limit        int32              `json:"limit"`
+}
+
+// List audit events newest first. Every filter is optional: a NULL argument
+// matches all rows.
+func (q *Queries) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error) {
+	rows, err := q.db.Query(ctx, listAuditEvents,
+		arg.UserID,
+		arg.Action,
+		arg.ResourceType,
+		arg.TeamID,
+		arg.Since,
+		arg.Until,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditEvent{}
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.UserEmail,
+			&i.Action,
+			&i.ResourceType,
+			&i.ResourceID,
+			&i.TeamID,
+			&i.IpAddress,
+			&i.Details,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listCollectionItems = `-- name: ListCollectionItems :many
 SELECT
     ci.collection_id,
@@ -2169,20 +3524,161 @@ func (q *Queries) ListExpiredExportJobPaths(ctx context.Context, expiresAt pgtyp
 	return items, nil
 }
 
-const listManagedSources = `-- name: ListManagedSources :many
-
-SELECT id, name, _meta_is_auto_created, _meta_ts_field, _meta_severity_field, description, ttl_days, managed, secret_ref, created_at, updated_at, source_type, connection_config, identity_key FROM sources WHERE managed = true ORDER BY id
+const listExpiredNotebookSnapshots = `-- name: ListExpiredNotebookSnapshots :many
+SELECT id, notebook_id, team_id, format, file_name, object_key, size_bytes, created_by, expires_at, created_at FROM notebook_snapshots
+WHERE expires_at < $1
+ORDER BY expires_at
 `
 
-// Provisioning Queries
-// Get all sources managed by provisioning config
-func (q *Queries) ListManagedSources(ctx context.Context) ([]Source, error) {
-	rows, err := q.db.Query(ctx, listManagedSources)
+// Snapshots past their expiry, for the cleanup loop.
+func (q *Queries) ListExpiredNotebookSnapshots(ctx context.Context, expiresAt pgtype.Timestamptz) ([]NotebookSnapshot, error) {
+	rows, err := q.db.Query(ctx, listExpiredNotebookSnapshots, expiresAt)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Source{}
+	items := []NotebookSnapshot{}
+	for rows.Next() {
+		var i NotebookSnapshot
+		if err := rows.Scan(
+			&i.ID,
+			&i.NotebookID,
+			&i.TeamID,
+			&i.Format,
+			&i.FileName,
+			&i.ObjectKey,
+			&i.SizeBytes,
+			&i.CreatedBy,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLogBookmarksForUser = `-- name: ListLogBookmarksForUser :many
+SELECT id, team_id, source_id, user_id, shared, log_timestamp, fingerprint, payload_json, note, created_at, updated_at FROM log_bookmarks
+WHERE team_id = $1
+  AND (user_id = $2 OR shared)
+ORDER BY created_at DESC, id DESC
+`
+
+type ListLogBookmarksForUserParams struct {
+	TeamID int64 `json:"team_id"`
+	UserID int64 `json:"user_id"`
+}
+
+// The bookmarks a user sees in a team: their own and the team's shared ones,
+// most recently pinned first.
+func (q *Queries) ListLogBookmarksForUser(ctx context.Context, arg ListLogBookmarksForUserParams) ([]LogBookmark, error) {
+	rows, err := q.db.Query(ctx, listLogBookmarksForUser, arg.TeamID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LogBookmark{}
+	for rows.Next() {
+		var i LogBookmark
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.SourceID,
+			&i.UserID,
+			&i.Shared,
+			&i.LogTimestamp,
+			&i.Fingerprint,
+			&i.PayloadJson,
+			&i.Note,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listManagedAlerts = `-- name: ListManagedAlerts :many
+SELECT id, source_id, name, description, query, condition_json, lookback_seconds, threshold_operator, threshold_value, frequency_seconds, severity, labels_json, annotations_json, generator_url, is_active, last_state, last_evaluated_at, last_triggered_at, recipient_user_ids_json, webhook_urls_json, created_by, created_at, updated_at, query_language, editor_mode, channels_json, slo_id, managed, for_seconds, pending_since, breach_count FROM alerts WHERE managed = true ORDER BY id
+`
+
+// Get all alerts managed by provisioning config
+func (q *Queries) ListManagedAlerts(ctx context.Context) ([]Alert, error) {
+	rows, err := q.db.Query(ctx, listManagedAlerts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Alert{}
+	for rows.Next() {
+		var i Alert
+		if err := rows.Scan(
+			&i.ID,
+			&i.SourceID,
+			&i.Name,
+			&i.Description,
+			&i.Query,
+			&i.ConditionJson,
+			&i.LookbackSeconds,
+			&i.ThresholdOperator,
+			&i.ThresholdValue,
+			&i.FrequencySeconds,
+			&i.Severity,
+			&i.LabelsJson,
+			&i.AnnotationsJson,
+			&i.GeneratorUrl,
+			&i.IsActive,
+			&i.LastState,
+			&i.LastEvaluatedAt,
+			&i.LastTriggeredAt,
+			&i.RecipientUserIdsJson,
+			&i.WebhookUrlsJson,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.QueryLanguage,
+			&i.EditorMode,
+			&i.ChannelsJson,
+			&i.SloID,
+			&i.Managed,
+			&i.ForSeconds,
+			&i.PendingSince,
+			&i.BreachCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listManagedSources = `-- name: ListManagedSources :many
+
+SELECT id, name, _meta_is_auto_created, _meta_ts_field, _meta_severity_field, description, ttl_days, managed, secret_ref, created_at, updated_at, source_type, connection_config, identity_key, tags, extraction_rules, trace_correlation, masked_columns FROM sources WHERE managed = true ORDER BY id
+`
+
+// Provisioning Queries
+// Get all sources managed by provisioning config
+func (q *Queries) ListManagedSources(ctx context.Context) ([]Source, error) {
+	rows, err := q.db.Query(ctx, listManagedSources)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Source{}
 	for rows.Next() {
 		var i Source
 		if err := rows.Scan(
@@ -2200,6 +3696,10 @@ func (q *Queries) ListManagedSources(ctx context.Context) ([]Source, error) {
 			&i.SourceType,
 			&i.ConnectionConfig,
 			&i.IdentityKey,
+			&i.Tags,
+			&i.ExtractionRules,
+			&i.TraceCorrelation,
+			&i.MaskedColumns,
 		); err != nil {
 			return nil, err
 		}
@@ -2212,7 +3712,7 @@ func (q *Queries) ListManagedSources(ctx context.Context) ([]Source, error) {
 }
 
 const listManagedTeams = `-- name: ListManagedTeams :many
-SELECT id, name, description, managed, created_at, updated_at FROM teams WHERE managed = true ORDER BY id
+SELECT id, name, description, managed, created_at, updated_at, raw_sql_enabled FROM teams WHERE managed = true ORDER BY id
 `
 
 // Get all teams managed by provisioning config
@@ -2232,6 +3732,7 @@ func (q *Queries) ListManagedTeams(ctx context.Context) ([]Team, error) {
 			&i.Managed,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RawSqlEnabled,
 		); err != nil {
 			return nil, err
 		}
@@ -2281,9 +3782,108 @@ func (q *Queries) ListManagedUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
+const listNotebookSnapshots = `-- name: ListNotebookSnapshots :many
+SELECT id, notebook_id, team_id, format, file_name, object_key, size_bytes, created_by, expires_at, created_at FROM notebook_snapshots
+WHERE notebook_id = $1
+ORDER BY created_at DESC, id
+`
+
+// A notebook's snapshots, newest first.
+func (q *Queries) ListNotebookSnapshots(ctx context.Context, notebookID int64) ([]NotebookSnapshot, error) {
+	rows, err := q.db.Query(ctx, listNotebookSnapshots, notebookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NotebookSnapshot{}
+	for rows.Next() {
+		var i NotebookSnapshot
+		if err := rows.Scan(
+			&i.ID,
+			&i.NotebookID,
+			&i.TeamID,
+			&i.Format,
+			&i.FileName,
+			&i.ObjectKey,
+			&i.SizeBytes,
+			&i.CreatedBy,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNotebooksByTeam = `-- name: ListNotebooksByTeam :many
+SELECT
+    n.id,
+    n.team_id,
+    n.name,
+    n.description,
+    n.created_by,
+    n.created_at,
+    n.updated_at,
+    u.email AS created_by_email,
+    u.full_name AS created_by_name
+FROM notebooks n
+LEFT JOIN users u ON u.id = n.created_by
+WHERE n.team_id = $1
+ORDER BY n.updated_at DESC, n.id DESC
+`
+
+type ListNotebooksByTeamRow struct {
+	ID             int64              `json:"id"`
+	TeamID         int64              `json:"team_id"`
+	Name           string             `json:"name"`
+	Description    pgtype.Text        `json:"description"`
+	CreatedBy      pgtype.Int8        `json:"created_by"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	CreatedByEmail pgtype.Text        `json:"created_by_email"`
+	CreatedByName  pgtype.Text        `json:"created_by_name"`
+}
+
+// List a team's notebooks, newest-updated first. Cells are omitted: they carry
+// cached results and are only needed when a single notebook is opened.
+func (q *Queries) ListNotebooksByTeam(ctx context.Context, teamID int64) ([]ListNotebooksByTeamRow, error) {
+	rows, err := q.db.Query(ctx, listNotebooksByTeam, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListNotebooksByTeamRow{}
+	for rows.Next() {
+		var i ListNotebooksByTeamRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.Name,
+			&i.Description,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CreatedByEmail,
+			&i.CreatedByName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listQueryActivity = `-- name: ListQueryActivity :many
 SELECT
-    qh.id, qh.user_id, qh.team_id, qh.source_id, qh.query_text, qh.query_language, qh.duration_ms, qh.row_count, qh.created_at,
+    qh.id, qh.user_id, qh.team_id, qh.source_id, qh.query_text, qh.query_language, qh.duration_ms, qh.row_count, qh.created_at, qh.status, qh.error_message,
     u.email AS user_email,
     s.name AS source_name
 FROM query_history qh
@@ -2303,36 +3903,257 @@ type ListQueryActivityRow struct {
 	DurationMs    int64              `json:"duration_ms"`
 	RowCount      int64              `json:"row_count"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	Status        string             `json:"status"`
+	ErrorMessage  string             `json:"error_message"`
 	UserEmail     string             `json:"user_email"`
 	SourceName    pgtype.Text        `json:"source_name"`
 }
 
-// Most recent query_history rows across all users, newest first, enriched with
-// the executing user's email and the source's display name. LEFT JOIN on
-// sources so history survives a deleted source (source_name is NULL then).
-// Backs the admin recent-activity view; the table is capped per user, so this
-// is a recent window, not all-time analytics.
-func (q *Queries) ListQueryActivity(ctx context.Context, limit int32) ([]ListQueryActivityRow, error) {
-	rows, err := q.db.Query(ctx, listQueryActivity, limit)
+// Most recent query_history rows across all users, newest first, enriched with
+// the executing user's email and the source's display name. LEFT JOIN on
+// sources so history survives a deleted source (source_name is NULL then).
+// Backs the admin recent-activity view; the table is capped per user, so this
+// is a recent window, not all-time analytics.
+func (q *Queries) ListQueryActivity(ctx context.Context, limit int32) ([]ListQueryActivityRow, error) {
+	rows, err := q.db.Query(ctx, listQueryActivity, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListQueryActivityRow{}
+	for rows.Next() {
+		var i ListQueryActivityRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TeamID,
+			&i.SourceID,
+			&i.QueryText,
+			&i.QueryLanguage,
+			&i.DurationMs,
+			&i.RowCount,
+			&i.CreatedAt,
+			&i.Status,
+			&i.ErrorMessage,
+			&i.UserEmail,
+			&i.SourceName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQueryHistory = `-- name: ListQueryHistory :many
+SELECT
+    qh.id,
+    qh.user_id,
+    qh.team_id,
+    qh.source_id,
+    qh.query_text,
+    qh.query_language,
+    qh.duration_ms,
+    qh.row_count,
+    qh.status,
+    qh.error_message,
+    qh.created_at,
+    u.email AS user_email,
+    COALESCE(s.name, '') AS source_name
+FROM query_history qh
+JOIN users u ON u.id = qh.user_id
+LEFT JOIN sources s ON s.id = qh.source_id
+WHERE (qh.user_id = $1 OR $1 IS NULL)
+  AND (qh.team_id = $2 OR $2 IS NULL)
+  AND (qh.source_id = $3 OR $3 IS NULL)
+  AND (qh.status = $4 OR $4 IS NULL)
+  AND (strpos(lower(qh.query_text), lower($5)) > 0 OR $5 IS NULL)
+  AND (qh.created_at >= $6 OR $6 IS NULL)
+ORDER BY qh.created_at DESC, qh.id DESC
+LIMIT $8 OFFSET $7
+`
+
+type ListQueryHistoryParams struct {
+	UserID   pgtype.Int8        `json:"user_id"`
+	TeamID   pgtype.Int8        `json:"team_id"`
+	SourceID pgtype.Int8        `json:"source_id"`
+	Status   pgtype.Text        `json:"status"`
+	Search   pgtype.Text        `json:"search"`
+	Since    pgtype.Timestamptz `json:"since"`
+	Offset   int32              `json:"offset"`
+	Limit    int32              `json:"limit"`
+}
+
+type ListQueryHistoryRow struct {
+	ID            int64              `json:"id"`
+	UserID        int64              `json:"user_id"`
+	TeamID        int64              `json:"team_id"`
+	SourceID      int64              `json:"source_id"`
+	QueryText     string             `json:"query_text"`
+	QueryLanguage string             `json:"query_language"`
+	DurationMs    int64              `json:"duration_ms"`
+	RowCount      int64              `json:"row_count"`
+	Status        string             `json:"status"`
+	ErrorMessage  string             `json:"error_message"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UserEmail     string             `json:"user_email"`
+	SourceName    string             `json:"source_name"`
+}
+
+// List a page of query history newest first, with the user's email and the
+// source's name (empty once the source is deleted). Every filter is optional:
+// a NULL argument matches all rows. search matches query text, ignoring case.
+func (q *Queries) ListQueryHistory(ctx context.Context, arg ListQueryHistoryParams) ([]ListQueryHistoryRow, error) {
+	rows, err := q.db.Query(ctx, listQueryHistory,
+		arg.UserID,
+		arg.TeamID,
+		arg.SourceID,
+		arg.Status,
+		arg.Search,
+		arg.Since,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListQueryHistoryRow{}
+	for rows.Next() {
+		var i ListQueryHistoryRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TeamID,
+			&i.SourceID,
+			&i.QueryText,
+			&i.QueryLanguage,
+			&i.DurationMs,
+			&i.RowCount,
+			&i.Status,
+			&i.ErrorMessage,
+			&i.CreatedAt,
+			&i.UserEmail,
+			&i.SourceName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQuerySnippetsByTeam = `-- name: ListQuerySnippetsByTeam :many
+SELECT id, team_id, name, description, query, created_by, created_at, updated_at FROM query_snippets WHERE team_id = $1 ORDER BY name, id
+`
+
+// A team's snippets, by name.
+func (q *Queries) ListQuerySnippetsByTeam(ctx context.Context, teamID int64) ([]QuerySnippet, error) {
+	rows, err := q.db.Query(ctx, listQuerySnippetsByTeam, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []QuerySnippet{}
+	for rows.Next() {
+		var i QuerySnippet
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.Name,
+			&i.Description,
+			&i.Query,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSLOEvaluations = `-- name: ListSLOEvaluations :many
+SELECT id, slo_id, good, total, compliance, burn_rate, error_budget_remaining, error, evaluated_at FROM slo_evaluations
+WHERE slo_id = $1
+ORDER BY evaluated_at DESC, id DESC
+LIMIT $2
+`
+
+type ListSLOEvaluationsParams struct {
+	SloID int64 `json:"slo_id"`
+	Limit int32 `json:"limit"`
+}
+
+// An SLO's evaluations, newest first.
+func (q *Queries) ListSLOEvaluations(ctx context.Context, arg ListSLOEvaluationsParams) ([]SloEvaluation, error) {
+	rows, err := q.db.Query(ctx, listSLOEvaluations, arg.SloID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SloEvaluation{}
+	for rows.Next() {
+		var i SloEvaluation
+		if err := rows.Scan(
+			&i.ID,
+			&i.SloID,
+			&i.Good,
+			&i.Total,
+			&i.Compliance,
+			&i.BurnRate,
+			&i.ErrorBudgetRemaining,
+			&i.Error,
+			&i.EvaluatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSLOs = `-- name: ListSLOs :many
+SELECT id, team_id, source_id, name, description, query_language, good_query, total_query, target, window_seconds, created_by, created_at, updated_at FROM slos ORDER BY id
+`
+
+// List every SLO, for the periodic evaluator.
+func (q *Queries) ListSLOs(ctx context.Context) ([]Slo, error) {
+	rows, err := q.db.Query(ctx, listSLOs)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []ListQueryActivityRow{}
+	items := []Slo{}
 	for rows.Next() {
-		var i ListQueryActivityRow
+		var i Slo
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.TeamID,
 			&i.SourceID,
-			&i.QueryText,
+			&i.Name,
+			&i.Description,
 			&i.QueryLanguage,
-			&i.DurationMs,
-			&i.RowCount,
+			&i.GoodQuery,
+			&i.TotalQuery,
+			&i.Target,
+			&i.WindowSeconds,
+			&i.CreatedBy,
 			&i.CreatedAt,
-			&i.UserEmail,
-			&i.SourceName,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -2344,48 +4165,34 @@ func (q *Queries) ListQueryActivity(ctx context.Context, limit int32) ([]ListQue
 	return items, nil
 }
 
-const listQueryHistory = `-- name: ListQueryHistory :many
-SELECT
-    id,
-    user_id,
-    team_id,
-    source_id,
-    query_text,
-    query_language,
-    duration_ms,
-    row_count,
-    created_at
-FROM query_history
-WHERE user_id = $1
-ORDER BY created_at DESC, id DESC
-LIMIT $2
+const listSLOsByTeam = `-- name: ListSLOsByTeam :many
+SELECT id, team_id, source_id, name, description, query_language, good_query, total_query, target, window_seconds, created_by, created_at, updated_at FROM slos WHERE team_id = $1 ORDER BY name, id
 `
 
-type ListQueryHistoryParams struct {
-	UserID int64 `json:"user_id"`
-	Limit  int32 `json:"limit"`
-}
-
-// List one user's recent history, newest first.
-func (q *Queries) ListQueryHistory(ctx context.Context, arg ListQueryHistoryParams) ([]QueryHistory, error) {
-	rows, err := q.db.Query(ctx, listQueryHistory, arg.UserID, arg.Limit)
+// List a team's SLOs by name.
+func (q *Queries) ListSLOsByTeam(ctx context.Context, teamID int64) ([]Slo, error) {
+	rows, err := q.db.Query(ctx, listSLOsByTeam, teamID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []QueryHistory{}
+	items := []Slo{}
 	for rows.Next() {
-		var i QueryHistory
+		var i Slo
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.TeamID,
 			&i.SourceID,
-			&i.QueryText,
+			&i.Name,
+			&i.Description,
 			&i.QueryLanguage,
-			&i.DurationMs,
-			&i.RowCount,
+			&i.GoodQuery,
+			&i.TotalQuery,
+			&i.Target,
+			&i.WindowSeconds,
+			&i.CreatedBy,
 			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -2588,8 +4395,159 @@ func (q *Queries) ListServiceAccounts(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
+const listSourceAlertEvents = `-- name: ListSourceAlertEvents :many
+SELECT id, source_id, kind, status, value, message, created_at FROM source_alert_events
+WHERE source_id = $1
+ORDER BY id DESC
+LIMIT $2
+`
+
+type ListSourceAlertEventsParams struct {
+	SourceID int64 `json:"source_id"`
+	Limit    int32 `json:"limit"`
+}
+
+func (q *Queries) ListSourceAlertEvents(ctx context.Context, arg ListSourceAlertEventsParams) ([]SourceAlertEvent, error) {
+	rows, err := q.db.Query(ctx, listSourceAlertEvents, arg.SourceID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SourceAlertEvent{}
+	for rows.Next() {
+		var i SourceAlertEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.SourceID,
+			&i.Kind,
+			&i.Status,
+			&i.Value,
+			&i.Message,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSourceRollups = `-- name: ListSourceRollups :many
+SELECT source_id, dimension, rolled_up_from, rolled_up_until, last_run_at, last_error, created_at, updated_at FROM source_rollups ORDER BY source_id
+`
+
+func (q *Queries) ListSourceRollups(ctx context.Context) ([]SourceRollup, error) {
+	rows, err := q.db.Query(ctx, listSourceRollups)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SourceRollup{}
+	for rows.Next() {
+		var i SourceRollup
+		if err := rows.Scan(
+			&i.SourceID,
+			&i.Dimension,
+			&i.RolledUpFrom,
+			&i.RolledUpUntil,
+			&i.LastRunAt,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSourceSchemaSnapshots = `-- name: ListSourceSchemaSnapshots :many
+SELECT id, source_id, columns, changes, captured_at FROM source_schema_snapshots
+WHERE source_id = $1
+ORDER BY id DESC
+LIMIT $2
+`
+
+type ListSourceSchemaSnapshotsParams struct {
+	SourceID int64 `json:"source_id"`
+	Limit    int32 `json:"limit"`
+}
+
+func (q *Queries) ListSourceSchemaSnapshots(ctx context.Context, arg ListSourceSchemaSnapshotsParams) ([]SourceSchemaSnapshot, error) {
+	rows, err := q.db.Query(ctx, listSourceSchemaSnapshots, arg.SourceID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SourceSchemaSnapshot{}
+	for rows.Next() {
+		var i SourceSchemaSnapshot
+		if err := rows.Scan(
+			&i.ID,
+			&i.SourceID,
+			&i.Columns,
+			&i.Changes,
+			&i.CapturedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSourceStatsSnapshots = `-- name: ListSourceStatsSnapshots :many
+SELECT source_id, snapshot_date, total_rows, part_count, compressed_bytes, uncompressed_bytes, columns, captured_at FROM source_stats_snapshots
+WHERE source_id = $1 AND snapshot_date >= $2
+ORDER BY snapshot_date
+`
+
+type ListSourceStatsSnapshotsParams struct {
+	SourceID  int64  `json:"source_id"`
+	SinceDate string `json:"since_date"`
+}
+
+func (q *Queries) ListSourceStatsSnapshots(ctx context.Context, arg ListSourceStatsSnapshotsParams) ([]SourceStatsSnapshot, error) {
+	rows, err := q.db.Query(ctx, listSourceStatsSnapshots, arg.SourceID, arg.SinceDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SourceStatsSnapshot{}
+	for rows.Next() {
+		var i SourceStatsSnapshot
+		if err := rows.Scan(
+			&i.SourceID,
+			&i.SnapshotDate,
+			&i.TotalRows,
+			&i.PartCount,
+			&i.CompressedBytes,
+			&i.UncompressedBytes,
+			&i.Columns,
+			&i.CapturedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSourceTeams = `-- name: ListSourceTeams :many
-SELECT t.id, t.name, t.description, t.managed, t.created_at, t.updated_at
+SELECT t.id, t.name, t.description, t.managed, t.created_at, t.updated_at, t.raw_sql_enabled
 FROM teams t
 JOIN team_sources ts ON t.id = ts.team_id
 WHERE ts.source_id = $1
@@ -2613,6 +4571,7 @@ func (q *Queries) ListSourceTeams(ctx context.Context, sourceID int64) ([]Team,
 			&i.Managed,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RawSqlEnabled,
 		); err != nil {
 			return nil, err
 		}
@@ -2625,7 +4584,7 @@ func (q *Queries) ListSourceTeams(ctx context.Context, sourceID int64) ([]Team,
 }
 
 const listSources = `-- name: ListSources :many
-SELECT id, name, _meta_is_auto_created, _meta_ts_field, _meta_severity_field, description, ttl_days, managed, secret_ref, created_at, updated_at, source_type, connection_config, identity_key FROM sources ORDER BY created_at DESC
+SELECT id, name, _meta_is_auto_created, _meta_ts_field, _meta_severity_field, description, ttl_days, managed, secret_ref, created_at, updated_at, source_type, connection_config, identity_key, tags, extraction_rules, trace_correlation, masked_columns FROM sources ORDER BY created_at DESC
 `
 
 // Get all sources ordered by creation date
@@ -2653,6 +4612,10 @@ func (q *Queries) ListSources(ctx context.Context) ([]Source, error) {
 			&i.SourceType,
 			&i.ConnectionConfig,
 			&i.IdentityKey,
+			&i.Tags,
+			&i.ExtractionRules,
+			&i.TraceCorrelation,
+			&i.MaskedColumns,
 		); err != nil {
 			return nil, err
 		}
@@ -2665,7 +4628,7 @@ func (q *Queries) ListSources(ctx context.Context) ([]Source, error) {
 }
 
 const listSourcesForUser = `-- name: ListSourcesForUser :many
-SELECT DISTINCT s.id, s.name, s._meta_is_auto_created, s._meta_ts_field, s._meta_severity_field, s.description, s.ttl_days, s.managed, s.secret_ref, s.created_at, s.updated_at, s.source_type, s.connection_config, s.identity_key FROM sources s
+SELECT DISTINCT s.id, s.name, s._meta_is_auto_created, s._meta_ts_field, s._meta_severity_field, s.description, s.ttl_days, s.managed, s.secret_ref, s.created_at, s.updated_at, s.source_type, s.connection_config, s.identity_key, s.tags, s.extraction_rules, s.trace_correlation, s.masked_columns FROM sources s
 JOIN team_sources ts ON s.id = ts.source_id
 JOIN team_members tm ON ts.team_id = tm.team_id
 WHERE tm.user_id = $1
@@ -2697,6 +4660,10 @@ func (q *Queries) ListSourcesForUser(ctx context.Context, userID int64) ([]Sourc
 			&i.SourceType,
 			&i.ConnectionConfig,
 			&i.IdentityKey,
+			&i.Tags,
+			&i.ExtractionRules,
+			&i.TraceCorrelation,
+			&i.MaskedColumns,
 		); err != nil {
 			return nil, err
 		}
@@ -2858,7 +4825,7 @@ func (q *Queries) ListTeamMembersWithDetails(ctx context.Context, teamID int64)
 }
 
 const listTeamSources = `-- name: ListTeamSources :many
-SELECT s.id, s.name, s._meta_is_auto_created, s._meta_ts_field, s._meta_severity_field, s.description, s.ttl_days, s.managed, s.secret_ref, s.created_at, s.updated_at, s.source_type, s.connection_config, s.identity_key
+SELECT s.id, s.name, s._meta_is_auto_created, s._meta_ts_field, s._meta_severity_field, s.description, s.ttl_days, s.managed, s.secret_ref, s.created_at, s.updated_at, s.source_type, s.connection_config, s.identity_key, s.tags, s.extraction_rules, s.trace_correlation, s.masked_columns
 FROM sources s
 JOIN team_sources ts ON s.id = ts.source_id
 WHERE ts.team_id = $1
@@ -2890,6 +4857,10 @@ func (q *Queries) ListTeamSources(ctx context.Context, teamID int64) ([]Source,
 			&i.SourceType,
 			&i.ConnectionConfig,
 			&i.IdentityKey,
+			&i.Tags,
+			&i.ExtractionRules,
+			&i.TraceCorrelation,
+			&i.MaskedColumns,
 		); err != nil {
 			return nil, err
 		}
@@ -2902,7 +4873,7 @@ func (q *Queries) ListTeamSources(ctx context.Context, teamID int64) ([]Source,
 }
 
 const listTeams = `-- name: ListTeams :many
-SELECT t.id, t.name, t.description, t.managed, t.created_at, t.updated_at, COUNT(tm.user_id) as member_count
+SELECT t.id, t.name, t.description, t.managed, t.created_at, t.updated_at, t.raw_sql_enabled, COUNT(tm.user_id) as member_count
 FROM teams t
 LEFT JOIN team_members tm ON t.id = tm.team_id
 GROUP BY t.id
@@ -2910,13 +4881,14 @@ ORDER BY t.created_at DESC
 `
 
 type ListTeamsRow struct {
-	ID          int64              `json:"id"`
-	Name        string             `json:"name"`
-	Description pgtype.Text        `json:"description"`
-	Managed     bool               `json:"managed"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	MemberCount int64              `json:"member_count"`
+	ID            int64              `json:"id"`
+	Name          string             `json:"name"`
+	Description   pgtype.Text        `json:"description"`
+	Managed       bool               `json:"managed"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	RawSqlEnabled bool               `json:"raw_sql_enabled"`
+	MemberCount   int64              `json:"member_count"`
 }
 
 // List all teams
@@ -2936,6 +4908,7 @@ func (q *Queries) ListTeams(ctx context.Context) ([]ListTeamsRow, error) {
 			&i.Managed,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RawSqlEnabled,
 			&i.MemberCount,
 		); err != nil {
 			return nil, err
@@ -2955,6 +4928,7 @@ SELECT
     t.description,
     t.created_at,
     t.updated_at,
+    t.raw_sql_enabled,
     tm.role,  -- The current user's role in this team
     (SELECT COUNT(*) FROM team_members sub_tm WHERE sub_tm.team_id = t.id) as member_count
 FROM
@@ -2968,13 +4942,14 @@ ORDER BY
 `
 
 type ListTeamsForUserRow struct {
-	ID          int64              `json:"id"`
-	Name        string             `json:"name"`
-	Description pgtype.Text        `json:"description"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	Role        string             `json:"role"`
-	MemberCount int64              `json:"member_count"`
+	ID            int64              `json:"id"`
+	Name          string             `json:"name"`
+	Description   pgtype.Text        `json:"description"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	RawSqlEnabled bool               `json:"raw_sql_enabled"`
+	Role          string             `json:"role"`
+	MemberCount   int64              `json:"member_count"`
 }
 
 // List all teams a user is a member of
@@ -2993,6 +4968,7 @@ func (q *Queries) ListTeamsForUser(ctx context.Context, userID int64) ([]ListTea
 			&i.Description,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RawSqlEnabled,
 			&i.Role,
 			&i.MemberCount,
 		); err != nil {
@@ -3006,8 +4982,115 @@ func (q *Queries) ListTeamsForUser(ctx context.Context, userID int64) ([]ListTea
 	return items, nil
 }
 
+const listTrackedQueries = `-- name: ListTrackedQueries :many
+SELECT id, instance, class, user_id, team_id, source_id, query_text, started_at FROM tracked_queries
+WHERE instance = $1
+ORDER BY started_at, id
+`
+
+// One instance's persisted queries, oldest first.
+func (q *Queries) ListTrackedQueries(ctx context.Context, instance string) ([]TrackedQuery, error) {
+	rows, err := q.db.Query(ctx, listTrackedQueries, instance)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TrackedQuery{}
+	for rows.Next() {
+		var i TrackedQuery
+		if err := rows.Scan(
+			&i.ID,
+			&i.Instance,
+			&i.Class,
+			&i.UserID,
+			&i.TeamID,
+			&i.SourceID,
+			&i.QueryText,
+			&i.StartedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnexpiredAlertSilences = `-- name: ListUnexpiredAlertSilences :many
+SELECT id, alert_id, source_id, team_id, reason, starts_at, ends_at, recurrence_json, created_by, created_at, updated_at FROM alert_silences
+WHERE ends_at IS NULL OR ends_at > $1
+ORDER BY id
+`
+
+// Silences that have not ended by the given time, for the alert evaluator.
+func (q *Queries) ListUnexpiredAlertSilences(ctx context.Context, endsAt pgtype.Timestamptz) ([]AlertSilence, error) {
+	rows, err := q.db.Query(ctx, listUnexpiredAlertSilences, endsAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AlertSilence{}
+	for rows.Next() {
+		var i AlertSilence
+		if err := rows.Scan(
+			&i.ID,
+			&i.AlertID,
+			&i.SourceID,
+			&i.TeamID,
+			&i.Reason,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.RecurrenceJson,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserSourceRoles = `-- name: ListUserSourceRoles :many
+SELECT DISTINCT tm.role FROM team_members tm
+JOIN team_sources ts ON tm.team_id = ts.team_id
+WHERE tm.user_id = $1 AND ts.source_id = $2
+`
+
+type ListUserSourceRolesParams struct {
+	UserID   int64 `json:"user_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+// List the distinct roles a user holds across the teams that have access to a source
+func (q *Queries) ListUserSourceRoles(ctx context.Context, arg ListUserSourceRolesParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, listUserSourceRoles, arg.UserID, arg.SourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, err
+		}
+		items = append(items, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUserTeams = `-- name: ListUserTeams :many
-SELECT t.id, t.name, t.description, t.managed, t.created_at, t.updated_at
+SELECT t.id, t.name, t.description, t.managed, t.created_at, t.updated_at, t.raw_sql_enabled
 FROM teams t
 JOIN team_members tm ON t.id = tm.team_id
 WHERE tm.user_id = $1
@@ -3031,6 +5114,7 @@ func (q *Queries) ListUserTeams(ctx context.Context, userID int64) ([]Team, erro
 			&i.Managed,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RawSqlEnabled,
 		); err != nil {
 			return nil, err
 		}
@@ -3068,7 +5152,42 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 			&i.AccountType,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.PasswordHash,
+			&i.PasswordHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhooks = `-- name: ListWebhooks :many
+SELECT id, name, url, event_types_json, secret, is_active, created_by, created_at, updated_at FROM webhooks ORDER BY name, id
+`
+
+// List every webhook by name.
+func (q *Queries) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows, err := q.db.Query(ctx, listWebhooks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Webhook{}
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.EventTypesJson,
+			&i.Secret,
+			&i.IsActive,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -3083,20 +5202,49 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 const markAlertEvaluated = `-- name: MarkAlertEvaluated :exec
 UPDATE alerts
 SET last_state = 'resolved',
+    pending_since = NULL,
+    breach_count = 0,
     last_evaluated_at = now(),
     updated_at = now()
 WHERE id = $1
 `
 
+// Resolve the alert and end any pending run of breaches.
 func (q *Queries) MarkAlertEvaluated(ctx context.Context, id int64) error {
 	_, err := q.db.Exec(ctx, markAlertEvaluated, id)
 	return err
 }
 
+const markAlertPending = `-- name: MarkAlertPending :one
+UPDATE alerts
+SET pending_since = COALESCE(pending_since, now()),
+    breach_count = breach_count + 1,
+    last_evaluated_at = now(),
+    updated_at = now()
+WHERE id = $1
+RETURNING pending_since, breach_count
+`
+
+type MarkAlertPendingRow struct {
+	PendingSince pgtype.Timestamptz `json:"pending_since"`
+	BreachCount  int64              `json:"breach_count"`
+}
+
+// Record a breach of an alert that has not fired yet, starting the pending
+// run on its first breach.
+func (q *Queries) MarkAlertPending(ctx context.Context, id int64) (MarkAlertPendingRow, error) {
+	row := q.db.QueryRow(ctx, markAlertPending, id)
+	var i MarkAlertPendingRow
+	err := row.Scan(&i.PendingSince, &i.BreachCount)
+	return i, err
+}
+
 const markAlertTriggered = `-- name: MarkAlertTriggered :exec
 UPDATE alerts
 SET last_state = 'firing',
     last_triggered_at = CASE WHEN last_state = 'firing' THEN last_triggered_at ELSE now() END,
+    pending_since = NULL,
+    breach_count = breach_count + 1,
     last_evaluated_at = now(),
     updated_at = now()
 WHERE id = $1
@@ -3163,6 +5311,30 @@ func (q *Queries) PruneQueryHistoryForUser(ctx context.Context, arg PruneQueryHi
 	return err
 }
 
+const pruneSLOEvaluations = `-- name: PruneSLOEvaluations :exec
+DELETE FROM slo_evaluations AS target
+WHERE target.slo_id = $1
+  AND target.id NOT IN (
+    SELECT keep.id
+    FROM slo_evaluations AS keep
+    WHERE keep.slo_id = $2
+    ORDER BY keep.evaluated_at DESC, keep.id DESC
+    LIMIT $3
+ )
+`
+
+type PruneSLOEvaluationsParams struct {
+	SloID   int64 `json:"slo_id"`
+	SloID_2 int64 `json:"slo_id_2"`
+	Limit   int32 `json:"limit"`
+}
+
+// Keep only an SLO's newest evaluations.
+func (q *Queries) PruneSLOEvaluations(ctx context.Context, arg PruneSLOEvaluationsParams) error {
+	_, err := q.db.Exec(ctx, pruneSLOEvaluations, arg.SloID, arg.SloID_2, arg.Limit)
+	return err
+}
+
 const queryVolumeByDay = `-- name: QueryVolumeByDay :many
 SELECT
     qsd.bucket_date AS bucket_date,
@@ -3281,6 +5453,21 @@ func (q *Queries) ResolveAlertHistory(ctx context.Context, arg ResolveAlertHisto
 	return id, err
 }
 
+const setAlertManaged = `-- name: SetAlertManaged :exec
+UPDATE alerts SET managed = $1, updated_at = now() WHERE id = $2
+`
+
+type SetAlertManagedParams struct {
+	Managed bool  `json:"managed"`
+	ID      int64 `json:"id"`
+}
+
+// Mark an alert as managed/unmanaged
+func (q *Queries) SetAlertManaged(ctx context.Context, arg SetAlertManagedParams) error {
+	_, err := q.db.Exec(ctx, setAlertManaged, arg.Managed, arg.ID)
+	return err
+}
+
 const setSourceManaged = `-- name: SetSourceManaged :exec
 UPDATE sources SET managed = $1, secret_ref = $2, updated_at = now() WHERE id = $3
 `
@@ -3312,6 +5499,26 @@ func (q *Queries) SetTeamManaged(ctx context.Context, arg SetTeamManagedParams)
 	return err
 }
 
+const setTeamSourceRowFilter = `-- name: SetTeamSourceRowFilter :execrows
+UPDATE team_sources SET row_filter = $1
+WHERE team_id = $2 AND source_id = $3
+`
+
+type SetTeamSourceRowFilterParams struct {
+	RowFilter string `json:"row_filter"`
+	TeamID    int64  `json:"team_id"`
+	SourceID  int64  `json:"source_id"`
+}
+
+// Set the row filter on a team-source link; ” removes it
+func (q *Queries) SetTeamSourceRowFilter(ctx context.Context, arg SetTeamSourceRowFilterParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setTeamSourceRowFilter, arg.RowFilter, arg.TeamID, arg.SourceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const setUserManaged = `-- name: SetUserManaged :exec
 UPDATE users SET managed = $1, updated_at = now() WHERE id = $2
 `
@@ -3513,10 +5720,13 @@ SET name = $1,
     annotations_json = $13,
     recipient_user_ids_json = $14,
     webhook_urls_json = $15,
-    generator_url = $16,
-    is_active = $17,
+    channels_json = $16,
+    slo_id = $17,
+    generator_url = $18,
+    is_active = $19,
+    for_seconds = $20,
     updated_at = now()
-WHERE id = $18
+WHERE id = $21
 RETURNING id
 `
 
@@ -3536,8 +5746,11 @@ type UpdateAlertParams struct {
 	AnnotationsJson      pgtype.Text `json:"annotations_json"`
 	RecipientUserIdsJson pgtype.Text `json:"recipient_user_ids_json"`
 	WebhookUrlsJson      pgtype.Text `json:"webhook_urls_json"`
+	ChannelsJson         pgtype.Text `json:"channels_json"`
+	SloID                pgtype.Int8 `json:"slo_id"`
 	GeneratorUrl         pgtype.Text `json:"generator_url"`
 	IsActive             bool        `json:"is_active"`
+	ForSeconds           int64       `json:"for_seconds"`
 	ID                   int64       `json:"id"`
 }
 
@@ -3558,8 +5771,11 @@ func (q *Queries) UpdateAlert(ctx context.Context, arg UpdateAlertParams) (int64
 		arg.AnnotationsJson,
 		arg.RecipientUserIdsJson,
 		arg.WebhookUrlsJson,
+		arg.ChannelsJson,
+		arg.SloID,
 		arg.GeneratorUrl,
 		arg.IsActive,
+		arg.ForSeconds,
 		arg.ID,
 	)
 	var id int64
@@ -3586,6 +5802,75 @@ func (q *Queries) UpdateAlertHistoryPayload(ctx context.Context, arg UpdateAlert
 	return id, err
 }
 
+const updateAlertSilence = `-- name: UpdateAlertSilence :one
+UPDATE alert_silences
+SET reason = $1,
+    starts_at = $2,
+    ends_at = $3,
+    recurrence_json = $4,
+    updated_at = now()
+WHERE id = $5
+RETURNING id
+`
+
+type UpdateAlertSilenceParams struct {
+	Reason         string             `json:"reason"`
+	StartsAt       pgtype.Timestamptz `json:"starts_at"`
+	EndsAt         pgtype.Timestamptz `json:"ends_at"`
+	RecurrenceJson string             `json:"recurrence_json"`
+	ID             int64              `json:"id"`
+}
+
+// Update a silence's reason and schedule; RETURNING lets callers detect not-found.
+func (q *Queries) UpdateAlertSilence(ctx context.Context, arg UpdateAlertSilenceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, updateAlertSilence,
+		arg.Reason,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.RecurrenceJson,
+		arg.ID,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const updateAnnotation = `-- name: UpdateAnnotation :one
+UPDATE annotations
+SET source_id = $1,
+    text = $2,
+    tags_json = $3,
+    starts_at = $4,
+    ends_at = $5,
+    updated_at = now()
+WHERE id = $6
+RETURNING id
+`
+
+type UpdateAnnotationParams struct {
+	SourceID pgtype.Int8        `json:"source_id"`
+	Text     string             `json:"text"`
+	TagsJson string             `json:"tags_json"`
+	StartsAt pgtype.Timestamptz `json:"starts_at"`
+	EndsAt   pgtype.Timestamptz `json:"ends_at"`
+	ID       int64              `json:"id"`
+}
+
+// Update an annotation's note, scope and range; RETURNING lets callers detect not-found.
+func (q *Queries) UpdateAnnotation(ctx context.Context, arg UpdateAnnotationParams) (int64, error) {
+	row := q.db.QueryRow(ctx, updateAnnotation,
+		arg.SourceID,
+		arg.Text,
+		arg.TagsJson,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.ID,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const updateCollection = `-- name: UpdateCollection :exec
 UPDATE collections
 SET name = $1,
@@ -3660,6 +5945,156 @@ func (q *Queries) UpdateExportJobRunning(ctx context.Context, arg UpdateExportJo
 	return id, err
 }
 
+const updateLogBookmark = `-- name: UpdateLogBookmark :one
+UPDATE log_bookmarks
+SET note = $1,
+    shared = $2,
+    updated_at = now()
+WHERE id = $3
+RETURNING id
+`
+
+type UpdateLogBookmarkParams struct {
+	Note   string `json:"note"`
+	Shared bool   `json:"shared"`
+	ID     int64  `json:"id"`
+}
+
+// Update a bookmark's note and visibility; RETURNING lets callers detect not-found.
+func (q *Queries) UpdateLogBookmark(ctx context.Context, arg UpdateLogBookmarkParams) (int64, error) {
+	row := q.db.QueryRow(ctx, updateLogBookmark, arg.Note, arg.Shared, arg.ID)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const updateNotebook = `-- name: UpdateNotebook :one
+UPDATE notebooks
+SET name = $1,
+    description = $2,
+    cells_json = $3,
+    updated_at = now()
+WHERE id = $4
+RETURNING id
+`
+
+type UpdateNotebookParams struct {
+	Name        string      `json:"name"`
+	Description pgtype.Text `json:"description"`
+	CellsJson   string      `json:"cells_json"`
+	ID          int64       `json:"id"`
+}
+
+// Update a notebook's mutable fields; RETURNING lets callers detect not-found.
+func (q *Queries) UpdateNotebook(ctx context.Context, arg UpdateNotebookParams) (int64, error) {
+	row := q.db.QueryRow(ctx, updateNotebook,
+		arg.Name,
+		arg.Description,
+		arg.CellsJson,
+		arg.ID,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const updateNotebookCells = `-- name: UpdateNotebookCells :one
+UPDATE notebooks
+SET cells_json = $1
+WHERE id = $2 AND cells_json = $3
+RETURNING id
+`
+
+type UpdateNotebookCellsParams struct {
+	CellsJson         string `json:"cells_json"`
+	ID                int64  `json:"id"`
+	PreviousCellsJson string `json:"previous_cells_json"`
+}
+
+// Replace only the cell blob, leaving updated_at alone: refreshing a cell's
+// cached result is not an edit and must not trip the optimistic-concurrency
+// check of someone editing the notebook. The previous-cells guard makes it a
+// compare-and-swap: a write based on a stale read matches no row instead of
+// clobbering a concurrent change.
+func (q *Queries) UpdateNotebookCells(ctx context.Context, arg UpdateNotebookCellsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, updateNotebookCells, arg.CellsJson, arg.ID, arg.PreviousCellsJson)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const updateQuerySnippet = `-- name: UpdateQuerySnippet :one
+UPDATE query_snippets
+SET name = $1,
+    description = $2,
+    query = $3,
+    updated_at = now()
+WHERE id = $4
+RETURNING id
+`
+
+type UpdateQuerySnippetParams struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Query       string `json:"query"`
+	ID          int64  `json:"id"`
+}
+
+// Update a snippet's name, description and query; RETURNING lets callers detect not-found.
+func (q *Queries) UpdateQuerySnippet(ctx context.Context, arg UpdateQuerySnippetParams) (int64, error) {
+	row := q.db.QueryRow(ctx, updateQuerySnippet,
+		arg.Name,
+		arg.Description,
+		arg.Query,
+		arg.ID,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const updateSLO = `-- name: UpdateSLO :one
+UPDATE slos
+SET name = $1,
+    description = $2,
+    query_language = $3,
+    good_query = $4,
+    total_query = $5,
+    target = $6,
+    window_seconds = $7,
+    updated_at = now()
+WHERE id = $8
+RETURNING id
+`
+
+type UpdateSLOParams struct {
+	Name          string  `json:"name"`
+	Description   string  `json:"description"`
+	QueryLanguage string  `json:"query_language"`
+	GoodQuery     string  `json:"good_query"`
+	TotalQuery    string  `json:"total_query"`
+	Target        float64 `json:"target"`
+	WindowSeconds int64   `json:"window_seconds"`
+	ID            int64   `json:"id"`
+}
+
+// Update an SLO's mutable fields; RETURNING lets callers detect not-found.
+func (q *Queries) UpdateSLO(ctx context.Context, arg UpdateSLOParams) (int64, error) {
+	row := q.db.QueryRow(ctx, updateSLO,
+		arg.Name,
+		arg.Description,
+		arg.QueryLanguage,
+		arg.GoodQuery,
+		arg.TotalQuery,
+		arg.Target,
+		arg.WindowSeconds,
+		arg.ID,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const updateSavedQuery = `-- name: UpdateSavedQuery :exec
 UPDATE saved_queries
 SET name = $1,
@@ -3706,8 +6141,12 @@ SET name = $1,
     ttl_days = $9,
     managed = $10,
     secret_ref = $11,
+    tags = $12,
+    extraction_rules = $13,
+    trace_correlation = $14,
+    masked_columns = $15,
     updated_at = now()
-WHERE id = $12
+WHERE id = $16
 `
 
 type UpdateSourceParams struct {
@@ -3722,6 +6161,10 @@ type UpdateSourceParams struct {
 	TtlDays           int64       `json:"ttl_days"`
 	Managed           bool        `json:"managed"`
 	SecretRef         pgtype.Text `json:"secret_ref"`
+	Tags              []byte      `json:"tags"`
+	ExtractionRules   []byte      `json:"extraction_rules"`
+	TraceCorrelation  []byte      `json:"trace_correlation"`
+	MaskedColumns     []byte      `json:"masked_columns"`
 	ID                int64       `json:"id"`
 }
 
@@ -3739,24 +6182,82 @@ func (q *Queries) UpdateSource(ctx context.Context, arg UpdateSourceParams) erro
 		arg.TtlDays,
 		arg.Managed,
 		arg.SecretRef,
+		arg.Tags,
+		arg.ExtractionRules,
+		arg.TraceCorrelation,
+		arg.MaskedColumns,
 		arg.ID,
 	)
 	return err
 }
 
+const updateSourceConnectionConfig = `-- name: UpdateSourceConnectionConfig :exec
+UPDATE sources SET connection_config = $1 WHERE id = $2
+`
+
+type UpdateSourceConnectionConfigParams struct {
+	ConnectionConfig []byte `json:"connection_config"`
+	ID               int64  `json:"id"`
+}
+
+// Rewrite a source's connection config in place, e.g. to re-seal its secrets,
+// without touching updated_at.
+func (q *Queries) UpdateSourceConnectionConfig(ctx context.Context, arg UpdateSourceConnectionConfigParams) error {
+	_, err := q.db.Exec(ctx, updateSourceConnectionConfig, arg.ConnectionConfig, arg.ID)
+	return err
+}
+
+const updateSourceRollupProgress = `-- name: UpdateSourceRollupProgress :execrows
+UPDATE source_rollups
+SET rolled_up_from = $1,
+    rolled_up_until = $2,
+    last_run_at = $3,
+    last_error = $4,
+    updated_at = now()
+WHERE source_id = $5 AND dimension = $6
+`
+
+type UpdateSourceRollupProgressParams struct {
+	RolledUpFrom  pgtype.Timestamptz `json:"rolled_up_from"`
+	RolledUpUntil pgtype.Timestamptz `json:"rolled_up_until"`
+	LastRunAt     pgtype.Timestamptz `json:"last_run_at"`
+	LastError     string             `json:"last_error"`
+	SourceID      int64              `json:"source_id"`
+	Dimension     string             `json:"dimension"`
+}
+
+// Record the outcome of a rollup run. The dimension guard drops the update when
+// the rollup was reconfigured while the run was in flight.
+func (q *Queries) UpdateSourceRollupProgress(ctx context.Context, arg UpdateSourceRollupProgressParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateSourceRollupProgress,
+		arg.RolledUpFrom,
+		arg.RolledUpUntil,
+		arg.LastRunAt,
+		arg.LastError,
+		arg.SourceID,
+		arg.Dimension,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateTeam = `-- name: UpdateTeam :exec
 UPDATE teams
 SET name = $1,
     description = $2,
-    updated_at = $3
-WHERE id = $4
+    raw_sql_enabled = $3,
+    updated_at = $4
+WHERE id = $5
 `
 
 type UpdateTeamParams struct {
-	Name        string             `json:"name"`
-	Description pgtype.Text        `json:"description"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	ID          int64              `json:"id"`
+	Name          string             `json:"name"`
+	Description   pgtype.Text        `json:"description"`
+	RawSqlEnabled bool               `json:"raw_sql_enabled"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	ID            int64              `json:"id"`
 }
 
 // Update a team
@@ -3764,6 +6265,7 @@ func (q *Queries) UpdateTeam(ctx context.Context, arg UpdateTeamParams) error {
 	_, err := q.db.Exec(ctx, updateTeam,
 		arg.Name,
 		arg.Description,
+		arg.RawSqlEnabled,
 		arg.UpdatedAt,
 		arg.ID,
 	)
@@ -3826,6 +6328,106 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
 	return err
 }
 
+const updateWebhook = `-- name: UpdateWebhook :one
+UPDATE webhooks
+SET name = $1,
+    url = $2,
+    event_types_json = $3,
+    secret = $4,
+    is_active = $5,
+    updated_at = now()
+WHERE id = $6
+RETURNING id
+`
+
+type UpdateWebhookParams struct {
+	Name           string `json:"name"`
+	Url            string `json:"url"`
+	EventTypesJson string `json:"event_types_json"`
+	Secret         string `json:"secret"`
+	IsActive       bool   `json:"is_active"`
+	ID             int64  `json:"id"`
+}
+
+// Replace a webhook's settings; RETURNING lets callers detect not-found.
+func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (int64, error) {
+	row := q.db.QueryRow(ctx, updateWebhook,
+		arg.Name,
+		arg.Url,
+		arg.EventTypesJson,
+		arg.Secret,
+		arg.IsActive,
+		arg.ID,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const upsertSourceRollup = `-- name: UpsertSourceRollup :exec
+INSERT INTO source_rollups (source_id, dimension)
+VALUES ($1, $2)
+ON CONFLICT (source_id) DO UPDATE SET
+    rolled_up_from = CASE WHEN source_rollups.dimension = excluded.dimension THEN source_rollups.rolled_up_from END,
+    rolled_up_until = CASE WHEN source_rollups.dimension = excluded.dimension THEN source_rollups.rolled_up_until END,
+    dimension = excluded.dimension,
+    updated_at = now()
+`
+
+type UpsertSourceRollupParams struct {
+	SourceID  int64  `json:"source_id"`
+	Dimension string `json:"dimension"`
+}
+
+// Enable or reconfigure a source's rollup. Changing the dimension invalidates
+// the materialised hours, so progress is reset and the rollup backfills again.
+func (q *Queries) UpsertSourceRollup(ctx context.Context, arg UpsertSourceRollupParams) error {
+	_, err := q.db.Exec(ctx, upsertSourceRollup, arg.SourceID, arg.Dimension)
+	return err
+}
+
+const upsertSourceStatsSnapshot = `-- name: UpsertSourceStatsSnapshot :exec
+
+INSERT INTO source_stats_snapshots (
+    source_id, snapshot_date, total_rows, part_count, compressed_bytes, uncompressed_bytes, columns, captured_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT(source_id, snapshot_date) DO UPDATE SET
+    total_rows = excluded.total_rows,
+    part_count = excluded.part_count,
+    compressed_bytes = excluded.compressed_bytes,
+    uncompressed_bytes = excluded.uncompressed_bytes,
+    columns = excluded.columns,
+    captured_at = excluded.captured_at
+`
+
+type UpsertSourceStatsSnapshotParams struct {
+	SourceID          int64              `json:"source_id"`
+	SnapshotDate      string             `json:"snapshot_date"`
+	TotalRows         int64              `json:"total_rows"`
+	PartCount         int64              `json:"part_count"`
+	CompressedBytes   int64              `json:"compressed_bytes"`
+	UncompressedBytes int64              `json:"uncompressed_bytes"`
+	Columns           []byte             `json:"columns"`
+	CapturedAt        pgtype.Timestamptz `json:"captured_at"`
+}
+
+// Source stats snapshots ------------------------------------------------------
+// Record a source's storage statistics for a day, replacing an earlier
+// snapshot taken the same day.
+func (q *Queries) UpsertSourceStatsSnapshot(ctx context.Context, arg UpsertSourceStatsSnapshotParams) error {
+	_, err := q.db.Exec(ctx, upsertSourceStatsSnapshot,
+		arg.SourceID,
+		arg.SnapshotDate,
+		arg.TotalRows,
+		arg.PartCount,
+		arg.CompressedBytes,
+		arg.UncompressedBytes,
+		arg.Columns,
+		arg.CapturedAt,
+	)
+	return err
+}
+
 const upsertSystemSetting = `-- name: UpsertSystemSetting :exec
 INSERT INTO system_settings (key, value, value_type, category, description, is_sensitive, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6, now())
@@ -3858,6 +6460,86 @@ func (q *Queries) UpsertSystemSetting(ctx context.Context, arg UpsertSystemSetti
 	return err
 }
 
+const upsertTeamColumnPreset = `-- name: UpsertTeamColumnPreset :exec
+INSERT INTO team_column_presets (team_id, source_id, columns_json, updated_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (team_id, source_id) DO UPDATE SET
+    columns_json = excluded.columns_json,
+    updated_by = excluded.updated_by,
+    updated_at = now()
+`
+
+type UpsertTeamColumnPresetParams struct {
+	TeamID      int64       `json:"team_id"`
+	SourceID    int64       `json:"source_id"`
+	ColumnsJson string      `json:"columns_json"`
+	UpdatedBy   pgtype.Int8 `json:"updated_by"`
+}
+
+// Save a team's default column layout for a source, replacing any earlier one.
+func (q *Queries) UpsertTeamColumnPreset(ctx context.Context, arg UpsertTeamColumnPresetParams) error {
+	_, err := q.db.Exec(ctx, upsertTeamColumnPreset,
+		arg.TeamID,
+		arg.SourceID,
+		arg.ColumnsJson,
+		arg.UpdatedBy,
+	)
+	return err
+}
+
+const upsertTrackedQuery = `-- name: UpsertTrackedQuery :exec
+
+INSERT INTO tracked_queries (id, instance, class, user_id, team_id, source_id, query_text, started_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (id) DO NOTHING
+`
+
+type UpsertTrackedQueryParams struct {
+	ID        string             `json:"id"`
+	Instance  string             `json:"instance"`
+	Class     string             `json:"class"`
+	UserID    int64              `json:"user_id"`
+	TeamID    int64              `json:"team_id"`
+	SourceID  int64              `json:"source_id"`
+	QueryText string             `json:"query_text"`
+	StartedAt pgtype.Timestamptz `json:"started_at"`
+}
+
+// Tracked queries --------------------------------------------------------------
+func (q *Queries) UpsertTrackedQuery(ctx context.Context, arg UpsertTrackedQueryParams) error {
+	_, err := q.db.Exec(ctx, upsertTrackedQuery,
+		arg.ID,
+		arg.Instance,
+		arg.Class,
+		arg.UserID,
+		arg.TeamID,
+		arg.SourceID,
+		arg.QueryText,
+		arg.StartedAt,
+	)
+	return err
+}
+
+const upsertUserColumnPreset = `-- name: UpsertUserColumnPreset :exec
+INSERT INTO user_column_presets (user_id, source_id, columns_json)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, source_id) DO UPDATE SET
+    columns_json = excluded.columns_json,
+    updated_at = now()
+`
+
+type UpsertUserColumnPresetParams struct {
+	UserID      int64  `json:"user_id"`
+	SourceID    int64  `json:"source_id"`
+	ColumnsJson string `json:"columns_json"`
+}
+
+// Save a user's column layout for a source, replacing any earlier one.
+func (q *Queries) UpsertUserColumnPreset(ctx context.Context, arg UpsertUserColumnPresetParams) error {
+	_, err := q.db.Exec(ctx, upsertUserColumnPreset, arg.UserID, arg.SourceID, arg.ColumnsJson)
+	return err
+}
+
 const upsertUserPreferences = `-- name: UpsertUserPreferences :exec
 INSERT INTO user_preferences (user_id, preferences_json, created_at, updated_at)
 VALUES ($1, $2, now(), now())
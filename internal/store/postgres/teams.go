@@ -10,11 +10,12 @@ import (
 
 func teamToModel(r sqlc.Team) *models.Team {
 	return &models.Team{
-		ID:          models.TeamID(r.ID),
-		Name:        r.Name,
-		Description: textStr(r.Description),
-		Managed:     r.Managed,
-		Timestamps:  models.Timestamps{CreatedAt: r.CreatedAt.Time, UpdatedAt: r.UpdatedAt.Time},
+		ID:            models.TeamID(r.ID),
+		Name:          r.Name,
+		Description:   textStr(r.Description),
+		Managed:       r.Managed,
+		RawSQLEnabled: r.RawSqlEnabled,
+		Timestamps:    models.Timestamps{CreatedAt: r.CreatedAt.Time, UpdatedAt: r.UpdatedAt.Time},
 	}
 }
 
@@ -44,6 +45,7 @@ func (s *Store) CreateTeam(ctx context.Context, team *models.Team) error {
 	if row, err := s.q.GetTeam(ctx, id); err == nil {
 		team.CreatedAt = row.CreatedAt.Time
 		team.UpdatedAt = row.UpdatedAt.Time
+		team.RawSQLEnabled = row.RawSqlEnabled
 	}
 	return nil
 }
@@ -75,10 +77,11 @@ func (s *Store) GetTeamByName(ctx context.Context, name string) (*models.Team, e
 // UpdateTeam updates an existing team record.
 func (s *Store) UpdateTeam(ctx context.Context, team *models.Team) error {
 	err := s.q.UpdateTeam(ctx, sqlc.UpdateTeamParams{
-		Name:        team.Name,
-		Description: text(team.Description),
-		UpdatedAt:   ts(team.UpdatedAt),
-		ID:          int64(team.ID),
+		Name:          team.Name,
+		Description:   text(team.Description),
+		RawSqlEnabled: team.RawSQLEnabled,
+		UpdatedAt:     ts(team.UpdatedAt),
+		ID:            int64(team.ID),
 	})
 	if err != nil {
 		if isUniqueViolation(err) {
@@ -110,11 +113,12 @@ func (s *Store) ListTeams(ctx context.Context) ([]*models.Team, error) {
 	for i := range rows {
 		row := rows[i]
 		teams = append(teams, &models.Team{
-			ID:          models.TeamID(row.ID),
-			Name:        row.Name,
-			Description: textStr(row.Description),
-			MemberCount: int(row.MemberCount),
-			Timestamps:  models.Timestamps{CreatedAt: row.CreatedAt.Time, UpdatedAt: row.UpdatedAt.Time},
+			ID:            models.TeamID(row.ID),
+			Name:          row.Name,
+			Description:   textStr(row.Description),
+			MemberCount:   int(row.MemberCount),
+			RawSQLEnabled: row.RawSqlEnabled,
+			Timestamps:    models.Timestamps{CreatedAt: row.CreatedAt.Time, UpdatedAt: row.UpdatedAt.Time},
 		})
 	}
 	return teams, nil
@@ -285,7 +289,7 @@ func (s *Store) ListTeamSources(ctx context.Context, teamID models.TeamID) ([]*m
 	sources := make([]*models.Source, 0, len(rows))
 	for i := range rows {
 		r := rows[i]
-		sources = append(sources, sourceToModel(r))
+		sources = append(sources, s.sourceToModel(r))
 	}
 	return sources, nil
 }
@@ -314,7 +318,7 @@ func (s *Store) ListSourcesForUser(ctx context.Context, userID models.UserID) ([
 	sources := make([]*models.Source, 0, len(rows))
 	for i := range rows {
 		r := rows[i]
-		sources = append(sources, sourceToModel(r))
+		sources = append(sources, s.sourceToModel(r))
 	}
 	return sources, nil
 }
@@ -332,6 +336,39 @@ func (s *Store) TeamHasSource(ctx context.Context, teamID models.TeamID, sourceI
 	return hasAccess, nil
 }
 
+// GetTeamSourceRowFilter returns the row filter on a team-source link.
+func (s *Store) GetTeamSourceRowFilter(ctx context.Context, teamID models.TeamID, sourceID models.SourceID) (string, error) {
+	filter, err := s.q.GetTeamSourceRowFilter(ctx, sqlc.GetTeamSourceRowFilterParams{
+		TeamID:   int64(teamID),
+		SourceID: int64(sourceID),
+	})
+	if err != nil {
+		if notFound(err) {
+			return "", models.ErrNotFound
+		}
+		s.log.Error("failed to get team source row filter", "error", err, "team_id", teamID, "source_id", sourceID)
+		return "", fmt.Errorf("error getting team source row filter: %w", err)
+	}
+	return filter, nil
+}
+
+// SetTeamSourceRowFilter replaces the row filter on a team-source link.
+func (s *Store) SetTeamSourceRowFilter(ctx context.Context, teamID models.TeamID, sourceID models.SourceID, filter string) error {
+	n, err := s.q.SetTeamSourceRowFilter(ctx, sqlc.SetTeamSourceRowFilterParams{
+		RowFilter: filter,
+		TeamID:    int64(teamID),
+		SourceID:  int64(sourceID),
+	})
+	if err != nil {
+		s.log.Error("failed to set team source row filter", "error", err, "team_id", teamID, "source_id", sourceID)
+		return fmt.Errorf("error setting team source row filter: %w", err)
+	}
+	if n == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
 // UserHasSourceAccess reports whether a user can reach a source via any team.
 func (s *Store) UserHasSourceAccess(ctx context.Context, userID models.UserID, sourceID models.SourceID) (bool, error) {
 	hasAccess, err := s.q.UserHasSourceAccess(ctx, sqlc.UserHasSourceAccessParams{
@@ -345,6 +382,24 @@ func (s *Store) UserHasSourceAccess(ctx context.Context, userID models.UserID, s
 	return hasAccess, nil
 }
 
+// ListUserSourceRoles returns the distinct team roles a user holds across the
+// teams that have access to a source.
+func (s *Store) ListUserSourceRoles(ctx context.Context, userID models.UserID, sourceID models.SourceID) ([]models.TeamRole, error) {
+	rows, err := s.q.ListUserSourceRoles(ctx, sqlc.ListUserSourceRolesParams{
+		UserID:   int64(userID),
+		SourceID: int64(sourceID),
+	})
+	if err != nil {
+		s.log.Error("failed to list user source roles in db", "error", err, "user_id", userID, "source_id", sourceID)
+		return nil, fmt.Errorf("error listing user source roles: %w", err)
+	}
+	roles := make([]models.TeamRole, 0, len(rows))
+	for _, role := range rows {
+		roles = append(roles, models.TeamRole(role))
+	}
+	return roles, nil
+}
+
 // ListTeamsForUser retrieves a user's teams with their role and member count.
 func (s *Store) ListTeamsForUser(ctx context.Context, userID models.UserID) ([]*models.UserTeamDetails, error) {
 	rows, err := s.q.ListTeamsForUser(ctx, int64(userID))
@@ -356,13 +411,14 @@ func (s *Store) ListTeamsForUser(ctx context.Context, userID models.UserID) ([]*
 	for i := range rows {
 		row := rows[i]
 		out = append(out, &models.UserTeamDetails{
-			ID:          models.TeamID(row.ID),
-			Name:        row.Name,
-			Description: textStr(row.Description),
-			CreatedAt:   row.CreatedAt.Time,
-			UpdatedAt:   row.UpdatedAt.Time,
-			MemberCount: int(row.MemberCount),
-			Role:        models.TeamRole(row.Role),
+			ID:            models.TeamID(row.ID),
+			Name:          row.Name,
+			Description:   textStr(row.Description),
+			CreatedAt:     row.CreatedAt.Time,
+			UpdatedAt:     row.UpdatedAt.Time,
+			MemberCount:   int(row.MemberCount),
+			Role:          models.TeamRole(row.Role),
+			RawSQLEnabled: row.RawSqlEnabled,
 		})
 	}
 	return out, nil
@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mr-karan/logchef/internal/store/alertjson"
+	"github.com/mr-karan/logchef/internal/store/postgres/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// webhookSecretField binds sealed webhook secrets to their column.
+const webhookSecretField = "webhook.secret"
+
+// CreateWebhook inserts a new webhook and repopulates the model with the
+// persisted row (id and timestamps).
+func (s *Store) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	if webhook == nil {
+		return fmt.Errorf("webhook payload is required")
+	}
+	eventTypes, secret, err := s.encodeWebhook(webhook)
+	if err != nil {
+		return err
+	}
+	params := sqlc.CreateWebhookParams{
+		Name:           webhook.Name,
+		Url:            webhook.URL,
+		EventTypesJson: eventTypes,
+		Secret:         secret,
+		IsActive:       webhook.IsActive,
+	}
+	if webhook.CreatedBy != nil {
+		params.CreatedBy = int8Val(int64(*webhook.CreatedBy))
+	}
+
+	id, err := s.q.CreateWebhook(ctx, params)
+	if err != nil {
+		s.log.Error("failed to create webhook", "error", err)
+		return fmt.Errorf("error creating webhook: %w", err)
+	}
+
+	created, err := s.GetWebhook(ctx, models.WebhookID(id))
+	if err != nil {
+		return err
+	}
+	*webhook = *created
+	return nil
+}
+
+// GetWebhook returns a webhook by id, or models.ErrNotFound if missing.
+func (s *Store) GetWebhook(ctx context.Context, id models.WebhookID) (*models.Webhook, error) {
+	row, err := s.q.GetWebhook(ctx, int64(id))
+	if err != nil {
+		if notFound(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting webhook id %d: %w", id, err)
+	}
+	return s.mapWebhookRow(row)
+}
+
+// ListWebhooks returns every webhook, by name.
+func (s *Store) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	rows, err := s.q.ListWebhooks(ctx)
+	if err != nil {
+		s.log.Error("failed to list webhooks", "error", err)
+		return nil, fmt.Errorf("error listing webhooks: %w", err)
+	}
+	webhooks := make([]*models.Webhook, 0, len(rows))
+	for _, row := range rows {
+		webhook, err := s.mapWebhookRow(row)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook overwrites a webhook's settings and secret. Returns
+// models.ErrNotFound when the id does not exist.
+func (s *Store) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	if webhook == nil {
+		return fmt.Errorf("webhook payload is required")
+	}
+	eventTypes, secret, err := s.encodeWebhook(webhook)
+	if err != nil {
+		return err
+	}
+	_, err = s.q.UpdateWebhook(ctx, sqlc.UpdateWebhookParams{
+		Name:           webhook.Name,
+		Url:            webhook.URL,
+		EventTypesJson: eventTypes,
+		Secret:         secret,
+		IsActive:       webhook.IsActive,
+		ID:             int64(webhook.ID),
+	})
+	if err != nil {
+		if notFound(err) {
+			return models.ErrNotFound
+		}
+		s.log.Error("failed to update webhook", "error", err, "webhook_id", webhook.ID)
+		return fmt.Errorf("error updating webhook: %w", err)
+	}
+	return nil
+}
+
+// DeleteWebhook removes a webhook. Returns models.ErrNotFound when the id does
+// not exist.
+func (s *Store) DeleteWebhook(ctx context.Context, id models.WebhookID) error {
+	if _, err := s.q.DeleteWebhook(ctx, int64(id)); err != nil {
+		if notFound(err) {
+			return models.ErrNotFound
+		}
+		s.log.Error("failed to delete webhook", "error", err, "webhook_id", id)
+		return fmt.Errorf("error deleting webhook: %w", err)
+	}
+	return nil
+}
+
+// encodeWebhook returns the webhook's event types as JSON and its secret
+// sealed for storage.
+func (s *Store) encodeWebhook(webhook *models.Webhook) (eventTypes, secret string, err error) {
+	eventTypes, err = alertjson.Encode(webhook.EventTypes, false)
+	if err != nil {
+		return "", "", fmt.Errorf("error encoding webhook event types: %w", err)
+	}
+	secret, err = s.secrets.Seal(webhookSecretField, webhook.Secret)
+	if err != nil {
+		return "", "", fmt.Errorf("error sealing webhook secret: %w", err)
+	}
+	return eventTypes, secret, nil
+}
+
+func (s *Store) mapWebhookRow(row sqlc.Webhook) (*models.Webhook, error) {
+	eventTypes, err := alertjson.Decode[[]models.EventType](row.EventTypesJson)
+	if err != nil {
+		return nil, fmt.Errorf("decoding event types of webhook %d: %w", row.ID, err)
+	}
+	secret, err := s.secrets.Open(webhookSecretField, row.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("opening secret of webhook %d: %w", row.ID, err)
+	}
+	webhook := &models.Webhook{
+		ID:         models.WebhookID(row.ID),
+		Name:       row.Name,
+		URL:        row.Url,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		IsActive:   row.IsActive,
+		Timestamps: models.Timestamps{
+			CreatedAt: row.CreatedAt.Time,
+			UpdatedAt: row.UpdatedAt.Time,
+		},
+	}
+	if row.CreatedBy.Valid {
+		uid := models.UserID(row.CreatedBy.Int64)
+		webhook.CreatedBy = &uid
+	}
+	return webhook, nil
+}
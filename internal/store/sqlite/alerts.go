@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/mr-karan/logchef/internal/store/alertjson"
 	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
@@ -29,6 +30,14 @@ func unmarshalUserIDs(raw sql.NullString) ([]models.UserID, error) {
 	return alertjson.Decode[[]models.UserID](raw.String)
 }
 
+func marshalAlertChannels(channels []models.AlertChannel) (string, error) {
+	return alertjson.Encode(channels, len(channels) == 0)
+}
+
+func unmarshalAlertChannels(raw sql.NullString) ([]models.AlertChannel, error) {
+	return alertjson.Decode[[]models.AlertChannel](raw.String)
+}
+
 func marshalStringSlice(values []string) (string, error) {
 	return alertjson.Encode(values, len(values) == 0)
 }
@@ -142,6 +151,40 @@ func (db *DB) MarkAlertEvaluated(ctx context.Context, alertID models.AlertID) er
 	return nil
 }
 
+// AcquireAlertLease claims the alert's evaluation lease for holder until
+// expiresAt and reports whether holder now has it.
+func (db *DB) AcquireAlertLease(ctx context.Context, alertID models.AlertID, holder string, now, expiresAt time.Time) (bool, error) {
+	n, err := db.writeQueries.AcquireAlertLease(ctx, sqlc.AcquireAlertLeaseParams{
+		AlertID:   int64(alertID),
+		Holder:    holder,
+		ExpiresAt: expiresAt.UTC(),
+		Now:       now.UTC(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire alert lease: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ReleaseAlertLeases drops every evaluation lease holder has.
+func (db *DB) ReleaseAlertLeases(ctx context.Context, holder string) error {
+	if err := db.writeQueries.DeleteAlertLeasesByHolder(ctx, holder); err != nil {
+		return fmt.Errorf("failed to release alert leases: %w", err)
+	}
+	return nil
+}
+
+// MarkAlertPending records a breach of an alert that is waiting out its
+// for_seconds, returning when the current run of breaches started and how
+// many evaluations it has lasted.
+func (db *DB) MarkAlertPending(ctx context.Context, alertID models.AlertID) (time.Time, int, error) {
+	row, err := db.writeQueries.MarkAlertPending(ctx, int64(alertID))
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("failed to mark alert pending: %w", err)
+	}
+	return row.PendingSince.Time, int(row.BreachCount), nil
+}
+
 // MarkAlertTriggered updates state when an alert fires.
 func (db *DB) MarkAlertTriggered(ctx context.Context, alertID models.AlertID) error {
 	if err := db.writeQueries.MarkAlertTriggered(ctx, int64(alertID)); err != nil {
@@ -237,6 +280,29 @@ func (db *DB) ListAlertHistory(ctx context.Context, alertID models.AlertID, limi
 	return history, nil
 }
 
+// ListAlertHistorySince returns the alert's history entries that were still
+// open at or after since, oldest first.
+func (db *DB) ListAlertHistorySince(ctx context.Context, alertID models.AlertID, since time.Time) ([]*models.AlertHistoryEntry, error) {
+	// Bound as text datetime() can parse; the driver's own time.Time format
+	// isn't one of them.
+	rows, err := db.readQueries.ListAlertHistorySince(ctx, sqlc.ListAlertHistorySinceParams{
+		AlertID: int64(alertID),
+		Since:   since.UTC().Format(time.DateTime),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert history: %w", err)
+	}
+	history := make([]*models.AlertHistoryEntry, 0, len(rows))
+	for i := range rows {
+		entry, err := alertHistoryFromSQLC(rows[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode alert history: %w", err)
+		}
+		history = append(history, entry)
+	}
+	return history, nil
+}
+
 // PruneAlertHistory keeps the most recent N entries for an alert.
 func (db *DB) PruneAlertHistory(ctx context.Context, alertID models.AlertID, keep int) error {
 	if keep <= 0 {
@@ -269,6 +335,10 @@ func alertCreateParams(alert *models.Alert) (sqlc.CreateAlertParams, error) {
 	if err != nil {
 		return sqlc.CreateAlertParams{}, fmt.Errorf("failed to marshal webhook URLs: %w", err)
 	}
+	channelsJSON, err := marshalAlertChannels(alert.Channels)
+	if err != nil {
+		return sqlc.CreateAlertParams{}, fmt.Errorf("failed to marshal channels: %w", err)
+	}
 
 	params := sqlc.CreateAlertParams{
 		SourceID:             int64(alert.SourceID),
@@ -282,17 +352,22 @@ func alertCreateParams(alert *models.Alert) (sqlc.CreateAlertParams, error) {
 		ThresholdOperator:    string(alert.ThresholdOperator),
 		ThresholdValue:       alert.ThresholdValue,
 		FrequencySeconds:     int64(alert.FrequencySeconds),
+		ForSeconds:           int64(alert.ForSeconds),
 		Severity:             string(alert.Severity),
 		LabelsJson:           nullString(labelsJSON),
 		AnnotationsJson:      nullString(annotationsJSON),
 		RecipientUserIdsJson: nullString(recipientUserIDsJSON),
 		WebhookUrlsJson:      nullString(webhookURLsJSON),
+		ChannelsJson:         nullString(channelsJSON),
 		GeneratorUrl:         nullString(alert.GeneratorURL),
 		IsActive:             boolToInt(alert.IsActive),
 	}
 	if alert.CreatedBy != nil {
 		params.CreatedBy = sql.NullInt64{Int64: int64(*alert.CreatedBy), Valid: true}
 	}
+	if alert.SLOID != nil {
+		params.SloID = sql.NullInt64{Int64: int64(*alert.SLOID), Valid: true}
+	}
 	return params, nil
 }
 
@@ -312,11 +387,14 @@ func alertUpdateParams(alert *models.Alert) (sqlc.UpdateAlertParams, error) {
 		ThresholdOperator:    createParams.ThresholdOperator,
 		ThresholdValue:       createParams.ThresholdValue,
 		FrequencySeconds:     createParams.FrequencySeconds,
+		ForSeconds:           createParams.ForSeconds,
 		Severity:             createParams.Severity,
 		LabelsJson:           createParams.LabelsJson,
 		AnnotationsJson:      createParams.AnnotationsJson,
 		RecipientUserIdsJson: createParams.RecipientUserIdsJson,
 		WebhookUrlsJson:      createParams.WebhookUrlsJson,
+		ChannelsJson:         createParams.ChannelsJson,
+		SloID:                createParams.SloID,
 		GeneratorUrl:         createParams.GeneratorUrl,
 		IsActive:             createParams.IsActive,
 		ID:                   int64(alert.ID),
@@ -353,6 +431,10 @@ func alertFromSQLC(row sqlc.Alert) (*models.Alert, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode webhook URLs: %w", err)
 	}
+	channels, err := unmarshalAlertChannels(row.ChannelsJson)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode channels: %w", err)
+	}
 
 	alert := &models.Alert{
 		ID:                models.AlertID(row.ID),
@@ -367,14 +449,18 @@ func alertFromSQLC(row sqlc.Alert) (*models.Alert, error) {
 		ThresholdOperator: models.AlertThresholdOperator(row.ThresholdOperator),
 		ThresholdValue:    row.ThresholdValue,
 		FrequencySeconds:  int(row.FrequencySeconds),
+		ForSeconds:        int(row.ForSeconds),
 		Severity:          models.AlertSeverity(row.Severity),
 		Labels:            labels,
 		Annotations:       annotations,
 		RecipientUserIDs:  recipientUserIDs,
 		WebhookURLs:       webhookURLs,
+		Channels:          channels,
 		GeneratorURL:      row.GeneratorUrl.String,
 		IsActive:          row.IsActive == 1,
 		LastState:         models.AlertState(row.LastState),
+		BreachCount:       int(row.BreachCount),
+		Managed:           row.Managed == 1,
 		CreatedAt:         row.CreatedAt,
 		UpdatedAt:         row.UpdatedAt,
 	}
@@ -384,10 +470,20 @@ func alertFromSQLC(row sqlc.Alert) (*models.Alert, error) {
 	if row.LastTriggeredAt.Valid {
 		alert.LastTriggeredAt = &row.LastTriggeredAt.Time
 	}
+	if row.PendingSince.Valid {
+		alert.PendingSince = &row.PendingSince.Time
+		if alert.LastState == models.AlertStateResolved {
+			alert.LastState = models.AlertStatePending
+		}
+	}
 	if row.CreatedBy.Valid {
 		uid := models.UserID(row.CreatedBy.Int64)
 		alert.CreatedBy = &uid
 	}
+	if row.SloID.Valid {
+		sloID := models.SLOID(row.SloID.Int64)
+		alert.SLOID = &sloID
+	}
 	return alert, nil
 }
 
@@ -0,0 +1,177 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store/alertjson"
+	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// CreateAnnotation inserts a new annotation and repopulates the model with the
+// persisted row (id and timestamps).
+func (db *DB) CreateAnnotation(ctx context.Context, annotation *models.Annotation) error {
+	if annotation == nil {
+		return fmt.Errorf("annotation payload is required")
+	}
+	tags, err := alertjson.Encode(annotation.Tags, len(annotation.Tags) == 0)
+	if err != nil {
+		return fmt.Errorf("error encoding annotation tags: %w", err)
+	}
+	params := sqlc.CreateAnnotationParams{
+		TeamID:   int64(annotation.TeamID),
+		Text:     annotation.Text,
+		TagsJson: tags,
+		StartsAt: annotation.StartsAt.UTC(),
+		EndsAt:   nullTime(utcPtr(annotation.EndsAt)),
+	}
+	if annotation.SourceID != nil {
+		params.SourceID = sql.NullInt64{Int64: int64(*annotation.SourceID), Valid: true}
+	}
+	if annotation.CreatedBy != nil {
+		params.CreatedBy = sql.NullInt64{Int64: int64(*annotation.CreatedBy), Valid: true}
+	}
+
+	id, err := db.writeQueries.CreateAnnotation(ctx, params)
+	if err != nil {
+		db.log.Error("failed to create annotation", "error", err, "team_id", annotation.TeamID)
+		return fmt.Errorf("error creating annotation: %w", err)
+	}
+
+	created, err := db.GetAnnotation(ctx, models.AnnotationID(id))
+	if err != nil {
+		return err
+	}
+	*annotation = *created
+	return nil
+}
+
+// GetAnnotation returns an annotation by id, or models.ErrNotFound if missing.
+func (db *DB) GetAnnotation(ctx context.Context, id models.AnnotationID) (*models.Annotation, error) {
+	row, err := db.readQueries.GetAnnotation(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting annotation id %d: %w", id, err)
+	}
+	return mapAnnotationRow(row)
+}
+
+// ListAnnotationsByTeam returns a team's annotations, newest start first.
+func (db *DB) ListAnnotationsByTeam(ctx context.Context, teamID models.TeamID) ([]*models.Annotation, error) {
+	rows, err := db.readQueries.ListAnnotationsByTeam(ctx, int64(teamID))
+	if err != nil {
+		db.log.Error("failed to list annotations", "error", err, "team_id", teamID)
+		return nil, fmt.Errorf("error listing annotations: %w", err)
+	}
+	return mapAnnotationRows(rows)
+}
+
+// ListAnnotationsInRange returns the team's annotations that overlap
+// [start, end] and are either team-wide or scoped to sourceID, oldest first.
+func (db *DB) ListAnnotationsInRange(ctx context.Context, teamID models.TeamID, sourceID models.SourceID, start, end time.Time) ([]*models.Annotation, error) {
+	rows, err := db.readQueries.ListAnnotationsInRange(ctx, sqlc.ListAnnotationsInRangeParams{
+		TeamID:     int64(teamID),
+		SourceID:   sql.NullInt64{Int64: int64(sourceID), Valid: true},
+		RangeEnd:   end.UTC(),
+		RangeStart: start.UTC(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing annotations in range: %w", err)
+	}
+	return mapAnnotationRows(rows)
+}
+
+// UpdateAnnotation overwrites an annotation's note, scope and range. Returns
+// models.ErrNotFound when the id does not exist.
+func (db *DB) UpdateAnnotation(ctx context.Context, annotation *models.Annotation) error {
+	if annotation == nil {
+		return fmt.Errorf("annotation payload is required")
+	}
+	tags, err := alertjson.Encode(annotation.Tags, len(annotation.Tags) == 0)
+	if err != nil {
+		return fmt.Errorf("error encoding annotation tags: %w", err)
+	}
+	params := sqlc.UpdateAnnotationParams{
+		Text:     annotation.Text,
+		TagsJson: tags,
+		StartsAt: annotation.StartsAt.UTC(),
+		EndsAt:   nullTime(utcPtr(annotation.EndsAt)),
+		ID:       int64(annotation.ID),
+	}
+	if annotation.SourceID != nil {
+		params.SourceID = sql.NullInt64{Int64: int64(*annotation.SourceID), Valid: true}
+	}
+	if _, err := db.writeQueries.UpdateAnnotation(ctx, params); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to update annotation", "error", err, "annotation_id", annotation.ID)
+		return fmt.Errorf("error updating annotation: %w", err)
+	}
+	return nil
+}
+
+// DeleteAnnotation removes an annotation. Returns models.ErrNotFound when the
+// id does not exist.
+func (db *DB) DeleteAnnotation(ctx context.Context, id models.AnnotationID) error {
+	if _, err := db.writeQueries.DeleteAnnotation(ctx, int64(id)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to delete annotation", "error", err, "annotation_id", id)
+		return fmt.Errorf("error deleting annotation: %w", err)
+	}
+	return nil
+}
+
+func mapAnnotationRows(rows []sqlc.Annotation) ([]*models.Annotation, error) {
+	annotations := make([]*models.Annotation, 0, len(rows))
+	for _, row := range rows {
+		annotation, err := mapAnnotationRow(row)
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations, nil
+}
+
+func mapAnnotationRow(row sqlc.Annotation) (*models.Annotation, error) {
+	tags, err := alertjson.Decode[[]string](row.TagsJson)
+	if err != nil {
+		return nil, fmt.Errorf("decoding tags of annotation %d: %w", row.ID, err)
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	annotation := &models.Annotation{
+		ID:       models.AnnotationID(row.ID),
+		TeamID:   models.TeamID(row.TeamID),
+		Text:     row.Text,
+		Tags:     tags,
+		StartsAt: row.StartsAt,
+		Timestamps: models.Timestamps{
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		},
+	}
+	if row.SourceID.Valid {
+		id := models.SourceID(row.SourceID.Int64)
+		annotation.SourceID = &id
+	}
+	if row.EndsAt.Valid {
+		endsAt := row.EndsAt.Time
+		annotation.EndsAt = &endsAt
+	}
+	if row.CreatedBy.Valid {
+		uid := models.UserID(row.CreatedBy.Int64)
+		annotation.CreatedBy = &uid
+	}
+	return annotation, nil
+}
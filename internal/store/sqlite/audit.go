@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// InsertAuditEvent appends one audit event and populates its ID. A zero
+// CreatedAt is stamped with the current time; timestamps are stored in UTC so
+// the text comparisons in ListAuditEvents order correctly.
+func (db *DB) InsertAuditEvent(ctx context.Context, event *models.AuditEvent) error {
+	if event == nil {
+		return fmt.Errorf("audit event is required")
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	event.CreatedAt = event.CreatedAt.UTC()
+
+	params := sqlc.InsertAuditEventParams{
+		UserEmail:    event.UserEmail,
+		Action:       string(event.Action),
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		IpAddress:    event.IPAddress,
+		Details:      nullString(string(event.Details)),
+		CreatedAt:    event.CreatedAt,
+	}
+	if event.UserID != nil {
+		params.UserID = sql.NullInt64{Int64: int64(*event.UserID), Valid: true}
+	}
+	if event.TeamID != nil {
+		params.TeamID = sql.NullInt64{Int64: int64(*event.TeamID), Valid: true}
+	}
+
+	id, err := db.writeQueries.InsertAuditEvent(ctx, params)
+	if err != nil {
+		db.log.Error("failed to insert audit event", "error", err, "action", event.Action)
+		return fmt.Errorf("error inserting audit event: %w", err)
+	}
+	event.ID = id
+	return nil
+}
+
+// ListAuditEvents returns audit events matching filter, newest first.
+func (db *DB) ListAuditEvents(ctx context.Context, filter models.AuditEventFilter) ([]*models.AuditEvent, error) {
+	params := sqlc.ListAuditEventsParams{
+		Action:       nullString(string(filter.Action)),
+		ResourceType: nullString(filter.ResourceType),
+		Limit:        int64(filter.Limit),
+		Offset:       int64(filter.Offset),
+	}
+	if filter.UserID != nil {
+		params.UserID = sql.NullInt64{Int64: int64(*filter.UserID), Valid: true}
+	}
+	if filter.TeamID != nil {
+		params.TeamID = sql.NullInt64{Int64: int64(*filter.TeamID), Valid: true}
+	}
+	if !filter.Since.IsZero() {
+		params.Since = sql.NullTime{Time: filter.Since.UTC(), Valid: true}
+	}
+	if !filter.Until.IsZero() {
+		params.Until = sql.NullTime{Time: filter.Until.UTC(), Valid: true}
+	}
+
+	rows, err := db.readQueries.ListAuditEvents(ctx, params)
+	if err != nil {
+		db.log.Error("failed to list audit events", "error", err)
+		return nil, fmt.Errorf("error listing audit events: %w", err)
+	}
+
+	events := make([]*models.AuditEvent, 0, len(rows))
+	for i := range rows {
+		r := rows[i]
+		event := &models.AuditEvent{
+			ID:           r.ID,
+			UserEmail:    r.UserEmail,
+			Action:       models.AuditAction(r.Action),
+			ResourceType: r.ResourceType,
+			ResourceID:   r.ResourceID,
+			IPAddress:    r.IpAddress,
+			CreatedAt:    r.CreatedAt,
+		}
+		if r.UserID.Valid {
+			userID := models.UserID(r.UserID.Int64)
+			event.UserID = &userID
+		}
+		if r.TeamID.Valid {
+			teamID := models.TeamID(r.TeamID.Int64)
+			event.TeamID = &teamID
+		}
+		if r.Details.Valid && r.Details.String != "" {
+			event.Details = json.RawMessage(r.Details.String)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,204 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store"
+
+	msqlite "modernc.org/sqlite"
+)
+
+// restoreSuffix names the staged restore file next to the database. New
+// swaps it in before running migrations.
+const restoreSuffix = ".restore"
+
+var _ store.Backuper = (*DB)(nil)
+
+// Backup writes a consistent snapshot of the live database to dest using
+// SQLite's online backup API. Readers and writers keep running; the copy is
+// taken in a single step so it reflects one point in time. dest must not
+// already exist.
+func (db *DB) Backup(ctx context.Context, dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("backup destination %s already exists", dest)
+	}
+
+	conn, err := db.readDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("acquiring connection for backup: %w", err)
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn any) error {
+		src, ok := driverConn.(interface {
+			NewBackup(string) (*msqlite.Backup, error)
+		})
+		if !ok {
+			return errors.New("sqlite driver does not support online backup")
+		}
+		bk, err := src.NewBackup(dest)
+		if err != nil {
+			return err
+		}
+		if _, err := bk.Step(-1); err != nil {
+			_ = bk.Finish()
+			return err
+		}
+		return bk.Finish()
+	})
+	if err == nil {
+		err = setRollbackJournal(ctx, dest)
+	}
+	if err != nil {
+		_ = os.Remove(dest)
+		return fmt.Errorf("backing up database: %w", err)
+	}
+	db.log.Info("database backup written", "path", dest)
+	return nil
+}
+
+// setRollbackJournal takes a backup out of WAL mode so it is a single
+// self-contained file.
+func setRollbackJournal(ctx context.Context, path string) error {
+	conn, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.ExecContext(ctx, "PRAGMA journal_mode=DELETE")
+	return err
+}
+
+// StageRestore verifies the backup at src and stages it to replace the
+// database at dbPath on the next start, before migrations run; a live
+// database can't be swapped out from under open connections. It does not
+// touch dbPath itself, so it is safe to run while the server is up.
+func StageRestore(ctx context.Context, dbPath, src string) error {
+	if err := VerifyBackup(ctx, src); err != nil {
+		return err
+	}
+	staged := dbPath + restoreSuffix
+	if err := copyFile(src, staged+".tmp"); err != nil {
+		return fmt.Errorf("staging restore: %w", err)
+	}
+	if err := os.Rename(staged+".tmp", staged); err != nil {
+		_ = os.Remove(staged + ".tmp")
+		return fmt.Errorf("staging restore: %w", err)
+	}
+	return nil
+}
+
+// VerifyBackup checks that path is an intact logchef database this binary
+// can migrate: it must pass PRAGMA integrity_check and carry a clean schema
+// version no newer than the embedded migrations.
+func VerifyBackup(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("backup %s: %w", path, err)
+	}
+	conn, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("opening backup: %w", err)
+	}
+	defer conn.Close()
+
+	var integrity string
+	if err := conn.QueryRowContext(ctx, "PRAGMA integrity_check").Scan(&integrity); err != nil {
+		return fmt.Errorf("backup %s is not a readable SQLite database: %w", path, err)
+	}
+	if integrity != "ok" {
+		return fmt.Errorf("backup %s failed integrity check: %s", path, integrity)
+	}
+
+	var version uint
+	var dirty bool
+	if err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations").Scan(&version, &dirty); err != nil {
+		return fmt.Errorf("backup %s has no logchef schema version: %w", path, err)
+	}
+	if dirty {
+		return fmt.Errorf("backup %s was taken mid-migration (version %d is dirty)", path, version)
+	}
+	latest, err := latestMigrationVersion()
+	if err != nil {
+		return err
+	}
+	if version > latest {
+		return fmt.Errorf("backup %s is at schema version %d, newer than this binary supports (%d)", path, version, latest)
+	}
+	return nil
+}
+
+// applyPendingRestore swaps a staged restore into place. The replaced
+// database and its WAL files are kept alongside with a .pre-restore suffix
+// so a bad restore can be undone by hand.
+func applyPendingRestore(ctx context.Context, path string, log *slog.Logger) error {
+	staged := path + restoreSuffix
+	if _, err := os.Stat(staged); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err := VerifyBackup(ctx, staged); err != nil {
+		return fmt.Errorf("staged restore %s: %w", staged, err)
+	}
+
+	keep := fmt.Sprintf("%s.pre-restore-%s", path, time.Now().UTC().Format("20060102-150405"))
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		err := os.Rename(path+suffix, keep+suffix)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("moving aside current database: %w", err)
+		}
+	}
+	if err := os.Rename(staged, path); err != nil {
+		return fmt.Errorf("applying staged restore: %w", err)
+	}
+	log.Warn("restored database from staged backup", "path", path, "previous", keep)
+	return nil
+}
+
+// latestMigrationVersion returns the highest embedded migration number.
+func latestMigrationVersion() (uint, error) {
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return 0, fmt.Errorf("reading embedded migrations: %w", err)
+	}
+	var latest uint
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok {
+			continue
+		}
+		if v, err := strconv.ParseUint(prefix, 10, 64); err == nil && uint(v) > latest {
+			latest = uint(v)
+		}
+	}
+	return latest, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
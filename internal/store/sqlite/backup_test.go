@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mr-karan/logchef/internal/config"
+)
+
+// TestBackupAndStagedRestore takes an online backup, changes the live
+// database, stages the backup and reopens: the restart must bring back the
+// snapshot and keep the replaced database aside.
+func TestBackupAndStagedRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logchef.db")
+	open := func() *DB {
+		t.Helper()
+		db, err := New(ctx, Options{
+			Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+			Config: config.SQLiteConfig{Path: path},
+		})
+		if err != nil {
+			t.Fatalf("sqlite.New: %v", err)
+		}
+		return db
+	}
+
+	db := open()
+	if err := db.CreateUser(ctx, makeUser("before@example.com")); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	backup := filepath.Join(dir, "snapshot.db")
+	if err := db.Backup(ctx, backup); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	if err := db.Backup(ctx, backup); err == nil {
+		t.Error("Backup over an existing file should fail")
+	}
+	if err := VerifyBackup(ctx, backup); err != nil {
+		t.Fatalf("VerifyBackup: %v", err)
+	}
+
+	if err := db.CreateUser(ctx, makeUser("after@example.com")); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if err := StageRestore(ctx, path, backup); err != nil {
+		t.Fatalf("StageRestore: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	db = open()
+	defer db.Close()
+	users, err := db.ListUsers(ctx)
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	if len(users) != 1 || users[0].Email != "before@example.com" {
+		t.Fatalf("users after restore = %+v, want only before@example.com", users)
+	}
+	if _, err := os.Stat(path + restoreSuffix); !os.IsNotExist(err) {
+		t.Errorf("staged restore file still present: %v", err)
+	}
+	kept, _ := filepath.Glob(path + ".pre-restore-*")
+	if len(kept) == 0 {
+		t.Error("replaced database was not kept aside")
+	}
+}
+
+func TestVerifyBackup_RejectsNonDatabase(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	junk := filepath.Join(dir, "junk.db")
+	if err := os.WriteFile(junk, []byte("not a database"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyBackup(ctx, junk); err == nil {
+		t.Error("VerifyBackup accepted a non-database file")
+	}
+	if err := StageRestore(ctx, filepath.Join(dir, "live.db"), junk); err == nil {
+		t.Error("StageRestore accepted a non-database file")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "live.db"+restoreSuffix)); !os.IsNotExist(err) {
+		t.Error("rejected backup was staged")
+	}
+	if err := VerifyBackup(ctx, filepath.Join(dir, "missing.db")); err == nil {
+		t.Error("VerifyBackup accepted a missing file")
+	}
+}
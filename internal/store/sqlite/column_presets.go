@@ -0,0 +1,171 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mr-karan/logchef/internal/store/alertjson"
+	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// GetUserColumnPreset returns a user's column preset for a source, or
+// models.ErrNotFound if they haven't saved one.
+func (db *DB) GetUserColumnPreset(ctx context.Context, userID models.UserID, sourceID models.SourceID) (*models.ColumnPreset, error) {
+	row, err := db.readQueries.GetUserColumnPreset(ctx, sqlc.GetUserColumnPresetParams{
+		UserID:   int64(userID),
+		SourceID: int64(sourceID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting column preset of user %d for source %d: %w", userID, sourceID, err)
+	}
+	columns, err := decodeColumnPresetColumns(row.ColumnsJson)
+	if err != nil {
+		return nil, err
+	}
+	uid := models.UserID(row.UserID)
+	return &models.ColumnPreset{
+		SourceID:   models.SourceID(row.SourceID),
+		UserID:     &uid,
+		Columns:    columns,
+		Timestamps: models.Timestamps{CreatedAt: row.CreatedAt, UpdatedAt: row.UpdatedAt},
+	}, nil
+}
+
+// SaveUserColumnPreset creates or replaces a user's column preset for a
+// source and repopulates the model with the persisted row.
+func (db *DB) SaveUserColumnPreset(ctx context.Context, preset *models.ColumnPreset) error {
+	if preset == nil || preset.UserID == nil {
+		return fmt.Errorf("user column preset payload is required")
+	}
+	columns, err := alertjson.Encode(preset.Columns, false)
+	if err != nil {
+		return fmt.Errorf("error encoding column preset: %w", err)
+	}
+	err = db.writeQueries.UpsertUserColumnPreset(ctx, sqlc.UpsertUserColumnPresetParams{
+		UserID:      int64(*preset.UserID),
+		SourceID:    int64(preset.SourceID),
+		ColumnsJson: columns,
+	})
+	if err != nil {
+		db.log.Error("failed to save user column preset", "error", err, "user_id", *preset.UserID, "source_id", preset.SourceID)
+		return fmt.Errorf("error saving user column preset: %w", err)
+	}
+	saved, err := db.GetUserColumnPreset(ctx, *preset.UserID, preset.SourceID)
+	if err != nil {
+		return err
+	}
+	*preset = *saved
+	return nil
+}
+
+// DeleteUserColumnPreset removes a user's column preset for a source. Returns
+// models.ErrNotFound when there is none.
+func (db *DB) DeleteUserColumnPreset(ctx context.Context, userID models.UserID, sourceID models.SourceID) error {
+	_, err := db.writeQueries.DeleteUserColumnPreset(ctx, sqlc.DeleteUserColumnPresetParams{
+		UserID:   int64(userID),
+		SourceID: int64(sourceID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to delete user column preset", "error", err, "user_id", userID, "source_id", sourceID)
+		return fmt.Errorf("error deleting user column preset: %w", err)
+	}
+	return nil
+}
+
+// GetTeamColumnPreset returns a team's default column preset for a source, or
+// models.ErrNotFound if it has none.
+func (db *DB) GetTeamColumnPreset(ctx context.Context, teamID models.TeamID, sourceID models.SourceID) (*models.ColumnPreset, error) {
+	row, err := db.readQueries.GetTeamColumnPreset(ctx, sqlc.GetTeamColumnPresetParams{
+		TeamID:   int64(teamID),
+		SourceID: int64(sourceID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting column preset of team %d for source %d: %w", teamID, sourceID, err)
+	}
+	columns, err := decodeColumnPresetColumns(row.ColumnsJson)
+	if err != nil {
+		return nil, err
+	}
+	tid := models.TeamID(row.TeamID)
+	preset := &models.ColumnPreset{
+		SourceID:   models.SourceID(row.SourceID),
+		TeamID:     &tid,
+		Columns:    columns,
+		Timestamps: models.Timestamps{CreatedAt: row.CreatedAt, UpdatedAt: row.UpdatedAt},
+	}
+	if row.UpdatedBy.Valid {
+		uid := models.UserID(row.UpdatedBy.Int64)
+		preset.UpdatedBy = &uid
+	}
+	return preset, nil
+}
+
+// SaveTeamColumnPreset creates or replaces a team's default column preset for
+// a source and repopulates the model with the persisted row.
+func (db *DB) SaveTeamColumnPreset(ctx context.Context, preset *models.ColumnPreset) error {
+	if preset == nil || preset.TeamID == nil {
+		return fmt.Errorf("team column preset payload is required")
+	}
+	columns, err := alertjson.Encode(preset.Columns, false)
+	if err != nil {
+		return fmt.Errorf("error encoding column preset: %w", err)
+	}
+	params := sqlc.UpsertTeamColumnPresetParams{
+		TeamID:      int64(*preset.TeamID),
+		SourceID:    int64(preset.SourceID),
+		ColumnsJson: columns,
+	}
+	if preset.UpdatedBy != nil {
+		params.UpdatedBy = sql.NullInt64{Int64: int64(*preset.UpdatedBy), Valid: true}
+	}
+	if err := db.writeQueries.UpsertTeamColumnPreset(ctx, params); err != nil {
+		db.log.Error("failed to save team column preset", "error", err, "team_id", *preset.TeamID, "source_id", preset.SourceID)
+		return fmt.Errorf("error saving team column preset: %w", err)
+	}
+	saved, err := db.GetTeamColumnPreset(ctx, *preset.TeamID, preset.SourceID)
+	if err != nil {
+		return err
+	}
+	*preset = *saved
+	return nil
+}
+
+// DeleteTeamColumnPreset removes a team's default column preset for a source.
+// Returns models.ErrNotFound when there is none.
+func (db *DB) DeleteTeamColumnPreset(ctx context.Context, teamID models.TeamID, sourceID models.SourceID) error {
+	_, err := db.writeQueries.DeleteTeamColumnPreset(ctx, sqlc.DeleteTeamColumnPresetParams{
+		TeamID:   int64(teamID),
+		SourceID: int64(sourceID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to delete team column preset", "error", err, "team_id", teamID, "source_id", sourceID)
+		return fmt.Errorf("error deleting team column preset: %w", err)
+	}
+	return nil
+}
+
+func decodeColumnPresetColumns(raw string) ([]models.ColumnPresetColumn, error) {
+	columns, err := alertjson.Decode[[]models.ColumnPresetColumn](raw)
+	if err != nil {
+		return nil, fmt.Errorf("decoding column preset: %w", err)
+	}
+	if columns == nil {
+		columns = []models.ColumnPresetColumn{}
+	}
+	return columns, nil
+}
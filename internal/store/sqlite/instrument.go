@@ -0,0 +1,188 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/metrics"
+
+	msqlite "modernc.org/sqlite"
+)
+
+// instrumentedDriverName is the database/sql driver the read and write pools
+// use. It wraps modernc's driver to time every statement, since the sqlc
+// queries run through prepared *sql.Stmt handles that offer no hook of their
+// own.
+const instrumentedDriverName = "sqlite-instrumented"
+
+func init() {
+	sql.Register(instrumentedDriverName, instrumentedDriver{&msqlite.Driver{}})
+}
+
+type instrumentedDriver struct {
+	drv *msqlite.Driver
+}
+
+func (d instrumentedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.drv.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{c}, nil
+}
+
+// sqliteConn is the subset of modernc's connection the wrapper forwards to.
+type sqliteConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+	NewBackup(string) (*msqlite.Backup, error)
+}
+
+// instrumentedConn forwards to the modernc connection, timing statements. It
+// keeps NewBackup reachable through sql.Conn.Raw for DB.Backup.
+type instrumentedConn struct {
+	driver.Conn
+}
+
+func (c *instrumentedConn) inner() (sqliteConn, error) {
+	sc, ok := c.Conn.(sqliteConn)
+	if !ok {
+		return nil, errors.New("sqlite driver connection lacks expected methods")
+	}
+	return sc, nil
+}
+
+func (c *instrumentedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	sc, err := c.inner()
+	if err != nil {
+		return nil, err
+	}
+	st, err := sc.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedStmt{Stmt: st, name: queryName(query)}, nil
+}
+
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	sc, err := c.inner()
+	if err != nil {
+		return nil, err
+	}
+	return sc.BeginTx(ctx, opts)
+}
+
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	sc, err := c.inner()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	res, err := sc.ExecContext(ctx, query, args)
+	metrics.RecordSQLiteQuery(queryName(query), err == nil, time.Since(start))
+	return res, err
+}
+
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	sc, err := c.inner()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	rows, err := sc.QueryContext(ctx, query, args)
+	metrics.RecordSQLiteQuery(queryName(query), err == nil, time.Since(start))
+	return rows, err
+}
+
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	sc, err := c.inner()
+	if err != nil {
+		return err
+	}
+	return sc.Ping(ctx)
+}
+
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	sc, err := c.inner()
+	if err != nil {
+		return err
+	}
+	return sc.ResetSession(ctx)
+}
+
+func (c *instrumentedConn) IsValid() bool {
+	sc, err := c.inner()
+	return err == nil && sc.IsValid()
+}
+
+func (c *instrumentedConn) NewBackup(dest string) (*msqlite.Backup, error) {
+	sc, err := c.inner()
+	if err != nil {
+		return nil, err
+	}
+	return sc.NewBackup(dest)
+}
+
+type sqliteStmt interface {
+	driver.Stmt
+	driver.StmtExecContext
+	driver.StmtQueryContext
+}
+
+// instrumentedStmt times a prepared statement. Query time covers running the
+// statement to its first row, not reading the result set.
+type instrumentedStmt struct {
+	driver.Stmt
+	name string
+}
+
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	st, ok := s.Stmt.(sqliteStmt)
+	if !ok {
+		return nil, errors.New("sqlite driver statement lacks ExecContext")
+	}
+	start := time.Now()
+	res, err := st.ExecContext(ctx, args)
+	metrics.RecordSQLiteQuery(s.name, err == nil, time.Since(start))
+	return res, err
+}
+
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	st, ok := s.Stmt.(sqliteStmt)
+	if !ok {
+		return nil, errors.New("sqlite driver statement lacks QueryContext")
+	}
+	start := time.Now()
+	rows, err := st.QueryContext(ctx, args)
+	metrics.RecordSQLiteQuery(s.name, err == nil, time.Since(start))
+	return rows, err
+}
+
+// queryName returns the sqlc query name from the "-- name: GetUser :one"
+// header sqlc puts at the top of every generated statement, or "other" for
+// hand-written SQL, which keeps the metric's label set bounded.
+func queryName(query string) string {
+	rest, ok := strings.CutPrefix(query, "-- name: ")
+	if !ok {
+		return "other"
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	if name == "" {
+		return "other"
+	}
+	return name
+}
@@ -0,0 +1,40 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VictoriaMetrics/metrics"
+)
+
+func TestQueryName(t *testing.T) {
+	tests := map[string]string{
+		"-- name: GetUser :one\nSELECT 1":  "GetUser",
+		"-- name: ListTeams :many\nSELECT": "ListTeams",
+		"PRAGMA foreign_keys":              "other",
+		"-- name: ":                        "other",
+	}
+	for query, want := range tests {
+		if got := queryName(query); got != want {
+			t.Errorf("queryName(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+// TestPreparedQueriesAreTimed checks sqlc's prepared statements pass through
+// the instrumented driver.
+func TestPreparedQueriesAreTimed(t *testing.T) {
+	db := newTxTestDB(t)
+	h := metrics.GetOrCreateHistogram(`logchef_sqlite_query_duration_seconds{query="CountAdminUsers"}`)
+
+	var before uint64
+	h.VisitNonZeroBuckets(func(_ string, count uint64) { before += count })
+	if _, err := db.CountAdminUsers(context.Background()); err != nil {
+		t.Fatalf("CountAdminUsers: %v", err)
+	}
+	var after uint64
+	h.VisitNonZeroBuckets(func(_ string, count uint64) { after += count })
+	if after != before+1 {
+		t.Errorf("histogram count = %d, want %d", after, before+1)
+	}
+}
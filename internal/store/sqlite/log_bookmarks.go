@@ -0,0 +1,125 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// CreateLogBookmark pins a log row and repopulates the model with the
+// persisted row (id and timestamps).
+func (db *DB) CreateLogBookmark(ctx context.Context, bookmark *models.LogBookmark) error {
+	if bookmark == nil {
+		return fmt.Errorf("log bookmark payload is required")
+	}
+	id, err := db.writeQueries.CreateLogBookmark(ctx, sqlc.CreateLogBookmarkParams{
+		TeamID:       int64(bookmark.TeamID),
+		SourceID:     int64(bookmark.SourceID),
+		UserID:       int64(bookmark.UserID),
+		Shared:       boolToInt(bookmark.Shared),
+		LogTimestamp: bookmark.LogTimestamp.UTC(),
+		Fingerprint:  bookmark.Fingerprint,
+		PayloadJson:  string(bookmark.Payload),
+		Note:         bookmark.Note,
+	})
+	if err != nil {
+		if isUniqueConstraintSQLiteError(err, "log_bookmarks", "fingerprint") {
+			return fmt.Errorf("%w: log row already bookmarked", models.ErrConflict)
+		}
+		db.log.Error("failed to create log bookmark", "error", err, "team_id", bookmark.TeamID, "source_id", bookmark.SourceID)
+		return fmt.Errorf("error creating log bookmark: %w", err)
+	}
+
+	created, err := db.GetLogBookmark(ctx, models.LogBookmarkID(id))
+	if err != nil {
+		return err
+	}
+	*bookmark = *created
+	return nil
+}
+
+// GetLogBookmark returns a bookmark by id, or models.ErrNotFound if missing.
+func (db *DB) GetLogBookmark(ctx context.Context, id models.LogBookmarkID) (*models.LogBookmark, error) {
+	row, err := db.readQueries.GetLogBookmark(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting log bookmark id %d: %w", id, err)
+	}
+	return mapLogBookmarkRow(row), nil
+}
+
+// ListLogBookmarksForUser returns userID's bookmarks in teamID plus the team's
+// shared ones, most recently pinned first.
+func (db *DB) ListLogBookmarksForUser(ctx context.Context, teamID models.TeamID, userID models.UserID) ([]*models.LogBookmark, error) {
+	rows, err := db.readQueries.ListLogBookmarksForUser(ctx, sqlc.ListLogBookmarksForUserParams{
+		TeamID: int64(teamID),
+		UserID: int64(userID),
+	})
+	if err != nil {
+		db.log.Error("failed to list log bookmarks", "error", err, "team_id", teamID, "user_id", userID)
+		return nil, fmt.Errorf("error listing log bookmarks: %w", err)
+	}
+	bookmarks := make([]*models.LogBookmark, 0, len(rows))
+	for _, row := range rows {
+		bookmarks = append(bookmarks, mapLogBookmarkRow(row))
+	}
+	return bookmarks, nil
+}
+
+// UpdateLogBookmark overwrites a bookmark's note and visibility. Returns
+// models.ErrNotFound when the id does not exist.
+func (db *DB) UpdateLogBookmark(ctx context.Context, bookmark *models.LogBookmark) error {
+	if bookmark == nil {
+		return fmt.Errorf("log bookmark payload is required")
+	}
+	_, err := db.writeQueries.UpdateLogBookmark(ctx, sqlc.UpdateLogBookmarkParams{
+		Note:   bookmark.Note,
+		Shared: boolToInt(bookmark.Shared),
+		ID:     int64(bookmark.ID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to update log bookmark", "error", err, "bookmark_id", bookmark.ID)
+		return fmt.Errorf("error updating log bookmark: %w", err)
+	}
+	return nil
+}
+
+// DeleteLogBookmark removes a bookmark. Returns models.ErrNotFound when the id
+// does not exist.
+func (db *DB) DeleteLogBookmark(ctx context.Context, id models.LogBookmarkID) error {
+	if _, err := db.writeQueries.DeleteLogBookmark(ctx, int64(id)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to delete log bookmark", "error", err, "bookmark_id", id)
+		return fmt.Errorf("error deleting log bookmark: %w", err)
+	}
+	return nil
+}
+
+func mapLogBookmarkRow(row sqlc.LogBookmark) *models.LogBookmark {
+	return &models.LogBookmark{
+		ID:           models.LogBookmarkID(row.ID),
+		TeamID:       models.TeamID(row.TeamID),
+		SourceID:     models.SourceID(row.SourceID),
+		UserID:       models.UserID(row.UserID),
+		Shared:       row.Shared == 1,
+		LogTimestamp: row.LogTimestamp,
+		Fingerprint:  row.Fingerprint,
+		Payload:      []byte(row.PayloadJson),
+		Note:         row.Note,
+		Timestamps: models.Timestamps{
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		},
+	}
+}
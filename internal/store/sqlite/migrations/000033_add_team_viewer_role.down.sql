@@ -0,0 +1,22 @@
+-- Remove the 'viewer' role from the team_members role constraint. Viewers are
+-- demoted to 'member' (the closest pre-existing role) so no memberships are lost.
+CREATE TABLE team_members_new (
+    team_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('admin', 'member', 'editor')),
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (team_id, user_id),
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO team_members_new (team_id, user_id, role, created_at)
+SELECT team_id, user_id, CASE WHEN role = 'viewer' THEN 'member' ELSE role END, created_at
+FROM team_members;
+
+DROP TABLE team_members;
+
+ALTER TABLE team_members_new RENAME TO team_members;
+
+CREATE INDEX IF NOT EXISTS idx_team_members_user_id ON team_members(user_id);
+CREATE INDEX IF NOT EXISTS idx_team_members_team_id ON team_members(team_id);
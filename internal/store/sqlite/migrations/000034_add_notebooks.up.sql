@@ -0,0 +1,18 @@
+-- Notebooks: team-scoped analysis documents made of ordered cells (markdown,
+-- query, histogram). Cells, including each query cell's last cached result,
+-- live in a single JSON blob (cells_json) validated in the application layer
+-- (models.ValidateNotebookCells). Notebooks are removed with their team;
+-- created_by is nulled when the author is deleted, mirroring dashboards.
+CREATE TABLE notebooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+    cells_json TEXT NOT NULL,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notebooks_team_id ON notebooks(team_id);
+CREATE INDEX IF NOT EXISTS idx_notebooks_updated_at ON notebooks(updated_at);
@@ -0,0 +1,22 @@
+-- Audit events: an append-only trail of sensitive operations (source
+-- create/delete, team membership changes, alert changes, raw SQL execution).
+-- user_email is snapshotted at write time and user_id is nulled when the user
+-- is deleted, so the trail outlives its actors. team_id and resource_id carry
+-- no FK for the same reason: the audited resource is often the one deleted.
+-- details holds an action-specific JSON object.
+CREATE TABLE audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    user_email TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL,
+    resource_type TEXT NOT NULL,
+    resource_id TEXT NOT NULL DEFAULT '',
+    team_id INTEGER,
+    ip_address TEXT NOT NULL DEFAULT '',
+    details TEXT,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_user_created ON audit_events(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action_created ON audit_events(action, created_at);
@@ -0,0 +1,15 @@
+-- Source rollups: opt-in per-source hourly rollups of log counts by severity
+-- and one dimension column, materialised into a ClickHouse table owned by
+-- logchef. A row here enables the rollup; rolled_up_from/rolled_up_until track
+-- the materialised hour range so trend queries know what the rollup covers.
+-- Removed with the source.
+CREATE TABLE source_rollups (
+    source_id INTEGER PRIMARY KEY REFERENCES sources(id) ON DELETE CASCADE,
+    dimension TEXT NOT NULL DEFAULT '',
+    rolled_up_from DATETIME,
+    rolled_up_until DATETIME,
+    last_run_at DATETIME,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
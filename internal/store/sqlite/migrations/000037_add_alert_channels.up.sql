@@ -0,0 +1 @@
+ALTER TABLE alerts ADD COLUMN channels_json TEXT;
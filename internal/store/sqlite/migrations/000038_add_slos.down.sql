@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_alerts_slo_id;
+ALTER TABLE alerts DROP COLUMN slo_id;
+DROP TABLE IF EXISTS slo_evaluations;
+DROP TABLE IF EXISTS slos;
@@ -0,0 +1,46 @@
+-- SLOs: team-scoped service-level objectives over one source. The SLI is the
+-- ratio of two log counts (good_query / total_query) over a rolling window of
+-- window_seconds; target is the objective as a fraction (0.999 = 99.9%).
+-- query_language is logchefql (filters counted by logchef) or the source's
+-- native language (queries returning the count). SLOs are removed with their
+-- team or source; created_by is nulled when the author is deleted.
+CREATE TABLE slos (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    source_id INTEGER NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    query_language TEXT NOT NULL,
+    good_query TEXT NOT NULL,
+    total_query TEXT NOT NULL,
+    target REAL NOT NULL,
+    window_seconds INTEGER NOT NULL,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_slos_team_id ON slos(team_id);
+
+-- One row per periodic evaluation. The derived figures are NULL when the
+-- window held no matching logs or the evaluation failed (error is set).
+-- History is pruned to the newest rows per SLO by the evaluator.
+CREATE TABLE slo_evaluations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    slo_id INTEGER NOT NULL REFERENCES slos(id) ON DELETE CASCADE,
+    good REAL NOT NULL DEFAULT 0,
+    total REAL NOT NULL DEFAULT 0,
+    compliance REAL,
+    burn_rate REAL,
+    error_budget_remaining REAL,
+    error TEXT NOT NULL DEFAULT '',
+    evaluated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_slo_evaluations_slo_id ON slo_evaluations(slo_id, evaluated_at);
+
+-- Burn-rate alerts evaluate an SLO's burn rate instead of a query, and go
+-- away with the SLO.
+ALTER TABLE alerts ADD COLUMN slo_id INTEGER REFERENCES slos(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_alerts_slo_id ON alerts(slo_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_alert_silences_ends_at;
+DROP TABLE IF EXISTS alert_silences;
@@ -0,0 +1,21 @@
+-- Alert silences suppress notifications for one alert, every alert on a
+-- source, or every alert on a team's sources. Exactly one of alert_id,
+-- source_id and team_id is set. A silence applies between starts_at and
+-- ends_at (NULL = open-ended, recurring silences only); recurrence_json, when
+-- set, narrows that to a weekly maintenance window. Silences are removed with
+-- whatever they target; created_by records who silenced it.
+CREATE TABLE alert_silences (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    alert_id INTEGER REFERENCES alerts(id) ON DELETE CASCADE,
+    source_id INTEGER REFERENCES sources(id) ON DELETE CASCADE,
+    team_id INTEGER REFERENCES teams(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL DEFAULT '',
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME,
+    recurrence_json TEXT NOT NULL DEFAULT '',
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_silences_ends_at ON alert_silences(ends_at);
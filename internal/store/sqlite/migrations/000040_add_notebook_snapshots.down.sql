@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_notebook_snapshots_expires_at;
+DROP INDEX IF EXISTS idx_notebook_snapshots_notebook;
+DROP TABLE IF EXISTS notebook_snapshots;
@@ -0,0 +1,20 @@
+-- Notebook snapshots record rendered notebook reports kept in artifact
+-- storage (local disk or S3) under object_key. There is deliberately no
+-- foreign key to notebooks or teams: a cascade would drop the row and orphan
+-- the stored object. Rows expire instead, and the cleanup loop deletes the
+-- object before the row.
+CREATE TABLE notebook_snapshots (
+    id TEXT PRIMARY KEY,
+    notebook_id INTEGER NOT NULL,
+    team_id INTEGER NOT NULL,
+    format TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    object_key TEXT NOT NULL,
+    size_bytes INTEGER NOT NULL DEFAULT 0,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notebook_snapshots_notebook ON notebook_snapshots(notebook_id);
+CREATE INDEX IF NOT EXISTS idx_notebook_snapshots_expires_at ON notebook_snapshots(expires_at);
@@ -0,0 +1,4 @@
+-- Free-form key/value tags on sources (env=prod, region=eu), stored as a JSON
+-- object. Used to filter the sources list and added to alert notification
+-- labels.
+ALTER TABLE sources ADD COLUMN tags TEXT NOT NULL DEFAULT '{}';
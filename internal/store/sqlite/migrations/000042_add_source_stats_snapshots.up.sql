@@ -0,0 +1,16 @@
+-- Daily storage statistics per source, written by the source stats scheduler.
+-- One row per source and UTC day (YYYY-MM-DD); a later snapshot on the same day
+-- replaces the earlier one. columns holds per-column storage as a JSON array.
+CREATE TABLE source_stats_snapshots (
+    source_id INTEGER NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    snapshot_date TEXT NOT NULL,
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    part_count INTEGER NOT NULL DEFAULT 0,
+    compressed_bytes INTEGER NOT NULL DEFAULT 0,
+    uncompressed_bytes INTEGER NOT NULL DEFAULT 0,
+    columns TEXT NOT NULL DEFAULT '[]',
+    captured_at DATETIME NOT NULL,
+    PRIMARY KEY (source_id, snapshot_date)
+);
+
+CREATE INDEX idx_source_stats_snapshots_date ON source_stats_snapshots(snapshot_date);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_query_history_team_created;
+ALTER TABLE query_history DROP COLUMN error_message;
+ALTER TABLE query_history DROP COLUMN status;
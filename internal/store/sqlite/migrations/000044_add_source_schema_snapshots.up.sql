@@ -0,0 +1,13 @@
+-- Schema history per source, written by the schema drift scheduler. A row is
+-- only added when a source's columns differ from its latest snapshot (or it has
+-- none yet). columns holds the full column list and changes the diff against
+-- the previous snapshot, both as JSON arrays.
+CREATE TABLE source_schema_snapshots (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    source_id INTEGER NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    columns TEXT NOT NULL DEFAULT '[]',
+    changes TEXT NOT NULL DEFAULT '[]',
+    captured_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_source_schema_snapshots_source ON source_schema_snapshots(source_id, id DESC);
@@ -0,0 +1,3 @@
+-- Alerts can be declared in provisioning config like sources and teams.
+-- managed: 0 = UI-managed (default), 1 = config-managed.
+ALTER TABLE alerts ADD COLUMN managed INTEGER NOT NULL DEFAULT 0 CHECK (managed IN (0, 1));
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tracked_queries_instance;
+DROP TABLE IF EXISTS tracked_queries;
@@ -0,0 +1,17 @@
+-- Long-running queries persisted by the in-process query tracker, so a
+-- restarted instance can find and kill the ClickHouse queries its previous
+-- process left running. id is the tracker's query ID (also the ClickHouse
+-- query_id); instance is the host that ran the query. Rows are ephemeral and
+-- reference users, teams and sources without foreign keys.
+CREATE TABLE tracked_queries (
+    id TEXT PRIMARY KEY,
+    instance TEXT NOT NULL,
+    class TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    team_id INTEGER NOT NULL DEFAULT 0,
+    source_id INTEGER NOT NULL,
+    query_text TEXT NOT NULL DEFAULT '',
+    started_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_tracked_queries_instance ON tracked_queries(instance);
@@ -0,0 +1,4 @@
+-- Per-source field extraction rules (regex or JSON path over a column such as
+-- body), stored as a JSON array. Each rule is exposed as a virtual column in
+-- the source schema and translated into a ClickHouse expression at query time.
+ALTER TABLE sources ADD COLUMN extraction_rules TEXT NOT NULL DEFAULT '[]';
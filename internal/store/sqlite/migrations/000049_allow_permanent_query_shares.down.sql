@@ -0,0 +1,26 @@
+-- Permanent share links cannot be represented any more; drop them.
+DELETE FROM query_shares WHERE expires_at IS NULL;
+
+CREATE TABLE query_shares_old (
+    token TEXT PRIMARY KEY,
+    source_id INTEGER NOT NULL,
+    created_by INTEGER NOT NULL,
+    payload_json TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    last_accessed_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    team_id INTEGER REFERENCES teams(id) ON DELETE SET NULL,
+    FOREIGN KEY (source_id) REFERENCES sources(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO query_shares_old (token, source_id, created_by, payload_json, expires_at, last_accessed_at, created_at, team_id)
+SELECT token, source_id, created_by, payload_json, expires_at, last_accessed_at, created_at, team_id
+FROM query_shares;
+
+DROP TABLE query_shares;
+ALTER TABLE query_shares_old RENAME TO query_shares;
+
+CREATE INDEX IF NOT EXISTS idx_query_shares_source ON query_shares(source_id);
+CREATE INDEX IF NOT EXISTS idx_query_shares_created_by ON query_shares(created_by);
+CREATE INDEX IF NOT EXISTS idx_query_shares_expires_at ON query_shares(expires_at);
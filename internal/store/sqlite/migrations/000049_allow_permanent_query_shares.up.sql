@@ -0,0 +1,26 @@
+-- Make query_shares.expires_at nullable so share links can be permanent.
+-- SQLite cannot drop a NOT NULL constraint in place, so rebuild the table.
+
+CREATE TABLE query_shares_new (
+    token TEXT PRIMARY KEY,
+    source_id INTEGER NOT NULL,
+    created_by INTEGER NOT NULL,
+    payload_json TEXT NOT NULL,
+    expires_at DATETIME,
+    last_accessed_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    team_id INTEGER REFERENCES teams(id) ON DELETE SET NULL,
+    FOREIGN KEY (source_id) REFERENCES sources(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO query_shares_new (token, source_id, created_by, payload_json, expires_at, last_accessed_at, created_at, team_id)
+SELECT token, source_id, created_by, payload_json, expires_at, last_accessed_at, created_at, team_id
+FROM query_shares;
+
+DROP TABLE query_shares;
+ALTER TABLE query_shares_new RENAME TO query_shares;
+
+CREATE INDEX IF NOT EXISTS idx_query_shares_source ON query_shares(source_id);
+CREATE INDEX IF NOT EXISTS idx_query_shares_created_by ON query_shares(created_by);
+CREATE INDEX IF NOT EXISTS idx_query_shares_expires_at ON query_shares(expires_at);
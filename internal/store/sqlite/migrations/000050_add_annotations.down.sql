@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_annotations_team_starts_at;
+DROP TABLE IF EXISTS annotations;
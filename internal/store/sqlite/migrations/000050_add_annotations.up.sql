@@ -0,0 +1,19 @@
+-- Timeline annotations mark a moment (ends_at NULL) or a range on a team's
+-- histograms: deploys, incident start/end and the like. source_id narrows an
+-- annotation to one source; NULL shows it on every source of the team.
+-- tags_json holds a JSON array of free-form labels. Annotations are removed
+-- with their team or source; created_by records who added it.
+CREATE TABLE annotations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    source_id INTEGER REFERENCES sources(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    tags_json TEXT NOT NULL DEFAULT '',
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_annotations_team_starts_at ON annotations(team_id, starts_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_log_bookmarks_team;
+DROP INDEX IF EXISTS idx_log_bookmarks_user_row;
+DROP TABLE IF EXISTS log_bookmarks;
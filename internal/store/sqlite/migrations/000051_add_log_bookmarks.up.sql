@@ -0,0 +1,21 @@
+-- Log bookmarks pin individual log rows from a team's source. payload_json
+-- holds a snapshot of the row (so it outlives the source's retention) and
+-- fingerprint a SHA-256 of that snapshot; a user can pin a given row once per
+-- source. shared = 0 keeps a bookmark personal to user_id, 1 shows it to the
+-- whole team. Bookmarks go with their team, source or user.
+CREATE TABLE log_bookmarks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    source_id INTEGER NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    shared INTEGER NOT NULL DEFAULT 0 CHECK (shared IN (0, 1)),
+    log_timestamp DATETIME NOT NULL,
+    fingerprint TEXT NOT NULL,
+    payload_json TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_log_bookmarks_user_row ON log_bookmarks(user_id, source_id, fingerprint);
+CREATE INDEX IF NOT EXISTS idx_log_bookmarks_team ON log_bookmarks(team_id, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS team_column_presets;
+DROP TABLE IF EXISTS user_column_presets;
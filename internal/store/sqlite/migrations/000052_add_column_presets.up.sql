@@ -0,0 +1,23 @@
+-- Column presets save the log table layout for a source: the visible columns
+-- in order, with width and formatting, as a JSON array in columns_json. A
+-- user has at most one preset per source; a team has at most one default per
+-- source, which applies to members without their own. Presets go with their
+-- user, team or source; updated_by records who last saved a team default.
+CREATE TABLE user_column_presets (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source_id INTEGER NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    columns_json TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (user_id, source_id)
+);
+
+CREATE TABLE team_column_presets (
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    source_id INTEGER NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    columns_json TEXT NOT NULL,
+    updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (team_id, source_id)
+);
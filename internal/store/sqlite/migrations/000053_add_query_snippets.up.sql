@@ -0,0 +1,16 @@
+-- Query snippets are a team's library of reusable LogchefQL filters, offered
+-- for insertion by the query editor's autocomplete. Names are unique within a
+-- team. Snippets go with their team; created_by is cleared when the author is
+-- deleted.
+CREATE TABLE query_snippets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    query TEXT NOT NULL,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_query_snippets_team_name ON query_snippets(team_id, name);
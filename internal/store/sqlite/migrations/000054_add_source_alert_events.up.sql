@@ -0,0 +1,15 @@
+-- Source degradation alerts raised by the source alert manager: a source
+-- becoming unreachable or its query error rate spiking, and each recovery.
+-- Kept apart from alert_history, which belongs to user-defined alerts.
+CREATE TABLE source_alert_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    source_id INTEGER NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('unreachable', 'error_rate')),
+    status TEXT NOT NULL CHECK (status IN ('triggered', 'resolved')),
+    value REAL NOT NULL DEFAULT 0,
+    message TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_source_alert_events_source ON source_alert_events(source_id, id DESC);
+CREATE INDEX idx_source_alert_events_created_at ON source_alert_events(created_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_alert_leases_holder;
+DROP TABLE IF EXISTS alert_leases;
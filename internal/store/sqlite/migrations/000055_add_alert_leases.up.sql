@@ -0,0 +1,10 @@
+-- Per-alert evaluation leases. Replicas sharing one database each run the
+-- alert manager; before evaluating an alert an instance claims its lease, and
+-- only the holder evaluates it until the lease expires.
+CREATE TABLE alert_leases (
+    alert_id INTEGER PRIMARY KEY REFERENCES alerts(id) ON DELETE CASCADE,
+    holder TEXT NOT NULL,
+    expires_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_alert_leases_holder ON alert_leases(holder);
@@ -0,0 +1 @@
+ALTER TABLE teams DROP COLUMN raw_sql_enabled;
@@ -0,0 +1,3 @@
+-- Per-team switch for raw SQL. When off, the team's members can only query
+-- its ClickHouse sources through LogchefQL; 1 = allowed (default).
+ALTER TABLE teams ADD COLUMN raw_sql_enabled INTEGER NOT NULL DEFAULT 1 CHECK (raw_sql_enabled IN (0, 1));
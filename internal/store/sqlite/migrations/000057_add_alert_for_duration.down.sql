@@ -0,0 +1,3 @@
+ALTER TABLE alerts DROP COLUMN breach_count;
+ALTER TABLE alerts DROP COLUMN pending_since;
+ALTER TABLE alerts DROP COLUMN for_seconds;
@@ -0,0 +1,8 @@
+-- Pending state for alerts with a for-duration. for_seconds is how long the
+-- condition must hold before the alert fires (0 = fire on the first breach).
+-- While it is pending, last_state stays 'resolved' and pending_since holds
+-- the first breach of the current run; breach_count counts the consecutive
+-- breaching evaluations.
+ALTER TABLE alerts ADD COLUMN for_seconds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE alerts ADD COLUMN pending_since DATETIME;
+ALTER TABLE alerts ADD COLUMN breach_count INTEGER NOT NULL DEFAULT 0;
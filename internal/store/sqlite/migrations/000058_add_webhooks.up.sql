@@ -0,0 +1,15 @@
+-- Outbound webhooks receive lifecycle events (sources created, alerts firing,
+-- members added). event_types_json is a JSON array of the event types the
+-- webhook subscribes to, "*" for all of them. secret is the HMAC signing key,
+-- sealed like source credentials when an encryption key is configured.
+CREATE TABLE webhooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL,
+    event_types_json TEXT NOT NULL DEFAULT '[]',
+    secret TEXT NOT NULL,
+    is_active INTEGER NOT NULL DEFAULT 1 CHECK (is_active IN (0, 1)),
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
@@ -0,0 +1,4 @@
+-- Rows read per day in the query stats rollup, so per-team quotas can cap
+-- how much data a team scans as well as how many queries it runs. Rows
+-- recorded before this migration count as 0.
+ALTER TABLE query_stats_daily ADD COLUMN rows_read INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE team_sources DROP COLUMN row_filter;
@@ -0,0 +1,5 @@
+-- Row-level access policy on a team-source link. row_filter is a ClickHouse
+-- boolean expression ANDed into every query, histogram and field-values
+-- request the team runs on the source, so several teams can share one table
+-- and each only sees its own rows. '' means no filter.
+ALTER TABLE team_sources ADD COLUMN row_filter TEXT NOT NULL DEFAULT '';
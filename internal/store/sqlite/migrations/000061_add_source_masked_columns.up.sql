@@ -0,0 +1,3 @@
+-- Per-source column masking: the columns hashed or redacted for users without
+-- the unmasked permission, stored as a JSON array ('[]' when none).
+ALTER TABLE sources ADD COLUMN masked_columns TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// CreateNotebookSnapshot records a stored notebook report.
+func (db *DB) CreateNotebookSnapshot(ctx context.Context, snapshot *models.NotebookSnapshot) error {
+	params := sqlc.CreateNotebookSnapshotParams{
+		ID:         snapshot.ID,
+		NotebookID: int64(snapshot.NotebookID),
+		TeamID:     int64(snapshot.TeamID),
+		Format:     snapshot.Format,
+		FileName:   snapshot.FileName,
+		ObjectKey:  snapshot.ObjectKey,
+		SizeBytes:  snapshot.SizeBytes,
+		ExpiresAt:  snapshot.ExpiresAt.UTC(),
+		CreatedAt:  snapshot.CreatedAt.UTC(),
+	}
+	if snapshot.CreatedBy != nil {
+		params.CreatedBy = sql.NullInt64{Int64: int64(*snapshot.CreatedBy), Valid: true}
+	}
+	if err := db.writeQueries.CreateNotebookSnapshot(ctx, params); err != nil {
+		db.log.Error("failed to create notebook snapshot", "error", err, "notebook_id", snapshot.NotebookID)
+		return fmt.Errorf("error creating notebook snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetNotebookSnapshot returns a snapshot by id, or models.ErrNotFound if missing.
+func (db *DB) GetNotebookSnapshot(ctx context.Context, id string) (*models.NotebookSnapshot, error) {
+	row, err := db.readQueries.GetNotebookSnapshot(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		db.log.Error("failed to get notebook snapshot", "error", err, "snapshot_id", id)
+		return nil, fmt.Errorf("error getting notebook snapshot: %w", err)
+	}
+	return mapNotebookSnapshotRow(row), nil
+}
+
+// ListNotebookSnapshots returns a notebook's snapshots, newest first.
+func (db *DB) ListNotebookSnapshots(ctx context.Context, notebookID int) ([]*models.NotebookSnapshot, error) {
+	rows, err := db.readQueries.ListNotebookSnapshots(ctx, int64(notebookID))
+	if err != nil {
+		db.log.Error("failed to list notebook snapshots", "error", err, "notebook_id", notebookID)
+		return nil, fmt.Errorf("error listing notebook snapshots: %w", err)
+	}
+	return mapNotebookSnapshotRows(rows), nil
+}
+
+// ListExpiredNotebookSnapshots returns snapshots whose expires_at is before
+// the given time.
+func (db *DB) ListExpiredNotebookSnapshots(ctx context.Context, before time.Time) ([]*models.NotebookSnapshot, error) {
+	rows, err := db.readQueries.ListExpiredNotebookSnapshots(ctx, before.UTC())
+	if err != nil {
+		db.log.Error("failed to list expired notebook snapshots", "error", err)
+		return nil, fmt.Errorf("error listing expired notebook snapshots: %w", err)
+	}
+	return mapNotebookSnapshotRows(rows), nil
+}
+
+// DeleteNotebookSnapshot removes a snapshot row, or returns models.ErrNotFound.
+func (db *DB) DeleteNotebookSnapshot(ctx context.Context, id string) error {
+	if _, err := db.writeQueries.DeleteNotebookSnapshot(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to delete notebook snapshot", "error", err, "snapshot_id", id)
+		return fmt.Errorf("error deleting notebook snapshot: %w", err)
+	}
+	return nil
+}
+
+func mapNotebookSnapshotRows(rows []sqlc.NotebookSnapshot) []*models.NotebookSnapshot {
+	snapshots := make([]*models.NotebookSnapshot, 0, len(rows))
+	for _, row := range rows {
+		snapshots = append(snapshots, mapNotebookSnapshotRow(row))
+	}
+	return snapshots
+}
+
+func mapNotebookSnapshotRow(row sqlc.NotebookSnapshot) *models.NotebookSnapshot {
+	snapshot := &models.NotebookSnapshot{
+		ID:         row.ID,
+		NotebookID: int(row.NotebookID),
+		TeamID:     models.TeamID(row.TeamID),
+		Format:     row.Format,
+		FileName:   row.FileName,
+		ObjectKey:  row.ObjectKey,
+		SizeBytes:  row.SizeBytes,
+		ExpiresAt:  row.ExpiresAt,
+		CreatedAt:  row.CreatedAt,
+	}
+	if row.CreatedBy.Valid {
+		createdBy := models.UserID(row.CreatedBy.Int64)
+		snapshot.CreatedBy = &createdBy
+	}
+	return snapshot
+}
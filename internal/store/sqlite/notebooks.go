@@ -0,0 +1,159 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// CreateNotebook inserts a new notebook and repopulates the model with the
+// persisted row (id and timestamps).
+func (db *DB) CreateNotebook(ctx context.Context, notebook *models.Notebook) error {
+	if notebook == nil {
+		return fmt.Errorf("notebook payload is required")
+	}
+	params := sqlc.CreateNotebookParams{
+		TeamID:      int64(notebook.TeamID),
+		Name:        notebook.Name,
+		Description: nullString(notebook.Description),
+		CellsJson:   string(notebook.CellsJSON),
+	}
+	if notebook.CreatedBy != nil {
+		params.CreatedBy = sql.NullInt64{Int64: int64(*notebook.CreatedBy), Valid: true}
+	}
+
+	id, err := db.writeQueries.CreateNotebook(ctx, params)
+	if err != nil {
+		db.log.Error("failed to create notebook", "error", err, "team_id", notebook.TeamID)
+		return fmt.Errorf("error creating notebook: %w", err)
+	}
+
+	created, err := db.GetNotebook(ctx, int(id))
+	if err != nil {
+		return err
+	}
+	*notebook = *created
+	return nil
+}
+
+// GetNotebook returns a notebook with its cells by id, or models.ErrNotFound
+// if missing.
+func (db *DB) GetNotebook(ctx context.Context, id int) (*models.Notebook, error) {
+	row, err := db.readQueries.GetNotebook(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting notebook id %d: %w", id, err)
+	}
+	n := &models.Notebook{
+		ID:          int(row.ID),
+		TeamID:      models.TeamID(row.TeamID),
+		Name:        row.Name,
+		Description: row.Description.String,
+		CellsJSON:   json.RawMessage(row.CellsJson),
+		Timestamps: models.Timestamps{
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		},
+		CreatedByEmail: row.CreatedByEmail.String,
+		CreatedByName:  row.CreatedByName.String,
+	}
+	if row.CreatedBy.Valid {
+		uid := models.UserID(row.CreatedBy.Int64)
+		n.CreatedBy = &uid
+	}
+	return n, nil
+}
+
+// ListNotebooksByTeam returns a team's notebooks, newest-updated first, with
+// creator info but without cells.
+func (db *DB) ListNotebooksByTeam(ctx context.Context, teamID models.TeamID) ([]*models.Notebook, error) {
+	rows, err := db.readQueries.ListNotebooksByTeam(ctx, int64(teamID))
+	if err != nil {
+		db.log.Error("failed to list notebooks", "error", err, "team_id", teamID)
+		return nil, fmt.Errorf("error listing notebooks: %w", err)
+	}
+
+	notebooks := make([]*models.Notebook, 0, len(rows))
+	for i := range rows {
+		r := rows[i]
+		n := &models.Notebook{
+			ID:          int(r.ID),
+			TeamID:      models.TeamID(r.TeamID),
+			Name:        r.Name,
+			Description: r.Description.String,
+			Timestamps: models.Timestamps{
+				CreatedAt: r.CreatedAt,
+				UpdatedAt: r.UpdatedAt,
+			},
+			CreatedByEmail: r.CreatedByEmail.String,
+			CreatedByName:  r.CreatedByName.String,
+		}
+		if r.CreatedBy.Valid {
+			uid := models.UserID(r.CreatedBy.Int64)
+			n.CreatedBy = &uid
+		}
+		notebooks = append(notebooks, n)
+	}
+	return notebooks, nil
+}
+
+// UpdateNotebook overwrites a notebook's mutable fields. Returns
+// models.ErrNotFound when the id does not exist.
+func (db *DB) UpdateNotebook(ctx context.Context, notebook *models.Notebook) error {
+	if notebook == nil {
+		return fmt.Errorf("notebook payload is required")
+	}
+	_, err := db.writeQueries.UpdateNotebook(ctx, sqlc.UpdateNotebookParams{
+		Name:        notebook.Name,
+		Description: nullString(notebook.Description),
+		CellsJson:   string(notebook.CellsJSON),
+		ID:          int64(notebook.ID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to update notebook", "error", err, "notebook_id", notebook.ID)
+		return fmt.Errorf("error updating notebook: %w", err)
+	}
+	return nil
+}
+
+// UpdateNotebookCells replaces a notebook's cell blob without touching
+// updated_at, provided it still holds previous. Returns models.ErrNotFound
+// when the id does not exist or its cells no longer match previous.
+func (db *DB) UpdateNotebookCells(ctx context.Context, id int, previous, cells json.RawMessage) error {
+	_, err := db.writeQueries.UpdateNotebookCells(ctx, sqlc.UpdateNotebookCellsParams{
+		CellsJson:         string(cells),
+		ID:                int64(id),
+		PreviousCellsJson: string(previous),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to update notebook cells", "error", err, "notebook_id", id)
+		return fmt.Errorf("error updating notebook cells: %w", err)
+	}
+	return nil
+}
+
+// DeleteNotebook removes a notebook. Returns models.ErrNotFound when the id
+// does not exist.
+func (db *DB) DeleteNotebook(ctx context.Context, id int) error {
+	if _, err := db.writeQueries.DeleteNotebook(ctx, int64(id)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to delete notebook", "error", err, "notebook_id", id)
+		return fmt.Errorf("error deleting notebook: %w", err)
+	}
+	return nil
+}
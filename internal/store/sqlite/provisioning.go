@@ -37,6 +37,15 @@ func (db *DB) IsUserManaged(ctx context.Context, id models.UserID) (bool, error)
 	return managed == 1, nil
 }
 
+// IsAlertManaged returns true if the alert is managed by provisioning config.
+func (db *DB) IsAlertManaged(ctx context.Context, id models.AlertID) (bool, error) {
+	managed, err := db.readQueries.IsAlertManaged(ctx, int64(id))
+	if err != nil {
+		return false, err
+	}
+	return managed == 1, nil
+}
+
 // ListManagedSources returns all sources currently marked managed.
 func (db *DB) ListManagedSources(ctx context.Context) ([]*models.Source, error) {
 	rows, err := db.readQueries.ListManagedSources(ctx)
@@ -46,7 +55,7 @@ func (db *DB) ListManagedSources(ctx context.Context) ([]*models.Source, error)
 	}
 	sources := make([]*models.Source, 0, len(rows))
 	for i := range rows {
-		if s := mapSourceRowToModel(&rows[i]); s != nil {
+		if s := db.mapSourceRowToModel(&rows[i]); s != nil {
 			sources = append(sources, s)
 		}
 	}
@@ -70,12 +79,23 @@ func (db *DB) ListManagedTeams(ctx context.Context) ([]*models.Team, error) {
 				CreatedAt: row.CreatedAt,
 				UpdatedAt: row.UpdatedAt,
 			},
-			Managed: row.Managed == 1,
+			Managed:       row.Managed == 1,
+			RawSQLEnabled: row.RawSqlEnabled == 1,
 		})
 	}
 	return teams, nil
 }
 
+// ListManagedAlerts returns all alerts currently marked managed.
+func (db *DB) ListManagedAlerts(ctx context.Context) ([]*models.Alert, error) {
+	rows, err := db.readQueries.ListManagedAlerts(ctx)
+	if err != nil {
+		db.log.Error("failed to list managed alerts", "error", err)
+		return nil, fmt.Errorf("error listing managed alerts: %w", err)
+	}
+	return alertsFromSQLC(rows)
+}
+
 // GetSourceByNameForProvisioning looks a source up by name (managed or not).
 // Returns models.ErrNotFound when no source has that name.
 func (db *DB) GetSourceByNameForProvisioning(ctx context.Context, name string) (*models.Source, error) {
@@ -87,7 +107,7 @@ func (db *DB) GetSourceByNameForProvisioning(ctx context.Context, name string) (
 		db.log.Error("failed to get source by name for provisioning", "error", err, "name", name)
 		return nil, fmt.Errorf("error getting source by name: %w", err)
 	}
-	return mapSourceRowToModel(&row), nil
+	return db.mapSourceRowToModel(&row), nil
 }
 
 // SetSourceManaged marks a source managed/unmanaged, recording the secret
@@ -130,3 +150,16 @@ func (db *DB) SetUserManaged(ctx context.Context, id models.UserID, managed bool
 	}
 	return nil
 }
+
+// SetAlertManaged marks an alert managed/unmanaged.
+func (db *DB) SetAlertManaged(ctx context.Context, id models.AlertID, managed bool) error {
+	err := db.writeQueries.SetAlertManaged(ctx, sqlc.SetAlertManagedParams{
+		Managed: boolToInt(managed),
+		ID:      int64(id),
+	})
+	if err != nil {
+		db.log.Error("failed to set alert managed flag", "error", err, "alert_id", id)
+		return fmt.Errorf("error setting alert managed: %w", err)
+	}
+	return nil
+}
@@ -1,17 +1,20 @@
 package sqlite
 
 import (
+	"cmp"
 	"context"
+	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
 // RecordQueryHistory inserts one executed-query record, then prunes the user's
-// history down to models.QueryHistoryPerUserCap so it stays bounded. The insert
-// populates the entry's ID.
-func (db *DB) RecordQueryHistory(ctx context.Context, entry *models.QueryHistory) error {
+// history down to the newest keepPerUser entries so it stays bounded (no cap
+// when keepPerUser <= 0). The insert populates the entry's ID.
+func (db *DB) RecordQueryHistory(ctx context.Context, entry *models.QueryHistory, keepPerUser int) error {
 	if entry == nil {
 		return fmt.Errorf("query history entry is required")
 	}
@@ -24,16 +27,21 @@ func (db *DB) RecordQueryHistory(ctx context.Context, entry *models.QueryHistory
 		QueryLanguage: string(entry.QueryLanguage),
 		DurationMs:    entry.DurationMs,
 		RowCount:      entry.RowCount,
+		Status:        string(cmp.Or(entry.Status, models.QueryHistoryStatusSuccess)),
+		ErrorMessage:  entry.ErrorMessage,
 	})
 	if err != nil {
 		db.log.Error("failed to record query history", "error", err, "user_id", entry.UserID)
 		return fmt.Errorf("error recording query history: %w", err)
 	}
 	entry.ID = id
+	if keepPerUser <= 0 {
+		return nil
+	}
 
 	if err := db.writeQueries.PruneQueryHistoryForUser(ctx, sqlc.PruneQueryHistoryForUserParams{
 		UserID: int64(entry.UserID),
-		Offset: models.QueryHistoryPerUserCap,
+		Offset: int64(keepPerUser),
 	}); err != nil {
 		db.log.Error("failed to prune query history", "error", err, "user_id", entry.UserID)
 		return fmt.Errorf("error pruning query history: %w", err)
@@ -41,15 +49,31 @@ func (db *DB) RecordQueryHistory(ctx context.Context, entry *models.QueryHistory
 	return nil
 }
 
-// ListQueryHistory returns a user's recent query history, newest first, capped
-// at limit.
-func (db *DB) ListQueryHistory(ctx context.Context, userID models.UserID, limit int) ([]*models.QueryHistory, error) {
-	rows, err := db.readQueries.ListQueryHistory(ctx, sqlc.ListQueryHistoryParams{
-		UserID: int64(userID),
-		Limit:  int64(limit),
-	})
+// ListQueryHistory returns a page of the history matching filter, newest
+// first, with user emails and source names filled in.
+func (db *DB) ListQueryHistory(ctx context.Context, filter models.QueryHistoryFilter) ([]*models.QueryHistory, error) {
+	params := sqlc.ListQueryHistoryParams{
+		Status: nullString(string(filter.Status)),
+		Search: nullString(filter.Search),
+		Limit:  int64(filter.Limit),
+		Offset: int64(filter.Offset),
+	}
+	if filter.UserID != nil {
+		params.UserID = sql.NullInt64{Int64: int64(*filter.UserID), Valid: true}
+	}
+	if filter.TeamID != nil {
+		params.TeamID = sql.NullInt64{Int64: int64(*filter.TeamID), Valid: true}
+	}
+	if filter.SourceID != nil {
+		params.SourceID = sql.NullInt64{Int64: int64(*filter.SourceID), Valid: true}
+	}
+	if filter.Since != nil {
+		params.Since = sql.NullTime{Time: filter.Since.UTC(), Valid: true}
+	}
+
+	rows, err := db.readQueries.ListQueryHistory(ctx, params)
 	if err != nil {
-		db.log.Error("failed to list query history", "error", err, "user_id", userID)
+		db.log.Error("failed to list query history", "error", err)
 		return nil, fmt.Errorf("error listing query history: %w", err)
 	}
 
@@ -65,12 +89,55 @@ func (db *DB) ListQueryHistory(ctx context.Context, userID models.UserID, limit
 			QueryLanguage: models.QueryLanguage(r.QueryLanguage),
 			DurationMs:    r.DurationMs,
 			RowCount:      r.RowCount,
+			Status:        models.QueryHistoryStatus(r.Status),
+			ErrorMessage:  r.ErrorMessage,
 			CreatedAt:     r.CreatedAt,
+			UserEmail:     r.UserEmail,
+			SourceName:    r.SourceName,
 		})
 	}
 	return history, nil
 }
 
+// CountQueryHistory returns how many history entries match filter, ignoring
+// its paging.
+func (db *DB) CountQueryHistory(ctx context.Context, filter models.QueryHistoryFilter) (int, error) {
+	params := sqlc.CountQueryHistoryParams{
+		Status: nullString(string(filter.Status)),
+		Search: nullString(filter.Search),
+	}
+	if filter.UserID != nil {
+		params.UserID = sql.NullInt64{Int64: int64(*filter.UserID), Valid: true}
+	}
+	if filter.TeamID != nil {
+		params.TeamID = sql.NullInt64{Int64: int64(*filter.TeamID), Valid: true}
+	}
+	if filter.SourceID != nil {
+		params.SourceID = sql.NullInt64{Int64: int64(*filter.SourceID), Valid: true}
+	}
+	if filter.Since != nil {
+		params.Since = sql.NullTime{Time: filter.Since.UTC(), Valid: true}
+	}
+
+	n, err := db.readQueries.CountQueryHistory(ctx, params)
+	if err != nil {
+		db.log.Error("failed to count query history", "error", err)
+		return 0, fmt.Errorf("error counting query history: %w", err)
+	}
+	return int(n), nil
+}
+
+// DeleteQueryHistoryBefore drops history entries recorded before the cutoff
+// and returns how many were removed.
+func (db *DB) DeleteQueryHistoryBefore(ctx context.Context, before time.Time) (int64, error) {
+	n, err := db.writeQueries.DeleteQueryHistoryBefore(ctx, before.UTC())
+	if err != nil {
+		db.log.Error("failed to delete old query history", "error", err)
+		return 0, fmt.Errorf("error deleting old query history: %w", err)
+	}
+	return n, nil
+}
+
 // ListQueryActivity returns the most recent query_history rows across all
 // users, newest first, capped at limit, enriched with user email and source
 // name. Because query_history is capped per user, this is a recent window
@@ -103,9 +170,9 @@ func (db *DB) ListQueryActivity(ctx context.Context, limit int) ([]models.QueryA
 }
 
 // IncrementQueryStats upserts one executed query into the non-pruned
-// query_stats_daily rollup, adding 1 to query_count and durationMs to
-// total_duration_ms for the composite key.
-func (db *DB) IncrementQueryStats(ctx context.Context, bucketDate string, userID models.UserID, teamID models.TeamID, sourceID models.SourceID, language models.QueryLanguage, durationMs int64) error {
+// query_stats_daily rollup, adding 1 to query_count, durationMs to
+// total_duration_ms and rowsRead to rows_read for the composite key.
+func (db *DB) IncrementQueryStats(ctx context.Context, bucketDate string, userID models.UserID, teamID models.TeamID, sourceID models.SourceID, language models.QueryLanguage, durationMs, rowsRead int64) error {
 	if err := db.writeQueries.IncrementQueryStats(ctx, sqlc.IncrementQueryStatsParams{
 		BucketDate:      bucketDate,
 		UserID:          int64(userID),
@@ -113,6 +180,7 @@ func (db *DB) IncrementQueryStats(ctx context.Context, bucketDate string, userID
 		SourceID:        int64(sourceID),
 		QueryLanguage:   string(models.NormalizeQueryLanguage(language)),
 		TotalDurationMs: durationMs,
+		RowsRead:        rowsRead,
 	}); err != nil {
 		db.log.Error("failed to increment query stats", "error", err, "user_id", userID, "source_id", sourceID)
 		return fmt.Errorf("error incrementing query stats: %w", err)
@@ -120,6 +188,19 @@ func (db *DB) IncrementQueryStats(ctx context.Context, bucketDate string, userID
 	return nil
 }
 
+// GetTeamQueryUsage sums a team's queries and rows read on bucketDate.
+func (db *DB) GetTeamQueryUsage(ctx context.Context, teamID models.TeamID, bucketDate string) (*models.TeamQueryUsage, error) {
+	row, err := db.readQueries.GetTeamQueryUsage(ctx, sqlc.GetTeamQueryUsageParams{
+		BucketDate: bucketDate,
+		TeamID:     int64(teamID),
+	})
+	if err != nil {
+		db.log.Error("failed to get team query usage", "error", err, "team_id", teamID)
+		return nil, fmt.Errorf("error getting team query usage: %w", err)
+	}
+	return &models.TeamQueryUsage{Date: bucketDate, Queries: row.QueryCount, RowsRead: row.RowsRead}, nil
+}
+
 // TopSourcesByQueries returns sources ordered by total query count desc (capped
 // at limit) over rollup rows with bucket_date >= since.
 func (db *DB) TopSourcesByQueries(ctx context.Context, since string, limit int) ([]models.SourceQueryStat, error) {
@@ -23,7 +23,7 @@ func (db *DB) CreateQueryShare(ctx context.Context, share *models.QueryShare) er
 		TeamID:      teamID,
 		CreatedBy:   int64(share.CreatedBy),
 		PayloadJson: string(share.Payload),
-		ExpiresAt:   share.ExpiresAt,
+		ExpiresAt:   nullTime(share.ExpiresAt),
 	})
 	if err != nil {
 		db.log.Error("failed to create query share", "error", err, "source_id", share.SourceID)
@@ -48,7 +48,6 @@ func (db *DB) GetQueryShare(ctx context.Context, token string) (*models.QuerySha
 		SourceID:       models.SourceID(row.SourceID),
 		CreatedBy:      models.UserID(row.CreatedBy),
 		Payload:        []byte(row.PayloadJson),
-		ExpiresAt:      row.ExpiresAt,
 		CreatedAt:      row.CreatedAt,
 		CreatedByEmail: row.Email,
 		CreatedByName:  row.FullName,
@@ -57,6 +56,9 @@ func (db *DB) GetQueryShare(ctx context.Context, token string) (*models.QuerySha
 		tid := models.TeamID(row.TeamID.Int64)
 		share.TeamID = &tid
 	}
+	if row.ExpiresAt.Valid {
+		share.ExpiresAt = &row.ExpiresAt.Time
+	}
 	if row.LastAccessedAt.Valid {
 		share.LastAccessedAt = &row.LastAccessedAt.Time
 	}
@@ -100,9 +102,10 @@ func (db *DB) GetUserTeamForSource(ctx context.Context, userID models.UserID, so
 	return models.TeamID(teamID), nil
 }
 
-// PruneExpiredQueryShares removes expired query shares.
+// PruneExpiredQueryShares removes expired query shares. Permanent shares
+// have no expiry and are kept.
 func (db *DB) PruneExpiredQueryShares(ctx context.Context, before time.Time) error {
-	if err := db.writeQueries.PruneExpiredQueryShares(ctx, before); err != nil {
+	if err := db.writeQueries.PruneExpiredQueryShares(ctx, sql.NullTime{Time: before, Valid: true}); err != nil {
 		db.log.Error("failed to prune expired query shares", "error", err)
 		return fmt.Errorf("error pruning expired query shares: %w", err)
 	}
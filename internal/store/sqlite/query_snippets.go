@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// CreateQuerySnippet stores a snippet and repopulates the model with the
+// persisted row (id and timestamps).
+func (db *DB) CreateQuerySnippet(ctx context.Context, snippet *models.QuerySnippet) error {
+	if snippet == nil {
+		return fmt.Errorf("query snippet payload is required")
+	}
+	params := sqlc.CreateQuerySnippetParams{
+		TeamID:      int64(snippet.TeamID),
+		Name:        snippet.Name,
+		Description: snippet.Description,
+		Query:       snippet.Query,
+	}
+	if snippet.CreatedBy != nil {
+		params.CreatedBy = sql.NullInt64{Int64: int64(*snippet.CreatedBy), Valid: true}
+	}
+	id, err := db.writeQueries.CreateQuerySnippet(ctx, params)
+	if err != nil {
+		if isUniqueConstraintSQLiteError(err, "query_snippets", "name") {
+			return fmt.Errorf("%w: snippet name already used in this team", models.ErrConflict)
+		}
+		db.log.Error("failed to create query snippet", "error", err, "team_id", snippet.TeamID)
+		return fmt.Errorf("error creating query snippet: %w", err)
+	}
+
+	created, err := db.GetQuerySnippet(ctx, models.QuerySnippetID(id))
+	if err != nil {
+		return err
+	}
+	*snippet = *created
+	return nil
+}
+
+// GetQuerySnippet returns a snippet by id, or models.ErrNotFound if missing.
+func (db *DB) GetQuerySnippet(ctx context.Context, id models.QuerySnippetID) (*models.QuerySnippet, error) {
+	row, err := db.readQueries.GetQuerySnippet(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting query snippet id %d: %w", id, err)
+	}
+	return mapQuerySnippetRow(row), nil
+}
+
+// ListQuerySnippetsByTeam returns a team's snippets ordered by name.
+func (db *DB) ListQuerySnippetsByTeam(ctx context.Context, teamID models.TeamID) ([]*models.QuerySnippet, error) {
+	rows, err := db.readQueries.ListQuerySnippetsByTeam(ctx, int64(teamID))
+	if err != nil {
+		db.log.Error("failed to list query snippets", "error", err, "team_id", teamID)
+		return nil, fmt.Errorf("error listing query snippets: %w", err)
+	}
+	snippets := make([]*models.QuerySnippet, 0, len(rows))
+	for _, row := range rows {
+		snippets = append(snippets, mapQuerySnippetRow(row))
+	}
+	return snippets, nil
+}
+
+// UpdateQuerySnippet overwrites a snippet's name, description and query.
+// Returns models.ErrNotFound when the id does not exist.
+func (db *DB) UpdateQuerySnippet(ctx context.Context, snippet *models.QuerySnippet) error {
+	if snippet == nil {
+		return fmt.Errorf("query snippet payload is required")
+	}
+	_, err := db.writeQueries.UpdateQuerySnippet(ctx, sqlc.UpdateQuerySnippetParams{
+		Name:        snippet.Name,
+		Description: snippet.Description,
+		Query:       snippet.Query,
+		ID:          int64(snippet.ID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		if isUniqueConstraintSQLiteError(err, "query_snippets", "name") {
+			return fmt.Errorf("%w: snippet name already used in this team", models.ErrConflict)
+		}
+		db.log.Error("failed to update query snippet", "error", err, "snippet_id", snippet.ID)
+		return fmt.Errorf("error updating query snippet: %w", err)
+	}
+	return nil
+}
+
+// DeleteQuerySnippet removes a snippet. Returns models.ErrNotFound when the id
+// does not exist.
+func (db *DB) DeleteQuerySnippet(ctx context.Context, id models.QuerySnippetID) error {
+	if _, err := db.writeQueries.DeleteQuerySnippet(ctx, int64(id)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to delete query snippet", "error", err, "snippet_id", id)
+		return fmt.Errorf("error deleting query snippet: %w", err)
+	}
+	return nil
+}
+
+func mapQuerySnippetRow(row sqlc.QuerySnippet) *models.QuerySnippet {
+	snippet := &models.QuerySnippet{
+		ID:          models.QuerySnippetID(row.ID),
+		TeamID:      models.TeamID(row.TeamID),
+		Name:        row.Name,
+		Description: row.Description,
+		Query:       row.Query,
+		Timestamps: models.Timestamps{
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		},
+	}
+	if row.CreatedBy.Valid {
+		uid := models.UserID(row.CreatedBy.Int64)
+		snippet.CreatedBy = &uid
+	}
+	return snippet
+}
@@ -0,0 +1,102 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// GetSourceRollup returns a source's rollup state, or models.ErrNotFound if the
+// source has no rollup.
+func (db *DB) GetSourceRollup(ctx context.Context, sourceID models.SourceID) (*models.SourceRollup, error) {
+	row, err := db.readQueries.GetSourceRollup(ctx, int64(sourceID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting rollup for source %d: %w", sourceID, err)
+	}
+	return mapSourceRollupRow(row), nil
+}
+
+// ListSourceRollups returns every configured rollup.
+func (db *DB) ListSourceRollups(ctx context.Context) ([]*models.SourceRollup, error) {
+	rows, err := db.readQueries.ListSourceRollups(ctx)
+	if err != nil {
+		db.log.Error("failed to list source rollups", "error", err)
+		return nil, fmt.Errorf("error listing source rollups: %w", err)
+	}
+	rollups := make([]*models.SourceRollup, 0, len(rows))
+	for _, row := range rows {
+		rollups = append(rollups, mapSourceRollupRow(row))
+	}
+	return rollups, nil
+}
+
+// UpsertSourceRollup enables a source's rollup or changes its dimension.
+func (db *DB) UpsertSourceRollup(ctx context.Context, sourceID models.SourceID, dimension string) error {
+	err := db.writeQueries.UpsertSourceRollup(ctx, sqlc.UpsertSourceRollupParams{
+		SourceID:  int64(sourceID),
+		Dimension: dimension,
+	})
+	if err != nil {
+		db.log.Error("failed to upsert source rollup", "error", err, "source_id", sourceID)
+		return fmt.Errorf("error saving rollup for source %d: %w", sourceID, err)
+	}
+	return nil
+}
+
+// UpdateSourceRollupProgress records the outcome of a rollup run.
+func (db *DB) UpdateSourceRollupProgress(ctx context.Context, rollup *models.SourceRollup) error {
+	n, err := db.writeQueries.UpdateSourceRollupProgress(ctx, sqlc.UpdateSourceRollupProgressParams{
+		RolledUpFrom:  nullTime(rollup.RolledUpFrom),
+		RolledUpUntil: nullTime(rollup.RolledUpUntil),
+		LastRunAt:     nullTime(rollup.LastRunAt),
+		LastError:     rollup.LastError,
+		SourceID:      int64(rollup.SourceID),
+		Dimension:     rollup.Dimension,
+	})
+	if err != nil {
+		db.log.Error("failed to update source rollup progress", "error", err, "source_id", rollup.SourceID)
+		return fmt.Errorf("error updating rollup progress for source %d: %w", rollup.SourceID, err)
+	}
+	if n == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteSourceRollup removes a source's rollup configuration.
+func (db *DB) DeleteSourceRollup(ctx context.Context, sourceID models.SourceID) error {
+	if err := db.writeQueries.DeleteSourceRollup(ctx, int64(sourceID)); err != nil {
+		db.log.Error("failed to delete source rollup", "error", err, "source_id", sourceID)
+		return fmt.Errorf("error deleting rollup for source %d: %w", sourceID, err)
+	}
+	return nil
+}
+
+func mapSourceRollupRow(row sqlc.SourceRollup) *models.SourceRollup {
+	rollup := &models.SourceRollup{
+		SourceID:  models.SourceID(row.SourceID),
+		Dimension: row.Dimension,
+		LastError: row.LastError,
+		Timestamps: models.Timestamps{
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		},
+	}
+	if row.RolledUpFrom.Valid {
+		rollup.RolledUpFrom = &row.RolledUpFrom.Time
+	}
+	if row.RolledUpUntil.Valid {
+		rollup.RolledUpUntil = &row.RolledUpUntil.Time
+	}
+	if row.LastRunAt.Valid {
+		rollup.LastRunAt = &row.LastRunAt.Time
+	}
+	return rollup
+}
@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/secrets"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// TestSourceSecretsSealedAtRest stores a source without a key, then reopens
+// with one: reads must decrypt, the stored row must hold no plaintext once
+// SealSourceSecrets has run, and a rotated key must still read it.
+func TestSourceSecretsSealedAtRest(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "logchef.db")
+	open := func(box *secrets.Box) *DB {
+		t.Helper()
+		db, err := New(ctx, Options{
+			Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+			Config:  config.SQLiteConfig{Path: path},
+			Secrets: box,
+		})
+		if err != nil {
+			t.Fatalf("sqlite.New: %v", err)
+		}
+		return db
+	}
+	storedConfig := func(db *DB, id models.SourceID) string {
+		t.Helper()
+		var raw string
+		if err := db.readDB.QueryRowContext(ctx, "SELECT connection_config FROM sources WHERE id = ?", id).Scan(&raw); err != nil {
+			t.Fatalf("reading stored config: %v", err)
+		}
+		return raw
+	}
+	key := func(b byte) secrets.KeyWrapper {
+		k, err := secrets.NewLocalKey(bytes.Repeat([]byte{b}, secrets.KeySize))
+		if err != nil {
+			t.Fatalf("NewLocalKey: %v", err)
+		}
+		return k
+	}
+
+	db := open(nil)
+	source := &models.Source{
+		Name:        "app",
+		MetaTSField: "timestamp",
+		Connection:  models.ConnectionInfo{Host: "ch:9000", Username: "default", Password: "hunter2", Database: "logs", TableName: "app"},
+	}
+	if err := db.CreateSource(ctx, source); err != nil {
+		t.Fatalf("CreateSource: %v", err)
+	}
+	if _, err := db.SealSourceSecrets(ctx); err == nil {
+		t.Error("SealSourceSecrets without a key succeeded")
+	}
+	_ = db.Close()
+
+	oldKey := key(1)
+	db = open(secrets.New(oldKey))
+	if n, err := db.SealSourceSecrets(ctx); err != nil || n != 1 {
+		t.Fatalf("SealSourceSecrets = %d, %v; want 1 source sealed", n, err)
+	}
+	if n, _ := db.SealSourceSecrets(ctx); n != 0 {
+		t.Errorf("second SealSourceSecrets sealed %d sources, want 0", n)
+	}
+	if raw := storedConfig(db, source.ID); strings.Contains(raw, "hunter2") || !strings.Contains(raw, "ch:9000") {
+		t.Errorf("stored config = %s, want the password sealed and the host readable", raw)
+	}
+	got, err := db.GetSource(ctx, source.ID)
+	if err != nil || got.Connection.Password != "hunter2" {
+		t.Fatalf("GetSource password = %q, %v; want it decrypted", got.Connection.Password, err)
+	}
+	_ = db.Close()
+
+	db = open(secrets.New(key(2), oldKey))
+	defer func() { _ = db.Close() }()
+	if got, err := db.GetSource(ctx, source.ID); err != nil || got.Connection.Password != "hunter2" {
+		t.Fatalf("GetSource after rotation = %v, %v", got, err)
+	}
+	if n, err := db.SealSourceSecrets(ctx); err != nil || n != 1 {
+		t.Errorf("SealSourceSecrets after rotation = %d, %v; want 1", n, err)
+	}
+}
@@ -0,0 +1,187 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store/alertjson"
+	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// CreateAlertSilence inserts a new silence and repopulates the model with the
+// persisted row (id and timestamps).
+func (db *DB) CreateAlertSilence(ctx context.Context, silence *models.AlertSilence) error {
+	if silence == nil {
+		return fmt.Errorf("silence payload is required")
+	}
+	recurrence, err := alertjson.Encode(silence.Recurrence, silence.Recurrence == nil)
+	if err != nil {
+		return fmt.Errorf("error encoding silence recurrence: %w", err)
+	}
+	params := sqlc.CreateAlertSilenceParams{
+		Reason:         silence.Reason,
+		StartsAt:       silence.StartsAt.UTC(),
+		EndsAt:         nullTime(utcPtr(silence.EndsAt)),
+		RecurrenceJson: recurrence,
+	}
+	if silence.AlertID != nil {
+		params.AlertID = sql.NullInt64{Int64: int64(*silence.AlertID), Valid: true}
+	}
+	if silence.SourceID != nil {
+		params.SourceID = sql.NullInt64{Int64: int64(*silence.SourceID), Valid: true}
+	}
+	if silence.TeamID != nil {
+		params.TeamID = sql.NullInt64{Int64: int64(*silence.TeamID), Valid: true}
+	}
+	if silence.CreatedBy != nil {
+		params.CreatedBy = sql.NullInt64{Int64: int64(*silence.CreatedBy), Valid: true}
+	}
+
+	id, err := db.writeQueries.CreateAlertSilence(ctx, params)
+	if err != nil {
+		db.log.Error("failed to create alert silence", "error", err)
+		return fmt.Errorf("error creating alert silence: %w", err)
+	}
+
+	created, err := db.GetAlertSilence(ctx, models.SilenceID(id))
+	if err != nil {
+		return err
+	}
+	*silence = *created
+	return nil
+}
+
+// GetAlertSilence returns a silence by id, or models.ErrNotFound if missing.
+func (db *DB) GetAlertSilence(ctx context.Context, id models.SilenceID) (*models.AlertSilence, error) {
+	row, err := db.readQueries.GetAlertSilence(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting alert silence id %d: %w", id, err)
+	}
+	return mapAlertSilenceRow(row)
+}
+
+// ListAlertSilences returns every silence, newest start first.
+func (db *DB) ListAlertSilences(ctx context.Context) ([]*models.AlertSilence, error) {
+	rows, err := db.readQueries.ListAlertSilences(ctx)
+	if err != nil {
+		db.log.Error("failed to list alert silences", "error", err)
+		return nil, fmt.Errorf("error listing alert silences: %w", err)
+	}
+	return mapAlertSilenceRows(rows)
+}
+
+// ListUnexpiredAlertSilences returns the silences that are open-ended or end
+// after now.
+func (db *DB) ListUnexpiredAlertSilences(ctx context.Context, now time.Time) ([]*models.AlertSilence, error) {
+	rows, err := db.readQueries.ListUnexpiredAlertSilences(ctx, sql.NullTime{Time: now.UTC(), Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("error listing unexpired alert silences: %w", err)
+	}
+	return mapAlertSilenceRows(rows)
+}
+
+// UpdateAlertSilence overwrites a silence's reason and schedule. Returns
+// models.ErrNotFound when the id does not exist.
+func (db *DB) UpdateAlertSilence(ctx context.Context, silence *models.AlertSilence) error {
+	if silence == nil {
+		return fmt.Errorf("silence payload is required")
+	}
+	recurrence, err := alertjson.Encode(silence.Recurrence, silence.Recurrence == nil)
+	if err != nil {
+		return fmt.Errorf("error encoding silence recurrence: %w", err)
+	}
+	_, err = db.writeQueries.UpdateAlertSilence(ctx, sqlc.UpdateAlertSilenceParams{
+		Reason:         silence.Reason,
+		StartsAt:       silence.StartsAt.UTC(),
+		EndsAt:         nullTime(utcPtr(silence.EndsAt)),
+		RecurrenceJson: recurrence,
+		ID:             int64(silence.ID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to update alert silence", "error", err, "silence_id", silence.ID)
+		return fmt.Errorf("error updating alert silence: %w", err)
+	}
+	return nil
+}
+
+// DeleteAlertSilence removes a silence. Returns models.ErrNotFound when the id
+// does not exist.
+func (db *DB) DeleteAlertSilence(ctx context.Context, id models.SilenceID) error {
+	if _, err := db.writeQueries.DeleteAlertSilence(ctx, int64(id)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to delete alert silence", "error", err, "silence_id", id)
+		return fmt.Errorf("error deleting alert silence: %w", err)
+	}
+	return nil
+}
+
+func mapAlertSilenceRows(rows []sqlc.AlertSilence) ([]*models.AlertSilence, error) {
+	silences := make([]*models.AlertSilence, 0, len(rows))
+	for _, row := range rows {
+		silence, err := mapAlertSilenceRow(row)
+		if err != nil {
+			return nil, err
+		}
+		silences = append(silences, silence)
+	}
+	return silences, nil
+}
+
+func mapAlertSilenceRow(row sqlc.AlertSilence) (*models.AlertSilence, error) {
+	recurrence, err := alertjson.Decode[*models.SilenceRecurrence](row.RecurrenceJson)
+	if err != nil {
+		return nil, fmt.Errorf("decoding recurrence of alert silence %d: %w", row.ID, err)
+	}
+	silence := &models.AlertSilence{
+		ID:         models.SilenceID(row.ID),
+		Reason:     row.Reason,
+		StartsAt:   row.StartsAt,
+		Recurrence: recurrence,
+		Timestamps: models.Timestamps{
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		},
+	}
+	if row.AlertID.Valid {
+		id := models.AlertID(row.AlertID.Int64)
+		silence.AlertID = &id
+	}
+	if row.SourceID.Valid {
+		id := models.SourceID(row.SourceID.Int64)
+		silence.SourceID = &id
+	}
+	if row.TeamID.Valid {
+		id := models.TeamID(row.TeamID.Int64)
+		silence.TeamID = &id
+	}
+	if row.EndsAt.Valid {
+		endsAt := row.EndsAt.Time
+		silence.EndsAt = &endsAt
+	}
+	if row.CreatedBy.Valid {
+		uid := models.UserID(row.CreatedBy.Int64)
+		silence.CreatedBy = &uid
+	}
+	return silence, nil
+}
+
+// utcPtr returns *t in UTC, keeping nil as nil.
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
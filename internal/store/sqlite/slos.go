@@ -0,0 +1,216 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// CreateSLO inserts a new SLO and repopulates the model with the persisted row
+// (id and timestamps).
+func (db *DB) CreateSLO(ctx context.Context, slo *models.SLO) error {
+	if slo == nil {
+		return fmt.Errorf("slo payload is required")
+	}
+	params := sqlc.CreateSLOParams{
+		TeamID:        int64(slo.TeamID),
+		SourceID:      int64(slo.SourceID),
+		Name:          slo.Name,
+		Description:   slo.Description,
+		QueryLanguage: string(slo.QueryLanguage),
+		GoodQuery:     slo.GoodQuery,
+		TotalQuery:    slo.TotalQuery,
+		Target:        slo.Target,
+		WindowSeconds: int64(slo.WindowSeconds),
+	}
+	if slo.CreatedBy != nil {
+		params.CreatedBy = sql.NullInt64{Int64: int64(*slo.CreatedBy), Valid: true}
+	}
+
+	id, err := db.writeQueries.CreateSLO(ctx, params)
+	if err != nil {
+		db.log.Error("failed to create slo", "error", err, "team_id", slo.TeamID)
+		return fmt.Errorf("error creating slo: %w", err)
+	}
+
+	created, err := db.GetSLO(ctx, models.SLOID(id))
+	if err != nil {
+		return err
+	}
+	*slo = *created
+	return nil
+}
+
+// GetSLO returns an SLO by id, or models.ErrNotFound if missing.
+func (db *DB) GetSLO(ctx context.Context, id models.SLOID) (*models.SLO, error) {
+	row, err := db.readQueries.GetSLO(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting slo id %d: %w", id, err)
+	}
+	return mapSLORow(row), nil
+}
+
+// ListSLOsByTeam returns a team's SLOs ordered by name.
+func (db *DB) ListSLOsByTeam(ctx context.Context, teamID models.TeamID) ([]*models.SLO, error) {
+	rows, err := db.readQueries.ListSLOsByTeam(ctx, int64(teamID))
+	if err != nil {
+		db.log.Error("failed to list slos", "error", err, "team_id", teamID)
+		return nil, fmt.Errorf("error listing slos: %w", err)
+	}
+	return mapSLORows(rows), nil
+}
+
+// ListSLOs returns every SLO.
+func (db *DB) ListSLOs(ctx context.Context) ([]*models.SLO, error) {
+	rows, err := db.readQueries.ListSLOs(ctx)
+	if err != nil {
+		db.log.Error("failed to list slos", "error", err)
+		return nil, fmt.Errorf("error listing slos: %w", err)
+	}
+	return mapSLORows(rows), nil
+}
+
+// UpdateSLO overwrites an SLO's mutable fields. Returns models.ErrNotFound
+// when the id does not exist.
+func (db *DB) UpdateSLO(ctx context.Context, slo *models.SLO) error {
+	if slo == nil {
+		return fmt.Errorf("slo payload is required")
+	}
+	_, err := db.writeQueries.UpdateSLO(ctx, sqlc.UpdateSLOParams{
+		Name:          slo.Name,
+		Description:   slo.Description,
+		QueryLanguage: string(slo.QueryLanguage),
+		GoodQuery:     slo.GoodQuery,
+		TotalQuery:    slo.TotalQuery,
+		Target:        slo.Target,
+		WindowSeconds: int64(slo.WindowSeconds),
+		ID:            int64(slo.ID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to update slo", "error", err, "slo_id", slo.ID)
+		return fmt.Errorf("error updating slo: %w", err)
+	}
+	return nil
+}
+
+// DeleteSLO removes an SLO together with its evaluations and burn-rate
+// alerts. Returns models.ErrNotFound when the id does not exist.
+func (db *DB) DeleteSLO(ctx context.Context, id models.SLOID) error {
+	if _, err := db.writeQueries.DeleteSLO(ctx, int64(id)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to delete slo", "error", err, "slo_id", id)
+		return fmt.Errorf("error deleting slo: %w", err)
+	}
+	return nil
+}
+
+// InsertSLOEvaluation appends one evaluation and populates its ID.
+func (db *DB) InsertSLOEvaluation(ctx context.Context, eval *models.SLOEvaluation) error {
+	id, err := db.writeQueries.InsertSLOEvaluation(ctx, sqlc.InsertSLOEvaluationParams{
+		SloID:                int64(eval.SLOID),
+		Good:                 eval.Good,
+		Total:                eval.Total,
+		Compliance:           nullFloat64(eval.Compliance),
+		BurnRate:             nullFloat64(eval.BurnRate),
+		ErrorBudgetRemaining: nullFloat64(eval.ErrorBudgetRemaining),
+		Error:                eval.Error,
+		EvaluatedAt:          eval.EvaluatedAt.UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("error inserting slo evaluation: %w", err)
+	}
+	eval.ID = id
+	return nil
+}
+
+// ListSLOEvaluations returns an SLO's newest evaluations, newest first.
+func (db *DB) ListSLOEvaluations(ctx context.Context, id models.SLOID, limit int) ([]*models.SLOEvaluation, error) {
+	rows, err := db.readQueries.ListSLOEvaluations(ctx, sqlc.ListSLOEvaluationsParams{
+		SloID: int64(id),
+		Limit: int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing slo evaluations: %w", err)
+	}
+	evals := make([]*models.SLOEvaluation, 0, len(rows))
+	for _, row := range rows {
+		evals = append(evals, &models.SLOEvaluation{
+			ID:                   row.ID,
+			SLOID:                models.SLOID(row.SloID),
+			Good:                 row.Good,
+			Total:                row.Total,
+			Compliance:           float64Ptr(row.Compliance),
+			BurnRate:             float64Ptr(row.BurnRate),
+			ErrorBudgetRemaining: float64Ptr(row.ErrorBudgetRemaining),
+			Error:                row.Error,
+			EvaluatedAt:          row.EvaluatedAt,
+		})
+	}
+	return evals, nil
+}
+
+// PruneSLOEvaluations keeps the most recent keep evaluations of an SLO.
+func (db *DB) PruneSLOEvaluations(ctx context.Context, id models.SLOID, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	if err := db.writeQueries.PruneSLOEvaluations(ctx, sqlc.PruneSLOEvaluationsParams{
+		SloID:   int64(id),
+		SloID_2: int64(id),
+		Limit:   int64(keep),
+	}); err != nil {
+		return fmt.Errorf("error pruning slo evaluations: %w", err)
+	}
+	return nil
+}
+
+func mapSLORows(rows []sqlc.Slo) []*models.SLO {
+	slos := make([]*models.SLO, 0, len(rows))
+	for _, row := range rows {
+		slos = append(slos, mapSLORow(row))
+	}
+	return slos
+}
+
+func mapSLORow(row sqlc.Slo) *models.SLO {
+	slo := &models.SLO{
+		ID:            models.SLOID(row.ID),
+		TeamID:        models.TeamID(row.TeamID),
+		SourceID:      models.SourceID(row.SourceID),
+		Name:          row.Name,
+		Description:   row.Description,
+		QueryLanguage: models.QueryLanguage(row.QueryLanguage),
+		GoodQuery:     row.GoodQuery,
+		TotalQuery:    row.TotalQuery,
+		Target:        row.Target,
+		WindowSeconds: int(row.WindowSeconds),
+		Timestamps: models.Timestamps{
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		},
+	}
+	if row.CreatedBy.Valid {
+		uid := models.UserID(row.CreatedBy.Int64)
+		slo.CreatedBy = &uid
+	}
+	return slo
+}
+
+func float64Ptr(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Float64
+}
@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// UpsertSourceStatsSnapshot stores a source's storage snapshot for a day.
+func (db *DB) UpsertSourceStatsSnapshot(ctx context.Context, snapshot *models.SourceStatsSnapshot) error {
+	columns, err := encodeColumnStorageStats(snapshot.Columns)
+	if err != nil {
+		return err
+	}
+	err = db.writeQueries.UpsertSourceStatsSnapshot(ctx, sqlc.UpsertSourceStatsSnapshotParams{
+		SourceID:          int64(snapshot.SourceID),
+		SnapshotDate:      snapshot.SnapshotDate,
+		TotalRows:         snapshot.Rows,
+		PartCount:         snapshot.PartCount,
+		CompressedBytes:   snapshot.CompressedBytes,
+		UncompressedBytes: snapshot.UncompressedBytes,
+		Columns:           string(columns),
+		CapturedAt:        snapshot.CapturedAt,
+	})
+	if err != nil {
+		db.log.Error("failed to upsert source stats snapshot", "error", err, "source_id", snapshot.SourceID)
+		return fmt.Errorf("error saving stats snapshot for source %d: %w", snapshot.SourceID, err)
+	}
+	return nil
+}
+
+// ListSourceStatsSnapshots returns a source's snapshots since sinceDate, oldest first.
+func (db *DB) ListSourceStatsSnapshots(ctx context.Context, sourceID models.SourceID, sinceDate string) ([]*models.SourceStatsSnapshot, error) {
+	rows, err := db.readQueries.ListSourceStatsSnapshots(ctx, sqlc.ListSourceStatsSnapshotsParams{
+		SourceID:  int64(sourceID),
+		SinceDate: sinceDate,
+	})
+	if err != nil {
+		db.log.Error("failed to list source stats snapshots", "error", err, "source_id", sourceID)
+		return nil, fmt.Errorf("error listing stats snapshots for source %d: %w", sourceID, err)
+	}
+	snapshots := make([]*models.SourceStatsSnapshot, 0, len(rows))
+	for _, row := range rows {
+		snapshots = append(snapshots, &models.SourceStatsSnapshot{
+			SourceID:          models.SourceID(row.SourceID),
+			SnapshotDate:      row.SnapshotDate,
+			Rows:              row.TotalRows,
+			PartCount:         row.PartCount,
+			CompressedBytes:   row.CompressedBytes,
+			UncompressedBytes: row.UncompressedBytes,
+			Columns:           decodeColumnStorageStats([]byte(row.Columns)),
+			CapturedAt:        row.CapturedAt,
+		})
+	}
+	return snapshots, nil
+}
+
+// DeleteSourceStatsSnapshotsBefore removes snapshots dated before beforeDate.
+func (db *DB) DeleteSourceStatsSnapshotsBefore(ctx context.Context, beforeDate string) (int64, error) {
+	n, err := db.writeQueries.DeleteSourceStatsSnapshotsBefore(ctx, beforeDate)
+	if err != nil {
+		db.log.Error("failed to prune source stats snapshots", "error", err)
+		return 0, fmt.Errorf("error pruning source stats snapshots: %w", err)
+	}
+	return n, nil
+}
+
+func encodeColumnStorageStats(columns []models.ColumnStorageStats) ([]byte, error) {
+	if columns == nil {
+		columns = []models.ColumnStorageStats{}
+	}
+	data, err := json.Marshal(columns)
+	if err != nil {
+		return nil, fmt.Errorf("encoding column storage stats: %w", err)
+	}
+	return data, nil
+}
+
+// decodeColumnStorageStats reads the stored columns array; a malformed value
+// yields no columns rather than failing the whole listing.
+func decodeColumnStorageStats(data []byte) []models.ColumnStorageStats {
+	var columns []models.ColumnStorageStats
+	if err := json.Unmarshal(data, &columns); err != nil || columns == nil {
+		return []models.ColumnStorageStats{}
+	}
+	return columns
+}
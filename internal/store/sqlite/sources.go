@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/mr-karan/logchef/internal/secrets"
 	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
 	"github.com/mr-karan/logchef/pkg/models"
 )
@@ -19,6 +20,10 @@ func (db *DB) CreateSource(ctx context.Context, source *models.Source) error {
 	if err := source.SyncConnectionConfig(); err != nil {
 		return fmt.Errorf("prepare source connection config: %w", err)
 	}
+	connectionConfig, err := db.secrets.SealConnection(source.SourceType, source.ConnectionConfig)
+	if err != nil {
+		return fmt.Errorf("encrypt source secrets: %w", err)
+	}
 
 	// Map domain model to sqlc parameters.
 	params := sqlc.CreateSourceParams{
@@ -27,12 +32,16 @@ func (db *DB) CreateSource(ctx context.Context, source *models.Source) error {
 		SourceType:        source.SourceType.String(),
 		MetaTsField:       source.MetaTSField,
 		MetaSeverityField: sql.NullString{String: source.MetaSeverityField, Valid: source.MetaSeverityField != ""},
-		ConnectionConfig:  string(source.ConnectionConfig),
+		ConnectionConfig:  string(connectionConfig),
 		IdentityKey:       source.IdentityKey,
 		Description:       sql.NullString{String: source.Description, Valid: source.Description != ""},
 		TtlDays:           int64(source.TTLDays),
 		Managed:           boolToInt(source.Managed),
 		SecretRef:         sql.NullString{String: source.SecretRef, Valid: source.SecretRef != ""},
+		Tags:              source.Tags.Encode(),
+		ExtractionRules:   source.ExtractionRules.Encode(),
+		TraceCorrelation:  source.TraceCorrelation.Encode(),
+		MaskedColumns:     source.MaskedColumns.Encode(),
 	}
 
 	// Execute the generated query.
@@ -59,7 +68,7 @@ func (db *DB) CreateSource(ctx context.Context, source *models.Source) error {
 	}
 
 	// Update the input model with the database-generated timestamps.
-	newSource := mapSourceRowToModel(&sourceRow) // Assume mapSourceRowToModel handles potential nil
+	newSource := db.mapSourceRowToModel(&sourceRow) // Assume mapSourceRowToModel handles potential nil
 	if newSource != nil {
 		source.CreatedAt = newSource.CreatedAt
 		source.UpdatedAt = newSource.UpdatedAt
@@ -79,7 +88,7 @@ func (db *DB) GetSource(ctx context.Context, id models.SourceID) (*models.Source
 	}
 
 	// Map sqlc result to domain model.
-	source := mapSourceRowToModel(&sourceRow)
+	source := db.mapSourceRowToModel(&sourceRow)
 	if source == nil {
 		// This case should ideally be covered by handleNotFoundError, but as a safeguard:
 		return nil, fmt.Errorf("internal error: source row for id %d mapped to nil", id)
@@ -101,7 +110,7 @@ func (db *DB) GetSourceByIdentityKey(ctx context.Context, identityKey string) (*
 	}
 
 	// Map sqlc result to domain model.
-	source := mapSourceRowToModel(&sourceRow)
+	source := db.mapSourceRowToModel(&sourceRow)
 	if source == nil {
 		return nil, fmt.Errorf("internal error: source row for identity %s mapped to nil", identityKey)
 	}
@@ -120,7 +129,7 @@ func (db *DB) ListSources(ctx context.Context) ([]*models.Source, error) {
 	// Map each sqlc row to the domain model.
 	sources := make([]*models.Source, 0, len(sourceRows)) // Initialize with 0 length
 	for i := range sourceRows {                           // Iterate safely over slice index
-		mappedSource := mapSourceRowToModel(&sourceRows[i])
+		mappedSource := db.mapSourceRowToModel(&sourceRows[i])
 		if mappedSource != nil { // Avoid appending nil if mapping fails
 			sources = append(sources, mappedSource)
 		}
@@ -136,6 +145,10 @@ func (db *DB) UpdateSource(ctx context.Context, source *models.Source) error {
 	if err := source.SyncConnectionConfig(); err != nil {
 		return fmt.Errorf("prepare source connection config: %w", err)
 	}
+	connectionConfig, err := db.secrets.SealConnection(source.SourceType, source.ConnectionConfig)
+	if err != nil {
+		return fmt.Errorf("encrypt source secrets: %w", err)
+	}
 
 	// Map domain model to sqlc parameters.
 	params := sqlc.UpdateSourceParams{
@@ -144,16 +157,20 @@ func (db *DB) UpdateSource(ctx context.Context, source *models.Source) error {
 		SourceType:        source.SourceType.String(),
 		MetaTsField:       source.MetaTSField,
 		MetaSeverityField: sql.NullString{String: source.MetaSeverityField, Valid: source.MetaSeverityField != ""},
-		ConnectionConfig:  string(source.ConnectionConfig),
+		ConnectionConfig:  string(connectionConfig),
 		IdentityKey:       source.IdentityKey,
 		Description:       sql.NullString{String: source.Description, Valid: source.Description != ""},
 		TtlDays:           int64(source.TTLDays),
 		Managed:           boolToInt(source.Managed),
 		SecretRef:         sql.NullString{String: source.SecretRef, Valid: source.SecretRef != ""},
+		Tags:              source.Tags.Encode(),
+		ExtractionRules:   source.ExtractionRules.Encode(),
+		TraceCorrelation:  source.TraceCorrelation.Encode(),
+		MaskedColumns:     source.MaskedColumns.Encode(),
 		ID:                int64(source.ID),
 	}
 
-	err := db.writeQueries.UpdateSource(ctx, params)
+	err = db.writeQueries.UpdateSource(ctx, params)
 	if err != nil {
 		db.log.Error("failed to update source record in db", "error", err, "source_id", source.ID)
 		// TODO: Check for specific errors like not found? The sqlc exec doesn't return ErrNoRows usually.
@@ -178,3 +195,37 @@ func (db *DB) DeleteSource(ctx context.Context, id models.SourceID) error {
 
 	return nil
 }
+
+// SealSourceSecrets re-seals the connection secrets of every source that has
+// them in plaintext or under a previous key.
+func (db *DB) SealSourceSecrets(ctx context.Context) (int, error) {
+	if db.secrets == nil {
+		return 0, secrets.ErrNoKey
+	}
+	rows, err := db.readQueries.ListSources(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error listing sources: %w", err)
+	}
+	sealed := 0
+	for _, row := range rows {
+		sourceType := models.SourceType(row.SourceType)
+		if !db.secrets.NeedsSeal(sourceType, []byte(row.ConnectionConfig)) {
+			continue
+		}
+		conn, err := db.secrets.OpenConnection(sourceType, []byte(row.ConnectionConfig))
+		if err != nil {
+			return sealed, fmt.Errorf("source %d: %w", row.ID, err)
+		}
+		if conn, err = db.secrets.SealConnection(sourceType, conn); err != nil {
+			return sealed, fmt.Errorf("source %d: %w", row.ID, err)
+		}
+		if err := db.writeQueries.UpdateSourceConnectionConfig(ctx, sqlc.UpdateSourceConnectionConfigParams{
+			ConnectionConfig: string(conn),
+			ID:               row.ID,
+		}); err != nil {
+			return sealed, fmt.Errorf("error updating source %d: %w", row.ID, err)
+		}
+		sealed++
+	}
+	return sealed, nil
+}
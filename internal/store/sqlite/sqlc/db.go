@@ -24,6 +24,9 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.acquireAlertLeaseStmt, err = db.PrepareContext(ctx, acquireAlertLease); err != nil {
+		return nil, fmt.Errorf("error preparing query AcquireAlertLease: %w", err)
+	}
 	if q.addCollectionItemStmt, err = db.PrepareContext(ctx, addCollectionItem); err != nil {
 		return nil, fmt.Errorf("error preparing query AddCollectionItem: %w", err)
 	}
@@ -42,6 +45,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.countAdminUsersStmt, err = db.PrepareContext(ctx, countAdminUsers); err != nil {
 		return nil, fmt.Errorf("error preparing query CountAdminUsers: %w", err)
 	}
+	if q.countQueryHistoryStmt, err = db.PrepareContext(ctx, countQueryHistory); err != nil {
+		return nil, fmt.Errorf("error preparing query CountQueryHistory: %w", err)
+	}
 	if q.countSharedCollectionEditAccessStmt, err = db.PrepareContext(ctx, countSharedCollectionEditAccess); err != nil {
 		return nil, fmt.Errorf("error preparing query CountSharedCollectionEditAccess: %w", err)
 	}
@@ -54,6 +60,12 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.createAlertStmt, err = db.PrepareContext(ctx, createAlert); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateAlert: %w", err)
 	}
+	if q.createAlertSilenceStmt, err = db.PrepareContext(ctx, createAlertSilence); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAlertSilence: %w", err)
+	}
+	if q.createAnnotationStmt, err = db.PrepareContext(ctx, createAnnotation); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAnnotation: %w", err)
+	}
 	if q.createCollectionStmt, err = db.PrepareContext(ctx, createCollection); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateCollection: %w", err)
 	}
@@ -63,9 +75,24 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.createExportJobStmt, err = db.PrepareContext(ctx, createExportJob); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateExportJob: %w", err)
 	}
+	if q.createLogBookmarkStmt, err = db.PrepareContext(ctx, createLogBookmark); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateLogBookmark: %w", err)
+	}
+	if q.createNotebookStmt, err = db.PrepareContext(ctx, createNotebook); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateNotebook: %w", err)
+	}
+	if q.createNotebookSnapshotStmt, err = db.PrepareContext(ctx, createNotebookSnapshot); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateNotebookSnapshot: %w", err)
+	}
 	if q.createQueryShareStmt, err = db.PrepareContext(ctx, createQueryShare); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateQueryShare: %w", err)
 	}
+	if q.createQuerySnippetStmt, err = db.PrepareContext(ctx, createQuerySnippet); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateQuerySnippet: %w", err)
+	}
+	if q.createSLOStmt, err = db.PrepareContext(ctx, createSLO); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateSLO: %w", err)
+	}
 	if q.createSavedQueryStmt, err = db.PrepareContext(ctx, createSavedQuery); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateSavedQuery: %w", err)
 	}
@@ -81,12 +108,24 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.createUserStmt, err = db.PrepareContext(ctx, createUser); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateUser: %w", err)
 	}
+	if q.createWebhookStmt, err = db.PrepareContext(ctx, createWebhook); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWebhook: %w", err)
+	}
 	if q.deleteAPITokenStmt, err = db.PrepareContext(ctx, deleteAPIToken); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteAPIToken: %w", err)
 	}
 	if q.deleteAlertStmt, err = db.PrepareContext(ctx, deleteAlert); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteAlert: %w", err)
 	}
+	if q.deleteAlertLeasesByHolderStmt, err = db.PrepareContext(ctx, deleteAlertLeasesByHolder); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteAlertLeasesByHolder: %w", err)
+	}
+	if q.deleteAlertSilenceStmt, err = db.PrepareContext(ctx, deleteAlertSilence); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteAlertSilence: %w", err)
+	}
+	if q.deleteAnnotationStmt, err = db.PrepareContext(ctx, deleteAnnotation); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteAnnotation: %w", err)
+	}
 	if q.deleteCollectionStmt, err = db.PrepareContext(ctx, deleteCollection); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteCollection: %w", err)
 	}
@@ -99,9 +138,27 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.deleteExpiredSessionsStmt, err = db.PrepareContext(ctx, deleteExpiredSessions); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteExpiredSessions: %w", err)
 	}
+	if q.deleteLogBookmarkStmt, err = db.PrepareContext(ctx, deleteLogBookmark); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteLogBookmark: %w", err)
+	}
+	if q.deleteNotebookStmt, err = db.PrepareContext(ctx, deleteNotebook); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteNotebook: %w", err)
+	}
+	if q.deleteNotebookSnapshotStmt, err = db.PrepareContext(ctx, deleteNotebookSnapshot); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteNotebookSnapshot: %w", err)
+	}
+	if q.deleteQueryHistoryBeforeStmt, err = db.PrepareContext(ctx, deleteQueryHistoryBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteQueryHistoryBefore: %w", err)
+	}
 	if q.deleteQueryShareStmt, err = db.PrepareContext(ctx, deleteQueryShare); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteQueryShare: %w", err)
 	}
+	if q.deleteQuerySnippetStmt, err = db.PrepareContext(ctx, deleteQuerySnippet); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteQuerySnippet: %w", err)
+	}
+	if q.deleteSLOStmt, err = db.PrepareContext(ctx, deleteSLO); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteSLO: %w", err)
+	}
 	if q.deleteSavedQueryStmt, err = db.PrepareContext(ctx, deleteSavedQuery); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteSavedQuery: %w", err)
 	}
@@ -111,18 +168,45 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.deleteSourceStmt, err = db.PrepareContext(ctx, deleteSource); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteSource: %w", err)
 	}
+	if q.deleteSourceAlertEventsBeforeStmt, err = db.PrepareContext(ctx, deleteSourceAlertEventsBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteSourceAlertEventsBefore: %w", err)
+	}
+	if q.deleteSourceRollupStmt, err = db.PrepareContext(ctx, deleteSourceRollup); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteSourceRollup: %w", err)
+	}
+	if q.deleteSourceSchemaSnapshotsBeforeStmt, err = db.PrepareContext(ctx, deleteSourceSchemaSnapshotsBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteSourceSchemaSnapshotsBefore: %w", err)
+	}
+	if q.deleteSourceStatsSnapshotsBeforeStmt, err = db.PrepareContext(ctx, deleteSourceStatsSnapshotsBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteSourceStatsSnapshotsBefore: %w", err)
+	}
 	if q.deleteSystemSettingStmt, err = db.PrepareContext(ctx, deleteSystemSetting); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteSystemSetting: %w", err)
 	}
 	if q.deleteTeamStmt, err = db.PrepareContext(ctx, deleteTeam); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteTeam: %w", err)
 	}
+	if q.deleteTeamColumnPresetStmt, err = db.PrepareContext(ctx, deleteTeamColumnPreset); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteTeamColumnPreset: %w", err)
+	}
+	if q.deleteTrackedQueriesBeforeStmt, err = db.PrepareContext(ctx, deleteTrackedQueriesBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteTrackedQueriesBefore: %w", err)
+	}
+	if q.deleteTrackedQueryStmt, err = db.PrepareContext(ctx, deleteTrackedQuery); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteTrackedQuery: %w", err)
+	}
 	if q.deleteUserStmt, err = db.PrepareContext(ctx, deleteUser); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteUser: %w", err)
 	}
+	if q.deleteUserColumnPresetStmt, err = db.PrepareContext(ctx, deleteUserColumnPreset); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteUserColumnPreset: %w", err)
+	}
 	if q.deleteUserSessionsStmt, err = db.PrepareContext(ctx, deleteUserSessions); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteUserSessions: %w", err)
 	}
+	if q.deleteWebhookStmt, err = db.PrepareContext(ctx, deleteWebhook); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteWebhook: %w", err)
+	}
 	if q.failExportJobStmt, err = db.PrepareContext(ctx, failExportJob); err != nil {
 		return nil, fmt.Errorf("error preparing query FailExportJob: %w", err)
 	}
@@ -135,6 +219,12 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getAlertStmt, err = db.PrepareContext(ctx, getAlert); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAlert: %w", err)
 	}
+	if q.getAlertSilenceStmt, err = db.PrepareContext(ctx, getAlertSilence); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAlertSilence: %w", err)
+	}
+	if q.getAnnotationStmt, err = db.PrepareContext(ctx, getAnnotation); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAnnotation: %w", err)
+	}
 	if q.getCollectionStmt, err = db.PrepareContext(ctx, getCollection); err != nil {
 		return nil, fmt.Errorf("error preparing query GetCollection: %w", err)
 	}
@@ -147,15 +237,33 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getExportJobStmt, err = db.PrepareContext(ctx, getExportJob); err != nil {
 		return nil, fmt.Errorf("error preparing query GetExportJob: %w", err)
 	}
+	if q.getLatestSourceSchemaSnapshotStmt, err = db.PrepareContext(ctx, getLatestSourceSchemaSnapshot); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLatestSourceSchemaSnapshot: %w", err)
+	}
 	if q.getLatestUnresolvedAlertHistoryStmt, err = db.PrepareContext(ctx, getLatestUnresolvedAlertHistory); err != nil {
 		return nil, fmt.Errorf("error preparing query GetLatestUnresolvedAlertHistory: %w", err)
 	}
+	if q.getLogBookmarkStmt, err = db.PrepareContext(ctx, getLogBookmark); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLogBookmark: %w", err)
+	}
+	if q.getNotebookStmt, err = db.PrepareContext(ctx, getNotebook); err != nil {
+		return nil, fmt.Errorf("error preparing query GetNotebook: %w", err)
+	}
+	if q.getNotebookSnapshotStmt, err = db.PrepareContext(ctx, getNotebookSnapshot); err != nil {
+		return nil, fmt.Errorf("error preparing query GetNotebookSnapshot: %w", err)
+	}
 	if q.getPersonalCollectionStmt, err = db.PrepareContext(ctx, getPersonalCollection); err != nil {
 		return nil, fmt.Errorf("error preparing query GetPersonalCollection: %w", err)
 	}
 	if q.getQueryShareStmt, err = db.PrepareContext(ctx, getQueryShare); err != nil {
 		return nil, fmt.Errorf("error preparing query GetQueryShare: %w", err)
 	}
+	if q.getQuerySnippetStmt, err = db.PrepareContext(ctx, getQuerySnippet); err != nil {
+		return nil, fmt.Errorf("error preparing query GetQuerySnippet: %w", err)
+	}
+	if q.getSLOStmt, err = db.PrepareContext(ctx, getSLO); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSLO: %w", err)
+	}
 	if q.getSavedQueryStmt, err = db.PrepareContext(ctx, getSavedQuery); err != nil {
 		return nil, fmt.Errorf("error preparing query GetSavedQuery: %w", err)
 	}
@@ -171,6 +279,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getSourceByNameForProvisioningStmt, err = db.PrepareContext(ctx, getSourceByNameForProvisioning); err != nil {
 		return nil, fmt.Errorf("error preparing query GetSourceByNameForProvisioning: %w", err)
 	}
+	if q.getSourceRollupStmt, err = db.PrepareContext(ctx, getSourceRollup); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSourceRollup: %w", err)
+	}
 	if q.getSystemSettingStmt, err = db.PrepareContext(ctx, getSystemSetting); err != nil {
 		return nil, fmt.Errorf("error preparing query GetSystemSetting: %w", err)
 	}
@@ -180,30 +291,60 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getTeamByNameStmt, err = db.PrepareContext(ctx, getTeamByName); err != nil {
 		return nil, fmt.Errorf("error preparing query GetTeamByName: %w", err)
 	}
+	if q.getTeamColumnPresetStmt, err = db.PrepareContext(ctx, getTeamColumnPreset); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTeamColumnPreset: %w", err)
+	}
 	if q.getTeamMemberStmt, err = db.PrepareContext(ctx, getTeamMember); err != nil {
 		return nil, fmt.Errorf("error preparing query GetTeamMember: %w", err)
 	}
+	if q.getTeamQueryUsageStmt, err = db.PrepareContext(ctx, getTeamQueryUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTeamQueryUsage: %w", err)
+	}
+	if q.getTeamSourceRowFilterStmt, err = db.PrepareContext(ctx, getTeamSourceRowFilter); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTeamSourceRowFilter: %w", err)
+	}
 	if q.getUserStmt, err = db.PrepareContext(ctx, getUser); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUser: %w", err)
 	}
 	if q.getUserByEmailStmt, err = db.PrepareContext(ctx, getUserByEmail); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUserByEmail: %w", err)
 	}
+	if q.getUserColumnPresetStmt, err = db.PrepareContext(ctx, getUserColumnPreset); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserColumnPreset: %w", err)
+	}
 	if q.getUserPreferencesStmt, err = db.PrepareContext(ctx, getUserPreferences); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUserPreferences: %w", err)
 	}
 	if q.getUserTeamForSourceStmt, err = db.PrepareContext(ctx, getUserTeamForSource); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUserTeamForSource: %w", err)
 	}
+	if q.getWebhookStmt, err = db.PrepareContext(ctx, getWebhook); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWebhook: %w", err)
+	}
 	if q.incrementQueryStatsStmt, err = db.PrepareContext(ctx, incrementQueryStats); err != nil {
 		return nil, fmt.Errorf("error preparing query IncrementQueryStats: %w", err)
 	}
 	if q.insertAlertHistoryStmt, err = db.PrepareContext(ctx, insertAlertHistory); err != nil {
 		return nil, fmt.Errorf("error preparing query InsertAlertHistory: %w", err)
 	}
+	if q.insertAuditEventStmt, err = db.PrepareContext(ctx, insertAuditEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertAuditEvent: %w", err)
+	}
 	if q.insertQueryHistoryStmt, err = db.PrepareContext(ctx, insertQueryHistory); err != nil {
 		return nil, fmt.Errorf("error preparing query InsertQueryHistory: %w", err)
 	}
+	if q.insertSLOEvaluationStmt, err = db.PrepareContext(ctx, insertSLOEvaluation); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertSLOEvaluation: %w", err)
+	}
+	if q.insertSourceAlertEventStmt, err = db.PrepareContext(ctx, insertSourceAlertEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertSourceAlertEvent: %w", err)
+	}
+	if q.insertSourceSchemaSnapshotStmt, err = db.PrepareContext(ctx, insertSourceSchemaSnapshot); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertSourceSchemaSnapshot: %w", err)
+	}
+	if q.isAlertManagedStmt, err = db.PrepareContext(ctx, isAlertManaged); err != nil {
+		return nil, fmt.Errorf("error preparing query IsAlertManaged: %w", err)
+	}
 	if q.isSourceManagedStmt, err = db.PrepareContext(ctx, isSourceManaged); err != nil {
 		return nil, fmt.Errorf("error preparing query IsSourceManaged: %w", err)
 	}
@@ -225,6 +366,12 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listAlertHistoryStmt, err = db.PrepareContext(ctx, listAlertHistory); err != nil {
 		return nil, fmt.Errorf("error preparing query ListAlertHistory: %w", err)
 	}
+	if q.listAlertHistorySinceStmt, err = db.PrepareContext(ctx, listAlertHistorySince); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAlertHistorySince: %w", err)
+	}
+	if q.listAlertSilencesStmt, err = db.PrepareContext(ctx, listAlertSilences); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAlertSilences: %w", err)
+	}
 	if q.listAlertsBySourceStmt, err = db.PrepareContext(ctx, listAlertsBySource); err != nil {
 		return nil, fmt.Errorf("error preparing query ListAlertsBySource: %w", err)
 	}
@@ -234,6 +381,15 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listAllSavedQueriesStmt, err = db.PrepareContext(ctx, listAllSavedQueries); err != nil {
 		return nil, fmt.Errorf("error preparing query ListAllSavedQueries: %w", err)
 	}
+	if q.listAnnotationsByTeamStmt, err = db.PrepareContext(ctx, listAnnotationsByTeam); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAnnotationsByTeam: %w", err)
+	}
+	if q.listAnnotationsInRangeStmt, err = db.PrepareContext(ctx, listAnnotationsInRange); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAnnotationsInRange: %w", err)
+	}
+	if q.listAuditEventsStmt, err = db.PrepareContext(ctx, listAuditEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAuditEvents: %w", err)
+	}
 	if q.listCollectionItemsStmt, err = db.PrepareContext(ctx, listCollectionItems); err != nil {
 		return nil, fmt.Errorf("error preparing query ListCollectionItems: %w", err)
 	}
@@ -249,6 +405,15 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listExpiredExportJobPathsStmt, err = db.PrepareContext(ctx, listExpiredExportJobPaths); err != nil {
 		return nil, fmt.Errorf("error preparing query ListExpiredExportJobPaths: %w", err)
 	}
+	if q.listExpiredNotebookSnapshotsStmt, err = db.PrepareContext(ctx, listExpiredNotebookSnapshots); err != nil {
+		return nil, fmt.Errorf("error preparing query ListExpiredNotebookSnapshots: %w", err)
+	}
+	if q.listLogBookmarksForUserStmt, err = db.PrepareContext(ctx, listLogBookmarksForUser); err != nil {
+		return nil, fmt.Errorf("error preparing query ListLogBookmarksForUser: %w", err)
+	}
+	if q.listManagedAlertsStmt, err = db.PrepareContext(ctx, listManagedAlerts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListManagedAlerts: %w", err)
+	}
 	if q.listManagedSourcesStmt, err = db.PrepareContext(ctx, listManagedSources); err != nil {
 		return nil, fmt.Errorf("error preparing query ListManagedSources: %w", err)
 	}
@@ -258,12 +423,30 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listManagedUsersStmt, err = db.PrepareContext(ctx, listManagedUsers); err != nil {
 		return nil, fmt.Errorf("error preparing query ListManagedUsers: %w", err)
 	}
+	if q.listNotebookSnapshotsStmt, err = db.PrepareContext(ctx, listNotebookSnapshots); err != nil {
+		return nil, fmt.Errorf("error preparing query ListNotebookSnapshots: %w", err)
+	}
+	if q.listNotebooksByTeamStmt, err = db.PrepareContext(ctx, listNotebooksByTeam); err != nil {
+		return nil, fmt.Errorf("error preparing query ListNotebooksByTeam: %w", err)
+	}
 	if q.listQueryActivityStmt, err = db.PrepareContext(ctx, listQueryActivity); err != nil {
 		return nil, fmt.Errorf("error preparing query ListQueryActivity: %w", err)
 	}
 	if q.listQueryHistoryStmt, err = db.PrepareContext(ctx, listQueryHistory); err != nil {
 		return nil, fmt.Errorf("error preparing query ListQueryHistory: %w", err)
 	}
+	if q.listQuerySnippetsByTeamStmt, err = db.PrepareContext(ctx, listQuerySnippetsByTeam); err != nil {
+		return nil, fmt.Errorf("error preparing query ListQuerySnippetsByTeam: %w", err)
+	}
+	if q.listSLOEvaluationsStmt, err = db.PrepareContext(ctx, listSLOEvaluations); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSLOEvaluations: %w", err)
+	}
+	if q.listSLOsStmt, err = db.PrepareContext(ctx, listSLOs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSLOs: %w", err)
+	}
+	if q.listSLOsByTeamStmt, err = db.PrepareContext(ctx, listSLOsByTeam); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSLOsByTeam: %w", err)
+	}
 	if q.listSavedQueriesForUserStmt, err = db.PrepareContext(ctx, listSavedQueriesForUser); err != nil {
 		return nil, fmt.Errorf("error preparing query ListSavedQueriesForUser: %w", err)
 	}
@@ -273,6 +456,18 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listServiceAccountsStmt, err = db.PrepareContext(ctx, listServiceAccounts); err != nil {
 		return nil, fmt.Errorf("error preparing query ListServiceAccounts: %w", err)
 	}
+	if q.listSourceAlertEventsStmt, err = db.PrepareContext(ctx, listSourceAlertEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSourceAlertEvents: %w", err)
+	}
+	if q.listSourceRollupsStmt, err = db.PrepareContext(ctx, listSourceRollups); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSourceRollups: %w", err)
+	}
+	if q.listSourceSchemaSnapshotsStmt, err = db.PrepareContext(ctx, listSourceSchemaSnapshots); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSourceSchemaSnapshots: %w", err)
+	}
+	if q.listSourceStatsSnapshotsStmt, err = db.PrepareContext(ctx, listSourceStatsSnapshots); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSourceStatsSnapshots: %w", err)
+	}
 	if q.listSourceTeamsStmt, err = db.PrepareContext(ctx, listSourceTeams); err != nil {
 		return nil, fmt.Errorf("error preparing query ListSourceTeams: %w", err)
 	}
@@ -303,15 +498,30 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listTeamsForUserStmt, err = db.PrepareContext(ctx, listTeamsForUser); err != nil {
 		return nil, fmt.Errorf("error preparing query ListTeamsForUser: %w", err)
 	}
+	if q.listTrackedQueriesStmt, err = db.PrepareContext(ctx, listTrackedQueries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTrackedQueries: %w", err)
+	}
+	if q.listUnexpiredAlertSilencesStmt, err = db.PrepareContext(ctx, listUnexpiredAlertSilences); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUnexpiredAlertSilences: %w", err)
+	}
+	if q.listUserSourceRolesStmt, err = db.PrepareContext(ctx, listUserSourceRoles); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUserSourceRoles: %w", err)
+	}
 	if q.listUserTeamsStmt, err = db.PrepareContext(ctx, listUserTeams); err != nil {
 		return nil, fmt.Errorf("error preparing query ListUserTeams: %w", err)
 	}
 	if q.listUsersStmt, err = db.PrepareContext(ctx, listUsers); err != nil {
 		return nil, fmt.Errorf("error preparing query ListUsers: %w", err)
 	}
+	if q.listWebhooksStmt, err = db.PrepareContext(ctx, listWebhooks); err != nil {
+		return nil, fmt.Errorf("error preparing query ListWebhooks: %w", err)
+	}
 	if q.markAlertEvaluatedStmt, err = db.PrepareContext(ctx, markAlertEvaluated); err != nil {
 		return nil, fmt.Errorf("error preparing query MarkAlertEvaluated: %w", err)
 	}
+	if q.markAlertPendingStmt, err = db.PrepareContext(ctx, markAlertPending); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkAlertPending: %w", err)
+	}
 	if q.markAlertTriggeredStmt, err = db.PrepareContext(ctx, markAlertTriggered); err != nil {
 		return nil, fmt.Errorf("error preparing query MarkAlertTriggered: %w", err)
 	}
@@ -324,6 +534,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.pruneQueryHistoryForUserStmt, err = db.PrepareContext(ctx, pruneQueryHistoryForUser); err != nil {
 		return nil, fmt.Errorf("error preparing query PruneQueryHistoryForUser: %w", err)
 	}
+	if q.pruneSLOEvaluationsStmt, err = db.PrepareContext(ctx, pruneSLOEvaluations); err != nil {
+		return nil, fmt.Errorf("error preparing query PruneSLOEvaluations: %w", err)
+	}
 	if q.queryVolumeByDayStmt, err = db.PrepareContext(ctx, queryVolumeByDay); err != nil {
 		return nil, fmt.Errorf("error preparing query QueryVolumeByDay: %w", err)
 	}
@@ -342,12 +555,18 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.resolveAlertHistoryStmt, err = db.PrepareContext(ctx, resolveAlertHistory); err != nil {
 		return nil, fmt.Errorf("error preparing query ResolveAlertHistory: %w", err)
 	}
+	if q.setAlertManagedStmt, err = db.PrepareContext(ctx, setAlertManaged); err != nil {
+		return nil, fmt.Errorf("error preparing query SetAlertManaged: %w", err)
+	}
 	if q.setSourceManagedStmt, err = db.PrepareContext(ctx, setSourceManaged); err != nil {
 		return nil, fmt.Errorf("error preparing query SetSourceManaged: %w", err)
 	}
 	if q.setTeamManagedStmt, err = db.PrepareContext(ctx, setTeamManaged); err != nil {
 		return nil, fmt.Errorf("error preparing query SetTeamManaged: %w", err)
 	}
+	if q.setTeamSourceRowFilterStmt, err = db.PrepareContext(ctx, setTeamSourceRowFilter); err != nil {
+		return nil, fmt.Errorf("error preparing query SetTeamSourceRowFilter: %w", err)
+	}
 	if q.setUserManagedStmt, err = db.PrepareContext(ctx, setUserManaged); err != nil {
 		return nil, fmt.Errorf("error preparing query SetUserManaged: %w", err)
 	}
@@ -375,6 +594,12 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.updateAlertHistoryPayloadStmt, err = db.PrepareContext(ctx, updateAlertHistoryPayload); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateAlertHistoryPayload: %w", err)
 	}
+	if q.updateAlertSilenceStmt, err = db.PrepareContext(ctx, updateAlertSilence); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateAlertSilence: %w", err)
+	}
+	if q.updateAnnotationStmt, err = db.PrepareContext(ctx, updateAnnotation); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateAnnotation: %w", err)
+	}
 	if q.updateCollectionStmt, err = db.PrepareContext(ctx, updateCollection); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateCollection: %w", err)
 	}
@@ -384,12 +609,33 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.updateExportJobRunningStmt, err = db.PrepareContext(ctx, updateExportJobRunning); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateExportJobRunning: %w", err)
 	}
+	if q.updateLogBookmarkStmt, err = db.PrepareContext(ctx, updateLogBookmark); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateLogBookmark: %w", err)
+	}
+	if q.updateNotebookStmt, err = db.PrepareContext(ctx, updateNotebook); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateNotebook: %w", err)
+	}
+	if q.updateNotebookCellsStmt, err = db.PrepareContext(ctx, updateNotebookCells); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateNotebookCells: %w", err)
+	}
+	if q.updateQuerySnippetStmt, err = db.PrepareContext(ctx, updateQuerySnippet); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateQuerySnippet: %w", err)
+	}
+	if q.updateSLOStmt, err = db.PrepareContext(ctx, updateSLO); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateSLO: %w", err)
+	}
 	if q.updateSavedQueryStmt, err = db.PrepareContext(ctx, updateSavedQuery); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateSavedQuery: %w", err)
 	}
 	if q.updateSourceStmt, err = db.PrepareContext(ctx, updateSource); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateSource: %w", err)
 	}
+	if q.updateSourceConnectionConfigStmt, err = db.PrepareContext(ctx, updateSourceConnectionConfig); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateSourceConnectionConfig: %w", err)
+	}
+	if q.updateSourceRollupProgressStmt, err = db.PrepareContext(ctx, updateSourceRollupProgress); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateSourceRollupProgress: %w", err)
+	}
 	if q.updateTeamStmt, err = db.PrepareContext(ctx, updateTeam); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateTeam: %w", err)
 	}
@@ -399,9 +645,27 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.updateUserStmt, err = db.PrepareContext(ctx, updateUser); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateUser: %w", err)
 	}
+	if q.updateWebhookStmt, err = db.PrepareContext(ctx, updateWebhook); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateWebhook: %w", err)
+	}
+	if q.upsertSourceRollupStmt, err = db.PrepareContext(ctx, upsertSourceRollup); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertSourceRollup: %w", err)
+	}
+	if q.upsertSourceStatsSnapshotStmt, err = db.PrepareContext(ctx, upsertSourceStatsSnapshot); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertSourceStatsSnapshot: %w", err)
+	}
 	if q.upsertSystemSettingStmt, err = db.PrepareContext(ctx, upsertSystemSetting); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertSystemSetting: %w", err)
 	}
+	if q.upsertTeamColumnPresetStmt, err = db.PrepareContext(ctx, upsertTeamColumnPreset); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertTeamColumnPreset: %w", err)
+	}
+	if q.upsertTrackedQueryStmt, err = db.PrepareContext(ctx, upsertTrackedQuery); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertTrackedQuery: %w", err)
+	}
+	if q.upsertUserColumnPresetStmt, err = db.PrepareContext(ctx, upsertUserColumnPreset); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertUserColumnPreset: %w", err)
+	}
 	if q.upsertUserPreferencesStmt, err = db.PrepareContext(ctx, upsertUserPreferences); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertUserPreferences: %w", err)
 	}
@@ -413,6 +677,11 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 
 func (q *Queries) Close() error {
 	var err error
+	if q.acquireAlertLeaseStmt != nil {
+		if cerr := q.acquireAlertLeaseStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing acquireAlertLeaseStmt: %w", cerr)
+		}
+	}
 	if q.addCollectionItemStmt != nil {
 		if cerr := q.addCollectionItemStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing addCollectionItemStmt: %w", cerr)
@@ -443,6 +712,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing countAdminUsersStmt: %w", cerr)
 		}
 	}
+	if q.countQueryHistoryStmt != nil {
+		if cerr := q.countQueryHistoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countQueryHistoryStmt: %w", cerr)
+		}
+	}
 	if q.countSharedCollectionEditAccessStmt != nil {
 		if cerr := q.countSharedCollectionEditAccessStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing countSharedCollectionEditAccessStmt: %w", cerr)
@@ -463,6 +737,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createAlertStmt: %w", cerr)
 		}
 	}
+	if q.createAlertSilenceStmt != nil {
+		if cerr := q.createAlertSilenceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAlertSilenceStmt: %w", cerr)
+		}
+	}
+	if q.createAnnotationStmt != nil {
+		if cerr := q.createAnnotationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAnnotationStmt: %w", cerr)
+		}
+	}
 	if q.createCollectionStmt != nil {
 		if cerr := q.createCollectionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createCollectionStmt: %w", cerr)
@@ -478,11 +762,36 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createExportJobStmt: %w", cerr)
 		}
 	}
+	if q.createLogBookmarkStmt != nil {
+		if cerr := q.createLogBookmarkStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createLogBookmarkStmt: %w", cerr)
+		}
+	}
+	if q.createNotebookStmt != nil {
+		if cerr := q.createNotebookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createNotebookStmt: %w", cerr)
+		}
+	}
+	if q.createNotebookSnapshotStmt != nil {
+		if cerr := q.createNotebookSnapshotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createNotebookSnapshotStmt: %w", cerr)
+		}
+	}
 	if q.createQueryShareStmt != nil {
 		if cerr := q.createQueryShareStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createQueryShareStmt: %w", cerr)
 		}
 	}
+	if q.createQuerySnippetStmt != nil {
+		if cerr := q.createQuerySnippetStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createQuerySnippetStmt: %w", cerr)
+		}
+	}
+	if q.createSLOStmt != nil {
+		if cerr := q.createSLOStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createSLOStmt: %w", cerr)
+		}
+	}
 	if q.createSavedQueryStmt != nil {
 		if cerr := q.createSavedQueryStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createSavedQueryStmt: %w", cerr)
@@ -508,6 +817,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createUserStmt: %w", cerr)
 		}
 	}
+	if q.createWebhookStmt != nil {
+		if cerr := q.createWebhookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWebhookStmt: %w", cerr)
+		}
+	}
 	if q.deleteAPITokenStmt != nil {
 		if cerr := q.deleteAPITokenStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteAPITokenStmt: %w", cerr)
@@ -518,6 +832,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteAlertStmt: %w", cerr)
 		}
 	}
+	if q.deleteAlertLeasesByHolderStmt != nil {
+		if cerr := q.deleteAlertLeasesByHolderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteAlertLeasesByHolderStmt: %w", cerr)
+		}
+	}
+	if q.deleteAlertSilenceStmt != nil {
+		if cerr := q.deleteAlertSilenceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteAlertSilenceStmt: %w", cerr)
+		}
+	}
+	if q.deleteAnnotationStmt != nil {
+		if cerr := q.deleteAnnotationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteAnnotationStmt: %w", cerr)
+		}
+	}
 	if q.deleteCollectionStmt != nil {
 		if cerr := q.deleteCollectionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteCollectionStmt: %w", cerr)
@@ -538,11 +867,41 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteExpiredSessionsStmt: %w", cerr)
 		}
 	}
+	if q.deleteLogBookmarkStmt != nil {
+		if cerr := q.deleteLogBookmarkStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteLogBookmarkStmt: %w", cerr)
+		}
+	}
+	if q.deleteNotebookStmt != nil {
+		if cerr := q.deleteNotebookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteNotebookStmt: %w", cerr)
+		}
+	}
+	if q.deleteNotebookSnapshotStmt != nil {
+		if cerr := q.deleteNotebookSnapshotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteNotebookSnapshotStmt: %w", cerr)
+		}
+	}
+	if q.deleteQueryHistoryBeforeStmt != nil {
+		if cerr := q.deleteQueryHistoryBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteQueryHistoryBeforeStmt: %w", cerr)
+		}
+	}
 	if q.deleteQueryShareStmt != nil {
 		if cerr := q.deleteQueryShareStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteQueryShareStmt: %w", cerr)
 		}
 	}
+	if q.deleteQuerySnippetStmt != nil {
+		if cerr := q.deleteQuerySnippetStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteQuerySnippetStmt: %w", cerr)
+		}
+	}
+	if q.deleteSLOStmt != nil {
+		if cerr := q.deleteSLOStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteSLOStmt: %w", cerr)
+		}
+	}
 	if q.deleteSavedQueryStmt != nil {
 		if cerr := q.deleteSavedQueryStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteSavedQueryStmt: %w", cerr)
@@ -558,6 +917,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteSourceStmt: %w", cerr)
 		}
 	}
+	if q.deleteSourceAlertEventsBeforeStmt != nil {
+		if cerr := q.deleteSourceAlertEventsBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteSourceAlertEventsBeforeStmt: %w", cerr)
+		}
+	}
+	if q.deleteSourceRollupStmt != nil {
+		if cerr := q.deleteSourceRollupStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteSourceRollupStmt: %w", cerr)
+		}
+	}
+	if q.deleteSourceSchemaSnapshotsBeforeStmt != nil {
+		if cerr := q.deleteSourceSchemaSnapshotsBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteSourceSchemaSnapshotsBeforeStmt: %w", cerr)
+		}
+	}
+	if q.deleteSourceStatsSnapshotsBeforeStmt != nil {
+		if cerr := q.deleteSourceStatsSnapshotsBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteSourceStatsSnapshotsBeforeStmt: %w", cerr)
+		}
+	}
 	if q.deleteSystemSettingStmt != nil {
 		if cerr := q.deleteSystemSettingStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteSystemSettingStmt: %w", cerr)
@@ -568,16 +947,41 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteTeamStmt: %w", cerr)
 		}
 	}
+	if q.deleteTeamColumnPresetStmt != nil {
+		if cerr := q.deleteTeamColumnPresetStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteTeamColumnPresetStmt: %w", cerr)
+		}
+	}
+	if q.deleteTrackedQueriesBeforeStmt != nil {
+		if cerr := q.deleteTrackedQueriesBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteTrackedQueriesBeforeStmt: %w", cerr)
+		}
+	}
+	if q.deleteTrackedQueryStmt != nil {
+		if cerr := q.deleteTrackedQueryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteTrackedQueryStmt: %w", cerr)
+		}
+	}
 	if q.deleteUserStmt != nil {
 		if cerr := q.deleteUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteUserStmt: %w", cerr)
 		}
 	}
+	if q.deleteUserColumnPresetStmt != nil {
+		if cerr := q.deleteUserColumnPresetStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteUserColumnPresetStmt: %w", cerr)
+		}
+	}
 	if q.deleteUserSessionsStmt != nil {
 		if cerr := q.deleteUserSessionsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteUserSessionsStmt: %w", cerr)
 		}
 	}
+	if q.deleteWebhookStmt != nil {
+		if cerr := q.deleteWebhookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteWebhookStmt: %w", cerr)
+		}
+	}
 	if q.failExportJobStmt != nil {
 		if cerr := q.failExportJobStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing failExportJobStmt: %w", cerr)
@@ -598,6 +1002,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getAlertStmt: %w", cerr)
 		}
 	}
+	if q.getAlertSilenceStmt != nil {
+		if cerr := q.getAlertSilenceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAlertSilenceStmt: %w", cerr)
+		}
+	}
+	if q.getAnnotationStmt != nil {
+		if cerr := q.getAnnotationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAnnotationStmt: %w", cerr)
+		}
+	}
 	if q.getCollectionStmt != nil {
 		if cerr := q.getCollectionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getCollectionStmt: %w", cerr)
@@ -618,11 +1032,31 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getExportJobStmt: %w", cerr)
 		}
 	}
+	if q.getLatestSourceSchemaSnapshotStmt != nil {
+		if cerr := q.getLatestSourceSchemaSnapshotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLatestSourceSchemaSnapshotStmt: %w", cerr)
+		}
+	}
 	if q.getLatestUnresolvedAlertHistoryStmt != nil {
 		if cerr := q.getLatestUnresolvedAlertHistoryStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getLatestUnresolvedAlertHistoryStmt: %w", cerr)
 		}
 	}
+	if q.getLogBookmarkStmt != nil {
+		if cerr := q.getLogBookmarkStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLogBookmarkStmt: %w", cerr)
+		}
+	}
+	if q.getNotebookStmt != nil {
+		if cerr := q.getNotebookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getNotebookStmt: %w", cerr)
+		}
+	}
+	if q.getNotebookSnapshotStmt != nil {
+		if cerr := q.getNotebookSnapshotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getNotebookSnapshotStmt: %w", cerr)
+		}
+	}
 	if q.getPersonalCollectionStmt != nil {
 		if cerr := q.getPersonalCollectionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getPersonalCollectionStmt: %w", cerr)
@@ -633,6 +1067,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getQueryShareStmt: %w", cerr)
 		}
 	}
+	if q.getQuerySnippetStmt != nil {
+		if cerr := q.getQuerySnippetStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getQuerySnippetStmt: %w", cerr)
+		}
+	}
+	if q.getSLOStmt != nil {
+		if cerr := q.getSLOStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSLOStmt: %w", cerr)
+		}
+	}
 	if q.getSavedQueryStmt != nil {
 		if cerr := q.getSavedQueryStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getSavedQueryStmt: %w", cerr)
@@ -658,6 +1102,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getSourceByNameForProvisioningStmt: %w", cerr)
 		}
 	}
+	if q.getSourceRollupStmt != nil {
+		if cerr := q.getSourceRollupStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSourceRollupStmt: %w", cerr)
+		}
+	}
 	if q.getSystemSettingStmt != nil {
 		if cerr := q.getSystemSettingStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getSystemSettingStmt: %w", cerr)
@@ -673,11 +1122,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getTeamByNameStmt: %w", cerr)
 		}
 	}
+	if q.getTeamColumnPresetStmt != nil {
+		if cerr := q.getTeamColumnPresetStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTeamColumnPresetStmt: %w", cerr)
+		}
+	}
 	if q.getTeamMemberStmt != nil {
 		if cerr := q.getTeamMemberStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getTeamMemberStmt: %w", cerr)
 		}
 	}
+	if q.getTeamQueryUsageStmt != nil {
+		if cerr := q.getTeamQueryUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTeamQueryUsageStmt: %w", cerr)
+		}
+	}
+	if q.getTeamSourceRowFilterStmt != nil {
+		if cerr := q.getTeamSourceRowFilterStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTeamSourceRowFilterStmt: %w", cerr)
+		}
+	}
 	if q.getUserStmt != nil {
 		if cerr := q.getUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getUserStmt: %w", cerr)
@@ -688,6 +1152,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getUserByEmailStmt: %w", cerr)
 		}
 	}
+	if q.getUserColumnPresetStmt != nil {
+		if cerr := q.getUserColumnPresetStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserColumnPresetStmt: %w", cerr)
+		}
+	}
 	if q.getUserPreferencesStmt != nil {
 		if cerr := q.getUserPreferencesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getUserPreferencesStmt: %w", cerr)
@@ -698,6 +1167,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getUserTeamForSourceStmt: %w", cerr)
 		}
 	}
+	if q.getWebhookStmt != nil {
+		if cerr := q.getWebhookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWebhookStmt: %w", cerr)
+		}
+	}
 	if q.incrementQueryStatsStmt != nil {
 		if cerr := q.incrementQueryStatsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing incrementQueryStatsStmt: %w", cerr)
@@ -708,11 +1182,36 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing insertAlertHistoryStmt: %w", cerr)
 		}
 	}
+	if q.insertAuditEventStmt != nil {
+		if cerr := q.insertAuditEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertAuditEventStmt: %w", cerr)
+		}
+	}
 	if q.insertQueryHistoryStmt != nil {
 		if cerr := q.insertQueryHistoryStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing insertQueryHistoryStmt: %w", cerr)
 		}
 	}
+	if q.insertSLOEvaluationStmt != nil {
+		if cerr := q.insertSLOEvaluationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertSLOEvaluationStmt: %w", cerr)
+		}
+	}
+	if q.insertSourceAlertEventStmt != nil {
+		if cerr := q.insertSourceAlertEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertSourceAlertEventStmt: %w", cerr)
+		}
+	}
+	if q.insertSourceSchemaSnapshotStmt != nil {
+		if cerr := q.insertSourceSchemaSnapshotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertSourceSchemaSnapshotStmt: %w", cerr)
+		}
+	}
+	if q.isAlertManagedStmt != nil {
+		if cerr := q.isAlertManagedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing isAlertManagedStmt: %w", cerr)
+		}
+	}
 	if q.isSourceManagedStmt != nil {
 		if cerr := q.isSourceManagedStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing isSourceManagedStmt: %w", cerr)
@@ -748,6 +1247,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listAlertHistoryStmt: %w", cerr)
 		}
 	}
+	if q.listAlertHistorySinceStmt != nil {
+		if cerr := q.listAlertHistorySinceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAlertHistorySinceStmt: %w", cerr)
+		}
+	}
+	if q.listAlertSilencesStmt != nil {
+		if cerr := q.listAlertSilencesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAlertSilencesStmt: %w", cerr)
+		}
+	}
 	if q.listAlertsBySourceStmt != nil {
 		if cerr := q.listAlertsBySourceStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listAlertsBySourceStmt: %w", cerr)
@@ -763,6 +1272,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listAllSavedQueriesStmt: %w", cerr)
 		}
 	}
+	if q.listAnnotationsByTeamStmt != nil {
+		if cerr := q.listAnnotationsByTeamStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAnnotationsByTeamStmt: %w", cerr)
+		}
+	}
+	if q.listAnnotationsInRangeStmt != nil {
+		if cerr := q.listAnnotationsInRangeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAnnotationsInRangeStmt: %w", cerr)
+		}
+	}
+	if q.listAuditEventsStmt != nil {
+		if cerr := q.listAuditEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAuditEventsStmt: %w", cerr)
+		}
+	}
 	if q.listCollectionItemsStmt != nil {
 		if cerr := q.listCollectionItemsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listCollectionItemsStmt: %w", cerr)
@@ -788,6 +1312,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listExpiredExportJobPathsStmt: %w", cerr)
 		}
 	}
+	if q.listExpiredNotebookSnapshotsStmt != nil {
+		if cerr := q.listExpiredNotebookSnapshotsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listExpiredNotebookSnapshotsStmt: %w", cerr)
+		}
+	}
+	if q.listLogBookmarksForUserStmt != nil {
+		if cerr := q.listLogBookmarksForUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listLogBookmarksForUserStmt: %w", cerr)
+		}
+	}
+	if q.listManagedAlertsStmt != nil {
+		if cerr := q.listManagedAlertsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listManagedAlertsStmt: %w", cerr)
+		}
+	}
 	if q.listManagedSourcesStmt != nil {
 		if cerr := q.listManagedSourcesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listManagedSourcesStmt: %w", cerr)
@@ -803,6 +1342,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listManagedUsersStmt: %w", cerr)
 		}
 	}
+	if q.listNotebookSnapshotsStmt != nil {
+		if cerr := q.listNotebookSnapshotsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listNotebookSnapshotsStmt: %w", cerr)
+		}
+	}
+	if q.listNotebooksByTeamStmt != nil {
+		if cerr := q.listNotebooksByTeamStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listNotebooksByTeamStmt: %w", cerr)
+		}
+	}
 	if q.listQueryActivityStmt != nil {
 		if cerr := q.listQueryActivityStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listQueryActivityStmt: %w", cerr)
@@ -813,6 +1362,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listQueryHistoryStmt: %w", cerr)
 		}
 	}
+	if q.listQuerySnippetsByTeamStmt != nil {
+		if cerr := q.listQuerySnippetsByTeamStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listQuerySnippetsByTeamStmt: %w", cerr)
+		}
+	}
+	if q.listSLOEvaluationsStmt != nil {
+		if cerr := q.listSLOEvaluationsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSLOEvaluationsStmt: %w", cerr)
+		}
+	}
+	if q.listSLOsStmt != nil {
+		if cerr := q.listSLOsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSLOsStmt: %w", cerr)
+		}
+	}
+	if q.listSLOsByTeamStmt != nil {
+		if cerr := q.listSLOsByTeamStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSLOsByTeamStmt: %w", cerr)
+		}
+	}
 	if q.listSavedQueriesForUserStmt != nil {
 		if cerr := q.listSavedQueriesForUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listSavedQueriesForUserStmt: %w", cerr)
@@ -828,6 +1397,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listServiceAccountsStmt: %w", cerr)
 		}
 	}
+	if q.listSourceAlertEventsStmt != nil {
+		if cerr := q.listSourceAlertEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSourceAlertEventsStmt: %w", cerr)
+		}
+	}
+	if q.listSourceRollupsStmt != nil {
+		if cerr := q.listSourceRollupsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSourceRollupsStmt: %w", cerr)
+		}
+	}
+	if q.listSourceSchemaSnapshotsStmt != nil {
+		if cerr := q.listSourceSchemaSnapshotsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSourceSchemaSnapshotsStmt: %w", cerr)
+		}
+	}
+	if q.listSourceStatsSnapshotsStmt != nil {
+		if cerr := q.listSourceStatsSnapshotsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSourceStatsSnapshotsStmt: %w", cerr)
+		}
+	}
 	if q.listSourceTeamsStmt != nil {
 		if cerr := q.listSourceTeamsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listSourceTeamsStmt: %w", cerr)
@@ -878,6 +1467,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listTeamsForUserStmt: %w", cerr)
 		}
 	}
+	if q.listTrackedQueriesStmt != nil {
+		if cerr := q.listTrackedQueriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTrackedQueriesStmt: %w", cerr)
+		}
+	}
+	if q.listUnexpiredAlertSilencesStmt != nil {
+		if cerr := q.listUnexpiredAlertSilencesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUnexpiredAlertSilencesStmt: %w", cerr)
+		}
+	}
+	if q.listUserSourceRolesStmt != nil {
+		if cerr := q.listUserSourceRolesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUserSourceRolesStmt: %w", cerr)
+		}
+	}
 	if q.listUserTeamsStmt != nil {
 		if cerr := q.listUserTeamsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listUserTeamsStmt: %w", cerr)
@@ -888,11 +1492,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listUsersStmt: %w", cerr)
 		}
 	}
+	if q.listWebhooksStmt != nil {
+		if cerr := q.listWebhooksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listWebhooksStmt: %w", cerr)
+		}
+	}
 	if q.markAlertEvaluatedStmt != nil {
 		if cerr := q.markAlertEvaluatedStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing markAlertEvaluatedStmt: %w", cerr)
 		}
 	}
+	if q.markAlertPendingStmt != nil {
+		if cerr := q.markAlertPendingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markAlertPendingStmt: %w", cerr)
+		}
+	}
 	if q.markAlertTriggeredStmt != nil {
 		if cerr := q.markAlertTriggeredStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing markAlertTriggeredStmt: %w", cerr)
@@ -913,6 +1527,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing pruneQueryHistoryForUserStmt: %w", cerr)
 		}
 	}
+	if q.pruneSLOEvaluationsStmt != nil {
+		if cerr := q.pruneSLOEvaluationsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing pruneSLOEvaluationsStmt: %w", cerr)
+		}
+	}
 	if q.queryVolumeByDayStmt != nil {
 		if cerr := q.queryVolumeByDayStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing queryVolumeByDayStmt: %w", cerr)
@@ -943,6 +1562,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing resolveAlertHistoryStmt: %w", cerr)
 		}
 	}
+	if q.setAlertManagedStmt != nil {
+		if cerr := q.setAlertManagedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setAlertManagedStmt: %w", cerr)
+		}
+	}
 	if q.setSourceManagedStmt != nil {
 		if cerr := q.setSourceManagedStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing setSourceManagedStmt: %w", cerr)
@@ -953,6 +1577,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing setTeamManagedStmt: %w", cerr)
 		}
 	}
+	if q.setTeamSourceRowFilterStmt != nil {
+		if cerr := q.setTeamSourceRowFilterStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setTeamSourceRowFilterStmt: %w", cerr)
+		}
+	}
 	if q.setUserManagedStmt != nil {
 		if cerr := q.setUserManagedStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing setUserManagedStmt: %w", cerr)
@@ -998,6 +1627,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateAlertHistoryPayloadStmt: %w", cerr)
 		}
 	}
+	if q.updateAlertSilenceStmt != nil {
+		if cerr := q.updateAlertSilenceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateAlertSilenceStmt: %w", cerr)
+		}
+	}
+	if q.updateAnnotationStmt != nil {
+		if cerr := q.updateAnnotationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateAnnotationStmt: %w", cerr)
+		}
+	}
 	if q.updateCollectionStmt != nil {
 		if cerr := q.updateCollectionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateCollectionStmt: %w", cerr)
@@ -1013,6 +1652,31 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateExportJobRunningStmt: %w", cerr)
 		}
 	}
+	if q.updateLogBookmarkStmt != nil {
+		if cerr := q.updateLogBookmarkStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateLogBookmarkStmt: %w", cerr)
+		}
+	}
+	if q.updateNotebookStmt != nil {
+		if cerr := q.updateNotebookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateNotebookStmt: %w", cerr)
+		}
+	}
+	if q.updateNotebookCellsStmt != nil {
+		if cerr := q.updateNotebookCellsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateNotebookCellsStmt: %w", cerr)
+		}
+	}
+	if q.updateQuerySnippetStmt != nil {
+		if cerr := q.updateQuerySnippetStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateQuerySnippetStmt: %w", cerr)
+		}
+	}
+	if q.updateSLOStmt != nil {
+		if cerr := q.updateSLOStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateSLOStmt: %w", cerr)
+		}
+	}
 	if q.updateSavedQueryStmt != nil {
 		if cerr := q.updateSavedQueryStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateSavedQueryStmt: %w", cerr)
@@ -1023,6 +1687,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateSourceStmt: %w", cerr)
 		}
 	}
+	if q.updateSourceConnectionConfigStmt != nil {
+		if cerr := q.updateSourceConnectionConfigStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateSourceConnectionConfigStmt: %w", cerr)
+		}
+	}
+	if q.updateSourceRollupProgressStmt != nil {
+		if cerr := q.updateSourceRollupProgressStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateSourceRollupProgressStmt: %w", cerr)
+		}
+	}
 	if q.updateTeamStmt != nil {
 		if cerr := q.updateTeamStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateTeamStmt: %w", cerr)
@@ -1038,11 +1712,41 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateUserStmt: %w", cerr)
 		}
 	}
+	if q.updateWebhookStmt != nil {
+		if cerr := q.updateWebhookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateWebhookStmt: %w", cerr)
+		}
+	}
+	if q.upsertSourceRollupStmt != nil {
+		if cerr := q.upsertSourceRollupStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertSourceRollupStmt: %w", cerr)
+		}
+	}
+	if q.upsertSourceStatsSnapshotStmt != nil {
+		if cerr := q.upsertSourceStatsSnapshotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertSourceStatsSnapshotStmt: %w", cerr)
+		}
+	}
 	if q.upsertSystemSettingStmt != nil {
 		if cerr := q.upsertSystemSettingStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing upsertSystemSettingStmt: %w", cerr)
 		}
 	}
+	if q.upsertTeamColumnPresetStmt != nil {
+		if cerr := q.upsertTeamColumnPresetStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertTeamColumnPresetStmt: %w", cerr)
+		}
+	}
+	if q.upsertTrackedQueryStmt != nil {
+		if cerr := q.upsertTrackedQueryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertTrackedQueryStmt: %w", cerr)
+		}
+	}
+	if q.upsertUserColumnPresetStmt != nil {
+		if cerr := q.upsertUserColumnPresetStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertUserColumnPresetStmt: %w", cerr)
+		}
+	}
 	if q.upsertUserPreferencesStmt != nil {
 		if cerr := q.upsertUserPreferencesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing upsertUserPreferencesStmt: %w", cerr)
@@ -1090,269 +1794,445 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                                  DBTX
-	tx                                  *sql.Tx
-	addCollectionItemStmt               *sql.Stmt
-	addCollectionMemberStmt             *sql.Stmt
-	addTeamMemberStmt                   *sql.Stmt
-	addTeamSourceStmt                   *sql.Stmt
-	completeExportJobStmt               *sql.Stmt
-	countAdminUsersStmt                 *sql.Stmt
-	countSharedCollectionEditAccessStmt *sql.Stmt
-	countUserSessionsStmt               *sql.Stmt
-	createAPITokenStmt                  *sql.Stmt
-	createAlertStmt                     *sql.Stmt
-	createCollectionStmt                *sql.Stmt
-	createDashboardStmt                 *sql.Stmt
-	createExportJobStmt                 *sql.Stmt
-	createQueryShareStmt                *sql.Stmt
-	createSavedQueryStmt                *sql.Stmt
-	createSessionStmt                   *sql.Stmt
-	createSourceStmt                    *sql.Stmt
-	createTeamStmt                      *sql.Stmt
-	createUserStmt                      *sql.Stmt
-	deleteAPITokenStmt                  *sql.Stmt
-	deleteAlertStmt                     *sql.Stmt
-	deleteCollectionStmt                *sql.Stmt
-	deleteDashboardStmt                 *sql.Stmt
-	deleteExpiredExportJobsStmt         *sql.Stmt
-	deleteExpiredSessionsStmt           *sql.Stmt
-	deleteQueryShareStmt                *sql.Stmt
-	deleteSavedQueryStmt                *sql.Stmt
-	deleteSessionStmt                   *sql.Stmt
-	deleteSourceStmt                    *sql.Stmt
-	deleteSystemSettingStmt             *sql.Stmt
-	deleteTeamStmt                      *sql.Stmt
-	deleteUserStmt                      *sql.Stmt
-	deleteUserSessionsStmt              *sql.Stmt
-	failExportJobStmt                   *sql.Stmt
-	getAPITokenStmt                     *sql.Stmt
-	getAPITokenByHashStmt               *sql.Stmt
-	getAlertStmt                        *sql.Stmt
-	getCollectionStmt                   *sql.Stmt
-	getCollectionMemberStmt             *sql.Stmt
-	getDashboardStmt                    *sql.Stmt
-	getExportJobStmt                    *sql.Stmt
-	getLatestUnresolvedAlertHistoryStmt *sql.Stmt
-	getPersonalCollectionStmt           *sql.Stmt
-	getQueryShareStmt                   *sql.Stmt
-	getSavedQueryStmt                   *sql.Stmt
-	getSessionStmt                      *sql.Stmt
-	getSourceStmt                       *sql.Stmt
-	getSourceByIdentityKeyStmt          *sql.Stmt
-	getSourceByNameForProvisioningStmt  *sql.Stmt
-	getSystemSettingStmt                *sql.Stmt
-	getTeamStmt                         *sql.Stmt
-	getTeamByNameStmt                   *sql.Stmt
-	getTeamMemberStmt                   *sql.Stmt
-	getUserStmt                         *sql.Stmt
-	getUserByEmailStmt                  *sql.Stmt
-	getUserPreferencesStmt              *sql.Stmt
-	getUserTeamForSourceStmt            *sql.Stmt
-	incrementQueryStatsStmt             *sql.Stmt
-	insertAlertHistoryStmt              *sql.Stmt
-	insertQueryHistoryStmt              *sql.Stmt
-	isSourceManagedStmt                 *sql.Stmt
-	isTeamManagedStmt                   *sql.Stmt
-	isUserManagedStmt                   *sql.Stmt
-	listAPITokensForUserStmt            *sql.Stmt
-	listAccessibleSourceIDsForUserStmt  *sql.Stmt
-	listActiveAlertsDueStmt             *sql.Stmt
-	listAlertHistoryStmt                *sql.Stmt
-	listAlertsBySourceStmt              *sql.Stmt
-	listAlertsForUserStmt               *sql.Stmt
-	listAllSavedQueriesStmt             *sql.Stmt
-	listCollectionItemsStmt             *sql.Stmt
-	listCollectionMembersStmt           *sql.Stmt
-	listCollectionsForUserStmt          *sql.Stmt
-	listDashboardsStmt                  *sql.Stmt
-	listExpiredExportJobPathsStmt       *sql.Stmt
-	listManagedSourcesStmt              *sql.Stmt
-	listManagedTeamsStmt                *sql.Stmt
-	listManagedUsersStmt                *sql.Stmt
-	listQueryActivityStmt               *sql.Stmt
-	listQueryHistoryStmt                *sql.Stmt
-	listSavedQueriesForUserStmt         *sql.Stmt
-	listSavedQueriesForUserBySourceStmt *sql.Stmt
-	listServiceAccountsStmt             *sql.Stmt
-	listSourceTeamsStmt                 *sql.Stmt
-	listSourcesStmt                     *sql.Stmt
-	listSourcesForUserStmt              *sql.Stmt
-	listSystemSettingsStmt              *sql.Stmt
-	listSystemSettingsByCategoryStmt    *sql.Stmt
-	listTeamMembersStmt                 *sql.Stmt
-	listTeamMembersWithDetailsStmt      *sql.Stmt
-	listTeamSourcesStmt                 *sql.Stmt
-	listTeamsStmt                       *sql.Stmt
-	listTeamsForUserStmt                *sql.Stmt
-	listUserTeamsStmt                   *sql.Stmt
-	listUsersStmt                       *sql.Stmt
-	markAlertEvaluatedStmt              *sql.Stmt
-	markAlertTriggeredStmt              *sql.Stmt
-	pruneAlertHistoryStmt               *sql.Stmt
-	pruneExpiredQuerySharesStmt         *sql.Stmt
-	pruneQueryHistoryForUserStmt        *sql.Stmt
-	queryVolumeByDayStmt                *sql.Stmt
-	removeCollectionItemStmt            *sql.Stmt
-	removeCollectionMemberStmt          *sql.Stmt
-	removeTeamMemberStmt                *sql.Stmt
-	removeTeamSourceStmt                *sql.Stmt
-	resolveAlertHistoryStmt             *sql.Stmt
-	setSourceManagedStmt                *sql.Stmt
-	setTeamManagedStmt                  *sql.Stmt
-	setUserManagedStmt                  *sql.Stmt
-	setUserPasswordHashStmt             *sql.Stmt
-	teamHasSourceStmt                   *sql.Stmt
-	topSourcesByQueriesStmt             *sql.Stmt
-	topUsersByQueriesStmt               *sql.Stmt
-	touchQueryShareStmt                 *sql.Stmt
-	updateAPITokenLastUsedStmt          *sql.Stmt
-	updateAlertStmt                     *sql.Stmt
-	updateAlertHistoryPayloadStmt       *sql.Stmt
-	updateCollectionStmt                *sql.Stmt
-	updateDashboardStmt                 *sql.Stmt
-	updateExportJobRunningStmt          *sql.Stmt
-	updateSavedQueryStmt                *sql.Stmt
-	updateSourceStmt                    *sql.Stmt
-	updateTeamStmt                      *sql.Stmt
-	updateTeamMemberRoleStmt            *sql.Stmt
-	updateUserStmt                      *sql.Stmt
-	upsertSystemSettingStmt             *sql.Stmt
-	upsertUserPreferencesStmt           *sql.Stmt
-	userHasSourceAccessStmt             *sql.Stmt
+	db                                    DBTX
+	tx                                    *sql.Tx
+	acquireAlertLeaseStmt                 *sql.Stmt
+	addCollectionItemStmt                 *sql.Stmt
+	addCollectionMemberStmt               *sql.Stmt
+	addTeamMemberStmt                     *sql.Stmt
+	addTeamSourceStmt                     *sql.Stmt
+	completeExportJobStmt                 *sql.Stmt
+	countAdminUsersStmt                   *sql.Stmt
+	countQueryHistoryStmt                 *sql.Stmt
+	countSharedCollectionEditAccessStmt   *sql.Stmt
+	countUserSessionsStmt                 *sql.Stmt
+	createAPITokenStmt                    *sql.Stmt
+	createAlertStmt                       *sql.Stmt
+	createAlertSilenceStmt                *sql.Stmt
+	createAnnotationStmt                  *sql.Stmt
+	createCollectionStmt                  *sql.Stmt
+	createDashboardStmt                   *sql.Stmt
+	createExportJobStmt                   *sql.Stmt
+	createLogBookmarkStmt                 *sql.Stmt
+	createNotebookStmt                    *sql.Stmt
+	createNotebookSnapshotStmt            *sql.Stmt
+	createQueryShareStmt                  *sql.Stmt
+	createQuerySnippetStmt                *sql.Stmt
+	createSLOStmt                         *sql.Stmt
+	createSavedQueryStmt                  *sql.Stmt
+	createSessionStmt                     *sql.Stmt
+	createSourceStmt                      *sql.Stmt
+	createTeamStmt                        *sql.Stmt
+	createUserStmt                        *sql.Stmt
+	createWebhookStmt                     *sql.Stmt
+	deleteAPITokenStmt                    *sql.Stmt
+	deleteAlertStmt                       *sql.Stmt
+	deleteAlertLeasesByHolderStmt         *sql.Stmt
+	deleteAlertSilenceStmt                *sql.Stmt
+	deleteAnnotationStmt                  *sql.Stmt
+	deleteCollectionStmt                  *sql.Stmt
+	deleteDashboardStmt                   *sql.Stmt
+	deleteExpiredExportJobsStmt           *sql.Stmt
+	deleteExpiredSessionsStmt             *sql.Stmt
+	deleteLogBookmarkStmt                 *sql.Stmt
+	deleteNotebookStmt                    *sql.Stmt
+	deleteNotebookSnapshotStmt            *sql.Stmt
+	deleteQueryHistoryBeforeStmt          *sql.Stmt
+	deleteQueryShareStmt                  *sql.Stmt
+	deleteQuerySnippetStmt                *sql.Stmt
+	deleteSLOStmt                         *sql.Stmt
+	deleteSavedQueryStmt                  *sql.Stmt
+	deleteSessionStmt                     *sql.Stmt
+	deleteSourceStmt                      *sql.Stmt
+	deleteSourceAlertEventsBeforeStmt     *sql.Stmt
+	deleteSourceRollupStmt                *sql.Stmt
+	deleteSourceSchemaSnapshotsBeforeStmt *sql.Stmt
+	deleteSourceStatsSnapshotsBeforeStmt  *sql.Stmt
+	deleteSystemSettingStmt               *sql.Stmt
+	deleteTeamStmt                        *sql.Stmt
+	deleteTeamColumnPresetStmt            *sql.Stmt
+	deleteTrackedQueriesBeforeStmt        *sql.Stmt
+	deleteTrackedQueryStmt                *sql.Stmt
+	deleteUserStmt                        *sql.Stmt
+	deleteUserColumnPresetStmt            *sql.Stmt
+	deleteUserSessionsStmt                *sql.Stmt
+	deleteWebhookStmt                     *sql.Stmt
+	failExportJobStmt                     *sql.Stmt
+	getAPITokenStmt                       *sql.Stmt
+	getAPITokenByHashStmt                 *sql.Stmt
+	getAlertStmt                          *sql.Stmt
+	getAlertSilenceStmt                   *sql.Stmt
+	getAnnotationStmt                     *sql.Stmt
+	getCollectionStmt                     *sql.Stmt
+	getCollectionMemberStmt               *sql.Stmt
+	getDashboardStmt                      *sql.Stmt
+	getExportJobStmt                      *sql.Stmt
+	getLatestSourceSchemaSnapshotStmt     *sql.Stmt
+	getLatestUnresolvedAlertHistoryStmt   *sql.Stmt
+	getLogBookmarkStmt                    *sql.Stmt
+	getNotebookStmt                       *sql.Stmt
+	getNotebookSnapshotStmt               *sql.Stmt
+	getPersonalCollectionStmt             *sql.Stmt
+	getQueryShareStmt                     *sql.Stmt
+	getQuerySnippetStmt                   *sql.Stmt
+	getSLOStmt                            *sql.Stmt
+	getSavedQueryStmt                     *sql.Stmt
+	getSessionStmt                        *sql.Stmt
+	getSourceStmt                         *sql.Stmt
+	getSourceByIdentityKeyStmt            *sql.Stmt
+	getSourceByNameForProvisioningStmt    *sql.Stmt
+	getSourceRollupStmt                   *sql.Stmt
+	getSystemSettingStmt                  *sql.Stmt
+	getTeamStmt                           *sql.Stmt
+	getTeamByNameStmt                     *sql.Stmt
+	getTeamColumnPresetStmt               *sql.Stmt
+	getTeamMemberStmt                     *sql.Stmt
+	getTeamQueryUsageStmt                 *sql.Stmt
+	getTeamSourceRowFilterStmt            *sql.Stmt
+	getUserStmt                           *sql.Stmt
+	getUserByEmailStmt                    *sql.Stmt
+	getUserColumnPresetStmt               *sql.Stmt
+	getUserPreferencesStmt                *sql.Stmt
+	getUserTeamForSourceStmt              *sql.Stmt
+	getWebhookStmt                        *sql.Stmt
+	incrementQueryStatsStmt               *sql.Stmt
+	insertAlertHistoryStmt                *sql.Stmt
+	insertAuditEventStmt                  *sql.Stmt
+	insertQueryHistoryStmt                *sql.Stmt
+	insertSLOEvaluationStmt               *sql.Stmt
+	insertSourceAlertEventStmt            *sql.Stmt
+	insertSourceSchemaSnapshotStmt        *sql.Stmt
+	isAlertManagedStmt                    *sql.Stmt
+	isSourceManagedStmt                   *sql.Stmt
+	isTeamManagedStmt                     *sql.Stmt
+	isUserManagedStmt                     *sql.Stmt
+	listAPITokensForUserStmt              *sql.Stmt
+	listAccessibleSourceIDsForUserStmt    *sql.Stmt
+	listActiveAlertsDueStmt               *sql.Stmt
+	listAlertHistoryStmt                  *sql.Stmt
+	listAlertHistorySinceStmt             *sql.Stmt
+	listAlertSilencesStmt                 *sql.Stmt
+	listAlertsBySourceStmt                *sql.Stmt
+	listAlertsForUserStmt                 *sql.Stmt
+	listAllSavedQueriesStmt               *sql.Stmt
+	listAnnotationsByTeamStmt             *sql.Stmt
+	listAnnotationsInRangeStmt            *sql.Stmt
+	listAuditEventsStmt                   *sql.Stmt
+	listCollectionItemsStmt               *sql.Stmt
+	listCollectionMembersStmt             *sql.Stmt
+	listCollectionsForUserStmt            *sql.Stmt
+	listDashboardsStmt                    *sql.Stmt
+	listExpiredExportJobPathsStmt         *sql.Stmt
+	listExpiredNotebookSnapshotsStmt      *sql.Stmt
+	listLogBookmarksForUserStmt           *sql.Stmt
+	listManagedAlertsStmt                 *sql.Stmt
+	listManagedSourcesStmt                *sql.Stmt
+	listManagedTeamsStmt                  *sql.Stmt
+	listManagedUsersStmt                  *sql.Stmt
+	listNotebookSnapshotsStmt             *sql.Stmt
+	listNotebooksByTeamStmt               *sql.Stmt
+	listQueryActivityStmt                 *sql.Stmt
+	listQueryHistoryStmt                  *sql.Stmt
+	listQuerySnippetsByTeamStmt           *sql.Stmt
+	listSLOEvaluationsStmt                *sql.Stmt
+	listSLOsStmt                          *sql.Stmt
+	listSLOsByTeamStmt                    *sql.Stmt
+	listSavedQueriesForUserStmt           *sql.Stmt
+	listSavedQueriesForUserBySourceStmt   *sql.Stmt
+	listServiceAccountsStmt               *sql.Stmt
+	listSourceAlertEventsStmt             *sql.Stmt
+	listSourceRollupsStmt                 *sql.Stmt
+	listSourceSchemaSnapshotsStmt         *sql.Stmt
+	listSourceStatsSnapshotsStmt          *sql.Stmt
+	listSourceTeamsStmt                   *sql.Stmt
+	listSourcesStmt                       *sql.Stmt
+	listSourcesForUserStmt                *sql.Stmt
+	listSystemSettingsStmt                *sql.Stmt
+	listSystemSettingsByCategoryStmt      *sql.Stmt
+	listTeamMembersStmt                   *sql.Stmt
+	listTeamMembersWithDetailsStmt        *sql.Stmt
+	listTeamSourcesStmt                   *sql.Stmt
+	listTeamsStmt                         *sql.Stmt
+	listTeamsForUserStmt                  *sql.Stmt
+	listTrackedQueriesStmt                *sql.Stmt
+	listUnexpiredAlertSilencesStmt        *sql.Stmt
+	listUserSourceRolesStmt               *sql.Stmt
+	listUserTeamsStmt                     *sql.Stmt
+	listUsersStmt                         *sql.Stmt
+	listWebhooksStmt                      *sql.Stmt
+	markAlertEvaluatedStmt                *sql.Stmt
+	markAlertPendingStmt                  *sql.Stmt
+	markAlertTriggeredStmt                *sql.Stmt
+	pruneAlertHistoryStmt                 *sql.Stmt
+	pruneExpiredQuerySharesStmt           *sql.Stmt
+	pruneQueryHistoryForUserStmt          *sql.Stmt
+	pruneSLOEvaluationsStmt               *sql.Stmt
+	queryVolumeByDayStmt                  *sql.Stmt
+	removeCollectionItemStmt              *sql.Stmt
+	removeCollectionMemberStmt            *sql.Stmt
+	removeTeamMemberStmt                  *sql.Stmt
+	removeTeamSourceStmt                  *sql.Stmt
+	resolveAlertHistoryStmt               *sql.Stmt
+	setAlertManagedStmt                   *sql.Stmt
+	setSourceManagedStmt                  *sql.Stmt
+	setTeamManagedStmt                    *sql.Stmt
+	setTeamSourceRowFilterStmt            *sql.Stmt
+	setUserManagedStmt                    *sql.Stmt
+	setUserPasswordHashStmt               *sql.Stmt
+	teamHasSourceStmt                     *sql.Stmt
+	topSourcesByQueriesStmt               *sql.Stmt
+	topUsersByQueriesStmt                 *sql.Stmt
+	touchQueryShareStmt                   *sql.Stmt
+	updateAPITokenLastUsedStmt            *sql.Stmt
+	updateAlertStmt                       *sql.Stmt
+	updateAlertHistoryPayloadStmt         *sql.Stmt
+	updateAlertSilenceStmt                *sql.Stmt
+	updateAnnotationStmt                  *sql.Stmt
+	updateCollectionStmt                  *sql.Stmt
+	updateDashboardStmt                   *sql.Stmt
+	updateExportJobRunningStmt            *sql.Stmt
+	updateLogBookmarkStmt                 *sql.Stmt
+	updateNotebookStmt                    *sql.Stmt
+	updateNotebookCellsStmt               *sql.Stmt
+	updateQuerySnippetStmt                *sql.Stmt
+	updateSLOStmt                         *sql.Stmt
+	updateSavedQueryStmt                  *sql.Stmt
+	updateSourceStmt                      *sql.Stmt
+	updateSourceConnectionConfigStmt      *sql.Stmt
+	updateSourceRollupProgressStmt        *sql.Stmt
+	updateTeamStmt                        *sql.Stmt
+	updateTeamMemberRoleStmt              *sql.Stmt
+	updateUserStmt                        *sql.Stmt
+	updateWebhookStmt                     *sql.Stmt
+	upsertSourceRollupStmt                *sql.Stmt
+	upsertSourceStatsSnapshotStmt         *sql.Stmt
+	upsertSystemSettingStmt               *sql.Stmt
+	upsertTeamColumnPresetStmt            *sql.Stmt
+	upsertTrackedQueryStmt                *sql.Stmt
+	upsertUserColumnPresetStmt            *sql.Stmt
+	upsertUserPreferencesStmt             *sql.Stmt
+	userHasSourceAccessStmt               *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                                  tx,
-		tx:                                  tx,
-		addCollectionItemStmt:               q.addCollectionItemStmt,
-		addCollectionMemberStmt:             q.addCollectionMemberStmt,
-		addTeamMemberStmt:                   q.addTeamMemberStmt,
-		addTeamSourceStmt:                   q.addTeamSourceStmt,
-		completeExportJobStmt:               q.completeExportJobStmt,
-		countAdminUsersStmt:                 q.countAdminUsersStmt,
-		countSharedCollectionEditAccessStmt: q.countSharedCollectionEditAccessStmt,
-		countUserSessionsStmt:               q.countUserSessionsStmt,
-		createAPITokenStmt:                  q.createAPITokenStmt,
-		createAlertStmt:                     q.createAlertStmt,
-		createCollectionStmt:                q.createCollectionStmt,
-		createDashboardStmt:                 q.createDashboardStmt,
-		createExportJobStmt:                 q.createExportJobStmt,
-		createQueryShareStmt:                q.createQueryShareStmt,
-		createSavedQueryStmt:                q.createSavedQueryStmt,
-		createSessionStmt:                   q.createSessionStmt,
-		createSourceStmt:                    q.createSourceStmt,
-		createTeamStmt:                      q.createTeamStmt,
-		createUserStmt:                      q.createUserStmt,
-		deleteAPITokenStmt:                  q.deleteAPITokenStmt,
-		deleteAlertStmt:                     q.deleteAlertStmt,
-		deleteCollectionStmt:                q.deleteCollectionStmt,
-		deleteDashboardStmt:                 q.deleteDashboardStmt,
-		deleteExpiredExportJobsStmt:         q.deleteExpiredExportJobsStmt,
-		deleteExpiredSessionsStmt:           q.deleteExpiredSessionsStmt,
-		deleteQueryShareStmt:                q.deleteQueryShareStmt,
-		deleteSavedQueryStmt:                q.deleteSavedQueryStmt,
-		deleteSessionStmt:                   q.deleteSessionStmt,
-		deleteSourceStmt:                    q.deleteSourceStmt,
-		deleteSystemSettingStmt:             q.deleteSystemSettingStmt,
-		deleteTeamStmt:                      q.deleteTeamStmt,
-		deleteUserStmt:                      q.deleteUserStmt,
-		deleteUserSessionsStmt:              q.deleteUserSessionsStmt,
-		failExportJobStmt:                   q.failExportJobStmt,
-		getAPITokenStmt:                     q.getAPITokenStmt,
-		getAPITokenByHashStmt:               q.getAPITokenByHashStmt,
-		getAlertStmt:                        q.getAlertStmt,
-		getCollectionStmt:                   q.getCollectionStmt,
-		getCollectionMemberStmt:             q.getCollectionMemberStmt,
-		getDashboardStmt:                    q.getDashboardStmt,
-		getExportJobStmt:                    q.getExportJobStmt,
-		getLatestUnresolvedAlertHistoryStmt: q.getLatestUnresolvedAlertHistoryStmt,
-		getPersonalCollectionStmt:           q.getPersonalCollectionStmt,
-		getQueryShareStmt:                   q.getQueryShareStmt,
-		getSavedQueryStmt:                   q.getSavedQueryStmt,
-		getSessionStmt:                      q.getSessionStmt,
-		getSourceStmt:                       q.getSourceStmt,
-		getSourceByIdentityKeyStmt:          q.getSourceByIdentityKeyStmt,
-		getSourceByNameForProvisioningStmt:  q.getSourceByNameForProvisioningStmt,
-		getSystemSettingStmt:                q.getSystemSettingStmt,
-		getTeamStmt:                         q.getTeamStmt,
-		getTeamByNameStmt:                   q.getTeamByNameStmt,
-		getTeamMemberStmt:                   q.getTeamMemberStmt,
-		getUserStmt:                         q.getUserStmt,
-		getUserByEmailStmt:                  q.getUserByEmailStmt,
-		getUserPreferencesStmt:              q.getUserPreferencesStmt,
-		getUserTeamForSourceStmt:            q.getUserTeamForSourceStmt,
-		incrementQueryStatsStmt:             q.incrementQueryStatsStmt,
-		insertAlertHistoryStmt:              q.insertAlertHistoryStmt,
-		insertQueryHistoryStmt:              q.insertQueryHistoryStmt,
-		isSourceManagedStmt:                 q.isSourceManagedStmt,
-		isTeamManagedStmt:                   q.isTeamManagedStmt,
-		isUserManagedStmt:                   q.isUserManagedStmt,
-		listAPITokensForUserStmt:            q.listAPITokensForUserStmt,
-		listAccessibleSourceIDsForUserStmt:  q.listAccessibleSourceIDsForUserStmt,
-		listActiveAlertsDueStmt:             q.listActiveAlertsDueStmt,
-		listAlertHistoryStmt:                q.listAlertHistoryStmt,
-		listAlertsBySourceStmt:              q.listAlertsBySourceStmt,
-		listAlertsForUserStmt:               q.listAlertsForUserStmt,
-		listAllSavedQueriesStmt:             q.listAllSavedQueriesStmt,
-		listCollectionItemsStmt:             q.listCollectionItemsStmt,
-		listCollectionMembersStmt:           q.listCollectionMembersStmt,
-		listCollectionsForUserStmt:          q.listCollectionsForUserStmt,
-		listDashboardsStmt:                  q.listDashboardsStmt,
-		listExpiredExportJobPathsStmt:       q.listExpiredExportJobPathsStmt,
-		listManagedSourcesStmt:              q.listManagedSourcesStmt,
-		listManagedTeamsStmt:                q.listManagedTeamsStmt,
-		listManagedUsersStmt:                q.listManagedUsersStmt,
-		listQueryActivityStmt:               q.listQueryActivityStmt,
-		listQueryHistoryStmt:                q.listQueryHistoryStmt,
-		listSavedQueriesForUserStmt:         q.listSavedQueriesForUserStmt,
-		listSavedQueriesForUserBySourceStmt: q.listSavedQueriesForUserBySourceStmt,
-		listServiceAccountsStmt:             q.listServiceAccountsStmt,
-		listSourceTeamsStmt:                 q.listSourceTeamsStmt,
-		listSourcesStmt:                     q.listSourcesStmt,
-		listSourcesForUserStmt:              q.listSourcesForUserStmt,
-		listSystemSettingsStmt:              q.listSystemSettingsStmt,
-		listSystemSettingsByCategoryStmt:    q.listSystemSettingsByCategoryStmt,
-		listTeamMembersStmt:                 q.listTeamMembersStmt,
-		listTeamMembersWithDetailsStmt:      q.listTeamMembersWithDetailsStmt,
-		listTeamSourcesStmt:                 q.listTeamSourcesStmt,
-		listTeamsStmt:                       q.listTeamsStmt,
-		listTeamsForUserStmt:                q.listTeamsForUserStmt,
-		listUserTeamsStmt:                   q.listUserTeamsStmt,
-		listUsersStmt:                       q.listUsersStmt,
-		markAlertEvaluatedStmt:              q.markAlertEvaluatedStmt,
-		markAlertTriggeredStmt:              q.markAlertTriggeredStmt,
-		pruneAlertHistoryStmt:               q.pruneAlertHistoryStmt,
-		pruneExpiredQuerySharesStmt:         q.pruneExpiredQuerySharesStmt,
-		pruneQueryHistoryForUserStmt:        q.pruneQueryHistoryForUserStmt,
-		queryVolumeByDayStmt:                q.queryVolumeByDayStmt,
-		removeCollectionItemStmt:            q.removeCollectionItemStmt,
-		removeCollectionMemberStmt:          q.removeCollectionMemberStmt,
-		removeTeamMemberStmt:                q.removeTeamMemberStmt,
-		removeTeamSourceStmt:                q.removeTeamSourceStmt,
-		resolveAlertHistoryStmt:             q.resolveAlertHistoryStmt,
-		setSourceManagedStmt:                q.setSourceManagedStmt,
-		setTeamManagedStmt:                  q.setTeamManagedStmt,
-		setUserManagedStmt:                  q.setUserManagedStmt,
-		setUserPasswordHashStmt:             q.setUserPasswordHashStmt,
-		teamHasSourceStmt:                   q.teamHasSourceStmt,
-		topSourcesByQueriesStmt:             q.topSourcesByQueriesStmt,
-		topUsersByQueriesStmt:               q.topUsersByQueriesStmt,
-		touchQueryShareStmt:                 q.touchQueryShareStmt,
-		updateAPITokenLastUsedStmt:          q.updateAPITokenLastUsedStmt,
-		updateAlertStmt:                     q.updateAlertStmt,
-		updateAlertHistoryPayloadStmt:       q.updateAlertHistoryPayloadStmt,
-		updateCollectionStmt:                q.updateCollectionStmt,
-		updateDashboardStmt:                 q.updateDashboardStmt,
-		updateExportJobRunningStmt:          q.updateExportJobRunningStmt,
-		updateSavedQueryStmt:                q.updateSavedQueryStmt,
-		updateSourceStmt:                    q.updateSourceStmt,
-		updateTeamStmt:                      q.updateTeamStmt,
-		updateTeamMemberRoleStmt:            q.updateTeamMemberRoleStmt,
-		updateUserStmt:                      q.updateUserStmt,
-		upsertSystemSettingStmt:             q.upsertSystemSettingStmt,
-		upsertUserPreferencesStmt:           q.upsertUserPreferencesStmt,
-		userHasSourceAccessStmt:             q.userHasSourceAccessStmt,
+		db:                                    tx,
+		tx:                                    tx,
+		acquireAlertLeaseStmt:                 q.acquireAlertLeaseStmt,
+		addCollectionItemStmt:                 q.addCollectionItemStmt,
+		addCollectionMemberStmt:               q.addCollectionMemberStmt,
+		addTeamMemberStmt:                     q.addTeamMemberStmt,
+		addTeamSourceStmt:                     q.addTeamSourceStmt,
+		completeExportJobStmt:                 q.completeExportJobStmt,
+		countAdminUsersStmt:                   q.countAdminUsersStmt,
+		countQueryHistoryStmt:                 q.countQueryHistoryStmt,
+		countSharedCollectionEditAccessStmt:   q.countSharedCollectionEditAccessStmt,
+		countUserSessionsStmt:                 q.countUserSessionsStmt,
+		createAPITokenStmt:                    q.createAPITokenStmt,
+		createAlertStmt:                       q.createAlertStmt,
+		createAlertSilenceStmt:                q.createAlertSilenceStmt,
+		createAnnotationStmt:                  q.createAnnotationStmt,
+		createCollectionStmt:                  q.createCollectionStmt,
+		createDashboardStmt:                   q.createDashboardStmt,
+		createExportJobStmt:                   q.createExportJobStmt,
+		createLogBookmarkStmt:                 q.createLogBookmarkStmt,
+		createNotebookStmt:                    q.createNotebookStmt,
+		createNotebookSnapshotStmt:            q.createNotebookSnapshotStmt,
+		createQueryShareStmt:                  q.createQueryShareStmt,
+		createQuerySnippetStmt:                q.createQuerySnippetStmt,
+		createSLOStmt:                         q.createSLOStmt,
+		createSavedQueryStmt:                  q.createSavedQueryStmt,
+		createSessionStmt:                     q.createSessionStmt,
+		createSourceStmt:                      q.createSourceStmt,
+		createTeamStmt:                        q.createTeamStmt,
+		createUserStmt:                        q.createUserStmt,
+		createWebhookStmt:                     q.createWebhookStmt,
+		deleteAPITokenStmt:                    q.deleteAPITokenStmt,
+		deleteAlertStmt:                       q.deleteAlertStmt,
+		deleteAlertLeasesByHolderStmt:         q.deleteAlertLeasesByHolderStmt,
+		deleteAlertSilenceStmt:                q.deleteAlertSilenceStmt,
+		deleteAnnotationStmt:                  q.deleteAnnotationStmt,
+		deleteCollectionStmt:                  q.deleteCollectionStmt,
+		deleteDashboardStmt:                   q.deleteDashboardStmt,
+		deleteExpiredExportJobsStmt:           q.deleteExpiredExportJobsStmt,
+		deleteExpiredSessionsStmt:             q.deleteExpiredSessionsStmt,
+		deleteLogBookmarkStmt:                 q.deleteLogBookmarkStmt,
+		deleteNotebookStmt:                    q.deleteNotebookStmt,
+		deleteNotebookSnapshotStmt:            q.deleteNotebookSnapshotStmt,
+		deleteQueryHistoryBeforeStmt:          q.deleteQueryHistoryBeforeStmt,
+		deleteQueryShareStmt:                  q.deleteQueryShareStmt,
+		deleteQuerySnippetStmt:                q.deleteQuerySnippetStmt,
+		deleteSLOStmt:                         q.deleteSLOStmt,
+		deleteSavedQueryStmt:                  q.deleteSavedQueryStmt,
+		deleteSessionStmt:                     q.deleteSessionStmt,
+		deleteSourceStmt:                      q.deleteSourceStmt,
+		deleteSourceAlertEventsBeforeStmt:     q.deleteSourceAlertEventsBeforeStmt,
+		deleteSourceRollupStmt:                q.deleteSourceRollupStmt,
+		deleteSourceSchemaSnapshotsBeforeStmt: q.deleteSourceSchemaSnapshotsBeforeStmt,
+		deleteSourceStatsSnapshotsBeforeStmt:  q.deleteSourceStatsSnapshotsBeforeStmt,
+		deleteSystemSettingStmt:               q.deleteSystemSettingStmt,
+		deleteTeamStmt:                        q.deleteTeamStmt,
+		deleteTeamColumnPresetStmt:            q.deleteTeamColumnPresetStmt,
+		deleteTrackedQueriesBeforeStmt:        q.deleteTrackedQueriesBeforeStmt,
+		deleteTrackedQueryStmt:                q.deleteTrackedQueryStmt,
+		deleteUserStmt:                        q.deleteUserStmt,
+		deleteUserColumnPresetStmt:            q.deleteUserColumnPresetStmt,
+		deleteUserSessionsStmt:                q.deleteUserSessionsStmt,
+		deleteWebhookStmt:                     q.deleteWebhookStmt,
+		failExportJobStmt:                     q.failExportJobStmt,
+		getAPITokenStmt:                       q.getAPITokenStmt,
+		getAPITokenByHashStmt:                 q.getAPITokenByHashStmt,
+		getAlertStmt:                          q.getAlertStmt,
+		getAlertSilenceStmt:                   q.getAlertSilenceStmt,
+		getAnnotationStmt:                     q.getAnnotationStmt,
+		getCollectionStmt:                     q.getCollectionStmt,
+		getCollectionMemberStmt:               q.getCollectionMemberStmt,
+		getDashboardStmt:                      q.getDashboardStmt,
+		getExportJobStmt:                      q.getExportJobStmt,
+		getLatestSourceSchemaSnapshotStmt:     q.getLatestSourceSchemaSnapshotStmt,
+		getLatestUnresolvedAlertHistoryStmt:   q.getLatestUnresolvedAlertHistoryStmt,
+		getLogBookmarkStmt:                    q.getLogBookmarkStmt,
+		getNotebookStmt:                       q.getNotebookStmt,
+		getNotebookSnapshotStmt:               q.getNotebookSnapshotStmt,
+		getPersonalCollectionStmt:             q.getPersonalCollectionStmt,
+		getQueryShareStmt:                     q.getQueryShareStmt,
+		getQuerySnippetStmt:                   q.getQuerySnippetStmt,
+		getSLOStmt:                            q.getSLOStmt,
+		getSavedQueryStmt:                     q.getSavedQueryStmt,
+		getSessionStmt:                        q.getSessionStmt,
+		getSourceStmt:                         q.getSourceStmt,
+		getSourceByIdentityKeyStmt:            q.getSourceByIdentityKeyStmt,
+		getSourceByNameForProvisioningStmt:    q.getSourceByNameForProvisioningStmt,
+		getSourceRollupStmt:                   q.getSourceRollupStmt,
+		getSystemSettingStmt:                  q.getSystemSettingStmt,
+		getTeamStmt:                           q.getTeamStmt,
+		getTeamByNameStmt:                     q.getTeamByNameStmt,
+		getTeamColumnPresetStmt:               q.getTeamColumnPresetStmt,
+		getTeamMemberStmt:                     q.getTeamMemberStmt,
+		getTeamQueryUsageStmt:                 q.getTeamQueryUsageStmt,
+		getTeamSourceRowFilterStmt:            q.getTeamSourceRowFilterStmt,
+		getUserStmt:                           q.getUserStmt,
+		getUserByEmailStmt:                    q.getUserByEmailStmt,
+		getUserColumnPresetStmt:               q.getUserColumnPresetStmt,
+		getUserPreferencesStmt:                q.getUserPreferencesStmt,
+		getUserTeamForSourceStmt:              q.getUserTeamForSourceStmt,
+		getWebhookStmt:                        q.getWebhookStmt,
+		incrementQueryStatsStmt:               q.incrementQueryStatsStmt,
+		insertAlertHistoryStmt:                q.insertAlertHistoryStmt,
+		insertAuditEventStmt:                  q.insertAuditEventStmt,
+		insertQueryHistoryStmt:                q.insertQueryHistoryStmt,
+		insertSLOEvaluationStmt:               q.insertSLOEvaluationStmt,
+		insertSourceAlertEventStmt:            q.insertSourceAlertEventStmt,
+		insertSourceSchemaSnapshotStmt:        q.insertSourceSchemaSnapshotStmt,
+		isAlertManagedStmt:                    q.isAlertManagedStmt,
+		isSourceManagedStmt:                   q.isSourceManagedStmt,
+		isTeamManagedStmt:                     q.isTeamManagedStmt,
+		isUserManagedStmt:                     q.isUserManagedStmt,
+		listAPITokensForUserStmt:              q.listAPITokensForUserStmt,
+		listAccessibleSourceIDsForUserStmt:    q.listAccessibleSourceIDsForUserStmt,
+		listActiveAlertsDueStmt:               q.listActiveAlertsDueStmt,
+		listAlertHistoryStmt:                  q.listAlertHistoryStmt,
+		listAlertHistorySinceStmt:             q.listAlertHistorySinceStmt,
+		listAlertSilencesStmt:                 q.listAlertSilencesStmt,
+		listAlertsBySourceStmt:                q.listAlertsBySourceStmt,
+		listAlertsForUserStmt:                 q.listAlertsForUserStmt,
+		listAllSavedQueriesStmt:               q.listAllSavedQueriesStmt,
+		listAnnotationsByTeamStmt:             q.listAnnotationsByTeamStmt,
+		listAnnotationsInRangeStmt:            q.listAnnotationsInRangeStmt,
+		listAuditEventsStmt:                   q.listAuditEventsStmt,
+		listCollectionItemsStmt:               q.listCollectionItemsStmt,
+		listCollectionMembersStmt:             q.listCollectionMembersStmt,
+		listCollectionsForUserStmt:            q.listCollectionsForUserStmt,
+		listDashboardsStmt:                    q.listDashboardsStmt,
+		listExpiredExportJobPathsStmt:         q.listExpiredExportJobPathsStmt,
+		listExpiredNotebookSnapshotsStmt:      q.listExpiredNotebookSnapshotsStmt,
+		listLogBookmarksForUserStmt:           q.listLogBookmarksForUserStmt,
+		listManagedAlertsStmt:                 q.listManagedAlertsStmt,
+		listManagedSourcesStmt:                q.listManagedSourcesStmt,
+		listManagedTeamsStmt:                  q.listManagedTeamsStmt,
+		listManagedUsersStmt:                  q.listManagedUsersStmt,
+		listNotebookSnapshotsStmt:             q.listNotebookSnapshotsStmt,
+		listNotebooksByTeamStmt:               q.listNotebooksByTeamStmt,
+		listQueryActivityStmt:                 q.listQueryActivityStmt,
+		listQueryHistoryStmt:                  q.listQueryHistoryStmt,
+		listQuerySnippetsByTeamStmt:           q.listQuerySnippetsByTeamStmt,
+		listSLOEvaluationsStmt:                q.listSLOEvaluationsStmt,
+		listSLOsStmt:                          q.listSLOsStmt,
+		listSLOsByTeamStmt:                    q.listSLOsByTeamStmt,
+		listSavedQueriesForUserStmt:           q.listSavedQueriesForUserStmt,
+		listSavedQueriesForUserBySourceStmt:   q.listSavedQueriesForUserBySourceStmt,
+		listServiceAccountsStmt:               q.listServiceAccountsStmt,
+		listSourceAlertEventsStmt:             q.listSourceAlertEventsStmt,
+		listSourceRollupsStmt:                 q.listSourceRollupsStmt,
+		listSourceSchemaSnapshotsStmt:         q.listSourceSchemaSnapshotsStmt,
+		listSourceStatsSnapshotsStmt:          q.listSourceStatsSnapshotsStmt,
+		listSourceTeamsStmt:                   q.listSourceTeamsStmt,
+		listSourcesStmt:                       q.listSourcesStmt,
+		listSourcesForUserStmt:                q.listSourcesForUserStmt,
+		listSystemSettingsStmt:                q.listSystemSettingsStmt,
+		listSystemSettingsByCategoryStmt:      q.listSystemSettingsByCategoryStmt,
+		listTeamMembersStmt:                   q.listTeamMembersStmt,
+		listTeamMembersWithDetailsStmt:        q.listTeamMembersWithDetailsStmt,
+		listTeamSourcesStmt:                   q.listTeamSourcesStmt,
+		listTeamsStmt:                         q.listTeamsStmt,
+		listTeamsForUserStmt:                  q.listTeamsForUserStmt,
+		listTrackedQueriesStmt:                q.listTrackedQueriesStmt,
+		listUnexpiredAlertSilencesStmt:        q.listUnexpiredAlertSilencesStmt,
+		listUserSourceRolesStmt:               q.listUserSourceRolesStmt,
+		listUserTeamsStmt:                     q.listUserTeamsStmt,
+		listUsersStmt:                         q.listUsersStmt,
+		listWebhooksStmt:                      q.listWebhooksStmt,
+		markAlertEvaluatedStmt:                q.markAlertEvaluatedStmt,
+		markAlertPendingStmt:                  q.markAlertPendingStmt,
+		markAlertTriggeredStmt:                q.markAlertTriggeredStmt,
+		pruneAlertHistoryStmt:                 q.pruneAlertHistoryStmt,
+		pruneExpiredQuerySharesStmt:           q.pruneExpiredQuerySharesStmt,
+		pruneQueryHistoryForUserStmt:          q.pruneQueryHistoryForUserStmt,
+		pruneSLOEvaluationsStmt:               q.pruneSLOEvaluationsStmt,
+		queryVolumeByDayStmt:                  q.queryVolumeByDayStmt,
+		removeCollectionItemStmt:              q.removeCollectionItemStmt,
+		removeCollectionMemberStmt:            q.removeCollectionMemberStmt,
+		removeTeamMemberStmt:                  q.removeTeamMemberStmt,
+		removeTeamSourceStmt:                  q.removeTeamSourceStmt,
+		resolveAlertHistoryStmt:               q.resolveAlertHistoryStmt,
+		setAlertManagedStmt:                   q.setAlertManagedStmt,
+		setSourceManagedStmt:                  q.setSourceManagedStmt,
+		setTeamManagedStmt:                    q.setTeamManagedStmt,
+		setTeamSourceRowFilterStmt:            q.setTeamSourceRowFilterStmt,
+		setUserManagedStmt:                    q.setUserManagedStmt,
+		setUserPasswordHashStmt:               q.setUserPasswordHashStmt,
+		teamHasSourceStmt:                     q.teamHasSourceStmt,
+		topSourcesByQueriesStmt:               q.topSourcesByQueriesStmt,
+		topUsersByQueriesStmt:                 q.topUsersByQueriesStmt,
+		touchQueryShareStmt:                   q.touchQueryShareStmt,
+		updateAPITokenLastUsedStmt:            q.updateAPITokenLastUsedStmt,
+		updateAlertStmt:                       q.updateAlertStmt,
+		updateAlertHistoryPayloadStmt:         q.updateAlertHistoryPayloadStmt,
+		updateAlertSilenceStmt:                q.updateAlertSilenceStmt,
+		updateAnnotationStmt:                  q.updateAnnotationStmt,
+		updateCollectionStmt:                  q.updateCollectionStmt,
+		updateDashboardStmt:                   q.updateDashboardStmt,
+		updateExportJobRunningStmt:            q.updateExportJobRunningStmt,
+		updateLogBookmarkStmt:                 q.updateLogBookmarkStmt,
+		updateNotebookStmt:                    q.updateNotebookStmt,
+		updateNotebookCellsStmt:               q.updateNotebookCellsStmt,
+		updateQuerySnippetStmt:                q.updateQuerySnippetStmt,
+		updateSLOStmt:                         q.updateSLOStmt,
+		updateSavedQueryStmt:                  q.updateSavedQueryStmt,
+		updateSourceStmt:                      q.updateSourceStmt,
+		updateSourceConnectionConfigStmt:      q.updateSourceConnectionConfigStmt,
+		updateSourceRollupProgressStmt:        q.updateSourceRollupProgressStmt,
+		updateTeamStmt:                        q.updateTeamStmt,
+		updateTeamMemberRoleStmt:              q.updateTeamMemberRoleStmt,
+		updateUserStmt:                        q.updateUserStmt,
+		updateWebhookStmt:                     q.updateWebhookStmt,
+		upsertSourceRollupStmt:                q.upsertSourceRollupStmt,
+		upsertSourceStatsSnapshotStmt:         q.upsertSourceStatsSnapshotStmt,
+		upsertSystemSettingStmt:               q.upsertSystemSettingStmt,
+		upsertTeamColumnPresetStmt:            q.upsertTeamColumnPresetStmt,
+		upsertTrackedQueryStmt:                q.upsertTrackedQueryStmt,
+		upsertUserColumnPresetStmt:            q.upsertUserColumnPresetStmt,
+		upsertUserPreferencesStmt:             q.upsertUserPreferencesStmt,
+		userHasSourceAccessStmt:               q.userHasSourceAccessStmt,
 	}
 }
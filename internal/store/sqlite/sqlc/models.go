@@ -35,6 +35,12 @@ type Alert struct {
 	CreatedBy            sql.NullInt64  `json:"created_by"`
 	CreatedAt            time.Time      `json:"created_at"`
 	UpdatedAt            time.Time      `json:"updated_at"`
+	ChannelsJson         sql.NullString `json:"channels_json"`
+	SloID                sql.NullInt64  `json:"slo_id"`
+	Managed              int64          `json:"managed"`
+	ForSeconds           int64          `json:"for_seconds"`
+	PendingSince         sql.NullTime   `json:"pending_since"`
+	BreachCount          int64          `json:"breach_count"`
 }
 
 type AlertHistory struct {
@@ -49,6 +55,39 @@ type AlertHistory struct {
 	CreatedAt   time.Time       `json:"created_at"`
 }
 
+type AlertLease struct {
+	AlertID   int64     `json:"alert_id"`
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type AlertSilence struct {
+	ID             int64         `json:"id"`
+	AlertID        sql.NullInt64 `json:"alert_id"`
+	SourceID       sql.NullInt64 `json:"source_id"`
+	TeamID         sql.NullInt64 `json:"team_id"`
+	Reason         string        `json:"reason"`
+	StartsAt       time.Time     `json:"starts_at"`
+	EndsAt         sql.NullTime  `json:"ends_at"`
+	RecurrenceJson string        `json:"recurrence_json"`
+	CreatedBy      sql.NullInt64 `json:"created_by"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+type Annotation struct {
+	ID        int64         `json:"id"`
+	TeamID    int64         `json:"team_id"`
+	SourceID  sql.NullInt64 `json:"source_id"`
+	Text      string        `json:"text"`
+	TagsJson  string        `json:"tags_json"`
+	StartsAt  time.Time     `json:"starts_at"`
+	EndsAt    sql.NullTime  `json:"ends_at"`
+	CreatedBy sql.NullInt64 `json:"created_by"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
 type ApiToken struct {
 	ID         int64        `json:"id"`
 	UserID     int64        `json:"user_id"`
@@ -62,6 +101,19 @@ type ApiToken struct {
 	Scopes     string       `json:"scopes"`
 }
 
+type AuditEvent struct {
+	ID           int64          `json:"id"`
+	UserID       sql.NullInt64  `json:"user_id"`
+	UserEmail    string         `json:"user_email"`
+	Action       string         `json:"action"`
+	ResourceType string         `json:"resource_type"`
+	ResourceID   string         `json:"resource_id"`
+	TeamID       sql.NullInt64  `json:"team_id"`
+	IpAddress    string         `json:"ip_address"`
+	Details      sql.NullString `json:"details"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
 type Collection struct {
 	ID          int64          `json:"id"`
 	Name        string         `json:"name"`
@@ -116,6 +168,44 @@ type ExportJob struct {
 	UpdatedAt    time.Time      `json:"updated_at"`
 }
 
+type LogBookmark struct {
+	ID           int64     `json:"id"`
+	TeamID       int64     `json:"team_id"`
+	SourceID     int64     `json:"source_id"`
+	UserID       int64     `json:"user_id"`
+	Shared       int64     `json:"shared"`
+	LogTimestamp time.Time `json:"log_timestamp"`
+	Fingerprint  string    `json:"fingerprint"`
+	PayloadJson  string    `json:"payload_json"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type Notebook struct {
+	ID          int64          `json:"id"`
+	TeamID      int64          `json:"team_id"`
+	Name        string         `json:"name"`
+	Description sql.NullString `json:"description"`
+	CellsJson   string         `json:"cells_json"`
+	CreatedBy   sql.NullInt64  `json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+type NotebookSnapshot struct {
+	ID         string        `json:"id"`
+	NotebookID int64         `json:"notebook_id"`
+	TeamID     int64         `json:"team_id"`
+	Format     string        `json:"format"`
+	FileName   string        `json:"file_name"`
+	ObjectKey  string        `json:"object_key"`
+	SizeBytes  int64         `json:"size_bytes"`
+	CreatedBy  sql.NullInt64 `json:"created_by"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
 type QueryHistory struct {
 	ID            int64     `json:"id"`
 	UserID        int64     `json:"user_id"`
@@ -126,6 +216,8 @@ type QueryHistory struct {
 	DurationMs    int64     `json:"duration_ms"`
 	RowCount      int64     `json:"row_count"`
 	CreatedAt     time.Time `json:"created_at"`
+	Status        string    `json:"status"`
+	ErrorMessage  string    `json:"error_message"`
 }
 
 type QueryShare struct {
@@ -133,12 +225,23 @@ type QueryShare struct {
 	SourceID       int64         `json:"source_id"`
 	CreatedBy      int64         `json:"created_by"`
 	PayloadJson    string        `json:"payload_json"`
-	ExpiresAt      time.Time     `json:"expires_at"`
+	ExpiresAt      sql.NullTime  `json:"expires_at"`
 	LastAccessedAt sql.NullTime  `json:"last_accessed_at"`
 	CreatedAt      time.Time     `json:"created_at"`
 	TeamID         sql.NullInt64 `json:"team_id"`
 }
 
+type QuerySnippet struct {
+	ID          int64         `json:"id"`
+	TeamID      int64         `json:"team_id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Query       string        `json:"query"`
+	CreatedBy   sql.NullInt64 `json:"created_by"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
 type QueryStatsDaily struct {
 	BucketDate      string `json:"bucket_date"`
 	UserID          int64  `json:"user_id"`
@@ -147,6 +250,7 @@ type QueryStatsDaily struct {
 	QueryLanguage   string `json:"query_language"`
 	QueryCount      int64  `json:"query_count"`
 	TotalDurationMs int64  `json:"total_duration_ms"`
+	RowsRead        int64  `json:"rows_read"`
 }
 
 type SavedQuery struct {
@@ -170,6 +274,34 @@ type Session struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type Slo struct {
+	ID            int64         `json:"id"`
+	TeamID        int64         `json:"team_id"`
+	SourceID      int64         `json:"source_id"`
+	Name          string        `json:"name"`
+	Description   string        `json:"description"`
+	QueryLanguage string        `json:"query_language"`
+	GoodQuery     string        `json:"good_query"`
+	TotalQuery    string        `json:"total_query"`
+	Target        float64       `json:"target"`
+	WindowSeconds int64         `json:"window_seconds"`
+	CreatedBy     sql.NullInt64 `json:"created_by"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+type SloEvaluation struct {
+	ID                   int64           `json:"id"`
+	SloID                int64           `json:"slo_id"`
+	Good                 float64         `json:"good"`
+	Total                float64         `json:"total"`
+	Compliance           sql.NullFloat64 `json:"compliance"`
+	BurnRate             sql.NullFloat64 `json:"burn_rate"`
+	ErrorBudgetRemaining sql.NullFloat64 `json:"error_budget_remaining"`
+	Error                string          `json:"error"`
+	EvaluatedAt          time.Time       `json:"evaluated_at"`
+}
+
 type Source struct {
 	ID                int64          `json:"id"`
 	Name              string         `json:"name"`
@@ -185,6 +317,50 @@ type Source struct {
 	UpdatedAt         time.Time      `json:"updated_at"`
 	Managed           int64          `json:"managed"`
 	SecretRef         sql.NullString `json:"secret_ref"`
+	Tags              string         `json:"tags"`
+	ExtractionRules   string         `json:"extraction_rules"`
+	TraceCorrelation  string         `json:"trace_correlation"`
+	MaskedColumns     string         `json:"masked_columns"`
+}
+
+type SourceAlertEvent struct {
+	ID        int64     `json:"id"`
+	SourceID  int64     `json:"source_id"`
+	Kind      string    `json:"kind"`
+	Status    string    `json:"status"`
+	Value     float64   `json:"value"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SourceRollup struct {
+	SourceID      int64        `json:"source_id"`
+	Dimension     string       `json:"dimension"`
+	RolledUpFrom  sql.NullTime `json:"rolled_up_from"`
+	RolledUpUntil sql.NullTime `json:"rolled_up_until"`
+	LastRunAt     sql.NullTime `json:"last_run_at"`
+	LastError     string       `json:"last_error"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+type SourceSchemaSnapshot struct {
+	ID         int64     `json:"id"`
+	SourceID   int64     `json:"source_id"`
+	Columns    string    `json:"columns"`
+	Changes    string    `json:"changes"`
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+type SourceStatsSnapshot struct {
+	SourceID          int64     `json:"source_id"`
+	SnapshotDate      string    `json:"snapshot_date"`
+	TotalRows         int64     `json:"total_rows"`
+	PartCount         int64     `json:"part_count"`
+	CompressedBytes   int64     `json:"compressed_bytes"`
+	UncompressedBytes int64     `json:"uncompressed_bytes"`
+	Columns           string    `json:"columns"`
+	CapturedAt        time.Time `json:"captured_at"`
 }
 
 type SystemSetting struct {
@@ -199,12 +375,22 @@ type SystemSetting struct {
 }
 
 type Team struct {
-	ID          int64          `json:"id"`
-	Name        string         `json:"name"`
-	Description sql.NullString `json:"description"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	Managed     int64          `json:"managed"`
+	ID            int64          `json:"id"`
+	Name          string         `json:"name"`
+	Description   sql.NullString `json:"description"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	Managed       int64          `json:"managed"`
+	RawSqlEnabled int64          `json:"raw_sql_enabled"`
+}
+
+type TeamColumnPreset struct {
+	TeamID      int64         `json:"team_id"`
+	SourceID    int64         `json:"source_id"`
+	ColumnsJson string        `json:"columns_json"`
+	UpdatedBy   sql.NullInt64 `json:"updated_by"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
 }
 
 type TeamMember struct {
@@ -218,6 +404,18 @@ type TeamSource struct {
 	TeamID    int64     `json:"team_id"`
 	SourceID  int64     `json:"source_id"`
 	CreatedAt time.Time `json:"created_at"`
+	RowFilter string    `json:"row_filter"`
+}
+
+type TrackedQuery struct {
+	ID        string    `json:"id"`
+	Instance  string    `json:"instance"`
+	Class     string    `json:"class"`
+	UserID    int64     `json:"user_id"`
+	TeamID    int64     `json:"team_id"`
+	SourceID  int64     `json:"source_id"`
+	QueryText string    `json:"query_text"`
+	StartedAt time.Time `json:"started_at"`
 }
 
 type User struct {
@@ -235,9 +433,29 @@ type User struct {
 	PasswordHash sql.NullString `json:"password_hash"`
 }
 
+type UserColumnPreset struct {
+	UserID      int64     `json:"user_id"`
+	SourceID    int64     `json:"source_id"`
+	ColumnsJson string    `json:"columns_json"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
 type UserPreference struct {
 	UserID          int64     `json:"user_id"`
 	PreferencesJson string    `json:"preferences_json"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 }
+
+type Webhook struct {
+	ID             int64         `json:"id"`
+	Name           string        `json:"name"`
+	Url            string        `json:"url"`
+	EventTypesJson string        `json:"event_types_json"`
+	Secret         string        `json:"secret"`
+	IsActive       int64         `json:"is_active"`
+	CreatedBy      sql.NullInt64 `json:"created_by"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
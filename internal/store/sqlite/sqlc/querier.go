@@ -11,6 +11,10 @@ import (
 )
 
 type Querier interface {
+	// Alert evaluation leases -------------------------------------------------------
+	// Claim an alert for evaluation, or extend the holder's own claim. Another
+	// instance's lease is only taken over once it has expired.
+	AcquireAlertLease(ctx context.Context, arg AcquireAlertLeaseParams) (int64, error)
 	// Add a saved query to a collection; idempotent on (collection_id, saved_query_id).
 	AddCollectionItem(ctx context.Context, arg AddCollectionItemParams) error
 	// Add a member; idempotent on (collection_id, user_id).
@@ -25,6 +29,8 @@ type Querier interface {
 	CompleteExportJob(ctx context.Context, arg CompleteExportJobParams) (string, error)
 	// Count active admin users
 	CountAdminUsers(ctx context.Context, arg CountAdminUsersParams) (int64, error)
+	// Count the history entries matching ListQueryHistory's filters.
+	CountQueryHistory(ctx context.Context, arg CountQueryHistoryParams) (int64, error)
 	// Count shared (non-personal) collections that contain the given saved query and
 	// in which the user is an owner or editor. A non-zero count means the user has
 	// delegated edit rights on that query via collection membership.
@@ -36,6 +42,12 @@ type Querier interface {
 	CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (int64, error)
 	// Alerts
 	CreateAlert(ctx context.Context, arg CreateAlertParams) (Alert, error)
+	// Alert silences ---------------------------------------------------------------
+	// Insert a new silence and return its id.
+	CreateAlertSilence(ctx context.Context, arg CreateAlertSilenceParams) (int64, error)
+	// Annotations ------------------------------------------------------------------
+	// Insert a new annotation and return its id.
+	CreateAnnotation(ctx context.Context, arg CreateAnnotationParams) (int64, error)
 	// Collections (cross-team curation lists for saved queries)
 	// Insert a new collection (personal or shared)
 	CreateCollection(ctx context.Context, arg CreateCollectionParams) (CreateCollectionRow, error)
@@ -45,9 +57,23 @@ type Querier interface {
 	// Export Jobs
 	// Persist an async export job
 	CreateExportJob(ctx context.Context, arg CreateExportJobParams) error
+	// Log bookmarks ----------------------------------------------------------------
+	// Pin a log row and return the bookmark id.
+	CreateLogBookmark(ctx context.Context, arg CreateLogBookmarkParams) (int64, error)
+	// Notebooks ------------------------------------------------------------------
+	// Insert a new notebook and return its id.
+	CreateNotebook(ctx context.Context, arg CreateNotebookParams) (int64, error)
+	// Notebook snapshots -----------------------------------------------------------
+	CreateNotebookSnapshot(ctx context.Context, arg CreateNotebookSnapshotParams) error
 	// Query Shares
 	// Persist an ad hoc query share token
 	CreateQueryShare(ctx context.Context, arg CreateQueryShareParams) error
+	// Query snippets ---------------------------------------------------------------
+	// Insert a new query snippet and return its id.
+	CreateQuerySnippet(ctx context.Context, arg CreateQuerySnippetParams) (int64, error)
+	// SLOs ------------------------------------------------------------------------
+	// Insert a new SLO and return its id.
+	CreateSLO(ctx context.Context, arg CreateSLOParams) (int64, error)
 	// Saved Queries (cross-team, source-scoped)
 	// Insert a new saved query and return its id
 	CreateSavedQuery(ctx context.Context, arg CreateSavedQueryParams) (int64, error)
@@ -63,9 +89,15 @@ type Querier interface {
 	// Users
 	// Create a new user
 	CreateUser(ctx context.Context, arg CreateUserParams) (int64, error)
+	// Webhooks ---------------------------------------------------------------------
+	// Register an outbound webhook and return its id.
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (int64, error)
 	// Delete an API token by ID and user ID (ensure user owns the token)
 	DeleteAPIToken(ctx context.Context, arg DeleteAPITokenParams) error
 	DeleteAlert(ctx context.Context, id int64) (int64, error)
+	DeleteAlertLeasesByHolder(ctx context.Context, holder string) error
+	DeleteAlertSilence(ctx context.Context, id int64) (int64, error)
+	DeleteAnnotation(ctx context.Context, id int64) (int64, error)
 	// Delete a collection. Personal collections cannot be deleted (enforced in app code).
 	DeleteCollection(ctx context.Context, id int64) error
 	// Delete a dashboard; RETURNING lets callers detect not-found.
@@ -74,21 +106,42 @@ type Querier interface {
 	DeleteExpiredExportJobs(ctx context.Context, expiresAt time.Time) error
 	// Delete all sessions whose expiry is at or before the given time
 	DeleteExpiredSessions(ctx context.Context, expiresAt time.Time) error
+	DeleteLogBookmark(ctx context.Context, id int64) (int64, error)
+	// Delete a notebook; RETURNING lets callers detect not-found.
+	DeleteNotebook(ctx context.Context, id int64) (int64, error)
+	DeleteNotebookSnapshot(ctx context.Context, id string) (string, error)
+	// Drop history entries older than the retention window.
+	DeleteQueryHistoryBefore(ctx context.Context, createdAt time.Time) (int64, error)
 	// Delete a query share and return its token
 	DeleteQueryShare(ctx context.Context, token string) (string, error)
+	DeleteQuerySnippet(ctx context.Context, id int64) (int64, error)
+	// Delete an SLO; its evaluations and burn-rate alerts cascade.
+	DeleteSLO(ctx context.Context, id int64) (int64, error)
 	// Delete a saved query
 	DeleteSavedQuery(ctx context.Context, id int64) error
 	// Delete a session by ID
 	DeleteSession(ctx context.Context, id string) error
 	// Delete a source by ID
 	DeleteSource(ctx context.Context, id int64) error
+	DeleteSourceAlertEventsBefore(ctx context.Context, createdAt time.Time) (int64, error)
+	DeleteSourceRollup(ctx context.Context, sourceID int64) error
+	// Drop snapshots older than the retention window, keeping each source's
+	// latest one as the baseline the next run diffs against.
+	DeleteSourceSchemaSnapshotsBefore(ctx context.Context, before time.Time) (int64, error)
+	DeleteSourceStatsSnapshotsBefore(ctx context.Context, snapshotDate string) (int64, error)
 	DeleteSystemSetting(ctx context.Context, key string) error
 	// Delete a team by ID
 	DeleteTeam(ctx context.Context, id int64) error
+	DeleteTeamColumnPreset(ctx context.Context, arg DeleteTeamColumnPresetParams) (int64, error)
+	// Rows no live query can still own, whichever instance wrote them.
+	DeleteTrackedQueriesBefore(ctx context.Context, startedAt time.Time) (int64, error)
+	DeleteTrackedQuery(ctx context.Context, id string) error
 	// Delete a user by ID
 	DeleteUser(ctx context.Context, id int64) error
+	DeleteUserColumnPreset(ctx context.Context, arg DeleteUserColumnPresetParams) (int64, error)
 	// Delete all sessions for a user
 	DeleteUserSessions(ctx context.Context, userID int64) error
+	DeleteWebhook(ctx context.Context, id int64) (int64, error)
 	// Mark an export job as failed and return its ID
 	FailExportJob(ctx context.Context, arg FailExportJobParams) (string, error)
 	// Get an API token by ID
@@ -96,6 +149,8 @@ type Querier interface {
 	// Get an API token by its hash (for authentication)
 	GetAPITokenByHash(ctx context.Context, tokenHash string) (ApiToken, error)
 	GetAlert(ctx context.Context, id int64) (Alert, error)
+	GetAlertSilence(ctx context.Context, id int64) (AlertSilence, error)
+	GetAnnotation(ctx context.Context, id int64) (Annotation, error)
 	// Look up a collection by id
 	GetCollection(ctx context.Context, id int64) (Collection, error)
 	// Look up a single membership row
@@ -104,11 +159,18 @@ type Querier interface {
 	GetDashboard(ctx context.Context, id int64) (GetDashboardRow, error)
 	// Retrieve an export job by ID
 	GetExportJob(ctx context.Context, id string) (ExportJob, error)
+	GetLatestSourceSchemaSnapshot(ctx context.Context, sourceID int64) (SourceSchemaSnapshot, error)
 	GetLatestUnresolvedAlertHistory(ctx context.Context, alertID int64) (AlertHistory, error)
+	GetLogBookmark(ctx context.Context, id int64) (LogBookmark, error)
+	// Look up one notebook by id, including its cells and creator identity.
+	GetNotebook(ctx context.Context, id int64) (GetNotebookRow, error)
+	GetNotebookSnapshot(ctx context.Context, id string) (NotebookSnapshot, error)
 	// Find the caller's personal collection if it exists
 	GetPersonalCollection(ctx context.Context, createdBy sql.NullInt64) (Collection, error)
 	// Retrieve an ad hoc query share by token with creator details
 	GetQueryShare(ctx context.Context, token string) (GetQueryShareRow, error)
+	GetQuerySnippet(ctx context.Context, id int64) (QuerySnippet, error)
+	GetSLO(ctx context.Context, id int64) (Slo, error)
 	// Look up one saved query by id
 	GetSavedQuery(ctx context.Context, id int64) (SavedQuery, error)
 	// Get a session by ID
@@ -119,32 +181,57 @@ type Querier interface {
 	GetSourceByIdentityKey(ctx context.Context, identityKey string) (Source, error)
 	// Get source by name for provisioning lookup
 	GetSourceByNameForProvisioning(ctx context.Context, name string) (Source, error)
+	// Source rollups ---------------------------------------------------------------
+	GetSourceRollup(ctx context.Context, sourceID int64) (SourceRollup, error)
 	// System Settings Queries
 	GetSystemSetting(ctx context.Context, key string) (SystemSetting, error)
 	// Get a team by ID
 	GetTeam(ctx context.Context, id int64) (Team, error)
 	// Get a team by its name
 	GetTeamByName(ctx context.Context, name string) (Team, error)
+	GetTeamColumnPreset(ctx context.Context, arg GetTeamColumnPresetParams) (TeamColumnPreset, error)
 	// Get a team member
 	GetTeamMember(ctx context.Context, arg GetTeamMemberParams) (TeamMember, error)
+	// Sum a team's queries and rows read on one day of the rollup.
+	GetTeamQueryUsage(ctx context.Context, arg GetTeamQueryUsageParams) (GetTeamQueryUsageRow, error)
+	// Get the row filter a team's queries on a linked source must match
+	GetTeamSourceRowFilter(ctx context.Context, arg GetTeamSourceRowFilterParams) (string, error)
 	// Get a user by ID
 	GetUser(ctx context.Context, id int64) (User, error)
 	// Get a user by email
 	GetUserByEmail(ctx context.Context, email string) (User, error)
+	// Column presets ---------------------------------------------------------------
+	GetUserColumnPreset(ctx context.Context, arg GetUserColumnPresetParams) (UserColumnPreset, error)
 	// User Preferences
 	// Get user preferences by user ID
 	GetUserPreferences(ctx context.Context, userID int64) (UserPreference, error)
 	// Get a team ID that the user belongs to and that has access to the source
 	GetUserTeamForSource(ctx context.Context, arg GetUserTeamForSourceParams) (int64, error)
+	GetWebhook(ctx context.Context, id int64) (Webhook, error)
 	// Query stats daily rollup -----------------------------------------------------
 	// Upsert one executed query into the non-pruned daily rollup: add 1 to
-	// query_count and the given duration to total_duration_ms for the composite key.
+	// query_count, and the given duration and rows read to total_duration_ms and
+	// rows_read, for the composite key.
 	IncrementQueryStats(ctx context.Context, arg IncrementQueryStatsParams) error
 	// Alert history queries
 	InsertAlertHistory(ctx context.Context, arg InsertAlertHistoryParams) (AlertHistory, error)
+	// Audit events ---------------------------------------------------------------
+	// Append one audit event and return its id. created_at is supplied by the
+	// caller (the time of the action, not of the asynchronous write).
+	InsertAuditEvent(ctx context.Context, arg InsertAuditEventParams) (int64, error)
 	// Query history ---------------------------------------------------------------
 	// Record one executed query and return its id.
 	InsertQueryHistory(ctx context.Context, arg InsertQueryHistoryParams) (int64, error)
+	// Append one evaluation and return its id.
+	InsertSLOEvaluation(ctx context.Context, arg InsertSLOEvaluationParams) (int64, error)
+	// Source alert events ----------------------------------------------------------
+	// Record a source degradation alert firing or resolving.
+	InsertSourceAlertEvent(ctx context.Context, arg InsertSourceAlertEventParams) (int64, error)
+	// Source schema snapshots -----------------------------------------------------
+	// Record a source's column list and its changes since the previous snapshot.
+	InsertSourceSchemaSnapshot(ctx context.Context, arg InsertSourceSchemaSnapshotParams) (int64, error)
+	// Check if an alert is managed
+	IsAlertManaged(ctx context.Context, id int64) (int64, error)
 	// Check if a source is managed
 	IsSourceManaged(ctx context.Context, id int64) (int64, error)
 	// Check if a team is managed
@@ -158,6 +245,12 @@ type Querier interface {
 	ListAccessibleSourceIDsForUser(ctx context.Context, userID int64) ([]int64, error)
 	ListActiveAlertsDue(ctx context.Context) ([]Alert, error)
 	ListAlertHistory(ctx context.Context, arg ListAlertHistoryParams) ([]AlertHistory, error)
+	// List an alert's history entries still open at or after since, oldest first.
+	// triggered_at and resolved_at are written in different text formats, so
+	// both sides go through datetime() to compare as times.
+	ListAlertHistorySince(ctx context.Context, arg ListAlertHistorySinceParams) ([]AlertHistory, error)
+	// List every silence, latest-starting first.
+	ListAlertSilences(ctx context.Context) ([]AlertSilence, error)
 	// List alerts for one source
 	ListAlertsBySource(ctx context.Context, sourceID int64) ([]Alert, error)
 	// List every alert the user can see (any source attached to any of their teams)
@@ -166,6 +259,14 @@ type Querier interface {
 	// surface only. The handler MUST authorize the caller as a global admin before
 	// calling this. Rows the caller cannot run are marked non-runnable in Go.
 	ListAllSavedQueries(ctx context.Context) ([]ListAllSavedQueriesRow, error)
+	// A team's annotations, latest-starting first.
+	ListAnnotationsByTeam(ctx context.Context, teamID int64) ([]Annotation, error)
+	// A team's annotations that touch [range_start, range_end] and apply to the
+	// given source: team-wide ones and the source's own. Oldest first.
+	ListAnnotationsInRange(ctx context.Context, arg ListAnnotationsInRangeParams) ([]Annotation, error)
+	// List audit events newest first. Every filter is optional: a NULL argument
+	// matches all rows.
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
 	// List items in a collection with saved-query details
 	ListCollectionItems(ctx context.Context, collectionID int64) ([]ListCollectionItemsRow, error)
 	// List members of a collection with user details
@@ -177,6 +278,13 @@ type Querier interface {
 	ListDashboards(ctx context.Context) ([]ListDashboardsRow, error)
 	// List artifact paths for expired export jobs
 	ListExpiredExportJobPaths(ctx context.Context, expiresAt time.Time) ([]sql.NullString, error)
+	// Snapshots past their expiry, for the cleanup loop.
+	ListExpiredNotebookSnapshots(ctx context.Context, expiresAt time.Time) ([]NotebookSnapshot, error)
+	// The bookmarks a user sees in a team: their own and the team's shared ones,
+	// most recently pinned first.
+	ListLogBookmarksForUser(ctx context.Context, arg ListLogBookmarksForUserParams) ([]LogBookmark, error)
+	// Get all alerts managed by provisioning config
+	ListManagedAlerts(ctx context.Context) ([]Alert, error)
 	// Provisioning Queries
 	// Get all sources managed by provisioning config
 	ListManagedSources(ctx context.Context) ([]Source, error)
@@ -184,20 +292,39 @@ type Querier interface {
 	ListManagedTeams(ctx context.Context) ([]Team, error)
 	// Get all users managed by provisioning config
 	ListManagedUsers(ctx context.Context) ([]User, error)
+	// A notebook's snapshots, newest first.
+	ListNotebookSnapshots(ctx context.Context, notebookID int64) ([]NotebookSnapshot, error)
+	// List a team's notebooks, newest-updated first. Cells are omitted: they carry
+	// cached results and are only needed when a single notebook is opened.
+	ListNotebooksByTeam(ctx context.Context, teamID int64) ([]ListNotebooksByTeamRow, error)
 	// Most recent query_history rows across all users, newest first, enriched with
 	// the executing user's email and the source's display name. LEFT JOIN on
 	// sources so history survives a deleted source (source_name is NULL then).
 	// Backs the admin recent-activity view; the table is capped per user, so this
 	// is a recent window, not all-time analytics.
 	ListQueryActivity(ctx context.Context, limit int64) ([]ListQueryActivityRow, error)
-	// List one user's recent history, newest first.
-	ListQueryHistory(ctx context.Context, arg ListQueryHistoryParams) ([]QueryHistory, error)
+	// List a page of query history newest first, with the user's email and the
+	// source's name (empty once the source is deleted). Every filter is optional:
+	// a NULL argument matches all rows. search matches query text, ignoring case.
+	ListQueryHistory(ctx context.Context, arg ListQueryHistoryParams) ([]ListQueryHistoryRow, error)
+	// A team's snippets, by name.
+	ListQuerySnippetsByTeam(ctx context.Context, teamID int64) ([]QuerySnippet, error)
+	// An SLO's evaluations, newest first.
+	ListSLOEvaluations(ctx context.Context, arg ListSLOEvaluationsParams) ([]SloEvaluation, error)
+	// List every SLO, for the periodic evaluator.
+	ListSLOs(ctx context.Context) ([]Slo, error)
+	// List a team's SLOs by name.
+	ListSLOsByTeam(ctx context.Context, teamID int64) ([]Slo, error)
 	// List every saved query the user can see (any source attached to any of their teams)
 	ListSavedQueriesForUser(ctx context.Context, userID int64) ([]ListSavedQueriesForUserRow, error)
 	// List saved queries for a specific source, scoped to a user that has access to it
 	ListSavedQueriesForUserBySource(ctx context.Context, arg ListSavedQueriesForUserBySourceParams) ([]ListSavedQueriesForUserBySourceRow, error)
 	// List service principals
 	ListServiceAccounts(ctx context.Context) ([]User, error)
+	ListSourceAlertEvents(ctx context.Context, arg ListSourceAlertEventsParams) ([]SourceAlertEvent, error)
+	ListSourceRollups(ctx context.Context) ([]SourceRollup, error)
+	ListSourceSchemaSnapshots(ctx context.Context, arg ListSourceSchemaSnapshotsParams) ([]SourceSchemaSnapshot, error)
+	ListSourceStatsSnapshots(ctx context.Context, arg ListSourceStatsSnapshotsParams) ([]SourceStatsSnapshot, error)
 	// List all teams a data source is a member of
 	ListSourceTeams(ctx context.Context, sourceID int64) ([]Team, error)
 	// Get all sources ordered by creation date
@@ -216,18 +343,32 @@ type Querier interface {
 	ListTeams(ctx context.Context) ([]ListTeamsRow, error)
 	// List all teams a user is a member of
 	ListTeamsForUser(ctx context.Context, userID int64) ([]ListTeamsForUserRow, error)
+	// One instance's persisted queries, oldest first.
+	ListTrackedQueries(ctx context.Context, instance string) ([]TrackedQuery, error)
+	// Silences that have not ended by the given time, for the alert evaluator.
+	ListUnexpiredAlertSilences(ctx context.Context, endsAt sql.NullTime) ([]AlertSilence, error)
+	// List the distinct roles a user holds across the teams that have access to a source
+	ListUserSourceRoles(ctx context.Context, arg ListUserSourceRolesParams) ([]string, error)
 	// List all teams a user is a member of
 	ListUserTeams(ctx context.Context, userID int64) ([]Team, error)
 	// List all users
 	ListUsers(ctx context.Context) ([]User, error)
+	// List every webhook by name.
+	ListWebhooks(ctx context.Context) ([]Webhook, error)
+	// Resolve the alert and end any pending run of breaches.
 	MarkAlertEvaluated(ctx context.Context, id int64) error
+	// Record a breach of an alert that has not fired yet, starting the pending
+	// run on its first breach.
+	MarkAlertPending(ctx context.Context, id int64) (MarkAlertPendingRow, error)
 	MarkAlertTriggered(ctx context.Context, id int64) error
 	PruneAlertHistory(ctx context.Context, arg PruneAlertHistoryParams) error
 	// Delete expired query shares
-	PruneExpiredQueryShares(ctx context.Context, expiresAt time.Time) error
+	PruneExpiredQueryShares(ctx context.Context, expiresAt sql.NullTime) error
 	// Delete a user's history rows beyond the newest `offset` (the per-user cap),
 	// keeping history bounded on every insert.
 	PruneQueryHistoryForUser(ctx context.Context, arg PruneQueryHistoryForUserParams) error
+	// Keep only an SLO's newest evaluations.
+	PruneSLOEvaluations(ctx context.Context, arg PruneSLOEvaluationsParams) error
 	// Per-day total query count over rollup rows on/after `since`, ascending by day.
 	QueryVolumeByDay(ctx context.Context, bucketDate string) ([]QueryVolumeByDayRow, error)
 	// Remove an item from a collection
@@ -239,10 +380,14 @@ type Querier interface {
 	// Remove a data source from a team
 	RemoveTeamSource(ctx context.Context, arg RemoveTeamSourceParams) error
 	ResolveAlertHistory(ctx context.Context, arg ResolveAlertHistoryParams) (int64, error)
+	// Mark an alert as managed/unmanaged
+	SetAlertManaged(ctx context.Context, arg SetAlertManagedParams) error
 	// Mark a source as managed/unmanaged and set secret_ref
 	SetSourceManaged(ctx context.Context, arg SetSourceManagedParams) error
 	// Mark a team as managed/unmanaged
 	SetTeamManaged(ctx context.Context, arg SetTeamManagedParams) error
+	// Set the row filter on a team-source link; '' removes it
+	SetTeamSourceRowFilter(ctx context.Context, arg SetTeamSourceRowFilterParams) (int64, error)
 	// Mark a user as managed/unmanaged
 	SetUserManaged(ctx context.Context, arg SetUserManagedParams) error
 	// Set (or clear) a user's local-auth bcrypt hash
@@ -263,23 +408,60 @@ type Querier interface {
 	UpdateAPITokenLastUsed(ctx context.Context, id int64) error
 	UpdateAlert(ctx context.Context, arg UpdateAlertParams) (int64, error)
 	UpdateAlertHistoryPayload(ctx context.Context, arg UpdateAlertHistoryPayloadParams) (int64, error)
+	// Update a silence's reason and schedule; RETURNING lets callers detect not-found.
+	UpdateAlertSilence(ctx context.Context, arg UpdateAlertSilenceParams) (int64, error)
+	// Update an annotation's note, scope and range; RETURNING lets callers detect not-found.
+	UpdateAnnotation(ctx context.Context, arg UpdateAnnotationParams) (int64, error)
 	// Update name/description (owner only - enforced in app code)
 	UpdateCollection(ctx context.Context, arg UpdateCollectionParams) error
 	// Update a dashboard's mutable fields; RETURNING lets callers detect not-found.
 	UpdateDashboard(ctx context.Context, arg UpdateDashboardParams) (int64, error)
 	// Mark an export job as running and return its ID
 	UpdateExportJobRunning(ctx context.Context, arg UpdateExportJobRunningParams) (string, error)
+	// Update a bookmark's note and visibility; RETURNING lets callers detect not-found.
+	UpdateLogBookmark(ctx context.Context, arg UpdateLogBookmarkParams) (int64, error)
+	// Update a notebook's mutable fields; RETURNING lets callers detect not-found.
+	UpdateNotebook(ctx context.Context, arg UpdateNotebookParams) (int64, error)
+	// Replace only the cell blob, leaving updated_at alone: refreshing a cell's
+	// cached result is not an edit and must not trip the optimistic-concurrency
+	// check of someone editing the notebook.
+	UpdateNotebookCells(ctx context.Context, arg UpdateNotebookCellsParams) (int64, error)
+	// Update a snippet's name, description and query; RETURNING lets callers detect not-found.
+	UpdateQuerySnippet(ctx context.Context, arg UpdateQuerySnippetParams) (int64, error)
+	// Update an SLO's mutable fields; RETURNING lets callers detect not-found.
+	UpdateSLO(ctx context.Context, arg UpdateSLOParams) (int64, error)
 	// Update a saved query's mutable fields
 	UpdateSavedQuery(ctx context.Context, arg UpdateSavedQueryParams) error
 	// Update an existing source
 	UpdateSource(ctx context.Context, arg UpdateSourceParams) error
+	// Rewrite a source's connection config in place, e.g. to re-seal its secrets,
+	// without touching updated_at.
+	UpdateSourceConnectionConfig(ctx context.Context, arg UpdateSourceConnectionConfigParams) error
+	// Record the outcome of a rollup run. The dimension guard drops the update when
+	// the rollup was reconfigured while the run was in flight.
+	UpdateSourceRollupProgress(ctx context.Context, arg UpdateSourceRollupProgressParams) (int64, error)
 	// Update a team
 	UpdateTeam(ctx context.Context, arg UpdateTeamParams) error
 	// Update a team member's role
 	UpdateTeamMemberRole(ctx context.Context, arg UpdateTeamMemberRoleParams) error
 	// Update a user
 	UpdateUser(ctx context.Context, arg UpdateUserParams) error
+	// Replace a webhook's settings; RETURNING lets callers detect not-found.
+	UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (int64, error)
+	// Enable or reconfigure a source's rollup. Changing the dimension invalidates
+	// the materialised hours, so progress is reset and the rollup backfills again.
+	UpsertSourceRollup(ctx context.Context, arg UpsertSourceRollupParams) error
+	// Source stats snapshots ------------------------------------------------------
+	// Record a source's storage statistics for a day, replacing an earlier
+	// snapshot taken the same day.
+	UpsertSourceStatsSnapshot(ctx context.Context, arg UpsertSourceStatsSnapshotParams) error
 	UpsertSystemSetting(ctx context.Context, arg UpsertSystemSettingParams) error
+	// Save a team's default column layout for a source, replacing any earlier one.
+	UpsertTeamColumnPreset(ctx context.Context, arg UpsertTeamColumnPresetParams) error
+	// Tracked queries --------------------------------------------------------------
+	UpsertTrackedQuery(ctx context.Context, arg UpsertTrackedQueryParams) error
+	// Save a user's column layout for a source, replacing any earlier one.
+	UpsertUserColumnPreset(ctx context.Context, arg UpsertUserColumnPresetParams) error
 	// Insert or update user preferences
 	UpsertUserPreferences(ctx context.Context, arg UpsertUserPreferencesParams) error
 	// Check if a user has access to a source through any team
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/secrets"
 	"github.com/mr-karan/logchef/internal/store"
 	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
 
@@ -47,13 +48,17 @@ type DB struct {
 	readQueries  *sqlc.Queries // Prepared queries bound to the read connection pool
 	writeQueries *sqlc.Queries // Prepared queries bound to the write connection
 	log          *slog.Logger
-	inTx         bool // true on a tx-scoped handle; guards against nested WithTx
+	secrets      *secrets.Box // seals source connection secrets; nil stores plaintext
+	inTx         bool         // true on a tx-scoped handle; guards against nested WithTx
 }
 
 // Options holds configuration for creating a new DB instance.
 type Options struct {
 	Logger *slog.Logger
 	Config config.SQLiteConfig
+	// Secrets seals source connection secrets at rest; nil stores them in
+	// plaintext.
+	Secrets *secrets.Box
 }
 
 // New establishes a connection to the SQLite database, configures it,
@@ -61,6 +66,11 @@ type Options struct {
 func New(ctx context.Context, opts Options) (*DB, error) {
 	log := opts.Logger.With("component", "sqlite")
 
+	// Swap in a restore staged by StageRestore before anything opens the file.
+	if err := applyPendingRestore(ctx, opts.Config.Path, log); err != nil {
+		return nil, err
+	}
+
 	// Run migrations first using a temporary connection.
 	if err := setupAndRunMigrations(opts.Config.Path, log); err != nil {
 		return nil, err
@@ -71,7 +81,7 @@ func New(ctx context.Context, opts Options) (*DB, error) {
 	// the pool opens — not just the one that happens to run a PRAGMA statement,
 	// which is the database/sql pitfall that would leave most pooled read
 	// connections without busy_timeout/foreign_keys/etc.
-	readDB, err := sql.Open("sqlite", buildDSN(opts.Config.Path))
+	readDB, err := sql.Open(instrumentedDriverName, buildDSN(opts.Config.Path))
 	if err != nil {
 		log.Error("failed to open read database", "error", err, "path", opts.Config.Path)
 		return nil, fmt.Errorf("error opening read database: %w", err)
@@ -84,7 +94,7 @@ func New(ctx context.Context, opts Options) (*DB, error) {
 
 	// Open write connection with _txlock=immediate to acquire the write lock
 	// early. This prevents deadlocks when multiple goroutines compete for writes.
-	writeDB, err := sql.Open("sqlite", buildDSN(opts.Config.Path, "_txlock=immediate"))
+	writeDB, err := sql.Open(instrumentedDriverName, buildDSN(opts.Config.Path, "_txlock=immediate"))
 	if err != nil {
 		readDB.Close()
 		log.Error("failed to open write database", "error", err, "path", opts.Config.Path)
@@ -120,6 +130,7 @@ func New(ctx context.Context, opts Options) (*DB, error) {
 		readQueries:  readQueries,
 		writeQueries: writeQueries,
 		log:          log,
+		secrets:      opts.Secrets,
 	}, nil
 }
 
@@ -44,6 +44,7 @@ func (db *DB) CreateTeam(ctx context.Context, team *models.Team) error {
 	// Update input model with DB-generated timestamps.
 	team.CreatedAt = teamRow.CreatedAt
 	team.UpdatedAt = teamRow.UpdatedAt
+	team.RawSQLEnabled = teamRow.RawSqlEnabled == 1
 
 	return nil
 }
@@ -66,7 +67,8 @@ func (db *DB) GetTeam(ctx context.Context, teamID models.TeamID) (*models.Team,
 			CreatedAt: teamRow.CreatedAt,
 			UpdatedAt: teamRow.UpdatedAt,
 		},
-		Managed: teamRow.Managed == 1,
+		Managed:       teamRow.Managed == 1,
+		RawSQLEnabled: teamRow.RawSqlEnabled == 1,
 	}
 	return team, nil
 }
@@ -76,10 +78,11 @@ func (db *DB) GetTeam(ctx context.Context, teamID models.TeamID) (*models.Team,
 func (db *DB) UpdateTeam(ctx context.Context, team *models.Team) error {
 
 	params := sqlc.UpdateTeamParams{
-		Name:        team.Name,
-		Description: sql.NullString{String: team.Description, Valid: team.Description != ""},
-		UpdatedAt:   team.UpdatedAt, // Pass current time or let DB handle? Assuming passed in.
-		ID:          int64(team.ID),
+		Name:          team.Name,
+		Description:   sql.NullString{String: team.Description, Valid: team.Description != ""},
+		RawSqlEnabled: boolToInt(team.RawSQLEnabled),
+		UpdatedAt:     team.UpdatedAt, // Pass current time or let DB handle? Assuming passed in.
+		ID:            int64(team.ID),
 	}
 
 	err := db.writeQueries.UpdateTeam(ctx, params)
@@ -128,6 +131,7 @@ func (db *DB) ListTeams(ctx context.Context) ([]*models.Team, error) {
 				CreatedAt: row.CreatedAt,
 				UpdatedAt: row.UpdatedAt,
 			},
+			RawSQLEnabled: row.RawSqlEnabled == 1,
 		})
 	}
 
@@ -316,6 +320,7 @@ func (db *DB) ListUserTeams(ctx context.Context, userID models.UserID) ([]*model
 				CreatedAt: row.CreatedAt,
 				UpdatedAt: row.UpdatedAt,
 			},
+			RawSQLEnabled: row.RawSqlEnabled == 1,
 			// MemberCount not included in this query.
 		})
 	}
@@ -379,7 +384,7 @@ func (db *DB) ListTeamSources(ctx context.Context, teamID models.TeamID) ([]*mod
 	// Map results. Note: mapSourceRowToModel is in utility.go.
 	sources := make([]*models.Source, 0, len(sourceRows))
 	for i := range sourceRows {
-		mappedSource := mapSourceRowToModel(&sourceRows[i])
+		mappedSource := db.mapSourceRowToModel(&sourceRows[i])
 		if mappedSource != nil {
 			sources = append(sources, mappedSource)
 		}
@@ -408,6 +413,7 @@ func (db *DB) ListSourceTeams(ctx context.Context, sourceID models.SourceID) ([]
 				CreatedAt: row.CreatedAt,
 				UpdatedAt: row.UpdatedAt,
 			},
+			RawSQLEnabled: row.RawSqlEnabled == 1,
 		})
 	}
 
@@ -426,7 +432,7 @@ func (db *DB) ListSourcesForUser(ctx context.Context, userID models.UserID) ([]*
 	// Map results using the shared mapper.
 	sources := make([]*models.Source, 0, len(sourceRows))
 	for i := range sourceRows {
-		mappedSource := mapSourceRowToModel(&sourceRows[i])
+		mappedSource := db.mapSourceRowToModel(&sourceRows[i])
 		if mappedSource != nil {
 			sources = append(sources, mappedSource)
 		}
@@ -453,7 +459,8 @@ func (db *DB) GetTeamByName(ctx context.Context, name string) (*models.Team, err
 			CreatedAt: teamRow.CreatedAt,
 			UpdatedAt: teamRow.UpdatedAt,
 		},
-		Managed: teamRow.Managed == 1,
+		Managed:       teamRow.Managed == 1,
+		RawSQLEnabled: teamRow.RawSqlEnabled == 1,
 	}
 	return team, nil
 }
@@ -471,6 +478,39 @@ func (db *DB) TeamHasSource(ctx context.Context, teamID models.TeamID, sourceID
 	return hasAccess, nil
 }
 
+// GetTeamSourceRowFilter returns the row filter on a team-source link.
+func (db *DB) GetTeamSourceRowFilter(ctx context.Context, teamID models.TeamID, sourceID models.SourceID) (string, error) {
+	filter, err := db.readQueries.GetTeamSourceRowFilter(ctx, sqlc.GetTeamSourceRowFilterParams{
+		TeamID:   int64(teamID),
+		SourceID: int64(sourceID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", models.ErrNotFound
+		}
+		db.log.Error("failed to get team source row filter", "error", err, "team_id", teamID, "source_id", sourceID)
+		return "", fmt.Errorf("error getting team source row filter: %w", err)
+	}
+	return filter, nil
+}
+
+// SetTeamSourceRowFilter replaces the row filter on a team-source link.
+func (db *DB) SetTeamSourceRowFilter(ctx context.Context, teamID models.TeamID, sourceID models.SourceID, filter string) error {
+	n, err := db.writeQueries.SetTeamSourceRowFilter(ctx, sqlc.SetTeamSourceRowFilterParams{
+		RowFilter: filter,
+		TeamID:    int64(teamID),
+		SourceID:  int64(sourceID),
+	})
+	if err != nil {
+		db.log.Error("failed to set team source row filter", "error", err, "team_id", teamID, "source_id", sourceID)
+		return fmt.Errorf("error setting team source row filter: %w", err)
+	}
+	if n == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
 // UserHasSourceAccess checks if a user can access a specific source through any of their team memberships.
 func (db *DB) UserHasSourceAccess(ctx context.Context, userID models.UserID, sourceID models.SourceID) (bool, error) {
 	hasAccess, err := db.readQueries.UserHasSourceAccess(ctx, sqlc.UserHasSourceAccessParams{
@@ -484,6 +524,24 @@ func (db *DB) UserHasSourceAccess(ctx context.Context, userID models.UserID, sou
 	return hasAccess, nil
 }
 
+// ListUserSourceRoles returns the distinct team roles a user holds across the
+// teams that have access to a source. Empty means the user has no access.
+func (db *DB) ListUserSourceRoles(ctx context.Context, userID models.UserID, sourceID models.SourceID) ([]models.TeamRole, error) {
+	rows, err := db.readQueries.ListUserSourceRoles(ctx, sqlc.ListUserSourceRolesParams{
+		UserID:   int64(userID),
+		SourceID: int64(sourceID),
+	})
+	if err != nil {
+		db.log.Error("failed to list user source roles in db", "error", err, "user_id", userID, "source_id", sourceID)
+		return nil, fmt.Errorf("error listing user source roles: %w", err)
+	}
+	roles := make([]models.TeamRole, 0, len(rows))
+	for _, role := range rows {
+		roles = append(roles, models.TeamRole(role))
+	}
+	return roles, nil
+}
+
 // ListTeamsForUser retrieves all teams a specific user is a member of, along
 // with their role and the team's member count.
 func (db *DB) ListTeamsForUser(ctx context.Context, userID models.UserID) ([]*models.UserTeamDetails, error) {
@@ -500,13 +558,14 @@ func (db *DB) ListTeamsForUser(ctx context.Context, userID models.UserID) ([]*mo
 			desc = row.Description.String
 		}
 		userTeams = append(userTeams, &models.UserTeamDetails{
-			ID:          models.TeamID(row.ID),
-			Name:        row.Name,
-			Description: desc,
-			CreatedAt:   row.CreatedAt,
-			UpdatedAt:   row.UpdatedAt,
-			MemberCount: int(row.MemberCount),
-			Role:        models.TeamRole(row.Role),
+			ID:            models.TeamID(row.ID),
+			Name:          row.Name,
+			Description:   desc,
+			CreatedAt:     row.CreatedAt,
+			UpdatedAt:     row.UpdatedAt,
+			MemberCount:   int(row.MemberCount),
+			Role:          models.TeamRole(row.Role),
+			RawSQLEnabled: row.RawSqlEnabled == 1,
 		})
 	}
 	return userTeams, nil
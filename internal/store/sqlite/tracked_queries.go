@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// UpsertTrackedQuery persists a running query; an existing row is kept.
+func (db *DB) UpsertTrackedQuery(ctx context.Context, query *models.TrackedQuery) error {
+	err := db.writeQueries.UpsertTrackedQuery(ctx, sqlc.UpsertTrackedQueryParams{
+		ID:        query.ID,
+		Instance:  query.Instance,
+		Class:     query.Class,
+		UserID:    int64(query.UserID),
+		TeamID:    int64(query.TeamID),
+		SourceID:  int64(query.SourceID),
+		QueryText: query.QueryText,
+		StartedAt: query.StartedAt.UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("error saving tracked query %s: %w", query.ID, err)
+	}
+	return nil
+}
+
+// DeleteTrackedQuery removes a persisted query.
+func (db *DB) DeleteTrackedQuery(ctx context.Context, id string) error {
+	if err := db.writeQueries.DeleteTrackedQuery(ctx, id); err != nil {
+		return fmt.Errorf("error deleting tracked query %s: %w", id, err)
+	}
+	return nil
+}
+
+// ListTrackedQueries returns the queries persisted by instance, oldest first.
+func (db *DB) ListTrackedQueries(ctx context.Context, instance string) ([]*models.TrackedQuery, error) {
+	rows, err := db.readQueries.ListTrackedQueries(ctx, instance)
+	if err != nil {
+		return nil, fmt.Errorf("error listing tracked queries: %w", err)
+	}
+	queries := make([]*models.TrackedQuery, 0, len(rows))
+	for _, row := range rows {
+		queries = append(queries, &models.TrackedQuery{
+			ID:        row.ID,
+			Instance:  row.Instance,
+			Class:     row.Class,
+			UserID:    models.UserID(row.UserID),
+			TeamID:    models.TeamID(row.TeamID),
+			SourceID:  models.SourceID(row.SourceID),
+			QueryText: row.QueryText,
+			StartedAt: row.StartedAt,
+		})
+	}
+	return queries, nil
+}
+
+// DeleteTrackedQueriesBefore removes queries of any instance started before
+// the cutoff.
+func (db *DB) DeleteTrackedQueriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	n, err := db.writeQueries.DeleteTrackedQueriesBefore(ctx, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("error pruning tracked queries: %w", err)
+	}
+	return n, nil
+}
@@ -75,8 +75,10 @@ func nullString(value string) sql.NullString {
 	return sql.NullString{String: value, Valid: value != ""}
 }
 
-// mapSourceRowToModel maps a sqlc.Source to a models.Source
-func mapSourceRowToModel(row *sqlc.Source) *models.Source {
+// mapSourceRowToModel maps a sqlc.Source to a models.Source, opening its
+// sealed connection secrets. A secret that can't be opened is logged and left
+// sealed, so the source fails to connect rather than failing every listing.
+func (db *DB) mapSourceRowToModel(row *sqlc.Source) *models.Source {
 	if row == nil {
 		return nil
 	}
@@ -91,6 +93,10 @@ func mapSourceRowToModel(row *sqlc.Source) *models.Source {
 		TTLDays:           int(row.TtlDays),
 		ConnectionConfig:  []byte(row.ConnectionConfig),
 		IdentityKey:       row.IdentityKey,
+		Tags:              models.DecodeSourceTags([]byte(row.Tags)),
+		ExtractionRules:   models.DecodeExtractionRules([]byte(row.ExtractionRules)),
+		TraceCorrelation:  models.DecodeTraceCorrelation([]byte(row.TraceCorrelation)),
+		MaskedColumns:     models.DecodeMaskedColumns([]byte(row.MaskedColumns)),
 		Timestamps: models.Timestamps{
 			CreatedAt: row.CreatedAt,
 			UpdatedAt: row.UpdatedAt,
@@ -99,6 +105,11 @@ func mapSourceRowToModel(row *sqlc.Source) *models.Source {
 		SecretRef: row.SecretRef.String,
 	}
 
+	if conn, err := db.secrets.OpenConnection(source.SourceType, source.ConnectionConfig); err != nil {
+		db.log.Error("failed to decrypt source secrets", "error", err, "source_id", row.ID)
+	} else {
+		source.ConnectionConfig = conn
+	}
 	_ = source.HydrateConnection()
 
 	return source
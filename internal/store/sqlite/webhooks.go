@@ -0,0 +1,163 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mr-karan/logchef/internal/store/alertjson"
+	"github.com/mr-karan/logchef/internal/store/sqlite/sqlc"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// webhookSecretField binds sealed webhook secrets to their column.
+const webhookSecretField = "webhook.secret"
+
+// CreateWebhook inserts a new webhook and repopulates the model with the
+// persisted row (id and timestamps).
+func (db *DB) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	if webhook == nil {
+		return fmt.Errorf("webhook payload is required")
+	}
+	eventTypes, secret, err := db.encodeWebhook(webhook)
+	if err != nil {
+		return err
+	}
+	params := sqlc.CreateWebhookParams{
+		Name:           webhook.Name,
+		Url:            webhook.URL,
+		EventTypesJson: eventTypes,
+		Secret:         secret,
+		IsActive:       boolToInt(webhook.IsActive),
+	}
+	if webhook.CreatedBy != nil {
+		params.CreatedBy = sql.NullInt64{Int64: int64(*webhook.CreatedBy), Valid: true}
+	}
+
+	id, err := db.writeQueries.CreateWebhook(ctx, params)
+	if err != nil {
+		db.log.Error("failed to create webhook", "error", err)
+		return fmt.Errorf("error creating webhook: %w", err)
+	}
+
+	created, err := db.GetWebhook(ctx, models.WebhookID(id))
+	if err != nil {
+		return err
+	}
+	*webhook = *created
+	return nil
+}
+
+// GetWebhook returns a webhook by id, or models.ErrNotFound if missing.
+func (db *DB) GetWebhook(ctx context.Context, id models.WebhookID) (*models.Webhook, error) {
+	row, err := db.readQueries.GetWebhook(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting webhook id %d: %w", id, err)
+	}
+	return db.mapWebhookRow(row)
+}
+
+// ListWebhooks returns every webhook, by name.
+func (db *DB) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	rows, err := db.readQueries.ListWebhooks(ctx)
+	if err != nil {
+		db.log.Error("failed to list webhooks", "error", err)
+		return nil, fmt.Errorf("error listing webhooks: %w", err)
+	}
+	webhooks := make([]*models.Webhook, 0, len(rows))
+	for _, row := range rows {
+		webhook, err := db.mapWebhookRow(row)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook overwrites a webhook's settings and secret. Returns
+// models.ErrNotFound when the id does not exist.
+func (db *DB) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	if webhook == nil {
+		return fmt.Errorf("webhook payload is required")
+	}
+	eventTypes, secret, err := db.encodeWebhook(webhook)
+	if err != nil {
+		return err
+	}
+	_, err = db.writeQueries.UpdateWebhook(ctx, sqlc.UpdateWebhookParams{
+		Name:           webhook.Name,
+		Url:            webhook.URL,
+		EventTypesJson: eventTypes,
+		Secret:         secret,
+		IsActive:       boolToInt(webhook.IsActive),
+		ID:             int64(webhook.ID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to update webhook", "error", err, "webhook_id", webhook.ID)
+		return fmt.Errorf("error updating webhook: %w", err)
+	}
+	return nil
+}
+
+// DeleteWebhook removes a webhook. Returns models.ErrNotFound when the id does
+// not exist.
+func (db *DB) DeleteWebhook(ctx context.Context, id models.WebhookID) error {
+	if _, err := db.writeQueries.DeleteWebhook(ctx, int64(id)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		db.log.Error("failed to delete webhook", "error", err, "webhook_id", id)
+		return fmt.Errorf("error deleting webhook: %w", err)
+	}
+	return nil
+}
+
+// encodeWebhook returns the webhook's event types as JSON and its secret
+// sealed for storage.
+func (db *DB) encodeWebhook(webhook *models.Webhook) (eventTypes, secret string, err error) {
+	eventTypes, err = alertjson.Encode(webhook.EventTypes, false)
+	if err != nil {
+		return "", "", fmt.Errorf("error encoding webhook event types: %w", err)
+	}
+	secret, err = db.secrets.Seal(webhookSecretField, webhook.Secret)
+	if err != nil {
+		return "", "", fmt.Errorf("error sealing webhook secret: %w", err)
+	}
+	return eventTypes, secret, nil
+}
+
+func (db *DB) mapWebhookRow(row sqlc.Webhook) (*models.Webhook, error) {
+	eventTypes, err := alertjson.Decode[[]models.EventType](row.EventTypesJson)
+	if err != nil {
+		return nil, fmt.Errorf("decoding event types of webhook %d: %w", row.ID, err)
+	}
+	secret, err := db.secrets.Open(webhookSecretField, row.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("opening secret of webhook %d: %w", row.ID, err)
+	}
+	webhook := &models.Webhook{
+		ID:         models.WebhookID(row.ID),
+		Name:       row.Name,
+		URL:        row.Url,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		IsActive:   row.IsActive == 1,
+		Timestamps: models.Timestamps{
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		},
+	}
+	if row.CreatedBy.Valid {
+		uid := models.UserID(row.CreatedBy.Int64)
+		webhook.CreatedBy = &uid
+	}
+	return webhook, nil
+}
@@ -17,6 +17,7 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"time"
 
@@ -36,6 +37,14 @@ type TxRunner interface {
 	WithTx(ctx context.Context, fn func(tx StoreOps) error) error
 }
 
+// Backuper is implemented by backends that can snapshot themselves while
+// serving (SQLite, via its online backup API). It is optional: callers
+// type-assert for it. Postgres deployments back up with pg_dump instead.
+type Backuper interface {
+	// Backup writes a consistent snapshot of the database to dest.
+	Backup(ctx context.Context, dest string) error
+}
+
 // SessionStore persists authentication sessions.
 type SessionStore interface {
 	CreateSession(ctx context.Context, session *models.Session) error
@@ -78,6 +87,11 @@ type SourceStore interface {
 	ListSources(ctx context.Context) ([]*models.Source, error)
 	UpdateSource(ctx context.Context, source *models.Source) error
 	DeleteSource(ctx context.Context, id models.SourceID) error
+	// SealSourceSecrets encrypts every source's plaintext connection secrets,
+	// and re-encrypts those sealed under a previous key, with the store's
+	// current key. It returns how many sources changed, or secrets.ErrNoKey
+	// when the store has no key.
+	SealSourceSecrets(ctx context.Context) (int, error)
 }
 
 // SavedQueryStore persists named, reusable queries. Visibility/edit rules are
@@ -123,6 +137,38 @@ type DashboardStore interface {
 	DeleteDashboard(ctx context.Context, id int) error
 }
 
+// NotebookStore persists team-scoped notebooks. The cell blob is validated in
+// models/core, not here. Reads/mutations on a missing id return
+// models.ErrNotFound.
+type NotebookStore interface {
+	CreateNotebook(ctx context.Context, notebook *models.Notebook) error
+	GetNotebook(ctx context.Context, id int) (*models.Notebook, error)
+	// ListNotebooksByTeam returns the team's notebooks without their cells.
+	ListNotebooksByTeam(ctx context.Context, teamID models.TeamID) ([]*models.Notebook, error)
+	UpdateNotebook(ctx context.Context, notebook *models.Notebook) error
+	// UpdateNotebookCells replaces only the cell blob (e.g. to cache a cell
+	// result) without advancing updated_at. It is a compare-and-swap: the
+	// write only lands while the stored blob still equals previous, and
+	// models.ErrNotFound is returned otherwise, as for a missing notebook.
+	UpdateNotebookCells(ctx context.Context, id int, previous, cells json.RawMessage) error
+	DeleteNotebook(ctx context.Context, id int) error
+}
+
+// NotebookSnapshotStore persists the metadata of stored notebook reports; the
+// reports themselves live in artifact storage. Snapshots are not removed with
+// their notebook: they expire, and the cleanup loop deletes the object before
+// the row.
+type NotebookSnapshotStore interface {
+	CreateNotebookSnapshot(ctx context.Context, snapshot *models.NotebookSnapshot) error
+	GetNotebookSnapshot(ctx context.Context, id string) (*models.NotebookSnapshot, error)
+	// ListNotebookSnapshots returns a notebook's snapshots, newest first.
+	ListNotebookSnapshots(ctx context.Context, notebookID int) ([]*models.NotebookSnapshot, error)
+	// ListExpiredNotebookSnapshots returns snapshots whose expires_at is
+	// before the given time.
+	ListExpiredNotebookSnapshots(ctx context.Context, before time.Time) ([]*models.NotebookSnapshot, error)
+	DeleteNotebookSnapshot(ctx context.Context, id string) error
+}
+
 // AlertStore persists alert definitions and their evaluation history.
 type AlertStore interface {
 	CreateAlert(ctx context.Context, alert *models.Alert) error
@@ -134,20 +180,145 @@ type AlertStore interface {
 	ListActiveAlertsDue(ctx context.Context) ([]*models.Alert, error)
 	MarkAlertEvaluated(ctx context.Context, alertID models.AlertID) error
 	MarkAlertTriggered(ctx context.Context, alertID models.AlertID) error
+	// MarkAlertPending records a breach that does not fire the alert yet and
+	// returns when the current run of breaches started and its length.
+	MarkAlertPending(ctx context.Context, alertID models.AlertID) (time.Time, int, error)
+	// AcquireAlertLease claims the alert's evaluation lease for holder until
+	// expiresAt, or renews holder's own, and reports whether holder has it.
+	// Another holder's lease is only taken once it expired before now.
+	AcquireAlertLease(ctx context.Context, alertID models.AlertID, holder string, now, expiresAt time.Time) (bool, error)
+	// ReleaseAlertLeases drops every lease holder has.
+	ReleaseAlertLeases(ctx context.Context, holder string) error
 	InsertAlertHistory(ctx context.Context, alertID models.AlertID, status models.AlertStatus, value *float64, message string, payload map[string]any) (*models.AlertHistoryEntry, error)
 	GetLatestUnresolvedAlertHistory(ctx context.Context, alertID models.AlertID) (*models.AlertHistoryEntry, error)
 	ResolveAlertHistory(ctx context.Context, historyID int64, message string) error
 	UpdateAlertHistoryPayload(ctx context.Context, historyID int64, payload map[string]any) error
 	ListAlertHistory(ctx context.Context, alertID models.AlertID, limit int) ([]*models.AlertHistoryEntry, error)
+	// ListAlertHistorySince returns the alert's history entries that were
+	// still open at or after since, oldest first.
+	ListAlertHistorySince(ctx context.Context, alertID models.AlertID, since time.Time) ([]*models.AlertHistoryEntry, error)
 	PruneAlertHistory(ctx context.Context, alertID models.AlertID, keep int) error
 }
 
-// QueryHistoryStore persists per-user query execution history. Recording is
-// best-effort (callers fire-and-forget on the query path) and self-pruning:
-// RecordQueryHistory caps each user's history at models.QueryHistoryPerUserCap.
+// SLOStore persists service-level objectives and their evaluation history.
+// Reads and mutations on a missing id return models.ErrNotFound.
+type SLOStore interface {
+	// CreateSLO inserts slo and repopulates it with the persisted row.
+	CreateSLO(ctx context.Context, slo *models.SLO) error
+	GetSLO(ctx context.Context, id models.SLOID) (*models.SLO, error)
+	ListSLOsByTeam(ctx context.Context, teamID models.TeamID) ([]*models.SLO, error)
+	// ListSLOs returns every SLO, for the periodic evaluator.
+	ListSLOs(ctx context.Context) ([]*models.SLO, error)
+	UpdateSLO(ctx context.Context, slo *models.SLO) error
+	DeleteSLO(ctx context.Context, id models.SLOID) error
+	// InsertSLOEvaluation appends one evaluation, populating its ID.
+	InsertSLOEvaluation(ctx context.Context, eval *models.SLOEvaluation) error
+	// ListSLOEvaluations returns an SLO's newest evaluations, newest first.
+	ListSLOEvaluations(ctx context.Context, id models.SLOID, limit int) ([]*models.SLOEvaluation, error)
+	// PruneSLOEvaluations keeps only the SLO's keep newest evaluations.
+	PruneSLOEvaluations(ctx context.Context, id models.SLOID, keep int) error
+}
+
+// AlertSilenceStore persists alert silences and maintenance windows. Reads and
+// mutations on a missing id return models.ErrNotFound.
+type AlertSilenceStore interface {
+	// CreateAlertSilence inserts silence and repopulates it with the persisted row.
+	CreateAlertSilence(ctx context.Context, silence *models.AlertSilence) error
+	GetAlertSilence(ctx context.Context, id models.SilenceID) (*models.AlertSilence, error)
+	// ListAlertSilences returns every silence, newest start first.
+	ListAlertSilences(ctx context.Context) ([]*models.AlertSilence, error)
+	// ListUnexpiredAlertSilences returns the silences that have not ended by
+	// now, for the alert manager's dispatch check.
+	ListUnexpiredAlertSilences(ctx context.Context, now time.Time) ([]*models.AlertSilence, error)
+	UpdateAlertSilence(ctx context.Context, silence *models.AlertSilence) error
+	DeleteAlertSilence(ctx context.Context, id models.SilenceID) error
+}
+
+// WebhookStore persists outbound webhooks. Their signing secrets are sealed
+// at rest when an encryption key is configured. Reads and mutations on a
+// missing id return models.ErrNotFound.
+type WebhookStore interface {
+	// CreateWebhook inserts webhook and repopulates it with the persisted row.
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+	GetWebhook(ctx context.Context, id models.WebhookID) (*models.Webhook, error)
+	// ListWebhooks returns every webhook, by name.
+	ListWebhooks(ctx context.Context) ([]*models.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook *models.Webhook) error
+	DeleteWebhook(ctx context.Context, id models.WebhookID) error
+}
+
+// AnnotationStore persists timeline annotations. Reads and mutations on a
+// missing id return models.ErrNotFound.
+type AnnotationStore interface {
+	// CreateAnnotation inserts annotation and repopulates it with the persisted row.
+	CreateAnnotation(ctx context.Context, annotation *models.Annotation) error
+	GetAnnotation(ctx context.Context, id models.AnnotationID) (*models.Annotation, error)
+	// ListAnnotationsByTeam returns a team's annotations, newest start first.
+	ListAnnotationsByTeam(ctx context.Context, teamID models.TeamID) ([]*models.Annotation, error)
+	// ListAnnotationsInRange returns the team's annotations that overlap
+	// [start, end] and apply to sourceID (team-wide or the source's own),
+	// oldest first.
+	ListAnnotationsInRange(ctx context.Context, teamID models.TeamID, sourceID models.SourceID, start, end time.Time) ([]*models.Annotation, error)
+	UpdateAnnotation(ctx context.Context, annotation *models.Annotation) error
+	DeleteAnnotation(ctx context.Context, id models.AnnotationID) error
+}
+
+// LogBookmarkStore persists pinned log rows. Reads and mutations on a missing
+// id return models.ErrNotFound; pinning a row the user already pinned from
+// the same source returns models.ErrConflict.
+type LogBookmarkStore interface {
+	// CreateLogBookmark inserts bookmark and repopulates it with the persisted row.
+	CreateLogBookmark(ctx context.Context, bookmark *models.LogBookmark) error
+	GetLogBookmark(ctx context.Context, id models.LogBookmarkID) (*models.LogBookmark, error)
+	// ListLogBookmarksForUser returns the bookmarks userID sees in teamID (their
+	// own and the team's shared ones), most recently pinned first.
+	ListLogBookmarksForUser(ctx context.Context, teamID models.TeamID, userID models.UserID) ([]*models.LogBookmark, error)
+	// UpdateLogBookmark overwrites a bookmark's note and visibility.
+	UpdateLogBookmark(ctx context.Context, bookmark *models.LogBookmark) error
+	DeleteLogBookmark(ctx context.Context, id models.LogBookmarkID) error
+}
+
+// ColumnPresetStore persists saved log table layouts: one per user and source,
+// and one team default per team and source. Gets and deletes of a missing
+// preset return models.ErrNotFound.
+type ColumnPresetStore interface {
+	GetUserColumnPreset(ctx context.Context, userID models.UserID, sourceID models.SourceID) (*models.ColumnPreset, error)
+	// SaveUserColumnPreset creates or replaces preset (UserID set) and
+	// repopulates it with the persisted row.
+	SaveUserColumnPreset(ctx context.Context, preset *models.ColumnPreset) error
+	DeleteUserColumnPreset(ctx context.Context, userID models.UserID, sourceID models.SourceID) error
+	GetTeamColumnPreset(ctx context.Context, teamID models.TeamID, sourceID models.SourceID) (*models.ColumnPreset, error)
+	// SaveTeamColumnPreset creates or replaces preset (TeamID set) and
+	// repopulates it with the persisted row.
+	SaveTeamColumnPreset(ctx context.Context, preset *models.ColumnPreset) error
+	DeleteTeamColumnPreset(ctx context.Context, teamID models.TeamID, sourceID models.SourceID) error
+}
+
+// QuerySnippetStore persists teams' reusable LogchefQL snippets. Reads and
+// mutations on a missing id return models.ErrNotFound; a name already taken in
+// the team returns models.ErrConflict.
+type QuerySnippetStore interface {
+	// CreateQuerySnippet inserts snippet and repopulates it with the persisted row.
+	CreateQuerySnippet(ctx context.Context, snippet *models.QuerySnippet) error
+	GetQuerySnippet(ctx context.Context, id models.QuerySnippetID) (*models.QuerySnippet, error)
+	// ListQuerySnippetsByTeam returns a team's snippets ordered by name.
+	ListQuerySnippetsByTeam(ctx context.Context, teamID models.TeamID) ([]*models.QuerySnippet, error)
+	// UpdateQuerySnippet overwrites a snippet's name, description and query.
+	UpdateQuerySnippet(ctx context.Context, snippet *models.QuerySnippet) error
+	DeleteQuerySnippet(ctx context.Context, id models.QuerySnippetID) error
+}
+
+// QueryHistoryStore persists query execution history. Recording is best-effort
+// (callers fire-and-forget on the query path) and self-pruning:
+// RecordQueryHistory caps each user's history at keepPerUser entries.
 type QueryHistoryStore interface {
-	RecordQueryHistory(ctx context.Context, entry *models.QueryHistory) error
-	ListQueryHistory(ctx context.Context, userID models.UserID, limit int) ([]*models.QueryHistory, error)
+	RecordQueryHistory(ctx context.Context, entry *models.QueryHistory, keepPerUser int) error
+	// ListQueryHistory returns a page of the history matching filter, newest
+	// first; CountQueryHistory is the number of matching entries.
+	ListQueryHistory(ctx context.Context, filter models.QueryHistoryFilter) ([]*models.QueryHistory, error)
+	CountQueryHistory(ctx context.Context, filter models.QueryHistoryFilter) (int, error)
+	// DeleteQueryHistoryBefore enforces the retention window.
+	DeleteQueryHistoryBefore(ctx context.Context, before time.Time) (int64, error)
 	// ListQueryActivity returns the most recent query_history rows across all
 	// users (newest first, capped at limit), enriched with user email and
 	// source name. It backs the admin recent-activity view; because
@@ -155,11 +326,15 @@ type QueryHistoryStore interface {
 	ListQueryActivity(ctx context.Context, limit int) ([]models.QueryActivityRecord, error)
 
 	// IncrementQueryStats upserts one executed query into the non-pruned
-	// query_stats_daily rollup: it adds 1 to query_count and durationMs to
-	// total_duration_ms for the (bucketDate,userID,teamID,sourceID,language)
-	// key. bucketDate is 'YYYY-MM-DD' (UTC). Called at record time (best-effort)
-	// so all-time usage analytics stay correct despite query_history pruning.
-	IncrementQueryStats(ctx context.Context, bucketDate string, userID models.UserID, teamID models.TeamID, sourceID models.SourceID, language models.QueryLanguage, durationMs int64) error
+	// query_stats_daily rollup: it adds 1 to query_count, durationMs to
+	// total_duration_ms and rowsRead to rows_read for the
+	// (bucketDate,userID,teamID,sourceID,language) key. bucketDate is
+	// 'YYYY-MM-DD' (UTC). Called at record time (best-effort) so all-time usage
+	// analytics stay correct despite query_history pruning.
+	IncrementQueryStats(ctx context.Context, bucketDate string, userID models.UserID, teamID models.TeamID, sourceID models.SourceID, language models.QueryLanguage, durationMs, rowsRead int64) error
+	// GetTeamQueryUsage sums a team's queries and rows read on bucketDate
+	// ('YYYY-MM-DD', UTC). A day without queries is all zeros.
+	GetTeamQueryUsage(ctx context.Context, teamID models.TeamID, bucketDate string) (*models.TeamQueryUsage, error)
 	// TopSourcesByQueries returns sources ordered by total query count desc
 	// (capped at limit) over rollup rows with bucket_date >= since. source_name
 	// is "" when the source row is gone (LEFT JOIN); avg_duration_ms is
@@ -173,6 +348,89 @@ type QueryHistoryStore interface {
 	QueryVolumeByDay(ctx context.Context, since string) ([]models.DailyQueryVolume, error)
 }
 
+// AuditStore persists the append-only audit trail of sensitive operations.
+// Events are written asynchronously by internal/audit and never updated.
+type AuditStore interface {
+	// InsertAuditEvent appends one event, populating its ID. A zero CreatedAt
+	// is stamped with the current time.
+	InsertAuditEvent(ctx context.Context, event *models.AuditEvent) error
+	// ListAuditEvents returns events matching filter, newest first.
+	ListAuditEvents(ctx context.Context, filter models.AuditEventFilter) ([]*models.AuditEvent, error)
+}
+
+// RollupStore persists per-source rollup configuration and progress. The
+// rolled-up counts themselves live in ClickHouse (see internal/rollups).
+type RollupStore interface {
+	// GetSourceRollup returns models.ErrNotFound when the source has no rollup.
+	GetSourceRollup(ctx context.Context, sourceID models.SourceID) (*models.SourceRollup, error)
+	ListSourceRollups(ctx context.Context) ([]*models.SourceRollup, error)
+	// UpsertSourceRollup enables a source's rollup or changes its dimension;
+	// a changed dimension resets the materialised range.
+	UpsertSourceRollup(ctx context.Context, sourceID models.SourceID, dimension string) error
+	// UpdateSourceRollupProgress records a run's outcome (range, last run and
+	// error) for rollup.SourceID. It returns models.ErrNotFound when the rollup
+	// was removed or its dimension changed since rollup was read.
+	UpdateSourceRollupProgress(ctx context.Context, rollup *models.SourceRollup) error
+	DeleteSourceRollup(ctx context.Context, sourceID models.SourceID) error
+}
+
+// SourceStatsStore persists the daily storage snapshots taken by the source
+// stats scheduler (see internal/sourcestats).
+type SourceStatsStore interface {
+	// UpsertSourceStatsSnapshot stores a snapshot, replacing one taken for the
+	// same source on the same day.
+	UpsertSourceStatsSnapshot(ctx context.Context, snapshot *models.SourceStatsSnapshot) error
+	// ListSourceStatsSnapshots returns a source's snapshots dated on or after
+	// sinceDate (YYYY-MM-DD), oldest first.
+	ListSourceStatsSnapshots(ctx context.Context, sourceID models.SourceID, sinceDate string) ([]*models.SourceStatsSnapshot, error)
+	// DeleteSourceStatsSnapshotsBefore removes snapshots dated before
+	// beforeDate and returns how many were removed.
+	DeleteSourceStatsSnapshotsBefore(ctx context.Context, beforeDate string) (int64, error)
+}
+
+// TrackedQueryStore persists long-running queries from the server's query
+// tracker so a restarted instance can kill what it left running.
+type TrackedQueryStore interface {
+	// UpsertTrackedQuery stores a query; storing the same ID again is a no-op.
+	UpsertTrackedQuery(ctx context.Context, query *models.TrackedQuery) error
+	// DeleteTrackedQuery removes a query. A missing ID is not an error.
+	DeleteTrackedQuery(ctx context.Context, id string) error
+	// ListTrackedQueries returns an instance's queries, oldest first.
+	ListTrackedQueries(ctx context.Context, instance string) ([]*models.TrackedQuery, error)
+	// DeleteTrackedQueriesBefore removes queries of any instance started
+	// before the cutoff and returns how many were removed.
+	DeleteTrackedQueriesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SchemaSnapshotStore persists the column history recorded by the schema
+// drift scheduler (see internal/schemadrift).
+type SchemaSnapshotStore interface {
+	// InsertSourceSchemaSnapshot stores a snapshot and sets its ID.
+	InsertSourceSchemaSnapshot(ctx context.Context, snapshot *models.SourceSchemaSnapshot) error
+	// GetLatestSourceSchemaSnapshot returns a source's most recent snapshot, or
+	// models.ErrNotFound if it has none.
+	GetLatestSourceSchemaSnapshot(ctx context.Context, sourceID models.SourceID) (*models.SourceSchemaSnapshot, error)
+	// ListSourceSchemaSnapshots returns up to limit of a source's snapshots,
+	// newest first.
+	ListSourceSchemaSnapshots(ctx context.Context, sourceID models.SourceID, limit int) ([]*models.SourceSchemaSnapshot, error)
+	// DeleteSourceSchemaSnapshotsBefore removes snapshots captured before the
+	// cutoff, except each source's latest, and returns how many were removed.
+	DeleteSourceSchemaSnapshotsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SourceAlertStore persists the events raised by source degradation alerts
+// (see internal/sourcealerts), kept apart from user alerts' history.
+type SourceAlertStore interface {
+	// InsertSourceAlertEvent stores an event and sets its ID.
+	InsertSourceAlertEvent(ctx context.Context, event *models.SourceAlertEvent) error
+	// ListSourceAlertEvents returns up to limit of a source's events, newest
+	// first.
+	ListSourceAlertEvents(ctx context.Context, sourceID models.SourceID, limit int) ([]*models.SourceAlertEvent, error)
+	// DeleteSourceAlertEventsBefore removes events created before the cutoff
+	// and returns how many were removed.
+	DeleteSourceAlertEventsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
 // ExportJobStore persists asynchronous CSV/export job records.
 type ExportJobStore interface {
 	CreateExportJob(ctx context.Context, job *models.ExportJob) error
@@ -202,15 +460,18 @@ type ProvisioningStore interface {
 	IsSourceManaged(ctx context.Context, id models.SourceID) (bool, error)
 	IsTeamManaged(ctx context.Context, id models.TeamID) (bool, error)
 	IsUserManaged(ctx context.Context, id models.UserID) (bool, error)
+	IsAlertManaged(ctx context.Context, id models.AlertID) (bool, error)
 
 	ListManagedSources(ctx context.Context) ([]*models.Source, error)
 	ListManagedTeams(ctx context.Context) ([]*models.Team, error)
+	ListManagedAlerts(ctx context.Context) ([]*models.Alert, error)
 	// GetSourceByNameForProvisioning looks a source up by name (managed or not),
 	// returning models.ErrNotFound when absent.
 	GetSourceByNameForProvisioning(ctx context.Context, name string) (*models.Source, error)
 	SetSourceManaged(ctx context.Context, id models.SourceID, managed bool, secretRef string) error
 	SetTeamManaged(ctx context.Context, id models.TeamID, managed bool) error
 	SetUserManaged(ctx context.Context, id models.UserID, managed bool) error
+	SetAlertManaged(ctx context.Context, id models.AlertID, managed bool) error
 }
 
 // TeamStore persists teams, their membership, and the team↔source links that
@@ -238,7 +499,14 @@ type TeamStore interface {
 	ListSourceTeams(ctx context.Context, sourceID models.SourceID) ([]*models.Team, error)
 	ListSourcesForUser(ctx context.Context, userID models.UserID) ([]*models.Source, error)
 	TeamHasSource(ctx context.Context, teamID models.TeamID, sourceID models.SourceID) (bool, error)
+	// GetTeamSourceRowFilter returns the row filter on a team-source link, ""
+	// when there is none, or models.ErrNotFound when the source isn't linked.
+	GetTeamSourceRowFilter(ctx context.Context, teamID models.TeamID, sourceID models.SourceID) (string, error)
+	// SetTeamSourceRowFilter replaces the row filter on a team-source link; ""
+	// removes it. It returns models.ErrNotFound when the source isn't linked.
+	SetTeamSourceRowFilter(ctx context.Context, teamID models.TeamID, sourceID models.SourceID, filter string) error
 	UserHasSourceAccess(ctx context.Context, userID models.UserID, sourceID models.SourceID) (bool, error)
+	ListUserSourceRoles(ctx context.Context, userID models.UserID, sourceID models.SourceID) ([]models.TeamRole, error)
 }
 
 // SettingsStore persists system settings. The typed getters return a default
@@ -284,8 +552,23 @@ type StoreOps interface {
 	SavedQueryStore
 	CollectionStore
 	DashboardStore
+	NotebookStore
+	NotebookSnapshotStore
 	AlertStore
+	SLOStore
+	AlertSilenceStore
+	WebhookStore
+	AnnotationStore
+	LogBookmarkStore
+	ColumnPresetStore
+	QuerySnippetStore
 	QueryHistoryStore
+	AuditStore
+	RollupStore
+	SourceStatsStore
+	SchemaSnapshotStore
+	SourceAlertStore
+	TrackedQueryStore
 	ExportJobStore
 	QueryShareStore
 	ProvisioningStore
@@ -23,15 +23,35 @@ func Run(t *testing.T, s store.Store) {
 	t.Run("Users", func(t *testing.T) { testUsers(t, ctx, s) })
 	t.Run("UserPasswordHash", func(t *testing.T) { testUserPasswordHash(t, ctx, s) })
 	t.Run("TeamsMembersSources", func(t *testing.T) { testTeams(t, ctx, s) })
+	t.Run("SourceTags", func(t *testing.T) { testSourceTags(t, ctx, s) })
+	t.Run("SourceExtractionRules", func(t *testing.T) { testSourceExtractionRules(t, ctx, s) })
+	t.Run("SourceTraceCorrelation", func(t *testing.T) { testSourceTraceCorrelation(t, ctx, s) })
+	t.Run("SourceMaskedColumns", func(t *testing.T) { testSourceMaskedColumns(t, ctx, s) })
 	t.Run("Sessions", func(t *testing.T) { testSessions(t, ctx, s) })
 	t.Run("Settings", func(t *testing.T) { testSettings(t, ctx, s) })
 	t.Run("SavedQueriesCollections", func(t *testing.T) { testSavedQueriesCollections(t, ctx, s) })
 	t.Run("Dashboards", func(t *testing.T) { testDashboards(t, ctx, s) })
+	t.Run("Notebooks", func(t *testing.T) { testNotebooks(t, ctx, s) })
+	t.Run("NotebookSnapshots", func(t *testing.T) { testNotebookSnapshots(t, ctx, s) })
 	t.Run("QueryHistory", func(t *testing.T) { testQueryHistory(t, ctx, s) })
 	t.Run("QueryStats", func(t *testing.T) { testQueryStats(t, ctx, s) })
+	t.Run("AuditEvents", func(t *testing.T) { testAuditEvents(t, ctx, s) })
+	t.Run("SourceRollups", func(t *testing.T) { testSourceRollups(t, ctx, s) })
+	t.Run("SourceStatsSnapshots", func(t *testing.T) { testSourceStatsSnapshots(t, ctx, s) })
+	t.Run("SourceSchemaSnapshots", func(t *testing.T) { testSourceSchemaSnapshots(t, ctx, s) })
+	t.Run("SourceAlertEvents", func(t *testing.T) { testSourceAlertEvents(t, ctx, s) })
+	t.Run("TrackedQueries", func(t *testing.T) { testTrackedQueries(t, ctx, s) })
 	t.Run("Alerts", func(t *testing.T) { testAlerts(t, ctx, s) })
+	t.Run("SLOs", func(t *testing.T) { testSLOs(t, ctx, s) })
+	t.Run("AlertSilences", func(t *testing.T) { testAlertSilences(t, ctx, s) })
+	t.Run("Webhooks", func(t *testing.T) { testWebhooks(t, ctx, s) })
+	t.Run("Annotations", func(t *testing.T) { testAnnotations(t, ctx, s) })
+	t.Run("LogBookmarks", func(t *testing.T) { testLogBookmarks(t, ctx, s) })
+	t.Run("ColumnPresets", func(t *testing.T) { testColumnPresets(t, ctx, s) })
+	t.Run("QuerySnippets", func(t *testing.T) { testQuerySnippets(t, ctx, s) })
 	t.Run("UserPreferences", func(t *testing.T) { testUserPreferences(t, ctx, s) })
 	t.Run("QuerySharesExportJobsNotFound", func(t *testing.T) { testQuerySharesExportJobsNotFound(t, ctx, s) })
+	t.Run("QueryShareExpiry", func(t *testing.T) { testQueryShareExpiry(t, ctx, s) })
 	t.Run("Provisioning", func(t *testing.T) { testProvisioning(t, ctx, s) })
 	t.Run("WithTxCommit", func(t *testing.T) { testWithTxCommit(t, ctx, s) })
 	t.Run("WithTxRollback", func(t *testing.T) { testWithTxRollback(t, ctx, s) })
@@ -161,6 +181,16 @@ func testTeams(t *testing.T, ctx context.Context, s store.Store) {
 	if got, err := s.GetTeamByName(ctx, "Platform"); err != nil || got.ID != team.ID {
 		t.Fatalf("GetTeamByName: %v / %+v", err, got)
 	}
+	if !team.RawSQLEnabled {
+		t.Fatal("CreateTeam: raw SQL should be enabled by default")
+	}
+	team.RawSQLEnabled = false
+	if err := s.UpdateTeam(ctx, team); err != nil {
+		t.Fatalf("UpdateTeam: %v", err)
+	}
+	if got, err := s.GetTeam(ctx, team.ID); err != nil || got.RawSQLEnabled {
+		t.Fatalf("GetTeam after disabling raw SQL: %v / %+v", err, got)
+	}
 
 	if err := s.AddTeamMember(ctx, team.ID, alice.ID, models.TeamRoleAdmin); err != nil {
 		t.Fatalf("AddTeamMember: %v", err)
@@ -170,7 +200,7 @@ func testTeams(t *testing.T, ctx context.Context, s store.Store) {
 		t.Fatalf("GetTeamMember: %v / %+v", err, m)
 	}
 	teams, err := s.ListTeamsForUser(ctx, alice.ID)
-	if err != nil || len(teams) != 1 || teams[0].Role != models.TeamRoleAdmin {
+	if err != nil || len(teams) != 1 || teams[0].Role != models.TeamRoleAdmin || teams[0].RawSQLEnabled {
 		t.Fatalf("ListTeamsForUser: %v / %+v", err, teams)
 	}
 
@@ -187,6 +217,131 @@ func testTeams(t *testing.T, ctx context.Context, s store.Store) {
 	if err != nil || len(srcs) != 1 {
 		t.Fatalf("ListTeamSources: %v / %d", err, len(srcs))
 	}
+
+	// Row filters live on the link: none by default, settable, and
+	// ErrNotFound for a source the team isn't linked to.
+	if filter, err := s.GetTeamSourceRowFilter(ctx, team.ID, src.ID); err != nil || filter != "" {
+		t.Fatalf("GetTeamSourceRowFilter (default) = %q / %v", filter, err)
+	}
+	if err := s.SetTeamSourceRowFilter(ctx, team.ID, src.ID, "namespace = 'team-a'"); err != nil {
+		t.Fatalf("SetTeamSourceRowFilter: %v", err)
+	}
+	if filter, err := s.GetTeamSourceRowFilter(ctx, team.ID, src.ID); err != nil || filter != "namespace = 'team-a'" {
+		t.Fatalf("GetTeamSourceRowFilter = %q / %v", filter, err)
+	}
+	if _, err := s.GetTeamSourceRowFilter(ctx, team.ID, other.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Fatalf("GetTeamSourceRowFilter (unlinked) err = %v, want ErrNotFound", err)
+	}
+	if err := s.SetTeamSourceRowFilter(ctx, team.ID, other.ID, "1"); !errors.Is(err, models.ErrNotFound) {
+		t.Fatalf("SetTeamSourceRowFilter (unlinked) err = %v, want ErrNotFound", err)
+	}
+}
+
+func testSourceTags(t *testing.T, ctx context.Context, s store.Store) {
+	src := mkSource(t, ctx, s, "tagged")
+	if got, err := s.GetSource(ctx, src.ID); err != nil || len(got.Tags) != 0 {
+		t.Fatalf("GetSource (untagged): %v / %v", err, got)
+	}
+
+	src.Tags = models.SourceTags{"env": "prod", "region": "eu"}
+	if err := s.UpdateSource(ctx, src); err != nil {
+		t.Fatalf("UpdateSource: %v", err)
+	}
+	got, err := s.GetSource(ctx, src.ID)
+	if err != nil || !got.Tags.Equal(src.Tags) {
+		t.Fatalf("GetSource tags = %v / %v, want %v", err, got.Tags, src.Tags)
+	}
+	all, err := s.ListSources(ctx)
+	if err != nil {
+		t.Fatalf("ListSources: %v", err)
+	}
+	for _, listed := range all {
+		if listed.ID == src.ID && !listed.Tags.Equal(src.Tags) {
+			t.Errorf("ListSources tags = %v, want %v", listed.Tags, src.Tags)
+		}
+	}
+
+	src.Tags = nil
+	if err := s.UpdateSource(ctx, src); err != nil {
+		t.Fatalf("UpdateSource (clear): %v", err)
+	}
+	if got, err := s.GetSource(ctx, src.ID); err != nil || len(got.Tags) != 0 {
+		t.Fatalf("GetSource after clearing tags: %v / %v", err, got.Tags)
+	}
+}
+
+func testSourceExtractionRules(t *testing.T, ctx context.Context, s store.Store) {
+	src := mkSource(t, ctx, s, "extracted")
+	src.ExtractionRules = models.ExtractionRules{
+		{Name: "status", Kind: models.ExtractionRegex, Field: "body", Pattern: `status=(\d+)`, Type: models.ExtractionInt},
+		{Name: "user_id", Kind: models.ExtractionJSON, Field: "body", Path: "user.id", Type: models.ExtractionString},
+	}
+	if err := s.UpdateSource(ctx, src); err != nil {
+		t.Fatalf("UpdateSource: %v", err)
+	}
+	got, err := s.GetSource(ctx, src.ID)
+	if err != nil || !got.ExtractionRules.Equal(src.ExtractionRules) {
+		t.Fatalf("GetSource extraction rules = %v / %v, want %v", err, got.ExtractionRules, src.ExtractionRules)
+	}
+
+	src.ExtractionRules = nil
+	if err := s.UpdateSource(ctx, src); err != nil {
+		t.Fatalf("UpdateSource (clear): %v", err)
+	}
+	if got, err := s.GetSource(ctx, src.ID); err != nil || len(got.ExtractionRules) != 0 {
+		t.Fatalf("GetSource after clearing extraction rules: %v / %v", err, got.ExtractionRules)
+	}
+}
+
+func testSourceMaskedColumns(t *testing.T, ctx context.Context, s store.Store) {
+	src := mkSource(t, ctx, s, "masked")
+	src.MaskedColumns = models.MaskedColumns{
+		{Column: "email", Mode: models.MaskHash},
+		{Column: "client_ip", Mode: models.MaskRedact},
+	}
+	if err := s.UpdateSource(ctx, src); err != nil {
+		t.Fatalf("UpdateSource: %v", err)
+	}
+	got, err := s.GetSource(ctx, src.ID)
+	if err != nil || !got.MaskedColumns.Equal(src.MaskedColumns) {
+		t.Fatalf("GetSource masked columns = %v / %v, want %v", err, got.MaskedColumns, src.MaskedColumns)
+	}
+
+	src.MaskedColumns = nil
+	if err := s.UpdateSource(ctx, src); err != nil {
+		t.Fatalf("UpdateSource (clear): %v", err)
+	}
+	if got, err := s.GetSource(ctx, src.ID); err != nil || len(got.MaskedColumns) != 0 {
+		t.Fatalf("GetSource after clearing masked columns: %v / %v", err, got.MaskedColumns)
+	}
+}
+
+func testSourceTraceCorrelation(t *testing.T, ctx context.Context, s store.Store) {
+	src := mkSource(t, ctx, s, "traced")
+	if got, err := s.GetSource(ctx, src.ID); err != nil || got.TraceCorrelation != nil {
+		t.Fatalf("GetSource trace correlation = %v / %+v, want none", err, got.TraceCorrelation)
+	}
+	src.TraceCorrelation = &models.TraceCorrelation{
+		TraceIDField: "trace_id",
+		SpanIDField:  "span_id",
+		URLTemplate:  "https://jaeger.example.com/trace/{trace_id}",
+		BackendName:  "Jaeger",
+	}
+	if err := s.UpdateSource(ctx, src); err != nil {
+		t.Fatalf("UpdateSource: %v", err)
+	}
+	got, err := s.GetSource(ctx, src.ID)
+	if err != nil || !got.TraceCorrelation.Equal(src.TraceCorrelation) {
+		t.Fatalf("GetSource trace correlation = %v / %+v, want %+v", err, got.TraceCorrelation, src.TraceCorrelation)
+	}
+
+	src.TraceCorrelation = nil
+	if err := s.UpdateSource(ctx, src); err != nil {
+		t.Fatalf("UpdateSource (clear): %v", err)
+	}
+	if got, err := s.GetSource(ctx, src.ID); err != nil || got.TraceCorrelation != nil {
+		t.Fatalf("GetSource after clearing trace correlation: %v / %+v", err, got.TraceCorrelation)
+	}
 }
 
 func testSessions(t *testing.T, ctx context.Context, s store.Store) {
@@ -380,12 +535,143 @@ func verifyDashboardUpdateAndDelete(t *testing.T, ctx context.Context, s store.S
 	}
 }
 
+func testNotebooks(t *testing.T, ctx context.Context, s store.Store) {
+	owner := mkUser(t, ctx, s, "notebook-owner@test.dev")
+	team := &models.Team{Name: "Notebook team"}
+	if err := s.CreateTeam(ctx, team); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+
+	cells := json.RawMessage(`[{"id":"c1","type":"markdown","content":"# Incident"}]`)
+	n := &models.Notebook{TeamID: team.ID, Name: "Postmortem", Description: "api outage", CellsJSON: cells, CreatedBy: &owner.ID}
+	if err := s.CreateNotebook(ctx, n); err != nil || n.ID == 0 {
+		t.Fatalf("CreateNotebook: %v / id=%d", err, n.ID)
+	}
+	if string(n.CellsJSON) != string(cells) || n.CreatedByEmail != owner.Email || n.TeamID != team.ID {
+		t.Fatalf("CreateNotebook did not repopulate the row: %+v", n)
+	}
+
+	// List is team-scoped and omits cells.
+	list, err := s.ListNotebooksByTeam(ctx, team.ID)
+	if err != nil || len(list) != 1 || list[0].ID != n.ID || list[0].CellsJSON != nil {
+		t.Fatalf("ListNotebooksByTeam: %v / %+v", err, list)
+	}
+	if other, err := s.ListNotebooksByTeam(ctx, team.ID+1000); err != nil || len(other) != 0 {
+		t.Fatalf("ListNotebooksByTeam(other team): %v / %d", err, len(other))
+	}
+
+	// Caching results rewrites the cells but is not an edit.
+	cached := json.RawMessage(`[{"id":"c1","type":"markdown","content":"# Incident v2"}]`)
+	if err := s.UpdateNotebookCells(ctx, n.ID, cells, cached); err != nil {
+		t.Fatalf("UpdateNotebookCells: %v", err)
+	}
+	got, err := s.GetNotebook(ctx, n.ID)
+	if err != nil || string(got.CellsJSON) != string(cached) || !got.UpdatedAt.Equal(n.UpdatedAt) {
+		t.Fatalf("after UpdateNotebookCells: %v / %+v", err, got)
+	}
+
+	// A write based on a stale read is refused and leaves the cells alone.
+	if err := s.UpdateNotebookCells(ctx, n.ID, cells, cells); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("UpdateNotebookCells(stale) err = %v, want ErrNotFound", err)
+	}
+	if after, _ := s.GetNotebook(ctx, n.ID); string(after.CellsJSON) != string(cached) {
+		t.Errorf("after stale UpdateNotebookCells = %s, want %s", after.CellsJSON, cached)
+	}
+
+	got.Name = "Postmortem v2"
+	if err := s.UpdateNotebook(ctx, got); err != nil {
+		t.Fatalf("UpdateNotebook: %v", err)
+	}
+	if after, _ := s.GetNotebook(ctx, n.ID); after.Name != "Postmortem v2" {
+		t.Errorf("after update = %+v", after)
+	}
+
+	if err := s.DeleteNotebook(ctx, n.ID); err != nil {
+		t.Fatalf("DeleteNotebook: %v", err)
+	}
+	if _, err := s.GetNotebook(ctx, n.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetNotebook(deleted) err = %v, want ErrNotFound", err)
+	}
+	if err := s.UpdateNotebook(ctx, got); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("UpdateNotebook(deleted) err = %v, want ErrNotFound", err)
+	}
+	if err := s.UpdateNotebookCells(ctx, n.ID, cached, cached); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("UpdateNotebookCells(deleted) err = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteNotebook(ctx, n.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("DeleteNotebook(deleted) err = %v, want ErrNotFound", err)
+	}
+}
+
+func testNotebookSnapshots(t *testing.T, ctx context.Context, s store.Store) {
+	owner := mkUser(t, ctx, s, "snapshot-owner@test.dev")
+	team := &models.Team{Name: "Snapshot team"}
+	if err := s.CreateTeam(ctx, team); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+	n := &models.Notebook{TeamID: team.ID, Name: "Incident", CellsJSON: json.RawMessage(`[]`), CreatedBy: &owner.ID}
+	if err := s.CreateNotebook(ctx, n); err != nil {
+		t.Fatalf("CreateNotebook: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	mk := func(id string, created, expires time.Time) *models.NotebookSnapshot {
+		snap := &models.NotebookSnapshot{
+			ID: id, NotebookID: n.ID, TeamID: team.ID, Format: "html",
+			FileName: id + ".html", ObjectKey: "notebooks/" + id + ".html", SizeBytes: 42,
+			CreatedBy: &owner.ID, ExpiresAt: expires, CreatedAt: created,
+		}
+		if err := s.CreateNotebookSnapshot(ctx, snap); err != nil {
+			t.Fatalf("CreateNotebookSnapshot(%s): %v", id, err)
+		}
+		return snap
+	}
+	mk("old", now.Add(-2*time.Hour), now.Add(-time.Hour))
+	mk("new", now.Add(-time.Minute), now.Add(time.Hour))
+
+	got, err := s.GetNotebookSnapshot(ctx, "new")
+	if err != nil || got.ObjectKey != "notebooks/new.html" || got.SizeBytes != 42 ||
+		got.CreatedBy == nil || *got.CreatedBy != owner.ID || !got.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("GetNotebookSnapshot: %v / %+v", err, got)
+	}
+	list, err := s.ListNotebookSnapshots(ctx, n.ID)
+	if err != nil || len(list) != 2 || list[0].ID != "new" || list[1].ID != "old" {
+		t.Fatalf("ListNotebookSnapshots: %v / %+v", err, list)
+	}
+	expired, err := s.ListExpiredNotebookSnapshots(ctx, now)
+	if err != nil || len(expired) != 1 || expired[0].ID != "old" {
+		t.Fatalf("ListExpiredNotebookSnapshots: %v / %+v", err, expired)
+	}
+
+	// Snapshots outlive their notebook until they expire, so the cleanup
+	// loop can still find and delete their objects.
+	if err := s.DeleteNotebook(ctx, n.ID); err != nil {
+		t.Fatalf("DeleteNotebook: %v", err)
+	}
+	if _, err := s.GetNotebookSnapshot(ctx, "new"); err != nil {
+		t.Errorf("snapshot after notebook delete: %v", err)
+	}
+
+	if err := s.DeleteNotebookSnapshot(ctx, "old"); err != nil {
+		t.Fatalf("DeleteNotebookSnapshot: %v", err)
+	}
+	if err := s.DeleteNotebookSnapshot(ctx, "old"); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("DeleteNotebookSnapshot(deleted) err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetNotebookSnapshot(ctx, "old"); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetNotebookSnapshot(deleted) err = %v, want ErrNotFound", err)
+	}
+}
+
 func testQueryHistory(t *testing.T, ctx context.Context, s store.Store) {
 	user := mkUser(t, ctx, s, "qh-user@test.dev")
 	src := mkSource(t, ctx, s, "qh")
+	byUser := func(id models.UserID, limit, offset int) models.QueryHistoryFilter {
+		return models.QueryHistoryFilter{UserID: &id, Limit: limit, Offset: offset}
+	}
 
 	// A fresh user has no history.
-	if h, err := s.ListQueryHistory(ctx, user.ID, 10); err != nil || len(h) != 0 {
+	if h, err := s.ListQueryHistory(ctx, byUser(user.ID, 10, 0)); err != nil || len(h) != 0 {
 		t.Fatalf("ListQueryHistory(empty) = %d / %v, want 0", len(h), err)
 	}
 
@@ -399,10 +685,10 @@ func testQueryHistory(t *testing.T, ctx context.Context, s store.Store) {
 		DurationMs:    42,
 		RowCount:      13,
 	}
-	if err := s.RecordQueryHistory(ctx, entry); err != nil || entry.ID == 0 {
+	if err := s.RecordQueryHistory(ctx, entry, queryHistoryTestCap); err != nil || entry.ID == 0 {
 		t.Fatalf("RecordQueryHistory: %v / id=%d", err, entry.ID)
 	}
-	got, err := s.ListQueryHistory(ctx, user.ID, 10)
+	got, err := s.ListQueryHistory(ctx, byUser(user.ID, 10, 0))
 	if err != nil || len(got) != 1 {
 		t.Fatalf("ListQueryHistory = %d / %v, want 1", len(got), err)
 	}
@@ -410,28 +696,88 @@ func testQueryHistory(t *testing.T, ctx context.Context, s store.Store) {
 
 	// History is scoped per user: another user sees none of the above.
 	other := mkUser(t, ctx, s, "qh-other@test.dev")
-	if h, err := s.ListQueryHistory(ctx, other.ID, 10); err != nil || len(h) != 0 {
+	if h, err := s.ListQueryHistory(ctx, byUser(other.ID, 10, 0)); err != nil || len(h) != 0 {
 		t.Errorf("ListQueryHistory(other) = %d / %v, want 0", len(h), err)
 	}
 
+	verifyQueryHistoryFilters(t, ctx, s, other.ID, src.ID)
 	verifyQueryHistoryPruning(t, ctx, s, user.ID, src.ID)
 }
 
+// queryHistoryTestCap is the per-user cap the shared tests record with.
+const queryHistoryTestCap = 20
+
 // assertQueryHistoryRow checks the round-tripped fields of the seed row.
 func assertQueryHistoryRow(t *testing.T, g *models.QueryHistory, sourceID models.SourceID) {
 	t.Helper()
 	if g.QueryText != "status>=500" || g.QueryLanguage != models.QueryLanguageLogchefQL ||
-		g.DurationMs != 42 || g.RowCount != 13 || g.TeamID != 7 || g.SourceID != sourceID || g.CreatedAt.IsZero() {
+		g.DurationMs != 42 || g.RowCount != 13 || g.TeamID != 7 || g.SourceID != sourceID || g.CreatedAt.IsZero() ||
+		g.Status != models.QueryHistoryStatusSuccess || g.UserEmail != "qh-user@test.dev" || g.SourceName == "" {
 		t.Fatalf("history row did not round-trip: %+v", g)
 	}
 }
 
+// verifyQueryHistoryFilters records a failed query and checks the team,
+// status and search filters, then the retention delete.
+func verifyQueryHistoryFilters(t *testing.T, ctx context.Context, s store.Store, userID models.UserID, sourceID models.SourceID) {
+	t.Helper()
+	failed := &models.QueryHistory{
+		UserID:        userID,
+		TeamID:        models.TeamID(8),
+		SourceID:      sourceID,
+		QueryText:     "SELECT * FROM Logs WHERE level = 'error'",
+		QueryLanguage: models.QueryLanguageClickHouseSQL,
+		Status:        models.QueryHistoryStatusError,
+		ErrorMessage:  "Unknown table",
+	}
+	if err := s.RecordQueryHistory(ctx, failed, queryHistoryTestCap); err != nil {
+		t.Fatalf("RecordQueryHistory(failed): %v", err)
+	}
+
+	team8, team7 := models.TeamID(8), models.TeamID(7)
+	hourAgo, inAnHour := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	cases := []struct {
+		name   string
+		filter models.QueryHistoryFilter
+		want   int
+	}{
+		{"team", models.QueryHistoryFilter{TeamID: &team8}, 1},
+		{"other team", models.QueryHistoryFilter{TeamID: &team7, UserID: &userID}, 0},
+		{"status", models.QueryHistoryFilter{Status: models.QueryHistoryStatusError}, 1},
+		{"search ignores case", models.QueryHistoryFilter{Search: "from logs"}, 1},
+		{"search miss", models.QueryHistoryFilter{Search: "nothing like this"}, 0},
+		{"since", models.QueryHistoryFilter{Status: models.QueryHistoryStatusError, Since: &hourAgo}, 1},
+		{"since future", models.QueryHistoryFilter{Since: &inAnHour}, 0},
+	}
+	for _, tc := range cases {
+		tc.filter.Limit = 10
+		h, err := s.ListQueryHistory(ctx, tc.filter)
+		if err != nil || len(h) != tc.want {
+			t.Errorf("ListQueryHistory(%s) = %d / %v, want %d", tc.name, len(h), err, tc.want)
+			continue
+		}
+		if n, err := s.CountQueryHistory(ctx, tc.filter); err != nil || n != tc.want {
+			t.Errorf("CountQueryHistory(%s) = %d / %v, want %d", tc.name, n, err, tc.want)
+		}
+		if tc.want == 1 && (h[0].ErrorMessage != "Unknown table" || h[0].Status != models.QueryHistoryStatusError) {
+			t.Errorf("ListQueryHistory(%s) = %+v, want the failed query", tc.name, h[0])
+		}
+	}
+
+	if n, err := s.DeleteQueryHistoryBefore(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Errorf("DeleteQueryHistoryBefore(past) = %d / %v, want 0", n, err)
+	}
+	if n, err := s.DeleteQueryHistoryBefore(ctx, time.Now().Add(time.Hour)); err != nil || n < 1 {
+		t.Errorf("DeleteQueryHistoryBefore(future) = %d / %v, want the recorded rows", n, err)
+	}
+}
+
 // verifyQueryHistoryPruning records more than the per-user cap and confirms the
 // store keeps only the newest cap rows, newest-first, and honors a smaller limit.
 func verifyQueryHistoryPruning(t *testing.T, ctx context.Context, s store.Store, userID models.UserID, sourceID models.SourceID) {
 	t.Helper()
-	total := models.QueryHistoryPerUserCap + 3
-	for i := 1; i < total; i++ { // the seed row above was #0
+	total := queryHistoryTestCap + 3
+	for i := range total {
 		e := &models.QueryHistory{
 			UserID:        userID,
 			TeamID:        models.TeamID(7),
@@ -439,31 +785,40 @@ func verifyQueryHistoryPruning(t *testing.T, ctx context.Context, s store.Store,
 			QueryText:     "q" + strconv.Itoa(i),
 			QueryLanguage: models.QueryLanguageClickHouseSQL,
 		}
-		if err := s.RecordQueryHistory(ctx, e); err != nil {
+		if err := s.RecordQueryHistory(ctx, e, queryHistoryTestCap); err != nil {
 			t.Fatalf("RecordQueryHistory(#%d): %v", i, err)
 		}
 	}
 
-	all, err := s.ListQueryHistory(ctx, userID, models.QueryHistoryPerUserCap+100)
+	filter := models.QueryHistoryFilter{UserID: &userID, Limit: queryHistoryTestCap + 100}
+	all, err := s.ListQueryHistory(ctx, filter)
 	if err != nil {
 		t.Fatalf("ListQueryHistory(all): %v", err)
 	}
-	if len(all) != models.QueryHistoryPerUserCap {
-		t.Fatalf("history not capped: got %d, want %d", len(all), models.QueryHistoryPerUserCap)
+	if len(all) != queryHistoryTestCap {
+		t.Fatalf("history not capped: got %d, want %d", len(all), queryHistoryTestCap)
 	}
 	if all[0].QueryText != "q"+strconv.Itoa(total-1) {
 		t.Errorf("ListQueryHistory[0] = %q, want newest %q", all[0].QueryText, "q"+strconv.Itoa(total-1))
 	}
 
-	if h, err := s.ListQueryHistory(ctx, userID, 5); err != nil || len(h) != 5 {
+	filter.Limit = 5
+	if h, err := s.ListQueryHistory(ctx, filter); err != nil || len(h) != 5 {
 		t.Errorf("ListQueryHistory(limit 5) = %d / %v, want 5", len(h), err)
 	}
+	filter.Offset = 2
+	if h, err := s.ListQueryHistory(ctx, filter); err != nil || len(h) != 5 || h[0].ID != all[2].ID {
+		t.Errorf("ListQueryHistory(offset 2) did not start at the third newest row: %v", err)
+	}
+	if n, err := s.CountQueryHistory(ctx, filter); err != nil || n != queryHistoryTestCap {
+		t.Errorf("CountQueryHistory = %d / %v, want %d", n, err, queryHistoryTestCap)
+	}
 }
 
 // testQueryStats verifies the non-pruned daily rollup: IncrementQueryStats on
-// the same key twice sums into one row (count 2, durations added), and the
-// aggregate reads (top sources, top users, volume by day) return the expected
-// values over a small seeded set. A ghost source_id (no sources row) exercises
+// the same key twice sums into one row (count 2, durations and rows read
+// added), and the aggregate reads (top sources, top users, volume by day,
+// team usage) return the expected values over a small seeded set. A ghost source_id (no sources row) exercises
 // the LEFT JOIN -> "" source_name path.
 func testQueryStats(t *testing.T, ctx context.Context, s store.Store) {
 	userA := mkUser(t, ctx, s, "qs-a@test.dev")
@@ -479,7 +834,7 @@ func testQueryStats(t *testing.T, ctx context.Context, s store.Store) {
 	team := models.TeamID(1)
 	inc := func(bucket string, u models.UserID, src models.SourceID, lang models.QueryLanguage, dur int64) {
 		t.Helper()
-		if err := s.IncrementQueryStats(ctx, bucket, u, team, src, lang, dur); err != nil {
+		if err := s.IncrementQueryStats(ctx, bucket, u, team, src, lang, dur, dur*10); err != nil {
 			t.Fatalf("IncrementQueryStats(%s,%d,%d): %v", bucket, u, src, err)
 		}
 	}
@@ -498,8 +853,303 @@ func testQueryStats(t *testing.T, ctx context.Context, s store.Store) {
 	verifyTopSources(t, ctx, s, srcA, srcB, ghostSource)
 	verifyTopUsers(t, ctx, s, userA, userB)
 	verifyQueryVolumeByDay(t, ctx, s, day1, day2)
+
+	// Rows read are seeded as 10x the duration: day1 is 4 queries, 1900 rows.
+	if usage, err := s.GetTeamQueryUsage(ctx, team, day1); err != nil || usage.Queries != 4 || usage.RowsRead != 1900 {
+		t.Errorf("GetTeamQueryUsage(day1) = %+v / %v, want 4 queries, 1900 rows", usage, err)
+	}
+	if usage, err := s.GetTeamQueryUsage(ctx, models.TeamID(2), day1); err != nil || usage.Queries != 0 || usage.RowsRead != 0 {
+		t.Errorf("GetTeamQueryUsage(other team) = %+v / %v, want zeros", usage, err)
+	}
+}
+
+func testSourceRollups(t *testing.T, ctx context.Context, s store.Store) {
+	src := mkSource(t, ctx, s, "rollup_logs")
+	if _, err := s.GetSourceRollup(ctx, src.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Fatalf("GetSourceRollup(unconfigured) err = %v, want ErrNotFound", err)
+	}
+
+	if err := s.UpsertSourceRollup(ctx, src.ID, "service"); err != nil {
+		t.Fatalf("UpsertSourceRollup: %v", err)
+	}
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := from.Add(48 * time.Hour)
+	ranAt := until.Add(20 * time.Minute)
+	if err := s.UpdateSourceRollupProgress(ctx, &models.SourceRollup{
+		SourceID: src.ID, Dimension: "service",
+		RolledUpFrom: &from, RolledUpUntil: &until, LastRunAt: &ranAt,
+	}); err != nil {
+		t.Fatalf("UpdateSourceRollupProgress: %v", err)
+	}
+	got, err := s.GetSourceRollup(ctx, src.ID)
+	if err != nil {
+		t.Fatalf("GetSourceRollup: %v", err)
+	}
+	if got.Dimension != "service" || got.RolledUpFrom == nil || !got.RolledUpFrom.Equal(from) ||
+		got.RolledUpUntil == nil || !got.RolledUpUntil.Equal(until) || got.LastRunAt == nil {
+		t.Fatalf("round-trip mismatch: %+v", got)
+	}
+
+	// Re-saving the same dimension keeps progress; a new one resets it.
+	if err := s.UpsertSourceRollup(ctx, src.ID, "service"); err != nil {
+		t.Fatalf("UpsertSourceRollup(same): %v", err)
+	}
+	if got, _ := s.GetSourceRollup(ctx, src.ID); got.RolledUpUntil == nil {
+		t.Error("same-dimension upsert reset progress")
+	}
+	if err := s.UpsertSourceRollup(ctx, src.ID, "host"); err != nil {
+		t.Fatalf("UpsertSourceRollup(new): %v", err)
+	}
+	if got, _ := s.GetSourceRollup(ctx, src.ID); got.RolledUpFrom != nil || got.RolledUpUntil != nil {
+		t.Errorf("dimension change kept progress: %+v", got)
+	}
+
+	// A run that started under the old dimension can't record its progress.
+	err = s.UpdateSourceRollupProgress(ctx, &models.SourceRollup{SourceID: src.ID, Dimension: "service", RolledUpUntil: &until, LastRunAt: &ranAt})
+	if !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("stale progress err = %v, want ErrNotFound", err)
+	}
+
+	all, err := s.ListSourceRollups(ctx)
+	if err != nil || len(all) != 1 || all[0].SourceID != src.ID {
+		t.Fatalf("ListSourceRollups: %v / %+v", err, all)
+	}
+	if err := s.DeleteSourceRollup(ctx, src.ID); err != nil {
+		t.Fatalf("DeleteSourceRollup: %v", err)
+	}
+	if _, err := s.GetSourceRollup(ctx, src.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetSourceRollup after delete err = %v", err)
+	}
 }
 
+func testSourceStatsSnapshots(t *testing.T, ctx context.Context, s store.Store) {
+	src := mkSource(t, ctx, s, "stats_logs")
+	capturedAt := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+	for _, snap := range []*models.SourceStatsSnapshot{
+		{SourceID: src.ID, SnapshotDate: "2026-03-01", Rows: 10, CompressedBytes: 5, UncompressedBytes: 20, CapturedAt: capturedAt.Add(-24 * time.Hour)},
+		{SourceID: src.ID, SnapshotDate: "2026-03-02", Rows: 15, CompressedBytes: 6, UncompressedBytes: 30, CapturedAt: capturedAt},
+		// Same day again replaces the earlier snapshot.
+		{
+			SourceID: src.ID, SnapshotDate: "2026-03-02", Rows: 20, PartCount: 3, CompressedBytes: 8, UncompressedBytes: 40, CapturedAt: capturedAt,
+			Columns: []models.ColumnStorageStats{{Name: "msg", CompressedBytes: 7, UncompressedBytes: 35}},
+		},
+	} {
+		if err := s.UpsertSourceStatsSnapshot(ctx, snap); err != nil {
+			t.Fatalf("UpsertSourceStatsSnapshot(%s): %v", snap.SnapshotDate, err)
+		}
+	}
+
+	got, err := s.ListSourceStatsSnapshots(ctx, src.ID, "2026-03-01")
+	if err != nil || len(got) != 2 {
+		t.Fatalf("ListSourceStatsSnapshots: %v / %+v", err, got)
+	}
+	last := got[1]
+	if got[0].SnapshotDate != "2026-03-01" || last.Rows != 20 || last.PartCount != 3 || !last.CapturedAt.Equal(capturedAt) ||
+		len(last.Columns) != 1 || last.Columns[0].Name != "msg" || last.Columns[0].UncompressedBytes != 35 {
+		t.Fatalf("round-trip mismatch: %+v / %+v", got[0], last)
+	}
+	if since, _ := s.ListSourceStatsSnapshots(ctx, src.ID, "2026-03-02"); len(since) != 1 {
+		t.Errorf("since filter returned %d snapshots, want 1", len(since))
+	}
+
+	n, err := s.DeleteSourceStatsSnapshotsBefore(ctx, "2026-03-02")
+	if err != nil || n != 1 {
+		t.Fatalf("DeleteSourceStatsSnapshotsBefore = %d, %v; want 1", n, err)
+	}
+	if left, _ := s.ListSourceStatsSnapshots(ctx, src.ID, "2026-01-01"); len(left) != 1 || left[0].SnapshotDate != "2026-03-02" {
+		t.Errorf("after prune: %+v", left)
+	}
+}
+
+func testSourceSchemaSnapshots(t *testing.T, ctx context.Context, s store.Store) {
+	src := mkSource(t, ctx, s, "schema_logs")
+	if _, err := s.GetLatestSourceSchemaSnapshot(ctx, src.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Fatalf("GetLatestSourceSchemaSnapshot before insert err = %v", err)
+	}
+
+	capturedAt := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+	first := &models.SourceSchemaSnapshot{
+		SourceID:   src.ID,
+		Columns:    []models.ColumnInfo{{Name: "msg", Type: "String"}},
+		CapturedAt: capturedAt.Add(-48 * time.Hour),
+	}
+	second := &models.SourceSchemaSnapshot{
+		SourceID:   src.ID,
+		Columns:    []models.ColumnInfo{{Name: "msg", Type: "String"}, {Name: "trace_id", Type: "String"}},
+		Changes:    []models.SchemaChange{{Kind: models.SchemaChangeAdded, Column: "trace_id", NewType: "String"}},
+		CapturedAt: capturedAt,
+	}
+	for _, snap := range []*models.SourceSchemaSnapshot{first, second} {
+		if err := s.InsertSourceSchemaSnapshot(ctx, snap); err != nil || snap.ID == 0 {
+			t.Fatalf("InsertSourceSchemaSnapshot: %v (id %d)", err, snap.ID)
+		}
+	}
+
+	latest, err := s.GetLatestSourceSchemaSnapshot(ctx, src.ID)
+	if err != nil || latest.ID != second.ID || len(latest.Columns) != 2 || !latest.CapturedAt.Equal(capturedAt) ||
+		len(latest.Changes) != 1 || latest.Changes[0].Column != "trace_id" || latest.Changes[0].Kind != models.SchemaChangeAdded {
+		t.Fatalf("GetLatestSourceSchemaSnapshot = %+v, %v", latest, err)
+	}
+	got, err := s.ListSourceSchemaSnapshots(ctx, src.ID, 10)
+	if err != nil || len(got) != 2 || got[0].ID != second.ID || len(got[1].Changes) != 0 {
+		t.Fatalf("ListSourceSchemaSnapshots: %v / %+v", err, got)
+	}
+	if limited, _ := s.ListSourceSchemaSnapshots(ctx, src.ID, 1); len(limited) != 1 {
+		t.Errorf("limit 1 returned %d snapshots", len(limited))
+	}
+
+	// Everything is older than the cutoff, but the latest snapshot is kept.
+	n, err := s.DeleteSourceSchemaSnapshotsBefore(ctx, capturedAt.Add(time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("DeleteSourceSchemaSnapshotsBefore = %d, %v; want 1", n, err)
+	}
+	if left, _ := s.ListSourceSchemaSnapshots(ctx, src.ID, 10); len(left) != 1 || left[0].ID != second.ID {
+		t.Errorf("after prune: %+v", left)
+	}
+}
+
+func testSourceAlertEvents(t *testing.T, ctx context.Context, s store.Store) {
+	src := mkSource(t, ctx, s, "alerting_logs")
+	createdAt := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+	triggered := &models.SourceAlertEvent{
+		SourceID:  src.ID,
+		Kind:      models.SourceAlertUnreachable,
+		Status:    models.AlertStatusTriggered,
+		Value:     3,
+		Message:   "dial tcp: connection refused",
+		CreatedAt: createdAt.Add(-48 * time.Hour),
+	}
+	resolved := &models.SourceAlertEvent{
+		SourceID:  src.ID,
+		Kind:      models.SourceAlertUnreachable,
+		Status:    models.AlertStatusResolved,
+		CreatedAt: createdAt,
+	}
+	for _, event := range []*models.SourceAlertEvent{triggered, resolved} {
+		if err := s.InsertSourceAlertEvent(ctx, event); err != nil || event.ID == 0 {
+			t.Fatalf("InsertSourceAlertEvent: %v (id %d)", err, event.ID)
+		}
+	}
+
+	got, err := s.ListSourceAlertEvents(ctx, src.ID, 10)
+	if err != nil || len(got) != 2 || got[0].ID != resolved.ID || got[1].Kind != models.SourceAlertUnreachable ||
+		got[1].Status != models.AlertStatusTriggered || got[1].Value != 3 || got[1].Message != triggered.Message ||
+		!got[0].CreatedAt.Equal(createdAt) {
+		t.Fatalf("ListSourceAlertEvents: %v / %+v", err, got)
+	}
+	if limited, _ := s.ListSourceAlertEvents(ctx, src.ID, 1); len(limited) != 1 {
+		t.Errorf("limit 1 returned %d events", len(limited))
+	}
+
+	n, err := s.DeleteSourceAlertEventsBefore(ctx, createdAt.Add(-time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("DeleteSourceAlertEventsBefore = %d, %v; want 1", n, err)
+	}
+	if left, _ := s.ListSourceAlertEvents(ctx, src.ID, 10); len(left) != 1 || left[0].ID != resolved.ID {
+		t.Errorf("after prune: %+v", left)
+	}
+}
+
+func testTrackedQueries(t *testing.T, ctx context.Context, s store.Store) {
+	startedAt := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+	old := &models.TrackedQuery{ID: "q-old", Instance: "host-a", Class: "export", UserID: 1, SourceID: 2, QueryText: "SELECT 1", StartedAt: startedAt.Add(-3 * time.Hour)}
+	recent := &models.TrackedQuery{ID: "q-recent", Instance: "host-a", Class: "preview", UserID: 1, TeamID: 4, SourceID: 2, QueryText: "SELECT 2", StartedAt: startedAt}
+	other := &models.TrackedQuery{ID: "q-other", Instance: "host-b", Class: "preview", UserID: 3, SourceID: 2, StartedAt: startedAt}
+	for _, q := range []*models.TrackedQuery{old, recent, other} {
+		if err := s.UpsertTrackedQuery(ctx, q); err != nil {
+			t.Fatalf("UpsertTrackedQuery(%s): %v", q.ID, err)
+		}
+	}
+	// Persisting the same query again keeps the first row.
+	if err := s.UpsertTrackedQuery(ctx, &models.TrackedQuery{ID: "q-recent", Instance: "host-a", Class: "tail", StartedAt: startedAt}); err != nil {
+		t.Fatalf("UpsertTrackedQuery again: %v", err)
+	}
+
+	got, err := s.ListTrackedQueries(ctx, "host-a")
+	if err != nil || len(got) != 2 || got[0].ID != "q-old" || got[1].ID != "q-recent" {
+		t.Fatalf("ListTrackedQueries = %+v, %v", got, err)
+	}
+	if q := got[1]; q.Class != "preview" || q.TeamID != 4 || q.SourceID != 2 || q.QueryText != "SELECT 2" || !q.StartedAt.Equal(startedAt) {
+		t.Errorf("tracked query = %+v", q)
+	}
+
+	if err := s.DeleteTrackedQuery(ctx, "q-recent"); err != nil {
+		t.Fatalf("DeleteTrackedQuery: %v", err)
+	}
+	if err := s.DeleteTrackedQuery(ctx, "q-recent"); err != nil {
+		t.Fatalf("DeleteTrackedQuery missing: %v", err)
+	}
+	n, err := s.DeleteTrackedQueriesBefore(ctx, startedAt.Add(-time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("DeleteTrackedQueriesBefore = %d, %v; want 1", n, err)
+	}
+	if left, _ := s.ListTrackedQueries(ctx, "host-a"); len(left) != 0 {
+		t.Errorf("host-a after prune: %+v", left)
+	}
+	if left, _ := s.ListTrackedQueries(ctx, "host-b"); len(left) != 1 {
+		t.Errorf("host-b after prune: %+v", left)
+	}
+}
+
+func testAuditEvents(t *testing.T, ctx context.Context, s store.Store) {
+	actor := mkUser(t, ctx, s, "auditor@test.dev")
+	other := mkUser(t, ctx, s, "audited-other@test.dev")
+	teamID := models.TeamID(7)
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	events := []*models.AuditEvent{
+		{UserID: &actor.ID, UserEmail: actor.Email, Action: models.AuditActionSourceCreate, ResourceType: models.AuditResourceSource, ResourceID: "1", IPAddress: "10.0.0.1", CreatedAt: base},
+		{UserID: &actor.ID, UserEmail: actor.Email, Action: models.AuditActionTeamMemberAdd, ResourceType: models.AuditResourceTeamMember, ResourceID: "7:2", TeamID: &teamID, IPAddress: "10.0.0.1", Details: json.RawMessage(`{"role":"editor"}`), CreatedAt: base.Add(time.Minute)},
+		{UserID: &other.ID, UserEmail: other.Email, Action: models.AuditActionSourceDelete, ResourceType: models.AuditResourceSource, ResourceID: "1", IPAddress: "10.0.0.2", CreatedAt: base.Add(2 * time.Minute)},
+	}
+	for _, e := range events {
+		if err := s.InsertAuditEvent(ctx, e); err != nil || e.ID == 0 {
+			t.Fatalf("InsertAuditEvent: %v / id=%d", err, e.ID)
+		}
+	}
+
+	all, err := s.ListAuditEvents(ctx, models.AuditEventFilter{Limit: 10})
+	if err != nil || len(all) != 3 {
+		t.Fatalf("ListAuditEvents: %v / %d", err, len(all))
+	}
+	if all[0].ID != events[2].ID || all[2].ID != events[0].ID {
+		t.Errorf("not newest first: %d, %d, %d", all[0].ID, all[1].ID, all[2].ID)
+	}
+	member := all[1]
+	if member.TeamID == nil || *member.TeamID != teamID || string(member.Details) != `{"role":"editor"}` || member.UserEmail != actor.Email || member.IPAddress != "10.0.0.1" {
+		t.Errorf("round-trip mismatch: %+v", member)
+	}
+
+	filters := []struct {
+		name   string
+		filter models.AuditEventFilter
+		want   int
+	}{
+		{"by user", models.AuditEventFilter{UserID: &actor.ID}, 2},
+		{"by action", models.AuditEventFilter{Action: models.AuditActionSourceDelete}, 1},
+		{"by resource type", models.AuditEventFilter{ResourceType: models.AuditResourceSource}, 2},
+		{"by team", models.AuditEventFilter{TeamID: &teamID}, 1},
+		{"since inclusive", models.AuditEventFilter{Since: base.Add(time.Minute)}, 2},
+		{"until exclusive", models.AuditEventFilter{Until: base.Add(time.Minute)}, 1},
+		{"offset", models.AuditEventFilter{Offset: 2}, 1},
+	}
+	for _, tc := range filters {
+		tc.filter.Limit = 10
+		got, err := s.ListAuditEvents(ctx, tc.filter)
+		if err != nil || len(got) != tc.want {
+			t.Errorf("%s: %v / got %d, want %d", tc.name, err, len(got), tc.want)
+		}
+	}
+
+	// The trail outlives its actor: the snapshotted email stays, the id goes.
+	if err := s.DeleteUser(ctx, other.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	got, err := s.ListAuditEvents(ctx, models.AuditEventFilter{Action: models.AuditActionSourceDelete, Limit: 10})
+	if err != nil || len(got) != 1 || got[0].UserID != nil || got[0].UserEmail != other.Email {
+		t.Fatalf("after DeleteUser: %v / %+v", err, got)
+	}
+}
 func verifyTopSources(t *testing.T, ctx context.Context, s store.Store, srcA, srcB *models.Source, ghost models.SourceID) {
 	t.Helper()
 	sources, err := s.TopSourcesByQueries(ctx, "2026-07-01", 10)
@@ -580,8 +1230,12 @@ func testAlerts(t *testing.T, ctx context.Context, s store.Store) {
 		ThresholdValue:    10,
 		FrequencySeconds:  60,
 		Severity:          models.AlertSeverityWarning,
-		IsActive:          true,
-		LastState:         models.AlertStateResolved,
+		Channels: []models.AlertChannel{
+			{Type: models.AlertChannelSlack, URL: "https://hooks.slack.test/T0/B0"},
+			{Type: models.AlertChannelWebhook, URL: "https://hooks.test/a", Template: `{"text":"{{.AlertName}}"}`},
+		},
+		IsActive:  true,
+		LastState: models.AlertStateResolved,
 	}
 	if err := s.CreateAlert(ctx, a); err != nil || a.ID == 0 {
 		t.Fatalf("CreateAlert: %v / id=%d", err, a.ID)
@@ -591,6 +1245,9 @@ func testAlerts(t *testing.T, ctx context.Context, s store.Store) {
 	if err != nil || got.Name != "5xx spike" || got.ThresholdValue != 10 {
 		t.Fatalf("GetAlert: %v / %+v", err, got)
 	}
+	if len(got.Channels) != 2 || got.Channels[1].Template != a.Channels[1].Template {
+		t.Fatalf("GetAlert channels = %+v, want %+v", got.Channels, a.Channels)
+	}
 
 	bySrc, err := s.ListAlertsBySource(ctx, src.ID)
 	if err != nil || len(bySrc) != 1 {
@@ -613,6 +1270,30 @@ func testAlerts(t *testing.T, ctx context.Context, s store.Store) {
 		t.Errorf("fresh active alert %d not returned by ListActiveAlertsDue", a.ID)
 	}
 
+	// Pending state: breaches accumulate until the alert fires or resolves.
+	got.ForSeconds = 120
+	if err := s.UpdateAlert(ctx, got); err != nil {
+		t.Fatalf("UpdateAlert: %v", err)
+	}
+	since, breaches, err := s.MarkAlertPending(ctx, a.ID)
+	if err != nil || since.IsZero() || breaches != 1 {
+		t.Fatalf("MarkAlertPending: %v / %v / %d", err, since, breaches)
+	}
+	again, breaches, err := s.MarkAlertPending(ctx, a.ID)
+	if err != nil || !again.Equal(since) || breaches != 2 {
+		t.Fatalf("MarkAlertPending again: %v / %v (want %v) / %d", err, again, since, breaches)
+	}
+	pending, err := s.GetAlert(ctx, a.ID)
+	if err != nil || pending.ForSeconds != 120 || pending.LastState != models.AlertStatePending || pending.BreachCount != 2 {
+		t.Fatalf("GetAlert(pending): %v / %+v", err, pending)
+	}
+	if err := s.MarkAlertEvaluated(ctx, a.ID); err != nil {
+		t.Fatalf("MarkAlertEvaluated: %v", err)
+	}
+	if resolved, err := s.GetAlert(ctx, a.ID); err != nil || resolved.LastState != models.AlertStateResolved || resolved.PendingSince != nil || resolved.BreachCount != 0 {
+		t.Fatalf("GetAlert(resolved): %v / %+v", err, resolved)
+	}
+
 	// History round-trip.
 	val := 12.0
 	if _, err := s.InsertAlertHistory(ctx, a.ID, models.AlertStatusTriggered, &val, "fired", map[string]any{"k": "v"}); err != nil {
@@ -622,6 +1303,48 @@ func testAlerts(t *testing.T, ctx context.Context, s store.Store) {
 	if err != nil || len(hist) != 1 {
 		t.Fatalf("ListAlertHistory: %v / %d", err, len(hist))
 	}
+	// An open entry is in every window; once resolved, only in those it overlaps.
+	if open, err := s.ListAlertHistorySince(ctx, a.ID, time.Now().Add(time.Hour)); err != nil || len(open) != 1 {
+		t.Fatalf("ListAlertHistorySince(open): %v / %d", err, len(open))
+	}
+	if err := s.ResolveAlertHistory(ctx, hist[0].ID, "ok"); err != nil {
+		t.Fatalf("ResolveAlertHistory: %v", err)
+	}
+	if recent, err := s.ListAlertHistorySince(ctx, a.ID, time.Now().Add(-time.Hour)); err != nil || len(recent) != 1 || recent[0].ResolvedAt == nil {
+		t.Fatalf("ListAlertHistorySince(past hour): %v / %+v", err, recent)
+	}
+	if later, err := s.ListAlertHistorySince(ctx, a.ID, time.Now().Add(time.Hour)); err != nil || len(later) != 0 {
+		t.Fatalf("ListAlertHistorySince(next hour): %v / %d", err, len(later))
+	}
+
+	// Evaluation leases: one holder at a time until the lease expires.
+	now := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+	acquire := func(holder string, at time.Time) bool {
+		t.Helper()
+		ok, err := s.AcquireAlertLease(ctx, a.ID, holder, at, at.Add(time.Minute))
+		if err != nil {
+			t.Fatalf("AcquireAlertLease(%s): %v", holder, err)
+		}
+		return ok
+	}
+	if !acquire("host-a", now) {
+		t.Fatal("host-a could not take a free lease")
+	}
+	if acquire("host-b", now.Add(30*time.Second)) {
+		t.Fatal("host-b took host-a's live lease")
+	}
+	if !acquire("host-a", now.Add(30*time.Second)) {
+		t.Fatal("host-a could not renew its own lease")
+	}
+	if !acquire("host-b", now.Add(2*time.Minute)) {
+		t.Fatal("host-b could not take an expired lease")
+	}
+	if err := s.ReleaseAlertLeases(ctx, "host-b"); err != nil {
+		t.Fatalf("ReleaseAlertLeases: %v", err)
+	}
+	if !acquire("host-a", now.Add(2*time.Minute)) {
+		t.Fatal("host-a could not take a released lease")
+	}
 
 	if err := s.DeleteAlert(ctx, a.ID); err != nil {
 		t.Fatalf("DeleteAlert: %v", err)
@@ -641,6 +1364,545 @@ func testAlerts(t *testing.T, ctx context.Context, s store.Store) {
 // query-share and export-job read/delete paths — both backends must return
 // models.ErrNotFound for a missing token/id (SQLite previously leaked raw
 // sql.ErrNoRows here while Postgres translated it).
+func testSLOs(t *testing.T, ctx context.Context, s store.Store) {
+	owner := mkUser(t, ctx, s, "slo-owner@test.dev")
+	src := mkSource(t, ctx, s, "slos")
+	team := &models.Team{Name: "SLO team"}
+	if err := s.CreateTeam(ctx, team); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+
+	slo := &models.SLO{
+		TeamID:        team.ID,
+		SourceID:      src.ID,
+		Name:          "checkout availability",
+		QueryLanguage: models.QueryLanguageLogchefQL,
+		GoodQuery:     `status < 500`,
+		TotalQuery:    `service = "checkout"`,
+		Target:        0.999,
+		WindowSeconds: 30 * 24 * 3600,
+		CreatedBy:     &owner.ID,
+	}
+	if err := s.CreateSLO(ctx, slo); err != nil || slo.ID == 0 {
+		t.Fatalf("CreateSLO: %v / id=%d", err, slo.ID)
+	}
+	if slo.Target != 0.999 || slo.CreatedAt.IsZero() || slo.CreatedBy == nil || *slo.CreatedBy != owner.ID {
+		t.Fatalf("CreateSLO did not repopulate the row: %+v", slo)
+	}
+
+	list, err := s.ListSLOsByTeam(ctx, team.ID)
+	if err != nil || len(list) != 1 || list[0].ID != slo.ID {
+		t.Fatalf("ListSLOsByTeam: %v / %+v", err, list)
+	}
+	if other, err := s.ListSLOsByTeam(ctx, team.ID+1000); err != nil || len(other) != 0 {
+		t.Fatalf("ListSLOsByTeam(other team): %v / %d", err, len(other))
+	}
+
+	slo.Target = 0.99
+	slo.WindowSeconds = 7 * 24 * 3600
+	if err := s.UpdateSLO(ctx, slo); err != nil {
+		t.Fatalf("UpdateSLO: %v", err)
+	}
+	if got, err := s.GetSLO(ctx, slo.ID); err != nil || got.Target != 0.99 || got.WindowSeconds != 7*24*3600 {
+		t.Fatalf("after UpdateSLO: %v / %+v", err, got)
+	}
+
+	// Evaluations come back newest first; nil derived figures survive.
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		eval := slo.NewEvaluation(float64(990+i), 1000, base.Add(time.Duration(i)*time.Minute))
+		if err := s.InsertSLOEvaluation(ctx, eval); err != nil || eval.ID == 0 {
+			t.Fatalf("InsertSLOEvaluation: %v / id=%d", err, eval.ID)
+		}
+	}
+	empty := slo.NewEvaluation(0, 0, base.Add(3*time.Minute))
+	if err := s.InsertSLOEvaluation(ctx, empty); err != nil {
+		t.Fatalf("InsertSLOEvaluation(empty): %v", err)
+	}
+	evals, err := s.ListSLOEvaluations(ctx, slo.ID, 10)
+	if err != nil || len(evals) != 4 {
+		t.Fatalf("ListSLOEvaluations: %v / %d", err, len(evals))
+	}
+	if evals[0].Compliance != nil || evals[1].Compliance == nil || *evals[1].Compliance != 0.992 {
+		t.Errorf("evaluations = %+v / %+v", evals[0], evals[1])
+	}
+	if err := s.PruneSLOEvaluations(ctx, slo.ID, 2); err != nil {
+		t.Fatalf("PruneSLOEvaluations: %v", err)
+	}
+	if evals, _ := s.ListSLOEvaluations(ctx, slo.ID, 10); len(evals) != 2 || !evals[1].EvaluatedAt.Equal(base.Add(2*time.Minute)) {
+		t.Errorf("after prune = %+v", evals)
+	}
+
+	// Burn-rate alerts reference the SLO and are removed with it.
+	sloID := slo.ID
+	alert := &models.Alert{
+		SourceID:          src.ID,
+		Name:              "fast burn",
+		QueryLanguage:     models.QueryLanguageClickHouseSQL,
+		EditorMode:        models.AlertEditorModeNative,
+		LookbackSeconds:   3600,
+		ThresholdOperator: models.AlertThresholdGreaterThan,
+		ThresholdValue:    14.4,
+		FrequencySeconds:  60,
+		Severity:          models.AlertSeverityCritical,
+		SLOID:             &sloID,
+		LastState:         models.AlertStateResolved,
+	}
+	if err := s.CreateAlert(ctx, alert); err != nil {
+		t.Fatalf("CreateAlert(burn rate): %v", err)
+	}
+	if got, err := s.GetAlert(ctx, alert.ID); err != nil || got.SLOID == nil || *got.SLOID != slo.ID {
+		t.Fatalf("GetAlert(burn rate): %v / %+v", err, got)
+	}
+
+	if err := s.DeleteSLO(ctx, slo.ID); err != nil {
+		t.Fatalf("DeleteSLO: %v", err)
+	}
+	if _, err := s.GetSLO(ctx, slo.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetSLO(deleted) err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetAlert(ctx, alert.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("burn-rate alert survived its SLO: err = %v", err)
+	}
+	if err := s.UpdateSLO(ctx, slo); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("UpdateSLO(deleted) err = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteSLO(ctx, slo.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("DeleteSLO(deleted) err = %v, want ErrNotFound", err)
+	}
+}
+
+func testAlertSilences(t *testing.T, ctx context.Context, s store.Store) {
+	owner := mkUser(t, ctx, s, "silencer@test.dev")
+	src := mkSource(t, ctx, s, "silences")
+	team := &models.Team{Name: "Silence team"}
+	if err := s.CreateTeam(ctx, team); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ends := base.Add(2 * time.Hour)
+	oneOff := &models.AlertSilence{
+		SourceID:  &src.ID,
+		Reason:    "database migration",
+		StartsAt:  base,
+		EndsAt:    &ends,
+		CreatedBy: &owner.ID,
+	}
+	if err := s.CreateAlertSilence(ctx, oneOff); err != nil || oneOff.ID == 0 {
+		t.Fatalf("CreateAlertSilence: %v / id=%d", err, oneOff.ID)
+	}
+	if oneOff.SourceID == nil || *oneOff.SourceID != src.ID || oneOff.AlertID != nil || oneOff.TeamID != nil ||
+		oneOff.EndsAt == nil || !oneOff.EndsAt.Equal(ends) || oneOff.Recurrence != nil || oneOff.CreatedAt.IsZero() {
+		t.Fatalf("CreateAlertSilence did not repopulate the row: %+v", oneOff)
+	}
+
+	// An open-ended weekly window round-trips its recurrence.
+	window := &models.AlertSilence{
+		TeamID:   &team.ID,
+		StartsAt: base,
+		Recurrence: &models.SilenceRecurrence{
+			Weekdays:        []time.Weekday{time.Saturday, time.Sunday},
+			StartTime:       "02:00",
+			DurationMinutes: 120,
+			Timezone:        "Asia/Kolkata",
+		},
+	}
+	if err := s.CreateAlertSilence(ctx, window); err != nil {
+		t.Fatalf("CreateAlertSilence(recurring): %v", err)
+	}
+	got, err := s.GetAlertSilence(ctx, window.ID)
+	if err != nil || got.EndsAt != nil || got.Recurrence == nil || got.Recurrence.Timezone != "Asia/Kolkata" ||
+		len(got.Recurrence.Weekdays) != 2 || got.Recurrence.Weekdays[0] != time.Saturday {
+		t.Fatalf("GetAlertSilence(recurring): %v / %+v", err, got)
+	}
+
+	if list, err := s.ListAlertSilences(ctx); err != nil || len(list) != 2 {
+		t.Fatalf("ListAlertSilences: %v / %d", err, len(list))
+	}
+	// Once the one-off silence has ended only the open-ended window is left.
+	unexpired, err := s.ListUnexpiredAlertSilences(ctx, ends.Add(time.Minute))
+	if err != nil || len(unexpired) != 1 || unexpired[0].ID != window.ID {
+		t.Fatalf("ListUnexpiredAlertSilences: %v / %+v", err, unexpired)
+	}
+	if unexpired, _ := s.ListUnexpiredAlertSilences(ctx, base); len(unexpired) != 2 {
+		t.Errorf("ListUnexpiredAlertSilences(during) = %d silences, want 2", len(unexpired))
+	}
+
+	later := ends.Add(time.Hour)
+	oneOff.Reason = "migration overran"
+	oneOff.EndsAt = &later
+	if err := s.UpdateAlertSilence(ctx, oneOff); err != nil {
+		t.Fatalf("UpdateAlertSilence: %v", err)
+	}
+	if got, err := s.GetAlertSilence(ctx, oneOff.ID); err != nil || got.Reason != "migration overran" || !got.EndsAt.Equal(later) {
+		t.Fatalf("after UpdateAlertSilence: %v / %+v", err, got)
+	}
+
+	if err := s.DeleteAlertSilence(ctx, oneOff.ID); err != nil {
+		t.Fatalf("DeleteAlertSilence: %v", err)
+	}
+	if _, err := s.GetAlertSilence(ctx, oneOff.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetAlertSilence(deleted) err = %v, want ErrNotFound", err)
+	}
+	if err := s.UpdateAlertSilence(ctx, oneOff); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("UpdateAlertSilence(deleted) err = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteAlertSilence(ctx, oneOff.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("DeleteAlertSilence(deleted) err = %v, want ErrNotFound", err)
+	}
+
+	// Silences go away with the team (or source, or alert) they cover.
+	if err := s.DeleteTeam(ctx, team.ID); err != nil {
+		t.Fatalf("DeleteTeam: %v", err)
+	}
+	if _, err := s.GetAlertSilence(ctx, window.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("silence survived its team: err = %v", err)
+	}
+}
+
+func testWebhooks(t *testing.T, ctx context.Context, s store.Store) {
+	owner := mkUser(t, ctx, s, "webhooks@test.dev")
+	hook := &models.Webhook{
+		Name:       "provisioner",
+		URL:        "https://hooks.example.com/logchef",
+		EventTypes: []models.EventType{models.EventSourceCreated, models.EventTeamMemberAdded},
+		Secret:     "0123456789abcdef0123",
+		IsActive:   true,
+		CreatedBy:  &owner.ID,
+	}
+	if err := s.CreateWebhook(ctx, hook); err != nil || hook.ID == 0 {
+		t.Fatalf("CreateWebhook: %v / id=%d", err, hook.ID)
+	}
+	if hook.Secret != "0123456789abcdef0123" || len(hook.EventTypes) != 2 || !hook.IsActive ||
+		hook.CreatedBy == nil || *hook.CreatedBy != owner.ID || hook.CreatedAt.IsZero() {
+		t.Fatalf("CreateWebhook did not repopulate the row: %+v", hook)
+	}
+
+	hook.EventTypes = []models.EventType{models.EventAll}
+	hook.Secret = "fedcba9876543210fedc"
+	hook.IsActive = false
+	if err := s.UpdateWebhook(ctx, hook); err != nil {
+		t.Fatalf("UpdateWebhook: %v", err)
+	}
+	got, err := s.GetWebhook(ctx, hook.ID)
+	if err != nil || got.IsActive || got.Secret != "fedcba9876543210fedc" || len(got.EventTypes) != 1 || got.EventTypes[0] != models.EventAll {
+		t.Fatalf("after UpdateWebhook: %v / %+v", err, got)
+	}
+	if list, err := s.ListWebhooks(ctx); err != nil || len(list) != 1 || list[0].URL != hook.URL {
+		t.Fatalf("ListWebhooks: %v / %+v", err, list)
+	}
+
+	if err := s.DeleteWebhook(ctx, hook.ID); err != nil {
+		t.Fatalf("DeleteWebhook: %v", err)
+	}
+	if _, err := s.GetWebhook(ctx, hook.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetWebhook(deleted) err = %v, want ErrNotFound", err)
+	}
+	if err := s.UpdateWebhook(ctx, hook); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("UpdateWebhook(deleted) err = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteWebhook(ctx, hook.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("DeleteWebhook(deleted) err = %v, want ErrNotFound", err)
+	}
+}
+
+func testAnnotations(t *testing.T, ctx context.Context, s store.Store) {
+	owner := mkUser(t, ctx, s, "annotator@test.dev")
+	src := mkSource(t, ctx, s, "annotated")
+	other := mkSource(t, ctx, s, "not_annotated")
+	team := &models.Team{Name: "Annotation team"}
+	if err := s.CreateTeam(ctx, team); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+
+	base := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+	deploy := &models.Annotation{
+		TeamID:    team.ID,
+		Text:      "deploy v1.4.0",
+		Tags:      []string{"deploy"},
+		StartsAt:  base,
+		CreatedBy: &owner.ID,
+	}
+	if err := s.CreateAnnotation(ctx, deploy); err != nil || deploy.ID == 0 {
+		t.Fatalf("CreateAnnotation: %v / id=%d", err, deploy.ID)
+	}
+	if deploy.SourceID != nil || deploy.EndsAt != nil || len(deploy.Tags) != 1 || deploy.Tags[0] != "deploy" ||
+		deploy.CreatedBy == nil || *deploy.CreatedBy != owner.ID || deploy.CreatedAt.IsZero() {
+		t.Fatalf("CreateAnnotation did not repopulate the row: %+v", deploy)
+	}
+
+	incidentEnds := base.Add(3 * time.Hour)
+	incident := &models.Annotation{
+		TeamID:   team.ID,
+		SourceID: &src.ID,
+		Text:     "checkout outage",
+		StartsAt: base.Add(time.Hour),
+		EndsAt:   &incidentEnds,
+	}
+	if err := s.CreateAnnotation(ctx, incident); err != nil {
+		t.Fatalf("CreateAnnotation(range): %v", err)
+	}
+	if got, err := s.GetAnnotation(ctx, incident.ID); err != nil || got.SourceID == nil || *got.SourceID != src.ID ||
+		got.EndsAt == nil || !got.EndsAt.Equal(incidentEnds) || len(got.Tags) != 0 {
+		t.Fatalf("GetAnnotation(range): %v / %+v", err, got)
+	}
+
+	list, err := s.ListAnnotationsByTeam(ctx, team.ID)
+	if err != nil || len(list) != 2 || list[0].ID != incident.ID {
+		t.Fatalf("ListAnnotationsByTeam: %v / %+v", err, list)
+	}
+
+	// The range query includes team-wide markers and the source's own, and
+	// catches ranges that started before the window but are still open in it.
+	inRange, err := s.ListAnnotationsInRange(ctx, team.ID, src.ID, base.Add(2*time.Hour), base.Add(5*time.Hour))
+	if err != nil || len(inRange) != 1 || inRange[0].ID != incident.ID {
+		t.Fatalf("ListAnnotationsInRange(overlap): %v / %+v", err, inRange)
+	}
+	if inRange, _ := s.ListAnnotationsInRange(ctx, team.ID, src.ID, base, base.Add(time.Hour)); len(inRange) != 2 {
+		t.Errorf("ListAnnotationsInRange(both) = %d annotations, want 2", len(inRange))
+	}
+	if inRange, _ := s.ListAnnotationsInRange(ctx, team.ID, other.ID, base, base.Add(time.Hour)); len(inRange) != 1 || inRange[0].ID != deploy.ID {
+		t.Errorf("ListAnnotationsInRange(other source) = %+v, want only the team-wide deploy", inRange)
+	}
+	if inRange, _ := s.ListAnnotationsInRange(ctx, team.ID, src.ID, incidentEnds.Add(time.Minute), incidentEnds.Add(time.Hour)); len(inRange) != 0 {
+		t.Errorf("ListAnnotationsInRange(after) = %d annotations, want 0", len(inRange))
+	}
+
+	deploy.Text = "deploy v1.4.1"
+	deploy.Tags = []string{"deploy", "hotfix"}
+	deploy.SourceID = &src.ID
+	if err := s.UpdateAnnotation(ctx, deploy); err != nil {
+		t.Fatalf("UpdateAnnotation: %v", err)
+	}
+	if got, err := s.GetAnnotation(ctx, deploy.ID); err != nil || got.Text != "deploy v1.4.1" || len(got.Tags) != 2 ||
+		got.SourceID == nil || *got.SourceID != src.ID {
+		t.Fatalf("after UpdateAnnotation: %v / %+v", err, got)
+	}
+
+	if err := s.DeleteAnnotation(ctx, deploy.ID); err != nil {
+		t.Fatalf("DeleteAnnotation: %v", err)
+	}
+	if _, err := s.GetAnnotation(ctx, deploy.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetAnnotation(deleted) err = %v, want ErrNotFound", err)
+	}
+	if err := s.UpdateAnnotation(ctx, deploy); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("UpdateAnnotation(deleted) err = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteAnnotation(ctx, deploy.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("DeleteAnnotation(deleted) err = %v, want ErrNotFound", err)
+	}
+
+	// Annotations go away with their team.
+	if err := s.DeleteTeam(ctx, team.ID); err != nil {
+		t.Fatalf("DeleteTeam: %v", err)
+	}
+	if _, err := s.GetAnnotation(ctx, incident.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("annotation survived its team: err = %v", err)
+	}
+}
+
+func testLogBookmarks(t *testing.T, ctx context.Context, s store.Store) {
+	alice := mkUser(t, ctx, s, "bookmarker@test.dev")
+	bob := mkUser(t, ctx, s, "teammate@test.dev")
+	src := mkSource(t, ctx, s, "bookmarked")
+	team := &models.Team{Name: "Bookmark team"}
+	if err := s.CreateTeam(ctx, team); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+
+	logAt := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+	pin := func(user models.UserID, fingerprint string, shared bool) *models.LogBookmark {
+		t.Helper()
+		b := &models.LogBookmark{
+			TeamID:       team.ID,
+			SourceID:     src.ID,
+			UserID:       user,
+			Shared:       shared,
+			LogTimestamp: logAt,
+			Fingerprint:  fingerprint,
+			Payload:      json.RawMessage(`{"msg":"boom"}`),
+			Note:         "first panic",
+		}
+		if err := s.CreateLogBookmark(ctx, b); err != nil {
+			t.Fatalf("CreateLogBookmark(%s): %v", fingerprint, err)
+		}
+		return b
+	}
+	mine := pin(alice.ID, "fp-1", false)
+	if mine.ID == 0 || !mine.LogTimestamp.Equal(logAt) || string(mine.Payload) != `{"msg":"boom"}` || mine.CreatedAt.IsZero() {
+		t.Fatalf("CreateLogBookmark did not repopulate the row: %+v", mine)
+	}
+	shared := pin(bob.ID, "fp-2", true)
+	pin(bob.ID, "fp-3", false)
+
+	// The same user can't pin the same row twice; another user can.
+	dup := &models.LogBookmark{TeamID: team.ID, SourceID: src.ID, UserID: alice.ID, LogTimestamp: logAt, Fingerprint: "fp-1", Payload: json.RawMessage(`{}`)}
+	if err := s.CreateLogBookmark(ctx, dup); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("CreateLogBookmark(duplicate) err = %v, want ErrConflict", err)
+	}
+	pin(bob.ID, "fp-1", false)
+
+	// Alice sees her own bookmark and Bob's shared one, not his personal ones.
+	list, err := s.ListLogBookmarksForUser(ctx, team.ID, alice.ID)
+	if err != nil || len(list) != 2 {
+		t.Fatalf("ListLogBookmarksForUser: %v / %+v", err, list)
+	}
+	for _, b := range list {
+		if b.ID != mine.ID && b.ID != shared.ID {
+			t.Errorf("ListLogBookmarksForUser returned someone else's personal bookmark %+v", b)
+		}
+	}
+
+	mine.Note = "root cause"
+	mine.Shared = true
+	if err := s.UpdateLogBookmark(ctx, mine); err != nil {
+		t.Fatalf("UpdateLogBookmark: %v", err)
+	}
+	if got, err := s.GetLogBookmark(ctx, mine.ID); err != nil || got.Note != "root cause" || !got.Shared {
+		t.Fatalf("after UpdateLogBookmark: %v / %+v", err, got)
+	}
+
+	if err := s.DeleteLogBookmark(ctx, mine.ID); err != nil {
+		t.Fatalf("DeleteLogBookmark: %v", err)
+	}
+	if _, err := s.GetLogBookmark(ctx, mine.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetLogBookmark(deleted) err = %v, want ErrNotFound", err)
+	}
+	if err := s.UpdateLogBookmark(ctx, mine); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("UpdateLogBookmark(deleted) err = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteLogBookmark(ctx, mine.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("DeleteLogBookmark(deleted) err = %v, want ErrNotFound", err)
+	}
+
+	// Bookmarks go away with their team.
+	if err := s.DeleteTeam(ctx, team.ID); err != nil {
+		t.Fatalf("DeleteTeam: %v", err)
+	}
+	if _, err := s.GetLogBookmark(ctx, shared.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("log bookmark survived its team: err = %v", err)
+	}
+}
+
+func testColumnPresets(t *testing.T, ctx context.Context, s store.Store) {
+	user := mkUser(t, ctx, s, "layout@test.dev")
+	src := mkSource(t, ctx, s, "laid_out")
+	team := &models.Team{Name: "Layout team"}
+	if err := s.CreateTeam(ctx, team); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+
+	if _, err := s.GetUserColumnPreset(ctx, user.ID, src.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Fatalf("GetUserColumnPreset(none) err = %v, want ErrNotFound", err)
+	}
+	personal := &models.ColumnPreset{
+		SourceID: src.ID,
+		UserID:   &user.ID,
+		Columns:  []models.ColumnPresetColumn{{Name: "timestamp", Format: models.ColumnFormatRelativeTime}, {Name: "msg", Width: 600, Wrap: true}},
+	}
+	if err := s.SaveUserColumnPreset(ctx, personal); err != nil {
+		t.Fatalf("SaveUserColumnPreset: %v", err)
+	}
+	if personal.CreatedAt.IsZero() || len(personal.Columns) != 2 || personal.Columns[1].Width != 600 || !personal.Columns[1].Wrap {
+		t.Fatalf("SaveUserColumnPreset did not repopulate the row: %+v", personal)
+	}
+	// Saving again replaces the layout.
+	personal.Columns = []models.ColumnPresetColumn{{Name: "level"}}
+	if err := s.SaveUserColumnPreset(ctx, personal); err != nil {
+		t.Fatalf("SaveUserColumnPreset(replace): %v", err)
+	}
+	if got, err := s.GetUserColumnPreset(ctx, user.ID, src.ID); err != nil || len(got.Columns) != 1 || got.Columns[0].Name != "level" {
+		t.Fatalf("after replace: %v / %+v", err, got)
+	}
+
+	teamDefault := &models.ColumnPreset{
+		SourceID:  src.ID,
+		TeamID:    &team.ID,
+		Columns:   []models.ColumnPresetColumn{{Name: "service"}},
+		UpdatedBy: &user.ID,
+	}
+	if err := s.SaveTeamColumnPreset(ctx, teamDefault); err != nil {
+		t.Fatalf("SaveTeamColumnPreset: %v", err)
+	}
+	if got, err := s.GetTeamColumnPreset(ctx, team.ID, src.ID); err != nil || got.UpdatedBy == nil || *got.UpdatedBy != user.ID ||
+		got.TeamID == nil || *got.TeamID != team.ID || len(got.Columns) != 1 {
+		t.Fatalf("GetTeamColumnPreset: %v / %+v", err, got)
+	}
+
+	if err := s.DeleteUserColumnPreset(ctx, user.ID, src.ID); err != nil {
+		t.Fatalf("DeleteUserColumnPreset: %v", err)
+	}
+	if err := s.DeleteUserColumnPreset(ctx, user.ID, src.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("DeleteUserColumnPreset(again) err = %v, want ErrNotFound", err)
+	}
+
+	// Team defaults go away with their team.
+	if err := s.DeleteTeam(ctx, team.ID); err != nil {
+		t.Fatalf("DeleteTeam: %v", err)
+	}
+	if _, err := s.GetTeamColumnPreset(ctx, team.ID, src.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("team column preset survived its team: err = %v", err)
+	}
+	if err := s.DeleteTeamColumnPreset(ctx, team.ID, src.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("DeleteTeamColumnPreset(missing) err = %v, want ErrNotFound", err)
+	}
+}
+
+func testQuerySnippets(t *testing.T, ctx context.Context, s store.Store) {
+	author := mkUser(t, ctx, s, "snippets@test.dev")
+	team := &models.Team{Name: "Snippet team"}
+	if err := s.CreateTeam(ctx, team); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+
+	prodErrors := &models.QuerySnippet{TeamID: team.ID, Name: "prod errors", Query: `namespace="prod" and level="error"`, CreatedBy: &author.ID}
+	if err := s.CreateQuerySnippet(ctx, prodErrors); err != nil {
+		t.Fatalf("CreateQuerySnippet: %v", err)
+	}
+	if prodErrors.ID == 0 || prodErrors.CreatedAt.IsZero() || prodErrors.CreatedBy == nil || *prodErrors.CreatedBy != author.ID {
+		t.Fatalf("CreateQuerySnippet did not repopulate the row: %+v", prodErrors)
+	}
+	if err := s.CreateQuerySnippet(ctx, &models.QuerySnippet{TeamID: team.ID, Name: "api", Query: `service="api"`}); err != nil {
+		t.Fatalf("CreateQuerySnippet(api): %v", err)
+	}
+	dup := &models.QuerySnippet{TeamID: team.ID, Name: "prod errors", Query: `level="error"`}
+	if err := s.CreateQuerySnippet(ctx, dup); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("CreateQuerySnippet(duplicate name) err = %v, want ErrConflict", err)
+	}
+
+	list, err := s.ListQuerySnippetsByTeam(ctx, team.ID)
+	if err != nil || len(list) != 2 || list[0].Name != "api" || list[0].CreatedBy != nil {
+		t.Fatalf("ListQuerySnippetsByTeam: %v / %+v", err, list)
+	}
+
+	prodErrors.Description = "errors in prod"
+	prodErrors.Name = "api"
+	if err := s.UpdateQuerySnippet(ctx, prodErrors); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("UpdateQuerySnippet(taken name) err = %v, want ErrConflict", err)
+	}
+	prodErrors.Name = "prod errors"
+	if err := s.UpdateQuerySnippet(ctx, prodErrors); err != nil {
+		t.Fatalf("UpdateQuerySnippet: %v", err)
+	}
+	if got, err := s.GetQuerySnippet(ctx, prodErrors.ID); err != nil || got.Description != "errors in prod" {
+		t.Fatalf("after UpdateQuerySnippet: %v / %+v", err, got)
+	}
+
+	if err := s.DeleteQuerySnippet(ctx, prodErrors.ID); err != nil {
+		t.Fatalf("DeleteQuerySnippet: %v", err)
+	}
+	if _, err := s.GetQuerySnippet(ctx, prodErrors.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetQuerySnippet(deleted) err = %v, want ErrNotFound", err)
+	}
+	if err := s.UpdateQuerySnippet(ctx, prodErrors); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("UpdateQuerySnippet(deleted) err = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteQuerySnippet(ctx, prodErrors.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("DeleteQuerySnippet(deleted) err = %v, want ErrNotFound", err)
+	}
+}
+
 func testQuerySharesExportJobsNotFound(t *testing.T, ctx context.Context, s store.Store) {
 	if _, err := s.GetQueryShare(ctx, "nonexistent-token"); !errors.Is(err, models.ErrNotFound) {
 		t.Errorf("GetQueryShare(missing) err = %v, want ErrNotFound", err)
@@ -653,6 +1915,42 @@ func testQuerySharesExportJobsNotFound(t *testing.T, ctx context.Context, s stor
 	}
 }
 
+// testQueryShareExpiry checks that permanent shares round-trip without an
+// expiry and survive pruning, while expired ones are removed.
+func testQueryShareExpiry(t *testing.T, ctx context.Context, s store.Store) {
+	u := mkUser(t, ctx, s, "sharer@test.dev")
+	src := mkSource(t, ctx, s, "shared")
+	now := time.Now().UTC().Truncate(time.Second)
+	expired := now.Add(-time.Hour)
+	for _, share := range []*models.QueryShare{
+		{Token: "permanent-share", SourceID: src.ID, CreatedBy: u.ID, Payload: []byte(`{"mode":"logchefql"}`)},
+		{Token: "expired-share", SourceID: src.ID, CreatedBy: u.ID, Payload: []byte(`{"mode":"logchefql"}`), ExpiresAt: &expired},
+	} {
+		if err := s.CreateQueryShare(ctx, share); err != nil {
+			t.Fatalf("CreateQueryShare(%s): %v", share.Token, err)
+		}
+	}
+
+	got, err := s.GetQueryShare(ctx, "permanent-share")
+	if err != nil || got.ExpiresAt != nil {
+		t.Fatalf("GetQueryShare(permanent) = %v / %v, want no expiry", err, got.ExpiresAt)
+	}
+	got, err = s.GetQueryShare(ctx, "expired-share")
+	if err != nil || got.ExpiresAt == nil || !got.ExpiresAt.Equal(expired) {
+		t.Fatalf("GetQueryShare(expired) = %v / %v, want expiry %v", err, got.ExpiresAt, expired)
+	}
+
+	if err := s.PruneExpiredQueryShares(ctx, now); err != nil {
+		t.Fatalf("PruneExpiredQueryShares: %v", err)
+	}
+	if _, err := s.GetQueryShare(ctx, "expired-share"); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("expired share after prune err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetQueryShare(ctx, "permanent-share"); err != nil {
+		t.Errorf("permanent share after prune: %v", err)
+	}
+}
+
 func testUserPreferences(t *testing.T, ctx context.Context, s store.Store) {
 	u := mkUser(t, ctx, s, "prefs@test.dev")
 	if err := s.UpsertUserPreferencesJSON(ctx, u.ID, `{"theme":"dark"}`); err != nil {
@@ -0,0 +1,81 @@
+package syslog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// errFrameTooLarge marks a newline-framed message longer than the limit. The
+// message is skipped and the stream stays usable.
+var errFrameTooLarge = errors.New("message exceeds max_message_bytes")
+
+// readFrame reads one message from a TCP stream framed per RFC 6587: octet
+// counting ("LEN SP MSG") when the frame starts with a digit, otherwise
+// non-transparent framing terminated by LF.
+func readFrame(r *bufio.Reader, maxBytes int) ([]byte, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if first[0] >= '1' && first[0] <= '9' {
+		return readOctetCounted(r, maxBytes)
+	}
+	return readLine(r, maxBytes)
+}
+
+func readOctetCounted(r *bufio.Reader, maxBytes int) ([]byte, error) {
+	n := 0
+	for digits := 0; ; digits++ {
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if c == ' ' {
+			break
+		}
+		if c < '0' || c > '9' || digits >= 9 {
+			// Lost sync with the sender; there is no safe way to resume.
+			return nil, fmt.Errorf("invalid octet count framing")
+		}
+		n = n*10 + int(c-'0')
+	}
+	if n > maxBytes {
+		return nil, fmt.Errorf("frame of %d bytes exceeds max_message_bytes", n)
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func readLine(r *bufio.Reader, maxBytes int) ([]byte, error) {
+	var msg []byte
+	tooLarge := false
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if !tooLarge {
+			if len(msg)+len(chunk) > maxBytes+1 { // +1 for the LF itself
+				tooLarge, msg = true, nil
+			} else {
+				msg = append(msg, chunk...)
+			}
+		}
+		switch {
+		case err == nil:
+			if tooLarge {
+				return nil, errFrameTooLarge
+			}
+			return msg, nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF) && len(msg) > 0 && !tooLarge:
+			// A final message without a trailing LF.
+			return msg, nil
+		default:
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/ingest"
+	"github.com/mr-karan/logchef/internal/metrics"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+const (
+	// insertTimeout bounds one batch insert.
+	insertTimeout = 30 * time.Second
+	// tcpIdleTimeout closes TCP connections that send nothing for this long.
+	tcpIdleTimeout = 10 * time.Minute
+	// maxUDPDatagram is the largest UDP payload.
+	maxUDPDatagram = 65535
+)
+
+// sourceStore is the store surface the manager needs. store.Store
+// implements it.
+type sourceStore interface {
+	GetSource(ctx context.Context, id models.SourceID) (*models.Source, error)
+}
+
+// logIngester inserts JSONEachRow rows into a source. datasource.Service
+// implements it.
+type logIngester interface {
+	IngestLogs(ctx context.Context, sourceID models.SourceID, rows []byte, wait bool) error
+}
+
+// Options encapsulates the dependencies of the syslog manager.
+type Options struct {
+	Config      config.IngestConfig
+	DB          sourceStore
+	Datasources logIngester
+	Logger      *slog.Logger
+}
+
+// Manager runs the configured syslog listeners.
+type Manager struct {
+	cfg         config.IngestConfig
+	db          sourceStore
+	datasources logIngester
+	log         *slog.Logger
+
+	// now is a seam for tests.
+	now func() time.Time
+
+	mu        sync.Mutex
+	closers   []io.Closer
+	conns     map[net.Conn]struct{}
+	listeners []*listener
+
+	stop      chan struct{}  // closed by Stop before it closes the sockets
+	receivers sync.WaitGroup // goroutines reading sockets
+	flushers  sync.WaitGroup // one batching goroutine per listener
+}
+
+// listener is one configured source binding with its batch queue.
+type listener struct {
+	sourceID models.SourceID
+	rows     chan ingest.Row
+}
+
+// NewManager constructs a syslog manager.
+func NewManager(opts Options) *Manager {
+	return &Manager{
+		cfg:         opts.Config,
+		db:          opts.DB,
+		datasources: opts.Datasources,
+		log:         opts.Logger.With("component", "syslog_manager"),
+		now:         time.Now,
+		conns:       make(map[net.Conn]struct{}),
+		stop:        make(chan struct{}),
+	}
+}
+
+// Start binds every configured listener. It fails if a listener's source is
+// not a LogChef-created ClickHouse table or its address can't be bound, so a
+// misconfiguration surfaces at startup rather than as silently dropped logs.
+func (m *Manager) Start(ctx context.Context) error {
+	for _, lc := range m.cfg.Syslog.Listeners {
+		if err := m.startListener(ctx, lc); err != nil {
+			m.closeSockets()
+			return fmt.Errorf("syslog listener for source %d: %w", lc.SourceID, err)
+		}
+	}
+	return nil
+}
+
+func (m *Manager) startListener(ctx context.Context, lc config.SyslogListenerConfig) error {
+	sourceID := models.SourceID(lc.SourceID)
+	source, err := m.db.GetSource(ctx, sourceID)
+	if err != nil {
+		return fmt.Errorf("loading source: %w", err)
+	}
+	if models.NormalizeSourceType(source.SourceType) != models.SourceTypeClickHouse || !source.MetaIsAutoCreated {
+		return errors.New("source must be a ClickHouse source whose table LogChef created")
+	}
+
+	l := &listener{sourceID: sourceID, rows: make(chan ingest.Row, m.cfg.Syslog.BatchSize)}
+	var nc net.ListenConfig
+	if lc.UDPAddress != "" {
+		conn, err := nc.ListenPacket(ctx, "udp", lc.UDPAddress)
+		if err != nil {
+			return fmt.Errorf("binding udp: %w", err)
+		}
+		m.track(conn)
+		m.receivers.Go(func() { m.serveUDP(conn, l) })
+		m.log.Info("syslog listener started", "source_id", sourceID, "protocol", "udp", "address", conn.LocalAddr().String())
+	}
+	if lc.TCPAddress != "" {
+		ln, err := nc.Listen(ctx, "tcp", lc.TCPAddress)
+		if err != nil {
+			return fmt.Errorf("binding tcp: %w", err)
+		}
+		m.track(ln)
+		m.receivers.Go(func() { m.serveTCP(ln, l) })
+		m.log.Info("syslog listener started", "source_id", sourceID, "protocol", "tcp", "address", ln.Addr().String())
+	}
+
+	m.mu.Lock()
+	m.listeners = append(m.listeners, l)
+	m.mu.Unlock()
+	m.flushers.Go(func() { m.batch(l) })
+	return nil
+}
+
+// Stop closes the sockets, waits for in-flight messages to be queued and
+// inserts what is still buffered.
+func (m *Manager) Stop() {
+	close(m.stop)
+	m.closeSockets()
+	m.receivers.Wait()
+	m.mu.Lock()
+	for _, l := range m.listeners {
+		close(l.rows)
+	}
+	m.mu.Unlock()
+	m.flushers.Wait()
+}
+
+func (m *Manager) track(c io.Closer) {
+	m.mu.Lock()
+	m.closers = append(m.closers, c)
+	m.mu.Unlock()
+}
+
+func (m *Manager) closeSockets() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.closers {
+		_ = c.Close()
+	}
+	m.closers = nil
+	for conn := range m.conns {
+		_ = conn.Close()
+	}
+}
+
+func (m *Manager) serveUDP(conn net.PacketConn, l *listener) {
+	buf := make([]byte, maxUDPDatagram)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				m.log.Error("syslog udp read failed", "source_id", l.sourceID, "error", err)
+			}
+			return
+		}
+		msg := buf[:n]
+		if n > m.cfg.Syslog.MaxMessageBytes {
+			msg = msg[:m.cfg.Syslog.MaxMessageBytes]
+		}
+		m.handle(l, msg, addr)
+	}
+}
+
+func (m *Manager) serveTCP(ln net.Listener, l *listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				m.log.Error("syslog tcp accept failed", "source_id", l.sourceID, "error", err)
+			}
+			return
+		}
+		m.mu.Lock()
+		select {
+		case <-m.stop:
+			// Accepted while Stop was closing connections; it would be missed.
+			m.mu.Unlock()
+			_ = conn.Close()
+			return
+		default:
+		}
+		m.conns[conn] = struct{}{}
+		m.mu.Unlock()
+		m.receivers.Go(func() {
+			defer func() {
+				m.mu.Lock()
+				delete(m.conns, conn)
+				m.mu.Unlock()
+				_ = conn.Close()
+			}()
+			m.serveConn(conn, l)
+		})
+	}
+}
+
+func (m *Manager) serveConn(conn net.Conn, l *listener) {
+	r := bufio.NewReaderSize(conn, 64*1024)
+	for {
+		_ = conn.SetReadDeadline(m.now().Add(tcpIdleTimeout))
+		msg, err := readFrame(r, m.cfg.Syslog.MaxMessageBytes)
+		if errors.Is(err, errFrameTooLarge) {
+			metrics.RecordSyslogMessage(l.sourceID, "too_large")
+			continue
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				m.log.Debug("syslog tcp connection closed", "source_id", l.sourceID, "remote", conn.RemoteAddr().String(), "error", err)
+			}
+			return
+		}
+		m.handle(l, msg, conn.RemoteAddr())
+	}
+}
+
+// handle parses a message and queues its row. The queue is bounded: when
+// inserts fall behind, TCP senders are slowed down and UDP datagrams back up
+// in the socket buffer until the kernel drops them.
+func (m *Manager) handle(l *listener, msg []byte, from net.Addr) {
+	row, err := Parse(msg, m.now())
+	if err != nil {
+		metrics.RecordSyslogMessage(l.sourceID, "invalid")
+		return
+	}
+	if host, _, err := net.SplitHostPort(from.String()); err == nil {
+		row.LogAttributes["net.peer.ip"] = host
+	}
+	l.rows <- row
+	metrics.RecordSyslogMessage(l.sourceID, "received")
+}
+
+// batch collects rows until BatchSize or FlushInterval and inserts them. It
+// returns after flushing what is left once the queue is closed.
+func (m *Manager) batch(l *listener) {
+	ticker := time.NewTicker(m.cfg.Syslog.FlushInterval)
+	defer ticker.Stop()
+
+	pending := make([]ingest.Row, 0, m.cfg.Syslog.BatchSize)
+	for {
+		select {
+		case row, ok := <-l.rows:
+			if !ok {
+				m.flush(l, pending)
+				return
+			}
+			pending = append(pending, row)
+			if len(pending) >= m.cfg.Syslog.BatchSize {
+				m.flush(l, pending)
+				pending = pending[:0]
+			}
+		case <-ticker.C:
+			m.flush(l, pending)
+			pending = pending[:0]
+		}
+	}
+}
+
+// flush inserts rows. A failed batch is logged and dropped: syslog has no
+// acknowledgements, so there is no sender to retry it.
+func (m *Manager) flush(l *listener, rows []ingest.Row) {
+	if len(rows) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), insertTimeout)
+	defer cancel()
+	batch := ingest.EncodeRows(rows)
+	if err := m.datasources.IngestLogs(ctx, l.sourceID, batch.Data, m.cfg.WaitForAsyncInsert); err != nil {
+		m.log.Error("failed to insert syslog batch", "source_id", l.sourceID, "rows", batch.Rows, "error", err)
+		metrics.RecordSyslogInsert(l.sourceID, false, batch.Rows)
+		return
+	}
+	metrics.RecordSyslogInsert(l.sourceID, true, batch.Rows)
+}
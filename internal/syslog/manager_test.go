@@ -0,0 +1,138 @@
+package syslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+type fakeSources map[models.SourceID]*models.Source
+
+func (f fakeSources) GetSource(_ context.Context, id models.SourceID) (*models.Source, error) {
+	if s, ok := f[id]; ok {
+		return s, nil
+	}
+	return nil, models.ErrNotFound
+}
+
+type fakeIngester struct {
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (f *fakeIngester) IngestLogs(_ context.Context, sourceID models.SourceID, rows []byte, _ bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	dec := json.NewDecoder(bytes.NewReader(rows))
+	for {
+		var row struct {
+			Body string `json:"body"`
+		}
+		if err := dec.Decode(&row); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		f.bodies = append(f.bodies, fmt.Sprintf("%d:%s", sourceID, row.Body))
+	}
+}
+
+func freePort(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func (f *fakeIngester) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.bodies)
+}
+
+func newTestManager(sources fakeSources, ingester *fakeIngester, listeners ...config.SyslogListenerConfig) *Manager {
+	return NewManager(Options{
+		Config: config.IngestConfig{Syslog: config.SyslogConfig{
+			Listeners:       listeners,
+			BatchSize:       3,
+			FlushInterval:   time.Hour,
+			MaxMessageBytes: 1024,
+		}},
+		DB:          sources,
+		Datasources: ingester,
+		Logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+}
+
+func TestManagerInsertsBatches(t *testing.T) {
+	sources := fakeSources{
+		7: {ID: 7, SourceType: models.SourceTypeClickHouse, MetaIsAutoCreated: true},
+	}
+	ingester := &fakeIngester{}
+	addr := freePort(t)
+	m := newTestManager(sources, ingester, config.SyslogListenerConfig{SourceID: 7, UDPAddress: addr, TCPAddress: addr})
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	udp, err := net.Dial("udp", addr)
+	if err != nil {
+		t.Fatalf("dial udp: %v", err)
+	}
+	defer udp.Close()
+	if _, err := udp.Write([]byte("<13>Jan  3 11:59:00 host app: over udp")); err != nil {
+		t.Fatalf("write udp: %v", err)
+	}
+
+	tcp, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial tcp: %v", err)
+	}
+	framed := "<13>1 - - app - - - framed"
+	if _, err := fmt.Fprintf(tcp, "<13>1 - - app - - - over tcp\n%d %s", len(framed), framed); err != nil {
+		t.Fatalf("write tcp: %v", err)
+	}
+	tcp.Close()
+
+	// A full batch is inserted without waiting for the flush interval.
+	deadline := time.Now().Add(5 * time.Second)
+	for ingester.count() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	m.Stop()
+
+	sort.Strings(ingester.bodies)
+	want := []string{"7:framed", "7:over tcp", "7:over udp"}
+	if fmt.Sprint(ingester.bodies) != fmt.Sprint(want) {
+		t.Errorf("inserted = %q, want %q", ingester.bodies, want)
+	}
+}
+
+func TestManagerStartRejectsUnsuitableSources(t *testing.T) {
+	sources := fakeSources{
+		1: {ID: 1, SourceType: models.SourceTypeClickHouse},
+		2: {ID: 2, SourceType: models.SourceTypeVictoriaLogs, MetaIsAutoCreated: true},
+	}
+	for _, id := range []int{1, 2, 3} {
+		m := newTestManager(sources, &fakeIngester{}, config.SyslogListenerConfig{SourceID: id, UDPAddress: "127.0.0.1:0"})
+		if err := m.Start(context.Background()); err == nil {
+			t.Errorf("source %d: expected Start to fail", id)
+		}
+		m.Stop()
+	}
+}
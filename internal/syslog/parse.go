@@ -0,0 +1,271 @@
+// Package syslog receives syslog messages over UDP and TCP and inserts them
+// into ClickHouse sources, so network devices and legacy applications can log
+// to LogChef directly.
+//
+// Both RFC 5424 and the older BSD format (RFC 3164) are parsed into rows of
+// LogChef's default OTel logs table: the application name becomes
+// service_name, the syslog severity maps onto the OTel severity, and the
+// hostname, facility, process ID, message ID and structured data go into
+// log_attributes.
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mr-karan/logchef/internal/ingest"
+)
+
+// defaultPriority is assumed for messages without a PRI part: facility user,
+// severity notice, as RFC 3164 relays do.
+const defaultPriority = 13
+
+// nilValue is the RFC 5424 NILVALUE.
+const nilValue = "-"
+
+var errEmptyMessage = errors.New("empty message")
+
+var facilityNames = [...]string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// severities maps syslog severities to their names and OTel severity
+// numbers and texts.
+var severities = [...]struct {
+	name   string
+	number int32
+	text   string
+}{
+	{"emerg", 23, "FATAL"},
+	{"alert", 22, "FATAL"},
+	{"crit", 21, "FATAL"},
+	{"err", 17, "ERROR"},
+	{"warning", 13, "WARN"},
+	{"notice", 10, "INFO"},
+	{"info", 9, "INFO"},
+	{"debug", 5, "DEBUG"},
+}
+
+var bsdMonths = map[string]time.Month{
+	"Jan": time.January, "Feb": time.February, "Mar": time.March, "Apr": time.April,
+	"May": time.May, "Jun": time.June, "Jul": time.July, "Aug": time.August,
+	"Sep": time.September, "Oct": time.October, "Nov": time.November, "Dec": time.December,
+}
+
+// Parse decodes one syslog message into a row. received is used when the
+// message carries no usable timestamp, and to complete the year of BSD
+// timestamps. Malformed headers are not an error: whatever follows the
+// priority is kept as the body.
+func Parse(msg []byte, received time.Time) (ingest.Row, error) {
+	msg = bytes.TrimRight(msg, "\r\n\x00")
+	if len(bytes.TrimSpace(msg)) == 0 {
+		return ingest.Row{}, errEmptyMessage
+	}
+
+	pri, rest := parsePriority(msg)
+	sev := severities[pri%8]
+	row := ingest.Row{
+		Timestamp:      received,
+		SeverityNumber: sev.number,
+		SeverityText:   sev.text,
+		LogAttributes: map[string]string{
+			"syslog.facility": facilityNames[pri/8],
+			"syslog.severity": sev.name,
+		},
+	}
+
+	if len(rest) >= 2 && rest[0] >= '1' && rest[0] <= '9' && rest[1] == ' ' {
+		parse5424(&row, string(rest))
+	} else {
+		parse3164(&row, string(rest), received)
+	}
+	row.Body = strings.ToValidUTF8(row.Body, string(utf8.RuneError))
+	return row, nil
+}
+
+// parsePriority reads the <PRI> prefix, falling back to defaultPriority when
+// it is missing or out of range.
+func parsePriority(msg []byte) (int, []byte) {
+	if len(msg) < 3 || msg[0] != '<' {
+		return defaultPriority, msg
+	}
+	end := bytes.IndexByte(msg[:min(len(msg), 5)], '>')
+	if end < 2 {
+		return defaultPriority, msg
+	}
+	pri, err := strconv.Atoi(string(msg[1:end]))
+	if err != nil || pri < 0 || pri > 191 {
+		return defaultPriority, msg
+	}
+	return pri, msg[end+1:]
+}
+
+// parse5424 reads "VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD [MSG]".
+func parse5424(row *ingest.Row, s string) {
+	var fields [6]string
+	for i := range fields {
+		var ok bool
+		fields[i], s, ok = strings.Cut(s, " ")
+		if !ok && i < len(fields)-1 {
+			// Truncated header: keep what is there as the body.
+			row.Body = strings.Join(append(fields[:i:i], fields[i]), " ")
+			return
+		}
+	}
+	version, timestamp, hostname, appName, procID, msgID := fields[0], fields[1], fields[2], fields[3], fields[4], fields[5]
+
+	row.LogAttributes["syslog.version"] = version
+	if timestamp != nilValue {
+		if t, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+			row.Timestamp = t
+		}
+	}
+	setAttr(row, "host.name", hostname)
+	if appName != nilValue {
+		row.ServiceName = appName
+	}
+	setAttr(row, "syslog.procid", procID)
+	setAttr(row, "syslog.msgid", msgID)
+
+	s = parseStructuredData(row, s)
+	s = strings.TrimPrefix(s, " ")
+	row.Body = strings.TrimPrefix(s, "\ufeff")
+}
+
+// parseStructuredData consumes STRUCTURED-DATA, storing each parameter as
+// "syslog.sd.<SD-ID>.<PARAM>", and returns the rest of the message.
+func parseStructuredData(row *ingest.Row, s string) string {
+	if strings.HasPrefix(s, nilValue) {
+		return s[len(nilValue):]
+	}
+	for strings.HasPrefix(s, "[") {
+		end := 1
+		for end < len(s) && s[end] != ' ' && s[end] != ']' {
+			end++
+		}
+		id := s[1:end]
+		s = s[end:]
+		for {
+			s = strings.TrimLeft(s, " ")
+			if s == "" {
+				return ""
+			}
+			if s[0] == ']' {
+				s = s[1:]
+				break
+			}
+			name, rest, ok := strings.Cut(s, "=")
+			if !ok || !strings.HasPrefix(rest, `"`) {
+				// Malformed element: stop parsing and keep the rest as the body.
+				return s
+			}
+			value, rest, ok := readParamValue(rest[1:])
+			if !ok {
+				return s
+			}
+			row.LogAttributes["syslog.sd."+id+"."+name] = value
+			s = rest
+		}
+	}
+	return s
+}
+
+// readParamValue reads a quoted SD-PARAM value up to its closing quote,
+// unescaping \", \\ and \].
+func readParamValue(s string) (value, rest string, ok bool) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '\\' || s[i+1] == ']'):
+			b.WriteByte(s[i+1])
+			i++
+		case c == '"':
+			return b.String(), s[i+1:], true
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", false
+}
+
+// parse3164 reads "TIMESTAMP HOSTNAME TAG[PID]: MSG". Senders vary a lot: an
+// RFC 3339 timestamp is accepted in place of the BSD one, and a missing
+// hostname is detected by the tag's trailing colon. A message that doesn't
+// start with a timestamp is kept whole as the body.
+func parse3164(row *ingest.Row, s string, received time.Time) {
+	if t, rest, ok := parseBSDTimestamp(s, received); ok {
+		row.Timestamp = t
+		s = rest
+	} else if first, rest, ok := strings.Cut(s, " "); ok {
+		t, err := time.Parse(time.RFC3339Nano, first)
+		if err != nil {
+			// Without a timestamp there is no header; it is all content.
+			row.Body = s
+			return
+		}
+		row.Timestamp = t
+		s = rest
+	} else {
+		row.Body = s
+		return
+	}
+
+	if host, rest, ok := strings.Cut(s, " "); ok && host != "" && !isTag(host) {
+		setAttr(row, "host.name", host)
+		s = rest
+	}
+	if tag, rest, ok := strings.Cut(s, " "); ok && isTag(tag) {
+		tag = strings.TrimSuffix(tag, ":")
+		if name, pid, hasPID := strings.Cut(tag, "["); hasPID {
+			tag = name
+			setAttr(row, "syslog.procid", strings.TrimSuffix(pid, "]"))
+		}
+		row.ServiceName = tag
+		s = rest
+	}
+	row.Body = s
+}
+
+// isTag reports whether a header token is a TAG ("sshd:", "cron[42]:").
+func isTag(tok string) bool {
+	return len(tok) > 1 && tok[len(tok)-1] == ':'
+}
+
+// parseBSDTimestamp reads "Mmm dd hh:mm:ss ". The year is taken from received,
+// minus one when that would put the message more than a day in the future
+// (a December message received in January).
+func parseBSDTimestamp(s string, received time.Time) (time.Time, string, bool) {
+	if len(s) < 16 || s[3] != ' ' || s[6] != ' ' || s[15] != ' ' {
+		return time.Time{}, s, false
+	}
+	month, ok := bsdMonths[s[:3]]
+	if !ok {
+		return time.Time{}, s, false
+	}
+	day, err := strconv.Atoi(strings.TrimSpace(s[4:6]))
+	if err != nil {
+		return time.Time{}, s, false
+	}
+	clock, err := time.Parse(time.TimeOnly, s[7:15])
+	if err != nil {
+		return time.Time{}, s, false
+	}
+	received = received.UTC()
+	t := time.Date(received.Year(), month, day, clock.Hour(), clock.Minute(), clock.Second(), 0, time.UTC)
+	if t.After(received.Add(24 * time.Hour)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t, s[16:], true
+}
+
+func setAttr(row *ingest.Row, key, value string) {
+	if value != "" && value != nilValue {
+		row.LogAttributes[key] = value
+	}
+}
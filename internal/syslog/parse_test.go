@@ -0,0 +1,164 @@
+package syslog
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var received = time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC)
+
+func TestParseRFC5424(t *testing.T) {
+	msg := `<165>1 2026-01-03T10:14:15.003Z mymachine.example.com evntslog 8710 ID47 [exampleSDID@32473 iut="3" eventSource="App\"lication"][origin ip="192.0.2.1"] ` + "\ufeff" + `An application event`
+	row, err := Parse([]byte(msg+"\n"), received)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !row.Timestamp.Equal(time.Date(2026, 1, 3, 10, 14, 15, 3_000_000, time.UTC)) {
+		t.Errorf("Timestamp = %v", row.Timestamp)
+	}
+	if row.SeverityText != "INFO" || row.SeverityNumber != 10 {
+		t.Errorf("severity = %q/%d, want INFO/10 (notice)", row.SeverityText, row.SeverityNumber)
+	}
+	if row.ServiceName != "evntslog" {
+		t.Errorf("ServiceName = %q", row.ServiceName)
+	}
+	if row.Body != "An application event" {
+		t.Errorf("Body = %q", row.Body)
+	}
+	want := map[string]string{
+		"syslog.facility":                 "local4",
+		"syslog.severity":                 "notice",
+		"syslog.version":                  "1",
+		"host.name":                       "mymachine.example.com",
+		"syslog.procid":                   "8710",
+		"syslog.msgid":                    "ID47",
+		"syslog.sd.exampleSDID@32473.iut": "3",
+		"syslog.sd.exampleSDID@32473.eventSource": `App"lication`,
+		"syslog.sd.origin.ip":                     "192.0.2.1",
+	}
+	assertAttributes(t, row.LogAttributes, want)
+}
+
+func TestParseRFC5424NilValues(t *testing.T) {
+	row, err := Parse([]byte("<11>1 - - - - - -"), received)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !row.Timestamp.Equal(received) || row.ServiceName != "" || row.Body != "" {
+		t.Errorf("row = %+v, want received time and empty fields", row)
+	}
+	if row.SeverityText != "ERROR" {
+		t.Errorf("SeverityText = %q, want ERROR", row.SeverityText)
+	}
+	assertAttributes(t, row.LogAttributes, map[string]string{
+		"syslog.facility": "user",
+		"syslog.severity": "err",
+		"syslog.version":  "1",
+	})
+}
+
+func TestParseRFC3164(t *testing.T) {
+	cases := []struct {
+		name    string
+		msg     string
+		ts      time.Time
+		host    string
+		service string
+		procID  string
+		body    string
+	}{
+		{
+			name: "full header", msg: "<34>Oct 11 22:14:15 mymachine su[42]: 'su root' failed for lonvick on /dev/pts/8",
+			ts: time.Date(2025, 10, 11, 22, 14, 15, 0, time.UTC), host: "mymachine", service: "su", procID: "42",
+			body: "'su root' failed for lonvick on /dev/pts/8",
+		},
+		{
+			name: "padded day, no hostname", msg: "<13>Jan  3 11:59:00 cron: job done",
+			ts: time.Date(2026, 1, 3, 11, 59, 0, 0, time.UTC), service: "cron", body: "job done",
+		},
+		{
+			name: "rfc3339 timestamp", msg: "<30>2026-01-03T11:00:00+05:30 router1 dhcpd: lease granted",
+			ts: time.Date(2026, 1, 3, 5, 30, 0, 0, time.UTC), host: "router1", service: "dhcpd", body: "lease granted",
+		},
+		{
+			name: "no header", msg: "just some text",
+			ts: received, body: "just some text",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			row, err := Parse([]byte(tc.msg), received)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !row.Timestamp.Equal(tc.ts) {
+				t.Errorf("Timestamp = %v, want %v", row.Timestamp, tc.ts)
+			}
+			if row.LogAttributes["host.name"] != tc.host || row.ServiceName != tc.service || row.LogAttributes["syslog.procid"] != tc.procID {
+				t.Errorf("host, service, procid = %q, %q, %q", row.LogAttributes["host.name"], row.ServiceName, row.LogAttributes["syslog.procid"])
+			}
+			if row.Body != tc.body {
+				t.Errorf("Body = %q, want %q", row.Body, tc.body)
+			}
+		})
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	row, _ := Parse([]byte("no pri"), received)
+	if row.LogAttributes["syslog.facility"] != "user" || row.LogAttributes["syslog.severity"] != "notice" {
+		t.Errorf("missing PRI: attributes = %v, want user.notice", row.LogAttributes)
+	}
+	row, _ = Parse([]byte("<999>too big"), received)
+	if row.Body != "<999>too big" {
+		t.Errorf("out-of-range PRI: Body = %q, want message kept whole", row.Body)
+	}
+	if _, err := Parse([]byte("\r\n"), received); err == nil {
+		t.Error("empty message: expected an error")
+	}
+}
+
+func TestReadFrame(t *testing.T) {
+	stream := "11 <13>1 - - -<13>plain line\r\n" + strings.Repeat("x", 40) + "\n24 <13>octet\ncounted frame\n<13>last"
+	r := bufio.NewReaderSize(strings.NewReader(stream), 16)
+
+	var got []string
+	var tooLarge int
+	for {
+		msg, err := readFrame(r, 32)
+		if errors.Is(err, errFrameTooLarge) {
+			tooLarge++
+			continue
+		}
+		if err != nil {
+			break
+		}
+		got = append(got, strings.TrimRight(string(msg), "\r\n"))
+	}
+	want := []string{"<13>1 - - -", "<13>plain line", "<13>octet\ncounted frame", "<13>last"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("frames = %q, want %q", got, want)
+	}
+	if tooLarge != 1 {
+		t.Errorf("oversized lines = %d, want 1", tooLarge)
+	}
+
+	if _, err := readFrame(bufio.NewReader(strings.NewReader("99999 x")), 32); err == nil {
+		t.Error("oversized octet count: expected an error")
+	}
+}
+
+func assertAttributes(t *testing.T, got, want map[string]string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("attributes = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("attribute %q = %q, want %q", k, got[k], v)
+		}
+	}
+}
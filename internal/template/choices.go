@@ -0,0 +1,36 @@
+package template
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaxChoices caps the number of choices a source query contributes.
+const MaxChoices = 1000
+
+// ChoiceLoader returns the choices listed by a saved query.
+type ChoiceLoader func(ctx context.Context, savedQueryID int) ([]string, error)
+
+// ResolveChoices fills in the allowed values of enum variables that name a
+// source query, so they are checked against what the server loads rather than
+// against a list sent by the client. Each query is loaded once.
+func ResolveChoices(ctx context.Context, vars []Variable, load ChoiceLoader) error {
+	loaded := make(map[int][]string)
+	for i := range vars {
+		v := &vars[i]
+		if v.Type != TypeEnum || v.SourceQueryID == 0 {
+			continue
+		}
+		choices, ok := loaded[v.SourceQueryID]
+		if !ok {
+			var err error
+			choices, err = load(ctx, v.SourceQueryID)
+			if err != nil {
+				return fmt.Errorf("variable %s: failed to load choices: %w", v.Name, err)
+			}
+			loaded[v.SourceQueryID] = choices
+		}
+		v.AllowedValues = choices
+	}
+	return nil
+}
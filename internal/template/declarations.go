@@ -0,0 +1,258 @@
+package template
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Declaration describes a variable as the SQL template declares it. A
+// placeholder may carry a type and a default after the name, separated by
+// colons: {{limit:number:default=100}} or {{host:default="prod-1"}}. An enum
+// also names its choices, inline or as a saved query:
+// {{env:enum:options="prod,staging"}} or {{env:enum:source_query=12}}.
+type Declaration struct {
+	Name string       `json:"name"`
+	Type VariableType `json:"type"`
+	// Default is the value used when none is given; nil when the variable
+	// has no default and so requires a value.
+	Default *string `json:"default,omitempty"`
+	// Options are an enum's inline choices.
+	Options []string `json:"options,omitempty"`
+	// SourceQueryID is the saved query listing an enum's choices.
+	SourceQueryID int `json:"source_query_id,omitempty"`
+}
+
+// declarableTypes are the types a placeholder may declare.
+var declarableTypes = map[VariableType]bool{
+	TypeString: true,
+	TypeText:   true,
+	TypeNumber: true,
+	TypeDate:   true,
+	TypeEnum:   true,
+}
+
+// ParseVariables returns the variables sql declares, in order of first use.
+// Undeclared types default to string. A variable may be written several
+// times, but declarations that disagree are an error.
+func ParseVariables(sql string) ([]Declaration, error) {
+	var decls []Declaration
+	index := make(map[string]int)
+	for _, m := range variablePattern.FindAllStringSubmatch(sql, -1) {
+		decl, err := parseDeclaration(m[1], m[2])
+		if err != nil {
+			return nil, err
+		}
+		i, seen := index[decl.Name]
+		if !seen {
+			index[decl.Name] = len(decls)
+			decls = append(decls, decl)
+			continue
+		}
+		prev := &decls[i]
+		if decl.Type != "" {
+			if prev.Type != "" && prev.Type != decl.Type {
+				return nil, fmt.Errorf("variable {{%s}} is declared with types %s and %s", decl.Name, prev.Type, decl.Type)
+			}
+			prev.Type = decl.Type
+		}
+		if decl.Default != nil {
+			if prev.Default != nil && *prev.Default != *decl.Default {
+				return nil, fmt.Errorf("variable {{%s}} is declared with different defaults", decl.Name)
+			}
+			prev.Default = decl.Default
+		}
+		if decl.Options != nil || decl.SourceQueryID != 0 {
+			if (prev.Options != nil || prev.SourceQueryID != 0) &&
+				(!slices.Equal(prev.Options, decl.Options) || prev.SourceQueryID != decl.SourceQueryID) {
+				return nil, fmt.Errorf("variable {{%s}} is declared with different choices", decl.Name)
+			}
+			prev.Options, prev.SourceQueryID = decl.Options, decl.SourceQueryID
+		}
+	}
+	for i := range decls {
+		d := &decls[i]
+		if d.Type == "" {
+			d.Type = TypeString
+		}
+		hasChoices := d.Options != nil || d.SourceQueryID != 0
+		if d.Type == TypeEnum && !hasChoices {
+			return nil, fmt.Errorf("enum variable {{%s}} needs options or a source_query", d.Name)
+		}
+		if d.Type != TypeEnum && hasChoices {
+			return nil, fmt.Errorf("variable {{%s}}: options and source_query need the enum type", d.Name)
+		}
+	}
+	return decls, nil
+}
+
+// ApplyDeclaredEnums makes the template's enum declarations authoritative:
+// each variable the template declares an enum takes its choices from the
+// declaration, whatever the request sent, and a request giving it another
+// type is rejected. An enum with a default is added when the request omits
+// it, so the default is checked too. Call ResolveChoices afterwards to load
+// source query choices.
+func ApplyDeclaredEnums(sql string, vars []Variable) ([]Variable, error) {
+	decls, err := ParseVariables(sql)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range decls {
+		if d.Type != TypeEnum {
+			continue
+		}
+		i := slices.IndexFunc(vars, func(v Variable) bool { return v.Name == d.Name })
+		if i < 0 {
+			if d.Default == nil {
+				continue
+			}
+			vars = append(vars, Variable{Name: d.Name})
+			i = len(vars) - 1
+		}
+		v := &vars[i]
+		if v.Type != "" && v.Type != TypeEnum {
+			return nil, fmt.Errorf("variable {{%s}} is declared enum, not %s", d.Name, v.Type)
+		}
+		v.Type = TypeEnum
+		v.AllowedValues = d.Options
+		v.SourceQueryID = d.SourceQueryID
+	}
+	return vars, nil
+}
+
+// RequiredVariableNames returns the variables in sql that have no default.
+// Malformed declarations are left for SubstituteVariables to report.
+func RequiredVariableNames(sql string) []string {
+	decls, err := ParseVariables(sql)
+	if err != nil {
+		return ExtractVariableNames(sql)
+	}
+	names := make([]string, 0, len(decls))
+	for _, d := range decls {
+		if d.Default == nil {
+			names = append(names, d.Name)
+		}
+	}
+	return names
+}
+
+// parseDeclaration parses the ":"-separated modifiers after a variable name.
+// Type is left empty when the modifiers don't set one.
+func parseDeclaration(name, modifiers string) (Declaration, error) {
+	decl := Declaration{Name: name}
+	if strings.TrimSpace(modifiers) == "" {
+		return decl, nil
+	}
+	for _, part := range splitModifiers(modifiers) {
+		part = strings.TrimSpace(part)
+		if declarableTypes[VariableType(part)] {
+			decl.Type = VariableType(part)
+			continue
+		}
+		if value, ok := modifierValue(part, "options"); ok {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return Declaration{}, fmt.Errorf("variable {{%s}}: options must be a quoted list", name)
+			}
+			decl.Options = []string{}
+			for _, opt := range strings.Split(unquoted, ",") {
+				if opt = strings.TrimSpace(opt); opt != "" {
+					decl.Options = append(decl.Options, opt)
+				}
+			}
+			if len(decl.Options) == 0 {
+				return Declaration{}, fmt.Errorf("variable {{%s}}: empty options", name)
+			}
+			continue
+		}
+		if value, ok := modifierValue(part, "source_query"); ok {
+			id, err := strconv.Atoi(value)
+			if err != nil || id <= 0 {
+				return Declaration{}, fmt.Errorf("variable {{%s}}: invalid source_query %s", name, value)
+			}
+			decl.SourceQueryID = id
+			continue
+		}
+		value, ok := strings.CutPrefix(part, "default")
+		value, hasEquals := strings.CutPrefix(strings.TrimSpace(value), "=")
+		if !ok || !hasEquals {
+			return Declaration{}, fmt.Errorf("variable {{%s}}: unknown modifier %q", name, part)
+		}
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return Declaration{}, fmt.Errorf("variable {{%s}}: invalid default %s", name, value)
+			}
+			value = unquoted
+		} else if value == "" {
+			return Declaration{}, fmt.Errorf("variable {{%s}}: empty default", name)
+		}
+		decl.Default = &value
+	}
+	return decl, nil
+}
+
+// modifierValue returns the trimmed value of a "key=value" modifier.
+func modifierValue(part, key string) (string, bool) {
+	value, ok := strings.CutPrefix(part, key)
+	if !ok {
+		return "", false
+	}
+	value, ok = strings.CutPrefix(strings.TrimSpace(value), "=")
+	return strings.TrimSpace(value), ok
+}
+
+// splitModifiers splits s at colons outside double quotes.
+func splitModifiers(s string) []string {
+	var parts []string
+	start, inQuote := 0, false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if inQuote {
+				i++
+			}
+		case '"':
+			inQuote = !inQuote
+		case ':':
+			if !inQuote {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func hasDefaults(decls []Declaration) bool {
+	for _, d := range decls {
+		if d.Default != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// applyDefaults gives variables without a value their declared default, typed
+// as declared unless the caller gave a type.
+func applyDefaults(varMap map[string]Variable, decls []Declaration) {
+	for _, d := range decls {
+		if d.Default == nil {
+			continue
+		}
+		v, exists := varMap[d.Name]
+		if exists && isValueProvided(v.Value) {
+			continue
+		}
+		if !exists {
+			v = Variable{Name: d.Name, AllowedValues: d.Options}
+		}
+		if v.Type == "" {
+			v.Type = d.Type
+		}
+		v.Value = *d.Default
+		varMap[d.Name] = v
+	}
+}
@@ -0,0 +1,171 @@
+package template
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVariables(t *testing.T) {
+	def := func(s string) *string { return &s }
+
+	tests := []struct {
+		name        string
+		sql         string
+		want        []Declaration
+		errContains string
+	}{
+		{
+			name: "bare placeholders",
+			sql:  "SELECT * FROM logs WHERE host = {{host}} AND env = {{ env }} AND host != {{host}}",
+			want: []Declaration{{Name: "host", Type: TypeString}, {Name: "env", Type: TypeString}},
+		},
+		{
+			name: "quoted default",
+			sql:  `SELECT * FROM logs WHERE host = {{host:default="prod-1"}}`,
+			want: []Declaration{{Name: "host", Type: TypeString, Default: def("prod-1")}},
+		},
+		{
+			name: "type and bare default",
+			sql:  "SELECT * FROM logs LIMIT {{ limit : number : default=100 }}",
+			want: []Declaration{{Name: "limit", Type: TypeNumber, Default: def("100")}},
+		},
+		{
+			name: "default with colons and escaped quotes",
+			sql:  `SELECT * FROM logs WHERE msg = {{msg:default="a:\"b\""}}`,
+			want: []Declaration{{Name: "msg", Type: TypeString, Default: def(`a:"b"`)}},
+		},
+		{
+			name: "declaration applies to bare uses",
+			sql:  "SELECT {{n}} FROM logs LIMIT {{n:number:default=5}}",
+			want: []Declaration{{Name: "n", Type: TypeNumber, Default: def("5")}},
+		},
+		{
+			name: "enum with inline options",
+			sql:  `SELECT * FROM logs WHERE env = {{env:enum:options="prod, staging":default="prod"}} OR env = {{env}}`,
+			want: []Declaration{{Name: "env", Type: TypeEnum, Default: def("prod"), Options: []string{"prod", "staging"}}},
+		},
+		{
+			name: "enum from a source query",
+			sql:  "SELECT * FROM logs WHERE service = {{service:enum:source_query=12}}",
+			want: []Declaration{{Name: "service", Type: TypeEnum, SourceQueryID: 12}},
+		},
+		{
+			name:        "enum without choices",
+			sql:         "SELECT {{env:enum}}",
+			errContains: "needs options or a source_query",
+		},
+		{
+			name:        "options on a non-enum",
+			sql:         `SELECT {{env:options="a,b"}}`,
+			errContains: "need the enum type",
+		},
+		{
+			name:        "conflicting choices",
+			sql:         `SELECT {{env:enum:options="a"}}, {{env:enum:options="b"}}`,
+			errContains: "different choices",
+		},
+		{
+			name:        "invalid source query",
+			sql:         "SELECT {{env:enum:source_query=x}}",
+			errContains: "invalid source_query",
+		},
+		{
+			name:        "conflicting types",
+			sql:         "SELECT {{n:number}}, {{n:date}}",
+			errContains: "declared with types",
+		},
+		{
+			name:        "conflicting defaults",
+			sql:         `SELECT {{n:default="a"}}, {{n:default="b"}}`,
+			errContains: "different defaults",
+		},
+		{
+			name:        "unknown modifier",
+			sql:         "SELECT {{n:required}}",
+			errContains: "unknown modifier",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseVariables(tt.sql)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("ParseVariables() error = %v, want one containing %q", err, tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseVariables() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseVariables() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubstituteVariablesDefaults(t *testing.T) {
+	sql := `SELECT * FROM logs WHERE host = {{host:default="prod-1"}} [[AND env = {{env:default="prod"}}]] LIMIT {{limit:number:default=10}}`
+
+	got, err := SubstituteVariables(sql, nil)
+	if err != nil {
+		t.Fatalf("SubstituteVariables() error = %v", err)
+	}
+	if want := "SELECT * FROM logs WHERE host = 'prod-1' AND env = 'prod' LIMIT 10"; got != want {
+		t.Errorf("SubstituteVariables() = %q, want %q", got, want)
+	}
+
+	got, err = SubstituteVariables(sql, []Variable{
+		{Name: "host", Type: TypeString, Value: "web-2"},
+		{Name: "limit", Value: ""},
+	})
+	if err != nil {
+		t.Fatalf("SubstituteVariables() error = %v", err)
+	}
+	if want := "SELECT * FROM logs WHERE host = 'web-2' AND env = 'prod' LIMIT 10"; got != want {
+		t.Errorf("SubstituteVariables() = %q, want %q", got, want)
+	}
+
+	if _, err := SubstituteVariables("SELECT {{n:number:default=abc}}", nil); err == nil {
+		t.Error("SubstituteVariables() accepted a non-numeric number default")
+	}
+}
+
+func TestApplyDeclaredEnums(t *testing.T) {
+	sql := `SELECT * FROM logs WHERE env = {{env:enum:options="prod,staging"}} AND region = {{region:enum:source_query=4:default="eu"}} AND host = {{host}}`
+
+	// The declaration's choices win over whatever the request sent.
+	vars, err := ApplyDeclaredEnums(sql, []Variable{
+		{Name: "env", Value: "dev", AllowedValues: []string{"dev"}},
+		{Name: "host", Type: TypeEnum, Value: "a", AllowedValues: []string{"a"}},
+	})
+	if err != nil {
+		t.Fatalf("ApplyDeclaredEnums() error = %v", err)
+	}
+	want := []Variable{
+		{Name: "env", Type: TypeEnum, Value: "dev", AllowedValues: []string{"prod", "staging"}},
+		{Name: "host", Type: TypeEnum, Value: "a", AllowedValues: []string{"a"}},
+		{Name: "region", Type: TypeEnum, SourceQueryID: 4},
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("ApplyDeclaredEnums() = %+v, want %+v", vars, want)
+	}
+	if _, err := SubstituteVariables(sql, vars); err == nil || !strings.Contains(err.Error(), "not one of the allowed values") {
+		t.Errorf("SubstituteVariables() error = %v, want the client's choice rejected", err)
+	}
+
+	// Sending a declared enum as a plain string doesn't skip the check.
+	_, err = ApplyDeclaredEnums(sql, []Variable{{Name: "env", Type: TypeString, Value: "dev"}})
+	if err == nil || !strings.Contains(err.Error(), "declared enum") {
+		t.Errorf("ApplyDeclaredEnums() error = %v, want a type mismatch", err)
+	}
+}
+
+func TestRequiredVariableNames(t *testing.T) {
+	got := RequiredVariableNames(`SELECT {{a}}, {{b:default="x"}}, {{c:number}}`)
+	if want := []string{"a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RequiredVariableNames() = %v, want %v", got, want)
+	}
+}
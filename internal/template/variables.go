@@ -3,6 +3,7 @@ package template
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -20,6 +21,8 @@ const (
 	TypeNumber VariableType = "number"
 	// TypeDate represents a date variable formatted as ClickHouse datetime.
 	TypeDate VariableType = "date"
+	// TypeEnum represents a string variable restricted to AllowedValues.
+	TypeEnum VariableType = "enum"
 )
 
 // Variable represents a template variable with its value.
@@ -27,11 +30,19 @@ type Variable struct {
 	Name  string       `json:"name"`
 	Type  VariableType `json:"type"`
 	Value any          `json:"value"`
+	// AllowedValues are the values an enum variable accepts. When
+	// SourceQueryID is set they are replaced by the saved query's results;
+	// see ResolveChoices.
+	AllowedValues []string `json:"allowed_values,omitempty"`
+	// SourceQueryID is the saved query whose first column lists an enum
+	// variable's choices.
+	SourceQueryID int `json:"source_query_id,omitempty"`
 }
 
 var (
-	// variablePattern matches {{variable_name}} with optional whitespace.
-	variablePattern = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+	// variablePattern matches {{variable_name}} with optional whitespace and
+	// an optional ":"-separated declaration; see ParseVariables.
+	variablePattern = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(?::((?:[^{}"]|"(?:[^"\\]|\\.)*")*))?\}\}`)
 	// validNamePattern validates variable names.
 	validNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 	optionalPattern  = regexp.MustCompile(`\[\[(.+?)\]\]`)
@@ -81,7 +92,11 @@ func ProcessOptionalClauses(sql string, varMap map[string]Variable) string {
 }
 
 func SubstituteVariables(sql string, variables []Variable) (string, error) {
-	if len(variables) == 0 {
+	decls, err := ParseVariables(sql)
+	if err != nil {
+		return "", err
+	}
+	if len(variables) == 0 && !hasDefaults(decls) {
 		sql = ProcessOptionalClauses(sql, nil)
 		return sql, nil
 	}
@@ -94,6 +109,7 @@ func SubstituteVariables(sql string, variables []Variable) (string, error) {
 		}
 		varMap[v.Name] = v
 	}
+	applyDefaults(varMap, decls)
 
 	// Process optional clauses first - removes blocks with missing variables
 	sql = ProcessOptionalClauses(sql, varMap)
@@ -121,7 +137,7 @@ func SubstituteVariables(sql string, variables []Variable) (string, error) {
 		}
 
 		submatches := variablePattern.FindStringSubmatch(match)
-		if len(submatches) != 2 {
+		if len(submatches) < 2 {
 			return match
 		}
 		varName := submatches[1]
@@ -148,6 +164,13 @@ func formatValue(v Variable) (string, error) {
 		varType = TypeString
 	}
 
+	if varType == TypeEnum {
+		if err := checkAllowed(v); err != nil {
+			return "", err
+		}
+		varType = TypeString
+	}
+
 	if isArrayValue(v.Value) {
 		return formatArray(v.Value, varType)
 	}
@@ -164,6 +187,32 @@ func formatValue(v Variable) (string, error) {
 	}
 }
 
+// checkAllowed reports an error unless every value of enum variable v is one
+// of its allowed values.
+func checkAllowed(v Variable) error {
+	if len(v.AllowedValues) == 0 {
+		return fmt.Errorf("enum variable has no allowed values")
+	}
+	var values []any
+	switch val := v.Value.(type) {
+	case []any:
+		values = val
+	case []string:
+		for _, s := range val {
+			values = append(values, s)
+		}
+	default:
+		values = []any{val}
+	}
+	for _, value := range values {
+		s := formatPlain(value)
+		if !slices.Contains(v.AllowedValues, s) {
+			return fmt.Errorf("value %q is not one of the allowed values", s)
+		}
+	}
+	return nil
+}
+
 func isArrayValue(value any) bool {
 	switch value.(type) {
 	case []any, []string:
@@ -217,21 +266,25 @@ func formatSingleValue(value any, varType VariableType) (string, error) {
 	}
 }
 
-// formatString escapes and quotes a string value.
-func formatString(value any) (string, error) {
-	var s string
+// formatPlain renders a scalar value as unquoted text.
+func formatPlain(value any) string {
 	switch val := value.(type) {
 	case string:
-		s = val
+		return val
 	case float64:
-		s = strconv.FormatFloat(val, 'f', -1, 64)
+		return strconv.FormatFloat(val, 'f', -1, 64)
 	case int:
-		s = strconv.Itoa(val)
+		return strconv.Itoa(val)
 	case int64:
-		s = strconv.FormatInt(val, 10)
+		return strconv.FormatInt(val, 10)
 	default:
-		s = fmt.Sprintf("%v", val)
+		return fmt.Sprintf("%v", val)
 	}
+}
+
+// formatString escapes and quotes a string value.
+func formatString(value any) (string, error) {
+	s := formatPlain(value)
 	// Escape single quotes for ClickHouse.
 	escaped := strings.ReplaceAll(s, "'", "''")
 	return fmt.Sprintf("'%s'", escaped), nil
@@ -316,7 +369,7 @@ func ExtractVariableNames(sql string) []string {
 	names := make([]string, 0, len(matches))
 
 	for _, m := range matches {
-		if len(m) == 2 && !seen[m[1]] {
+		if len(m) >= 2 && !seen[m[1]] {
 			names = append(names, m[1])
 			seen[m[1]] = true
 		}
@@ -1,6 +1,8 @@
 package template
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -185,6 +187,40 @@ func TestSubstituteVariables(t *testing.T) {
 			wantErr:     true,
 			errContains: "invalid date format",
 		},
+		{
+			name: "enum variable with an allowed value",
+			sql:  "SELECT * FROM logs WHERE env = {{env}}",
+			variables: []Variable{
+				{Name: "env", Type: TypeEnum, Value: "prod", AllowedValues: []string{"prod", "staging"}},
+			},
+			want: "SELECT * FROM logs WHERE env = 'prod'",
+		},
+		{
+			name: "enum variable with several allowed values",
+			sql:  "SELECT * FROM logs WHERE env IN ({{env}})",
+			variables: []Variable{
+				{Name: "env", Type: TypeEnum, Value: []any{"prod", "staging"}, AllowedValues: []string{"prod", "staging"}},
+			},
+			want: "SELECT * FROM logs WHERE env IN ('prod', 'staging')",
+		},
+		{
+			name: "enum variable with a value outside the list",
+			sql:  "SELECT * FROM logs WHERE env = {{env}}",
+			variables: []Variable{
+				{Name: "env", Type: TypeEnum, Value: "prod' OR 1=1 --", AllowedValues: []string{"prod"}},
+			},
+			wantErr:     true,
+			errContains: "not one of the allowed values",
+		},
+		{
+			name: "enum variable without allowed values",
+			sql:  "SELECT * FROM logs WHERE env = {{env}}",
+			variables: []Variable{
+				{Name: "env", Type: TypeEnum, Value: "prod"},
+			},
+			wantErr:     true,
+			errContains: "no allowed values",
+		},
 	}
 
 	for _, tt := range tests {
@@ -571,3 +607,37 @@ func TestExtractVariableNames(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveChoices(t *testing.T) {
+	loads := 0
+	load := func(_ context.Context, id int) ([]string, error) {
+		loads++
+		if id != 7 {
+			return nil, errors.New("not found")
+		}
+		return []string{"api", "worker"}, nil
+	}
+
+	vars := []Variable{
+		{Name: "service", Type: TypeEnum, Value: "api", AllowedValues: []string{"anything"}, SourceQueryID: 7},
+		{Name: "other", Type: TypeEnum, Value: "worker", SourceQueryID: 7},
+		{Name: "host", Type: TypeString, Value: "a", SourceQueryID: 9},
+	}
+	if err := ResolveChoices(context.Background(), vars, load); err != nil {
+		t.Fatalf("ResolveChoices() error = %v", err)
+	}
+	if loads != 1 {
+		t.Errorf("loads = %d, want 1", loads)
+	}
+	if got := strings.Join(vars[0].AllowedValues, ","); got != "api,worker" {
+		t.Errorf("allowed values = %q, want the loaded choices", got)
+	}
+	if len(vars[2].AllowedValues) != 0 {
+		t.Errorf("non-enum variable got allowed values %v", vars[2].AllowedValues)
+	}
+
+	vars = []Variable{{Name: "service", Type: TypeEnum, Value: "api", SourceQueryID: 8}}
+	if err := ResolveChoices(context.Background(), vars, load); err == nil {
+		t.Error("ResolveChoices() with a failing loader succeeded")
+	}
+}
@@ -0,0 +1,69 @@
+// Package timezone resolves the IANA timezone names clients send with
+// queries and converts result times into them.
+package timezone
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	// Embedded so validation doesn't depend on the host's zoneinfo files,
+	// which slim container images often lack.
+	_ "time/tzdata"
+)
+
+// ErrInvalid is returned for a name that isn't an IANA timezone.
+var ErrInvalid = errors.New("invalid timezone")
+
+// locations caches loaded zones; time.LoadLocation reads and parses zoneinfo
+// on every call.
+var locations sync.Map // name -> *time.Location
+
+// Load resolves an IANA timezone name such as "Asia/Kolkata", with "" meaning
+// UTC. "Local" is rejected: the server's zone means nothing to a client.
+// Names go into ClickHouse SQL as they are, so only zones ClickHouse knows
+// are accepted; a fixed offset is spelled the IANA way, "Etc/GMT-5" for
+// UTC+5, not "UTC+05:00".
+func Load(name string) (*time.Location, error) {
+	if name == "" || name == "UTC" {
+		return time.UTC, nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	if name == "Local" {
+		return nil, fmt.Errorf("%w %q", ErrInvalid, name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q", ErrInvalid, name)
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// Offset formats loc's UTC offset at t as "+05:30".
+func Offset(loc *time.Location, t time.Time) string {
+	_, seconds := t.In(loc).Zone()
+	sign := '+'
+	if seconds < 0 {
+		sign, seconds = '-', -seconds
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// Convert returns v in loc when it is a time.Time or a non-nil *time.Time,
+// and v unchanged otherwise.
+func Convert(v any, loc *time.Location) any {
+	switch t := v.(type) {
+	case time.Time:
+		return t.In(loc)
+	case *time.Time:
+		if t != nil {
+			converted := t.In(loc)
+			return &converted
+		}
+	}
+	return v
+}
@@ -0,0 +1,62 @@
+package timezone
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	for _, name := range []string{"", "UTC", "Asia/Kolkata", "America/New_York", "Etc/GMT-5"} {
+		if _, err := Load(name); err != nil {
+			t.Errorf("Load(%q): %v", name, err)
+		}
+	}
+	for _, name := range []string{"Local", "Mars/Olympus", "UTC+05:30", "UTC-3", "UTC'; DROP TABLE logs"} {
+		if _, err := Load(name); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Load(%q) err = %v, want ErrInvalid", name, err)
+		}
+	}
+}
+
+func TestOffset(t *testing.T) {
+	at := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	tests := map[string]string{
+		"UTC":                "+00:00",
+		"Asia/Kolkata":       "+05:30",
+		"America/New_York":   "-05:00",
+		"America/St_Johns":   "-03:30",
+		"Pacific/Kiritimati": "+14:00",
+		"Asia/Kathmandu":     "+05:45",
+		"Etc/GMT-5":          "+05:00",
+	}
+	for name, want := range tests {
+		loc, err := Load(name)
+		if err != nil {
+			t.Fatalf("Load(%q): %v", name, err)
+		}
+		if got := Offset(loc, at); got != want {
+			t.Errorf("Offset(%s) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	loc, _ := Load("Asia/Kolkata")
+	at := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	got, ok := Convert(at, loc).(time.Time)
+	if !ok || got.Location() != loc || !got.Equal(at) {
+		t.Errorf("Convert(time.Time) = %v, want %v in %s", got, at, loc)
+	}
+	ptr, ok := Convert(&at, loc).(*time.Time)
+	if !ok || ptr.Location() != loc || !ptr.Equal(at) || at.Location() != time.UTC {
+		t.Errorf("Convert(*time.Time) = %v, want a converted copy", ptr)
+	}
+	if v := Convert((*time.Time)(nil), loc); v.(*time.Time) != nil {
+		t.Errorf("Convert(nil) = %v, want nil", v)
+	}
+	if v := Convert("2026-01-15", loc); v != "2026-01-15" {
+		t.Errorf("Convert(string) = %v, want it unchanged", v)
+	}
+}
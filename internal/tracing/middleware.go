@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware starts a server span per request, continuing the trace from a
+// W3C traceparent header when the caller sends one. The span is reachable
+// from both c.Context() and c.UserContext(), and the trace ID is echoed in a
+// traceresponse header so a slow request seen in the browser can be looked up
+// in the tracing backend.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !Enabled() || c.Path() == "/metrics" {
+			return c.Next()
+		}
+
+		ctx := c.UserContext()
+		if remote, ok := ParseTraceparent(c.Get("traceparent")); ok {
+			ctx = trace.ContextWithRemoteSpanContext(ctx, remote)
+		}
+		ctx, span := Start(ctx, c.Method(), trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Method()),
+				attribute.String("url.path", c.Path()),
+			))
+		defer span.End()
+
+		c.SetUserContext(ctx)
+		c.Context().SetUserValue(requestSpanKey{}, span)
+		if sc := span.SpanContext(); sc.IsValid() {
+			c.Set("traceresponse", FormatTraceparent(sc))
+		}
+
+		err := c.Next()
+
+		// The matched route is only known after routing.
+		route := c.Route().Path
+		span.SetName(c.Method() + " " + route)
+		status := c.Response().StatusCode()
+		if err != nil {
+			var fe *fiber.Error
+			if errors.As(err, &fe) {
+				status = fe.Code
+			}
+			span.RecordError(err)
+		}
+		span.SetAttributes(
+			attribute.String("http.route", route),
+			attribute.Int("http.response.status_code", status),
+		)
+		if status >= 500 {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+		return err
+	}
+}
+
+// ParseTraceparent parses a W3C traceparent header
+// ("00-<trace-id>-<span-id>-<flags>").
+func ParseTraceparent(h string) (trace.SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(h), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return trace.SpanContext{}, false
+	}
+	traceID, err := trace.TraceIDFromHex(parts[1])
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	spanID, err := trace.SpanIDFromHex(parts[2])
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil || len(parts[3]) != 2 {
+		return trace.SpanContext{}, false
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.TraceFlags(flags) & trace.FlagsSampled,
+		Remote:     true,
+	})
+	return sc, sc.IsValid()
+}
+
+// FormatTraceparent renders sc as a W3C traceparent value.
+func FormatTraceparent(sc trace.SpanContext) string {
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID(), sc.SpanID(), sc.TraceFlags())
+}
@@ -0,0 +1,322 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mr-karan/logchef/internal/config"
+)
+
+const (
+	exportQueueSize     = 2048
+	exportBatchSize     = 512
+	exportInterval      = 5 * time.Second
+	exportClientTimeout = 10 * time.Second
+)
+
+// exporter batches ended spans and POSTs them to the collector as OTLP/HTTP
+// JSON. When the queue is full spans are dropped rather than blocking the
+// request that ended them.
+type exporter struct {
+	url      string
+	headers  map[string]string
+	resource otlpResource
+	client   *http.Client
+	log      *slog.Logger
+
+	queue chan *span
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+
+	dropMu  sync.Mutex
+	dropped int
+}
+
+func newExporter(cfg config.TracingConfig, log *slog.Logger) *exporter {
+	e := &exporter{
+		url:     strings.TrimSuffix(cfg.Endpoint, "/") + "/v1/traces",
+		headers: cfg.Headers,
+		resource: otlpResource{Attributes: []otlpKeyValue{
+			otlpAttr(attribute.String("service.name", cfg.ServiceName)),
+		}},
+		client: &http.Client{Timeout: exportClientTimeout},
+		log:    log.With("component", "tracing"),
+		queue:  make(chan *span, exportQueueSize),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+func (e *exporter) enqueue(s *span) {
+	select {
+	case e.queue <- s:
+	default:
+		e.dropMu.Lock()
+		e.dropped++
+		e.dropMu.Unlock()
+	}
+}
+
+func (e *exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	batch := make([]*span, 0, exportBatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			e.export(batch)
+			batch = batch[:0]
+		}
+		e.dropMu.Lock()
+		dropped := e.dropped
+		e.dropped = 0
+		e.dropMu.Unlock()
+		if dropped > 0 {
+			e.log.Warn("dropped spans; export queue full", "count", dropped)
+		}
+	}
+	for {
+		select {
+		case s := <-e.queue:
+			batch = append(batch, s)
+			if len(batch) >= exportBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.stop:
+			for {
+				select {
+				case s := <-e.queue:
+					batch = append(batch, s)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// shutdown exports whatever is queued, waiting until ctx is done.
+func (e *exporter) shutdown(ctx context.Context) error {
+	e.once.Do(func() { close(e.stop) })
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *exporter) export(spans []*span) {
+	byScope := make(map[string][]otlpSpan)
+	for _, s := range spans {
+		byScope[s.tracer.scope] = append(byScope[s.tracer.scope], s.otlp())
+	}
+	rs := otlpResourceSpans{Resource: e.resource}
+	for scope, spans := range byScope {
+		rs.ScopeSpans = append(rs.ScopeSpans, otlpScopeSpans{Scope: otlpScope{Name: scope}, Spans: spans})
+	}
+	body, err := json.Marshal(otlpTraces{ResourceSpans: []otlpResourceSpans{rs}})
+	if err != nil {
+		e.log.Error("encoding spans", "error", err)
+		return
+	}
+	if err := e.post(body); err != nil {
+		e.log.Warn("exporting spans failed", "error", err, "spans", len(spans))
+	}
+}
+
+func (e *exporter) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("collector returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// OTLP/JSON encoding of ExportTraceServiceRequest. IDs are hex and 64-bit
+// integers are decimal strings, as the OTLP JSON mapping requires.
+
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	TraceState        string         `json:"traceState,omitempty"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    *string         `json:"intValue,omitempty"`
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpValue `json:"values"`
+}
+
+func (s *span) otlp() otlpSpan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := otlpSpan{
+		TraceID:           s.sc.TraceID().String(),
+		SpanID:            s.sc.SpanID().String(),
+		TraceState:        s.sc.TraceState().String(),
+		Name:              s.name,
+		Kind:              otlpKind(s.kind),
+		StartTimeUnixNano: unixNano(s.start),
+		EndTimeUnixNano:   unixNano(s.end),
+		Attributes:        otlpAttrs(s.attrs),
+		Status:            otlpStatus{Code: otlpStatusCode(s.status), Message: s.statusDesc},
+	}
+	if s.parent.IsValid() {
+		out.ParentSpanID = s.parent.SpanID().String()
+	}
+	for _, ev := range s.events {
+		out.Events = append(out.Events, otlpEvent{TimeUnixNano: unixNano(ev.time), Name: ev.name, Attributes: otlpAttrs(ev.attrs)})
+	}
+	return out
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// otlpKind maps trace.SpanKind to the OTLP enum, where 0 is unspecified and
+// the named kinds start at 1 in the same order.
+func otlpKind(k trace.SpanKind) int {
+	if k == trace.SpanKindUnspecified {
+		return int(trace.SpanKindInternal)
+	}
+	return int(k)
+}
+
+// otlpStatusCode maps codes.Code (Unset, Error, Ok) to the OTLP enum (Unset,
+// Ok, Error).
+func otlpStatusCode(c codes.Code) int {
+	switch c {
+	case codes.Ok:
+		return 1
+	case codes.Error:
+		return 2
+	}
+	return 0
+}
+
+func otlpAttrs(kvs []attribute.KeyValue) []otlpKeyValue {
+	out := make([]otlpKeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		out = append(out, otlpAttr(kv))
+	}
+	return out
+}
+
+func otlpAttr(kv attribute.KeyValue) otlpKeyValue {
+	return otlpKeyValue{Key: string(kv.Key), Value: otlpVal(kv.Value)}
+}
+
+func otlpVal(v attribute.Value) otlpValue {
+	switch v.Type() {
+	case attribute.BOOL:
+		b := v.AsBool()
+		return otlpValue{BoolValue: &b}
+	case attribute.INT64:
+		i := strconv.FormatInt(v.AsInt64(), 10)
+		return otlpValue{IntValue: &i}
+	case attribute.FLOAT64:
+		f := v.AsFloat64()
+		return otlpValue{DoubleValue: &f}
+	case attribute.STRINGSLICE:
+		arr := &otlpArrayValue{}
+		for _, s := range v.AsStringSlice() {
+			arr.Values = append(arr.Values, otlpVal(attribute.StringValue(s)))
+		}
+		return otlpValue{ArrayValue: arr}
+	case attribute.INT64SLICE:
+		arr := &otlpArrayValue{}
+		for _, i := range v.AsInt64Slice() {
+			arr.Values = append(arr.Values, otlpVal(attribute.Int64Value(i)))
+		}
+		return otlpValue{ArrayValue: arr}
+	}
+	s := v.Emit()
+	return otlpValue{StringValue: &s}
+}
@@ -0,0 +1,221 @@
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/mr-karan/logchef/internal/config"
+)
+
+// Provider is a trace.TracerProvider that samples new traces by trace ID
+// ratio, follows the parent's decision otherwise, and hands ended spans to an
+// OTLP exporter. It covers what logchef's instrumentation uses; links are not
+// recorded.
+type Provider struct {
+	embedded.TracerProvider
+
+	exporter *exporter
+	// threshold is SampleRatio scaled to the 63-bit range compared against
+	// the trace ID, as the SDK's TraceIDRatioBased sampler does.
+	threshold uint64
+}
+
+// NewProvider creates a provider exporting to cfg.Endpoint.
+func NewProvider(cfg config.TracingConfig, log *slog.Logger) *Provider {
+	return &Provider{
+		exporter:  newExporter(cfg, log),
+		threshold: uint64(cfg.SampleRatio * (1 << 63)),
+	}
+}
+
+// Tracer returns a tracer whose spans report name as their scope.
+func (p *Provider) Tracer(name string, _ ...trace.TracerOption) trace.Tracer {
+	return &spanTracer{provider: p, scope: name}
+}
+
+// Shutdown flushes queued spans and stops the exporter.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	return p.exporter.shutdown(ctx)
+}
+
+func (p *Provider) sampled(id trace.TraceID) bool {
+	return binary.BigEndian.Uint64(id[8:16])>>1 < p.threshold
+}
+
+type spanTracer struct {
+	embedded.Tracer
+
+	provider *Provider
+	scope    string
+}
+
+func (t *spanTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+
+	parent := trace.SpanContextFromContext(ctx)
+	if cfg.NewRoot() {
+		parent = trace.SpanContext{}
+	}
+
+	sc := trace.SpanContextConfig{SpanID: newSpanID()}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID()
+		sc.TraceFlags = parent.TraceFlags()
+		sc.TraceState = parent.TraceState()
+	} else {
+		sc.TraceID = newTraceID()
+		if t.provider.sampled(sc.TraceID) {
+			sc.TraceFlags = trace.FlagsSampled
+		}
+	}
+	if !sc.TraceFlags.IsSampled() {
+		// Unsampled spans still carry the trace ID so propagation works.
+		return noop.NewTracerProvider().Tracer("").Start(trace.ContextWithSpanContext(ctx, trace.NewSpanContext(sc)), name)
+	}
+
+	start := cfg.Timestamp()
+	if start.IsZero() {
+		start = time.Now()
+	}
+	s := &span{
+		tracer: t,
+		name:   name,
+		sc:     trace.NewSpanContext(sc),
+		parent: parent,
+		kind:   cfg.SpanKind(),
+		start:  start,
+		attrs:  cfg.Attributes(),
+	}
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+type event struct {
+	name  string
+	time  time.Time
+	attrs []attribute.KeyValue
+}
+
+type span struct {
+	embedded.Span
+
+	tracer *spanTracer
+	sc     trace.SpanContext
+	parent trace.SpanContext
+	kind   trace.SpanKind
+	start  time.Time
+
+	mu         sync.Mutex
+	name       string
+	end        time.Time
+	attrs      []attribute.KeyValue
+	events     []event
+	status     codes.Code
+	statusDesc string
+	ended      bool
+}
+
+func (s *span) End(opts ...trace.SpanEndOption) {
+	cfg := trace.NewSpanEndConfig(opts...)
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = cfg.Timestamp()
+	if s.end.IsZero() {
+		s.end = time.Now()
+	}
+	s.mu.Unlock()
+	s.tracer.provider.exporter.enqueue(s)
+}
+
+func (s *span) AddEvent(name string, opts ...trace.EventOption) {
+	cfg := trace.NewEventConfig(opts...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.events = append(s.events, event{name: name, time: cfg.Timestamp(), attrs: cfg.Attributes()})
+	}
+}
+
+func (s *span) AddLink(trace.Link) {}
+
+func (s *span) IsRecording() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.ended
+}
+
+// RecordError adds an exception event. Like the SDK it leaves the status
+// alone; callers set codes.Error when the error fails the operation.
+func (s *span) RecordError(err error, opts ...trace.EventOption) {
+	if err == nil {
+		return
+	}
+	opts = append(opts, trace.WithAttributes(
+		attribute.String("exception.type", fmt.Sprintf("%T", err)),
+		attribute.String("exception.message", err.Error()),
+	))
+	s.AddEvent("exception", opts...)
+}
+
+func (s *span) SpanContext() trace.SpanContext { return s.sc }
+
+func (s *span) SetStatus(code codes.Code, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// OK is final, and a description only accompanies Error.
+	if s.ended || s.status == codes.Ok || code < s.status {
+		return
+	}
+	s.status = code
+	s.statusDesc = ""
+	if code == codes.Error {
+		s.statusDesc = description
+	}
+}
+
+func (s *span) SetName(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.name = name
+	}
+}
+
+func (s *span) SetAttributes(kv ...attribute.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.attrs = append(s.attrs, kv...)
+	}
+}
+
+func (s *span) TracerProvider() trace.TracerProvider { return s.tracer.provider }
+
+func newTraceID() trace.TraceID {
+	var id trace.TraceID
+	binary.BigEndian.PutUint64(id[:8], rand.Uint64())
+	binary.BigEndian.PutUint64(id[8:], rand.Uint64())
+	return id
+}
+
+func newSpanID() trace.SpanID {
+	var id trace.SpanID
+	for id == (trace.SpanID{}) {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return id
+}
@@ -0,0 +1,83 @@
+// Package tracing traces the query pipeline (HTTP request, LogchefQL
+// translation, SQL parsing, ClickHouse execution and row scanning) with
+// OpenTelemetry spans.
+//
+// Instrumented code uses the standard go.opentelemetry.io/otel/trace API
+// through Start. The provider installed by Setup records sampled spans and
+// exports them to an OTLP/HTTP collector as JSON; until then spans are no-ops.
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/mr-karan/logchef/internal/config"
+)
+
+// instrumentationName names the tracer all logchef spans come from.
+const instrumentationName = "github.com/mr-karan/logchef"
+
+var tracer atomic.Value // of trace.Tracer
+
+func init() {
+	tracer.Store(trace.Tracer(noop.NewTracerProvider().Tracer(instrumentationName)))
+}
+
+// Setup installs the exporting provider when tracing is enabled and returns
+// a function that flushes pending spans on shutdown.
+func Setup(cfg config.TracingConfig, log *slog.Logger) (shutdown func(context.Context) error) {
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }
+	}
+	p := NewProvider(cfg, log)
+	tracer.Store(p.Tracer(instrumentationName))
+	log.Info("tracing enabled", "endpoint", cfg.Endpoint, "sample_ratio", cfg.SampleRatio)
+	return p.Shutdown
+}
+
+// Enabled reports whether an exporting provider is installed.
+func Enabled() bool {
+	_, ok := tracer.Load().(*spanTracer)
+	return ok
+}
+
+// Start starts a span as a child of the span in ctx. The returned context
+// carries the new span; callers must End it.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if parent, ok := ctx.Value(requestSpanKey{}).(trace.Span); ok && !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = trace.ContextWithSpan(ctx, parent)
+	}
+	return tracer.Load().(trace.Tracer).Start(ctx, name, opts...)
+}
+
+// End ends span, marking it failed when err is non-nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// SpanContextFromContext returns the current span's context, including the
+// request span stored by Middleware on a Fiber request context.
+func SpanContextFromContext(ctx context.Context) trace.SpanContext {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc
+	}
+	if span, ok := ctx.Value(requestSpanKey{}).(trace.Span); ok {
+		return span.SpanContext()
+	}
+	return trace.SpanContext{}
+}
+
+// requestSpanKey stores the request span as a fasthttp user value. Handlers
+// pass the *fasthttp.RequestCtx on as their context.Context, and it only
+// resolves Value lookups through user values, so trace.ContextWithSpan on the
+// user context would not reach them.
+type requestSpanKey struct{}
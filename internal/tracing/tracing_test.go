@@ -0,0 +1,130 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mr-karan/logchef/internal/config"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	const h = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, ok := ParseTraceparent(h)
+	if !ok {
+		t.Fatalf("ParseTraceparent(%q) failed", h)
+	}
+	if !sc.IsSampled() || !sc.IsRemote() {
+		t.Fatalf("span context = %+v, want sampled remote", sc)
+	}
+	if got := FormatTraceparent(sc); got != h {
+		t.Fatalf("FormatTraceparent = %q, want %q", got, h)
+	}
+
+	for _, bad := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, ok := ParseTraceparent(bad); ok {
+			t.Errorf("ParseTraceparent(%q) accepted an invalid header", bad)
+		}
+	}
+}
+
+func TestProviderSampling(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tc := range []struct {
+		ratio float64
+		want  bool
+	}{{0, false}, {1, true}} {
+		p := NewProvider(config.TracingConfig{Endpoint: "http://127.0.0.1:0", SampleRatio: tc.ratio}, log)
+		tr := p.Tracer("test")
+		for range 50 {
+			_, span := tr.Start(context.Background(), "root")
+			if got := span.SpanContext().IsSampled(); got != tc.want {
+				t.Fatalf("ratio %v: sampled = %v, want %v", tc.ratio, got, tc.want)
+			}
+			if !span.SpanContext().IsValid() {
+				t.Fatalf("ratio %v: span context is invalid", tc.ratio)
+			}
+		}
+		_ = p.Shutdown(context.Background())
+	}
+
+	// A sampled parent is followed even when the ratio would drop the trace.
+	p := NewProvider(config.TracingConfig{Endpoint: "http://127.0.0.1:0", SampleRatio: 0}, log)
+	defer p.Shutdown(context.Background()) //nolint:errcheck // test cleanup
+	parent, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := p.Tracer("test").Start(trace.ContextWithRemoteSpanContext(context.Background(), parent), "child")
+	if !span.IsRecording() || span.SpanContext().TraceID() != parent.TraceID() {
+		t.Fatalf("child of sampled parent: recording = %v, trace = %s", span.IsRecording(), span.SpanContext().TraceID())
+	}
+}
+
+func TestExporterPostsOTLPJSON(t *testing.T) {
+	got := make(chan otlpTraces, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("request %s with auth %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var body otlpTraces
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		got <- body
+	}))
+	defer srv.Close()
+
+	p := NewProvider(config.TracingConfig{
+		Endpoint:    srv.URL,
+		Headers:     map[string]string{"Authorization": "Bearer secret"},
+		ServiceName: "logchef-test",
+		SampleRatio: 1,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, root := p.Tracer("test").Start(context.Background(), "GET /api/v1/logs", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := p.Tracer("test").Start(ctx, "clickhouse.execute", trace.WithAttributes(attribute.Int("db.query.length", 42)))
+	child.End()
+	root.End()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	body := <-got
+	if len(body.ResourceSpans) != 1 || len(body.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("body = %+v, want one resource and scope", body)
+	}
+	if v := body.ResourceSpans[0].Resource.Attributes[0].Value.StringValue; v == nil || *v != "logchef-test" {
+		t.Fatalf("service.name = %v, want logchef-test", v)
+	}
+	spans := body.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	exec, server := spans[0], spans[1]
+	if exec.TraceID != server.TraceID || exec.ParentSpanID != server.SpanID {
+		t.Fatalf("execute span %+v is not a child of %+v", exec, server)
+	}
+	if server.Kind != 2 || exec.Kind != 1 {
+		t.Fatalf("kinds = %d/%d, want server(2)/internal(1)", server.Kind, exec.Kind)
+	}
+	if len(exec.Attributes) != 1 || exec.Attributes[0].Value.IntValue == nil || *exec.Attributes[0].Value.IntValue != "42" {
+		t.Fatalf("execute attributes = %+v", exec.Attributes)
+	}
+}
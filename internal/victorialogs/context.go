@@ -0,0 +1,151 @@
+package victorialogs
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+const (
+	defaultContextLimit = 10
+	maxContextLimit     = 100
+	// contextLookback bounds how far either side of the target each context
+	// query scans. Without it a sparse stream would make VL walk every
+	// partition looking for N neighbours.
+	contextLookback = 24 * time.Hour
+)
+
+// GetLogContext fetches logs surrounding a target timestamp with two LogsQL
+// queries over `_time` ranges: one ending at the target (newest first, so the
+// closest rows win the limit) and one starting just after it. The target is a
+// millisecond timestamp while VL stores nanoseconds, so "at the target" means
+// anywhere within that millisecond, mirroring ClickHouse's DateTime64(3)
+// comparison. When req.Stream carries the target row's `_stream`, both queries
+// are narrowed to that stream so the context comes from the same emitter;
+// req.Scope narrows them further to exact field values.
+//
+// As with the ClickHouse provider, rows at the target timestamp are returned at
+// the end of BeforeLogs (unless ExcludeBoundary is set) and TargetLogs is empty.
+func (p *Provider) GetLogContext(ctx context.Context, source *models.Source, req datasource.LogContextRequest) (*models.LogContextResponse, error) {
+	conn, err := p.connectionForSource(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	streamFilter, err := contextStreamFilter(req.Stream)
+	if err != nil {
+		return nil, err
+	}
+	streamFilter += contextScopeFilter(req.Scope)
+
+	beforeLimit := contextLimit(req.BeforeLimit)
+	afterLimit := contextLimit(req.AfterLimit)
+	if req.QueryTimeout == nil {
+		defaultTimeout := models.DefaultQueryTimeoutSeconds
+		req.QueryTimeout = &defaultTimeout
+	}
+
+	target := time.UnixMilli(req.TargetTimestamp).UTC()
+	boundary := target.Add(time.Millisecond)
+	if req.ExcludeBoundary {
+		boundary = target
+	}
+
+	beforeQuery := fmt.Sprintf("_time:[%s, %s) %s| sort by (_time desc) | offset %d | limit %d",
+		formatContextTime(target.Add(-contextLookback)), formatContextTime(boundary), streamFilter, req.BeforeOffset, beforeLimit)
+	beforeLogs, beforeStats, err := p.queryContextRows(ctx, conn, beforeQuery, req.QueryTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query logs before target time: %w", err)
+	}
+	// Reverse so logs read oldest first, ending at the target.
+	for i, j := 0, len(beforeLogs)-1; i < j; i, j = i+1, j-1 {
+		beforeLogs[i], beforeLogs[j] = beforeLogs[j], beforeLogs[i]
+	}
+
+	after := target.Add(time.Millisecond)
+	afterQuery := fmt.Sprintf("_time:[%s, %s) %s| sort by (_time) | offset %d | limit %d",
+		formatContextTime(after), formatContextTime(after.Add(contextLookback)), streamFilter, req.AfterOffset, afterLimit)
+	afterLogs, afterStats, err := p.queryContextRows(ctx, conn, afterQuery, req.QueryTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query logs after target time: %w", err)
+	}
+
+	return &models.LogContextResponse{
+		TargetTimestamp: req.TargetTimestamp,
+		BeforeLogs:      beforeLogs,
+		TargetLogs:      []map[string]any{},
+		AfterLogs:       afterLogs,
+		Stats: models.QueryStats{
+			RowsRead:        len(beforeLogs) + len(afterLogs),
+			RowsReturned:    len(beforeLogs) + len(afterLogs),
+			ExecutionTimeMs: beforeStats.ExecutionTimeMs + afterStats.ExecutionTimeMs,
+		},
+	}, nil
+}
+
+// queryContextRows runs one context query. The query carries its own limit, so
+// no response byte budget is applied.
+func (p *Provider) queryContextRows(ctx context.Context, conn models.VictoriaLogsConnectionInfo, query string, timeout *int) ([]map[string]any, models.QueryStats, error) {
+	form := url.Values{}
+	form.Set("query", query)
+	if t := formatTimeout(timeout); t != "" {
+		form.Set("timeout", t)
+	}
+	applyScopeFilters(form, conn)
+
+	resp, err := p.doFormRequest(ctx, conn, "/select/logsql/query", form)
+	if err != nil {
+		return nil, models.QueryStats{}, err
+	}
+	defer resp.Body.Close()
+
+	logs, _, _, _, err := readQueryRows(resp.Body, 0, 0)
+	if err != nil {
+		return nil, models.QueryStats{}, err
+	}
+	return logs, statsFromHeaders(resp, len(logs)), nil
+}
+
+// contextStreamFilter turns the target row's `_stream` value (e.g.
+// `{app="api",host="a"}`) into a LogsQL stream filter followed by a space, or
+// "" when no stream was given.
+func contextStreamFilter(stream string) (string, error) {
+	stream = strings.TrimSpace(stream)
+	if stream == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(stream, "{") || !strings.HasSuffix(stream, "}") || strings.Count(stream, "{") != 1 || strings.Count(stream, "}") != 1 {
+		return "", &datasource.ValidationError{Field: "stream", Message: "stream must be a single {label=\"value\",...} stream selector"}
+	}
+	return "_stream:" + stream + " ", nil
+}
+
+// contextScopeFilter turns a log context scope into exact-match LogsQL field
+// filters, each followed by a space, or "" for an empty scope.
+func contextScopeFilter(scope models.LogContextScope) string {
+	var b strings.Builder
+	for _, field := range scope.Fields() {
+		fmt.Fprintf(&b, "%s:=%s ", strconv.Quote(field), strconv.Quote(scope[field]))
+	}
+	return b.String()
+}
+
+// contextLimit applies the handler's 10 default and 100 cap.
+func contextLimit(limit int) int {
+	if limit <= 0 {
+		return defaultContextLimit
+	}
+	return min(limit, maxContextLimit)
+}
+
+// formatContextTime renders ts for a LogsQL `_time` range. Millisecond
+// precision is enough: context boundaries are always whole milliseconds.
+func formatContextTime(ts time.Time) string {
+	return ts.UTC().Format("2006-01-02T15:04:05.000Z07:00")
+}
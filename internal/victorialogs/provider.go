@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/secrets"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
@@ -73,6 +74,9 @@ type Provider struct {
 	// can interleave and leave stale state (e.g. health cached for a source that
 	// a concurrent remove already deleted).
 	opLocks keyedMutex
+	// secrets resolves secret references in base_url and credentials; the
+	// sources map holds connections with them resolved.
+	secrets *secrets.Resolver
 }
 
 // keyedMutex hands out one mutex per key, so callers can serialise operations
@@ -123,6 +127,12 @@ func NewProvider(log *slog.Logger) *Provider {
 	}
 }
 
+// SetSecretResolver sets the resolver for secret references in source
+// connections. Call it before any source is initialized.
+func (p *Provider) SetSecretResolver(resolver *secrets.Resolver) {
+	p.secrets = resolver
+}
+
 func (p *Provider) Type() models.SourceType {
 	return models.SourceTypeVictoriaLogs
 }
@@ -135,6 +145,7 @@ func (p *Provider) Capabilities() []datasource.Capability {
 		datasource.CapabilitySourceInspection,
 		datasource.CapabilityLiveTail,
 		datasource.CapabilityAISQLGeneration,
+		datasource.CapabilityLogContext,
 	}
 }
 
@@ -299,12 +310,21 @@ func (p *Provider) InitializeSource(ctx context.Context, source *models.Source)
 	if err != nil {
 		return err
 	}
+	if conn, err = p.resolveConnection(ctx, conn); err != nil {
+		p.updateHealth(source.ID, false, err)
+		return err
+	}
 
 	p.mu.Lock()
 	p.sources[source.ID] = conn
 	p.mu.Unlock()
 
 	healthy, healthErr := p.checkHealth(ctx, source.ID, conn)
+	if !healthy {
+		if fresh, ok := p.refreshSecrets(ctx, source, conn); ok {
+			healthy, healthErr = p.checkHealth(ctx, source.ID, fresh)
+		}
+	}
 	p.updateHealth(source.ID, healthy, healthErr)
 	if healthErr != nil {
 		return healthErr
@@ -331,13 +351,18 @@ func (p *Provider) CheckSourceConnectionStatus(ctx context.Context, source *mode
 
 	defer p.opLocks.lock(source.ID)()
 
-	conn, err := p.connectionForSource(source)
+	conn, err := p.connectionForSource(ctx, source)
 	if err != nil {
 		p.updateHealth(source.ID, false, err)
 		return false
 	}
 
 	healthy, healthErr := p.checkHealth(ctx, source.ID, conn)
+	if !healthy {
+		if fresh, ok := p.refreshSecrets(ctx, source, conn); ok {
+			healthy, healthErr = p.checkHealth(ctx, source.ID, fresh)
+		}
+	}
 	p.updateHealth(source.ID, healthy, healthErr)
 	return healthy
 }
@@ -389,7 +414,7 @@ func (p *Provider) connectionFromConfig(raw json.RawMessage) (models.VictoriaLog
 	return conn, nil
 }
 
-func (p *Provider) connectionForSource(source *models.Source) (models.VictoriaLogsConnectionInfo, error) {
+func (p *Provider) connectionForSource(ctx context.Context, source *models.Source) (models.VictoriaLogsConnectionInfo, error) {
 	p.mu.RLock()
 	conn, ok := p.sources[source.ID]
 	p.mu.RUnlock()
@@ -401,6 +426,9 @@ func (p *Provider) connectionForSource(source *models.Source) (models.VictoriaLo
 	if err != nil {
 		return models.VictoriaLogsConnectionInfo{}, err
 	}
+	if conn, err = p.resolveConnection(ctx, conn); err != nil {
+		return models.VictoriaLogsConnectionInfo{}, err
+	}
 
 	p.mu.Lock()
 	p.sources[source.ID] = conn
@@ -408,6 +436,47 @@ func (p *Provider) connectionForSource(source *models.Source) (models.VictoriaLo
 	return conn, nil
 }
 
+// resolveConnection returns conn with the secret references in its base_url
+// and credentials resolved.
+func (p *Provider) resolveConnection(ctx context.Context, conn models.VictoriaLogsConnectionInfo) (models.VictoriaLogsConnectionInfo, error) {
+	if err := p.secrets.ResolveFields(ctx, &conn.BaseURL, &conn.Auth.Password, &conn.Auth.Token); err != nil {
+		return conn, fmt.Errorf("resolving connection secrets: %w", err)
+	}
+	return conn, nil
+}
+
+// refreshSecrets resolves the source's secret references again, bypassing
+// the cache, after a failed check. When that changes any of them it caches and
+// returns the new connection, which is worth checking again.
+func (p *Provider) refreshSecrets(ctx context.Context, source *models.Source, current models.VictoriaLogsConnectionInfo) (models.VictoriaLogsConnectionInfo, bool) {
+	conn, err := source.VictoriaLogsConnection()
+	if err != nil {
+		return current, false
+	}
+	var refs []string
+	for _, value := range []string{conn.BaseURL, conn.Auth.Password, conn.Auth.Token} {
+		if secrets.IsRef(value) {
+			refs = append(refs, value)
+		}
+	}
+	if len(refs) == 0 {
+		return current, false
+	}
+
+	p.secrets.Forget(refs...)
+	if conn, err = p.resolveConnection(ctx, conn); err != nil {
+		p.log.Warn("failed to re-resolve connection secrets", "source_id", source.ID, "error", err)
+		return current, false
+	}
+	if conn.BaseURL == current.BaseURL && conn.Auth.Password == current.Auth.Password && conn.Auth.Token == current.Auth.Token {
+		return current, false
+	}
+	p.mu.Lock()
+	p.sources[source.ID] = conn
+	p.mu.Unlock()
+	return conn, true
+}
+
 func (p *Provider) checkHealth(ctx context.Context, sourceID models.SourceID, conn models.VictoriaLogsConnectionInfo) (bool, error) {
 	if strings.TrimSpace(conn.BaseURL) == "" {
 		return false, fmt.Errorf("victorialogs base_url is required")
@@ -558,6 +627,10 @@ func validateVictoriaLogsConnectionConfig(fieldPrefix string, conn models.Victor
 }
 
 func (p *Provider) validateConnectionAccess(ctx context.Context, conn models.VictoriaLogsConnectionInfo) error {
+	conn, err := p.resolveConnection(ctx, conn)
+	if err != nil {
+		return &datasource.ValidationError{Field: "connection", Message: "Failed to resolve connection secrets", Err: err}
+	}
 	if _, err := p.checkHealth(ctx, 0, conn); err != nil {
 		return &datasource.ValidationError{Field: "connection.base_url", Message: "Failed to reach the VictoriaLogs server", Err: err}
 	}
@@ -16,7 +16,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mr-karan/logchef/internal/config"
 	"github.com/mr-karan/logchef/internal/datasource"
+	"github.com/mr-karan/logchef/internal/secrets"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
@@ -250,6 +252,70 @@ func TestQueryLogsAppliesHeadersScopeAndLimit(t *testing.T) {
 	}
 }
 
+func TestGetLogContextQueriesTimeRangesWithinStream(t *testing.T) {
+	t.Parallel()
+
+	target := time.Date(2026, 4, 8, 10, 0, 0, 0, time.UTC)
+	var queries []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/select/logsql/query" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("parse form: %v", err)
+		}
+		query := r.Form.Get("query")
+		queries = append(queries, query)
+		if strings.Contains(query, "_time desc") {
+			_, _ = w.Write([]byte(
+				`{"_time":"2026-04-08T10:00:00.000300Z","_msg":"target"}` + "\n" +
+					`{"_time":"2026-04-08T09:59:59Z","_msg":"before"}` + "\n",
+			))
+			return
+		}
+		_, _ = w.Write([]byte(`{"_time":"2026-04-08T10:00:01Z","_msg":"after"}` + "\n"))
+	}))
+	defer server.Close()
+
+	provider := newTestProvider(server)
+	source := mustSource(t, models.VictoriaLogsConnectionInfo{BaseURL: server.URL})
+
+	result, err := provider.GetLogContext(context.Background(), source, datasource.LogContextRequest{
+		TargetTimestamp: target.UnixMilli(),
+		BeforeLimit:     5,
+		AfterLimit:      500,
+		AfterOffset:     3,
+		Stream:          `{app="api"}`,
+		Scope:           models.LogContextScope{"service_name": "api", "pod": "api-7f9c"},
+	})
+	if err != nil {
+		t.Fatalf("GetLogContext returned error: %v", err)
+	}
+
+	wantQueries := []string{
+		`_time:[2026-04-07T10:00:00.000Z, 2026-04-08T10:00:00.001Z) _stream:{app="api"} "pod":="api-7f9c" "service_name":="api" | sort by (_time desc) | offset 0 | limit 5`,
+		`_time:[2026-04-08T10:00:00.001Z, 2026-04-09T10:00:00.001Z) _stream:{app="api"} "pod":="api-7f9c" "service_name":="api" | sort by (_time) | offset 3 | limit 100`,
+	}
+	if !reflect.DeepEqual(queries, wantQueries) {
+		t.Fatalf("unexpected queries:\n%s", strings.Join(queries, "\n"))
+	}
+	if len(result.BeforeLogs) != 2 || result.BeforeLogs[0]["_msg"] != "before" || result.BeforeLogs[1]["_msg"] != "target" {
+		t.Fatalf("before logs not oldest-first: %#v", result.BeforeLogs)
+	}
+	if len(result.AfterLogs) != 1 || len(result.TargetLogs) != 0 || result.Stats.RowsReturned != 3 {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+
+	_, err = provider.GetLogContext(context.Background(), source, datasource.LogContextRequest{
+		TargetTimestamp: target.UnixMilli(),
+		Stream:          `{app="api"} or {app="billing"}`,
+	})
+	if !datasource.IsValidationError(err) {
+		t.Fatalf("expected validation error for a compound stream, got %v", err)
+	}
+}
+
 func TestSchemaAndFieldValueDiscovery(t *testing.T) {
 	t.Parallel()
 
@@ -858,3 +924,37 @@ func TestOrderFieldNamesPrioritizesMetadata(t *testing.T) {
 		t.Fatalf("unexpected ordered fields: got=%v want=%v", got, want)
 	}
 }
+
+func TestSecretRefsReResolvedOnFailedHealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer rotated" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Setenv("VL_TEST_TOKEN", "original")
+	provider := newTestProvider(server)
+	provider.SetSecretResolver(secrets.NewResolver(config.SecretsConfig{CacheTTL: time.Hour}))
+	source := mustSource(t, models.VictoriaLogsConnectionInfo{
+		BaseURL: server.URL,
+		Auth:    models.VictoriaLogsAuth{Mode: "bearer", Token: "env:VL_TEST_TOKEN"},
+	})
+
+	if err := provider.InitializeSource(context.Background(), source); err == nil {
+		t.Fatal("InitializeSource succeeded with the original token")
+	}
+	t.Setenv("VL_TEST_TOKEN", "rotated")
+	if !provider.CheckSourceConnectionStatus(context.Background(), source) {
+		t.Fatal("health check failed after the token rotated; the cached token was not re-resolved")
+	}
+	conn, err := provider.connectionForSource(context.Background(), source)
+	if err != nil || conn.Auth.Token != "rotated" {
+		t.Fatalf("cached connection token = %q, %v; want the rotated token", conn.Auth.Token, err)
+	}
+	if stored, _ := source.VictoriaLogsConnection(); stored.Auth.Token != "env:VL_TEST_TOKEN" {
+		t.Errorf("source token = %q, want the reference kept", stored.Auth.Token)
+	}
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/mr-karan/logchef/internal/datasource"
 	"github.com/mr-karan/logchef/internal/logchefql"
+	"github.com/mr-karan/logchef/internal/timezone"
 	"github.com/mr-karan/logchef/pkg/models"
 )
 
@@ -92,7 +93,7 @@ func (p *Provider) PopulateSourceDetails(ctx context.Context, source *models.Sou
 }
 
 func (p *Provider) QueryLogs(ctx context.Context, source *models.Source, req datasource.QueryRequest) (*models.QueryResult, error) {
-	conn, err := p.connectionForSource(source)
+	conn, err := p.connectionForSource(ctx, source)
 	if err != nil {
 		return nil, err
 	}
@@ -289,8 +290,37 @@ func (p *Provider) CompileLogchefQL(_ context.Context, source *models.Source, re
 	return compiled, nil
 }
 
+// BuildConditionAlertQuery compiles a structured alert condition into a
+// LogsQL stats query. The lookback window is applied by EvaluateAlert.
+func (p *Provider) BuildConditionAlertQuery(_ context.Context, source *models.Source, req datasource.ConditionAlertRequest) (string, error) {
+	cond := req.Condition
+	if cond == nil {
+		return "", fmt.Errorf("alert condition is required")
+	}
+	if cond.Baseline != "" {
+		return "", fmt.Errorf("baseline comparisons are not supported for VictoriaLogs sources")
+	}
+	result := translateLogchefQLToLogsQL(cond.Filter, source)
+	if !result.Valid {
+		if result.Error != nil {
+			return "", result.Error
+		}
+		return "", fmt.Errorf("invalid LogchefQL query")
+	}
+
+	filter := strings.TrimSpace(result.Query)
+	if filter == "" {
+		filter = "*"
+	}
+	stats := "count()"
+	if cond.Aggregate != models.AlertAggregateCount {
+		stats = fmt.Sprintf("%s(%s)", cond.Aggregate, cond.Field)
+	}
+	return fmt.Sprintf("%s | stats %s as value", filter, stats), nil
+}
+
 func (p *Provider) GetSourceSchema(ctx context.Context, source *models.Source) ([]models.ColumnInfo, error) {
-	conn, err := p.connectionForSource(source)
+	conn, err := p.connectionForSource(ctx, source)
 	if err != nil {
 		return nil, err
 	}
@@ -329,7 +359,7 @@ func (p *Provider) GetSourceSchema(ctx context.Context, source *models.Source) (
 }
 
 func (p *Provider) Histogram(ctx context.Context, source *models.Source, req datasource.HistogramRequest) (*datasource.HistogramResult, error) {
-	conn, err := p.connectionForSource(source)
+	conn, err := p.connectionForSource(ctx, source)
 	if err != nil {
 		return nil, err
 	}
@@ -434,7 +464,7 @@ func (p *Provider) Histogram(ctx context.Context, source *models.Source, req dat
 }
 
 func (p *Provider) GetFieldValues(ctx context.Context, source *models.Source, req datasource.FieldValuesRequest) (*datasource.FieldValuesResult, error) {
-	conn, err := p.connectionForSource(source)
+	conn, err := p.connectionForSource(ctx, source)
 	if err != nil {
 		return nil, err
 	}
@@ -486,7 +516,7 @@ func (p *Provider) GetFieldValues(ctx context.Context, source *models.Source, re
 }
 
 func (p *Provider) GetAllFieldValues(ctx context.Context, source *models.Source, req datasource.AllFieldValuesRequest) (datasource.AllFieldValuesResult, error) {
-	conn, err := p.connectionForSource(source)
+	conn, err := p.connectionForSource(ctx, source)
 	if err != nil {
 		return nil, err
 	}
@@ -551,7 +581,7 @@ func (p *Provider) InspectSource(ctx context.Context, source *models.Source) (*d
 }
 
 func (p *Provider) InspectSourceActivity(ctx context.Context, source *models.Source) (*datasource.SourceActivity, error) {
-	conn, err := p.connectionForSource(source)
+	conn, err := p.connectionForSource(ctx, source)
 	if err != nil {
 		return nil, err
 	}
@@ -635,7 +665,7 @@ func sumBucketsSince(buckets []datasource.IngestionBucket, since time.Time) uint
 }
 
 func (p *Provider) EvaluateAlert(ctx context.Context, source *models.Source, req datasource.AlertQueryRequest) (*models.QueryResult, error) {
-	conn, err := p.connectionForSource(source)
+	conn, err := p.connectionForSource(ctx, source)
 	if err != nil {
 		return nil, err
 	}
@@ -1120,13 +1150,13 @@ func formatAPITime(ts time.Time) string {
 // (00:00 IST == 18:30 UTC), and offset=-9000s (UTC-2:30, America/St_Johns)
 // aligns buckets to NDT midnight (00:00 NDT == 02:30 UTC) — i.e. the offset
 // value is simply the zone's UTC offset in seconds.
-func formatTimezoneOffset(timezone string, start, end *time.Time) string {
-	locationName := strings.TrimSpace(timezone)
+func formatTimezoneOffset(tz string, start, end *time.Time) string {
+	locationName := strings.TrimSpace(tz)
 	if locationName == "" || strings.EqualFold(locationName, "UTC") {
 		return ""
 	}
 
-	loc, err := time.LoadLocation(locationName)
+	loc, err := timezone.Load(locationName)
 	if err != nil {
 		return ""
 	}
@@ -40,7 +40,7 @@ var ErrTailUpstreamClosed = errors.New("victorialogs tail: upstream closed the c
 // the main loop exits some other way (an emit error), and rows already
 // buffered when ctx is cancelled are flushed rather than dropped.
 func (p *Provider) TailLogs(ctx context.Context, source *models.Source, req datasource.TailRequest, emit datasource.TailEmitter) error {
-	conn, err := p.connectionForSource(source)
+	conn, err := p.connectionForSource(ctx, source)
 	if err != nil {
 		return err
 	}
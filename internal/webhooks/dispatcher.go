@@ -0,0 +1,277 @@
+// Package webhooks delivers lifecycle events to the outbound webhooks admins
+// register. The dispatcher subscribes to the event bus and queues every event;
+// it matches each against the active webhooks and hands a delivery for every
+// one subscribed to its type to a fixed pool of workers, which post it and
+// retry failures with exponential backoff.
+//
+// Every request is signed so receivers can check it came from Logchef:
+//
+//	X-Logchef-Timestamp: <unix seconds the attempt was signed at>
+//	X-Logchef-Signature: sha256=<hex HMAC-SHA256 of "<timestamp>.<body>">
+//
+// keyed by the webhook's secret. X-Logchef-Event carries the event type and
+// X-Logchef-Delivery the event ID, which stays the same across retries.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/events"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// Request headers set on every delivery.
+const (
+	HeaderEvent     = "X-Logchef-Event"
+	HeaderDelivery  = "X-Logchef-Delivery"
+	HeaderTimestamp = "X-Logchef-Timestamp"
+	HeaderSignature = "X-Logchef-Signature"
+)
+
+const (
+	initialBackoff = 2 * time.Second
+	maxBackoff     = 2 * time.Minute
+)
+
+// Options encapsulates the dependencies of the webhook dispatcher.
+type Options struct {
+	Config config.WebhooksConfig
+	DB     store.WebhookStore
+	// Bus is where events are read from; nil uses the process-wide bus.
+	Bus    *events.Bus
+	Logger *slog.Logger
+}
+
+// Dispatcher delivers events from the bus to the subscribed webhooks.
+type Dispatcher struct {
+	cfg    config.WebhooksConfig
+	db     store.WebhookStore
+	bus    *events.Bus
+	client *http.Client
+	log    *slog.Logger
+	queue  chan models.Event
+	// jobs hands deliveries to the workers. It is unbuffered, so the queue
+	// backs up while every worker is busy.
+	jobs chan deliveryJob
+
+	// sleep waits between attempts; tests replace it to skip the backoff.
+	sleep func(ctx context.Context, d time.Duration) error
+
+	unsubscribe func()
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher. Call Start to begin delivering.
+func NewDispatcher(opts Options) *Dispatcher {
+	bus := opts.Bus
+	if bus == nil {
+		bus = events.Default()
+	}
+	return &Dispatcher{
+		cfg:    opts.Config,
+		db:     opts.DB,
+		bus:    bus,
+		client: &http.Client{Timeout: opts.Config.Timeout},
+		log:    opts.Logger.With("component", "webhook_dispatcher"),
+		queue:  make(chan models.Event, max(opts.Config.QueueSize, 1)),
+		jobs:   make(chan deliveryJob),
+		sleep:  sleepContext,
+	}
+}
+
+// deliveryJob is one event to post to one webhook.
+type deliveryJob struct {
+	webhook *models.Webhook
+	event   models.Event
+	body    []byte
+}
+
+// Start subscribes to the bus and delivers queued events until Stop. It does
+// nothing when webhooks are disabled.
+func (d *Dispatcher) Start(ctx context.Context) {
+	if !d.cfg.Enabled {
+		d.log.Info("webhook delivery disabled")
+		return
+	}
+	ctx, d.cancel = context.WithCancel(ctx)
+	d.unsubscribe = d.bus.Subscribe(d.enqueue)
+	for range max(d.cfg.Workers, 1) {
+		d.wg.Go(func() {
+			for {
+				select {
+				case job := <-d.jobs:
+					d.deliver(ctx, job.webhook, job.event, job.body)
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+	}
+	d.wg.Go(func() {
+		for {
+			select {
+			case event := <-d.queue:
+				d.dispatch(ctx, event)
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// Stop unsubscribes from the bus and waits for deliveries in flight to give
+// up. Events still queued are dropped.
+func (d *Dispatcher) Stop() {
+	if d.unsubscribe != nil {
+		d.unsubscribe()
+	}
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+}
+
+// enqueue queues an event without blocking the publisher, dropping it when
+// the queue is full. In-process events webhooks can't subscribe to, such as
+// query.executed, are skipped.
+func (d *Dispatcher) enqueue(event models.Event) {
+	if !slices.Contains(models.EventTypes, event.Type) {
+		return
+	}
+	select {
+	case d.queue <- event:
+	default:
+		d.log.Warn("webhook queue full, dropping event", "event", event.Type, "event_id", event.ID)
+	}
+}
+
+// dispatch hands a delivery of event to the workers for every active webhook
+// subscribed to its type, waiting for a free worker each time.
+func (d *Dispatcher) dispatch(ctx context.Context, event models.Event) {
+	webhooks, err := d.db.ListWebhooks(ctx)
+	if err != nil {
+		d.log.Error("failed to list webhooks", "event", event.Type, "event_id", event.ID, "error", err)
+		return
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		d.log.Error("failed to encode event", "event", event.Type, "event_id", event.ID, "error", err)
+		return
+	}
+	for _, webhook := range webhooks {
+		if !webhook.IsActive || !webhook.Subscribes(event.Type) {
+			continue
+		}
+		select {
+		case d.jobs <- deliveryJob{webhook: webhook, event: event, body: body}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deliver posts body to the webhook, retrying with exponential backoff while
+// the failure may be temporary.
+func (d *Dispatcher) deliver(ctx context.Context, webhook *models.Webhook, event models.Event, body []byte) {
+	backoff := initialBackoff
+	attempts := max(d.cfg.MaxAttempts, 1)
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		var retry bool
+		if retry, err = d.send(ctx, webhook, event, body); err == nil {
+			d.log.Debug("webhook delivered", "webhook_id", webhook.ID, "event", event.Type, "event_id", event.ID, "attempt", attempt)
+			return
+		}
+		if !retry || attempt == attempts || d.sleep(ctx, backoff) != nil {
+			break
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+	d.log.Warn("webhook delivery failed",
+		"webhook_id", webhook.ID, "webhook", webhook.Name, "event", event.Type, "event_id", event.ID, "error", err)
+}
+
+// Ping sends the webhook a signed ping event once, without retrying, and
+// reports whether it was accepted.
+func (d *Dispatcher) Ping(ctx context.Context, webhook *models.Webhook) error {
+	event := events.New(models.EventPing, map[string]any{"webhook_id": webhook.ID})
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode ping: %w", err)
+	}
+	_, err = d.send(ctx, webhook, event, body)
+	return err
+}
+
+// send makes one signed delivery attempt. retry reports whether a failure may
+// be temporary: a network error, a timeout, 408, 429 or a 5xx status.
+func (d *Dispatcher) send(ctx context.Context, webhook *models.Webhook, event models.Event, body []byte) (retry bool, err error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "Logchef-Webhook")
+	request.Header.Set(HeaderEvent, string(event.Type))
+	request.Header.Set(HeaderDelivery, event.ID)
+	request.Header.Set(HeaderTimestamp, timestamp)
+	request.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	_ = response.Body.Close()
+	if response.StatusCode >= http.StatusOK && response.StatusCode < http.StatusMultipleChoices {
+		return false, nil
+	}
+	status := strings.TrimSpace(string(responseBody))
+	if status == "" {
+		status = response.Status
+	}
+	retry = response.StatusCode >= http.StatusInternalServerError ||
+		response.StatusCode == http.StatusRequestTimeout ||
+		response.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("status %d (%s)", response.StatusCode, status)
+}
+
+// Sign returns the X-Logchef-Signature value for a body sent at timestamp
+// (unix seconds): "sha256=" and the hex HMAC-SHA256 of "<timestamp>.<body>"
+// keyed by secret.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
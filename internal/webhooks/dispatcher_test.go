@@ -0,0 +1,260 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mr-karan/logchef/internal/config"
+	"github.com/mr-karan/logchef/internal/events"
+	"github.com/mr-karan/logchef/internal/store"
+	"github.com/mr-karan/logchef/pkg/models"
+)
+
+// fakeStore serves a fixed list of webhooks.
+type fakeStore struct {
+	store.WebhookStore
+	webhooks []*models.Webhook
+}
+
+func (f *fakeStore) ListWebhooks(context.Context) ([]*models.Webhook, error) {
+	return f.webhooks, nil
+}
+
+type delivery struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver records deliveries, answering the first failures with 503.
+type receiver struct {
+	mu         sync.Mutex
+	failures   int
+	calls      int
+	deliveries []delivery
+	received   chan struct{}
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	r.calls++
+	fail := r.calls <= r.failures
+	if !fail {
+		r.deliveries = append(r.deliveries, delivery{header: req.Header.Clone(), body: body})
+	}
+	r.mu.Unlock()
+	if fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	r.received <- struct{}{}
+}
+
+func newTestDispatcher(t *testing.T, bus *events.Bus, webhooks ...*models.Webhook) *Dispatcher {
+	t.Helper()
+	d := NewDispatcher(Options{
+		Config: config.WebhooksConfig{Enabled: true, Timeout: 5 * time.Second, MaxAttempts: 3, QueueSize: 10},
+		DB:     &fakeStore{webhooks: webhooks},
+		Bus:    bus,
+		Logger: slog.New(slog.DiscardHandler),
+	})
+	d.sleep = func(context.Context, time.Duration) error { return nil }
+	d.Start(context.Background())
+	t.Cleanup(d.Stop)
+	return d
+}
+
+func waitForDelivery(t *testing.T, r *receiver) {
+	t.Helper()
+	select {
+	case <-r.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for delivery")
+	}
+}
+
+func TestDispatcherSignsAndRetries(t *testing.T) {
+	t.Parallel()
+
+	r := &receiver{failures: 2, received: make(chan struct{}, 10)}
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	bus := events.NewBus()
+	secret := "0123456789abcdef0123"
+	newTestDispatcher(t, bus, &models.Webhook{
+		ID: 1, Name: "ops", URL: server.URL, Secret: secret, IsActive: true,
+		EventTypes: []models.EventType{models.EventSourceCreated},
+	})
+
+	bus.Publish(models.EventSourceCreated, models.SourceEventData{SourceID: 7, Name: "nginx"})
+	waitForDelivery(t, r)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.calls != 3 {
+		t.Errorf("got %d attempts, want 3 (two 503s, then success)", r.calls)
+	}
+	got := r.deliveries[0]
+	if want := Sign(secret, got.header.Get(HeaderTimestamp), got.body); got.header.Get(HeaderSignature) != want {
+		t.Errorf("signature = %q, want %q", got.header.Get(HeaderSignature), want)
+	}
+	if got.header.Get(HeaderEvent) != string(models.EventSourceCreated) {
+		t.Errorf("event header = %q", got.header.Get(HeaderEvent))
+	}
+
+	var event struct {
+		ID   string                 `json:"id"`
+		Type models.EventType       `json:"type"`
+		Data models.SourceEventData `json:"data"`
+	}
+	if err := json.Unmarshal(got.body, &event); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if event.ID == "" || event.ID != got.header.Get(HeaderDelivery) {
+		t.Errorf("event id %q does not match delivery header %q", event.ID, got.header.Get(HeaderDelivery))
+	}
+	if event.Type != models.EventSourceCreated || event.Data.SourceID != 7 || event.Data.Name != "nginx" {
+		t.Errorf("event = %+v", event)
+	}
+}
+
+func TestDispatcherFiltersWebhooks(t *testing.T) {
+	t.Parallel()
+
+	subscribed := &receiver{received: make(chan struct{}, 10)}
+	other := &receiver{received: make(chan struct{}, 10)}
+	subscribedServer := httptest.NewServer(subscribed)
+	defer subscribedServer.Close()
+	otherServer := httptest.NewServer(other)
+	defer otherServer.Close()
+
+	bus := events.NewBus()
+	secret := "0123456789abcdef0123"
+	newTestDispatcher(t, bus,
+		&models.Webhook{ID: 1, URL: subscribedServer.URL, Secret: secret, IsActive: true,
+			EventTypes: []models.EventType{models.EventAll}},
+		&models.Webhook{ID: 2, URL: otherServer.URL, Secret: secret, IsActive: true,
+			EventTypes: []models.EventType{models.EventSourceDeleted}},
+		&models.Webhook{ID: 3, URL: otherServer.URL, Secret: secret, IsActive: false,
+			EventTypes: []models.EventType{models.EventAll}},
+	)
+
+	bus.Publish(models.EventQueryExecuted, models.QueryEventData{SourceID: 1})
+	bus.Publish(models.EventTeamMemberAdded, models.TeamMemberEventData{TeamID: 1, UserID: 2})
+	bus.Publish(models.EventAlertFired, models.AlertEventData{AlertID: 3})
+	waitForDelivery(t, subscribed)
+	waitForDelivery(t, subscribed)
+
+	other.mu.Lock()
+	defer other.mu.Unlock()
+	if other.calls != 0 {
+		t.Errorf("unsubscribed and inactive webhooks got %d deliveries, want 0", other.calls)
+	}
+	subscribed.mu.Lock()
+	defer subscribed.mu.Unlock()
+	if subscribed.calls != 2 {
+		t.Errorf("\"*\" webhook got %d deliveries, want 2 (query.executed is not delivered)", subscribed.calls)
+	}
+}
+
+func TestPingDoesNotRetry(t *testing.T) {
+	t.Parallel()
+
+	r := &receiver{failures: 1, received: make(chan struct{}, 10)}
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	d := newTestDispatcher(t, events.NewBus())
+	err := d.Ping(context.Background(), &models.Webhook{ID: 1, URL: server.URL, Secret: "0123456789abcdef"})
+	if err == nil {
+		t.Fatal("Ping() error = nil, want the 503")
+	}
+	if r.calls != 1 {
+		t.Errorf("got %d attempts, want 1", r.calls)
+	}
+}
+
+// A burst against a stalled endpoint holds at most Workers deliveries open;
+// the rest wait in the queue or are dropped once it is full.
+func TestDispatcherBoundsConcurrentDeliveries(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	inFlight, peak, calls := 0, 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		calls++
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	bus := events.NewBus()
+	d := NewDispatcher(Options{
+		Config: config.WebhooksConfig{Enabled: true, Timeout: 5 * time.Second, MaxAttempts: 1, QueueSize: 2, Workers: 2},
+		DB: &fakeStore{webhooks: []*models.Webhook{{ID: 1, URL: server.URL, Secret: "0123456789abcdef", IsActive: true,
+			EventTypes: []models.EventType{models.EventAll}}}},
+		Bus:    bus,
+		Logger: slog.New(slog.DiscardHandler),
+	})
+	d.Start(context.Background())
+	defer d.Stop()
+
+	publish := func(n int) {
+		for i := range n {
+			bus.Publish(models.EventAlertFired, models.AlertEventData{AlertID: models.AlertID(i)})
+		}
+	}
+	callsSoFar := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls
+	}
+	waitUntil := func(what string, done func() bool) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); !done(); {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// Fill both workers, then have the dispatcher take a third event and wait
+	// for a free worker. Of the next seven, two fit in the queue.
+	publish(2)
+	waitUntil("both workers to deliver", func() bool { return callsSoFar() == 2 })
+	publish(1)
+	waitUntil("the dispatcher to take the third event", func() bool { return len(d.queue) == 0 })
+	publish(7)
+	if got := len(d.queue); got != 2 {
+		t.Fatalf("queued events = %d, want 2", got)
+	}
+	close(release)
+
+	// Two deliveries in flight, one held by the dispatcher and two queued:
+	// the other five events were dropped.
+	waitUntil("the held and queued events", func() bool { return callsSoFar() >= 5 })
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if peak != 2 || calls != 5 {
+		t.Errorf("peak in flight = %d, deliveries = %d, want 2 and 5", peak, calls)
+	}
+}
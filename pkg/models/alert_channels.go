@@ -0,0 +1,143 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// AlertChannelType identifies how a notification channel delivers alerts.
+type AlertChannelType string
+
+const (
+	AlertChannelEmail   AlertChannelType = "email"
+	AlertChannelWebhook AlertChannelType = "webhook"
+	AlertChannelSlack   AlertChannelType = "slack"
+)
+
+// AlertChannel is one notification destination of an alert. Alerts keep their
+// recipient_user_ids and webhook_urls as shorthand for an email channel and
+// plain webhook channels; Channels adds Slack, templated webhooks and email to
+// addresses outside Logchef.
+type AlertChannel struct {
+	Type AlertChannelType `json:"type"`
+	// URL is the endpoint of webhook channels and the incoming-webhook URL of
+	// Slack channels.
+	URL string `json:"url,omitempty"`
+	// Emails are the addresses an email channel sends to.
+	Emails []string `json:"emails,omitempty"`
+	// Template, for webhook channels, is a Go text/template rendered with the
+	// notification to form the request body. Empty sends the default JSON
+	// payload.
+	Template string `json:"template,omitempty"`
+	// Headers are added to webhook requests (e.g. Authorization,
+	// Content-Type for templated bodies).
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Key identifies the channel's destination. Delivery results are recorded
+// under it, so a retry can skip the channels that already succeeded.
+func (c AlertChannel) Key() string {
+	switch c.Type {
+	case AlertChannelEmail:
+		return string(c.Type) + ":" + strings.Join(c.Emails, ",")
+	default:
+		return string(c.Type) + ":" + c.URL
+	}
+}
+
+// Normalize trims the channel's fields and drops empty and duplicate emails.
+func (c *AlertChannel) Normalize() {
+	c.Type = AlertChannelType(strings.ToLower(strings.TrimSpace(string(c.Type))))
+	c.URL = strings.TrimSpace(c.URL)
+	if len(c.Emails) > 0 {
+		seen := make(map[string]struct{}, len(c.Emails))
+		emails := make([]string, 0, len(c.Emails))
+		for _, raw := range c.Emails {
+			email := strings.TrimSpace(raw)
+			if email == "" {
+				continue
+			}
+			if _, ok := seen[email]; ok {
+				continue
+			}
+			seen[email] = struct{}{}
+			emails = append(emails, email)
+		}
+		c.Emails = emails
+	}
+}
+
+// Validate checks the channel has what its type needs to deliver.
+func (c *AlertChannel) Validate() error {
+	switch c.Type {
+	case AlertChannelEmail:
+		if len(c.Emails) == 0 {
+			return fmt.Errorf("email channel requires at least one address")
+		}
+		for _, email := range c.Emails {
+			if _, err := mail.ParseAddress(email); err != nil {
+				return fmt.Errorf("invalid email address %q", email)
+			}
+		}
+	case AlertChannelWebhook, AlertChannelSlack:
+		parsed, err := url.Parse(c.URL)
+		if err != nil || parsed.Host == "" {
+			return fmt.Errorf("invalid %s channel URL %q", c.Type, c.URL)
+		}
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return fmt.Errorf("%s channel URL %q must use http or https", c.Type, c.URL)
+		}
+	default:
+		return fmt.Errorf("invalid channel type %q (use email, webhook or slack)", c.Type)
+	}
+	if c.Template != "" {
+		if c.Type != AlertChannelWebhook {
+			return fmt.Errorf("only webhook channels support a template")
+		}
+		if _, err := ParseWebhookTemplate(c.Template); err != nil {
+			return fmt.Errorf("invalid webhook template: %w", err)
+		}
+	}
+	return nil
+}
+
+// webhookTemplateFuncs are available to webhook channel templates. json
+// encodes a value as JSON, so templates can embed strings and maps safely.
+var webhookTemplateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		encoded, err := json.Marshal(v)
+		return string(encoded), err
+	},
+}
+
+// ParseWebhookTemplate parses a webhook channel's body template.
+func ParseWebhookTemplate(text string) (*template.Template, error) {
+	return template.New("webhook").Funcs(webhookTemplateFuncs).Option("missingkey=error").Parse(text)
+}
+
+// AlertDeliveryStatus is the outcome of delivering to one channel.
+type AlertDeliveryStatus string
+
+const (
+	AlertDeliveryDelivered AlertDeliveryStatus = "delivered"
+	AlertDeliveryFailed    AlertDeliveryStatus = "failed"
+	// AlertDeliveryQueued means the notification was handed to a grouping
+	// dispatcher and goes out with the next batch for its channel.
+	AlertDeliveryQueued AlertDeliveryStatus = "queued"
+)
+
+// AlertChannelDelivery records one channel's delivery of a notification. A
+// history entry's payload lists these under "deliveries".
+type AlertChannelDelivery struct {
+	Channel  string              `json:"channel"`
+	Type     AlertChannelType    `json:"type"`
+	Status   AlertDeliveryStatus `json:"status"`
+	Attempts int                 `json:"attempts"`
+	Error    string              `json:"error,omitempty"`
+	At       time.Time           `json:"at"`
+}
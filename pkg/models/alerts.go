@@ -1,6 +1,12 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
 
 // AlertThresholdOperator represents the comparison operator used when checking the evaluated value.
 type AlertThresholdOperator string
@@ -102,18 +108,141 @@ func (e ErrInvalidAlertQueryConfiguration) Error() string {
 	return "invalid alert query configuration: " + e.Value
 }
 
+// AlertConditionAggregate is the aggregation a condition alert applies to the
+// logs matching its filter.
+type AlertConditionAggregate string
+
+const (
+	AlertAggregateCount AlertConditionAggregate = "count"
+	AlertAggregateSum   AlertConditionAggregate = "sum"
+	AlertAggregateAvg   AlertConditionAggregate = "avg"
+	AlertAggregateMin   AlertConditionAggregate = "min"
+	AlertAggregateMax   AlertConditionAggregate = "max"
+)
+
+// alertConditionFieldPattern restricts aggregate fields to plain (optionally
+// dotted) identifiers, so they can be embedded in SQL and LogsQL unescaped.
+var alertConditionFieldPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// AlertCondition is the structured form of a condition alert's ConditionJSON:
+// a LogchefQL filter, an aggregation over the matching logs and an optional
+// lookback window. The evaluator compiles it into the source's native query on
+// every run, so the alert needs no hand-written SQL.
+type AlertCondition struct {
+	Filter    string                  `json:"filter"`
+	Aggregate AlertConditionAggregate `json:"aggregate,omitempty"`
+	// Field is the numeric field aggregated by sum/avg/min/max.
+	Field string `json:"field,omitempty"`
+	// LookbackSeconds overrides the alert's lookback_seconds when positive.
+	LookbackSeconds int `json:"lookback_seconds,omitempty"`
+	// Baseline, when set, makes the alert's value the percent change of the
+	// aggregate against the same window a day or a week earlier.
+	Baseline AlertBaseline `json:"baseline,omitempty"`
+}
+
+// AlertBaseline is the earlier window a condition alert compares against.
+type AlertBaseline string
+
+const (
+	AlertBaselinePreviousDay  AlertBaseline = "previous_day"
+	AlertBaselinePreviousWeek AlertBaseline = "previous_week"
+)
+
+// Offset returns how far back the baseline window lies, 0 for none or an
+// unknown baseline.
+func (b AlertBaseline) Offset() time.Duration {
+	switch b {
+	case AlertBaselinePreviousDay:
+		return 24 * time.Hour
+	case AlertBaselinePreviousWeek:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// ParseAlertCondition decodes a ConditionJSON value. Alerts created before
+// conditions were evaluated server-side store the bare LogchefQL string and
+// carry the generated query in Query; for those ok is false and the caller
+// should keep evaluating Query. A JSON object is decoded, defaulted (count)
+// and validated.
+func ParseAlertCondition(raw string) (cond *AlertCondition, ok bool, err error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.HasPrefix(raw, "{") {
+		return nil, false, nil
+	}
+	cond = &AlertCondition{}
+	if err := json.Unmarshal([]byte(raw), cond); err != nil {
+		return nil, true, fmt.Errorf("condition_json is not a valid condition: %w", err)
+	}
+	if cond.Aggregate == "" {
+		cond.Aggregate = AlertAggregateCount
+	}
+	if err := cond.Validate(); err != nil {
+		return nil, true, err
+	}
+	return cond, true, nil
+}
+
+// Validate checks the aggregation and its field and the lookback override.
+// The filter itself is validated when it is compiled against a source.
+func (c *AlertCondition) Validate() error {
+	switch c.Aggregate {
+	case AlertAggregateCount:
+	case AlertAggregateSum, AlertAggregateAvg, AlertAggregateMin, AlertAggregateMax:
+		if c.Field == "" {
+			return fmt.Errorf("condition aggregate %q requires a field", c.Aggregate)
+		}
+	default:
+		return fmt.Errorf("invalid condition aggregate %q", c.Aggregate)
+	}
+	if c.Field != "" && !alertConditionFieldPattern.MatchString(c.Field) {
+		return fmt.Errorf("invalid condition field %q", c.Field)
+	}
+	if c.LookbackSeconds < 0 {
+		return fmt.Errorf("condition lookback_seconds must not be negative")
+	}
+	if c.Baseline != "" && c.Baseline.Offset() == 0 {
+		return fmt.Errorf("invalid condition baseline %q: use previous_day or previous_week", c.Baseline)
+	}
+	if offset := c.Baseline.Offset(); offset > 0 && time.Duration(c.LookbackSeconds)*time.Second > offset {
+		return fmt.Errorf("condition lookback_seconds must not exceed the %s baseline offset", c.Baseline)
+	}
+	return nil
+}
+
+// Lookback returns the condition's lookback window, or fallback when the
+// condition doesn't override it.
+func (c *AlertCondition) Lookback(fallback int) int {
+	if c.LookbackSeconds > 0 {
+		return c.LookbackSeconds
+	}
+	return fallback
+}
+
 // AlertState captures the persisted lifecycle state of an alert rule.
 type AlertState string
 
 const (
 	AlertStateFiring   AlertState = "firing"
 	AlertStateResolved AlertState = "resolved"
+	// AlertStatePending marks an alert whose condition holds but has not yet
+	// held for its ForSeconds.
+	AlertStatePending AlertState = "pending"
 )
 
 // Alert encapsulates a rule that is continuously evaluated against log data.
 // Alerts are scoped to a single source. Visibility (read access) is granted to
 // any user with source access via any team; edit access is creator + global
 // admin (legacy alerts with NULL CreatedBy are global-admin-only).
+//
+// An alert with SLOID set is a burn-rate alert: instead of running a query, its
+// value is that SLO's error-budget burn rate over LookbackSeconds. Updates set
+// slo_id to 0 to unlink it.
+//
+// With ForSeconds set, a breaching alert is first pending and only fires once
+// the condition has held on every evaluation for that long. BreachCount counts
+// the consecutive breaching evaluations since PendingSince.
 type Alert struct {
 	ID                AlertID                `json:"id"`
 	SourceID          SourceID               `json:"source_id"`
@@ -127,19 +256,27 @@ type Alert struct {
 	ThresholdOperator AlertThresholdOperator `json:"threshold_operator"`
 	ThresholdValue    float64                `json:"threshold_value"`
 	FrequencySeconds  int                    `json:"frequency_seconds"`
+	ForSeconds        int                    `json:"for_seconds"`
 	Severity          AlertSeverity          `json:"severity"`
 	Labels            map[string]string      `json:"labels,omitempty"`
 	Annotations       map[string]string      `json:"annotations,omitempty"`
 	RecipientUserIDs  []UserID               `json:"recipient_user_ids,omitempty"`
 	WebhookURLs       []string               `json:"webhook_urls,omitempty"`
+	Channels          []AlertChannel         `json:"channels,omitempty"`
+	SLOID             *SLOID                 `json:"slo_id,omitempty"`
 	GeneratorURL      string                 `json:"generator_url,omitempty"`
 	IsActive          bool                   `json:"is_active"`
 	LastState         AlertState             `json:"last_state"`
 	LastEvaluatedAt   *time.Time             `json:"last_evaluated_at,omitempty"`
 	LastTriggeredAt   *time.Time             `json:"last_triggered_at,omitempty"`
+	PendingSince      *time.Time             `json:"pending_since,omitempty"`
+	BreachCount       int                    `json:"breach_count"`
 	CreatedBy         *UserID                `json:"created_by,omitempty"`
-	CreatedAt         time.Time              `json:"created_at"`
-	UpdatedAt         time.Time              `json:"updated_at"`
+	// Managed marks an alert declared in provisioning config; the API
+	// rejects edits to it.
+	Managed   bool      `json:"managed"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // AlertHistoryEntry captures individual trigger or resolution events for an alert.
@@ -155,6 +292,27 @@ type AlertHistoryEntry struct {
 	CreatedAt   time.Time      `json:"created_at"`
 }
 
+// AlertStateSpan is one stretch of an alert's timeline spent in a single state.
+type AlertStateSpan struct {
+	State AlertState `json:"state"`
+	Start time.Time  `json:"start"`
+	End   time.Time  `json:"end"`
+	// Value is the value that triggered a firing span.
+	Value *float64 `json:"value,omitempty"`
+}
+
+// AlertStateResponse combines an alert with its current state and the states
+// it went through between From and To, oldest first. LastValue is the value
+// of the most recent history entry that recorded one.
+type AlertStateResponse struct {
+	Alert     *Alert           `json:"alert"`
+	State     AlertState       `json:"state"`
+	LastValue *float64         `json:"last_value,omitempty"`
+	From      time.Time        `json:"from"`
+	To        time.Time        `json:"to"`
+	Timeline  []AlertStateSpan `json:"timeline"`
+}
+
 // CreateAlertRequest defines the payload required to create a new alert rule.
 type CreateAlertRequest struct {
 	SourceID          SourceID               `json:"source_id"`
@@ -168,11 +326,14 @@ type CreateAlertRequest struct {
 	ThresholdOperator AlertThresholdOperator `json:"threshold_operator"`
 	ThresholdValue    float64                `json:"threshold_value"`
 	FrequencySeconds  int                    `json:"frequency_seconds"`
+	ForSeconds        int                    `json:"for_seconds"`
 	Severity          AlertSeverity          `json:"severity"`
 	Labels            map[string]string      `json:"labels"`
 	Annotations       map[string]string      `json:"annotations"`
 	RecipientUserIDs  []UserID               `json:"recipient_user_ids"`
 	WebhookURLs       []string               `json:"webhook_urls"`
+	Channels          []AlertChannel         `json:"channels"`
+	SLOID             *SLOID                 `json:"slo_id"`
 	GeneratorURL      string                 `json:"generator_url"`
 	IsActive          bool                   `json:"is_active"`
 }
@@ -189,11 +350,14 @@ type UpdateAlertRequest struct {
 	ThresholdOperator *AlertThresholdOperator `json:"threshold_operator"`
 	ThresholdValue    *float64                `json:"threshold_value"`
 	FrequencySeconds  *int                    `json:"frequency_seconds"`
+	ForSeconds        *int                    `json:"for_seconds"`
 	Severity          *AlertSeverity          `json:"severity"`
 	Labels            *map[string]string      `json:"labels"`
 	Annotations       *map[string]string      `json:"annotations"`
 	RecipientUserIDs  *[]UserID               `json:"recipient_user_ids"`
 	WebhookURLs       *[]string               `json:"webhook_urls"`
+	Channels          *[]AlertChannel         `json:"channels"`
+	SLOID             *SLOID                  `json:"slo_id"`
 	GeneratorURL      *string                 `json:"generator_url"`
 	IsActive          *bool                   `json:"is_active"`
 }
@@ -1,6 +1,9 @@
 package models
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestResolveAlertMetadataAllowsConditionModeForLogsQL(t *testing.T) {
 	language, mode, err := ResolveAlertMetadata(QueryLanguageLogsQL, AlertEditorModeCondition)
@@ -14,3 +17,50 @@ func TestResolveAlertMetadataAllowsConditionModeForLogsQL(t *testing.T) {
 		t.Fatalf("unexpected mode: %q", mode)
 	}
 }
+
+func TestParseAlertCondition(t *testing.T) {
+	// Legacy conditions are the bare LogchefQL string.
+	cond, ok, err := ParseAlertCondition(`level="error"`)
+	if err != nil || ok || cond != nil {
+		t.Fatalf("legacy condition: cond=%+v ok=%v err=%v", cond, ok, err)
+	}
+
+	cond, ok, err = ParseAlertCondition(`{"filter":"level=\"error\""}`)
+	if err != nil || !ok {
+		t.Fatalf("ParseAlertCondition: ok=%v err=%v", ok, err)
+	}
+	if cond.Aggregate != AlertAggregateCount {
+		t.Fatalf("aggregate = %q, want count", cond.Aggregate)
+	}
+	if got := cond.Lookback(300); got != 300 {
+		t.Fatalf("Lookback(300) = %d, want 300", got)
+	}
+
+	cond, _, err = ParseAlertCondition(`{"filter":"","aggregate":"avg","field":"http.duration_ms","lookback_seconds":60}`)
+	if err != nil {
+		t.Fatalf("ParseAlertCondition(avg): %v", err)
+	}
+	if got := cond.Lookback(300); got != 60 {
+		t.Fatalf("Lookback(300) = %d, want 60", got)
+	}
+
+	cond, _, err = ParseAlertCondition(`{"filter":"","baseline":"previous_week"}`)
+	if err != nil || cond.Baseline.Offset() != 7*24*time.Hour {
+		t.Fatalf("ParseAlertCondition(baseline): cond=%+v err=%v", cond, err)
+	}
+
+	invalid := []string{
+		`{"filter":`,
+		`{"aggregate":"median","field":"x"}`,
+		`{"aggregate":"sum"}`,
+		`{"aggregate":"max","field":"x; DROP TABLE logs"}`,
+		`{"lookback_seconds":-1}`,
+		`{"baseline":"previous_month"}`,
+		`{"baseline":"previous_day","lookback_seconds":90000}`,
+	}
+	for _, raw := range invalid {
+		if _, ok, err := ParseAlertCondition(raw); err == nil || !ok {
+			t.Errorf("ParseAlertCondition(%s): ok=%v err=%v, want an error", raw, ok, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxAnnotationTextLength caps an annotation's note.
+const MaxAnnotationTextLength = 2000
+
+// Annotation marks a moment or a time range on a team's timelines, such as a
+// deploy or the start of an incident. A team-wide annotation (SourceID nil)
+// shows on the histograms of every source the team can query; one with a
+// SourceID only on that source's.
+type Annotation struct {
+	ID       AnnotationID `json:"id"`
+	TeamID   TeamID       `json:"team_id"`
+	SourceID *SourceID    `json:"source_id,omitempty"`
+	Text     string       `json:"text"`
+	// Tags are free-form labels ("deploy", "incident") the frontend can style
+	// markers by.
+	Tags     []string  `json:"tags"`
+	StartsAt time.Time `json:"starts_at"`
+	// EndsAt is nil for a point-in-time marker.
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	CreatedBy *UserID    `json:"created_by,omitempty"`
+	Timestamps
+}
+
+// AnnotationRequest is the body for creating or replacing an annotation.
+// StartsAt defaults to now on create.
+type AnnotationRequest struct {
+	SourceID *SourceID  `json:"source_id"`
+	Text     string     `json:"text"`
+	Tags     []string   `json:"tags"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+}
+
+// Validate normalizes the annotation's text and tags and checks its range.
+func (a *Annotation) Validate() error {
+	a.Text = strings.TrimSpace(a.Text)
+	if a.Text == "" {
+		return fmt.Errorf("text is required")
+	}
+	if len(a.Text) > MaxAnnotationTextLength {
+		return fmt.Errorf("text must be at most %d characters", MaxAnnotationTextLength)
+	}
+	tags := make([]string, 0, len(a.Tags))
+	seen := make(map[string]struct{}, len(a.Tags))
+	for _, tag := range a.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if _, dup := seen[tag]; dup {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+	a.Tags = tags
+	if a.StartsAt.IsZero() {
+		return fmt.Errorf("starts_at is required")
+	}
+	if a.EndsAt != nil && a.EndsAt.Before(a.StartsAt) {
+		return fmt.Errorf("ends_at must not be before starts_at")
+	}
+	return nil
+}
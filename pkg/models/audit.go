@@ -0,0 +1,92 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction names a recorded sensitive operation, as "<resource>.<verb>".
+type AuditAction string
+
+// Audited actions.
+const (
+	AuditActionSourceCreate     AuditAction = "source.create"
+	AuditActionSourceDelete     AuditAction = "source.delete"
+	AuditActionSourceExport     AuditAction = "source.export"
+	AuditActionSourceTTLUpdate  AuditAction = "source.ttl_update"
+	AuditActionSourcePartDrop   AuditAction = "source.partition_drop"
+	AuditActionSourceExtraction AuditAction = "source.extraction_rules_update"
+	AuditActionSourceTracing    AuditAction = "source.trace_correlation_update"
+	AuditActionSourceMasking    AuditAction = "source.masking_update"
+	AuditActionSourceHealth     AuditAction = "source.health_change"
+	AuditActionUserCreate       AuditAction = "user.create"
+	AuditActionTeamCreate       AuditAction = "team.create"
+	AuditActionTeamMemberAdd    AuditAction = "team.member.add"
+	AuditActionTeamMemberRemove AuditAction = "team.member.remove"
+	AuditActionTeamSourceAdd    AuditAction = "team.source.add"
+	AuditActionTeamSourcePolicy AuditAction = "team.source_policy_update"
+	AuditActionAlertCreate      AuditAction = "alert.create"
+	AuditActionAlertUpdate      AuditAction = "alert.update"
+	AuditActionAlertDelete      AuditAction = "alert.delete"
+	AuditActionAlertResolve     AuditAction = "alert.resolve"
+	AuditActionSilenceCreate    AuditAction = "silence.create"
+	AuditActionSilenceUpdate    AuditAction = "silence.update"
+	AuditActionSilenceDelete    AuditAction = "silence.delete"
+	AuditActionWebhookCreate    AuditAction = "webhook.create"
+	AuditActionWebhookUpdate    AuditAction = "webhook.update"
+	AuditActionWebhookDelete    AuditAction = "webhook.delete"
+	AuditActionQueryExecuteSQL  AuditAction = "query.execute_sql"
+	AuditActionQueryKill        AuditAction = "query.kill"
+	AuditActionDatabaseBackup   AuditAction = "database.backup"
+)
+
+// Audited resource types.
+const (
+	AuditResourceSource     = "source"
+	AuditResourceUser       = "user"
+	AuditResourceTeam       = "team"
+	AuditResourceTeamMember = "team_member"
+	AuditResourceTeamSource = "team_source"
+	AuditResourceAlert      = "alert"
+	AuditResourceSilence    = "silence"
+	AuditResourceWebhook    = "webhook"
+	AuditResourceDatabase   = "database"
+	AuditResourceQuery      = "query"
+)
+
+// Audit list bounds for the admin endpoint.
+const (
+	AuditEventsDefaultLimit = 100
+	AuditEventsMaxLimit     = 1000
+)
+
+// AuditEvent is one entry of the audit trail: who did what to which resource,
+// when and from where. UserEmail is a snapshot taken when the event was
+// recorded, so it survives the user being deleted (UserID is then nil).
+// ResourceID is a string so composite resources (a team member is
+// "<team>:<user>") fit the same column.
+type AuditEvent struct {
+	ID           int64           `json:"id"`
+	UserID       *UserID         `json:"user_id,omitempty"`
+	UserEmail    string          `json:"user_email"`
+	Action       AuditAction     `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	TeamID       *TeamID         `json:"team_id,omitempty"`
+	IPAddress    string          `json:"ip_address"`
+	Details      json.RawMessage `json:"details,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// AuditEventFilter narrows ListAuditEvents. Zero-valued fields don't filter;
+// Since is inclusive and Until exclusive.
+type AuditEventFilter struct {
+	UserID       *UserID
+	Action       AuditAction
+	ResourceType string
+	TeamID       *TeamID
+	Since        time.Time
+	Until        time.Time
+	Limit        int
+	Offset       int
+}
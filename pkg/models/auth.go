@@ -48,6 +48,7 @@ const (
 	TokenScopeSourcesRead       TokenScope = "sources:read"
 	TokenScopeSourcesWrite      TokenScope = "sources:write"
 	TokenScopeLogsRead          TokenScope = "logs:read"
+	TokenScopeLogsWrite         TokenScope = "logs:write"
 	TokenScopeSavedQueriesRead  TokenScope = "saved_queries:read"
 	TokenScopeSavedQueriesWrite TokenScope = "saved_queries:write"
 	TokenScopeCollectionsRead   TokenScope = "collections:read"
@@ -56,10 +57,17 @@ const (
 	TokenScopeAlertsWrite       TokenScope = "alerts:write"
 	TokenScopeDashboardsRead    TokenScope = "dashboards:read"
 	TokenScopeDashboardsWrite   TokenScope = "dashboards:write"
+	TokenScopeNotebooksRead     TokenScope = "notebooks:read"
+	TokenScopeNotebooksWrite    TokenScope = "notebooks:write"
 	TokenScopeQuerySharesRead   TokenScope = "query_shares:read"
 	TokenScopeQuerySharesWrite  TokenScope = "query_shares:write"
+	TokenScopeAnnotationsRead   TokenScope = "annotations:read"
+	TokenScopeAnnotationsWrite  TokenScope = "annotations:write"
+	TokenScopeBookmarksRead     TokenScope = "bookmarks:read"
+	TokenScopeBookmarksWrite    TokenScope = "bookmarks:write"
 	TokenScopeSettingsRead      TokenScope = "settings:read"
 	TokenScopeSettingsWrite     TokenScope = "settings:write"
+	TokenScopeAuditRead         TokenScope = "audit:read"
 )
 
 // TeamRole represents the possible team member roles
@@ -77,8 +85,77 @@ const (
 
 	// TeamRoleMember represents a regular team member
 	TeamRoleMember TeamRole = "member"
+
+	// TeamRoleViewer represents a read-only team member who can query logs
+	// but cannot author saved queries or alerts
+	TeamRoleViewer TeamRole = "viewer"
+)
+
+// TeamPermission is an action gated by a user's role within a team.
+type TeamPermission string
+
+const (
+	// TeamPermissionQueryLogs allows querying and exploring the team's sources.
+	TeamPermissionQueryLogs TeamPermission = "query_logs"
+	// TeamPermissionManageSavedQueries allows creating and editing saved queries.
+	TeamPermissionManageSavedQueries TeamPermission = "manage_saved_queries"
+	// TeamPermissionManageAlerts allows creating, editing, and resolving alerts.
+	TeamPermissionManageAlerts TeamPermission = "manage_alerts"
+	// TeamPermissionManageCollections allows curating shared collections.
+	TeamPermissionManageCollections TeamPermission = "manage_collections"
+	// TeamPermissionManageMembers allows adding and removing team members.
+	TeamPermissionManageMembers TeamPermission = "manage_members"
+	// TeamPermissionManageSources allows linking and unlinking team sources.
+	TeamPermissionManageSources TeamPermission = "manage_sources"
+	// TeamPermissionManageNotebooks allows authoring notebooks and refreshing
+	// their cached cell results.
+	TeamPermissionManageNotebooks TeamPermission = "manage_notebooks"
+	// TeamPermissionIngestLogs allows pushing logs into the team's sources.
+	TeamPermissionIngestLogs TeamPermission = "ingest_logs"
+	// TeamPermissionAnnotate allows marking deploys and incidents on the
+	// team's timelines.
+	TeamPermissionAnnotate TeamPermission = "annotate"
+	// TeamPermissionManageColumnPresets allows setting the team's default
+	// column layouts for its sources.
+	TeamPermissionManageColumnPresets TeamPermission = "manage_column_presets"
+	// TeamPermissionViewUnmasked allows reading a source's masked columns as
+	// they are instead of hashed or redacted.
+	TeamPermissionViewUnmasked TeamPermission = "view_unmasked"
 )
 
+// Valid reports whether r is a role a team member can hold.
+func (r TeamRole) Valid() bool {
+	switch r {
+	case TeamRoleAdmin, TeamRoleEditor, TeamRoleMember, TeamRoleViewer:
+		return true
+	default:
+		return false
+	}
+}
+
+// HasPermission reports whether the team role grants p. Viewers can only
+// query; members can also author their own saved queries, alerts, notebooks
+// and annotations; editors additionally curate collections, push logs and set
+// team column presets; admins manage members and sources and see masked
+// columns unmasked.
+func (r TeamRole) HasPermission(p TeamPermission) bool {
+	switch r {
+	case TeamRoleAdmin:
+		return true
+	case TeamRoleEditor:
+		return p != TeamPermissionManageMembers && p != TeamPermissionManageSources &&
+			p != TeamPermissionViewUnmasked
+	case TeamRoleMember:
+		return p == TeamPermissionQueryLogs || p == TeamPermissionManageSavedQueries ||
+			p == TeamPermissionManageAlerts || p == TeamPermissionManageNotebooks ||
+			p == TeamPermissionAnnotate
+	case TeamRoleViewer:
+		return p == TeamPermissionQueryLogs
+	default:
+		return false
+	}
+}
+
 // User represents a user in the system
 type User struct {
 	ID           UserID          `json:"id" db:"id"`
@@ -127,6 +204,9 @@ type Team struct {
 	MemberCount int    `db:"-" json:"member_count"`
 	Timestamps
 	Managed bool `db:"managed" json:"managed"`
+	// RawSQLEnabled allows members to query the team's ClickHouse sources
+	// in raw SQL. When false, only LogchefQL is accepted.
+	RawSQLEnabled bool `db:"raw_sql_enabled" json:"raw_sql_enabled"`
 }
 
 // TeamMember represents a user's membership in a team
@@ -149,6 +229,18 @@ type UserTeamDetails struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 	MemberCount int       `json:"member_count"`
 	Role        TeamRole  `json:"role"`
+	// RawSQLEnabled mirrors Team.RawSQLEnabled.
+	RawSQLEnabled bool `json:"raw_sql_enabled"`
+}
+
+// TeamSourcePolicy is the row-level access policy on a team's link to a
+// source. RowFilter is a ClickHouse boolean expression, such as
+// namespace = 'team-a', ANDed into every query the team runs on the source;
+// empty means the team sees every row.
+type TeamSourcePolicy struct {
+	TeamID    TeamID   `json:"team_id"`
+	SourceID  SourceID `json:"source_id"`
+	RowFilter string   `json:"row_filter"`
 }
 
 // APIToken represents an API token for authentication
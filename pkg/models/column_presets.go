@@ -0,0 +1,108 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Column preset limits.
+const (
+	MaxColumnPresetColumns    = 200
+	MaxColumnPresetNameLength = 256
+	MaxColumnPresetWidth      = 4000
+)
+
+// ColumnFormat is how the log table renders a column's values.
+type ColumnFormat string
+
+const (
+	// ColumnFormatAuto leaves rendering to the column's type.
+	ColumnFormatAuto         ColumnFormat = ""
+	ColumnFormatText         ColumnFormat = "text"
+	ColumnFormatJSON         ColumnFormat = "json"
+	ColumnFormatTimestamp    ColumnFormat = "timestamp"
+	ColumnFormatRelativeTime ColumnFormat = "relative_time"
+	ColumnFormatNumber       ColumnFormat = "number"
+	ColumnFormatBytes        ColumnFormat = "bytes"
+)
+
+// Valid reports whether f is a known column format.
+func (f ColumnFormat) Valid() bool {
+	switch f {
+	case ColumnFormatAuto, ColumnFormatText, ColumnFormatJSON, ColumnFormatTimestamp,
+		ColumnFormatRelativeTime, ColumnFormatNumber, ColumnFormatBytes:
+		return true
+	default:
+		return false
+	}
+}
+
+// ColumnPresetColumn is one visible column of a preset, in display order.
+type ColumnPresetColumn struct {
+	Name string `json:"name"`
+	// Width is the column width in pixels; 0 sizes it automatically.
+	Width  int          `json:"width,omitempty"`
+	Format ColumnFormat `json:"format,omitempty"`
+	// Wrap wraps long values instead of truncating them.
+	Wrap bool `json:"wrap,omitempty"`
+}
+
+// ColumnPreset is a saved column layout for a source: either a user's own
+// (UserID set) or a team's default for its members (TeamID set).
+type ColumnPreset struct {
+	SourceID SourceID             `json:"source_id"`
+	UserID   *UserID              `json:"user_id,omitempty"`
+	TeamID   *TeamID              `json:"team_id,omitempty"`
+	Columns  []ColumnPresetColumn `json:"columns"`
+	// UpdatedBy is who last saved a team default.
+	UpdatedBy *UserID `json:"updated_by,omitempty"`
+	Timestamps
+}
+
+// ColumnPresetRequest is the body for saving a personal preset or a team
+// default.
+type ColumnPresetRequest struct {
+	Columns []ColumnPresetColumn `json:"columns"`
+}
+
+// ColumnPresets is what a user sees for a source in a team: their own
+// preset, the team default, and the one that applies (personal over team).
+// Any of them may be nil.
+type ColumnPresets struct {
+	Personal  *ColumnPreset `json:"personal"`
+	Team      *ColumnPreset `json:"team"`
+	Effective *ColumnPreset `json:"effective"`
+}
+
+// ValidateColumnPresetColumns trims column names and checks the list: at
+// least one column, no duplicates, bounded widths and known formats.
+func ValidateColumnPresetColumns(columns []ColumnPresetColumn) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("at least one column is required")
+	}
+	if len(columns) > MaxColumnPresetColumns {
+		return fmt.Errorf("a preset can hold at most %d columns", MaxColumnPresetColumns)
+	}
+	seen := make(map[string]struct{}, len(columns))
+	for i := range columns {
+		col := &columns[i]
+		col.Name = strings.TrimSpace(col.Name)
+		if col.Name == "" {
+			return fmt.Errorf("column %d: name is required", i)
+		}
+		if len(col.Name) > MaxColumnPresetNameLength {
+			return fmt.Errorf("column %d: name must be at most %d characters", i, MaxColumnPresetNameLength)
+		}
+		if _, dup := seen[col.Name]; dup {
+			return fmt.Errorf("column %q is listed more than once", col.Name)
+		}
+		seen[col.Name] = struct{}{}
+		if col.Width < 0 || col.Width > MaxColumnPresetWidth {
+			return fmt.Errorf("column %q: width must be between 0 and %d", col.Name, MaxColumnPresetWidth)
+		}
+		if !col.Format.Valid() {
+			return fmt.Errorf("column %q: unknown format %q", col.Name, col.Format)
+		}
+	}
+	return nil
+}
@@ -24,6 +24,24 @@ type (
 
 	// AlertID represents a unique alert identifier
 	AlertID int64
+
+	// SLOID represents a unique service-level objective identifier
+	SLOID int64
+
+	// SilenceID represents a unique alert silence identifier
+	SilenceID int64
+
+	// WebhookID represents a unique outbound webhook endpoint identifier
+	WebhookID int64
+
+	// AnnotationID represents a unique timeline annotation identifier
+	AnnotationID int64
+
+	// LogBookmarkID represents a unique pinned log row identifier
+	LogBookmarkID int64
+
+	// QuerySnippetID represents a unique query snippet identifier
+	QuerySnippetID int64
 )
 
 const sessionIDLogPrefix = 8
@@ -63,13 +81,30 @@ const (
 // QueryStats represents statistics about query execution
 type QueryStats struct {
 	ExecutionTimeMs float64 `json:"execution_time_ms"`
-	RowsRead        int     `json:"rows_read"`
-	BytesRead       int     `json:"bytes_read,omitempty"`
-	RowsReturned    int     `json:"rows_returned,omitempty"`
-	BytesReturned   int     `json:"bytes_returned,omitempty"`
-	LimitApplied    int     `json:"limit_applied,omitempty"`
-	Truncated       bool    `json:"truncated,omitempty"`
-	TruncatedReason string  `json:"truncated_reason,omitempty"`
+	// RowsRead and BytesRead are what ClickHouse scanned, taken from its
+	// progress packets; without them RowsRead falls back to the rows returned.
+	RowsRead  int `json:"rows_read"`
+	BytesRead int `json:"bytes_read,omitempty"`
+	// PeakMemoryBytes is the query's peak memory usage, when ClickHouse reports it.
+	PeakMemoryBytes int64  `json:"peak_memory_bytes,omitempty"`
+	RowsReturned    int    `json:"rows_returned,omitempty"`
+	BytesReturned   int    `json:"bytes_returned,omitempty"`
+	LimitApplied    int    `json:"limit_applied,omitempty"`
+	Truncated       bool   `json:"truncated,omitempty"`
+	TruncatedReason string `json:"truncated_reason,omitempty"`
+	// SampleRatio is the fraction of rows a sampled query kept, and
+	// SampleMethod how it picked them: "sample" or "random". Rows returned
+	// are then representative rather than complete.
+	Sampled      bool    `json:"sampled,omitempty"`
+	SampleRatio  float64 `json:"sample_ratio,omitempty"`
+	SampleMethod string  `json:"sample_method,omitempty"`
+	// DedupedRows is how many rows a deduped query folded into the rows
+	// before them; RowsReturned then counts the rows left.
+	DedupedRows int `json:"deduped_rows,omitempty"`
+	// Timezone is the zone DateTime values in the rows were converted to, and
+	// TimezoneOffset its UTC offset when the query ran, e.g. "+05:30".
+	Timezone       string `json:"timezone,omitempty"`
+	TimezoneOffset string `json:"timezone_offset,omitempty"`
 }
 
 // ColumnInfo represents column metadata from ClickHouse
@@ -77,6 +112,10 @@ type ColumnInfo struct {
 	Name        string `json:"name"`
 	Type        string `json:"type"`
 	Description string `json:"description,omitempty"`
+	// Virtual columns come from a source extraction rule rather than the
+	// table; Expression is the ClickHouse expression that computes them.
+	Virtual    bool   `json:"virtual,omitempty"`
+	Expression string `json:"expression,omitempty"`
 }
 
 // QueryWarning represents a non-fatal query execution warning.
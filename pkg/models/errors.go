@@ -39,6 +39,13 @@ const (
 
 	// ManagedResourceErrorType indicates a mutation attempted on a config-managed resource
 	ManagedResourceErrorType ErrorType = "ManagedResourceError"
+
+	// QueryCostErrorType indicates a query rejected by the query cost limits
+	QueryCostErrorType ErrorType = "QueryCostError"
+
+	// QuotaExceededErrorType indicates a query rejected because the team has
+	// used up its daily query quota
+	QuotaExceededErrorType ErrorType = "QuotaExceededError"
 )
 
 // ErrorResponse represents a standardized error response
@@ -0,0 +1,100 @@
+package models
+
+import "time"
+
+// EventType names an event, as "<resource>.<what happened>".
+type EventType string
+
+// Events published on the internal event bus. All but EventQueryExecuted can
+// also be delivered to outbound webhooks.
+const (
+	EventSourceCreated       EventType = "source.created"
+	EventSourceDeleted       EventType = "source.deleted"
+	EventSourceHealthChanged EventType = "source.health_changed"
+	EventAlertFired          EventType = "alert.fired"
+	EventAlertResolved       EventType = "alert.resolved"
+	EventTeamMemberAdded     EventType = "team.member_added"
+	EventTeamMemberRemoved   EventType = "team.member_removed"
+
+	// EventQueryExecuted is published for every query run from the explorer,
+	// dashboards and the API. It stays in-process: it is too frequent, and
+	// carries too much, to send to webhooks.
+	EventQueryExecuted EventType = "query.executed"
+
+	// EventPing is only sent by the webhook test endpoint.
+	EventPing EventType = "ping"
+	// EventAll subscribes a webhook to every event type.
+	EventAll EventType = "*"
+)
+
+// EventTypes lists the event types a webhook can subscribe to.
+var EventTypes = []EventType{
+	EventSourceCreated,
+	EventSourceDeleted,
+	EventSourceHealthChanged,
+	EventAlertFired,
+	EventAlertResolved,
+	EventTeamMemberAdded,
+	EventTeamMemberRemoved,
+}
+
+// Event is one published event. It is also the JSON body a webhook receives;
+// Data is one of the *EventData types below, by value.
+type Event struct {
+	// ID is unique per event and repeated on every delivery attempt, so
+	// receivers can drop duplicates.
+	ID         string    `json:"id"`
+	Type       EventType `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// SourceEventData is the data of source.created and source.deleted.
+type SourceEventData struct {
+	SourceID   SourceID   `json:"source_id"`
+	Name       string     `json:"name,omitempty"`
+	SourceType SourceType `json:"source_type,omitempty"`
+}
+
+// SourceHealthEventData is the data of source.health_changed, published when
+// a health probe finds a source's status differs from the previous probe.
+type SourceHealthEventData struct {
+	SourceID       SourceID     `json:"source_id"`
+	Name           string       `json:"name"`
+	Status         HealthStatus `json:"status"`
+	PreviousStatus HealthStatus `json:"previous_status"`
+	Error          string       `json:"error,omitempty"`
+}
+
+// AlertEventData is the data of alert.fired and alert.resolved.
+type AlertEventData struct {
+	AlertID           AlertID                `json:"alert_id"`
+	Name              string                 `json:"name"`
+	SourceID          SourceID               `json:"source_id"`
+	Severity          AlertSeverity          `json:"severity"`
+	Value             float64                `json:"value"`
+	ThresholdOperator AlertThresholdOperator `json:"threshold_operator"`
+	ThresholdValue    float64                `json:"threshold_value"`
+	Message           string                 `json:"message,omitempty"`
+}
+
+// TeamMemberEventData is the data of team.member_added and
+// team.member_removed. Role is empty on removal.
+type TeamMemberEventData struct {
+	TeamID TeamID   `json:"team_id"`
+	UserID UserID   `json:"user_id"`
+	Role   TeamRole `json:"role,omitempty"`
+}
+
+// QueryEventData is the data of query.executed.
+type QueryEventData struct {
+	UserID     UserID             `json:"user_id"`
+	TeamID     TeamID             `json:"team_id"`
+	SourceID   SourceID           `json:"source_id"`
+	Language   QueryLanguage      `json:"language"`
+	Status     QueryHistoryStatus `json:"status"`
+	DurationMs int64              `json:"duration_ms"`
+	RowCount   int64              `json:"row_count"`
+	RowsRead   int64              `json:"rows_read"`
+	Error      string             `json:"error,omitempty"`
+}
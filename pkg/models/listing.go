@@ -0,0 +1,50 @@
+package models
+
+// List endpoints share one query convention: ?limit=&offset= for paging,
+// ?sort=<field> with an optional leading "-" for descending order, and
+// per-endpoint equality filters. Each endpoint allow-lists its sort fields and
+// filters; anything else is rejected rather than ignored.
+const (
+	// DefaultListLimit is the page size when a request gives no limit.
+	DefaultListLimit = 100
+	// MaxListLimit caps the page size of any list endpoint.
+	MaxListLimit = 1000
+)
+
+// ListQuery is a parsed list request.
+type ListQuery struct {
+	Limit   int
+	Offset  int
+	Sort    string
+	Desc    bool
+	Filters map[string]string
+}
+
+// Pagination describes the page returned by a list endpoint. Total counts
+// every item matching the filters, not only those on the page.
+type Pagination struct {
+	Total   int    `json:"total"`
+	Limit   int    `json:"limit"`
+	Offset  int    `json:"offset"`
+	Sort    string `json:"sort,omitempty"`
+	Order   string `json:"order,omitempty"`
+	HasMore bool   `json:"has_more"`
+}
+
+// NewPagination describes the page of q out of total matching items.
+func NewPagination(q ListQuery, total int) *Pagination {
+	p := &Pagination{
+		Total:   total,
+		Limit:   q.Limit,
+		Offset:  q.Offset,
+		Sort:    q.Sort,
+		HasMore: q.Offset+q.Limit < total,
+	}
+	if q.Sort != "" {
+		p.Order = "asc"
+		if q.Desc {
+			p.Order = "desc"
+		}
+	}
+	return p
+}
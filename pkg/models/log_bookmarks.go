@@ -0,0 +1,76 @@
+package models
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Log bookmark limits.
+const (
+	// MaxLogBookmarkPayloadSize caps the stored snapshot of a pinned row.
+	MaxLogBookmarkPayloadSize = 64 * 1024
+	// MaxLogBookmarkNoteLength caps a bookmark's note.
+	MaxLogBookmarkNoteLength = 2000
+)
+
+// LogBookmark is a single log row pinned from a team's source. The row is kept
+// as a snapshot (Payload) so it survives retention, and identified by
+// Fingerprint, a hash of that snapshot, so pinning the same row twice is
+// detected. A personal bookmark (Shared false) is only visible to the user who
+// pinned it; a shared one to the whole team.
+type LogBookmark struct {
+	ID       LogBookmarkID `json:"id"`
+	TeamID   TeamID        `json:"team_id"`
+	SourceID SourceID      `json:"source_id"`
+	UserID   UserID        `json:"user_id"`
+	Shared   bool          `json:"shared"`
+	// LogTimestamp is the pinned row's own timestamp, not when it was pinned.
+	LogTimestamp time.Time       `json:"log_timestamp"`
+	Fingerprint  string          `json:"fingerprint"`
+	Payload      json.RawMessage `json:"payload"`
+	Note         string          `json:"note"`
+	Timestamps
+}
+
+// CreateLogBookmarkRequest is the body for pinning a log row. Payload is the
+// row as returned by a query, a JSON object.
+type CreateLogBookmarkRequest struct {
+	SourceID  SourceID        `json:"source_id"`
+	Timestamp *time.Time      `json:"timestamp"`
+	Payload   json.RawMessage `json:"payload"`
+	Note      string          `json:"note"`
+	Shared    bool            `json:"shared"`
+}
+
+// UpdateLogBookmarkRequest is the body for changing a bookmark's note and
+// visibility. The pinned row itself is immutable.
+type UpdateLogBookmarkRequest struct {
+	Note   string `json:"note"`
+	Shared bool   `json:"shared"`
+}
+
+// CanonicalLogPayload re-encodes a log row snapshot with sorted keys and
+// returns it with its fingerprint, so the same row always hashes the same
+// regardless of the key order it was submitted in. Numbers keep their
+// original text so wide integers aren't rounded.
+func CanonicalLogPayload(raw json.RawMessage) (json.RawMessage, string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var row map[string]any
+	if err := dec.Decode(&row); err != nil || row == nil {
+		return nil, "", fmt.Errorf("payload must be a JSON object")
+	}
+	canonical, err := json.Marshal(row)
+	if err != nil {
+		return nil, "", fmt.Errorf("encoding payload: %w", err)
+	}
+	if len(canonical) > MaxLogBookmarkPayloadSize {
+		return nil, "", fmt.Errorf("payload exceeds %d bytes", MaxLogBookmarkPayloadSize)
+	}
+	sum := sha256.Sum256(canonical)
+	return canonical, hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,28 @@
+package models
+
+// LogPattern is a message template with its variable parts (numbers, IDs,
+// addresses, timestamps) replaced by placeholders such as <num>, and the
+// in-range messages that produced it. FirstSeen and LastSeen are Unix
+// milliseconds.
+type LogPattern struct {
+	Pattern   string   `json:"pattern"`
+	Count     int64    `json:"count"`
+	Percent   float64  `json:"percent"`
+	Examples  []string `json:"examples"`
+	FirstSeen int64    `json:"first_seen"`
+	LastSeen  int64    `json:"last_seen"`
+	// BaselineCount is the pattern's count in the window before the range,
+	// set only when a baseline was requested; New is true when it is zero.
+	BaselineCount *int64 `json:"baseline_count,omitempty"`
+	New           bool   `json:"new"`
+}
+
+// LogPatternsResult holds the most frequent patterns of a field over a time
+// range. TotalRows and TotalPatterns cover all patterns, not just the ones
+// returned.
+type LogPatternsResult struct {
+	Field         string       `json:"field"`
+	TotalRows     int64        `json:"total_rows"`
+	TotalPatterns int64        `json:"total_patterns"`
+	Patterns      []LogPattern `json:"patterns"`
+}
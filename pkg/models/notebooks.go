@@ -0,0 +1,234 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Notebook is a team-scoped analysis document: an ordered list of cells mixing
+// markdown narrative with query and histogram cells whose last results are
+// cached alongside them. The cells live in a single JSON blob (CellsJSON),
+// validated by ValidateNotebookCells before persistence.
+type Notebook struct {
+	ID          int             `json:"id" db:"id"`
+	TeamID      TeamID          `json:"team_id" db:"team_id"`
+	Name        string          `json:"name" db:"name"`
+	Description string          `json:"description" db:"description"`
+	CellsJSON   json.RawMessage `json:"cells,omitempty" db:"cells_json"`
+	CreatedBy   *UserID         `json:"created_by,omitempty" db:"created_by"`
+	Timestamps
+	// CreatedByName / CreatedByEmail identify the notebook's creator for
+	// display; empty when the author was deleted (created_by NULL).
+	CreatedByName  string `json:"created_by_name,omitempty" db:"-"`
+	CreatedByEmail string `json:"created_by_email,omitempty" db:"-"`
+	// CanEdit is a per-request UI authorization hint for the calling user.
+	// nil when not computed.
+	CanEdit *bool `json:"can_edit,omitempty" db:"-"`
+}
+
+// NotebookSnapshot is a rendered notebook report (see the notebook export
+// endpoint) kept in artifact storage under ObjectKey until ExpiresAt, so it
+// can be shared as a fixed record of an investigation.
+type NotebookSnapshot struct {
+	ID         string    `json:"id" db:"id"`
+	NotebookID int       `json:"notebook_id" db:"notebook_id"`
+	TeamID     TeamID    `json:"team_id" db:"team_id"`
+	Format     string    `json:"format" db:"format"`
+	FileName   string    `json:"file_name" db:"file_name"`
+	ObjectKey  string    `json:"-" db:"object_key"`
+	SizeBytes  int64     `json:"size_bytes" db:"size_bytes"`
+	CreatedBy  *UserID   `json:"created_by,omitempty" db:"created_by"`
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	// DownloadURL is the API path that serves the snapshot. Set per response.
+	DownloadURL string `json:"download_url,omitempty" db:"-"`
+}
+
+// CreateNotebookRequest is the body for creating a notebook.
+type CreateNotebookRequest struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Cells       json.RawMessage `json:"cells"`
+}
+
+// UpdateNotebookRequest is the body for replacing a notebook's mutable fields.
+// Cached results sent back by the client are ignored; a cell keeps its stored
+// result as long as its query definition is unchanged.
+type UpdateNotebookRequest struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Cells       json.RawMessage `json:"cells"`
+	// UpdatedAt is an optimistic-concurrency precondition, as for dashboards:
+	// when set, the update is rejected if the stored notebook has advanced
+	// past it. The zero value disables the check.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Notebook limits.
+const (
+	MaxNotebookCells         = 100
+	MaxNotebookCellSize      = 64 * 1024 // 64 KB of cell content.
+	MaxNotebookCellIDLength  = 64
+	DefaultNotebookCellLimit = 100
+	// MaxNotebookCachedRows caps the rows kept in a query cell's cached result
+	// so a wide query can't bloat the notebook row; the live run still returns
+	// everything up to the preview limit.
+	MaxNotebookCachedRows = 200
+)
+
+// NotebookCellType enumerates the kinds of cell a notebook may contain.
+type NotebookCellType string
+
+const (
+	NotebookCellMarkdown  NotebookCellType = "markdown"
+	NotebookCellQuery     NotebookCellType = "query"
+	NotebookCellHistogram NotebookCellType = "histogram"
+)
+
+// NotebookCell is one entry of a notebook. Markdown cells only use Content;
+// query and histogram cells hold the query in Content and execute it against
+// SourceID over the pinned [StartTime, EndTime) window, so re-running a
+// postmortem notebook reproduces the same evidence.
+type NotebookCell struct {
+	ID            string           `json:"id"`
+	Type          NotebookCellType `json:"type"`
+	Content       string           `json:"content"`
+	SourceID      SourceID         `json:"source_id,omitempty"`
+	QueryLanguage QueryLanguage    `json:"query_language,omitempty"`
+	StartTime     string           `json:"start_time,omitempty"` // RFC3339
+	EndTime       string           `json:"end_time,omitempty"`   // RFC3339
+	Timezone      string           `json:"timezone,omitempty"`
+	// Limit bounds a query cell's rows; 0 uses DefaultNotebookCellLimit.
+	Limit int `json:"limit,omitempty"`
+	// Window and GroupBy shape a histogram cell, as for the histogram endpoint.
+	Window  string `json:"window,omitempty"`
+	GroupBy string `json:"group_by,omitempty"`
+	// Notes is markdown commentary on what the cell's result shows, kept next
+	// to the evidence rather than in a separate markdown cell.
+	Notes string `json:"notes,omitempty"`
+	// Pinned freezes the cell's result as the sample the write-up refers to:
+	// once a pinned cell has a result, later runs return live results without
+	// replacing it, and its query can't change until it is unpinned.
+	Pinned bool `json:"pinned,omitempty"`
+	// Result is the cell's most recent server-side execution, or its pinned
+	// sample.
+	Result *NotebookCellResult `json:"result,omitempty"`
+}
+
+// NotebookCellResult is the cached outcome of running a query or histogram
+// cell. A failed run is cached too (Error set) so the document shows what
+// happened when it was last refreshed.
+type NotebookCellResult struct {
+	ExecutedAt time.Time        `json:"executed_at"`
+	Columns    []ColumnInfo     `json:"columns,omitempty"`
+	Rows       []map[string]any `json:"rows,omitempty"`
+	Stats      *QueryStats      `json:"stats,omitempty"`
+	// Histogram is the histogram payload (granularity + buckets) as returned
+	// by the histogram endpoint.
+	Histogram json.RawMessage `json:"histogram,omitempty"`
+	// RowsTruncated is set when Rows was capped to MaxNotebookCachedRows.
+	RowsTruncated bool   `json:"rows_truncated,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// IsExecutable reports whether the cell runs a query (query or histogram).
+func (c *NotebookCell) IsExecutable() bool {
+	return c.Type == NotebookCellQuery || c.Type == NotebookCellHistogram
+}
+
+// SameQuery reports whether two cells would execute the same query, i.e.
+// whether a cached result of one is still valid for the other.
+func (c *NotebookCell) SameQuery(other *NotebookCell) bool {
+	return c.Type == other.Type &&
+		c.Content == other.Content &&
+		c.SourceID == other.SourceID &&
+		NormalizeQueryLanguage(c.QueryLanguage) == NormalizeQueryLanguage(other.QueryLanguage) &&
+		c.StartTime == other.StartTime &&
+		c.EndTime == other.EndTime &&
+		c.Timezone == other.Timezone &&
+		c.Limit == other.Limit &&
+		c.Window == other.Window &&
+		c.GroupBy == other.GroupBy
+}
+
+// ParseNotebookCells decodes a stored or submitted cell blob.
+func ParseNotebookCells(raw json.RawMessage) ([]NotebookCell, error) {
+	var cells []NotebookCell
+	if err := json.Unmarshal(raw, &cells); err != nil {
+		return nil, fmt.Errorf("cells must be a JSON array of cells: %w", err)
+	}
+	return cells, nil
+}
+
+// ValidateNotebookCells checks a submitted cell list: unique non-empty ids,
+// known cell types, bounded content and notes, and for executable cells a
+// source, a supported language and a valid pinned time range.
+func ValidateNotebookCells(cells []NotebookCell) error {
+	if len(cells) > MaxNotebookCells {
+		return fmt.Errorf("a notebook can hold at most %d cells", MaxNotebookCells)
+	}
+	seen := make(map[string]struct{}, len(cells))
+	for i := range cells {
+		cell := &cells[i]
+		id := strings.TrimSpace(cell.ID)
+		if id == "" {
+			return fmt.Errorf("cell %d: id is required", i)
+		}
+		if len(id) > MaxNotebookCellIDLength {
+			return fmt.Errorf("cell %d: id must be at most %d characters", i, MaxNotebookCellIDLength)
+		}
+		if _, dup := seen[id]; dup {
+			return fmt.Errorf("cell %d: duplicate id %q", i, id)
+		}
+		seen[id] = struct{}{}
+		if len(cell.Content) > MaxNotebookCellSize {
+			return fmt.Errorf("cell %q: content exceeds %d bytes", id, MaxNotebookCellSize)
+		}
+		if len(cell.Notes) > MaxNotebookCellSize {
+			return fmt.Errorf("cell %q: notes exceed %d bytes", id, MaxNotebookCellSize)
+		}
+
+		switch cell.Type {
+		case NotebookCellMarkdown:
+			if cell.Pinned {
+				return fmt.Errorf("cell %q: only query and histogram cells can be pinned", id)
+			}
+			continue
+		case NotebookCellQuery, NotebookCellHistogram:
+		default:
+			return fmt.Errorf("cell %q: unknown type %q", id, cell.Type)
+		}
+
+		if strings.TrimSpace(cell.Content) == "" {
+			return fmt.Errorf("cell %q: query is required", id)
+		}
+		if cell.SourceID <= 0 {
+			return fmt.Errorf("cell %q: source_id is required", id)
+		}
+		if !NormalizeQueryLanguage(cell.QueryLanguage).Valid() {
+			return fmt.Errorf("cell %q: unsupported query_language %q", id, cell.QueryLanguage)
+		}
+		start, err := time.Parse(time.RFC3339, cell.StartTime)
+		if err != nil {
+			return fmt.Errorf("cell %q: start_time must be RFC3339", id)
+		}
+		end, err := time.Parse(time.RFC3339, cell.EndTime)
+		if err != nil {
+			return fmt.Errorf("cell %q: end_time must be RFC3339", id)
+		}
+		if !end.After(start) {
+			return fmt.Errorf("cell %q: end_time must be after start_time", id)
+		}
+		if cell.Timezone != "" {
+			if _, err := time.LoadLocation(cell.Timezone); err != nil {
+				return fmt.Errorf("cell %q: invalid timezone %q", id, cell.Timezone)
+			}
+		}
+		if cell.Limit < 0 {
+			return fmt.Errorf("cell %q: limit must not be negative", id)
+		}
+	}
+	return nil
+}
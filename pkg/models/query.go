@@ -2,6 +2,9 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
 	"time"
 )
 
@@ -96,8 +99,13 @@ type CacheDirective struct {
 // Variables in the SQL query (e.g., {{from_date}}) will be replaced with their values.
 type TemplateVariable struct {
 	Name  string `json:"name"`  // Variable name (without braces)
-	Type  string `json:"type"`  // "string", "text", "number", or "date"
+	Type  string `json:"type"`  // "string", "text", "number", "date", or "enum"
 	Value any    `json:"value"` // The value to substitute
+	// AllowedValues are the values an enum variable accepts.
+	AllowedValues []string `json:"allowed_values,omitempty"`
+	// SourceQueryID names a saved query whose first column lists an enum
+	// variable's allowed values; the server loads them itself.
+	SourceQueryID int `json:"source_query_id,omitempty"`
 }
 
 // APIQueryRequest represents the request payload for the standard log querying endpoint.
@@ -116,9 +124,50 @@ type APIQueryRequest struct {
 	// Cache opts this request into the dashboard result cache. Omitted for
 	// explorer/ad-hoc queries so they are never cached.
 	Cache *CacheDirective `json:"cache,omitempty"`
+	// Selection runs only the selected part of QueryText ("run selection" in the
+	// editor). QueryText stays the full editor content so the server can check
+	// the selection against its statement boundaries.
+	Selection *QuerySelection `json:"selection,omitempty"`
+	// DerivedColumns are extra result columns computed from each row, e.g.
+	// {"name": "duration_s", "expression": "duration_ms / 1000"}. ClickHouse only.
+	DerivedColumns []DerivedColumn `json:"derived_columns,omitempty"`
+	// IncludeCount adds total_count, the number of rows the query matches
+	// without its limit, to the response when it can be counted in time.
+	IncludeCount bool `json:"include_count,omitempty"`
+	// Sample, between 0 and 1, runs the query over roughly that fraction of
+	// rows so wide time ranges return quickly. ClickHouse only.
+	Sample float64 `json:"sample,omitempty"`
+	// Dedupe collapses consecutive identical rows into one with a repeat
+	// count; see DedupeOptions. The limit applies before collapsing.
+	Dedupe *DedupeOptions `json:"dedupe,omitempty"`
+	// Distributed routes the query to one shard of a Distributed-table
+	// source or sets prefer_localhost_replica; see DistributedRouting.
+	Distributed *DistributedRouting `json:"distributed,omitempty"`
 	// Sort and other general query params could be added here if needed later.
 }
 
+// DerivedColumn is a client-defined result column. Expression may only use
+// schema columns, literals and an allow-list of scalar functions and operators.
+type DerivedColumn struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+// QuerySelection is an editor selection within a query's text. Start and End
+// are character (code point) offsets, with End exclusive.
+type QuerySelection struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// ExecutedStatement identifies the statement a "run selection" request
+// executed. Index is the statement's zero-based position among the
+// semicolon-delimited statements of the full editor content.
+type ExecutedStatement struct {
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+}
+
 // APIHistogramRequest represents the request payload for the histogram endpoint.
 type APIHistogramRequest struct {
 	StartTimestamp int64  `json:"start_timestamp,omitempty"` // Legacy - Unix timestamp in milliseconds
@@ -127,9 +176,12 @@ type APIHistogramRequest struct {
 	EndTime        string `json:"end_time,omitempty"`        // ISO8601/RFC3339 time range end
 	Limit          int    `json:"limit"`                     // Limit might influence histogram sampling/performance
 	QueryText      string `json:"query_text"`                // Contains non-time filters
-	Window         string `json:"window,omitempty"`          // For histogram queries: time window size like "1m", "5m", "1h"
+	Window         string `json:"window,omitempty"`          // For histogram queries: time window size like "1m", "5m", "1h", or "auto"
 	GroupBy        string `json:"group_by,omitempty"`        // For histogram queries: field to group by
 	Timezone       string `json:"timezone,omitempty"`        // Kept for histogram, optional otherwise
+	// SeverityBreakdown groups buckets by the source's severity field when no
+	// GroupBy is given, returning one stacked series per level.
+	SeverityBreakdown bool `json:"severity_breakdown,omitempty"`
 	// Variables for template substitution in the query text.
 	Variables []TemplateVariable `json:"variables,omitempty"`
 	// Query execution timeout in seconds. If not specified, uses default timeout.
@@ -156,6 +208,35 @@ type LogContextRequest struct {
 	BeforeOffset    int      `json:"before_offset"`    // Offset for before query (for pagination)
 	AfterOffset     int      `json:"after_offset"`     // Offset for after query (for pagination)
 	ExcludeBoundary bool     `json:"exclude_boundary"` // When true, excludes logs at exact timestamp (for pagination)
+	Stream          string   `json:"stream,omitempty"` // Optional `_stream` of the target row (VictoriaLogs) to scope context to
+	// Scope optionally narrows context to rows sharing the target row's values
+	// for some fields, e.g. {"service_name": "api", "pod": "api-7f9c"}, so it
+	// shows one stream rather than interleaved logs from every emitter.
+	Scope LogContextScope `json:"scope,omitempty"`
+}
+
+// MaxLogContextScopeFields caps the fields a log context request is scoped on.
+const MaxLogContextScopeFields = 8
+
+// LogContextScope maps field names to the values surrounding logs must have.
+type LogContextScope map[string]string
+
+// Validate checks the scope names plain columns.
+func (s LogContextScope) Validate() error {
+	if len(s) > MaxLogContextScopeFields {
+		return fmt.Errorf("log context can be scoped on at most %d fields", MaxLogContextScopeFields)
+	}
+	for field := range s {
+		if !extractionNameRe.MatchString(field) {
+			return fmt.Errorf("invalid scope field %q: use letters, digits and underscores (max 64)", field)
+		}
+	}
+	return nil
+}
+
+// Fields returns the scope's field names in sorted order.
+func (s LogContextScope) Fields() []string {
+	return slices.Sorted(maps.Keys(s))
 }
 
 // LogContextResponse represents temporal context query results
@@ -274,6 +355,9 @@ type SavedQueryVariable struct {
 	IsOptional   bool                       `json:"isOptional,omitempty"`
 	IsRequired   bool                       `json:"isRequired,omitempty"`
 	Options      []SavedQueryVariableOption `json:"options,omitempty"`
+	// SourceQueryID names a saved query whose first column lists the choices
+	// of an enum variable, in place of Options.
+	SourceQueryID int `json:"sourceQueryId,omitempty"`
 }
 
 type SavedQueryContent struct {
@@ -381,7 +465,7 @@ type QueryShare struct {
 	TeamID         *TeamID         `json:"team_id,omitempty" db:"team_id"`
 	CreatedBy      UserID          `json:"created_by" db:"created_by"`
 	Payload        json.RawMessage `json:"payload" db:"payload_json"`
-	ExpiresAt      time.Time       `json:"expires_at" db:"expires_at"`
+	ExpiresAt      *time.Time      `json:"expires_at,omitempty" db:"expires_at"` // nil for a permanent link
 	LastAccessedAt *time.Time      `json:"last_accessed_at,omitempty" db:"last_accessed_at"`
 	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
 	CreatedByEmail string          `json:"created_by_email,omitempty"`
@@ -397,12 +481,16 @@ type QuerySharePayload struct {
 	TimeRange SavedQueryTimeRange  `json:"time_range"`
 	Timezone  string               `json:"timezone,omitempty"`
 	Variables []SavedQueryVariable `json:"variables,omitempty"`
+	// Columns are the columns shown in the explorer, in order; empty means all.
+	Columns []string `json:"columns,omitempty"`
 }
 
 // CreateQueryShareRequest creates a short share token for a query payload.
 type CreateQueryShareRequest struct {
 	Payload          json.RawMessage `json:"payload"`
 	ExpiresInSeconds int             `json:"expires_in_seconds,omitempty"`
+	// Permanent creates a link that never expires, if the server allows it.
+	Permanent bool `json:"permanent,omitempty"`
 }
 
 // QueryShareResponse is returned for create and read operations.
@@ -412,7 +500,7 @@ type QueryShareResponse struct {
 	SourceID  SourceID        `json:"source_id"`
 	TeamID    *TeamID         `json:"team_id,omitempty"`
 	Payload   json.RawMessage `json:"payload"`
-	ExpiresAt time.Time       `json:"expires_at"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
 	CreatedAt time.Time       `json:"created_at"`
 	CreatedBy UserID          `json:"created_by"`
 }
@@ -437,6 +525,10 @@ type CreateExportJobRequest struct {
 	Limit        int                `json:"limit,omitempty"`
 	QueryTimeout *int               `json:"query_timeout,omitempty"`
 	Variables    []TemplateVariable `json:"variables,omitempty"`
+	// StartTime and EndTime (RFC3339) bound a query without a timestamp
+	// condition when query.require_time_bound is set.
+	StartTime string `json:"start_time,omitempty"`
+	EndTime   string `json:"end_time,omitempty"`
 }
 
 // ExportJob stores an async export request and its eventual artifact metadata.
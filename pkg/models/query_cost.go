@@ -0,0 +1,60 @@
+package models
+
+import "fmt"
+
+// QueryCostLimits caps how much data a single ClickHouse SQL query may read.
+// A zero cap means unlimited.
+type QueryCostLimits struct {
+	MaxRowsToRead  uint64
+	MaxBytesToRead uint64
+	// Explain runs EXPLAIN ESTIMATE first and rejects a query whose estimate
+	// exceeds a cap, before it touches any data.
+	Explain bool
+	// Enforce sends the caps as max_rows_to_read / max_bytes_to_read, so
+	// ClickHouse aborts a query that reads past them while running.
+	Enforce bool
+}
+
+// IsZero reports whether the limits cap nothing.
+func (l *QueryCostLimits) IsZero() bool {
+	return l == nil || (l.MaxRowsToRead == 0 && l.MaxBytesToRead == 0)
+}
+
+// QueryCostEstimate is ClickHouse's estimate of what a query would read.
+// Bytes are uncompressed, derived from each table's average row size.
+type QueryCostEstimate struct {
+	Rows  uint64 `json:"rows"`
+	Bytes uint64 `json:"bytes"`
+	Parts uint64 `json:"parts"`
+	Marks uint64 `json:"marks"`
+}
+
+// QueryCostError rejects a query whose estimated scan exceeds the caller's
+// limits. Limit names the cap that tripped: "rows" or "bytes".
+type QueryCostError struct {
+	Limit     string            `json:"limit"`
+	Max       uint64            `json:"max"`
+	Estimated QueryCostEstimate `json:"estimated"`
+}
+
+func (e *QueryCostError) Error() string {
+	estimated := e.Estimated.Rows
+	if e.Limit == "bytes" {
+		estimated = e.Estimated.Bytes
+	}
+	return fmt.Sprintf("query would read about %d %s, over the limit of %d; narrow the time range or add filters", estimated, e.Limit, e.Max)
+}
+
+// Check returns a *QueryCostError when the estimate exceeds a cap.
+func (l *QueryCostLimits) Check(estimate QueryCostEstimate) error {
+	if l == nil {
+		return nil
+	}
+	if l.MaxRowsToRead > 0 && estimate.Rows > l.MaxRowsToRead {
+		return &QueryCostError{Limit: "rows", Max: l.MaxRowsToRead, Estimated: estimate}
+	}
+	if l.MaxBytesToRead > 0 && estimate.Bytes > l.MaxBytesToRead {
+		return &QueryCostError{Limit: "bytes", Max: l.MaxBytesToRead, Estimated: estimate}
+	}
+	return nil
+}
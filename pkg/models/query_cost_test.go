@@ -0,0 +1,29 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQueryCostLimitsCheck(t *testing.T) {
+	t.Parallel()
+
+	limits := &QueryCostLimits{MaxRowsToRead: 1000, MaxBytesToRead: 1 << 20}
+	if err := limits.Check(QueryCostEstimate{Rows: 1000, Bytes: 1 << 20}); err != nil {
+		t.Fatalf("estimate at the caps: %v", err)
+	}
+
+	err := limits.Check(QueryCostEstimate{Rows: 10, Bytes: 2 << 20})
+	var costErr *QueryCostError
+	if !errors.As(err, &costErr) || costErr.Limit != "bytes" || costErr.Max != 1<<20 {
+		t.Fatalf("over the byte cap: %v", err)
+	}
+	if err := limits.Check(QueryCostEstimate{Rows: 5000}); !errors.As(err, &costErr) || costErr.Limit != "rows" {
+		t.Fatalf("over the row cap: %v", err)
+	}
+
+	var none *QueryCostLimits
+	if !none.IsZero() || none.Check(QueryCostEstimate{Rows: 1 << 40}) != nil {
+		t.Error("nil limits must cap nothing")
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import "fmt"
+
+// MaxDedupeFields caps the fields a dedupe request compares.
+const MaxDedupeFields = 32
+
+// DedupeCountColumn is the column a deduped result adds, holding how many
+// consecutive identical rows each returned row stands for.
+const DedupeCountColumn = "_repeat_count"
+
+// DedupeOptions collapses runs of consecutive identical rows in a query's
+// result into their first row plus a repeat count, to make noisy logs
+// readable. Rows are identical when they agree on Fields; without Fields they
+// must agree on every column but the source's timestamp.
+// For example {"fields": ["msg", "level"]}.
+type DedupeOptions struct {
+	Fields []string `json:"fields,omitempty"`
+}
+
+// Validate checks the fields are plain, unique column names.
+func (d *DedupeOptions) Validate() error {
+	if len(d.Fields) > MaxDedupeFields {
+		return fmt.Errorf("dedupe can compare at most %d fields", MaxDedupeFields)
+	}
+	seen := make(map[string]struct{}, len(d.Fields))
+	for _, field := range d.Fields {
+		if !extractionNameRe.MatchString(field) {
+			return fmt.Errorf("invalid dedupe field %q: use letters, digits and underscores (max 64)", field)
+		}
+		if _, dup := seen[field]; dup {
+			return fmt.Errorf("duplicate dedupe field %q", field)
+		}
+		seen[field] = struct{}{}
+	}
+	return nil
+}
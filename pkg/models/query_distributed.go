@@ -0,0 +1,27 @@
+package models
+
+import "fmt"
+
+// DistributedRouting steers a query on a source whose table is Distributed,
+// to debug data skew across a cluster. Shard, when set, reads only that
+// shard's local table (shard_num in system.clusters, counted from 1);
+// PreferLocalhostReplica sets ClickHouse's prefer_localhost_replica, i.e.
+// whether the connected server answers for its own shard instead of asking
+// another replica. ClickHouse only.
+// For example {"shard": 2, "prefer_localhost_replica": false}.
+type DistributedRouting struct {
+	Shard                  int   `json:"shard,omitempty"`
+	PreferLocalhostReplica *bool `json:"prefer_localhost_replica,omitempty"`
+}
+
+// Validate checks the routing asks for something and the shard is a
+// shard number.
+func (d *DistributedRouting) Validate() error {
+	if d.Shard < 0 {
+		return fmt.Errorf("shard must be a shard number from 1, got %d", d.Shard)
+	}
+	if d.Shard == 0 && d.PreferLocalhostReplica == nil {
+		return fmt.Errorf("distributed routing needs a shard or prefer_localhost_replica")
+	}
+	return nil
+}
@@ -2,32 +2,59 @@ package models
 
 import "time"
 
-// QueryHistoryPerUserCap bounds how many query-history rows are retained per
-// user. On each successful record the store prunes anything beyond the newest
-// cap for that user, so history stays bounded without a separate sweep.
-const QueryHistoryPerUserCap = 200
-
-// QueryHistoryDefaultLimit / QueryHistoryMaxLimit bound the list endpoint's
+// QueryHistoryDefaultLimit / QueryHistoryMaxLimit bound the list endpoints'
 // page size.
 const (
 	QueryHistoryDefaultLimit = 50
-	QueryHistoryMaxLimit     = QueryHistoryPerUserCap
+	QueryHistoryMaxLimit     = 200
+)
+
+// QueryHistoryStatus is the outcome of a recorded query.
+type QueryHistoryStatus string
+
+const (
+	QueryHistoryStatusSuccess QueryHistoryStatus = "success"
+	QueryHistoryStatusError   QueryHistoryStatus = "error"
 )
 
 // QueryHistory is one persisted record of a query a user executed against a
 // source, captured on the preview execution paths. It survives across machines
 // (unlike the old localStorage-only history) so it can back a server-side
-// history panel and, later, the CLI/MCP.
+// history panel and, later, the CLI/MCP. Failed queries are recorded too, with
+// Status "error" and the failure in ErrorMessage.
 type QueryHistory struct {
-	ID            int64         `json:"id" db:"id"`
-	UserID        UserID        `json:"user_id" db:"user_id"`
-	TeamID        TeamID        `json:"team_id" db:"team_id"`
-	SourceID      SourceID      `json:"source_id" db:"source_id"`
-	QueryText     string        `json:"query_text" db:"query_text"`
-	QueryLanguage QueryLanguage `json:"query_language" db:"query_language"`
-	DurationMs    int64         `json:"duration_ms" db:"duration_ms"`
-	RowCount      int64         `json:"row_count" db:"row_count"`
-	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
+	ID            int64              `json:"id" db:"id"`
+	UserID        UserID             `json:"user_id" db:"user_id"`
+	TeamID        TeamID             `json:"team_id" db:"team_id"`
+	SourceID      SourceID           `json:"source_id" db:"source_id"`
+	QueryText     string             `json:"query_text" db:"query_text"`
+	QueryLanguage QueryLanguage      `json:"query_language" db:"query_language"`
+	DurationMs    int64              `json:"duration_ms" db:"duration_ms"`
+	RowCount      int64              `json:"row_count" db:"row_count"`
+	Status        QueryHistoryStatus `json:"status" db:"status"`
+	ErrorMessage  string             `json:"error_message,omitempty" db:"error_message"`
+	CreatedAt     time.Time          `json:"created_at" db:"created_at"`
+	// UserEmail and SourceName are filled in when listing; SourceName is empty
+	// once the source is deleted.
+	UserEmail  string `json:"user_email,omitempty" db:"-"`
+	SourceName string `json:"source_name,omitempty" db:"-"`
+	// RowsRead is what a successful query scanned. It feeds the daily usage
+	// rollup behind team quotas and is not stored with the entry.
+	RowsRead int64 `json:"-" db:"-"`
+}
+
+// QueryHistoryFilter selects query history entries. Unset fields match all
+// entries; Search matches query text, ignoring case, and Since keeps entries
+// recorded at or after it.
+type QueryHistoryFilter struct {
+	UserID   *UserID
+	TeamID   *TeamID
+	SourceID *SourceID
+	Status   QueryHistoryStatus
+	Search   string
+	Since    *time.Time
+	Limit    int
+	Offset   int
 }
 
 // QueryActivityRecord is one query_history row enriched with the executing
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Query snippet limits.
+const (
+	MaxQuerySnippetNameLength        = 128
+	MaxQuerySnippetDescriptionLength = 1000
+	MaxQuerySnippetQueryLength       = 4000
+)
+
+// QuerySnippet is a named, reusable LogchefQL filter in a team's library, such
+// as `namespace="prod" and severity_text in ("error","fatal")`. The query
+// editor offers snippets for insertion through autocomplete.
+type QuerySnippet struct {
+	ID          QuerySnippetID `json:"id"`
+	TeamID      TeamID         `json:"team_id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Query       string         `json:"query"`
+	CreatedBy   *UserID        `json:"created_by,omitempty"`
+	Timestamps
+}
+
+// QuerySnippetRequest is the body for creating or replacing a snippet.
+type QuerySnippetRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Query       string `json:"query"`
+}
+
+// Validate trims the snippet's fields and checks their lengths. It does not
+// parse the query; that is left to the LogchefQL validator.
+func (s *QuerySnippet) Validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Description = strings.TrimSpace(s.Description)
+	s.Query = strings.TrimSpace(s.Query)
+	if s.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(s.Name) > MaxQuerySnippetNameLength {
+		return fmt.Errorf("name must be at most %d characters", MaxQuerySnippetNameLength)
+	}
+	if len(s.Description) > MaxQuerySnippetDescriptionLength {
+		return fmt.Errorf("description must be at most %d characters", MaxQuerySnippetDescriptionLength)
+	}
+	if s.Query == "" {
+		return fmt.Errorf("query is required")
+	}
+	if len(s.Query) > MaxQuerySnippetQueryLength {
+		return fmt.Errorf("query must be at most %d characters", MaxQuerySnippetQueryLength)
+	}
+	return nil
+}
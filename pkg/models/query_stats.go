@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // query_stats_daily is an authoritative, non-pruned daily rollup of executed
 // queries, incremented at record time. Unlike QueryHistory (capped per user, so
 // a recent window only), it backs correct all-time usage analytics. The types
@@ -29,3 +31,36 @@ type DailyQueryVolume struct {
 	Date       string `json:"date"`
 	QueryCount int64  `json:"query_count"`
 }
+
+// TeamQueryUsage is a team's successful queries and the rows they read on one
+// day of the rollup. Date is 'YYYY-MM-DD' (UTC).
+type TeamQueryUsage struct {
+	Date     string `json:"date"`
+	Queries  int64  `json:"queries"`
+	RowsRead int64  `json:"rows_read"`
+}
+
+// TeamQuota caps a team's query usage per UTC day. A zero cap is unlimited.
+type TeamQuota struct {
+	MaxQueriesPerDay  int64 `json:"max_queries_per_day"`
+	MaxRowsReadPerDay int64 `json:"max_rows_read_per_day"`
+}
+
+// IsZero reports whether the quota caps nothing.
+func (q TeamQuota) IsZero() bool {
+	return q.MaxQueriesPerDay <= 0 && q.MaxRowsReadPerDay <= 0
+}
+
+// TeamUsage is a team's usage today against its quota. The remaining counts
+// are nil when the matching cap is unlimited and never go below zero.
+type TeamUsage struct {
+	TeamQueryUsage
+	TeamID            TeamID    `json:"team_id"`
+	Limits            TeamQuota `json:"limits"`
+	RemainingQueries  *int64    `json:"remaining_queries"`
+	RemainingRowsRead *int64    `json:"remaining_rows_read"`
+	// Exceeded is set once either cap is reached; queries are then rejected
+	// until ResetsAt, the next UTC midnight.
+	Exceeded bool      `json:"exceeded"`
+	ResetsAt time.Time `json:"resets_at"`
+}
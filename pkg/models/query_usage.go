@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// QueryUsageReport aggregates the query history recorded since Since into
+// usage stats for capacity planning. History is capped per user, so very
+// active users may be under-counted over long windows.
+type QueryUsageReport struct {
+	Since       time.Time `json:"since"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Days        int       `json:"days"`
+	// TotalQueries and FailedQueries count every entry scanned in the window.
+	TotalQueries  int `json:"total_queries"`
+	FailedQueries int `json:"failed_queries"`
+	// SlowQueries counts entries that ran for at least SlowQueryThresholdMs.
+	SlowQueryThresholdMs int64 `json:"slow_query_threshold_ms"`
+	SlowQueries          int   `json:"slow_queries"`
+	// Truncated is set when the window held more entries than a report scans;
+	// the counts then cover only the newest entries.
+	Truncated bool `json:"truncated"`
+
+	TopUsers       []QueryUsageUser   `json:"top_users"`
+	TopSources     []QueryUsageSource `json:"top_sources"`
+	SlowestQueries []*QueryHistory    `json:"slowest_queries"`
+	TopFields      []QueryFieldUsage  `json:"top_fields"`
+}
+
+// QueryUsageUser is one user's share of the queries in a usage report.
+type QueryUsageUser struct {
+	UserID          UserID `json:"user_id"`
+	UserEmail       string `json:"user_email"`
+	Queries         int    `json:"queries"`
+	FailedQueries   int    `json:"failed_queries"`
+	TotalDurationMs int64  `json:"total_duration_ms"`
+}
+
+// QueryUsageSource is one source's share of the queries in a usage report.
+// SourceName is empty once the source is deleted.
+type QueryUsageSource struct {
+	SourceID        SourceID `json:"source_id"`
+	SourceName      string   `json:"source_name"`
+	Queries         int      `json:"queries"`
+	FailedQueries   int      `json:"failed_queries"`
+	TotalDurationMs int64    `json:"total_duration_ms"`
+	AvgDurationMs   int64    `json:"avg_duration_ms"`
+	P95DurationMs   int64    `json:"p95_duration_ms"`
+	RowsReturned    int64    `json:"rows_returned"`
+}
+
+// QueryFieldUsage counts the queries that filtered a source on a field.
+type QueryFieldUsage struct {
+	SourceID   SourceID `json:"source_id"`
+	SourceName string   `json:"source_name"`
+	Field      string   `json:"field"`
+	Queries    int      `json:"queries"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// Range comparison defaults.
+const (
+	DefaultRangeComparisonThreshold = 100.0 // percent, i.e. a value's share doubles or halves
+	DefaultRangeComparisonLimit     = 20
+	MaxRangeComparisonLimit         = 100
+	// minShareChange is the smallest change of a value's share, in percentage
+	// points, that counts, so a value going from 0.01% to 0.03% of rows isn't
+	// reported as tripled.
+	minShareChange = 1.0
+)
+
+// ValueChangeKind names how a field value's frequency changed between the
+// baseline and target ranges.
+type ValueChangeKind string
+
+const (
+	ValueAppeared    ValueChangeKind = "appeared"
+	ValueDisappeared ValueChangeKind = "disappeared"
+	ValueIncreased   ValueChangeKind = "increased"
+	ValueDecreased   ValueChangeKind = "decreased"
+)
+
+// ValueChange is one field value's row count in both ranges. The percents
+// are shares of each range's rows.
+type ValueChange struct {
+	Value           string          `json:"value"`
+	BaselineCount   int64           `json:"baseline_count"`
+	TargetCount     int64           `json:"target_count"`
+	BaselinePercent float64         `json:"baseline_percent"`
+	TargetPercent   float64         `json:"target_percent"`
+	Change          ValueChangeKind `json:"change"`
+}
+
+// FieldDiff lists the values of one field whose frequency changed
+// significantly, largest share change first.
+type FieldDiff struct {
+	Field   string        `json:"field"`
+	Changes []ValueChange `json:"changes"`
+}
+
+// CompareWindow is one of the compared time ranges and the rows it matched.
+type CompareWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Rows  int64     `json:"rows"`
+}
+
+// RangeComparison diffs the value distributions of a source's fields between
+// a baseline range and a target range.
+type RangeComparison struct {
+	ThresholdPercent float64       `json:"threshold_percent"`
+	Baseline         CompareWindow `json:"baseline"`
+	Target           CompareWindow `json:"target"`
+	Fields           []FieldDiff   `json:"fields"`
+}
+
+// DiffValues fills in the shares of values counted over baseline and target
+// rows and keeps the significant changes. A value present in only one range
+// appeared or disappeared; otherwise its share must grow or shrink by the
+// ratio 1 + thresholdPercent/100 and by at least one percentage point.
+func DiffValues(values []ValueChange, baselineRows, targetRows int64, thresholdPercent float64) []ValueChange {
+	share := func(count, rows int64) float64 {
+		if rows == 0 {
+			return 0
+		}
+		return float64(count) * 100 / float64(rows)
+	}
+	ratio := 1 + thresholdPercent/100
+
+	changes := []ValueChange{}
+	for _, v := range values {
+		v.BaselinePercent = share(v.BaselineCount, baselineRows)
+		v.TargetPercent = share(v.TargetCount, targetRows)
+		switch {
+		case v.BaselineCount == 0 && v.TargetCount > 0:
+			v.Change = ValueAppeared
+		case v.TargetCount == 0 && v.BaselineCount > 0:
+			v.Change = ValueDisappeared
+		case v.TargetPercent-v.BaselinePercent >= minShareChange && v.TargetPercent >= v.BaselinePercent*ratio:
+			v.Change = ValueIncreased
+		case v.BaselinePercent-v.TargetPercent >= minShareChange && v.TargetPercent*ratio <= v.BaselinePercent:
+			v.Change = ValueDecreased
+		default:
+			continue
+		}
+		changes = append(changes, v)
+	}
+	return changes
+}
@@ -0,0 +1,34 @@
+package models
+
+import "testing"
+
+func TestDiffValues(t *testing.T) {
+	values := []ValueChange{
+		{Value: "200", BaselineCount: 900, TargetCount: 400},
+		{Value: "500", BaselineCount: 50, TargetCount: 400},
+		{Value: "v2", BaselineCount: 0, TargetCount: 100},
+		{Value: "v1", BaselineCount: 50, TargetCount: 0},
+		{Value: "404", BaselineCount: 4, TargetCount: 12}, // tripled, but only 0.4% to 1.2%
+		{Value: "301", BaselineCount: 100, TargetCount: 100},
+	}
+	got := DiffValues(values, 1000, 1000, DefaultRangeComparisonThreshold)
+	want := map[string]ValueChangeKind{"200": ValueDecreased, "500": ValueIncreased, "v2": ValueAppeared, "v1": ValueDisappeared}
+	if len(got) != len(want) {
+		t.Fatalf("DiffValues() = %+v, want %d changes", got, len(want))
+	}
+	for _, v := range got {
+		if want[v.Value] != v.Change {
+			t.Errorf("value %q: change = %q, want %q", v.Value, v.Change, want[v.Value])
+		}
+	}
+	if got[1].BaselinePercent != 5 || got[1].TargetPercent != 40 {
+		t.Errorf("500 shares = %v%%, %v%%; want 5 and 40", got[1].BaselinePercent, got[1].TargetPercent)
+	}
+
+	// Shares account for ranges of different sizes: the same count in a
+	// range with twice the rows is half the share.
+	got = DiffValues([]ValueChange{{Value: "x", BaselineCount: 100, TargetCount: 100}}, 1000, 2000, 50)
+	if len(got) != 1 || got[0].Change != ValueDecreased {
+		t.Errorf("DiffValues() across uneven ranges = %+v, want x decreased", got)
+	}
+}
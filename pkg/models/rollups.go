@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// SourceRollup is a source's opt-in hourly rollup: per-hour log counts by
+// severity and by one configured dimension column, materialised into a small
+// ClickHouse table next to the source table. Long-range trend requests are
+// answered from it instead of scanning raw logs.
+type SourceRollup struct {
+	SourceID SourceID `json:"source_id"`
+	// Dimension is the column counted alongside severity; empty rolls up by
+	// severity only. It should be low-cardinality (service, host, ...).
+	Dimension string `json:"dimension"`
+	// RolledUpFrom is the start of the first materialised hour and
+	// RolledUpUntil the (exclusive) end of the last one. Both are nil until the
+	// first successful run.
+	RolledUpFrom  *time.Time `json:"rolled_up_from,omitempty"`
+	RolledUpUntil *time.Time `json:"rolled_up_until,omitempty"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	// LastError is the failure of the most recent run; empty once a run succeeds.
+	LastError string `json:"last_error,omitempty"`
+	Timestamps
+}
+
+// Covers reports whether the materialised hours include [start, end).
+func (r *SourceRollup) Covers(start, end time.Time) bool {
+	if r.RolledUpFrom == nil || r.RolledUpUntil == nil {
+		return false
+	}
+	return !start.Before(*r.RolledUpFrom) && !end.After(*r.RolledUpUntil)
+}
+
+// UpdateSourceRollupRequest enables (or reconfigures) a source's rollup.
+// Changing the dimension discards the rolled-up hours and backfills again.
+type UpdateSourceRollupRequest struct {
+	Dimension string `json:"dimension"`
+}
+
+// TrendGroupBy selects the series a trend is split into.
+type TrendGroupBy string
+
+const (
+	TrendGroupByNone      TrendGroupBy = ""
+	TrendGroupBySeverity  TrendGroupBy = "severity"
+	TrendGroupByDimension TrendGroupBy = "dimension"
+)
+
+// trendWindows are the bucket sizes a trend supports. They are whole hours so
+// rollup buckets aggregate into them exactly.
+var trendWindows = map[string]int{"1h": 1, "3h": 3, "6h": 6, "12h": 12, "24h": 24}
+
+// TrendWindowHours returns the bucket size of window in hours.
+func TrendWindowHours(window string) (int, error) {
+	hours, ok := trendWindows[window]
+	if !ok {
+		return 0, fmt.Errorf("invalid trend window %q (use 1h, 3h, 6h, 12h or 24h)", window)
+	}
+	return hours, nil
+}
+
+// DefaultTrendWindow picks a bucket size that keeps a trend over span at a
+// few hundred points at most.
+func DefaultTrendWindow(span time.Duration) string {
+	switch {
+	case span <= 2*24*time.Hour:
+		return "1h"
+	case span <= 14*24*time.Hour:
+		return "6h"
+	default:
+		return "24h"
+	}
+}
+
+// TrendBucket is one point of a trend series.
+type TrendBucket struct {
+	Bucket     time.Time `json:"bucket"`
+	LogCount   int64     `json:"log_count"`
+	GroupValue string    `json:"group_value,omitempty"`
+}
+
+// TrendResult is a log-volume trend over a time range. FromRollup reports
+// whether the bulk of the range was answered from the source's rollup.
+type TrendResult struct {
+	Granularity string        `json:"granularity"`
+	GroupBy     TrendGroupBy  `json:"group_by,omitempty"`
+	Data        []TrendBucket `json:"data"`
+	FromRollup  bool          `json:"from_rollup"`
+}
@@ -13,6 +13,37 @@ type QueryResult struct {
 	Warnings []QueryWarning   `json:"warnings,omitempty"`
 }
 
+// JSONField is one flattened key found in a column's JSON values.
+type JSONField struct {
+	Column string   `json:"column"`
+	Path   []string `json:"path"`
+	// Field is the LogchefQL reference, e.g. body.user.id.
+	Field string `json:"field"`
+	// Type is the most common type seen; Types lists all of them, most
+	// common first.
+	Type  string   `json:"type"`
+	Types []string `json:"types"`
+	// Count is the number of sampled rows holding the key, and Frequency
+	// that count as a fraction of all sampled rows.
+	Count     int64   `json:"count"`
+	Frequency float64 `json:"frequency"`
+}
+
+// JSONColumnSummary reports how many sampled rows of a column held JSON.
+type JSONColumnSummary struct {
+	Column   string `json:"column"`
+	JSONRows int64  `json:"json_rows"`
+}
+
+// JSONFieldsResult holds the keys discovered in a sample. Truncated is set
+// when more keys were found than are returned; the most frequent are kept.
+type JSONFieldsResult struct {
+	SampledRows int64               `json:"sampled_rows"`
+	Columns     []JSONColumnSummary `json:"columns"`
+	Fields      []JSONField         `json:"fields"`
+	Truncated   bool                `json:"truncated"`
+}
+
 // Schema Constants
 const (
 	// OTELLogsTableSchema is the schema for OpenTelemetry logs
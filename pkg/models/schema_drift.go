@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// SchemaChangeKind is the kind of a column change between two schema snapshots.
+type SchemaChangeKind string
+
+const (
+	SchemaChangeAdded       SchemaChangeKind = "added"
+	SchemaChangeDropped     SchemaChangeKind = "dropped"
+	SchemaChangeTypeChanged SchemaChangeKind = "type_changed"
+)
+
+// SchemaChange is one column's change between two schema snapshots. OldType is
+// empty for added columns and NewType for dropped ones.
+type SchemaChange struct {
+	Kind    SchemaChangeKind `json:"kind"`
+	Column  string           `json:"column"`
+	OldType string           `json:"old_type,omitempty"`
+	NewType string           `json:"new_type,omitempty"`
+}
+
+// SourceSchemaSnapshot is a source's column list as seen by the schema drift
+// scheduler. A snapshot is only recorded when the columns changed, so Changes
+// (the diff against the previous snapshot) is empty only for a source's first.
+type SourceSchemaSnapshot struct {
+	ID         int64          `json:"id"`
+	SourceID   SourceID       `json:"source_id"`
+	Columns    []ColumnInfo   `json:"columns"`
+	Changes    []SchemaChange `json:"changes"`
+	CapturedAt time.Time      `json:"captured_at"`
+}
+
+// DiffSchemas compares two column lists by name. Dropped columns come first in
+// their old order, then added and retyped columns in their new order.
+// Descriptions and column order are not compared.
+func DiffSchemas(previous, current []ColumnInfo) []SchemaChange {
+	currentTypes := make(map[string]string, len(current))
+	for _, col := range current {
+		currentTypes[col.Name] = col.Type
+	}
+	previousTypes := make(map[string]string, len(previous))
+	for _, col := range previous {
+		previousTypes[col.Name] = col.Type
+	}
+
+	changes := []SchemaChange{}
+	for _, col := range previous {
+		if _, ok := currentTypes[col.Name]; !ok {
+			changes = append(changes, SchemaChange{Kind: SchemaChangeDropped, Column: col.Name, OldType: col.Type})
+		}
+	}
+	for _, col := range current {
+		oldType, ok := previousTypes[col.Name]
+		switch {
+		case !ok:
+			changes = append(changes, SchemaChange{Kind: SchemaChangeAdded, Column: col.Name, NewType: col.Type})
+		case oldType != col.Type:
+			changes = append(changes, SchemaChange{Kind: SchemaChangeTypeChanged, Column: col.Name, OldType: oldType, NewType: col.Type})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	previous := []ColumnInfo{
+		{Name: "timestamp", Type: "DateTime64(3)"},
+		{Name: "msg", Type: "String"},
+		{Name: "status", Type: "UInt16"},
+		{Name: "legacy", Type: "String"},
+	}
+	current := []ColumnInfo{
+		{Name: "timestamp", Type: "DateTime64(3)", Description: "event time"},
+		{Name: "status", Type: "UInt32"},
+		{Name: "msg", Type: "String"},
+		{Name: "trace_id", Type: "String"},
+	}
+
+	want := []SchemaChange{
+		{Kind: SchemaChangeDropped, Column: "legacy", OldType: "String"},
+		{Kind: SchemaChangeTypeChanged, Column: "status", OldType: "UInt16", NewType: "UInt32"},
+		{Kind: SchemaChangeAdded, Column: "trace_id", NewType: "String"},
+	}
+	if got := DiffSchemas(previous, current); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffSchemas = %+v, want %+v", got, want)
+	}
+	if got := DiffSchemas(current, current); len(got) != 0 {
+		t.Errorf("DiffSchemas of identical schemas = %+v, want none", got)
+	}
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AlertSilence suppresses alert notifications. Exactly one of AlertID,
+// SourceID and TeamID is set: the silence covers that alert, every alert on
+// that source, or every alert on a source linked to that team. Silenced
+// alerts are still evaluated and recorded in their history; only the
+// notifications are held back.
+type AlertSilence struct {
+	ID       SilenceID `json:"id"`
+	AlertID  *AlertID  `json:"alert_id,omitempty"`
+	SourceID *SourceID `json:"source_id,omitempty"`
+	TeamID   *TeamID   `json:"team_id,omitempty"`
+	Reason   string    `json:"reason"`
+	StartsAt time.Time `json:"starts_at"`
+	// EndsAt is nil only for open-ended recurring silences.
+	EndsAt *time.Time `json:"ends_at,omitempty"`
+	// Recurrence narrows the silence to a weekly maintenance window between
+	// StartsAt and EndsAt. nil silences the whole range.
+	Recurrence *SilenceRecurrence `json:"recurrence,omitempty"`
+	CreatedBy  *UserID            `json:"created_by,omitempty"`
+	Timestamps
+	// Active is a response-only hint: whether the silence applies right now.
+	Active bool `json:"active"`
+}
+
+// SilenceRecurrence is a weekly maintenance window: it opens at StartTime on
+// each of Weekdays and stays open for DurationMinutes.
+type SilenceRecurrence struct {
+	// Weekdays the window opens on, 0 (Sunday) to 6 (Saturday). Empty opens
+	// it every day.
+	Weekdays []time.Weekday `json:"weekdays,omitempty"`
+	// StartTime is the local time the window opens, as "HH:MM".
+	StartTime       string `json:"start_time"`
+	DurationMinutes int    `json:"duration_minutes"`
+	// Timezone is the IANA zone StartTime is in; empty means UTC.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// CreateAlertSilenceRequest is the body for creating a silence. StartsAt
+// defaults to now; the end is EndsAt, or StartsAt plus DurationSeconds.
+type CreateAlertSilenceRequest struct {
+	AlertID         *AlertID           `json:"alert_id"`
+	SourceID        *SourceID          `json:"source_id"`
+	TeamID          *TeamID            `json:"team_id"`
+	Reason          string             `json:"reason"`
+	StartsAt        *time.Time         `json:"starts_at"`
+	EndsAt          *time.Time         `json:"ends_at"`
+	DurationSeconds int                `json:"duration_seconds"`
+	Recurrence      *SilenceRecurrence `json:"recurrence"`
+}
+
+// UpdateAlertSilenceRequest replaces a silence's reason and schedule. What it
+// silences can't change.
+type UpdateAlertSilenceRequest struct {
+	Reason          string             `json:"reason"`
+	StartsAt        *time.Time         `json:"starts_at"`
+	EndsAt          *time.Time         `json:"ends_at"`
+	DurationSeconds int                `json:"duration_seconds"`
+	Recurrence      *SilenceRecurrence `json:"recurrence"`
+}
+
+// MaxSilenceWindowMinutes caps a recurring window at one week, so windows
+// never overlap their own next occurrence.
+const MaxSilenceWindowMinutes = 7 * 24 * 60
+
+// Validate checks the silence targets exactly one thing and has a usable
+// schedule.
+func (s *AlertSilence) Validate() error {
+	targets := 0
+	if s.AlertID != nil {
+		targets++
+	}
+	if s.SourceID != nil {
+		targets++
+	}
+	if s.TeamID != nil {
+		targets++
+	}
+	if targets != 1 {
+		return fmt.Errorf("exactly one of alert_id, source_id or team_id is required")
+	}
+	s.Reason = strings.TrimSpace(s.Reason)
+	if s.StartsAt.IsZero() {
+		return fmt.Errorf("starts_at is required")
+	}
+	if s.EndsAt == nil && s.Recurrence == nil {
+		return fmt.Errorf("ends_at or duration_seconds is required (only recurring silences may be open-ended)")
+	}
+	if s.EndsAt != nil && !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	if s.Recurrence != nil {
+		if err := s.Recurrence.Validate(); err != nil {
+			return fmt.Errorf("invalid recurrence: %w", err)
+		}
+	}
+	return nil
+}
+
+// ActiveAt reports whether the silence applies at t.
+func (s *AlertSilence) ActiveAt(t time.Time) bool {
+	if t.Before(s.StartsAt) || (s.EndsAt != nil && !t.Before(*s.EndsAt)) {
+		return false
+	}
+	return s.Recurrence == nil || s.Recurrence.covers(t)
+}
+
+// Validate checks the window's fields and normalizes its timezone.
+func (r *SilenceRecurrence) Validate() error {
+	r.StartTime = strings.TrimSpace(r.StartTime)
+	r.Timezone = strings.TrimSpace(r.Timezone)
+	if _, err := time.Parse("15:04", r.StartTime); err != nil {
+		return fmt.Errorf("start_time must be HH:MM, got %q", r.StartTime)
+	}
+	if r.DurationMinutes <= 0 || r.DurationMinutes > MaxSilenceWindowMinutes {
+		return fmt.Errorf("duration_minutes must be between 1 and %d", MaxSilenceWindowMinutes)
+	}
+	for _, day := range r.Weekdays {
+		if day < time.Sunday || day > time.Saturday {
+			return fmt.Errorf("weekdays must be 0 (Sunday) to 6 (Saturday), got %d", day)
+		}
+	}
+	if _, err := time.LoadLocation(r.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q", r.Timezone)
+	}
+	return nil
+}
+
+// covers reports whether t falls in one of the window's occurrences. A window
+// can run past midnight, so occurrences opening on the previous days (up to
+// the one-week maximum) are checked too.
+func (r *SilenceRecurrence) covers(t time.Time) bool {
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return false
+	}
+	opens, err := time.Parse("15:04", r.StartTime)
+	if err != nil {
+		return false
+	}
+	duration := time.Duration(r.DurationMinutes) * time.Minute
+	local := t.In(loc)
+	for back := 0; back <= 7; back++ {
+		day := local.AddDate(0, 0, -back)
+		if !r.opensOn(day.Weekday()) {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), opens.Hour(), opens.Minute(), 0, 0, loc)
+		if !t.Before(start) && t.Before(start.Add(duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *SilenceRecurrence) opensOn(day time.Weekday) bool {
+	if len(r.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range r.Weekdays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAlertSilenceActiveAt(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC) // a Sunday
+	end := start.Add(48 * time.Hour)
+	oneOff := AlertSilence{StartsAt: start, EndsAt: &end}
+	if !oneOff.ActiveAt(start) || !oneOff.ActiveAt(end.Add(-time.Second)) {
+		t.Error("one-off silence inactive inside its range")
+	}
+	if oneOff.ActiveAt(start.Add(-time.Second)) || oneOff.ActiveAt(end) {
+		t.Error("one-off silence active outside its range")
+	}
+
+	// Saturdays 23:00-01:00 in Kolkata (UTC+5:30), crossing midnight.
+	window := AlertSilence{
+		StartsAt: start,
+		Recurrence: &SilenceRecurrence{
+			Weekdays:        []time.Weekday{time.Saturday},
+			StartTime:       "23:00",
+			DurationMinutes: 120,
+			Timezone:        "Asia/Kolkata",
+		},
+	}
+	if err := window.Recurrence.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+	cases := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 3, 7, 23, 30, 0, 0, kolkata), true},   // Saturday night
+		{time.Date(2026, 3, 8, 0, 59, 0, 0, kolkata), true},    // early Sunday, same window
+		{time.Date(2026, 3, 8, 1, 0, 0, 0, kolkata), false},    // window closed
+		{time.Date(2026, 3, 6, 23, 30, 0, 0, kolkata), false},  // Friday
+		{time.Date(2026, 3, 7, 17, 45, 0, 0, time.UTC), true},  // 23:15 in Kolkata
+		{time.Date(2026, 2, 28, 23, 30, 0, 0, kolkata), false}, // before StartsAt
+	}
+	for _, tc := range cases {
+		if got := window.ActiveAt(tc.at); got != tc.want {
+			t.Errorf("ActiveAt(%s) = %v, want %v", tc.at, got, tc.want)
+		}
+	}
+}
+
+func TestAlertSilenceValidate(t *testing.T) {
+	id := AlertID(1)
+	team := TeamID(2)
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	cases := map[string]AlertSilence{
+		"no target":         {StartsAt: start, EndsAt: &end},
+		"two targets":       {AlertID: &id, TeamID: &team, StartsAt: start, EndsAt: &end},
+		"no end":            {AlertID: &id, StartsAt: start},
+		"ends before start": {AlertID: &id, StartsAt: end, EndsAt: &start},
+		"bad start time": {AlertID: &id, StartsAt: start, Recurrence: &SilenceRecurrence{
+			StartTime: "25:00", DurationMinutes: 60,
+		}},
+		"window too long": {AlertID: &id, StartsAt: start, Recurrence: &SilenceRecurrence{
+			StartTime: "02:00", DurationMinutes: MaxSilenceWindowMinutes + 1,
+		}},
+		"unknown timezone": {AlertID: &id, StartsAt: start, Recurrence: &SilenceRecurrence{
+			StartTime: "02:00", DurationMinutes: 60, Timezone: "Mars/Olympus",
+		}},
+	}
+	for name, silence := range cases {
+		if err := silence.Validate(); err == nil {
+			t.Errorf("%s: Validate() = nil, want an error", name)
+		}
+	}
+
+	valid := AlertSilence{AlertID: &id, StartsAt: start, EndsAt: &end}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate(valid) = %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SLO is a team-scoped service-level objective over one source. Its SLI is the
+// ratio of two log counts over a rolling window: the logs matched by GoodQuery
+// out of those matched by TotalQuery. Logchef evaluates it periodically and
+// records compliance and error-budget burn in the SLO's history.
+type SLO struct {
+	ID          SLOID    `json:"id"`
+	TeamID      TeamID   `json:"team_id"`
+	SourceID    SourceID `json:"source_id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	// QueryLanguage is logchefql (the queries are filters, counted by Logchef)
+	// or the source's native language (each query returns the count itself).
+	QueryLanguage QueryLanguage `json:"query_language"`
+	GoodQuery     string        `json:"good_query"`
+	TotalQuery    string        `json:"total_query"`
+	// Target is the objective as a fraction, e.g. 0.999 for 99.9%.
+	Target        float64 `json:"target"`
+	WindowSeconds int     `json:"window_seconds"`
+	CreatedBy     *UserID `json:"created_by,omitempty"`
+	Timestamps
+	// Status is the most recent evaluation; nil until the SLO is first
+	// evaluated.
+	Status *SLOEvaluation `json:"status,omitempty"`
+	// CanEdit is a per-request UI authorization hint for the calling user.
+	// nil when not computed.
+	CanEdit *bool `json:"can_edit,omitempty"`
+}
+
+// SLOEvaluation is one evaluation of an SLO over its window. The derived
+// figures are nil when the window held no matching logs (total is zero) or the
+// evaluation failed, in which case Error says why.
+type SLOEvaluation struct {
+	ID    int64   `json:"id"`
+	SLOID SLOID   `json:"slo_id"`
+	Good  float64 `json:"good"`
+	Total float64 `json:"total"`
+	// Compliance is good/total.
+	Compliance *float64 `json:"compliance"`
+	// BurnRate is how fast the error budget is being spent: 1 spends exactly
+	// the budget over the window, 2 spends it in half the window.
+	BurnRate *float64 `json:"burn_rate"`
+	// ErrorBudgetRemaining is the fraction of the error budget left (1 - burn
+	// rate); negative once the objective is missed.
+	ErrorBudgetRemaining *float64  `json:"error_budget_remaining"`
+	Error                string    `json:"error,omitempty"`
+	EvaluatedAt          time.Time `json:"evaluated_at"`
+}
+
+// CreateSLORequest is the body for creating an SLO.
+type CreateSLORequest struct {
+	SourceID      SourceID      `json:"source_id"`
+	Name          string        `json:"name"`
+	Description   string        `json:"description"`
+	QueryLanguage QueryLanguage `json:"query_language"`
+	GoodQuery     string        `json:"good_query"`
+	TotalQuery    string        `json:"total_query"`
+	Target        float64       `json:"target"`
+	WindowSeconds int           `json:"window_seconds"`
+}
+
+// UpdateSLORequest replaces an SLO's mutable fields. The source can't change:
+// burn-rate alerts linked to the SLO are bound to it.
+type UpdateSLORequest struct {
+	Name          string        `json:"name"`
+	Description   string        `json:"description"`
+	QueryLanguage QueryLanguage `json:"query_language"`
+	GoodQuery     string        `json:"good_query"`
+	TotalQuery    string        `json:"total_query"`
+	Target        float64       `json:"target"`
+	WindowSeconds int           `json:"window_seconds"`
+}
+
+// SLO limits.
+const (
+	MinSLOWindowSeconds = 60 * 60
+	MaxSLOWindowSeconds = 90 * 24 * 60 * 60
+	// DefaultSLOHistoryLimit is the number of evaluations returned when the
+	// caller doesn't ask for a specific count.
+	DefaultSLOHistoryLimit = 100
+)
+
+// sloWindowStartPattern matches the {{start}} placeholder native ClickHouse
+// SLI queries use to scope themselves to the evaluation window.
+var sloWindowStartPattern = regexp.MustCompile(`\{\{\s*start\s*\}\}`)
+
+// Validate normalizes the SLO's definition and checks it. Whether the queries
+// compile against the source is checked separately, when the SLO is saved.
+func (s *SLO) Validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Description = strings.TrimSpace(s.Description)
+	s.GoodQuery = strings.TrimSpace(s.GoodQuery)
+	s.TotalQuery = strings.TrimSpace(s.TotalQuery)
+	s.QueryLanguage = NormalizeQueryLanguage(s.QueryLanguage)
+
+	if s.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch s.QueryLanguage {
+	case QueryLanguageLogchefQL, QueryLanguageClickHouseSQL, QueryLanguageLogsQL:
+	default:
+		return fmt.Errorf("invalid query_language %q (use logchefql, clickhouse-sql or logsql)", s.QueryLanguage)
+	}
+	if s.TotalQuery == "" {
+		return fmt.Errorf("total_query is required")
+	}
+	// An empty LogchefQL filter matches every log, which is a meaningful
+	// total but never a meaningful "good" count.
+	if s.GoodQuery == "" {
+		return fmt.Errorf("good_query is required")
+	}
+	if s.QueryLanguage == QueryLanguageClickHouseSQL {
+		for _, q := range []string{s.GoodQuery, s.TotalQuery} {
+			if !sloWindowStartPattern.MatchString(q) {
+				return fmt.Errorf("clickhouse-sql SLI queries must filter on the window with {{start}}")
+			}
+		}
+	}
+	if s.Target <= 0 || s.Target >= 1 {
+		return fmt.Errorf("target must be a fraction between 0 and 1 (e.g. 0.999), got %v", s.Target)
+	}
+	if s.WindowSeconds < MinSLOWindowSeconds || s.WindowSeconds > MaxSLOWindowSeconds {
+		return fmt.Errorf("window_seconds must be between %d (1h) and %d (90d)", MinSLOWindowSeconds, MaxSLOWindowSeconds)
+	}
+	return nil
+}
+
+// BurnRate returns the rate at which good out of total spends the SLO's error
+// budget, and false when total is zero. good is capped at total, so a good
+// query that isn't a strict subset of the total can't report over 100%.
+func (s *SLO) BurnRate(good, total float64) (float64, bool) {
+	if total <= 0 {
+		return 0, false
+	}
+	compliance := min(good, total) / total
+	return (1 - compliance) / (1 - s.Target), true
+}
+
+// NewEvaluation derives an evaluation's compliance and error-budget figures
+// from the good and total counts over the SLO's window.
+func (s *SLO) NewEvaluation(good, total float64, at time.Time) *SLOEvaluation {
+	eval := &SLOEvaluation{SLOID: s.ID, Good: good, Total: total, EvaluatedAt: at}
+	burn, ok := s.BurnRate(good, total)
+	if !ok {
+		return eval
+	}
+	compliance := min(good, total) / total
+	remaining := 1 - burn
+	eval.Compliance = &compliance
+	eval.BurnRate = &burn
+	eval.ErrorBudgetRemaining = &remaining
+	return eval
+}
@@ -36,6 +36,12 @@ func (t SourceType) String() string {
 	return string(NormalizeSourceType(t))
 }
 
+// ClickHouse wire protocols a source can connect over.
+const (
+	ClickHouseProtocolNative = "native"
+	ClickHouseProtocolHTTP   = "http"
+)
+
 // ConnectionInfo represents the connection details for a ClickHouse database.
 type ConnectionInfo struct {
 	Host      string `json:"host"`
@@ -43,11 +49,71 @@ type ConnectionInfo struct {
 	Password  string `json:"password"`
 	Database  string `json:"database"`
 	TableName string `json:"table_name"`
+	// Protocol is ClickHouseProtocolNative (the default when empty) or
+	// ClickHouseProtocolHTTP. With TLSEnable, HTTP becomes HTTPS.
+	Protocol  string `json:"protocol,omitempty"`
 	TLSEnable bool   `json:"tls_enable"`
+	// TLS options, used only with TLSEnable. Certificates and the key are PEM
+	// encoded. TLSCACert replaces the system roots; TLSClientCert and
+	// TLSClientKey go together for mutual TLS.
+	TLSCACert     string `json:"tls_ca_cert,omitempty"`
+	TLSClientCert string `json:"tls_client_cert,omitempty"`
+	TLSClientKey  string `json:"tls_client_key,omitempty"`
+	TLSSkipVerify bool   `json:"tls_skip_verify,omitempty"`
 	// Settings carries optional per-source ClickHouse query settings applied to
 	// every query executed against this source. Nil means "no per-source
 	// settings" and is omitted from the persisted connection_config JSON.
 	Settings *ClickHouseQuerySettings `json:"settings,omitempty"`
+	// Pool tunes the connection pool and caps concurrent queries against this
+	// source. Nil keeps the defaults.
+	Pool *ClickHouseConnectionPool `json:"pool,omitempty"`
+}
+
+// ClickHouseConnectionPool holds optional per-source connection pool limits.
+// Unset or zero fields keep LogChef's defaults; MaxConcurrentQueries of zero
+// means no limit. Like query settings, these are returned to the UI.
+type ClickHouseConnectionPool struct {
+	// MaxOpenConns caps the connections held open to ClickHouse.
+	MaxOpenConns int `json:"max_open_conns,omitempty"`
+	// MaxIdleConns caps the idle connections kept for reuse.
+	MaxIdleConns int `json:"max_idle_conns,omitempty"`
+	// DialTimeoutSeconds bounds how long opening a connection may take.
+	DialTimeoutSeconds int `json:"dial_timeout_seconds,omitempty"`
+	// MaxConcurrentQueries caps the queries LogChef runs against the source at
+	// once. Further queries wait for a free slot until their context ends.
+	MaxConcurrentQueries int `json:"max_concurrent_queries,omitempty"`
+}
+
+// MaxDialTimeoutSeconds caps ClickHouseConnectionPool.DialTimeoutSeconds.
+const MaxDialTimeoutSeconds = 300
+
+// Validate reports whether the pool limits are sane: all non-negative, the
+// dial timeout at most MaxDialTimeoutSeconds, and max_idle_conns no larger
+// than max_open_conns when both are set. A nil receiver is valid.
+func (p *ClickHouseConnectionPool) Validate() error {
+	if p == nil {
+		return nil
+	}
+	for _, c := range []struct {
+		name string
+		v    int
+	}{
+		{"max_open_conns", p.MaxOpenConns},
+		{"max_idle_conns", p.MaxIdleConns},
+		{"dial_timeout_seconds", p.DialTimeoutSeconds},
+		{"max_concurrent_queries", p.MaxConcurrentQueries},
+	} {
+		if c.v < 0 {
+			return fmt.Errorf("%s must be non-negative", c.name)
+		}
+	}
+	if p.DialTimeoutSeconds > MaxDialTimeoutSeconds {
+		return fmt.Errorf("dial_timeout_seconds must be at most %d", MaxDialTimeoutSeconds)
+	}
+	if p.MaxOpenConns > 0 && p.MaxIdleConns > p.MaxOpenConns {
+		return fmt.Errorf("max_idle_conns must not exceed max_open_conns")
+	}
+	return nil
 }
 
 // ClickHouseQuerySettings holds optional ClickHouse query settings configured per
@@ -65,6 +131,12 @@ type ClickHouseQuerySettings struct {
 	MaxRowsToRead *int64 `json:"max_rows_to_read,omitempty"`
 	// MaxBytesToRead caps the number of bytes read during execution (max_bytes_to_read).
 	MaxBytesToRead *int64 `json:"max_bytes_to_read,omitempty"`
+	// MaxThreads caps the threads a query may use (max_threads); 0 lets
+	// ClickHouse pick.
+	MaxThreads *int `json:"max_threads,omitempty"`
+	// MaxMemoryUsage caps a query's memory on a single server in bytes
+	// (max_memory_usage); 0 means unlimited.
+	MaxMemoryUsage *int64 `json:"max_memory_usage,omitempty"`
 	// Readonly sets the connection read-only mode (readonly): 0 (read-write),
 	// 1 (read-only, no setting changes), or 2 (read-only, setting changes allowed).
 	Readonly *int `json:"readonly,omitempty"`
@@ -89,6 +161,9 @@ func (s *ClickHouseQuerySettings) Validate() error {
 	if s.MaxExecutionTime != nil && *s.MaxExecutionTime < 0 {
 		return fmt.Errorf("max_execution_time must be non-negative")
 	}
+	if s.MaxThreads != nil && *s.MaxThreads < 0 {
+		return fmt.Errorf("max_threads must be non-negative")
+	}
 	for _, c := range []struct {
 		name string
 		v    *int64
@@ -97,6 +172,7 @@ func (s *ClickHouseQuerySettings) Validate() error {
 		{"max_result_bytes", s.MaxResultBytes},
 		{"max_rows_to_read", s.MaxRowsToRead},
 		{"max_bytes_to_read", s.MaxBytesToRead},
+		{"max_memory_usage", s.MaxMemoryUsage},
 	} {
 		if err := checkNonNegative(c.name, c.v); err != nil {
 			return err
@@ -138,6 +214,12 @@ func (s *ClickHouseQuerySettings) ToSettingsMap() map[string]any {
 	if s.MaxBytesToRead != nil {
 		m["max_bytes_to_read"] = *s.MaxBytesToRead
 	}
+	if s.MaxThreads != nil {
+		m["max_threads"] = *s.MaxThreads
+	}
+	if s.MaxMemoryUsage != nil {
+		m["max_memory_usage"] = *s.MaxMemoryUsage
+	}
 	if s.Readonly != nil {
 		m["readonly"] = *s.Readonly
 	}
@@ -188,6 +270,13 @@ type Source struct {
 	IdentityKey       string          `db:"identity_key" json:"identity_key,omitempty"`
 	Description       string          `db:"description" json:"description,omitempty"`
 	TTLDays           int             `db:"ttl_days" json:"ttl_days"`
+	Tags              SourceTags      `db:"tags" json:"tags,omitempty"`
+	ExtractionRules   ExtractionRules `db:"extraction_rules" json:"extraction_rules,omitempty"`
+	// TraceCorrelation is nil when the source has no trace columns configured.
+	TraceCorrelation *TraceCorrelation `db:"trace_correlation" json:"trace_correlation,omitempty"`
+	// MaskedColumns are hashed or redacted for users without the
+	// view_unmasked team permission.
+	MaskedColumns MaskedColumns `db:"masked_columns" json:"masked_columns,omitempty"`
 	Timestamps
 	IsConnected bool         `db:"-" json:"is_connected"`
 	Schema      string       `db:"-" json:"schema,omitempty"`
@@ -196,6 +285,7 @@ type Source struct {
 	Engine                string                 `db:"-" json:"engine,omitempty"`
 	EngineParams          []string               `db:"-" json:"engine_params,omitempty"`
 	SortKeys              []string               `db:"-" json:"sort_keys,omitempty"`
+	EffectiveTTL          string                 `db:"-" json:"effective_ttl,omitempty"` // TTL in the table's CREATE statement.
 	QueryLanguages        []QueryLanguage        `db:"-" json:"query_languages,omitempty"`
 	SavedQueryEditorModes []SavedQueryEditorMode `db:"-" json:"saved_query_editor_modes,omitempty"`
 	AlertEditorModes      []AlertEditorMode      `db:"-" json:"alert_editor_modes,omitempty"`
@@ -205,6 +295,14 @@ type Source struct {
 	SecretRef string `db:"secret_ref" json:"secret_ref,omitempty"`
 }
 
+// ClickHouseProtocol returns the connection's protocol, defaulting to native.
+func (c ConnectionInfo) ClickHouseProtocol() string {
+	if strings.TrimSpace(c.Protocol) == "" {
+		return ClickHouseProtocolNative
+	}
+	return strings.ToLower(strings.TrimSpace(c.Protocol))
+}
+
 func BuildClickHouseIdentityKey(conn ConnectionInfo) string {
 	host := strings.ToLower(strings.TrimSpace(conn.Host))
 	database := strings.ToLower(strings.TrimSpace(conn.Database))
@@ -364,11 +462,18 @@ func (s *Source) RedactedConnectionConfig() json.RawMessage {
 			Username:    s.Connection.Username,
 			Database:    s.Connection.Database,
 			TableName:   s.Connection.TableName,
+			Protocol:    s.Connection.ClickHouseProtocol(),
 			TLSEnable:   s.Connection.TLSEnable,
 			HasPassword: s.Connection.Password != "",
+			// The CA and client certificate are public; the key is not.
+			TLSCACert:       s.Connection.TLSCACert,
+			TLSClientCert:   s.Connection.TLSClientCert,
+			HasTLSClientKey: s.Connection.TLSClientKey != "",
+			TLSSkipVerify:   s.Connection.TLSSkipVerify,
 			// Settings aren't secrets: return them so the UI can display and
 			// round-trip them on edit (unlike the password, which is redacted).
 			Settings: s.Connection.Settings,
+			Pool:     s.Connection.Pool,
 		})
 		if err != nil {
 			return json.RawMessage(`{}`)
@@ -405,25 +510,30 @@ func (s *Source) RedactedConnectionConfig() json.RawMessage {
 
 // SourceResponse represents a Source for API responses, with sensitive information removed.
 type SourceResponse struct {
-	ID                SourceID        `json:"id"`
-	Name              string          `json:"name"`
-	MetaIsAutoCreated bool            `json:"_meta_is_auto_created"`
-	SourceType        SourceType      `json:"source_type"`
-	MetaTSField       string          `json:"_meta_ts_field"`
-	MetaSeverityField string          `json:"_meta_severity_field"`
-	Connection        json.RawMessage `json:"connection"`
-	IdentityKey       string          `json:"identity_key,omitempty"`
-	Description       string          `json:"description,omitempty"`
-	TTLDays           int             `json:"ttl_days"`
-	CreatedAt         time.Time       `json:"created_at"`
-	UpdatedAt         time.Time       `json:"updated_at"`
-	IsConnected       bool            `json:"is_connected"`
-	Schema            string          `json:"schema,omitempty"`
-	Columns           []ColumnInfo    `json:"columns,omitempty"`
+	ID                SourceID          `json:"id"`
+	Name              string            `json:"name"`
+	MetaIsAutoCreated bool              `json:"_meta_is_auto_created"`
+	SourceType        SourceType        `json:"source_type"`
+	MetaTSField       string            `json:"_meta_ts_field"`
+	MetaSeverityField string            `json:"_meta_severity_field"`
+	Connection        json.RawMessage   `json:"connection"`
+	IdentityKey       string            `json:"identity_key,omitempty"`
+	Description       string            `json:"description,omitempty"`
+	TTLDays           int               `json:"ttl_days"`
+	Tags              SourceTags        `json:"tags"`
+	ExtractionRules   ExtractionRules   `json:"extraction_rules"`
+	TraceCorrelation  *TraceCorrelation `json:"trace_correlation,omitempty"`
+	MaskedColumns     MaskedColumns     `json:"masked_columns"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+	IsConnected       bool              `json:"is_connected"`
+	Schema            string            `json:"schema,omitempty"`
+	Columns           []ColumnInfo      `json:"columns,omitempty"`
 	// Enhanced schema information
 	Engine                string                 `json:"engine,omitempty"`
 	EngineParams          []string               `json:"engine_params,omitempty"`
 	SortKeys              []string               `json:"sort_keys,omitempty"`
+	EffectiveTTL          string                 `json:"effective_ttl,omitempty"`
 	QueryLanguages        []QueryLanguage        `json:"query_languages,omitempty"`
 	SavedQueryEditorModes []SavedQueryEditorMode `json:"saved_query_editor_modes,omitempty"`
 	AlertEditorModes      []AlertEditorMode      `json:"alert_editor_modes,omitempty"`
@@ -443,6 +553,10 @@ func (s *Source) ToResponse() *SourceResponse {
 		IdentityKey:           s.IdentityKey,
 		Description:           s.Description,
 		TTLDays:               s.TTLDays,
+		Tags:                  s.Tags.orEmpty(),
+		ExtractionRules:       s.ExtractionRules.orEmpty(),
+		TraceCorrelation:      s.TraceCorrelation,
+		MaskedColumns:         s.MaskedColumns.orEmpty(),
 		CreatedAt:             s.CreatedAt,
 		UpdatedAt:             s.UpdatedAt,
 		IsConnected:           s.IsConnected,
@@ -451,6 +565,7 @@ func (s *Source) ToResponse() *SourceResponse {
 		Engine:                s.Engine,
 		EngineParams:          s.EngineParams,
 		SortKeys:              s.SortKeys,
+		EffectiveTTL:          s.EffectiveTTL,
 		QueryLanguages:        s.QueryLanguages,
 		SavedQueryEditorModes: s.SavedQueryEditorModes,
 		AlertEditorModes:      s.AlertEditorModes,
@@ -465,23 +580,99 @@ func (s *Source) GetFullTableName() string {
 
 // SourceHealth represents the health status of a source.
 type SourceHealth struct {
-	SourceID    SourceID     `json:"source_id"`
-	Status      HealthStatus `json:"status"`
-	Error       string       `json:"error,omitempty"`
-	LastChecked time.Time    `json:"last_checked"`
+	SourceID    SourceID        `json:"source_id"`
+	Status      HealthStatus    `json:"status"`
+	Error       string          `json:"error,omitempty"`
+	ErrorKind   HealthErrorKind `json:"error_kind,omitempty"`
+	LastChecked time.Time       `json:"last_checked"`
+	// LatencyMs is how long the last background probe took to answer.
+	LatencyMs int64 `json:"latency_ms,omitempty"`
+	// Capabilities is what the connection was allowed to do at the last
+	// successful check; nil until one has run.
+	Capabilities *SourceHealthCapabilities `json:"capabilities,omitempty"`
+	// Replicas is each replica's status at the last check, for a ClickHouse
+	// source whose host lists several.
+	Replicas []ReplicaHealth `json:"replicas,omitempty"`
+}
+
+// ReplicaHealth is one replica's status at a source's last health check.
+type ReplicaHealth struct {
+	Host   string       `json:"host"`
+	Status HealthStatus `json:"status"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// HealthCheck is one background probe of a source.
+type HealthCheck struct {
+	CheckedAt time.Time    `json:"checked_at"`
+	Status    HealthStatus `json:"status"`
+	LatencyMs int64        `json:"latency_ms"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// SourceHealthHistory is a source's recent probes, oldest first. Uptime is
+// the fraction of them that found the source healthy, 0 with no probes.
+type SourceHealthHistory struct {
+	SourceID SourceID      `json:"source_id"`
+	Checks   []HealthCheck `json:"checks"`
+	Uptime   float64       `json:"uptime"`
+}
+
+// NewSourceHealthHistory wraps checks, computing the uptime.
+func NewSourceHealthHistory(sourceID SourceID, checks []HealthCheck) *SourceHealthHistory {
+	history := &SourceHealthHistory{SourceID: sourceID, Checks: checks}
+	if history.Checks == nil {
+		history.Checks = []HealthCheck{}
+	}
+	healthy := 0
+	for _, check := range checks {
+		if check.Status == HealthStatusHealthy {
+			healthy++
+		}
+	}
+	if len(checks) > 0 {
+		history.Uptime = float64(healthy) / float64(len(checks))
+	}
+	return history
+}
+
+// HealthErrorKind classifies why a health check failed.
+type HealthErrorKind string
+
+const (
+	HealthErrorConnection HealthErrorKind = "connection" // Server unreachable or connection refused.
+	HealthErrorTimeout    HealthErrorKind = "timeout"    // Ping or reconnect timed out.
+	HealthErrorAuth       HealthErrorKind = "auth"       // Credentials rejected.
+	HealthErrorPermission HealthErrorKind = "permission" // Authenticated, but access denied.
+)
+
+// SourceHealthCapabilities records which kinds of operations a source's
+// connection supports. A read-only replica (or a user with readonly set)
+// can query but not run DDL, so table creation and rollups are refused up
+// front instead of failing mid-way.
+type SourceHealthCapabilities struct {
+	CanQuery bool `json:"can_query"`
+	CanDDL   bool `json:"can_ddl"`
+	CanStats bool `json:"can_stats"` // Can read system.parts for storage stats.
+	// ReadOnlyReason explains CanDDL being false, e.g. "readonly=1".
+	ReadOnlyReason string `json:"read_only_reason,omitempty"`
 }
 
 // CreateSourceRequest represents a request to create a new data source.
 type CreateSourceRequest struct {
-	Name              string          `json:"name"`
-	MetaIsAutoCreated bool            `json:"meta_is_auto_created"`
-	SourceType        SourceType      `json:"source_type"`
-	MetaTSField       string          `json:"meta_ts_field"`
-	MetaSeverityField string          `json:"meta_severity_field"`
-	Connection        json.RawMessage `json:"connection"`
-	Description       string          `json:"description"`
-	TTLDays           int             `json:"ttl_days"`
-	Schema            string          `json:"schema,omitempty"`
+	Name              string            `json:"name"`
+	MetaIsAutoCreated bool              `json:"meta_is_auto_created"`
+	SourceType        SourceType        `json:"source_type"`
+	MetaTSField       string            `json:"meta_ts_field"`
+	MetaSeverityField string            `json:"meta_severity_field"`
+	Connection        json.RawMessage   `json:"connection"`
+	Description       string            `json:"description"`
+	TTLDays           int               `json:"ttl_days"`
+	Schema            string            `json:"schema,omitempty"`
+	Tags              SourceTags        `json:"tags,omitempty"`
+	ExtractionRules   ExtractionRules   `json:"extraction_rules,omitempty"`
+	TraceCorrelation  *TraceCorrelation `json:"trace_correlation,omitempty"`
+	MaskedColumns     MaskedColumns     `json:"masked_columns,omitempty"`
 }
 
 // ValidateConnectionRequest represents a request to validate a connection.
@@ -501,6 +692,45 @@ type UpdateSourceRequest struct {
 	MetaTSField       *string         `json:"meta_ts_field,omitempty"`
 	MetaSeverityField *string         `json:"meta_severity_field,omitempty"`
 	Connection        json.RawMessage `json:"connection,omitempty"`
+	// Tags, when set, replaces the source's tags; an empty object clears them.
+	Tags *SourceTags `json:"tags,omitempty"`
+	// ExtractionRules, when set, replaces the source's extraction rules; an
+	// empty array clears them.
+	ExtractionRules *ExtractionRules `json:"extraction_rules,omitempty"`
+	// TraceCorrelation, when set, replaces the source's trace correlation; an
+	// object without trace_id_field clears it.
+	TraceCorrelation *TraceCorrelation `json:"trace_correlation,omitempty"`
+	// MaskedColumns, when set, replaces the source's masked columns; an empty
+	// array clears them.
+	MaskedColumns *MaskedColumns `json:"masked_columns,omitempty"`
+}
+
+// UpdateSourceTTLRequest changes a source's retention on the table itself.
+type UpdateSourceTTLRequest struct {
+	// TTLDays is the new retention; -1 removes the TTL.
+	TTLDays int `json:"ttl_days"`
+	// DryRun returns the DDL without running it or saving the source.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// SourceTTLChange is the outcome of a TTL update or dry run.
+type SourceTTLChange struct {
+	TTLDays int `json:"ttl_days"`
+	// Statement is the ALTER that was, or in a dry run would be, executed.
+	Statement string `json:"statement"`
+	Applied   bool   `json:"applied"`
+	// EffectiveTTL is the table's TTL expression, read back after applying.
+	EffectiveTTL string `json:"effective_ttl,omitempty"`
+}
+
+// UpdateSourceExtractionRulesRequest replaces a source's extraction rules.
+type UpdateSourceExtractionRulesRequest struct {
+	Rules ExtractionRules `json:"rules"`
+}
+
+// UpdateSourceMaskedColumnsRequest replaces a source's masked columns.
+type UpdateSourceMaskedColumnsRequest struct {
+	Columns MaskedColumns `json:"columns"`
 }
 
 // HasConnectionChanges returns true if any connection-related fields are being updated.
@@ -517,18 +747,83 @@ type SourceWithTeams struct {
 
 // ConnectionValidationResult represents the result of a connection validation
 type ConnectionValidationResult struct {
-	Message string `json:"message"`
+	Message      string                    `json:"message"`
+	Capabilities *SourceHealthCapabilities `json:"capabilities,omitempty"`
+}
+
+// DiscoveryRequest carries the connection details of a source being set up.
+// Table listing reads connection.database, and table previews read
+// connection.database and connection.table_name. Limit is the number of
+// preview rows.
+type DiscoveryRequest struct {
+	SourceType SourceType      `json:"source_type"`
+	Connection json.RawMessage `json:"connection"`
+	Limit      int             `json:"limit,omitempty"`
+}
+
+// TablePartition is one active partition of a source table. MinTime and
+// MaxTime are nil unless the table is partitioned by a DateTime expression.
+type TablePartition struct {
+	ID                string     `json:"partition_id"`
+	Partition         string     `json:"partition"`
+	Rows              uint64     `json:"rows"`
+	PartCount         uint64     `json:"part_count"`
+	BytesOnDisk       uint64     `json:"bytes_on_disk"`
+	UncompressedBytes uint64     `json:"uncompressed_bytes"`
+	MinTime           *time.Time `json:"min_time,omitempty"`
+	MaxTime           *time.Time `json:"max_time,omitempty"`
+}
+
+// DropPartitionsRequest names the partitions of a source table to drop.
+type DropPartitionsRequest struct {
+	PartitionIDs []string `json:"partition_ids"`
+	// DryRun returns the DDL without running it.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// DropPartitionsResult lists the statements a drop ran, or in a dry run
+// would run, and the space they free.
+type DropPartitionsResult struct {
+	Statements  []string `json:"statements"`
+	Applied     bool     `json:"applied"`
+	Rows        uint64   `json:"rows"`
+	BytesOnDisk uint64   `json:"bytes_on_disk"`
+}
+
+// DiscoveredTable is a table or view offered by the source setup wizard.
+// TotalRows and TotalBytes are nil for engines that don't track them.
+type DiscoveredTable struct {
+	Name       string  `json:"name"`
+	Engine     string  `json:"engine"`
+	TotalRows  *uint64 `json:"total_rows"`
+	TotalBytes *uint64 `json:"total_bytes"`
+}
+
+// TablePreview is a sample of a candidate source table. The candidate lists
+// name the columns that look like a timestamp or severity field, most likely
+// first.
+type TablePreview struct {
+	Columns             []ColumnInfo     `json:"columns"`
+	Rows                []map[string]any `json:"rows"`
+	TimestampCandidates []string         `json:"timestamp_candidates"`
+	SeverityCandidates  []string         `json:"severity_candidates"`
 }
 
 // ConnectionInfoResponse represents the connection details for API responses.
-// Credentials are never serialized; HasPassword lets the UI show whether one
-// is set (edit forms treat a blank password as "keep existing").
+// Credentials are never serialized; HasPassword and HasTLSClientKey let the UI
+// show whether one is set (edit forms treat a blank value as "keep existing").
 type ConnectionInfoResponse struct {
-	Host        string                   `json:"host"`
-	Username    string                   `json:"username,omitempty"`
-	Database    string                   `json:"database"`
-	TableName   string                   `json:"table_name"`
-	TLSEnable   bool                     `json:"tls_enable"`
-	HasPassword bool                     `json:"has_password,omitempty"`
-	Settings    *ClickHouseQuerySettings `json:"settings,omitempty"`
+	Host            string                    `json:"host"`
+	Username        string                    `json:"username,omitempty"`
+	Database        string                    `json:"database"`
+	TableName       string                    `json:"table_name"`
+	Protocol        string                    `json:"protocol"`
+	TLSEnable       bool                      `json:"tls_enable"`
+	TLSCACert       string                    `json:"tls_ca_cert,omitempty"`
+	TLSClientCert   string                    `json:"tls_client_cert,omitempty"`
+	HasTLSClientKey bool                      `json:"has_tls_client_key,omitempty"`
+	TLSSkipVerify   bool                      `json:"tls_skip_verify,omitempty"`
+	HasPassword     bool                      `json:"has_password,omitempty"`
+	Settings        *ClickHouseQuerySettings  `json:"settings,omitempty"`
+	Pool            *ClickHouseConnectionPool `json:"pool,omitempty"`
 }
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// SourceAlertKind is the degradation a source alert reports.
+type SourceAlertKind string
+
+const (
+	// SourceAlertUnreachable is raised when a source fails consecutive health
+	// probes.
+	SourceAlertUnreachable SourceAlertKind = "unreachable"
+	// SourceAlertErrorRate is raised when too many of a source's queries fail.
+	SourceAlertErrorRate SourceAlertKind = "error_rate"
+)
+
+// SourceAlertEvent records a source degradation alert firing or resolving.
+// These are raised by Logchef itself rather than a user's alert rule, so they
+// are kept apart from alert history. Value is the consecutive failed probes
+// for unreachable events and the query error rate for error_rate ones.
+type SourceAlertEvent struct {
+	ID        int64           `json:"id"`
+	SourceID  SourceID        `json:"source_id"`
+	Kind      SourceAlertKind `json:"kind"`
+	Status    AlertStatus     `json:"status"`
+	Value     float64         `json:"value"`
+	Message   string          `json:"message"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -0,0 +1,226 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Extraction rule limits. Every rule becomes an expression in each query on
+// the source, so the list is kept short.
+const (
+	MaxExtractionRules       = 32
+	maxExtractionPatternSize = 512
+	defaultExtractionField   = "body"
+)
+
+// ExtractionRuleKind is how a rule reads its value from the field.
+type ExtractionRuleKind string
+
+const (
+	// ExtractionRegex takes the first capture group of Pattern (or the whole
+	// match without a group), via ClickHouse extract().
+	ExtractionRegex ExtractionRuleKind = "regex"
+	// ExtractionJSON reads the value at Path from a JSON field.
+	ExtractionJSON ExtractionRuleKind = "json"
+)
+
+// ExtractionValueType is the type of a rule's virtual column.
+type ExtractionValueType string
+
+const (
+	ExtractionString ExtractionValueType = "string"
+	ExtractionInt    ExtractionValueType = "int"
+	ExtractionFloat  ExtractionValueType = "float"
+)
+
+var (
+	extractionNameRe  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+	extractionFieldRe = regexp.MustCompile(`^@?[A-Za-z_][A-Za-z0-9_]*$`)
+	extractionPathRe  = regexp.MustCompile(`^[A-Za-z0-9_@-]+(\.[A-Za-z0-9_@-]+)*$`)
+)
+
+// ExtractionRule defines a virtual column computed from a string column,
+// usually the log body, so semi-structured bodies can be filtered and
+// selected without changing the table. For example
+// {"name": "status", "kind": "regex", "pattern": "status=(\\d+)", "type": "int"}.
+type ExtractionRule struct {
+	Name string             `json:"name"`
+	Kind ExtractionRuleKind `json:"kind"`
+	// Field is the column the rule reads; it defaults to "body".
+	Field string `json:"field,omitempty"`
+	// Pattern is an RE2 regular expression, for regex rules.
+	Pattern string `json:"pattern,omitempty"`
+	// Path is a dotted key path such as "user.id", for JSON rules. A leading
+	// "$." is accepted and ignored.
+	Path string              `json:"path,omitempty"`
+	Type ExtractionValueType `json:"type,omitempty"`
+}
+
+// ExtractionRules are a source's field extraction rules, in display order.
+type ExtractionRules []ExtractionRule
+
+// Normalize fills in defaults: the body field, the string type and a path
+// without its "$." prefix.
+func (r ExtractionRules) Normalize() ExtractionRules {
+	if len(r) == 0 {
+		return nil
+	}
+	out := make(ExtractionRules, len(r))
+	for i, rule := range r {
+		rule.Name = strings.TrimSpace(rule.Name)
+		rule.Field = strings.TrimSpace(rule.Field)
+		if rule.Field == "" {
+			rule.Field = defaultExtractionField
+		}
+		if rule.Type == "" {
+			rule.Type = ExtractionString
+		}
+		rule.Path = strings.TrimPrefix(strings.TrimSpace(rule.Path), "$.")
+		out[i] = rule
+	}
+	return out
+}
+
+// Validate checks normalized rules: unique column names, a known kind and
+// type, and a pattern that compiles or a plain key path.
+func (r ExtractionRules) Validate() error {
+	if len(r) > MaxExtractionRules {
+		return fmt.Errorf("a source can have at most %d extraction rules", MaxExtractionRules)
+	}
+	seen := make(map[string]struct{}, len(r))
+	for _, rule := range r {
+		if !extractionNameRe.MatchString(rule.Name) {
+			return fmt.Errorf("invalid extraction rule name %q: use letters, digits and underscores (max 64)", rule.Name)
+		}
+		if _, dup := seen[rule.Name]; dup {
+			return fmt.Errorf("duplicate extraction rule %q", rule.Name)
+		}
+		seen[rule.Name] = struct{}{}
+		if !extractionFieldRe.MatchString(rule.Field) {
+			return fmt.Errorf("extraction rule %q: invalid field %q", rule.Name, rule.Field)
+		}
+		switch rule.Type {
+		case ExtractionString, ExtractionInt, ExtractionFloat:
+		default:
+			return fmt.Errorf("extraction rule %q: type must be string, int or float", rule.Name)
+		}
+
+		switch rule.Kind {
+		case ExtractionRegex:
+			if rule.Pattern == "" || len(rule.Pattern) > maxExtractionPatternSize {
+				return fmt.Errorf("extraction rule %q: pattern is required and must be at most %d characters", rule.Name, maxExtractionPatternSize)
+			}
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				return fmt.Errorf("extraction rule %q: invalid pattern: %v", rule.Name, err)
+			}
+		case ExtractionJSON:
+			if !extractionPathRe.MatchString(rule.Path) {
+				return fmt.Errorf("extraction rule %q: path must be dot-separated keys such as \"user.id\"", rule.Name)
+			}
+		default:
+			return fmt.Errorf("extraction rule %q: kind must be regex or json", rule.Name)
+		}
+	}
+	return nil
+}
+
+// Expression returns the ClickHouse expression that computes the rule. The
+// rule must be normalized and valid.
+func (rule ExtractionRule) Expression() string {
+	field := "`" + rule.Field + "`"
+	if rule.Kind == ExtractionJSON {
+		var args strings.Builder
+		args.WriteString(field)
+		for key := range strings.SplitSeq(rule.Path, ".") {
+			args.WriteString(", " + quoteExtractionString(key))
+		}
+		switch rule.Type {
+		case ExtractionInt:
+			return fmt.Sprintf("JSONExtract(%s, 'Nullable(Int64)')", args.String())
+		case ExtractionFloat:
+			return fmt.Sprintf("JSONExtract(%s, 'Nullable(Float64)')", args.String())
+		default:
+			return fmt.Sprintf("JSONExtractString(%s)", args.String())
+		}
+	}
+
+	extract := fmt.Sprintf("extract(%s, %s)", field, quoteExtractionString(rule.Pattern))
+	switch rule.Type {
+	case ExtractionInt:
+		return fmt.Sprintf("toInt64OrNull(%s)", extract)
+	case ExtractionFloat:
+		return fmt.Sprintf("toFloat64OrNull(%s)", extract)
+	default:
+		return extract
+	}
+}
+
+// ColumnType returns the ClickHouse type of the rule's virtual column.
+func (rule ExtractionRule) ColumnType() string {
+	switch rule.Type {
+	case ExtractionInt:
+		return "Nullable(Int64)"
+	case ExtractionFloat:
+		return "Nullable(Float64)"
+	default:
+		return "String"
+	}
+}
+
+// VirtualColumns returns a column for each rule whose name is not already
+// one of columns; a real column always wins over a rule of the same name.
+func (r ExtractionRules) VirtualColumns(columns []ColumnInfo) []ColumnInfo {
+	var out []ColumnInfo
+	for _, rule := range r {
+		if slices.ContainsFunc(columns, func(c ColumnInfo) bool { return c.Name == rule.Name }) {
+			continue
+		}
+		out = append(out, ColumnInfo{
+			Name:       rule.Name,
+			Type:       rule.ColumnType(),
+			Virtual:    true,
+			Expression: rule.Expression(),
+		})
+	}
+	return out
+}
+
+// Equal reports whether r and other hold the same rules in the same order.
+func (r ExtractionRules) Equal(other ExtractionRules) bool {
+	return slices.Equal(r, other)
+}
+
+// Encode returns the JSON array stored in the sources table.
+func (r ExtractionRules) Encode() string {
+	if len(r) == 0 {
+		return "[]"
+	}
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+// DecodeExtractionRules parses a stored extraction_rules column. Malformed or
+// empty input yields no rules.
+func DecodeExtractionRules(raw []byte) ExtractionRules {
+	var rules ExtractionRules
+	if err := json.Unmarshal(raw, &rules); err != nil || len(rules) == 0 {
+		return nil
+	}
+	return rules
+}
+
+func (r ExtractionRules) orEmpty() ExtractionRules {
+	if r == nil {
+		return ExtractionRules{}
+	}
+	return r
+}
+
+// quoteExtractionString quotes s as a ClickHouse string literal.
+func quoteExtractionString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, `'`, `\'`) + "'"
+}
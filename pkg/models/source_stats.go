@@ -0,0 +1,90 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SourceStatsDateLayout is the layout of SourceStatsSnapshot.SnapshotDate.
+const SourceStatsDateLayout = "2006-01-02"
+
+// SourceStatsSnapshot is a source table's storage statistics for one UTC day,
+// taken by the source stats scheduler. Only active parts are counted.
+type SourceStatsSnapshot struct {
+	SourceID          SourceID             `json:"source_id"`
+	SnapshotDate      string               `json:"snapshot_date"`
+	Rows              int64                `json:"rows"`
+	PartCount         int64                `json:"part_count"`
+	CompressedBytes   int64                `json:"compressed_bytes"`
+	UncompressedBytes int64                `json:"uncompressed_bytes"`
+	Columns           []ColumnStorageStats `json:"columns"`
+	CapturedAt        time.Time            `json:"captured_at"`
+}
+
+// CompressionRatio is uncompressed over compressed bytes, 0 for an empty table.
+func (s SourceStatsSnapshot) CompressionRatio() float64 {
+	return compressionRatio(s.CompressedBytes, s.UncompressedBytes)
+}
+
+// MarshalJSON adds the derived compression ratio to the stored fields.
+func (s SourceStatsSnapshot) MarshalJSON() ([]byte, error) {
+	type plain SourceStatsSnapshot
+	return json.Marshal(struct {
+		plain
+		CompressionRatio float64 `json:"compression_ratio"`
+	}{plain(s), s.CompressionRatio()})
+}
+
+// ColumnStorageStats is one column's share of a table's storage.
+type ColumnStorageStats struct {
+	Name              string `json:"name"`
+	CompressedBytes   int64  `json:"compressed_bytes"`
+	UncompressedBytes int64  `json:"uncompressed_bytes"`
+}
+
+// CompressionRatio is uncompressed over compressed bytes, 0 for an empty column.
+func (c ColumnStorageStats) CompressionRatio() float64 {
+	return compressionRatio(c.CompressedBytes, c.UncompressedBytes)
+}
+
+// MarshalJSON adds the derived compression ratio to the stored fields.
+func (c ColumnStorageStats) MarshalJSON() ([]byte, error) {
+	type plain ColumnStorageStats
+	return json.Marshal(struct {
+		plain
+		CompressionRatio float64 `json:"compression_ratio"`
+	}{plain(c), c.CompressionRatio()})
+}
+
+func compressionRatio(compressed, uncompressed int64) float64 {
+	if compressed <= 0 {
+		return 0
+	}
+	return float64(uncompressed) / float64(compressed)
+}
+
+// SourceStatsTrend is a source's daily snapshots over a range, oldest first,
+// with the change between the first and the last of them.
+type SourceStatsTrend struct {
+	SourceID  SourceID               `json:"source_id"`
+	Snapshots []*SourceStatsSnapshot `json:"snapshots"`
+	// The changes are zero with fewer than two snapshots.
+	RowsChange             int64   `json:"rows_change"`
+	CompressedBytesChange  int64   `json:"compressed_bytes_change"`
+	CompressionRatioChange float64 `json:"compression_ratio_change"`
+}
+
+// NewSourceStatsTrend builds a trend from snapshots sorted oldest first.
+func NewSourceStatsTrend(sourceID SourceID, snapshots []*SourceStatsSnapshot) *SourceStatsTrend {
+	trend := &SourceStatsTrend{SourceID: sourceID, Snapshots: snapshots}
+	if trend.Snapshots == nil {
+		trend.Snapshots = []*SourceStatsSnapshot{}
+	}
+	if len(snapshots) >= 2 {
+		first, last := snapshots[0], snapshots[len(snapshots)-1]
+		trend.RowsChange = last.Rows - first.Rows
+		trend.CompressedBytesChange = last.CompressedBytes - first.CompressedBytes
+		trend.CompressionRatioChange = last.CompressionRatio() - first.CompressionRatio()
+	}
+	return trend
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Source tag limits, so tags stay small enough to travel as alert labels.
+const (
+	MaxSourceTags         = 32
+	MaxSourceTagValueSize = 128
+)
+
+var sourceTagKeyRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.-]{0,62}$`)
+
+// SourceTags are free-form key/value labels on a source (env=prod,
+// region=eu). They organize large installs: the sources list filters on them
+// and alert notifications carry them as labels for routing.
+type SourceTags map[string]string
+
+// Validate checks tag count, key syntax and value length.
+func (t SourceTags) Validate() error {
+	if len(t) > MaxSourceTags {
+		return fmt.Errorf("a source can have at most %d tags", MaxSourceTags)
+	}
+	for _, key := range slices.Sorted(maps.Keys(t)) {
+		if !sourceTagKeyRe.MatchString(key) {
+			return fmt.Errorf("invalid tag key %q: must start with a letter or underscore and contain only letters, digits, '_', '.' or '-' (max 63)", key)
+		}
+		if value := t[key]; len(value) > MaxSourceTagValueSize || strings.TrimSpace(value) != value {
+			return fmt.Errorf("invalid value for tag %q: must be at most %d characters without surrounding whitespace", key, MaxSourceTagValueSize)
+		}
+	}
+	return nil
+}
+
+// Equal reports whether t and other hold the same tags.
+func (t SourceTags) Equal(other SourceTags) bool {
+	return maps.Equal(t, other)
+}
+
+// Encode returns the JSON object stored in the sources table.
+func (t SourceTags) Encode() string {
+	b, _ := json.Marshal(t.orEmpty())
+	return string(b)
+}
+
+// DecodeSourceTags parses a stored tags column. Malformed or empty input
+// yields no tags.
+func DecodeSourceTags(raw []byte) SourceTags {
+	var tags SourceTags
+	if err := json.Unmarshal(raw, &tags); err != nil || len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+func (t SourceTags) orEmpty() SourceTags {
+	if t == nil {
+		return SourceTags{}
+	}
+	return t
+}
+
+// TagSelector matches sources by tag. Each term is "key=value" (the tag must
+// have that value) or "key" (the tag must be present); a source matches when
+// every term does. The zero selector matches everything.
+type TagSelector []TagSelectorTerm
+
+// TagSelectorTerm is one term of a TagSelector. An empty Value with HasValue
+// false only requires the key.
+type TagSelectorTerm struct {
+	Key      string
+	Value    string
+	HasValue bool
+}
+
+// ParseTagSelector parses selector terms, as repeated ?tag= query parameters
+// or comma-separated in a single string.
+func ParseTagSelector(terms ...string) (TagSelector, error) {
+	var selector TagSelector
+	for _, raw := range terms {
+		for term := range strings.SplitSeq(raw, ",") {
+			term = strings.TrimSpace(term)
+			if term == "" {
+				continue
+			}
+			key, value, hasValue := strings.Cut(term, "=")
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			if !sourceTagKeyRe.MatchString(key) {
+				return nil, fmt.Errorf("invalid tag selector %q", term)
+			}
+			selector = append(selector, TagSelectorTerm{Key: key, Value: value, HasValue: hasValue})
+		}
+	}
+	return selector, nil
+}
+
+// Matches reports whether tags satisfy every term of the selector.
+func (s TagSelector) Matches(tags SourceTags) bool {
+	for _, term := range s {
+		value, ok := tags[term.Key]
+		if !ok || (term.HasValue && value != term.Value) {
+			return false
+		}
+	}
+	return true
+}
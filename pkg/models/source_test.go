@@ -68,6 +68,39 @@ func TestRedactedConnectionConfigVictoriaLogsBlanksSecrets(t *testing.T) {
 	}
 }
 
+// TestRedactedConnectionConfigClickHouseWithholdsTLSKey checks the client key
+// never leaves the server while the public TLS settings round-trip to the UI.
+func TestRedactedConnectionConfigClickHouseWithholdsTLSKey(t *testing.T) {
+	t.Parallel()
+
+	source := &Source{
+		SourceType: SourceTypeClickHouse,
+		Connection: ConnectionInfo{
+			Host:          "ch.example.com",
+			Password:      "pw-secret",
+			Protocol:      ClickHouseProtocolHTTP,
+			TLSEnable:     true,
+			TLSCACert:     "ca-pem",
+			TLSClientCert: "cert-pem",
+			TLSClientKey:  "key-secret",
+		},
+	}
+	redacted := source.RedactedConnectionConfig()
+	for _, secret := range []string{"pw-secret", "key-secret"} {
+		if strings.Contains(string(redacted), secret) {
+			t.Fatalf("redacted config leaked secret %q: %s", secret, redacted)
+		}
+	}
+
+	var out ConnectionInfoResponse
+	if err := json.Unmarshal(redacted, &out); err != nil {
+		t.Fatalf("unmarshal redacted: %v", err)
+	}
+	if out.Protocol != ClickHouseProtocolHTTP || out.TLSCACert != "ca-pem" || out.TLSClientCert != "cert-pem" || !out.HasTLSClientKey {
+		t.Fatalf("unexpected redacted config: %+v", out)
+	}
+}
+
 func intPtr(v int) *int       { return &v }
 func int64Ptr(v int64) *int64 { return &v }
 func strPtr(v string) *string { return &v }
@@ -93,6 +126,8 @@ func TestClickHouseQuerySettingsValidate(t *testing.T) {
 				MaxResultBytes:     int64Ptr(1 << 20),
 				MaxRowsToRead:      int64Ptr(5000),
 				MaxBytesToRead:     int64Ptr(1 << 30),
+				MaxThreads:         intPtr(4),
+				MaxMemoryUsage:     int64Ptr(8 << 30),
 				Readonly:           intPtr(2),
 				ResultOverflowMode: strPtr("break"),
 			},
@@ -103,6 +138,8 @@ func TestClickHouseQuerySettingsValidate(t *testing.T) {
 		{"negative max_result_bytes", &ClickHouseQuerySettings{MaxResultBytes: int64Ptr(-1)}, true},
 		{"negative max_rows_to_read", &ClickHouseQuerySettings{MaxRowsToRead: int64Ptr(-1)}, true},
 		{"negative max_bytes_to_read", &ClickHouseQuerySettings{MaxBytesToRead: int64Ptr(-1)}, true},
+		{"negative max_threads", &ClickHouseQuerySettings{MaxThreads: intPtr(-1)}, true},
+		{"negative max_memory_usage", &ClickHouseQuerySettings{MaxMemoryUsage: int64Ptr(-1)}, true},
 		{"readonly too high", &ClickHouseQuerySettings{Readonly: intPtr(3)}, true},
 		{"readonly negative", &ClickHouseQuerySettings{Readonly: intPtr(-1)}, true},
 		{"readonly 0 ok", &ClickHouseQuerySettings{Readonly: intPtr(0)}, false},
@@ -120,6 +157,33 @@ func TestClickHouseQuerySettingsValidate(t *testing.T) {
 	}
 }
 
+func TestClickHouseConnectionPoolValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		p       *ClickHouseConnectionPool
+		wantErr bool
+	}{
+		{"nil is valid", nil, false},
+		{"empty is valid", &ClickHouseConnectionPool{}, false},
+		{"all set", &ClickHouseConnectionPool{MaxOpenConns: 20, MaxIdleConns: 10, DialTimeoutSeconds: 5, MaxConcurrentQueries: 4}, false},
+		{"idle without open", &ClickHouseConnectionPool{MaxIdleConns: 50}, false},
+		{"negative max_open_conns", &ClickHouseConnectionPool{MaxOpenConns: -1}, true},
+		{"negative max_concurrent_queries", &ClickHouseConnectionPool{MaxConcurrentQueries: -1}, true},
+		{"idle above open", &ClickHouseConnectionPool{MaxOpenConns: 4, MaxIdleConns: 8}, true},
+		{"dial timeout too long", &ClickHouseConnectionPool{DialTimeoutSeconds: MaxDialTimeoutSeconds + 1}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tc.p.Validate(); (err != nil) != tc.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr = %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
 // TestClickHouseQuerySettingsToSettingsMap verifies only-set settings are
 // emitted and a nil/empty struct yields nil.
 func TestClickHouseQuerySettingsToSettingsMap(t *testing.T) {
@@ -134,14 +198,15 @@ func TestClickHouseQuerySettingsToSettingsMap(t *testing.T) {
 
 	s := &ClickHouseQuerySettings{
 		MaxResultRows:      int64Ptr(1000),
+		MaxThreads:         intPtr(4),
 		Readonly:           intPtr(2),
 		ResultOverflowMode: strPtr("throw"),
 	}
 	m := s.ToSettingsMap()
-	if len(m) != 3 {
-		t.Fatalf("settings map = %#v, want 3 entries", m)
+	if len(m) != 4 {
+		t.Fatalf("settings map = %#v, want 4 entries", m)
 	}
-	if m["max_result_rows"] != int64(1000) || m["readonly"] != 2 || m["result_overflow_mode"] != "throw" {
+	if m["max_result_rows"] != int64(1000) || m["max_threads"] != 4 || m["readonly"] != 2 || m["result_overflow_mode"] != "throw" {
 		t.Fatalf("unexpected settings map: %#v", m)
 	}
 	if _, ok := m["max_execution_time"]; ok {
@@ -232,3 +297,210 @@ func TestRedactedConnectionConfigClickHousePreservesSettings(t *testing.T) {
 		t.Fatalf("readonly not preserved: %#v", out.Settings)
 	}
 }
+
+func TestSourceTagsValidate(t *testing.T) {
+	t.Parallel()
+
+	if err := (SourceTags{"env": "prod", "team.owner": "payments", "k8s-cluster": ""}).Validate(); err != nil {
+		t.Errorf("valid tags rejected: %v", err)
+	}
+	for name, tags := range map[string]SourceTags{
+		"bad key":          {"1env": "prod"},
+		"space in key":     {"my env": "prod"},
+		"padded value":     {"env": " prod"},
+		"oversized value":  {"env": strings.Repeat("x", MaxSourceTagValueSize+1)},
+		"too many entries": manyTags(MaxSourceTags + 1),
+	} {
+		if tags.Validate() == nil {
+			t.Errorf("%s: accepted %v", name, tags)
+		}
+	}
+}
+
+func manyTags(n int) SourceTags {
+	tags := make(SourceTags, n)
+	for i := range n {
+		tags["t"+strings.Repeat("x", i)] = "v"
+	}
+	return tags
+}
+
+func TestTagSelector(t *testing.T) {
+	t.Parallel()
+
+	tags := SourceTags{"env": "prod", "region": "eu"}
+	cases := []struct {
+		terms []string
+		want  bool
+	}{
+		{nil, true},
+		{[]string{"env=prod"}, true},
+		{[]string{"env=prod", "region"}, true},
+		{[]string{"env=prod,region=eu"}, true},
+		{[]string{"env=staging"}, false},
+		{[]string{"env=prod", "app"}, false},
+		{[]string{"region="}, false},
+	}
+	for _, tc := range cases {
+		selector, err := ParseTagSelector(tc.terms...)
+		if err != nil {
+			t.Fatalf("ParseTagSelector(%q): %v", tc.terms, err)
+		}
+		if got := selector.Matches(tags); got != tc.want {
+			t.Errorf("selector %q matches %v = %v, want %v", tc.terms, tags, got, tc.want)
+		}
+	}
+	if _, err := ParseTagSelector("=prod"); err == nil {
+		t.Error("ParseTagSelector accepted a term without a key")
+	}
+}
+
+func TestDecodeSourceTags(t *testing.T) {
+	t.Parallel()
+
+	tags := SourceTags{"env": "prod"}
+	if got := DecodeSourceTags([]byte(tags.Encode())); !got.Equal(tags) {
+		t.Errorf("round trip = %v, want %v", got, tags)
+	}
+	if got := (SourceTags(nil)).Encode(); got != "{}" {
+		t.Errorf("nil tags encode to %q, want {}", got)
+	}
+	if got := DecodeSourceTags([]byte("not json")); got != nil {
+		t.Errorf("malformed column decoded to %v", got)
+	}
+}
+
+func TestExtractionRulesValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   ExtractionRules
+		wantErr bool
+	}{
+		{"regex", ExtractionRules{{Name: "status", Kind: ExtractionRegex, Pattern: `status=(\d+)`, Type: ExtractionInt}}, false},
+		{"json with $ prefix", ExtractionRules{{Name: "user_id", Kind: ExtractionJSON, Path: "$.user.id"}}, false},
+		{"bad name", ExtractionRules{{Name: "user-id", Kind: ExtractionJSON, Path: "user.id"}}, true},
+		{"duplicate", ExtractionRules{{Name: "a", Kind: ExtractionJSON, Path: "a"}, {Name: "a", Kind: ExtractionJSON, Path: "b"}}, true},
+		{"bad pattern", ExtractionRules{{Name: "a", Kind: ExtractionRegex, Pattern: `(`}}, true},
+		{"empty path", ExtractionRules{{Name: "a", Kind: ExtractionJSON}}, true},
+		{"path with quote", ExtractionRules{{Name: "a", Kind: ExtractionJSON, Path: "a'b"}}, true},
+		{"bad field", ExtractionRules{{Name: "a", Kind: ExtractionJSON, Path: "a", Field: "body)"}}, true},
+		{"bad type", ExtractionRules{{Name: "a", Kind: ExtractionJSON, Path: "a", Type: "date"}}, true},
+		{"unknown kind", ExtractionRules{{Name: "a", Kind: "grok", Pattern: "%{IP}"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rules.Normalize().Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractionRuleExpression(t *testing.T) {
+	rules := ExtractionRules{
+		{Name: "status", Kind: ExtractionRegex, Pattern: `status=(\d+) it's`, Type: ExtractionInt},
+		{Name: "user_id", Kind: ExtractionJSON, Path: "$.user.id"},
+		{Name: "latency", Kind: ExtractionJSON, Field: "payload", Path: "timing.ms", Type: ExtractionFloat},
+	}.Normalize()
+	want := []string{
+		`toInt64OrNull(extract(` + "`body`" + `, 'status=(\\d+) it\'s'))`,
+		"JSONExtractString(`body`, 'user', 'id')",
+		"JSONExtract(`payload`, 'timing', 'ms', 'Nullable(Float64)')",
+	}
+	for i, rule := range rules {
+		if got := rule.Expression(); got != want[i] {
+			t.Errorf("%s: Expression() = %s, want %s", rule.Name, got, want[i])
+		}
+	}
+
+	columns := rules.VirtualColumns([]ColumnInfo{{Name: "body", Type: "String"}, {Name: "user_id", Type: "String"}})
+	if len(columns) != 2 || columns[0].Name != "status" || columns[0].Type != "Nullable(Int64)" || !columns[0].Virtual || columns[1].Name != "latency" {
+		t.Errorf("VirtualColumns() = %+v, want status and latency (user_id is a real column)", columns)
+	}
+}
+
+func TestMaskedColumnsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns MaskedColumns
+		wantErr bool
+	}{
+		{"hash by default", MaskedColumns{{Column: " email "}}, false},
+		{"redact", MaskedColumns{{Column: "client_ip", Mode: MaskRedact}}, false},
+		{"bad name", MaskedColumns{{Column: "user.email"}}, true},
+		{"duplicate", MaskedColumns{{Column: "email"}, {Column: "email", Mode: MaskRedact}}, true},
+		{"unknown mode", MaskedColumns{{Column: "email", Mode: "truncate"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.columns.Normalize().Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaskedColumnExpression(t *testing.T) {
+	columns := MaskedColumns{{Column: "email"}, {Column: "client_ip", Mode: MaskRedact}}.Normalize()
+	want := []string{"hex(SHA256(toString(`email`)))", "'[REDACTED]'"}
+	for i, col := range columns {
+		if got := col.Expression(); got != want[i] {
+			t.Errorf("%s: Expression() = %s, want %s", col.Column, got, want[i])
+		}
+	}
+	if got := DecodeMaskedColumns([]byte(columns.Encode())); !got.Equal(columns) {
+		t.Errorf("DecodeMaskedColumns(Encode()) = %v, want %v", got, columns)
+	}
+}
+
+func TestTraceCorrelationValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tracing *TraceCorrelation
+		wantErr bool
+	}{
+		{"unset", nil, false},
+		{"empty clears", &TraceCorrelation{TraceIDField: "  "}, false},
+		{"fields only", &TraceCorrelation{TraceIDField: "trace_id", SpanIDField: "span_id"}, false},
+		{"nested field", &TraceCorrelation{TraceIDField: "attributes.trace_id"}, false},
+		{"jaeger", &TraceCorrelation{TraceIDField: "trace_id", URLTemplate: "https://jaeger.example.com/trace/{trace_id}"}, false},
+		{"tempo with span", &TraceCorrelation{TraceIDField: "trace_id", SpanIDField: "span_id", URLTemplate: "https://grafana.example.com/explore?traceId={trace_id}&spanId={span_id}"}, false},
+		{"template only", &TraceCorrelation{URLTemplate: "https://jaeger.example.com/trace/{trace_id}"}, true},
+		{"bad field", &TraceCorrelation{TraceIDField: "trace-id"}, true},
+		{"no placeholder", &TraceCorrelation{TraceIDField: "trace_id", URLTemplate: "https://jaeger.example.com/search"}, true},
+		{"span without field", &TraceCorrelation{TraceIDField: "trace_id", URLTemplate: "https://t.example.com/{trace_id}/{span_id}"}, true},
+		{"not http", &TraceCorrelation{TraceIDField: "trace_id", URLTemplate: "javascript:alert('{trace_id}')"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tracing.Normalize().Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTraceCorrelationTraceURL(t *testing.T) {
+	tracing := &TraceCorrelation{
+		TraceIDField: "trace_id",
+		SpanIDField:  "span_id",
+		URLTemplate:  "https://grafana.example.com/explore?traceId={trace_id}&spanId={span_id}",
+	}
+	if got, want := tracing.TraceURL("4bf92f35", "a&b"), "https://grafana.example.com/explore?traceId=4bf92f35&spanId=a%26b"; got != want {
+		t.Errorf("TraceURL() = %q, want %q", got, want)
+	}
+	if got := (&TraceCorrelation{TraceIDField: "trace_id"}).TraceURL("4bf92f35", ""); got != "" {
+		t.Errorf("TraceURL() without a template = %q, want empty", got)
+	}
+
+	if got := DecodeTraceCorrelation([]byte(tracing.Encode())); !got.Equal(tracing) {
+		t.Errorf("Decode(Encode()) = %+v, want %+v", got, tracing)
+	}
+	var unset *TraceCorrelation
+	if got := DecodeTraceCorrelation([]byte(unset.Encode())); got != nil {
+		t.Errorf("Decode(%q) = %+v, want nil", unset.Encode(), got)
+	}
+}
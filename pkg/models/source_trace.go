@@ -0,0 +1,115 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Placeholders substituted into a TraceCorrelation URL template.
+const (
+	TraceIDPlaceholder = "{trace_id}"
+	SpanIDPlaceholder  = "{span_id}"
+)
+
+var traceFieldRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// TraceCorrelation maps a source's trace and span ID columns to an external
+// tracing backend such as Tempo or Jaeger, so a log line can link to its
+// trace and a trace ID can be looked up across sources. For example
+// {"trace_id_field": "trace_id", "url_template": "https://jaeger.example.com/trace/{trace_id}"}.
+type TraceCorrelation struct {
+	TraceIDField string `json:"trace_id_field"`
+	SpanIDField  string `json:"span_id_field,omitempty"`
+	// URLTemplate links to the tracing backend; {trace_id} and {span_id} are
+	// replaced with the row's escaped values.
+	URLTemplate string `json:"url_template,omitempty"`
+	// BackendName labels the link in the UI, e.g. "Tempo".
+	BackendName string `json:"backend_name,omitempty"`
+}
+
+// Normalize trims the fields. A configuration without a trace ID field is
+// no configuration, so it returns nil.
+func (t *TraceCorrelation) Normalize() *TraceCorrelation {
+	if t == nil {
+		return nil
+	}
+	out := TraceCorrelation{
+		TraceIDField: strings.TrimSpace(t.TraceIDField),
+		SpanIDField:  strings.TrimSpace(t.SpanIDField),
+		URLTemplate:  strings.TrimSpace(t.URLTemplate),
+		BackendName:  strings.TrimSpace(t.BackendName),
+	}
+	if out.TraceIDField == "" && out.SpanIDField == "" && out.URLTemplate == "" {
+		return nil
+	}
+	return &out
+}
+
+// Validate checks the field names and that the URL template is an http(s)
+// URL using {trace_id}. Call it on a normalized configuration.
+func (t *TraceCorrelation) Validate() error {
+	if t == nil {
+		return nil
+	}
+	if !traceFieldRe.MatchString(t.TraceIDField) {
+		return fmt.Errorf("trace_id_field %q is not a valid column name", t.TraceIDField)
+	}
+	if t.SpanIDField != "" && !traceFieldRe.MatchString(t.SpanIDField) {
+		return fmt.Errorf("span_id_field %q is not a valid column name", t.SpanIDField)
+	}
+	if t.URLTemplate == "" {
+		return nil
+	}
+	if !strings.Contains(t.URLTemplate, TraceIDPlaceholder) {
+		return fmt.Errorf("url_template must contain %s", TraceIDPlaceholder)
+	}
+	if strings.Contains(t.URLTemplate, SpanIDPlaceholder) && t.SpanIDField == "" {
+		return fmt.Errorf("url_template uses %s but no span_id_field is set", SpanIDPlaceholder)
+	}
+	u, err := url.Parse(t.TraceURL("x", "x"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url_template must be an http or https URL")
+	}
+	return nil
+}
+
+// TraceURL fills in the URL template, or returns "" without one.
+func (t *TraceCorrelation) TraceURL(traceID, spanID string) string {
+	if t == nil || t.URLTemplate == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		TraceIDPlaceholder, url.QueryEscape(traceID),
+		SpanIDPlaceholder, url.QueryEscape(spanID),
+	).Replace(t.URLTemplate)
+}
+
+// Equal reports whether t and other hold the same configuration.
+func (t *TraceCorrelation) Equal(other *TraceCorrelation) bool {
+	if t == nil || other == nil {
+		return t == other
+	}
+	return *t == *other
+}
+
+// Encode returns the JSON object stored in the sources table.
+func (t *TraceCorrelation) Encode() string {
+	if t == nil {
+		return "{}"
+	}
+	b, _ := json.Marshal(t)
+	return string(b)
+}
+
+// DecodeTraceCorrelation parses a stored trace_correlation column. Malformed
+// or empty input yields nil.
+func DecodeTraceCorrelation(raw []byte) *TraceCorrelation {
+	var t TraceCorrelation
+	if err := json.Unmarshal(raw, &t); err != nil || t.TraceIDField == "" {
+		return nil
+	}
+	return &t
+}
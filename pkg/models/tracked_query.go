@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// TrackedQuery is a long-running query persisted by the server's query
+// tracker. ID is the tracker's query ID, which is also the query's ClickHouse
+// query_id, and Instance is the host that ran it, so a restarted process can
+// find and kill what it left running.
+type TrackedQuery struct {
+	ID        string    `json:"id"`
+	Instance  string    `json:"instance"`
+	Class     string    `json:"class"`
+	UserID    UserID    `json:"user_id"`
+	TeamID    TeamID    `json:"team_id"`
+	SourceID  SourceID  `json:"source_id"`
+	QueryText string    `json:"query_text"`
+	StartedAt time.Time `json:"started_at"`
+}
@@ -0,0 +1,90 @@
+package models
+
+import "time"
+
+// Volume anomaly defaults.
+const (
+	DefaultVolumeAnomalyThreshold = 100.0 // percent, i.e. a 2x change either way
+	DefaultVolumeAnomalyMinRows   = 100
+	// minErrorRateRise is the smallest error-rate increase, in percentage
+	// points, that is flagged, so a rate going from 0.01% to 0.03% isn't.
+	minErrorRateRise = 1.0
+)
+
+// VolumeAnomalyKind names a deviation found by a volume comparison.
+type VolumeAnomalyKind string
+
+const (
+	VolumeAnomalySpike          VolumeAnomalyKind = "volume_spike"
+	VolumeAnomalyDrop           VolumeAnomalyKind = "volume_drop"
+	VolumeAnomalyErrorRateSpike VolumeAnomalyKind = "error_rate_spike"
+)
+
+// VolumeWindow counts a source's rows in one time window. The error fields
+// are nil when the source has no severity field.
+type VolumeWindow struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Rows      int64     `json:"rows"`
+	ErrorRows *int64    `json:"error_rows,omitempty"`
+	// ErrorRate is ErrorRows as a percentage of Rows.
+	ErrorRate *float64 `json:"error_rate,omitempty"`
+}
+
+// SetErrors records the window's error rows and derives its error rate.
+func (w *VolumeWindow) SetErrors(errorRows int64) {
+	rate := 0.0
+	if w.Rows > 0 {
+		rate = float64(errorRows) * 100 / float64(w.Rows)
+	}
+	w.ErrorRows = &errorRows
+	w.ErrorRate = &rate
+}
+
+// VolumeAnomalyReport compares a source's current window with the same
+// window a day or a week earlier.
+type VolumeAnomalyReport struct {
+	Baseline         AlertBaseline `json:"baseline"`
+	ThresholdPercent float64       `json:"threshold_percent"`
+	MinRows          int64         `json:"min_rows"`
+	Current          VolumeWindow  `json:"current"`
+	Previous         VolumeWindow  `json:"previous"`
+	// VolumeChangePercent is computed like a baseline alert's value: the
+	// divisor is at least 1 so an empty previous window stays finite.
+	VolumeChangePercent float64 `json:"volume_change_percent"`
+	// ErrorRateChange is the error-rate difference in percentage points.
+	ErrorRateChange *float64            `json:"error_rate_change,omitempty"`
+	Anomalies       []VolumeAnomalyKind `json:"anomalies"`
+}
+
+// Evaluate fills in the changes and flags deviations. A change of
+// ThresholdPercent is read as a ratio r = 1 + ThresholdPercent/100, so volume
+// is flagged when it grows by r or shrinks to 1/r: the default 100% flags a
+// doubling or a halving. Windows where neither side reaches MinRows are too
+// small to judge. An error-rate spike needs the rate to grow by r and by at
+// least one percentage point.
+func (r *VolumeAnomalyReport) Evaluate() {
+	cur, prev := float64(r.Current.Rows), float64(r.Previous.Rows)
+	r.VolumeChangePercent = (cur - prev) * 100 / max(prev, 1)
+	r.Anomalies = []VolumeAnomalyKind{}
+	hasErrors := r.Current.ErrorRate != nil && r.Previous.ErrorRate != nil
+	if hasErrors {
+		change := *r.Current.ErrorRate - *r.Previous.ErrorRate
+		r.ErrorRateChange = &change
+	}
+
+	if max(r.Current.Rows, r.Previous.Rows) < r.MinRows {
+		return
+	}
+	ratio := 1 + r.ThresholdPercent/100
+	switch {
+	case cur >= prev*ratio:
+		r.Anomalies = append(r.Anomalies, VolumeAnomalySpike)
+	case cur*ratio <= prev:
+		r.Anomalies = append(r.Anomalies, VolumeAnomalyDrop)
+	}
+	if hasErrors && r.Current.Rows >= r.MinRows && *r.ErrorRateChange >= minErrorRateRise &&
+		*r.Current.ErrorRate >= *r.Previous.ErrorRate*ratio {
+		r.Anomalies = append(r.Anomalies, VolumeAnomalyErrorRateSpike)
+	}
+}
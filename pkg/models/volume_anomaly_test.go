@@ -0,0 +1,44 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVolumeAnomalyReportEvaluate(t *testing.T) {
+	window := func(rows, errors int64) VolumeWindow {
+		w := VolumeWindow{Rows: rows}
+		w.SetErrors(errors)
+		return w
+	}
+	cases := []struct {
+		name     string
+		current  VolumeWindow
+		previous VolumeWindow
+		want     []VolumeAnomalyKind
+	}{
+		{"steady", window(1000, 10), window(900, 9), []VolumeAnomalyKind{}},
+		{"doubled", window(2000, 20), window(1000, 10), []VolumeAnomalyKind{VolumeAnomalySpike}},
+		{"halved", window(500, 5), window(1000, 10), []VolumeAnomalyKind{VolumeAnomalyDrop}},
+		{"errors up", window(1000, 50), window(1000, 10), []VolumeAnomalyKind{VolumeAnomalyErrorRateSpike}},
+		{"tiny error rates", window(1000, 3), window(1000, 1), []VolumeAnomalyKind{}},
+		{"too few rows", window(90, 90), window(10, 0), []VolumeAnomalyKind{}},
+		{"new traffic", window(500, 0), window(0, 0), []VolumeAnomalyKind{VolumeAnomalySpike}},
+		{"no severity field", VolumeWindow{Rows: 1000}, VolumeWindow{Rows: 1000}, []VolumeAnomalyKind{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := VolumeAnomalyReport{ThresholdPercent: DefaultVolumeAnomalyThreshold, MinRows: DefaultVolumeAnomalyMinRows, Current: tc.current, Previous: tc.previous}
+			r.Evaluate()
+			if !slices.Equal(r.Anomalies, tc.want) {
+				t.Errorf("Anomalies = %v, want %v", r.Anomalies, tc.want)
+			}
+		})
+	}
+
+	r := VolumeAnomalyReport{ThresholdPercent: 100, MinRows: 100, Current: window(300, 30), Previous: window(200, 10)}
+	r.Evaluate()
+	if r.VolumeChangePercent != 50 || r.ErrorRateChange == nil || *r.ErrorRateChange != 5 {
+		t.Errorf("changes = %v%% volume, %v pp error rate; want 50 and 5", r.VolumeChangePercent, r.ErrorRateChange)
+	}
+}
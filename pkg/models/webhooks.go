@@ -0,0 +1,89 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Webhook is an outbound endpoint that receives the lifecycle events it
+// subscribes to, each signed with its secret.
+type Webhook struct {
+	ID   WebhookID `json:"id"`
+	Name string    `json:"name"`
+	URL  string    `json:"url"`
+	// EventTypes the webhook receives; EventAll receives every one.
+	EventTypes []EventType `json:"event_types"`
+	// Secret is the HMAC-SHA256 signing key. It is returned only when the
+	// webhook is created or its secret rotated.
+	Secret    string  `json:"-"`
+	IsActive  bool    `json:"is_active"`
+	CreatedBy *UserID `json:"created_by,omitempty"`
+	Timestamps
+}
+
+// WebhookWithSecret is the response that reveals a webhook's signing secret.
+type WebhookWithSecret struct {
+	*Webhook
+	Secret string `json:"secret"`
+}
+
+// CreateWebhookRequest is the body for registering a webhook. An empty Secret
+// is generated.
+type CreateWebhookRequest struct {
+	Name       string      `json:"name"`
+	URL        string      `json:"url"`
+	EventTypes []EventType `json:"event_types"`
+	Secret     string      `json:"secret"`
+	IsActive   *bool       `json:"is_active"`
+}
+
+// UpdateWebhookRequest replaces a webhook's settings. RotateSecret replaces
+// its signing secret with a generated one.
+type UpdateWebhookRequest struct {
+	Name         string      `json:"name"`
+	URL          string      `json:"url"`
+	EventTypes   []EventType `json:"event_types"`
+	IsActive     *bool       `json:"is_active"`
+	RotateSecret bool        `json:"rotate_secret"`
+}
+
+// MinWebhookSecretLength is the shortest signing secret accepted.
+const MinWebhookSecretLength = 16
+
+// Validate checks the webhook's name, URL, event types and secret, trimming
+// the name and dropping duplicate event types.
+func (w *Webhook) Validate() error {
+	w.Name = strings.TrimSpace(w.Name)
+	w.URL = strings.TrimSpace(w.URL)
+	if w.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	parsed, err := url.Parse(w.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if len(w.EventTypes) == 0 {
+		return fmt.Errorf("at least one event type is required")
+	}
+	types := make([]EventType, 0, len(w.EventTypes))
+	for _, t := range w.EventTypes {
+		if t != EventAll && !slices.Contains(EventTypes, t) {
+			return fmt.Errorf("unknown event type %q", t)
+		}
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	w.EventTypes = types
+	if len(w.Secret) < MinWebhookSecretLength {
+		return fmt.Errorf("secret must be at least %d characters", MinWebhookSecretLength)
+	}
+	return nil
+}
+
+// Subscribes reports whether the webhook receives events of type t.
+func (w *Webhook) Subscribes(t EventType) bool {
+	return slices.Contains(w.EventTypes, EventAll) || slices.Contains(w.EventTypes, t)
+}